@@ -47,6 +47,7 @@ type Entry[K cachekey, V any] struct {
 	cost      atomic.Int64
 	frequency atomic.Int32
 	epoch     atomic.Uint32
+	expire    atomic.Int64 // unix nanoseconds, 0 if the entry does not expire
 	removed   bool
 	deque     bool
 	root      bool
@@ -62,6 +63,12 @@ func NewEntry[K cachekey, V any](key K, value V, cost int64) *Entry[K, V] {
 	return entry
 }
 
+// expired returns whether the entry is expired at now, in unix nanoseconds.
+func (e *Entry[K, V]) expired(now int64) bool {
+	expire := e.expire.Load()
+	return expire != 0 && expire <= now
+}
+
 func (e *Entry[K, V]) Next() *Entry[K, V] {
 	if p := e.meta.next; !p.root {
 		return e.meta.next
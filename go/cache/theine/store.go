@@ -18,6 +18,7 @@ limitations under the License.
 package theine
 
 import (
+	"container/heap"
 	"hash/maphash"
 	"runtime"
 	"sync"
@@ -52,6 +53,11 @@ type Shard[K cachekey, V any] struct {
 	qlen       int
 	counter    uint
 	mu         sync.RWMutex
+
+	// The reads are buffered per shard, so that concurrent reads of different
+	// shards do not contend on the same counter and queue.
+	readbuf     *Queue[ReadBufItem[K, V]]
+	readCounter atomic.Uint32
 }
 
 func NewShard[K cachekey, V any](qsize uint, doorkeeper bool) *Shard[K, V] {
@@ -60,6 +66,7 @@ func NewShard[K cachekey, V any](qsize uint, doorkeeper bool) *Shard[K, V] {
 		qsize:   qsize,
 		deque:   deque.New[*Entry[K, V]](),
 		group:   NewGroup[K, V](),
+		readbuf: NewQueue[ReadBufItem[K, V]](),
 	}
 	if doorkeeper {
 		s.doorkeeper = bf.New(0.01)
@@ -165,7 +172,6 @@ type Store[K cachekey, V cacheval] struct {
 	entryPool    sync.Pool
 	writebuf     chan WriteBufItem[K, V]
 	policy       *TinyLfu[K, V]
-	readbuf      *Queue[ReadBufItem[K, V]]
 	shards       []*Shard[K, V]
 	cap          uint
 	shardCount   uint
@@ -173,9 +179,21 @@ type Store[K cachekey, V cacheval] struct {
 	tailUpdate   bool
 	doorkeeper   bool
 
-	mlock       sync.Mutex
-	readCounter atomic.Uint32
-	open        atomic.Bool
+	clock func() time.Time
+
+	// expiries queues the entries set with a TTL by expiry, for the
+	// maintenance to remove them once they expire.
+	expmu    sync.Mutex
+	expiries expiryQueue[K, V]
+
+	mlock sync.Mutex
+	open  atomic.Bool
+}
+
+type removal[K cachekey, V cacheval] struct {
+	key    K
+	value  V
+	reason RemoveReason
 }
 
 func NewStore[K cachekey, V cacheval](maxsize int64, doorkeeper bool) *Store[K, V] {
@@ -202,12 +220,12 @@ func NewStore[K cachekey, V cacheval](maxsize int64, doorkeeper bool) *Store[K,
 	s := &Store[K, V]{
 		cap:          uint(maxsize),
 		policy:       NewTinyLfu[K, V](uint(policySize)),
-		readbuf:      NewQueue[ReadBufItem[K, V]](),
 		writebuf:     make(chan WriteBufItem[K, V], writeBufSize),
 		entryPool:    sync.Pool{New: func() any { return &Entry[K, V]{} }},
 		shardCount:   uint(shardCount),
 		doorkeeper:   doorkeeper,
 		writebufsize: writeBufSize,
+		clock:        time.Now,
 	}
 	s.shards = make([]*Shard[K, V], 0, s.shardCount)
 	for range s.shardCount {
@@ -227,13 +245,38 @@ func (s *Store[K, V]) EnsureOpen() {
 	go s.maintenance()
 }
 
+// expiredNow returns whether the entry is expired, only reading the clock for
+// the entries which expire.
+func (s *Store[K, V]) expiredNow(entry *Entry[K, V]) bool {
+	return entry.expire.Load() != 0 && entry.expired(s.clock().UnixNano())
+}
+
+// expireAt returns the expiry of an entry set with ttl, in unix nanoseconds.
+func (s *Store[K, V]) expireAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return s.clock().Add(ttl).UnixNano()
+}
+
+// queueExpiry queues the entry, set to expire at expire, for the maintenance
+// to remove it once it expires.
+func (s *Store[K, V]) queueExpiry(entry *Entry[K, V], key K, expire int64) {
+	if expire == 0 {
+		return
+	}
+	s.expmu.Lock()
+	heap.Push(&s.expiries, expiry[K, V]{entry: entry, key: key, expire: expire})
+	s.expmu.Unlock()
+}
+
 func (s *Store[K, V]) getFromShard(key K, hash uint64, shard *Shard[K, V], epoch uint32) (V, bool) {
-	new := s.readCounter.Add(1)
+	new := shard.readCounter.Add(1)
 	shard.mu.RLock()
 	entry, ok := shard.get(key)
 	var value V
 	if ok {
-		if entry.epoch.Load() < epoch {
+		if entry.epoch.Load() < epoch || s.expiredNow(entry) {
 			s.Metrics.misses.Add(1)
 			ok = false
 		} else {
@@ -252,15 +295,15 @@ func (s *Store[K, V]) getFromShard(key K, hash uint64, shard *Shard[K, V], epoch
 		if ok {
 			send.entry = entry
 		}
-		s.readbuf.Push(send)
+		shard.readbuf.Push(send)
 	case new == MaxReadBuffSize:
 		var send ReadBufItem[K, V]
 		send.hash = hash
 		if ok {
 			send.entry = entry
 		}
-		s.readbuf.Push(send)
-		s.drainRead()
+		shard.readbuf.Push(send)
+		s.drainRead(shard)
 	}
 	return value, ok
 }
@@ -272,6 +315,12 @@ func (s *Store[K, V]) Get(key K, epoch uint32) (V, bool) {
 }
 
 func (s *Store[K, V]) GetOrLoad(key K, epoch uint32, load func() (V, error)) (V, bool, error) {
+	return s.GetOrLoadWithTTL(key, epoch, 0, load)
+}
+
+// GetOrLoadWithTTL is like GetOrLoad, but the loaded value expires after ttl,
+// unless ttl is 0.
+func (s *Store[K, V]) GetOrLoadWithTTL(key K, epoch uint32, ttl time.Duration, load func() (V, error)) (V, bool, error) {
 	h, index := s.index(key)
 	shard := s.shards[index]
 	v, ok := s.getFromShard(key, h, shard, epoch)
@@ -279,7 +328,7 @@ func (s *Store[K, V]) GetOrLoad(key K, epoch uint32, load func() (V, error)) (V,
 		loaded, err, _ := shard.group.Do(key, func() (V, error) {
 			loaded, err := load()
 			if err == nil {
-				s.Set(key, loaded, 0, epoch)
+				s.SetWithTTL(key, loaded, 0, epoch, ttl)
 			}
 			return loaded, err
 		})
@@ -302,7 +351,7 @@ func (s *Store[K, V]) setEntry(shard *Shard[K, V], cost int64, epoch uint32, ent
 	s.processDeque(shard, epoch)
 }
 
-func (s *Store[K, V]) setInternal(key K, value V, cost int64, epoch uint32) (*Shard[K, V], *Entry[K, V], bool) {
+func (s *Store[K, V]) setInternal(key K, value V, cost int64, epoch uint32, expire int64) (*Shard[K, V], *Entry[K, V], bool) {
 	h, index := s.index(key)
 	shard := s.shards[index]
 	shard.mu.Lock()
@@ -310,6 +359,8 @@ func (s *Store[K, V]) setInternal(key K, value V, cost int64, epoch uint32) (*Sh
 	if ok {
 		var costChange int64
 		exist.value = value
+		exist.expire.Store(expire)
+		s.queueExpiry(exist, key, expire)
 		oldCost := exist.cost.Swap(cost)
 		if oldCost != cost {
 			costChange = cost - oldCost
@@ -344,33 +395,37 @@ func (s *Store[K, V]) setInternal(key K, value V, cost int64, epoch uint32) (*Sh
 	entry.value = value
 	entry.cost.Store(cost)
 	entry.epoch.Store(epoch)
+	entry.expire.Store(expire)
+	s.queueExpiry(entry, key, expire)
 	s.setEntry(shard, cost, epoch, entry)
 	return shard, entry, true
 
 }
 
 func (s *Store[K, V]) Set(key K, value V, cost int64, epoch uint32) bool {
+	return s.SetWithTTL(key, value, cost, epoch, 0)
+}
+
+// SetWithTTL is like Set, but the entry expires after ttl, unless ttl is 0.
+// The expired entries are not returned, and are removed with the EXPIRED
+// reason.
+func (s *Store[K, V]) SetWithTTL(key K, value V, cost int64, epoch uint32, ttl time.Duration) bool {
 	if cost == 0 {
 		cost = value.CachedSize(true)
 	}
 	if cost > int64(s.cap) {
 		return false
 	}
-	_, _, ok := s.setInternal(key, value, cost, epoch)
+	_, _, ok := s.setInternal(key, value, cost, epoch, s.expireAt(ttl))
 	return ok
 }
 
-type dequeKV[K cachekey, V cacheval] struct {
-	k K
-	v V
-}
-
 func (s *Store[K, V]) processDeque(shard *Shard[K, V], epoch uint32) {
 	if shard.qlen <= int(shard.qsize) {
 		shard.mu.Unlock()
 		return
 	}
-	var evictedkv []dequeKV[K, V]
+	var evictedkv []removal[K, V]
 
 	// send to slru
 	send := make([]*Entry[K, V], 0, 2)
@@ -379,11 +434,15 @@ func (s *Store[K, V]) processDeque(shard *Shard[K, V], epoch uint32) {
 		evicted.deque = false
 		shard.qlen -= int(evicted.cost.Load())
 
-		if evicted.epoch.Load() < epoch {
+		if expired := s.expiredNow(evicted); expired || evicted.epoch.Load() < epoch {
 			deleted := shard.delete(evicted)
 			if deleted {
 				if s.OnRemoval != nil {
-					evictedkv = append(evictedkv, dequeKV[K, V]{evicted.key, evicted.value})
+					reason := EVICTED
+					if expired {
+						reason = EXPIRED
+					}
+					evictedkv = append(evictedkv, removal[K, V]{evicted.key, evicted.value, reason})
 				}
 				s.postDelete(evicted)
 				s.Metrics.evicted.Add(1)
@@ -401,7 +460,7 @@ func (s *Store[K, V]) processDeque(shard *Shard[K, V], epoch uint32) {
 					// double check because entry maybe removed already by Delete API
 					if deleted {
 						if s.OnRemoval != nil {
-							evictedkv = append(evictedkv, dequeKV[K, V]{evicted.key, evicted.value})
+							evictedkv = append(evictedkv, removal[K, V]{evicted.key, evicted.value, EVICTED})
 						}
 						s.postDelete(evicted)
 						s.Metrics.evicted.Add(1)
@@ -416,8 +475,8 @@ func (s *Store[K, V]) processDeque(shard *Shard[K, V], epoch uint32) {
 		s.writebuf <- WriteBufItem[K, V]{entry: entry, code: NEW}
 	}
 	if s.OnRemoval != nil {
-		for _, kv := range evictedkv {
-			s.OnRemoval(kv.k, kv.v, EVICTED)
+		for _, r := range evictedkv {
+			s.OnRemoval(r.key, r.value, r.reason)
 		}
 	}
 }
@@ -499,18 +558,18 @@ func (s *Store[K, V]) removeEntry(entry *Entry[K, V], reason RemoveReason) {
 	}
 }
 
-func (s *Store[K, V]) drainRead() {
+func (s *Store[K, V]) drainRead(shard *Shard[K, V]) {
 	s.policy.total.Add(MaxReadBuffSize)
 	s.mlock.Lock()
 	for {
-		v, ok := s.readbuf.Pop()
+		v, ok := shard.readbuf.Pop()
 		if !ok {
 			break
 		}
 		s.policy.Access(v)
 	}
 	s.mlock.Unlock()
-	s.readCounter.Store(0)
+	shard.readCounter.Store(0)
 }
 
 func (s *Store[K, V]) maintenanceItem(item WriteBufItem[K, V]) {
@@ -568,6 +627,7 @@ func (s *Store[K, V]) maintenance() {
 		case <-tick.C:
 			s.mlock.Lock()
 			s.policy.UpdateThreshold()
+			s.removeExpired()
 			s.mlock.Unlock()
 
 		case item, ok := <-s.writebuf:
@@ -579,11 +639,86 @@ func (s *Store[K, V]) maintenance() {
 	}
 }
 
+// removeExpired removes the entries of the policy which expired, popping them
+// from the expiry queue. The expired entries still in the deque of their shard
+// are removed when they leave it. It must be called with mlock held.
+func (s *Store[K, V]) removeExpired() {
+	now := s.clock().UnixNano()
+	var due []expiry[K, V]
+	s.expmu.Lock()
+	for len(s.expiries) > 0 && s.expiries[0].expire <= now {
+		due = append(due, heap.Pop(&s.expiries).(expiry[K, V]))
+	}
+	s.expmu.Unlock()
+
+	removed := false
+	for _, e := range due {
+		_, index := s.index(e.key)
+		shard := s.shards[index]
+		shard.mu.Lock()
+		entry, ok := shard.get(e.key)
+		// The entry may have been set again, or deleted, since it was queued.
+		if !ok || entry != e.entry || entry.expire.Load() != e.expire || entry.deque {
+			shard.mu.Unlock()
+			continue
+		}
+		if entry.meta.prev == nil {
+			// The entry is not in the policy yet; retry on the next tick.
+			shard.mu.Unlock()
+			s.queueExpiry(entry, e.key, e.expire)
+			continue
+		}
+		deleted := shard.delete(entry)
+		shard.mu.Unlock()
+		if !deleted {
+			continue
+		}
+		s.policy.Remove(entry)
+		if s.OnRemoval != nil {
+			s.OnRemoval(entry.key, entry.value, EXPIRED)
+		}
+		s.postDelete(entry)
+		s.Metrics.evicted.Add(1)
+		removed = true
+	}
+	if removed {
+		s.policy.UpdateThreshold()
+	}
+}
+
+// expiry is an entry queued for removal once it expires. The key and expiry
+// are copied, to tell whether the entry was set again or reused since.
+type expiry[K cachekey, V cacheval] struct {
+	entry  *Entry[K, V]
+	key    K
+	expire int64
+}
+
+// expiryQueue is a min-heap of expiries, ordered by expiry.
+type expiryQueue[K cachekey, V cacheval] []expiry[K, V]
+
+func (q expiryQueue[K, V]) Len() int           { return len(q) }
+func (q expiryQueue[K, V]) Less(i, j int) bool { return q[i].expire < q[j].expire }
+func (q expiryQueue[K, V]) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *expiryQueue[K, V]) Push(x any) {
+	*q = append(*q, x.(expiry[K, V]))
+}
+
+func (q *expiryQueue[K, V]) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = expiry[K, V]{}
+	*q = old[:n-1]
+	return e
+}
+
 func (s *Store[K, V]) Range(epoch uint32, f func(key K, value V) bool) {
 	for _, shard := range s.shards {
 		shard.mu.RLock()
 		for _, entry := range shard.hashmap {
-			if entry.epoch.Load() < epoch {
+			if entry.epoch.Load() < epoch || s.expiredNow(entry) {
 				continue
 			}
 			if !f(entry.key, entry.value) {
@@ -606,4 +741,7 @@ func (s *Store[K, V]) Close() {
 		s.mu.Unlock()
 	}
 	close(s.writebuf)
+	s.expmu.Lock()
+	s.expiries = nil
+	s.expmu.Unlock()
 }
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package theine
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"vitess.io/vitess/go/cache"
+)
+
+const benchKeys = 4096
+
+func benchStore(b *testing.B) *Store[StringKey, cachedint] {
+	store := NewStore[StringKey, cachedint](benchKeys*2, false)
+	b.Cleanup(store.Close)
+	for i := range benchKeys {
+		store.Set(StringKey(strconv.Itoa(i)), cachedint(i), 1, 0)
+	}
+	return store
+}
+
+func benchLRU() *cache.LRUCache[cachedint] {
+	lru := cache.NewLRUCache[cachedint](benchKeys * 2)
+	for i := range benchKeys {
+		lru.Set(strconv.Itoa(i), cachedint(i))
+	}
+	return lru
+}
+
+func benchKeyNames() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+// BenchmarkCacheGetParallel compares concurrent reads of cached keys, the
+// access pattern of the plan caches, between the Store and the LRUCache.
+func BenchmarkCacheGetParallel(b *testing.B) {
+	keys := benchKeyNames()
+
+	b.Run("Store", func(b *testing.B) {
+		store := benchStore(b)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				store.Get(StringKey(keys[i%benchKeys]), 0)
+				i++
+			}
+		})
+	})
+
+	b.Run("LRUCache", func(b *testing.B) {
+		lru := benchLRU()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				lru.Get(keys[i%benchKeys])
+				i++
+			}
+		})
+	})
+}
+
+// BenchmarkCacheSetParallel compares concurrent writes, evicting keys, between
+// the Store and the LRUCache.
+func BenchmarkCacheSetParallel(b *testing.B) {
+	keys := make([]string, benchKeys*4)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.Run("Store", func(b *testing.B) {
+		store := benchStore(b)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				store.Set(StringKey(keys[i%len(keys)]), cachedint(i), 1, 0)
+				i++
+			}
+		})
+	})
+
+	b.Run("StoreWithTTL", func(b *testing.B) {
+		store := benchStore(b)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				store.SetWithTTL(StringKey(keys[i%len(keys)]), cachedint(i), 1, 0, time.Minute)
+				i++
+			}
+		})
+	})
+
+	b.Run("LRUCache", func(b *testing.B) {
+		lru := benchLRU()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				lru.Set(keys[i%len(keys)], cachedint(i))
+				i++
+			}
+		})
+	})
+}
+
+// BenchmarkCacheGetOrLoadParallel measures concurrent reads of the Store which
+// mostly hit, and load the missing keys.
+func BenchmarkCacheGetOrLoadParallel(b *testing.B) {
+	keys := make([]string, benchKeys*2)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	store := benchStore(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_, _, _ = store.GetOrLoad(StringKey(keys[i%len(keys)]), 0, func() (cachedint, error) {
+				return cachedint(i), nil
+			})
+			i++
+		}
+	})
+}
@@ -18,7 +18,9 @@ limitations under the License.
 package theine
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -80,3 +82,102 @@ func TestDoorKeeperDynamicSize(t *testing.T) {
 	}
 	require.True(t, shard.doorkeeper.Capacity > 100000)
 }
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestStoreTTL(t *testing.T) {
+	store := NewStore[keyint, cachedint](1000, false)
+	defer store.Close()
+	clock := &testClock{now: time.Unix(1000, 0)}
+	store.clock = clock.Now
+
+	store.SetWithTTL(1, 1, 0, 0, time.Minute)
+	store.Set(2, 2, 0, 0)
+	_, _, err := store.GetOrLoadWithTTL(3, 0, 2*time.Minute, func() (cachedint, error) { return 3, nil })
+	require.NoError(t, err)
+
+	v, ok := store.Get(1, 0)
+	require.True(t, ok)
+	require.Equal(t, cachedint(1), v)
+
+	clock.Advance(time.Minute)
+	_, ok = store.Get(1, 0)
+	require.False(t, ok)
+	_, ok = store.Get(2, 0)
+	require.True(t, ok)
+	_, ok = store.Get(3, 0)
+	require.True(t, ok)
+
+	var keys []keyint
+	store.Range(0, func(key keyint, _ cachedint) bool {
+		keys = append(keys, key)
+		return true
+	})
+	require.ElementsMatch(t, []keyint{2, 3}, keys)
+
+	// Setting the entry again sets its expiry again.
+	store.SetWithTTL(1, 10, 0, 0, time.Minute)
+	v, ok = store.Get(1, 0)
+	require.True(t, ok)
+	require.Equal(t, cachedint(10), v)
+	store.Set(1, 11, 0, 0)
+	clock.Advance(time.Hour)
+	_, ok = store.Get(1, 0)
+	require.True(t, ok)
+}
+
+func TestStoreRemoveExpired(t *testing.T) {
+	store := NewStore[keyint, cachedint](1000, false)
+	defer store.Close()
+	clock := &testClock{now: time.Unix(1000, 0)}
+	store.clock = clock.Now
+
+	var mu sync.Mutex
+	expired := map[keyint]bool{}
+	store.OnRemoval = func(key keyint, _ cachedint, reason RemoveReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		if reason == EXPIRED {
+			expired[key] = true
+		}
+	}
+
+	for i := range keyint(100) {
+		ttl := time.Duration(0)
+		if i%2 == 0 {
+			ttl = time.Minute
+		}
+		store.SetWithTTL(i, cachedint(i), 1, 0, ttl)
+	}
+	clock.Advance(time.Minute)
+
+	require.Eventually(t, func() bool {
+		return store.Len() == 50
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, expired, 50)
+	for key := range expired {
+		require.Zero(t, key%2)
+	}
+
+	// The expired entries are popped from the expiry queue.
+	store.expmu.Lock()
+	defer store.expmu.Unlock()
+	require.Empty(t, store.expiries)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+)
+
+var CheckConfig = &cobra.Command{
+	Use:   "check_config",
+	Short: "Reports the drift of the my.cnf file from its templates.",
+	Long: "Renders the configuration file of an existing `mysqld` instance from the templates and the `--mysqlctl-mycnf-class` class, and prints the settings of its my.cnf file which differ.\n" +
+		"The command fails when the my.cnf file drifted, `refresh_config` then rewrites it.",
+	Example: `mysqlctl \
+	--tablet_uid 101 \
+	--mysqlctl-mycnf-classes /etc/vitess/mycnf-classes.yaml \
+	--mysqlctl-mycnf-class large \
+	check_config`,
+	Args: cobra.NoArgs,
+	RunE: commandCheckConfig,
+}
+
+func commandCheckConfig(cmd *cobra.Command, args []string) error {
+	// There ought to be an existing my.cnf, so use it to find mysqld.
+	mysqld, cnf, err := mysqlctl.OpenMysqldAndMycnf(tabletUID, collationEnv)
+	if err != nil {
+		return fmt.Errorf("failed to find mysql config: %v", err)
+	}
+	defer mysqld.Close()
+
+	drifts, err := mysqld.CheckConfig(cnf)
+	if err != nil {
+		return fmt.Errorf("failed to check mysql config: %v", err)
+	}
+	for _, drift := range drifts {
+		fmt.Fprintln(cmd.OutOrStdout(), drift.String())
+	}
+	if len(drifts) > 0 {
+		return fmt.Errorf("%v drifted from its templates in %d settings", cnf.Path, len(drifts))
+	}
+	return nil
+}
+
+func init() {
+	Root.AddCommand(CheckConfig)
+}
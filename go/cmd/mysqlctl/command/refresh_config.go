@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+)
+
+var RefreshConfig = &cobra.Command{
+	Use:   "refresh_config",
+	Short: "Rewrites the my.cnf file from its templates if it drifted.",
+	Long: "Renders the configuration file of an existing `mysqld` instance from the templates and the `--mysqlctl-mycnf-class` class, keeping its server_id, and replaces its my.cnf file if it differs.\n" +
+		"The previous my.cnf file is kept with a `.previous` suffix. `mysqld` must be restarted to apply the changes.",
+	Example: `mysqlctl \
+	--tablet_uid 101 \
+	--mysqlctl-mycnf-classes /etc/vitess/mycnf-classes.yaml \
+	--mysqlctl-mycnf-class large \
+	refresh_config`,
+	Args: cobra.NoArgs,
+	RunE: commandRefreshConfig,
+}
+
+func commandRefreshConfig(cmd *cobra.Command, args []string) error {
+	// There ought to be an existing my.cnf, so use it to find mysqld.
+	mysqld, cnf, err := mysqlctl.OpenMysqldAndMycnf(tabletUID, collationEnv)
+	if err != nil {
+		return fmt.Errorf("failed to find mysql config: %v", err)
+	}
+	defer mysqld.Close()
+
+	if err := mysqld.RefreshConfig(context.TODO(), cnf); err != nil {
+		return fmt.Errorf("failed to refresh mysql config: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	Root.AddCommand(RefreshConfig)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+)
+
+var RenderConfig = &cobra.Command{
+	Use:   "render_config",
+	Short: "Renders the my.cnf file of a new mysqld instance and prints it.",
+	Long: "Renders the configuration file `init_config` would create for a new `mysqld` instance, including the settings of the `--mysqlctl-mycnf-class` class, validates it and prints it without writing it.\n" +
+		"This helps to review the changes to the my.cnf classes or templates before applying them.",
+	Example: `mysqlctl \
+	--tablet_uid 101 \
+	--mysql_port 12345 \
+	--mysqlctl-mycnf-classes /etc/vitess/mycnf-classes.yaml \
+	--mysqlctl-mycnf-class large \
+	--mysqlctl-mycnf-tablet-type primary \
+	render_config`,
+	Args: cobra.NoArgs,
+	RunE: commandRenderConfig,
+}
+
+func commandRenderConfig(cmd *cobra.Command, args []string) error {
+	mysqld, cnf, err := mysqlctl.CreateMysqldAndMycnf(tabletUID, mysqlSocket, mysqlPort, collationEnv)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mysql config: %v", err)
+	}
+	defer mysqld.Close()
+
+	configData, err := mysqld.RenderConfig(cnf)
+	if err != nil {
+		return fmt.Errorf("failed to render mysql config: %v", err)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), configData)
+	return nil
+}
+
+func init() {
+	Root.AddCommand(RenderConfig)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstats"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+)
+
+const (
+	// standbyPrimaryCheckInterval is how often a standby checks whether the
+	// primary of the shard changed, to replicate from the new one.
+	standbyPrimaryCheckInterval = 30 * time.Second
+)
+
+var (
+	standby           bool
+	standbyTabletUID  uint32
+	standbyLagSeconds = stats.NewGauge(
+		"StandbyReplicationLagSeconds",
+		"Replication lag of the standby data directory (in seconds), or -1 if it is unknown.",
+	)
+)
+
+// validateStandbyFlags checks that the flags are compatible with --standby.
+func validateStandbyFlags() error {
+	if standbyTabletUID == 0 {
+		return fmt.Errorf("--standby-tablet-uid is required with --standby")
+	}
+	if initialBackup {
+		return fmt.Errorf("--standby and --initial_backup are mutually exclusive")
+	}
+	return nil
+}
+
+// runStandby maintains a standby data directory for the shard: it restores
+// the latest backup into the data directory of the standby tablet UID, or
+// resumes from the data directory restored by a previous run, and keeps
+// replicating from the primary of the shard until the context is done.
+//
+// mysqld is shut down cleanly on exit, and the data directory is kept, so a
+// tablet with the same UID can be started from it, already caught up, instead
+// of restoring a backup.
+func runStandby(ctx, backgroundCtx context.Context, topoServer *topo.Server) error {
+	tabletAlias := &topodatapb.TabletAlias{
+		Cell: "vtbackup",
+		Uid:  standbyTabletUID,
+	}
+	extraEnv := map[string]string{
+		"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
+	}
+
+	mysqld, mycnf, restored, err := openStandbyMysqld(ctx)
+	if err != nil {
+		return err
+	}
+	// Shut down mysqld when we're done, keeping the data directory.
+	defer func() {
+		mysqlShutdownCtx, mysqlShutdownCancel := context.WithTimeout(backgroundCtx, mysqlShutdownTimeout+10*time.Second)
+		defer mysqlShutdownCancel()
+		if err := mysqld.Shutdown(mysqlShutdownCtx, mycnf, true, mysqlShutdownTimeout); err != nil {
+			log.Errorf("failed to shutdown mysqld: %v", err)
+			return
+		}
+		log.Infof("Standby data directory %v is ready to be attached to tablet %v.", mycnf.DataDir, topoproto.TabletAliasString(tabletAlias))
+	}()
+
+	if !restored {
+		dbName := initDbNameOverride
+		if dbName == "" {
+			dbName = fmt.Sprintf("vt_%s", initKeyspace)
+		}
+
+		phase.Set(phaseNameRestoreLastBackup, int64(1))
+		log.Infof("Restoring latest backup from directory %v into standby data directory %v", mysqlctl.GetBackupDir(initKeyspace, initShard), mycnf.DataDir)
+		backupManifest, err := mysqlctl.Restore(ctx, mysqlctl.RestoreParams{
+			Cnf:                  mycnf,
+			Mysqld:               mysqld,
+			Logger:               logutil.NewConsoleLogger(),
+			Concurrency:          concurrency,
+			HookExtraEnv:         extraEnv,
+			DeleteBeforeRestore:  true,
+			DbName:               dbName,
+			Keyspace:             initKeyspace,
+			Shard:                initShard,
+			Stats:                backupstats.RestoreStats(),
+			MysqlShutdownTimeout: mysqlShutdownTimeout,
+		})
+		phase.Set(phaseNameRestoreLastBackup, int64(0))
+		if err != nil {
+			return fmt.Errorf("can't restore from backup: %v", err)
+		}
+		log.Infof("Successfully restored from backup at replication position %v", backupManifest.Position)
+
+		if err := resetReplication(ctx, backupManifest.Position, mysqld); err != nil {
+			return fmt.Errorf("error resetting replication: %v", err)
+		}
+	}
+
+	phase.Set(phaseNameStandbyReplication, int64(1))
+	defer phase.Set(phaseNameStandbyReplication, int64(0))
+	return keepStandbyReplicating(ctx, mysqld, topoServer)
+}
+
+// openStandbyMysqld starts mysqld on the data directory of the standby tablet
+// UID. restored is true if the data directory was completely restored by a
+// previous run, in which case it is resumed as is.
+func openStandbyMysqld(ctx context.Context) (mysqld *mysqlctl.Mysqld, mycnf *mysqlctl.Mycnf, restored bool, err error) {
+	initCtx, initCancel := context.WithTimeout(ctx, mysqlTimeout)
+	defer initCancel()
+
+	if _, statErr := os.Stat(mysqlctl.MycnfFile(standbyTabletUID)); statErr != nil {
+		log.Infof("Initializing standby data directory for tablet UID %v", standbyTabletUID)
+		mysqld, mycnf, err = mysqlctl.CreateMysqldAndMycnf(standbyTabletUID, mysqlSocket, mysqlPort, collationEnv)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to initialize mysql config: %v", err)
+		}
+		if err := mysqld.Init(initCtx, mycnf, initDBSQLFile); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
+		}
+		return mysqld, mycnf, false, nil
+	}
+
+	mysqld, mycnf, err = mysqlctl.OpenMysqldAndMycnf(standbyTabletUID, collationEnv)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to find mysql config: %v", err)
+	}
+	if mysqlctl.RestoreWasInterrupted(mycnf) {
+		// The restore engine starts mysqld once the backup is restored.
+		log.Infof("Found interrupted restore in standby data directory %v, restoring again", mycnf.DataDir)
+		return mysqld, mycnf, false, nil
+	}
+	log.Infof("Resuming standby data directory %v", mycnf.DataDir)
+	if err := mysqld.Start(initCtx, mycnf); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to start mysqld: %v", err)
+	}
+	return mysqld, mycnf, true, nil
+}
+
+// keepStandbyReplicating replicates from the primary of the shard until the
+// context is done. Replication is restarted when it stops, and pointed at the
+// new primary when the primary of the shard changes.
+func keepStandbyReplicating(ctx context.Context, mysqld mysqlctl.MysqlDaemon, topoServer *topo.Server) error {
+	var (
+		primaryAlias     *topodatapb.TabletAlias
+		lastPrimaryCheck time.Time
+
+		lastStatus replication.ReplicationStatus
+		status     replication.ReplicationStatus
+		statusErr  error
+	)
+	for {
+		if time.Since(lastPrimaryCheck) >= standbyPrimaryCheckInterval {
+			lastPrimaryCheck = time.Now()
+			si, err := topoServer.GetShard(ctx, initKeyspace, initShard)
+			switch {
+			case err != nil:
+				log.Warningf("Can't read shard %v/%v: %v", initKeyspace, initShard, err)
+			case !topoproto.TabletAliasEqual(si.PrimaryAlias, primaryAlias):
+				log.Infof("Standby replicating from primary %v", topoproto.TabletAliasString(si.PrimaryAlias))
+				if err := startReplication(ctx, mysqld, topoServer); err != nil {
+					log.Warningf("Failed to start replication: %v", err)
+				} else {
+					primaryAlias = si.PrimaryAlias
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Infof("Stopping standby replication at %v", status.Position)
+			return nil
+		case <-time.After(time.Second):
+		}
+
+		lastStatus = status
+		status, statusErr = mysqld.ReplicationStatus(ctx)
+		if statusErr != nil {
+			log.Warningf("Error getting replication status: %v", statusErr)
+			standbyLagSeconds.Set(-1)
+			continue
+		}
+		if status.ReplicationLagUnknown {
+			standbyLagSeconds.Set(-1)
+		} else {
+			standbyLagSeconds.Set(int64(status.ReplicationLagSeconds))
+		}
+		if !lastStatus.Position.IsZero() && status.Position.Equal(lastStatus.Position) && status.ReplicationLagSeconds > 0 {
+			phaseStatus.Set([]string{phaseNameStandbyReplication, phaseStatusCatchupReplicationStalled}, 1)
+		} else {
+			phaseStatus.Set([]string{phaseNameStandbyReplication, phaseStatusCatchupReplicationStalled}, 0)
+		}
+		if !status.Healthy() {
+			log.Warning("Standby replication has stopped. Trying to restart replication.")
+			phaseStatus.Set([]string{phaseNameStandbyReplication, phaseStatusCatchupReplicationStopped}, 1)
+			if err := startReplication(ctx, mysqld, topoServer); err != nil {
+				log.Warningf("Failed to restart replication: %v", err)
+			}
+		} else {
+			phaseStatus.Set([]string{phaseNameStandbyReplication, phaseStatusCatchupReplicationStopped}, 0)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestValidateStandbyFlags(t *testing.T) {
+	defer func(uid uint32, backup bool) {
+		standbyTabletUID, initialBackup = uid, backup
+	}(standbyTabletUID, initialBackup)
+
+	standbyTabletUID, initialBackup = 0, false
+	assert.EqualError(t, validateStandbyFlags(), "--standby-tablet-uid is required with --standby")
+
+	standbyTabletUID, initialBackup = 100, true
+	assert.EqualError(t, validateStandbyFlags(), "--standby and --initial_backup are mutually exclusive")
+
+	standbyTabletUID, initialBackup = 100, false
+	assert.NoError(t, validateStandbyFlags())
+}
+
+// newStandbyTestTopo returns a topo server with the shard of the standby,
+// with a primary if primaryAlias is set.
+func newStandbyTestTopo(t *testing.T, ctx context.Context, primaryAlias *topodatapb.TabletAlias) *topo.Server {
+	oldKeyspace, oldShard := initKeyspace, initShard
+	t.Cleanup(func() {
+		initKeyspace, initShard = oldKeyspace, oldShard
+	})
+	initKeyspace, initShard = "ks", "0"
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	t.Cleanup(ts.Close)
+	require.NoError(t, ts.CreateKeyspace(ctx, initKeyspace, &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, initKeyspace, initShard))
+	if primaryAlias == nil {
+		return ts
+	}
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+		Alias:         primaryAlias,
+		Keyspace:      initKeyspace,
+		Shard:         initShard,
+		Type:          topodatapb.TabletType_PRIMARY,
+		MysqlHostname: "primary-host",
+		MysqlPort:     3306,
+	}))
+	_, err := ts.UpdateShardFields(ctx, initKeyspace, initShard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primaryAlias
+		return nil
+	})
+	require.NoError(t, err)
+	return ts
+}
+
+func TestKeepStandbyReplicating(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := newStandbyTestTopo(t, ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 100})
+
+	mysqld := mysqlctl.NewFakeMysqlDaemon(nil)
+	mysqld.SetReplicationSourceInputs = []string{"primary-host:3306"}
+	mysqld.ExpectedExecuteSuperQueryList = []string{"STOP REPLICA", "FAKE SET SOURCE", "START REPLICA"}
+	mysqld.ReplicationLagSeconds = 5
+	standbyLagSeconds.Set(0)
+
+	done := make(chan error)
+	go func() {
+		done <- keepStandbyReplicating(ctx, mysqld, ts)
+	}()
+
+	// The standby replicates from the primary of the shard, and reports its lag.
+	require.Eventually(t, func() bool {
+		return standbyLagSeconds.Get() == 5
+	}, 10*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, "primary-host", mysqld.CurrentSourceHost)
+	assert.EqualValues(t, 3306, mysqld.CurrentSourcePort)
+	assert.True(t, mysqld.Replicating)
+}
+
+func TestKeepStandbyReplicatingErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The shard has no primary to replicate from.
+	ts := newStandbyTestTopo(t, ctx, nil)
+
+	mysqld := mysqlctl.NewFakeMysqlDaemon(nil)
+	mysqld.ReplicationStatusError = errors.New("replication status error")
+	standbyLagSeconds.Set(0)
+
+	done := make(chan error)
+	go func() {
+		done <- keepStandbyReplicating(ctx, mysqld, ts)
+	}()
+
+	// The standby keeps running, with an unknown lag, until it is stopped.
+	require.Eventually(t, func() bool {
+		return standbyLagSeconds.Get() == -1
+	}, 10*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, mysqld.CurrentSourceHost)
+}
@@ -62,6 +62,7 @@ const (
 	phaseNameCatchupReplication          = "CatchupReplication"
 	phaseNameInitialBackup               = "InitialBackup"
 	phaseNameRestoreLastBackup           = "RestoreLastBackup"
+	phaseNameStandbyReplication          = "StandbyReplication"
 	phaseNameTakeNewBackup               = "TakeNewBackup"
 	phaseStatusCatchupReplicationStalled = "Stalled"
 	phaseStatusCatchupReplicationStopped = "Stopped"
@@ -121,6 +122,7 @@ var (
 		phaseNameCatchupReplication,
 		phaseNameInitialBackup,
 		phaseNameRestoreLastBackup,
+		phaseNameStandbyReplication,
 		phaseNameTakeNewBackup,
 	}
 	phaseStatus = stats.NewGaugesWithMultiLabels(
@@ -133,6 +135,10 @@ var (
 			phaseStatusCatchupReplicationStalled,
 			phaseStatusCatchupReplicationStopped,
 		},
+		phaseNameStandbyReplication: {
+			phaseStatusCatchupReplicationStalled,
+			phaseStatusCatchupReplicationStopped,
+		},
 	}
 
 	Main = &cobra.Command{
@@ -176,7 +182,17 @@ to the handling of the query path.
 The command-line parameters to vtbackup specify a policy for when a new backup
 is needed, and when old backups should be removed. If the existing backups
 already satisfy the policy, then vtbackup will do nothing and return success
-immediately.`,
+immediately.
+
+With --standby, vtbackup instead maintains a warm standby data directory for
+the shard, for the tablet UID given by --standby-tablet-uid. It restores the
+most recent backup into the data directory of that tablet UID, or resumes from
+the data directory restored by a previous run, and keeps replicating from the
+current shard primary until it is stopped. No backup is taken, and no old
+backup is removed. On exit, mysqld is shut down cleanly and the data directory
+is kept, so replacing a failed tablet only takes starting a tablet with that
+UID on the standby's disk, instead of a full restore plus catch-up. A pool of
+standbys is a set of vtbackup instances with distinct tablet UIDs.`,
 		Version: servenv.AppVersion.String(),
 		Args:    cobra.NoArgs,
 		PreRunE: servenv.CobraPreRunE,
@@ -198,6 +214,8 @@ func init() {
 	Main.Flags().BoolVar(&allowFirstBackup, "allow_first_backup", allowFirstBackup, "Allow this job to take the first backup of an existing shard.")
 	Main.Flags().BoolVar(&restartBeforeBackup, "restart_before_backup", restartBeforeBackup, "Perform a mysqld clean/full restart after applying binlogs, but before taking the backup. Only makes sense to work around xtrabackup bugs.")
 	Main.Flags().BoolVar(&upgradeSafe, "upgrade-safe", upgradeSafe, "Whether to use innodb_fast_shutdown=0 for the backup so it is safe to use for MySQL upgrades.")
+	Main.Flags().BoolVar(&standby, "standby", standby, "Instead of taking a backup, maintain a standby data directory for --standby-tablet-uid, restored from the most recent backup and kept up to date by replicating from the shard primary until vtbackup is stopped.")
+	Main.Flags().Uint32Var(&standbyTabletUID, "standby-tablet-uid", standbyTabletUID, "UID of the tablet whose data directory is maintained in --standby mode. The data directory is kept when vtbackup exits, so a tablet with this UID can be started from it.")
 
 	// vttablet-like flags
 	Main.Flags().StringVar(&initDbNameOverride, "init_db_name_override", initDbNameOverride, "(init parameter) override the name of the db used by vttablet")
@@ -252,6 +270,11 @@ func run(cc *cobra.Command, args []string) error {
 		log.Errorf("min_retention_count must be at least 1 to allow restores to succeed")
 		exit.Return(1)
 	}
+	if standby {
+		if err := validateStandbyFlags(); err != nil {
+			return err
+		}
+	}
 
 	// Open connection backup storage.
 	backupStorage, err := backupstorage.GetBackupStorage()
@@ -273,6 +296,14 @@ func run(cc *cobra.Command, args []string) error {
 		}
 	}
 
+	if standby {
+		if err := runStandby(ctx, cc.Context(), topoServer); err != nil {
+			return fmt.Errorf("standby failed: %w", err)
+		}
+		log.Info("Exiting.")
+		return nil
+	}
+
 	// Try to take a backup, if it's been long enough since the last one.
 	// Skip pruning if backup wasn't fully successful. We don't want to be
 	// deleting things if the backup process is not healthy.
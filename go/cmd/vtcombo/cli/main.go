@@ -79,6 +79,7 @@ In particular, it contains:
 	externalTopoServer    bool
 	plannerName           string
 	vschemaPersistenceDir string
+	persistenceDir        string
 
 	tpb               vttestpb.VTTestTopology
 	ts                *topo.Server
@@ -116,6 +117,11 @@ func init() {
 		"vschema even if developer's machine reboots. This works in tandem with vttestserver's --persistent_mode flag. Needless to say, "+
 		"this is neither a perfect nor a production solution for vschema persistence. Consider using the --external_topo_server flag if "+
 		"you require a more complete solution. This flag is ignored if --external_topo_server is set.")
+	Main.Flags().StringVar(&persistenceDir, "persistence-dir", persistenceDir, "If set, vtcombo persists its topology, including the keyspaces created and dropped with CREATE DATABASE and DROP DATABASE, "+
+		"its vschemas and its routing rules in this directory, and re-adopts them on startup instead of the topology given with --proto_topo or --json_topo. "+
+		"The MySQL data directory of --start_mysql is re-adopted on startup as well. Snapshots of this state, along with the MySQL data with --start_mysql, "+
+		"are taken with a POST to /debug/vtcombo/snapshot?name=<name>, listed with a GET to /debug/vtcombo/snapshot and restored with a POST to "+
+		"/debug/vtcombo/restore?name=<name>. This flag is ignored if --external_topo_server is set.")
 
 	Main.Flags().Var(vttest.TextTopoData(&tpb), "proto_topo", "vttest proto definition of the topology, encoded in compact text format. See vttest.proto for more information.")
 	Main.Flags().Var(vttest.JSONTopoData(&tpb), "json_topo", "vttest proto definition of the topology, encoded in json format. See vttest.proto for more information.")
@@ -176,6 +182,13 @@ func run(cmd *cobra.Command, args []string) (err error) {
 	// get recreated.
 	originalTopology := (&tpb).CloneVT()
 
+	// Re-adopt the topology of a previous run, if any.
+	if persistenceDir != "" && !externalTopoServer {
+		if err := loadPersistedTopology(persistenceDir, &tpb); err != nil {
+			return err
+		}
+	}
+
 	// default cell to "test" if unspecified
 	if len(tpb.Cells) == 0 {
 		tpb.Cells = append(tpb.Cells, "test")
@@ -305,6 +318,7 @@ func run(cmd *cobra.Command, args []string) (err error) {
 	// vtgate configuration and init
 
 	resilientServer = srvtopo.NewResilientServer(ctx, ts, srvTopoCounts)
+	stats.NewGaugesFuncWithMultiLabels("ResilientSrvTopoServerStalenessSeconds", "How long the oldest cached srvtopo value served without a topology watch was last known to be current", []string{"Type"}, resilientServer.Staleness)
 
 	tabletTypes := make([]topodatapb.TabletType, 0, 1)
 	if len(tabletTypesToWait) != 0 {
@@ -336,6 +350,20 @@ func run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	if persistenceDir != "" && !externalTopoServer {
+		state := &persistence{
+			dir:      persistenceDir,
+			ts:       ts,
+			mysqld:   mysqld,
+			cnf:      cnf,
+			createDb: globalCreateDb,
+			dropDb:   globalDropDb,
+		}
+		if err := state.open(ctx); err != nil {
+			return err
+		}
+	}
+
 	if vschemaPersistenceDir != "" && !externalTopoServer {
 		startVschemaWatcher(ctx, vschemaPersistenceDir, ts)
 	}
@@ -0,0 +1,501 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+const (
+	persistedTopologyFile   = "topology.json"
+	persistedSrvVSchemaFile = "srv_vschema.json"
+	snapshotsDir            = "snapshots"
+	snapshotMysqlDir        = "mysql"
+)
+
+var snapshotNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// persistence keeps the state of vtcombo in a directory, so that it is
+// re-adopted when vtcombo restarts: the topology, including the keyspaces
+// created and dropped with CREATE DATABASE and DROP DATABASE, and the SrvVSchema,
+// which holds the vschemas and the routing rules. The MySQL data is kept by
+// mysqld in the tablet directory, which is re-adopted on restart with
+// --start_mysql.
+//
+// It also takes snapshots of this state, along with the MySQL data when
+// vtcombo started mysqld, and restores them.
+type persistence struct {
+	dir    string
+	ts     *topo.Server
+	mysqld *vtcomboMysqld
+	// cnf is nil unless vtcombo started mysqld.
+	cnf *mysqlctl.Mycnf
+
+	// createDb and dropDb create and drop keyspaces without persisting the
+	// topology.
+	createDb func(ctx context.Context, ks *vttestpb.Keyspace) error
+	dropDb   func(ctx context.Context, ksName string) error
+
+	// mu serializes the changes of the topology, the snapshots and the restores.
+	mu sync.Mutex
+}
+
+// loadPersistedTopology replaces the topology with the one persisted in dir, if any.
+func loadPersistedTopology(dir string, topology *vttestpb.VTTestTopology) error {
+	persisted, err := readTopology(dir)
+	if err != nil || persisted == nil {
+		return err
+	}
+	log.Infof("Loaded the topology persisted in %v, ignoring the one given with --proto_topo or --json_topo", dir)
+	proto.Reset(topology)
+	proto.Merge(topology, persisted)
+	return nil
+}
+
+// open persists the current state of vtcombo, restores the vschemas and the
+// routing rules persisted by a previous run, if any, and starts persisting
+// their changes.
+func (p *persistence) open(ctx context.Context) error {
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return fmt.Errorf("unable to create persistence directory %v: %w", p.dir, err)
+	}
+	srvVSchema, err := readSrvVSchema(p.dir)
+	if err != nil {
+		return err
+	}
+	if srvVSchema != nil {
+		if err := p.restoreSrvVSchema(ctx, srvVSchema); err != nil {
+			return fmt.Errorf("unable to restore the vschemas persisted in %v: %w", p.dir, err)
+		}
+		log.Infof("Restored the vschemas and routing rules persisted in %v", p.dir)
+	}
+	if err := p.persistTopology(); err != nil {
+		return err
+	}
+
+	globalCreateDb = func(ctx context.Context, ks *vttestpb.Keyspace) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err := p.createDb(ctx, ks); err != nil {
+			return err
+		}
+		return p.persistTopology()
+	}
+	globalDropDb = func(ctx context.Context, ksName string) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err := p.dropDb(ctx, ksName); err != nil {
+			return err
+		}
+		return p.persistTopology()
+	}
+
+	go p.watchSrvVSchema(ctx)
+
+	servenv.HTTPHandleFunc("/debug/vtcombo/snapshot", p.handleSnapshot)
+	servenv.HTTPHandleFunc("/debug/vtcombo/restore", p.handleRestore)
+	return nil
+}
+
+func (p *persistence) persistTopology() error {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(&tpb)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(p.dir, persistedTopologyFile), data)
+}
+
+func (p *persistence) watchSrvVSchema(ctx context.Context) {
+	data, ch, err := p.ts.WatchSrvVSchema(ctx, tpb.Cells[0])
+	if err != nil {
+		log.Errorf("WatchSrvVSchema failed, vschemas will not be persisted: %v", err)
+		return
+	}
+	if data.Err == nil {
+		p.persistSrvVSchema(data.Value)
+	}
+	for update := range ch {
+		if update.Err != nil {
+			log.Errorf("WatchSrvVSchema returned an error: %v", update.Err)
+			continue
+		}
+		p.persistSrvVSchema(update.Value)
+	}
+}
+
+func (p *persistence) persistSrvVSchema(srvVSchema *vschemapb.SrvVSchema) {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(srvVSchema)
+	if err != nil {
+		log.Errorf("Error marshaling SrvVSchema: %v", err)
+		return
+	}
+	if err := writeFileAtomic(filepath.Join(p.dir, persistedSrvVSchemaFile), data); err != nil {
+		log.Errorf("Error persisting SrvVSchema: %v", err)
+	}
+}
+
+// restoreSrvVSchema saves the vschemas of the keyspaces of the topology and
+// the routing rules of the SrvVSchema, and rebuilds the SrvVSchema.
+func (p *persistence) restoreSrvVSchema(ctx context.Context, srvVSchema *vschemapb.SrvVSchema) error {
+	for _, ks := range tpb.Keyspaces {
+		if vschema, ok := srvVSchema.Keyspaces[ks.Name]; ok {
+			if err := p.ts.SaveVSchema(ctx, ks.Name, vschema); err != nil {
+				return err
+			}
+		}
+	}
+	if err := p.ts.SaveRoutingRules(ctx, srvVSchema.GetRoutingRules()); err != nil {
+		return err
+	}
+	if err := p.ts.SaveShardRoutingRules(ctx, srvVSchema.GetShardRoutingRules()); err != nil {
+		return err
+	}
+	if srvVSchema.KeyspaceRoutingRules != nil {
+		if err := p.ts.SaveKeyspaceRoutingRules(ctx, srvVSchema.KeyspaceRoutingRules); err != nil {
+			return err
+		}
+	}
+	return p.ts.RebuildSrvVSchema(ctx, tpb.Cells)
+}
+
+func (p *persistence) snapshotDir(name string) (string, error) {
+	if !snapshotNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return filepath.Join(p.dir, snapshotsDir, name), nil
+}
+
+// snapshots returns the names of the snapshots.
+func (p *persistence) snapshots() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(p.dir, snapshotsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// snapshot saves the topology, the SrvVSchema and, if vtcombo started mysqld,
+// the MySQL data in a new snapshot. mysqld is stopped while its data is copied.
+func (p *persistence) snapshot(ctx context.Context, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dir, err := p.snapshotDir(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("snapshot %s already exists", name)
+	}
+	srvVSchema, err := p.ts.GetSrvVSchema(ctx, tpb.Cells[0])
+	if err != nil {
+		return err
+	}
+	topology, err := protojson.Marshal(&tpb)
+	if err != nil {
+		return err
+	}
+	vschema, err := protojson.Marshal(srvVSchema)
+	if err != nil {
+		return err
+	}
+
+	// Build the snapshot in a temporary directory, so that a failed snapshot
+	// does not leave a partial one behind.
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, persistedTopologyFile), topology, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, persistedSrvVSchemaFile), vschema, 0644); err != nil {
+		return err
+	}
+	if p.cnf != nil {
+		err := p.withMysqldStopped(ctx, func() error {
+			tabletDir := mysqlctl.TabletDir(1)
+			for _, d := range mysqlctl.TopLevelDirs() {
+				if err := copyDir(filepath.Join(tabletDir, d), filepath.Join(tmpDir, snapshotMysqlDir, d)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to copy the MySQL data: %w", err)
+		}
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+// restore restores a snapshot: the keyspaces which are not in the snapshot are
+// dropped, the keyspaces of the snapshot which do not exist anymore are created,
+// then the MySQL data, if the snapshot has it, and the vschemas and routing
+// rules are restored.
+func (p *persistence) restore(ctx context.Context, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dir, err := p.snapshotDir(name)
+	if err != nil {
+		return err
+	}
+	topology, err := readTopology(dir)
+	if err != nil {
+		return err
+	}
+	if topology == nil {
+		return fmt.Errorf("snapshot %s not found", name)
+	}
+	srvVSchema, err := readSrvVSchema(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, ks := range slices.Clone(tpb.Keyspaces) {
+		if !hasKeyspace(topology, ks.Name) {
+			if err := p.dropDb(ctx, ks.Name); err != nil {
+				return fmt.Errorf("unable to drop keyspace %s: %w", ks.Name, err)
+			}
+		}
+	}
+	for _, ks := range topology.Keyspaces {
+		if !hasKeyspace(&tpb, ks.Name) {
+			if err := p.createDb(ctx, ks.CloneVT()); err != nil {
+				return fmt.Errorf("unable to create keyspace %s: %w", ks.Name, err)
+			}
+		}
+	}
+	if err := p.persistTopology(); err != nil {
+		return err
+	}
+
+	mysqlDir := filepath.Join(dir, snapshotMysqlDir)
+	if _, err := os.Stat(mysqlDir); err == nil && p.cnf != nil {
+		err := p.withMysqldStopped(ctx, func() error {
+			tabletDir := mysqlctl.TabletDir(1)
+			for _, d := range mysqlctl.TopLevelDirs() {
+				if err := os.RemoveAll(filepath.Join(tabletDir, d)); err != nil {
+					return err
+				}
+				if err := copyDir(filepath.Join(mysqlDir, d), filepath.Join(tabletDir, d)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to restore the MySQL data: %w", err)
+		}
+	}
+
+	if srvVSchema != nil {
+		return p.restoreSrvVSchema(ctx, srvVSchema)
+	}
+	return nil
+}
+
+// withMysqldStopped stops mysqld, runs f, and starts mysqld again.
+func (p *persistence) withMysqldStopped(ctx context.Context, f func() error) error {
+	if err := p.mysqld.Shutdown(ctx, p.cnf, true, mysqlctl.DefaultShutdownTimeout); err != nil {
+		return err
+	}
+	ferr := f()
+	if err := p.mysqld.Start(ctx, p.cnf); err != nil {
+		return err
+	}
+	if err := p.mysqld.SetReadOnly(ctx, false); err != nil {
+		return err
+	}
+	return ferr
+}
+
+func (p *persistence) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		names, err := p.snapshots()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(names)
+	case http.MethodPost:
+		name := r.FormValue("name")
+		if err := p.snapshot(r.Context(), name); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "snapshot %s created\n", name)
+	default:
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *persistence) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.FormValue("name")
+	if err := p.restore(r.Context(), name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "snapshot %s restored\n", name)
+}
+
+func hasKeyspace(topology *vttestpb.VTTestTopology, name string) bool {
+	return slices.ContainsFunc(topology.Keyspaces, func(ks *vttestpb.Keyspace) bool {
+		return ks.Name == name
+	})
+}
+
+func readTopology(dir string) (*vttestpb.VTTestTopology, error) {
+	data, err := os.ReadFile(filepath.Join(dir, persistedTopologyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	topology := &vttestpb.VTTestTopology{}
+	if err := protojson.Unmarshal(data, topology); err != nil {
+		return nil, fmt.Errorf("unable to parse the topology persisted in %v: %w", dir, err)
+	}
+	return topology, nil
+}
+
+func readSrvVSchema(dir string) (*vschemapb.SrvVSchema, error) {
+	data, err := os.ReadFile(filepath.Join(dir, persistedSrvVSchemaFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	srvVSchema := &vschemapb.SrvVSchema{}
+	if err := protojson.Unmarshal(data, srvVSchema); err != nil {
+		return nil, fmt.Errorf("unable to parse the SrvVSchema persisted in %v: %w", dir, err)
+	}
+	return srvVSchema, nil
+}
+
+// writeFileAtomic writes the file through a temporary file, so that it is never
+// left half written.
+func writeFileAtomic(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// copyDir copies the directory src to dst, which must not exist. It does
+// nothing if src does not exist.
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+// newTestPersistence returns a persistence of a vtcombo without mysqld, with
+// the keyspaces of the topology created in a memory topo server.
+func newTestPersistence(t *testing.T, ctx context.Context, keyspaces ...string) *persistence {
+	oldTopology := tpb.CloneVT()
+	t.Cleanup(func() {
+		proto.Reset(&tpb)
+		proto.Merge(&tpb, oldTopology)
+	})
+	proto.Reset(&tpb)
+	tpb.Cells = []string{"cell1"}
+
+	ts := memorytopo.NewServer(ctx, tpb.Cells...)
+	t.Cleanup(ts.Close)
+	p := &persistence{
+		dir: t.TempDir(),
+		ts:  ts,
+		createDb: func(ctx context.Context, ks *vttestpb.Keyspace) error {
+			if err := ts.CreateKeyspace(ctx, ks.Name, &topodatapb.Keyspace{}); err != nil {
+				return err
+			}
+			tpb.Keyspaces = append(tpb.Keyspaces, ks)
+			return nil
+		},
+		dropDb: func(ctx context.Context, ksName string) error {
+			if err := ts.DeleteKeyspace(ctx, ksName); err != nil {
+				return err
+			}
+			tpb.Keyspaces = slices.DeleteFunc(tpb.Keyspaces, func(ks *vttestpb.Keyspace) bool {
+				return ks.Name == ksName
+			})
+			return nil
+		},
+	}
+	for _, ks := range keyspaces {
+		require.NoError(t, p.createDb(ctx, &vttestpb.Keyspace{Name: ks}))
+	}
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, tpb.Cells))
+	return p
+}
+
+func TestLoadPersistedTopology(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestPersistence(t, ctx, "ks1", "ks2")
+	require.NoError(t, p.persistTopology())
+	persisted := tpb.CloneVT()
+
+	// The topology given on the command line is replaced by the persisted one.
+	topology := &vttestpb.VTTestTopology{Cells: []string{"other"}}
+	require.NoError(t, loadPersistedTopology(p.dir, topology))
+	utils.MustMatch(t, persisted, topology)
+
+	// Nothing is loaded from an empty directory.
+	topology = &vttestpb.VTTestTopology{Cells: []string{"other"}}
+	require.NoError(t, loadPersistedTopology(t.TempDir(), topology))
+	assert.Equal(t, []string{"other"}, topology.Cells)
+
+	// A corrupted topology is an error.
+	require.NoError(t, os.WriteFile(filepath.Join(p.dir, persistedTopologyFile), []byte("{"), 0644))
+	err := loadPersistedTopology(p.dir, topology)
+	require.ErrorContains(t, err, "unable to parse the topology persisted in "+p.dir)
+}
+
+func TestPersistSrvVSchema(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestPersistence(t, ctx, "ks1")
+
+	srvVSchema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks1": {Tables: map[string]*vschemapb.Table{"t1": {}}},
+		},
+		RoutingRules: &vschemapb.RoutingRules{
+			Rules: []*vschemapb.RoutingRule{{FromTable: "t2", ToTables: []string{"ks1.t1"}}},
+		},
+	}
+	p.persistSrvVSchema(srvVSchema)
+	persisted, err := readSrvVSchema(p.dir)
+	require.NoError(t, err)
+	utils.MustMatch(t, srvVSchema, persisted)
+
+	// The vschemas and routing rules persisted are restored in the topo server.
+	require.NoError(t, p.restoreSrvVSchema(ctx, persisted))
+	vschema, err := p.ts.GetVSchema(ctx, "ks1")
+	require.NoError(t, err)
+	utils.MustMatch(t, srvVSchema.Keyspaces["ks1"], vschema)
+	rules, err := p.ts.GetRoutingRules(ctx)
+	require.NoError(t, err)
+	utils.MustMatch(t, srvVSchema.RoutingRules, rules)
+
+	require.NoError(t, os.WriteFile(filepath.Join(p.dir, persistedSrvVSchemaFile), []byte("{"), 0644))
+	_, err = readSrvVSchema(p.dir)
+	require.ErrorContains(t, err, "unable to parse the SrvVSchema persisted in "+p.dir)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestPersistence(t, ctx, "ks1", "ks2")
+	ks1VSchema := &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}}}
+	require.NoError(t, p.ts.SaveVSchema(ctx, "ks1", ks1VSchema))
+	require.NoError(t, p.ts.RebuildSrvVSchema(ctx, tpb.Cells))
+
+	require.NoError(t, p.snapshot(ctx, "snap1"))
+	names, err := p.snapshots()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap1"}, names)
+
+	// Change the keyspaces and the vschemas after the snapshot.
+	require.NoError(t, p.dropDb(ctx, "ks2"))
+	require.NoError(t, p.createDb(ctx, &vttestpb.Keyspace{Name: "ks3"}))
+	require.NoError(t, p.ts.SaveVSchema(ctx, "ks1", &vschemapb.Keyspace{}))
+	require.NoError(t, p.ts.RebuildSrvVSchema(ctx, tpb.Cells))
+
+	require.NoError(t, p.restore(ctx, "snap1"))
+	var keyspaces []string
+	for _, ks := range tpb.Keyspaces {
+		keyspaces = append(keyspaces, ks.Name)
+	}
+	assert.ElementsMatch(t, []string{"ks1", "ks2"}, keyspaces)
+	vschema, err := p.ts.GetVSchema(ctx, "ks1")
+	require.NoError(t, err)
+	utils.MustMatch(t, ks1VSchema, vschema)
+
+	// The restored topology is persisted.
+	persisted, err := readTopology(p.dir)
+	require.NoError(t, err)
+	utils.MustMatch(t, &tpb, persisted)
+}
+
+func TestSnapshotRestoreErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestPersistence(t, ctx, "ks1")
+
+	names, err := p.snapshots()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	for _, name := range []string{"", "../snap", "snap/1", ".hidden"} {
+		require.ErrorContains(t, p.snapshot(ctx, name), "invalid snapshot name")
+		require.ErrorContains(t, p.restore(ctx, name), "invalid snapshot name")
+	}
+
+	require.NoError(t, p.snapshot(ctx, "snap1"))
+	require.ErrorContains(t, p.snapshot(ctx, "snap1"), "snapshot snap1 already exists")
+	require.ErrorContains(t, p.restore(ctx, "snap2"), "snapshot snap2 not found")
+
+	// A failed snapshot does not leave a partial one behind.
+	require.NoError(t, p.ts.DeleteSrvVSchema(ctx, tpb.Cells[0]))
+	require.Error(t, p.snapshot(ctx, "snap2"))
+	names, err = p.snapshots()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap1"}, names)
+
+	// A corrupted snapshot is not restored.
+	require.NoError(t, os.WriteFile(filepath.Join(p.dir, snapshotsDir, "snap1", persistedSrvVSchemaFile), []byte("{"), 0644))
+	require.ErrorContains(t, p.restore(ctx, "snap1"), "unable to parse the SrvVSchema persisted in")
+}
+
+func TestCopyDir(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "a", "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a", "b", "f1"), []byte("data1"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "f2"), []byte("data2"), 0644))
+	require.NoError(t, os.Symlink("f2", filepath.Join(src, "link")))
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	require.NoError(t, copyDir(src, dst))
+	data, err := os.ReadFile(filepath.Join(dst, "a", "b", "f1"))
+	require.NoError(t, err)
+	assert.Equal(t, "data1", string(data))
+	info, err := os.Stat(filepath.Join(dst, "a", "b", "f1"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "f2", link)
+
+	// The files are never overwritten.
+	require.ErrorIs(t, copyDir(src, dst), os.ErrExist)
+
+	// Nothing is copied from a directory which does not exist.
+	missing := filepath.Join(t.TempDir(), "missing")
+	require.NoError(t, copyDir(filepath.Join(src, "missing"), missing))
+	_, err = os.Stat(missing)
+	require.True(t, os.IsNotExist(err))
+}
@@ -81,6 +81,12 @@ func run(cmd *cobra.Command, args []string) error {
 	// Start schema manager service.
 	initSchema(cmd.Context())
 
+	// Start schema drift check.
+	initSchemaDrift(cmd.Context())
+
+	// Start the SrvVSchema reconcile.
+	initSrvVSchemaReconcile(cmd.Context())
+
 	// And run the server.
 	servenv.RunDefault()
 
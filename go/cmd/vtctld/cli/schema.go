@@ -26,6 +26,7 @@ import (
 	"vitess.io/vitess/go/vt/schemamanager"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctld"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/wrangler"
 )
@@ -36,6 +37,9 @@ var (
 	schemaChangeUser            string
 	schemaChangeCheckInterval   = time.Minute
 	schemaChangeReplicasTimeout = grpcvtctldserver.DefaultWaitReplicasTimeout
+	schemaDriftCheckInterval    time.Duration
+	schemaDriftCheckTimeout     = 5 * time.Minute
+	srvVSchemaReconcileInterval = time.Minute
 )
 
 func init() {
@@ -45,6 +49,11 @@ func init() {
 
 	Main.Flags().DurationVar(&schemaChangeCheckInterval, "schema_change_check_interval", schemaChangeCheckInterval, "How often the schema change dir is checked for schema changes. This value must be positive; if zero or lower, the default of 1m is used.")
 	Main.Flags().DurationVar(&schemaChangeReplicasTimeout, "schema_change_replicas_timeout", schemaChangeReplicasTimeout, "How long to wait for replicas to receive a schema change.")
+
+	Main.Flags().DurationVar(&schemaDriftCheckInterval, "schema_drift_check_interval", schemaDriftCheckInterval, "How often the schema of all the tablets of each keyspace is compared to detect schema drift between shards and between primaries and replicas. Zero disables the check.")
+	Main.Flags().DurationVar(&schemaDriftCheckTimeout, "schema_drift_check_timeout", schemaDriftCheckTimeout, "How long a schema drift check of all the keyspaces may take.")
+
+	Main.Flags().DurationVar(&srvVSchemaReconcileInterval, "srv_vschema_reconcile_interval", srvVSchemaReconcileInterval, "How often the SrvVSchema of each cell is compared to the global VSchema with the cell's staged changes, and rebuilt if it diverged. Zero disables the reconcile.")
 }
 
 func initSchema(ctx context.Context) {
@@ -74,7 +83,7 @@ func initSchema(ctx context.Context) {
 			_, err = schemamanager.Run(
 				ctx,
 				controller,
-				schemamanager.NewTabletExecutor("vtctld/schema", wr.TopoServer(), wr.TabletManagerClient(), wr.Logger(), schemaChangeReplicasTimeout, 0, env.Parser()),
+				schemamanager.NewTabletExecutor("vtctld/schema", wr.TopoServer(), wr.TabletManagerClient(), wr.Logger(), schemaChangeReplicasTimeout, 0, env),
 			)
 			if err != nil {
 				log.Errorf("Schema change failed, error: %v", err)
@@ -83,3 +92,34 @@ func initSchema(ctx context.Context) {
 		servenv.OnClose(func() { timer.Stop() })
 	}
 }
+
+func initSchemaDrift(ctx context.Context) {
+	// Start the schema drift check if needed.
+	if schemaDriftCheckInterval > 0 {
+		timer := timer.NewTimer(schemaDriftCheckInterval)
+		server := grpcvtctldserver.NewVtctldServer(env, ts)
+
+		timer.Start(func() {
+			ctx, cancel := context.WithTimeout(ctx, schemaDriftCheckTimeout)
+			defer cancel()
+
+			vtctld.CheckSchemaDrift(ctx, ts, server)
+		})
+		servenv.OnClose(func() { timer.Stop() })
+	}
+}
+
+func initSrvVSchemaReconcile(ctx context.Context) {
+	// Start the SrvVSchema reconcile if needed.
+	if srvVSchemaReconcileInterval > 0 {
+		timer := timer.NewTimer(srvVSchemaReconcileInterval)
+
+		timer.Start(func() {
+			ctx, cancel := context.WithTimeout(ctx, srvVSchemaReconcileInterval)
+			defer cancel()
+
+			vtctld.ReconcileSrvVSchemas(ctx, ts)
+		})
+		servenv.OnClose(func() { timer.Stop() })
+	}
+}
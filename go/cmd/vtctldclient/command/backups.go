@@ -52,6 +52,20 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandBackupShard,
 	}
+	// BackupVerify makes a BackupVerify gRPC call to a vtctld.
+	BackupVerify = &cobra.Command{
+		Use:   "BackupVerify [--scratch-directory <dir>] <keyspace/shard> <backup name>",
+		Short: "Verifies the integrity of the given backup from the BackupStorage used by vtctld.",
+		Long: `Verifies the integrity of the given backup from the BackupStorage used by vtctld, against the checksums recorded in its MANIFEST. Serving tablets are not involved.
+
+If --scratch-directory is specified, the backup is also restored in a temporary directory created in it, which is removed afterwards. Only builtin backups can be restore-tested.
+The restore runs on the vtctld host serving the request, not on a tablet: the directory is a path of the vtctld host, which must be writable by vtctld and have room for the whole restored backup, and the restore uses the disk and CPU of that host.
+
+The result of the verification is recorded in the MANIFEST of the backup, if the BackupStorage supports it.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandBackupVerify,
+	}
 	// GetBackups makes a GetBackups gRPC call to a vtctld.
 	GetBackups = &cobra.Command{
 		Use:                   "GetBackups [--limit <limit>] [--json] <keyspace/shard>",
@@ -157,6 +171,44 @@ func commandBackupShard(cmd *cobra.Command, args []string) error {
 	}
 }
 
+var backupVerifyOptions = struct {
+	ScratchDirectory string
+}{}
+
+func commandBackupVerify(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	name := cmd.Flags().Arg(1)
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.BackupVerify(commandCtx, &vtctldatapb.BackupVerifyRequest{
+		Keyspace:         keyspace,
+		Shard:            shard,
+		Name:             name,
+		ScratchDirectory: backupVerifyOptions.ScratchDirectory,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	if !resp.Valid {
+		return fmt.Errorf("backup %s of %s/%s is not valid", name, keyspace, shard)
+	}
+
+	return nil
+}
+
 var getBackupsOptions = struct {
 	Limit      uint32
 	OutputJSON bool
@@ -292,6 +344,9 @@ func init() {
 	BackupShard.Flags().BoolVar(&backupOptions.UpgradeSafe, "upgrade-safe", false, "Whether to use innodb_fast_shutdown=0 for the backup so it is safe to use for MySQL upgrades.")
 	Root.AddCommand(BackupShard)
 
+	BackupVerify.Flags().StringVar(&backupVerifyOptions.ScratchDirectory, "scratch-directory", "", "Directory of the vtctld host, not of a tablet, in which vtctld restores the backup to test it. The backup is not restore-tested if empty.")
+	Root.AddCommand(BackupVerify)
+
 	GetBackups.Flags().Uint32VarP(&getBackupsOptions.Limit, "limit", "l", 0, "Retrieve only the most recent N backups.")
 	GetBackups.Flags().BoolVarP(&getBackupsOptions.OutputJSON, "json", "j", false, "Output backup info in JSON format rather than a list of backups.")
 	Root.AddCommand(GetBackups)
@@ -31,13 +31,18 @@ import (
 var (
 	// AddCellInfo makes an AddCellInfo gRPC call to a vtctld.
 	AddCellInfo = &cobra.Command{
-		Use:   "AddCellInfo --root <root> [--server-address <addr>] <cell>",
+		Use:   "AddCellInfo --root <root> [--server-address <addr>] [--region <region>] [--zone <zone>] [--tablet-tags <key=value,...>] <cell>",
 		Short: "Registers a local topology service in a new cell by creating the CellInfo.",
 		Long: `Registers a local topology service in a new cell by creating the CellInfo
 with the provided parameters.
 
 The address will be used to connect to the topology service, and Vitess data will
-be stored starting at the provided root.`,
+be stored starting at the provided root.
+
+The optional region and zone describe where the cell is located, and are used by
+vtgate to prefer tablets in the same region when none are available in its own
+cell. Tablet tags are added to every tablet that registers in the cell, unless
+the tablet sets the same tag itself.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandAddCellInfo,
@@ -99,11 +104,12 @@ the same group (alias).`,
 	}
 	// UpdateCellInfo makes an UpdateCellInfo gRPC call to a vtctld.
 	UpdateCellInfo = &cobra.Command{
-		Use:   "UpdateCellInfo [--root <root>] [--server-address <addr>] <cell>",
+		Use:   "UpdateCellInfo [--root <root>] [--server-address <addr>] [--region <region>] [--zone <zone>] [--tablet-tags <key=value,...>] [--clear-region] [--clear-zone] [--clear-tablet-tags] <cell>",
 		Short: "Updates the content of a CellInfo with the provided parameters, creating the CellInfo if it does not exist.",
 		Long: `Updates the content of a CellInfo with the provided parameters, creating the CellInfo if it does not exist.
 
-If a value is empty, it is ignored.`,
+If a value is empty, it is ignored. The region, zone and default tablet tags
+are cleared with --clear-region, --clear-zone and --clear-tablet-tags.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandUpdateCellInfo,
@@ -241,15 +247,23 @@ func commandGetCellsAliases(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-var updateCellInfoOptions topodatapb.CellInfo
+var updateCellInfoOptions = struct {
+	topodatapb.CellInfo
+	ClearRegion     bool
+	ClearZone       bool
+	ClearTabletTags bool
+}{}
 
 func commandUpdateCellInfo(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	cell := cmd.Flags().Arg(0)
 	resp, err := client.UpdateCellInfo(commandCtx, &vtctldatapb.UpdateCellInfoRequest{
-		Name:     cell,
-		CellInfo: &updateCellInfoOptions,
+		Name:            cell,
+		CellInfo:        &updateCellInfoOptions.CellInfo,
+		ClearRegion:     updateCellInfoOptions.ClearRegion,
+		ClearZone:       updateCellInfoOptions.ClearZone,
+		ClearTabletTags: updateCellInfoOptions.ClearTabletTags,
 	})
 	if err != nil {
 		return err
@@ -290,6 +304,9 @@ func commandUpdateCellsAlias(cmd *cobra.Command, args []string) error {
 func init() {
 	AddCellInfo.Flags().StringVarP(&addCellInfoOptions.ServerAddress, "server-address", "a", "", "The address the topology server will connect to for this cell.")
 	AddCellInfo.Flags().StringVarP(&addCellInfoOptions.Root, "root", "r", "", "The root path the topology server will use for this cell.")
+	AddCellInfo.Flags().StringVar(&addCellInfoOptions.Region, "region", "", "The region this cell belongs to.")
+	AddCellInfo.Flags().StringVar(&addCellInfoOptions.Zone, "zone", "", "The availability zone of this cell within its region.")
+	AddCellInfo.Flags().StringToStringVar(&addCellInfoOptions.TabletTags, "tablet-tags", nil, "Default tags added to every tablet registering in this cell.")
 	AddCellInfo.MarkFlagRequired("root")
 	Root.AddCommand(AddCellInfo)
 
@@ -306,6 +323,12 @@ func init() {
 
 	UpdateCellInfo.Flags().StringVarP(&updateCellInfoOptions.ServerAddress, "server-address", "a", "", "The address the topology server will connect to for this cell.")
 	UpdateCellInfo.Flags().StringVarP(&updateCellInfoOptions.Root, "root", "r", "", "The root path the topology server will use for this cell.")
+	UpdateCellInfo.Flags().StringVar(&updateCellInfoOptions.Region, "region", "", "The region this cell belongs to.")
+	UpdateCellInfo.Flags().StringVar(&updateCellInfoOptions.Zone, "zone", "", "The availability zone of this cell within its region.")
+	UpdateCellInfo.Flags().StringToStringVar(&updateCellInfoOptions.TabletTags, "tablet-tags", nil, "Default tags added to every tablet registering in this cell. Replaces any existing default tags.")
+	UpdateCellInfo.Flags().BoolVar(&updateCellInfoOptions.ClearRegion, "clear-region", false, "Clear the region of this cell.")
+	UpdateCellInfo.Flags().BoolVar(&updateCellInfoOptions.ClearZone, "clear-zone", false, "Clear the availability zone of this cell.")
+	UpdateCellInfo.Flags().BoolVar(&updateCellInfoOptions.ClearTabletTags, "clear-tablet-tags", false, "Clear the default tablet tags of this cell.")
 	Root.AddCommand(UpdateCellInfo)
 
 	UpdateCellsAlias.Flags().StringSliceVarP(&updateCellsAliasOptions.Cells, "cells", "c", nil, "The list of cell names that are members of this alias.")
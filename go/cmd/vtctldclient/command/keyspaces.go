@@ -38,12 +38,15 @@ import (
 var (
 	// CreateKeyspace makes a CreateKeyspace gRPC call to a vtctld.
 	CreateKeyspace = &cobra.Command{
-		Use:   "CreateKeyspace <keyspace> [--force|-f] [--type KEYSPACE_TYPE] [--base-keyspace KEYSPACE --snapshot-timestamp TIME] [--served-from DB_TYPE:KEYSPACE ...] [--durability-policy <policy_name>] [--sidecar-db-name <db_name>]",
+		Use:   "CreateKeyspace <keyspace> [--force|-f] [--type KEYSPACE_TYPE] [--base-keyspace KEYSPACE --snapshot-timestamp TIME [--snapshot-binlog-host HOST --snapshot-binlog-port PORT]] [--served-from DB_TYPE:KEYSPACE ...] [--durability-policy <policy_name>] [--sidecar-db-name <db_name>] [--twopc-enabled] [--default-charset <charset>] [--default-collation <collation>]",
 		Short: "Creates the specified keyspace in the topology.",
 		Long: `Creates the specified keyspace in the topology.
 	
 For a SNAPSHOT keyspace, the request must specify the name of a base keyspace,
-as well as a snapshot time.`,
+as well as a snapshot time. Its tablets restore the last backup of the base
+keyspace before the snapshot time, and replicate up to the snapshot time from
+the binlog server set with --snapshot-binlog-host and --snapshot-binlog-port, or
+else from the primaries of the base keyspace.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandCreateKeyspace,
@@ -78,6 +81,19 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetKeyspace,
 	}
+	// GetKeyspaceCharsetReport makes a GetKeyspaceCharsetReport gRPC call to a vtctld.
+	GetKeyspaceCharsetReport = &cobra.Command{
+		Use:   "GetKeyspaceCharsetReport [--exclude-tables=<exclude_tables>] <keyspace>",
+		Short: "Returns the tables and columns whose character set or collation differ from the default ones of the specified keyspace.",
+		Long: `Returns the tables and columns whose character set or collation differ from the default ones of the specified keyspace.
+
+The tables are read from the primary of every shard, so that the tables which drifted on some shards only are
+reported, and the report includes the ALTER TABLE statements converting each deviating table to the default character
+set and collation of the keyspace.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetKeyspaceCharsetReport,
+	}
 	// GetKeyspaces makes a GetKeyspaces gRPC call to a vtctld.
 	GetKeyspaces = &cobra.Command{
 		Use:                   "GetKeyspaces",
@@ -87,6 +103,18 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.NoArgs,
 		RunE:                  commandGetKeyspaces,
 	}
+	// GetSnapshotKeyspaceStatus makes a GetSnapshotKeyspaceStatus gRPC call to a vtctld.
+	GetSnapshotKeyspaceStatus = &cobra.Command{
+		Use:   "GetSnapshotKeyspaceStatus <keyspace>",
+		Short: "Returns the progress of the tablets of a snapshot keyspace catching up to its snapshot time.",
+		Long: `Returns the progress of the tablets of a snapshot keyspace catching up to its snapshot time.
+
+The tablets serve their tablet type once they caught up, and caught_up is true
+once all the tablets of the keyspace did.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetSnapshotKeyspaceStatus,
+	}
 	// RemoveKeyspaceCell makes a RemoveKeyspaceCell gRPC call to a vtctld.
 	RemoveKeyspaceCell = &cobra.Command{
 		Use:                   "RemoveKeyspaceCell [--force|-f] [--recursive|-r] <keyspace> <cell>",
@@ -102,6 +130,7 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Long: `Sets the durability-policy used by the specified keyspace. 
 Durability policy governs the durability of the keyspace by describing which tablets should be sending semi-sync acknowledgements to the primary.
 Possible values include 'semi_sync', 'none' and others as dictated by registered plugins.
+Custom rules can also be defined declaratively with a 'rules:' policy, e.g. 'rules:ackers=1;different_tag=region' to require an acknowledgement from a tablet of another region.
 
 To set the durability policy of customer keyspace to semi_sync, you would use the following command:
 SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
@@ -109,6 +138,31 @@ SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandSetKeyspaceDurabilityPolicy,
 	}
+	// SetKeyspaceCharset makes a SetKeyspaceCharset gRPC call to a vtctld.
+	SetKeyspaceCharset = &cobra.Command{
+		Use:   "SetKeyspaceCharset [--charset=<charset>] [--collation=<collation>] <keyspace>",
+		Short: "Sets the default character set and collation of the specified keyspace.",
+		Long: `Sets the default character set and collation of the specified keyspace.
+
+The CREATE TABLE and ALTER TABLE statements applied with ApplySchema or Online DDL to the keyspace cannot set another
+character set or collation, unless their DDL strategy has --allow-nondefault-charset. Empty values remove the defaults.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetKeyspaceCharset,
+	}
+	// SetKeyspaceTwoPC makes a SetKeyspaceTwoPC gRPC call to a vtctld.
+	SetKeyspaceTwoPC = &cobra.Command{
+		Use:   "SetKeyspaceTwoPC [--enabled=false] <keyspace>",
+		Short: "Marks the tablets of the specified keyspace as running with 2PC enabled or not.",
+		Long: `Marks the tablets of the specified keyspace as running with 2PC enabled or not, in the keyspace and in its SrvKeyspace in every cell.
+
+The sessions which set transaction_mode to TWOPC can only start transactions on the keyspaces marked as such, so the
+tablets of the keyspace must run with --twopc_enable before it is marked, and the keyspace must be unmarked before
+they stop.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetKeyspaceTwoPC,
+	}
 	// ValidateSchemaKeyspace makes a ValidateSchemaKeyspace gRPC call to a vtctld.
 	ValidateSchemaKeyspace = &cobra.Command{
 		Use:                   "ValidateSchemaKeyspace [--exclude-tables=<exclude_tables>] [--include-views] [--skip-no-primary] [--include-vschema] <keyspace>",
@@ -133,11 +187,16 @@ var createKeyspaceOptions = struct {
 	Force             bool
 	AllowEmptyVSchema bool
 
-	KeyspaceType      cli.KeyspaceTypeFlag
-	BaseKeyspace      string
-	SnapshotTimestamp string
-	DurabilityPolicy  string
-	SidecarDBName     string
+	KeyspaceType       cli.KeyspaceTypeFlag
+	BaseKeyspace       string
+	SnapshotTimestamp  string
+	SnapshotBinlogHost string
+	SnapshotBinlogPort int32
+	DurabilityPolicy   string
+	SidecarDBName      string
+	TwoPCEnabled       bool
+	DefaultCharset     string
+	DefaultCollation   string
 }{
 	KeyspaceType: cli.KeyspaceTypeFlag(topodatapb.KeyspaceType_NORMAL),
 }
@@ -151,7 +210,10 @@ func commandCreateKeyspace(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid keyspace type passed to --type: %v", createKeyspaceOptions.KeyspaceType)
 	}
 
-	var snapshotTime *vttime.Time
+	var (
+		snapshotTime         *vttime.Time
+		snapshotBinlogSource *topodatapb.SnapshotBinlogSource
+	)
 	if topodatapb.KeyspaceType(createKeyspaceOptions.KeyspaceType) == topodatapb.KeyspaceType_SNAPSHOT {
 		if createKeyspaceOptions.DurabilityPolicy != "none" {
 			return errors.New("--durability-policy cannot be specified while creating a snapshot keyspace")
@@ -175,6 +237,18 @@ func commandCreateKeyspace(cmd *cobra.Command, args []string) error {
 		}
 
 		snapshotTime = protoutil.TimeToProto(t)
+
+		if createKeyspaceOptions.SnapshotBinlogHost != "" {
+			if createKeyspaceOptions.SnapshotBinlogPort <= 0 {
+				return errors.New("--snapshot-binlog-port is required with --snapshot-binlog-host")
+			}
+			snapshotBinlogSource = &topodatapb.SnapshotBinlogSource{
+				Host: createKeyspaceOptions.SnapshotBinlogHost,
+				Port: createKeyspaceOptions.SnapshotBinlogPort,
+			}
+		}
+	} else if createKeyspaceOptions.SnapshotBinlogHost != "" {
+		return errors.New("--snapshot-binlog-host can only be specified while creating a snapshot keyspace")
 	}
 
 	createKeyspaceOptions.SidecarDBName = strings.TrimSpace(createKeyspaceOptions.SidecarDBName)
@@ -189,14 +263,18 @@ func commandCreateKeyspace(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	req := &vtctldatapb.CreateKeyspaceRequest{
-		Name:              name,
-		Force:             createKeyspaceOptions.Force,
-		AllowEmptyVSchema: createKeyspaceOptions.AllowEmptyVSchema,
-		Type:              topodatapb.KeyspaceType(createKeyspaceOptions.KeyspaceType),
-		BaseKeyspace:      createKeyspaceOptions.BaseKeyspace,
-		SnapshotTime:      snapshotTime,
-		DurabilityPolicy:  createKeyspaceOptions.DurabilityPolicy,
-		SidecarDbName:     createKeyspaceOptions.SidecarDBName,
+		Name:                 name,
+		Force:                createKeyspaceOptions.Force,
+		AllowEmptyVSchema:    createKeyspaceOptions.AllowEmptyVSchema,
+		Type:                 topodatapb.KeyspaceType(createKeyspaceOptions.KeyspaceType),
+		BaseKeyspace:         createKeyspaceOptions.BaseKeyspace,
+		SnapshotTime:         snapshotTime,
+		SnapshotBinlogSource: snapshotBinlogSource,
+		DurabilityPolicy:     createKeyspaceOptions.DurabilityPolicy,
+		SidecarDbName:        createKeyspaceOptions.SidecarDBName,
+		TwopcEnabled:         createKeyspaceOptions.TwoPCEnabled,
+		DefaultCharset:       createKeyspaceOptions.DefaultCharset,
+		DefaultCollation:     createKeyspaceOptions.DefaultCollation,
 	}
 
 	resp, err := client.CreateKeyspace(commandCtx, req)
@@ -280,6 +358,50 @@ func commandGetKeyspace(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetSnapshotKeyspaceStatus(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetSnapshotKeyspaceStatus(commandCtx, &vtctldatapb.GetSnapshotKeyspaceStatusRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var getKeyspaceCharsetReportOptions = struct {
+	ExcludeTables []string
+}{}
+
+func commandGetKeyspaceCharsetReport(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetKeyspaceCharsetReport(commandCtx, &vtctldatapb.GetKeyspaceCharsetReportRequest{
+		Keyspace:      keyspace,
+		ExcludeTables: getKeyspaceCharsetReportOptions.ExcludeTables,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandGetKeyspaces(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -350,6 +472,58 @@ func commandSetKeyspaceDurabilityPolicy(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+var setKeyspaceCharsetOptions = struct {
+	Charset   string
+	Collation string
+}{}
+
+func commandSetKeyspaceCharset(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetKeyspaceCharset(commandCtx, &vtctldatapb.SetKeyspaceCharsetRequest{
+		Keyspace:  keyspace,
+		Charset:   setKeyspaceCharsetOptions.Charset,
+		Collation: setKeyspaceCharsetOptions.Collation,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var setKeyspaceTwoPCOptions = struct {
+	Enabled bool
+}{}
+
+func commandSetKeyspaceTwoPC(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetKeyspaceTwoPC(commandCtx, &vtctldatapb.SetKeyspaceTwoPCRequest{
+		Keyspace: keyspace,
+		Enabled:  setKeyspaceTwoPCOptions.Enabled,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 var validateSchemaKeyspaceOptions = struct {
 	ExcludeTables  []string
 	IncludeViews   bool
@@ -409,8 +583,13 @@ func init() {
 	CreateKeyspace.Flags().Var(&createKeyspaceOptions.KeyspaceType, "type", "The type of the keyspace.")
 	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.BaseKeyspace, "base-keyspace", "", "The base keyspace for a snapshot keyspace.")
 	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.SnapshotTimestamp, "snapshot-timestamp", "", "The snapshot time for a snapshot keyspace, as a timestamp in RFC3339 format.")
-	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Possible values include 'semi_sync' and others as dictated by registered plugins.")
+	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.SnapshotBinlogHost, "snapshot-binlog-host", "", "The host of the binlog server the tablets of a snapshot keyspace replicate from up to the snapshot time, e.g. a ripple server. It can contain the {{.Keyspace}} and {{.Shard}} placeholders for the base keyspace and the shard of each tablet. If not set, they replicate from the primaries of the base keyspace.")
+	CreateKeyspace.Flags().Int32Var(&createKeyspaceOptions.SnapshotBinlogPort, "snapshot-binlog-port", 0, "The port of the binlog server of a snapshot keyspace.")
+	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Possible values include 'semi_sync' and others as dictated by registered plugins, or declarative 'rules:' policies.")
 	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.SidecarDBName, "sidecar-db-name", sidecar.DefaultName, "(Experimental) Name of the Vitess sidecar database that tablets in this keyspace will use for internal metadata.")
+	CreateKeyspace.Flags().BoolVar(&createKeyspaceOptions.TwoPCEnabled, "twopc-enabled", false, "Marks the tablets of this keyspace as running with 2PC enabled, so that the sessions with transaction_mode TWOPC can use it.")
+	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.DefaultCharset, "default-charset", "", "The default character set of the tables of this keyspace, which the schema changes applied with ApplySchema or Online DDL are validated against.")
+	CreateKeyspace.Flags().StringVar(&createKeyspaceOptions.DefaultCollation, "default-collation", "", "The default collation of the tables of this keyspace, which the schema changes applied with ApplySchema or Online DDL are validated against.")
 	Root.AddCommand(CreateKeyspace)
 
 	DeleteKeyspace.Flags().BoolVarP(&deleteKeyspaceOptions.Recursive, "recursive", "r", false, "Recursively delete all shards in the keyspace, and all tablets in those shards.")
@@ -419,15 +598,27 @@ func init() {
 
 	Root.AddCommand(FindAllShardsInKeyspace)
 	Root.AddCommand(GetKeyspace)
+
+	GetKeyspaceCharsetReport.Flags().StringSliceVar(&getKeyspaceCharsetReportOptions.ExcludeTables, "exclude-tables", nil, "Tables to exclude from the report.")
+	Root.AddCommand(GetKeyspaceCharsetReport)
+
 	Root.AddCommand(GetKeyspaces)
+	Root.AddCommand(GetSnapshotKeyspaceStatus)
 
 	RemoveKeyspaceCell.Flags().BoolVarP(&removeKeyspaceCellOptions.Force, "force", "f", false, "Proceed even if the cell's topology server cannot be reached. The assumption is that you turned down the entire cell, and just need to update the global topo data.")
 	RemoveKeyspaceCell.Flags().BoolVarP(&removeKeyspaceCellOptions.Recursive, "recursive", "r", false, "Also delete all tablets in that cell beloning to the specified keyspace.")
 	Root.AddCommand(RemoveKeyspaceCell)
 
-	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins.")
+	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins, or declarative 'rules:' policies.")
 	Root.AddCommand(SetKeyspaceDurabilityPolicy)
 
+	SetKeyspaceCharset.Flags().StringVar(&setKeyspaceCharsetOptions.Charset, "charset", "", "The default character set of the keyspace, e.g. utf8mb4.")
+	SetKeyspaceCharset.Flags().StringVar(&setKeyspaceCharsetOptions.Collation, "collation", "", "The default collation of the keyspace, e.g. utf8mb4_0900_ai_ci.")
+	Root.AddCommand(SetKeyspaceCharset)
+
+	SetKeyspaceTwoPC.Flags().BoolVar(&setKeyspaceTwoPCOptions.Enabled, "enabled", true, "Whether the tablets of the keyspace run with 2PC enabled.")
+	Root.AddCommand(SetKeyspaceTwoPC)
+
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeViews, "include-views", false, "Includes views in compared schemas.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeVSchema, "include-vschema", false, "Includes VSchema validation in validation results.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.SkipNoPrimary, "skip-no-primary", false, "Skips validation on whether or not a primary exists in shards.")
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetPlanCacheControl makes a GetPlanCacheControl gRPC call to a vtctld.
+	GetPlanCacheControl = &cobra.Command{
+		Use:                   "GetPlanCacheControl",
+		Short:                 "Displays the pinned queries and the recent plan invalidations of the vtgate plan cache control.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetPlanCacheControl,
+	}
+	// InvalidateQueryPlans makes an InvalidateQueryPlans gRPC call to a vtctld.
+	InvalidateQueryPlans = &cobra.Command{
+		Use:   "InvalidateQueryPlans <table> [<table> ...]",
+		Short: "Invalidates the vtgate query plans using any of the tables.",
+		Long: `Invalidates the vtgate query plans using any of the tables, including the
+pinned plans, which are pinned again when they are rebuilt.
+
+A table may be qualified by its keyspace, as in customer.orders. vtgates
+started with --enable-plan-cache-control apply the invalidation when they see
+it.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandInvalidateQueryPlans,
+	}
+	// PinQueryPlan makes a PinQueryPlan gRPC call to a vtctld.
+	PinQueryPlan = &cobra.Command{
+		Use:   "PinQueryPlan --keyspace <keyspace> <query>",
+		Short: "Pins the vtgate query plan of a normalized query.",
+		Long: `Pins the vtgate query plan of a normalized query, as listed by SHOW VITESS_PLANS,
+for the sessions targeting the keyspace.
+
+vtgates started with --enable-plan-cache-control keep the plan of the query
+built after it is pinned: it is neither evicted from the plan cache nor rebuilt
+when the vschema or the schema changes, until the query is unpinned or its
+tables are invalidated.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandPinQueryPlan,
+	}
+	// UnpinQueryPlan makes an UnpinQueryPlan gRPC call to a vtctld.
+	UnpinQueryPlan = &cobra.Command{
+		Use:                   "UnpinQueryPlan --keyspace <keyspace> <query>",
+		Short:                 "Unpins the vtgate query plan of a normalized query pinned with PinQueryPlan.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandUnpinQueryPlan,
+	}
+)
+
+func commandGetPlanCacheControl(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetPlanCacheControl(commandCtx, &vtctldatapb.GetPlanCacheControlRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.PlanCacheControl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandInvalidateQueryPlans(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.InvalidateQueryPlans(commandCtx, &vtctldatapb.InvalidateQueryPlansRequest{
+		Tables: cmd.Flags().Args(),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Invalidation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var pinQueryPlanOptions = struct {
+	Keyspace string
+}{}
+
+func commandPinQueryPlan(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.PinQueryPlan(commandCtx, &vtctldatapb.PinQueryPlanRequest{
+		Keyspace: pinQueryPlanOptions.Keyspace,
+		Query:    cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.PlanCacheControl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandUnpinQueryPlan(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.UnpinQueryPlan(commandCtx, &vtctldatapb.UnpinQueryPlanRequest{
+		Keyspace: pinQueryPlanOptions.Keyspace,
+		Query:    cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.PlanCacheControl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(GetPlanCacheControl)
+
+	Root.AddCommand(InvalidateQueryPlans)
+
+	PinQueryPlan.Flags().StringVarP(&pinQueryPlanOptions.Keyspace, "keyspace", "k", "", "The keyspace targeted by the sessions running the query.")
+	PinQueryPlan.MarkFlagRequired("keyspace")
+	Root.AddCommand(PinQueryPlan)
+
+	UnpinQueryPlan.Flags().StringVarP(&pinQueryPlanOptions.Keyspace, "keyspace", "k", "", "The keyspace targeted by the sessions running the query.")
+	UnpinQueryPlan.MarkFlagRequired("keyspace")
+	Root.AddCommand(UnpinQueryPlan)
+}
@@ -18,6 +18,7 @@ package command
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -31,9 +32,30 @@ import (
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 var (
+	// ClearTabletQuarantine makes a ClearTabletQuarantine gRPC call to a vtctld.
+	ClearTabletQuarantine = &cobra.Command{
+		Use:   "ClearTabletQuarantine [--tablet-type <type>] <alias>",
+		Short: "Lifts the quarantine of a tablet EmergencyReparentShard quarantined for its errant GTIDs.",
+		Long: `Lifts the quarantine of a tablet EmergencyReparentShard quarantined for its errant GTIDs.
+
+The tablet is changed back to a serving type, REPLICA by default, and its errant GTIDs are removed from its tags.
+The errant GTIDs should be removed from its database first, e.g. by restoring it from a backup.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandClearTabletQuarantine,
+	}
+	// CancelScheduledReparent makes a CancelScheduledReparent gRPC call to a vtctld.
+	CancelScheduledReparent = &cobra.Command{
+		Use:                   "CancelScheduledReparent <id>",
+		Short:                 "Cancels a reparent scheduled with SchedulePlannedReparentShard, unless it is running.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandCancelScheduledReparent,
+	}
 	// EmergencyReparentShard makes an EmergencyReparent gRPC call to a vtctld.
 	EmergencyReparentShard = &cobra.Command{
 		Use:                   "EmergencyReparentShard <keyspace/shard>",
@@ -42,6 +64,22 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandEmergencyReparentShard,
 	}
+	// GetQuarantinedTablets makes a GetQuarantinedTablets gRPC call to a vtctld.
+	GetQuarantinedTablets = &cobra.Command{
+		Use:                   "GetQuarantinedTablets <keyspace|keyspace/shard>",
+		Short:                 "Lists the tablets EmergencyReparentShard quarantined for their errant GTIDs, with the errant GTIDs in their tags.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetQuarantinedTablets,
+	}
+	// GetScheduledReparents makes a GetScheduledReparents gRPC call to a vtctld.
+	GetScheduledReparents = &cobra.Command{
+		Use:                   "GetScheduledReparents [<keyspace|keyspace/shard>]",
+		Short:                 "Lists the reparents scheduled with SchedulePlannedReparentShard on the vtctld, with their state.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MaximumNArgs(1),
+		RunE:                  commandGetScheduledReparents,
+	}
 	// InitShardPrimary makes an InitShardPrimary gRPC call to a vtctld.
 	InitShardPrimary = &cobra.Command{
 		Use:   "InitShardPrimary <keyspace/shard> <primary alias>",
@@ -73,6 +111,24 @@ EmergencyReparentShard should be used instead.
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandReparentTablet,
 	}
+	// SchedulePlannedReparentShard makes a SchedulePlannedReparentShard gRPC
+	// call to a vtctld.
+	SchedulePlannedReparentShard = &cobra.Command{
+		Use:   "SchedulePlannedReparentShard [--start-time <time>] [--retry-window <duration>] [--max-replication-lag <duration>] [--max-transaction-age <duration>] [--check-throttler] <keyspace/shard>",
+		Short: "Schedules a PlannedReparentShard at a given time, run once the shard meets the given preconditions.",
+		Long: `Schedules a PlannedReparentShard at a given time, run once the shard meets the given preconditions.
+
+The preconditions are checked at the start time: the replication lag of the replicas, the age of the transactions in flight
+on the primary, and the throttler of the primary. If they are not met, or the reparent fails, they are checked again every
+retry interval until the end of the retry window, after which the scheduled reparent fails.
+
+The scheduled reparents are kept in the memory of the vtctld, and are lost if it restarts. The vthook scheduled_reparent
+of the vtctld, if any, is run whenever a scheduled reparent changes state, with its KEYSPACE, SHARD, ID, STATE, ATTEMPTS
+and MESSAGE in its environment.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSchedulePlannedReparentShard,
+	}
 	// TabletExternallyReparented makes a TabletExternallyReparented gRPC call
 	// to a vtctld.
 	TabletExternallyReparented = &cobra.Command{
@@ -88,6 +144,31 @@ See the Reparenting guide for more information: https://vitess.io/docs/user-guid
 	}
 )
 
+var clearTabletQuarantineOptions = struct {
+	TabletType topodatapb.TabletType
+}{}
+
+func commandClearTabletQuarantine(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ClearTabletQuarantine(commandCtx, &vtctldatapb.ClearTabletQuarantineRequest{
+		TabletAlias: alias,
+		TabletType:  clearTabletQuarantineOptions.TabletType,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v\n", cli.MarshalTabletAWK(resp.Tablet))
+
+	return nil
+}
+
 var emergencyReparentShardOptions = struct {
 	Force                     bool
 	WaitReplicasTimeout       time.Duration
@@ -95,8 +176,29 @@ var emergencyReparentShardOptions = struct {
 	IgnoreReplicaAliasStrList []string
 	PreventCrossCellPromotion bool
 	WaitForAllTablets         bool
+	QuarantineErrantReplicas  bool
 }{}
 
+func commandCancelScheduledReparent(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.CancelScheduledReparent(commandCtx, &vtctldatapb.CancelScheduledReparentRequest{
+		Id: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.ScheduledReparent)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandEmergencyReparentShard(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -134,6 +236,7 @@ func commandEmergencyReparentShard(cmd *cobra.Command, args []string) error {
 		WaitReplicasTimeout:       protoutil.DurationToProto(emergencyReparentShardOptions.WaitReplicasTimeout),
 		PreventCrossCellPromotion: emergencyReparentShardOptions.PreventCrossCellPromotion,
 		WaitForAllTablets:         emergencyReparentShardOptions.WaitForAllTablets,
+		QuarantineErrantReplicas:  emergencyReparentShardOptions.QuarantineErrantReplicas,
 	})
 	if err != nil {
 		return err
@@ -146,6 +249,66 @@ func commandEmergencyReparentShard(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetQuarantinedTablets(cmd *cobra.Command, args []string) error {
+	keyspace, shard := cmd.Flags().Arg(0), ""
+	if strings.Contains(keyspace, "/") {
+		var err error
+		keyspace, shard, err = topoproto.ParseKeyspaceShard(keyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetQuarantinedTablets(commandCtx, &vtctldatapb.GetQuarantinedTabletsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandGetScheduledReparents(cmd *cobra.Command, args []string) error {
+	keyspace, shard := cmd.Flags().Arg(0), ""
+	if strings.Contains(keyspace, "/") {
+		var err error
+		keyspace, shard, err = topoproto.ParseKeyspaceShard(keyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetScheduledReparents(commandCtx, &vtctldatapb.GetScheduledReparentsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 var initShardPrimaryOptions = struct {
 	WaitReplicasTimeout time.Duration
 	Force               bool
@@ -189,10 +352,12 @@ var plannedReparentShardOptions = struct {
 	TolerableReplicationLag time.Duration
 }{}
 
-func commandPlannedReparentShard(cmd *cobra.Command, args []string) error {
-	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+// plannedReparentShardRequest returns the PlannedReparentShard request of the
+// shard for the PlannedReparentShard flags.
+func plannedReparentShardRequest(arg string) (*vtctldatapb.PlannedReparentShardRequest, error) {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(arg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var (
@@ -203,27 +368,36 @@ func commandPlannedReparentShard(cmd *cobra.Command, args []string) error {
 	if plannedReparentShardOptions.NewPrimaryAliasStr != "" {
 		newPrimaryAlias, err = topoproto.ParseTabletAlias(plannedReparentShardOptions.NewPrimaryAliasStr)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if plannedReparentShardOptions.AvoidPrimaryAliasStr != "" {
 		avoidPrimaryAlias, err = topoproto.ParseTabletAlias(plannedReparentShardOptions.AvoidPrimaryAliasStr)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	cli.FinishedParsing(cmd)
-
-	resp, err := client.PlannedReparentShard(commandCtx, &vtctldatapb.PlannedReparentShardRequest{
+	return &vtctldatapb.PlannedReparentShardRequest{
 		Keyspace:                keyspace,
 		Shard:                   shard,
 		NewPrimary:              newPrimaryAlias,
 		AvoidPrimary:            avoidPrimaryAlias,
 		WaitReplicasTimeout:     protoutil.DurationToProto(plannedReparentShardOptions.WaitReplicasTimeout),
 		TolerableReplicationLag: protoutil.DurationToProto(plannedReparentShardOptions.TolerableReplicationLag),
-	})
+	}, nil
+}
+
+func commandPlannedReparentShard(cmd *cobra.Command, args []string) error {
+	req, err := plannedReparentShardRequest(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.PlannedReparentShard(commandCtx, req)
 	if err != nil {
 		return err
 	}
@@ -258,6 +432,57 @@ func commandReparentTablet(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var schedulePlannedReparentShardOptions = struct {
+	StartTime         string
+	RetryWindow       time.Duration
+	RetryInterval     time.Duration
+	MaxReplicationLag time.Duration
+	MaxTransactionAge time.Duration
+	CheckThrottler    bool
+}{}
+
+func commandSchedulePlannedReparentShard(cmd *cobra.Command, args []string) error {
+	req, err := plannedReparentShardRequest(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var startTime *vttimepb.Time
+	if schedulePlannedReparentShardOptions.StartTime != "" {
+		t, err := time.Parse(time.RFC3339, schedulePlannedReparentShardOptions.StartTime)
+		if err != nil {
+			return fmt.Errorf("invalid --start-time: %w", err)
+		}
+		startTime = protoutil.TimeToProto(t)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SchedulePlannedReparentShard(commandCtx, &vtctldatapb.SchedulePlannedReparentShardRequest{
+		Request:       req,
+		StartTime:     startTime,
+		RetryWindow:   protoutil.DurationToProto(schedulePlannedReparentShardOptions.RetryWindow),
+		RetryInterval: protoutil.DurationToProto(schedulePlannedReparentShardOptions.RetryInterval),
+		Preconditions: &vtctldatapb.ReparentPreconditions{
+			MaxReplicationLag: protoutil.DurationToProto(schedulePlannedReparentShardOptions.MaxReplicationLag),
+			MaxTransactionAge: protoutil.DurationToProto(schedulePlannedReparentShardOptions.MaxTransactionAge),
+			CheckThrottler:    schedulePlannedReparentShardOptions.CheckThrottler,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.ScheduledReparent)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandTabletExternallyReparented(cmd *cobra.Command, args []string) error {
 	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -282,13 +507,22 @@ func commandTabletExternallyReparented(cmd *cobra.Command, args []string) error
 }
 
 func init() {
+	ClearTabletQuarantine.Flags().Var((*topoproto.TabletTypeFlag)(&clearTabletQuarantineOptions.TabletType), "tablet-type", "The type the tablet is changed to. REPLICA by default.")
+	Root.AddCommand(ClearTabletQuarantine)
+
+	Root.AddCommand(CancelScheduledReparent)
+
 	EmergencyReparentShard.Flags().DurationVar(&emergencyReparentShardOptions.WaitReplicasTimeout, "wait-replicas-timeout", topo.RemoteOperationTimeout, "Time to wait for replicas to catch up in reparenting.")
 	EmergencyReparentShard.Flags().StringVar(&emergencyReparentShardOptions.NewPrimaryAliasStr, "new-primary", "", "Alias of a tablet that should be the new primary. If not specified, the vtctld will select the best candidate to promote.")
 	EmergencyReparentShard.Flags().BoolVar(&emergencyReparentShardOptions.PreventCrossCellPromotion, "prevent-cross-cell-promotion", false, "Only promotes a new primary from the same cell as the previous primary.")
 	EmergencyReparentShard.Flags().BoolVar(&emergencyReparentShardOptions.WaitForAllTablets, "wait-for-all-tablets", false, "Should ERS wait for all the tablets to respond. Useful when all the tablets are reachable.")
+	EmergencyReparentShard.Flags().BoolVar(&emergencyReparentShardOptions.QuarantineErrantReplicas, "quarantine-errant-replicas", false, "Changes the type of the replicas with errant GTIDs to DRAINED, and adds their errant GTIDs to their tags, so that they are not promoted until their quarantine is cleared with ClearTabletQuarantine.")
 	EmergencyReparentShard.Flags().StringSliceVarP(&emergencyReparentShardOptions.IgnoreReplicaAliasStrList, "ignore-replicas", "i", nil, "Comma-separated, repeated list of replica tablet aliases to ignore during the emergency reparent.")
 	Root.AddCommand(EmergencyReparentShard)
 
+	Root.AddCommand(GetQuarantinedTablets)
+	Root.AddCommand(GetScheduledReparents)
+
 	InitShardPrimary.Flags().DurationVar(&initShardPrimaryOptions.WaitReplicasTimeout, "wait-replicas-timeout", 30*time.Second, "Time to wait for replicas to catch up in reparenting.")
 	InitShardPrimary.Flags().BoolVar(&initShardPrimaryOptions.Force, "force", false, "Force the reparent even if the provided tablet is not writable or the shard primary.")
 	Root.AddCommand(InitShardPrimary)
@@ -300,5 +534,18 @@ func init() {
 	Root.AddCommand(PlannedReparentShard)
 
 	Root.AddCommand(ReparentTablet)
+
+	SchedulePlannedReparentShard.Flags().DurationVar(&plannedReparentShardOptions.WaitReplicasTimeout, "wait-replicas-timeout", topo.RemoteOperationTimeout, "Time to wait for replicas to catch up on replication both before and after reparenting.")
+	SchedulePlannedReparentShard.Flags().DurationVar(&plannedReparentShardOptions.TolerableReplicationLag, "tolerable-replication-lag", 0, "Amount of replication lag that is considered acceptable for a tablet to be eligible for promotion when Vitess makes the choice of a new primary.")
+	SchedulePlannedReparentShard.Flags().StringVar(&plannedReparentShardOptions.NewPrimaryAliasStr, "new-primary", "", "Alias of a tablet that should be the new primary.")
+	SchedulePlannedReparentShard.Flags().StringVar(&plannedReparentShardOptions.AvoidPrimaryAliasStr, "avoid-primary", "", "Alias of a tablet that should not be the primary; i.e. \"reparent to any other tablet if this one is the primary\".")
+	SchedulePlannedReparentShard.Flags().StringVar(&schedulePlannedReparentShardOptions.StartTime, "start-time", "", "Time of the first attempt, in RFC3339 format. Right away by default.")
+	SchedulePlannedReparentShard.Flags().DurationVar(&schedulePlannedReparentShardOptions.RetryWindow, "retry-window", 0, "How long after the start time the reparent is attempted again when the preconditions are not met or it fails. A single attempt is made if 0.")
+	SchedulePlannedReparentShard.Flags().DurationVar(&schedulePlannedReparentShardOptions.RetryInterval, "retry-interval", time.Minute, "Time between two attempts.")
+	SchedulePlannedReparentShard.Flags().DurationVar(&schedulePlannedReparentShardOptions.MaxReplicationLag, "max-replication-lag", 0, "Maximum replication lag of the replicas of the shard. Not checked if 0.")
+	SchedulePlannedReparentShard.Flags().DurationVar(&schedulePlannedReparentShardOptions.MaxTransactionAge, "max-transaction-age", 0, "Maximum age of the transactions in flight on the primary of the shard. Not checked if 0.")
+	SchedulePlannedReparentShard.Flags().BoolVar(&schedulePlannedReparentShardOptions.CheckThrottler, "check-throttler", false, "Requires the throttler of the primary of the shard to be OK.")
+	Root.AddCommand(SchedulePlannedReparentShard)
+
 	Root.AddCommand(TabletExternallyReparented)
 }
@@ -34,7 +34,7 @@ import (
 var (
 	// ApplyRoutingRules makes an ApplyRoutingRules gRPC call to a vtctld.
 	ApplyRoutingRules = &cobra.Command{
-		Use:                   "ApplyRoutingRules {--rules RULES | --rules-file RULES_FILE} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run]",
+		Use:                   "ApplyRoutingRules {--rules RULES | --rules-file RULES_FILE} [--cells=c1,c2,...] [--skip-rebuild] [--staged] [--dry-run]",
 		Short:                 "Applies the VSchema routing rules.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.NoArgs,
@@ -55,6 +55,7 @@ var applyRoutingRulesOptions = struct {
 	RulesFilePath string
 	Cells         []string
 	SkipRebuild   bool
+	Staged        bool
 	DryRun        bool
 }{}
 
@@ -113,6 +114,7 @@ func commandApplyRoutingRules(cmd *cobra.Command, args []string) error {
 		RoutingRules: rr,
 		SkipRebuild:  applyRoutingRulesOptions.SkipRebuild,
 		RebuildCells: applyRoutingRulesOptions.Cells,
+		Staged:       applyRoutingRulesOptions.Staged,
 	})
 	if err != nil {
 		return err
@@ -150,6 +152,7 @@ func init() {
 	ApplyRoutingRules.Flags().StringVarP(&applyRoutingRulesOptions.RulesFilePath, "rules-file", "f", "", "Path to a file containing routing rules specified as JSON.")
 	ApplyRoutingRules.Flags().StringSliceVarP(&applyRoutingRulesOptions.Cells, "cells", "c", nil, "Limit the VSchema graph rebuilding to the specified cells. Ignored if --skip-rebuild is specified.")
 	ApplyRoutingRules.Flags().BoolVar(&applyRoutingRulesOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvVSchema objects.")
+	ApplyRoutingRules.Flags().BoolVar(&applyRoutingRulesOptions.Staged, "staged", false, "Only apply the routing rules to the SrvVSchema objects in --cells, without saving them to the global topo. Use ReconcileSrvVSchemas to promote or revert them.")
 	ApplyRoutingRules.Flags().BoolVarP(&applyRoutingRulesOptions.DryRun, "dry-run", "d", false, "Load the specified routing rules as a validation step, but do not actually apply the rules to the topo.")
 	Root.AddCommand(ApplyRoutingRules)
 
@@ -19,6 +19,7 @@ package command
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -39,7 +40,7 @@ import (
 var (
 	// ApplySchema makes an ApplySchema gRPC call to a vtctld.
 	ApplySchema = &cobra.Command{
-		Use:   "ApplySchema [--ddl-strategy <strategy>] [--uuid <uuid> ...] [--migration-context <context>] [--wait-replicas-timeout <duration>] [--caller-id <caller_id>] {--sql-file <file> | --sql <sql>} <keyspace>",
+		Use:   "ApplySchema [--ddl-strategy <strategy>] [--uuid <uuid> ...] [--migration-context <context>] [--wait-replicas-timeout <duration>] [--caller-id <caller_id>] [--shard-concurrency <n>] [--shard-retries <n>] [--shard-retry-delay <duration>] [--max-shard-failure-ratio <ratio>] [--progress] {--sql-file <file> | --sql <sql>} <keyspace>",
 		Short: "Applies the schema change to the specified keyspace on every primary, running in parallel on all shards. The changes are then propagated to replicas via replication.",
 		Long: `Applies the schema change to the specified keyspace on every primary, running in parallel on all shards. The changes are then propagated to replicas via replication.
 
@@ -53,7 +54,14 @@ For --uuid, this is used like "--uuid $first_uuid --uuid $second_uuid".
 For --sql, semi-colons and repeated values may be mixed, for example:
 
 	ApplySchema --sql "CREATE TABLE my_table; CREATE TABLE my_other_table"
-	ApplySchema --sql "CREATE TABLE my_table" --sql "CREATE TABLE my_other_table"`,
+	ApplySchema --sql "CREATE TABLE my_table" --sql "CREATE TABLE my_other_table"
+
+With the 'direct' strategy, --shard-concurrency rolls the schema change out to at most that many shards at a time,
+--shard-retries retries it on the shards where it failed after waiting --shard-retry-delay, and the rollout stops
+starting it on new shards once the ratio of failed shards exceeds --max-shard-failure-ratio. --progress prints the
+progress of every shard as it goes:
+
+	ApplySchema --shard-concurrency 4 --shard-retries 2 --shard-retry-delay 10s --max-shard-failure-ratio 0.1 --progress --sql "ALTER TABLE t ADD COLUMN c int" commerce`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandApplySchema,
@@ -66,6 +74,33 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetSchema,
 	}
+	// GetSchemaAtPosition makes a GetSchemaAtPosition gRPC call to a vtctld.
+	GetSchemaAtPosition = &cobra.Command{
+		Use:   "GetSchemaAtPosition [--tables TABLES ...] <tablet_alias> <position>",
+		Short: "Displays the schema tracked by a tablet at the given replication position.",
+		Long: `Displays the schema tracked by a tablet at the given replication position.
+
+The returned schema is the most recent version recorded at or before <position>, which must
+include the flavor prefix (e.g. "MySQL56/<gtid set>"); only MySQL GTID positions are supported.
+The tablet must be running with --track_schema_versions for schema versions to be recorded.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandGetSchemaAtPosition,
+	}
+	// GetSchemaDrift makes a GetSchemaDrift gRPC call to a vtctld.
+	GetSchemaDrift = &cobra.Command{
+		Use:   "GetSchemaDrift [--exclude-tables EXCLUDE_TABLES ...] [--include-views] [--concurrency <concurrency>] <keyspace>",
+		Short: "Displays the tables whose definitions diverge between the tablets of a keyspace.",
+		Long: `Displays the tables whose definitions diverge between the tablets of a keyspace.
+
+The schema of every tablet of the keyspace, primaries and replicas alike, is compared to the
+schema of the primary of the first shard. For each diverging table, the output includes both
+definitions and the statement which brings the table of the tablet in line with the reference.
+At most --concurrency tablets are read at the same time.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetSchemaDrift,
+	}
 	// ReloadSchema makes a ReloadSchema gRPC call to a vtctld.
 	ReloadSchema = &cobra.Command{
 		Use:                   "ReloadSchema <tablet_alias>",
@@ -103,6 +138,11 @@ var applySchemaOptions = struct {
 	SkipPreflight           bool
 	CallerID                string
 	BatchSize               int64
+	ShardConcurrency        int64
+	ShardRetries            int64
+	ShardRetryDelay         time.Duration
+	MaxShardFailureRatio    float64
+	Progress                bool
 }{}
 
 func commandApplySchema(cmd *cobra.Command, args []string) error {
@@ -136,7 +176,7 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 
 	ks := cmd.Flags().Arg(0)
 
-	resp, err := client.ApplySchema(commandCtx, &vtctldatapb.ApplySchemaRequest{
+	req := &vtctldatapb.ApplySchemaRequest{
 		Keyspace:            ks,
 		DdlStrategy:         applySchemaOptions.DDLStrategy,
 		Sql:                 parts,
@@ -145,7 +185,23 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 		WaitReplicasTimeout: protoutil.DurationToProto(applySchemaOptions.WaitReplicasTimeout),
 		CallerId:            cid,
 		BatchSize:           applySchemaOptions.BatchSize,
-	})
+	}
+	flags := cmd.Flags()
+	if flags.Changed("shard-concurrency") || flags.Changed("shard-retries") || flags.Changed("shard-retry-delay") || flags.Changed("max-shard-failure-ratio") {
+		req.ShardRollout = &vtctldatapb.ApplySchemaShardRollout{
+			Concurrency:     applySchemaOptions.ShardConcurrency,
+			Retries:         applySchemaOptions.ShardRetries,
+			RetryDelay:      protoutil.DurationToProto(applySchemaOptions.ShardRetryDelay),
+			MaxFailureRatio: applySchemaOptions.MaxShardFailureRatio,
+		}
+	}
+
+	var resp *vtctldatapb.ApplySchemaResponse
+	if applySchemaOptions.Progress {
+		resp, err = applySchemaWithProgress(req)
+	} else {
+		resp, err = client.ApplySchema(commandCtx, req)
+	}
 	if err != nil {
 		return err
 	}
@@ -154,6 +210,41 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applySchemaWithProgress applies the schema change with ApplySchemaStream,
+// and prints the progress of every shard as it comes.
+func applySchemaWithProgress(req *vtctldatapb.ApplySchemaRequest) (*vtctldatapb.ApplySchemaResponse, error) {
+	stream, err := client.ApplySchemaStream(commandCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *vtctldatapb.ApplySchemaResponse
+	for {
+		msg, err := stream.Recv()
+		switch err {
+		case nil:
+		case io.EOF:
+			if resp == nil {
+				return nil, errors.New("ApplySchemaStream ended without a result")
+			}
+			return resp, nil
+		default:
+			return nil, err
+		}
+
+		if msg.Result != nil {
+			resp = msg.Result
+		}
+		if progress := msg.Progress; progress != nil {
+			line := fmt.Sprintf("sql %d, shard %s: %s (attempt %d)", progress.SqlIndex, progress.Shard, progress.State, progress.Attempt)
+			if progress.Error != "" {
+				line += ": " + progress.Error
+			}
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
+
 var getSchemaOptions = struct {
 	Tables          []string
 	ExcludeTables   []string
@@ -210,6 +301,66 @@ func commandGetSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var getSchemaAtPositionOptions = struct {
+	Tables []string
+}{}
+
+func commandGetSchemaAtPosition(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetSchemaAtPosition(commandCtx, &vtctldatapb.GetSchemaAtPositionRequest{
+		TabletAlias: alias,
+		Position:    cmd.Flags().Arg(1),
+		Tables:      getSchemaAtPositionOptions.Tables,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var getSchemaDriftOptions = struct {
+	ExcludeTables []string
+	IncludeViews  bool
+	Concurrency   uint32
+}{}
+
+func commandGetSchemaDrift(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetSchemaDrift(commandCtx, &vtctldatapb.GetSchemaDriftRequest{
+		Keyspace:      cmd.Flags().Arg(0),
+		ExcludeTables: getSchemaDriftOptions.ExcludeTables,
+		IncludeViews:  getSchemaDriftOptions.IncludeViews,
+		Concurrency:   getSchemaDriftOptions.Concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandReloadSchema(cmd *cobra.Command, args []string) error {
 	tabletAlias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -293,6 +444,11 @@ func init() {
 	ApplySchema.Flags().StringArrayVar(&applySchemaOptions.SQL, "sql", nil, "Semicolon-delimited, repeatable SQL commands to apply. Exactly one of --sql|--sql-file is required.")
 	ApplySchema.Flags().StringVar(&applySchemaOptions.SQLFile, "sql-file", "", "Path to a file containing semicolon-delimited SQL commands to apply. Exactly one of --sql|--sql-file is required.")
 	ApplySchema.Flags().Int64Var(&applySchemaOptions.BatchSize, "batch-size", 0, "How many queries to batch together. Only applicable when all queries are CREATE TABLE|VIEW")
+	ApplySchema.Flags().Int64Var(&applySchemaOptions.ShardConcurrency, "shard-concurrency", 0, "Maximum number of shards applying a direct DDL at the same time. 0 applies it on all the shards at once.")
+	ApplySchema.Flags().Int64Var(&applySchemaOptions.ShardRetries, "shard-retries", 0, "Number of times a direct DDL is retried on a shard where it failed.")
+	ApplySchema.Flags().DurationVar(&applySchemaOptions.ShardRetryDelay, "shard-retry-delay", 5*time.Second, "Time to wait before retrying a direct DDL on a shard.")
+	ApplySchema.Flags().Float64Var(&applySchemaOptions.MaxShardFailureRatio, "max-shard-failure-ratio", 0, "Ratio of failed shards above which a direct DDL is not started on more shards. 0 stops at the first failed shard.")
+	ApplySchema.Flags().BoolVar(&applySchemaOptions.Progress, "progress", false, "Print the progress of the schema change on every shard.")
 
 	Root.AddCommand(ApplySchema)
 
@@ -305,6 +461,14 @@ func init() {
 
 	Root.AddCommand(GetSchema)
 
+	GetSchemaAtPosition.Flags().StringSliceVar(&getSchemaAtPositionOptions.Tables, "tables", nil, "List of tables to display the schema for. If empty, all tracked tables are displayed.")
+	Root.AddCommand(GetSchemaAtPosition)
+
+	GetSchemaDrift.Flags().StringSliceVar(&getSchemaDriftOptions.ExcludeTables, "exclude-tables", nil, "List of tables to leave out of the comparison. Each is either an exact match, or a regular expression of the form `/regexp/`.")
+	GetSchemaDrift.Flags().BoolVar(&getSchemaDriftOptions.IncludeViews, "include-views", false, "Compares views in addition to base tables.")
+	GetSchemaDrift.Flags().Uint32Var(&getSchemaDriftOptions.Concurrency, "concurrency", 10, "Maximum number of tablets whose schema is read at the same time.")
+	Root.AddCommand(GetSchemaDrift)
+
 	Root.AddCommand(ReloadSchema)
 
 	ReloadSchemaKeyspace.Flags().Int32Var(&reloadSchemaKeyspaceOptions.Concurrency, "concurrency", 10, "Number of tablets to reload in parallel. Set to zero for unbounded concurrency.")
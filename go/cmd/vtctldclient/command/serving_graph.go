@@ -59,6 +59,14 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetSrvVSchema,
 	}
+	// GetSrvVSchemaDiffs makes a GetSrvVSchemaDiffs gRPC call to a vtctld.
+	GetSrvVSchemaDiffs = &cobra.Command{
+		Use:                   "GetSrvVSchemaDiffs [<cell> ...]",
+		Short:                 "Outputs a JSON mapping of cell=>divergence from the global VSchema, for cells whose SrvVSchema differs from it. Omit to compare all cells.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ArbitraryArgs,
+		RunE:                  commandGetSrvVSchemaDiffs,
+	}
 	// GetSrvVSchemas makes a GetSrvVSchemas gRPC call to a vtctld.
 	GetSrvVSchemas = &cobra.Command{
 		Use:                   "GetSrvVSchemas [<cell> ...]",
@@ -83,6 +91,24 @@ var (
 		Args:                  cobra.NoArgs,
 		RunE:                  commandRebuildVSchemaGraph,
 	}
+	// ReconcileSrvVSchemas makes a ReconcileSrvVSchemas gRPC call to a vtctld.
+	ReconcileSrvVSchemas = &cobra.Command{
+		Use:   "ReconcileSrvVSchemas [--cells=c1,c2,...] [--promote-from-cell=<cell>] [--dry-run]",
+		Short: "Rebuilds the SrvVSchema in cells that diverge from the global VSchema, optionally promoting one cell's SrvVSchema to the global VSchema first.",
+		Long: `Rebuilds the SrvVSchema in cells that diverge from the global VSchema, for
+example after a staged ApplyVSchema or ApplyRoutingRules.
+
+Without --promote-from-cell, the changes staged in --cells (or all cells if none
+provided) are dropped, and diverging cells are reverted to the global VSchema.
+
+With --promote-from-cell, the keyspace VSchemas and routing rules of that cell
+that diverge from the global VSchema are saved to the global topo, and the
+SrvVSchema is then rebuilt in all of --cells (or all cells if none provided).
+The changes staged in that cell are dropped once promoted.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandReconcileSrvVSchemas,
+	}
 )
 
 func commandDeleteSrvVSchema(cmd *cobra.Command, args []string) error {
@@ -189,6 +215,28 @@ func commandGetSrvVSchemas(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetSrvVSchemaDiffs(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	cells := cmd.Flags().Args()[0:]
+
+	resp, err := client.GetSrvVSchemaDiffs(commandCtx, &vtctldatapb.GetSrvVSchemaDiffsRequest{
+		Cells: cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Diffs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 var rebuildKeyspaceGraphOptions = struct {
 	Cells        []string
 	AllowPartial bool
@@ -231,12 +279,41 @@ func commandRebuildVSchemaGraph(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var reconcileSrvVSchemasOptions = struct {
+	Cells           []string
+	PromoteFromCell string
+	DryRun          bool
+}{}
+
+func commandReconcileSrvVSchemas(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ReconcileSrvVSchemas(commandCtx, &vtctldatapb.ReconcileSrvVSchemasRequest{
+		Cells:           reconcileSrvVSchemasOptions.Cells,
+		PromoteFromCell: reconcileSrvVSchemasOptions.PromoteFromCell,
+		DryRun:          reconcileSrvVSchemasOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func init() {
 	Root.AddCommand(DeleteSrvVSchema)
 
 	Root.AddCommand(GetSrvKeyspaceNames)
 	Root.AddCommand(GetSrvKeyspaces)
 	Root.AddCommand(GetSrvVSchema)
+	Root.AddCommand(GetSrvVSchemaDiffs)
 	Root.AddCommand(GetSrvVSchemas)
 
 	RebuildKeyspaceGraph.Flags().StringSliceVarP(&rebuildKeyspaceGraphOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update.")
@@ -245,4 +322,9 @@ func init() {
 
 	RebuildVSchemaGraph.Flags().StringSliceVarP(&rebuildVSchemaGraphOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to look for tablets.")
 	Root.AddCommand(RebuildVSchemaGraph)
+
+	ReconcileSrvVSchemas.Flags().StringSliceVarP(&reconcileSrvVSchemasOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to reconcile.")
+	ReconcileSrvVSchemas.Flags().StringVar(&reconcileSrvVSchemasOptions.PromoteFromCell, "promote-from-cell", "", "Save the diverging VSchema of this cell to the global topo before reconciling.")
+	ReconcileSrvVSchemas.Flags().BoolVar(&reconcileSrvVSchemasOptions.DryRun, "dry-run", false, "Report the divergence that would be reconciled, without changing the topo.")
+	Root.AddCommand(ReconcileSrvVSchemas)
 }
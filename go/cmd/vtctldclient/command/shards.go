@@ -53,6 +53,22 @@ that shard.`,
 		Args:                  cobra.MinimumNArgs(1),
 		RunE:                  commandDeleteShards,
 	}
+	// FreezeShardWrites makes a FreezeShardWrites gRPC request to a vtctld.
+	FreezeShardWrites = &cobra.Command{
+		Use:   "FreezeShardWrites --reason <reason> [--buffer] <keyspace/shard>",
+		Short: "Fences the writes to a shard until UnfreezeShardWrites.",
+		Long: `Fences the writes to a shard until UnfreezeShardWrites.
+
+The primary of the shard rejects the writes with the given reason, and its MySQL
+is made super_read_only. The freeze is recorded in the shard record, so that it
+survives restarts and reparents of the primary.
+
+With --buffer, the vtgates buffer the writes, up to their buffering window,
+instead of failing them right away.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandFreezeShardWrites,
+	}
 	// GenerateShardRanges outputs a set of shard ranges assuming a (mostly)
 	// equal distribution of N shards.
 	GenerateShardRanges = &cobra.Command{
@@ -101,6 +117,30 @@ that shard.`,
 		Args:                  cobra.MinimumNArgs(1),
 		RunE:                  commandGetShardReplication,
 	}
+	// GetShardWriteFreezeStatus makes a GetShardWriteFreezeStatus gRPC request
+	// to a vtctld.
+	GetShardWriteFreezeStatus = &cobra.Command{
+		Use:                   "GetShardWriteFreezeStatus <keyspace/shard>",
+		Short:                 "Returns whether the writes to a shard are frozen, and whether its primary is super_read_only.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetShardWriteFreezeStatus,
+	}
+	// PlanReshard makes a PlanReshard gRPC request to a vtctld.
+	PlanReshard = &cobra.Command{
+		Use:   "PlanReshard --shard-count <n> [--source-shards <shards>] [--tables <tables>] [--sample-size <rows>] [--key-bytes <bytes>] [--tablet-type <type>] <keyspace>",
+		Short: "Proposes target shard ranges of balanced data sizes for a Reshard, from a sample of the rows of the source shards.",
+		Long: `Proposes target shard ranges of balanced data sizes for a Reshard, from a sample of the rows of the source shards.
+
+The rows of the tables are sampled from a tablet of each source shard, and mapped
+to their keyspace ids by the primary vindex of their table. Each sampled row
+stands for an equal share of the data size of its table on its shard. The
+proposed shards, and their estimated data sizes and row counts, are printed as
+JSON, along with the list of shards to pass to Reshard --target-shards.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandPlanReshard,
+	}
 	// RemoveShardCell makes a RemoveShardCell gRPC request to a vtctld.
 	RemoveShardCell = &cobra.Command{
 		Use:                   "RemoveShardCell [--force|-f] [--recursive|-r] <keyspace/shard> <cell>",
@@ -195,6 +235,14 @@ Use ctrl-C to interrupt the command and see partial results if needed.`,
 		RunE:                  commandSourceShardDelete,
 	}
 
+	// UnfreezeShardWrites makes a UnfreezeShardWrites gRPC request to a vtctld.
+	UnfreezeShardWrites = &cobra.Command{
+		Use:                   "UnfreezeShardWrites <keyspace/shard>",
+		Short:                 "Lifts the freeze of the writes to a shard set by FreezeShardWrites.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandUnfreezeShardWrites,
+	}
 	// ValidateVersionShard makes a ValidateVersionShard gRPC request to a vtctld.
 	ValidateVersionShard = &cobra.Command{
 		Use:                   "ValidateVersionShard <keyspace/shard>",
@@ -268,6 +316,38 @@ func commandDeleteShards(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var freezeShardWritesOptions = struct {
+	Reason string
+	Buffer bool
+}{}
+
+func commandFreezeShardWrites(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.FreezeShardWrites(commandCtx, &vtctldatapb.FreezeShardWritesRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+		Reason:   freezeShardWritesOptions.Reason,
+		Buffer:   freezeShardWritesOptions.Buffer,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Shard)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandGetShard(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -329,6 +409,67 @@ var removeShardCellOptions = struct {
 	Recursive bool
 }{}
 
+func commandGetShardWriteFreezeStatus(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetShardWriteFreezeStatus(commandCtx, &vtctldatapb.GetShardWriteFreezeStatusRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var planReshardOptions = struct {
+	SourceShards []string
+	ShardCount   int32
+	Tables       []string
+	SampleSize   int64
+	KeyBytes     int32
+	TabletType   topodatapb.TabletType
+}{
+	TabletType: topodatapb.TabletType_REPLICA,
+}
+
+func commandPlanReshard(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.PlanReshard(commandCtx, &vtctldatapb.PlanReshardRequest{
+		Keyspace:     cmd.Flags().Arg(0),
+		SourceShards: planReshardOptions.SourceShards,
+		ShardCount:   planReshardOptions.ShardCount,
+		Tables:       planReshardOptions.Tables,
+		SampleSize:   planReshardOptions.SampleSize,
+		KeyBytes:     planReshardOptions.KeyBytes,
+		TabletType:   planReshardOptions.TabletType,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandRemoveShardCell(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -626,6 +767,31 @@ func commandSourceShardDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandUnfreezeShardWrites(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.UnfreezeShardWrites(commandCtx, &vtctldatapb.UnfreezeShardWritesRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Shard)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandValidateVersionShard(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -661,10 +827,25 @@ func init() {
 	DeleteShards.Flags().BoolVarP(&deleteShardsOptions.Force, "force", "f", false, "Remove the shard even if it cannot be locked; this should only be used for cleanup operations.")
 	Root.AddCommand(DeleteShards)
 
+	FreezeShardWrites.Flags().StringVar(&freezeShardWritesOptions.Reason, "reason", "", "The reason the writes are frozen, returned to the clients whose writes are rejected.")
+	FreezeShardWrites.MarkFlagRequired("reason")
+	FreezeShardWrites.Flags().BoolVar(&freezeShardWritesOptions.Buffer, "buffer", false, "Make the vtgates buffer the writes, up to their buffering window, instead of failing them right away.")
+	Root.AddCommand(FreezeShardWrites)
+
 	Root.AddCommand(GetShard)
 	Root.AddCommand(GetShardReplication)
+	Root.AddCommand(GetShardWriteFreezeStatus)
 	Root.AddCommand(GenerateShardRanges)
 
+	PlanReshard.Flags().StringSliceVar(&planReshardOptions.SourceShards, "source-shards", nil, "The shards to split or merge, covering a contiguous key range. All the serving shards of the keyspace by default.")
+	PlanReshard.Flags().Int32Var(&planReshardOptions.ShardCount, "shard-count", 0, "The number of target shards.")
+	PlanReshard.MarkFlagRequired("shard-count")
+	PlanReshard.Flags().StringSliceVar(&planReshardOptions.Tables, "tables", nil, "The tables whose rows are sampled. All the tables with a primary vindex not needing a lookup by default.")
+	PlanReshard.Flags().Int64Var(&planReshardOptions.SampleSize, "sample-size", 1000, "The number of rows sampled from each table of each source shard.")
+	PlanReshard.Flags().Int32Var(&planReshardOptions.KeyBytes, "key-bytes", 1, "The number of bytes of the boundaries of the target shards.")
+	PlanReshard.Flags().Var((*topoproto.TabletTypeFlag)(&planReshardOptions.TabletType), "tablet-type", "The type of the tablets the rows are sampled from. The primary is used for the shards without a tablet of that type.")
+	Root.AddCommand(PlanReshard)
+
 	RemoveShardCell.Flags().BoolVarP(&removeShardCellOptions.Force, "force", "f", false, "Proceed even if the cell's topology server cannot be reached. The assumption is that you turned down the entire cell, and just need to update the global topo data.")
 	RemoveShardCell.Flags().BoolVarP(&removeShardCellOptions.Recursive, "recursive", "r", false, "Also delete all tablets in that cell beloning to the specified shard.")
 	Root.AddCommand(RemoveShardCell)
@@ -681,6 +862,7 @@ func init() {
 	Root.AddCommand(ShardReplicationFix)
 	Root.AddCommand(ShardReplicationPositions)
 	Root.AddCommand(ShardReplicationRemove)
+	Root.AddCommand(UnfreezeShardWrites)
 	Root.AddCommand(ValidateVersionShard)
 
 	SourceShardAdd.Flags().StringVar(&sourceShardAddOptions.KeyRangeStr, "key-range", "", "Key range to use for the SourceShard.")
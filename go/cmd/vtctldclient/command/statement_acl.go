@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	// ApplyStatementACL makes an ApplyStatementACL gRPC call to a vtctld.
+	ApplyStatementACL = &cobra.Command{
+		Use:   "ApplyStatementACL {--acl ACL | --acl-file ACL_FILE} [--dry-run]",
+		Short: "Applies the vtgate statement ACL.",
+		Long: `Applies the vtgate statement ACL.
+
+The statement ACL denies classes of statements ("ddl", "dml", "set" or "show")
+or specific SHOW targets to users or caller ID groups, for example:
+
+{"rules": [{"users": ["app"], "groups": ["readers"], "deny": ["ddl", "set"], "deny_show": ["processlist"]}]}
+
+vtgates started with --enable-statement-acl reload the ACL on change.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandApplyStatementACL,
+	}
+	// GetStatementACL makes a GetStatementACL gRPC call to a vtctld.
+	GetStatementACL = &cobra.Command{
+		Use:                   "GetStatementACL",
+		Short:                 "Displays the vtgate statement ACL.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetStatementACL,
+	}
+)
+
+var applyStatementACLOptions = struct {
+	ACL         string
+	ACLFilePath string
+	DryRun      bool
+}{}
+
+func commandApplyStatementACL(cmd *cobra.Command, args []string) error {
+	if applyStatementACLOptions.ACL != "" && applyStatementACLOptions.ACLFilePath != "" {
+		return fmt.Errorf("cannot pass both --acl (=%s) and --acl-file (=%s)", applyStatementACLOptions.ACL, applyStatementACLOptions.ACLFilePath)
+	}
+
+	if applyStatementACLOptions.ACL == "" && applyStatementACLOptions.ACLFilePath == "" {
+		return errors.New("must pass exactly one of --acl or --acl-file")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	var aclBytes []byte
+	if applyStatementACLOptions.ACLFilePath != "" {
+		data, err := os.ReadFile(applyStatementACLOptions.ACLFilePath)
+		if err != nil {
+			return err
+		}
+
+		aclBytes = data
+	} else {
+		aclBytes = []byte(applyStatementACLOptions.ACL)
+	}
+
+	acl := &vtgatepb.StatementACL{}
+	if err := json2.UnmarshalPB(aclBytes, acl); err != nil {
+		return err
+	}
+
+	// Round-trip so when we display the result it's readable.
+	data, err := cli.MarshalJSON(acl)
+	if err != nil {
+		return err
+	}
+
+	if applyStatementACLOptions.DryRun {
+		fmt.Printf("[DRY RUN] Would have saved new StatementACL object:\n%s\n", data)
+		return nil
+	}
+
+	_, err = client.ApplyStatementACL(commandCtx, &vtctldatapb.ApplyStatementACLRequest{
+		StatementAcl: acl,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("New StatementACL object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", data)
+
+	return nil
+}
+
+func commandGetStatementACL(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetStatementACL(commandCtx, &vtctldatapb.GetStatementACLRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.StatementAcl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	ApplyStatementACL.Flags().StringVar(&applyStatementACLOptions.ACL, "acl", "", "Statement ACL, specified as a string.")
+	ApplyStatementACL.Flags().StringVarP(&applyStatementACLOptions.ACLFilePath, "acl-file", "f", "", "Path to a file containing the statement ACL specified as JSON.")
+	ApplyStatementACL.Flags().BoolVarP(&applyStatementACLOptions.DryRun, "dry-run", "d", false, "Load the specified statement ACL as a validation step, but do not actually apply it to the topo.")
+	Root.AddCommand(ApplyStatementACL)
+
+	Root.AddCommand(GetStatementACL)
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/tablechecksum"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ChecksumTable makes a ChecksumTable gRPC call to a vtctld.
+	ChecksumTable = &cobra.Command{
+		Use:   "ChecksumTable --table <table> [--shards <shards>] [--tablet-type <type>] [--chunk-size <rows>] [--max-rows-per-second <rows>] <keyspace>",
+		Short: "Computes the checksums of the rows of a table, in chunks of primary key ranges.",
+		Long: `Computes the checksums of the rows of a table, in chunks of primary key ranges.
+
+The rows are read from a tablet of each shard, and the checksums of each chunk
+are summed across the shards. The chunks, their row counts and checksums are
+printed as JSON. Use CompareTable to compare two copies of a table.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandChecksumTable,
+	}
+	// CompareTable makes ChecksumTable gRPC calls to one or two vtctlds, and
+	// compares the checksums of two copies of a table.
+	CompareTable = &cobra.Command{
+		Use:   "CompareTable --table <table> [--target-keyspace <keyspace>] [--target-server <vtctld>] [--target-mysql-host <host>] [--report-file <path>] <source-keyspace>",
+		Short: "Compares the rows of a table between two keyspaces, two clusters, or a keyspace and an external MySQL, chunk by chunk.",
+		Long: `Compares the rows of a table between two keyspaces, two clusters, or a keyspace and an external MySQL, chunk by chunk.
+
+The source table is split into chunks of primary key ranges, whose checksums
+are compared with those of the same ranges of the target table. The target
+table is in the target keyspace of the vtctld of --target-server, or of the
+vtctld of --server by default, or in the database of the external MySQL of
+--target-mysql-host or --target-mysql-socket, with the password of the
+MYSQL_PWD environment variable. The target can also be other shards of the
+source keyspace, with --target-shards.
+
+Unlike VDiff, it does not need a workflow, nor to stop replication: the rows
+should not change during the comparison. The report, including the mismatching
+chunks, is printed as JSON and written to --report-file. The command fails if
+the tables differ.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandCompareTable,
+	}
+)
+
+var checksumTableOptions = struct {
+	Table            string
+	Shards           []string
+	TabletType       topodatapb.TabletType
+	ChunkSize        int64
+	MaxRowsPerSecond int64
+}{}
+
+func commandChecksumTable(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ChecksumTable(commandCtx, &vtctldatapb.ChecksumTableRequest{
+		Keyspace:         cmd.Flags().Arg(0),
+		Table:            checksumTableOptions.Table,
+		Shards:           checksumTableOptions.Shards,
+		TabletType:       checksumTableOptions.TabletType,
+		ChunkSize:        checksumTableOptions.ChunkSize,
+		MaxRowsPerSecond: checksumTableOptions.MaxRowsPerSecond,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var compareTableOptions = struct {
+	TargetKeyspace    string
+	TargetShards      []string
+	TargetServer      string
+	TargetTabletType  topodatapb.TabletType
+	TargetMySQLHost   string
+	TargetMySQLPort   int
+	TargetMySQLSocket string
+	TargetMySQLUser   string
+	TargetMySQLDB     string
+	ReportFile        string
+}{}
+
+// tableComparisonReport is the report of a CompareTable.
+type tableComparisonReport struct {
+	Table             string
+	Source            string
+	Target            string
+	Columns           []string
+	PrimaryKeyColumns []string
+	StartedAt         time.Time
+	CompletedAt       time.Time
+	Chunks            int
+	SourceRowCount    int64
+	TargetRowCount    int64
+	SourceChecksum    uint64
+	TargetChecksum    uint64
+	Consistent        bool
+	Mismatches        []tableChunkMismatch `json:",omitempty"`
+}
+
+// tableChunkMismatch is a chunk whose checksums differ between the source and
+// the target tables.
+type tableChunkMismatch struct {
+	Chunk int
+	// Start is the primary key of the first row of the chunk, and End the
+	// primary key of the first row of the next chunk. They are empty for the
+	// first and the last chunk.
+	Start          []string `json:",omitempty"`
+	End            []string `json:",omitempty"`
+	SourceRowCount int64
+	TargetRowCount int64
+	SourceChecksum uint64
+	TargetChecksum uint64
+}
+
+func commandCompareTable(cmd *cobra.Command, args []string) error {
+	sourceKeyspace := cmd.Flags().Arg(0)
+	targetKeyspace := compareTableOptions.TargetKeyspace
+	if targetKeyspace == "" {
+		targetKeyspace = sourceKeyspace
+	}
+	external := compareTableOptions.TargetMySQLHost != "" || compareTableOptions.TargetMySQLSocket != ""
+	if external && compareTableOptions.TargetServer != "" {
+		return fmt.Errorf("--target-server and --target-mysql-host/--target-mysql-socket are mutually exclusive")
+	}
+	if !external && compareTableOptions.TargetServer == "" && targetKeyspace == sourceKeyspace && len(compareTableOptions.TargetShards) == 0 {
+		return fmt.Errorf("the target must differ from the source: specify --target-keyspace, --target-shards, --target-server or --target-mysql-host")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	report := &tableComparisonReport{
+		Table:     checksumTableOptions.Table,
+		Source:    describeChecksumTarget(server, sourceKeyspace, checksumTableOptions.Shards),
+		StartedAt: time.Now().UTC(),
+	}
+
+	source, err := client.ChecksumTable(commandCtx, &vtctldatapb.ChecksumTableRequest{
+		Keyspace:         sourceKeyspace,
+		Table:            checksumTableOptions.Table,
+		Shards:           checksumTableOptions.Shards,
+		TabletType:       checksumTableOptions.TabletType,
+		ChunkSize:        checksumTableOptions.ChunkSize,
+		MaxRowsPerSecond: checksumTableOptions.MaxRowsPerSecond,
+	})
+	if err != nil {
+		return fmt.Errorf("checksumming the source table: %w", err)
+	}
+	report.Columns = source.Columns
+	report.PrimaryKeyColumns = source.PrimaryKeyColumns
+
+	var target *vtctldatapb.ChecksumTableResponse
+	if external {
+		address := fmt.Sprintf("%s:%d", compareTableOptions.TargetMySQLHost, compareTableOptions.TargetMySQLPort)
+		if compareTableOptions.TargetMySQLSocket != "" {
+			address = compareTableOptions.TargetMySQLSocket
+		}
+		report.Target = describeChecksumTarget("mysql://"+address, compareTableOptions.TargetMySQLDB, nil)
+		target, err = checksumExternalTable(commandCtx, source)
+	} else {
+		targetClient := client
+		targetServer := server
+		if compareTableOptions.TargetServer != "" {
+			targetServer = compareTableOptions.TargetServer
+			targetClient, err = vtctldclient.New(commandCtx, VtctldClientProtocol, targetServer)
+			if err != nil {
+				return err
+			}
+			defer targetClient.Close()
+		}
+		report.Target = describeChecksumTarget(targetServer, targetKeyspace, compareTableOptions.TargetShards)
+
+		tabletType := compareTableOptions.TargetTabletType
+		if tabletType == topodatapb.TabletType_UNKNOWN {
+			tabletType = checksumTableOptions.TabletType
+		}
+		target, err = targetClient.ChecksumTable(commandCtx, &vtctldatapb.ChecksumTableRequest{
+			Keyspace:         targetKeyspace,
+			Table:            checksumTableOptions.Table,
+			Shards:           compareTableOptions.TargetShards,
+			TabletType:       tabletType,
+			Boundaries:       source.Boundaries,
+			MaxRowsPerSecond: checksumTableOptions.MaxRowsPerSecond,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("checksumming the target table: %w", err)
+	}
+	if !slices.Equal(source.Columns, target.Columns) || !slices.Equal(source.PrimaryKeyColumns, target.PrimaryKeyColumns) {
+		return fmt.Errorf("the source table has columns %v and primary key %v, but the target table has columns %v and primary key %v",
+			source.Columns, source.PrimaryKeyColumns, target.Columns, target.PrimaryKeyColumns)
+	}
+
+	mismatches, err := tablechecksum.Compare(checksumChunksFromProto(source.Chunks), checksumChunksFromProto(target.Chunks))
+	if err != nil {
+		return err
+	}
+	report.CompletedAt = time.Now().UTC()
+	report.Chunks = len(source.Chunks)
+	report.SourceRowCount, report.TargetRowCount = source.RowCount, target.RowCount
+	report.SourceChecksum, report.TargetChecksum = source.Checksum, target.Checksum
+	report.Consistent = len(mismatches) == 0
+	for _, mismatch := range mismatches {
+		m := tableChunkMismatch{
+			Chunk:          mismatch.Chunk,
+			SourceRowCount: mismatch.Source.Rows,
+			TargetRowCount: mismatch.Target.Rows,
+			SourceChecksum: mismatch.Source.Checksum,
+			TargetChecksum: mismatch.Target.Checksum,
+		}
+		if mismatch.Chunk > 0 {
+			m.Start = checksumBoundaryStrings(source.Boundaries[mismatch.Chunk-1])
+		}
+		if mismatch.Chunk < len(source.Boundaries) {
+			m.End = checksumBoundaryStrings(source.Boundaries[mismatch.Chunk])
+		}
+		report.Mismatches = append(report.Mismatches, m)
+	}
+
+	data, err := cli.MarshalJSON(report)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+	if compareTableOptions.ReportFile != "" {
+		if err := os.WriteFile(compareTableOptions.ReportFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("writing the report: %w", err)
+		}
+	}
+
+	if !report.Consistent {
+		return fmt.Errorf("table %s differs between %s and %s in %d of %d chunks", report.Table, report.Source, report.Target, len(mismatches), report.Chunks)
+	}
+	return nil
+}
+
+// checksumExternalTable computes the checksums of the chunks of the source
+// table in the external MySQL.
+func checksumExternalTable(ctx context.Context, source *vtctldatapb.ChecksumTableResponse) (*vtctldatapb.ChecksumTableResponse, error) {
+	conn, err := mysql.Connect(ctx, &mysql.ConnParams{
+		Host:       compareTableOptions.TargetMySQLHost,
+		Port:       compareTableOptions.TargetMySQLPort,
+		UnixSocket: compareTableOptions.TargetMySQLSocket,
+		Uname:      compareTableOptions.TargetMySQLUser,
+		Pass:       os.Getenv("MYSQL_PWD"),
+		DbName:     compareTableOptions.TargetMySQLDB,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	table := tablechecksum.Table{
+		Name:              checksumTableOptions.Table,
+		Columns:           source.Columns,
+		PrimaryKeyColumns: source.PrimaryKeyColumns,
+	}
+	boundaries := make([]tablechecksum.Boundary, 0, len(source.Boundaries))
+	for _, boundary := range source.Boundaries {
+		values := make(tablechecksum.Boundary, 0, len(boundary.Values))
+		for _, value := range boundary.Values {
+			values = append(values, sqltypes.ProtoToValue(value))
+		}
+		boundaries = append(boundaries, values)
+	}
+	exec := func(ctx context.Context, query string) (*sqltypes.Result, error) {
+		return conn.ExecuteFetch(query, 1, true)
+	}
+	chunks, err := tablechecksum.Checksum(ctx, []tablechecksum.Executor{exec}, table, boundaries, checksumTableOptions.MaxRowsPerSecond)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.ChecksumTableResponse{
+		Columns:           source.Columns,
+		PrimaryKeyColumns: source.PrimaryKeyColumns,
+		Boundaries:        source.Boundaries,
+	}
+	for _, chunk := range chunks {
+		resp.Chunks = append(resp.Chunks, &vtctldatapb.TableChecksumChunk{RowCount: chunk.Rows, Checksum: chunk.Checksum})
+		resp.RowCount += chunk.Rows
+		resp.Checksum += chunk.Checksum
+	}
+	return resp, nil
+}
+
+func describeChecksumTarget(server string, keyspace string, shards []string) string {
+	desc := fmt.Sprintf("%s/%s", server, keyspace)
+	if len(shards) > 0 {
+		desc += fmt.Sprintf("[%s]", strings.Join(shards, ","))
+	}
+	return desc
+}
+
+func checksumChunksFromProto(chunks []*vtctldatapb.TableChecksumChunk) []tablechecksum.Chunk {
+	res := make([]tablechecksum.Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		res = append(res, tablechecksum.Chunk{Rows: chunk.RowCount, Checksum: chunk.Checksum})
+	}
+	return res
+}
+
+func checksumBoundaryStrings(boundary *vtctldatapb.TableChecksumBoundary) []string {
+	res := make([]string, 0, len(boundary.Values))
+	for _, value := range boundary.Values {
+		res = append(res, sqltypes.ProtoToValue(value).ToString())
+	}
+	return res
+}
+
+func addChecksumTableFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&checksumTableOptions.Table, "table", "", "The table to checksum. It must have a primary key.")
+	cmd.MarkFlagRequired("table")
+	cmd.Flags().StringSliceVar(&checksumTableOptions.Shards, "shards", nil, "The shards whose rows are checksummed. All the serving shards of the keyspace by default.")
+	cmd.Flags().Var((*topoproto.TabletTypeFlag)(&checksumTableOptions.TabletType), "tablet-type", "The type of the tablets the rows are read from. The primary is used for the shards without a tablet of that type. REPLICA by default.")
+	cmd.Flags().Int64Var(&checksumTableOptions.ChunkSize, "chunk-size", 10000, "The approximate number of rows of the chunks.")
+	cmd.Flags().Int64Var(&checksumTableOptions.MaxRowsPerSecond, "max-rows-per-second", 0, "The maximum number of rows read per second from each shard. Unlimited if 0.")
+}
+
+func init() {
+	addChecksumTableFlags(ChecksumTable)
+	Root.AddCommand(ChecksumTable)
+
+	addChecksumTableFlags(CompareTable)
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetKeyspace, "target-keyspace", "", "The keyspace of the target table. The source keyspace by default.")
+	CompareTable.Flags().StringSliceVar(&compareTableOptions.TargetShards, "target-shards", nil, "The shards of the target keyspace whose rows are compared. All its serving shards by default.")
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetServer, "target-server", "", "The vtctld of the cluster of the target keyspace. The vtctld of --server by default.")
+	CompareTable.Flags().Var((*topoproto.TabletTypeFlag)(&compareTableOptions.TargetTabletType), "target-tablet-type", "The type of the tablets the target rows are read from. The --tablet-type by default.")
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetMySQLHost, "target-mysql-host", "", "The host of the external MySQL holding the target table.")
+	CompareTable.Flags().IntVar(&compareTableOptions.TargetMySQLPort, "target-mysql-port", 3306, "The port of the external MySQL holding the target table.")
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetMySQLSocket, "target-mysql-socket", "", "The unix socket of the external MySQL holding the target table.")
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetMySQLUser, "target-mysql-user", "", "The user to connect to the external MySQL as.")
+	CompareTable.Flags().StringVar(&compareTableOptions.TargetMySQLDB, "target-mysql-database", "", "The database of the target table in the external MySQL.")
+	CompareTable.Flags().StringVar(&compareTableOptions.ReportFile, "report-file", "", "The file to write the JSON report of the comparison to.")
+	Root.AddCommand(CompareTable)
+}
@@ -99,7 +99,7 @@ Note: hook names may not contain slash (/) characters.
 	}
 	// GetTablets makes a GetTablets gRPC call to a vtctld.
 	GetTablets = &cobra.Command{
-		Use:   "GetTablets [--strict] [{--cell $c1 [--cell $c2 ...] [--tablet-type $t1] [--keyspace $ks [--shard $shard]], --tablet-alias $alias}]",
+		Use:   "GetTablets [--strict] [--tag $key=$value ...] [{--cell $c1 [--cell $c2 ...] [--tablet-type $t1] [--keyspace $ks [--shard $shard]], --tablet-alias $alias}]",
 		Short: "Looks up tablets according to filter criteria.",
 		Long: fmt.Sprintf(`Looks up tablets according to the filter criteria.
 
@@ -118,6 +118,10 @@ Passing --cell limits the set of tablets to those in the specified cells. The
 --cell flag accepts a CSV argument (e.g. --cell "c1,c2") and may be repeated
 (e.g. --cell "c1" --cell "c2").
 
+Passing --tag limits the set of tablets to those carrying all of the given
+tags. The --tag flag accepts key=value pairs and may be repeated (e.g.
+--tag role=reporting --tag team=analytics).
+
 Valid output formats are "awk" and "json".`, strings.Join(topoproto.MakeUniqueStringTypeList(topoproto.AllTabletTypes), "\", \"")),
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.NoArgs,
@@ -390,6 +394,8 @@ var getTabletsOptions = struct {
 
 	TabletAliasStrings []string
 
+	Tags map[string]string
+
 	Format string
 	Strict bool
 }{}
@@ -437,6 +443,7 @@ func commandGetTablets(cmd *cobra.Command, args []string) error {
 		Keyspace:      getTabletsOptions.Keyspace,
 		Shard:         getTabletsOptions.Shard,
 		Strict:        getTabletsOptions.Strict,
+		Tags:          getTabletsOptions.Tags,
 	})
 	if err != nil {
 		return err
@@ -645,6 +652,7 @@ func init() {
 	GetTablets.Flags().Var((*topoproto.TabletTypeFlag)(&getTabletsOptions.TabletType), "tablet-type", "Tablet type to filter by (e.g. primary or replica).")
 	GetTablets.Flags().StringVarP(&getTabletsOptions.Keyspace, "keyspace", "k", "", "Keyspace to filter tablets by.")
 	GetTablets.Flags().StringVarP(&getTabletsOptions.Shard, "shard", "s", "", "Shard to filter tablets by.")
+	GetTablets.Flags().StringToStringVar(&getTabletsOptions.Tags, "tag", nil, "Tablet tag (as key=value) to filter tablets by. May be repeated, in which case tablets must carry all of the given tags.")
 	GetTablets.Flags().StringVar(&getTabletsOptions.Format, "format", "awk", "Output format to use; valid choices are (json, awk).")
 	GetTablets.Flags().BoolVar(&getTabletsOptions.Strict, "strict", false, "Require all cells to return successful tablet data. Without --strict, tablet listings may be partial.")
 	Root.AddCommand(GetTablets)
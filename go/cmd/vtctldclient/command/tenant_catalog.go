@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	// ApplyTenantCatalog makes an ApplyTenantCatalog gRPC call to a vtctld.
+	ApplyTenantCatalog = &cobra.Command{
+		Use:   "ApplyTenantCatalog {--catalog CATALOG | --catalog-file CATALOG_FILE} [--dry-run]",
+		Short: "Applies the vtgate tenant catalog.",
+		Long: `Applies the vtgate tenant catalog.
+
+The tenant catalog maps the databases selected by clients to the tenants of
+multi-tenant keyspaces, whose vschema sets the tenant id column with a
+multi_tenant_spec, for example:
+
+{"tenants": {"customer1": {"keyspace": "customers", "tenant_id": "1"}}}
+
+vtgates started with --enable-tenant-catalog reload the catalog on change. The
+statements of the sessions using the database of a tenant are routed to the
+keyspace of the tenant and restricted to its rows.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandApplyTenantCatalog,
+	}
+	// GetTenantCatalog makes a GetTenantCatalog gRPC call to a vtctld.
+	GetTenantCatalog = &cobra.Command{
+		Use:                   "GetTenantCatalog",
+		Short:                 "Displays the vtgate tenant catalog.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetTenantCatalog,
+	}
+)
+
+var applyTenantCatalogOptions = struct {
+	Catalog         string
+	CatalogFilePath string
+	DryRun          bool
+}{}
+
+func commandApplyTenantCatalog(cmd *cobra.Command, args []string) error {
+	if applyTenantCatalogOptions.Catalog != "" && applyTenantCatalogOptions.CatalogFilePath != "" {
+		return fmt.Errorf("cannot pass both --catalog (=%s) and --catalog-file (=%s)", applyTenantCatalogOptions.Catalog, applyTenantCatalogOptions.CatalogFilePath)
+	}
+
+	if applyTenantCatalogOptions.Catalog == "" && applyTenantCatalogOptions.CatalogFilePath == "" {
+		return errors.New("must pass exactly one of --catalog or --catalog-file")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	var catalogBytes []byte
+	if applyTenantCatalogOptions.CatalogFilePath != "" {
+		data, err := os.ReadFile(applyTenantCatalogOptions.CatalogFilePath)
+		if err != nil {
+			return err
+		}
+
+		catalogBytes = data
+	} else {
+		catalogBytes = []byte(applyTenantCatalogOptions.Catalog)
+	}
+
+	catalog := &vtgatepb.TenantCatalog{}
+	if err := json2.UnmarshalPB(catalogBytes, catalog); err != nil {
+		return err
+	}
+
+	// Round-trip so when we display the result it's readable.
+	data, err := cli.MarshalJSON(catalog)
+	if err != nil {
+		return err
+	}
+
+	if applyTenantCatalogOptions.DryRun {
+		fmt.Printf("[DRY RUN] Would have saved new TenantCatalog object:\n%s\n", data)
+		return nil
+	}
+
+	_, err = client.ApplyTenantCatalog(commandCtx, &vtctldatapb.ApplyTenantCatalogRequest{
+		TenantCatalog: catalog,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("New TenantCatalog object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", data)
+
+	return nil
+}
+
+func commandGetTenantCatalog(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetTenantCatalog(commandCtx, &vtctldatapb.GetTenantCatalogRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.TenantCatalog)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	ApplyTenantCatalog.Flags().StringVar(&applyTenantCatalogOptions.Catalog, "catalog", "", "Tenant catalog, specified as a string.")
+	ApplyTenantCatalog.Flags().StringVarP(&applyTenantCatalogOptions.CatalogFilePath, "catalog-file", "f", "", "Path to a file containing the tenant catalog specified as JSON.")
+	ApplyTenantCatalog.Flags().BoolVarP(&applyTenantCatalogOptions.DryRun, "dry-run", "d", false, "Load the specified tenant catalog as a validation step, but do not actually apply it to the topo.")
+	Root.AddCommand(ApplyTenantCatalog)
+
+	Root.AddCommand(GetTenantCatalog)
+}
@@ -18,6 +18,7 @@ package command
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 
@@ -29,8 +30,19 @@ import (
 var (
 	// GetTopologyPath makes a GetTopologyPath gRPC call to a vtctld.
 	GetTopologyPath = &cobra.Command{
-		Use:                   "GetTopologyPath <path>",
-		Short:                 "Gets the value associated with the particular path (key) in the topology server.",
+		Use:   "GetTopologyPath [--version <version>] [--data-as-json] [--follow] <path>",
+		Short: "Gets the value associated with the particular path (key) in the topology server.",
+		Long: `Gets the value associated with the particular path (key) in the topology server.
+
+If the path is a directory, its children are listed. Files of known types, such as
+/global/keyspaces/<keyspace>/Keyspace or /<cell>/tablets/<alias>/Tablet, are decoded
+into the corresponding protobuf message.
+
+With --follow, the value of the file is output again every time it changes, until the
+file is deleted or the command is interrupted.`,
+		Example: `GetTopologyPath /global/keyspaces
+GetTopologyPath --data-as-json /global/keyspaces/commerce/VSchema
+GetTopologyPath --follow /zone1/SrvVSchema`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetTopologyPath,
@@ -41,13 +53,23 @@ var (
 	version int64 = 0
 	// If true, only the data is output and it is in JSON format rather than prototext.
 	dataAsJSON bool = false
+	// If true, the value is output again every time it changes.
+	follow bool = false
 )
 
 func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 	path := cmd.Flags().Arg(0)
 
+	if follow && version != 0 {
+		return fmt.Errorf("--follow cannot be used with --version")
+	}
+
 	cli.FinishedParsing(cmd)
 
+	if follow {
+		return watchTopologyPath(path)
+	}
+
 	resp, err := client.GetTopologyPath(commandCtx, &vtctldatapb.GetTopologyPathRequest{
 		Path:    path,
 		Version: version,
@@ -57,15 +79,43 @@ func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	return printTopologyCell(path, resp.GetCell())
+}
+
+func watchTopologyPath(path string) error {
+	stream, err := client.WatchTopologyPath(commandCtx, &vtctldatapb.WatchTopologyPathRequest{
+		Path:   path,
+		AsJson: dataAsJSON,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		switch err {
+		case nil:
+			if err := printTopologyCell(path, resp.GetCell()); err != nil {
+				return err
+			}
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+func printTopologyCell(path string, cell *vtctldatapb.TopologyCell) error {
 	if dataAsJSON {
-		if resp.GetCell() == nil || resp.GetCell().GetData() == "" {
+		if cell == nil || cell.GetData() == "" {
 			return fmt.Errorf("no data found for path %s", path)
 		}
-		fmt.Println(resp.GetCell().GetData())
+		fmt.Println(cell.GetData())
 		return nil
 	}
 
-	data, err := cli.MarshalJSONPretty(resp.GetCell())
+	data, err := cli.MarshalJSONPretty(cell)
 	if err != nil {
 		return err
 	}
@@ -78,5 +128,6 @@ func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 func init() {
 	GetTopologyPath.Flags().Int64Var(&version, "version", version, "The version of the path's key to get. If not specified, the latest version is returned.")
 	GetTopologyPath.Flags().BoolVar(&dataAsJSON, "data-as-json", dataAsJSON, "If true, only the data is output and it is in JSON format rather than prototext.")
+	GetTopologyPath.Flags().BoolVar(&follow, "follow", follow, "If true, output the value of the file again every time it changes, until it is deleted or the command is interrupted.")
 	Root.AddCommand(GetTopologyPath)
 }
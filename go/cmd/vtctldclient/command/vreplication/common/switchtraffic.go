@@ -99,6 +99,7 @@ func commandSwitchTraffic(cmd *cobra.Command, args []string) error {
 		EnableReverseReplication:  SwitchTrafficOptions.EnableReverseReplication,
 		InitializeTargetSequences: SwitchTrafficOptions.InitializeTargetSequences,
 		Direction:                 int32(SwitchTrafficOptions.Direction),
+		Tables:                    SwitchTrafficOptions.Tables,
 	}
 	resp, err := GetClient().WorkflowSwitchTraffic(GetCommandCtx(), req)
 	if err != nil {
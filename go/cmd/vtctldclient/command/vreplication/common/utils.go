@@ -234,6 +234,7 @@ var SwitchTrafficOptions = struct {
 	Direction                 workflow.TrafficSwitchDirection
 	InitializeTargetSequences bool
 	Shards                    []string
+	Tables                    []string
 }{}
 
 func AddCommonSwitchTrafficFlags(cmd *cobra.Command, initializeTargetSequences bool) {
@@ -18,12 +18,16 @@ package lookupvindex
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+	"vitess.io/vitess/go/protoutil"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
@@ -74,6 +78,25 @@ var (
 		Keyspace string
 	}{}
 
+	completeOptions = struct {
+		Keyspace string
+	}{}
+
+	revertOptions = struct {
+		Keyspace string
+		KeepData bool
+	}{}
+
+	statusOptions = struct {
+		Keyspace string
+	}{}
+
+	verifyOptions = struct {
+		FilteredReplicationWaitTime time.Duration
+		MaxExtraRowsToCompare       int64
+		MaxReportSampleRows         int64
+	}{}
+
 	parseAndValidateCreate = func(cmd *cobra.Command, args []string) error {
 		if createOptions.TableName == "" { // Use vindex name
 			createOptions.TableName = baseOptions.Name
@@ -142,6 +165,18 @@ var (
 		RunE:                  commandCancel,
 	}
 
+	// complete makes a LookupVindexComplete call to a vtctld.
+	complete = &cobra.Command{
+		Use:                   "complete",
+		Short:                 "Externalize the Lookup Vindex once the last VDiff of the VReplication workflow which backfilled it completed without any mismatch.",
+		Example:               `vtctldclient --server localhost:15999 LookupVindex --name corder_lookup_vdx --table-keyspace customer complete --keyspace customer`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Complete"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandComplete,
+	}
+
 	// create makes a LookupVindexCreate call to a vtctld.
 	create = &cobra.Command{
 		Use:                   "create",
@@ -167,6 +202,18 @@ var (
 		RunE:                  commandExternalize,
 	}
 
+	// revert makes a LookupVindexRevert call to a vtctld.
+	revert = &cobra.Command{
+		Use:                   "revert",
+		Short:                 "Delete the VReplication workflow, the lookup table and the Lookup Vindex which has not been externalized yet.",
+		Example:               `vtctldclient --server localhost:15999 LookupVindex --name corder_lookup_vdx --table-keyspace customer revert --keyspace customer`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Revert"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandRevert,
+	}
+
 	// show makes a GetWorkflows call to a vtctld.
 	show = &cobra.Command{
 		Use:                   "show",
@@ -178,6 +225,30 @@ var (
 		Args:                  cobra.NoArgs,
 		RunE:                  commandShow,
 	}
+
+	// status makes a LookupVindexStatus call to a vtctld.
+	status = &cobra.Command{
+		Use:                   "status",
+		Short:                 "Show the phase of the Lookup Vindex: backfilling, backfilled, verifying, verified, verification-failed or completed.",
+		Example:               `vtctldclient --server localhost:15999 LookupVindex --name corder_lookup_vdx --table-keyspace customer status --keyspace customer`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Status"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandStatus,
+	}
+
+	// verify makes a VDiffCreate call to a vtctld.
+	verify = &cobra.Command{
+		Use:                   "verify",
+		Short:                 "Start a VDiff of the VReplication workflow to verify the contents of the lookup table once it has been backfilled.",
+		Example:               `vtctldclient --server localhost:15999 LookupVindex --name corder_lookup_vdx --table-keyspace customer verify`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Verify"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandVerify,
+	}
 )
 
 func commandCancel(cmd *cobra.Command, args []string) error {
@@ -199,6 +270,30 @@ func commandCancel(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandComplete(cmd *cobra.Command, args []string) error {
+	if completeOptions.Keyspace == "" {
+		completeOptions.Keyspace = baseOptions.TableKeyspace
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().LookupVindexComplete(common.GetCommandCtx(), &vtctldatapb.LookupVindexCompleteRequest{
+		Keyspace:      completeOptions.Keyspace,
+		Name:          baseOptions.Name,
+		TableKeyspace: baseOptions.TableKeyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := fmt.Sprintf("LookupVindex %s has been verified and externalized", baseOptions.Name)
+	if resp.WorkflowDeleted {
+		output = output + fmt.Sprintf(" and the %s VReplication workflow has been deleted", baseOptions.Name)
+	}
+	fmt.Println(output)
+
+	return nil
+}
+
 func commandCreate(cmd *cobra.Command, args []string) error {
 	tsp := common.GetTabletSelectionPreference(cmd)
 	cli.FinishedParsing(cmd)
@@ -251,6 +346,31 @@ func commandExternalize(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandRevert(cmd *cobra.Command, args []string) error {
+	if revertOptions.Keyspace == "" {
+		revertOptions.Keyspace = baseOptions.TableKeyspace
+	}
+	cli.FinishedParsing(cmd)
+
+	_, err := common.GetClient().LookupVindexRevert(common.GetCommandCtx(), &vtctldatapb.LookupVindexRevertRequest{
+		Keyspace:      revertOptions.Keyspace,
+		Name:          baseOptions.Name,
+		TableKeyspace: baseOptions.TableKeyspace,
+		KeepData:      revertOptions.KeepData,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := fmt.Sprintf("LookupVindex %s and the %s VReplication workflow have been deleted", baseOptions.Name, baseOptions.Name)
+	if revertOptions.KeepData {
+		output = output + ", the lookup table has been left in place"
+	}
+	fmt.Println(output)
+
+	return nil
+}
+
 func commandShow(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -273,6 +393,55 @@ func commandShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandStatus(cmd *cobra.Command, args []string) error {
+	if statusOptions.Keyspace == "" {
+		statusOptions.Keyspace = baseOptions.TableKeyspace
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().LookupVindexStatus(common.GetCommandCtx(), &vtctldatapb.LookupVindexStatusRequest{
+		Keyspace:      statusOptions.Keyspace,
+		Name:          baseOptions.Name,
+		TableKeyspace: baseOptions.TableKeyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandVerify(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().VDiffCreate(common.GetCommandCtx(), &vtctldatapb.VDiffCreateRequest{
+		Workflow:                    baseOptions.Name,
+		TargetKeyspace:              baseOptions.TableKeyspace,
+		Uuid:                        uuid.New().String(),
+		Limit:                       math.MaxInt64,
+		FilteredReplicationWaitTime: protoutil.DurationToProto(verifyOptions.FilteredReplicationWaitTime),
+		MaxExtraRowsToCompare:       verifyOptions.MaxExtraRowsToCompare,
+		MaxReportSampleRows:         verifyOptions.MaxReportSampleRows,
+		AutoRetry:                   true,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := fmt.Sprintf("VDiff %s of the %s VReplication workflow has been scheduled, use status to view progress",
+		resp.UUID, baseOptions.Name)
+	fmt.Println(output)
+
+	return nil
+}
+
 func registerCommands(root *cobra.Command) {
 	base.PersistentFlags().StringVar(&baseOptions.Name, "name", "", "The name of the Lookup Vindex to create. This will also be the name of the VReplication workflow created to backfill the Lookup Vindex.")
 	base.MarkPersistentFlagRequired("name")
@@ -310,6 +479,27 @@ func registerCommands(root *cobra.Command) {
 	externalize.Flags().StringVar(&externalizeOptions.Keyspace, "keyspace", "", "The keyspace containing the Lookup Vindex. If no value is specified then the table-keyspace will be used.")
 	base.AddCommand(externalize)
 
+	// The verify command starts a VDiff of the VReplication
+	// workflow, whose result is checked by complete.
+	verify.Flags().DurationVar(&verifyOptions.FilteredReplicationWaitTime, "filtered-replication-wait-time", 30*time.Second, "Specifies the maximum time to wait, in seconds, for replication to catch up when syncing tablet streams.")
+	verify.Flags().Int64Var(&verifyOptions.MaxExtraRowsToCompare, "max-extra-rows-to-compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation.")
+	verify.Flags().Int64Var(&verifyOptions.MaxReportSampleRows, "max-report-sample-rows", 10, "Maximum number of row differences to report (0 for all differences).")
+	base.AddCommand(verify)
+
+	status.Flags().StringVar(&statusOptions.Keyspace, "keyspace", "", "The keyspace containing the Lookup Vindex. If no value is specified then the table-keyspace will be used.")
+	base.AddCommand(status)
+
+	// This externalizes the vindex like externalize does, but
+	// only once the lookup table has been verified.
+	complete.Flags().StringVar(&completeOptions.Keyspace, "keyspace", "", "The keyspace containing the Lookup Vindex. If no value is specified then the table-keyspace will be used.")
+	base.AddCommand(complete)
+
+	// The revert command undoes everything create did as long
+	// as the vindex has not been externalized.
+	revert.Flags().StringVar(&revertOptions.Keyspace, "keyspace", "", "The keyspace containing the Lookup Vindex. If no value is specified then the table-keyspace will be used.")
+	revert.Flags().BoolVar(&revertOptions.KeepData, "keep-data", false, "Keep the lookup table instead of dropping it.")
+	base.AddCommand(revert)
+
 	// The cancel command deletes the VReplication workflow used
 	// to backfill the lookup vindex. It ends up making a
 	// WorkflowDelete VtctldServer call.
@@ -50,6 +50,7 @@ func registerCommands(root *cobra.Command) {
 	create.Flags().StringVar(&createOptions.WorkflowOptions.TenantId, "tenant-id", "", "(EXPERIMENTAL: Multi-tenant migrations only) The tenant ID to use for the MoveTables workflow into a multi-tenant keyspace.")
 	create.Flags().BoolVar(&createOptions.WorkflowOptions.StripShardedAutoIncrement, "remove-sharded-auto-increment", true, "If moving the table(s) to a sharded keyspace, remove any auto_increment clauses when copying the schema to the target as sharded keyspaces should rely on either user/application generated values or Vitess sequences to ensure uniqueness.")
 	create.Flags().StringSliceVar(&createOptions.WorkflowOptions.Shards, "shards", nil, "(EXPERIMENTAL: Multi-tenant migrations only) Specify that vreplication streams should only be created on this subset of target shards. Warning: you should first ensure that all rows on the source route to the specified subset of target shards using your VIndex of choice or you could lose data during the migration.")
+	create.Flags().StringVar(&createOptions.WorkflowOptions.TabletPickerPreferences, "tablet-picker-preferences", "", "Ordered list of <tablet_type>@<cells> preferences for the source tablets of the workflow, where <cells> is local, alias, any or a cell name, e.g. rdonly@local,replica@local,replica@any. It overrides --tablet-types and --tablet-types-in-preference-order.")
 	base.AddCommand(create)
 
 	opts := &common.SubCommandsOpts{
@@ -70,6 +71,7 @@ func registerCommands(root *cobra.Command) {
 	switchTrafficCommand := common.GetSwitchTrafficCommand(opts)
 	common.AddCommonSwitchTrafficFlags(switchTrafficCommand, true)
 	common.AddShardSubsetFlag(switchTrafficCommand, &common.SwitchTrafficOptions.Shards)
+	switchTrafficCommand.Flags().StringSliceVar(&common.SwitchTrafficOptions.Tables, "tables", nil, "(Optional) Specifies a comma-separated list of tables whose reads and writes are switched, leaving the other tables of the workflow where they are. Run SwitchTraffic again without it to switch the remaining tables.")
 	base.AddCommand(switchTrafficCommand)
 
 	reverseTrafficCommand := common.GetReverseTrafficCommand(opts)
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package referencetables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// base is the base command for all actions related to ReferenceTables.
+	base = &cobra.Command{
+		Use:                   "ReferenceTables --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to replicating the reference tables of a keyspace from an unsharded keyspace to all of its shards.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"referencetables"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	createOptions = struct {
+		SourceKeyspace string
+		Tables         []string
+	}{}
+
+	// create makes a ReferenceTablesCreate gRPC call to a vtctld.
+	create = &cobra.Command{
+		Use:   "create",
+		Short: "Create and run a VReplication workflow replicating reference tables to every shard of the target keyspace.",
+		Example: `vtctldclient --server localhost:15999 ReferenceTables --workflow ref --target-keyspace customer create --source-keyspace commerce
+vtctldclient --server localhost:15999 ReferenceTables --workflow ref --target-keyspace customer create --source-keyspace commerce --tables countries,currencies`,
+		Long: `Create a Materialize workflow copying the reference tables declared in the VSchema of the target keyspace
+from the unsharded source keyspace, and keeping them in sync on every shard of the target keyspace. The tables
+must be declared with type "reference" and a source in the source keyspace, such as "commerce.countries".
+By default all such tables are replicated. The schema of the tables is copied from the source keyspace.`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return common.ParseAndValidateCreateOptions(cmd)
+		},
+		RunE: commandCreate,
+	}
+
+	// check makes a ReferenceTablesCheck gRPC call to a vtctld.
+	check = &cobra.Command{
+		Use:     "check",
+		Short:   "Check that the workflow replicates all the reference tables declared in the VSchema of the target keyspace.",
+		Example: `vtctldclient --server localhost:15999 ReferenceTables --workflow ref --target-keyspace customer check`,
+		Long: `Report the shards of the target keyspace where the workflow does not replicate a reference table declared in
+the VSchema, replicates a table which is no longer declared, or is not running. Use VDiff to compare the data of
+the tables with the source.`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Check"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandCheck,
+	}
+)
+
+func commandCreate(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	tsp := common.GetTabletSelectionPreference(cmd)
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().ReferenceTablesCreate(common.GetCommandCtx(), &vtctldatapb.ReferenceTablesCreateRequest{
+		Workflow:                  common.BaseOptions.Workflow,
+		TargetKeyspace:            common.BaseOptions.TargetKeyspace,
+		SourceKeyspace:            createOptions.SourceKeyspace,
+		Tables:                    createOptions.Tables,
+		Cells:                     common.CreateOptions.Cells,
+		TabletTypes:               common.CreateOptions.TabletTypes,
+		TabletSelectionPreference: tsp,
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := cli.MarshalJSONPretty(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+	} else {
+		fmt.Printf("Reference tables workflow %s successfully created in the %s keyspace for tables %s. Use show to view the status.\n",
+			common.BaseOptions.Workflow, common.BaseOptions.TargetKeyspace, strings.Join(resp.Tables, ", "))
+	}
+
+	return nil
+}
+
+func commandCheck(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().ReferenceTablesCheck(common.GetCommandCtx(), &vtctldatapb.ReferenceTablesCheckRequest{
+		Workflow:       common.BaseOptions.Workflow,
+		TargetKeyspace: common.BaseOptions.TargetKeyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := cli.MarshalJSONPretty(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	if len(resp.Drifts) == 0 {
+		fmt.Printf("Reference tables workflow %s replicates all the reference tables of the %s keyspace.\n",
+			common.BaseOptions.Workflow, common.BaseOptions.TargetKeyspace)
+		return nil
+	}
+	for _, drift := range resp.Drifts {
+		if drift.Table == "" {
+			fmt.Printf("%s/%s: %s\n", common.BaseOptions.TargetKeyspace, drift.Shard, drift.Message)
+		} else {
+			fmt.Printf("%s/%s: %s: %s\n", common.BaseOptions.TargetKeyspace, drift.Shard, drift.Table, drift.Message)
+		}
+	}
+	return fmt.Errorf("reference tables workflow %s has drifted", common.BaseOptions.Workflow)
+}
+
+func registerCommands(root *cobra.Command) {
+	common.AddCommonFlags(base)
+	root.AddCommand(base)
+
+	create.Flags().StringVar(&createOptions.SourceKeyspace, "source-keyspace", "", "Unsharded keyspace the reference tables are replicated from.")
+	create.MarkFlagRequired("source-keyspace")
+	create.Flags().StringSliceVar(&createOptions.Tables, "tables", nil, "Reference tables to replicate. Defaults to all the reference tables of the target keyspace with a source in the source keyspace.")
+	create.Flags().StringSliceVarP(&common.CreateOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to copy table data from.")
+	create.Flags().Var((*topoproto.TabletTypeListFlag)(&common.CreateOptions.TabletTypes), "tablet-types", "Source tablet types to replicate table data from (e.g. PRIMARY,REPLICA,RDONLY).")
+	create.Flags().BoolVar(&common.CreateOptions.TabletTypesInPreferenceOrder, "tablet-types-in-preference-order", true, "When performing source tablet selection, look for candidates in the type order as they are listed in the tablet-types flag.")
+	base.AddCommand(create)
+
+	base.AddCommand(check)
+
+	// Generic workflow commands.
+	opts := &common.SubCommandsOpts{
+		SubCommand: "ReferenceTables",
+		Workflow:   "ref",
+	}
+	base.AddCommand(common.GetCancelCommand(opts))
+	base.AddCommand(common.GetShowCommand(opts))
+	base.AddCommand(common.GetStartCommand(opts))
+	base.AddCommand(common.GetStopCommand(opts))
+}
+
+func init() {
+	common.RegisterCommandHandler("ReferenceTables", registerCommands)
+}
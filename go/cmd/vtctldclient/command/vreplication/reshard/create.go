@@ -27,15 +27,29 @@ import (
 
 var (
 	reshardCreateOptions = struct {
-		sourceShards   []string
-		targetShards   []string
-		skipSchemaCopy bool
+		sourceShards        []string
+		targetShards        []string
+		skipSchemaCopy      bool
+		skipMergeValidation bool
 	}{}
 
 	// reshardCreate makes a ReshardCreate gRPC call to a vtctld.
 	reshardCreate = &cobra.Command{
-		Use:                   "create",
-		Short:                 "Create and optionally run a Reshard VReplication workflow.",
+		Use:   "create",
+		Short: "Create and optionally run a Reshard VReplication workflow.",
+		Long: `Create and optionally run a Reshard VReplication workflow, which splits the source shards into the target shards
+or merges them. Each target shard replicates the rows of its key range from every source shard it overlaps.
+
+When shards are merged, the rows of several source shards are copied to the same target shard, where they must not
+have the same primary key. The workflow is only created if the primary key of every table includes the columns of its
+primary vindex, is generated by a sequence, or has values whose ranges do not overlap across the merged shards, unless
+they are interleaved by the same auto_increment_increment and distinct auto_increment_offset. Use
+--skip-merge-validation to skip these checks.
+
+The progress of the copy is reported by "reshard show" and "reshard status" for each stream, from one source shard to
+one target shard. Once the target shards are in sync, "reshard switchtraffic" switches the reads and then the writes
+to them, all at once for the merged shards, and creates a reverse workflow splitting the writes back to the source
+shards until "reshard complete".`,
 		Example:               `vtctldclient --server localhost:15999 reshard --workflow customer2customer --target-keyspace customer create --source-shards="0" --target-shards="-80,80-" --cells zone1 --cells zone2 --tablet-types replica`,
 		SilenceUsage:          true,
 		DisableFlagsInUseLine: true,
@@ -72,6 +86,7 @@ func commandReshardCreate(cmd *cobra.Command, args []string) error {
 		SourceShards:              reshardCreateOptions.sourceShards,
 		TargetShards:              reshardCreateOptions.targetShards,
 		SkipSchemaCopy:            reshardCreateOptions.skipSchemaCopy,
+		SkipMergeValidation:       reshardCreateOptions.skipMergeValidation,
 	}
 	resp, err := common.GetClient().ReshardCreate(common.GetCommandCtx(), req)
 	if err != nil {
@@ -88,5 +103,6 @@ func registerCreateCommand(root *cobra.Command) {
 	reshardCreate.Flags().StringSliceVar(&reshardCreateOptions.sourceShards, "source-shards", nil, "Source shards.")
 	reshardCreate.Flags().StringSliceVar(&reshardCreateOptions.targetShards, "target-shards", nil, "Target shards.")
 	reshardCreate.Flags().BoolVar(&reshardCreateOptions.skipSchemaCopy, "skip-schema-copy", false, "Skip copying the schema from the source shards to the target shards.")
+	reshardCreate.Flags().BoolVar(&reshardCreateOptions.skipMergeValidation, "skip-merge-validation", false, "Skip checking that the rows of the merged source shards cannot have the same primary key.")
 	root.AddCommand(reshardCreate)
 }
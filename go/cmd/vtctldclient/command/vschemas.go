@@ -19,6 +19,7 @@ package command
 import (
 	"fmt"
 	"os"
+	"os/user"
 
 	"github.com/spf13/cobra"
 
@@ -40,12 +41,41 @@ var (
 	}
 	// ApplyVSchema makes an ApplyVSchema gRPC call to a vtctld.
 	ApplyVSchema = &cobra.Command{
-		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run] [--strict] <keyspace>",
+		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--staged] [--dry-run] [--strict] [--comment=<comment>] <keyspace>",
 		Short:                 "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandApplyVSchema,
 	}
+	// DiffVSchemaVersions makes a DiffVSchemaVersions gRPC call to a vtctld.
+	DiffVSchemaVersions = &cobra.Command{
+		Use:                   "DiffVSchemaVersions --from <version> [--to <version>] <keyspace>",
+		Short:                 "Shows the changes between two versions of the vschema history of a keyspace, or between a version and the current vschema.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDiffVSchemaVersions,
+	}
+	// GetVSchemaHistory makes a GetVSchemaHistory gRPC call to a vtctld.
+	GetVSchemaHistory = &cobra.Command{
+		Use:   "GetVSchemaHistory <keyspace>",
+		Short: "Prints a JSON representation of the vschema history of a keyspace.",
+		Long: `Prints a JSON representation of the vschema history of a keyspace.
+
+Each ApplyVSchema and RollbackVSchema records the vschema it saves as a new
+version of the history, with its author, comment and time. The oldest versions
+are pruned once the history holds 100 versions.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetVSchemaHistory,
+	}
+	// RollbackVSchema makes a RollbackVSchema gRPC call to a vtctld.
+	RollbackVSchema = &cobra.Command{
+		Use:                   "RollbackVSchema --version <version> [--comment <comment>] [--author <author>] [--cells=c1,c2,...] [--skip-rebuild] <keyspace>",
+		Short:                 "Restores a version of the vschema history of a keyspace, recording it as a new version. Shows the changes it made.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRollbackVSchema,
+	}
 )
 
 var applyVSchemaOptions = struct {
@@ -57,8 +87,23 @@ var applyVSchemaOptions = struct {
 	SkipRebuild bool
 	Cells       []string
 	Strict      bool
+	Staged      bool
+	Author      string
+	Comment     string
 }{}
 
+// vschemaChangeAuthor returns the author of a vschema change recorded in the
+// vschema history: the given one, or the current OS user.
+func vschemaChangeAuthor(author string) string {
+	if author != "" {
+		return author
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
 func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 	sqlMode := (applyVSchemaOptions.SQL != "") != (applyVSchemaOptions.SQLFile != "")
 	jsonMode := (applyVSchemaOptions.VSchema != "") != (applyVSchemaOptions.VSchemaFile != "")
@@ -77,6 +122,9 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 		Cells:       applyVSchemaOptions.Cells,
 		DryRun:      applyVSchemaOptions.DryRun,
 		Strict:      applyVSchemaOptions.Strict,
+		Staged:      applyVSchemaOptions.Staged,
+		Author:      vschemaChangeAuthor(applyVSchemaOptions.Author),
+		Comment:     applyVSchemaOptions.Comment,
 	}
 
 	var err error
@@ -128,6 +176,31 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var diffVSchemaVersionsOptions = struct {
+	From int64
+	To   int64
+}{}
+
+func commandDiffVSchemaVersions(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.DiffVSchemaVersions(commandCtx, &vtctldatapb.DiffVSchemaVersionsRequest{
+		Keyspace:    cmd.Flags().Arg(0),
+		FromVersion: diffVSchemaVersionsOptions.From,
+		ToVersion:   diffVSchemaVersionsOptions.To,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.Diff == "" {
+		fmt.Println("No changes.")
+		return nil
+	}
+	fmt.Println(resp.Diff)
+	return nil
+}
+
 func commandGetVSchema(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -150,6 +223,57 @@ func commandGetVSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetVSchemaHistory(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVSchemaHistory(commandCtx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Versions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var rollbackVSchemaOptions = struct {
+	Version     int64
+	Author      string
+	Comment     string
+	SkipRebuild bool
+	Cells       []string
+}{}
+
+func commandRollbackVSchema(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	keyspace := cmd.Flags().Arg(0)
+	resp, err := client.RollbackVSchema(commandCtx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace:    keyspace,
+		Version:     rollbackVSchemaOptions.Version,
+		Author:      vschemaChangeAuthor(rollbackVSchemaOptions.Author),
+		Comment:     rollbackVSchemaOptions.Comment,
+		SkipRebuild: rollbackVSchemaOptions.SkipRebuild,
+		Cells:       rollbackVSchemaOptions.Cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back the vschema of keyspace %s to version %d as version %d.\n", keyspace, rollbackVSchemaOptions.Version, resp.Version.Version)
+	if resp.Diff != "" {
+		fmt.Println(resp.Diff)
+	}
+	return nil
+}
+
 func init() {
 	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.VSchema, "vschema", "", "VSchema to apply, in JSON form.")
 	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.VSchemaFile, "vschema-file", "", "Path to a file containing the vschema to apply, in JSON form.")
@@ -159,7 +283,24 @@ func init() {
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
 	ApplyVSchema.Flags().StringSliceVar(&applyVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after application. Ignored if --skip-rebuild is set.")
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.Strict, "strict", false, "If set, treat unknown vindex params as errors.")
+	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.Staged, "staged", false, "Only apply the vschema to the SrvVSchema objects in --cells, without saving it to the global topo. Use ReconcileSrvVSchemas to promote or revert it.")
+	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.Author, "author", "", "Author recorded with the new version of the vschema history. Defaults to the current OS user.")
+	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.Comment, "comment", "", "Comment recorded with the new version of the vschema history.")
 	Root.AddCommand(ApplyVSchema)
 
+	DiffVSchemaVersions.Flags().Int64Var(&diffVSchemaVersionsOptions.From, "from", 0, "Version of the vschema history to diff from.")
+	DiffVSchemaVersions.Flags().Int64Var(&diffVSchemaVersionsOptions.To, "to", 0, "Version of the vschema history to diff to. Defaults to the current vschema.")
+	DiffVSchemaVersions.MarkFlagRequired("from")
+	Root.AddCommand(DiffVSchemaVersions)
+
 	Root.AddCommand(GetVSchema)
+	Root.AddCommand(GetVSchemaHistory)
+
+	RollbackVSchema.Flags().Int64Var(&rollbackVSchemaOptions.Version, "version", 0, "Version of the vschema history to restore.")
+	RollbackVSchema.MarkFlagRequired("version")
+	RollbackVSchema.Flags().StringVar(&rollbackVSchemaOptions.Author, "author", "", "Author recorded with the new version of the vschema history. Defaults to the current OS user.")
+	RollbackVSchema.Flags().StringVar(&rollbackVSchemaOptions.Comment, "comment", "", "Comment recorded with the new version of the vschema history.")
+	RollbackVSchema.Flags().BoolVar(&rollbackVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
+	RollbackVSchema.Flags().StringSliceVar(&rollbackVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after the rollback. Ignored if --skip-rebuild is set.")
+	Root.AddCommand(RollbackVSchema)
 }
@@ -20,12 +20,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vtexplain"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
@@ -47,6 +50,16 @@ var (
 	normalize          bool
 	dbName             string
 	plannerVersionStr  string
+	sqlLogFileFlag     string
+	vtctldServer       string
+	keyspaces          []string
+	adviseSharding     bool
+
+	sqlLogFormat        = vtexplain.QueryLogFormatText
+	vtctldClientTimeout = 30 * time.Second
+
+	advisorMaxLookupVindexes = 2
+	advisorMinLookupRatio    = 0.1
 
 	numShards       = 2
 	replicationMode = "ROW"
@@ -82,7 +95,11 @@ If no keyspace name is present, VTExplain will return the following error:
 		Example: "Explain how Vitess will execute the query `SELECT * FROM users` using the VSchema contained in `vschemas.json` and the database schema `schema.sql`:\n\n" +
 			"```\nvtexplain --vschema-file vschema.json --schema-file schema.sql --sql \"SELECT * FROM users\"\n```\n\n" +
 			"Explain how the example will execute on 128 shards using Row-based replication:\n\n" +
-			"```\nvtexplain -- -shards 128 --vschema-file vschema.json --schema-file schema.sql --replication-mode \"ROW\" --output-mode text --sql \"INSERT INTO users (user_id, name) VALUES(1, 'john')\"\n```\n",
+			"```\nvtexplain -- -shards 128 --vschema-file vschema.json --schema-file schema.sql --replication-mode \"ROW\" --output-mode text --sql \"INSERT INTO users (user_id, name) VALUES(1, 'john')\"\n```\n\n" +
+			"Explain the queries of a vtgate query log using the VSchema, schema and shards of a running cluster:\n\n" +
+			"```\nvtexplain --vtctld-server localhost:15999 --sql-log-file /var/log/vtgate/querylog.txt --output-mode json\n```\n\n" +
+			"Recommend the vindexes of the tables from the query plans of a vtgate, using the schema of a running cluster:\n\n" +
+			"```\ncurl -s http://vtgate:15001/debug/query_plans > plans.json\nvtexplain --advise-sharding --vtctld-server localhost:15999 --sql-log-file plans.json --sql-log-format plans\n```\n",
 		Args:    cobra.NoArgs,
 		PreRunE: servenv.CobraPreRunE,
 		Version: servenv.AppVersion.String(),
@@ -107,6 +124,14 @@ func init() {
 	Main.Flags().IntVar(&numShards, "shards", numShards, "Number of shards per keyspace. Passing --ks-shard-map/--ks-shard-map-file causes this flag to be ignored.")
 	Main.Flags().StringVar(&executionMode, "execution-mode", executionMode, "The execution mode to simulate -- must be set to multi, legacy-autocommit, or twopc")
 	Main.Flags().StringVar(&outputMode, "output-mode", outputMode, "Output in human-friendly text or json")
+	Main.Flags().StringVar(&sqlLogFileFlag, "sql-log-file", sqlLogFileFlag, "Identifies a vtgate query log file whose queries are analyzed")
+	Main.Flags().StringVar(&sqlLogFormat, "sql-log-format", sqlLogFormat, "The format of the --sql-log-file query log -- must be set to text or json, or to plans for the output of the /debug/query_plans page of vtgate with --advise-sharding")
+	Main.Flags().StringVar(&vtctldServer, "vtctld-server", vtctldServer, "Address of the vtctld of a running cluster to read the VSchema, schema and shards from, instead of the schema, vschema and ks-shard-map flags")
+	Main.Flags().StringSliceVar(&keyspaces, "keyspaces", keyspaces, "Keyspaces to read from the running cluster with --vtctld-server. Defaults to all the keyspaces")
+	Main.Flags().DurationVar(&vtctldClientTimeout, "vtctld-timeout", vtctldClientTimeout, "Timeout for reading the VSchema, schema and shards from the running cluster with --vtctld-server")
+	Main.Flags().BoolVar(&adviseSharding, "advise-sharding", adviseSharding, "Instead of explaining the queries, report the columns used in the predicates of the queries of each table, its ratio of scatter queries, and recommend its primary and lookup vindexes. The schema is optional, and the VSchema is not used")
+	Main.Flags().IntVar(&advisorMaxLookupVindexes, "advisor-max-lookup-vindexes", advisorMaxLookupVindexes, "Maximum number of lookup vindexes recommended per table with --advise-sharding")
+	Main.Flags().Float64Var(&advisorMinLookupRatio, "advisor-min-lookup-ratio", advisorMinLookupRatio, "Minimum ratio of the queries of a table which a lookup vindex must route to a single shard, besides the ones routed by the other recommended vindexes, to be recommended with --advise-sharding")
 
 	acl.RegisterFlags(Main.Flags())
 }
@@ -135,6 +160,123 @@ func getFileParam(flag, flagFile, name string, required bool) (string, error) {
 	return string(data), nil
 }
 
+// getSQL returns the queries to analyze, from either the sql flags or the
+// vtgate query log file.
+func getSQL() (string, error) {
+	if sqlLogFileFlag == "" {
+		return getFileParam(sqlFlag, sqlFileFlag, "sql", true)
+	}
+	if sqlFlag != "" || sqlFileFlag != "" {
+		return "", fmt.Errorf("action requires only one of sql, sql-file or sql-log-file")
+	}
+	f, err := os.Open(sqlLogFileFlag)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file %v: %v", sqlLogFileFlag, err)
+	}
+	defer f.Close()
+	return vtexplain.ReadQueryLog(f, sqlLogFormat)
+}
+
+// loadClusterInputs reads the VSchema, schema and shards of the keyspaces
+// from the vtctld of a running cluster.
+func loadClusterInputs(ctx context.Context) (*vtexplain.ClusterInputs, error) {
+	ctx, cancel := context.WithTimeout(ctx, vtctldClientTimeout)
+	defer cancel()
+
+	client, err := vtctldclient.New(ctx, "grpc", vtctldServer)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return vtexplain.LoadClusterInputs(ctx, client, keyspaces)
+}
+
+// getQueryLogEntries returns the queries to analyze with --advise-sharding,
+// from either the sql flags or the vtgate query log file.
+func getQueryLogEntries(parser *sqlparser.Parser) ([]*vtexplain.QueryLogEntry, error) {
+	if sqlLogFileFlag == "" {
+		sql, err := getFileParam(sqlFlag, sqlFileFlag, "sql", true)
+		if err != nil {
+			return nil, err
+		}
+		queries, err := parser.SplitStatementToPieces(sql)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]*vtexplain.QueryLogEntry, 0, len(queries))
+		for _, query := range queries {
+			entries = append(entries, &vtexplain.QueryLogEntry{SQL: query, Count: 1})
+		}
+		return entries, nil
+	}
+	if sqlFlag != "" || sqlFileFlag != "" {
+		return nil, fmt.Errorf("action requires only one of sql, sql-file or sql-log-file")
+	}
+	f, err := os.Open(sqlLogFileFlag)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %v: %v", sqlLogFileFlag, err)
+	}
+	defer f.Close()
+	return vtexplain.ReadQueryLogEntries(f, sqlLogFormat)
+}
+
+func newEnvironment() (*vtenv.Environment, error) {
+	return vtenv.New(vtenv.Options{
+		MySQLServerVersion: servenv.MySQLServerVersion(),
+		TruncateUILen:      servenv.TruncateUILen,
+		TruncateErrLen:     servenv.TruncateErrLen,
+	})
+}
+
+// parseAndAdviseSharding analyzes the queries and prints the recommended
+// vindexes of their tables.
+func parseAndAdviseSharding(ctx context.Context) error {
+	env, err := newEnvironment()
+	if err != nil {
+		return err
+	}
+	entries, err := getQueryLogEntries(env.Parser())
+	if err != nil {
+		return err
+	}
+
+	var schema string
+	if vtctldServer != "" {
+		if schemaFlag != "" || schemaFileFlag != "" {
+			return fmt.Errorf("action requires only one of vtctld-server or the schema flags")
+		}
+		inputs, err := loadClusterInputs(ctx)
+		if err != nil {
+			return err
+		}
+		schema = inputs.Schema
+	} else {
+		schema, err = getFileParam(schemaFlag, schemaFileFlag, "schema", false)
+		if err != nil {
+			return err
+		}
+	}
+
+	advice, err := vtexplain.AdviseSharding(env.Parser(), entries, schema, vtexplain.AdvisorOptions{
+		MaxLookupVindexes: advisorMaxLookupVindexes,
+		MinLookupRatio:    advisorMinLookupRatio,
+	})
+	if err != nil {
+		return err
+	}
+
+	if outputMode == "text" {
+		fmt.Print(vtexplain.ShardingAdviceAsText(advice))
+		return nil
+	}
+	out, err := vtexplain.ShardingAdviceAsJSON(advice)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	defer logutil.Flush()
 
@@ -148,24 +290,40 @@ func parseAndRun(ctx context.Context) error {
 		return fmt.Errorf("invalid value specified for planner-version of '%s' -- valid value is Gen4 or an empty value to use the default planner", plannerVersionStr)
 	}
 
-	sql, err := getFileParam(sqlFlag, sqlFileFlag, "sql", true)
-	if err != nil {
-		return err
+	if adviseSharding {
+		return parseAndAdviseSharding(ctx)
 	}
 
-	schema, err := getFileParam(schemaFlag, schemaFileFlag, "schema", true)
+	sql, err := getSQL()
 	if err != nil {
 		return err
 	}
 
-	vschema, err := getFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
-	if err != nil {
-		return err
-	}
+	var schema, vschema, ksShardMap string
+	if vtctldServer != "" {
+		if schemaFlag != "" || schemaFileFlag != "" || vschemaFlag != "" || vschemaFileFlag != "" || ksShardMapFlag != "" || ksShardMapFileFlag != "" {
+			return fmt.Errorf("action requires only one of vtctld-server or the schema, vschema and ks-shard-map flags")
+		}
+		inputs, err := loadClusterInputs(ctx)
+		if err != nil {
+			return err
+		}
+		schema, vschema, ksShardMap = inputs.Schema, inputs.VSchema, inputs.KsShardMap
+	} else {
+		schema, err = getFileParam(schemaFlag, schemaFileFlag, "schema", true)
+		if err != nil {
+			return err
+		}
 
-	ksShardMap, err := getFileParam(ksShardMapFlag, ksShardMapFileFlag, "ks-shard-map", false)
-	if err != nil {
-		return err
+		vschema, err = getFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
+		if err != nil {
+			return err
+		}
+
+		ksShardMap, err = getFileParam(ksShardMapFlag, ksShardMapFileFlag, "ks-shard-map", false)
+		if err != nil {
+			return err
+		}
 	}
 
 	opts := &vtexplain.Options{
@@ -177,11 +335,7 @@ func parseAndRun(ctx context.Context) error {
 		Target:          dbName,
 	}
 
-	env, err := vtenv.New(vtenv.Options{
-		MySQLServerVersion: servenv.MySQLServerVersion(),
-		TruncateUILen:      servenv.TruncateUILen,
-		TruncateErrLen:     servenv.TruncateErrLen,
-	})
+	env, err := newEnvironment()
 	if err != nil {
 		return err
 	}
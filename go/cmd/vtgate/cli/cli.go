@@ -153,6 +153,7 @@ func run(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(cmd.Context())
 	defer cancel()
 	resilientServer = srvtopo.NewResilientServer(ctx, ts, srvTopoCounts)
+	stats.NewGaugesFuncWithMultiLabels("ResilientSrvTopoServerStalenessSeconds", "How long the oldest cached srvtopo value served without a topology watch was last known to be current", []string{"Type"}, resilientServer.Staleness)
 
 	tabletTypes := make([]topodatapb.TabletType, 0, 1)
 	for _, tt := range tabletTypesToWait {
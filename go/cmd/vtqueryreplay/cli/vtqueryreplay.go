@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtqueryreplay"
+)
+
+var (
+	host, unixSocket, user, db, logFile string
+	port                                int
+	speed                               = 1.0
+	maxRows                             = 100000
+	maxDivergences                      = 100
+	deadline                            = 24 * time.Hour
+	outputMode                          = "text"
+
+	Main = &cobra.Command{
+		Use:   "vtqueryreplay",
+		Short: "vtqueryreplay replays the queries of a vtgate query log against a cluster and compares their results with the recorded ones.",
+		Long: `vtqueryreplay replays the queries of a vtgate query log against a cluster and compares their results with the recorded ones.
+
+The query log must be written in the JSON format, with --querylog-format json. The queries are replayed on
+the MySQL protocol of a vtgate of the target cluster. The queries of each session of the log are executed in
+order on a connection of their own, at the time they were recorded scaled by --speed. Queries executed with
+bind variables, e.g. prepared statements, are skipped.
+
+A replayed query diverges when it fails but succeeded when recorded or vice versa, or when it affects or
+returns a different number of rows. Run the vtgates with --querylog-result-checksum to also compare the
+values of the returned rows. The order of the rows is not compared.`,
+		Example: `Replay a query log twice as fast against a vtgate:
+
+vtqueryreplay \
+	--host vtgate-host.my.domain \
+	--port 15306 \
+	--user db_username \
+	--db-credentials-file ./creds.json \
+	--log-file ./querylog.json \
+	--speed 2`,
+		Args:    cobra.NoArgs,
+		Version: servenv.AppVersion.String(),
+		PreRunE: servenv.CobraPreRunE,
+		RunE:    run,
+	}
+)
+
+func init() {
+	servenv.MoveFlagsToCobraCommand(Main)
+
+	Main.Flags().StringVar(&host, "host", host, "VTGate host to replay the queries against")
+	Main.Flags().IntVar(&port, "port", port, "VTGate MySQL port")
+	Main.Flags().StringVar(&unixSocket, "unix_socket", unixSocket, "VTGate unix socket")
+	Main.Flags().StringVar(&user, "user", user, "Username to connect using mysql (password comes from the db-credentials-file)")
+	Main.Flags().StringVar(&db, "db", db, "Database name to use when connecting (e.g. @replica, keyspace, keyspace/shard etc)")
+
+	Main.Flags().StringVar(&logFile, "log-file", logFile, "vtgate query log file in the JSON format to replay")
+	Main.Flags().Float64Var(&speed, "speed", speed, "Speed of the replay relative to the recorded traffic, e.g. 2 replays the queries twice as fast. 0 replays them as fast as possible")
+	Main.Flags().IntVar(&maxRows, "max-rows", maxRows, "Maximum number of rows fetched for a query")
+	Main.Flags().IntVar(&maxDivergences, "max-divergences", maxDivergences, "Maximum number of divergences listed in the report. 0 lists them all")
+	Main.Flags().DurationVar(&deadline, "deadline", deadline, "Maximum duration of the replay")
+	Main.Flags().StringVar(&outputMode, "output-mode", outputMode, "Output the report in human-friendly text or json")
+
+	Main.MarkFlagRequired("log-file")
+
+	acl.RegisterFlags(Main.Flags())
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	logger := logutil.NewConsoleLogger()
+	cmd.SetOutput(logutil.NewLoggerWriter(logger))
+	_ = cmd.Flags().Set("logtostderr", "true")
+
+	servenv.Init()
+
+	if (host != "" || port != 0) && unixSocket != "" {
+		return errors.New("can't specify both host:port and unix_socket")
+	}
+	if host == "" && port == 0 && unixSocket == "" {
+		return errors.New("vtqueryreplay requires either host/port or unix_socket")
+	}
+	if speed < 0 {
+		return fmt.Errorf("invalid speed %v", speed)
+	}
+	if outputMode != "text" && outputMode != "json" {
+		return fmt.Errorf("invalid output mode %s", outputMode)
+	}
+
+	_, password, err := dbconfigs.GetCredentialsServer().GetUserAndPassword(user)
+	if err != nil {
+		return fmt.Errorf("error reading password for user %v from file: %w", user, err)
+	}
+	connParams := &mysql.ConnParams{
+		Host:       host,
+		Port:       port,
+		UnixSocket: unixSocket,
+		Uname:      user,
+		Pass:       password,
+		DbName:     db,
+	}
+
+	f, err := os.Open(logFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := vtqueryreplay.ReadLog(f)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", logFile, err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), deadline)
+	defer cancel()
+
+	dial := func(ctx context.Context) (vtqueryreplay.Conn, error) {
+		return mysql.Connect(ctx, connParams)
+	}
+	report, err := vtqueryreplay.Replay(ctx, dial, entries, vtqueryreplay.Options{
+		Speed:          speed,
+		MaxRows:        maxRows,
+		MaxDivergences: maxDivergences,
+	})
+	if report != nil {
+		if perr := printReport(report); perr != nil {
+			return perr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if report.Diverged > 0 {
+		return fmt.Errorf("%d of %d replayed queries diverged", report.Diverged, report.Queries)
+	}
+	return nil
+}
+
+func printReport(report *vtqueryreplay.Report) error {
+	if outputMode == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
+	fmt.Printf("Replayed Queries: %d\n", report.Queries)
+	fmt.Printf("Skipped Queries: %d\n", report.Skipped)
+	fmt.Printf("Failed Queries: %d\n", report.Errors)
+	fmt.Printf("Diverged Queries: %d\n", report.Diverged)
+	fmt.Printf("Max Lag: %v\n", report.MaxLag)
+	fmt.Printf("Total Replay Time: %v\n", report.Duration)
+	for _, d := range report.Divergences {
+		fmt.Printf("\nline %d (session %s): %s\n", d.Line, d.SessionUUID, d.SQL)
+		for _, msg := range d.Messages {
+			fmt.Printf("  %s\n", msg)
+		}
+	}
+	return nil
+}
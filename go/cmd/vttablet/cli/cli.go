@@ -124,6 +124,7 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse --tablet-path: %w", err)
 	}
+	servenv.SetProfileLabel("tablet_alias", topoproto.TabletAliasString(tabletAlias))
 
 	mysqlVersion := servenv.MySQLServerVersion()
 	env, err := vtenv.New(vtenv.Options{
@@ -175,6 +176,7 @@ func run(cmd *cobra.Command, args []string) error {
 		ts.Close()
 		return fmt.Errorf("failed to parse --tablet-path or initialize DB credentials: %w", err)
 	}
+	servenv.OnRun(tm.RegisterSelfCheckHandler)
 	servenv.OnClose(func() {
 		// Close the tm so that our topo entry gets pruned properly and any
 		// background goroutines that use the topo connection are stopped.
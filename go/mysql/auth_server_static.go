@@ -357,3 +357,30 @@ type StaticUserData struct {
 func (sud *StaticUserData) Get() *querypb.VTGateCallerID {
 	return &querypb.VTGateCallerID{Username: sud.Username, Groups: sud.Groups}
 }
+
+// ExpandGroupRoles returns the given groups, followed by the roles that
+// roleMapping grants to any of them, without duplicates. Auth servers use it
+// to expose a user's roles to the ACL layers through the caller ID groups.
+func ExpandGroupRoles(groups []string, roleMapping map[string][]string) []string {
+	if len(roleMapping) == 0 {
+		return groups
+	}
+
+	seen := make(map[string]struct{}, len(groups))
+	expanded := make([]string, 0, len(groups))
+	add := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			expanded = append(expanded, name)
+		}
+	}
+	for _, group := range groups {
+		add(group)
+	}
+	for _, group := range groups {
+		for _, role := range roleMapping[group] {
+			add(role)
+		}
+	}
+	return expanded
+}
@@ -241,3 +241,12 @@ func TestStaticPasswords(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandGroupRoles(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, ExpandGroupRoles([]string{"a", "b"}, nil))
+	require.Equal(t, []string{"a", "b", "reader", "writer"}, ExpandGroupRoles([]string{"a", "b"}, map[string][]string{
+		"a": {"reader", "b"},
+		"b": {"writer", "reader"},
+		"c": {"admin"},
+	}))
+}
@@ -75,6 +75,10 @@ type BinlogEvent interface {
 	IsPreviousGTIDs() bool
 	// IsHeartbeat returns true if this event is a HEARTBEAT_EVENT.
 	IsHeartbeat() bool
+	// IsRowsQuery returns true if this event is a ROWS_QUERY_LOG_EVENT,
+	// which precedes the row events of a statement when
+	// binlog_rows_query_log_events is ON.
+	IsRowsQuery() bool
 	// IsSemiSyncAckRequested returns true if the source requests a semi-sync ack for this event
 	IsSemiSyncAckRequested() bool
 
@@ -111,6 +115,10 @@ type BinlogEvent interface {
 	// PreviousGTIDs returns the Position from the event.
 	// This is only valid if IsPreviousGTIDs() returns true.
 	PreviousGTIDs(BinlogFormat) (replication.Position, error)
+	// RowsQuery returns the original statement, comments included, of the
+	// row events which follow a ROWS_QUERY_LOG_EVENT.
+	// This is only valid if IsRowsQuery() returns true.
+	RowsQuery(BinlogFormat) (string, error)
 
 	// TableID returns the table ID for a TableMap, UpdateRows,
 	// WriteRows or DeleteRows event.
@@ -170,6 +170,11 @@ func (ev binlogEvent) IsHeartbeat() bool {
 	return ev.Type() == eHeartbeatEvent
 }
 
+// IsRowsQuery implements BinlogEvent.IsRowsQuery().
+func (ev binlogEvent) IsRowsQuery() bool {
+	return ev.Type() == eRowsQueryEvent
+}
+
 // IsTableMap implements BinlogEvent.IsTableMap().
 func (ev binlogEvent) IsTableMap() bool {
 	return ev.Type() == eTableMapEvent
@@ -354,6 +359,21 @@ func (ev binlogEvent) Rand(f BinlogFormat) (seed1 uint64, seed2 uint64, err erro
 	return seed1, seed2, nil
 }
 
+// RowsQuery implements BinlogEvent.RowsQuery().
+//
+// Expected format (L = total length of event data):
+//
+//	# bytes   field
+//	1         length of the statement, truncated to 255 (ignored)
+//	L-1       statement
+func (ev binlogEvent) RowsQuery(f BinlogFormat) (string, error) {
+	data := ev.Bytes()[f.HeaderLength:]
+	if len(data) < 1 {
+		return "", vterrors.Errorf(vtrpc.Code_INTERNAL, "ROWS_QUERY_LOG_EVENT has no data")
+	}
+	return string(data[1:]), nil
+}
+
 func (ev binlogEvent) TableID(f BinlogFormat) uint64 {
 	typ := ev.Type()
 	pos := f.HeaderLength
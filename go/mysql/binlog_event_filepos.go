@@ -191,6 +191,10 @@ func (ev filePosFakeEvent) IsHeartbeat() bool {
 	return false
 }
 
+func (ev filePosFakeEvent) IsRowsQuery() bool {
+	return false
+}
+
 func (ev filePosFakeEvent) IsTableMap() bool {
 	return false
 }
@@ -235,6 +239,10 @@ func (ev filePosFakeEvent) PreviousGTIDs(BinlogFormat) (replication.Position, er
 	return replication.Position{}, nil
 }
 
+func (ev filePosFakeEvent) RowsQuery(BinlogFormat) (string, error) {
+	return "", nil
+}
+
 func (ev filePosFakeEvent) TableID(BinlogFormat) uint64 {
 	return 0
 }
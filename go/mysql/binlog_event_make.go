@@ -292,6 +292,16 @@ func NewIntVarEvent(f BinlogFormat, s *FakeBinlogStream, typ byte, value uint64)
 	return NewMysql56BinlogEvent(ev)
 }
 
+// NewRowsQueryEvent returns a RowsQuery event.
+func NewRowsQueryEvent(f BinlogFormat, s *FakeBinlogStream, query string) BinlogEvent {
+	data := make([]byte, 1+len(query))
+	data[0] = byte(min(len(query), 255))
+	copy(data[1:], query)
+
+	ev := s.Packetize(f, eRowsQueryEvent, 0, data)
+	return NewMysql56BinlogEvent(ev)
+}
+
 // NewMariaDBGTIDEvent returns a MariaDB specific GTID event.
 // It ignores the Server in the gtid, instead uses the FakeBinlogStream.ServerID.
 func NewMariaDBGTIDEvent(f BinlogFormat, s *FakeBinlogStream, gtid replication.MariadbGTID, hasBegin bool) BinlogEvent {
@@ -18,6 +18,7 @@ package mysql
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -117,6 +118,24 @@ func TestIntVarEvent(t *testing.T) {
 
 }
 
+func TestRowsQueryEvent(t *testing.T) {
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+
+	// The length of the statement in the event is truncated, and ignored.
+	query := "insert /*vt+ JOB=backfill */ into t1 values " + strings.Repeat("(1, 'a'), ", 30) + "(2, 'b')"
+	event := NewRowsQueryEvent(f, s, query)
+	require.True(t, event.IsValid(), "NewRowsQueryEvent().IsValid() is false")
+	require.True(t, event.IsRowsQuery(), "NewRowsQueryEvent().IsRowsQuery() is false")
+	require.False(t, event.IsQuery())
+
+	event, _, err := event.StripChecksum(f)
+	require.NoError(t, err)
+	got, err := event.RowsQuery(f)
+	require.NoError(t, err)
+	assert.Equal(t, query, got)
+}
+
 func TestInvalidEvents(t *testing.T) {
 	f := NewMySQL56BinlogFormat()
 	s := NewFakeBinlogStream()
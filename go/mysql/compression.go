@@ -0,0 +1,386 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// CompressionAlgorithm is an algorithm used to compress the packets of a
+// connection. It is negotiated during the handshake.
+type CompressionAlgorithm string
+
+const (
+	// CompressionNone means the packets are not compressed. This is the
+	// default.
+	CompressionNone CompressionAlgorithm = ""
+
+	// CompressionZlib compresses the packets with zlib (CLIENT_COMPRESS).
+	CompressionZlib CompressionAlgorithm = "zlib"
+
+	// CompressionZstd compresses the packets with zstd
+	// (CLIENT_ZSTD_COMPRESSION_ALGORITHM).
+	CompressionZstd CompressionAlgorithm = "zstd"
+)
+
+const (
+	// DefaultZstdCompressionLevel is the zstd compression level used when
+	// none is given, as in MySQL.
+	DefaultZstdCompressionLevel = 3
+
+	// compressedPacketHeaderSize is the size of the header of a compressed
+	// packet: the length of the compressed payload, the sequence, and the
+	// length of the payload before compression.
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the size under which payloads are not worth
+	// compressing, as in MySQL.
+	minCompressLength = 50
+)
+
+var compressionBytes = stats.NewCountersWithMultiLabels(
+	"MysqlCompressionBytes",
+	"Bytes read and written by the compressed MySQL connections, before (uncompressed) and after (compressed) compression",
+	[]string{"Side", "Algorithm", "Direction", "Stage"})
+
+// ParseCompressionAlgorithm parses the name of a compression algorithm, as
+// used by MySQL.
+func ParseCompressionAlgorithm(name string) (CompressionAlgorithm, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "uncompressed":
+		return CompressionNone, nil
+	case "zlib":
+		return CompressionZlib, nil
+	case "zstd":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, fmt.Errorf("unknown compression algorithm %q, must be one of uncompressed, zlib or zstd", name)
+	}
+}
+
+// compressionCapabilities returns the capability flags of the compression
+// algorithms.
+func compressionCapabilities(algorithms []CompressionAlgorithm) uint32 {
+	var capabilities uint32
+	for _, algorithm := range algorithms {
+		switch algorithm {
+		case CompressionZlib:
+			capabilities |= CapabilityClientCompress
+		case CompressionZstd:
+			capabilities |= CapabilityClientZstdCompressionAlgorithm
+		}
+	}
+	return capabilities
+}
+
+// CompressionStats are the bytes read and written by a compressed
+// connection, before and after compression.
+type CompressionStats struct {
+	// BytesRead and BytesWritten are the bytes which went over the
+	// network, including the headers of the compressed packets.
+	BytesRead    int64
+	BytesWritten int64
+
+	// UncompressedBytesRead and UncompressedBytesWritten are the bytes of
+	// the MySQL packets, before compression.
+	UncompressedBytesRead    int64
+	UncompressedBytesWritten int64
+}
+
+// Ratio returns how many uncompressed bytes each byte sent over the network
+// carried, or 0 if nothing was sent yet.
+func (cs CompressionStats) Ratio() float64 {
+	compressed := cs.BytesRead + cs.BytesWritten
+	if compressed == 0 {
+		return 0
+	}
+	return float64(cs.UncompressedBytesRead+cs.UncompressedBytesWritten) / float64(compressed)
+}
+
+var (
+	zlibWriters = sync.Pool{New: func() any { return zlib.NewWriter(nil) }}
+	zlibReaders sync.Pool
+
+	// zstdEncoders are shared by all the connections, one per encoder
+	// level. EncodeAll can be called concurrently.
+	zstdEncodersMu sync.Mutex
+	zstdEncoders   = make(map[zstd.EncoderLevel]*zstd.Encoder)
+)
+
+// zstdEncoder returns the encoder for a zstd compression level.
+func zstdEncoder(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+	if encoder, ok := zstdEncoders[encoderLevel]; ok {
+		return encoder, nil
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+	if err != nil {
+		return nil, err
+	}
+	zstdEncoders[encoderLevel] = encoder
+	return encoder, nil
+}
+
+// compressedConn reads and writes the compressed packets of a connection.
+// Each write is sent as one or more compressed packets, so writes should be
+// buffered by the caller. Reads return the decompressed payloads.
+//
+// The compressed packets have their own sequence, which restarts at 0 with
+// each command, like the sequence of the packets they carry.
+type compressedConn struct {
+	algorithm CompressionAlgorithm
+	zstdLevel int
+
+	r io.Reader
+	w io.Writer
+
+	sequence uint8
+	header   [compressedPacketHeaderSize]byte
+
+	// pending is the decompressed data not read yet.
+	pending []byte
+	// readBuf and decompressBuf are reused between compressed packets.
+	readBuf       []byte
+	decompressBuf []byte
+	writeBuf      []byte
+
+	bytesRead                atomic.Int64
+	bytesWritten             atomic.Int64
+	uncompressedBytesRead    atomic.Int64
+	uncompressedBytesWritten atomic.Int64
+
+	// statsLabels are the labels of the compressionBytes counters, by
+	// direction and stage.
+	statsLabels [2][2][]string
+}
+
+func newCompressedConn(r io.Reader, w io.Writer, algorithm CompressionAlgorithm, zstdLevel int, side string) *compressedConn {
+	cc := &compressedConn{
+		algorithm: algorithm,
+		zstdLevel: zstdLevel,
+		r:         r,
+		w:         w,
+	}
+	for i, direction := range []string{"read", "write"} {
+		for j, stage := range []string{"compressed", "uncompressed"} {
+			cc.statsLabels[i][j] = []string{side, string(algorithm), direction, stage}
+		}
+	}
+	return cc
+}
+
+// Read implements io.Reader. It reads the next compressed packet if all
+// the data of the previous one was read.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	for len(cc.pending) == 0 {
+		if err := cc.readPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.pending)
+	cc.pending = cc.pending[n:]
+	return n, nil
+}
+
+func (cc *compressedConn) readPacket() error {
+	if _, err := io.ReadFull(cc.r, cc.header[:]); err != nil {
+		return err
+	}
+	length := int(uint32(cc.header[0]) | uint32(cc.header[1])<<8 | uint32(cc.header[2])<<16)
+	uncompressedLength := int(uint32(cc.header[4]) | uint32(cc.header[5])<<8 | uint32(cc.header[6])<<16)
+
+	// MySQL clients do not check the sequence of the compressed packets,
+	// as the server may reply before it read all the packets of a command.
+	// Follow the sequence of the other side instead.
+	cc.sequence = cc.header[3] + 1
+
+	if cap(cc.readBuf) < length {
+		cc.readBuf = make([]byte, length)
+	}
+	payload := cc.readBuf[:length]
+	if _, err := io.ReadFull(cc.r, payload); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet of length %v) failed", length)
+	}
+
+	cc.bytesRead.Add(int64(compressedPacketHeaderSize + length))
+	compressionBytes.Add(cc.statsLabels[0][0], int64(compressedPacketHeaderSize+length))
+
+	// A zero uncompressed length means the payload was not compressed.
+	if uncompressedLength == 0 {
+		cc.pending = payload
+		cc.uncompressedBytesRead.Add(int64(length))
+		compressionBytes.Add(cc.statsLabels[0][1], int64(length))
+		return nil
+	}
+
+	data, err := cc.decompress(payload, uncompressedLength)
+	if err != nil {
+		return err
+	}
+	cc.pending = data
+	cc.uncompressedBytesRead.Add(int64(uncompressedLength))
+	compressionBytes.Add(cc.statsLabels[0][1], int64(uncompressedLength))
+	return nil
+}
+
+func (cc *compressedConn) decompress(payload []byte, uncompressedLength int) ([]byte, error) {
+	if cap(cc.decompressBuf) < uncompressedLength {
+		cc.decompressBuf = make([]byte, uncompressedLength)
+	}
+	data := cc.decompressBuf[:uncompressedLength]
+
+	switch cc.algorithm {
+	case CompressionZlib:
+		var zr io.ReadCloser
+		if pooled := zlibReaders.Get(); pooled != nil {
+			zr = pooled.(io.ReadCloser)
+			if err := zr.(zlib.Resetter).Reset(bytes.NewReader(payload), nil); err != nil {
+				return nil, vterrors.Wrapf(err, "cannot decompress zlib packet")
+			}
+		} else {
+			var err error
+			if zr, err = zlib.NewReader(bytes.NewReader(payload)); err != nil {
+				return nil, vterrors.Wrapf(err, "cannot decompress zlib packet")
+			}
+		}
+		defer zlibReaders.Put(zr)
+		if _, err := io.ReadFull(zr, data); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot decompress zlib packet")
+		}
+	case CompressionZstd:
+		decompressed, err := zstdDecoder.DecodeAll(payload, data[:0])
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot decompress zstd packet")
+		}
+		if len(decompressed) != uncompressedLength {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "decompressed zstd packet has %v bytes, expected %v", len(decompressed), uncompressedLength)
+		}
+		data = decompressed
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown compression algorithm %q", cc.algorithm)
+	}
+	return data, nil
+}
+
+// Write implements io.Writer. It writes the data in compressed packets of
+// at most MaxPacketSize bytes before compression.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), MaxPacketSize)]
+		if err := cc.writePacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writePacket(data []byte) error {
+	if cap(cc.writeBuf) < compressedPacketHeaderSize {
+		cc.writeBuf = make([]byte, compressedPacketHeaderSize, connBufferSize)
+	}
+	packet := cc.writeBuf[:compressedPacketHeaderSize]
+
+	uncompressedLength := 0
+	if len(data) >= minCompressLength {
+		var err error
+		packet, err = cc.compress(packet, data)
+		if err != nil {
+			return err
+		}
+		uncompressedLength = len(data)
+	}
+	// Send the data as is when compressing it does not make it smaller.
+	if uncompressedLength == 0 || len(packet)-compressedPacketHeaderSize >= len(data) {
+		packet = append(packet[:compressedPacketHeaderSize], data...)
+		uncompressedLength = 0
+	}
+	cc.writeBuf = packet
+
+	length := len(packet) - compressedPacketHeaderSize
+	packet[0] = byte(length)
+	packet[1] = byte(length >> 8)
+	packet[2] = byte(length >> 16)
+	packet[3] = cc.sequence
+	packet[4] = byte(uncompressedLength)
+	packet[5] = byte(uncompressedLength >> 8)
+	packet[6] = byte(uncompressedLength >> 16)
+	cc.sequence++
+
+	if _, err := cc.w.Write(packet); err != nil {
+		return err
+	}
+
+	cc.bytesWritten.Add(int64(len(packet)))
+	cc.uncompressedBytesWritten.Add(int64(len(data)))
+	compressionBytes.Add(cc.statsLabels[1][0], int64(len(packet)))
+	compressionBytes.Add(cc.statsLabels[1][1], int64(len(data)))
+	return nil
+}
+
+// compress appends the compressed data to the packet.
+func (cc *compressedConn) compress(packet []byte, data []byte) ([]byte, error) {
+	switch cc.algorithm {
+	case CompressionZlib:
+		buf := bytes.NewBuffer(packet)
+		zw := zlibWriters.Get().(*zlib.Writer)
+		defer zlibWriters.Put(zw)
+		zw.Reset(buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot compress zlib packet")
+		}
+		if err := zw.Close(); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot compress zlib packet")
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		encoder, err := zstdEncoder(cc.zstdLevel)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot create zstd encoder")
+		}
+		return encoder.EncodeAll(data, packet), nil
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown compression algorithm %q", cc.algorithm)
+	}
+}
+
+func (cc *compressedConn) stats() CompressionStats {
+	return CompressionStats{
+		BytesRead:                cc.bytesRead.Load(),
+		BytesWritten:             cc.bytesWritten.Load(),
+		UncompressedBytesRead:    cc.uncompressedBytesRead.Load(),
+		UncompressedBytesWritten: cc.uncompressedBytesWritten.Load(),
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompressionAlgorithm(t *testing.T) {
+	for name, want := range map[string]CompressionAlgorithm{
+		"":             CompressionNone,
+		"uncompressed": CompressionNone,
+		"zlib":         CompressionZlib,
+		"ZSTD":         CompressionZstd,
+	} {
+		algorithm, err := ParseCompressionAlgorithm(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, algorithm, name)
+	}
+
+	_, err := ParseCompressionAlgorithm("lz4")
+	assert.ErrorContains(t, err, "unknown compression algorithm")
+}
+
+func TestCompressedConn(t *testing.T) {
+	random := make([]byte, 1000)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	payloads := map[string][]byte{
+		"small":          []byte("select 1"),
+		"compressible":   bytes.Repeat([]byte("select * from t1 where id = 1;"), 10000),
+		"incompressible": random,
+	}
+
+	for _, algorithm := range []CompressionAlgorithm{CompressionZlib, CompressionZstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			for name, payload := range payloads {
+				t.Run(name, func(t *testing.T) {
+					var network bytes.Buffer
+					writer := newCompressedConn(nil, &network, algorithm, DefaultZstdCompressionLevel, "client")
+					reader := newCompressedConn(&network, nil, algorithm, DefaultZstdCompressionLevel, "server")
+
+					n, err := writer.Write(payload)
+					require.NoError(t, err)
+					assert.Equal(t, len(payload), n)
+					assert.EqualValues(t, 1, writer.sequence)
+
+					sent := network.Len()
+					read, err := io.ReadAll(reader)
+					require.NoError(t, err)
+					assert.Equal(t, payload, read)
+					assert.EqualValues(t, 1, reader.sequence)
+
+					stats := writer.stats()
+					assert.EqualValues(t, sent, stats.BytesWritten)
+					assert.EqualValues(t, len(payload), stats.UncompressedBytesWritten)
+					assert.Equal(t, stats.BytesWritten, reader.stats().BytesRead)
+					assert.Equal(t, stats.UncompressedBytesWritten, reader.stats().UncompressedBytesRead)
+
+					if name == "compressible" {
+						assert.Greater(t, stats.Ratio(), 10.0)
+					} else {
+						// Payloads which do not shrink are sent as they are.
+						assert.Equal(t, len(payload)+compressedPacketHeaderSize, sent)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestServerCompression(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{{
+		Password: "password1",
+	}}
+	defer authServer.close()
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer l.Close()
+	l.CompressionAlgorithms = []CompressionAlgorithm{CompressionZstd}
+	go l.Accept()
+
+	host, port := getHostPort(t, l.Addr())
+
+	testcases := []struct {
+		compression CompressionAlgorithm
+		level       int
+		want        CompressionAlgorithm
+		wantLevel   int
+	}{{
+		compression: CompressionNone,
+		want:        CompressionNone,
+	}, {
+		compression: CompressionZstd,
+		want:        CompressionZstd,
+		wantLevel:   DefaultZstdCompressionLevel,
+	}, {
+		compression: CompressionZstd,
+		level:       9,
+		want:        CompressionZstd,
+		wantLevel:   9,
+	}, {
+		// The server does not allow zlib.
+		compression: CompressionZlib,
+		want:        CompressionNone,
+	}}
+	for _, tc := range testcases {
+		t.Run(string(tc.compression), func(t *testing.T) {
+			params := &ConnParams{
+				Host:                 host,
+				Port:                 port,
+				Uname:                "user1",
+				Pass:                 "password1",
+				Compression:          tc.compression,
+				ZstdCompressionLevel: tc.level,
+			}
+			conn, err := Connect(context.Background(), params)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			assert.Equal(t, tc.want, conn.CompressionAlgorithm())
+			assert.Equal(t, tc.want, th.LastConn().CompressionAlgorithm())
+			if tc.want == CompressionZstd {
+				assert.Equal(t, tc.wantLevel, th.LastConn().zstdCompressionLevel)
+			}
+
+			// Several commands, to check the sequences restart with each
+			// of them.
+			for range 3 {
+				result, err := conn.ExecuteFetch("select rows", 1000, true)
+				require.NoError(t, err)
+				assert.Equal(t, selectRowsResult.Rows, result.Rows)
+
+				query := benchmarkQueryPrefix + strings.Repeat("compressible ", 100000)
+				result, err = conn.ExecuteFetch(query, 1000, true)
+				require.NoError(t, err)
+				require.Len(t, result.Rows, 1)
+				assert.Equal(t, query, result.Rows[0][0].ToString())
+			}
+			require.NoError(t, conn.Ping())
+
+			stats := conn.CompressionStats()
+			if tc.want == CompressionNone {
+				assert.Zero(t, stats)
+				return
+			}
+			assert.Greater(t, stats.Ratio(), 10.0)
+			assert.Greater(t, stats.UncompressedBytesRead, stats.BytesRead)
+			assert.Greater(t, stats.UncompressedBytesWritten, stats.BytesWritten)
+		})
+	}
+}
@@ -128,6 +128,11 @@ type Conn struct {
 	// It is set during the initial handshake.
 	UserData Getter
 
+	// Attributes are the connection attributes sent by the client,
+	// e.g. program_name or _client_version. They are set during the
+	// initial handshake of server-side connections.
+	Attributes map[string]string
+
 	bufferedReader *bufio.Reader
 	flushTimer     *time.Timer
 	flushDelay     time.Duration
@@ -194,6 +199,15 @@ type Conn struct {
 	// Packet encoding variables.
 	sequence uint8
 
+	// compressed reads and writes the compressed packets once
+	// compression is enabled, right after the handshake. It is nil
+	// for uncompressed connections.
+	compressed *compressedConn
+
+	// zstdCompressionLevel is the zstd level negotiated during the
+	// handshake, if CapabilityClientZstdCompressionAlgorithm is set.
+	zstdCompressionLevel int
+
 	// ExpectSemiSyncIndicator is applicable when the connection is used for replication (ComBinlogDump).
 	// When 'true', events are assumed to be padded with 2-byte semi-sync information
 	// See https://dev.mysql.com/doc/internals/en/semi-sync-binlog-event.html
@@ -203,6 +217,10 @@ type Conn struct {
 	// See: ConnParams.EnableQueryInfo
 	enableQueryInfo bool
 
+	// localInfile opens the files of LOAD DATA LOCAL INFILE statements.
+	// See: ConnParams.LocalInfile
+	localInfile LocalInfileOpener
+
 	// keepAliveOn marks when keep alive is active on the connection.
 	// This is currently used for testing.
 	keepAliveOn bool
@@ -323,7 +341,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.getWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -371,14 +389,76 @@ func (c *Conn) startFlushTimer() {
 }
 
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// depending on which buffer size was passed to newServerConn, or the
+// compressed connection reading from them.
 func (c *Conn) getReader() io.Reader {
+	if c.compressed != nil {
+		return c.compressed
+	}
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
 	return c.conn
 }
 
+// getWriter returns the writer for connection. It is the compressed
+// connection if compression is enabled, net.Conn otherwise.
+func (c *Conn) getWriter() io.Writer {
+	if c.compressed != nil {
+		return c.compressed
+	}
+	return c.conn
+}
+
+// enableCompression starts compressing the packets, with the algorithm
+// negotiated during the handshake. It must be called once the handshake
+// is complete.
+func (c *Conn) enableCompression() {
+	algorithm := CompressionNone
+	switch {
+	case c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		algorithm = CompressionZstd
+	case c.Capabilities&CapabilityClientCompress != 0:
+		algorithm = CompressionZlib
+	default:
+		return
+	}
+
+	side := "client"
+	if c.listener != nil {
+		side = "server"
+	}
+	c.compressed = newCompressedConn(c.getReader(), c.conn, algorithm, c.zstdCompressionLevel, side)
+}
+
+// CompressionAlgorithm returns the algorithm compressing the packets of
+// the connection, or CompressionNone.
+func (c *Conn) CompressionAlgorithm() CompressionAlgorithm {
+	if c.compressed == nil {
+		return CompressionNone
+	}
+	return c.compressed.algorithm
+}
+
+// CompressionStats returns the bytes read and written by the connection,
+// before and after compression. They are zero if the connection is not
+// compressed.
+func (c *Conn) CompressionStats() CompressionStats {
+	if c.compressed == nil {
+		return CompressionStats{}
+	}
+	return c.compressed.stats()
+}
+
+// resetSequence resets the sequence of the packets, and of the compressed
+// packets, at the start of a new command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	if c.compressed != nil {
+		c.compressed.sequence = 0
+	}
+}
+
 func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	// Note io.ReadFull will return two different types of errors:
 	// 1. if the socket is already closed, and the go runtime knows it,
@@ -400,7 +480,12 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	}
 
 	sequence := uint8(c.header[3])
-	if sequence != c.sequence {
+	if c.compressed != nil {
+		// MySQL does not check the sequence of the packets carried by
+		// compressed packets, and numbers them along the compressed
+		// packets it read: follow it.
+		c.sequence = sequence
+	} else if sequence != c.sequence {
 		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
 	}
 
@@ -611,7 +696,7 @@ func (c *Conn) writePacket(data []byte) error {
 		}()
 	} else {
 		c.bufMu.Unlock()
-		w = c.conn
+		w = c.getWriter()
 	}
 
 	var header [packetHeaderSize]byte
@@ -715,7 +800,7 @@ func (c *Conn) recycleWritePacket() {
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -921,7 +1006,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -1424,7 +1509,7 @@ func (c *Conn) execQuery(query string, handler Handler, more bool) execResult {
 					lastInsertID:     qr.InsertID,
 					statusFlags:      flag,
 					warnings:         handler.WarningCount(c),
-					info:             "",
+					info:             qr.Info,
 					sessionStateData: qr.SessionStateChanges,
 				}
 				return c.writeOKPacket(&ok)
@@ -1560,8 +1645,14 @@ func (c *Conn) parseOKPacket(packetOK *PacketOK, in []byte) error {
 	}
 	packetOK.warnings = warnings
 
-	// info
-	info, _ := data.readLenEncInfo()
+	// info, length encoded with session tracking, the rest of the
+	// packet otherwise.
+	var info string
+	if c.Capabilities&uint32(CapabilityClientSessionTrack) == CapabilityClientSessionTrack {
+		info, _ = data.readLenEncInfo()
+	} else {
+		info = string(data.data[data.pos:])
+	}
 	if c.enableQueryInfo {
 		packetOK.info = info
 	}
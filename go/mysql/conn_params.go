@@ -17,6 +17,7 @@ limitations under the License.
 package mysql
 
 import (
+	"io"
 	"time"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -64,7 +65,26 @@ type ConnParams struct {
 	// FlushDelay is the delay after which buffered response will be flushed to the client.
 	FlushDelay time.Duration
 
+	// Compression is the algorithm compressing the packets of the
+	// connection. The connection is not compressed if the server does
+	// not support it. It is not compressed by default.
+	Compression CompressionAlgorithm
+
+	// ZstdCompressionLevel is the zstd compression level, from 1 to 22,
+	// when Compression is CompressionZstd. DefaultZstdCompressionLevel
+	// is used if it is not set.
+	ZstdCompressionLevel int
+
 	TruncateErrLen int
+
+	// LocalInfile opens the files the server asks for to run the LOAD DATA
+	// LOCAL INFILE statements. They are rejected if it is not set.
+	LocalInfile LocalInfileOpener
+}
+
+// LocalInfileOpener opens the files of LOAD DATA LOCAL INFILE statements.
+type LocalInfileOpener interface {
+	OpenLocalInfile(filename string) (io.ReadCloser, error)
 }
 
 // EnableSSL will set the right flag on the parameters.
@@ -76,15 +76,18 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Use zlib to compress the packets. Only negotiated when enabled,
+	// as CPU is usually our bottleneck.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
 
-	// CLIENT_LOCAL_FILES 1 << 7
+	// CapabilityClientLocalFiles is CLIENT_LOCAL_FILES.
 	// Client can use LOCAL INFILE request of LOAD DATA|XML.
-	// We do not set it.
+	// We only set it if the Listener allows LocalInfile.
+	CapabilityClientLocalFiles = 1 << 7
 
 	// CLIENT_IGNORE_SPACE 1 << 8
 	// Parser can ignore spaces before '('.
@@ -148,8 +151,41 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CLIENT_OPTIONAL_RESULTSET_METADATA 1 << 25
+	// Not supported.
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Use zstd to compress the packets, at the level sent by the client
+	// at the end of its handshake response.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+)
+
+// Connection attributes commonly sent by the clients.
+// See https://dev.mysql.com/doc/refman/8.0/en/performance-schema-connection-attribute-tables.html
+const (
+	// ConnAttrProgramName is the name of the client program.
+	ConnAttrProgramName = "program_name"
+
+	// ConnAttrClientName is the name of the client library.
+	ConnAttrClientName = "_client_name"
+
+	// ConnAttrClientVersion is the version of the client library.
+	ConnAttrClientVersion = "_client_version"
 )
 
+// ClientProgram returns the program_name and _client_version connection
+// attributes sent by a client, which are empty if it did not send them.
+// The name of the client library, _client_name, is returned for the clients
+// which do not send the name of their program.
+func ClientProgram(attrs map[string]string) (program, version string) {
+	program = attrs[ConnAttrProgramName]
+	if program == "" {
+		program = attrs[ConnAttrClientName]
+	}
+	return program, attrs[ConnAttrClientVersion]
+}
+
 // Status flags. They are returned by the server in a few cases.
 // Originally found in include/mysql/mysql_com.h
 // See http://dev.mysql.com/doc/internals/en/status-flags.html
@@ -255,6 +291,10 @@ const (
 	// ErrPacket is the header of the error packet.
 	ErrPacket = 0xff
 
+	// LocalInfilePacket is the header of the packet requesting the
+	// content of a LOAD DATA LOCAL INFILE file from the client.
+	LocalInfilePacket = 0xfb
+
 	// NullValue is the encoded value of NULL.
 	NullValue = 0xfb
 )
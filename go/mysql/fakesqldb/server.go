@@ -151,6 +151,7 @@ type exprResult struct {
 	expr         *regexp.Regexp
 	result       *sqltypes.Result
 	err          string
+	rejectErr    error
 }
 
 // ExpectedExecuteFetch defines for an expected query the to be faked output.
@@ -431,6 +432,9 @@ func (db *DB) HandleQuery(c *mysql.Conn, query string, callback func(*sqltypes.R
 			if ok {
 				userCallback(query)
 			}
+			if pat.rejectErr != nil {
+				return pat.rejectErr
+			}
 			if pat.err != "" {
 				return fmt.Errorf(pat.err)
 			}
@@ -600,6 +604,15 @@ func (db *DB) RejectQueryPattern(queryPattern, error string) {
 	db.patternData[queryPattern] = exprResult{queryPattern: queryPattern, expr: expr, err: error}
 }
 
+// RejectQueryPatternWithError allows a query pattern to be rejected with the
+// given error, e.g. a *sqlerror.SQLError to return a specific MySQL error code.
+func (db *DB) RejectQueryPatternWithError(queryPattern string, err error) {
+	expr := regexp.MustCompile("(?is)^" + queryPattern + "$")
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.patternData[queryPattern] = exprResult{queryPattern: queryPattern, expr: expr, rejectErr: err}
+}
+
 // ClearQueryPattern removes all query patterns set up
 func (db *DB) ClearQueryPattern() {
 	db.mu.Lock()
@@ -852,6 +865,9 @@ func (db *DB) GetQueryPatternResult(key string) (func(string), ExpectedResult, b
 		if pat.expr.MatchString(key) {
 			userCallback, ok := db.queryPatternUserCallback[pat.expr]
 			if ok {
+				if pat.rejectErr != nil {
+					return userCallback, ExpectedResult{pat.result, nil}, true, pat.rejectErr
+				}
 				if pat.err != "" {
 					return userCallback, ExpectedResult{pat.result, nil}, true, fmt.Errorf(pat.err)
 				}
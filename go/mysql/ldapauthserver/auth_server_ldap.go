@@ -17,6 +17,8 @@ limitations under the License.
 package ldapauthserver
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -28,12 +30,19 @@ import (
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/netutil"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/vttls"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
+var (
+	authAttempts = stats.NewCountersWithSingleLabel("LdapAuthAttempts", "Number of LDAP authentication attempts, by result", "Result")
+	authTimings  = stats.NewTimings("LdapAuthTimings", "Time spent authenticating against the LDAP server", "Operation")
+	serverUp     = stats.NewGauge("LdapAuthServerUp", "Whether the last connection to the LDAP server succeeded (1) or failed (0)")
+)
+
 // AuthServerLdap implements AuthServer with an LDAP backend
 type AuthServerLdap struct {
 	Client
@@ -43,7 +52,24 @@ type AuthServerLdap struct {
 	GroupQuery     string
 	UserDnPattern  string
 	RefreshSeconds int64
-	methods        []mysql.AuthMethod
+	// RoleMapping maps LDAP groups to additional roles, which are added to
+	// the groups of the caller ID of the users in these groups.
+	RoleMapping map[string][]string
+	// CacheSeconds is how long a successful bind is cached, to avoid binding
+	// against the LDAP server on every connection. 0 disables the cache.
+	CacheSeconds int64
+	methods      []mysql.AuthMethod
+
+	cacheMu sync.Mutex
+	cache   map[string]*cachedBind
+}
+
+// cachedBind is a successful bind, keyed by username, along with a hash of
+// the password used.
+type cachedBind struct {
+	passwordHash [sha256.Size]byte
+	expires      time.Time
+	userData     *LdapUserData
 }
 
 // Init is public so it can be called from plugin_auth_ldap.go (go/cmd/vtgate)
@@ -116,18 +142,71 @@ func (asl *AuthServerLdap) UserEntryWithPassword(conn *mysql.Conn, user string,
 }
 
 func (asl *AuthServerLdap) validate(username, password string) (mysql.Getter, error) {
+	passwordHash := sha256.Sum256([]byte(password))
+	if lud := asl.cachedUserData(username, passwordHash); lud != nil {
+		authAttempts.Add("cached", 1)
+		return lud, nil
+	}
+
+	defer authTimings.Record("bind", time.Now())
 	if err := asl.Client.Connect("tcp", &asl.ServerConfig); err != nil {
+		serverUp.Set(0)
+		authAttempts.Add("error", 1)
 		return nil, err
 	}
+	serverUp.Set(1)
 	defer asl.Client.Close()
 	if err := asl.Client.Bind(fmt.Sprintf(asl.UserDnPattern, username), password); err != nil {
+		authAttempts.Add("denied", 1)
 		return nil, err
 	}
 	groups, err := asl.getGroups(username)
 	if err != nil {
+		authAttempts.Add("error", 1)
 		return nil, err
 	}
-	return &LdapUserData{asl: asl, groups: groups, username: username, lastUpdated: time.Now(), updating: false}, nil
+	authAttempts.Add("success", 1)
+	lud := &LdapUserData{asl: asl, groups: groups, username: username, lastUpdated: time.Now(), updating: false}
+	asl.cacheUserData(username, passwordHash, lud)
+	return lud, nil
+}
+
+func (asl *AuthServerLdap) cachedUserData(username string, passwordHash [sha256.Size]byte) *LdapUserData {
+	if asl.CacheSeconds <= 0 {
+		return nil
+	}
+
+	asl.cacheMu.Lock()
+	defer asl.cacheMu.Unlock()
+	entry, ok := asl.cache[username]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(asl.cache, username)
+		return nil
+	}
+	if subtle.ConstantTimeCompare(entry.passwordHash[:], passwordHash[:]) != 1 {
+		return nil
+	}
+	return entry.userData
+}
+
+func (asl *AuthServerLdap) cacheUserData(username string, passwordHash [sha256.Size]byte, lud *LdapUserData) {
+	if asl.CacheSeconds <= 0 {
+		return
+	}
+
+	asl.cacheMu.Lock()
+	defer asl.cacheMu.Unlock()
+	if asl.cache == nil {
+		asl.cache = make(map[string]*cachedBind)
+	}
+	asl.cache[username] = &cachedBind{
+		passwordHash: passwordHash,
+		expires:      time.Now().Add(time.Duration(asl.CacheSeconds) * time.Second),
+		userData:     lud,
+	}
 }
 
 // this needs to be passed an already connected client...should check for this
@@ -175,11 +254,14 @@ func (lud *LdapUserData) update() {
 	}
 	lud.updating = true
 	lud.Unlock()
+	defer authTimings.Record("refresh", time.Now())
 	err := lud.asl.Client.Connect("tcp", &lud.asl.ServerConfig)
 	if err != nil {
+		serverUp.Set(0)
 		log.Errorf("Error updating LDAP user data: %v", err)
 		return
 	}
+	serverUp.Set(1)
 	defer lud.asl.Client.Close() //after the error check
 	groups, err := lud.asl.getGroups(lud.username)
 	if err != nil {
@@ -193,12 +275,16 @@ func (lud *LdapUserData) update() {
 	lud.Unlock()
 }
 
-// Get returns wrapped username and LDAP groups and possibly updates the cache
+// Get returns wrapped username and LDAP groups, along with the roles mapped
+// to them, and possibly updates the cache
 func (lud *LdapUserData) Get() *querypb.VTGateCallerID {
-	if int64(time.Since(lud.lastUpdated).Seconds()) > lud.asl.RefreshSeconds {
+	lud.Lock()
+	lastUpdated, groups := lud.lastUpdated, lud.groups
+	lud.Unlock()
+	if int64(time.Since(lastUpdated).Seconds()) > lud.asl.RefreshSeconds {
 		go lud.update()
 	}
-	return &querypb.VTGateCallerID{Username: lud.username, Groups: lud.groups}
+	return &querypb.VTGateCallerID{Username: lud.username, Groups: mysql.ExpandGroupRoles(groups, lud.asl.RoleMapping)}
 }
 
 // ServerConfig holds the config for and LDAP server
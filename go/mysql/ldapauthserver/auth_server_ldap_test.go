@@ -53,3 +53,47 @@ func TestValidateClearText(t *testing.T) {
 	require.Error(t, err, "AuthServerLdap validated invalid credentials.")
 
 }
+
+// countingLdapClient wraps MockLdapClient, counting connections and
+// returning a fixed group.
+type countingLdapClient struct {
+	MockLdapClient
+	connects int
+}
+
+func (clc *countingLdapClient) Connect(network string, config *ServerConfig) error {
+	clc.connects++
+	return nil
+}
+
+func (clc *countingLdapClient) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	return &ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry("cn=dba", map[string][]string{"cn": {"dba"}})}}, nil
+}
+
+func TestValidateCacheAndRoleMapping(t *testing.T) {
+	client := &countingLdapClient{}
+	asl := &AuthServerLdap{
+		Client:         client,
+		User:           "testuser",
+		Password:       "testpass",
+		UserDnPattern:  "%s",
+		RefreshSeconds: 3600,
+		CacheSeconds:   3600,
+		RoleMapping:    map[string][]string{"dba": {"admin", "dba"}},
+	}
+
+	getter, err := asl.validate("testuser", "testpass")
+	require.NoError(t, err)
+	require.Equal(t, 1, client.connects)
+	require.Equal(t, []string{"dba", "admin"}, getter.Get().Groups)
+
+	// A second login with the same password is served from the cache.
+	_, err = asl.validate("testuser", "testpass")
+	require.NoError(t, err)
+	require.Equal(t, 1, client.connects)
+
+	// A different password is never served from the cache.
+	_, err = asl.validate("testuser", "otherpass")
+	require.Error(t, err)
+	require.Equal(t, 2, client.connects)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"io"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+)
+
+// LocalInfileEnabled returns true if the client of the connection accepts
+// to send the files of LOAD DATA LOCAL INFILE statements.
+func (c *Conn) LocalInfileEnabled() bool {
+	return c.Capabilities&CapabilityClientLocalFiles != 0
+}
+
+// RequestLocalInfile asks the client for the content of the file of a LOAD
+// DATA LOCAL INFILE statement. It must be called from Handler.ComQuery,
+// before the callback is. The returned reader streams the packets the
+// client sends, and must be closed before ComQuery returns: Close reads
+// whatever the client did not send yet, so that the result of the statement
+// follows the end of the file.
+// Server -> Client.
+func (c *Conn) RequestLocalInfile(filename string) (io.ReadCloser, error) {
+	if !c.LocalInfileEnabled() {
+		return nil, sqlerror.NewSQLError(sqlerror.ERNotAllowedCommand, sqlerror.SSClientError, "Loading local data is disabled; this must be enabled on both the client and server sides")
+	}
+
+	data, pos := c.startEphemeralPacketWithHeader(1 + len(filename))
+	data[pos] = LocalInfilePacket
+	copy(data[pos+1:], filename)
+	if err := c.writeEphemeralPacket(); err != nil {
+		return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+	// The client only sends the file once it got the request.
+	if err := c.flush(); err != nil {
+		return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+	return &localInfileReader{c: c}, nil
+}
+
+// flush writes the buffered packets, if the writes are buffered.
+func (c *Conn) flush() error {
+	c.bufMu.Lock()
+	defer c.bufMu.Unlock()
+
+	if c.bufferedWriter == nil {
+		return nil
+	}
+	return c.bufferedWriter.Flush()
+}
+
+// localInfileReader reads the content of a file the client sends, as
+// packets ending with an empty one.
+type localInfileReader struct {
+	c    *Conn
+	data []byte
+	done bool
+	err  error
+}
+
+// Read is part of the io.Reader interface.
+func (r *localInfileReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.next()
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Close is part of the io.Closer interface. It reads the rest of the file.
+func (r *localInfileReader) Close() error {
+	for !r.done && r.err == nil {
+		r.next()
+	}
+	r.data = nil
+	return r.err
+}
+
+func (r *localInfileReader) next() {
+	data, err := r.c.readPacket()
+	if err != nil {
+		r.err = sqlerror.NewSQLError(sqlerror.CRServerLost, sqlerror.SSUnknownSQLState, "%v", err)
+		return
+	}
+	if len(data) == 0 {
+		r.done = true
+		return
+	}
+	r.data = data
+}
+
+// localInfileChunkSize is the size of the packets the files are sent in.
+const localInfileChunkSize = 16 * 1024
+
+// sendLocalInfile sends the file of a LOAD DATA LOCAL INFILE statement the
+// server asked for, in packets ending with an empty one. If the file cannot
+// be opened or read, the client sends what it read and returns the error of
+// the file once the server responded. err is only set if the connection
+// failed.
+// Client -> Server.
+func (c *Conn) sendLocalInfile(filename string) (fileErr, err error) {
+	var file io.ReadCloser
+	if c.localInfile == nil {
+		fileErr = sqlerror.NewSQLError(sqlerror.CRLoadDataLocalInfileRejected, sqlerror.SSUnknownSQLState, "LOAD DATA LOCAL INFILE file request rejected due to restrictions on access.")
+	} else {
+		file, fileErr = c.localInfile.OpenLocalInfile(filename)
+	}
+	if file != nil {
+		defer file.Close()
+		data := make([]byte, packetHeaderSize+localInfileChunkSize)
+		for {
+			n, err := file.Read(data[packetHeaderSize:])
+			if n > 0 {
+				if err := c.writePacket(data[:packetHeaderSize+n]); err != nil {
+					return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				fileErr = err
+				break
+			}
+		}
+	}
+	if err := c.writePacket(make([]byte, packetHeaderSize)); err != nil {
+		return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+	return fileErr, nil
+}
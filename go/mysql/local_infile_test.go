@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// localInfileHandler requests the file of every query, and reads the given
+// number of bytes of it.
+type localInfileHandler struct {
+	testRun
+	limit int
+}
+
+func (h *localInfileHandler) ComQuery(c *Conn, query string, callback func(*sqltypes.Result) error) error {
+	r, err := c.RequestLocalInfile(query)
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(io.LimitReader(r, int64(h.limit)))
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	return callback(&sqltypes.Result{
+		RowsAffected: uint64(len(content)),
+		Info:         string(content),
+	})
+}
+
+func TestRequestLocalInfile(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+	cConn.enableQueryInfo = true
+
+	// sendFile answers the request of the server with the chunks.
+	sendFile := func(filename string, chunks ...string) {
+		data, err := cConn.readPacket()
+		require.NoError(t, err)
+		require.Equal(t, append([]byte{LocalInfilePacket}, filename...), data)
+		for _, chunk := range append(chunks, "") {
+			packet := make([]byte, packetHeaderSize+len(chunk))
+			copy(packet[packetHeaderSize:], chunk)
+			require.NoError(t, cConn.writePacket(packet))
+		}
+	}
+
+	tcases := []struct {
+		limit    int
+		chunks   []string
+		expected string
+	}{
+		{limit: 100, chunks: []string{"1,a\n", "2,b\n"}, expected: "1,a\n2,b\n"},
+		{limit: 100},
+		// The rest of the file is read after the handler is done with it.
+		{limit: 5, chunks: []string{"1,a\n", "2,b\n", "3,c\n"}, expected: "1,a\n2"},
+	}
+	for i, tcase := range tcases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			sConn.Capabilities |= CapabilityClientLocalFiles
+			filename := fmt.Sprintf("/tmp/file%d.csv", i)
+			require.NoError(t, cConn.WriteComQuery(filename))
+
+			done := make(chan bool)
+			go func() {
+				defer close(done)
+				assert.True(t, sConn.handleNextCommand(&localInfileHandler{testRun: testRun{t: t}, limit: tcase.limit}))
+			}()
+			sendFile(filename, tcase.chunks...)
+			result, _, _, err := cConn.ReadQueryResult(100, true)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(tcase.expected), result.RowsAffected)
+			assert.Equal(t, tcase.expected, result.Info)
+			<-done
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		sConn.Capabilities &^= CapabilityClientLocalFiles
+		require.NoError(t, cConn.WriteComQuery("/tmp/file.csv"))
+		assert.True(t, sConn.handleNextCommand(&localInfileHandler{testRun: testRun{t: t}}))
+		_, _, _, err := cConn.ReadQueryResult(100, true)
+		assert.ErrorContains(t, err, "Loading local data is disabled")
+		sqlErr, ok := err.(*sqlerror.SQLError)
+		require.True(t, ok)
+		assert.Equal(t, sqlerror.ERNotAllowedCommand, sqlErr.Num)
+	})
+}
+
+// localInfileFiles maps the names of the files to their content.
+type localInfileFiles map[string]string
+
+func (files localInfileFiles) OpenLocalInfile(filename string) (io.ReadCloser, error) {
+	content, ok := files[filename]
+	if !ok {
+		return nil, fmt.Errorf("open %s: no such file or directory", filename)
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func TestSendLocalInfile(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+	sConn.Capabilities |= CapabilityClientLocalFiles
+	cConn.enableQueryInfo = true
+
+	content := strings.Repeat("1,abc\n", localInfileChunkSize/3)
+	files := localInfileFiles{"/tmp/file.csv": content}
+
+	tcases := []struct {
+		filename    string
+		localInfile LocalInfileOpener
+		expected    string
+		err         string
+	}{{
+		filename:    "/tmp/file.csv",
+		localInfile: files,
+		expected:    content,
+	}, {
+		// The server gets an empty file.
+		filename:    "/tmp/missing.csv",
+		localInfile: files,
+		err:         "open /tmp/missing.csv: no such file or directory",
+	}, {
+		filename: "/tmp/file.csv",
+		err:      "LOAD DATA LOCAL INFILE file request rejected due to restrictions on access.",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.filename, func(t *testing.T) {
+			cConn.localInfile = tcase.localInfile
+			done := make(chan bool)
+			go func() {
+				defer close(done)
+				assert.True(t, sConn.handleNextCommand(&localInfileHandler{testRun: testRun{t: t}, limit: len(content)}))
+			}()
+			result, err := cConn.ExecuteFetch(tcase.filename, 100, false)
+			<-done
+			if tcase.err != "" {
+				assert.ErrorContains(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, len(tcase.expected), result.RowsAffected)
+			assert.Equal(t, tcase.expected, result.Info)
+		})
+	}
+
+	// The connection is still in sync.
+	cConn.localInfile = files
+	go sConn.handleNextCommand(&localInfileHandler{testRun: testRun{t: t}, limit: 2})
+	result, err := cConn.ExecuteFetch("/tmp/file.csv", 100, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1,", result.Info)
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package oidcauthserver implements a MySQL AuthServer validating OIDC
+// tokens, passed by clients in the password field.
+package oidcauthserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"vitess.io/vitess/go/cache"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	authAttempts  = stats.NewCountersWithSingleLabel("OidcAuthAttempts", "Number of OIDC authentication attempts, by result", "Result")
+	jwksRefreshes = stats.NewCountersWithSingleLabel("OidcJWKSRefreshes", "Number of refreshes of the OIDC signing keys, by result", "Result")
+	jwksAge       = stats.NewGaugeFunc("OidcJWKSAgeSeconds", "Seconds since the OIDC signing keys were last refreshed successfully", func() int64 {
+		if registered == nil {
+			return 0
+		}
+		return int64(registered.keys.age().Seconds())
+	})
+
+	registered *AuthServerOIDC
+)
+
+// Config is the JSON configuration of AuthServerOIDC.
+type Config struct {
+	// Issuer is the expected "iss" claim of the tokens.
+	Issuer string
+	// Audience is the expected "aud" claim of the tokens. It is required, so
+	// that tokens issued to other clients of the provider are rejected.
+	Audience string
+	// JWKSURL is the URL of the JSON Web Key Set used to sign the tokens.
+	JWKSURL string
+	// JWKSFile is a file containing the JSON Web Key Set, used instead of
+	// JWKSURL when set.
+	JWKSFile string
+	// JWKSRefreshSeconds is how often the key set is refreshed. Defaults to
+	// one hour. Unknown key IDs trigger an early refresh.
+	JWKSRefreshSeconds int64
+	// JWKSMinRefreshSeconds is the minimum time between two refreshes of the
+	// key set. Defaults to 10 seconds.
+	JWKSMinRefreshSeconds int64
+	// UsernameClaim is the claim holding the username, which must match the
+	// MySQL user. Defaults to "sub".
+	UsernameClaim string
+	// GroupsClaim is the claim holding the list of groups of the user.
+	// Defaults to "groups".
+	GroupsClaim string
+	// RoleMapping maps groups to additional roles, which are added to the
+	// groups of the caller ID of the users in these groups.
+	RoleMapping map[string][]string
+	// ClockSkewSeconds is the leeway allowed when checking the token
+	// validity period. Defaults to 60 seconds.
+	ClockSkewSeconds int64
+	// TokenCacheSize is the maximum number of validated tokens cached, after
+	// which the least recently used ones are evicted. Defaults to 10000.
+	TokenCacheSize int64
+}
+
+// AuthServerOIDC implements AuthServer, validating the OIDC token passed in
+// the password field with the mysql_clear_password method.
+type AuthServerOIDC struct {
+	Config
+
+	keys    *keySet
+	methods []mysql.AuthMethod
+
+	cache *cache.LRUCache[*cachedToken]
+}
+
+type cachedToken struct {
+	user     string
+	expires  time.Time
+	userData *mysql.StaticUserData
+}
+
+// Init is public so it can be called from plugin_auth_oidc.go (go/cmd/vtgate)
+func Init(oidcAuthConfigFile, oidcAuthConfigString string) {
+	if oidcAuthConfigFile == "" && oidcAuthConfigString == "" {
+		log.Infof("Not configuring AuthServerOIDC because mysql_oidc_auth_config_file and mysql_oidc_auth_config_string are empty")
+		return
+	}
+	if oidcAuthConfigFile != "" && oidcAuthConfigString != "" {
+		log.Infof("Both mysql_oidc_auth_config_file and mysql_oidc_auth_config_string are non-empty, can only use one.")
+		return
+	}
+
+	data := []byte(oidcAuthConfigString)
+	if oidcAuthConfigFile != "" {
+		var err error
+		data, err = os.ReadFile(oidcAuthConfigFile)
+		if err != nil {
+			log.Exitf("Failed to read mysql_oidc_auth_config_file: %v", err)
+		}
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Exitf("Error parsing AuthServerOIDC config: %v", err)
+	}
+
+	asoidc, err := NewAuthServerOIDC(config)
+	if err != nil {
+		log.Exitf("Error configuring AuthServerOIDC: %v", err)
+	}
+	registered = asoidc
+	mysql.RegisterAuthServer("oidc", asoidc)
+}
+
+// NewAuthServerOIDC returns an AuthServerOIDC for the given config, after
+// loading the signing keys.
+func NewAuthServerOIDC(config Config) (*AuthServerOIDC, error) {
+	if config.Issuer == "" {
+		return nil, fmt.Errorf("Issuer is required")
+	}
+	if config.Audience == "" {
+		return nil, fmt.Errorf("Audience is required")
+	}
+	if (config.JWKSURL == "") == (config.JWKSFile == "") {
+		return nil, fmt.Errorf("exactly one of JWKSURL and JWKSFile is required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.JWKSRefreshSeconds <= 0 {
+		config.JWKSRefreshSeconds = 3600
+	}
+	if config.JWKSMinRefreshSeconds <= 0 {
+		config.JWKSMinRefreshSeconds = 10
+	}
+	if config.ClockSkewSeconds <= 0 {
+		config.ClockSkewSeconds = 60
+	}
+	if config.TokenCacheSize <= 0 {
+		config.TokenCacheSize = 10000
+	}
+
+	asoidc := &AuthServerOIDC{
+		Config: config,
+		keys: &keySet{
+			url:                config.JWKSURL,
+			file:               config.JWKSFile,
+			refreshTTL:         time.Duration(config.JWKSRefreshSeconds) * time.Second,
+			minRefreshInterval: time.Duration(config.JWKSMinRefreshSeconds) * time.Second,
+			client:             &http.Client{Timeout: 10 * time.Second},
+		},
+		cache: cache.NewLRUCache[*cachedToken](config.TokenCacheSize),
+	}
+	if err := asoidc.keys.refresh(); err != nil {
+		return nil, err
+	}
+	asoidc.methods = []mysql.AuthMethod{mysql.NewMysqlClearAuthMethod(asoidc, asoidc)}
+	return asoidc, nil
+}
+
+// AuthMethods returns the list of registered auth methods
+// implemented by this auth server.
+func (asoidc *AuthServerOIDC) AuthMethods() []mysql.AuthMethod {
+	return asoidc.methods
+}
+
+// DefaultAuthMethodDescription returns MysqlNativePassword as the default
+// authentication method for the auth server implementation.
+func (asoidc *AuthServerOIDC) DefaultAuthMethodDescription() mysql.AuthMethodDescription {
+	return mysql.MysqlNativePassword
+}
+
+// HandleUser is part of the UserValidator interface. We
+// handle any user here since we don't check up front.
+func (asoidc *AuthServerOIDC) HandleUser(user string) bool {
+	return true
+}
+
+// UserEntryWithPassword is part of the PlaintextStorage interface
+// and called after the token is sent by the client as the password.
+func (asoidc *AuthServerOIDC) UserEntryWithPassword(conn *mysql.Conn, user string, password string, remoteAddr net.Addr) (mysql.Getter, error) {
+	userData, err := asoidc.validate(user, password, time.Now())
+	if err != nil {
+		log.Infof("OIDC authentication of user %q from %v failed: %v", user, remoteAddr, err)
+		return nil, err
+	}
+	return userData, nil
+}
+
+func (asoidc *AuthServerOIDC) validate(user, token string, now time.Time) (*mysql.StaticUserData, error) {
+	hash := sha256.Sum256([]byte(token))
+	key := string(hash[:])
+	entry, ok := asoidc.cache.Get(key)
+	if ok && now.After(entry.expires) {
+		asoidc.cache.Delete(key)
+		ok = false
+	}
+	if ok {
+		if entry.user != user {
+			authAttempts.Add("denied", 1)
+			return nil, fmt.Errorf("token was not issued for user %s", user)
+		}
+		authAttempts.Add("cached", 1)
+		return entry.userData, nil
+	}
+
+	claims, err := verifyJWT(token, asoidc.keys)
+	if err != nil {
+		authAttempts.Add("denied", 1)
+		return nil, err
+	}
+
+	expires, err := asoidc.checkClaims(claims, now)
+	if err != nil {
+		authAttempts.Add("denied", 1)
+		return nil, err
+	}
+
+	username, _ := claims[asoidc.UsernameClaim].(string)
+	if username != user {
+		authAttempts.Add("denied", 1)
+		return nil, fmt.Errorf("token was not issued for user %s", user)
+	}
+
+	userData := &mysql.StaticUserData{
+		Username: username,
+		Groups:   mysql.ExpandGroupRoles(stringList(claims[asoidc.GroupsClaim]), asoidc.RoleMapping),
+	}
+
+	asoidc.cache.Set(key, &cachedToken{user: username, expires: expires, userData: userData})
+
+	authAttempts.Add("success", 1)
+	return userData, nil
+}
+
+// checkClaims checks the issuer, audience and validity period of the token,
+// returning its expiration time.
+func (asoidc *AuthServerOIDC) checkClaims(claims map[string]any, now time.Time) (time.Time, error) {
+	if iss, _ := claims["iss"].(string); iss != asoidc.Issuer {
+		return time.Time{}, fmt.Errorf("unexpected token issuer %q", iss)
+	}
+
+	if !slices.Contains(stringList(claims["aud"]), asoidc.Audience) {
+		return time.Time{}, fmt.Errorf("token audience does not include %q", asoidc.Audience)
+	}
+
+	skew := time.Duration(asoidc.ClockSkewSeconds) * time.Second
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("token has no expiration")
+	}
+	expires := time.Unix(int64(exp), 0)
+	if now.After(expires.Add(skew)) {
+		return time.Time{}, fmt.Errorf("token expired at %v", expires)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(skew).Before(time.Unix(int64(nbf), 0)) {
+		return time.Time{}, fmt.Errorf("token not valid before %v", time.Unix(int64(nbf), 0))
+	}
+	return expires, nil
+}
+
+// stringList returns the claim as a list of strings. Claims may hold either a
+// single string or a list of strings.
+func stringList(claim any) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []any:
+		list := make([]string, 0, len(claim))
+		for _, v := range claim {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
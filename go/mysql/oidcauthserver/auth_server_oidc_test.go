@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidcauthserver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + b64(signature)
+}
+
+func TestAuthServerOIDC(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "rsa1",
+		"use": "sig",
+		"n":   b64(rsaKey.N.Bytes()),
+		"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}, {
+		"kty": "EC",
+		"kid": "ec1",
+		"crv": "P-256",
+		"x":   b64(ecKey.X.FillBytes(make([]byte, 32))),
+		"y":   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+	}}})
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	asoidc, err := NewAuthServerOIDC(Config{
+		Issuer:      "https://issuer.example.com",
+		Audience:    "vitess",
+		JWKSURL:     server.URL,
+		RoleMapping: map[string][]string{"dba": {"admin"}},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"vitess", "other"},
+			"sub":    "alice",
+			"groups": []string{"dba"},
+			"exp":    now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	testcases := []struct {
+		name    string
+		user    string
+		token   string
+		wantErr string
+	}{{
+		name:  "rsa",
+		user:  "alice",
+		token: signToken(t, "RS256", "rsa1", rsaKey, claims(nil)),
+	}, {
+		name:  "ecdsa",
+		user:  "alice",
+		token: signToken(t, "ES256", "ec1", ecKey, claims(map[string]any{"iat": now.Unix()})),
+	}, {
+		name:    "wrong user",
+		user:    "bob",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"nonce": 1})),
+		wantErr: "not issued for user bob",
+	}, {
+		name:    "bad signature",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", otherKey, claims(nil)),
+		wantErr: "invalid token signature",
+	}, {
+		name:    "unknown key",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa2", rsaKey, claims(nil)),
+		wantErr: "unknown signing key",
+	}, {
+		name:    "expired",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})),
+		wantErr: "token expired",
+	}, {
+		name:    "not yet valid",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})),
+		wantErr: "not valid before",
+	}, {
+		name:    "wrong issuer",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"iss": "https://evil.example.com"})),
+		wantErr: "unexpected token issuer",
+	}, {
+		name:    "wrong audience",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"aud": "other"})),
+		wantErr: "audience",
+	}, {
+		name:    "no audience",
+		user:    "alice",
+		token:   signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"aud": nil})),
+		wantErr: "audience",
+	}, {
+		name:    "algorithm mismatch",
+		user:    "alice",
+		token:   signToken(t, "ES256", "rsa1", rsaKey, claims(nil)),
+		wantErr: "does not match RSA key",
+	}, {
+		name:    "malformed",
+		user:    "alice",
+		token:   "not-a-token",
+		wantErr: "malformed token",
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			userData, err := asoidc.validate(tc.user, tc.token, now)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			callerID := userData.Get()
+			assert.Equal(t, "alice", callerID.Username)
+			assert.Equal(t, []string{"dba", "admin"}, callerID.Groups)
+		})
+	}
+
+	// A cached token is still bound to its user.
+	token := signToken(t, "RS256", "rsa1", rsaKey, claims(nil))
+	_, err = asoidc.validate("alice", token, now)
+	require.NoError(t, err)
+	_, err = asoidc.validate("bob", token, now)
+	require.ErrorContains(t, err, "not issued for user bob")
+	// And expires with it.
+	_, err = asoidc.validate("alice", token, now.Add(2*time.Hour))
+	require.ErrorContains(t, err, "token expired")
+
+	// The least recently used tokens are evicted from the cache.
+	asoidc.cache.SetCapacity(1)
+	for i := range 3 {
+		_, err = asoidc.validate("alice", signToken(t, "RS256", "rsa1", rsaKey, claims(map[string]any{"nonce": i})), now)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, asoidc.cache.Len())
+
+	// The audience is required.
+	_, err = NewAuthServerOIDC(Config{
+		Issuer:  "https://issuer.example.com",
+		JWKSURL: server.URL,
+	})
+	require.ErrorContains(t, err, "Audience is required")
+}
+
+func TestKeySetRefreshLimit(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "rsa1",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	ks := &keySet{
+		url:                server.URL,
+		refreshTTL:         time.Hour,
+		minRefreshInterval: time.Hour,
+		client:             server.Client(),
+	}
+	require.NoError(t, ks.refresh())
+	assert.Equal(t, 1, fetches)
+
+	// Unknown key IDs do not refresh the key set more than once per interval.
+	for _, kid := range []string{"rsa2", "rsa2", "rsa3"} {
+		_, err = ks.get(kid)
+		assert.ErrorContains(t, err, "unknown signing key")
+	}
+	assert.Equal(t, 1, fetches)
+
+	// Once the interval elapsed, an unknown key ID refreshes it again, but a
+	// key ID that was already unknown after a refresh does not.
+	ks.minRefreshInterval = 0
+	_, err = ks.get("rsa2")
+	assert.ErrorContains(t, err, "unknown signing key")
+	assert.Equal(t, 2, fetches)
+	_, err = ks.get("rsa2")
+	assert.ErrorContains(t, err, "unknown signing key")
+	assert.Equal(t, 2, fetches)
+	_, err = ks.get("rsa3")
+	assert.ErrorContains(t, err, "unknown signing key")
+	assert.Equal(t, 3, fetches)
+
+	_, err = ks.get("rsa1")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+}
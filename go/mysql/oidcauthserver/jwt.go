@@ -0,0 +1,349 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidcauthserver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	// Register the hash functions used by the supported algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// jwk is a JSON Web Key, as found in a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+const (
+	// unknownKeyTTL is how long a key ID that is still unknown after a
+	// refresh is rejected without refreshing the key set again.
+	unknownKeyTTL = 5 * time.Minute
+	// maxUnknownKeys bounds the number of unknown key IDs remembered, so that
+	// tokens with random key IDs cannot grow it without limit.
+	maxUnknownKeys = 1000
+)
+
+// keySet holds the signing keys, loaded from a JSON Web Key Set URL or file.
+type keySet struct {
+	url        string
+	file       string
+	refreshTTL time.Duration
+	// minRefreshInterval is the minimum time between two fetches of the key
+	// set, whether it is stale or a key ID is unknown.
+	minRefreshInterval time.Duration
+	client             *http.Client
+
+	// refreshMu serializes the refreshes, so that concurrent lookups share
+	// one fetch.
+	refreshMu sync.Mutex
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+	// lastAttempt and lastErr are the time and result of the last fetch.
+	lastAttempt time.Time
+	lastErr     error
+	// unknownKeys holds when each key ID that was unknown after a refresh
+	// can trigger a refresh again.
+	unknownKeys map[string]time.Time
+}
+
+func (ks *keySet) age() time.Duration {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.lastRefresh.IsZero() {
+		return 0
+	}
+	return time.Since(ks.lastRefresh)
+}
+
+func (ks *keySet) fetch() ([]byte, error) {
+	if ks.file != "" {
+		return os.ReadFile(ks.file)
+	}
+
+	resp, err := ks.client.Get(ks.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", ks.url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (ks *keySet) refresh() error {
+	data, err := ks.fetch()
+	defer func() {
+		ks.mu.Lock()
+		ks.lastAttempt, ks.lastErr = time.Now(), err
+		ks.mu.Unlock()
+	}()
+	if err == nil {
+		var set struct {
+			Keys []jwk `json:"keys"`
+		}
+		if err = json.Unmarshal(data, &set); err == nil {
+			keys := make(map[string]crypto.PublicKey, len(set.Keys))
+			for _, k := range set.Keys {
+				if k.Use != "" && k.Use != "sig" {
+					continue
+				}
+				pub, kerr := k.publicKey()
+				if kerr != nil {
+					continue
+				}
+				keys[k.Kid] = pub
+			}
+
+			ks.mu.Lock()
+			ks.keys = keys
+			ks.lastRefresh = time.Now()
+			ks.mu.Unlock()
+		}
+	}
+
+	if err != nil {
+		jwksRefreshes.Add("error", 1)
+		err = fmt.Errorf("failed to load JWKS: %w", err)
+		return err
+	}
+	jwksRefreshes.Add("success", 1)
+	return nil
+}
+
+// refreshLimited refreshes the key set, unless it was fetched less than
+// minRefreshInterval ago, in which case it returns the result of that fetch.
+// It reports whether it fetched the key set.
+func (ks *keySet) refreshLimited() (bool, error) {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	ks.mu.Lock()
+	recent := !ks.lastAttempt.IsZero() && time.Since(ks.lastAttempt) < ks.minRefreshInterval
+	err := ks.lastErr
+	ks.mu.Unlock()
+	if recent {
+		return false, err
+	}
+	return true, ks.refresh()
+}
+
+// rememberUnknown records that kid was unknown after a refresh. ks.mu must
+// be held.
+func (ks *keySet) rememberUnknown(kid string) {
+	now := time.Now()
+	if len(ks.unknownKeys) >= maxUnknownKeys {
+		for k, until := range ks.unknownKeys {
+			if now.After(until) {
+				delete(ks.unknownKeys, k)
+			}
+		}
+		if len(ks.unknownKeys) >= maxUnknownKeys {
+			ks.unknownKeys = nil
+		}
+	}
+	if ks.unknownKeys == nil {
+		ks.unknownKeys = make(map[string]time.Time)
+	}
+	ks.unknownKeys[kid] = now.Add(unknownKeyTTL)
+}
+
+// get returns the key with the given ID, refreshing the key set if it is
+// stale or the key is unknown. Refreshes are rate limited, and a key ID that
+// is still unknown after a refresh does not trigger another one for
+// unknownKeyTTL.
+func (ks *keySet) get(kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	key, ok := ks.keys[kid]
+	stale := time.Since(ks.lastRefresh) > ks.refreshTTL
+	negative := !ok && time.Now().Before(ks.unknownKeys[kid])
+	ks.mu.Unlock()
+	if ok && !stale {
+		return key, nil
+	}
+	if negative {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	fetched, err := ks.refreshLimited()
+	if err != nil {
+		// Keep using the keys we have if the refresh failed.
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if key, ok = ks.keys[kid]; !ok {
+		if fetched {
+			ks.rememberUnknown(kid)
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// verifyJWT checks the signature of a compact serialized JWT and returns its
+// claims. It does not validate the claims themselves.
+func verifyJWT(token string, keys *keySet) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	key, err := keys.get(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+	return claims, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match EC key", alg)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type %T", key)
+}
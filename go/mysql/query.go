@@ -55,7 +55,7 @@ const (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -545,6 +545,21 @@ func (c *Conn) readComQueryResponse(packetOk *PacketOK) (int, error) {
 	if err != nil {
 		return 0, sqlerror.NewSQLError(sqlerror.CRServerLost, sqlerror.SSUnknownSQLState, "%v", err)
 	}
+	if len(data) > 0 && data[0] == LocalInfilePacket {
+		// The server asks for the file of a LOAD DATA LOCAL INFILE
+		// statement, and sends its response once it got it.
+		filename := string(data[1:])
+		c.recycleReadPacket()
+		fileErr, err := c.sendLocalInfile(filename)
+		if err != nil {
+			return 0, err
+		}
+		n, err := c.readComQueryResponse(packetOk)
+		if fileErr != nil {
+			return 0, fileErr
+		}
+		return n, err
+	}
 	defer c.recycleReadPacket()
 	if len(data) == 0 {
 		return 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "invalid empty COM_QUERY response packet")
@@ -556,9 +571,6 @@ func (c *Conn) readComQueryResponse(packetOk *PacketOK) (int, error) {
 	case ErrPacket:
 		// Error
 		return 0, ParseErrorPacket(data)
-	case 0xfb:
-		// Local infile
-		return 0, vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "not implemented")
 	}
 	n, pos, ok := readLenEncInt(data, 0)
 	if !ok {
@@ -0,0 +1,34 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replication contains the types and helpers used to reason about
+// MySQL and MariaDB replication positions.
+//
+// A Position wraps a flavor-specific GTIDSet. Positions are encoded as
+// "<flavor>/<gtid set>" strings by EncodePosition and decoded back with
+// DecodePosition, or with DecodePositionDetectFlavor when the flavor prefix
+// may be missing.
+//
+// Set operations are available on Position:
+//   - AtLeast reports whether a position contains another one.
+//   - Union returns the transactions present in either position.
+//   - Subtract returns the transactions present in one position but not the
+//     other. It is only supported for the MySQL flavor.
+//
+// ErrantGTIDs finds the transactions a replica has that none of its peers
+// have, which is how EmergencyReparentShard rules out candidates with errant
+// GTIDs.
+package replication
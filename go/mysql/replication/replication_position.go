@@ -97,6 +97,39 @@ func (rp Position) IsZero() bool {
 	return rp.GTIDSet == nil
 }
 
+// Union returns a Position that contains every transaction in this position
+// and in other. Either position may be the zero value.
+func (rp Position) Union(other Position) Position {
+	if rp.GTIDSet == nil {
+		return other
+	}
+	if other.GTIDSet == nil {
+		return rp
+	}
+	return Position{GTIDSet: rp.GTIDSet.Union(other.GTIDSet)}
+}
+
+// Subtract returns a Position that contains the transactions in this position
+// that are not in other. Only the MySQL56 flavor supports subtraction, since
+// other flavors can't represent a set with gaps.
+func (rp Position) Subtract(other Position) (Position, error) {
+	if rp.GTIDSet == nil {
+		return rp, nil
+	}
+	lhs, ok := rp.GTIDSet.(Mysql56GTIDSet)
+	if !ok {
+		return Position{}, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "subtract is only supported for the %s flavor, got %s", Mysql56FlavorID, rp.GTIDSet.Flavor())
+	}
+	if other.GTIDSet == nil {
+		return rp, nil
+	}
+	rhs, ok := other.GTIDSet.(Mysql56GTIDSet)
+	if !ok {
+		return Position{}, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot subtract a %s position from a %s position", other.GTIDSet.Flavor(), Mysql56FlavorID)
+	}
+	return Position{GTIDSet: lhs.Difference(rhs)}, nil
+}
+
 // AppendGTID returns a new Position that represents the position
 // after the given GTID is replicated.
 func AppendGTID(rp Position, gtid GTID) Position {
@@ -164,6 +197,37 @@ func DecodePositionDefaultFlavor(s string, flavor string) (rp Position, err erro
 	return ParsePosition(flav, gtid)
 }
 
+// DetectFlavor guesses the flavor of a GTID set string that carries no flavor
+// prefix. MySQL GTID sets are of the form "<uuid>:<intervals>", MariaDB GTID
+// sets are of the form "<domain>-<server>-<sequence>". An empty string is
+// returned if the flavor can't be determined.
+func DetectFlavor(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return ""
+	case strings.Contains(s, ":"):
+		if _, err := ParseMysql56GTIDSet(s); err == nil {
+			return Mysql56FlavorID
+		}
+	default:
+		if _, err := ParseMariadbGTIDSet(s); err == nil {
+			return MariadbFlavorID
+		}
+	}
+	return ""
+}
+
+// DecodePositionDetectFlavor is like DecodePosition, but if the string does not
+// carry a flavor prefix, the flavor is detected with DetectFlavor instead of
+// relying on a default parser.
+func DecodePositionDetectFlavor(s string) (rp Position, err error) {
+	if strings.Contains(s, "/") {
+		return DecodePosition(s)
+	}
+	return DecodePositionDefaultFlavor(s, DetectFlavor(s))
+}
+
 // ParsePosition calls the parser for the specified flavor.
 func ParsePosition(flavor, value string) (rp Position, err error) {
 	parser := gtidSetParsers[flavor]
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPositionEqual(t *testing.T) {
@@ -407,3 +408,55 @@ func TestJsonUnmarshalPositionZero(t *testing.T) {
 	assert.True(t, got.Equal(want), "json.Unmarshal(%#v) = %#v, want %#v", input, got, want)
 
 }
+
+func TestPositionUnionSubtract(t *testing.T) {
+	pos1 := MustParsePosition(Mysql56FlavorID, "00010203-0405-0607-0809-0a0b0c0d0e0f:1-10")
+	pos2 := MustParsePosition(Mysql56FlavorID, "00010203-0405-0607-0809-0a0b0c0d0e0f:5-20,00010203-0405-0607-0809-0a0b0c0d0e10:1-3")
+
+	union := pos1.Union(pos2)
+	assert.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f:1-20,00010203-0405-0607-0809-0a0b0c0d0e10:1-3", union.String())
+	assert.True(t, union.Equal(pos2.Union(pos1)))
+	assert.True(t, pos1.Union(Position{}).Equal(pos1))
+	assert.True(t, Position{}.Union(pos1).Equal(pos1))
+
+	diff, err := pos2.Subtract(pos1)
+	require.NoError(t, err)
+	assert.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f:11-20,00010203-0405-0607-0809-0a0b0c0d0e10:1-3", diff.String())
+
+	diff, err = pos1.Subtract(Position{})
+	require.NoError(t, err)
+	assert.True(t, diff.Equal(pos1))
+
+	mariadbPos := MustParsePosition(MariadbFlavorID, "0-1-100")
+	_, err = mariadbPos.Subtract(pos1)
+	assert.Error(t, err)
+	_, err = pos1.Subtract(mariadbPos)
+	assert.Error(t, err)
+}
+
+func TestDetectFlavor(t *testing.T) {
+	testcases := []struct {
+		in   string
+		want string
+	}{
+		{"00010203-0405-0607-0809-0a0b0c0d0e0f:1-10", Mysql56FlavorID},
+		{"00010203-0405-0607-0809-0a0b0c0d0e0f:1-10,00010203-0405-0607-0809-0a0b0c0d0e10:1-3", Mysql56FlavorID},
+		{"0-1-100", MariadbFlavorID},
+		{"0-1-100,1-2-200", MariadbFlavorID},
+		{"", ""},
+		{"not a gtid", ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.in, func(t *testing.T) {
+			assert.Equal(t, tc.want, DetectFlavor(tc.in))
+		})
+	}
+
+	pos, err := DecodePositionDetectFlavor("0-1-100")
+	require.NoError(t, err)
+	assert.Equal(t, "MariaDB/0-1-100", EncodePosition(pos))
+
+	pos, err = DecodePositionDetectFlavor("MySQL56/00010203-0405-0607-0809-0a0b0c0d0e0f:1-10")
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/00010203-0405-0607-0809-0a0b0c0d0e0f:1-10", EncodePosition(pos))
+}
@@ -182,29 +182,46 @@ func ProtoToReplicationStatus(s *replicationdatapb.Status) ReplicationStatus {
 // The result is returned as a Mysql56GTIDSet, each of whose elements is a found errant GTID.
 // This function is best effort in nature. If it marks something as errant, then it is for sure errant. But there may be cases of errant GTIDs, which aren't caught by this function.
 func (s *ReplicationStatus) FindErrantGTIDs(otherReplicaStatuses []*ReplicationStatus) (Mysql56GTIDSet, error) {
-	if len(otherReplicaStatuses) == 0 {
+	others := make([]Position, 0, len(otherReplicaStatuses))
+	for _, status := range otherReplicaStatuses {
+		others = append(others, status.RelayLogPosition)
+	}
+	return ErrantGTIDs(s.RelayLogPosition, s.SourceUUID, others)
+}
+
+// ErrantGTIDs returns the transactions in candidate that are not present in
+// any of the other positions, ignoring transactions originating from
+// sourceUUID (usually the candidate's current replication source, whose
+// transactions may simply not have reached the others yet). Pass the zero SID
+// to consider every source. All positions must be of the MySQL flavor.
+//
+// An empty result means no errant GTIDs were found. Like FindErrantGTIDs,
+// this is best effort: anything returned is errant for sure, but errant
+// transactions that are also present on one of the others are not caught.
+func ErrantGTIDs(candidate Position, sourceUUID SID, others []Position) (Mysql56GTIDSet, error) {
+	if len(others) == 0 {
 		// If there is nothing to compare this replica against, then we must assume that its GTID set is the correct one.
 		return nil, nil
 	}
 
-	relayLogSet, ok := s.RelayLogPosition.GTIDSet.(Mysql56GTIDSet)
+	candidateSet, ok := candidate.GTIDSet.(Mysql56GTIDSet)
 	if !ok {
 		return nil, fmt.Errorf("errant GTIDs can only be computed on the MySQL flavor")
 	}
 
-	otherSets := make([]Mysql56GTIDSet, 0, len(otherReplicaStatuses))
-	for _, status := range otherReplicaStatuses {
-		otherSet, ok := status.RelayLogPosition.GTIDSet.(Mysql56GTIDSet)
+	otherSets := make([]Mysql56GTIDSet, 0, len(others))
+	for _, other := range others {
+		otherSet, ok := other.GTIDSet.(Mysql56GTIDSet)
 		if !ok {
-			panic("The receiver ReplicationStatus contained a Mysql56GTIDSet in its relay log, but a replica's ReplicationStatus is of another flavor. This should never happen.")
+			return nil, fmt.Errorf("errant GTIDs can only be computed on the MySQL flavor, got position %v", other)
 		}
 		otherSets = append(otherSets, otherSet)
 	}
 
-	// Copy set for final diffSet so we don't mutate receiver.
-	diffSet := make(Mysql56GTIDSet, len(relayLogSet))
-	for sid, intervals := range relayLogSet {
-		if sid == s.SourceUUID {
+	// Copy set for final diffSet so we don't mutate the candidate.
+	diffSet := make(Mysql56GTIDSet, len(candidateSet))
+	for sid, intervals := range candidateSet {
+		if sid == sourceUUID {
 			continue
 		}
 		diffSet[sid] = intervals
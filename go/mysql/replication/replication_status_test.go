@@ -116,6 +116,36 @@ func TestFindErrantGTIDs(t *testing.T) {
 	}
 }
 
+func TestErrantGTIDs(t *testing.T) {
+	sid1 := SID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	sid2 := SID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 16}
+
+	candidate := Position{GTIDSet: Mysql56GTIDSet{
+		sid1: []interval{{1, 10}},
+		sid2: []interval{{1, 5}},
+	}}
+	others := []Position{
+		{GTIDSet: Mysql56GTIDSet{sid1: []interval{{1, 8}}}},
+		{GTIDSet: Mysql56GTIDSet{sid1: []interval{{1, 6}}, sid2: []interval{{1, 2}}}},
+	}
+
+	got, err := ErrantGTIDs(candidate, SID{}, others)
+	require.NoError(t, err)
+	assert.Equal(t, Mysql56GTIDSet{sid1: []interval{{9, 10}}, sid2: []interval{{3, 5}}}, got)
+
+	// Transactions from the source are not considered errant.
+	got, err = ErrantGTIDs(candidate, sid1, others)
+	require.NoError(t, err)
+	assert.Equal(t, Mysql56GTIDSet{sid2: []interval{{3, 5}}}, got)
+
+	got, err = ErrantGTIDs(candidate, SID{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = ErrantGTIDs(candidate, SID{}, []Position{MustParsePosition(MariadbFlavorID, "0-1-100")})
+	assert.Error(t, err)
+}
+
 func TestMysqlShouldGetPosition(t *testing.T) {
 	resultMap := map[string]string{
 		"Executed_Gtid_Set": "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
@@ -182,6 +182,10 @@ type Listener struct {
 	// beyond which a warning is logged to identify the slow connection
 	SlowConnectWarnThreshold atomic.Int64
 
+	// CompressionAlgorithms are the algorithms the clients may compress
+	// their connections with. If empty, connections are not compressed.
+	CompressionAlgorithms []CompressionAlgorithm
+
 	// The following parameters are changed by the Accept routine.
 
 	// Incrementing ID for connection id.
@@ -207,6 +211,10 @@ type Listener struct {
 	// RequireSecureTransport configures the server to reject connections from insecure clients
 	RequireSecureTransport bool
 
+	// LocalInfile lets the clients which support it send the files of
+	// LOAD DATA LOCAL INFILE statements, see Conn.RequestLocalInfile.
+	LocalInfile bool
+
 	// PreHandleFunc is called for each incoming connection, immediately after
 	// accepting a new connection. By default it's no-op. Useful for custom
 	// connection inspection or TLS termination. The returned connection is
@@ -272,6 +280,25 @@ func NewListener(
 	return NewFromListener(listener, authServer, handler, connReadTimeout, connWriteTimeout, connBufferPooling, keepAlivePeriod, flushDelay)
 }
 
+// SetProxyProtocolAllowedSources restricts the peers which may send a PROXY
+// protocol header, of version 1 or 2, to the given IP addresses and CIDR
+// ranges. The connections from other peers which send one are refused, so
+// that their clients cannot pretend to connect from another address, while
+// their connections without a header are accepted. It must be called before
+// Accept, on a listener created with the PROXY protocol enabled.
+func (l *Listener) SetProxyProtocolAllowedSources(allowed []string) error {
+	proxyListener, ok := l.listener.(*proxyproto.Listener)
+	if !ok {
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the PROXY protocol is not enabled on the listener")
+	}
+	policy, err := proxyproto.StrictWhiteListPolicy(allowed)
+	if err != nil {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid PROXY protocol allowed sources: %v", err)
+	}
+	proxyListener.Policy = policy
+	return nil
+}
+
 // ListenerConfig should be used with NewListenerWithConfig to specify listener parameters.
 type ListenerConfig struct {
 	// Protocol-Address pair and Listener are mutually exclusive parameters
@@ -391,7 +418,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil, l.optionalCapabilities())
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -534,6 +561,10 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		return
 	}
 
+	// The packets following the OK packet are compressed, if the client
+	// asked for it.
+	c.enableCompression()
+
 	// Record how long we took to establish the connection
 	timings.Record(connectTimingKey, acceptTime)
 
@@ -570,9 +601,19 @@ func (l *Listener) Shutdown() {
 	}
 }
 
+// optionalCapabilities returns the capability flags the listener advertises
+// on top of the ones it always does.
+func (l *Listener) optionalCapabilities() uint32 {
+	capabilities := compressionCapabilities(l.CompressionAlgorithms)
+	if l.LocalInfile {
+		capabilities |= CapabilityClientLocalFiles
+	}
+	return capabilities
+}
+
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool) ([]byte, error) {
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool, optionalCapabilities uint32) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -589,6 +630,7 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, ch
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(optionalCapabilities)
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -714,6 +756,11 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 		c.Capabilities |= CapabilityClientMultiStatements
 	}
 
+	// set connection capability for sending LOAD DATA LOCAL INFILE files
+	if l.LocalInfile && clientFlags&CapabilityClientLocalFiles > 0 {
+		c.Capabilities |= CapabilityClientLocalFiles
+	}
+
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
@@ -806,9 +853,27 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		if attrs, attrsEnd, err := parseConnAttrs(data, pos); err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
+		} else {
+			c.Attributes = attrs
+			pos = attrsEnd
+		}
+	}
+
+	// Compression, if the client asked for an algorithm we allow. zstd
+	// is preferred, and its level ends the packet.
+	allowed := compressionCapabilities(l.CompressionAlgorithms)
+	c.Capabilities &^= CapabilityClientCompress | CapabilityClientZstdCompressionAlgorithm
+	switch {
+	case clientFlags&allowed&CapabilityClientZstdCompressionAlgorithm != 0:
+		c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+		c.zstdCompressionLevel = DefaultZstdCompressionLevel
+		if level, _, ok := readByte(data, pos); ok && level != 0 {
+			c.zstdCompressionLevel = int(level)
 		}
+	case clientFlags&allowed&CapabilityClientCompress != 0:
+		c.Capabilities |= CapabilityClientCompress
 	}
 
 	return username, AuthMethodDescription(authMethod), authResponse, nil
@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -29,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pires/go-proxyproto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -430,6 +432,79 @@ func TestConnectionUnixSocket(t *testing.T) {
 	c.Close()
 }
 
+// proxyProtocolRelay relays a single connection to the server, after sending
+// it the PROXY protocol header of a client connecting from clientAddr.
+func proxyProtocolRelay(t *testing.T, server net.Addr, version byte, clientAddr *net.TCPAddr) (string, int) {
+	relay, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { relay.Close() })
+
+	go func() {
+		client, err := relay.Accept()
+		if err != nil {
+			return
+		}
+		defer client.Close()
+		conn, err := net.Dial("tcp", server.String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		header := proxyproto.HeaderProxyFromAddrs(version, clientAddr, server)
+		if _, err := header.WriteTo(conn); err != nil {
+			return
+		}
+		go io.Copy(conn, client)
+		io.Copy(client, conn)
+	}()
+	return getHostPort(t, relay.Addr())
+}
+
+func TestProxyProtocol(t *testing.T) {
+	clientAddr := &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 4567}
+	for _, version := range []byte{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			th := &testHandler{}
+			l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, true, false, 0, 0)
+			require.NoError(t, err)
+			defer l.Close()
+			go l.Accept()
+
+			host, port := proxyProtocolRelay(t, l.Addr(), version, clientAddr)
+			c, err := Connect(context.Background(), &ConnParams{Host: host, Port: port})
+			require.NoError(t, err)
+			defer c.Close()
+			assert.Equal(t, clientAddr.String(), th.LastConn().RemoteAddr().String())
+		})
+	}
+}
+
+func TestProxyProtocolAllowedSources(t *testing.T) {
+	th := &testHandler{}
+	l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, true, false, 0, 0)
+	require.NoError(t, err)
+	defer l.Close()
+	require.ErrorContains(t, l.SetProxyProtocolAllowedSources([]string{"not an address"}), "invalid PROXY protocol allowed sources")
+	require.NoError(t, l.SetProxyProtocolAllowedSources([]string{"192.168.0.0/16"}))
+	go l.Accept()
+
+	// The relay is not allowed to send a header.
+	host, port := proxyProtocolRelay(t, l.Addr(), 2, &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 4567})
+	_, err = Connect(context.Background(), &ConnParams{Host: host, Port: port})
+	require.Error(t, err)
+
+	// The connections without a header are still accepted.
+	host, port = getHostPort(t, l.Addr())
+	c, err := Connect(context.Background(), &ConnParams{Host: host, Port: port})
+	require.NoError(t, err)
+	c.Close()
+
+	l2, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer l2.Close()
+	require.ErrorContains(t, l2.SetProxyProtocolAllowedSources([]string{"192.168.0.0/16"}), "the PROXY protocol is not enabled on the listener")
+}
+
 func TestClientFoundRows(t *testing.T) {
 	th := &testHandler{}
 
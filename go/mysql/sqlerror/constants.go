@@ -36,6 +36,9 @@ const (
 	// Vitess specific errors, (100-999)
 	ERNotReplica      = ErrorCode(100)
 	ERNonAtomicCommit = ErrorCode(301)
+	ERVersionConflict = ErrorCode(302)
+	ERTxSizeExceeded  = ErrorCode(303)
+	ERIdleTransaction = ErrorCode(304)
 
 	// unknown
 	ERUnknownError = ErrorCode(1105)
@@ -496,4 +499,7 @@ const (
 
 	// CRMalformedPacket is CR_MALFORMED_PACKET
 	CRMalformedPacket = ErrorCode(2027)
+
+	// CRLoadDataLocalInfileRejected is CR_LOAD_DATA_LOCAL_INFILE_REJECTED
+	CRLoadDataLocalInfileRejected = ErrorCode(2068)
 )
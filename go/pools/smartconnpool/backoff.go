@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smartconnpool
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker that stops a pool from
+// connecting while it is backing off after failed connection attempts.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state of a pool that is connecting normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state of a pool that is backing off; new connections
+	// fail immediately.
+	CircuitOpen
+	// CircuitHalfOpen is the state of a pool that is done backing off; the next
+	// connection attempt decides whether the circuit closes or opens again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "Open"
+	case CircuitHalfOpen:
+		return "HalfOpen"
+	default:
+		return "Closed"
+	}
+}
+
+// connectBackoff is an exponential backoff for the connection attempts of a pool.
+type connectBackoff struct {
+	// initial is the delay after the first failed attempt; 0 disables the backoff
+	initial time.Duration
+	// max is the maximum delay after consecutive failed attempts
+	max time.Duration
+
+	// failures is the number of consecutive failed attempts
+	failures atomic.Int64
+	// next is the time, in Unix nanoseconds, before which no attempt is allowed
+	next atomic.Int64
+	// lastErr is the error of the last failed attempt
+	lastErr atomic.Pointer[error]
+}
+
+func (b *connectBackoff) state(now time.Time) CircuitState {
+	if b.initial == 0 || b.failures.Load() == 0 {
+		return CircuitClosed
+	}
+	if now.UnixNano() < b.next.Load() {
+		return CircuitOpen
+	}
+	return CircuitHalfOpen
+}
+
+func (b *connectBackoff) lastError() error {
+	if err := b.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// allow returns whether a connection attempt is allowed, along with the number
+// of consecutive failed attempts before it. Once the delay has elapsed, a single
+// attempt is allowed, and the others keep backing off while it is connecting.
+func (b *connectBackoff) allow(now time.Time) (int64, bool) {
+	failures := b.failures.Load()
+	if b.initial == 0 || failures == 0 {
+		return failures, true
+	}
+	next := b.next.Load()
+	if now.UnixNano() < next {
+		return failures, false
+	}
+	return failures, b.next.CompareAndSwap(next, now.Add(b.delay(failures)).UnixNano())
+}
+
+// failed records a failed attempt, started after the given number of consecutive
+// failed attempts, and returns whether it started a new backoff delay. Attempts
+// that were started together and failed together only count once, so that a
+// burst of connections failing at once doesn't escalate the delay.
+func (b *connectBackoff) failed(failures int64, err error, now time.Time) bool {
+	b.lastErr.Store(&err)
+	if !b.failures.CompareAndSwap(failures, failures+1) {
+		return false
+	}
+	b.next.Store(now.Add(b.delay(failures + 1)).UnixNano())
+	return true
+}
+
+// succeeded records a successful attempt and returns the number of consecutive
+// failed attempts before it.
+func (b *connectBackoff) succeeded() int64 {
+	return b.failures.Swap(0)
+}
+
+// delay returns the delay before the next attempt after the given number of
+// consecutive failed attempts. The delay is jittered so that pools that failed
+// at the same time don't all retry at the same time.
+func (b *connectBackoff) delay(failures int64) time.Duration {
+	delay := b.initial
+	if delay == 0 {
+		return 0
+	}
+	for i := int64(1); i < failures; i++ {
+		delay *= 2
+		if b.max > 0 && delay >= b.max {
+			delay = b.max
+			break
+		}
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smartconnpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+const (
+	// DefaultGrowInterval is how long clients must keep waiting for connections
+	// before an elastic pool grows, when Config.GrowInterval is not set.
+	DefaultGrowInterval = time.Second
+	// DefaultShrinkInterval is how long an elastic pool must keep spare connections
+	// before it shrinks, when Config.ShrinkInterval is not set.
+	DefaultShrinkInterval = 5 * time.Minute
+	// DefaultResizeStep is the number of connections an elastic pool grows or
+	// shrinks by, when Config.ResizeStep is not set.
+	DefaultResizeStep = 1
+)
+
+// elasticity resizes a pool between its minimum and maximum capacity based on
+// its load. Apart from its counters, its state is only accessed by the resize
+// worker of the pool.
+type elasticity struct {
+	// min is the capacity below which the pool never shrinks
+	min int64
+	// step is the number of connections the pool grows or shrinks by
+	step int64
+	// growInterval is how long clients must keep waiting before the pool grows
+	growInterval time.Duration
+	// shrinkInterval is how long the pool must keep step spare connections
+	// before it shrinks
+	shrinkInterval time.Duration
+
+	// waitCount is the wait count of the pool at the previous check
+	waitCount int64
+	// pressureSince is when clients started waiting in every check; it is zero
+	// if they did not wait in the last check
+	pressureSince time.Time
+	// idleSince is when the pool started having spare connections in every
+	// check; it is zero if it did not have them in the last check
+	idleSince time.Time
+
+	// grown and shrunk count the resize events of the pool; they are kept here
+	// rather than in Metrics because the pool must fit in 512 bytes
+	grown  atomic.Int64
+	shrunk atomic.Int64
+}
+
+func newElasticity[C Connection](config *Config[C]) *elasticity {
+	if config.MinCapacity <= 0 || config.MinCapacity >= config.Capacity {
+		return nil
+	}
+
+	e := &elasticity{
+		min:            config.MinCapacity,
+		step:           config.ResizeStep,
+		growInterval:   config.GrowInterval,
+		shrinkInterval: config.ShrinkInterval,
+	}
+	if e.step <= 0 {
+		e.step = DefaultResizeStep
+	}
+	if e.growInterval <= 0 {
+		e.growInterval = DefaultGrowInterval
+	}
+	if e.shrinkInterval <= 0 {
+		e.shrinkInterval = DefaultShrinkInterval
+	}
+	return e
+}
+
+// checkInterval is how often the resize worker samples the load of the pool.
+func (e *elasticity) checkInterval() time.Duration {
+	return min(e.growInterval, e.shrinkInterval) / 4
+}
+
+// reset forgets the load sampled since the pool last resized.
+func (e *elasticity) reset() {
+	e.pressureSince = time.Time{}
+	e.idleSince = time.Time{}
+}
+
+// MinCapacity returns the capacity below which an elastic pool never shrinks;
+// it is the same as MaxCapacity for a pool with a fixed capacity.
+func (pool *ConnPool[C]) MinCapacity() int64 {
+	if pool.elastic == nil {
+		return pool.config.maxCapacity
+	}
+	return pool.elastic.min
+}
+
+// GrownCount returns the number of times an elastic pool grew because clients
+// kept waiting for connections.
+func (pool *ConnPool[C]) GrownCount() int64 {
+	if pool.elastic == nil {
+		return 0
+	}
+	return pool.elastic.grown.Load()
+}
+
+// ShrunkCount returns the number of times an elastic pool shrank because it
+// kept spare connections.
+func (pool *ConnPool[C]) ShrunkCount() int64 {
+	if pool.elastic == nil {
+		return 0
+	}
+	return pool.elastic.shrunk.Load()
+}
+
+// resize samples the load of an elastic pool and grows it when clients have kept
+// waiting for connections for the grow interval, or shrinks it when it has kept
+// spare connections for the shrink interval.
+func (pool *ConnPool[C]) resize(now time.Time) {
+	e := pool.elastic
+
+	waitCount := pool.Metrics.WaitCount()
+	pressure := pool.wait.waiting() > 0 || waitCount > e.waitCount
+	e.waitCount = waitCount
+
+	capacity := pool.Capacity()
+	idle := !pressure && capacity-pool.InUse() >= e.step && capacity > e.min
+
+	if !pressure {
+		e.pressureSince = time.Time{}
+	} else if e.pressureSince.IsZero() {
+		e.pressureSince = now
+	}
+	if !idle {
+		e.idleSince = time.Time{}
+	} else if e.idleSince.IsZero() {
+		e.idleSince = now
+	}
+
+	switch {
+	case pressure && capacity < pool.config.maxCapacity && now.Sub(e.pressureSince) >= e.growInterval:
+		pool.resizeTo(min(capacity+e.step, pool.config.maxCapacity))
+	case idle && now.Sub(e.idleSince) >= e.shrinkInterval:
+		pool.resizeTo(max(capacity-e.step, e.min))
+	}
+}
+
+// resizeTo sets the capacity of an elastic pool and records the resize event
+// once the capacity is set. It gives up if the capacity is already being
+// changed, e.g. because the pool is closing, and tries again on the next check.
+func (pool *ConnPool[C]) resizeTo(newcap int64) {
+	if !pool.capacityMu.TryLock() {
+		return
+	}
+
+	oldcap := pool.capacity.Load()
+	if oldcap == 0 || oldcap == newcap {
+		pool.capacityMu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pool.elastic.checkInterval())
+	defer cancel()
+
+	err := pool.setCapacity(ctx, newcap)
+	pool.capacityMu.Unlock()
+	pool.elastic.reset()
+	if err != nil {
+		log.Warningf("failed to resize pool %q from %d to %d connections: %v", pool.Name, oldcap, newcap, err)
+		return
+	}
+
+	if newcap > oldcap {
+		pool.elastic.grown.Add(1)
+		log.Infof("pool %q grew from %d to %d connections", pool.Name, oldcap, newcap)
+
+		// clients that are already waiting only get the connections returned to
+		// the pool, so hand them the connections we now have room for
+		for range newcap - oldcap {
+			if pool.wait.waiting() == 0 {
+				break
+			}
+			conn, err := pool.getNew(ctx)
+			if conn == nil || err != nil {
+				break
+			}
+			pool.borrowed.Add(1)
+			pool.put(conn)
+		}
+	} else {
+		pool.elastic.shrunk.Add(1)
+		log.Infof("pool %q shrank from %d to %d connections", pool.Name, oldcap, newcap)
+	}
+}
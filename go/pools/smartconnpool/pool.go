@@ -53,6 +53,8 @@ type Metrics struct {
 	idleClosed           atomic.Int64
 	diffSetting          atomic.Int64
 	resetSetting         atomic.Int64
+	connectErrors        atomic.Int64
+	connectBackoffs      atomic.Int64
 }
 
 func (m *Metrics) MaxLifetimeClosed() int64 {
@@ -87,6 +89,37 @@ func (m *Metrics) ResetSettingCount() int64 {
 	return m.resetSetting.Load()
 }
 
+func (m *Metrics) ConnectErrorCount() int64 {
+	return m.connectErrors.Load()
+}
+
+func (m *Metrics) ConnectBackoffCount() int64 {
+	return m.connectBackoffs.Load()
+}
+
+// The labels of the WaitTimings and GetTimings histograms.
+const (
+	// waitAcquired is a wait which ended with a connection returned to the pool.
+	waitAcquired = "Acquired"
+	// waitTimeout is a wait which ended with the context of the client.
+	waitTimeout = "Timeout"
+
+	// getIdle is a connection taken from the idle connections of the pool.
+	getIdle = "Idle"
+	// getNew is a connection opened for the client.
+	getNew = "New"
+	// getWait is a connection returned to the pool by another client.
+	getWait = "Wait"
+	// getError is a failure to get a connection.
+	getError = "Error"
+)
+
+// timings are the histograms of a pool registered with RegisterStats.
+type timings struct {
+	wait *servenv.TimingsWrapper
+	get  *servenv.TimingsWrapper
+}
+
 type Connector[C Connection] func(ctx context.Context) (C, error)
 type RefreshCheck func() (bool, error)
 
@@ -96,6 +129,20 @@ type Config[C Connection] struct {
 	MaxLifetime     time.Duration
 	RefreshInterval time.Duration
 	LogWait         func(time.Time)
+	// ConnectBackoff is how long the pool waits before connecting again after a
+	// failed connection attempt. It doubles with every consecutive failure, up to
+	// MaxConnectBackoff. 0 disables the backoff.
+	ConnectBackoff    time.Duration
+	MaxConnectBackoff time.Duration
+	// MinCapacity makes the pool elastic: it opens with MinCapacity connections,
+	// grows by ResizeStep up to Capacity whenever clients have kept waiting for
+	// connections for GrowInterval, and shrinks by ResizeStep back down to
+	// MinCapacity whenever it has kept ResizeStep spare connections for
+	// ShrinkInterval. 0 keeps the capacity of the pool fixed.
+	MinCapacity    int64
+	GrowInterval   time.Duration
+	ShrinkInterval time.Duration
+	ResizeStep     int64
 }
 
 // stackMask is the number of connection setting stacks minus one;
@@ -124,6 +171,19 @@ type ConnPool[C Connection] struct {
 	// capacity is the maximum number of connections that this pool can open
 	capacity atomic.Int64
 
+	// backoff tracks the failed connection attempts of the pool; it's kept out of
+	// line because the pool must fit in 512 bytes: larger allocations get a header
+	// that breaks the 16-byte alignment of the connection stacks
+	backoff *connectBackoff
+
+	// timings are the histograms of the pool, kept out of line for the same
+	// reason; it is nil until RegisterStats is called
+	timings *timings
+
+	// elastic resizes the pool based on its load, also kept out of line; it is
+	// nil if the capacity of the pool is fixed
+	elastic *elasticity
+
 	// workers is a waitgroup for all the currently running worker goroutines
 	workers    sync.WaitGroup
 	close      chan struct{}
@@ -162,6 +222,8 @@ func NewPool[C Connection](config *Config[C]) *ConnPool[C] {
 	pool.config.idleTimeout.Store(config.IdleTimeout.Nanoseconds())
 	pool.config.refreshInterval.Store(config.RefreshInterval.Nanoseconds())
 	pool.config.logWait = config.LogWait
+	pool.backoff = &connectBackoff{initial: config.ConnectBackoff, max: config.MaxConnectBackoff}
+	pool.elastic = newElasticity(config)
 	pool.wait.init()
 
 	return pool
@@ -191,7 +253,7 @@ func (pool *ConnPool[C]) runWorker(close <-chan struct{}, interval time.Duration
 
 func (pool *ConnPool[C]) open() {
 	pool.close = make(chan struct{})
-	pool.capacity.Store(pool.config.maxCapacity)
+	pool.capacity.Store(pool.MinCapacity())
 
 	// The expire worker takes care of removing from the waiter list any clients whose
 	// context has been cancelled.
@@ -209,6 +271,16 @@ func (pool *ConnPool[C]) open() {
 		})
 	}
 
+	if pool.elastic != nil {
+		// The resize worker grows the pool while clients keep waiting for connections
+		// and shrinks it back while it keeps spare connections.
+		pool.elastic.reset()
+		pool.runWorker(pool.close, pool.elastic.checkInterval(), func(now time.Time) bool {
+			pool.resize(now)
+			return true
+		})
+	}
+
 	refreshInterval := pool.RefreshInterval()
 	if refreshInterval != 0 && pool.config.refresh != nil {
 		// The refresh worker periodically checks the refresh callback in this pool
@@ -347,11 +419,26 @@ func (pool *ConnPool[D]) RefreshInterval() time.Duration {
 func (pool *ConnPool[C]) recordWait(start time.Time) {
 	pool.Metrics.waitCount.Add(1)
 	pool.Metrics.waitTime.Add(time.Since(start).Nanoseconds())
+	if pool.timings != nil {
+		pool.timings.wait.Record(waitAcquired, start)
+	}
 	if pool.config.logWait != nil {
 		pool.config.logWait(start)
 	}
 }
 
+func (pool *ConnPool[C]) recordWaitTimeout(start time.Time) {
+	if pool.timings != nil {
+		pool.timings.wait.Record(waitTimeout, start)
+	}
+}
+
+func (pool *ConnPool[C]) recordGet(source string, start time.Time) {
+	if pool.timings != nil {
+		pool.timings.get.Record(source, start)
+	}
+}
+
 // Get returns a connection from the pool with the given Setting applied.
 // If there are no connections in the pool to be returned, Get blocks until one
 // is returned, or until the given ctx is cancelled.
@@ -363,10 +450,23 @@ func (pool *ConnPool[C]) Get(ctx context.Context, setting *Setting) (*Pooled[C],
 	if pool.capacity.Load() == 0 {
 		return nil, ErrConnPoolClosed
 	}
+
+	start := time.Now()
+	var (
+		conn   *Pooled[C]
+		source string
+		err    error
+	)
 	if setting == nil {
-		return pool.get(ctx)
+		conn, source, err = pool.get(ctx)
+	} else {
+		conn, source, err = pool.getWithSetting(ctx, setting)
 	}
-	return pool.getWithSetting(ctx, setting)
+	if err != nil {
+		source = getError
+	}
+	pool.recordGet(source, start)
+	return conn, err
 }
 
 // put returns a connection to the pool. This is a private API.
@@ -415,9 +515,54 @@ func (pool *ConnPool[D]) extendedMaxLifetime() time.Duration {
 	return time.Duration(maxLifetime) + time.Duration(rand.Uint32N(uint32(maxLifetime)))
 }
 
+// CircuitState returns the state of the circuit breaker of the pool.
+func (pool *ConnPool[C]) CircuitState() CircuitState {
+	return pool.backoff.state(time.Now())
+}
+
+// ConnectError returns an error describing the failed connection attempts of
+// the pool while its circuit breaker is open, and nil otherwise.
+func (pool *ConnPool[C]) ConnectError() error {
+	if pool.CircuitState() != CircuitOpen {
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "connection pool %s is backing off after %d failed connection attempts: %v",
+		pool.Name, pool.backoff.failures.Load(), pool.backoff.lastError())
+}
+
+// connect opens a new connection. After a failed connection attempt, the pool
+// fails new connections immediately until its backoff delay has elapsed; a
+// single attempt is then let through to find out whether the server is back.
+// This keeps all the pools of a process from reconnecting at once in a tight
+// loop while their server is down.
+func (pool *ConnPool[C]) connect(ctx context.Context) (C, error) {
+	failures, ok := pool.backoff.allow(time.Now())
+	if !ok {
+		pool.Metrics.connectBackoffs.Add(1)
+		var conn C
+		return conn, pool.ConnectError()
+	}
+
+	conn, err := pool.config.connect(ctx)
+	if err != nil {
+		// a cancelled client says nothing about the health of the server
+		if ctx.Err() == nil {
+			pool.Metrics.connectErrors.Add(1)
+			if pool.backoff.failed(failures, err, time.Now()) && failures == 0 && pool.backoff.initial > 0 {
+				log.Warningf("connection pool %q failed to connect, backing off: %v", pool.Name, err)
+			}
+		}
+		return conn, err
+	}
+	if failures := pool.backoff.succeeded(); failures > 0 {
+		log.Infof("connection pool %q connected after %d failed attempts", pool.Name, failures)
+	}
+	return conn, nil
+}
+
 func (pool *ConnPool[C]) connReopen(ctx context.Context, dbconn *Pooled[C], now time.Time) error {
 	var err error
-	dbconn.Conn, err = pool.config.connect(ctx)
+	dbconn.Conn, err = pool.connect(ctx)
 	if err != nil {
 		return err
 	}
@@ -428,7 +573,7 @@ func (pool *ConnPool[C]) connReopen(ctx context.Context, dbconn *Pooled[C], now
 }
 
 func (pool *ConnPool[C]) connNew(ctx context.Context) (*Pooled[C], error) {
-	conn, err := pool.config.connect(ctx)
+	conn, err := pool.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -485,38 +630,43 @@ func (pool *ConnPool[C]) getNew(ctx context.Context) (*Pooled[C], error) {
 	}
 }
 
-// get returns a pooled connection with no Setting applied
-func (pool *ConnPool[C]) get(ctx context.Context) (*Pooled[C], error) {
+// get returns a pooled connection with no Setting applied, and where it
+// comes from
+func (pool *ConnPool[C]) get(ctx context.Context) (*Pooled[C], string, error) {
 	pool.Metrics.getCount.Add(1)
 
 	// best case: if there's a connection in the clean stack, return it right away
 	if conn, ok := pool.clean.Pop(); ok {
 		pool.borrowed.Add(1)
-		return conn, nil
+		return conn, getIdle, nil
 	}
 
 	// check if we have enough capacity to open a brand-new connection to return
+	source := getNew
 	conn, err := pool.getNew(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	// if we don't have capacity, try popping a connection from any of the setting stacks
 	if conn == nil {
+		source = getIdle
 		conn = pool.getFromSettingsStack(nil)
 	}
 	// if there are no connections in the setting stacks and we've lent out connections
 	// to other clients, wait until one of the connections is returned
 	if conn == nil {
+		source = getWait
 		start := time.Now()
 		conn, err = pool.wait.waitForConn(ctx, nil)
 		if err != nil {
-			return nil, ErrTimeout
+			pool.recordWaitTimeout(start)
+			return nil, "", ErrTimeout
 		}
 		pool.recordWait(start)
 	}
 	// no connections available and no connections to wait for (pool is closed)
 	if conn == nil {
-		return nil, ErrTimeout
+		return nil, "", ErrTimeout
 	}
 
 	// if the connection we've acquired has a Setting applied, we must reset it before returning
@@ -529,20 +679,22 @@ func (pool *ConnPool[C]) get(ctx context.Context) (*Pooled[C], error) {
 			err = pool.connReopen(ctx, conn, time.Now())
 			if err != nil {
 				pool.closedConn()
-				return nil, err
+				return nil, "", err
 			}
 		}
 	}
 
 	pool.borrowed.Add(1)
-	return conn, nil
+	return conn, source, nil
 }
 
-// getWithSetting returns a connection from the pool with the given Setting applied
-func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (*Pooled[C], error) {
+// getWithSetting returns a connection from the pool with the given Setting applied,
+// and where it comes from
+func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (*Pooled[C], string, error) {
 	pool.Metrics.getWithSettingsCount.Add(1)
 
 	var err error
+	source := getIdle
 	// best case: check if there's a connection in the setting stack where our Setting belongs
 	conn, _ := pool.settings[setting.bucket&stackMask].Pop()
 	// if there's connection with our setting, try popping a clean connection
@@ -551,29 +703,33 @@ func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (
 	}
 	// otherwise try opening a brand new connection and we'll apply the setting to it
 	if conn == nil {
+		source = getNew
 		conn, err = pool.getNew(ctx)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 	// try on the _other_ setting stacks, even if we have to reset the Setting for the returned
 	// connection
 	if conn == nil {
+		source = getIdle
 		conn = pool.getFromSettingsStack(setting)
 	}
 	// no connections anywhere in the pool; if we've lent out connections to other clients
 	// wait for one of them
 	if conn == nil {
+		source = getWait
 		start := time.Now()
 		conn, err = pool.wait.waitForConn(ctx, setting)
 		if err != nil {
-			return nil, ErrTimeout
+			pool.recordWaitTimeout(start)
+			return nil, "", ErrTimeout
 		}
 		pool.recordWait(start)
 	}
 	// no connections available and no connections to wait for (pool is closed)
 	if conn == nil {
-		return nil, ErrTimeout
+		return nil, "", ErrTimeout
 	}
 
 	// ensure that the setting applied to the connection matches the one we want
@@ -589,7 +745,7 @@ func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (
 				err = pool.connReopen(ctx, conn, time.Now())
 				if err != nil {
 					pool.closedConn()
-					return nil, err
+					return nil, "", err
 				}
 			}
 		}
@@ -598,12 +754,12 @@ func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (
 		if err := conn.Conn.ApplySetting(ctx, setting); err != nil {
 			conn.Close()
 			pool.closedConn()
-			return nil, err
+			return nil, "", err
 		}
 	}
 
 	pool.borrowed.Add(1)
-	return conn, nil
+	return conn, source, nil
 }
 
 // SetCapacity changes the capacity (number of open connections) on the pool.
@@ -732,12 +888,17 @@ func (pool *ConnPool[C]) RegisterStats(stats *servenv.Exporter, name string) {
 		// the smartconnpool doesn't have a maximum capacity
 		return pool.Capacity()
 	})
+	// WaitCount and WaitTime are deprecated in favor of WaitTimings.
 	stats.NewCounterFunc(name+"WaitCount", "Tablet server conn pool wait count", func() int64 {
 		return pool.Metrics.WaitCount()
 	})
 	stats.NewCounterDurationFunc(name+"WaitTime", "Tablet server wait time", func() time.Duration {
 		return pool.Metrics.WaitTime()
 	})
+	pool.timings = &timings{
+		wait: stats.NewTimings(name+"WaitTimings", "Time waited for a connection to be returned to the pool, by whether it was acquired or timed out", "Result"),
+		get:  stats.NewTimings(name+"GetTimings", "Time to get a connection from the pool, by where the connection came from (Idle, New or Wait) or Error", "Source"),
+	}
 	stats.NewGaugeDurationFunc(name+"IdleTimeout", "Tablet server idle timeout", func() time.Duration {
 		return pool.IdleTimeout()
 	})
@@ -759,4 +920,19 @@ func (pool *ConnPool[C]) RegisterStats(stats *servenv.Exporter, name string) {
 	stats.NewCounterFunc(name+"ResetSetting", "Number of times pool reset the setting", func() int64 {
 		return pool.Metrics.ResetSettingCount()
 	})
+	stats.NewCounterFunc(name+"ConnectBackoffs", "Number of connections the pool failed without connecting, because it was backing off after failed connection attempts", func() int64 {
+		return pool.Metrics.ConnectBackoffCount()
+	})
+	stats.NewGaugeFunc(name+"MinCapacity", "Capacity below which the elastic conn pool never shrinks", func() int64 {
+		return pool.MinCapacity()
+	})
+	stats.NewCounterFunc(name+"Grown", "Number of times the elastic conn pool grew because clients kept waiting for connections", func() int64 {
+		return pool.GrownCount()
+	})
+	stats.NewCounterFunc(name+"Shrunk", "Number of times the elastic conn pool shrank because it kept spare connections", func() int64 {
+		return pool.ShrunkCount()
+	})
+	stats.NewGaugeFunc(name+"CircuitState", "State of the circuit breaker of the pool: 0 for closed, 1 for open (backing off), 2 for half-open", func() int64 {
+		return int64(pool.CircuitState())
+	})
 }
@@ -26,6 +26,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
 )
 
 var (
@@ -777,6 +781,174 @@ func TestCreateFail(t *testing.T) {
 	}
 }
 
+func TestConnectBackoff(t *testing.T) {
+	var state TestState
+	state.chaos.failConnect = true
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:          5,
+		IdleTimeout:       time.Second,
+		LogWait:           state.LogWait,
+		ConnectBackoff:    50 * time.Millisecond,
+		MaxConnectBackoff: 100 * time.Millisecond,
+	}).Open(newConnector(&state), nil)
+	defer p.Close()
+
+	// the first failure opens the circuit
+	_, err := p.Get(ctx, nil)
+	require.EqualError(t, err, "failed to connect: forced failure")
+	assert.Equal(t, CircuitOpen, p.CircuitState())
+	assert.ErrorContains(t, p.ConnectError(), "backing off after 1 failed connection attempts: failed to connect: forced failure")
+
+	// while backing off, connections fail without connecting
+	for range 10 {
+		_, err = p.Get(ctx, sFoo)
+		assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	}
+	assert.EqualValues(t, 1, state.open.Load())
+	assert.EqualValues(t, 10, p.Metrics.ConnectBackoffCount())
+
+	// once the delay has elapsed, a single attempt is let through and its
+	// failure backs off again, for longer
+	require.Eventually(t, func() bool { return p.CircuitState() == CircuitHalfOpen }, time.Second, time.Millisecond)
+	_, err = p.Get(ctx, nil)
+	require.EqualError(t, err, "failed to connect: forced failure")
+	assert.EqualValues(t, 2, state.open.Load())
+	assert.EqualValues(t, 2, p.Metrics.ConnectErrorCount())
+	assert.Equal(t, CircuitOpen, p.CircuitState())
+
+	// a successful attempt closes the circuit
+	state.chaos.failConnect = false
+	require.Eventually(t, func() bool { return p.CircuitState() == CircuitHalfOpen }, time.Second, time.Millisecond)
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, CircuitClosed, p.CircuitState())
+	assert.NoError(t, p.ConnectError())
+	p.put(r)
+}
+
+func TestConnectDelay(t *testing.T) {
+	p := NewPool(&Config[*TestConn]{
+		ConnectBackoff:    100 * time.Millisecond,
+		MaxConnectBackoff: time.Second,
+	})
+	for failures, want := range map[int64]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		64: time.Second,
+	} {
+		for range 10 {
+			delay := p.backoff.delay(failures)
+			assert.LessOrEqual(t, want/2, delay)
+			assert.GreaterOrEqual(t, want, delay)
+		}
+	}
+}
+
+func TestElasticCapacity(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:       3,
+		MinCapacity:    1,
+		GrowInterval:   40 * time.Millisecond,
+		ShrinkInterval: 80 * time.Millisecond,
+		LogWait:        state.LogWait,
+	}).Open(newConnector(&state), nil)
+	defer p.Close()
+
+	// an elastic pool opens with its minimum capacity
+	assert.EqualValues(t, 1, p.Capacity())
+	assert.EqualValues(t, 1, p.MinCapacity())
+	assert.EqualValues(t, 3, p.MaxCapacity())
+
+	r1, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	// a client that keeps waiting makes the pool grow, and gets one of the
+	// new connections
+	got := make(chan *Pooled[*TestConn])
+	go func() {
+		r, err := p.Get(ctx, nil)
+		assert.NoError(t, err)
+		got <- r
+	}()
+
+	var r2 *Pooled[*TestConn]
+	select {
+	case r2 = <-got:
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for the pool to grow")
+	}
+	assert.EqualValues(t, 2, p.Capacity())
+	assert.EqualValues(t, 1, p.GrownCount())
+	assert.EqualValues(t, 2, state.open.Load())
+
+	// once the connections are back, the pool shrinks to its minimum capacity
+	// and never below it
+	p.put(r1)
+	p.put(r2)
+	require.Eventually(t, func() bool { return p.Capacity() == 1 }, time.Second, time.Millisecond)
+	assert.EqualValues(t, 1, p.ShrunkCount())
+	assert.EqualValues(t, 1, p.Active())
+	assert.EqualValues(t, 1, state.open.Load())
+
+	time.Sleep(200 * time.Millisecond)
+	assert.EqualValues(t, 1, p.Capacity())
+	assert.EqualValues(t, 1, p.ShrunkCount())
+}
+
+func TestElasticCapacityResizeFailure(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:       3,
+		MinCapacity:    1,
+		GrowInterval:   time.Hour,
+		ShrinkInterval: time.Hour,
+		LogWait:        state.LogWait,
+	}).Open(newConnector(&state), nil)
+	defer p.Close()
+
+	// the resize worker does not check the pool during the test, and the
+	// resizes give up waiting for the connections after 10ms
+	p.elastic.growInterval = 40 * time.Millisecond
+
+	p.resizeTo(2)
+	assert.EqualValues(t, 2, p.Capacity())
+	assert.EqualValues(t, 1, p.GrownCount())
+
+	r1, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+	r2, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	// the borrowed connections are not returned in time, so the pool does not
+	// count the shrink
+	p.resizeTo(1)
+	assert.Zero(t, p.ShrunkCount())
+	assert.EqualValues(t, 1, p.GrownCount())
+
+	p.put(r1)
+	p.put(r2)
+}
+
+func TestFixedCapacity(t *testing.T) {
+	p := NewPool(&Config[*TestConn]{
+		Capacity:    5,
+		MinCapacity: 5,
+	})
+	assert.Nil(t, p.elastic)
+	assert.EqualValues(t, 5, p.MinCapacity())
+	assert.Zero(t, p.GrownCount())
+	assert.Zero(t, p.ShrunkCount())
+}
+
 func TestCreateFailOnPut(t *testing.T) {
 	var state TestState
 	var connector = newConnector(&state)
@@ -878,6 +1050,56 @@ func TestTimeout(t *testing.T) {
 	p.put(r)
 }
 
+func TestPoolTimings(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:    1,
+		IdleTimeout: time.Second,
+		LogWait:     state.LogWait,
+	}).Open(newConnector(&state), nil)
+	defer p.Close()
+	p.RegisterStats(servenv.NewExporter("", ""), "TestPoolTimings")
+
+	// open the only connection of the pool
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	// time out waiting for it
+	newctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	_, err = p.Get(newctx, nil)
+	cancel()
+	require.Error(t, err)
+
+	// wait for it to be returned
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.put(r)
+	}()
+	r, err = p.Get(ctx, sFoo)
+	require.NoError(t, err)
+	p.put(r)
+
+	// take it from the idle connections
+	r, err = p.Get(ctx, nil)
+	require.NoError(t, err)
+	p.put(r)
+
+	assert.Equal(t, map[string]int64{
+		"All":      2,
+		"Acquired": 1,
+		"Timeout":  1,
+	}, p.timings.wait.Counts())
+	assert.Equal(t, map[string]int64{
+		"All":   4,
+		"New":   1,
+		"Error": 1,
+		"Wait":  1,
+		"Idle":  1,
+	}, p.timings.get.Counts())
+}
+
 func TestExpired(t *testing.T) {
 	var state TestState
 
@@ -35,6 +35,10 @@ type Result struct {
 	SessionStateChanges string           `json:"session_state_changes"`
 	StatusFlags         uint16           `json:"status_flags"`
 	Info                string           `json:"info"`
+
+	// Warnings are the warnings of the tablet about the execution of the
+	// query, which vtgate records in the session.
+	Warnings []*querypb.QueryWarning `json:"warnings,omitempty"`
 }
 
 //goland:noinspection GoUnusedConst
@@ -108,6 +112,12 @@ func (result *Result) Copy() *Result {
 			out.Rows = append(out.Rows, CopyRow(r))
 		}
 	}
+	if result.Warnings != nil {
+		out.Warnings = make([]*querypb.QueryWarning, len(result.Warnings))
+		for i, w := range result.Warnings {
+			out.Warnings[i] = w.CloneVT()
+		}
+	}
 	return out
 }
 
@@ -120,6 +130,7 @@ func (result *Result) ShallowCopy() *Result {
 		Info:                result.Info,
 		SessionStateChanges: result.SessionStateChanges,
 		Rows:                result.Rows,
+		Warnings:            result.Warnings,
 	}
 }
 
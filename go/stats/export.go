@@ -61,6 +61,7 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&combineDimensions, "stats_combine_dimensions", combineDimensions, `List of dimensions to be combined into a single "all" value in exported stats vars`)
 	fs.StringVar(&dropVariables, "stats_drop_variables", dropVariables, `Variables to be dropped from the list of exported variables.`)
 	fs.StringSliceVar(&CommonTags, "stats_common_tags", CommonTags, `Comma-separated list of common tags for the stats backend. It provides both label and values. Example: label1:value1,label2:value2`)
+	fs.Var(timingsBuckets, "stats_timings_buckets", `Comma-separated list of the buckets of the timings histograms, as colon-separated durations, optionally prefixed by the name of a timings variable to only apply to it. Example: 1ms:10ms:100ms:1s,VtgateApi=500us:1ms:5ms:10ms`)
 }
 
 // StatsAllStr is the consolidated name if a dimension gets combined.
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync/atomic"
+	"time"
 )
 
 // Histogram tracks counts and totals while
@@ -34,8 +35,17 @@ type Histogram struct {
 	totalLabel string
 	hook       func(int64)
 
-	buckets []atomic.Int64
-	total   atomic.Int64
+	buckets   []atomic.Int64
+	exemplars []atomic.Pointer[Exemplar]
+	total     atomic.Int64
+}
+
+// Exemplar is a measurement of a Histogram linked to the trace of the
+// operation it measured.
+type Exemplar struct {
+	Value   int64
+	TraceID string
+	Time    time.Time
 }
 
 // NewHistogram creates a histogram with auto-generated labels
@@ -43,12 +53,18 @@ type Histogram struct {
 // following criterion: cutoff[i-1] < value <= cutoff[i]. Anything
 // higher than the highest cutoff is labeled as "inf".
 func NewHistogram(name, help string, cutoffs []int64) *Histogram {
+	return NewGenericHistogram(name, help, cutoffs, cutoffLabels(cutoffs), "Count", "Total")
+}
+
+// cutoffLabels returns the labels of the buckets of a histogram with the
+// given cutoffs.
+func cutoffLabels(cutoffs []int64) []string {
 	labels := make([]string, len(cutoffs)+1)
 	for i, v := range cutoffs {
 		labels[i] = fmt.Sprintf("%d", v)
 	}
 	labels[len(labels)-1] = "inf"
-	return NewGenericHistogram(name, help, cutoffs, labels, "Count", "Total")
+	return labels
 }
 
 // NewGenericHistogram creates a histogram where all the labels are
@@ -67,6 +83,7 @@ func NewGenericHistogram(name, help string, cutoffs []int64, labels []string, co
 		countLabel: countLabel,
 		totalLabel: totalLabel,
 		buckets:    make([]atomic.Int64, len(labels)),
+		exemplars:  make([]atomic.Pointer[Exemplar], len(labels)),
 	}
 	if name != "" {
 		publish(name, h)
@@ -81,10 +98,19 @@ func (h *Histogram) AddHook(hook func(int64)) {
 
 // Add adds a new measurement to the Histogram.
 func (h *Histogram) Add(value int64) {
+	h.AddWithExemplar(value, "")
+}
+
+// AddWithExemplar adds a new measurement to the Histogram, and records it as
+// the exemplar of its bucket if traceID is set.
+func (h *Histogram) AddWithExemplar(value int64, traceID string) {
 	for i := range h.labels {
 		if i == len(h.labels)-1 || value <= h.cutoffs[i] {
 			h.buckets[i].Add(1)
 			h.total.Add(value)
+			if traceID != "" {
+				h.exemplars[i].Store(&Exemplar{Value: value, TraceID: traceID, Time: time.Now()})
+			}
 			break
 		}
 	}
@@ -173,6 +199,16 @@ func (h *Histogram) Buckets() []int64 {
 	return buckets
 }
 
+// Exemplars returns the last exemplar recorded in each bucket, or nil for
+// the buckets without any.
+func (h *Histogram) Exemplars() []*Exemplar {
+	exemplars := make([]*Exemplar, len(h.exemplars))
+	for i := range h.exemplars {
+		exemplars[i] = h.exemplars[i].Load()
+	}
+	return exemplars
+}
+
 // Help returns the help string.
 func (h *Histogram) Help() string {
 	return h.help
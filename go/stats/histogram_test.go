@@ -129,3 +129,19 @@ func TestHistogramHook(t *testing.T) {
 	assert.Equal(t, hookCalled, true)
 	assert.Equal(t, addedValue, int64(10))
 }
+
+func TestHistogramExemplars(t *testing.T) {
+	h := NewHistogram("", "help", []int64{1, 5})
+	h.Add(0)
+	h.AddWithExemplar(3, "trace1")
+	h.AddWithExemplar(4, "trace2")
+	h.AddWithExemplar(6, "trace3")
+
+	assert.Equal(t, []int64{1, 2, 1}, h.Buckets())
+	exemplars := h.Exemplars()
+	assert.Len(t, exemplars, 3)
+	assert.Nil(t, exemplars[0])
+	assert.Equal(t, int64(4), exemplars[1].Value)
+	assert.Equal(t, "trace2", exemplars[1].TraceID)
+	assert.Equal(t, "trace3", exemplars[2].TraceID)
+}
@@ -249,20 +249,13 @@ func (c *metricsFuncWithMultiLabelsCollector) Collect(ch chan<- prometheus.Metri
 }
 
 type timingsCollector struct {
-	t       *stats.Timings
-	cutoffs []float64
-	desc    *prometheus.Desc
+	t    *stats.Timings
+	desc *prometheus.Desc
 }
 
 func newTimingsCollector(t *stats.Timings, name string) {
-	cutoffs := make([]float64, len(t.Cutoffs()))
-	for i, val := range t.Cutoffs() {
-		cutoffs[i] = float64(val) / 1000000000
-	}
-
 	collector := &timingsCollector{
-		t:       t,
-		cutoffs: cutoffs,
+		t: t,
 		desc: prometheus.NewDesc(
 			name,
 			t.Help(),
@@ -280,12 +273,11 @@ func (c *timingsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements Collector.
 func (c *timingsCollector) Collect(ch chan<- prometheus.Metric) {
+	// The cutoffs are only known once the flags are parsed, which happens
+	// after the timings are published.
+	cutoffs := durationCutoffs(c.t.Cutoffs())
 	for cat, his := range c.t.Histograms() {
-		metric, err := prometheus.NewConstHistogram(c.desc,
-			uint64(his.Count()),
-			float64(his.Total())/1000000000,
-			makeCumulativeBuckets(c.cutoffs,
-				his.Buckets()), cat)
+		metric, err := newTimingsHistogram(c.desc, cutoffs, his, cat)
 		if err != nil {
 			log.Errorf("Error adding metric: %s", c.desc)
 		} else {
@@ -294,6 +286,43 @@ func (c *timingsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// durationCutoffs converts the cutoffs of timings histograms to seconds.
+func durationCutoffs(cutoffs []int64) []float64 {
+	output := make([]float64, len(cutoffs))
+	for i, val := range cutoffs {
+		output[i] = float64(val) / 1000000000
+	}
+	return output
+}
+
+// newTimingsHistogram returns the histogram of timings, along with the trace
+// IDs of its exemplars.
+func newTimingsHistogram(desc *prometheus.Desc, cutoffs []float64, his *stats.Histogram, labelValues ...string) (prometheus.Metric, error) {
+	metric, err := prometheus.NewConstHistogram(desc,
+		uint64(his.Count()),
+		float64(his.Total())/1000000000,
+		makeCumulativeBuckets(cutoffs, his.Buckets()),
+		labelValues...)
+	if err != nil {
+		return nil, err
+	}
+	var exemplars []prometheus.Exemplar
+	for _, e := range his.Exemplars() {
+		if e == nil {
+			continue
+		}
+		exemplars = append(exemplars, prometheus.Exemplar{
+			Value:     float64(e.Value) / 1000000000,
+			Labels:    prometheus.Labels{"trace_id": e.TraceID},
+			Timestamp: e.Time,
+		})
+	}
+	if len(exemplars) == 0 {
+		return metric, nil
+	}
+	return prometheus.NewMetricWithExemplars(metric, exemplars...)
+}
+
 func makeCumulativeBuckets(cutoffs []float64, buckets []int64) map[float64]uint64 {
 	output := make(map[float64]uint64)
 	last := uint64(0)
@@ -306,20 +335,13 @@ func makeCumulativeBuckets(cutoffs []float64, buckets []int64) map[float64]uint6
 }
 
 type multiTimingsCollector struct {
-	mt      *stats.MultiTimings
-	cutoffs []float64
-	desc    *prometheus.Desc
+	mt   *stats.MultiTimings
+	desc *prometheus.Desc
 }
 
 func newMultiTimingsCollector(mt *stats.MultiTimings, name string) {
-	cutoffs := make([]float64, len(mt.Cutoffs()))
-	for i, val := range mt.Cutoffs() {
-		cutoffs[i] = float64(val) / 1000000000
-	}
-
 	collector := &multiTimingsCollector{
-		mt:      mt,
-		cutoffs: cutoffs,
+		mt: mt,
 		desc: prometheus.NewDesc(
 			name,
 			mt.Help(),
@@ -337,14 +359,10 @@ func (c *multiTimingsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements Collector.
 func (c *multiTimingsCollector) Collect(ch chan<- prometheus.Metric) {
+	cutoffs := durationCutoffs(c.mt.Cutoffs())
 	for cat, his := range c.mt.Timings.Histograms() {
 		labelValues := strings.Split(cat, ".")
-		metric, err := prometheus.NewConstHistogram(
-			c.desc,
-			uint64(his.Count()),
-			float64(his.Total())/1000000000,
-			makeCumulativeBuckets(c.cutoffs, his.Buckets()),
-			labelValues...)
+		metric, err := newTimingsHistogram(c.desc, cutoffs, his, labelValues...)
 		if err != nil {
 			log.Errorf("Error adding metric: %s", c.desc)
 		} else {
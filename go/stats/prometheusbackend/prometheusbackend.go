@@ -22,6 +22,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/log"
@@ -35,11 +36,26 @@ type PromBackend struct {
 
 var (
 	be PromBackend
+
+	// enableOpenMetrics serves the metrics in the OpenMetrics format to the
+	// scrapers which accept it, which is required to export the exemplars.
+	enableOpenMetrics bool
 )
 
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&enableOpenMetrics, "prometheus-open-metrics", enableOpenMetrics, "Serve the metrics in the OpenMetrics format to the Prometheus scrapers which accept it. This exports the exemplars linking the timings histograms to the traces.")
+}
+
+func init() {
+	servenv.OnParse(registerFlags)
+}
+
 // Init initializes the Prometheus be with the given namespace.
 func Init(namespace string) {
-	servenv.HTTPHandle("/metrics", promhttp.Handler())
+	servenv.HTTPHandle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: enableOpenMetrics}),
+	))
 	be.namespace = namespace
 	stats.Register(be.publishPrometheusMetric)
 }
@@ -27,7 +27,9 @@ import (
 
 	"vitess.io/vitess/go/stats"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/pflag"
 )
 
 const namespace = "namespace"
@@ -338,6 +340,35 @@ func TestPrometheusMultiTimings(t *testing.T) {
 	}
 }
 
+func TestPrometheusTimingsExemplars(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	stats.RegisterFlags(fs)
+	if err := fs.Parse([]string{"--stats_timings_buckets=blah_exemplar_timings=10ms:100ms"}); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "blah_exemplar_timings"
+	timing := stats.NewMultiTimings(name, "help", []string{"method"})
+	timing.Add([]string{"get"}, 5*time.Millisecond)
+	timing.AddWithTraceID([]string{"get"}, 30*time.Millisecond, "abc123")
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	response := httptest.NewRecorder()
+	promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(response, req)
+
+	expect := []string{
+		fmt.Sprintf("%s_%s_bucket{method=\"get\",le=\"0.01\"} 1\n", namespace, name),
+		fmt.Sprintf("%s_%s_bucket{method=\"get\",le=\"0.1\"} 2 # {trace_id=\"abc123\"} 0.03 ", namespace, name),
+		fmt.Sprintf("%s_%s_bucket{method=\"get\",le=\"+Inf\"} 2\n", namespace, name),
+	}
+	for _, line := range expect {
+		if !strings.Contains(response.Body.String(), line) {
+			t.Fatalf("Expected result to contain %s, got %s", line, response.Body.String())
+		}
+	}
+}
+
 func TestPrometheusMultiTimings_PanicWrongLength(t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
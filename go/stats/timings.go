@@ -19,6 +19,8 @@ package stats
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,6 +35,13 @@ type Timings struct {
 	mu         sync.RWMutex
 	histograms map[string]*Histogram
 
+	// The cutoffs of the histograms depend on the --stats_timings_buckets
+	// flag, so they are resolved on first use, once the flags are parsed.
+	bucketsOnce sync.Once
+	cutoffs     []int64
+	labels      []string
+	categories  []string
+
 	name          string
 	help          string
 	label         string
@@ -50,9 +59,7 @@ func NewTimings(name, help, label string, categories ...string) *Timings {
 		help:          help,
 		label:         label,
 		labelCombined: IsDimensionCombined(label),
-	}
-	for _, cat := range categories {
-		t.histograms[cat] = NewGenericHistogram("", "", bucketCutoffs, bucketLabels, "Count", "Time")
+		categories:    categories,
 	}
 	if name != "" {
 		publish(name, t)
@@ -61,6 +68,26 @@ func NewTimings(name, help, label string, categories ...string) *Timings {
 	return t
 }
 
+// initBuckets resolves the cutoffs of the histograms, and creates the
+// histograms of the initial categories.
+func (t *Timings) initBuckets() {
+	t.bucketsOnce.Do(func() {
+		t.cutoffs, t.labels = timingsBucketCutoffs(t.name)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for _, cat := range t.categories {
+			if _, ok := t.histograms[cat]; !ok {
+				t.histograms[cat] = t.newHistogram()
+			}
+		}
+	})
+}
+
+func (t *Timings) newHistogram() *Histogram {
+	return NewGenericHistogram("", "", t.cutoffs, t.labels, "Count", "Time")
+}
+
 // Reset will clear histograms and counters: used during testing
 func (t *Timings) Reset() {
 	t.mu.RLock()
@@ -72,9 +99,16 @@ func (t *Timings) Reset() {
 
 // Add will add a new value to the named histogram.
 func (t *Timings) Add(name string, elapsed time.Duration) {
+	t.AddWithTraceID(name, elapsed, "")
+}
+
+// AddWithTraceID will add a new value to the named histogram, and record it
+// as an exemplar of the histogram if traceID is set.
+func (t *Timings) AddWithTraceID(name string, elapsed time.Duration, traceID string) {
 	if t.labelCombined {
 		name = StatsAllStr
 	}
+	t.initBuckets()
 	// Get existing Histogram.
 	t.mu.RLock()
 	hist, ok := t.histograms[name]
@@ -85,7 +119,7 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 		t.mu.Lock()
 		hist, ok = t.histograms[name]
 		if !ok {
-			hist = NewGenericHistogram("", "", bucketCutoffs, bucketLabels, "Count", "Time")
+			hist = t.newHistogram()
 			t.histograms[name] = hist
 		}
 		t.mu.Unlock()
@@ -95,7 +129,7 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 	}
 
 	elapsedNs := int64(elapsed)
-	hist.Add(elapsedNs)
+	hist.AddWithExemplar(elapsedNs, traceID)
 	t.totalCount.Add(1)
 	t.totalTime.Add(elapsedNs)
 }
@@ -109,8 +143,15 @@ func (t *Timings) Record(name string, startTime time.Time) {
 	t.Add(name, time.Since(startTime))
 }
 
+// RecordWithTraceID is like Record, and records the timing as an exemplar
+// of the histogram if traceID is set.
+func (t *Timings) RecordWithTraceID(name string, startTime time.Time, traceID string) {
+	t.AddWithTraceID(name, time.Since(startTime), traceID)
+}
+
 // String is for expvar.
 func (t *Timings) String() string {
+	t.initBuckets()
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
@@ -133,6 +174,7 @@ func (t *Timings) String() string {
 
 // Histograms returns a map pointing at the histograms.
 func (t *Timings) Histograms() (h map[string]*Histogram) {
+	t.initBuckets()
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 	h = make(map[string]*Histogram, len(t.histograms))
@@ -154,6 +196,7 @@ func (t *Timings) Time() int64 {
 
 // Counts returns the total count for each value.
 func (t *Timings) Counts() map[string]int64 {
+	t.initBuckets()
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
@@ -168,7 +211,8 @@ func (t *Timings) Counts() map[string]int64 {
 // Cutoffs returns the cutoffs used in the component histograms.
 // Do not change the returned slice.
 func (t *Timings) Cutoffs() []int64 {
-	return bucketCutoffs
+	t.initBuckets()
+	return t.cutoffs
 }
 
 // Help returns the help string.
@@ -183,14 +227,75 @@ func (t *Timings) Label() string {
 
 var bucketCutoffs = []int64{5e5, 1e6, 5e6, 1e7, 5e7, 1e8, 5e8, 1e9, 5e9, 1e10}
 
-var bucketLabels []string
+var bucketLabels = cutoffLabels(bucketCutoffs)
+
+// timingsBuckets holds the value of the --stats_timings_buckets flag.
+var timingsBuckets = timingsBucketsFlag{}
+
+// timingsBucketCutoffs returns the cutoffs of the histograms of the named
+// timings, and their labels.
+func timingsBucketCutoffs(name string) ([]int64, []string) {
+	cutoffs, ok := timingsBuckets[name]
+	if !ok {
+		cutoffs, ok = timingsBuckets[""]
+	}
+	if !ok {
+		return bucketCutoffs, bucketLabels
+	}
+	return cutoffs, cutoffLabels(cutoffs)
+}
+
+// timingsBucketsFlag maps the names of timings to the cutoffs of their
+// histograms. The cutoffs of the empty name apply to the timings which are
+// not listed. It is set with a comma-separated list of [name=]cutoffs, where
+// the cutoffs are colon-separated durations, e.g.
+// "1ms:10ms:100ms:1s,VtgateApi=500us:1ms:5ms:10ms:50ms".
+type timingsBucketsFlag map[string][]int64
+
+// Set is part of the pflag.Value interface.
+func (f timingsBucketsFlag) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		name, durations, ok := strings.Cut(entry, "=")
+		if !ok {
+			name, durations = "", entry
+		}
+		var cutoffs []int64
+		for _, d := range strings.Split(durations, ":") {
+			cutoff, err := time.ParseDuration(d)
+			if err != nil {
+				return fmt.Errorf("invalid bucket %q of %q: %v", d, entry, err)
+			}
+			if cutoff <= 0 || (len(cutoffs) > 0 && int64(cutoff) <= cutoffs[len(cutoffs)-1]) {
+				return fmt.Errorf("the buckets of %q must be positive and increasing", entry)
+			}
+			cutoffs = append(cutoffs, int64(cutoff))
+		}
+		f[name] = cutoffs
+	}
+	return nil
+}
 
-func init() {
-	bucketLabels = make([]string, len(bucketCutoffs)+1)
-	for i, v := range bucketCutoffs {
-		bucketLabels[i] = fmt.Sprintf("%d", v)
+// String is part of the pflag.Value interface.
+func (f timingsBucketsFlag) String() string {
+	entries := make([]string, 0, len(f))
+	for name, cutoffs := range f {
+		durations := make([]string, len(cutoffs))
+		for i, cutoff := range cutoffs {
+			durations[i] = time.Duration(cutoff).String()
+		}
+		entry := strings.Join(durations, ":")
+		if name != "" {
+			entry = name + "=" + entry
+		}
+		entries = append(entries, entry)
 	}
-	bucketLabels[len(bucketLabels)-1] = "inf"
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (f timingsBucketsFlag) Type() string {
+	return "buckets"
 }
 
 // MultiTimings is meant to tracks timing data by categories as well
@@ -238,6 +343,15 @@ func (mt *MultiTimings) Add(names []string, elapsed time.Duration) {
 	mt.Timings.Add(safeJoinLabels(names, mt.combinedLabels), elapsed)
 }
 
+// AddWithTraceID is like Add, and records the value as an exemplar of the
+// histogram if traceID is set.
+func (mt *MultiTimings) AddWithTraceID(names []string, elapsed time.Duration, traceID string) {
+	if len(names) != len(mt.labels) {
+		panic("MultiTimings: wrong number of values in Add")
+	}
+	mt.Timings.AddWithTraceID(safeJoinLabels(names, mt.combinedLabels), elapsed, traceID)
+}
+
 // Record is a convenience function that records completion
 // timing data based on the provided start time of an event.
 func (mt *MultiTimings) Record(names []string, startTime time.Time) {
@@ -247,8 +361,17 @@ func (mt *MultiTimings) Record(names []string, startTime time.Time) {
 	mt.Timings.Record(safeJoinLabels(names, mt.combinedLabels), startTime)
 }
 
+// RecordWithTraceID is like Record, and records the timing as an exemplar
+// of the histogram if traceID is set.
+func (mt *MultiTimings) RecordWithTraceID(names []string, startTime time.Time, traceID string) {
+	if len(names) != len(mt.labels) {
+		panic("MultiTimings: wrong number of values in Record")
+	}
+	mt.Timings.RecordWithTraceID(safeJoinLabels(names, mt.combinedLabels), startTime, traceID)
+}
+
 // Cutoffs returns the cutoffs used in the component histograms.
 // Do not change the returned slice.
 func (mt *MultiTimings) Cutoffs() []int64 {
-	return bucketCutoffs
+	return mt.Timings.Cutoffs()
 }
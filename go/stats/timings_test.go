@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTimings(t *testing.T) {
@@ -99,3 +100,27 @@ func TestTimingsCombineDimension(t *testing.T) {
 	want = `{"TotalCount":1,"TotalTime":1,"Histograms":{"all.c2.all":{"500000":1,"1000000":0,"5000000":0,"10000000":0,"50000000":0,"100000000":0,"500000000":0,"1000000000":0,"5000000000":0,"10000000000":0,"inf":0,"Count":1,"Time":1}}}`
 	assert.Equal(t, want, t3.String())
 }
+
+func TestTimingsBuckets(t *testing.T) {
+	defer func() { timingsBuckets = timingsBucketsFlag{} }()
+
+	flag := timingsBucketsFlag{}
+	require.NoError(t, flag.Set("1ms:10ms,timings3=500us:1s"))
+	assert.Equal(t, "1ms:10ms,timings3=500µs:1s", flag.String())
+	require.ErrorContains(t, flag.Set("timings3=1ms:1ms"), `the buckets of "timings3=1ms:1ms" must be positive and increasing`)
+	require.ErrorContains(t, flag.Set("1ms:fast"), `invalid bucket "fast" of "1ms:fast"`)
+	timingsBuckets = flag
+
+	clearStats()
+	tm := NewTimings("timings3", "help", "category", "tag1")
+	tm.Add("tag2", 2*time.Millisecond)
+	assert.Equal(t, []int64{500000, 1000000000}, tm.Cutoffs())
+	assert.Equal(t, `{"TotalCount":1,"TotalTime":2000000,"Histograms":{"tag1":{"500000":0,"1000000000":0,"inf":0,"Count":0,"Time":0},"tag2":{"500000":0,"1000000000":1,"inf":0,"Count":1,"Time":2000000}}}`, tm.String())
+
+	mtm := NewMultiTimings("maptimings3", "help", []string{"label"})
+	mtm.AddWithTraceID([]string{"value"}, 20*time.Millisecond, "trace1")
+	assert.Equal(t, []int64{1000000, 10000000}, mtm.Cutoffs())
+	exemplars := mtm.Histograms()["value"].Exemplars()
+	require.NotNil(t, exemplars[2])
+	assert.Equal(t, "trace1", exemplars[2].TraceID)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcsink registers the "grpc" streamlog sink, which ships the
+// messages of a stream log to a gRPC LogCollector service, e.g.
+// "grpc://collector:15999?ca=/path/to/ca.pem".
+package grpcsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/grpcclient"
+
+	logcollectorpb "vitess.io/vitess/go/vt/proto/logcollector"
+)
+
+func init() {
+	streamlog.RegisterSinkFactory("grpc", newSink)
+}
+
+// sink sends the batches of messages on a Collect stream, which is opened on
+// the first write, and opened again after the stream fails.
+type sink struct {
+	logName string
+	cc      *grpc.ClientConn
+	client  logcollectorpb.LogCollectorClient
+
+	stream       logcollectorpb.LogCollector_CollectClient
+	cancelStream context.CancelFunc
+}
+
+// newSink creates the sink of a URL, whose host is the address of the
+// collector. The cert, key, ca, crl and server-name options of its query
+// configure TLS, which is disabled when neither cert nor ca is set.
+func newSink(logName string, u *url.URL) (streamlog.Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing collector address")
+	}
+	query := u.Query()
+	opt, err := grpcclient.SecureDialOption(query.Get("cert"), query.Get("key"), query.Get("ca"), query.Get("crl"), query.Get("server-name"), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Fail fast, so that the writes to an unavailable collector are retried
+	// with a backoff, and dropped, instead of blocking the sink.
+	cc, err := grpcclient.DialContext(context.Background(), u.Host, grpcclient.FailFast(true), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &sink{
+		logName: logName,
+		cc:      cc,
+		client:  logcollectorpb.NewLogCollectorClient(cc),
+	}, nil
+}
+
+// Write is part of the streamlog.Sink interface.
+func (s *sink) Write(messages [][]byte) error {
+	if s.stream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := s.client.Collect(ctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		s.stream = stream
+		s.cancelStream = cancel
+	}
+	err := s.stream.Send(&logcollectorpb.CollectRequest{
+		Log:     s.logName,
+		Records: messages,
+	})
+	if err != nil {
+		// Send returns io.EOF when the stream is broken, and the error of the
+		// stream is returned by CloseAndRecv.
+		if errors.Is(err, io.EOF) {
+			if _, recvErr := s.stream.CloseAndRecv(); recvErr != nil {
+				err = recvErr
+			}
+		}
+		s.resetStream()
+		return err
+	}
+	return nil
+}
+
+// Close is part of the streamlog.Sink interface.
+func (s *sink) Close() error {
+	var err error
+	if s.stream != nil {
+		_, err = s.stream.CloseAndRecv()
+		s.resetStream()
+	}
+	return errors.Join(err, s.cc.Close())
+}
+
+func (s *sink) resetStream() {
+	s.cancelStream()
+	s.stream = nil
+	s.cancelStream = nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcsink
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	logcollectorpb "vitess.io/vitess/go/vt/proto/logcollector"
+)
+
+type fakeCollector struct {
+	logcollectorpb.UnimplementedLogCollectorServer
+
+	mu      sync.Mutex
+	records []string
+	done    chan struct{}
+}
+
+func (c *fakeCollector) Collect(stream logcollectorpb.LogCollector_CollectServer) error {
+	defer close(c.done)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&logcollectorpb.CollectResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		for _, record := range req.Records {
+			c.records = append(c.records, req.Log+": "+string(record))
+		}
+		c.mu.Unlock()
+	}
+}
+
+func TestSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	collector := &fakeCollector{done: make(chan struct{})}
+	logcollectorpb.RegisterLogCollectorServer(server, collector)
+	go server.Serve(listener) // nolint:errcheck
+	defer server.Stop()
+
+	s, err := newSink("TxLog", &url.URL{Scheme: "grpc", Host: listener.Addr().String()})
+	require.NoError(t, err)
+	require.NoError(t, s.Write([][]byte{[]byte("test 1"), []byte("test 2")}))
+	require.NoError(t, s.Write([][]byte{[]byte("test 3")}))
+	require.NoError(t, s.Close())
+
+	<-collector.done
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, []string{"TxLog: test 1", "TxLog: test 2", "TxLog: test 3"}, collector.records)
+}
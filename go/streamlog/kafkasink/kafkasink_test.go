@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkasink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSink(t *testing.T) {
+	var (
+		gotPath, gotUser, gotPassword, gotContentType string
+		gotRecords                                    []string
+		response                                      = `{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, gotPassword, _ = r.BasicAuth()
+		gotContentType = r.Header.Get("Content-Type")
+		var req produceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		gotRecords = nil
+		for _, record := range req.Records {
+			gotRecords = append(gotRecords, string(record.Value))
+		}
+		w.Write([]byte(response)) // nolint:errcheck
+	}))
+	defer proxy.Close()
+
+	u, err := url.Parse(strings.Replace(proxy.URL, "http://", "kafka+http://user:secret@", 1) + "/querylog?timeout=5s")
+	require.NoError(t, err)
+	s, err := newSink("VTGate", u)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Write([][]byte{[]byte("test 1"), []byte("test 2")}))
+	assert.Equal(t, "/topics/querylog", gotPath)
+	assert.Equal(t, "user", gotUser)
+	assert.Equal(t, "secret", gotPassword)
+	assert.Equal(t, contentType, gotContentType)
+	assert.Equal(t, []string{"test 1", "test 2"}, gotRecords)
+
+	response = `{"offsets":[{"partition":0,"offset":3},{"error_code":50003,"error":"timed out"}]}`
+	err = s.Write([][]byte{[]byte("test 3"), []byte("test 4")})
+	assert.ErrorContains(t, err, "timed out (error code 50003)")
+
+	_, err = newSink("VTGate", &url.URL{Scheme: "kafka+http", Host: "proxy:8082"})
+	assert.ErrorContains(t, err, "must be of the form kafka+http://host:port/topic")
+}
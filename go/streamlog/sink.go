@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streamlog
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// Sink ships the messages of a StreamLogger to a destination, e.g. a file or
+// a log collector, so that they are kept even when no client is streaming
+// them over HTTP.
+type Sink interface {
+	// Write writes a batch of formatted messages, without their trailing
+	// newline. It is only called by one goroutine at a time, and is retried
+	// with the same batch when it fails.
+	Write(messages [][]byte) error
+
+	// Close flushes and releases the resources of the sink.
+	Close() error
+}
+
+// SinkFactory creates the Sink of a URL. The options common to all the sinks,
+// which are handled by the StreamLogger, are removed from the query of the URL
+// before it is passed to the factory.
+type SinkFactory func(logName string, u *url.URL) (Sink, error)
+
+var sinkFactories = make(map[string]SinkFactory)
+
+// RegisterSinkFactory registers the factory of the sinks of a URL scheme. It
+// must be called from an init function.
+func RegisterSinkFactory(scheme string, factory SinkFactory) {
+	if sinkFactories[scheme] != nil {
+		log.Fatalf("Duplicate streamlog.SinkFactory registration for %v", scheme)
+	}
+	sinkFactories[scheme] = factory
+}
+
+const (
+	// The options common to all the sinks, set in the query of their URL.
+	sinkBufferOption  = "buffer"
+	sinkBatchOption   = "batch"
+	sinkRetriesOption = "retries"
+
+	defaultSinkBufferSize = 1000
+	defaultSinkBatchSize  = 100
+	defaultSinkRetries    = 3
+
+	sinkRetryBackoff    = 100 * time.Millisecond
+	sinkMaxRetryBackoff = 5 * time.Second
+)
+
+var (
+	sinkWriteCount = stats.NewCountersWithMultiLabels(
+		"StreamlogSinkWrites",
+		"Batches of messages written by the streamlog sinks",
+		[]string{"Log", "Subscriber"})
+	sinkWriteErrorCount = stats.NewCountersWithMultiLabels(
+		"StreamlogSinkWriteErrors",
+		"Failed writes of batches of messages by the streamlog sinks, including the writes which are retried",
+		[]string{"Log", "Subscriber"})
+)
+
+// sinkRunner ships the messages of a subscription to a sink. The messages
+// are buffered in the channel of the subscription, so that a slow sink
+// neither blocks Send nor the other subscribers: the messages are dropped
+// when the buffer is full, and counted in StreamlogDeliveryDroppedMessages.
+type sinkRunner[T any] struct {
+	logger     *StreamLogger[T]
+	name       string
+	sink       Sink
+	logf       LogFormatter
+	ch         chan T
+	batchSize  int
+	maxRetries int
+	done       chan struct{}
+}
+
+// LogToSinks starts shipping the messages of the logger to the sinks of the
+// URLs, e.g. "file:///vt/logs/querylog.txt?max-size=1073741824". The scheme
+// of each URL selects the kind of sink, and the following options of the
+// query are common to all the sinks:
+//
+//   - buffer: the number of messages buffered for the sink, 1000 by default.
+//   - batch: the maximum number of messages written at once, 100 by default.
+//   - retries: the number of times a failed write is retried before its
+//     messages are dropped, 3 by default.
+//
+// It returns a function which stops shipping the messages, and closes the
+// sinks once they have written the buffered messages.
+func (logger *StreamLogger[T]) LogToSinks(urls []string, logf LogFormatter) (func(), error) {
+	var runners []*sinkRunner[T]
+	stop := func() {
+		for _, runner := range runners {
+			runner.stop()
+		}
+	}
+	for _, rawURL := range urls {
+		runner, err := logger.newSinkRunner(rawURL, logf)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		runners = append(runners, runner)
+		go runner.run()
+		log.Infof("Shipping logs from %s to %s.", logger.Name(), runner.name)
+	}
+	return stop, nil
+}
+
+func (logger *StreamLogger[T]) newSinkRunner(rawURL string, logf LogFormatter) (*sinkRunner[T], error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URL %q: %w", rawURL, err)
+	}
+	factory, ok := sinkFactories[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("invalid sink URL %q: unknown sink scheme %q", rawURL, u.Scheme)
+	}
+
+	query := u.Query()
+	bufferSize, err := popSinkIntOption(query, sinkBufferOption, defaultSinkBufferSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URL %q: %w", rawURL, err)
+	}
+	batchSize, err := popSinkIntOption(query, sinkBatchOption, defaultSinkBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URL %q: %w", rawURL, err)
+	}
+	maxRetries, err := popSinkIntOption(query, sinkRetriesOption, defaultSinkRetries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URL %q: %w", rawURL, err)
+	}
+	u.RawQuery = query.Encode()
+
+	sink, err := factory(logger.Name(), u)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the sink of %q: %w", rawURL, err)
+	}
+
+	// The name of the sink labels its stats, so it excludes the options and
+	// credentials of the URL.
+	name := "Sink:" + (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	return &sinkRunner[T]{
+		logger:     logger,
+		name:       name,
+		sink:       sink,
+		logf:       logf,
+		ch:         logger.subscribe(name, max(bufferSize, 1)),
+		batchSize:  max(batchSize, 1),
+		maxRetries: max(maxRetries, 0),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func popSinkIntOption(query url.Values, option string, defaultValue int) (int, error) {
+	if !query.Has(option) {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(query.Get(option))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s option: %w", option, err)
+	}
+	query.Del(option)
+	return value, nil
+}
+
+// run writes the messages of the subscription to the sink in batches, until
+// the subscription is stopped.
+func (runner *sinkRunner[T]) run() {
+	defer close(runner.done)
+	defer func() {
+		if err := runner.sink.Close(); err != nil {
+			log.Errorf("Cannot close the %s sink of %s: %v", runner.name, runner.logger.Name(), err)
+		}
+	}()
+
+	formatParams := url.Values{"full": {}}
+	batch := make([][]byte, 0, runner.batchSize)
+	for message := range runner.ch {
+		batch = runner.appendMessage(batch, message, formatParams)
+		// Add the messages which are already buffered to the batch.
+	fill:
+		for len(batch) < runner.batchSize {
+			select {
+			case message, ok := <-runner.ch:
+				if !ok {
+					break fill
+				}
+				batch = runner.appendMessage(batch, message, formatParams)
+			default:
+				break fill
+			}
+		}
+		if len(batch) > 0 {
+			runner.write(batch)
+		}
+		batch = batch[:0]
+	}
+}
+
+// appendMessage formats the message and appends it to the batch, unless the
+// formatter filters it out.
+func (runner *sinkRunner[T]) appendMessage(batch [][]byte, message T, formatParams url.Values) [][]byte {
+	var buf bytes.Buffer
+	if err := runner.logf(&buf, formatParams, message); err != nil {
+		log.Errorf("Cannot format a message of %s for the %s sink: %v", runner.logger.Name(), runner.name, err)
+		return batch
+	}
+	formatted := bytes.TrimRight(buf.Bytes(), "\n")
+	if len(formatted) == 0 {
+		return batch
+	}
+	return append(batch, formatted)
+}
+
+// write writes the batch to the sink, and retries with an exponential
+// backoff when it fails. The batch is dropped when all the retries fail.
+func (runner *sinkRunner[T]) write(batch [][]byte) {
+	labels := []string{runner.logger.Name(), runner.name}
+	backoff := sinkRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := runner.sink.Write(batch)
+		if err == nil {
+			sinkWriteCount.Add(labels, 1)
+			return
+		}
+		sinkWriteErrorCount.Add(labels, 1)
+		if attempt >= runner.maxRetries {
+			log.Errorf("Dropping %d messages of %s which cannot be written to the %s sink: %v", len(batch), runner.logger.Name(), runner.name, err)
+			deliveryDropCount.Add(labels, int64(len(batch)))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, sinkMaxRetryBackoff)
+	}
+}
+
+// stop stops the subscription, and waits for the buffered messages to be
+// written and for the sink to be closed.
+func (runner *sinkRunner[T]) stop() {
+	runner.logger.Unsubscribe(runner.ch)
+	// Send never writes to the channel once it is unsubscribed.
+	close(runner.ch)
+	<-runner.done
+}
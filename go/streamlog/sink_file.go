@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streamlog
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rotatedFileTimeFormat is the suffix of the rotated files, which sorts
+// them in the order they were rotated.
+const rotatedFileTimeFormat = "20060102T150405.000000000Z"
+
+func init() {
+	RegisterSinkFactory("file", newFileSink)
+}
+
+// fileSink appends the messages to a file, one per line, and rotates the file
+// once it reaches a size or an age. It is configured by the query of its URL:
+//
+//   - max-size: the size in bytes from which the file is rotated.
+//   - max-age: the duration, e.g. "1h", after which the file is rotated.
+//   - max-files: the number of rotated files which are kept, all of them by
+//     default.
+type fileSink struct {
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+
+	// now is overridden in tests.
+	now func() time.Time
+
+	file    *os.File
+	w       *bufio.Writer
+	size    int64
+	created time.Time
+}
+
+func newFileSink(logName string, u *url.URL) (Sink, error) {
+	sink := &fileSink{
+		path: u.Path,
+		now:  time.Now,
+	}
+	if sink.path == "" {
+		return nil, fmt.Errorf("missing file path")
+	}
+	query := u.Query()
+	var err error
+	if value := query.Get("max-size"); value != "" {
+		if sink.maxSize, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid max-size option: %w", err)
+		}
+	}
+	if value := query.Get("max-age"); value != "" {
+		if sink.maxAge, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid max-age option: %w", err)
+		}
+	}
+	if value := query.Get("max-files"); value != "" {
+		if sink.maxFiles, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid max-files option: %w", err)
+		}
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// open opens the file, and appends to it when it already exists.
+func (sink *fileSink) open() error {
+	file, err := os.OpenFile(sink.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	sink.file = file
+	sink.w = bufio.NewWriter(file)
+	sink.size = info.Size()
+	sink.created = sink.now()
+	return nil
+}
+
+// Write is part of the Sink interface.
+func (sink *fileSink) Write(messages [][]byte) error {
+	if sink.file == nil {
+		// A previous rotation failed to reopen the file.
+		if err := sink.open(); err != nil {
+			return err
+		}
+	}
+	if sink.shouldRotate() {
+		if err := sink.rotate(); err != nil {
+			return err
+		}
+	}
+	for _, message := range messages {
+		n, err := sink.w.Write(message)
+		sink.size += int64(n)
+		if err != nil {
+			return err
+		}
+		if err := sink.w.WriteByte('\n'); err != nil {
+			return err
+		}
+		sink.size++
+	}
+	return sink.w.Flush()
+}
+
+func (sink *fileSink) shouldRotate() bool {
+	if sink.size == 0 {
+		return false
+	}
+	if sink.maxSize > 0 && sink.size >= sink.maxSize {
+		return true
+	}
+	return sink.maxAge > 0 && sink.now().Sub(sink.created) >= sink.maxAge
+}
+
+// rotate renames the file with the time of the rotation as suffix, opens a
+// new file, and removes the oldest rotated files beyond max-files.
+func (sink *fileSink) rotate() error {
+	if err := sink.file.Close(); err != nil {
+		return err
+	}
+	sink.file = nil
+	rotated := sink.path + "." + sink.now().UTC().Format(rotatedFileTimeFormat)
+	if err := os.Rename(sink.path, rotated); err != nil {
+		return err
+	}
+	if err := sink.open(); err != nil {
+		return err
+	}
+	if sink.maxFiles <= 0 {
+		return nil
+	}
+	rotatedFiles, err := filepath.Glob(sink.path + ".*")
+	if err != nil {
+		return err
+	}
+	prefix := sink.path + "."
+	rotatedFiles = filterRotatedFiles(rotatedFiles, prefix)
+	sort.Strings(rotatedFiles)
+	for len(rotatedFiles) > sink.maxFiles {
+		if err := os.Remove(rotatedFiles[0]); err != nil {
+			return err
+		}
+		rotatedFiles = rotatedFiles[1:]
+	}
+	return nil
+}
+
+// filterRotatedFiles keeps the files which are named like the rotated files,
+// so that other files sharing the prefix are never removed.
+func filterRotatedFiles(files []string, prefix string) []string {
+	var rotatedFiles []string
+	for _, file := range files {
+		if _, err := time.Parse(rotatedFileTimeFormat, strings.TrimPrefix(file, prefix)); err == nil {
+			rotatedFiles = append(rotatedFiles, file)
+		}
+	}
+	return rotatedFiles
+}
+
+// Close is part of the Sink interface.
+func (sink *fileSink) Close() error {
+	if sink.file == nil {
+		return nil
+	}
+	if err := sink.w.Flush(); err != nil {
+		sink.file.Close()
+		return err
+	}
+	return sink.file.Close()
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streamlog
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSink records the messages written to it, after failing as many writes
+// as its failures.
+type testSink struct {
+	mu       sync.Mutex
+	query    url.Values
+	failures int
+	batches  [][]string
+	closed   bool
+}
+
+var testSinks = make(map[string]*testSink)
+
+func init() {
+	RegisterSinkFactory("test", func(logName string, u *url.URL) (Sink, error) {
+		sink, ok := testSinks[u.Host]
+		if !ok {
+			return nil, errors.New("no such test sink")
+		}
+		sink.query = u.Query()
+		return sink, nil
+	})
+}
+
+func (sink *testSink) Write(messages [][]byte) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.failures > 0 {
+		sink.failures--
+		return errors.New("write failed")
+	}
+	var batch []string
+	for _, message := range messages {
+		batch = append(batch, string(message))
+	}
+	sink.batches = append(sink.batches, batch)
+	return nil
+}
+
+func (sink *testSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.closed = true
+	return nil
+}
+
+func (sink *testSink) messages() []string {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	var messages []string
+	for _, batch := range sink.batches {
+		messages = append(messages, batch...)
+	}
+	return messages
+}
+
+func TestLogToSinks(t *testing.T) {
+	logger := New[*logMessage]("logger", 10)
+	sink1 := &testSink{}
+	sink2 := &testSink{failures: 1}
+	testSinks["sink1"] = sink1
+	testSinks["sink2"] = sink2
+	defer func() {
+		delete(testSinks, "sink1")
+		delete(testSinks, "sink2")
+	}()
+
+	stop, err := logger.LogToSinks([]string{
+		"test://sink1?batch=2&foo=bar",
+		"test://sink2?retries=1",
+	}, testLogf)
+	require.NoError(t, err)
+
+	logger.Send(&logMessage{"test 1"})
+	logger.Send(&logMessage{""})
+	logger.Send(&logMessage{"test 2"})
+	logger.Send(&logMessage{"test 3"})
+	stop()
+
+	// The common options are not passed to the sinks, and the messages
+	// which are formatted as empty lines are skipped.
+	assert.Equal(t, url.Values{"foo": {"bar"}}, sink1.query)
+	assert.Equal(t, []string{"test 1", "test 2", "test 3"}, sink1.messages())
+	for _, batch := range sink1.batches {
+		assert.LessOrEqual(t, len(batch), 2)
+	}
+	assert.True(t, sink1.closed)
+
+	// The failed write is retried.
+	assert.Equal(t, []string{"test 1", "test 2", "test 3"}, sink2.messages())
+	assert.True(t, sink2.closed)
+
+	// No more messages are written once the sinks are stopped.
+	logger.Send(&logMessage{"test 4"})
+	assert.Len(t, sink1.messages(), 3)
+}
+
+func TestLogToSinksDrop(t *testing.T) {
+	logger := New[*logMessage]("logger", 10)
+	sink := &testSink{failures: 2}
+	testSinks["sink"] = sink
+	defer delete(testSinks, "sink")
+
+	stop, err := logger.LogToSinks([]string{"test://sink?retries=1&batch=1"}, testLogf)
+	require.NoError(t, err)
+
+	labels := []string{"logger", "Sink:test://sink"}
+	droppedBefore := deliveryDropCount.Counts()[labels[0]+"."+labels[1]]
+	logger.Send(&logMessage{"test 1"})
+	logger.Send(&logMessage{"test 2"})
+	stop()
+
+	// The first message is dropped after it fails to be written twice.
+	assert.Equal(t, []string{"test 2"}, sink.messages())
+	assert.Equal(t, droppedBefore+1, deliveryDropCount.Counts()[labels[0]+"."+labels[1]])
+}
+
+func TestLogToSinksErrors(t *testing.T) {
+	logger := New[*logMessage]("logger", 10)
+	sink := &testSink{}
+	testSinks["sink"] = sink
+	defer delete(testSinks, "sink")
+
+	testcases := []struct {
+		url     string
+		wantErr string
+	}{{
+		url:     "unknown://sink",
+		wantErr: `unknown sink scheme "unknown"`,
+	}, {
+		url:     "test://sink?buffer=many",
+		wantErr: "invalid buffer option",
+	}, {
+		url:     "test://nosuchsink",
+		wantErr: "no such test sink",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.url, func(t *testing.T) {
+			_, err := logger.LogToSinks([]string{"test://sink", tcase.url}, testLogf)
+			require.ErrorContains(t, err, tcase.wantErr)
+			// The sinks which were started are stopped.
+			assert.True(t, sink.closed)
+			sink.closed = false
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	logPath := path.Join(dir, "test.log")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	u, err := url.Parse("file://" + logPath + "?max-size=20&max-age=1h&max-files=2")
+	require.NoError(t, err)
+	s, err := newFileSink("logger", u)
+	require.NoError(t, err)
+	sink := s.(*fileSink)
+	sink.now = func() time.Time { return now }
+	sink.created = now
+
+	readFile := func(name string) string {
+		contents, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(contents)
+	}
+	rotatedFiles := func() []string {
+		files, err := filepath.Glob(logPath + ".*")
+		require.NoError(t, err)
+		return files
+	}
+
+	require.NoError(t, sink.Write([][]byte{[]byte("test 1"), []byte("test 2")}))
+	assert.Equal(t, "test 1\ntest 2\n", readFile(logPath))
+
+	// The file reaches its maximum size, and is rotated before the next write.
+	require.NoError(t, sink.Write([][]byte{[]byte("test 3")}))
+	now = now.Add(time.Second)
+	require.NoError(t, sink.Write([][]byte{[]byte("test 4")}))
+	assert.Equal(t, "test 4\n", readFile(logPath))
+	require.Len(t, rotatedFiles(), 1)
+	assert.Equal(t, "test 1\ntest 2\ntest 3\n", readFile(rotatedFiles()[0]))
+
+	// The file reaches its maximum age.
+	now = now.Add(time.Hour)
+	require.NoError(t, sink.Write([][]byte{[]byte("test 5")}))
+	assert.Equal(t, "test 5\n", readFile(logPath))
+	require.Len(t, rotatedFiles(), 2)
+
+	// Only the most recent rotated files are kept, and the other files are
+	// left alone.
+	require.NoError(t, os.WriteFile(logPath+".bak", []byte("backup"), 0644))
+	now = now.Add(time.Hour)
+	require.NoError(t, sink.Write([][]byte{[]byte("test 6")}))
+	files := rotatedFiles()
+	require.Len(t, files, 3)
+	assert.Equal(t, logPath+".bak", files[2])
+	assert.Equal(t, "test 4\n", readFile(files[0]))
+	assert.Equal(t, "test 5\n", readFile(files[1]))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, "test 6\n", readFile(logPath))
+}
@@ -53,6 +53,7 @@ var (
 	queryLogRowThreshold uint64
 	queryLogFormat       = "text"
 	queryLogSampleRate   float64
+	queryLogSinks        []string
 )
 
 func GetRedactDebugUIQueries() bool {
@@ -75,6 +76,14 @@ func SetQueryLogSampleRate(sampleRate float64) {
 	queryLogSampleRate = sampleRate
 }
 
+func GetQueryLogSinks() []string {
+	return queryLogSinks
+}
+
+func SetQueryLogSinks(newQueryLogSinks []string) {
+	queryLogSinks = newQueryLogSinks
+}
+
 func GetQueryLogFormat() string {
 	return queryLogFormat
 }
@@ -104,6 +113,9 @@ func registerStreamLogFlags(fs *pflag.FlagSet) {
 
 	// QueryLogSampleRate causes a sample of queries to be logged
 	fs.Float64Var(&queryLogSampleRate, "querylog-sample-rate", queryLogSampleRate, "Sample rate for logging queries. Value must be between 0.0 (no logging) and 1.0 (all queries)")
+
+	// QueryLogSinks ships the query logs to sinks, whether or not a client streams them
+	fs.StringSliceVar(&queryLogSinks, "querylog-sinks", queryLogSinks, "URLs of the sinks to which the query logs are shipped, e.g. file:///vt/logs/querylog.txt?max-size=1073741824&max-files=10. The scheme selects the sink: file, grpc, kafka+http or kafka+https. The buffer, batch and retries query parameters tune the backpressure of each sink")
 }
 
 const (
@@ -157,10 +169,16 @@ func (logger *StreamLogger[T]) Send(message T) {
 // Subscribe returns a channel which can be used to listen
 // for messages.
 func (logger *StreamLogger[T]) Subscribe(name string) chan T {
+	return logger.subscribe(name, logger.size)
+}
+
+// subscribe returns a channel of the given size which can be used to listen
+// for messages.
+func (logger *StreamLogger[T]) subscribe(name string, size int) chan T {
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 
-	ch := make(chan T, logger.size)
+	ch := make(chan T, size)
 	logger.subscribed[ch] = name
 	return ch
 }
@@ -0,0 +1,27 @@
+//go:build windows
+
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syscallutil
+
+import "errors"
+
+// DiskStat returns the free and total bytes of the file system of path.
+// The free bytes are the ones available to unprivileged users.
+func DiskStat(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk stats are not supported on windows")
+}
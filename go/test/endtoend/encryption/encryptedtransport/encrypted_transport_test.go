@@ -453,7 +453,7 @@ func tabletConnExtraArgs(name string) []string {
 }
 
 func getVitessClient(ctx context.Context, addr string) (vtgateservicepb.VitessClient, error) {
-	opt, err := grpcclient.SecureDialOption(grpcCert, grpcKey, grpcCa, "", grpcName)
+	opt, err := grpcclient.SecureDialOption(grpcCert, grpcKey, grpcCa, "", grpcName, nil)
 	if err != nil {
 		return nil, err
 	}
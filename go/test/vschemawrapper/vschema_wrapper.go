@@ -41,16 +41,17 @@ import (
 var _ plancontext.VSchema = (*VSchemaWrapper)(nil)
 
 type VSchemaWrapper struct {
-	V                     *vindexes.VSchema
-	Keyspace              *vindexes.Keyspace
-	TabletType_           topodatapb.TabletType
-	Dest                  key.Destination
-	SysVarEnabled         bool
-	ForeignKeyChecksState *bool
-	Version               plancontext.PlannerVersion
-	EnableViews           bool
-	TestBuilder           func(query string, vschema plancontext.VSchema, keyspace string) (*engine.Plan, error)
-	Env                   *vtenv.Environment
+	V                           *vindexes.VSchema
+	Keyspace                    *vindexes.Keyspace
+	TabletType_                 topodatapb.TabletType
+	Dest                        key.Destination
+	SysVarEnabled               bool
+	ForeignKeyChecksState       *bool
+	Version                     plancontext.PlannerVersion
+	EnableViews                 bool
+	EnableSchemaTrackerMetadata bool
+	TestBuilder                 func(query string, vschema plancontext.VSchema, keyspace string) (*engine.Plan, error)
+	Env                         *vtenv.Environment
 }
 
 func (vw *VSchemaWrapper) GetPrepareData(stmtName string) *vtgatepb.PrepareData {
@@ -342,3 +343,7 @@ func (vw *VSchemaWrapper) FindRoutedShard(keyspace, shard string) (string, error
 func (vw *VSchemaWrapper) IsViewsEnabled() bool {
 	return vw.EnableViews
 }
+
+func (vw *VSchemaWrapper) IsSchemaTrackerMetadataEnabled() bool {
+	return vw.EnableSchemaTrackerMetadata
+}
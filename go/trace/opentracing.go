@@ -44,6 +44,21 @@ func (js openTracingSpan) Annotate(key string, value any) {
 	js.otSpan.SetTag(key, value)
 }
 
+// traceIDExtractors read the trace ID of the span contexts of the
+// opentracing implementations, which don't expose it in a common way.
+var traceIDExtractors []func(opentracing.SpanContext) (string, bool)
+
+// TraceID returns the ID of the trace of the span.
+func (js openTracingSpan) TraceID() (string, bool) {
+	sc := js.otSpan.Context()
+	for _, extract := range traceIDExtractors {
+		if id, ok := extract(sc); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
 var _ tracingService = (*openTracingService)(nil)
 
 type tracer interface {
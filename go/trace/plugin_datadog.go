@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/pflag"
@@ -76,6 +77,12 @@ func (ddCloser) Close() error {
 
 func init() {
 	tracingBackendFactories["opentracing-datadog"] = newDatadogTracer
+	traceIDExtractors = append(traceIDExtractors, func(sc opentracing.SpanContext) (string, bool) {
+		if dsc, ok := sc.(interface{ TraceID() uint64 }); ok && dsc.TraceID() != 0 {
+			return strconv.FormatUint(dsc.TraceID(), 10), true
+		}
+		return "", false
+	})
 }
 
 var _ tracer = (*datadogTracer)(nil)
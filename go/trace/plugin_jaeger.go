@@ -21,6 +21,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/pflag"
+	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
 
 	"vitess.io/vitess/go/viperutil"
@@ -128,6 +129,12 @@ func newJagerTracerFromEnv(serviceName string) (tracingService, io.Closer, error
 
 func init() {
 	tracingBackendFactories["opentracing-jaeger"] = newJagerTracerFromEnv
+	traceIDExtractors = append(traceIDExtractors, func(sc opentracing.SpanContext) (string, bool) {
+		if jsc, ok := sc.(jaeger.SpanContext); ok && jsc.IsValid() {
+			return jsc.TraceID().String(), true
+		}
+		return "", false
+	})
 }
 
 var _ tracer = (*jaegerTracer)(nil)
@@ -77,6 +77,19 @@ func FromContext(ctx context.Context) (Span, bool) {
 	return currentTracer.FromContext(ctx)
 }
 
+// TraceID returns the ID of the trace of the Span in a Context, if it has
+// one and the tracing plugin exposes it.
+func TraceID(ctx context.Context) (string, bool) {
+	span, ok := FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	if s, ok := span.(interface{ TraceID() (string, bool) }); ok {
+		return s.TraceID()
+	}
+	return "", false
+}
+
 // NewContext returns a context based on parent with a new Span value.
 func NewContext(parent context.Context, span Span) context.Context {
 	return currentTracer.NewContext(parent, span)
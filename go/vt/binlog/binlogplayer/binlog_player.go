@@ -27,8 +27,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"os"
+	"regexp"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -110,6 +112,15 @@ type Stats struct {
 
 	TableCopyRowCounts *stats.CountersWithSingleLabel
 	TableCopyTimings   *stats.Timings
+	TableCopyRowRates  *stats.Rates
+
+	TableRowsApplied      *stats.CountersWithSingleLabel
+	TableRowsAppliedRates *stats.Rates
+
+	// TableErrorCounts and the last errors per table, see RecordTableError.
+	TableErrorCounts     *stats.CountersWithSingleLabel
+	tableLastErrorsMutex sync.Mutex
+	tableLastErrors      map[string]string
 
 	PartialQueryCount     *stats.CountersWithMultiLabels
 	PartialQueryCacheSize *stats.CountersWithMultiLabels
@@ -159,9 +170,27 @@ func (bps *Stats) MessageHistory() []string {
 	return strs
 }
 
+// RecordTableError counts an error hit while copying or applying the rows of
+// the table, and keeps it as the last error of the table.
+func (bps *Stats) RecordTableError(table string, err error) {
+	bps.TableErrorCounts.Add(table, 1)
+	bps.tableLastErrorsMutex.Lock()
+	defer bps.tableLastErrorsMutex.Unlock()
+	bps.tableLastErrors[table] = err.Error()
+}
+
+// TableLastErrors returns the last error recorded for each table.
+func (bps *Stats) TableLastErrors() map[string]string {
+	bps.tableLastErrorsMutex.Lock()
+	defer bps.tableLastErrorsMutex.Unlock()
+	return maps.Clone(bps.tableLastErrors)
+}
+
 func (bps *Stats) Stop() {
 	bps.Rates.Stop()
 	bps.VReplicationLagRates.Stop()
+	bps.TableCopyRowRates.Stop()
+	bps.TableRowsAppliedRates.Stop()
 }
 
 // NewStats creates a new Stats structure.
@@ -184,6 +213,11 @@ func NewStats() *Stats {
 	bps.VReplicationLagRates = stats.NewRates("", bps.VReplicationLags, 15*60/5, 5*time.Second)
 	bps.TableCopyRowCounts = stats.NewCountersWithSingleLabel("", "", "Table")
 	bps.TableCopyTimings = stats.NewTimings("", "", "Table")
+	bps.TableCopyRowRates = stats.NewRates("", bps.TableCopyRowCounts, 15*60/5, 5*time.Second)
+	bps.TableRowsApplied = stats.NewCountersWithSingleLabel("", "", "Table")
+	bps.TableRowsAppliedRates = stats.NewRates("", bps.TableRowsApplied, 15*60/5, 5*time.Second)
+	bps.TableErrorCounts = stats.NewCountersWithSingleLabel("", "", "Table")
+	bps.tableLastErrors = make(map[string]string)
 	bps.PartialQueryCacheSize = stats.NewCountersWithMultiLabels("", "", []string{"type"})
 	bps.PartialQueryCount = stats.NewCountersWithMultiLabels("", "", []string{"type"})
 	bps.ThrottledCounts = stats.NewCountersWithMultiLabels("", "", []string{"throttler", "component"})
@@ -695,6 +729,26 @@ func DeleteVReplication(uid int32) string {
 	return fmt.Sprintf("delete from _vt.vreplication where id=%v", uid)
 }
 
+// terminalErrorRegexp matches the suffix of TerminalErrorMessage.
+var terminalErrorRegexp = regexp.MustCompile(`\(terminal (\w+) error, attempts: (\d+)\)$`)
+
+// TerminalErrorMessage returns the message of a stream stopped by an error
+// which is not retried anymore, with the class of the error and the number of
+// consecutive attempts which failed with it.
+func TerminalErrorMessage(class string, attempts int, err error) string {
+	return fmt.Sprintf("%v (terminal %s error, attempts: %d)", err, class, attempts)
+}
+
+// TerminalErrorClass returns the class of the error which stopped the stream
+// with the message, and whether the message is the one of a terminal error.
+func TerminalErrorClass(message string) (string, bool) {
+	match := terminalErrorRegexp.FindStringSubmatch(message)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
 // MessageTruncate truncates the message string to a safe length.
 func MessageTruncate(msg string) string {
 	// message length is 1000 bytes.
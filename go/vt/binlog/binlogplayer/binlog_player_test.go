@@ -454,3 +454,17 @@ func TestReadVReplicationStatus(t *testing.T) {
 		t.Errorf("ReadVReplicationStatus(482821) = %#v, want %#v", got, want)
 	}
 }
+
+func TestTerminalErrorMessage(t *testing.T) {
+	message := TerminalErrorMessage("duplicate_key", 3, errors.New("Duplicate entry '1' for key 'PRIMARY' (errno 1062)"))
+	want := "Duplicate entry '1' for key 'PRIMARY' (errno 1062) (terminal duplicate_key error, attempts: 3)"
+	if message != want {
+		t.Errorf("TerminalErrorMessage() = %#v, want %#v", message, want)
+	}
+	if class, ok := TerminalErrorClass(message); !ok || class != "duplicate_key" {
+		t.Errorf("TerminalErrorClass(%#v) = %#v, %v, want duplicate_key, true", message, class, ok)
+	}
+	if class, ok := TerminalErrorClass("Duplicate entry '1' for key 'PRIMARY' (errno 1062)"); ok {
+		t.Errorf("TerminalErrorClass() = %#v, %v, want no terminal error", class, ok)
+	}
+}
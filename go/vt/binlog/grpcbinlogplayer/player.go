@@ -31,7 +31,10 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 )
 
-var cert, key, ca, crl, name string
+var (
+	cert, key, ca, crl, name string
+	spiffeIDs                []string
+)
 
 func init() {
 	servenv.OnParseFor("vtcombo", registerFlags)
@@ -44,6 +47,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca, "binlog_player_grpc_ca", ca, "the server ca to use to validate servers when connecting")
 	fs.StringVar(&crl, "binlog_player_grpc_crl", crl, "the server crl to use to validate server certificates when connecting")
 	fs.StringVar(&name, "binlog_player_grpc_server_name", name, "the server name to use to validate server certificate")
+	fs.StringSliceVar(&spiffeIDs, "binlog_player_grpc_spiffe_ids", spiffeIDs, "comma-separated list of SPIFFE IDs allowed in the server certificate, checked instead of the server name. An ID ending with /* allows all the IDs under it")
 }
 
 // client implements a Client over go rpc
@@ -55,7 +59,7 @@ type client struct {
 func (client *client) Dial(ctx context.Context, tablet *topodatapb.Tablet) error {
 	addr := netutil.JoinHostPort(tablet.Hostname, tablet.PortMap["grpc"])
 	var err error
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		return err
 	}
@@ -32,14 +32,30 @@ import (
 // only for Mysql contexts.
 func MysqlCallInfo(ctx context.Context, c *mysql.Conn) context.Context {
 	return NewContext(ctx, &mysqlCallInfoImpl{
-		remoteAddr: c.RemoteAddr().String(),
-		user:       c.User,
+		remoteAddr:   c.RemoteAddr().String(),
+		user:         c.User,
+		connectionID: c.ConnectionID,
 	})
 }
 
+// MysqlConnectionID returns the ID of the Mysql connection of the call, for
+// the contexts returned by MysqlCallInfo.
+func MysqlConnectionID(ctx context.Context) (uint32, bool) {
+	ci, ok := FromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	mci, ok := ci.(*mysqlCallInfoImpl)
+	if !ok {
+		return 0, false
+	}
+	return mci.connectionID, true
+}
+
 type mysqlCallInfoImpl struct {
-	remoteAddr string
-	user       string
+	remoteAddr   string
+	user         string
+	connectionID uint32
 }
 
 func (mci *mysqlCallInfoImpl) RemoteAddr() string {
@@ -17,6 +17,7 @@ limitations under the License.
 package callinfo
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -33,3 +34,13 @@ func TestMysqlCallInfo(t *testing.T) {
 	require.Equal(t, "test@localhost(Mysql)", mysqlCi.Text())
 	require.Equal(t, "<b>MySQL User:</b> test <b>Remote Addr:</b> localhost", mysqlCi.HTML().String())
 }
+
+func TestMysqlConnectionID(t *testing.T) {
+	ctx := NewContext(context.Background(), &mysqlCallInfoImpl{connectionID: 42})
+	id, ok := MysqlConnectionID(ctx)
+	require.True(t, ok)
+	require.EqualValues(t, 42, id)
+
+	_, ok = MysqlConnectionID(context.Background())
+	require.False(t, ok)
+}
@@ -62,6 +62,7 @@ var (
 		"vtbackup",
 		"vtcombo",
 		"vttablet",
+		"vtqueryreplay",
 	}
 )
 
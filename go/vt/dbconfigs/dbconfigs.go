@@ -89,6 +89,8 @@ type DBConfigs struct {
 	ConnectTimeoutMilliseconds int           `json:"connectTimeoutMilliseconds,omitempty"`
 	DBName                     string        `json:"dbName,omitempty"`
 	EnableQueryInfo            bool          `json:"enableQueryInfo,omitempty"`
+	Compression                string        `json:"compression,omitempty"`
+	ZstdCompressionLevel       int           `json:"zstdCompressionLevel,omitempty"`
 
 	App          UserConfig `json:"app,omitempty"`
 	Dba          UserConfig `json:"dba,omitempty"`
@@ -145,6 +147,8 @@ func registerBaseFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&GlobalDBConfigs.ServerName, "db_server_name", "", "server name of the DB we are connecting to.")
 	fs.IntVar(&GlobalDBConfigs.ConnectTimeoutMilliseconds, "db_connect_timeout_ms", 0, "connection timeout to mysqld in milliseconds (0 for no timeout)")
 	fs.BoolVar(&GlobalDBConfigs.EnableQueryInfo, "db_conn_query_info", false, "enable parsing and processing of QUERY_OK info fields")
+	fs.StringVar(&GlobalDBConfigs.Compression, "db_compression", "", "Algorithm compressing the connections to mysqld, if it supports it. One of uncompressed, zlib & zstd.")
+	fs.IntVar(&GlobalDBConfigs.ZstdCompressionLevel, "db_zstd_compression_level", 0, "zstd compression level, from 1 to 22, when db_compression is zstd. Defaults to 3 if not set.")
 }
 
 // The flags will change the global singleton
@@ -356,6 +360,13 @@ func (dbcfgs *DBConfigs) InitWithSocket(defaultSocketFile string, collationEnv *
 		cp.ConnectTimeoutMs = uint64(dbcfgs.ConnectTimeoutMilliseconds)
 		cp.EnableQueryInfo = dbcfgs.EnableQueryInfo
 
+		compression, err := mysql.ParseCompressionAlgorithm(dbcfgs.Compression)
+		if err != nil {
+			log.Warningf("Error parsing compression algorithm, the connections will not be compressed: %v", err)
+		}
+		cp.Compression = compression
+		cp.ZstdCompressionLevel = dbcfgs.ZstdCompressionLevel
+
 		cp.Uname = uc.User
 		cp.Pass = uc.Password
 		if uc.UseSSL {
@@ -168,6 +168,7 @@ type shardState struct {
 	serving              bool
 	externallyReparented int64
 	currentPrimary       *topodatapb.TabletAlias
+	writesFrozen         bool
 }
 
 // Subscribe returns a channel that will receive any KeyspaceEvents for all keyspaces in the
@@ -367,6 +368,14 @@ func (kss *keyspaceState) onHealthCheck(th *TabletHealth) {
 		kss.consistent = false
 	}
 
+	// if the primary reports that the writes to this shard are not frozen anymore, the
+	// writes buffered while they were frozen can be retried, as after a failover
+	writesFrozen := th.Stats != nil && th.Stats.WritesFrozen
+	if sstate.writesFrozen && !writesFrozen {
+		kss.consistent = false
+	}
+	sstate.writesFrozen = writesFrozen
+
 	kss.ensureConsistentLocked()
 }
 
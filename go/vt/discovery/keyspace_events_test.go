@@ -397,3 +397,55 @@ func (f *fakeTopoServer) WatchSrvVSchema(ctx context.Context, cell string, callb
 	sv, err := f.GetSrvVSchema(ctx, cell)
 	callback(sv, err)
 }
+
+// TestKeyspaceEventWritesUnfrozen confirms that the keyspace event watcher
+// broadcasts a resolution event when the primary of a shard reports that its
+// writes are not frozen anymore, so that the writes buffered during the freeze
+// are retried right away.
+func TestKeyspaceEventWritesUnfrozen(t *testing.T) {
+	cell := "cell1"
+	keyspace := "testks"
+	kew := &KeyspaceEventWatcher{
+		localCell: cell,
+		keyspaces: make(map[string]*keyspaceState),
+		subs:      make(map[chan *KeyspaceEvent]struct{}),
+	}
+	receiver := kew.Subscribe()
+	kss := &keyspaceState{
+		kew:      kew,
+		keyspace: keyspace,
+		shards:   make(map[string]*shardState),
+		lastKeyspace: &topodatapb.SrvKeyspace{
+			Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+				ServedType:      topodatapb.TabletType_PRIMARY,
+				ShardReferences: []*topodatapb.ShardReference{{Name: "0"}},
+			}},
+		},
+	}
+	healthCheck := func(writesFrozen bool) {
+		kss.onHealthCheck(&TabletHealth{
+			Tablet:               &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: cell, Uid: 100}},
+			Target:               &querypb.Target{Keyspace: keyspace, Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+			Stats:                &querypb.RealtimeStats{WritesFrozen: writesFrozen},
+			PrimaryTermStartTime: 1,
+			Serving:              true,
+		})
+	}
+
+	// The first health check of the primary resolves the keyspace.
+	healthCheck(true)
+	require.Len(t, receiver, 1)
+	<-receiver
+
+	healthCheck(true)
+	require.Empty(t, receiver)
+
+	healthCheck(false)
+	require.Len(t, receiver, 1)
+	ev := <-receiver
+	require.Len(t, ev.Shards, 1)
+	require.True(t, ev.Shards[0].Serving)
+
+	healthCheck(false)
+	require.Empty(t, receiver)
+}
@@ -60,6 +60,15 @@ const (
 	InOrderHint = "in_order:"
 )
 
+const (
+	// TabletPickerPreferenceLocalCell matches the tablets in the local cell.
+	TabletPickerPreferenceLocalCell = "local"
+	// TabletPickerPreferenceLocalAlias matches the tablets in the cells of the local cell's alias.
+	TabletPickerPreferenceLocalAlias = "alias"
+	// TabletPickerPreferenceAnyCell matches the tablets in any of the cells of the picker.
+	TabletPickerPreferenceAnyCell = "any"
+)
+
 var (
 	tabletPickerRetryDelay   = 30 * time.Second
 	muTabletPickerRetryDelay sync.Mutex
@@ -104,6 +113,48 @@ type TabletPickerOptions struct {
 	CellPreference           string
 	TabletOrder              string
 	IncludeNonServingTablets bool
+	// Preferences is an ordered list of <tablet_type>@<cells> preferences, e.g.
+	// rdonly@local,replica@local,replica@any. See ParseTabletPickerPreferences.
+	// When set, it overrides the tablet types, the tablet order and the cell
+	// preference of the picker.
+	Preferences string
+}
+
+// TabletPickerPreference is a preference of the tablet picker for the
+// tablets of a type in some cells.
+type TabletPickerPreference struct {
+	TabletType topodatapb.TabletType
+	// Cell is TabletPickerPreferenceLocalCell, TabletPickerPreferenceLocalAlias,
+	// TabletPickerPreferenceAnyCell or the name of a cell.
+	Cell string
+}
+
+func (p TabletPickerPreference) String() string {
+	return fmt.Sprintf("%s@%s", strings.ToLower(p.TabletType.String()), p.Cell)
+}
+
+// ParseTabletPickerPreferences parses a comma separated, ordered list of
+// <tablet_type>@<cells> tablet picker preferences, where <cells> is local for
+// the local cell, alias for the cells of the local cell's alias, any for any
+// of the cells of the picker, or the name of a cell.
+func ParseTabletPickerPreferences(str string) ([]TabletPickerPreference, error) {
+	var preferences []TabletPickerPreference
+	for _, pref := range strings.Split(str, ",") {
+		pref = strings.TrimSpace(pref)
+		if pref == "" {
+			continue
+		}
+		typ, cell, ok := strings.Cut(pref, "@")
+		if !ok || cell == "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet picker preference %s: expected <tablet_type>@<cells>", pref)
+		}
+		tabletType, err := topoproto.ParseTabletType(typ)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet picker preference %s: %v", pref, err)
+		}
+		preferences = append(preferences, TabletPickerPreference{TabletType: tabletType, Cell: cell})
+	}
+	return preferences, nil
 }
 
 func parseTabletPickerCellPreferenceString(str string) (TabletPickerCellPreference, error) {
@@ -150,6 +201,7 @@ type TabletPicker struct {
 	// This map is keyed on the results of TabletAlias.String().
 	ignoreTablets map[string]struct{}
 	options       TabletPickerOptions
+	preferences   []TabletPickerPreference
 }
 
 // NewTabletPicker returns a TabletPicker.
@@ -189,6 +241,10 @@ func NewTabletPicker(
 	if err != nil {
 		return nil, err
 	}
+	preferences, err := ParseTabletPickerPreferences(options.Preferences)
+	if err != nil {
+		return nil, err
+	}
 
 	// For backward compatibility only parse the options for tablet ordering
 	// if the in_order hint wasn't already specified. Otherwise it could be overridden.
@@ -206,8 +262,27 @@ func NewTabletPicker(
 		}
 	}
 
+	// The preferences give the tablet types to pick, and the cells to pick
+	// them from on top of the given cells.
+	var preferLocal bool
+	if len(preferences) > 0 {
+		tabletTypes = nil
+		for _, pref := range preferences {
+			if !topoproto.IsTypeInList(pref.TabletType, tabletTypes) {
+				tabletTypes = append(tabletTypes, pref.TabletType)
+			}
+			switch pref.Cell {
+			case TabletPickerPreferenceLocalCell, TabletPickerPreferenceLocalAlias:
+				preferLocal = true
+			case TabletPickerPreferenceAnyCell:
+			default:
+				cells = append(cells, pref.Cell)
+			}
+		}
+	}
+
 	aliasCellMap := make(map[string]string)
-	if cellPref == TabletPickerCellPreference_PreferLocalWithAlias {
+	if cellPref == TabletPickerCellPreference_PreferLocalWithAlias || preferLocal {
 		if localCell == "" {
 			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot have local cell preference without local cell")
 		}
@@ -246,6 +321,7 @@ func NewTabletPicker(
 		cellPref:      cellPref,
 		ignoreTablets: make(map[string]struct{}, len(ignoreTablets)),
 		options:       options,
+		preferences:   preferences,
 	}
 
 	for _, ignoreTablet := range ignoreTablets {
@@ -306,8 +382,58 @@ func (tp *TabletPicker) orderByTabletType(candidates []*topo.TabletInfo) []*topo
 	return candidates
 }
 
+// preferenceRank returns the index of the first preference matched by the
+// tablet, or -1 if it matches none of them.
+func (tp *TabletPicker) preferenceRank(candidate *topo.TabletInfo) int {
+	for i, pref := range tp.preferences {
+		if candidate.Type != pref.TabletType {
+			continue
+		}
+		cell := candidate.Alias.Cell
+		switch pref.Cell {
+		case TabletPickerPreferenceLocalCell:
+			if cell == tp.localCellInfo.localCell {
+				return i
+			}
+		case TabletPickerPreferenceLocalAlias:
+			if _, ok := tp.localCellInfo.cellsInAlias[cell]; ok || cell == tp.localCellInfo.localCell {
+				return i
+			}
+		case TabletPickerPreferenceAnyCell:
+			return i
+		default:
+			if cell == pref.Cell {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// orderByPreference orders the candidates by the first preference they match,
+// randomly among the candidates matching the same one, and drops the candidates
+// matching none of them.
+func (tp *TabletPicker) orderByPreference(candidates []*topo.TabletInfo) []*topo.TabletInfo {
+	ranked := make([][]*topo.TabletInfo, len(tp.preferences))
+	for _, c := range candidates {
+		if rank := tp.preferenceRank(c); rank >= 0 {
+			ranked[rank] = append(ranked[rank], c)
+		}
+	}
+	ordered := make([]*topo.TabletInfo, 0, len(candidates))
+	for _, rankCandidates := range ranked {
+		rand.Shuffle(len(rankCandidates), func(i, j int) {
+			rankCandidates[i], rankCandidates[j] = rankCandidates[j], rankCandidates[i]
+		})
+		ordered = append(ordered, rankCandidates...)
+	}
+	return ordered
+}
+
 func (tp *TabletPicker) sortCandidates(ctx context.Context, candidates []*topo.TabletInfo) []*topo.TabletInfo {
-	if tp.cellPref == TabletPickerCellPreference_PreferLocalWithAlias {
+	if len(tp.preferences) > 0 {
+		candidates = tp.orderByPreference(candidates)
+	} else if tp.cellPref == TabletPickerCellPreference_PreferLocalWithAlias {
 		sameCellCandidates, sameAliasCandidates, allOtherCandidates := tp.prioritizeTablets(candidates)
 
 		if tp.inOrder {
@@ -369,6 +495,7 @@ func (tp *TabletPicker) PickForStreaming(ctx context.Context) (*topodatapb.Table
 			continue
 		}
 		log.Infof("Tablet picker found a healthy tablet for streaming: %s", candidates[0].Tablet.String())
+		tp.incPickStats(candidates[0])
 		return candidates[0].Tablet, nil
 	}
 }
@@ -486,11 +613,15 @@ func init() {
 type tabletPickerStats struct {
 	mu                 sync.Mutex
 	noTabletFoundError *stats.CountersWithMultiLabels
+	pickCount          *stats.CountersWithMultiLabels
+	failoverCount      *stats.CountersWithMultiLabels
 }
 
 func newTabletPickerStats() *tabletPickerStats {
 	tpStats := &tabletPickerStats{}
 	tpStats.noTabletFoundError = stats.NewCountersWithMultiLabels("TabletPickerNoTabletFoundErrorCount", "", []string{"cells", "keyspace", "shard", "types"})
+	tpStats.pickCount = stats.NewCountersWithMultiLabels("TabletPickerPickCount", "Number of tablets picked", []string{"keyspace", "shard", "cell", "type"})
+	tpStats.failoverCount = stats.NewCountersWithMultiLabels("TabletPickerFailoverCount", "Number of tablets picked with a preference other than the first one, by the preference they were picked with", []string{"keyspace", "shard", "preference"})
 	return tpStats
 }
 
@@ -502,3 +633,14 @@ func (tp *TabletPicker) incNoTabletFoundStat() {
 	labels := []string{cells, tp.keyspace, tp.shard, tabletTypes}
 	globalTPStats.noTabletFoundError.Add(labels, 1)
 }
+
+func (tp *TabletPicker) incPickStats(picked *topo.TabletInfo) {
+	globalTPStats.mu.Lock()
+	defer globalTPStats.mu.Unlock()
+	globalTPStats.pickCount.Add([]string{tp.keyspace, tp.shard, picked.Alias.Cell, strings.ToLower(picked.Type.String())}, 1)
+	if len(tp.preferences) > 0 {
+		if rank := tp.preferenceRank(picked); rank > 0 {
+			globalTPStats.failoverCount.Add([]string{tp.keyspace, tp.shard, tp.preferences[rank].String()}, 1)
+		}
+	}
+}
@@ -662,6 +662,86 @@ func TestPickNonServingTablets(t *testing.T) {
 	assert.True(t, picked3)
 }
 
+func TestParseTabletPickerPreferences(t *testing.T) {
+	preferences, err := ParseTabletPickerPreferences("rdonly@local, replica@alias,REPLICA@otherCell,primary@any")
+	require.NoError(t, err)
+	assert.Equal(t, []TabletPickerPreference{
+		{TabletType: topodatapb.TabletType_RDONLY, Cell: TabletPickerPreferenceLocalCell},
+		{TabletType: topodatapb.TabletType_REPLICA, Cell: TabletPickerPreferenceLocalAlias},
+		{TabletType: topodatapb.TabletType_REPLICA, Cell: "otherCell"},
+		{TabletType: topodatapb.TabletType_PRIMARY, Cell: TabletPickerPreferenceAnyCell},
+	}, preferences)
+	assert.Equal(t, "replica@alias", preferences[1].String())
+
+	preferences, err = ParseTabletPickerPreferences("")
+	require.NoError(t, err)
+	assert.Empty(t, preferences)
+
+	_, err = ParseTabletPickerPreferences("rdonly")
+	assert.ErrorContains(t, err, "invalid tablet picker preference rdonly: expected <tablet_type>@<cells>")
+	_, err = ParseTabletPickerPreferences("foo@local")
+	assert.ErrorContains(t, err, "invalid tablet picker preference foo@local")
+}
+
+func TestPickPreferences(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	// test env puts "cell" and "otherCell" into an alias called "cella", but not "remote"
+	te := newPickerTestEnv(t, ctx, []string{"cell", "otherCell"}, "remote")
+	localReplica := addTablet(ctx, te, 100, topodatapb.TabletType_REPLICA, "cell", true, true)
+	defer deleteTablet(t, te, localReplica)
+	localRdonly := addTablet(ctx, te, 101, topodatapb.TabletType_RDONLY, "cell", true, true)
+	aliasReplica := addTablet(ctx, te, 102, topodatapb.TabletType_REPLICA, "otherCell", true, true)
+	defer deleteTablet(t, te, aliasReplica)
+	remoteRdonly := addTablet(ctx, te, 103, topodatapb.TabletType_RDONLY, "remote", true, true)
+	defer deleteTablet(t, te, remoteRdonly)
+
+	pick := func(tp *TabletPicker) *topodatapb.Tablet {
+		ctx, cancel := context.WithTimeout(ctx, contextTimeout)
+		defer cancel()
+		tablet, err := tp.PickForStreaming(ctx)
+		require.NoError(t, err)
+		return tablet
+	}
+
+	// The preferences override the tablet types and add the cells they name.
+	tp, err := NewTabletPicker(ctx, te.topoServ, []string{"cell"}, "cell", te.keyspace, te.shard, "primary",
+		TabletPickerOptions{Preferences: "rdonly@local,replica@local,rdonly@remote"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []topodatapb.TabletType{topodatapb.TabletType_RDONLY, topodatapb.TabletType_REPLICA}, tp.tabletTypes)
+	assert.ElementsMatch(t, []string{"cell", "cella", "remote"}, tp.cells)
+	for i := 0; i < numTestIterations; i++ {
+		assert.True(t, proto.Equal(localRdonly, pick(tp)))
+	}
+
+	// The picker falls back to the next preference when no tablet matches the first one.
+	failovers := globalTPStats.failoverCount.Counts()["ks.0.replica@local"]
+	deleteTablet(t, te, localRdonly)
+	for i := 0; i < numTestIterations; i++ {
+		assert.True(t, proto.Equal(localReplica, pick(tp)))
+	}
+	assert.EqualValues(t, failovers+numTestIterations, globalTPStats.failoverCount.Counts()["ks.0.replica@local"])
+
+	// Tablets matching none of the preferences are not picked.
+	tp, err = NewTabletPicker(ctx, te.topoServ, []string{"cell"}, "cell", te.keyspace, te.shard, "",
+		TabletPickerOptions{Preferences: "rdonly@local,rdonly@remote", CellPreference: "OnlySpecified"})
+	require.NoError(t, err)
+	for i := 0; i < numTestIterations; i++ {
+		assert.True(t, proto.Equal(remoteRdonly, pick(tp)))
+	}
+
+	tp, err = NewTabletPicker(ctx, te.topoServ, []string{"cell"}, "cell", te.keyspace, te.shard, "",
+		TabletPickerOptions{Preferences: "replica@otherCell,replica@local"})
+	require.NoError(t, err)
+	for i := 0; i < numTestIterations; i++ {
+		assert.True(t, proto.Equal(aliasReplica, pick(tp)))
+	}
+
+	_, err = NewTabletPicker(ctx, te.topoServ, []string{"cell"}, "", te.keyspace, te.shard, "",
+		TabletPickerOptions{Preferences: "replica@alias", CellPreference: "OnlySpecified"})
+	assert.ErrorContains(t, err, "cannot have local cell preference without local cell")
+}
+
 type pickerTestEnv struct {
 	t        *testing.T
 	keyspace string
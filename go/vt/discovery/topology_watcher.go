@@ -404,16 +404,5 @@ func NewFilterByTabletTags(tabletTags map[string]string) *FilterByTabletTags {
 
 // IsIncluded returns true if the tablet's tags match what we expect.
 func (fbtg *FilterByTabletTags) IsIncluded(tablet *topodata.Tablet) bool {
-	if fbtg.tags == nil {
-		return true
-	}
-	if tablet.Tags == nil {
-		return false
-	}
-	for key, val := range fbtg.tags {
-		if tabletVal, found := tablet.Tags[key]; !found || tabletVal != val {
-			return false
-		}
-	}
-	return true
+	return topoproto.TabletHasTags(tablet, fbtg.tags)
 }
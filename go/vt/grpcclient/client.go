@@ -28,7 +28,6 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 
@@ -157,21 +156,36 @@ func interceptors() []grpc.DialOption {
 // SecureDialOption returns the gRPC dial option to use for the
 // given client connection. It is either using TLS, or Insecure if
 // nothing is set.
-func SecureDialOption(cert, key, ca, crl, name string) (grpc.DialOption, error) {
+//
+// The TLS config is rebuilt for each new connection, so that changes to the
+// cert, key, ca and crl files are picked up without a restart. If spiffeIDs
+// is set, the server certificate must carry one of these SPIFFE IDs, which
+// replaces the verification of its hostname.
+func SecureDialOption(cert, key, ca, crl, name string, spiffeIDs []string) (grpc.DialOption, error) {
 	// No security options set, just return.
 	if (cert == "" || key == "") && ca == "" {
 		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
 	}
 
 	// Load the config. At this point we know
-	// we want a strict config with verify identity.
-	config, err := vttls.ClientConfig(vttls.VerifyIdentity, cert, key, ca, crl, name, tls.VersionTLS12)
+	// we want a strict config with verify identity,
+	// or verify CA when identities are SPIFFE IDs.
+	mode := vttls.VerifyIdentity
+	if len(spiffeIDs) > 0 {
+		mode = vttls.VerifyCA
+	}
+	creds, err := grpccommon.NewReloadingTLS(func() (*tls.Config, error) {
+		config, err := vttls.ClientConfig(mode, cert, key, ca, crl, name, tls.VersionTLS12)
+		if err != nil {
+			return nil, err
+		}
+		return config, vttls.RequireSPIFFEIDs(config, spiffeIDs)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Create the creds server options.
-	creds := credentials.NewTLS(config)
 	return grpc.WithTransportCredentials(creds), nil
 }
 
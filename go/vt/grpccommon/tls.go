@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpccommon
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadingTLSCreds are TLS transport credentials which build a new TLS config
+// for each handshake, so that new connections pick up rotated certificates.
+type reloadingTLSCreds struct {
+	credentials.TransportCredentials
+	config func() (*tls.Config, error)
+}
+
+// NewReloadingTLS returns TLS transport credentials calling config for each
+// handshake. config is expected to be cheap, for instance because it uses the
+// vttls file caches, which reload certificate, CA and CRL files on change.
+func NewReloadingTLS(config func() (*tls.Config, error)) (credentials.TransportCredentials, error) {
+	initial, err := config()
+	if err != nil {
+		return nil, err
+	}
+	return &reloadingTLSCreds{
+		TransportCredentials: credentials.NewTLS(initial),
+		config:               config,
+	}, nil
+}
+
+func (c *reloadingTLSCreds) current() (credentials.TransportCredentials, error) {
+	config, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ClientHandshake is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLSCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds, err := c.current()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return creds.ClientHandshake(ctx, authority, conn)
+}
+
+// ServerHandshake is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLSCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds, err := c.current()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return creds.ServerHandshake(conn)
+}
+
+// Clone is part of the credentials.TransportCredentials interface.
+func (c *reloadingTLSCreds) Clone() credentials.TransportCredentials {
+	return &reloadingTLSCreds{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		config:               c.config,
+	}
+}
@@ -149,6 +149,7 @@ func Backup(ctx context.Context, params BackupParams) error {
 	if err != nil {
 		return vterrors.Wrap(err, "StartBackup failed")
 	}
+	bh = newChecksumBackupHandle(bh)
 	params.Logger.Infof("Starting backup %v", bh.Name())
 
 	// Scope stats to selected backup engine.
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/ioutil"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// checksumBackupHandle is a BackupHandle which computes the SHA-256 checksums
+// of the files added to the backup, for the backup engines to record them in
+// the MANIFEST.
+type checksumBackupHandle struct {
+	backupstorage.BackupHandle
+
+	mu        sync.Mutex
+	checksums map[string]string
+}
+
+func newChecksumBackupHandle(bh backupstorage.BackupHandle) *checksumBackupHandle {
+	return &checksumBackupHandle{
+		BackupHandle: bh,
+		checksums:    make(map[string]string),
+	}
+}
+
+// AddFile is part of the BackupHandle interface.
+func (bh *checksumBackupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	wc, err := bh.BackupHandle.AddFile(ctx, filename, filesize)
+	if err != nil || filename == backupManifestFileName {
+		return wc, err
+	}
+	return &checksumWriter{WriteCloser: wc, bh: bh, filename: filename, hash: sha256.New()}, nil
+}
+
+// checksumWriter computes the checksum of a file added to a backup, and
+// records it in the handle once the file is closed successfully.
+type checksumWriter struct {
+	io.WriteCloser
+	bh       *checksumBackupHandle
+	filename string
+	hash     hash.Hash
+}
+
+func (cw *checksumWriter) Write(p []byte) (int, error) {
+	n, err := cw.WriteCloser.Write(p)
+	_, _ = cw.hash.Write(p[:n])
+	return n, err
+}
+
+func (cw *checksumWriter) Close() error {
+	if err := cw.WriteCloser.Close(); err != nil {
+		return err
+	}
+	cw.bh.mu.Lock()
+	defer cw.bh.mu.Unlock()
+	cw.bh.checksums[cw.filename] = hex.EncodeToString(cw.hash.Sum(nil))
+	return nil
+}
+
+// backupChecksums returns the checksums of the files added to a backup, if
+// they were computed.
+func backupChecksums(bh backupstorage.BackupHandle) map[string]string {
+	cbh, ok := bh.(*checksumBackupHandle)
+	if !ok {
+		return nil
+	}
+	cbh.mu.Lock()
+	defer cbh.mu.Unlock()
+	checksums := make(map[string]string, len(cbh.checksums))
+	for filename, checksum := range cbh.checksums {
+		checksums[filename] = checksum
+	}
+	return checksums
+}
+
+// VerifyBackup verifies the checksums of the files of a backup. If
+// scratchDir is set, the files of the backup are also restored in a temporary
+// directory created in scratchDir, to test that they can be decompressed;
+// only builtin backups can be restore-tested. The temporary directory is
+// removed once the backup is verified.
+//
+// The problems found in the backup are reported in the returned
+// BackupVerification. An error is only returned if the backup cannot be
+// verified at all.
+func VerifyBackup(ctx context.Context, bh backupstorage.BackupHandle, scratchDir string, logger logutil.Logger) (*BackupVerification, error) {
+	bm, err := GetBackupManifest(ctx, bh)
+	if err != nil {
+		return nil, err
+	}
+	builtin := bm.BackupMethod == "" || bm.BackupMethod == builtinBackupEngineName
+	if scratchDir != "" && !builtin {
+		return nil, vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "only builtin backups can be restore-tested, backup %v was taken with %v", bh.Name(), bm.BackupMethod)
+	}
+	if len(bm.Checksums) == 0 && !builtin {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup %v has no checksums to verify", bh.Name())
+	}
+
+	v := &BackupVerification{RestoreTested: scratchDir != ""}
+	verified := make(map[string]bool)
+	if builtin {
+		var bbm builtinBackupManifest
+		if err := getBackupManifestInto(ctx, bh, &bbm); err != nil {
+			return nil, err
+		}
+		restoreDir := ""
+		if scratchDir != "" {
+			restoreDir, err = os.MkdirTemp(scratchDir, "backup-verify-")
+			if err != nil {
+				return nil, vterrors.Wrap(err, "cannot create the restore directory")
+			}
+			defer os.RemoveAll(restoreDir)
+		}
+		for i := range bbm.FileEntries {
+			name := fmt.Sprintf("%v", i)
+			if err := verifyBuiltinBackupFile(ctx, bh, &bbm, &bbm.FileEntries[i], name, restoreDir, logger); err != nil {
+				v.Errors = append(v.Errors, fmt.Sprintf("%v (%v): %v", name, bbm.FileEntries[i].Name, err.Error()))
+			}
+			verified[name] = true
+			v.VerifiedFiles++
+		}
+	}
+
+	// Verify the files which were not verified with the builtin manifest.
+	names := make([]string, 0, len(bm.Checksums))
+	for name := range bm.Checksums {
+		if !verified[name] {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := verifyBackupFile(ctx, bh, name, bm.Checksums[name]); err != nil {
+			v.Errors = append(v.Errors, fmt.Sprintf("%v: %v", name, err.Error()))
+		}
+		v.VerifiedFiles++
+	}
+
+	v.Valid = len(v.Errors) == 0
+	v.Time = FormatRFC3339(time.Now().UTC())
+	return v, nil
+}
+
+// verifyBackupFile reads a file of a backup and checks its checksum.
+func verifyBackupFile(ctx context.Context, bh backupstorage.BackupHandle, name string, checksum string) error {
+	source, err := bh.ReadFile(ctx, name)
+	if err != nil {
+		return vterrors.Wrap(err, "can't open source file for reading")
+	}
+	defer source.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, source); err != nil {
+		return vterrors.Wrap(err, "failed to read file contents")
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != checksum {
+		return fmt.Errorf("checksum mismatch, got %v expected %v", got, checksum)
+	}
+	return nil
+}
+
+// verifyBuiltinBackupFile reads a file of a builtin backup and checks its
+// checksum, if the backup has checksums, and its hash. If restoreDir is set,
+// the file is decompressed and restored in it.
+func verifyBuiltinBackupFile(ctx context.Context, bh backupstorage.BackupHandle, bm *builtinBackupManifest, fe *FileEntry, name string, restoreDir string, logger logutil.Logger) (finalErr error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	source, err := bh.ReadFile(ctx, name)
+	if err != nil {
+		return vterrors.Wrap(err, "can't open source file for reading")
+	}
+	defer source.Close()
+
+	h := sha256.New()
+	br := newBackupReader(name, 0, io.TeeReader(source, h))
+	defer br.Close()
+	var reader io.Reader = br
+
+	dest := io.Discard
+	if restoreDir != "" {
+		p := filepath.Join(restoreDir, fe.Base, fe.Name)
+		if !strings.HasPrefix(p, restoreDir+string(os.PathSeparator)) {
+			return fmt.Errorf("file %v/%v is outside of the restore directory", fe.Base, fe.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			return vterrors.Wrap(err, "can't create destination directory")
+		}
+		f, err := os.Create(p)
+		if err != nil {
+			return vterrors.Wrap(err, "can't open destination file for writing")
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil {
+				finalErr = errors.Join(finalErr, vterrors.Wrap(cerr, "failed to close destination file"))
+			}
+		}()
+		dest = f
+
+		if !bm.SkipCompress {
+			decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, logger)
+			if err != nil {
+				return vterrors.Wrap(err, "can't create decompressor")
+			}
+			closer := ioutil.NewTimeoutCloser(ctx, decompressor, closeTimeout)
+			defer func() {
+				if cerr := closer.Close(); cerr != nil {
+					finalErr = errors.Join(finalErr, vterrors.Wrap(cerr, "failed to close decompressor"))
+				}
+			}()
+			reader = decompressor
+		}
+	}
+
+	if _, err := io.Copy(dest, reader); err != nil {
+		return vterrors.Wrap(err, "failed to copy file contents")
+	}
+	if hash := br.HashString(); hash != fe.Hash {
+		return fmt.Errorf("hash mismatch, got %v expected %v", hash, fe.Hash)
+	}
+	if checksum, ok := bm.Checksums[name]; ok {
+		if got := hex.EncodeToString(h.Sum(nil)); got != checksum {
+			return fmt.Errorf("checksum mismatch, got %v expected %v", got, checksum)
+		}
+	}
+	return nil
+}
+
+// RecordBackupVerification records a verification in the MANIFEST of a
+// backup. It returns false if the backup storage cannot update the MANIFEST.
+func RecordBackupVerification(ctx context.Context, bs backupstorage.BackupStorage, bh backupstorage.BackupHandle, v *BackupVerification) (bool, error) {
+	updater, ok := bs.(backupstorage.BackupFileUpdater)
+	if !ok {
+		return false, nil
+	}
+
+	// The MANIFEST is decoded generically, to keep the fields of all the
+	// backup engines.
+	var manifest map[string]json.RawMessage
+	if err := getBackupManifestInto(ctx, bh, &manifest); err != nil {
+		return false, err
+	}
+	verification, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	manifest["Verification"] = verification
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
+	}
+
+	wc, err := updater.UpdateFile(ctx, bh.Directory(), bh.Name(), backupManifestFileName)
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot update %v", backupManifestFileName)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return false, vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+	if err := wc.Close(); err != nil {
+		return false, vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+	return true, nil
+}
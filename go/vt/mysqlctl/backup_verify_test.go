@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const testBackupDir = "ks/0"
+
+// writeTestBuiltinBackup writes a builtin backup of the given files in the
+// file backup storage, and returns a read-only handle of it.
+func writeTestBuiltinBackup(t *testing.T, bs backupstorage.BackupStorage, name string, skipCompress bool, files []string) backupstorage.BackupHandle {
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+
+	sbh, err := bs.StartBackup(ctx, testBackupDir, name)
+	require.NoError(t, err)
+	bh := newChecksumBackupHandle(sbh)
+
+	bm := &builtinBackupManifest{
+		BackupManifest: BackupManifest{BackupMethod: builtinBackupEngineName, BackupName: name},
+		SkipCompress:   skipCompress,
+	}
+	if !skipCompress {
+		bm.CompressionEngine = PgzipCompressor
+	}
+	for i, contents := range files {
+		wc, err := bh.AddFile(ctx, fmt.Sprintf("%v", i), 0)
+		require.NoError(t, err)
+		bw := newBackupWriter(fmt.Sprintf("%v", i), 1024, 0, wc)
+		if skipCompress {
+			_, err = bw.Write([]byte(contents))
+			require.NoError(t, err)
+		} else {
+			compressor, err := newBuiltinCompressor(PgzipCompressor, bw, logger)
+			require.NoError(t, err)
+			_, err = compressor.Write([]byte(contents))
+			require.NoError(t, err)
+			require.NoError(t, compressor.Close())
+		}
+		require.NoError(t, bw.Close())
+		require.NoError(t, wc.Close())
+		bm.FileEntries = append(bm.FileEntries, FileEntry{
+			Base: backupData,
+			Name: fmt.Sprintf("db/t%v.ibd", i),
+			Hash: bw.HashString(),
+		})
+	}
+	bm.Checksums = backupChecksums(bh)
+
+	data, err := json.MarshalIndent(bm, "", "  ")
+	require.NoError(t, err)
+	wc, err := bh.AddFile(ctx, backupManifestFileName, 0)
+	require.NoError(t, err)
+	_, err = wc.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+
+	bhs, err := bs.ListBackups(ctx, testBackupDir)
+	require.NoError(t, err)
+	for _, h := range bhs {
+		if h.Name() == name {
+			return h
+		}
+	}
+	require.FailNow(t, "backup not found", name)
+	return nil
+}
+
+func setupTestBackupStorage(t *testing.T) backupstorage.BackupStorage {
+	filebackupstorage.FileBackupStorageRoot = t.TempDir()
+	return backupstorage.BackupStorageMap["file"]
+}
+
+func TestChecksumBackupHandle(t *testing.T) {
+	bs := setupTestBackupStorage(t)
+	bh := writeTestBuiltinBackup(t, bs, "backup", true, []string{"hello", "world"})
+
+	bm, err := GetBackupManifest(context.Background(), bh)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"0": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"1": "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7",
+	}, bm.Checksums)
+}
+
+func TestVerifyBackup(t *testing.T) {
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	bs := setupTestBackupStorage(t)
+
+	for _, skipCompress := range []bool{true, false} {
+		t.Run(fmt.Sprintf("skipCompress=%v", skipCompress), func(t *testing.T) {
+			name := fmt.Sprintf("backup-%v", skipCompress)
+			bh := writeTestBuiltinBackup(t, bs, name, skipCompress, []string{"hello", "world"})
+
+			v, err := VerifyBackup(ctx, bh, "", logger)
+			require.NoError(t, err)
+			assert.True(t, v.Valid)
+			assert.Empty(t, v.Errors)
+			assert.Equal(t, 2, v.VerifiedFiles)
+			assert.False(t, v.RestoreTested)
+
+			scratchDir := t.TempDir()
+			v, err = VerifyBackup(ctx, bh, scratchDir, logger)
+			require.NoError(t, err)
+			assert.True(t, v.Valid)
+			assert.Equal(t, 2, v.VerifiedFiles)
+			assert.True(t, v.RestoreTested)
+
+			// The restored files are removed.
+			entries, err := os.ReadDir(scratchDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+
+	t.Run("corrupted file", func(t *testing.T) {
+		bh := writeTestBuiltinBackup(t, bs, "corrupted", true, []string{"hello", "world"})
+		p := path.Join(filebackupstorage.FileBackupStorageRoot, testBackupDir, "corrupted", "1")
+		require.NoError(t, os.WriteFile(p, []byte("w0rld"), 0644))
+
+		v, err := VerifyBackup(ctx, bh, "", logger)
+		require.NoError(t, err)
+		assert.False(t, v.Valid)
+		require.Len(t, v.Errors, 1)
+		assert.Contains(t, v.Errors[0], "1 (db/t1.ibd): hash mismatch")
+		assert.Equal(t, 2, v.VerifiedFiles)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		bh := writeTestBuiltinBackup(t, bs, "missing", true, []string{"hello", "world"})
+		p := path.Join(filebackupstorage.FileBackupStorageRoot, testBackupDir, "missing", "0")
+		require.NoError(t, os.Remove(p))
+
+		v, err := VerifyBackup(ctx, bh, "", logger)
+		require.NoError(t, err)
+		assert.False(t, v.Valid)
+		require.Len(t, v.Errors, 1)
+		assert.Contains(t, v.Errors[0], "0 (db/t0.ibd): can't open source file for reading")
+	})
+}
+
+func TestVerifyBackupOtherEngine(t *testing.T) {
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	bs := setupTestBackupStorage(t)
+
+	writeBackup := func(name string, checksums bool) backupstorage.BackupHandle {
+		sbh, err := bs.StartBackup(ctx, testBackupDir, name)
+		require.NoError(t, err)
+		bh := newChecksumBackupHandle(sbh)
+		wc, err := bh.AddFile(ctx, "backup.xbstream.gz", 0)
+		require.NoError(t, err)
+		_, err = wc.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, wc.Close())
+
+		bm := &BackupManifest{BackupMethod: xtrabackupEngineName, BackupName: name}
+		if checksums {
+			bm.Checksums = backupChecksums(bh)
+		}
+		data, err := json.Marshal(bm)
+		require.NoError(t, err)
+		wc, err = bh.AddFile(ctx, backupManifestFileName, 0)
+		require.NoError(t, err)
+		_, err = wc.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, wc.Close())
+		require.NoError(t, bh.EndBackup(ctx))
+		return filebackupstorage.NewBackupHandle(nil, testBackupDir, name, true)
+	}
+
+	bh := writeBackup("with-checksums", true)
+	v, err := VerifyBackup(ctx, bh, "", logger)
+	require.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Equal(t, 1, v.VerifiedFiles)
+
+	_, err = VerifyBackup(ctx, bh, t.TempDir(), logger)
+	assert.Equal(t, vtrpc.Code_UNIMPLEMENTED, vterrors.Code(err))
+
+	bh = writeBackup("without-checksums", false)
+	_, err = VerifyBackup(ctx, bh, "", logger)
+	assert.Equal(t, vtrpc.Code_FAILED_PRECONDITION, vterrors.Code(err))
+}
+
+func TestRecordBackupVerification(t *testing.T) {
+	ctx := context.Background()
+	bs := setupTestBackupStorage(t)
+	bh := writeTestBuiltinBackup(t, bs, "backup", true, []string{"hello", "world"})
+
+	v, err := VerifyBackup(ctx, bh, "", logutil.NewMemoryLogger())
+	require.NoError(t, err)
+	recorded, err := RecordBackupVerification(ctx, bs, bh, v)
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	// The verification is recorded, and the fields of the builtin engine are kept.
+	var bm builtinBackupManifest
+	require.NoError(t, getBackupManifestInto(ctx, bh, &bm))
+	assert.Equal(t, v, bm.Verification)
+	assert.Len(t, bm.FileEntries, 2)
+	assert.True(t, bm.SkipCompress)
+	assert.Len(t, bm.Checksums, 2)
+}
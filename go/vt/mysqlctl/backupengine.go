@@ -203,7 +203,7 @@ func isIncrementalBackup(params BackupParams) bool {
 }
 
 func registerBackupEngineFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&backupEngineImplementation, "backup_engine_implementation", backupEngineImplementation, "Specifies which implementation to use for creating new backups (builtin or xtrabackup). Restores will always be done with whichever engine created a given backup.")
+	fs.StringVar(&backupEngineImplementation, "backup_engine_implementation", backupEngineImplementation, "Specifies which implementation to use for creating new backups (builtin, xtrabackup or snapshot). Restores will always be done with whichever engine created a given backup.")
 }
 
 // GetBackupEngine returns the BackupEngine implementation that should be used
@@ -326,6 +326,35 @@ type BackupManifest struct {
 
 	// IncrementalDetails is nil for non-incremental backups
 	IncrementalDetails *IncrementalBackupDetails
+
+	// Checksums are the hex-encoded SHA-256 checksums of the files of the
+	// backup, other than the MANIFEST, by their name in the backup storage.
+	// They are empty for the backups taken before they were added.
+	Checksums map[string]string `json:",omitempty"`
+
+	// Verification is the result of the last verification of the backup, if
+	// it was verified.
+	Verification *BackupVerification `json:",omitempty"`
+}
+
+// BackupVerification is the result of the verification of a backup.
+type BackupVerification struct {
+	// Time is when the backup was verified, in RFC 3339 format, UTC.
+	Time string
+
+	// Valid is true if all the files of the backup matched their checksums,
+	// and could be restored if the backup was restore-tested.
+	Valid bool
+
+	// Errors are the problems found in the backup.
+	Errors []string `json:",omitempty"`
+
+	// VerifiedFiles is the number of files of the backup which were verified.
+	VerifiedFiles int
+
+	// RestoreTested is true if the files of the backup were restored in a
+	// scratch directory.
+	RestoreTested bool
 }
 
 func (m *BackupManifest) HashKey() string {
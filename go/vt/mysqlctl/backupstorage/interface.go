@@ -128,6 +128,16 @@ type BackupStorage interface {
 	WithParams(Params) BackupStorage
 }
 
+// BackupFileUpdater is implemented by the BackupStorage implementations which
+// can replace a file of a complete backup, e.g. to record its verification in
+// its MANIFEST.
+type BackupFileUpdater interface {
+	// UpdateFile replaces the given file of the backup dir/name with the data
+	// written to the returned WriteCloser. The file is only replaced once the
+	// WriteCloser is closed successfully.
+	UpdateFile(ctx context.Context, dir, name, filename string) (io.WriteCloser, error)
+}
+
 // BackupStorageMap contains the registered implementations for BackupStorage
 var BackupStorageMap = make(map[string]BackupStorage)
 
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupstorage
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// ProfilesDir is the directory of the backup storage where the captured
+// profiles are uploaded, in a subdirectory per component.
+const ProfilesDir = "profiles"
+
+// profileUploader uploads the captured profiles to the backup storage, as
+// backups named after the profiles with the files "profile" and "metadata".
+type profileUploader struct{}
+
+// UploadProfile is part of the servenv.ProfileUploader interface.
+func (profileUploader) UploadProfile(ctx context.Context, capture *servenv.ProfileCapture, data []byte) (location string, finalErr error) {
+	metadata, err := json.MarshalIndent(capture, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	bs, err := GetBackupStorage()
+	if err != nil {
+		return "", err
+	}
+	defer bs.Close()
+	bs = bs.WithParams(NoParams())
+
+	bh, err := bs.StartBackup(ctx, path.Join(ProfilesDir, capture.Component), capture.Name)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if finalErr != nil {
+			bh.AbortBackup(ctx)
+		}
+	}()
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{{"profile", data}, {"metadata", metadata}} {
+		wc, err := bh.AddFile(ctx, file.name, int64(len(file.data)))
+		if err != nil {
+			return "", err
+		}
+		if _, err := wc.Write(file.data); err != nil {
+			wc.Close()
+			return "", err
+		}
+		if err := wc.Close(); err != nil {
+			return "", err
+		}
+	}
+	if err := bh.EndBackup(ctx); err != nil {
+		return "", err
+	}
+	return path.Join(bh.Directory(), bh.Name(), "profile"), nil
+}
+
+func init() {
+	servenv.RegisterProfileUploader("backup_storage", profileUploader{})
+}
@@ -107,6 +107,11 @@ type builtinBackupManifest struct {
 	// get a hint about what kind of compression was used.
 	CompressionEngine string `json:",omitempty"`
 
+	// CompressionLevel is the value of --compression-level the files were
+	// compressed with. It is only informational, as the decompressors don't
+	// need it.
+	CompressionLevel int `json:",omitempty"`
+
 	// FileEntries contains all the files in the backup
 	FileEntries []FileEntry
 
@@ -686,6 +691,7 @@ func (be *BuiltinBackupEngine) backupFiles(
 			MySQLVersion:       mysqlVersion,
 			UpgradeSafe:        params.UpgradeSafe,
 			IncrementalDetails: incrDetails,
+			Checksums:          backupChecksums(bh),
 		},
 
 		// Builtin-specific fields
@@ -694,6 +700,9 @@ func (be *BuiltinBackupEngine) backupFiles(
 		CompressionEngine:    CompressionEngineName,
 		ExternalDecompressor: ManifestExternalDecompressorCmd,
 	}
+	if backupStorageCompress && ExternalCompressorCmd == "" {
+		bm.CompressionLevel = compressionLevel
+	}
 	data, err := json.MarshalIndent(bm, "", "  ")
 	if err != nil {
 		return vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
@@ -860,12 +869,7 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		}()
 		// Create the gzip compression pipe, if necessary.
 		if backupStorageCompress {
-			var compressor io.WriteCloser
-			if ExternalCompressorCmd != "" {
-				compressor, err = newExternalCompressor(ctx, ExternalCompressorCmd, writer, params.Logger)
-			} else {
-				compressor, err = newBuiltinCompressor(CompressionEngineName, writer, params.Logger)
-			}
+			compressor, err := newCompressor(ctx, CompressionEngineName, writer, params.Logger)
 			if err != nil {
 				return vterrors.Wrap(err, "can't create compressor")
 			}
@@ -1107,30 +1111,7 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 
 	// Create the uncompresser if needed.
 	if !bm.SkipCompress {
-		var decompressor io.ReadCloser
-		var deCompressionEngine = bm.CompressionEngine
-
-		if deCompressionEngine == "" {
-			// for backward compatibility
-			deCompressionEngine = PgzipCompressor
-		}
-		externalDecompressorCmd := ExternalDecompressorCmd
-		if externalDecompressorCmd == "" && bm.ExternalDecompressor != "" {
-			externalDecompressorCmd = bm.ExternalDecompressor
-		}
-		if externalDecompressorCmd != "" {
-			if deCompressionEngine == ExternalCompressor {
-				deCompressionEngine = externalDecompressorCmd
-				decompressor, err = newExternalDecompressor(ctx, deCompressionEngine, reader, params.Logger)
-			} else {
-				decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, params.Logger)
-			}
-		} else {
-			if deCompressionEngine == ExternalCompressor {
-				return fmt.Errorf("%w value: %q", errUnsupportedDeCompressionEngine, ExternalCompressor)
-			}
-			decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, params.Logger)
-		}
+		decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, params.Logger)
 		if err != nil {
 			return vterrors.Wrap(err, "can't create decompressor")
 		}
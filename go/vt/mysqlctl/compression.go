@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"strings"
 	"sync"
 
 	"github.com/google/shlex"
@@ -42,6 +43,14 @@ const (
 	ZstdCompressor     = "zstd"
 	Lz4Compressor      = "lz4"
 	ExternalCompressor = "external"
+
+	// ExternalZstdCompressor and ExternalPigzCompressor compress the backups
+	// with the zstd and pigz commands, whose parallelism is set from
+	// --backup_storage_number_blocks. Both commands decompress with a single
+	// thread. The backups are compatible with the zstd and pgzip engines,
+	// which are used to restore them when the commands are not available.
+	ExternalZstdCompressor = "external-zstd"
+	ExternalPigzCompressor = "external-pigz"
 )
 
 var (
@@ -53,15 +62,26 @@ var (
 	ExternalCompressorExt           string
 	ExternalDecompressorCmd         string
 	ManifestExternalDecompressorCmd string
+	// decompressionConcurrency is the number of blocks the zstd engine
+	// decompresses in parallel, and the pgzip engine reads ahead, when
+	// restoring a backup. 0 uses GOMAXPROCS for zstd and 16 blocks for pgzip.
+	decompressionConcurrency int
 
 	errUnsupportedDeCompressionEngine = errors.New("unsupported engine in MANIFEST. You need to provide --external-decompressor if using 'external' compression engine")
-	errUnsupportedCompressionEngine   = errors.New("unsupported engine value for --compression-engine-name. supported values are 'external', 'pgzip', 'pargzip', 'zstd', 'lz4'")
+	errUnsupportedCompressionEngine   = errors.New("unsupported engine value for --compression-engine-name. supported values are 'external', 'external-zstd', 'external-pigz', 'pgzip', 'pargzip', 'zstd', 'lz4'")
 
 	// this is used by getEngineFromExtension() to figure out which engine to use in case the user didn't specify
 	engineExtensions = map[string][]string{
-		".gz":  {PgzipCompressor, PargzipCompressor},
+		".gz":  {PgzipCompressor, PargzipCompressor, ExternalPigzCompressor},
 		".lz4": {Lz4Compressor},
-		".zst": {ZstdCompressor},
+		".zst": {ZstdCompressor, ExternalZstdCompressor},
+	}
+
+	// externalEngineBuiltins maps the external compression engines to the
+	// builtin engines able to decompress their backups.
+	externalEngineBuiltins = map[string]string{
+		ExternalZstdCompressor: ZstdCompressor,
+		ExternalPigzCompressor: PgzipCompressor,
 	}
 )
 
@@ -78,6 +98,7 @@ func registerBackupCompressionFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ExternalCompressorExt, "external-compressor-extension", ExternalCompressorExt, "extension to use when using an external compressor.")
 	fs.StringVar(&ExternalDecompressorCmd, "external-decompressor", ExternalDecompressorCmd, "command with arguments to use when decompressing a backup.")
 	fs.StringVar(&ManifestExternalDecompressorCmd, "manifest-external-decompressor", ManifestExternalDecompressorCmd, "command with arguments to store in the backup manifest when compressing a backup with an external compression engine.")
+	fs.IntVar(&decompressionConcurrency, "decompression-concurrency", decompressionConcurrency, "number of blocks decompressed in parallel by the zstd engine, and read ahead by the pgzip engine, when restoring a compressed backup. gzip streams are decompressed by a single thread. 0 uses GOMAXPROCS for zstd and 16 blocks for pgzip.")
 }
 
 func getExtensionFromEngine(engine string) (string, error) {
@@ -107,6 +128,8 @@ func validateExternalCompressionEngineName(engine string) error {
 	case Lz4Compressor:
 	case ZstdCompressor:
 	case ExternalCompressor:
+	case ExternalZstdCompressor:
+	case ExternalPigzCompressor:
 	default:
 		return fmt.Errorf("%w value: %q", errUnsupportedCompressionEngine, engine)
 	}
@@ -114,6 +137,80 @@ func validateExternalCompressionEngineName(engine string) error {
 	return nil
 }
 
+// externalEngineCompressorCmd returns the command compressing the backups of
+// an external compression engine.
+func externalEngineCompressorCmd(engine string) string {
+	switch engine {
+	case ExternalZstdCompressor:
+		return fmt.Sprintf("zstd -c -q -T%d -%d", backupCompressBlocks, compressionLevel)
+	case ExternalPigzCompressor:
+		return fmt.Sprintf("pigz -c -p %d -%d", backupCompressBlocks, compressionLevel)
+	}
+	return ""
+}
+
+// externalEngineDecompressorCmd returns the command decompressing the backups
+// of an external compression engine. Neither command decompresses in parallel.
+func externalEngineDecompressorCmd(engine string) string {
+	switch engine {
+	case ExternalZstdCompressor:
+		return "zstd -d -c -q"
+	case ExternalPigzCompressor:
+		return "pigz -d -c"
+	}
+	return ""
+}
+
+// newCompressor returns a writer that compresses the data with the given engine,
+// or with the --external-compressor command if set, before writing it to the
+// underlying writer.
+func newCompressor(ctx context.Context, engine string, writer io.Writer, logger logutil.Logger) (io.WriteCloser, error) {
+	if ExternalCompressorCmd != "" {
+		return newExternalCompressor(ctx, ExternalCompressorCmd, writer, logger)
+	}
+	if cmd := externalEngineCompressorCmd(engine); cmd != "" {
+		return newExternalCompressor(ctx, cmd, writer, logger)
+	}
+	return newBuiltinCompressor(engine, writer, logger)
+}
+
+// newDecompressor returns a reader that decompresses the data of a backup
+// compressed with the given engine. The --external-decompressor command is
+// used for the backups compressed with an external command, and otherwise the
+// command recorded in the manifest. The backups of the external-zstd and
+// external-pigz engines are decompressed with the builtin zstd and pgzip
+// engines when their command is not available.
+func newDecompressor(ctx context.Context, engine, manifestDecompressorCmd string, reader io.Reader, logger logutil.Logger) (io.ReadCloser, error) {
+	if engine == "" {
+		// for backward compatibility
+		engine = PgzipCompressor
+	}
+	externalDecompressorCmd := ExternalDecompressorCmd
+	if externalDecompressorCmd == "" && manifestDecompressorCmd != "" {
+		externalDecompressorCmd = manifestDecompressorCmd
+	}
+
+	switch engine {
+	case ExternalCompressor:
+		if externalDecompressorCmd == "" {
+			return nil, fmt.Errorf("%w value: %q", errUnsupportedDeCompressionEngine, ExternalCompressor)
+		}
+		return newExternalDecompressor(ctx, externalDecompressorCmd, reader, logger)
+	case ExternalZstdCompressor, ExternalPigzCompressor:
+		if externalDecompressorCmd != "" {
+			return newExternalDecompressor(ctx, externalDecompressorCmd, reader, logger)
+		}
+		cmd := externalEngineDecompressorCmd(engine)
+		name, _, _ := strings.Cut(cmd, " ")
+		if _, err := validateExternalCmd(name); err != nil {
+			logger.Warningf("cannot find %q to decompress backup compressed with engine %q, using engine %q instead", name, engine, externalEngineBuiltins[engine])
+			return newBuiltinDecompressor(externalEngineBuiltins[engine], reader, logger)
+		}
+		return newExternalDecompressor(ctx, cmd, reader, logger)
+	}
+	return newBuiltinDecompressor(engine, reader, logger)
+}
+
 func prepareExternalCmd(ctx context.Context, cmdStr string) (*exec.Cmd, error) {
 	cmdArgs, err := shlex.Split(cmdStr)
 	if err != nil {
@@ -201,7 +298,9 @@ func newBuiltinDecompressor(engine string, reader io.Reader, logger logutil.Logg
 
 	switch engine {
 	case PgzipCompressor:
-		d, err := pgzip.NewReader(reader)
+		// gzip streams can only be decompressed sequentially, so pgzip only
+		// reads blocks ahead and checks their CRC in parallel.
+		d, err := pgzip.NewReaderN(reader, 0, decompressionConcurrency)
 		if err != nil {
 			return nil, err
 		}
@@ -209,7 +308,8 @@ func newBuiltinDecompressor(engine string, reader io.Reader, logger logutil.Logg
 	case Lz4Compressor:
 		decompressor = io.NopCloser(lz4.NewReader(reader))
 	case ZstdCompressor:
-		d, err := zstd.NewReader(reader)
+		// A concurrency of 0 uses GOMAXPROCS decoders.
+		d, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(max(decompressionConcurrency, 0)))
 		if err != nil {
 			return nil, err
 		}
@@ -246,7 +346,9 @@ func newBuiltinCompressor(engine string, writer io.Writer, logger logutil.Logger
 		}
 		compressor = lz4Writer
 	case ZstdCompressor:
-		zst, err := zstd.NewWriter(writer, zstd.WithEncoderLevel(zstd.EncoderLevel(compressionLevel)))
+		zst, err := zstd.NewWriter(writer,
+			zstd.WithEncoderLevel(zstd.EncoderLevel(compressionLevel)),
+			zstd.WithEncoderConcurrency(backupCompressBlocks))
 		if err != nil {
 			return compressor, vterrors.Wrap(err, "cannot create zstd compressor")
 		}
@@ -40,6 +40,8 @@ func TestGetExtensionFromEngine(t *testing.T) {
 		{"pargzip", ".gz", nil},
 		{"lz4", ".lz4", nil},
 		{"zstd", ".zst", nil},
+		{"external-zstd", ".zst", nil},
+		{"external-pigz", ".gz", nil},
 		{"foobar", "", errUnsupportedCompressionEngine},
 	}
 
@@ -79,13 +81,138 @@ func TestBuiltinCompressors(t *testing.T) {
 	}
 }
 
+func TestDecompressionConcurrency(t *testing.T) {
+	data := bytes.Repeat([]byte("foo bar foobar"), 100000)
+	logger := logutil.NewMemoryLogger()
+	defer func(concurrency int) { decompressionConcurrency = concurrency }(decompressionConcurrency)
+
+	for _, tc := range []struct {
+		engine      string
+		concurrency int
+	}{
+		{"pgzip", 0},
+		{"pgzip", 8},
+		{"zstd", 0},
+		{"zstd", 8},
+	} {
+		t.Run(fmt.Sprintf("%s-%d", tc.engine, tc.concurrency), func(t *testing.T) {
+			decompressionConcurrency = tc.concurrency
+			var compressed, decompressed bytes.Buffer
+			compressor, err := newBuiltinCompressor(tc.engine, &compressed, logger)
+			require.NoError(t, err)
+			_, err = compressor.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, compressor.Close())
+
+			decompressor, err := newBuiltinDecompressor(tc.engine, &compressed, logger)
+			require.NoError(t, err)
+			_, err = io.Copy(&decompressed, decompressor)
+			require.NoError(t, err)
+			decompressor.Close()
+			assert.Equal(t, data, decompressed.Bytes())
+		})
+	}
+}
+
+func TestExternalEngines(t *testing.T) {
+	data := []byte("foo bar foobar")
+	logger := logutil.NewMemoryLogger()
+	defer func(engine string) { CompressionEngineName = engine }(CompressionEngineName)
+
+	tests := []struct {
+		engine, builtin string
+	}{
+		{ExternalZstdCompressor, ZstdCompressor},
+		{ExternalPigzCompressor, PgzipCompressor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+			CompressionEngineName = tt.engine
+
+			// The backups of the external engines are decompressed with their
+			// command if available, and otherwise with the builtin engine.
+			var compressed, decompressed bytes.Buffer
+			compressor, err := newBuiltinCompressor(tt.builtin, &compressed, logger)
+			require.NoError(t, err)
+			_, err = compressor.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, compressor.Close())
+
+			decompressor, err := newDecompressor(ctx, tt.engine, "", &compressed, logger)
+			require.NoError(t, err)
+			_, err = io.Copy(&decompressed, decompressor)
+			require.NoError(t, err)
+			require.NoError(t, decompressor.Close())
+			assert.Equal(t, data, decompressed.Bytes())
+
+			name, _, _ := strings.Cut(externalEngineCompressorCmd(tt.engine), " ")
+			if _, err := validateExternalCmd(name); err != nil {
+				t.Skip("Command not available in this host:", err)
+			}
+			compressed.Reset()
+			decompressed.Reset()
+			compressor, err = newCompressor(ctx, tt.engine, &compressed, logger)
+			require.NoError(t, err)
+			_, err = compressor.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, compressor.Close())
+
+			decompressor, err = newBuiltinDecompressor(tt.builtin, bytes.NewReader(compressed.Bytes()), logger)
+			require.NoError(t, err)
+			_, err = io.Copy(&decompressed, decompressor)
+			require.NoError(t, err)
+			decompressor.Close()
+			assert.Equal(t, data, decompressed.Bytes())
+		})
+	}
+}
+
+func TestExternalEngineCmds(t *testing.T) {
+	defer func(level, blocks, concurrency int) {
+		compressionLevel, backupCompressBlocks, decompressionConcurrency = level, blocks, concurrency
+	}(compressionLevel, backupCompressBlocks, decompressionConcurrency)
+	compressionLevel, backupCompressBlocks, decompressionConcurrency = 3, 4, 0
+
+	assert.Equal(t, "zstd -c -q -T4 -3", externalEngineCompressorCmd(ExternalZstdCompressor))
+	assert.Equal(t, "pigz -c -p 4 -3", externalEngineCompressorCmd(ExternalPigzCompressor))
+	assert.Equal(t, "zstd -d -c -q", externalEngineDecompressorCmd(ExternalZstdCompressor))
+	assert.Equal(t, "", externalEngineCompressorCmd(ZstdCompressor))
+
+	// The commands do not decompress in parallel.
+	decompressionConcurrency = 8
+	assert.Equal(t, "pigz -d -c", externalEngineDecompressorCmd(ExternalPigzCompressor))
+}
+
+func TestNewDecompressor(t *testing.T) {
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	defer func(cmd string) { ExternalDecompressorCmd = cmd }(ExternalDecompressorCmd)
+	ExternalDecompressorCmd = ""
+
+	_, err := newDecompressor(ctx, ExternalCompressor, "", nil, logger)
+	require.ErrorIs(t, err, errUnsupportedDeCompressionEngine)
+
+	_, err = newDecompressor(ctx, "foobar", "", nil, logger)
+	require.ErrorContains(t, err, `Unkown decompressor engine: "foobar"`)
+
+	// The manifest's decompressor is used for the external engine.
+	decompressor, err := newDecompressor(ctx, ExternalCompressor, "cat", strings.NewReader("foo"), logger)
+	require.NoError(t, err)
+	data, err := io.ReadAll(decompressor)
+	require.NoError(t, err)
+	require.NoError(t, decompressor.Close())
+	assert.Equal(t, "foo", string(data))
+}
+
 func TestUnSupportedBuiltinCompressors(t *testing.T) {
 	logger := logutil.NewMemoryLogger()
 
 	for _, engine := range []string{"external", "foobar"} {
 		t.Run(engine, func(t *testing.T) {
 			_, err := newBuiltinCompressor(engine, nil, logger)
-			require.ErrorContains(t, err, "unsupported engine value for --compression-engine-name. supported values are 'external', 'pgzip', 'pargzip', 'zstd', 'lz4' value:")
+			require.ErrorContains(t, err, "unsupported engine value for --compression-engine-name. supported values are 'external', 'external-zstd', 'external-pigz', 'pgzip', 'pargzip', 'zstd', 'lz4' value:")
 		})
 	}
 }
@@ -175,7 +302,7 @@ func TestValidateCompressionEngineName(t *testing.T) {
 	}{
 		// we expect ls to be on PATH as it is a basic command part of busybox and most containers
 		{"external", ""},
-		{"foobar", "unsupported engine value for --compression-engine-name. supported values are 'external', 'pgzip', 'pargzip', 'zstd', 'lz4' value: \"foobar\""},
+		{"foobar", "unsupported engine value for --compression-engine-name. supported values are 'external', 'external-zstd', 'external-pigz', 'pgzip', 'pargzip', 'zstd', 'lz4' value: \"foobar\""},
 	}
 
 	for i, tt := range tests {
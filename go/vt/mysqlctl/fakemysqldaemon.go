@@ -177,6 +177,9 @@ type FakeMysqlDaemon struct {
 	SemiSyncPrimaryEnabled bool
 	// SemiSyncReplicaEnabled represents the state of rpl_semi_sync_replica_enabled.
 	SemiSyncReplicaEnabled bool
+	// SemiSyncClientCount represents the number of semi-sync replicas
+	// connected to the primary.
+	SemiSyncClientCount uint32
 
 	// TimeoutHook is a func that can be called at the beginning of
 	// any method to fake a timeout.
@@ -529,8 +532,10 @@ func (fmd *FakeMysqlDaemon) WaitSourcePos(_ context.Context, pos replication.Pos
 }
 
 // CatchupToGTID is part of the MysqlDaemon interface.
-func (fmd *FakeMysqlDaemon) CatchupToGTID(_ context.Context, pos replication.Position) error {
-	return nil
+func (fmd *FakeMysqlDaemon) CatchupToGTID(ctx context.Context, source *mysql.ConnParams, pos replication.Position) error {
+	return fmd.ExecuteSuperQueryList(ctx, []string{
+		fmt.Sprintf("FAKE CATCHUP TO %v FROM %s:%d", pos, source.Host, source.Port),
+	})
 }
 
 // Promote is part of the MysqlDaemon interface.
@@ -708,7 +713,7 @@ func (fmd *FakeMysqlDaemon) SemiSyncStatus(ctx context.Context) (bool, bool) {
 
 // SemiSyncClients is part of the MysqlDaemon interface.
 func (fmd *FakeMysqlDaemon) SemiSyncClients(ctx context.Context) uint32 {
-	return 0
+	return fmd.SemiSyncClientCount
 }
 
 // SemiSyncExtensionLoaded is part of the MysqlDaemon interface.
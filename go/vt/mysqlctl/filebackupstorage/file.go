@@ -205,6 +205,46 @@ func (fbs *FileBackupStorage) RemoveBackup(ctx context.Context, dir, name string
 	return os.RemoveAll(p)
 }
 
+// UpdateFile is part of the BackupFileUpdater interface. The data is written
+// to a temporary file, which is renamed to the file once it is closed, so that
+// the file is never left partially written.
+func (fbs *FileBackupStorage) UpdateFile(ctx context.Context, dir, name, filename string) (io.WriteCloser, error) {
+	p := path.Join(FileBackupStorageRoot, dir, name)
+	if _, err := os.Stat(path.Join(p, filename)); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(p, filename+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	stat := fbs.params.Stats.Scope(stats.Operation("File:Write"))
+	return &updatedFile{
+		WriteCloser: ioutil.NewMeteredWriteCloser(f, stat.TimedIncrementBytes),
+		tmpPath:     f.Name(),
+		path:        path.Join(p, filename),
+	}, nil
+}
+
+// updatedFile is a file written by UpdateFile.
+type updatedFile struct {
+	io.WriteCloser
+	tmpPath string
+	path    string
+}
+
+// Close closes the temporary file, and renames it to the updated file.
+func (uf *updatedFile) Close() error {
+	if err := uf.WriteCloser.Close(); err != nil {
+		os.Remove(uf.tmpPath)
+		return err
+	}
+	if err := os.Rename(uf.tmpPath, uf.path); err != nil {
+		os.Remove(uf.tmpPath)
+		return err
+	}
+	return nil
+}
+
 // Close implements BackupStorage.
 func (fbs *FileBackupStorage) Close() error {
 	return nil
@@ -19,6 +19,8 @@ package filebackupstorage
 import (
 	"context"
 	"io"
+	"os"
+	"path"
 	"testing"
 
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
@@ -192,3 +194,69 @@ func TestFileContents(t *testing.T) {
 		t.Fatalf("rc.Close failed: %v", err)
 	}
 }
+
+func TestUpdateFile(t *testing.T) {
+	fbs := setupFileBackupStorage(t)
+	ctx := context.Background()
+
+	dir := "keyspace/shard"
+	name := "name"
+	filename := "MANIFEST"
+
+	bh, err := fbs.StartBackup(ctx, dir, name)
+	if err != nil {
+		t.Fatalf("fbs.StartBackup failed: %v", err)
+	}
+	wc, err := bh.AddFile(ctx, filename, 0)
+	if err != nil {
+		t.Fatalf("bh.AddFile failed: %v", err)
+	}
+	if _, err := wc.Write([]byte("original contents")); err != nil {
+		t.Fatalf("wc.Write failed: %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatalf("wc.Close failed: %v", err)
+	}
+	if err := bh.EndBackup(ctx); err != nil {
+		t.Fatalf("bh.EndBackup failed: %v", err)
+	}
+
+	updater := fbs.(backupstorage.BackupFileUpdater)
+
+	// only existing files can be updated
+	if _, err := updater.UpdateFile(ctx, dir, name, "unknown"); err == nil {
+		t.Fatalf("was able to UpdateFile a file which does not exist")
+	}
+
+	wc, err = updater.UpdateFile(ctx, dir, name, filename)
+	if err != nil {
+		t.Fatalf("UpdateFile failed: %v", err)
+	}
+	contents := "updated contents"
+	if _, err := wc.Write([]byte(contents)); err != nil {
+		t.Fatalf("wc.Write failed: %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatalf("wc.Close failed: %v", err)
+	}
+
+	bhs, err := fbs.ListBackups(ctx, dir)
+	if err != nil || len(bhs) != 1 {
+		t.Fatalf("ListBackups after update returned wrong return: %v %v", err, bhs)
+	}
+	rc, err := bhs[0].ReadFile(ctx, filename)
+	if err != nil {
+		t.Fatalf("bhs[0].ReadFile failed: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil || string(data) != contents {
+		t.Fatalf("ReadFile after update returned wrong result: %v %q", err, data)
+	}
+
+	// the temporary file was renamed
+	entries, err := os.ReadDir(path.Join(FileBackupStorageRoot, dir, name))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("backup directory has unexpected entries: %v %v", err, entries)
+	}
+}
@@ -0,0 +1,498 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/yaml2"
+)
+
+// innodbBufferPoolChunkSize is the default innodb_buffer_pool_chunk_size.
+// MySQL rounds the buffer pool size up to a multiple of it, so the size
+// rendered from a fraction of the host memory is rounded down to it.
+const innodbBufferPoolChunkSize = 128 * 1024 * 1024
+
+var (
+	mycnfClassesFile    string
+	mycnfClassName      string
+	mycnfTabletType     = os.Getenv("TABLET_TYPE")
+	mycnfHostMemorySize uint64
+
+	// procMeminfoPath and cgroupMemoryLimitPaths are the files the host
+	// memory is read from, the smallest limit applies.
+	procMeminfoPath        = "/proc/meminfo"
+	cgroupMemoryLimitPaths = []string{
+		"/sys/fs/cgroup/memory.max",
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	}
+)
+
+func init() {
+	for _, cmd := range []string{"mysqlctl", "mysqlctld", "vtcombo", "vttablet", "vttestserver"} {
+		servenv.OnParseFor(cmd, registerMycnfClassFlags)
+	}
+}
+
+func registerMycnfClassFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&mycnfClassesFile, "mysqlctl-mycnf-classes", mycnfClassesFile, "YAML file defining the my.cnf classes, whose templated settings are appended to the my.cnf template. Ignored when a make_mycnf hook generates the my.cnf.")
+	fs.StringVar(&mycnfClassName, "mysqlctl-mycnf-class", mycnfClassName, "Name of the class of --mysqlctl-mycnf-classes the my.cnf of this tablet is rendered with.")
+	fs.StringVar(&mycnfTabletType, "mysqlctl-mycnf-tablet-type", mycnfTabletType, "Tablet type the settings of the my.cnf class are rendered for. Defaults to the TABLET_TYPE environment variable, or replica if it is not set.")
+	fs.Uint64Var(&mycnfHostMemorySize, "mysqlctl-mycnf-host-memory", mycnfHostMemorySize, "Memory of the host in bytes the InnoDB buffer pool of the my.cnf class is sized from. Defaults to the memory limit of the cgroup of mysqlctl, or the total memory of the host.")
+}
+
+// MycnfClasses is the content of the --mysqlctl-mycnf-classes file.
+type MycnfClasses struct {
+	Classes []*MycnfClass `json:"classes"`
+}
+
+// MycnfClass is a class of tablets sharing the same hardware shape. Its
+// settings are appended to the my.cnf template, so they override the ones
+// of the template. The values of the settings and the template are
+// themselves templates, rendered with the fields of MycnfClassData.
+type MycnfClass struct {
+	Name string `json:"name"`
+
+	// InnodbBufferPoolFraction sizes innodb_buffer_pool_size as a fraction
+	// of the memory of the host, when it is set.
+	InnodbBufferPoolFraction float64 `json:"innodb_buffer_pool_fraction,omitempty"`
+
+	// Settings are added to the [mysqld] section for all the tablet types.
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// TabletTypes are the settings added for a tablet type, e.g. the
+	// durability settings of the primary. They override the Settings.
+	TabletTypes map[string]map[string]string `json:"tablet_types,omitempty"`
+
+	// Template is appended as is after the settings, e.g. to add other
+	// sections.
+	Template string `json:"template,omitempty"`
+}
+
+// MycnfClassData is the data the templates of a MycnfClass are rendered
+// with. It embeds the Mycnf, as the my.cnf template is rendered with it.
+type MycnfClassData struct {
+	*Mycnf
+
+	Class                string
+	TabletType           string
+	HostMemory           uint64
+	InnodbBufferPoolSize uint64
+}
+
+// LoadMycnfClasses reads and validates a my.cnf classes file.
+func LoadMycnfClasses(path string) (*MycnfClasses, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read my.cnf classes file %v: %v", path, err)
+	}
+	classes := &MycnfClasses{}
+	if err := yaml2.Unmarshal(data, classes); err != nil {
+		return nil, fmt.Errorf("could not parse my.cnf classes file %v: %v", path, err)
+	}
+	if err := classes.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid my.cnf classes file %v: %v", path, err)
+	}
+	return classes, nil
+}
+
+// Validate returns an error if a class is invalid.
+func (classes *MycnfClasses) Validate() error {
+	names := make(map[string]bool, len(classes.Classes))
+	for _, class := range classes.Classes {
+		if class.Name == "" {
+			return fmt.Errorf("class without a name")
+		}
+		if names[class.Name] {
+			return fmt.Errorf("class %v is defined more than once", class.Name)
+		}
+		names[class.Name] = true
+		if err := class.validate(); err != nil {
+			return fmt.Errorf("class %v: %v", class.Name, err)
+		}
+	}
+	return nil
+}
+
+func (class *MycnfClass) validate() error {
+	if class.InnodbBufferPoolFraction < 0 || class.InnodbBufferPoolFraction >= 1 {
+		return fmt.Errorf("innodb_buffer_pool_fraction must be in [0, 1), got %v", class.InnodbBufferPoolFraction)
+	}
+	if err := validateMycnfSettings(class.Settings); err != nil {
+		return err
+	}
+	for tabletType, settings := range class.TabletTypes {
+		if _, err := topoproto.ParseTabletType(tabletType); err != nil {
+			return err
+		}
+		if err := validateMycnfSettings(settings); err != nil {
+			return fmt.Errorf("tablet type %v: %v", tabletType, err)
+		}
+	}
+	if _, err := template.New(class.Name).Parse(class.source()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateMycnfSettings(settings map[string]string) error {
+	for key, value := range settings {
+		if key == "" || strings.ContainsAny(key, "=[]#\n") {
+			return fmt.Errorf("invalid setting name %q", key)
+		}
+		if strings.Contains(value, "\n") {
+			return fmt.Errorf("the value of setting %v must be a single line", key)
+		}
+	}
+	return nil
+}
+
+// Class returns the class of this name.
+func (classes *MycnfClasses) Class(name string) (*MycnfClass, error) {
+	for _, class := range classes.Classes {
+		if class.Name == name {
+			return class, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown my.cnf class %v", name)
+}
+
+// settings returns the settings of the class for the tablet type, sorted by
+// name. A setting of the tablet type overrides the one of the class spelled
+// with hyphens instead of underscores, or the other way around.
+func (class *MycnfClass) settings(tabletType string) [][2]string {
+	merged := make(map[string][2]string, len(class.Settings))
+	for key, value := range class.Settings {
+		merged[normKey([]byte(key))] = [2]string{key, value}
+	}
+	for name, settings := range class.TabletTypes {
+		if tt, _ := topoproto.ParseTabletType(name); topoproto.TabletTypeLString(tt) != tabletType {
+			continue
+		}
+		for key, value := range settings {
+			merged[normKey([]byte(key))] = [2]string{key, value}
+		}
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	settings := make([][2]string, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, merged[key])
+	}
+	return settings
+}
+
+// source returns the template the class appends to the my.cnf one, with
+// the settings of all the tablet types, so that it can be validated.
+func (class *MycnfClass) source() string {
+	var source strings.Builder
+	writeMycnfSettings(&source, class.settings(""))
+	for _, settings := range class.TabletTypes {
+		for key, value := range settings {
+			writeMycnfSettings(&source, [][2]string{{key, value}})
+		}
+	}
+	source.WriteString(class.Template)
+	return source.String()
+}
+
+// writeMycnfSettings writes the settings, the ones without a value, e.g.
+// skip-name-resolve, as their name only.
+func writeMycnfSettings(w *strings.Builder, settings [][2]string) {
+	for _, setting := range settings {
+		if setting[1] == "" {
+			fmt.Fprintf(w, "%s\n", setting[0])
+			continue
+		}
+		fmt.Fprintf(w, "%s = %s\n", setting[0], setting[1])
+	}
+}
+
+// render renders the my.cnf template followed by the settings of the class
+// for the tablet type.
+func (class *MycnfClass) render(cnf *Mycnf, base string, tabletType string, hostMemory uint64) (string, error) {
+	data := &MycnfClassData{
+		Mycnf:      cnf,
+		Class:      class.Name,
+		TabletType: tabletType,
+		HostMemory: hostMemory,
+	}
+
+	var source strings.Builder
+	source.WriteString(base)
+	if !strings.HasSuffix(base, "\n") {
+		source.WriteString("\n")
+	}
+	fmt.Fprintf(&source, "## my.cnf class %s for tablet type %s\n", class.Name, tabletType)
+	if class.InnodbBufferPoolFraction > 0 {
+		size := uint64(float64(hostMemory)*class.InnodbBufferPoolFraction) / innodbBufferPoolChunkSize * innodbBufferPoolChunkSize
+		if size == 0 {
+			return "", fmt.Errorf("my.cnf class %v: %v of the host memory of %v bytes is less than the minimum InnoDB buffer pool size of %v bytes",
+				class.Name, class.InnodbBufferPoolFraction, hostMemory, innodbBufferPoolChunkSize)
+		}
+		data.InnodbBufferPoolSize = size
+		fmt.Fprintf(&source, "innodb_buffer_pool_size = %d\n", size)
+	}
+	writeMycnfSettings(&source, class.settings(tabletType))
+	source.WriteString(class.Template)
+
+	tmpl, err := template.New(class.Name).Option("missingkey=error").Parse(source.String())
+	if err != nil {
+		return "", fmt.Errorf("my.cnf class %v: %v", class.Name, err)
+	}
+	var mycnfData strings.Builder
+	if err := tmpl.Execute(&mycnfData, data); err != nil {
+		return "", fmt.Errorf("my.cnf class %v: %v", class.Name, err)
+	}
+	return mycnfData.String(), nil
+}
+
+// mycnfClass returns the class selected by --mysqlctl-mycnf-class, or nil
+// if my.cnf classes are not used. The classes file is read on each call, so
+// that refreshing the my.cnf applies its changes.
+func mycnfClass() (*MycnfClass, error) {
+	if mycnfClassesFile == "" {
+		if mycnfClassName != "" {
+			return nil, fmt.Errorf("--mysqlctl-mycnf-class requires --mysqlctl-mycnf-classes")
+		}
+		return nil, nil
+	}
+	if mycnfClassName == "" {
+		return nil, fmt.Errorf("--mysqlctl-mycnf-classes requires --mysqlctl-mycnf-class")
+	}
+	classes, err := LoadMycnfClasses(mycnfClassesFile)
+	if err != nil {
+		return nil, err
+	}
+	return classes.Class(mycnfClassName)
+}
+
+// renderMycnf renders the my.cnf from the template files, followed by the
+// settings of the my.cnf class if one is used. The my.cnf rendered with a
+// class is validated.
+func (mysqld *Mysqld) renderMycnf(cnf *Mycnf) (string, error) {
+	class, err := mycnfClass()
+	if err != nil {
+		return "", err
+	}
+	if class == nil {
+		return cnf.makeMycnf(mysqld.getMycnfTemplate())
+	}
+	tabletType, err := mycnfClassTabletType()
+	if err != nil {
+		return "", err
+	}
+	memory, err := hostMemory()
+	if err != nil {
+		return "", err
+	}
+	log.Infof("rendering my.cnf with class %v for tablet type %v and host memory of %v bytes", class.Name, tabletType, memory)
+	configData, err := class.render(cnf, mysqld.getMycnfTemplate(), tabletType, memory)
+	if err != nil {
+		return "", err
+	}
+	if err := validateMycnf(configData); err != nil {
+		return "", fmt.Errorf("invalid my.cnf rendered with class %v: %v", class.Name, err)
+	}
+	return configData, nil
+}
+
+// mycnfClassTabletType returns the tablet type the class is rendered for.
+func mycnfClassTabletType() (string, error) {
+	if mycnfTabletType == "" {
+		return "replica", nil
+	}
+	tabletType, err := topoproto.ParseTabletType(mycnfTabletType)
+	if err != nil {
+		return "", err
+	}
+	return topoproto.TabletTypeLString(tabletType), nil
+}
+
+// hostMemory returns the memory available to mysqld: the smallest of the
+// total memory of the host and the memory limit of the cgroup.
+func hostMemory() (uint64, error) {
+	if mycnfHostMemorySize != 0 {
+		return mycnfHostMemorySize, nil
+	}
+	memory, err := readMemTotal(procMeminfoPath)
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range cgroupMemoryLimitPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// cgroup v2 reports "max", and cgroup v1 a huge number, when there
+		// is no limit.
+		limit, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err == nil && limit < memory {
+			memory = limit
+		}
+	}
+	return memory, nil
+}
+
+func readMemTotal(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not read the host memory, use --mysqlctl-mycnf-host-memory: %v", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemTotal in %v: %v", path, err)
+		}
+		return kb * 1024, nil
+	}
+	return 0, fmt.Errorf("no MemTotal in %v, use --mysqlctl-mycnf-host-memory", path)
+}
+
+// mycnfSetting identifies a setting of a my.cnf by its section and
+// normalized name.
+type mycnfSetting struct {
+	section string
+	name    string
+}
+
+// parseMycnfSettings returns the settings of the sections of a my.cnf. The
+// last value of a setting wins, as it does for mysqld.
+func parseMycnfSettings(data []byte) (map[mycnfSetting]string, error) {
+	settings := make(map[mycnfSetting]string)
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %q", lineNumber, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		case strings.HasPrefix(line, "!"):
+			// !include and !includedir directives.
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("line %d: setting %q outside of a section", lineNumber, line)
+		}
+		key, value, _ := strings.Cut(line, "=")
+		settings[mycnfSetting{section: section, name: normKey([]byte(key))}] = strings.TrimSpace(value)
+	}
+	return settings, scanner.Err()
+}
+
+// validateMycnf returns an error if a rendered my.cnf cannot be parsed, or
+// lacks the settings ReadMycnf needs.
+func validateMycnf(data string) error {
+	settings, err := parseMycnfSettings([]byte(data))
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"server-id", "port", "datadir", "socket"} {
+		if settings[mycnfSetting{section: "mysqld", name: name}] == "" {
+			return fmt.Errorf("%v is not set in the [mysqld] section", name)
+		}
+	}
+	if _, err := strconv.ParseUint(settings[mycnfSetting{section: "mysqld", name: "server-id"}], 10, 32); err != nil {
+		return fmt.Errorf("invalid server-id: %v", err)
+	}
+	if _, err := strconv.Atoi(settings[mycnfSetting{section: "mysqld", name: "port"}]); err != nil {
+		return fmt.Errorf("invalid port: %v", err)
+	}
+	return nil
+}
+
+// MycnfDrift is a setting whose value in the my.cnf differs from the one the
+// my.cnf would be rendered with.
+type MycnfDrift struct {
+	Section  string
+	Name     string
+	Expected string
+	Actual   string
+	// Missing is set when the setting is only in the rendered my.cnf, and
+	// Unexpected when it is only in the existing one.
+	Missing    bool
+	Unexpected bool
+}
+
+// String is part of the fmt.Stringer interface.
+func (drift *MycnfDrift) String() string {
+	switch {
+	case drift.Missing:
+		return fmt.Sprintf("[%s] %s: expected %q, missing", drift.Section, drift.Name, drift.Expected)
+	case drift.Unexpected:
+		return fmt.Sprintf("[%s] %s: unexpected %q", drift.Section, drift.Name, drift.Actual)
+	default:
+		return fmt.Sprintf("[%s] %s: expected %q, got %q", drift.Section, drift.Name, drift.Expected, drift.Actual)
+	}
+}
+
+// diffMycnf returns the drifts of the existing my.cnf from the rendered one,
+// sorted by section and name.
+func diffMycnf(rendered, existing []byte) ([]*MycnfDrift, error) {
+	expected, err := parseMycnfSettings(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rendered my.cnf: %v", err)
+	}
+	actual, err := parseMycnfSettings(existing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid existing my.cnf: %v", err)
+	}
+	var drifts []*MycnfDrift
+	for key, value := range expected {
+		if actualValue, ok := actual[key]; !ok || actualValue != value {
+			drifts = append(drifts, &MycnfDrift{Section: key.section, Name: key.name, Expected: value, Actual: actualValue, Missing: !ok})
+		}
+	}
+	for key, value := range actual {
+		if _, ok := expected[key]; !ok {
+			drifts = append(drifts, &MycnfDrift{Section: key.section, Name: key.name, Actual: value, Unexpected: true})
+		}
+	}
+	slices.SortFunc(drifts, func(a, b *MycnfDrift) int {
+		if c := strings.Compare(a.Section, b.Section); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return drifts, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMycnfClasses = `
+classes:
+- name: large
+  innodb_buffer_pool_fraction: 0.75
+  settings:
+    max_connections: "2000"
+    innodb_log_buffer_size: "{{.InnodbBufferPoolSize}}"
+  tablet_types:
+    primary:
+      sync_binlog: "1"
+      innodb_flush_log_at_trx_commit: "1"
+    replica:
+      sync_binlog: "0"
+      innodb_flush_log_at_trx_commit: "2"
+- name: small
+  settings:
+    performance_schema: "OFF"
+    skip-log-bin: ""
+`
+
+func TestLoadMycnfClasses(t *testing.T) {
+	testcases := []struct {
+		name    string
+		classes string
+		wantErr string
+	}{{
+		name:    "valid",
+		classes: testMycnfClasses,
+	}, {
+		name:    "no name",
+		classes: "classes:\n- settings:\n    a: b\n",
+		wantErr: "class without a name",
+	}, {
+		name:    "duplicate",
+		classes: "classes:\n- name: a\n- name: a\n",
+		wantErr: "class a is defined more than once",
+	}, {
+		name:    "fraction",
+		classes: "classes:\n- name: a\n  innodb_buffer_pool_fraction: 1.5\n",
+		wantErr: "class a: innodb_buffer_pool_fraction must be in [0, 1), got 1.5",
+	}, {
+		name:    "tablet type",
+		classes: "classes:\n- name: a\n  tablet_types:\n    leader:\n      sync_binlog: \"1\"\n",
+		wantErr: "class a: unknown TabletType leader",
+	}, {
+		name:    "setting name",
+		classes: "classes:\n- name: a\n  settings:\n    \"a=b\": c\n",
+		wantErr: `class a: invalid setting name "a=b"`,
+	}, {
+		name:    "template",
+		classes: "classes:\n- name: a\n  template: \"{{.Foo\"\n",
+		wantErr: "class a: template: a:1: unclosed action",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			file := path.Join(t.TempDir(), "classes.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tcase.classes), 0o644))
+			_, err := LoadMycnfClasses(file)
+			if tcase.wantErr != "" {
+				require.ErrorContains(t, err, tcase.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMycnfClassRender(t *testing.T) {
+	file := path.Join(t.TempDir(), "classes.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testMycnfClasses), 0o644))
+	classes, err := LoadMycnfClasses(file)
+	require.NoError(t, err)
+	large, err := classes.Class("large")
+	require.NoError(t, err)
+
+	cnf := NewMycnf(11111, 6802)
+	base := "[mysqld]\nport = {{.MysqlPort}}\nsync_binlog = 0\n"
+	// 75% of 10GiB is 7.5GiB, a multiple of the chunk size.
+	data, err := large.render(cnf, base, "primary", 10*1024*1024*1024)
+	require.NoError(t, err)
+	assert.Equal(t, `[mysqld]
+port = 6802
+sync_binlog = 0
+## my.cnf class large for tablet type primary
+innodb_buffer_pool_size = 8053063680
+innodb_flush_log_at_trx_commit = 1
+innodb_log_buffer_size = 8053063680
+max_connections = 2000
+sync_binlog = 1
+`, data)
+
+	// 75% of 1000MiB is rounded down to 640MiB.
+	data, err = large.render(cnf, base, "replica", 1000*1024*1024)
+	require.NoError(t, err)
+	assert.Contains(t, data, "innodb_buffer_pool_size = 671088640\n")
+	assert.Contains(t, data, "sync_binlog = 0\n## ")
+	assert.Contains(t, data, "innodb_flush_log_at_trx_commit = 2\n")
+
+	// The settings of the other tablet types do not apply.
+	data, err = large.render(cnf, base, "rdonly", 10*1024*1024*1024)
+	require.NoError(t, err)
+	assert.NotContains(t, data, "sync_binlog = 1")
+
+	_, err = large.render(cnf, base, "primary", 100*1024*1024)
+	require.ErrorContains(t, err, "is less than the minimum InnoDB buffer pool size")
+
+	small, err := classes.Class("small")
+	require.NoError(t, err)
+	data, err = small.render(cnf, base, "replica", 0)
+	require.NoError(t, err)
+	assert.Contains(t, data, "performance_schema = OFF\nskip-log-bin\n")
+
+	_, err = classes.Class("medium")
+	require.EqualError(t, err, "unknown my.cnf class medium")
+}
+
+func TestValidateMycnf(t *testing.T) {
+	require.NoError(t, validateMycnf("[mysqld]\nserver-id = 1\nport = 3306\ndatadir = /vt/data\nsocket = /vt/mysql.sock\nskip-name-resolve\n"))
+	require.EqualError(t, validateMycnf("[mysqld]\nserver-id = 1\nport = 3306\ndatadir = /vt/data\n"), "socket is not set in the [mysqld] section")
+	require.EqualError(t, validateMycnf("port = 3306\n"), `line 1: setting "port = 3306" outside of a section`)
+	require.EqualError(t, validateMycnf("[mysqld\n"), `line 1: invalid section "[mysqld"`)
+	require.ErrorContains(t, validateMycnf("[mysqld]\nserver-id = a\nport = 3306\ndatadir = /vt/data\nsocket = /vt/mysql.sock\n"), "invalid server-id")
+}
+
+func TestDiffMycnf(t *testing.T) {
+	rendered := "[mysqld]\nport = 3306\nsync_binlog = 0\nsync_binlog = 1\nmax_connections = 2000\n[client]\nport = 3306\n"
+	existing := "[mysqld]\nport = 3306\nsync-binlog = 0\nslow-query-log\n# comment\n[client]\nport = 3306\n"
+	drifts, err := diffMycnf([]byte(rendered), []byte(existing))
+	require.NoError(t, err)
+	var got []string
+	for _, drift := range drifts {
+		got = append(got, drift.String())
+	}
+	assert.Equal(t, []string{
+		`[mysqld] max-connections: expected "2000", missing`,
+		`[mysqld] slow-query-log: unexpected ""`,
+		`[mysqld] sync-binlog: expected "1", got "0"`,
+	}, got)
+
+	drifts, err = diffMycnf([]byte(rendered), []byte(rendered))
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestReadMemTotal(t *testing.T) {
+	file := path.Join(t.TempDir(), "meminfo")
+	require.NoError(t, os.WriteFile(file, []byte("MemTotal:       16318156 kB\nMemFree:         1052628 kB\n"), 0o644))
+	memory, err := readMemTotal(file)
+	require.NoError(t, err)
+	assert.EqualValues(t, 16318156*1024, memory)
+
+	require.NoError(t, os.WriteFile(file, []byte("MemFree:         1052628 kB\n"), 0o644))
+	_, err = readMemTotal(file)
+	require.ErrorContains(t, err, "no MemTotal")
+}
@@ -93,7 +93,7 @@ type MysqlDaemon interface {
 	WaitForReparentJournal(ctx context.Context, timeCreatedNS int64) error
 
 	WaitSourcePos(context.Context, replication.Position) error
-	CatchupToGTID(context.Context, replication.Position) error
+	CatchupToGTID(ctx context.Context, source *mysql.ConnParams, pos replication.Position) error
 
 	// Promote makes the current server the primary. It will not change
 	// the read_only state of the server.
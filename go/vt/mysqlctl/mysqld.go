@@ -876,6 +876,17 @@ func (mysqld *Mysqld) installDataDir(cnf *Mycnf) error {
 }
 
 func (mysqld *Mysqld) initConfig(cnf *Mycnf, outFile string) error {
+	configData, err := mysqld.makeConfig(cnf)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outFile, []byte(configData), 0o664)
+}
+
+// makeConfig generates the my.cnf with the make_mycnf hook, or renders it
+// from the template files.
+func (mysqld *Mysqld) makeConfig(cnf *Mycnf) (string, error) {
 	var err error
 	var configData string
 
@@ -888,17 +899,13 @@ func (mysqld *Mysqld) initConfig(cnf *Mycnf, outFile string) error {
 	switch hr := hook.NewHookWithEnv("make_mycnf", nil, env).Execute(); hr.ExitStatus {
 	case hook.HOOK_DOES_NOT_EXIST:
 		log.Infof("make_mycnf hook doesn't exist, reading template files")
-		configData, err = cnf.makeMycnf(mysqld.getMycnfTemplate())
+		configData, err = mysqld.renderMycnf(cnf)
 	case hook.HOOK_SUCCESS:
 		configData, err = cnf.fillMycnfTemplate(hr.Stdout)
 	default:
-		return fmt.Errorf("make_mycnf hook failed(%v): %v", hr.ExitStatus, hr.Stderr)
+		return "", fmt.Errorf("make_mycnf hook failed(%v): %v", hr.ExitStatus, hr.Stderr)
 	}
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(outFile, []byte(configData), 0o664)
+	return configData, err
 }
 
 func (mysqld *Mysqld) getMycnfTemplate() string {
@@ -1021,6 +1028,27 @@ func (mysqld *Mysqld) RefreshConfig(ctx context.Context, cnf *Mycnf) error {
 	return nil
 }
 
+// RenderConfig returns the my.cnf as it would be generated by InitConfig,
+// without writing it.
+func (mysqld *Mysqld) RenderConfig(cnf *Mycnf) (string, error) {
+	return mysqld.makeConfig(cnf)
+}
+
+// CheckConfig returns the settings of the existing my.cnf which differ from
+// the ones the my.cnf would be generated with, e.g. after the my.cnf classes
+// or the template files changed. RefreshConfig fixes the drifts.
+func (mysqld *Mysqld) CheckConfig(cnf *Mycnf) ([]*MycnfDrift, error) {
+	rendered, err := mysqld.makeConfig(cnf)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := os.ReadFile(cnf.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read existing file %v: %v", cnf.Path, err)
+	}
+	return diffMycnf([]byte(rendered), existing)
+}
+
 // ReinitConfig updates the config file as if Mysqld is initializing. At the
 // moment it only randomizes ServerID because it's not safe to restore a replica
 // from a backup and then give it the same ServerID as before, MySQL can then
@@ -400,18 +400,15 @@ func (mysqld *Mysqld) WaitSourcePos(ctx context.Context, targetPos replication.P
 	return nil
 }
 
-func (mysqld *Mysqld) CatchupToGTID(ctx context.Context, targetPos replication.Position) error {
-	params, err := mysqld.dbcfgs.ReplConnector().MysqlParams()
-	if err != nil {
-		return err
-	}
+// CatchupToGTID replicates from the given source until the target position.
+func (mysqld *Mysqld) CatchupToGTID(ctx context.Context, source *mysql.ConnParams, targetPos replication.Position) error {
 	conn, err := getPoolReconnect(ctx, mysqld.dbaPool)
 	if err != nil {
 		return err
 	}
 	defer conn.Recycle()
 
-	cmds := conn.Conn.CatchupToGTIDCommands(params, targetPos)
+	cmds := conn.Conn.CatchupToGTIDCommands(source, targetPos)
 	return mysqld.executeSuperQueryListConn(ctx, conn, cmds)
 }
 
@@ -19,6 +19,7 @@ package mysqlctl
 import (
 	"context"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
@@ -156,6 +157,21 @@ func (mysqld *Mysqld) GetSchema(ctx context.Context, dbName string, request *tab
 		})
 	}
 
+	partitionMap := map[string][]*tabletmanagerdatapb.PartitionDefinition{}
+	// Get the partitions concurrently. As above, this only reads `INFORMATION_SCHEMA`.
+	if len(tableNames) > 0 && !request.TableSchemaOnly {
+		eg.Go(func() error {
+			var err error
+			partitionMap, err = mysqld.getPartitions(ctx, dbName, tableNames...)
+			if err != nil {
+				allErrors.RecordError(err)
+				cancel()
+				return err
+			}
+			return nil
+		})
+	}
+
 	eg.Wait()
 	if err := allErrors.AggrError(vterrors.Aggregate); err != nil {
 		return nil, err
@@ -163,6 +179,7 @@ func (mysqld *Mysqld) GetSchema(ctx context.Context, dbName string, request *tab
 
 	for _, td := range tds {
 		td.PrimaryKeyColumns = colMap[td.Name]
+		td.Partitions = partitionMap[td.Name]
 	}
 
 	sd.TableDefinitions = tds
@@ -171,7 +188,7 @@ func (mysqld *Mysqld) GetSchema(ctx context.Context, dbName string, request *tab
 
 func (mysqld *Mysqld) collectBasicTableData(ctx context.Context, dbName string, tables, excludeTables []string, includeViews bool) ([]*tabletmanagerdatapb.TableDefinition, error) {
 	// get the list of tables we're interested in
-	sql := "SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = '" + dbName + "'"
+	sql := "SELECT table_name, table_type, data_length, table_rows, index_length FROM information_schema.tables WHERE table_schema = '" + dbName + "'"
 	if !includeViews {
 		sql += " AND table_type = '" + tmutils.TableBaseTable + "'"
 	}
@@ -216,11 +233,22 @@ func (mysqld *Mysqld) collectBasicTableData(ctx context.Context, dbName string,
 			}
 		}
 
+		// compute indexLength
+		var indexLength uint64
+		if !row[4].IsNull() {
+			// indexLength is NULL for views, then we use 0
+			indexLength, err = row[4].ToCastUint64()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		tds = append(tds, &tabletmanagerdatapb.TableDefinition{
-			Name:       tableName,
-			Type:       tableType,
-			DataLength: dataLength,
-			RowCount:   rowCount,
+			Name:        tableName,
+			Type:        tableType,
+			DataLength:  dataLength,
+			RowCount:    rowCount,
+			IndexLength: indexLength,
 		})
 	}
 
@@ -290,6 +318,13 @@ const (
 		WHERE TABLE_SCHEMA = %s AND TABLE_NAME = %s
 		ORDER BY ORDINAL_POSITION`
 	GetFieldsQuery = "SELECT %s FROM %s WHERE 1 != 1"
+	// GetPartitionsQuery uses column name aliases to guarantee lower case sensitivity.
+	GetPartitionsQuery = `SELECT TABLE_NAME as table_name, PARTITION_NAME as partition_name, SUBPARTITION_NAME as subpartition_name,
+		PARTITION_METHOD as partition_method, PARTITION_DESCRIPTION as partition_description,
+		DATA_LENGTH as data_length, TABLE_ROWS as table_rows, INDEX_LENGTH as index_length
+		FROM INFORMATION_SCHEMA.PARTITIONS
+		WHERE TABLE_SCHEMA = %s AND TABLE_NAME IN %s AND PARTITION_NAME IS NOT NULL
+		ORDER BY table_name, PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION`
 )
 
 // GetColumnsList returns the column names for a given table/view, using a query generating function.
@@ -416,6 +451,42 @@ func (mysqld *Mysqld) getPrimaryKeyColumns(ctx context.Context, dbName string, t
 	return colMap, err
 }
 
+// getPartitions returns the partitions and subpartitions of the partitioned tables among the given tables,
+// along with their sizes.
+func (mysqld *Mysqld) getPartitions(ctx context.Context, dbName string, tables ...string) (map[string][]*tabletmanagerdatapb.PartitionDefinition, error) {
+	conn, err := getPoolReconnect(ctx, mysqld.dbaPool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	tableList, err := tableListSQL(tables)
+	if err != nil {
+		return nil, err
+	}
+	sql := fmt.Sprintf(GetPartitionsQuery, sqltypes.EncodeStringSQL(dbName), tableList)
+	qr, err := conn.Conn.ExecuteFetch(sql, math.MaxInt32, true)
+	if err != nil {
+		return nil, err
+	}
+
+	named := qr.Named()
+	partitionMap := map[string][]*tabletmanagerdatapb.PartitionDefinition{}
+	for _, row := range named.Rows {
+		tableName := row.AsString("table_name", "")
+		partitionMap[tableName] = append(partitionMap[tableName], &tabletmanagerdatapb.PartitionDefinition{
+			Name:             row.AsString("partition_name", ""),
+			SubpartitionName: row.AsString("subpartition_name", ""),
+			Method:           row.AsString("partition_method", ""),
+			Description:      row.AsString("partition_description", ""),
+			DataLength:       row.AsUint64("data_length", 0),
+			RowCount:         row.AsUint64("table_rows", 0),
+			IndexLength:      row.AsUint64("index_length", 0),
+		})
+	}
+	return partitionMap, nil
+}
+
 // PreflightSchemaChange checks the schema changes in "changes" by applying them
 // to an intermediate database that has the same schema as the target database.
 func (mysqld *Mysqld) PreflightSchemaChange(ctx context.Context, dbName string, changes []string) ([]*tabletmanagerdatapb.SchemaChangeResult, error) {
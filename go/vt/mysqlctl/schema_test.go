@@ -141,11 +141,11 @@ func TestGetSchemaAndSchemaChange(t *testing.T) {
 	db.AddQuery("SHOW CREATE DATABASE IF NOT EXISTS `fakesqldb`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("test_field|cmd", "varchar|varchar"), "create_db|create_db_cmd"))
 	db.AddQuery("SHOW CREATE TABLE `fakesqldb`.`test_table`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("test_field|cmd", "varchar|varchar"), "create_table|create_table_cmd"))
 
-	db.AddQuery("SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = 'fakesqldb' AND table_type = 'BASE TABLE'", sqltypes.MakeTestResult(
-		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows", "varchar|varchar|uint64|uint64"), "test_table|test_type|NULL|2"))
+	db.AddQuery("SELECT table_name, table_type, data_length, table_rows, index_length FROM information_schema.tables WHERE table_schema = 'fakesqldb' AND table_type = 'BASE TABLE'", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows|index_length", "varchar|varchar|uint64|uint64|uint64"), "test_table|test_type|NULL|2|NULL"))
 
-	db.AddQuery("SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = 'fakesqldb'", sqltypes.MakeTestResult(
-		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows", "varchar|varchar|uint64|uint64"), "test_table|test_type|NULL|2"))
+	db.AddQuery("SELECT table_name, table_type, data_length, table_rows, index_length FROM information_schema.tables WHERE table_schema = 'fakesqldb'", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows|index_length", "varchar|varchar|uint64|uint64|uint64"), "test_table|test_type|NULL|2|NULL"))
 
 	query := fmt.Sprintf(GetColumnNamesQuery, sqltypes.EncodeStringSQL(db.Name()), sqltypes.EncodeStringSQL("test_table"))
 	db.AddQuery(query, &sqltypes.Result{
@@ -184,10 +184,14 @@ func TestGetSchemaAndSchemaChange(t *testing.T) {
 	query = fmt.Sprintf(query, sqltypes.EncodeStringSQL("fakesqldb"), tableList)
 	db.AddQuery(query, sqltypes.MakeTestResult(sqltypes.MakeTestFields("TABLE_NAME|COLUMN_NAME", "varchar|varchar"), "test_table|col1", "test_table|col2"))
 
+	partitionsFields := sqltypes.MakeTestFields("table_name|partition_name|subpartition_name|partition_method|partition_description|data_length|table_rows|index_length", "varchar|varchar|varchar|varchar|varchar|uint64|uint64|uint64")
+	query = fmt.Sprintf(GetPartitionsQuery, sqltypes.EncodeStringSQL("fakesqldb"), tableList)
+	db.AddQuery(query, sqltypes.MakeTestResult(partitionsFields, "test_table|p0|NULL|RANGE|100|16384|1|0", "test_table|p1|NULL|RANGE|MAXVALUE|16384|1|0"))
+
 	ctx := context.Background()
 	res, err := testMysqld.GetSchema(ctx, db.Name(), &tabletmanagerdata.GetSchemaRequest{})
 	assert.NoError(t, err)
-	assert.Equal(t, res.String(), `database_schema:"create_db_cmd" table_definitions:{name:"test_table" schema:"create_table_cmd" columns:"col1" columns:"col2" type:"test_type" row_count:2 fields:{name:"col1" type:VARCHAR} fields:{name:"col2" type:VARCHAR}}`)
+	assert.Equal(t, res.String(), `database_schema:"create_db_cmd" table_definitions:{name:"test_table" schema:"create_table_cmd" columns:"col1" columns:"col2" type:"test_type" row_count:2 fields:{name:"col1" type:VARCHAR} fields:{name:"col2" type:VARCHAR} partitions:{name:"p0" method:"RANGE" description:"100" data_length:16384 row_count:1} partitions:{name:"p1" method:"RANGE" description:"MAXVALUE" data_length:16384 row_count:1}}`)
 
 	// Test ApplySchemaChange
 	db.AddQuery("\nSET sql_log_bin = 0", &sqltypes.Result{})
@@ -195,7 +199,7 @@ func TestGetSchemaAndSchemaChange(t *testing.T) {
 	r, err := testMysqld.ApplySchemaChange(ctx, db.Name(), &tmutils.SchemaChange{})
 	assert.NoError(t, err)
 	assert.Equal(t, r.BeforeSchema, r.AfterSchema, "BeforeSchema should be equal to AfterSchema as no schema change was passed")
-	assert.Equal(t, `database_schema:"create_db_cmd" table_definitions:{name:"test_table" schema:"create_table_cmd" columns:"col1" columns:"col2" type:"test_type" row_count:2 fields:{name:"col1" type:VARCHAR} fields:{name:"col2" type:VARCHAR}}`, r.BeforeSchema.String())
+	assert.Equal(t, `database_schema:"create_db_cmd" table_definitions:{name:"test_table" schema:"create_table_cmd" columns:"col1" columns:"col2" type:"test_type" row_count:2 fields:{name:"col1" type:VARCHAR} fields:{name:"col2" type:VARCHAR} partitions:{name:"p0" method:"RANGE" description:"100" data_length:16384 row_count:1} partitions:{name:"p1" method:"RANGE" description:"MAXVALUE" data_length:16384 row_count:1}}`, r.BeforeSchema.String())
 
 	r, err = testMysqld.ApplySchemaChange(ctx, db.Name(), &tmutils.SchemaChange{
 		BeforeSchema: &tabletmanagerdata.SchemaDefinition{
@@ -252,10 +256,10 @@ func TestGetSchemaAndSchemaChange(t *testing.T) {
 
 	db.AddQuery("SHOW CREATE DATABASE IF NOT EXISTS `_vt_preflight`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("test_field|cmd", "varchar|varchar"), "create_db|create_db_cmd"))
 
-	db.AddQuery("SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = '_vt_preflight' AND table_type = 'BASE TABLE'", sqltypes.MakeTestResult(
-		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows", "varchar|varchar|uint64|uint64"), "test_table|test_type|NULL|2"))
-	db.AddQuery("SELECT table_name, table_type, data_length, table_rows FROM information_schema.tables WHERE table_schema = '_vt_preflight'", sqltypes.MakeTestResult(
-		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows", "varchar|varchar|uint64|uint64"), "test_table|test_type|NULL|2"))
+	db.AddQuery("SELECT table_name, table_type, data_length, table_rows, index_length FROM information_schema.tables WHERE table_schema = '_vt_preflight' AND table_type = 'BASE TABLE'", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows|index_length", "varchar|varchar|uint64|uint64|uint64"), "test_table|test_type|NULL|2|NULL"))
+	db.AddQuery("SELECT table_name, table_type, data_length, table_rows, index_length FROM information_schema.tables WHERE table_schema = '_vt_preflight'", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_type|data_length|table_rows|index_length", "varchar|varchar|uint64|uint64|uint64"), "test_table|test_type|NULL|2|NULL"))
 	db.AddQuery("SHOW CREATE TABLE `_vt_preflight`.`test_table`", sqltypes.MakeTestResult(sqltypes.MakeTestFields("test_field|cmd", "varchar|varchar"), "create_table|create_table_cmd"))
 
 	query = `
@@ -266,6 +270,9 @@ func TestGetSchemaAndSchemaChange(t *testing.T) {
 	query = fmt.Sprintf(query, sqltypes.EncodeStringSQL("_vt_preflight"), tableList)
 	db.AddQuery(query, sqltypes.MakeTestResult(sqltypes.MakeTestFields("TABLE_NAME|COLUMN_NAME", "varchar|varchar"), "test_table|col1", "test_table|col2"))
 
+	query = fmt.Sprintf(GetPartitionsQuery, sqltypes.EncodeStringSQL("_vt_preflight"), tableList)
+	db.AddQuery(query, &sqltypes.Result{Fields: partitionsFields})
+
 	query = fmt.Sprintf(GetColumnNamesQuery, sqltypes.EncodeStringSQL("_vt_preflight"), sqltypes.EncodeStringSQL("test_table"))
 	db.AddQuery(query, &sqltypes.Result{
 		Fields: []*querypb.Field{{
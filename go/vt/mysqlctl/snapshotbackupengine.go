@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/hook"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// SnapshotBackupEngine takes backups with the filesystem or block device
+// snapshots of an external tool, e.g. EBS or LVM snapshots, called through a
+// hook. It only holds a lock on MySQL while the snapshot is taken, so that the
+// snapshot is consistent with the replication position of the backup, and
+// only stores the MANIFEST in the backup storage, with the ID of the snapshot.
+// Another hook restores the snapshot into the MySQL directories.
+type SnapshotBackupEngine struct {
+}
+
+const (
+	snapshotBackupEngineName = "snapshot"
+
+	// snapshotLockFlushTables takes the snapshot under FLUSH TABLES WITH READ
+	// LOCK, which blocks the writes and the commits.
+	snapshotLockFlushTables = "flush_tables"
+	// snapshotLockBackupLock takes the snapshot under LOCK INSTANCE FOR
+	// BACKUP, which only blocks the DDLs, with the replication SQL thread
+	// stopped so that nothing is committed. It is only possible on replicas.
+	snapshotLockBackupLock = "backup_lock"
+)
+
+var (
+	// snapshotBackupHook is the name of the vthook taking the snapshot.
+	snapshotBackupHook = "snapshot_backup"
+	// snapshotRestoreHook is the name of the vthook restoring the snapshot.
+	snapshotRestoreHook = "snapshot_restore"
+	// snapshotBackupLock is the lock held while the snapshot is taken.
+	snapshotBackupLock = snapshotLockFlushTables
+	// snapshotBackupTimeout bounds the time the lock is held while the
+	// snapshot backup hook runs.
+	snapshotBackupTimeout = 5 * time.Minute
+)
+
+// snapshotBackupManifest represents a snapshot backup.
+type snapshotBackupManifest struct {
+	// BackupManifest is an anonymous embedding of the base manifest struct.
+	BackupManifest
+	// SnapshotID identifies the snapshot, as printed by the snapshot backup
+	// hook, and is given to the snapshot restore hook.
+	SnapshotID string
+	// Lock is the lock held while the snapshot was taken.
+	Lock string
+}
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vttablet", "vtbackup"} {
+		servenv.OnParseFor(cmd, registerSnapshotBackupEngineFlags)
+	}
+}
+
+func registerSnapshotBackupEngineFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&snapshotBackupHook, "snapshot-backup-hook", snapshotBackupHook, "Name of the vthook taking the snapshots of the snapshot backup engine. It must print the ID of the snapshot on its last line of output.")
+	fs.StringVar(&snapshotRestoreHook, "snapshot-restore-hook", snapshotRestoreHook, "Name of the vthook restoring the snapshots of the snapshot backup engine into the MySQL directories.")
+	fs.StringVar(&snapshotBackupLock, "snapshot-backup-lock", snapshotBackupLock, "Lock held by the snapshot backup engine while the snapshot is taken: flush_tables, for FLUSH TABLES WITH READ LOCK, or backup_lock, for LOCK INSTANCE FOR BACKUP with the replication SQL thread stopped, only on replicas.")
+	fs.DurationVar(&snapshotBackupTimeout, "snapshot-backup-timeout", snapshotBackupTimeout, "Timeout of the snapshot backup hook, which bounds the time the lock is held.")
+}
+
+// ExecuteBackup takes a snapshot backup. Incremental backups are not
+// supported.
+func (be *SnapshotBackupEngine) ExecuteBackup(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle) (BackupResult, error) {
+	params.Logger.Infof("Executing snapshot backup at %v for keyspace/shard %v/%v on tablet %v, lock: %v",
+		params.BackupTime, params.Keyspace, params.Shard, params.TabletAlias, snapshotBackupLock)
+
+	if isIncrementalBackup(params) {
+		return BackupUnusable, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "incremental backups not supported in snapshot engine.")
+	}
+	switch snapshotBackupLock {
+	case snapshotLockFlushTables, snapshotLockBackupLock:
+	default:
+		return BackupUnusable, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown snapshot backup lock %q, must be %s or %s", snapshotBackupLock, snapshotLockFlushTables, snapshotLockBackupLock)
+	}
+
+	serverUUID, err := params.Mysqld.GetServerUUID(ctx)
+	if err != nil {
+		return BackupUnusable, vterrors.Wrap(err, "can't get server uuid")
+	}
+	mysqlVersion, err := params.Mysqld.GetVersionString(ctx)
+	if err != nil {
+		return BackupUnusable, vterrors.Wrap(err, "can't get MySQL version")
+	}
+
+	snapshotID, position, gtidPurged, err := be.takeSnapshot(ctx, params, bh)
+	if err != nil {
+		return BackupUnusable, err
+	}
+
+	bm := &snapshotBackupManifest{
+		BackupManifest: BackupManifest{
+			BackupName:     bh.Name(),
+			BackupMethod:   snapshotBackupEngineName,
+			Position:       position,
+			PurgedPosition: gtidPurged,
+			ServerUUID:     serverUUID,
+			TabletAlias:    params.TabletAlias,
+			Keyspace:       params.Keyspace,
+			Shard:          params.Shard,
+			BackupTime:     FormatRFC3339(params.BackupTime.UTC()),
+			FinishedTime:   FormatRFC3339(time.Now().UTC()),
+			MySQLVersion:   mysqlVersion,
+			// The snapshot is taken while MySQL runs, it is recovered like
+			// after a crash, which is not safe to do with another version.
+			UpgradeSafe: false,
+			Checksums:   backupChecksums(bh),
+		},
+		SnapshotID: snapshotID,
+		Lock:       snapshotBackupLock,
+	}
+	if err := be.writeManifest(ctx, params, bh, bm); err != nil {
+		return BackupUnusable, err
+	}
+
+	params.Logger.Infof("Snapshot backup %v completed with snapshot %v at position %v", bh.Name(), snapshotID, position)
+	return BackupUsable, nil
+}
+
+// takeSnapshot locks MySQL, reads its replication position and runs the
+// snapshot backup hook, which returns the ID of the snapshot.
+func (be *SnapshotBackupEngine) takeSnapshot(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle) (snapshotID string, position, gtidPurged replication.Position, finalErr error) {
+	conn, err := params.Mysqld.GetDbaConnection(ctx)
+	if err != nil {
+		return "", position, gtidPurged, vterrors.Wrap(err, "unable to obtain a connection to the database")
+	}
+	defer conn.Close()
+
+	lock, unlock := "FLUSH TABLES WITH READ LOCK", "UNLOCK TABLES"
+	if snapshotBackupLock == snapshotLockBackupLock {
+		// LOCK INSTANCE FOR BACKUP does not block the commits, they must
+		// come from the replication SQL thread only, which is stopped.
+		status, err := params.Mysqld.ReplicationStatus(ctx)
+		if err != nil {
+			return "", position, gtidPurged, vterrors.Wrapf(err, "the %s snapshot backup lock is only possible on replicas", snapshotLockBackupLock)
+		}
+		if status.SQLHealthy() {
+			params.Logger.Infof("Stopping the replication SQL thread for the snapshot")
+			if _, err := conn.ExecuteFetch(conn.StopSQLThreadCommand(), 0, false); err != nil {
+				return "", position, gtidPurged, vterrors.Wrap(err, "can't stop the replication SQL thread")
+			}
+			defer func() {
+				params.Logger.Infof("Starting the replication SQL thread after the snapshot")
+				if _, err := conn.ExecuteFetch(conn.StartSQLThreadCommand(), 0, false); err != nil && finalErr == nil {
+					finalErr = vterrors.Wrap(err, "can't start the replication SQL thread")
+				}
+			}()
+		}
+		lock, unlock = "LOCK INSTANCE FOR BACKUP", "UNLOCK INSTANCE"
+	}
+
+	params.Logger.Infof("Locking MySQL with %s", lock)
+	lockStart := time.Now()
+	if _, err := conn.ExecuteFetch(lock, 0, false); err != nil {
+		return "", position, gtidPurged, vterrors.Wrapf(err, "can't lock MySQL with %s", lock)
+	}
+	defer func() {
+		if _, err := conn.ExecuteFetch(unlock, 0, false); err != nil && finalErr == nil {
+			finalErr = vterrors.Wrapf(err, "can't unlock MySQL with %s", unlock)
+		}
+		params.Logger.Infof("Unlocked MySQL with %s after %v", unlock, time.Since(lockStart))
+	}()
+
+	// Nothing is committed while MySQL is locked, the position is the one of
+	// the snapshot.
+	position, err = params.Mysqld.PrimaryPosition(ctx)
+	if err != nil {
+		return "", position, gtidPurged, vterrors.Wrap(err, "can't get position")
+	}
+	gtidPurged, err = params.Mysqld.GetGTIDPurged(ctx)
+	if err != nil {
+		return "", position, gtidPurged, vterrors.Wrap(err, "can't get gtid_purged")
+	}
+
+	env := snapshotHookEnv(params.Cnf, params.HookExtraEnv)
+	env["TABLET_ALIAS"] = params.TabletAlias
+	env["KEYSPACE"] = params.Keyspace
+	env["SHARD"] = params.Shard
+	env["BACKUP_NAME"] = bh.Name()
+	env["BACKUP_DIR"] = bh.Directory()
+	env["POSITION"] = replication.EncodePosition(position)
+
+	params.Logger.Infof("Running the %v snapshot backup hook", snapshotBackupHook)
+	hookCtx, cancel := context.WithTimeout(ctx, snapshotBackupTimeout)
+	defer cancel()
+	hr := hook.NewHookWithEnv(snapshotBackupHook, nil, env).ExecuteContext(hookCtx)
+	if hr.ExitStatus != hook.HOOK_SUCCESS {
+		return "", position, gtidPurged, vterrors.Errorf(vtrpc.Code_UNKNOWN, "snapshot backup hook %v failed: %v", snapshotBackupHook, hr.String())
+	}
+	snapshotID = lastLine(hr.Stdout)
+	if snapshotID == "" {
+		return "", position, gtidPurged, vterrors.Errorf(vtrpc.Code_UNKNOWN, "snapshot backup hook %v did not print the ID of the snapshot", snapshotBackupHook)
+	}
+	return snapshotID, position, gtidPurged, nil
+}
+
+func (be *SnapshotBackupEngine) writeManifest(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, bm *snapshotBackupManifest) (finalErr error) {
+	params.Logger.Infof("Writing backup MANIFEST")
+	mwc, err := bh.AddFile(ctx, backupManifestFileName, backupstorage.FileSizeUnknown)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add %v to backup", backupManifestFileName)
+	}
+	defer closeFile(mwc, backupManifestFileName, params.Logger, &finalErr)
+
+	data, err := json.MarshalIndent(bm, "", "  ")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
+	}
+	if _, err := mwc.Write(data); err != nil {
+		return vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+	return nil
+}
+
+// ExecuteRestore restores a snapshot backup with the snapshot restore hook.
+func (be *SnapshotBackupEngine) ExecuteRestore(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle) (*BackupManifest, error) {
+	var bm snapshotBackupManifest
+	if err := getBackupManifestInto(ctx, bh, &bm); err != nil {
+		return nil, err
+	}
+	if bm.SnapshotID == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup %v has no snapshot ID", bh.Name())
+	}
+
+	// mark restore as in progress
+	if err := createStateFile(params.Cnf); err != nil {
+		return nil, err
+	}
+
+	if err := prepareToRestore(ctx, params.Cnf, params.Mysqld, params.Logger, params.MysqlShutdownTimeout); err != nil {
+		return nil, err
+	}
+
+	env := snapshotHookEnv(params.Cnf, nil)
+	env["KEYSPACE"] = params.Keyspace
+	env["SHARD"] = params.Shard
+	env["BACKUP_NAME"] = bh.Name()
+	env["BACKUP_DIR"] = bh.Directory()
+	env["SNAPSHOT_ID"] = bm.SnapshotID
+
+	params.Logger.Infof("Restore: running the %v snapshot restore hook for snapshot %v", snapshotRestoreHook, bm.SnapshotID)
+	hr := hook.NewHookWithEnv(snapshotRestoreHook, nil, env).ExecuteContext(ctx)
+	if hr.ExitStatus != hook.HOOK_SUCCESS {
+		// don't delete the state file here because that is how we detect an interrupted restore
+		return nil, vterrors.Errorf(vtrpc.Code_UNKNOWN, "snapshot restore hook %v failed: %v", snapshotRestoreHook, hr.String())
+	}
+
+	// The snapshot has the server UUID of the backed up tablet, MySQL
+	// generates a new one without it.
+	if err := os.Remove(path.Join(params.Cnf.DataDir, "auto.cnf")); err != nil && !os.IsNotExist(err) {
+		return nil, vterrors.Wrap(err, "can't remove the auto.cnf of the snapshot")
+	}
+
+	params.Logger.Infof("Restore: returning replication position %v", bm.Position)
+	return &bm.BackupManifest, nil
+}
+
+// ShouldDrainForBackup returns true, the writes, or the replication, of the
+// tablet are stopped while the snapshot is taken.
+func (be *SnapshotBackupEngine) ShouldDrainForBackup(req *tabletmanagerdatapb.BackupRequest) bool {
+	return true
+}
+
+// snapshotHookEnv returns the environment of the snapshot hooks, with the
+// MySQL directories to take the snapshot of, or to restore it into.
+func snapshotHookEnv(cnf *Mycnf, extraEnv map[string]string) map[string]string {
+	env := make(map[string]string, len(extraEnv)+10)
+	for k, v := range extraEnv {
+		env[k] = v
+	}
+	env["DATA_DIR"] = cnf.DataDir
+	env["INNODB_DATA_HOME_DIR"] = cnf.InnodbDataHomeDir
+	env["INNODB_LOG_GROUP_HOME_DIR"] = cnf.InnodbLogGroupHomeDir
+	env["BINLOG_PATH"] = cnf.BinLogPath
+	return env
+}
+
+// lastLine returns the last non-empty line of output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func init() {
+	BackupRestoreEngineMap[snapshotBackupEngineName] = &SnapshotBackupEngine{}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+type manifestBuffer struct {
+	bytes.Buffer
+}
+
+func (mb *manifestBuffer) Close() error {
+	return nil
+}
+
+// setupSnapshotHooks installs the snapshot hooks in a new VTROOT. The backup
+// hook prints a line and the snapshot ID, the restore hook writes
+// the snapshot ID in the data directory.
+func setupSnapshotHooks(t *testing.T) {
+	vtroot := t.TempDir()
+	require.NoError(t, os.Mkdir(path.Join(vtroot, "vthook"), 0o755))
+	require.NoError(t, os.WriteFile(path.Join(vtroot, "vthook", "snapshot_backup"), []byte("#!/bin/sh\necho \"taking a snapshot of $DATA_DIR at $POSITION\"\necho snap-$BACKUP_NAME\n"), 0o755))
+	require.NoError(t, os.WriteFile(path.Join(vtroot, "vthook", "snapshot_restore"), []byte("#!/bin/sh\nmkdir -p $DATA_DIR && echo $SNAPSHOT_ID > $DATA_DIR/snapshot && echo uuid > $DATA_DIR/auto.cnf\n"), 0o755))
+	t.Setenv("VTROOT", vtroot)
+}
+
+func TestSnapshotBackupEngine(t *testing.T) {
+	ctx := context.Background()
+	setupSnapshotHooks(t)
+
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery("FLUSH TABLES WITH READ LOCK", &sqltypes.Result{})
+	db.AddQuery("UNLOCK TABLES", &sqltypes.Result{})
+	mysqld := NewFakeMysqlDaemon(db)
+	defer mysqld.Close()
+	pos, err := replication.DecodePosition("MySQL56/00000000-0000-0000-0000-000000000001:1-10")
+	require.NoError(t, err)
+	mysqld.CurrentPrimaryPosition = pos
+
+	dir := t.TempDir()
+	cnf := &Mycnf{
+		DataDir:               path.Join(dir, "data"),
+		InnodbDataHomeDir:     path.Join(dir, "innodb"),
+		InnodbLogGroupHomeDir: path.Join(dir, "log"),
+		BinLogPath:            path.Join(dir, "binlog"),
+		RelayLogPath:          path.Join(dir, "relay"),
+		RelayLogIndexPath:     path.Join(dir, "relay.index"),
+		RelayLogInfoPath:      path.Join(dir, "relay.info"),
+		Path:                  path.Join(dir, "my.cnf"),
+	}
+	require.NoError(t, os.MkdirAll(cnf.TabletDir(), 0o755))
+
+	be := &SnapshotBackupEngine{}
+	manifest := &manifestBuffer{}
+	bh := &FakeBackupHandle{NameV: "backup1", AddFileReturn: FakeBackupHandleAddFileReturn{WriteCloser: manifest}}
+	result, err := be.ExecuteBackup(ctx, BackupParams{
+		Cnf:         cnf,
+		Mysqld:      mysqld,
+		Logger:      logutil.NewMemoryLogger(),
+		Keyspace:    "ks",
+		Shard:       "0",
+		TabletAlias: "zone1-0000000100",
+		BackupTime:  time.Now(),
+	}, bh)
+	require.NoError(t, err)
+	assert.Equal(t, BackupUsable, result)
+
+	var bm snapshotBackupManifest
+	require.NoError(t, json.Unmarshal(manifest.Bytes(), &bm))
+	assert.Equal(t, "snap-backup1", bm.SnapshotID)
+	assert.Equal(t, snapshotLockFlushTables, bm.Lock)
+	assert.Equal(t, snapshotBackupEngineName, bm.BackupMethod)
+	assert.True(t, pos.Equal(bm.Position))
+	assert.False(t, bm.UpgradeSafe)
+
+	// The snapshot is restored by the restore hook, with a new server UUID.
+	manifestBytes := manifest.Bytes()
+	bh = &FakeBackupHandle{NameV: "backup1", ReadFileReturnF: func(context.Context, string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(manifestBytes)), nil
+	}}
+	restored, err := be.ExecuteRestore(ctx, RestoreParams{
+		Cnf:      cnf,
+		Mysqld:   mysqld,
+		Logger:   logutil.NewMemoryLogger(),
+		Keyspace: "ks",
+		Shard:    "0",
+	}, bh)
+	require.NoError(t, err)
+	assert.True(t, pos.Equal(restored.Position))
+	snapshot, err := os.ReadFile(path.Join(cnf.DataDir, "snapshot"))
+	require.NoError(t, err)
+	assert.Equal(t, "snap-backup1\n", string(snapshot))
+	assert.NoFileExists(t, path.Join(cnf.DataDir, "auto.cnf"))
+}
+
+func TestSnapshotBackupEngineErrors(t *testing.T) {
+	ctx := context.Background()
+	setupSnapshotHooks(t)
+
+	db := fakesqldb.New(t)
+	defer db.Close()
+	mysqld := NewFakeMysqlDaemon(db)
+	defer mysqld.Close()
+	params := BackupParams{
+		Cnf:    &Mycnf{},
+		Mysqld: mysqld,
+		Logger: logutil.NewMemoryLogger(),
+	}
+	be := &SnapshotBackupEngine{}
+
+	params.IncrementalFromPos = "auto"
+	_, err := be.ExecuteBackup(ctx, params, &FakeBackupHandle{})
+	assert.ErrorContains(t, err, "incremental backups not supported")
+	params.IncrementalFromPos = ""
+
+	// The backup lock is only possible on replicas.
+	defer func(lock string) { snapshotBackupLock = lock }(snapshotBackupLock)
+	snapshotBackupLock = snapshotLockBackupLock
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+	_, err = be.ExecuteBackup(ctx, params, &FakeBackupHandle{})
+	assert.ErrorContains(t, err, "only possible on replicas")
+
+	// The backup fails if MySQL cannot be locked.
+	snapshotBackupLock = snapshotLockFlushTables
+	_, err = be.ExecuteBackup(ctx, params, &FakeBackupHandle{})
+	assert.ErrorContains(t, err, "can't lock MySQL with FLUSH TABLES WITH READ LOCK")
+
+	snapshotBackupLock = "lvm"
+	_, err = be.ExecuteBackup(ctx, params, &FakeBackupHandle{})
+	assert.ErrorContains(t, err, `unknown snapshot backup lock "lvm"`)
+}
+
+func TestLastLine(t *testing.T) {
+	assert.Equal(t, "snap-1", lastLine("creating snapshot\nsnap-1\n\n"))
+	assert.Equal(t, "snap-1", lastLine("snap-1"))
+	assert.Empty(t, lastLine(""))
+}
@@ -258,6 +258,7 @@ func (be *XtrabackupEngine) executeFullBackup(ctx context.Context, params Backup
 			// xtrabackup backups are always created such that they
 			// are safe to use for upgrades later on.
 			UpgradeSafe: true,
+			Checksums:   backupChecksums(bh),
 		},
 
 		// XtraBackup-specific fields
@@ -371,13 +372,7 @@ func (be *XtrabackupEngine) backupFiles(
 
 		// Create the gzip compression pipe, if necessary.
 		if backupStorageCompress {
-			var compressor io.WriteCloser
-
-			if ExternalCompressorCmd != "" {
-				compressor, err = newExternalCompressor(ctx, ExternalCompressorCmd, writer, params.Logger)
-			} else {
-				compressor, err = newBuiltinCompressor(CompressionEngineName, writer, params.Logger)
-			}
+			compressor, err := newCompressor(ctx, CompressionEngineName, writer, params.Logger)
 			if err != nil {
 				return replicationPosition, vterrors.Wrap(err, "can't create compressor")
 			}
@@ -637,30 +632,7 @@ func (be *XtrabackupEngine) extractFiles(ctx context.Context, logger logutil.Log
 
 		// Create the decompressor if needed.
 		if compressed {
-			var decompressor io.ReadCloser
-			var deCompressionEngine = bm.CompressionEngine
-			if deCompressionEngine == "" {
-				// For backward compatibility. Incase if Manifest is from N-1 binary
-				// then we assign the default value of compressionEngine.
-				deCompressionEngine = PgzipCompressor
-			}
-			externalDecompressorCmd := ExternalDecompressorCmd
-			if externalDecompressorCmd == "" && bm.ExternalDecompressor != "" {
-				externalDecompressorCmd = bm.ExternalDecompressor
-			}
-			if externalDecompressorCmd != "" {
-				if deCompressionEngine == ExternalCompressor {
-					deCompressionEngine = externalDecompressorCmd
-					decompressor, err = newExternalDecompressor(ctx, deCompressionEngine, reader, logger)
-				} else {
-					decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, logger)
-				}
-			} else {
-				if deCompressionEngine == ExternalCompressor {
-					return fmt.Errorf("%w %q", errUnsupportedCompressionEngine, ExternalCompressor)
-				}
-				decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, logger)
-			}
+			decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, logger)
 			if err != nil {
 				return vterrors.Wrap(err, "can't create decompressor")
 			}
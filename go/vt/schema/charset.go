@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// CharsetDeviation is a character set or a collation set by a table, or by one
+// of its columns, which differs from the default ones of its keyspace.
+type CharsetDeviation struct {
+	Table string
+	// Column is empty when the deviation is the default character set or
+	// collation of the table.
+	Column    string
+	Charset   string
+	Collation string
+}
+
+func (d *CharsetDeviation) String() string {
+	var set []string
+	if d.Charset != "" {
+		set = append(set, "character set "+d.Charset)
+	}
+	if d.Collation != "" {
+		set = append(set, "collation "+d.Collation)
+	}
+	if d.Column != "" {
+		return fmt.Sprintf("column %s.%s has %s", d.Table, d.Column, strings.Join(set, " and "))
+	}
+	return fmt.Sprintf("table %s has %s", d.Table, strings.Join(set, " and "))
+}
+
+// ValidateKeyspaceCharset validates the default character set and collation
+// of a keyspace, and returns them normalized, e.g. utf8 is returned as
+// utf8mb3. Either of them can be empty.
+func ValidateKeyspaceCharset(env *collations.Environment, charset, collation string) (string, string, error) {
+	charset = normalizeCharset(env, charset)
+	collation = normalizeCollation(env, collation)
+	if charset != "" && env.LookupByCharset(charset) == nil {
+		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown character set: %s", charset)
+	}
+	if collation != "" {
+		id := env.LookupByName(collation)
+		if id == collations.Unknown {
+			return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown collation: %s", collation)
+		}
+		if charset != "" && env.LookupCharsetName(id) != charset {
+			return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "collation %s is not a collation of character set %s", collation, charset)
+		}
+	}
+	return charset, collation, nil
+}
+
+// CharsetDeviations returns the character sets and collations which the
+// CREATE TABLE or ALTER TABLE statement sets, and which differ from the
+// default character set and collation of the keyspace. The tables and columns
+// which don't set any inherit them from the database or the table, and don't
+// deviate.
+func CharsetDeviations(env *collations.Environment, stmt sqlparser.Statement, charset, collation string) []*CharsetDeviation {
+	if charset == "" && collation == "" {
+		return nil
+	}
+	var deviations []*CharsetDeviation
+	var table string
+	check := func(column, setCharset, setCollation string) {
+		setCharset = normalizeCharset(env, setCharset)
+		setCollation = normalizeCollation(env, setCollation)
+		if charsetMatches(env, setCharset, charset, collation) && collationMatches(env, setCollation, charset, collation) {
+			return
+		}
+		deviations = append(deviations, &CharsetDeviation{Table: table, Column: column, Charset: setCharset, Collation: setCollation})
+	}
+	checkTableOptions := func(options sqlparser.TableOptions) {
+		var setCharset, setCollation string
+		for _, option := range options {
+			switch strings.ToUpper(option.Name) {
+			case "CHARSET":
+				setCharset = option.String
+			case "COLLATE":
+				setCollation = option.String
+			}
+		}
+		check("", setCharset, setCollation)
+	}
+	checkColumn := func(column *sqlparser.ColumnDefinition) {
+		var setCollation string
+		if column.Type.Options != nil {
+			setCollation = column.Type.Options.Collate
+		}
+		check(column.Name.String(), column.Type.Charset.Name, setCollation)
+	}
+
+	switch stmt := stmt.(type) {
+	case *sqlparser.CreateTable:
+		if stmt.TableSpec == nil {
+			return nil
+		}
+		table = stmt.Table.Name.String()
+		checkTableOptions(stmt.TableSpec.Options)
+		for _, column := range stmt.TableSpec.Columns {
+			checkColumn(column)
+		}
+	case *sqlparser.AlterTable:
+		table = stmt.Table.Name.String()
+		for _, option := range stmt.AlterOptions {
+			switch option := option.(type) {
+			case sqlparser.TableOptions:
+				checkTableOptions(option)
+			case *sqlparser.AlterCharset:
+				check("", option.CharacterSet, option.Collate)
+			case *sqlparser.AddColumns:
+				for _, column := range option.Columns {
+					checkColumn(column)
+				}
+			case *sqlparser.ModifyColumn:
+				checkColumn(option.NewColDefinition)
+			case *sqlparser.ChangeColumn:
+				checkColumn(option.NewColDefinition)
+			}
+		}
+	}
+	return deviations
+}
+
+// ValidateCharset returns an error if the CREATE TABLE or ALTER TABLE
+// statement sets a character set or a collation which differs from the
+// default ones of the keyspace, unless the DDL strategy allows it with
+// --allow-nondefault-charset.
+func ValidateCharset(env *collations.Environment, stmt sqlparser.Statement, setting *DDLStrategySetting, charset, collation string) error {
+	if setting != nil && setting.IsAllowNonDefaultCharsetFlag() {
+		return nil
+	}
+	deviations := CharsetDeviations(env, stmt, charset, collation)
+	if len(deviations) == 0 {
+		return nil
+	}
+	reasons := make([]string, 0, len(deviations))
+	for _, deviation := range deviations {
+		reasons = append(reasons, deviation.String())
+	}
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s, but the keyspace defaults to %s; use --%s in the ddl strategy to override",
+		strings.Join(reasons, ", "), keyspaceCharsetString(charset, collation), allowNonDefaultCharsetFlag)
+}
+
+// ConvertCharsetStatement returns the ALTER TABLE statement converting the
+// table and its columns to the default character set and collation of the
+// keyspace.
+func ConvertCharsetStatement(env *collations.Environment, table string, charset, collation string) string {
+	if charset == "" {
+		charset = env.LookupCharsetName(env.LookupByName(collation))
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s CONVERT TO CHARACTER SET %s", sqlparser.String(sqlparser.NewIdentifierCS(table)), charset)
+	if collation != "" {
+		stmt += " COLLATE " + collation
+	}
+	return stmt
+}
+
+func keyspaceCharsetString(charset, collation string) string {
+	switch {
+	case charset == "":
+		return "collation " + collation
+	case collation == "":
+		return "character set " + charset
+	default:
+		return fmt.Sprintf("character set %s and collation %s", charset, collation)
+	}
+}
+
+func normalizeCharset(env *collations.Environment, charset string) string {
+	charset = strings.ToLower(charset)
+	if alias, ok := env.CharsetAlias(charset); ok {
+		return alias
+	}
+	return charset
+}
+
+func normalizeCollation(env *collations.Environment, collation string) string {
+	collation = strings.ToLower(collation)
+	if alias, ok := env.CollationAlias(collation); ok {
+		return alias
+	}
+	return collation
+}
+
+// charsetMatches returns true if the character set set by a statement, if
+// any, is the default character set of the keyspace, or the one of its
+// default collation.
+func charsetMatches(env *collations.Environment, setCharset, charset, collation string) bool {
+	if setCharset == "" {
+		return true
+	}
+	if charset != "" {
+		return setCharset == charset
+	}
+	return setCharset == env.LookupCharsetName(env.LookupByName(collation))
+}
+
+// collationMatches returns true if the collation set by a statement, if any,
+// is the default collation of the keyspace, or a collation of its default
+// character set when it has no default collation.
+func collationMatches(env *collations.Environment, setCollation, charset, collation string) bool {
+	if setCollation == "" {
+		return true
+	}
+	if collation != "" {
+		return setCollation == collation
+	}
+	return env.LookupCharsetName(env.LookupByName(setCollation)) == charset
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestValidateKeyspaceCharset(t *testing.T) {
+	env := collations.MySQL8()
+	tcases := []struct {
+		charset, collation         string
+		wantCharset, wantCollation string
+		wantErr                    string
+	}{
+		{charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", wantCharset: "utf8mb4", wantCollation: "utf8mb4_0900_ai_ci"},
+		{charset: "UTF8", wantCharset: "utf8mb3"},
+		{collation: "utf8_general_ci", wantCollation: "utf8mb3_general_ci"},
+		{},
+		{charset: "utf9", wantErr: "unknown character set: utf9"},
+		{collation: "utf8mb4_klingon_ci", wantErr: "unknown collation: utf8mb4_klingon_ci"},
+		{charset: "latin1", collation: "utf8mb4_bin", wantErr: "collation utf8mb4_bin is not a collation of character set latin1"},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.charset+"/"+tcase.collation, func(t *testing.T) {
+			charset, collation, err := ValidateKeyspaceCharset(env, tcase.charset, tcase.collation)
+			if tcase.wantErr != "" {
+				assert.EqualError(t, err, tcase.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.wantCharset, charset)
+			assert.Equal(t, tcase.wantCollation, collation)
+		})
+	}
+}
+
+func TestCharsetDeviations(t *testing.T) {
+	env := collations.MySQL8()
+	parser := sqlparser.NewTestParser()
+	tcases := []struct {
+		charset, collation string
+		sql                string
+		want               []*CharsetDeviation
+	}{
+		{
+			charset:   "utf8mb4",
+			collation: "utf8mb4_0900_ai_ci",
+			sql:       "create table t (id int primary key, name varchar(64)) default charset=utf8mb4 collate=utf8mb4_0900_ai_ci",
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "create table t (id int primary key, name varchar(64))",
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "create table t (id int primary key, name varchar(64) collate utf8mb4_bin) charset utf8mb4",
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "create table t (id int primary key, name varchar(64)) charset utf8",
+			want:    []*CharsetDeviation{{Table: "t", Charset: "utf8mb3"}},
+		},
+		{
+			charset:   "utf8mb4",
+			collation: "utf8mb4_0900_ai_ci",
+			sql:       "create table t (id int primary key, name varchar(64) character set latin1, code varchar(8) collate utf8mb4_bin)",
+			want: []*CharsetDeviation{
+				{Table: "t", Column: "name", Charset: "latin1"},
+				{Table: "t", Column: "code", Collation: "utf8mb4_bin"},
+			},
+		},
+		{
+			collation: "utf8mb4_0900_ai_ci",
+			sql:       "create table t (id int primary key) default character set utf8mb3",
+			want:      []*CharsetDeviation{{Table: "t", Charset: "utf8mb3"}},
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "alter table t convert to character set utf8mb3 collate utf8mb3_general_ci",
+			want:    []*CharsetDeviation{{Table: "t", Charset: "utf8mb3", Collation: "utf8mb3_general_ci"}},
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "alter table t add column c varchar(8) charset latin1, modify column d text charset utf8mb4, change column e f varchar(8) collate latin1_bin",
+			want: []*CharsetDeviation{
+				{Table: "t", Column: "c", Charset: "latin1"},
+				{Table: "t", Column: "f", Collation: "latin1_bin"},
+			},
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "alter table t add column c int, engine=innodb",
+		},
+		{
+			sql: "create table t (id int primary key) charset latin1",
+		},
+		{
+			charset: "utf8mb4",
+			sql:     "drop table t",
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			stmt, err := parser.ParseStrictDDL(tcase.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, CharsetDeviations(env, stmt, tcase.charset, tcase.collation))
+		})
+	}
+}
+
+func TestValidateCharset(t *testing.T) {
+	env := collations.MySQL8()
+	stmt, err := sqlparser.NewTestParser().ParseStrictDDL("create table t (id int primary key, name varchar(64) charset latin1) charset utf8mb3")
+	require.NoError(t, err)
+
+	err = ValidateCharset(env, stmt, NewDDLStrategySetting(DDLStrategyVitess, ""), "utf8mb4", "utf8mb4_0900_ai_ci")
+	assert.EqualError(t, err, "table t has character set utf8mb3, column t.name has character set latin1, but the keyspace defaults to character set utf8mb4 and collation utf8mb4_0900_ai_ci; use --allow-nondefault-charset in the ddl strategy to override")
+	assert.NoError(t, ValidateCharset(env, stmt, NewDDLStrategySetting(DDLStrategyVitess, "--allow-nondefault-charset"), "utf8mb4", "utf8mb4_0900_ai_ci"))
+	assert.NoError(t, ValidateCharset(env, stmt, nil, "", ""))
+}
+
+func TestConvertCharsetStatement(t *testing.T) {
+	env := collations.MySQL8()
+	assert.Equal(t, "ALTER TABLE `order` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci", ConvertCharsetStatement(env, "order", "utf8mb4", "utf8mb4_0900_ai_ci"))
+	assert.Equal(t, "ALTER TABLE t CONVERT TO CHARACTER SET utf8mb4", ConvertCharsetStatement(env, "t", "utf8mb4", ""))
+	assert.Equal(t, "ALTER TABLE t CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_bin", ConvertCharsetStatement(env, "t", "", "utf8mb4_bin"))
+}
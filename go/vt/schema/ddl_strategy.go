@@ -34,23 +34,24 @@ var (
 )
 
 const (
-	declarativeFlag        = "declarative"
-	skipTopoFlag           = "skip-topo" // legacy. Kept for backwards compatibility, but unused
-	singletonFlag          = "singleton"
-	singletonContextFlag   = "singleton-context"
-	allowZeroInDateFlag    = "allow-zero-in-date"
-	postponeLaunchFlag     = "postpone-launch"
-	postponeCompletionFlag = "postpone-completion"
-	inOrderCompletionFlag  = "in-order-completion"
-	allowConcurrentFlag    = "allow-concurrent"
-	preferInstantDDL       = "prefer-instant-ddl"
-	fastRangeRotationFlag  = "fast-range-rotation"
-	cutOverThresholdFlag   = "cut-over-threshold"
-	forceCutOverAfterFlag  = "force-cut-over-after"
-	retainArtifactsFlag    = "retain-artifacts"
-	vreplicationTestSuite  = "vreplication-test-suite"
-	allowForeignKeysFlag   = "unsafe-allow-foreign-keys"
-	analyzeTableFlag       = "analyze-table"
+	declarativeFlag            = "declarative"
+	skipTopoFlag               = "skip-topo" // legacy. Kept for backwards compatibility, but unused
+	singletonFlag              = "singleton"
+	singletonContextFlag       = "singleton-context"
+	allowZeroInDateFlag        = "allow-zero-in-date"
+	postponeLaunchFlag         = "postpone-launch"
+	postponeCompletionFlag     = "postpone-completion"
+	inOrderCompletionFlag      = "in-order-completion"
+	allowConcurrentFlag        = "allow-concurrent"
+	preferInstantDDL           = "prefer-instant-ddl"
+	fastRangeRotationFlag      = "fast-range-rotation"
+	cutOverThresholdFlag       = "cut-over-threshold"
+	forceCutOverAfterFlag      = "force-cut-over-after"
+	retainArtifactsFlag        = "retain-artifacts"
+	vreplicationTestSuite      = "vreplication-test-suite"
+	allowForeignKeysFlag       = "unsafe-allow-foreign-keys"
+	analyzeTableFlag           = "analyze-table"
+	allowNonDefaultCharsetFlag = "allow-nondefault-charset"
 )
 
 // DDLStrategy suggests how an ALTER TABLE should run (e.g. "direct", "online", "gh-ost" or "pt-osc")
@@ -303,6 +304,11 @@ func (setting *DDLStrategySetting) IsAnalyzeTableFlag() bool {
 	return setting.hasFlag(analyzeTableFlag)
 }
 
+// IsAllowNonDefaultCharsetFlag checks if strategy options include --allow-nondefault-charset
+func (setting *DDLStrategySetting) IsAllowNonDefaultCharsetFlag() bool {
+	return setting.hasFlag(allowNonDefaultCharsetFlag)
+}
+
 // RuntimeOptions returns the options used as runtime flags for given strategy, removing any internal hint options
 func (setting *DDLStrategySetting) RuntimeOptions() []string {
 	opts, _ := shlex.Split(setting.Options)
@@ -332,6 +338,7 @@ func (setting *DDLStrategySetting) RuntimeOptions() []string {
 		case isFlag(opt, vreplicationTestSuite):
 		case isFlag(opt, allowForeignKeysFlag):
 		case isFlag(opt, analyzeTableFlag):
+		case isFlag(opt, allowNonDefaultCharsetFlag):
 		default:
 			validOpts = append(validOpts, opt)
 		}
@@ -184,24 +184,25 @@ func TestIsExpireArtifactsFlag(t *testing.T) {
 
 func TestParseDDLStrategy(t *testing.T) {
 	tt := []struct {
-		strategyVariable     string
-		strategy             DDLStrategy
-		options              string
-		isDeclarative        bool
-		isSingleton          bool
-		isPostponeLaunch     bool
-		isPostponeCompletion bool
-		isInOrderCompletion  bool
-		isAllowConcurrent    bool
-		fastOverRevertible   bool
-		fastRangeRotation    bool
-		allowForeignKeys     bool
-		analyzeTable         bool
-		cutOverThreshold     time.Duration
-		forceCutOverAfter    time.Duration
-		expireArtifacts      time.Duration
-		runtimeOptions       string
-		expectError          string
+		strategyVariable       string
+		strategy               DDLStrategy
+		options                string
+		isDeclarative          bool
+		isSingleton            bool
+		isPostponeLaunch       bool
+		isPostponeCompletion   bool
+		isInOrderCompletion    bool
+		isAllowConcurrent      bool
+		fastOverRevertible     bool
+		fastRangeRotation      bool
+		allowForeignKeys       bool
+		analyzeTable           bool
+		allowNonDefaultCharset bool
+		cutOverThreshold       time.Duration
+		forceCutOverAfter      time.Duration
+		expireArtifacts        time.Duration
+		runtimeOptions         string
+		expectError            string
 	}{
 		{
 			strategyVariable: "direct",
@@ -354,6 +355,13 @@ func TestParseDDLStrategy(t *testing.T) {
 			runtimeOptions:   "",
 			analyzeTable:     true,
 		},
+		{
+			strategyVariable:       "direct --allow-nondefault-charset",
+			strategy:               DDLStrategyDirect,
+			options:                "--allow-nondefault-charset",
+			runtimeOptions:         "",
+			allowNonDefaultCharset: true,
+		},
 
 		{
 			strategyVariable: "vitess --alow-concrrnt", // intentional typo
@@ -386,6 +394,7 @@ func TestParseDDLStrategy(t *testing.T) {
 			assert.Equal(t, ts.fastOverRevertible, setting.IsPreferInstantDDL())
 			assert.Equal(t, ts.allowForeignKeys, setting.IsAllowForeignKeysFlag())
 			assert.Equal(t, ts.analyzeTable, setting.IsAnalyzeTableFlag())
+			assert.Equal(t, ts.allowNonDefaultCharset, setting.IsAllowNonDefaultCharsetFlag())
 			cutOverThreshold, err := setting.CutOverThreshold()
 			assert.NoError(t, err)
 			assert.Equal(t, ts.cutOverThreshold, cutOverThreshold)
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	"vitess.io/vitess/go/protoutil"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// ShardRollout applies the direct DDLs shard by shard, instead of on all
+// the shards at once.
+type ShardRollout struct {
+	// Concurrency is the maximum number of shards applying a DDL at the same
+	// time. Zero applies it on all the shards at once.
+	Concurrency int
+	// Retries is the number of times a DDL is retried on a shard where it
+	// failed, before the shard is considered failed.
+	Retries int
+	// RetryDelay is the time to wait before retrying a DDL on a shard.
+	RetryDelay time.Duration
+	// MaxFailureRatio is the ratio of failed shards, among the shards done
+	// with the DDL, above which the rollout is aborted: the DDL is not
+	// started on the remaining shards. Zero aborts on the first failed shard.
+	MaxFailureRatio float64
+	// Progress, if set, is called on every change of the state of a shard.
+	// The calls are serialized.
+	Progress func(progress *vtctldatapb.ApplySchemaShardProgress)
+}
+
+// NewShardRollout creates a ShardRollout from its protobuf definition, which
+// may be nil to apply the DDLs on all the shards at once.
+func NewShardRollout(rollout *vtctldatapb.ApplySchemaShardRollout) (*ShardRollout, error) {
+	if rollout.GetConcurrency() < 0 {
+		return nil, fmt.Errorf("invalid shard rollout concurrency: %d", rollout.Concurrency)
+	}
+	if rollout.GetRetries() < 0 {
+		return nil, fmt.Errorf("invalid shard rollout retries: %d", rollout.Retries)
+	}
+	if rollout.GetMaxFailureRatio() < 0 || rollout.GetMaxFailureRatio() > 1 {
+		return nil, fmt.Errorf("invalid shard rollout max failure ratio: %v, must be between 0 and 1", rollout.MaxFailureRatio)
+	}
+	retryDelay, _, err := protoutil.DurationFromProto(rollout.GetRetryDelay())
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard rollout retry delay: %v", err)
+	}
+	return &ShardRollout{
+		Concurrency:     int(rollout.GetConcurrency()),
+		Retries:         int(rollout.GetRetries()),
+		RetryDelay:      retryDelay,
+		MaxFailureRatio: rollout.GetMaxFailureRatio(),
+	}, nil
+}
+
+// SetShardRollout makes the executor apply the direct DDLs with the rollout.
+func (exec *TabletExecutor) SetShardRollout(rollout *ShardRollout) {
+	exec.shardRollout = rollout
+}
+
+// rolloutOnAllTablets runs a direct DDL on all the tablets with the shard
+// rollout: with a bounded concurrency, retrying the failed shards, and
+// aborting once too many shards failed.
+func (exec *TabletExecutor) rolloutOnAllTablets(ctx context.Context, execResult *ExecuteResult, sql string) {
+	rollout := exec.shardRollout
+	concurrency := rollout.Concurrency
+	if concurrency <= 0 || concurrency > len(exec.tablets) {
+		concurrency = len(exec.tablets)
+	}
+	sem := semaphore.NewWeighted(int64(concurrency))
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		done      int
+		aborted   bool
+		failed    []ShardWithError
+		succeeded []ShardResult
+	)
+	report := func(progress *vtctldatapb.ApplySchemaShardProgress) {
+		progress.SqlIndex = int64(execResult.CurSQLIndex)
+		exec.logger.Infof("ApplySchema on shard %s: %s (attempt %d) %s", progress.Shard, progress.State, progress.Attempt, progress.Error)
+		if rollout.Progress != nil {
+			rollout.Progress(progress)
+		}
+	}
+
+	next := 0
+	for ; next < len(exec.tablets); next++ {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break
+		}
+		mu.Lock()
+		stop := aborted
+		mu.Unlock()
+		if stop {
+			sem.Release(1)
+			break
+		}
+		wg.Add(1)
+		go func(tablet *topodatapb.Tablet) {
+			defer wg.Done()
+			defer sem.Release(1)
+			result, shardErr := exec.rolloutOneTablet(ctx, tablet, sql, func(progress *vtctldatapb.ApplySchemaShardProgress) {
+				mu.Lock()
+				defer mu.Unlock()
+				report(progress)
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if shardErr != nil {
+				failed = append(failed, *shardErr)
+				if !aborted && float64(len(failed))/float64(done) > rollout.MaxFailureRatio {
+					aborted = true
+					exec.logger.Errorf("ApplySchema aborted after %d failed shards out of %d", len(failed), done)
+				}
+				return
+			}
+			succeeded = append(succeeded, *result)
+		}(exec.tablets[next])
+	}
+	wg.Wait()
+
+	// The DDL is never started on the remaining shards.
+	for _, tablet := range exec.tablets[next:] {
+		err := "schema change aborted before it started on the shard"
+		if ctx.Err() != nil {
+			err = fmt.Sprintf("schema change not started on the shard: %v", ctx.Err())
+		}
+		failed = append(failed, ShardWithError{Shard: tablet.Shard, Err: err})
+		report(&vtctldatapb.ApplySchemaShardProgress{
+			Shard: tablet.Shard,
+			State: vtctldatapb.ApplySchemaShardProgress_ABORTED,
+			Error: err,
+		})
+	}
+	execResult.FailedShards = append(make([]ShardWithError, 0, len(failed)), failed...)
+	execResult.SuccessShards = append(make([]ShardResult, 0, len(succeeded)), succeeded...)
+}
+
+// rolloutOneTablet runs a direct DDL on a tablet, and retries it as long as
+// the rollout allows it.
+func (exec *TabletExecutor) rolloutOneTablet(ctx context.Context, tablet *topodatapb.Tablet, sql string, report func(*vtctldatapb.ApplySchemaShardProgress)) (*ShardResult, *ShardWithError) {
+	rollout := exec.shardRollout
+	for attempt := 1; ; attempt++ {
+		report(&vtctldatapb.ApplySchemaShardProgress{
+			Shard:   tablet.Shard,
+			State:   vtctldatapb.ApplySchemaShardProgress_RUNNING,
+			Attempt: int64(attempt),
+		})
+		errChan := make(chan ShardWithError, 1)
+		successChan := make(chan ShardResult, 1)
+		exec.executeOneTablet(ctx, tablet, sql, false, errChan, successChan)
+		select {
+		case result := <-successChan:
+			report(&vtctldatapb.ApplySchemaShardProgress{
+				Shard:        tablet.Shard,
+				State:        vtctldatapb.ApplySchemaShardProgress_SUCCEEDED,
+				Attempt:      int64(attempt),
+				RowsAffected: result.Result.GetRowsAffected(),
+			})
+			return &result, nil
+		case shardErr := <-errChan:
+			if attempt > rollout.Retries || ctx.Err() != nil {
+				report(&vtctldatapb.ApplySchemaShardProgress{
+					Shard:   tablet.Shard,
+					State:   vtctldatapb.ApplySchemaShardProgress_FAILED,
+					Attempt: int64(attempt),
+					Error:   shardErr.Err,
+				})
+				return nil, &shardErr
+			}
+			report(&vtctldatapb.ApplySchemaShardProgress{
+				Shard:   tablet.Shard,
+				State:   vtctldatapb.ApplySchemaShardProgress_RETRYING,
+				Attempt: int64(attempt),
+				Error:   shardErr.Err,
+			})
+			select {
+			case <-ctx.Done():
+			case <-time.After(rollout.RetryDelay):
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestNewShardRollout(t *testing.T) {
+	rollout, err := NewShardRollout(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &ShardRollout{}, rollout)
+
+	rollout, err = NewShardRollout(&vtctldatapb.ApplySchemaShardRollout{
+		Concurrency:     2,
+		Retries:         3,
+		RetryDelay:      protoutil.DurationToProto(time.Second),
+		MaxFailureRatio: 0.5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ShardRollout{Concurrency: 2, Retries: 3, RetryDelay: time.Second, MaxFailureRatio: 0.5}, rollout)
+
+	_, err = NewShardRollout(&vtctldatapb.ApplySchemaShardRollout{Concurrency: -1})
+	assert.ErrorContains(t, err, "invalid shard rollout concurrency")
+	_, err = NewShardRollout(&vtctldatapb.ApplySchemaShardRollout{MaxFailureRatio: 2})
+	assert.ErrorContains(t, err, "invalid shard rollout max failure ratio")
+}
+
+// runShardRollout runs the direct DDL on the 3 shards of test_keyspace with
+// the rollout, and returns the progress of the shards by state.
+func runShardRollout(t *testing.T, fakeTmc *fakeTabletManagerClient, rollout *ShardRollout) (*ExecuteResult, map[vtctldatapb.ApplySchemaShardProgress_State][]string, error) {
+	sql := "alter table test_table add column c int"
+	fakeTmc.AddSchemaDefinition("vt_test_keyspace", &tabletmanagerdatapb.SchemaDefinition{})
+	executor := NewTabletExecutor("TestShardRollout", newFakeTopo(t), fakeTmc, logutil.NewMemoryLogger(), testWaitReplicasTimeout, 0, vtenv.NewTestEnv())
+
+	states := make(map[vtctldatapb.ApplySchemaShardProgress_State][]string)
+	rollout.Progress = func(progress *vtctldatapb.ApplySchemaShardProgress) {
+		states[progress.State] = append(states[progress.State], progress.Shard)
+	}
+	executor.SetShardRollout(rollout)
+	result, err := Run(context.Background(), newFakeController([]string{sql}, false, false, false), executor)
+	return result, states, err
+}
+
+func TestShardRolloutRetries(t *testing.T) {
+	fakeTmc := newFakeTabletManagerClient()
+	fakeTmc.FailExecuteFetchAsDba("1", 2)
+
+	result, states, err := runShardRollout(t, fakeTmc, &ShardRollout{Concurrency: 1, Retries: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.SuccessShards, 3)
+	assert.Equal(t, []string{"1", "1"}, states[vtctldatapb.ApplySchemaShardProgress_RETRYING])
+	assert.ElementsMatch(t, []string{"0", "1", "2"}, states[vtctldatapb.ApplySchemaShardProgress_SUCCEEDED])
+	assert.Equal(t, 5, fakeTmc.executeFetchAsDbaCalls)
+}
+
+func TestShardRolloutAbort(t *testing.T) {
+	fakeTmc := newFakeTabletManagerClient()
+	for _, shard := range []string{"0", "1", "2"} {
+		fakeTmc.FailExecuteFetchAsDba(shard, 1)
+	}
+
+	// The rollout stops at the first failed shard.
+	result, states, err := runShardRollout(t, fakeTmc, &ShardRollout{Concurrency: 1})
+	assert.ErrorContains(t, err, "schema change failed")
+	assert.Empty(t, result.SuccessShards)
+	assert.Len(t, result.FailedShards, 3)
+	assert.Len(t, states[vtctldatapb.ApplySchemaShardProgress_FAILED], 1)
+	assert.Len(t, states[vtctldatapb.ApplySchemaShardProgress_ABORTED], 2)
+	assert.Equal(t, 1, fakeTmc.executeFetchAsDbaCalls)
+
+	// The rollout goes on as long as the ratio of failed shards is low enough.
+	fakeTmc = newFakeTabletManagerClient()
+	fakeTmc.FailExecuteFetchAsDba("1", 1)
+	result, states, err = runShardRollout(t, fakeTmc, &ShardRollout{Concurrency: 1, MaxFailureRatio: 1})
+	assert.ErrorContains(t, err, "schema change failed")
+	assert.Len(t, result.SuccessShards, 2)
+	assert.Equal(t, []string{"1"}, states[vtctldatapb.ApplySchemaShardProgress_FAILED])
+	assert.Empty(t, states[vtctldatapb.ApplySchemaShardProgress_ABORTED])
+}
+
+func TestShardRolloutRequiresDirectStrategy(t *testing.T) {
+	executor := newFakeExecutor(t)
+	require.NoError(t, executor.SetDDLStrategy("vitess"))
+	executor.SetShardRollout(&ShardRollout{Concurrency: 1})
+	_, err := Run(context.Background(), newFakeController([]string{"alter table test_table add column c int"}, false, false, false), executor)
+	assert.ErrorContains(t, err, "shard rollout requires 'direct' ddl-strategy")
+}
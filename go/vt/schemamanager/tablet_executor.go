@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
@@ -58,11 +59,12 @@ type TabletExecutor struct {
 	ddlStrategySetting  *schema.DDLStrategySetting
 	uuids               []string
 	batchSize           int64
-	parser              *sqlparser.Parser
+	shardRollout        *ShardRollout
+	env                 *vtenv.Environment
 }
 
 // NewTabletExecutor creates a new TabletExecutor instance
-func NewTabletExecutor(migrationContext string, ts *topo.Server, tmc tmclient.TabletManagerClient, logger logutil.Logger, waitReplicasTimeout time.Duration, batchSize int64, parser *sqlparser.Parser) *TabletExecutor {
+func NewTabletExecutor(migrationContext string, ts *topo.Server, tmc tmclient.TabletManagerClient, logger logutil.Logger, waitReplicasTimeout time.Duration, batchSize int64, env *vtenv.Environment) *TabletExecutor {
 	return &TabletExecutor{
 		ts:                  ts,
 		tmc:                 tmc,
@@ -71,7 +73,7 @@ func NewTabletExecutor(migrationContext string, ts *topo.Server, tmc tmclient.Ta
 		waitReplicasTimeout: waitReplicasTimeout,
 		migrationContext:    migrationContext,
 		batchSize:           batchSize,
-		parser:              parser,
+		env:                 env,
 	}
 }
 
@@ -143,13 +145,38 @@ func (exec *TabletExecutor) Validate(ctx context.Context, sqls []string) error {
 	if err := exec.parseDDLs(sqls); err != nil {
 		return err
 	}
+	if err := exec.validateCharsets(ctx, sqls); err != nil {
+		return err
+	}
+
+	return nil
+}
 
+// validateCharsets validates the character sets and collations set by the
+// statements against the default ones of the keyspace.
+func (exec *TabletExecutor) validateCharsets(ctx context.Context, sqls []string) error {
+	ki, err := exec.ts.GetKeyspace(ctx, exec.keyspace)
+	if err != nil {
+		return fmt.Errorf("unable to get keyspace: %s, error: %v", exec.keyspace, err)
+	}
+	if ki.DefaultCharset == "" && ki.DefaultCollation == "" {
+		return nil
+	}
+	for _, sql := range sqls {
+		stmt, err := exec.env.Parser().Parse(sql)
+		if err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "failed to parse sql: %s, got error: %v", sql, err)
+		}
+		if err := schema.ValidateCharset(exec.env.CollationEnv(), stmt, exec.ddlStrategySetting, ki.DefaultCharset, ki.DefaultCollation); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (exec *TabletExecutor) parseDDLs(sqls []string) error {
 	for _, sql := range sqls {
-		stmt, err := exec.parser.Parse(sql)
+		stmt, err := exec.env.Parser().Parse(sql)
 		if err != nil {
 			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "failed to parse sql: %s, got error: %v", sql, err)
 		}
@@ -277,14 +304,14 @@ func (exec *TabletExecutor) executeSQL(ctx context.Context, sql string, provided
 		return executeViaFetch()
 	}
 	// Analyze what type of query this is:
-	stmt, err := exec.parser.Parse(sql)
+	stmt, err := exec.env.Parser().Parse(sql)
 	if err != nil {
 		return false, err
 	}
 	switch stmt := stmt.(type) {
 	case sqlparser.DDLStatement:
 		if exec.isOnlineSchemaDDL(stmt) {
-			onlineDDLs, err := schema.NewOnlineDDLs(exec.keyspace, sql, stmt, exec.ddlStrategySetting, exec.migrationContext, providedUUID, exec.parser)
+			onlineDDLs, err := schema.NewOnlineDDLs(exec.keyspace, sql, stmt, exec.ddlStrategySetting, exec.migrationContext, providedUUID, exec.env.Parser())
 			if err != nil {
 				execResult.ExecutorErr = err.Error()
 				return false, err
@@ -300,7 +327,7 @@ func (exec *TabletExecutor) executeSQL(ctx context.Context, sql string, provided
 		}
 	case *sqlparser.RevertMigration:
 		strategySetting := schema.NewDDLStrategySetting(schema.DDLStrategyOnline, exec.ddlStrategySetting.Options)
-		onlineDDL, err := schema.NewOnlineDDL(exec.keyspace, "", sqlparser.String(stmt), strategySetting, exec.migrationContext, providedUUID, exec.parser)
+		onlineDDL, err := schema.NewOnlineDDL(exec.keyspace, "", sqlparser.String(stmt), strategySetting, exec.migrationContext, providedUUID, exec.env.Parser())
 		if err != nil {
 			execResult.ExecutorErr = err.Error()
 			return false, err
@@ -463,7 +490,7 @@ func (exec *TabletExecutor) Execute(ctx context.Context, sqls []string) *Execute
 		if exec.hasProvidedUUIDs() {
 			return errorExecResult(fmt.Errorf("--batch-size conflicts with --uuid-list. Batching does not support UUIDs."))
 		}
-		allSQLsAreCreate, err := allSQLsAreCreateQueries(sqls, exec.parser)
+		allSQLsAreCreate, err := allSQLsAreCreateQueries(sqls, exec.env.Parser())
 		if err != nil {
 			return errorExecResult(err)
 		}
@@ -473,6 +500,9 @@ func (exec *TabletExecutor) Execute(ctx context.Context, sqls []string) *Execute
 
 		sqls = batchSQLs(sqls, int(exec.batchSize))
 	}
+	if exec.shardRollout != nil && !exec.isDirectStrategy() {
+		return errorExecResult(fmt.Errorf("shard rollout requires 'direct' ddl-strategy"))
+	}
 	for index, sql := range sqls {
 		// Attempt to renew lease:
 		if err := rl.Do(func() error { return topo.CheckKeyspaceLocked(ctx, exec.keyspace) }); err != nil {
@@ -499,6 +529,10 @@ func (exec *TabletExecutor) Execute(ctx context.Context, sqls []string) *Execute
 
 // executeOnAllTablets runs a query on all tablets, synchronously. This can be a long running operation.
 func (exec *TabletExecutor) executeOnAllTablets(ctx context.Context, execResult *ExecuteResult, sql string, viaQueryService bool) {
+	if exec.shardRollout != nil && !viaQueryService {
+		exec.rolloutOnAllTablets(ctx, execResult, sql)
+		return
+	}
 	var wg sync.WaitGroup
 	numOfPrimaryTablets := len(exec.tablets)
 	wg.Add(numOfPrimaryTablets)
@@ -572,7 +606,7 @@ func (exec *TabletExecutor) executeOneTablet(
 	} else {
 		if exec.ddlStrategySetting != nil && exec.ddlStrategySetting.IsAllowZeroInDateFlag() {
 			// --allow-zero-in-date Applies to DDLs
-			sql, err = applyAllowZeroInDate(sql, exec.parser)
+			sql, err = applyAllowZeroInDate(sql, exec.env.Parser())
 			if err != nil {
 				errChan <- ShardWithError{Shard: tablet.Shard, Err: err.Error()}
 				return
@@ -33,6 +33,7 @@ import (
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 )
 
 var (
@@ -70,7 +71,7 @@ func TestTabletExecutorOpenWithEmptyPrimaryAlias(t *testing.T) {
 	err := ts.InitTablet(ctx, tablet, false /*allowPrimaryOverride*/, true /*createShardAndKeyspace*/, false /*allowUpdate*/)
 	require.NoError(t, err)
 
-	executor := NewTabletExecutor("TestTabletExecutorOpenWithEmptyPrimaryAlias", ts, newFakeTabletManagerClient(), logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, sqlparser.NewTestParser())
+	executor := NewTabletExecutor("TestTabletExecutorOpenWithEmptyPrimaryAlias", ts, newFakeTabletManagerClient(), logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, vtenv.NewTestEnv())
 	err = executor.Open(ctx, "test_keyspace")
 	require.ErrorContains(t, err, "does not have a primary")
 	executor.Close()
@@ -102,7 +103,7 @@ func TestTabletExecutorValidate(t *testing.T) {
 		},
 	})
 
-	executor := NewTabletExecutor("TestTabletExecutorValidate", newFakeTopo(t), fakeTmc, logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, sqlparser.NewTestParser())
+	executor := NewTabletExecutor("TestTabletExecutorValidate", newFakeTopo(t), fakeTmc, logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, vtenv.NewTestEnv())
 	ctx := context.Background()
 
 	sqls := []string{
@@ -145,6 +146,34 @@ func TestTabletExecutorValidate(t *testing.T) {
 	require.NoError(t, err, "executor.Validate should succeed, drop a table with more than 2,000,000 rows is allowed")
 }
 
+func TestTabletExecutorValidateCharset(t *testing.T) {
+	ctx := context.Background()
+	ts := newFakeTopo(t)
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "test_keyspace", "TestTabletExecutorValidateCharset")
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(lockCtx, "test_keyspace")
+	require.NoError(t, err)
+	ki.DefaultCharset = "utf8mb4"
+	ki.DefaultCollation = "utf8mb4_0900_ai_ci"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+
+	executor := NewTabletExecutor("TestTabletExecutorValidateCharset", ts, newFakeTabletManagerClient(), logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, vtenv.NewTestEnv())
+	require.NoError(t, executor.Open(ctx, "test_keyspace"))
+	defer executor.Close()
+
+	sqls := []string{
+		"CREATE TABLE t1 (id int primary key, name varchar(64)) DEFAULT CHARSET=utf8mb4",
+		"ALTER TABLE t2 ADD COLUMN name varchar(64) CHARACTER SET utf8mb3",
+	}
+	err = executor.Validate(ctx, sqls)
+	assert.EqualError(t, err, "column t2.name has character set utf8mb3, but the keyspace defaults to character set utf8mb4 and collation utf8mb4_0900_ai_ci; use --allow-nondefault-charset in the ddl strategy to override")
+
+	require.NoError(t, executor.SetDDLStrategy("vitess --allow-nondefault-charset"))
+	assert.NoError(t, executor.Validate(ctx, sqls))
+}
+
 func TestTabletExecutorDML(t *testing.T) {
 	fakeTmc := newFakeTabletManagerClient()
 
@@ -171,7 +200,7 @@ func TestTabletExecutorDML(t *testing.T) {
 		},
 	})
 
-	executor := NewTabletExecutor("TestTabletExecutorDML", newFakeTopo(t), fakeTmc, logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, sqlparser.NewTestParser())
+	executor := NewTabletExecutor("TestTabletExecutorDML", newFakeTopo(t), fakeTmc, logutil.NewConsoleLogger(), testWaitReplicasTimeout, 0, vtenv.NewTestEnv())
 	ctx := context.Background()
 
 	executor.Open(ctx, "unsharded_keyspace")
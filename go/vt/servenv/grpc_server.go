@@ -28,7 +28,6 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
@@ -120,6 +119,9 @@ var (
 	gRPCEnableOptionalTLS bool
 	// gRPCServerCA if specified will combine server cert and server CA.
 	gRPCServerCA string
+	// gRPCSPIFFEIDs are the SPIFFE IDs allowed in client certificates, if
+	// specified.
+	gRPCSPIFFEIDs []string
 )
 
 // RegisterGRPCServerFlags registers flags required to run a gRPC server via Run
@@ -144,6 +146,7 @@ func RegisterGRPCServerFlags() {
 		fs.StringVar(&gRPCCRL, "grpc_crl", gRPCCRL, "path to a certificate revocation list in PEM format, client certificates will be further verified against this file during TLS handshake")
 		fs.BoolVar(&gRPCEnableOptionalTLS, "grpc_enable_optional_tls", gRPCEnableOptionalTLS, "enable optional TLS mode when a server accepts both TLS and plain-text connections on the same port")
 		fs.StringVar(&gRPCServerCA, "grpc_server_ca", gRPCServerCA, "path to server CA in PEM format, which will be combine with server cert, return full certificate chain to clients")
+		fs.StringSliceVar(&gRPCSPIFFEIDs, "grpc_spiffe_ids", gRPCSPIFFEIDs, "comma-separated list of SPIFFE IDs allowed in client certificates, requires grpc_ca. An ID ending with /* allows all the IDs under it")
 		fs.DurationVar(&gRPCKeepaliveTime, "grpc_server_keepalive_time", gRPCKeepaliveTime, "After a duration of this time, if the server doesn't see any activity, it pings the client to see if the transport is still alive.")
 		fs.DurationVar(&gRPCKeepaliveTimeout, "grpc_server_keepalive_timeout", gRPCKeepaliveTimeout, "After having pinged for keepalive check, the server waits for a duration of Timeout and if no activity is seen even after that the connection is closed.")
 	})
@@ -194,13 +197,22 @@ func createGRPCServer() {
 
 	var opts []grpc.ServerOption
 	if gRPCCert != "" && gRPCKey != "" {
-		config, err := vttls.ServerConfig(gRPCCert, gRPCKey, gRPCCA, gRPCCRL, gRPCServerCA, tls.VersionTLS12)
+		if len(gRPCSPIFFEIDs) > 0 && gRPCCA == "" {
+			log.Exitf("grpc_spiffe_ids requires grpc_ca to verify client certificates")
+		}
+
+		// create the creds server options, which reload the cert/key/ca/crl
+		// files for new connections when they change.
+		creds, err := grpccommon.NewReloadingTLS(func() (*tls.Config, error) {
+			config, err := vttls.ServerConfig(gRPCCert, gRPCKey, gRPCCA, gRPCCRL, gRPCServerCA, tls.VersionTLS12)
+			if err != nil {
+				return nil, err
+			}
+			return config, vttls.RequireSPIFFEIDs(config, gRPCSPIFFEIDs)
+		})
 		if err != nil {
 			log.Exitf("Failed to log gRPC cert/key/ca: %v", err)
 		}
-
-		// create the creds server options
-		creds := credentials.NewTLS(config)
 		if gRPCEnableOptionalTLS {
 			log.Warning("Optional TLS is active. Plain-text connections will be accepted")
 			creds = grpcoptionaltls.New(creds)
@@ -243,6 +255,11 @@ func createGRPCServer() {
 	}
 	opts = append(opts, grpc.KeepaliveParams(ka))
 
+	if gRPCMaxConcurrentStreams > 0 {
+		log.Infof("Setting grpc server max concurrent streams to %d", gRPCMaxConcurrentStreams)
+		opts = append(opts, grpc.MaxConcurrentStreams(gRPCMaxConcurrentStreams))
+	}
+
 	opts = append(opts, interceptors()...)
 
 	GRPCServer = grpc.NewServer(opts...)
@@ -263,6 +280,17 @@ func interceptors() []grpc.ServerOption {
 		interceptors.Add(authenticatingStreamInterceptor, authenticatingUnaryInterceptor)
 	}
 
+	// Enforce the rate limits after the authentication, so that
+	// unauthenticated clients cannot use up the rate of a method.
+	if len(gRPCMethodRateLimits) > 0 {
+		limits, err := newMethodLimits(gRPCMethodRateLimits)
+		if err != nil {
+			log.Exitf("Invalid gRPC server limits: %v", err)
+		}
+		log.Infof("enabling gRPC rate limits %v", gRPCMethodRateLimits)
+		interceptors.Add(limits.streamInterceptor, limits.unaryInterceptor)
+	}
+
 	if grpccommon.EnableGRPCPrometheus() {
 		interceptors.Add(grpc_prometheus.StreamServerInterceptor, grpc_prometheus.UnaryServerInterceptor)
 	}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var (
+	// gRPCMaxConcurrentStreams is the maximum number of concurrent streams
+	// per client connection. 0 keeps the gRPC default.
+	gRPCMaxConcurrentStreams uint32
+	// gRPCMethodRateLimits maps methods to their rate limit, in requests per
+	// second.
+	gRPCMethodRateLimits map[string]string
+
+	grpcServerRejections = stats.NewCountersWithMultiLabels("GRPCServerRejections", "Number of gRPC requests rejected by the server limits, by method and reason", []string{"Method", "Reason"})
+)
+
+const (
+	rejectRateLimit = "RateLimit"
+	// allMethods is the key applying a limit to every method without a more
+	// specific limit.
+	allMethods = "*"
+)
+
+func init() {
+	for _, cmd := range []string{
+		"vtctld",
+		"vttablet",
+	} {
+		OnParseFor(cmd, registerGRPCServerLimitsFlags)
+	}
+}
+
+func registerGRPCServerLimitsFlags(fs *pflag.FlagSet) {
+	fs.Uint32Var(&gRPCMaxConcurrentStreams, "grpc_max_concurrent_streams", gRPCMaxConcurrentStreams, "Maximum number of concurrent streams (in-flight RPCs) per gRPC client connection. 0 means no limit.")
+	fs.StringToStringVar(&gRPCMethodRateLimits, "grpc_method_rate_limits", gRPCMethodRateLimits, "Per-method gRPC rate limits, as method=requests per second. Methods are full method names (/tabletmanagerservice.TabletManager/GetSchema), bare method names (GetSchema), or * for every other method. Requests over the limit fail with RESOURCE_EXHAUSTED.")
+}
+
+// methodLimits enforces the per-method rate limits.
+type methodLimits struct {
+	rates map[string]rate.Limit
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newMethodLimits(rates map[string]string) (*methodLimits, error) {
+	ml := &methodLimits{
+		rates:    make(map[string]rate.Limit, len(rates)),
+		limiters: make(map[string]*rate.Limiter),
+	}
+	for method, value := range rates {
+		qps, err := strconv.ParseFloat(value, 64)
+		if err != nil || qps <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q for method %s, must be a positive number of requests per second", value, method)
+		}
+		ml.rates[method] = rate.Limit(qps)
+	}
+	return ml, nil
+}
+
+// lookup returns the limit configured for the given full method name,
+// trying the full name, the bare method name and then all methods.
+func lookup[T any](limits map[string]T, fullMethod string) (T, bool) {
+	if limit, ok := limits[fullMethod]; ok {
+		return limit, true
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		if limit, ok := limits[fullMethod[i+1:]]; ok {
+			return limit, true
+		}
+	}
+	limit, ok := limits[allMethods]
+	return limit, ok
+}
+
+// allow checks the rate limit of the method. Each method has its own limiter,
+// even when its limit comes from the * entry.
+func (ml *methodLimits) allow(fullMethod string) error {
+	limit, ok := lookup(ml.rates, fullMethod)
+	if !ok {
+		return nil
+	}
+
+	ml.mu.Lock()
+	limiter, ok := ml.limiters[fullMethod]
+	if !ok {
+		// Allow bursts of one second worth of requests.
+		limiter = rate.NewLimiter(limit, max(1, int(limit)))
+		ml.limiters[fullMethod] = limiter
+	}
+	ml.mu.Unlock()
+
+	if !limiter.Allow() {
+		grpcServerRejections.Add([]string{fullMethod, rejectRateLimit}, 1)
+		return status.Errorf(codes.ResourceExhausted, "rate limit of %v requests per second exceeded for %s", float64(limit), fullMethod)
+	}
+	return nil
+}
+
+func (ml *methodLimits) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := ml.allow(info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (ml *methodLimits) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := ml.allow(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
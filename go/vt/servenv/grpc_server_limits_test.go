@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestMethodLimits(t *testing.T) {
+	_, err := newMethodLimits(map[string]string{"GetSchema": "fast"})
+	require.ErrorContains(t, err, "invalid rate limit")
+	_, err = newMethodLimits(map[string]string{"GetSchema": "-1"})
+	require.ErrorContains(t, err, "invalid rate limit")
+
+	limits, err := newMethodLimits(map[string]string{
+		"GetSchema":                         "2",
+		"/queryservice.Query/StreamExecute": "1",
+	})
+	require.NoError(t, err)
+
+	calls := 0
+	handler := func(ctx context.Context, req any) (any, error) {
+		calls++
+		return nil, nil
+	}
+	call := func(method string, req any) error {
+		_, err := limits.unaryInterceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	getSchema := "/tabletmanagerservice.TabletManager/GetSchema"
+	counter := "/tabletmanagerservice_TabletManager/GetSchema." + rejectRateLimit
+	before := grpcServerRejections.Counts()[counter]
+	require.NoError(t, call(getSchema, &querypb.ExecuteRequest{}))
+	require.NoError(t, call(getSchema, &querypb.ExecuteRequest{}))
+	err = call(getSchema, &querypb.ExecuteRequest{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, before+1, grpcServerRejections.Counts()[counter])
+
+	// Methods without a rate limit are not limited.
+	for i := 0; i < 10; i++ {
+		require.NoError(t, call("/queryservice.Query/Execute", &querypb.ExecuteRequest{}))
+	}
+	assert.Equal(t, 12, calls)
+
+	// Streams are rate limited when they start.
+	streamHandler := func(srv any, stream grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: "/queryservice.Query/StreamExecute"}
+	require.NoError(t, limits.streamInterceptor(nil, nil, info, streamHandler))
+	err = limits.streamInterceptor(nil, nil, info, streamHandler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// ProfileCaptureURLPath is the path of the endpoint capturing profiles.
+const ProfileCaptureURLPath = "/debug/profile/capture"
+
+// The profiles which can be captured, with the names of runtime/pprof, and
+// "cpu" for the CPU profile.
+var captureProfileTypes = map[string]bool{
+	"cpu":          true,
+	"heap":         true,
+	"allocs":       true,
+	"goroutine":    true,
+	"mutex":        true,
+	"block":        true,
+	"threadcreate": true,
+}
+
+const defaultCPUProfileDuration = 30 * time.Second
+
+var (
+	profileCaptureUploader    = "file"
+	profileCaptureDir         string
+	profileCaptureMaxDuration = 2 * time.Minute
+
+	profileUploadersMu sync.Mutex
+	profileUploaders   = map[string]ProfileUploader{
+		"file": fileProfileUploader{},
+	}
+
+	profileLabelsMu sync.Mutex
+	profileLabels   = map[string]string{}
+
+	// captureMu allows a single capture at a time.
+	captureMu sync.Mutex
+)
+
+// ProfileCapture describes a captured profile.
+type ProfileCapture struct {
+	// Name identifies the profile, it is made of the component, the
+	// hostname, the time and the type of the profile.
+	Name      string
+	Type      string
+	Component string
+	Hostname  string
+	// Labels are the labels set with SetProfileLabel, e.g. the tablet alias.
+	Labels   map[string]string
+	Start    time.Time
+	Duration time.Duration `json:",omitempty"`
+}
+
+// ProfileUploader stores the captured profiles, e.g. in an object storage.
+type ProfileUploader interface {
+	// UploadProfile stores the profile data and the metadata of capture,
+	// and returns where the profile was stored.
+	UploadProfile(ctx context.Context, capture *ProfileCapture, data []byte) (string, error)
+}
+
+// RegisterProfileUploader registers a ProfileUploader, which is then
+// selected with --profile-capture-uploader.
+func RegisterProfileUploader(name string, uploader ProfileUploader) {
+	profileUploadersMu.Lock()
+	defer profileUploadersMu.Unlock()
+	if _, ok := profileUploaders[name]; ok {
+		log.Fatalf("profile uploader %s already registered", name)
+	}
+	profileUploaders[name] = uploader
+}
+
+func getProfileUploader() (ProfileUploader, error) {
+	profileUploadersMu.Lock()
+	defer profileUploadersMu.Unlock()
+	uploader, ok := profileUploaders[profileCaptureUploader]
+	if !ok {
+		return nil, fmt.Errorf("no registered profile uploader %q", profileCaptureUploader)
+	}
+	return uploader, nil
+}
+
+// SetProfileLabel sets a label recorded in the metadata of the captured
+// profiles, e.g. the tablet alias of a vttablet.
+func SetProfileLabel(key, value string) {
+	profileLabelsMu.Lock()
+	defer profileLabelsMu.Unlock()
+	profileLabels[key] = value
+}
+
+func getProfileLabels() map[string]string {
+	profileLabelsMu.Lock()
+	defer profileLabelsMu.Unlock()
+	return maps.Clone(profileLabels)
+}
+
+// captureProfile captures a profile of the given type. The CPU profile is
+// taken for the given duration, the other profiles are snapshots.
+func captureProfile(ctx context.Context, profileType string, duration time.Duration) (*ProfileCapture, []byte, error) {
+	capture := &ProfileCapture{
+		Type:      profileType,
+		Component: binaryName,
+		Hostname:  hostname,
+		Labels:    getProfileLabels(),
+		Start:     time.Now(),
+	}
+	capture.Name = fmt.Sprintf("%s-%s-%s-%s", capture.Component, capture.Hostname, capture.Start.UTC().Format("20060102-150405"), profileType)
+
+	var buf bytes.Buffer
+	if profileType != "cpu" {
+		if err := pprof.Lookup(profileType).WriteTo(&buf, 0); err != nil {
+			return nil, nil, err
+		}
+		return capture, buf.Bytes(), nil
+	}
+
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, nil, err
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	pprof.StopCPUProfile()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("CPU profile interrupted: %w", err)
+	}
+	capture.Duration = duration
+	return capture, buf.Bytes(), nil
+}
+
+// handleProfileCapture captures a profile and uploads it with the configured
+// ProfileUploader, so that the profiles of production hosts can be collected
+// during incidents without pulling them from the pprof endpoints. It only
+// accepts POST requests, with the type of the profile, cpu by default, and
+// the seconds of the CPU profile. It replies with the capture and the
+// location of the profile as JSON.
+func handleProfileCapture(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "profiles are only captured by POST requests", http.StatusMethodNotAllowed)
+		return
+	}
+
+	profileType := r.FormValue("type")
+	if profileType == "" {
+		profileType = "cpu"
+	}
+	if !captureProfileTypes[profileType] {
+		http.Error(w, fmt.Sprintf("unknown profile type %q", profileType), http.StatusBadRequest)
+		return
+	}
+	duration := defaultCPUProfileDuration
+	if value := r.FormValue("seconds"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			http.Error(w, "seconds must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+	if duration > profileCaptureMaxDuration {
+		http.Error(w, fmt.Sprintf("seconds must be at most %v", profileCaptureMaxDuration), http.StatusBadRequest)
+		return
+	}
+	uploader, err := getProfileUploader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !captureMu.TryLock() {
+		http.Error(w, "a profile is already being captured", http.StatusConflict)
+		return
+	}
+	capture, data, err := captureProfile(r.Context(), profileType, duration)
+	captureMu.Unlock()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot capture the %s profile: %v", profileType, err), http.StatusInternalServerError)
+		return
+	}
+	location, err := uploader.UploadProfile(r.Context(), capture, data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot upload the profile %s: %v", capture.Name, err), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Captured the %s profile %s to %s", profileType, capture.Name, location)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(struct {
+		*ProfileCapture
+		Location string
+	}{capture, location}, "", " ")
+	if err != nil {
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Write(b)
+}
+
+// fileProfileUploader stores the profiles in --profile-capture-dir, e.g. a
+// mounted bucket, with their metadata in a JSON file next to them.
+type fileProfileUploader struct{}
+
+// UploadProfile is part of the ProfileUploader interface.
+func (fileProfileUploader) UploadProfile(ctx context.Context, capture *ProfileCapture, data []byte) (string, error) {
+	if profileCaptureDir == "" {
+		return "", fmt.Errorf("--profile-capture-dir must be set to store the profiles in files")
+	}
+	if err := os.MkdirAll(profileCaptureDir, 0o755); err != nil {
+		return "", err
+	}
+	metadata, err := json.MarshalIndent(capture, "", " ")
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Join(profileCaptureDir, capture.Name)
+	if err := os.WriteFile(name+".json", metadata, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(name+".pprof", data, 0o644); err != nil {
+		return "", err
+	}
+	return name + ".pprof", nil
+}
+
+func init() {
+	OnParse(func(fs *pflag.FlagSet) {
+		fs.StringVar(&profileCaptureUploader, "profile-capture-uploader", profileCaptureUploader, "Where the profiles captured by "+ProfileCaptureURLPath+" are uploaded: file, or backup_storage to upload them to the backup storage of the binaries using one.")
+		fs.StringVar(&profileCaptureDir, "profile-capture-dir", profileCaptureDir, "Directory where the file uploader stores the profiles captured by "+ProfileCaptureURLPath+".")
+		fs.DurationVar(&profileCaptureMaxDuration, "profile-capture-max-duration", profileCaptureMaxDuration, "Maximum duration of the CPU profiles captured by "+ProfileCaptureURLPath+".")
+	})
+	OnInit(func() {
+		HTTPHandleFunc(ProfileCaptureURLPath, handleProfileCapture)
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProfileUploader struct {
+	captures []*ProfileCapture
+	err      error
+}
+
+func (fpu *fakeProfileUploader) UploadProfile(ctx context.Context, capture *ProfileCapture, data []byte) (string, error) {
+	if fpu.err != nil {
+		return "", fpu.err
+	}
+	fpu.captures = append(fpu.captures, capture)
+	return "fake/" + capture.Name, nil
+}
+
+func TestProfileCapture(t *testing.T) {
+	defer func(dir string) { profileCaptureDir = dir }(profileCaptureDir)
+	profileCaptureDir = t.TempDir()
+	SetProfileLabel("tablet_alias", "zone1-0000000100")
+	defer delete(profileLabels, "tablet_alias")
+
+	capture := func(method, query string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handleProfileCapture(resp, httptest.NewRequest(method, ProfileCaptureURLPath+query, nil))
+		return resp
+	}
+
+	resp := capture(http.MethodPost, "?type=heap")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var result struct {
+		ProfileCapture
+		Location string
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "heap", result.Type)
+	assert.Equal(t, binaryName, result.Component)
+	assert.Equal(t, "zone1-0000000100", result.Labels["tablet_alias"])
+	assert.True(t, strings.HasSuffix(result.Location, result.Name+".pprof"))
+	assert.FileExists(t, result.Location)
+	metadata, err := os.ReadFile(strings.TrimSuffix(result.Location, ".pprof") + ".json")
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "zone1-0000000100")
+
+	resp = capture(http.MethodPost, "?type=cpu&seconds=1")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, time.Second, result.Duration)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, capture(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusBadRequest, capture(http.MethodPost, "?type=disk").Code)
+	assert.Equal(t, http.StatusBadRequest, capture(http.MethodPost, "?seconds=0").Code)
+	assert.Equal(t, http.StatusBadRequest, capture(http.MethodPost, "?seconds=3600").Code)
+
+	// A single profile is captured at a time.
+	captureMu.Lock()
+	assert.Equal(t, http.StatusConflict, capture(http.MethodPost, "?type=heap").Code)
+	captureMu.Unlock()
+
+	// The profiles are uploaded by the configured uploader.
+	defer func(uploader string) { profileCaptureUploader = uploader }(profileCaptureUploader)
+	fpu := &fakeProfileUploader{}
+	RegisterProfileUploader("fake", fpu)
+	defer delete(profileUploaders, "fake")
+	profileCaptureUploader = "fake"
+	resp = capture(http.MethodPost, "?type=goroutine")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.Len(t, fpu.captures, 1)
+	assert.Equal(t, "goroutine", fpu.captures[0].Type)
+
+	fpu.err = fmt.Errorf("bucket not found")
+	resp = capture(http.MethodPost, "?type=goroutine")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "bucket not found")
+
+	profileCaptureUploader = "s3"
+	resp = capture(http.MethodPost, "?type=goroutine")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), `no registered profile uploader "s3"`)
+}
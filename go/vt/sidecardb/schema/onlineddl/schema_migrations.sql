@@ -73,6 +73,8 @@ CREATE TABLE IF NOT EXISTS schema_migrations
     `removed_foreign_key_names`       text             NOT NULL,
     `last_cutover_attempt_timestamp`  timestamp        NULL DEFAULT NULL,
     `force_cutover`                   tinyint unsigned NOT NULL DEFAULT '0',
+    `projected_disk_bytes`            bigint unsigned  NOT NULL DEFAULT '0',
+    `impact_analysis`                 text             NOT NULL,
     PRIMARY KEY (`id`),
     UNIQUE KEY `uuid_idx` (`migration_uuid`),
     KEY `keyspace_shard_idx` (`keyspace`(64), `shard`(64)),
@@ -0,0 +1,24 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+CREATE TABLE IF NOT EXISTS vstream_consumer_positions
+(
+    consumer_group VARBINARY(255) NOT NULL,
+    pos            TEXT           NOT NULL,
+    shard_gtid     MEDIUMBLOB     NOT NULL,
+    time_updated   BIGINT         NOT NULL,
+    PRIMARY KEY (`consumer_group`)
+) ENGINE = InnoDB
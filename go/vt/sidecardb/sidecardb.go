@@ -103,18 +103,7 @@ func init() {
 	ddlCount = stats.NewCounter(StatsKeyQueryCount, "Number of queries executed")
 	ddlErrorCount = stats.NewCounter(StatsKeyErrorCount, "Number of errors during sidecar schema upgrade")
 	ddlErrorHistory = history.New(maxDDLErrorHistoryLength)
-	stats.Publish(StatsKeyErrors, stats.StringMapFunc(func() map[string]string {
-		mu.Lock()
-		defer mu.Unlock()
-		result := make(map[string]string, len(ddlErrorHistory.Records()))
-		for _, e := range ddlErrorHistory.Records() {
-			d, ok := e.(*ddlError)
-			if ok {
-				result[d.tableName] = d.err.Error()
-			}
-		}
-		return result
-	}))
+	stats.Publish(StatsKeyErrors, stats.StringMapFunc(GetDDLErrors))
 }
 
 func validateSchemaDefinition(name, schema string, parser *sqlparser.Parser) (string, error) {
@@ -231,6 +220,22 @@ func getDDLErrorHistory() []*ddlError {
 	return errors
 }
 
+// GetDDLErrors returns the last error of the DDLs which failed to create or
+// upgrade the tables of the sidecar database as part of this vttablet's init
+// process, by table name.
+func GetDDLErrors() map[string]string {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make(map[string]string, len(ddlErrorHistory.Records()))
+	for _, e := range ddlErrorHistory.Records() {
+		d, ok := e.(*ddlError)
+		if ok {
+			result[d.tableName] = d.err.Error()
+		}
+	}
+	return result
+}
+
 // Init creates or upgrades the sidecar database based on
 // the declarative schema defined for all tables.
 func Init(ctx context.Context, env *vtenv.Environment, exec Exec) error {
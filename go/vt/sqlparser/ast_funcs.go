@@ -2027,8 +2027,12 @@ func (ty ShowCommandType) ToString() string {
 		return VGtidExecGlobalStr
 	case VitessMigrations:
 		return VitessMigrationsStr
+	case VitessPlans:
+		return VitessPlansStr
 	case VitessReplicationStatus:
 		return VitessReplicationStatusStr
+	case VitessSession:
+		return VitessSessionStr
 	case VitessShards:
 		return VitessShardsStr
 	case VitessTablets:
@@ -2037,6 +2041,8 @@ func (ty ShowCommandType) ToString() string {
 		return VitessTargetStr
 	case VitessVariables:
 		return VitessVariablesStr
+	case VitessWorkflows:
+		return VitessWorkflowsStr
 	case VschemaTables:
 		return VschemaTablesStr
 	case VschemaKeyspaces:
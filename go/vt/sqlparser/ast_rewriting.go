@@ -76,6 +76,7 @@ func RewriteAST(
 ) (*RewriteASTResult, error) {
 	er := newASTRewriter(keyspace, selectLimit, setVarComment, sysVars, fkChecksState, views)
 	er.shouldRewriteDatabaseFunc = shouldRewriteDatabaseFunc(in)
+	er.bindVars.LastInsertIDColumn = rewriteLastInsertIDExpr(in)
 	result := SafeRewrite(in, er.rewriteDown, er.rewriteUp)
 	if er.err != nil {
 		return nil, er.err
@@ -112,6 +113,35 @@ func shouldRewriteDatabaseFunc(in Statement) bool {
 	return tableName.Name.String() == "dual"
 }
 
+// rewriteLastInsertIDExpr rewrites the LAST_INSERT_ID(expr) select expressions of a
+// SELECT statement into CAST(expr AS UNSIGNED), so that vtgate, rather than the MySQL
+// connection of the shard which happens to execute the query, keeps track of the
+// last insert id. It returns the name of the column of the last one, whose value
+// in the last row of the result is the new last insert id, like in MySQL.
+func rewriteLastInsertIDExpr(in Statement) string {
+	sel, ok := in.(*Select)
+	if !ok {
+		return ""
+	}
+	var column string
+	for _, expr := range sel.SelectExprs {
+		aliasedExpr, ok := expr.(*AliasedExpr)
+		if !ok {
+			continue
+		}
+		funcExpr, ok := aliasedExpr.Expr.(*FuncExpr)
+		if !ok || funcExpr.Name.Lowered() != "last_insert_id" || len(funcExpr.Exprs) != 1 {
+			continue
+		}
+		if aliasedExpr.As.IsEmpty() {
+			aliasedExpr.As = NewIdentifierCI(String(funcExpr))
+		}
+		aliasedExpr.Expr = &CastExpr{Expr: funcExpr.Exprs[0], Type: &ConvertType{Type: "unsigned"}}
+		column = aliasedExpr.As.String()
+	}
+	return column
+}
+
 type astRewriter struct {
 	bindVars                  *BindVarNeeds
 	shouldRewriteDatabaseFunc bool
@@ -153,6 +183,9 @@ const (
 	// RowCountName is a reserved bind var name for row_count()
 	RowCountName = "__vtrcount"
 
+	// ConnectionIDName is a reserved bind var name for connection_id()
+	ConnectionIDName = "__vtconnid"
+
 	// UserDefinedVariableName is what we prepend bind var names for user defined variables
 	UserDefinedVariableName = "__vtudv"
 )
@@ -397,6 +430,7 @@ func (er *astRewriter) sysVarRewrite(cursor *Cursor, node *Variable) {
 		sysvars.SQLSelectLimit.Name,
 		sysvars.Version.Name,
 		sysvars.VersionComment.Name,
+		sysvars.VitessSession.Name,
 		sysvars.QueryTimeout.Name,
 		sysvars.Workload.Name:
 		found = true
@@ -420,6 +454,7 @@ var funcRewrites = map[string]string{
 	"schema":         DBVarName,
 	"found_rows":     FoundRowsName,
 	"row_count":      RowCountName,
+	"connection_id":  ConnectionIDName,
 }
 
 func (er *astRewriter) funcRewrite(cursor *Cursor, node *FuncExpr) {
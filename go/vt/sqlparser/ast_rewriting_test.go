@@ -38,11 +38,12 @@ type testCaseSysVar struct {
 
 type myTestCase struct {
 	in, expected                                                                            string
-	liid, db, foundRows, rowCount, rawGTID, rawTimeout, sessTrackGTID                       bool
+	liid, db, foundRows, rowCount, connectionID, rawGTID, rawTimeout, sessTrackGTID         bool
 	ddlStrategy, migrationContext, sessionUUID, sessionEnableSystemSettings                 bool
 	udv                                                                                     int
 	autocommit, foreignKeyChecks, clientFoundRows, skipQueryPlanCache, socket, queryTimeout bool
 	sqlSelectLimit, transactionMode, workload, version, versionComment                      bool
+	liidColumn                                                                              string
 }
 
 func TestRewrites(in *testing.T) {
@@ -70,6 +71,26 @@ func TestRewrites(in *testing.T) {
 		in:       "SELECT last_insert_id()",
 		expected: "SELECT :__lastInsertId as `last_insert_id()`",
 		liid:     true,
+	}, {
+		in:         "SELECT last_insert_id(5)",
+		expected:   "SELECT cast(5 as unsigned) as `last_insert_id(5)`",
+		liidColumn: "last_insert_id(5)",
+	}, {
+		in:         "SELECT last_insert_id(id + 1) as x, c, last_insert_id(c) from t",
+		expected:   "SELECT cast(id + 1 as unsigned) as x, c, cast(c as unsigned) as `last_insert_id(c)` from t",
+		liidColumn: "last_insert_id(c)",
+	}, {
+		in:         "SELECT last_insert_id(last_insert_id() + 1)",
+		expected:   "SELECT cast(:__lastInsertId + 1 as unsigned) as `last_insert_id(last_insert_id() + 1)`",
+		liid:       true,
+		liidColumn: "last_insert_id(last_insert_id() + 1)",
+	}, {
+		// LAST_INSERT_ID(expr) is only tracked as a select expression
+		in:       "SELECT last_insert_id(5) + 1",
+		expected: "SELECT last_insert_id(5) + 1",
+	}, {
+		in:       "update t set c = last_insert_id(c + 1)",
+		expected: "update t set c = last_insert_id(c + 1)",
 	}, {
 		in:       "SELECT database()",
 		expected: "SELECT :__vtdbname as `database()`",
@@ -136,6 +157,10 @@ func TestRewrites(in *testing.T) {
 		in:       "select row_count()",
 		expected: "select :__vtrcount as `row_count()`",
 		rowCount: true,
+	}, {
+		in:           "select connection_id()",
+		expected:     "select :__vtconnid as `connection_id()`",
+		connectionID: true,
 	}, {
 		in:       "SELECT lower(database())",
 		expected: "SELECT lower(:__vtdbname) as `lower(database())`",
@@ -360,9 +385,11 @@ func TestRewrites(in *testing.T) {
 			assert := assert.New(t)
 			assert.Equal(s, String(result.AST))
 			assert.Equal(tc.liid, result.NeedsFuncResult(LastInsertIDName), "should need last insert id")
+			assert.Equal(tc.liidColumn, result.LastInsertIDColumn, "last insert id column")
 			assert.Equal(tc.db, result.NeedsFuncResult(DBVarName), "should need database name")
 			assert.Equal(tc.foundRows, result.NeedsFuncResult(FoundRowsName), "should need found rows")
 			assert.Equal(tc.rowCount, result.NeedsFuncResult(RowCountName), "should need row count")
+			assert.Equal(tc.connectionID, result.NeedsFuncResult(ConnectionIDName), "should need connection id")
 			assert.Equal(tc.udv, len(result.NeedUserDefinedVariables), "count of user defined variables")
 			assert.Equal(tc.autocommit, result.NeedsSysVar(sysvars.Autocommit.Name), "should need :__vtautocommit")
 			assert.Equal(tc.foreignKeyChecks, result.NeedsSysVar(sysvars.ForeignKeyChecks), "should need :__vtforeignKeyChecks")
@@ -22,7 +22,11 @@ type BindVarNeeds struct {
 	NeedSystemVariable,
 	// NeedUserDefinedVariables keeps track of all user defined variables a query is using
 	NeedUserDefinedVariables []string
-	otherRewrites bool
+	// LastInsertIDColumn is the name of the column holding the value of the
+	// LAST_INSERT_ID(expr) select expression of the statement, if any. Its value
+	// in the last row of the result is the new last insert id of the session.
+	LastInsertIDColumn string
+	otherRewrites      bool
 }
 
 // MergeWith adds bind vars needs coming from sub scopes
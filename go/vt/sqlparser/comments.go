@@ -58,6 +58,12 @@ const (
 	// DirectivePriority specifies the priority of a workload. It should be an integer between 0 and MaxPriorityValue,
 	// where 0 is the highest priority, and MaxPriorityValue is the lowest one.
 	DirectivePriority = "PRIORITY"
+	// DirectiveMaxStaleness sets the maximum replication lag, in milliseconds, of the non-PRIMARY tablets
+	// executing the query. More stale tablets reject the query, and vtgate retries it on another tablet.
+	DirectiveMaxStaleness = "MAX_STALENESS_MS"
+	// DirectiveDMLChunkSize makes vttablet execute an UPDATE or DELETE statement without LIMIT in a series of
+	// transactions, each modifying at most the given number of rows, with throttler checks between them.
+	DirectiveDMLChunkSize = "DML_CHUNK_SIZE"
 
 	// MaxPriorityValue specifies the maximum value allowed for the priority query directive. Valid priority values are
 	// between zero and MaxPriorityValue.
@@ -65,10 +71,17 @@ const (
 
 	// OptimizerHintSetVar is the optimizer hint used in MySQL to set the value of a specific session variable for a query.
 	OptimizerHintSetVar = "SET_VAR"
+
+	// OptimizerHintMaxExecutionTime is the optimizer hint used in MySQL to set the timeout, in milliseconds, of a SELECT.
+	OptimizerHintMaxExecutionTime = "MAX_EXECUTION_TIME"
 )
 
 var ErrInvalidPriority = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid priority value specified in query")
 
+var ErrInvalidMaxStaleness = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid max staleness value specified in query")
+
+var ErrInvalidDMLChunkSize = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid DML chunk size value specified in query")
+
 func isNonSpace(r rune) bool {
 	return !unicode.IsSpace(r)
 }
@@ -318,6 +331,36 @@ func (c *ParsedComments) GetMySQLSetVarValue(key string) string {
 	return ""
 }
 
+// GetMySQLOptimizerHint gets the content of the given MySQL optimizer hint, e.g. 1000 for /*+ MAX_EXECUTION_TIME(1000) */.
+// It returns an empty string if the hint is not part of the first optimizer hint comment.
+func (c *ParsedComments) GetMySQLOptimizerHint(name string) string {
+	if c == nil {
+		return ""
+	}
+	for _, commentStr := range c.comments {
+		// Skip all the comments that don't start with the query optimizer prefix.
+		if commentStr[0:3] != queryOptimizerPrefix {
+			continue
+		}
+
+		pos := 4
+		for pos < len(commentStr) {
+			finalPos, ohNameStart, ohNameEnd, ohContentStart, ohContentEnd := getOptimizerHint(pos, commentStr)
+			pos = finalPos + 1
+			if ohContentEnd == -1 {
+				break
+			}
+			if strings.EqualFold(strings.TrimSpace(commentStr[ohNameStart:ohNameEnd]), name) {
+				return strings.TrimSpace(commentStr[ohContentStart:ohContentEnd])
+			}
+		}
+
+		// MySQL only parses the first comment that has the optimizer hint prefix. The following ones are ignored.
+		return ""
+	}
+	return ""
+}
+
 // SetMySQLSetVarValue updates or sets the value of the given variable as part of a /*+ SET_VAR() */ MySQL optimizer hint.
 func (c *ParsedComments) SetMySQLSetVarValue(key string, value string) (newComments Comments) {
 	if c == nil {
@@ -606,6 +649,50 @@ func GetPriorityFromStatement(statement Statement) (string, error) {
 	return priority, nil
 }
 
+// GetMaxStalenessFromStatement gets the maximum staleness, in milliseconds, from the provided Statement, using
+// DirectiveMaxStaleness. It returns 0 if the directive is not set.
+func GetMaxStalenessFromStatement(statement Statement) (int64, error) {
+	commentedStatement, ok := statement.(Commented)
+	if !ok {
+		return 0, nil
+	}
+
+	directives := commentedStatement.GetParsedComments().Directives()
+	maxStaleness, ok := directives.GetString(DirectiveMaxStaleness, "")
+	if !ok || maxStaleness == "" {
+		return 0, nil
+	}
+
+	intMaxStaleness, err := strconv.ParseInt(maxStaleness, 10, 64)
+	if err != nil || intMaxStaleness <= 0 {
+		return 0, ErrInvalidMaxStaleness
+	}
+
+	return intMaxStaleness, nil
+}
+
+// GetDMLChunkSizeFromStatement gets the number of rows of the chunks of the provided Statement, using
+// DirectiveDMLChunkSize. It returns 0 if the directive is not set.
+func GetDMLChunkSizeFromStatement(statement Statement) (int64, error) {
+	commentedStatement, ok := statement.(Commented)
+	if !ok {
+		return 0, nil
+	}
+
+	directives := commentedStatement.GetParsedComments().Directives()
+	chunkSize, ok := directives.GetString(DirectiveDMLChunkSize, "")
+	if !ok || chunkSize == "" {
+		return 0, nil
+	}
+
+	intChunkSize, err := strconv.ParseInt(chunkSize, 10, 64)
+	if err != nil || intChunkSize <= 0 {
+		return 0, ErrInvalidDMLChunkSize
+	}
+
+	return intChunkSize, nil
+}
+
 // Consolidator returns the consolidator option.
 func Consolidator(stmt Statement) querypb.ExecuteOptions_Consolidator {
 	var comments *ParsedComments
@@ -545,6 +545,100 @@ func TestGetPriorityFromStatement(t *testing.T) {
 	}
 }
 
+func TestGetMaxStalenessFromStatement(t *testing.T) {
+	testCases := []struct {
+		query                string
+		expectedMaxStaleness int64
+		expectedError        error
+	}{
+		{
+			query:                "select * from a_table",
+			expectedMaxStaleness: 0,
+		},
+		{
+			query:                "select /*vt+ PRIORITY=33 */ * from another_table",
+			expectedMaxStaleness: 0,
+		},
+		{
+			query:                "select /*vt+ MAX_STALENESS_MS=500 */ * from another_table",
+			expectedMaxStaleness: 500,
+		},
+		{
+			query:         "select /*vt+ MAX_STALENESS_MS=0 */ * from another_table",
+			expectedError: ErrInvalidMaxStaleness,
+		},
+		{
+			query:         "select /*vt+ MAX_STALENESS_MS=-1 */ * from another_table",
+			expectedError: ErrInvalidMaxStaleness,
+		},
+		{
+			query:         "select /*vt+ MAX_STALENESS_MS=1s */ * from another_table",
+			expectedError: ErrInvalidMaxStaleness,
+		},
+	}
+
+	parser := NewTestParser()
+	for _, testCase := range testCases {
+		t.Run(testCase.query, func(t *testing.T) {
+			t.Parallel()
+			stmt, err := parser.Parse(testCase.query)
+			assert.NoError(t, err)
+			actualMaxStaleness, actualError := GetMaxStalenessFromStatement(stmt)
+			if testCase.expectedError != nil {
+				assert.ErrorIs(t, actualError, testCase.expectedError)
+			} else {
+				assert.NoError(t, actualError)
+				assert.Equal(t, testCase.expectedMaxStaleness, actualMaxStaleness)
+			}
+		})
+	}
+}
+
+func TestGetDMLChunkSizeFromStatement(t *testing.T) {
+	testCases := []struct {
+		query             string
+		expectedChunkSize int64
+		expectedError     error
+	}{
+		{
+			query:             "delete from a_table where id > 1",
+			expectedChunkSize: 0,
+		},
+		{
+			query:             "delete /*vt+ DML_CHUNK_SIZE=1000 */ from a_table where id > 1",
+			expectedChunkSize: 1000,
+		},
+		{
+			query:             "update /*vt+ DML_CHUNK_SIZE=500 */ a_table set a = 1",
+			expectedChunkSize: 500,
+		},
+		{
+			query:         "delete /*vt+ DML_CHUNK_SIZE=0 */ from a_table",
+			expectedError: ErrInvalidDMLChunkSize,
+		},
+		{
+			query:         "delete /*vt+ DML_CHUNK_SIZE=many */ from a_table",
+			expectedError: ErrInvalidDMLChunkSize,
+		},
+	}
+
+	parser := NewTestParser()
+	for _, testCase := range testCases {
+		t.Run(testCase.query, func(t *testing.T) {
+			t.Parallel()
+			stmt, err := parser.Parse(testCase.query)
+			assert.NoError(t, err)
+			actualChunkSize, actualError := GetDMLChunkSizeFromStatement(stmt)
+			if testCase.expectedError != nil {
+				assert.ErrorIs(t, actualError, testCase.expectedError)
+			} else {
+				assert.NoError(t, actualError)
+				assert.Equal(t, testCase.expectedChunkSize, actualChunkSize)
+			}
+		})
+	}
+}
+
 // TestGetMySQLSetVarValue tests the functionality of GetMySQLSetVarValue
 func TestGetMySQLSetVarValue(t *testing.T) {
 	tests := []struct {
@@ -600,6 +694,43 @@ func TestGetMySQLSetVarValue(t *testing.T) {
 	}
 }
 
+func TestGetMySQLOptimizerHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		want     string
+	}{
+		{
+			name:     "Single hint",
+			comments: []string{"/*+ MAX_EXECUTION_TIME(1000) */"},
+			want:     "1000",
+		},
+		{
+			name:     "Hint after other hints",
+			comments: []string{"/* normal comment */", "/*+ SET_VAR(sort_buffer_size = 16M) max_execution_time( 50 ) */"},
+			want:     "50",
+		},
+		{
+			name:     "Hint in a later optimizer hint comment",
+			comments: []string{"/*+ NO_ICP(t1) */", "/*+ MAX_EXECUTION_TIME(1000) */"},
+			want:     "",
+		},
+		{
+			name:     "No comments",
+			comments: nil,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ParsedComments{
+				comments: tt.comments,
+			}
+			assert.Equal(t, tt.want, c.GetMySQLOptimizerHint(OptimizerHintMaxExecutionTime))
+		})
+	}
+}
+
 func TestSetMySQLSetVarValue(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -311,11 +311,14 @@ const (
 	VGtidExecGlobalStr         = " global vgtid_executed"
 	KeyspaceStr                = " keyspaces"
 	VitessMigrationsStr        = " vitess_migrations"
+	VitessPlansStr             = " vitess_plans"
 	VitessReplicationStatusStr = " vitess_replication_status"
+	VitessSessionStr           = " vitess_session"
 	VitessShardsStr            = " vitess_shards"
 	VitessTabletsStr           = " vitess_tablets"
 	VitessTargetStr            = " vitess_target"
 	VitessVariablesStr         = " vitess_metadata variables"
+	VitessWorkflowsStr         = " vitess_workflows"
 	VschemaTablesStr           = " vschema tables"
 	VschemaKeyspacesStr        = " vschema keyspaces"
 	VschemaVindexesStr         = " vschema vindexes"
@@ -907,11 +910,14 @@ const (
 	VariableSession
 	VGtidExecGlobal
 	VitessMigrations
+	VitessPlans
 	VitessReplicationStatus
+	VitessSession
 	VitessShards
 	VitessTablets
 	VitessTarget
 	VitessVariables
+	VitessWorkflows
 	VschemaTables
 	VschemaKeyspaces
 	VschemaVindexes
@@ -762,12 +762,15 @@ var keywords = []keyword{
 	{"vitess_metadata", VITESS_METADATA},
 	{"vitess_migration", VITESS_MIGRATION},
 	{"vitess_migrations", VITESS_MIGRATIONS},
+	{"vitess_plans", VITESS_PLANS},
 	{"vitess_replication_status", VITESS_REPLICATION_STATUS},
+	{"vitess_session", VITESS_SESSION},
 	{"vitess_shards", VITESS_SHARDS},
 	{"vitess_tablets", VITESS_TABLETS},
 	{"vitess_target", VITESS_TARGET},
 	{"vitess_throttled_apps", VITESS_THROTTLED_APPS},
 	{"vitess_throttler", VITESS_THROTTLER},
+	{"vitess_workflows", VITESS_WORKFLOWS},
 	{"vschema", VSCHEMA},
 	{"vstream", VSTREAM},
 	{"vtexplain", VTEXPLAIN},
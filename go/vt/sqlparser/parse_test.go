@@ -1411,6 +1411,12 @@ var (
 	}, {
 		input:  "set @@session.autocommit= OFF",
 		output: "set @@autocommit = 'off'",
+	}, {
+		input:  "set vitess_session = 'CAEgAQ=='",
+		output: "set @@`vitess_session` = 'CAEgAQ=='",
+	}, {
+		input:  "set session vitess_session = 'CAEgAQ=='",
+		output: "set @@`vitess_session` = 'CAEgAQ=='",
 	}, {
 		input:  "set autocommit = on",
 		output: "set @@autocommit = 'on'",
@@ -2405,6 +2411,8 @@ var (
 		input: "show vitess_tablets where hostname = 'some-tablet'",
 	}, {
 		input: "show vitess_targets",
+	}, {
+		input: "show vitess_session",
 	}, {
 		input: "show vschema tables",
 	}, {
@@ -2428,6 +2436,20 @@ var (
 		input: "show vitess_migrations like '9748c3b7_7fdb_11eb_ac2c_f875a4d24e90'",
 	}, {
 		input: "show vitess_migration '9748c3b7_7fdb_11eb_ac2c_f875a4d24e90' logs",
+	}, {
+		input: "show vitess_workflows",
+	}, {
+		input: "show vitess_workflows from ks",
+	}, {
+		input: "show vitess_workflows from ks where state = 'Running'",
+	}, {
+		input: "show vitess_workflows like 'commerce2customer%'",
+	}, {
+		input: "show vitess_plans",
+	}, {
+		input: "show vitess_plans like '%customer%'",
+	}, {
+		input: "show vitess_plans where Pinned = 'true'",
 	}, {
 		input: "revert vitess_migration '9748c3b7_7fdb_11eb_ac2c_f875a4d24e90'",
 	}, {
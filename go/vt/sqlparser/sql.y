@@ -350,7 +350,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 // SHOW tokens
 %token <str> CODE COLLATION COLUMNS DATABASES ENGINES EVENT EXTENDED FIELDS FULL FUNCTION GTID_EXECUTED
 %token <str> KEYSPACES OPEN PLUGINS PRIVILEGES PROCESSLIST SCHEMAS TABLES TRIGGERS USER
-%token <str> VGTID_EXECUTED VITESS_KEYSPACES VITESS_METADATA VITESS_MIGRATIONS VITESS_REPLICATION_STATUS VITESS_SHARDS VITESS_TABLETS VITESS_TARGET VSCHEMA VITESS_THROTTLED_APPS
+%token <str> VGTID_EXECUTED VITESS_KEYSPACES VITESS_METADATA VITESS_MIGRATIONS VITESS_REPLICATION_STATUS VITESS_SESSION VITESS_SHARDS VITESS_TABLETS VITESS_TARGET VSCHEMA VITESS_THROTTLED_APPS VITESS_WORKFLOWS VITESS_PLANS
 
 // SET tokens
 %token <str> NAMES GLOBAL SESSION ISOLATION LEVEL READ WRITE ONLY REPEATABLE COMMITTED UNCOMMITTED SERIALIZABLE
@@ -1068,6 +1068,14 @@ set_variable:
   {
     $$ = NewSetVariable(string($2), $1)
   }
+| VITESS_SESSION
+  {
+    $$ = NewSetVariable(string($1), SessionScope)
+  }
+| set_session_or_global VITESS_SESSION
+  {
+    $$ = NewSetVariable(string($2), $1)
+  }
 
 set_transaction_statement:
   SET comment_opt set_session_or_global TRANSACTION transaction_chars
@@ -4147,6 +4155,10 @@ show_statement:
   {
     $$ = &ShowThrottlerStatus{}
   }
+| SHOW VITESS_WORKFLOWS from_database_opt like_or_where_opt
+  {
+    $$ = &Show{&ShowBasic{Command: VitessWorkflows, Filter: $4, DbName: $3}}
+  }
 | SHOW VSCHEMA TABLES
   {
     $$ = &Show{&ShowBasic{Command: VschemaTables}}
@@ -4167,6 +4179,10 @@ show_statement:
   {
     $$ = &Show{&ShowBasic{Command: Warnings}}
   }
+| SHOW VITESS_PLANS like_or_where_opt
+  {
+    $$ = &Show{&ShowBasic{Command: VitessPlans, Filter: $3}}
+  }
 | SHOW VITESS_SHARDS like_or_where_opt
   {
     $$ = &Show{&ShowBasic{Command: VitessShards, Filter: $3}}
@@ -4179,6 +4195,10 @@ show_statement:
   {
     $$ = &Show{&ShowBasic{Command: VitessTarget}}
   }
+| SHOW VITESS_SESSION
+  {
+    $$ = &Show{&ShowBasic{Command: VitessSession}}
+  }
 /*
  * Catch-all for show statements without vitess keywords:
  */
@@ -8623,12 +8643,15 @@ non_reserved_keyword:
 | VITESS_METADATA
 | VITESS_MIGRATION
 | VITESS_MIGRATIONS
+| VITESS_PLANS
 | VITESS_REPLICATION_STATUS
+| VITESS_SESSION
 | VITESS_SHARDS
 | VITESS_TABLETS
 | VITESS_TARGET
 | VITESS_THROTTLED_APPS
 | VITESS_THROTTLER
+| VITESS_WORKFLOWS
 | VSCHEMA
 | VTEXPLAIN
 | WAIT_FOR_EXECUTED_GTID_SET %prec FUNCTION_CALL_NON_KEYWORD
@@ -44,12 +44,19 @@ var (
 	srvTopoTimeout      = 5 * time.Second
 	srvTopoCacheTTL     = 1 * time.Second
 	srvTopoCacheRefresh = 1 * time.Second
+
+	// srvKeyspaceCacheTTL and srvVSchemaCacheTTL override srvTopoCacheTTL
+	// for the SrvKeyspace and SrvVSchema objects, when set.
+	srvKeyspaceCacheTTL time.Duration
+	srvVSchemaCacheTTL  time.Duration
 )
 
 func registerFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&srvTopoTimeout, "srv_topo_timeout", srvTopoTimeout, "topo server timeout")
 	fs.DurationVar(&srvTopoCacheTTL, "srv_topo_cache_ttl", srvTopoCacheTTL, "how long to use cached entries for topology")
 	fs.DurationVar(&srvTopoCacheRefresh, "srv_topo_cache_refresh", srvTopoCacheRefresh, "how frequently to refresh the topology for cached entries")
+	fs.DurationVar(&srvKeyspaceCacheTTL, "srv-topo-keyspace-cache-ttl", srvKeyspaceCacheTTL, "how long to use cached SrvKeyspace entries when the topology cannot be reached, if set. Overrides --srv_topo_cache_ttl for the SrvKeyspace entries.")
+	fs.DurationVar(&srvVSchemaCacheTTL, "srv-topo-vschema-cache-ttl", srvVSchemaCacheTTL, "how long to use cached SrvVSchema entries when the topology cannot be reached, if set. Overrides --srv_topo_cache_ttl for the SrvVSchema entries.")
 }
 
 func init() {
@@ -59,9 +66,11 @@ func init() {
 }
 
 const (
-	queryCategory  = "query"
-	cachedCategory = "cached"
-	errorCategory  = "error"
+	queryCategory             = "query"
+	cachedCategory            = "cached"
+	staleCategory             = "stale"
+	errorCategory             = "error"
+	revalidationErrorCategory = "revalidation_error"
 )
 
 // ResilientServer is an implementation of srvtopo.Server based
@@ -82,15 +91,27 @@ func NewResilientServer(ctx context.Context, base *topo.Server, counts *stats.Co
 	if srvTopoCacheRefresh > srvTopoCacheTTL {
 		log.Fatalf("srv_topo_cache_refresh must be less than or equal to srv_topo_cache_ttl")
 	}
+	keyspaceTTL := cacheTTLOrDefault(srvKeyspaceCacheTTL)
+	vschemaTTL := cacheTTLOrDefault(srvVSchemaCacheTTL)
+	if srvTopoCacheRefresh > keyspaceTTL || srvTopoCacheRefresh > vschemaTTL {
+		log.Fatalf("srv_topo_cache_refresh must be less than or equal to srv-topo-keyspace-cache-ttl and srv-topo-vschema-cache-ttl")
+	}
 
 	return &ResilientServer{
 		topoServer:            base,
-		SrvKeyspaceWatcher:    NewSrvKeyspaceWatcher(ctx, base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
-		SrvVSchemaWatcher:     NewSrvVSchemaWatcher(ctx, base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
+		SrvKeyspaceWatcher:    NewSrvKeyspaceWatcher(ctx, base, counts, srvTopoCacheRefresh, keyspaceTTL),
+		SrvVSchemaWatcher:     NewSrvVSchemaWatcher(ctx, base, counts, srvTopoCacheRefresh, vschemaTTL),
 		SrvKeyspaceNamesQuery: NewSrvKeyspaceNamesQuery(base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
 	}
 }
 
+func cacheTTLOrDefault(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return srvTopoCacheTTL
+	}
+	return ttl
+}
+
 // GetTopoServer returns the topo.Server that backs the resilient server.
 func (server *ResilientServer) GetTopoServer() (*topo.Server, error) {
 	return server.topoServer, nil
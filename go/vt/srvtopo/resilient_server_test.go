@@ -397,6 +397,56 @@ func TestSrvKeyspaceCachedError(t *testing.T) {
 	}
 }
 
+// TestSrvKeyspaceMustRevalidate will test the cached SrvKeyspace is served
+// during a topo outage, within its own TTL, unless it must be revalidated.
+func TestSrvKeyspaceMustRevalidate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "test_cell")
+	srvTopoCacheTTL = 100 * time.Millisecond
+	srvTopoCacheRefresh = 40 * time.Millisecond
+	srvKeyspaceCacheTTL = 10 * time.Second
+	defer func() {
+		srvTopoCacheTTL = 1 * time.Second
+		srvTopoCacheRefresh = 1 * time.Second
+		srvKeyspaceCacheTTL = 0
+	}()
+	counts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
+	rs := NewResilientServer(ctx, ts, counts)
+	assert.Equal(t, srvKeyspaceCacheTTL, rs.SrvKeyspaceWatcher.rw.cacheTTL)
+	assert.Equal(t, srvTopoCacheTTL, rs.SrvVSchemaWatcher.rw.cacheTTL)
+
+	want := &topodatapb.SrvKeyspace{Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{ServedType: topodatapb.TabletType_PRIMARY}}}
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "test_cell", "test_ks", want))
+	got, err := rs.GetSrvKeyspace(WithMustRevalidate(ctx), "test_cell", "test_ks")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, got))
+	assert.Zero(t, rs.SrvKeyspaceWatcher.rw.staleness())
+
+	// The watch fails, and cannot be started again.
+	factory.SetError(topo.NewError(topo.Timeout, "test topo error"))
+	require.Eventually(t, func() bool {
+		_, err = rs.GetSrvKeyspace(WithMustRevalidate(ctx), "test_cell", "test_ks")
+		return err != nil
+	}, 5*time.Second, time.Millisecond)
+	assert.ErrorContains(t, err, "cannot revalidate test_cell.test_ks against the topology")
+	assert.Positive(t, counts.Counts()[revalidationErrorCategory])
+
+	// The cached value is still served past the default TTL.
+	time.Sleep(srvTopoCacheTTL)
+	got, err = rs.GetSrvKeyspace(ctx, "test_cell", "test_ks")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(want, got))
+	assert.Positive(t, rs.SrvKeyspaceWatcher.rw.staleness())
+
+	factory.SetError(nil)
+	require.Eventually(t, func() bool {
+		_, err = rs.GetSrvKeyspace(WithMustRevalidate(ctx), "test_cell", "test_ks")
+		return err == nil
+	}, 5*time.Second, time.Millisecond)
+	assert.Zero(t, rs.SrvKeyspaceWatcher.rw.staleness())
+}
+
 // TestGetSrvKeyspaceCreated will test we properly get the initial
 // value if the SrvKeyspace already exists.
 func TestGetSrvKeyspaceCreated(t *testing.T) {
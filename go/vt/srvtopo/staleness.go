@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srvtopo
+
+import (
+	"context"
+	"time"
+)
+
+type mustRevalidateKey struct{}
+
+// WithMustRevalidate returns a context whose reads of the SrvKeyspace and
+// SrvVSchema objects fail with an UNAVAILABLE error, rather than return a
+// cached value, unless the value is kept up to date by a running watch of the
+// topology. It is meant for the decisions which must not be taken on stale
+// data during a topology outage, such as picking the shards of a keyspace
+// being resharded.
+func WithMustRevalidate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mustRevalidateKey{}, true)
+}
+
+func mustRevalidate(ctx context.Context) bool {
+	revalidate, _ := ctx.Value(mustRevalidateKey{}).(bool)
+	return revalidate
+}
+
+// staleness returns how long the oldest of the values which are not kept up
+// to date by a running watch was last known to be current.
+func (w *resilientWatcher) staleness() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var staleness time.Duration
+	for _, entry := range w.entries {
+		entry.mutex.Lock()
+		if entry.watchState != watchStateRunning && entry.value != nil {
+			staleness = max(staleness, time.Since(entry.lastValueTime))
+		}
+		entry.mutex.Unlock()
+	}
+	return staleness
+}
+
+// Staleness returns, in seconds, how long the oldest cached SrvKeyspace and
+// SrvVSchema values served without a running watch of the topology were last
+// known to be current, keyed by object type. It is meant to be exported as a
+// gauge.
+func (server *ResilientServer) Staleness() map[string]int64 {
+	return map[string]int64{
+		"SrvKeyspace": int64(server.SrvKeyspaceWatcher.rw.staleness().Seconds()),
+		"SrvVSchema":  int64(server.SrvVSchemaWatcher.rw.staleness().Seconds()),
+	}
+}
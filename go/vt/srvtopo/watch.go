@@ -26,6 +26,9 @@ import (
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 type watchState int
@@ -128,7 +131,9 @@ func (entry *watchEntry) currentValueLocked(ctx context.Context) (any, error) {
 
 	entry.ensureWatchingLocked(ctx)
 
-	cacheValid := entry.value != nil && time.Since(entry.lastValueTime) < entry.rw.cacheTTL
+	// A value which must be revalidated is only served by a running watch.
+	revalidate := mustRevalidate(ctx)
+	cacheValid := !revalidate && entry.value != nil && time.Since(entry.lastValueTime) < entry.rw.cacheTTL
 	if cacheValid {
 		entry.rw.counts.Add(cachedCategory, 1)
 		return entry.value, nil
@@ -145,7 +150,14 @@ func (entry *watchEntry) currentValueLocked(ctx context.Context) (any, error) {
 		}
 		entry.mutex.Lock()
 	}
+	if revalidate && entry.watchState != watchStateRunning {
+		entry.rw.counts.Add(revalidationErrorCategory, 1)
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "cannot revalidate %v against the topology: %v", entry.key, entry.lastError)
+	}
 	if entry.value != nil {
+		if entry.watchState != watchStateRunning {
+			entry.rw.counts.Add(staleCategory, 1)
+		}
 		return entry.value, nil
 	}
 	return nil, entry.lastError
@@ -73,6 +73,7 @@ var (
 	TxReadOnly                  = SystemVariable{Name: "tx_read_only", IsBoolean: true, Default: off}
 	Workload                    = SystemVariable{Name: "workload", IdentifierAsString: true}
 	QueryTimeout                = SystemVariable{Name: "query_timeout"}
+	VitessSession               = SystemVariable{Name: "vitess_session"}
 
 	// Online DDL
 	DDLStrategy      = SystemVariable{Name: "ddl_strategy", IdentifierAsString: true}
@@ -106,6 +107,7 @@ var (
 		ReadAfterWriteTimeOut,
 		SessionTrackGTIDs,
 		QueryTimeout,
+		VitessSession,
 	}
 
 	ReadOnly = []SystemVariable{
@@ -274,6 +276,11 @@ func GetInterestingVariables() []string {
 	var res []string
 	// Add all the vitess aware variables
 	for _, variable := range VitessAware {
+		// The exported session cannot be computed while a transaction is open,
+		// and it is not a setting of the session anyway.
+		if variable == VitessSession {
+			continue
+		}
 		res = append(res, variable.Name)
 	}
 	// Also add version and version comment
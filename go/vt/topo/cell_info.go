@@ -81,6 +81,67 @@ func (ts *Server) GetCellInfo(ctx context.Context, cell string, strongRead bool)
 	return ci, nil
 }
 
+// WatchCellInfoData is returned / streamed by WatchCellInfo.
+// The WatchCellInfo API guarantees exactly one of Value or Err will be set.
+type WatchCellInfoData struct {
+	Value *topodatapb.CellInfo
+	Err   error
+}
+
+// WatchCellInfo will set a watch on the CellInfo of a cell.
+// It has the same contract as Conn.Watch, but it also unpacks the contents
+// into a CellInfo object.
+func (ts *Server) WatchCellInfo(ctx context.Context, cell string) (*WatchCellInfoData, <-chan *WatchCellInfoData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	current, wdChannel, err := ts.globalCell.Watch(ctx, pathForCellInfo(cell))
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	value := &topodatapb.CellInfo{}
+	if err := value.UnmarshalVT(current.Contents); err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return nil, nil, vterrors.Wrapf(err, "error unpacking initial CellInfo object")
+	}
+
+	changes := make(chan *WatchCellInfoData, 10)
+
+	// The background routine reads any event from the watch channel,
+	// translates it, and sends it to the caller.
+	// If cancel() is called, the underlying Watch() code will
+	// send an ErrInterrupted and then close the channel. We'll
+	// just propagate that back to our caller.
+	go func() {
+		defer cancel()
+		defer close(changes)
+
+		for wd := range wdChannel {
+			if wd.Err != nil {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchCellInfoData{Err: wd.Err}
+				return
+			}
+
+			value := &topodatapb.CellInfo{}
+			if err := value.UnmarshalVT(wd.Contents); err != nil {
+				cancel()
+				for range wdChannel {
+				}
+				changes <- &WatchCellInfoData{Err: vterrors.Wrapf(err, "error unpacking CellInfo object")}
+				return
+			}
+			changes <- &WatchCellInfoData{Value: value}
+		}
+	}()
+
+	return &WatchCellInfoData{Value: value}, changes, nil
+}
+
 // CreateCellInfo creates a new CellInfo with the provided content.
 func (ts *Server) CreateCellInfo(ctx context.Context, cell string, ci *topodatapb.CellInfo) error {
 	// Pack the content.
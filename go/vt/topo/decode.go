@@ -26,6 +26,7 @@ import (
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 )
 
 // DecodeContent uses the filename to imply a type, and proto-decodes
@@ -37,6 +38,8 @@ func DecodeContent(filename string, data []byte, json bool) (string, error) {
 	switch name {
 	case CellInfoFile:
 		p = new(topodatapb.CellInfo)
+	case CellsAliasFile:
+		p = new(topodatapb.CellsAlias)
 	case KeyspaceFile:
 		p = new(topodatapb.Keyspace)
 	case ShardFile:
@@ -53,6 +56,10 @@ func DecodeContent(filename string, data []byte, json bool) (string, error) {
 		p = new(topodatapb.SrvKeyspace)
 	case RoutingRulesFile:
 		p = new(vschemapb.RoutingRules)
+	case ShardRoutingRulesFile:
+		p = new(vschemapb.ShardRoutingRules)
+	case StatementACLFile:
+		p = new(vtgatepb.StatementACL)
 	case CommonRoutingRulesFile:
 		switch path.Base(dir) {
 		case "keyspace":
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestDecodeContent(t *testing.T) {
+	tcs := []struct {
+		filename string
+		msg      proto.Message
+	}{{
+		filename: "/keyspaces/ks/Keyspace",
+		msg:      &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"},
+	}, {
+		filename: "/cells_aliases/region1/CellsAlias",
+		msg:      &topodatapb.CellsAlias{Cells: []string{"zone1"}},
+	}, {
+		filename: "/ShardRoutingRules",
+		msg:      &vschemapb.ShardRoutingRules{Rules: []*vschemapb.ShardRoutingRule{{FromKeyspace: "ks1", ToKeyspace: "ks2", Shard: "0"}}},
+	}, {
+		filename: "/StatementACL",
+		msg:      &vtgatepb.StatementACL{Rules: []*vtgatepb.StatementACLRule{{Users: []string{"app"}}}},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.filename, func(t *testing.T) {
+			data, err := proto.Marshal(tc.msg)
+			require.NoError(t, err)
+
+			// The output format is not stable, so compare the decoded messages.
+			got, err := DecodeContent(tc.filename, data, false)
+			require.NoError(t, err)
+			decoded := tc.msg.ProtoReflect().New().Interface()
+			require.NoError(t, prototext.Unmarshal([]byte(got), decoded))
+			assert.True(t, proto.Equal(tc.msg, decoded), "got %s", got)
+
+			got, err = DecodeContent(tc.filename, data, true)
+			require.NoError(t, err)
+			decoded = tc.msg.ProtoReflect().New().Interface()
+			require.NoError(t, protojson.Unmarshal([]byte(got), decoded))
+			assert.True(t, proto.Equal(tc.msg, decoded), "got %s", got)
+		})
+	}
+
+	t.Run("unknown type", func(t *testing.T) {
+		got, err := DecodeContent("/unknown", []byte("raw"), false)
+		require.NoError(t, err)
+		assert.Equal(t, "raw", got)
+
+		_, err = DecodeContent("/unknown", []byte("raw"), true)
+		require.EqualError(t, err, "unknown topo protobuf type for unknown")
+	})
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+// VSchemaChange is an event that describes a new version of the vschema of a
+// keyspace.
+type VSchemaChange struct {
+	KeyspaceName string
+	Version      int64
+	Author       string
+	Comment      string
+	// Diff is the change from the previous vschema, as returned by
+	// topo.DiffVSchemas.
+	Diff string
+}
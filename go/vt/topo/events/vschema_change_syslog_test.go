@@ -0,0 +1,43 @@
+//go:build !windows
+
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"log/syslog"
+	"testing"
+)
+
+func TestVSchemaChangeSyslog(t *testing.T) {
+	wantSev, wantMsg := syslog.LOG_INFO, `keyspace-123 [vschema] version 3 by "alice": "add t1" diff: "+ table t1: {}"`
+	vc := &VSchemaChange{
+		KeyspaceName: "keyspace-123",
+		Version:      3,
+		Author:       "alice",
+		Comment:      "add t1",
+		Diff:         "+ table t1: {}",
+	}
+	gotSev, gotMsg := vc.Syslog()
+
+	if gotSev != wantSev {
+		t.Errorf("wrong severity: got %v, want %v", gotSev, wantSev)
+	}
+	if gotMsg != wantMsg {
+		t.Errorf("wrong message: got %q, want %q", gotMsg, wantMsg)
+	}
+}
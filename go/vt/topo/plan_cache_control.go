@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// This file contains the utility methods to manage the vtgate PlanCacheControl.
+
+// WatchPlanCacheControlData is returned / streamed by WatchPlanCacheControl.
+// The WatchPlanCacheControl API guarantees exactly one of Value or Err will be set.
+type WatchPlanCacheControlData struct {
+	Value *vtgatepb.PlanCacheControl
+	Err   error
+}
+
+// GetPlanCacheControl fetches the plan cache control from the global topo. It
+// returns an empty plan cache control if none was saved.
+func (ts *Server) GetPlanCacheControl(ctx context.Context) (*vtgatepb.PlanCacheControl, error) {
+	control, _, err := ts.getPlanCacheControl(ctx)
+	return control, err
+}
+
+func (ts *Server) getPlanCacheControl(ctx context.Context) (*vtgatepb.PlanCacheControl, Version, error) {
+	control := &vtgatepb.PlanCacheControl{}
+	data, version, err := ts.globalCell.Get(ctx, PlanCacheControlFile)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return control, nil, nil
+		}
+		return nil, nil, err
+	}
+	if err := control.UnmarshalVT(data); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad plan cache control data: %q", data)
+	}
+	return control, version, nil
+}
+
+// UpdatePlanCacheControl applies update to the plan cache control of the
+// global topo, and saves the result. It retries when the plan cache control
+// is updated concurrently, so update may be called several times.
+func (ts *Server) UpdatePlanCacheControl(ctx context.Context, update func(control *vtgatepb.PlanCacheControl) error) (*vtgatepb.PlanCacheControl, error) {
+	for {
+		control, version, err := ts.getPlanCacheControl(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := update(control); err != nil {
+			return nil, err
+		}
+		data, err := control.MarshalVT()
+		if err != nil {
+			return nil, err
+		}
+
+		if version == nil {
+			_, err = ts.globalCell.Create(ctx, PlanCacheControlFile, data)
+		} else {
+			_, err = ts.globalCell.Update(ctx, PlanCacheControlFile, data, version)
+		}
+		switch {
+		case IsErrType(err, NodeExists), IsErrType(err, BadVersion):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		return control, nil
+	}
+}
+
+// WatchPlanCacheControl will set a watch on the plan cache control in the global topo.
+// It has the same contract as Conn.Watch, but it also unpacks the contents
+// into a PlanCacheControl object.
+func (ts *Server) WatchPlanCacheControl(ctx context.Context) (*WatchPlanCacheControlData, <-chan *WatchPlanCacheControlData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	current, wdChannel, err := ts.globalCell.Watch(ctx, PlanCacheControlFile)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	value := &vtgatepb.PlanCacheControl{}
+	if err := value.UnmarshalVT(current.Contents); err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return nil, nil, vterrors.Wrapf(err, "error unpacking initial PlanCacheControl object")
+	}
+
+	changes := make(chan *WatchPlanCacheControlData, 10)
+
+	// The background routine reads any event from the watch channel,
+	// translates it, and sends it to the caller.
+	// If cancel() is called, the underlying Watch() code will
+	// send an ErrInterrupted and then close the channel. We'll
+	// just propagate that back to our caller.
+	go func() {
+		defer cancel()
+		defer close(changes)
+
+		for wd := range wdChannel {
+			if wd.Err != nil {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchPlanCacheControlData{Err: wd.Err}
+				return
+			}
+
+			value := &vtgatepb.PlanCacheControl{}
+			if err := value.UnmarshalVT(wd.Contents); err != nil {
+				cancel()
+				for range wdChannel {
+				}
+				changes <- &WatchPlanCacheControlData{Err: vterrors.Wrapf(err, "error unpacking PlanCacheControl object")}
+				return
+			}
+			changes <- &WatchPlanCacheControlData{Value: value}
+		}
+	}()
+
+	return &WatchPlanCacheControlData{Value: value}, changes, nil
+}
@@ -76,11 +76,15 @@ const (
 	ShardReplicationFile   = "ShardReplication"
 	TabletFile             = "Tablet"
 	SrvVSchemaFile         = "SrvVSchema"
+	StagedSrvVSchemaFile   = "StagedSrvVSchema"
 	SrvKeyspaceFile        = "SrvKeyspace"
 	RoutingRulesFile       = "RoutingRules"
 	ExternalClustersFile   = "ExternalClusters"
 	ShardRoutingRulesFile  = "ShardRoutingRules"
 	CommonRoutingRulesFile = "Rules"
+	StatementACLFile       = "StatementACL"
+	TenantCatalogFile      = "TenantCatalog"
+	PlanCacheControlFile   = "PlanCacheControl"
 )
 
 // Path for all object types.
@@ -94,6 +98,7 @@ const (
 	ExternalClusterVitess    = "vitess"
 	RoutingRulesPath         = "routing_rules"
 	KeyspaceRoutingRulesPath = "keyspace"
+	VSchemaHistoryPath       = "vschema_history"
 )
 
 // Factory is a factory interface to create Conn objects.
@@ -750,3 +750,49 @@ func (ts *Server) ValidateSrvKeyspace(ctx context.Context, keyspace, cells strin
 	}
 	return nil
 }
+
+// UpdateSrvKeyspaceTwoPC updates the TwopcEnabled of the existing SrvKeyspaces
+// of the keyspace in the given cells, or in all cells if none is given.
+func (ts *Server) UpdateSrvKeyspaceTwoPC(ctx context.Context, keyspace string, cells []string, enabled bool) (updatedCells []string, err error) {
+	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+		return updatedCells, err
+	}
+
+	if len(cells) == 0 {
+		cells, err = ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return updatedCells, err
+		}
+	}
+
+	var mu sync.Mutex
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	for _, cell := range cells {
+		wg.Add(1)
+		go func(cell string) {
+			defer wg.Done()
+			srvKeyspace, err := ts.GetSrvKeyspace(ctx, cell, keyspace)
+			switch {
+			case err == nil:
+				srvKeyspace.TwopcEnabled = enabled
+				if err := ts.UpdateSrvKeyspace(ctx, cell, keyspace, srvKeyspace); err != nil {
+					rec.RecordError(err)
+					return
+				}
+				mu.Lock()
+				updatedCells = append(updatedCells, cell)
+				mu.Unlock()
+			case IsErrType(err, NoNode):
+				// NOOP as not every cell will contain a serving tablet in the keyspace
+			default:
+				rec.RecordError(err)
+			}
+		}(cell)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return updatedCells, NewError(PartialResult, rec.Error().Error())
+	}
+	return updatedCells, nil
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"sync"
 
+	"google.golang.org/protobuf/proto"
+
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/vterrors"
 
@@ -141,8 +143,114 @@ func (ts *Server) DeleteSrvVSchema(ctx context.Context, cell string) error {
 	return conn.Delete(ctx, nodePath, nil)
 }
 
+// UpdateStagedSrvVSchemaFields is a high level helper to read the changes
+// staged in the SrvVSchema of a cell, update them, and then write them back.
+// If the write fails due to a version mismatch, it will re-read the staged
+// changes and retry the update. The staged Keyspaces are the staged keyspace
+// VSchemas, and the staged RoutingRules are set only if routing rules are
+// staged.
+func (ts *Server) UpdateStagedSrvVSchemaFields(ctx context.Context, cell string, update func(*vschemapb.SrvVSchema) error) error {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+
+	for {
+		staged := &vschemapb.SrvVSchema{}
+
+		// Read the file, unpack the contents.
+		contents, version, err := conn.Get(ctx, StagedSrvVSchemaFile)
+		switch {
+		case err == nil:
+			if err := staged.UnmarshalVT(contents); err != nil {
+				return vterrors.Wrapf(err, "StagedSrvVSchema unmarshal failed: %v", contents)
+			}
+		case IsErrType(err, NoNode):
+			// Nothing staged yet.
+		default:
+			return err
+		}
+
+		// Call update method.
+		if err = update(staged); err != nil {
+			if IsErrType(err, NoUpdateNeeded) {
+				return nil
+			}
+			return err
+		}
+
+		// Pack and save.
+		contents, err = staged.MarshalVT()
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			_, err = conn.Create(ctx, StagedSrvVSchemaFile, contents)
+			if !IsErrType(err, NodeExists) {
+				return err
+			}
+			continue
+		}
+		if _, err = conn.Update(ctx, StagedSrvVSchemaFile, contents, version); !IsErrType(err, BadVersion) {
+			// This includes the 'err=nil' case.
+			return err
+		}
+	}
+}
+
+// GetStagedSrvVSchema returns the changes staged in the SrvVSchema of a cell.
+// It returns a NoNode error if the cell has no staged changes.
+func (ts *Server) GetStagedSrvVSchema(ctx context.Context, cell string) (*vschemapb.SrvVSchema, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := conn.Get(ctx, StagedSrvVSchemaFile)
+	if err != nil {
+		return nil, err
+	}
+	staged := &vschemapb.SrvVSchema{}
+	if err := staged.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "StagedSrvVSchema unmarshal failed: %v", data)
+	}
+	return staged, nil
+}
+
+// DeleteStagedSrvVSchema deletes the changes staged in the SrvVSchema of a
+// cell. The SrvVSchema of the cell keeps them until it is rebuilt.
+func (ts *Server) DeleteStagedSrvVSchema(ctx context.Context, cell string) error {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+
+	err = conn.Delete(ctx, StagedSrvVSchemaFile, nil)
+	if IsErrType(err, NoNode) {
+		return nil
+	}
+	return err
+}
+
+// withStagedSrvVSchema returns a copy of srvVSchema with the staged changes
+// applied on top of it.
+func withStagedSrvVSchema(srvVSchema, staged *vschemapb.SrvVSchema) *vschemapb.SrvVSchema {
+	sv := srvVSchema.CloneVT()
+	if sv.Keyspaces == nil {
+		sv.Keyspaces = map[string]*vschemapb.Keyspace{}
+	}
+	for keyspace, k := range staged.Keyspaces {
+		sv.Keyspaces[keyspace] = k
+	}
+	if staged.RoutingRules != nil {
+		sv.RoutingRules = staged.RoutingRules
+	}
+	return sv
+}
+
 // RebuildSrvVSchema rebuilds the SrvVSchema for the provided cell list
-// (or all cells if cell list is empty).
+// (or all cells if cell list is empty). The changes staged in a cell are
+// applied on top of the global VSchema, so they survive the rebuild.
 func (ts *Server) RebuildSrvVSchema(ctx context.Context, cells []string) error {
 	// get the actual list of cells
 	if len(cells) == 0 {
@@ -153,10 +261,103 @@ func (ts *Server) RebuildSrvVSchema(ctx context.Context, cells []string) error {
 		}
 	}
 
+	srvVSchema, err := ts.BuildSrvVSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	// now save the SrvVSchema in all cells in parallel
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finalErr error
+	)
+	for _, cell := range cells {
+		wg.Add(1)
+		go func(cell string) {
+			defer wg.Done()
+			sv := srvVSchema
+			staged, err := ts.GetStagedSrvVSchema(ctx, cell)
+			switch {
+			case err == nil:
+				sv = withStagedSrvVSchema(srvVSchema, staged)
+			case !IsErrType(err, NoNode):
+				log.Errorf("%v: GetStagedSrvVSchema(%v) failed", err, cell)
+				mu.Lock()
+				finalErr = err
+				mu.Unlock()
+				return
+			}
+			if err := ts.UpdateSrvVSchema(ctx, cell, sv); err != nil {
+				log.Errorf("%v: UpdateSrvVSchema(%v) failed", err, cell)
+				mu.Lock()
+				finalErr = err
+				mu.Unlock()
+			}
+		}(cell)
+	}
+	wg.Wait()
+
+	return finalErr
+}
+
+// ReconcileSrvVSchemas rebuilds the SrvVSchema of each of the given cells (or
+// all cells if the cell list is empty) that diverges from the global VSchema
+// with the changes staged in the cell applied, e.g. because a rebuild failed
+// or raced with a concurrent change. It returns the rebuilt cells.
+func (ts *Server) ReconcileSrvVSchemas(ctx context.Context, cells []string) ([]string, error) {
+	if len(cells) == 0 {
+		var err error
+		cells, err = ts.GetKnownCells(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("GetKnownCells failed: %v", err)
+		}
+	}
+
+	srvVSchema, err := ts.BuildSrvVSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var diverged []string
+	for _, cell := range cells {
+		want := srvVSchema
+		staged, err := ts.GetStagedSrvVSchema(ctx, cell)
+		switch {
+		case err == nil:
+			want = withStagedSrvVSchema(srvVSchema, staged)
+		case !IsErrType(err, NoNode):
+			return nil, fmt.Errorf("GetStagedSrvVSchema(%v) failed: %v", cell, err)
+		}
+
+		got, err := ts.GetSrvVSchema(ctx, cell)
+		switch {
+		case err == nil:
+			if proto.Equal(want, got) {
+				continue
+			}
+		case !IsErrType(err, NoNode):
+			return nil, fmt.Errorf("GetSrvVSchema(%v) failed: %v", cell, err)
+		}
+		diverged = append(diverged, cell)
+	}
+
+	if len(diverged) == 0 {
+		return nil, nil
+	}
+	if err := ts.RebuildSrvVSchema(ctx, diverged); err != nil {
+		return nil, err
+	}
+	return diverged, nil
+}
+
+// BuildSrvVSchema builds the SrvVSchema from the global keyspace VSchemas and
+// routing rules, without saving it to any cell.
+func (ts *Server) BuildSrvVSchema(ctx context.Context) (*vschemapb.SrvVSchema, error) {
 	// get the keyspaces
 	keyspaces, err := ts.GetKeyspaces(ctx)
 	if err != nil {
-		return fmt.Errorf("GetKeyspaces failed: %v", err)
+		return nil, fmt.Errorf("GetKeyspaces failed: %v", err)
 	}
 
 	// build the SrvVSchema in parallel, protected by mu
@@ -189,41 +390,26 @@ func (ts *Server) RebuildSrvVSchema(ctx context.Context, cells []string) error {
 	}
 	wg.Wait()
 	if finalErr != nil {
-		return finalErr
+		return nil, finalErr
 	}
 
 	rr, err := ts.GetRoutingRules(ctx)
 	if err != nil {
-		return fmt.Errorf("GetRoutingRules failed: %v", err)
+		return nil, fmt.Errorf("GetRoutingRules failed: %v", err)
 	}
 	srvVSchema.RoutingRules = rr
 
 	srr, err := ts.GetShardRoutingRules(ctx)
 	if err != nil {
-		return fmt.Errorf("GetShardRoutingRules failed: %v", err)
+		return nil, fmt.Errorf("GetShardRoutingRules failed: %v", err)
 	}
 	srvVSchema.ShardRoutingRules = srr
 
 	krr, err := ts.GetKeyspaceRoutingRules(ctx)
 	if err != nil {
-		return fmt.Errorf("GetKeyspaceRoutingRules failed: %v", err)
+		return nil, fmt.Errorf("GetKeyspaceRoutingRules failed: %v", err)
 	}
 	srvVSchema.KeyspaceRoutingRules = krr
 
-	// now save the SrvVSchema in all cells in parallel
-	for _, cell := range cells {
-		wg.Add(1)
-		go func(cell string) {
-			defer wg.Done()
-			if err := ts.UpdateSrvVSchema(ctx, cell, srvVSchema); err != nil {
-				log.Errorf("%v: UpdateSrvVSchema(%v) failed", err, cell)
-				mu.Lock()
-				finalErr = err
-				mu.Unlock()
-			}
-		}(cell)
-	}
-	wg.Wait()
-
-	return finalErr
+	return srvVSchema, nil
 }
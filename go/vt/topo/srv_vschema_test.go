@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestUpdateStagedSrvVSchemaFieldsConcurrent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// Concurrent updates are all kept, none overwrites another.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := ts.UpdateStagedSrvVSchemaFields(ctx, "zone1", func(staged *vschemapb.SrvVSchema) error {
+				if staged.Keyspaces == nil {
+					staged.Keyspaces = map[string]*vschemapb.Keyspace{}
+				}
+				staged.Keyspaces[fmt.Sprintf("ks%d", i)] = &vschemapb.Keyspace{}
+				return nil
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	staged, err := ts.GetStagedSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Len(t, staged.Keyspaces, 10)
+}
+
+func TestReconcileSrvVSchemas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: true}))
+	require.NoError(t, ts.UpdateStagedSrvVSchemaFields(ctx, "zone2", func(staged *vschemapb.SrvVSchema) error {
+		staged.Keyspaces = map[string]*vschemapb.Keyspace{"ks": {}}
+		return nil
+	}))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	cells, err := ts.ReconcileSrvVSchemas(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, cells)
+
+	// A cell whose SrvVSchema was overwritten is rebuilt, with its staged
+	// changes.
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "zone2", &vschemapb.SrvVSchema{}))
+	cells, err = ts.ReconcileSrvVSchemas(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2"}, cells)
+
+	sv, err := ts.GetSrvVSchema(ctx, "zone2")
+	require.NoError(t, err)
+	require.Contains(t, sv.Keyspaces, "ks")
+	assert.False(t, sv.Keyspaces["ks"].Sharded)
+
+	// A cell without a SrvVSchema is built.
+	require.NoError(t, ts.DeleteSrvVSchema(ctx, "zone1"))
+	cells, err = ts.ReconcileSrvVSchemas(ctx, []string{"zone1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone1"}, cells)
+
+	sv, err = ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	require.Contains(t, sv.Keyspaces, "ks")
+	assert.True(t, sv.Keyspaces["ks"].Sharded)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// This file contains the utility methods to manage the vtgate StatementACL.
+
+// WatchStatementACLData is returned / streamed by WatchStatementACL.
+// The WatchStatementACL API guarantees exactly one of Value or Err will be set.
+type WatchStatementACLData struct {
+	Value *vtgatepb.StatementACL
+	Err   error
+}
+
+// SaveStatementACL saves the statement ACL into the global topo. An empty ACL
+// removes it.
+func (ts *Server) SaveStatementACL(ctx context.Context, acl *vtgatepb.StatementACL) error {
+	data, err := acl.MarshalVT()
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		if err := ts.globalCell.Delete(ctx, StatementACLFile, nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+		return nil
+	}
+
+	_, err = ts.globalCell.Update(ctx, StatementACLFile, data, nil)
+	return err
+}
+
+// GetStatementACL fetches the statement ACL from the global topo. It returns
+// an empty ACL if none was saved.
+func (ts *Server) GetStatementACL(ctx context.Context) (*vtgatepb.StatementACL, error) {
+	acl := &vtgatepb.StatementACL{}
+	data, _, err := ts.globalCell.Get(ctx, StatementACLFile)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return acl, nil
+		}
+		return nil, err
+	}
+	if err := acl.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "bad statement acl data: %q", data)
+	}
+	return acl, nil
+}
+
+// WatchStatementACL will set a watch on the statement ACL in the global topo.
+// It has the same contract as Conn.Watch, but it also unpacks the contents
+// into a StatementACL object.
+func (ts *Server) WatchStatementACL(ctx context.Context) (*WatchStatementACLData, <-chan *WatchStatementACLData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	current, wdChannel, err := ts.globalCell.Watch(ctx, StatementACLFile)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	value := &vtgatepb.StatementACL{}
+	if err := value.UnmarshalVT(current.Contents); err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return nil, nil, vterrors.Wrapf(err, "error unpacking initial StatementACL object")
+	}
+
+	changes := make(chan *WatchStatementACLData, 10)
+
+	// The background routine reads any event from the watch channel,
+	// translates it, and sends it to the caller.
+	// If cancel() is called, the underlying Watch() code will
+	// send an ErrInterrupted and then close the channel. We'll
+	// just propagate that back to our caller.
+	go func() {
+		defer cancel()
+		defer close(changes)
+
+		for wd := range wdChannel {
+			if wd.Err != nil {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchStatementACLData{Err: wd.Err}
+				return
+			}
+
+			value := &vtgatepb.StatementACL{}
+			if err := value.UnmarshalVT(wd.Contents); err != nil {
+				cancel()
+				for range wdChannel {
+				}
+				changes <- &WatchStatementACLData{Err: vterrors.Wrapf(err, "error unpacking StatementACL object")}
+				return
+			}
+			changes <- &WatchStatementACLData{Value: value}
+		}
+	}()
+
+	return &WatchStatementACLData{Value: value}, changes, nil
+}
@@ -99,6 +99,16 @@ func IsReplicaType(tt topodatapb.TabletType) bool {
 	return true
 }
 
+// ErrantGTIDsTag is the tag EmergencyReparentShard adds to the replicas it
+// quarantines for their errant GTIDs, with the errant GTID set as its value.
+const ErrantGTIDsTag = "errant_gtids"
+
+// IsQuarantined returns if a tablet is quarantined for its errant GTIDs: it
+// is DRAINED, and tagged with them.
+func IsQuarantined(tablet *topodatapb.Tablet) bool {
+	return tablet.Type == topodatapb.TabletType_DRAINED && tablet.Tags[ErrantGTIDsTag] != ""
+}
+
 // NewTablet create a new Tablet record with the given id, cell, and hostname.
 func NewTablet(uid uint32, cell, host string) *topodatapb.Tablet {
 	return &topodatapb.Tablet{
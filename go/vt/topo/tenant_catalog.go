@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// This file contains the utility methods to manage the vtgate TenantCatalog.
+
+// WatchTenantCatalogData is returned / streamed by WatchTenantCatalog.
+// The WatchTenantCatalog API guarantees exactly one of Value or Err will be set.
+type WatchTenantCatalogData struct {
+	Value *vtgatepb.TenantCatalog
+	Err   error
+}
+
+// SaveTenantCatalog saves the tenant catalog into the global topo. An empty
+// catalog removes it.
+func (ts *Server) SaveTenantCatalog(ctx context.Context, catalog *vtgatepb.TenantCatalog) error {
+	data, err := catalog.MarshalVT()
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		if err := ts.globalCell.Delete(ctx, TenantCatalogFile, nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+		return nil
+	}
+
+	_, err = ts.globalCell.Update(ctx, TenantCatalogFile, data, nil)
+	return err
+}
+
+// GetTenantCatalog fetches the tenant catalog from the global topo. It returns
+// an empty catalog if none was saved.
+func (ts *Server) GetTenantCatalog(ctx context.Context) (*vtgatepb.TenantCatalog, error) {
+	catalog := &vtgatepb.TenantCatalog{}
+	data, _, err := ts.globalCell.Get(ctx, TenantCatalogFile)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return catalog, nil
+		}
+		return nil, err
+	}
+	if err := catalog.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "bad tenant catalog data: %q", data)
+	}
+	return catalog, nil
+}
+
+// WatchTenantCatalog will set a watch on the tenant catalog in the global topo.
+// It has the same contract as Conn.Watch, but it also unpacks the contents
+// into a TenantCatalog object.
+func (ts *Server) WatchTenantCatalog(ctx context.Context) (*WatchTenantCatalogData, <-chan *WatchTenantCatalogData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	current, wdChannel, err := ts.globalCell.Watch(ctx, TenantCatalogFile)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	value := &vtgatepb.TenantCatalog{}
+	if err := value.UnmarshalVT(current.Contents); err != nil {
+		// Cancel the watch, drain channel.
+		cancel()
+		for range wdChannel {
+		}
+		return nil, nil, vterrors.Wrapf(err, "error unpacking initial TenantCatalog object")
+	}
+
+	changes := make(chan *WatchTenantCatalogData, 10)
+
+	// The background routine reads any event from the watch channel,
+	// translates it, and sends it to the caller.
+	// If cancel() is called, the underlying Watch() code will
+	// send an ErrInterrupted and then close the channel. We'll
+	// just propagate that back to our caller.
+	go func() {
+		defer cancel()
+		defer close(changes)
+
+		for wd := range wdChannel {
+			if wd.Err != nil {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchTenantCatalogData{Err: wd.Err}
+				return
+			}
+
+			value := &vtgatepb.TenantCatalog{}
+			if err := value.UnmarshalVT(wd.Contents); err != nil {
+				cancel()
+				for range wdChannel {
+				}
+				changes <- &WatchTenantCatalogData{Err: vterrors.Wrapf(err, "error unpacking TenantCatalog object")}
+				return
+			}
+			changes <- &WatchTenantCatalogData{Value: value}
+		}
+	}()
+
+	return &WatchTenantCatalogData{Value: value}, changes, nil
+}
@@ -29,19 +29,58 @@ import (
 
 // ParseDestination parses the string representation of a Destination
 // of the form keyspace:shard@tablet_type. You can use a / instead of a :.
+// The tablet alias of a target pinned to a tablet is ignored, see
+// ParseDestinationWithTabletAlias.
 func ParseDestination(targetString string, defaultTabletType topodatapb.TabletType) (string, topodatapb.TabletType, key.Destination, error) {
+	keyspace, tabletType, dest, _, err := ParseDestinationWithTabletAlias(targetString, defaultTabletType)
+	return keyspace, tabletType, dest, err
+}
+
+// ParseDestinationWithTabletAlias parses the string representation of a
+// Destination of the form keyspace:shard@tablet_type|tablet_alias, where the
+// tablet alias is optional. A tablet alias pins the target to this tablet, so
+// it requires a shard and a tablet type, e.g. ks:-80@replica|zone1-0000000101.
+func ParseDestinationWithTabletAlias(targetString string, defaultTabletType topodatapb.TabletType) (string, topodatapb.TabletType, key.Destination, *topodatapb.TabletAlias, error) {
 	var dest key.Destination
 	var keyspace string
+	var tabletAlias *topodatapb.TabletAlias
 	tabletType := defaultTabletType
+	target := targetString
 
 	last := strings.LastIndexAny(targetString, "@")
 	if last != -1 {
+		tabletTypeString := targetString[last+1:]
+		if pipe := strings.IndexByte(tabletTypeString, '|'); pipe != -1 {
+			var err error
+			tabletAlias, err = ParseTabletAlias(tabletTypeString[pipe+1:])
+			if err != nil {
+				return keyspace, tabletType, dest, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet alias in target %s: %v", target, err)
+			}
+			tabletTypeString = tabletTypeString[:pipe]
+		}
 		// No need to check the error. UNKNOWN will be returned on
 		// error and it will fail downstream.
-		tabletType, _ = ParseTabletType(targetString[last+1:])
+		tabletType, _ = ParseTabletType(tabletTypeString)
 		targetString = targetString[:last]
 	}
-	last = strings.LastIndexAny(targetString, "/:")
+	keyspace, dest, err := parseKeyspaceAndDestination(targetString)
+	if err != nil || tabletAlias == nil {
+		return keyspace, tabletType, dest, tabletAlias, err
+	}
+	if _, ok := dest.(key.DestinationShard); !ok {
+		return keyspace, tabletType, dest, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "target %s pinned to tablet %s must target a shard", target, TabletAliasString(tabletAlias))
+	}
+	if tabletType == topodatapb.TabletType_UNKNOWN {
+		return keyspace, tabletType, dest, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "target %s pinned to tablet %s must have a valid tablet type", target, TabletAliasString(tabletAlias))
+	}
+	return keyspace, tabletType, dest, tabletAlias, nil
+}
+
+func parseKeyspaceAndDestination(targetString string) (string, key.Destination, error) {
+	var dest key.Destination
+	var keyspace string
+
+	last := strings.LastIndexAny(targetString, "/:")
 	if last != -1 {
 		dest = key.DestinationShard(targetString[last+1:])
 		targetString = targetString[:last]
@@ -51,29 +90,29 @@ func ParseDestination(targetString string, defaultTabletType topodatapb.TabletTy
 	if last != -1 {
 		rangeEnd := strings.LastIndexAny(targetString, "]")
 		if rangeEnd == -1 {
-			return keyspace, tabletType, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid key range provided. Couldn't find range end ']'")
+			return keyspace, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid key range provided. Couldn't find range end ']'")
 		}
 		rangeString := targetString[last+1 : rangeEnd]
 		if strings.Contains(rangeString, "-") {
 			// Parse as range
 			keyRange, err := key.ParseShardingSpec(rangeString)
 			if err != nil {
-				return keyspace, tabletType, dest, err
+				return keyspace, dest, err
 			}
 			if len(keyRange) != 1 {
-				return keyspace, tabletType, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "single keyrange expected in %s", rangeString)
+				return keyspace, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "single keyrange expected in %s", rangeString)
 			}
 			dest = key.DestinationExactKeyRange{KeyRange: keyRange[0]}
 		} else {
 			// Parse as keyspace id
 			destBytes, err := hex.DecodeString(rangeString)
 			if err != nil {
-				return keyspace, tabletType, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected valid hex in keyspace id %s", rangeString)
+				return keyspace, dest, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected valid hex in keyspace id %s", rangeString)
 			}
 			dest = key.DestinationKeyspaceID(destBytes)
 		}
 		targetString = targetString[:last]
 	}
 	keyspace = targetString
-	return keyspace, tabletType, dest, nil
+	return keyspace, dest, nil
 }
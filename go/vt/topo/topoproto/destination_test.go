@@ -19,6 +19,7 @@ package topoproto
 import (
 	"encoding/hex"
 	"reflect"
+	"strings"
 	"testing"
 
 	"vitess.io/vitess/go/vt/key"
@@ -121,3 +122,51 @@ func TestParseDestination(t *testing.T) {
 		t.Errorf("executorExec error: %v, want %s", err, want)
 	}
 }
+
+func TestParseDestinationWithTabletAlias(t *testing.T) {
+	keyspace, tabletType, dest, alias, err := ParseDestinationWithTabletAlias("ks:-80@replica|zone1-0000000101", topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyspace != "ks" || tabletType != topodatapb.TabletType_REPLICA || dest != key.DestinationShard("-80") || TabletAliasString(alias) != "zone1-0000000101" {
+		t.Errorf("ParseDestinationWithTabletAlias - got: (%v, %v, %v, %v)", keyspace, tabletType, dest, alias)
+	}
+
+	// ParseDestination ignores the tablet alias.
+	keyspace, tabletType, dest, err = ParseDestination("ks/-80@rdonly|zone1-101", topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyspace != "ks" || tabletType != topodatapb.TabletType_RDONLY || dest != key.DestinationShard("-80") {
+		t.Errorf("ParseDestination - got: (%v, %v, %v)", keyspace, tabletType, dest)
+	}
+
+	// A target without a tablet alias is parsed as by ParseDestination.
+	_, _, _, alias, err = ParseDestinationWithTabletAlias("ks:-80@replica", topodatapb.TabletType_PRIMARY)
+	if err != nil || alias != nil {
+		t.Errorf("ParseDestinationWithTabletAlias - got: (%v, %v), want no tablet alias", alias, err)
+	}
+
+	testcases := []struct {
+		targetString string
+		want         string
+	}{{
+		targetString: "ks:-80@replica|zone1",
+		want:         "invalid tablet alias in target ks:-80@replica|zone1",
+	}, {
+		targetString: "ks@replica|zone1-101",
+		want:         "target ks@replica|zone1-101 pinned to tablet zone1-0000000101 must target a shard",
+	}, {
+		targetString: "ks[10-20]@replica|zone1-101",
+		want:         "target ks[10-20]@replica|zone1-101 pinned to tablet zone1-0000000101 must target a shard",
+	}, {
+		targetString: "ks:-80@|zone1-101",
+		want:         "target ks:-80@|zone1-101 pinned to tablet zone1-0000000101 must have a valid tablet type",
+	}}
+	for _, tcase := range testcases {
+		_, _, _, _, err := ParseDestinationWithTabletAlias(tcase.targetString, topodatapb.TabletType_PRIMARY)
+		if err == nil || !strings.HasPrefix(err.Error(), tcase.want) {
+			t.Errorf("ParseDestinationWithTabletAlias(%s) error: %v, want %s", tcase.targetString, err, tcase.want)
+		}
+	}
+}
@@ -72,6 +72,17 @@ func IsTabletInList(tablet *topodatapb.Tablet, allTablets []*topodatapb.Tablet)
 	return false
 }
 
+// TabletHasTags returns true if the tablet carries all of the given tags, with
+// the same values.
+func TabletHasTags(tablet *topodatapb.Tablet, tags map[string]string) bool {
+	for key, val := range tags {
+		if tabletVal, found := tablet.Tags[key]; !found || tabletVal != val {
+			return false
+		}
+	}
+	return true
+}
+
 // TabletAliasString formats a TabletAlias
 func TabletAliasString(ta *topodatapb.TabletAlias) string {
 	if ta == nil {
@@ -29,8 +29,14 @@ import (
 )
 
 // SaveVSchema saves a Vschema. A valid Vschema should be passed in. It does not verify its correctness.
-// If the VSchema is empty, just remove it.
+// The Vschema is recorded in the vschema history of the keyspace, see SaveVSchemaVersion.
 func (ts *Server) SaveVSchema(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace) error {
+	_, err := ts.saveVSchema(ctx, keyspace, vschema, VSchemaChange{})
+	return err
+}
+
+// writeVSchema writes the Vschema of a keyspace, without recording it.
+func (ts *Server) writeVSchema(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace) error {
 	nodePath := path.Join(KeyspacesPath, keyspace, VSchemaFile)
 	data, err := vschema.MarshalVT()
 	if err != nil {
@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/events"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// VSchemaHistorySize is the number of versions kept in the vschema history of
+// a keyspace. The oldest versions are pruned when a new one is recorded.
+var VSchemaHistorySize = 100
+
+// vschemaVersionRetries is the number of times recording a version is retried
+// when another one is recorded concurrently.
+const vschemaVersionRetries = 5
+
+// VSchemaChange describes a change of the vschema of a keyspace, recorded with
+// the new version in its vschema history.
+type VSchemaChange struct {
+	Author  string
+	Comment string
+	// RolledBackTo is the version restored by the change, if it is a rollback.
+	RolledBackTo int64
+}
+
+func vschemaHistoryPath(keyspace string) string {
+	return path.Join(KeyspacesPath, keyspace, VSchemaHistoryPath)
+}
+
+func vschemaVersionPath(keyspace string, version int64) string {
+	// The versions are zero-padded so that they sort lexically.
+	return path.Join(vschemaHistoryPath(keyspace), fmt.Sprintf("%020d", version))
+}
+
+// SaveVSchemaVersion saves the vschema of a keyspace, like SaveVSchema, with
+// the author and comment of the change recorded in its vschema history. It
+// takes the keyspace lock, unless ctx holds it already, so that the versions
+// are recorded in the order the vschemas are saved. The version is nil if the
+// vschema was saved, but could not be recorded.
+func (ts *Server) SaveVSchemaVersion(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace, change VSchemaChange) (*vschemapb.VSchemaVersion, error) {
+	if CheckKeyspaceLocked(ctx, keyspace) != nil {
+		lockCtx, unlock, err := ts.LockKeyspace(ctx, keyspace, "SaveVSchemaVersion")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			// The vschema is saved already, so failing to release the lock
+			// does not fail the change.
+			var unlockErr error
+			unlock(&unlockErr)
+			if unlockErr != nil {
+				log.Warningf("failed to unlock keyspace %s after saving its vschema: %v", keyspace, unlockErr)
+			}
+		}()
+		ctx = lockCtx
+	}
+	return ts.saveVSchema(ctx, keyspace, vschema, change)
+}
+
+// saveVSchema saves the vschema of a keyspace and records it as the next
+// version of the vschema history of the keyspace. Failing to record it is only
+// logged, as the vschema is saved already; the version is nil then.
+func (ts *Server) saveVSchema(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace, change VSchemaChange) (*vschemapb.VSchemaVersion, error) {
+	previous, err := ts.GetVSchema(ctx, keyspace)
+	if err != nil && !IsErrType(err, NoNode) {
+		return nil, err
+	}
+	if err := ts.writeVSchema(ctx, keyspace, vschema); err != nil {
+		return nil, err
+	}
+
+	version, err := ts.recordVSchemaChange(ctx, keyspace, previous, vschema, change)
+	if err != nil {
+		log.Warningf("vschema of keyspace %s was saved, but not recorded in its history: %v", keyspace, err)
+	}
+	event.Dispatch(&events.VSchemaChange{
+		KeyspaceName: keyspace,
+		Version:      version.GetVersion(),
+		Author:       change.Author,
+		Comment:      change.Comment,
+		Diff:         DiffVSchemas(previous, vschema),
+	})
+	return version, nil
+}
+
+// recordVSchemaChange records vschema as the next version of the vschema
+// history of the keyspace. If the history is empty, the previous vschema it
+// replaced is recorded first, so that the change can be rolled back.
+func (ts *Server) recordVSchemaChange(ctx context.Context, keyspace string, previous, vschema *vschemapb.Keyspace, change VSchemaChange) (*vschemapb.VSchemaVersion, error) {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 && previous != nil {
+		if _, err := ts.recordVSchemaVersion(ctx, keyspace, &vschemapb.VSchemaVersion{
+			Vschema: previous,
+			Comment: "vschema before the history was recorded",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := ts.recordVSchemaVersion(ctx, keyspace, &vschemapb.VSchemaVersion{
+		Vschema:      vschema,
+		Author:       change.Author,
+		Comment:      change.Comment,
+		Time:         protoutil.TimeToProto(time.Now()),
+		RolledBackTo: change.RolledBackTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ts.pruneVSchemaHistory(ctx, keyspace)
+	return version, nil
+}
+
+// recordVSchemaVersion records version as the next version of the vschema
+// history of the keyspace, and returns it with its number set.
+func (ts *Server) recordVSchemaVersion(ctx context.Context, keyspace string, version *vschemapb.VSchemaVersion) (*vschemapb.VSchemaVersion, error) {
+	for attempt := 0; ; attempt++ {
+		versions, err := ts.listVSchemaVersions(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		version.Version = 1
+		if len(versions) > 0 {
+			version.Version = versions[len(versions)-1] + 1
+		}
+		data, err := version.MarshalVT()
+		if err != nil {
+			return nil, err
+		}
+		_, err = ts.globalCell.Create(ctx, vschemaVersionPath(keyspace, version.Version), data)
+		if IsErrType(err, NodeExists) && attempt < vschemaVersionRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return version, nil
+	}
+}
+
+// pruneVSchemaHistory deletes the oldest versions of the vschema history of
+// the keyspace beyond VSchemaHistorySize. Errors are only logged, as they leave
+// the history longer than needed.
+func (ts *Server) pruneVSchemaHistory(ctx context.Context, keyspace string) {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		log.Warningf("failed to prune vschema history of keyspace %s: %v", keyspace, err)
+		return
+	}
+	for len(versions) > VSchemaHistorySize {
+		if err := ts.globalCell.Delete(ctx, vschemaVersionPath(keyspace, versions[0]), nil); err != nil && !IsErrType(err, NoNode) {
+			log.Warningf("failed to prune vschema history of keyspace %s: %v", keyspace, err)
+			return
+		}
+		versions = versions[1:]
+	}
+}
+
+// listVSchemaVersions returns the numbers of the versions of the vschema
+// history of the keyspace, in ascending order.
+func (ts *Server) listVSchemaVersions(ctx context.Context, keyspace string) ([]int64, error) {
+	entries, err := ts.globalCell.ListDir(ctx, vschemaHistoryPath(keyspace), false /*full*/)
+	if IsErrType(err, NoNode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		version, err := strconv.ParseInt(entry.Name, 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+// GetVSchemaHistory returns the versions of the vschema history of the
+// keyspace, in ascending order.
+func (ts *Server) GetVSchemaHistory(ctx context.Context, keyspace string) ([]*vschemapb.VSchemaVersion, error) {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*vschemapb.VSchemaVersion, 0, len(versions))
+	for _, v := range versions {
+		version, err := ts.GetVSchemaVersion(ctx, keyspace, v)
+		if IsErrType(err, NoNode) {
+			// Pruned since it was listed.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, version)
+	}
+	return history, nil
+}
+
+// GetVSchemaVersion returns a version of the vschema history of the keyspace.
+func (ts *Server) GetVSchemaVersion(ctx context.Context, keyspace string, version int64) (*vschemapb.VSchemaVersion, error) {
+	data, _, err := ts.globalCell.Get(ctx, vschemaVersionPath(keyspace, version))
+	if err != nil {
+		return nil, err
+	}
+	v := &vschemapb.VSchemaVersion{}
+	if err := v.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "bad vschema version data: %q", data)
+	}
+	return v, nil
+}
+
+// DeleteVSchemaHistory deletes the vschema history of the keyspace.
+func (ts *Server) DeleteVSchemaHistory(ctx context.Context, keyspace string) error {
+	versions, err := ts.listVSchemaVersions(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := ts.globalCell.Delete(ctx, vschemaVersionPath(keyspace, version), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffVSchemas returns the changes from one vschema of a keyspace to another,
+// one per line: the vindexes and tables added (+), removed (-) and changed (~),
+// and the change of the other settings of the keyspace. A nil vschema is
+// empty.
+func DiffVSchemas(from, to *vschemapb.Keyspace) string {
+	var lines []string
+
+	fromSettings, toSettings := keyspaceSettings(from), keyspaceSettings(to)
+	if !proto.Equal(fromSettings, toSettings) {
+		lines = append(lines, fmt.Sprintf("~ keyspace: %s -> %s", marshalDiffValue(fromSettings), marshalDiffValue(toSettings)))
+	}
+	lines = append(lines, diffVSchemaMaps("vindex", from.GetVindexes(), to.GetVindexes())...)
+	lines = append(lines, diffVSchemaMaps("table", from.GetTables(), to.GetTables())...)
+	return strings.Join(lines, "\n")
+}
+
+// keyspaceSettings returns the vschema without its vindexes and tables.
+func keyspaceSettings(vschema *vschemapb.Keyspace) *vschemapb.Keyspace {
+	settings := vschema.CloneVT()
+	if settings == nil {
+		return &vschemapb.Keyspace{}
+	}
+	settings.Vindexes = nil
+	settings.Tables = nil
+	return settings
+}
+
+func diffVSchemaMaps[T proto.Message](kind string, from, to map[string]T) []string {
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var lines []string
+	for _, name := range names {
+		f, inFrom := from[name]
+		t, inTo := to[name]
+		switch {
+		case !inFrom:
+			lines = append(lines, fmt.Sprintf("+ %s %s: %s", kind, name, marshalDiffValue(t)))
+		case !inTo:
+			lines = append(lines, fmt.Sprintf("- %s %s: %s", kind, name, marshalDiffValue(f)))
+		case !proto.Equal(f, t):
+			lines = append(lines, fmt.Sprintf("~ %s %s: %s -> %s", kind, name, marshalDiffValue(f), marshalDiffValue(t)))
+		}
+	}
+	return lines
+}
+
+func marshalDiffValue(m proto.Message) string {
+	data, err := json2.MarshalPB(m)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestVSchemaHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	defer func(size int) { topo.VSchemaHistorySize = size }(topo.VSchemaHistorySize)
+	topo.VSchemaHistorySize = 3
+
+	const keyspace = "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	initial := &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}}}
+	// The vschemas saved by SaveVSchema are recorded too.
+	require.NoError(t, ts.SaveVSchema(ctx, keyspace, initial))
+
+	v, err := ts.SaveVSchemaVersion(ctx, keyspace, &vschemapb.Keyspace{}, topo.VSchemaChange{Author: "alice", Comment: "drop t1"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, v.Version)
+	history, err := ts.GetVSchemaHistory(ctx, keyspace)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.EqualValues(t, 1, history[0].Version)
+	assert.True(t, proto.Equal(initial, history[0].Vschema))
+	assert.Equal(t, "alice", history[1].Author)
+	assert.Equal(t, "drop t1", history[1].Comment)
+	assert.NotNil(t, history[1].Time)
+
+	// The oldest versions are pruned.
+	for i := 0; i < 3; i++ {
+		_, err := ts.SaveVSchemaVersion(ctx, keyspace, &vschemapb.Keyspace{Sharded: i%2 == 0}, topo.VSchemaChange{})
+		require.NoError(t, err)
+	}
+	history, err = ts.GetVSchemaHistory(ctx, keyspace)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.EqualValues(t, 3, history[0].Version)
+	assert.EqualValues(t, 5, history[2].Version)
+	_, err = ts.GetVSchemaVersion(ctx, keyspace, 1)
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+
+	// The history is deleted with the keyspace.
+	require.NoError(t, ts.DeleteKeyspace(ctx, keyspace))
+	history, err = ts.GetVSchemaHistory(ctx, keyspace)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestVSchemaHistoryNotRecorded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	const keyspace = "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	vschema := &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}}}
+
+	// A vschema is saved even if it cannot be recorded in the history.
+	factory.AddOperationError(memorytopo.Create, "vschema_history", errors.New("history unavailable"))
+	require.NoError(t, ts.SaveVSchema(ctx, keyspace, vschema))
+	v, err := ts.SaveVSchemaVersion(ctx, keyspace, vschema, topo.VSchemaChange{})
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	saved, err := ts.GetVSchema(ctx, keyspace)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(vschema, saved))
+	history, err := ts.GetVSchemaHistory(ctx, keyspace)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestVSchemaHistoryFirstVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	const keyspace = "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	initial := &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}}}
+	require.NoError(t, ts.SaveVSchema(ctx, keyspace, initial))
+	require.NoError(t, ts.DeleteVSchemaHistory(ctx, keyspace))
+
+	// The keyspace lock held by the caller is used.
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, keyspace, "TestVSchemaHistoryFirstVersion")
+	require.NoError(t, err)
+	v, err := ts.SaveVSchemaVersion(lockCtx, keyspace, &vschemapb.Keyspace{}, topo.VSchemaChange{Author: "alice"})
+	require.NoError(t, err)
+	unlock(&err)
+	require.NoError(t, err)
+
+	// The vschema saved before the history is recorded as its first version.
+	assert.EqualValues(t, 2, v.Version)
+	history, err := ts.GetVSchemaHistory(ctx, keyspace)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.True(t, proto.Equal(initial, history[0].Vschema))
+	assert.Equal(t, "alice", history[1].Author)
+}
+
+func TestDiffVSchemas(t *testing.T) {
+	from := &vschemapb.Keyspace{
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
+			"t2": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+		},
+	}
+	to := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"xxh":  {Type: "xxhash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t2": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "xxh"}}},
+			"t3": {},
+		},
+	}
+
+	var got []string
+	for _, line := range strings.Split(topo.DiffVSchemas(from, to), "\n") {
+		prefix, _, _ := strings.Cut(line, ":")
+		got = append(got, prefix)
+	}
+	assert.Equal(t, []string{"~ keyspace", "+ vindex xxh", "- table t1", "~ table t2", "+ table t3"}, got)
+
+	assert.Empty(t, topo.DiffVSchemas(from, from.CloneVT()))
+	assert.Equal(t, "+ table t1: {}", topo.DiffVSchemas(nil, &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}}}))
+}
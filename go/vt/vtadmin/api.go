@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"net/url"
+	"regexp"
 	stdsort "sort"
 	"strings"
 	"sync"
@@ -397,6 +398,7 @@ func (api *API) Handler() http.Handler {
 	router.HandleFunc("/srvkeyspace/{cluster_id}/{name}", httpAPI.Adapt(vtadminhttp.GetSrvKeyspace)).Name("API.GetSrvKeyspace").Methods("GET")
 	router.HandleFunc("/srvvschema/{cluster_id}/{cell}", httpAPI.Adapt(vtadminhttp.GetSrvVSchema)).Name("API.GetSrvVSchema")
 	router.HandleFunc("/srvvschemas", httpAPI.Adapt(vtadminhttp.GetSrvVSchemas)).Name("API.GetSrvVSchemas")
+	router.HandleFunc("/tables", httpAPI.Adapt(vtadminhttp.FindTables)).Name("API.FindTables")
 	router.HandleFunc("/tablets", httpAPI.Adapt(vtadminhttp.GetTablets)).Name("API.GetTablets")
 	router.HandleFunc("/tablet/{tablet}", httpAPI.Adapt(vtadminhttp.GetTablet)).Name("API.GetTablet").Methods("GET")
 	router.HandleFunc("/tablet/{tablet}", httpAPI.Adapt(vtadminhttp.DeleteTablet)).Name("API.DeleteTablet").Methods("DELETE", "OPTIONS")
@@ -715,6 +717,146 @@ func (api *API) FindSchema(ctx context.Context, req *vtadminpb.FindSchemaRequest
 	}
 }
 
+// FindTables is part of the vtadminpb.VTAdminServer interface.
+func (api *API) FindTables(ctx context.Context, req *vtadminpb.FindTablesRequest) (*vtadminpb.FindTablesResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.FindTables")
+	defer span.Finish()
+
+	span.Annotate("filter", req.Filter)
+	span.Annotate("keyspaces", strings.Join(req.Keyspaces, ","))
+	span.Annotate("include_non_serving_shards", req.IncludeNonServingShards)
+
+	match, err := tableNameMatcher(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	keyspaces := sets.New[string](req.Keyspaces...)
+	clusters, _ := api.getClustersForRequest(req.ClusterIds)
+
+	var (
+		m      sync.Mutex
+		wg     sync.WaitGroup
+		rec    concurrency.AllErrorRecorder
+		tables []*vtadminpb.Table
+	)
+
+	for _, c := range clusters {
+		if !api.authz.IsAuthorized(ctx, c.ID, rbac.SchemaResource, rbac.GetAction) {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(c *cluster.Cluster) {
+			defer wg.Done()
+
+			// Request the full payload (rather than sizes only), so that we
+			// share cache entries with GetSchemas requests from the UI.
+			schemas, err := c.GetSchemas(ctx, cluster.GetSchemaOptions{
+				BaseRequest: &vtctldatapb.GetSchemaRequest{
+					IncludeViews: true,
+				},
+				TableSizeOptions: &vtadminpb.GetSchemaTableSizeOptions{
+					AggregateSizes:          true,
+					IncludeNonServingShards: req.IncludeNonServingShards,
+				},
+			})
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			var ts []*vtadminpb.Table
+			for _, schema := range schemas {
+				if keyspaces.Len() > 0 && !keyspaces.Has(schema.Keyspace) {
+					continue
+				}
+
+				for _, td := range schema.TableDefinitions {
+					if !match(td.Name) {
+						continue
+					}
+
+					table := &vtadminpb.Table{
+						Cluster:  schema.Cluster,
+						Keyspace: schema.Keyspace,
+						Name:     td.Name,
+						Type:     td.Type,
+					}
+
+					if size, ok := schema.TableSizes[td.Name]; ok {
+						table.RowCount = size.RowCount
+						table.DataLength = size.DataLength
+						table.IndexLength = size.IndexLength
+						table.ByShard = size.ByShard
+					}
+
+					ts = append(ts, table)
+				}
+			}
+
+			m.Lock()
+			defer m.Unlock()
+
+			tables = append(tables, ts...)
+		}(c)
+	}
+
+	wg.Wait()
+
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	stdsort.Slice(tables, func(i, j int) bool {
+		left, right := tables[i], tables[j]
+
+		leftSize, rightSize := left.DataLength+left.IndexLength, right.DataLength+right.IndexLength
+		if leftSize != rightSize {
+			return leftSize > rightSize
+		}
+
+		if left.Cluster.Id != right.Cluster.Id {
+			return left.Cluster.Id < right.Cluster.Id
+		}
+
+		if left.Keyspace != right.Keyspace {
+			return left.Keyspace < right.Keyspace
+		}
+
+		return left.Name < right.Name
+	})
+
+	return &vtadminpb.FindTablesResponse{
+		Tables: tables,
+	}, nil
+}
+
+// tableNameMatcher returns a function matching table names against the given
+// FindTables filter. Filters wrapped in slashes are compiled as regular
+// expressions, anything else is matched as a substring. Both are
+// case-insensitive.
+func tableNameMatcher(filter string) (func(name string) bool, error) {
+	if filter == "" {
+		return func(string) bool { return true }, nil
+	}
+
+	if len(filter) > 2 && strings.HasPrefix(filter, "/") && strings.HasSuffix(filter, "/") {
+		re, err := regexp.Compile("(?i)" + filter[1:len(filter)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid table filter %s: %s", errors.ErrInvalidRequest, filter, err)
+		}
+
+		return re.MatchString, nil
+	}
+
+	filter = strings.ToLower(filter)
+	return func(name string) bool {
+		return strings.Contains(strings.ToLower(name), filter)
+	}, nil
+}
+
 // GetBackups is part of the vtadminpb.VTAdminServer interface.
 func (api *API) GetBackups(ctx context.Context, req *vtadminpb.GetBackupsRequest) (*vtadminpb.GetBackupsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "API.GetBackups")
@@ -803,6 +803,110 @@ func TestFindSchema(t *testing.T) {
 	})
 }
 
+func TestFindTables(t *testing.T) {
+	t.Parallel()
+
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Schema",
+					Actions:  []string{"get"},
+					Subjects: []string{"user:allowed-all"},
+					Clusters: []string{"*"},
+				},
+				{
+					Resource: "Schema",
+					Actions:  []string{"get"},
+					Subjects: []string{"user:allowed-other"},
+					Clusters: []string{"other"},
+				},
+			},
+		},
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	t.Run("unauthorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+		t.Cleanup(func() {
+			if err := api.Close(); err != nil {
+				t.Logf("api did not close cleanly: %s", err.Error())
+			}
+		})
+
+		actor := &rbac.Actor{Name: "unauthorized"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, err := api.FindTables(ctx, &vtadminpb.FindTablesRequest{
+			Filter:                  "t1",
+			IncludeNonServingShards: true,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Tables, "actor %+v should not be permitted to FindTables", actor)
+	})
+
+	t.Run("partial access", func(t *testing.T) {
+		t.Parallel()
+
+		api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+		t.Cleanup(func() {
+			if err := api.Close(); err != nil {
+				t.Logf("api did not close cleanly: %s", err.Error())
+			}
+		})
+
+		actor := &rbac.Actor{Name: "allowed-other"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, _ := api.FindTables(ctx, &vtadminpb.FindTablesRequest{
+			Filter:                  "t1",
+			IncludeNonServingShards: true,
+		})
+		assert.NotEmpty(t, resp.Tables, "actor %+v should be permitted to FindTables", actor)
+		clusterIDs := make([]string, 0, len(resp.Tables))
+		for _, table := range resp.Tables {
+			clusterIDs = append(clusterIDs, table.Cluster.Id)
+		}
+		assert.ElementsMatch(t, clusterIDs, []string{"other"}, "actor %+v should be permitted to FindTables", actor)
+	})
+
+	t.Run("full access", func(t *testing.T) {
+		t.Parallel()
+
+		api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+		t.Cleanup(func() {
+			if err := api.Close(); err != nil {
+				t.Logf("api did not close cleanly: %s", err.Error())
+			}
+		})
+
+		actor := &rbac.Actor{Name: "allowed-all"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, _ := api.FindTables(ctx, &vtadminpb.FindTablesRequest{
+			Filter:                  "t1",
+			IncludeNonServingShards: true,
+		})
+		assert.NotEmpty(t, resp.Tables, "actor %+v should be permitted to FindTables", actor)
+		clusterIDs := make([]string, 0, len(resp.Tables))
+		for _, table := range resp.Tables {
+			clusterIDs = append(clusterIDs, table.Cluster.Id)
+		}
+		assert.ElementsMatch(t, clusterIDs, []string{"test", "other"}, "actor %+v should be permitted to FindTables", actor)
+	})
+}
+
 func TestGetBackups(t *testing.T) {
 	t.Parallel()
 
@@ -3771,6 +3875,7 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 							Uid:  100,
 						},
 						Keyspace: "test",
+						Shard:    "-",
 						Type:     topodatapb.TabletType_REPLICA,
 					},
 					State: vtadminpb.Tablet_SERVING,
@@ -3932,6 +4037,7 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 							Uid:  100,
 						},
 						Keyspace: "otherks",
+						Shard:    "-",
 						Type:     topodatapb.TabletType_UNKNOWN,
 					},
 					State: vtadminpb.Tablet_SERVING,
@@ -817,6 +817,362 @@ func TestFindSchema(t *testing.T) {
 	})
 }
 
+func TestFindTables(t *testing.T) {
+	t.Parallel()
+
+	c1pb := &vtadminpb.Cluster{
+		Id:   "c1",
+		Name: "cluster1",
+	}
+	c2pb := &vtadminpb.Cluster{
+		Id:   "c2",
+		Name: "cluster2",
+	}
+
+	clusters := func(t *testing.T) []*cluster.Cluster {
+		c1 := vtadmintestutil.BuildCluster(t, vtadmintestutil.TestClusterConfig{
+			Cluster: c1pb,
+			VtctldClient: &fakevtctldclient.VtctldClient{
+				FindAllShardsInKeyspaceResults: map[string]struct {
+					Response *vtctldatapb.FindAllShardsInKeyspaceResponse
+					Error    error
+				}{
+					"testkeyspace": {
+						Response: &vtctldatapb.FindAllShardsInKeyspaceResponse{
+							Shards: map[string]*vtctldatapb.Shard{
+								"-80": {
+									Keyspace: "testkeyspace",
+									Name:     "-80",
+									Shard: &topodatapb.Shard{
+										IsPrimaryServing: true,
+										PrimaryAlias: &topodatapb.TabletAlias{
+											Cell: "c1zone1",
+											Uid:  100,
+										},
+									},
+								},
+								"80-": {
+									Keyspace: "testkeyspace",
+									Name:     "80-",
+									Shard: &topodatapb.Shard{
+										IsPrimaryServing: true,
+										PrimaryAlias: &topodatapb.TabletAlias{
+											Cell: "c1zone1",
+											Uid:  200,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				GetKeyspacesResults: &struct {
+					Keyspaces []*vtctldatapb.Keyspace
+					Error     error
+				}{
+					Keyspaces: []*vtctldatapb.Keyspace{
+						{Name: "testkeyspace"},
+					},
+				},
+				GetSchemaResults: map[string]struct {
+					Response *vtctldatapb.GetSchemaResponse
+					Error    error
+				}{
+					"c1zone1-0000000100": {
+						Response: &vtctldatapb.GetSchemaResponse{
+							Schema: &tabletmanagerdatapb.SchemaDefinition{
+								TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+									{
+										Name:       "orders",
+										Type:       "BASE TABLE",
+										RowCount:   5,
+										DataLength: 1000,
+									},
+									{
+										Name:        "users",
+										Type:        "BASE TABLE",
+										RowCount:    10,
+										DataLength:  100,
+										IndexLength: 50,
+									},
+								},
+							},
+						},
+					},
+					"c1zone1-0000000200": {
+						Response: &vtctldatapb.GetSchemaResponse{
+							Schema: &tabletmanagerdatapb.SchemaDefinition{
+								TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+									{
+										Name:       "orders",
+										Type:       "BASE TABLE",
+										RowCount:   5,
+										DataLength: 1000,
+									},
+									{
+										Name:        "users",
+										Type:        "BASE TABLE",
+										RowCount:    20,
+										DataLength:  200,
+										IndexLength: 50,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Tablets: []*vtadminpb.Tablet{
+				{
+					Cluster: c1pb,
+					Tablet: &topodatapb.Tablet{
+						Alias: &topodatapb.TabletAlias{
+							Cell: "c1zone1",
+							Uid:  100,
+						},
+						Keyspace: "testkeyspace",
+						Shard:    "-80",
+					},
+					State: vtadminpb.Tablet_SERVING,
+				},
+				{
+					Cluster: c1pb,
+					Tablet: &topodatapb.Tablet{
+						Alias: &topodatapb.TabletAlias{
+							Cell: "c1zone1",
+							Uid:  200,
+						},
+						Keyspace: "testkeyspace",
+						Shard:    "80-",
+					},
+					State: vtadminpb.Tablet_SERVING,
+				},
+			},
+		})
+		c2 := vtadmintestutil.BuildCluster(t, vtadmintestutil.TestClusterConfig{
+			Cluster: c2pb,
+			VtctldClient: &fakevtctldclient.VtctldClient{
+				FindAllShardsInKeyspaceResults: map[string]struct {
+					Response *vtctldatapb.FindAllShardsInKeyspaceResponse
+					Error    error
+				}{
+					"ks2": {
+						Response: &vtctldatapb.FindAllShardsInKeyspaceResponse{
+							Shards: map[string]*vtctldatapb.Shard{
+								"-": {
+									Keyspace: "ks2",
+									Name:     "-",
+									Shard: &topodatapb.Shard{
+										IsPrimaryServing: true,
+										PrimaryAlias: &topodatapb.TabletAlias{
+											Cell: "c2z1",
+											Uid:  100,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				GetKeyspacesResults: &struct {
+					Keyspaces []*vtctldatapb.Keyspace
+					Error     error
+				}{
+					Keyspaces: []*vtctldatapb.Keyspace{
+						{Name: "ks2"},
+					},
+				},
+				GetSchemaResults: map[string]struct {
+					Response *vtctldatapb.GetSchemaResponse
+					Error    error
+				}{
+					"c2z1-0000000100": {
+						Response: &vtctldatapb.GetSchemaResponse{
+							Schema: &tabletmanagerdatapb.SchemaDefinition{
+								TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+									{
+										Name:        "users",
+										Type:        "BASE TABLE",
+										RowCount:    1,
+										DataLength:  16,
+										IndexLength: 16,
+									},
+									{
+										Name: "users_view",
+										Type: "VIEW",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Tablets: []*vtadminpb.Tablet{
+				{
+					Cluster: c2pb,
+					Tablet: &topodatapb.Tablet{
+						Alias: &topodatapb.TabletAlias{
+							Cell: "c2z1",
+							Uid:  100,
+						},
+						Keyspace: "ks2",
+						Shard:    "-",
+					},
+					State: vtadminpb.Tablet_SERVING,
+				},
+			},
+		})
+
+		return []*cluster.Cluster{c1, c2}
+	}
+
+	orders := &vtadminpb.Table{
+		Cluster:    c1pb,
+		Keyspace:   "testkeyspace",
+		Name:       "orders",
+		Type:       "BASE TABLE",
+		RowCount:   10,
+		DataLength: 2000,
+		ByShard: map[string]*vtadminpb.Schema_ShardTableSize{
+			"-80": {
+				RowCount:   5,
+				DataLength: 1000,
+			},
+			"80-": {
+				RowCount:   5,
+				DataLength: 1000,
+			},
+		},
+	}
+	c1Users := &vtadminpb.Table{
+		Cluster:     c1pb,
+		Keyspace:    "testkeyspace",
+		Name:        "users",
+		Type:        "BASE TABLE",
+		RowCount:    30,
+		DataLength:  300,
+		IndexLength: 100,
+		ByShard: map[string]*vtadminpb.Schema_ShardTableSize{
+			"-80": {
+				RowCount:    10,
+				DataLength:  100,
+				IndexLength: 50,
+			},
+			"80-": {
+				RowCount:    20,
+				DataLength:  200,
+				IndexLength: 50,
+			},
+		},
+	}
+	c2Users := &vtadminpb.Table{
+		Cluster:     c2pb,
+		Keyspace:    "ks2",
+		Name:        "users",
+		Type:        "BASE TABLE",
+		RowCount:    1,
+		DataLength:  16,
+		IndexLength: 16,
+		ByShard: map[string]*vtadminpb.Schema_ShardTableSize{
+			"-": {
+				RowCount:    1,
+				DataLength:  16,
+				IndexLength: 16,
+			},
+		},
+	}
+	c2UsersView := &vtadminpb.Table{
+		Cluster:  c2pb,
+		Keyspace: "ks2",
+		Name:     "users_view",
+		Type:     "VIEW",
+		ByShard: map[string]*vtadminpb.Schema_ShardTableSize{
+			"-": {},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		req       *vtadminpb.FindTablesRequest
+		expected  []*vtadminpb.Table
+		shouldErr bool
+	}{
+		{
+			name:     "all tables ordered by size",
+			req:      &vtadminpb.FindTablesRequest{},
+			expected: []*vtadminpb.Table{orders, c1Users, c2Users, c2UsersView},
+		},
+		{
+			name: "substring filter",
+			req: &vtadminpb.FindTablesRequest{
+				Filter: "USER",
+			},
+			expected: []*vtadminpb.Table{c1Users, c2Users, c2UsersView},
+		},
+		{
+			name: "regexp filter",
+			req: &vtadminpb.FindTablesRequest{
+				Filter: "/^users$/",
+			},
+			expected: []*vtadminpb.Table{c1Users, c2Users},
+		},
+		{
+			name: "filtered by keyspace",
+			req: &vtadminpb.FindTablesRequest{
+				Keyspaces: []string{"ks2"},
+			},
+			expected: []*vtadminpb.Table{c2Users, c2UsersView},
+		},
+		{
+			name: "filtered by cluster ID",
+			req: &vtadminpb.FindTablesRequest{
+				ClusterIds: []string{"c1"},
+				Filter:     "users",
+			},
+			expected: []*vtadminpb.Table{c1Users},
+		},
+		{
+			name: "no matches",
+			req: &vtadminpb.FindTablesRequest{
+				Filter: "nonexistent",
+			},
+			expected: nil,
+		},
+		{
+			name: "invalid regexp filter",
+			req: &vtadminpb.FindTablesRequest{
+				Filter: "/(/",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := NewAPI(vtenv.NewTestEnv(), clusters(t), Options{})
+			defer api.Close()
+
+			resp, err := api.FindTables(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			expected := &vtadminpb.FindTablesResponse{
+				Tables: tt.expected,
+			}
+
+			assert.NoError(t, err)
+			assert.Truef(t, proto.Equal(expected, resp), "expected %v, got %v", expected, resp)
+		})
+	}
+}
+
 func TestGetClusters(t *testing.T) {
 	t.Parallel()
 
@@ -572,6 +572,7 @@ func (c *Cluster) EmergencyFailoverShard(ctx context.Context, req *vtctldatapb.E
 	span.Annotate("ignore_replicas", strings.Join(topoproto.TabletAliasList(req.IgnoreReplicas).ToStringSlice(), ","))
 	span.Annotate("prevent_cross_cell_promotion", req.PreventCrossCellPromotion)
 	span.Annotate("wait_for_all_tablets", req.WaitForAllTablets)
+	span.Annotate("quarantine_errant_replicas", req.QuarantineErrantReplicas)
 
 	if d, ok, err := protoutil.DurationFromProto(req.WaitReplicasTimeout); ok && err == nil {
 		span.Annotate("wait_replicas_timeout", d.String())
@@ -1745,10 +1746,12 @@ func (c *Cluster) getSchemaFromTablets(ctx context.Context, keyspace string, tab
 
 				tableSize.RowCount += td.RowCount
 				tableSize.DataLength += td.DataLength
+				tableSize.IndexLength += td.IndexLength
 
 				tableSize.ByShard[tablet.Tablet.Shard] = &vtadminpb.Schema_ShardTableSize{
-					RowCount:   td.RowCount,
-					DataLength: td.DataLength,
+					RowCount:    td.RowCount,
+					DataLength:  td.DataLength,
+					IndexLength: td.IndexLength,
 				}
 			}
 		}(tablet, sizesOnly)
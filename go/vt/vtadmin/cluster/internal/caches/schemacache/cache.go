@@ -202,9 +202,10 @@ func loadSchema(cachedSchema *vtadminpb.Schema, opts LoadOptions) *vtadminpb.Sch
 
 		if opts.BaseRequest.TableSizesOnly {
 			tables = append(tables, &tabletmanagerdatapb.TableDefinition{
-				Name:       td.Name,
-				DataLength: td.DataLength,
-				RowCount:   td.RowCount,
+				Name:        td.Name,
+				DataLength:  td.DataLength,
+				IndexLength: td.IndexLength,
+				RowCount:    td.RowCount,
 			})
 			continue
 		}
@@ -46,6 +46,26 @@ func FindSchema(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(schema, err)
 }
 
+// FindTables implements the http wrapper for the
+// /tables[?cluster_id=[&cluster_id=]][?keyspace=[&keyspace=]][?filter=] route.
+func FindTables(ctx context.Context, r Request, api *API) *JSONResponse {
+	query := r.URL.Query()
+
+	includeNonServingShards, err := r.ParseQueryParamAsBool("include_non_serving_shards", false)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	resp, err := api.server.FindTables(ctx, &vtadminpb.FindTablesRequest{
+		ClusterIds:              query["cluster_id"],
+		Keyspaces:               query["keyspace"],
+		Filter:                  query.Get("filter"),
+		IncludeNonServingShards: includeNonServingShards,
+	})
+
+	return NewJSONResponse(resp, err)
+}
+
 // GetSchema implements the http wrapper for the
 // /schema/{cluster_id}/{keyspace}/{table} route.
 func GetSchema(ctx context.Context, r Request, api *API) *JSONResponse {
@@ -156,6 +156,7 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 							Uid: {{ .Tablet.Alias.Uid }},
 						},
 						Keyspace: "{{ .Tablet.Keyspace }}",
+						Shard: "{{ .Tablet.Shard }}",
 						Type: topodatapb.TabletType_{{ .Tablet.Type }},
 					},
 					State: vtadminpb.Tablet_{{ .State }},
@@ -536,6 +536,18 @@ func (itc *internalTabletConn) ReadTransaction(ctx context.Context, target *quer
 	return metadata, tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
 }
 
+// TransactionLockWaits is part of queryservice.QueryService
+func (itc *internalTabletConn) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	lockWaits, err := itc.tablet.qsc.QueryService().TransactionLockWaits(ctx, target)
+	return lockWaits, tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
+}
+
+// AbortTransaction is part of queryservice.QueryService
+func (itc *internalTabletConn) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	err := itc.tablet.qsc.QueryService().AbortTransaction(ctx, target, transactionID, reason)
+	return tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
+}
+
 // BeginExecute is part of queryservice.QueryService
 func (itc *internalTabletConn) BeginExecute(
 	ctx context.Context,
@@ -714,6 +726,18 @@ func (itc *internalTabletConn) VStreamResults(
 	return tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
 }
 
+// SaveVStreamPosition is part of the QueryService interface.
+func (itc *internalTabletConn) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	err := itc.tablet.qsc.QueryService().SaveVStreamPosition(ctx, target, consumerGroup, shardGtid)
+	return tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
+}
+
+// ReadVStreamPosition is part of the QueryService interface.
+func (itc *internalTabletConn) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	shardGtid, err := itc.tablet.qsc.QueryService().ReadVStreamPosition(ctx, target, consumerGroup)
+	return shardGtid, tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
+}
+
 //
 // TabletManagerClient implementation
 //
@@ -771,6 +795,15 @@ func (itmc *internalTabletManagerClient) GetGlobalStatusVars(ctx context.Context
 	return t.tm.GetGlobalStatusVars(ctx, variables)
 }
 
+// SelfCheck is part of the tmclient.TabletManagerClient interface.
+func (itmc *internalTabletManagerClient) SelfCheck(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.SelfCheckResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", topoproto.TabletAliasString(tablet.Alias))
+	}
+	return t.tm.SelfCheck(ctx), nil
+}
+
 func (itmc *internalTabletManagerClient) SetReadOnly(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return fmt.Errorf("not implemented in vtcombo")
 }
@@ -954,6 +987,10 @@ func (itmc *internalTabletManagerClient) RestoreFromBackup(context.Context, *top
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) RestartMySQL(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.RestartMySQLRequest) (logutil.EventStream, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
 func (itmc *internalTabletManagerClient) CheckThrottler(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.CheckThrottlerRequest) (*tabletmanagerdatapb.CheckThrottlerResponse, error) {
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
@@ -26,13 +26,17 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 )
 
-var cert, key, ca, crl, name string
+var (
+	cert, key, ca, crl, name string
+	spiffeIDs                []string
+)
 
 func init() {
 	servenv.OnParseFor("vtctl", RegisterFlags)
 	servenv.OnParseFor("vttestserver", RegisterFlags)
 	servenv.OnParseFor("vtctlclient", RegisterFlags)
 	servenv.OnParseFor("vtctldclient", RegisterFlags)
+	servenv.OnParseFor("vtexplain", RegisterFlags)
 }
 
 func RegisterFlags(fs *pflag.FlagSet) {
@@ -41,11 +45,12 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca, "vtctld_grpc_ca", ca, "the server ca to use to validate servers when connecting")
 	fs.StringVar(&crl, "vtctld_grpc_crl", crl, "the server crl to use to validate server certificates when connecting")
 	fs.StringVar(&name, "vtctld_grpc_server_name", name, "the server name to use to validate server certificate")
+	fs.StringSliceVar(&spiffeIDs, "vtctld_grpc_spiffe_ids", spiffeIDs, "comma-separated list of SPIFFE IDs allowed in the server certificate, checked instead of the server name. An ID ending with /* allows all the IDs under it")
 }
 
 // SecureDialOption returns a grpc.DialOption configured to use TLS (or
 // insecure if no flags were set) based on the vtctld_grpc_* flags declared by
 // this package.
 func SecureDialOption() (grpc.DialOption, error) {
-	return grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	return grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 }
@@ -74,6 +74,15 @@ func (client *gRPCVtctldClient) ApplySchema(ctx context.Context, in *vtctldatapb
 	return client.c.ApplySchema(ctx, in, opts...)
 }
 
+// ApplySchemaStream is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ApplySchemaStream(ctx context.Context, in *vtctldatapb.ApplySchemaRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_ApplySchemaStreamClient, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ApplySchemaStream(ctx, in, opts...)
+}
+
 // ApplyShardRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ApplyShardRoutingRules(ctx context.Context, in *vtctldatapb.ApplyShardRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyShardRoutingRulesResponse, error) {
 	if client.c == nil {
@@ -83,6 +92,24 @@ func (client *gRPCVtctldClient) ApplyShardRoutingRules(ctx context.Context, in *
 	return client.c.ApplyShardRoutingRules(ctx, in, opts...)
 }
 
+// ApplyStatementACL is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ApplyStatementACL(ctx context.Context, in *vtctldatapb.ApplyStatementACLRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyStatementACLResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ApplyStatementACL(ctx, in, opts...)
+}
+
+// ApplyTenantCatalog is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ApplyTenantCatalog(ctx context.Context, in *vtctldatapb.ApplyTenantCatalogRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyTenantCatalogResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ApplyTenantCatalog(ctx, in, opts...)
+}
+
 // ApplyVSchema is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ApplyVSchema(ctx context.Context, in *vtctldatapb.ApplyVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyVSchemaResponse, error) {
 	if client.c == nil {
@@ -110,6 +137,24 @@ func (client *gRPCVtctldClient) BackupShard(ctx context.Context, in *vtctldatapb
 	return client.c.BackupShard(ctx, in, opts...)
 }
 
+// BackupVerify is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) BackupVerify(ctx context.Context, in *vtctldatapb.BackupVerifyRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupVerifyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.BackupVerify(ctx, in, opts...)
+}
+
+// CancelScheduledReparent is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) CancelScheduledReparent(ctx context.Context, in *vtctldatapb.CancelScheduledReparentRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelScheduledReparentResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.CancelScheduledReparent(ctx, in, opts...)
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CancelSchemaMigration(ctx context.Context, in *vtctldatapb.CancelSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelSchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -137,6 +182,15 @@ func (client *gRPCVtctldClient) CheckThrottler(ctx context.Context, in *vtctldat
 	return client.c.CheckThrottler(ctx, in, opts...)
 }
 
+// ChecksumTable is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ChecksumTable(ctx context.Context, in *vtctldatapb.ChecksumTableRequest, opts ...grpc.CallOption) (*vtctldatapb.ChecksumTableResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ChecksumTable(ctx, in, opts...)
+}
+
 // CleanupSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CleanupSchemaMigration(ctx context.Context, in *vtctldatapb.CleanupSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CleanupSchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -146,6 +200,15 @@ func (client *gRPCVtctldClient) CleanupSchemaMigration(ctx context.Context, in *
 	return client.c.CleanupSchemaMigration(ctx, in, opts...)
 }
 
+// ClearTabletQuarantine is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ClearTabletQuarantine(ctx context.Context, in *vtctldatapb.ClearTabletQuarantineRequest, opts ...grpc.CallOption) (*vtctldatapb.ClearTabletQuarantineResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ClearTabletQuarantine(ctx, in, opts...)
+}
+
 // CompleteSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CompleteSchemaMigration(ctx context.Context, in *vtctldatapb.CompleteSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CompleteSchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -227,6 +290,15 @@ func (client *gRPCVtctldClient) DeleteTablets(ctx context.Context, in *vtctldata
 	return client.c.DeleteTablets(ctx, in, opts...)
 }
 
+// DiffVSchemaVersions is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DiffVSchemaVersions(ctx context.Context, in *vtctldatapb.DiffVSchemaVersionsRequest, opts ...grpc.CallOption) (*vtctldatapb.DiffVSchemaVersionsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DiffVSchemaVersions(ctx, in, opts...)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	if client.c == nil {
@@ -290,6 +362,15 @@ func (client *gRPCVtctldClient) ForceCutOverSchemaMigration(ctx context.Context,
 	return client.c.ForceCutOverSchemaMigration(ctx, in, opts...)
 }
 
+// FreezeShardWrites is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) FreezeShardWrites(ctx context.Context, in *vtctldatapb.FreezeShardWritesRequest, opts ...grpc.CallOption) (*vtctldatapb.FreezeShardWritesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.FreezeShardWrites(ctx, in, opts...)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	if client.c == nil {
@@ -344,6 +425,15 @@ func (client *gRPCVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatapb
 	return client.c.GetKeyspace(ctx, in, opts...)
 }
 
+// GetKeyspaceCharsetReport is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetKeyspaceCharsetReport(ctx context.Context, in *vtctldatapb.GetKeyspaceCharsetReportRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceCharsetReportResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetKeyspaceCharsetReport(ctx, in, opts...)
+}
+
 // GetKeyspaceRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetKeyspaceRoutingRules(ctx context.Context, in *vtctldatapb.GetKeyspaceRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceRoutingRulesResponse, error) {
 	if client.c == nil {
@@ -371,6 +461,24 @@ func (client *gRPCVtctldClient) GetPermissions(ctx context.Context, in *vtctldat
 	return client.c.GetPermissions(ctx, in, opts...)
 }
 
+// GetPlanCacheControl is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetPlanCacheControl(ctx context.Context, in *vtctldatapb.GetPlanCacheControlRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPlanCacheControlResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetPlanCacheControl(ctx, in, opts...)
+}
+
+// GetQuarantinedTablets is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetQuarantinedTablets(ctx context.Context, in *vtctldatapb.GetQuarantinedTabletsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetQuarantinedTabletsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetQuarantinedTablets(ctx, in, opts...)
+}
+
 // GetRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetRoutingRules(ctx context.Context, in *vtctldatapb.GetRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetRoutingRulesResponse, error) {
 	if client.c == nil {
@@ -380,6 +488,15 @@ func (client *gRPCVtctldClient) GetRoutingRules(ctx context.Context, in *vtctlda
 	return client.c.GetRoutingRules(ctx, in, opts...)
 }
 
+// GetScheduledReparents is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetScheduledReparents(ctx context.Context, in *vtctldatapb.GetScheduledReparentsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetScheduledReparentsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetScheduledReparents(ctx, in, opts...)
+}
+
 // GetSchema is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetSchema(ctx context.Context, in *vtctldatapb.GetSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaResponse, error) {
 	if client.c == nil {
@@ -389,6 +506,24 @@ func (client *gRPCVtctldClient) GetSchema(ctx context.Context, in *vtctldatapb.G
 	return client.c.GetSchema(ctx, in, opts...)
 }
 
+// GetSchemaAtPosition is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSchemaAtPosition(ctx context.Context, in *vtctldatapb.GetSchemaAtPositionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaAtPositionResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSchemaAtPosition(ctx, in, opts...)
+}
+
+// GetSchemaDrift is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSchemaDrift(ctx context.Context, in *vtctldatapb.GetSchemaDriftRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaDriftResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSchemaDrift(ctx, in, opts...)
+}
+
 // GetSchemaMigrations is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetSchemaMigrations(ctx context.Context, in *vtctldatapb.GetSchemaMigrationsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaMigrationsResponse, error) {
 	if client.c == nil {
@@ -425,6 +560,24 @@ func (client *gRPCVtctldClient) GetShardRoutingRules(ctx context.Context, in *vt
 	return client.c.GetShardRoutingRules(ctx, in, opts...)
 }
 
+// GetShardWriteFreezeStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetShardWriteFreezeStatus(ctx context.Context, in *vtctldatapb.GetShardWriteFreezeStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardWriteFreezeStatusResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetShardWriteFreezeStatus(ctx, in, opts...)
+}
+
+// GetSnapshotKeyspaceStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSnapshotKeyspaceStatus(ctx context.Context, in *vtctldatapb.GetSnapshotKeyspaceStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSnapshotKeyspaceStatusResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSnapshotKeyspaceStatus(ctx, in, opts...)
+}
+
 // GetSrvKeyspaceNames is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetSrvKeyspaceNames(ctx context.Context, in *vtctldatapb.GetSrvKeyspaceNamesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspaceNamesResponse, error) {
 	if client.c == nil {
@@ -452,6 +605,15 @@ func (client *gRPCVtctldClient) GetSrvVSchema(ctx context.Context, in *vtctldata
 	return client.c.GetSrvVSchema(ctx, in, opts...)
 }
 
+// GetSrvVSchemaDiffs is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSrvVSchemaDiffs(ctx context.Context, in *vtctldatapb.GetSrvVSchemaDiffsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvVSchemaDiffsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSrvVSchemaDiffs(ctx, in, opts...)
+}
+
 // GetSrvVSchemas is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctldatapb.GetSrvVSchemasRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvVSchemasResponse, error) {
 	if client.c == nil {
@@ -461,6 +623,15 @@ func (client *gRPCVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctldat
 	return client.c.GetSrvVSchemas(ctx, in, opts...)
 }
 
+// GetStatementACL is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetStatementACL(ctx context.Context, in *vtctldatapb.GetStatementACLRequest, opts ...grpc.CallOption) (*vtctldatapb.GetStatementACLResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetStatementACL(ctx, in, opts...)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	if client.c == nil {
@@ -479,6 +650,15 @@ func (client *gRPCVtctldClient) GetTablets(ctx context.Context, in *vtctldatapb.
 	return client.c.GetTablets(ctx, in, opts...)
 }
 
+// GetTenantCatalog is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetTenantCatalog(ctx context.Context, in *vtctldatapb.GetTenantCatalogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTenantCatalogResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetTenantCatalog(ctx, in, opts...)
+}
+
 // GetThrottlerStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetThrottlerStatus(ctx context.Context, in *vtctldatapb.GetThrottlerStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetThrottlerStatusResponse, error) {
 	if client.c == nil {
@@ -506,6 +686,15 @@ func (client *gRPCVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVSchema(ctx, in, opts...)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVSchemaHistory(ctx, in, opts...)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	if client.c == nil {
@@ -533,6 +722,15 @@ func (client *gRPCVtctldClient) InitShardPrimary(ctx context.Context, in *vtctld
 	return client.c.InitShardPrimary(ctx, in, opts...)
 }
 
+// InvalidateQueryPlans is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) InvalidateQueryPlans(ctx context.Context, in *vtctldatapb.InvalidateQueryPlansRequest, opts ...grpc.CallOption) (*vtctldatapb.InvalidateQueryPlansResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.InvalidateQueryPlans(ctx, in, opts...)
+}
+
 // LaunchSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) LaunchSchemaMigration(ctx context.Context, in *vtctldatapb.LaunchSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.LaunchSchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -542,6 +740,15 @@ func (client *gRPCVtctldClient) LaunchSchemaMigration(ctx context.Context, in *v
 	return client.c.LaunchSchemaMigration(ctx, in, opts...)
 }
 
+// LookupVindexComplete is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) LookupVindexComplete(ctx context.Context, in *vtctldatapb.LookupVindexCompleteRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCompleteResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.LookupVindexComplete(ctx, in, opts...)
+}
+
 // LookupVindexCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) LookupVindexCreate(ctx context.Context, in *vtctldatapb.LookupVindexCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCreateResponse, error) {
 	if client.c == nil {
@@ -560,6 +767,24 @@ func (client *gRPCVtctldClient) LookupVindexExternalize(ctx context.Context, in
 	return client.c.LookupVindexExternalize(ctx, in, opts...)
 }
 
+// LookupVindexRevert is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) LookupVindexRevert(ctx context.Context, in *vtctldatapb.LookupVindexRevertRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexRevertResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.LookupVindexRevert(ctx, in, opts...)
+}
+
+// LookupVindexStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) LookupVindexStatus(ctx context.Context, in *vtctldatapb.LookupVindexStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexStatusResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.LookupVindexStatus(ctx, in, opts...)
+}
+
 // MaterializeCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) MaterializeCreate(ctx context.Context, in *vtctldatapb.MaterializeCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.MaterializeCreateResponse, error) {
 	if client.c == nil {
@@ -632,6 +857,15 @@ func (client *gRPCVtctldClient) MoveTablesCreate(ctx context.Context, in *vtctld
 	return client.c.MoveTablesCreate(ctx, in, opts...)
 }
 
+// PinQueryPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) PinQueryPlan(ctx context.Context, in *vtctldatapb.PinQueryPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.PinQueryPlanResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.PinQueryPlan(ctx, in, opts...)
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.PingTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTabletResponse, error) {
 	if client.c == nil {
@@ -641,6 +875,15 @@ func (client *gRPCVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.
 	return client.c.PingTablet(ctx, in, opts...)
 }
 
+// PlanReshard is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) PlanReshard(ctx context.Context, in *vtctldatapb.PlanReshardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlanReshardResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.PlanReshard(ctx, in, opts...)
+}
+
 // PlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) PlannedReparentShard(ctx context.Context, in *vtctldatapb.PlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlannedReparentShardResponse, error) {
 	if client.c == nil {
@@ -668,6 +911,33 @@ func (client *gRPCVtctldClient) RebuildVSchemaGraph(ctx context.Context, in *vtc
 	return client.c.RebuildVSchemaGraph(ctx, in, opts...)
 }
 
+// ReconcileSrvVSchemas is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReconcileSrvVSchemas(ctx context.Context, in *vtctldatapb.ReconcileSrvVSchemasRequest, opts ...grpc.CallOption) (*vtctldatapb.ReconcileSrvVSchemasResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReconcileSrvVSchemas(ctx, in, opts...)
+}
+
+// ReferenceTablesCheck is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReferenceTablesCheck(ctx context.Context, in *vtctldatapb.ReferenceTablesCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.ReferenceTablesCheckResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReferenceTablesCheck(ctx, in, opts...)
+}
+
+// ReferenceTablesCreate is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReferenceTablesCreate(ctx context.Context, in *vtctldatapb.ReferenceTablesCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.ReferenceTablesCreateResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReferenceTablesCreate(ctx, in, opts...)
+}
+
 // RefreshState is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RefreshState(ctx context.Context, in *vtctldatapb.RefreshStateRequest, opts ...grpc.CallOption) (*vtctldatapb.RefreshStateResponse, error) {
 	if client.c == nil {
@@ -776,6 +1046,15 @@ func (client *gRPCVtctldClient) RetrySchemaMigration(ctx context.Context, in *vt
 	return client.c.RetrySchemaMigration(ctx, in, opts...)
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RollbackVSchema(ctx, in, opts...)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	if client.c == nil {
@@ -785,6 +1064,24 @@ func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldat
 	return client.c.RunHealthCheck(ctx, in, opts...)
 }
 
+// SchedulePlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SchedulePlannedReparentShard(ctx context.Context, in *vtctldatapb.SchedulePlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.SchedulePlannedReparentShardResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SchedulePlannedReparentShard(ctx, in, opts...)
+}
+
+// SetKeyspaceCharset is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetKeyspaceCharset(ctx context.Context, in *vtctldatapb.SetKeyspaceCharsetRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceCharsetResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetKeyspaceCharset(ctx, in, opts...)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	if client.c == nil {
@@ -794,6 +1091,15 @@ func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context,
 	return client.c.SetKeyspaceDurabilityPolicy(ctx, in, opts...)
 }
 
+// SetKeyspaceTwoPC is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetKeyspaceTwoPC(ctx context.Context, in *vtctldatapb.SetKeyspaceTwoPCRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceTwoPCResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetKeyspaceTwoPC(ctx, in, opts...)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	if client.c == nil {
@@ -911,6 +1217,24 @@ func (client *gRPCVtctldClient) TabletExternallyReparented(ctx context.Context,
 	return client.c.TabletExternallyReparented(ctx, in, opts...)
 }
 
+// UnfreezeShardWrites is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) UnfreezeShardWrites(ctx context.Context, in *vtctldatapb.UnfreezeShardWritesRequest, opts ...grpc.CallOption) (*vtctldatapb.UnfreezeShardWritesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.UnfreezeShardWrites(ctx, in, opts...)
+}
+
+// UnpinQueryPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) UnpinQueryPlan(ctx context.Context, in *vtctldatapb.UnpinQueryPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.UnpinQueryPlanResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.UnpinQueryPlan(ctx, in, opts...)
+}
+
 // UpdateCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) UpdateCellInfo(ctx context.Context, in *vtctldatapb.UpdateCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateCellInfoResponse, error) {
 	if client.c == nil {
@@ -1046,6 +1370,15 @@ func (client *gRPCVtctldClient) ValidateVersionShard(ctx context.Context, in *vt
 	return client.c.ValidateVersionShard(ctx, in, opts...)
 }
 
+// WatchTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WatchTopologyPath(ctx context.Context, in *vtctldatapb.WatchTopologyPathRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_WatchTopologyPathClient, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WatchTopologyPath(ctx, in, opts...)
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) WorkflowDelete(ctx context.Context, in *vtctldatapb.WorkflowDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowDeleteResponse, error) {
 	if client.c == nil {
@@ -21,12 +21,14 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vttime"
@@ -39,6 +41,11 @@ const (
 	*
 	from _vt.schema_migrations where %s %s %s`
 	AllMigrationsIndicator = "all"
+
+	// selectSchemaVersionAtPositionSql selects the most recent schema version
+	// whose GTID set is contained in the requested one. The pos column holds
+	// an encoded position (flavor/gtidset), so the flavor prefix is stripped.
+	selectSchemaVersionAtPositionSql = `select id, pos, ddl, time_updated, schemax from %s.schema_version where gtid_subset(substring_index(pos, '/', -1), %a) order by id desc limit 1`
 )
 
 func alterSchemaMigrationQuery(command, uuid string) (string, error) {
@@ -52,6 +59,34 @@ func selectSchemaMigrationsQuery(condition, order, skipLimit string) string {
 	return fmt.Sprintf(selectSchemaMigrationsSql, condition, order, skipLimit)
 }
 
+func selectSchemaVersionAtPositionQuery(sidecarDBName string, pos replication.Position) (string, error) {
+	pq := sqlparser.BuildParsedQuery(selectSchemaVersionAtPositionSql, sqlparser.String(sqlparser.NewIdentifierCS(sidecarDBName)), ":pos")
+	return pq.GenerateQuery(map[string]*querypb.BindVariable{
+		"pos": sqltypes.StringBindVariable(pos.GTIDSet.String()),
+	}, nil)
+}
+
+// rowToSchemaVersion converts the row read by selectSchemaVersionAtPositionQuery
+// into a GetSchemaAtPositionResponse. It returns nil if the query returned no
+// rows.
+func rowToSchemaVersion(qr *sqltypes.Result) (*vtctldatapb.GetSchemaAtPositionResponse, error) {
+	if len(qr.Rows) == 0 {
+		return nil, nil
+	}
+	row := qr.Named().Row()
+
+	sch := &binlogdatapb.MinimalSchema{}
+	if err := sch.UnmarshalVT(row.AsBytes("schemax", nil)); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.GetSchemaAtPositionResponse{
+		Position:    row.AsString("pos", ""),
+		Ddl:         row.AsString("ddl", ""),
+		TimeUpdated: row.AsInt64("time_updated", 0),
+		Tables:      sch.Tables,
+	}, nil
+}
+
 // rowToSchemaMigration converts a single row into a SchemaMigration protobuf.
 func rowToSchemaMigration(row sqltypes.RowNamedValues) (sm *vtctldatapb.SchemaMigration, err error) {
 	sm = new(vtctldatapb.SchemaMigration)
@@ -139,6 +174,8 @@ func rowToSchemaMigration(row sqltypes.RowNamedValues) (sm *vtctldatapb.SchemaMi
 
 	sm.PostponeCompletion = row.AsBool("postpone_completion", false)
 	sm.RemovedForeignKeyNames = row.AsString("removed_foreign_key_names", "")
+	sm.ProjectedDiskBytes = row.AsUint64("projected_disk_bytes", 0)
+	sm.ImpactAnalysis = row.AsString("impact_analysis", "")
 	sm.RemovedUniqueKeyNames = row.AsString("removed_unique_key_names", "")
 	sm.DroppedNoDefaultColumnNames = row.AsString("dropped_no_default_column_names", "")
 	sm.ExpandedColumnNames = row.AsString("expanded_column_names", "")
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
@@ -234,3 +235,14 @@ func TestAlterSchemaMigrationQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectSchemaVersionAtPositionQuery(t *testing.T) {
+	t.Parallel()
+
+	pos, err := replication.DecodePosition("MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-15")
+	require.NoError(t, err)
+
+	query, err := selectSchemaVersionAtPositionQuery("_vt", pos)
+	require.NoError(t, err)
+	assert.Equal(t, "select id, pos, ddl, time_updated, schemax from _vt.schema_version where gtid_subset(substring_index(pos, '/', -1), '16b1039f-22b6-11ed-b765-0a43f95f28a3:1-15') order by id desc limit 1", query)
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	defaultReshardPlanSampleSize = 1000
+	defaultReshardPlanKeyBytes   = 1
+)
+
+// reshardSample is a sampled row of a source shard, standing for size bytes
+// and rows rows of its table around its keyspace id.
+type reshardSample struct {
+	keyspaceID []byte
+	size       float64
+	rows       float64
+}
+
+// reshardPlanTables returns the tables of the keyspace whose rows are sampled:
+// those with a primary vindex which maps their rows to keyspace ids by itself.
+// If names is not empty, it returns those tables, which must qualify.
+func reshardPlanTables(ks *vindexes.KeyspaceSchema, names []string) ([]*vindexes.Table, error) {
+	qualifies := func(table *vindexes.Table) bool {
+		return table.Type == "" && len(table.ColumnVindexes) > 0 && !table.ColumnVindexes[0].Vindex.NeedsVCursor()
+	}
+
+	var tables []*vindexes.Table
+	if len(names) == 0 {
+		for _, table := range ks.Tables {
+			if qualifies(table) {
+				tables = append(tables, table)
+			}
+		}
+		sort.Slice(tables, func(i, j int) bool {
+			return tables[i].Name.String() < tables[j].Name.String()
+		})
+	} else {
+		for _, name := range names {
+			table, ok := ks.Tables[name]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s not found in the vschema of keyspace %s", name, ks.Keyspace.Name)
+			}
+			if !qualifies(table) {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s has no primary vindex mapping its rows to keyspace ids without a lookup", name)
+			}
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no table with a primary vindex mapping its rows to keyspace ids without a lookup", ks.Keyspace.Name)
+	}
+	return tables, nil
+}
+
+// reshardPlanSourceKeyRange returns the key range covered by the source
+// shards, which must be contiguous.
+func reshardPlanSourceKeyRange(shards []*topo.ShardInfo) (*topodatapb.KeyRange, error) {
+	sort.Slice(shards, func(i, j int) bool {
+		return key.KeyRangeLess(shards[i].KeyRange, shards[j].KeyRange)
+	})
+	keyRange := shards[0].KeyRange
+	if keyRange == nil {
+		keyRange = &topodatapb.KeyRange{}
+	}
+	for _, si := range shards[1:] {
+		var ok bool
+		if keyRange, ok = key.KeyRangeAdd(keyRange, si.KeyRange); !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "source shard %s is not contiguous with the previous source shards", si.ShardName())
+		}
+	}
+	return keyRange, nil
+}
+
+// readTablet returns the tablet of the shard to read the rows from: the first
+// tablet of tabletType, or the primary.
+func (s *VtctldServer) readTablet(ctx context.Context, si *topo.ShardInfo, tabletType topodatapb.TabletType) (*topodatapb.Tablet, error) {
+	tabletMap, err := s.ts.GetTabletMapForShard(ctx, si.Keyspace(), si.ShardName())
+	if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+		return nil, fmt.Errorf("GetTabletMapForShard(%s, %s) failed: %w", si.Keyspace(), si.ShardName(), err)
+	}
+	aliases := make([]string, 0, len(tabletMap))
+	for alias := range tabletMap {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if tablet := tabletMap[alias].Tablet; tablet.Type == tabletType {
+			return tablet, nil
+		}
+	}
+	if si.HasPrimary() {
+		if ti, ok := tabletMap[topoproto.TabletAliasString(si.PrimaryAlias)]; ok {
+			return ti.Tablet, nil
+		}
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no %v tablet nor primary in shard %s/%s", tabletType, si.Keyspace(), si.ShardName())
+}
+
+// sampleReshardShard samples up to sampleSize rows of each table from the
+// tablet, and maps them to their keyspace ids. Each sample stands for an equal
+// share of the size and of the rows of its table on the tablet.
+func (s *VtctldServer) sampleReshardShard(ctx context.Context, tablet *topodatapb.Tablet, tables []*vindexes.Table, sampleSize int64) ([]reshardSample, error) {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name.String())
+	}
+	sd, err := s.tmc.GetSchema(ctx, tablet, &tabletmanagerdatapb.GetSchemaRequest{Tables: names, TableSchemaOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("GetSchema(%v) failed: %w", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	definitions := make(map[string]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
+	for _, td := range sd.TableDefinitions {
+		definitions[td.Name] = td
+	}
+
+	var samples []reshardSample
+	for _, table := range tables {
+		td, ok := definitions[table.Name.String()]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s not found on tablet %v", table.Name.String(), topoproto.TabletAliasString(tablet.Alias))
+		}
+
+		query := reshardSampleQuery(table, td.RowCount, sampleSize)
+		p3qr, err := s.tmc.ExecuteFetchAsApp(ctx, tablet, true, &tabletmanagerdatapb.ExecuteFetchAsAppRequest{
+			Query:   []byte(query),
+			MaxRows: uint64(sampleSize),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ExecuteFetchAsApp(%v, %s) failed: %w", topoproto.TabletAliasString(tablet.Alias), query, err)
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		if len(qr.Rows) == 0 {
+			continue
+		}
+
+		destinations, err := vindexes.Map(ctx, table.ColumnVindexes[0].Vindex, nil, qr.Rows)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "mapping the rows of table %s to keyspace ids", table.Name.String())
+		}
+		size := float64(td.DataLength+td.IndexLength) / float64(len(qr.Rows))
+		rows := float64(td.RowCount) / float64(len(qr.Rows))
+		for _, destination := range destinations {
+			ksid, ok := destination.(key.DestinationKeyspaceID)
+			if !ok {
+				continue
+			}
+			samples = append(samples, reshardSample{keyspaceID: ksid, size: size, rows: rows})
+		}
+	}
+	return samples, nil
+}
+
+// reshardSampleQuery returns the query sampling about sampleSize rows of the
+// primary vindex columns of a table of rowCount rows.
+func reshardSampleQuery(table *vindexes.Table, rowCount uint64, sampleSize int64) string {
+	columns := make([]string, 0, len(table.ColumnVindexes[0].Columns))
+	for _, column := range table.ColumnVindexes[0].Columns {
+		columns = append(columns, sqlescape.EscapeID(column.String()))
+	}
+	query := fmt.Sprintf("select %s from %s", strings.Join(columns, ", "), sqlescape.EscapeID(table.Name.String()))
+	// The row count is an estimate, so the rows are sampled with twice the
+	// probability needed, and the query stops at sampleSize rows.
+	if probability := 2 * float64(sampleSize) / float64(rowCount); rowCount > 0 && probability < 1 {
+		query += " where rand() < " + strconv.FormatFloat(probability, 'f', -1, 64)
+	}
+	return query + fmt.Sprintf(" limit %d", sampleSize)
+}
+
+// planReshardShards splits keyRange into shardCount shards of about the same
+// size, as estimated from the samples, with boundaries of keyBytes bytes.
+func planReshardShards(keyRange *topodatapb.KeyRange, samples []reshardSample, shardCount int, keyBytes int) ([]*vtctldatapb.PlannedShard, error) {
+	var inRange []reshardSample
+	for _, sample := range samples {
+		if key.KeyRangeContains(keyRange, sample.keyspaceID) {
+			inRange = append(inRange, sample)
+		}
+	}
+	if len(inRange) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no rows sampled in key range %s", key.KeyRangeString(keyRange))
+	}
+	sort.Slice(inRange, func(i, j int) bool {
+		return bytes.Compare(inRange[i].keyspaceID, inRange[j].keyspaceID) < 0
+	})
+
+	// The shards are balanced by size, or by row count if the tables report
+	// no size, or by sampled rows if they report no rows either.
+	weight := func(sample reshardSample) float64 { return sample.size }
+	var total float64
+	for _, sample := range inRange {
+		total += sample.size
+	}
+	if total == 0 {
+		weight = func(sample reshardSample) float64 { return sample.rows }
+		for _, sample := range inRange {
+			total += sample.rows
+		}
+	}
+	if total == 0 {
+		weight = func(reshardSample) float64 { return 1 }
+		total = float64(len(inRange))
+	}
+
+	boundaries := [][]byte{keyRange.Start}
+	var cumulative float64
+	for i, sample := range inRange[:len(inRange)-1] {
+		if len(boundaries) == shardCount {
+			break
+		}
+		cumulative += weight(sample)
+		if cumulative < total*float64(len(boundaries))/float64(shardCount) {
+			continue
+		}
+		// The shard ends with the sample which fills it.
+		boundary := inRange[i+1].keyspaceID
+		if len(boundary) > keyBytes {
+			boundary = boundary[:keyBytes]
+		}
+		if key.Compare(boundary, boundaries[len(boundaries)-1]) <= 0 || (!key.Empty(keyRange.End) && key.Compare(boundary, keyRange.End) >= 0) {
+			// The boundary is too coarse to split the keyspace ids there.
+			continue
+		}
+		boundaries = append(boundaries, boundary)
+	}
+	if len(boundaries) < shardCount {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot split key range %s into %d shards with boundaries of %d bytes from %d distinct sampled rows", key.KeyRangeString(keyRange), shardCount, keyBytes, len(inRange))
+	}
+	boundaries = append(boundaries, keyRange.End)
+
+	shards := make([]*vtctldatapb.PlannedShard, shardCount)
+	for i := range shards {
+		kr := &topodatapb.KeyRange{Start: boundaries[i], End: boundaries[i+1]}
+		shards[i] = &vtctldatapb.PlannedShard{
+			Name:     key.KeyRangeString(kr),
+			KeyRange: kr,
+		}
+	}
+	sizes := make([]float64, shardCount)
+	rows := make([]float64, shardCount)
+	i := 0
+	for _, sample := range inRange {
+		for !key.KeyRangeContains(shards[i].KeyRange, sample.keyspaceID) {
+			i++
+		}
+		shards[i].SampledRows++
+		sizes[i] += sample.size
+		rows[i] += sample.rows
+	}
+	for i, shard := range shards {
+		shard.EstimatedDataSize = uint64(sizes[i])
+		shard.EstimatedRowCount = uint64(rows[i])
+	}
+	return shards, nil
+}
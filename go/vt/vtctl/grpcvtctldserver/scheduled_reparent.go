@@ -0,0 +1,345 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/hook"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The states of a scheduled reparent.
+const (
+	scheduledReparentScheduled = "scheduled"
+	scheduledReparentRunning   = "running"
+	scheduledReparentSucceeded = "succeeded"
+	scheduledReparentFailed    = "failed"
+	scheduledReparentCancelled = "cancelled"
+)
+
+const (
+	// scheduledReparentHook is the vthook run whenever a scheduled reparent
+	// changes state, with the KEYSPACE, SHARD, ID, STATE, ATTEMPTS and
+	// MESSAGE of the reparent in its environment. It is optional.
+	scheduledReparentHook = "scheduled_reparent"
+
+	// defaultScheduledReparentRetryInterval is the time between two attempts
+	// of a scheduled reparent, when the request does not specify it.
+	defaultScheduledReparentRetryInterval = time.Minute
+)
+
+// scheduledReparents are the reparents scheduled by
+// SchedulePlannedReparentShard. They are only kept in the memory of the vtctld
+// which scheduled them, and are lost when it restarts.
+type scheduledReparents struct {
+	mu        sync.Mutex
+	reparents map[string]*scheduledReparent
+}
+
+type scheduledReparent struct {
+	// sr is guarded by scheduledReparents.mu.
+	sr     *vtctldatapb.ScheduledReparent
+	cancel context.CancelFunc
+}
+
+func (srs *scheduledReparents) add(sr *vtctldatapb.ScheduledReparent, cancel context.CancelFunc) {
+	srs.mu.Lock()
+	defer srs.mu.Unlock()
+
+	if srs.reparents == nil {
+		srs.reparents = map[string]*scheduledReparent{}
+	}
+	srs.reparents[sr.Id] = &scheduledReparent{sr: sr, cancel: cancel}
+}
+
+// update applies f to the scheduled reparent, unless it was cancelled, and
+// returns a copy of it. ok is false if it was cancelled.
+func (srs *scheduledReparents) update(id string, f func(sr *vtctldatapb.ScheduledReparent)) (sr *vtctldatapb.ScheduledReparent, ok bool) {
+	srs.mu.Lock()
+	defer srs.mu.Unlock()
+
+	r := srs.reparents[id]
+	if r.sr.State == scheduledReparentCancelled {
+		return nil, false
+	}
+	f(r.sr)
+	return r.sr.CloneVT(), true
+}
+
+// cancelReparent cancels a scheduled reparent which is not running, and returns a
+// copy of it.
+func (srs *scheduledReparents) cancelReparent(id string) (*vtctldatapb.ScheduledReparent, error) {
+	srs.mu.Lock()
+	defer srs.mu.Unlock()
+
+	r, ok := srs.reparents[id]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no scheduled reparent %s", id)
+	}
+	if r.sr.State != scheduledReparentScheduled {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot cancel scheduled reparent %s in state %s", id, r.sr.State)
+	}
+	r.sr.State = scheduledReparentCancelled
+	r.cancel()
+	return r.sr.CloneVT(), nil
+}
+
+// list returns copies of the scheduled reparents of a keyspace, or of a shard
+// if shard is set, or of all the keyspaces if keyspace is empty, ordered by
+// start time.
+func (srs *scheduledReparents) list(keyspace, shard string) []*vtctldatapb.ScheduledReparent {
+	srs.mu.Lock()
+	defer srs.mu.Unlock()
+
+	var reparents []*vtctldatapb.ScheduledReparent
+	for _, r := range srs.reparents {
+		if keyspace != "" && r.sr.Request.Keyspace != keyspace {
+			continue
+		}
+		if shard != "" && r.sr.Request.Shard != shard {
+			continue
+		}
+		reparents = append(reparents, r.sr.CloneVT())
+	}
+	sort.Slice(reparents, func(i, j int) bool {
+		ti, tj := protoutil.TimeFromProto(reparents[i].StartTime), protoutil.TimeFromProto(reparents[j].StartTime)
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return reparents[i].Id < reparents[j].Id
+	})
+	return reparents
+}
+
+// runScheduledReparent waits for the start time of a scheduled reparent, and
+// runs it once its preconditions are met, until the end of its retry window.
+func (s *VtctldServer) runScheduledReparent(ctx context.Context, id string, req *vtctldatapb.SchedulePlannedReparentShardRequest, start time.Time, window, interval time.Duration) {
+	end := start.Add(window)
+	wait := time.Until(start)
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sr, ok := s.scheduledReparents.update(id, func(sr *vtctldatapb.ScheduledReparent) {
+			sr.State = scheduledReparentRunning
+			sr.Attempts++
+		})
+		if !ok {
+			return
+		}
+		s.notifyScheduledReparent(sr)
+
+		err := s.checkReparentPreconditions(ctx, req.Request.Keyspace, req.Request.Shard, req.Preconditions)
+		if err == nil {
+			var resp *vtctldatapb.PlannedReparentShardResponse
+			resp, err = s.PlannedReparentShard(ctx, req.Request)
+			if err == nil {
+				sr, _ = s.scheduledReparents.update(id, func(sr *vtctldatapb.ScheduledReparent) {
+					sr.State = scheduledReparentSucceeded
+					sr.Message = ""
+					sr.PromotedPrimary = resp.PromotedPrimary
+				})
+				s.notifyScheduledReparent(sr)
+				return
+			}
+		}
+
+		state := scheduledReparentScheduled
+		if time.Now().Add(interval).After(end) {
+			state = scheduledReparentFailed
+		}
+		sr, _ = s.scheduledReparents.update(id, func(sr *vtctldatapb.ScheduledReparent) {
+			sr.State = state
+			sr.Message = err.Error()
+		})
+		log.Warningf("attempt %d of scheduled reparent %s of %s/%s failed: %v", sr.Attempts, id, req.Request.Keyspace, req.Request.Shard, err)
+		s.notifyScheduledReparent(sr)
+		if state == scheduledReparentFailed {
+			return
+		}
+		wait = interval
+	}
+}
+
+// checkReparentPreconditions returns an error if the replicas of a shard lag
+// too much, if transactions have been in flight for too long on its primary,
+// or if the throttler of its primary is not OK.
+func (s *VtctldServer) checkReparentPreconditions(ctx context.Context, keyspace, shard string, preconditions *vtctldatapb.ReparentPreconditions) error {
+	if preconditions == nil {
+		return nil
+	}
+	maxLag, _, err := protoutil.DurationFromProto(preconditions.MaxReplicationLag)
+	if err != nil {
+		return err
+	}
+	maxTransactionAge, _, err := protoutil.DurationFromProto(preconditions.MaxTransactionAge)
+	if err != nil {
+		return err
+	}
+	if maxLag == 0 && maxTransactionAge == 0 && !preconditions.CheckThrottler {
+		return nil
+	}
+
+	tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil {
+		return err
+	}
+	aliases := make([]string, 0, len(tabletMap))
+	for alias := range tabletMap {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var primary *topodatapb.Tablet
+	for _, alias := range aliases {
+		tablet := tabletMap[alias].Tablet
+		switch {
+		case tablet.Type == topodatapb.TabletType_PRIMARY:
+			primary = tablet
+		case tablet.Type == topodatapb.TabletType_REPLICA && maxLag > 0:
+			status, err := s.tmc.ReplicationStatus(ctx, tablet)
+			if err != nil {
+				return fmt.Errorf("ReplicationStatus(%s) failed: %w", alias, err)
+			}
+			if status.ReplicationLagUnknown {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "replication lag of replica %s is unknown", alias)
+			}
+			if lag := time.Duration(status.ReplicationLagSeconds) * time.Second; lag > maxLag {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "replica %s lags by %v, more than %v", alias, lag, maxLag)
+			}
+		}
+	}
+	if maxTransactionAge == 0 && !preconditions.CheckThrottler {
+		return nil
+	}
+	if primary == nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no primary in shard %s/%s", keyspace, shard)
+	}
+	primaryAlias := topoproto.TabletAliasString(primary.Alias)
+
+	if maxTransactionAge > 0 {
+		query := fmt.Sprintf("select count(*) from information_schema.innodb_trx where trx_started < now() - interval %d second", int64(maxTransactionAge.Seconds()))
+		qr, err := s.tmc.ExecuteFetchAsDba(ctx, primary, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return fmt.Errorf("ExecuteFetchAsDba(%s) failed: %w", primaryAlias, err)
+		}
+		result := sqltypes.Proto3ToResult(qr)
+		if len(result.Rows) != 1 {
+			return fmt.Errorf("unexpected result counting the transactions of primary %s: %v", primaryAlias, result.Rows)
+		}
+		count, err := result.Rows[0][0].ToInt64()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%d transactions in flight for more than %v on primary %s", count, maxTransactionAge, primaryAlias)
+		}
+	}
+
+	if preconditions.CheckThrottler {
+		resp, err := s.tmc.CheckThrottler(ctx, primary, &tabletmanagerdatapb.CheckThrottlerRequest{
+			AppName: throttlerapp.VitessName.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("CheckThrottler(%s) failed: %w", primaryAlias, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "throttler of primary %s is not OK: %d %s", primaryAlias, resp.StatusCode, resp.Message)
+		}
+	}
+	return nil
+}
+
+// notifyScheduledReparent runs the scheduled_reparent hook, if it exists.
+func (s *VtctldServer) notifyScheduledReparent(sr *vtctldatapb.ScheduledReparent) {
+	env := map[string]string{
+		"KEYSPACE": sr.Request.Keyspace,
+		"SHARD":    sr.Request.Shard,
+		"ID":       sr.Id,
+		"STATE":    sr.State,
+		"ATTEMPTS": strconv.Itoa(int(sr.Attempts)),
+		"MESSAGE":  sr.Message,
+	}
+	if err := hook.NewHookWithEnv(scheduledReparentHook, nil, env).ExecuteOptional(); err != nil {
+		log.Warningf("%s hook failed for scheduled reparent %s: %v", scheduledReparentHook, sr.Id, err)
+	}
+}
+
+// validateScheduledReparent validates the request of a scheduled reparent, and
+// returns its start time, retry window and retry interval.
+func validateScheduledReparent(ctx context.Context, ts *topo.Server, req *vtctldatapb.SchedulePlannedReparentShardRequest) (start time.Time, window, interval time.Duration, err error) {
+	if req.Request == nil || req.Request.Keyspace == "" || req.Request.Shard == "" {
+		return start, 0, 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace and shard of the PlannedReparentShard request are required")
+	}
+	if _, err := ts.GetShard(ctx, req.Request.Keyspace, req.Request.Shard); err != nil {
+		return start, 0, 0, err
+	}
+
+	start = time.Now()
+	if req.StartTime != nil {
+		start = protoutil.TimeFromProto(req.StartTime)
+	}
+	window, _, err = protoutil.DurationFromProto(req.RetryWindow)
+	if err != nil {
+		return start, 0, 0, err
+	}
+	interval, ok, err := protoutil.DurationFromProto(req.RetryInterval)
+	if err != nil {
+		return start, 0, 0, err
+	}
+	if !ok {
+		interval = defaultScheduledReparentRetryInterval
+	}
+	if window < 0 || interval <= 0 {
+		return start, 0, 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "retry window must not be negative and retry interval must be positive")
+	}
+	if req.Preconditions != nil {
+		if _, _, err := protoutil.DurationFromProto(req.Preconditions.MaxReplicationLag); err != nil {
+			return start, 0, 0, err
+		}
+		if _, _, err := protoutil.DurationFromProto(req.Preconditions.MaxTransactionAge); err != nil {
+			return start, 0, 0, err
+		}
+	}
+	return start, window, interval, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestCheckReparentPreconditions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	}, &topodatapb.Tablet{
+		// The lag of RDONLY tablets is not checked.
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_RDONLY,
+	})
+
+	transactions := func(count string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), count))
+	}
+	tcs := []struct {
+		name          string
+		preconditions *vtctldatapb.ReparentPreconditions
+		lag           uint32
+		lagUnknown    bool
+		transactions  string
+		throttler     int32
+		expectErr     string
+	}{
+		{
+			name: "no preconditions",
+		},
+		{
+			name: "all met",
+			preconditions: &vtctldatapb.ReparentPreconditions{
+				MaxReplicationLag: protoutil.DurationToProto(10 * time.Second),
+				MaxTransactionAge: protoutil.DurationToProto(time.Minute),
+				CheckThrottler:    true,
+			},
+			lag:          5,
+			transactions: "0",
+			throttler:    http.StatusOK,
+		},
+		{
+			name: "replica lags",
+			preconditions: &vtctldatapb.ReparentPreconditions{
+				MaxReplicationLag: protoutil.DurationToProto(10 * time.Second),
+			},
+			lag:       11,
+			expectErr: "replica zone1-0000000101 lags by 11s, more than 10s",
+		},
+		{
+			name: "replica lag unknown",
+			preconditions: &vtctldatapb.ReparentPreconditions{
+				MaxReplicationLag: protoutil.DurationToProto(10 * time.Second),
+			},
+			lagUnknown: true,
+			expectErr:  "replication lag of replica zone1-0000000101 is unknown",
+		},
+		{
+			name: "long transactions",
+			preconditions: &vtctldatapb.ReparentPreconditions{
+				MaxTransactionAge: protoutil.DurationToProto(time.Minute),
+			},
+			transactions: "2",
+			expectErr:    "2 transactions in flight for more than 1m0s on primary zone1-0000000100",
+		},
+		{
+			name: "throttled",
+			preconditions: &vtctldatapb.ReparentPreconditions{
+				CheckThrottler: true,
+			},
+			throttler: http.StatusTooManyRequests,
+			expectErr: "throttler of primary zone1-0000000100 is not OK: 429",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tmc := &testutil.TabletManagerClient{
+				ReplicationStatusResults: map[string]struct {
+					Position *replicationdatapb.Status
+					Error    error
+				}{
+					"zone1-0000000101": {Position: &replicationdatapb.Status{ReplicationLagSeconds: tc.lag, ReplicationLagUnknown: tc.lagUnknown}},
+				},
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {Response: transactions(tc.transactions)},
+				},
+				CheckThrottlerResults: map[string]*tabletmanagerdatapb.CheckThrottlerResponse{
+					"zone1-0000000100": {StatusCode: tc.throttler},
+				},
+			}
+			vtctld := NewTestVtctldServer(ts, tmc)
+			err := vtctld.checkReparentPreconditions(ctx, "ks", "-", tc.preconditions)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/schemadiff"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// diffSchemaDrift returns the tables of a tablet schema which differ from the
+// reference schema, in the order of the reference schema, followed by the
+// tables only the tablet has.
+//
+// Tables whose definitions differ textually, but not semantically, are not
+// reported: this happens when the tablets run different MySQL versions.
+func diffSchemaDrift(env *schemadiff.Environment, reference, sd *tabletmanagerdatapb.SchemaDefinition) []*vtctldatapb.TableSchemaDrift {
+	tables := make(map[string]*tabletmanagerdatapb.TableDefinition, len(sd.TableDefinitions))
+	for _, td := range sd.TableDefinitions {
+		tables[td.Name] = td
+	}
+
+	var drifts []*vtctldatapb.TableSchemaDrift
+	referenceTables := make(map[string]bool, len(reference.TableDefinitions))
+	for _, ref := range reference.TableDefinitions {
+		referenceTables[ref.Name] = true
+		td, ok := tables[ref.Name]
+		switch {
+		case !ok:
+			drifts = append(drifts, &vtctldatapb.TableSchemaDrift{
+				Name:            ref.Name,
+				Kind:            vtctldatapb.TableSchemaDrift_MISSING,
+				ReferenceSchema: ref.Schema,
+				Diff:            schemaDriftDiff(env, ref.Type, "", ref.Schema),
+			})
+		case td.Type != ref.Type:
+			// A table on one side and a view on the other: there is no
+			// statement turning one into the other.
+			drifts = append(drifts, &vtctldatapb.TableSchemaDrift{
+				Name:            ref.Name,
+				Kind:            vtctldatapb.TableSchemaDrift_DIFFERENT,
+				ReferenceSchema: ref.Schema,
+				Schema:          td.Schema,
+			})
+		case td.Schema != ref.Schema:
+			diff, err := diffSchemaDriftDefinitions(env, ref.Type, td.Schema, ref.Schema)
+			if err == nil && diff == nil {
+				continue
+			}
+			drift := &vtctldatapb.TableSchemaDrift{
+				Name:            ref.Name,
+				Kind:            vtctldatapb.TableSchemaDrift_DIFFERENT,
+				ReferenceSchema: ref.Schema,
+				Schema:          td.Schema,
+			}
+			if diff != nil {
+				drift.Diff = diff.CanonicalStatementString()
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+
+	for _, td := range sd.TableDefinitions {
+		if referenceTables[td.Name] {
+			continue
+		}
+		drifts = append(drifts, &vtctldatapb.TableSchemaDrift{
+			Name:   td.Name,
+			Kind:   vtctldatapb.TableSchemaDrift_EXTRA,
+			Schema: td.Schema,
+			Diff:   schemaDriftDiff(env, td.Type, td.Schema, ""),
+		})
+	}
+
+	return drifts
+}
+
+// schemaDriftDiff returns the statement turning the from definition into the
+// to definition, or an empty string if it cannot be computed.
+func schemaDriftDiff(env *schemadiff.Environment, tableType string, from, to string) string {
+	diff, err := diffSchemaDriftDefinitions(env, tableType, from, to)
+	if err != nil || diff == nil {
+		return ""
+	}
+	return diff.CanonicalStatementString()
+}
+
+// diffSchemaDriftDefinitions diffs two definitions of a table or view, either
+// of which may be empty. It returns a nil diff if the definitions are
+// semantically equal.
+func diffSchemaDriftDefinitions(env *schemadiff.Environment, tableType string, from, to string) (schemadiff.EntityDiff, error) {
+	hints := &schemadiff.DiffHints{}
+
+	var (
+		diff schemadiff.EntityDiff
+		err  error
+	)
+	if tableType == tmutils.TableView {
+		diff, err = schemadiff.DiffCreateViewsQueries(env, from, to, hints)
+	} else {
+		diff, err = schemadiff.DiffCreateTablesQueries(env, from, to, hints)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil || diff.IsEmpty() {
+		return nil, nil
+	}
+	return diff, nil
+}
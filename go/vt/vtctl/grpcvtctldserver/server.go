@@ -22,19 +22,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
 
+	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
@@ -52,6 +58,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl/mysqlctlproto"
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
 	"vitess.io/vitess/go/vt/schemamanager"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
@@ -60,12 +67,16 @@ import (
 	"vitess.io/vitess/go/vt/topotools/events"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vtctl/tablechecksum"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/statementacl"
+	"vitess.io/vitess/go/vt/vtgate/tenants"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -75,6 +86,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -83,14 +95,25 @@ const (
 
 	// DefaultWaitReplicasTimeout is the default value for waitReplicasTimeout, which is used when calling method ApplySchema.
 	DefaultWaitReplicasTimeout = 10 * time.Second
+
+	// planInvalidationHistorySize is the number of plan invalidations kept in
+	// the PlanCacheControl, for the vtgates to apply those they did not see.
+	planInvalidationHistorySize = 100
+
+	// defaultGetSchemaDriftConcurrency is the default number of tablets whose
+	// schema GetSchemaDrift reads at the same time.
+	defaultGetSchemaDriftConcurrency = 10
 )
 
 // VtctldServer implements the Vtctld RPC service protocol.
 type VtctldServer struct {
 	vtctlservicepb.UnimplementedVtctldServer
+	env *vtenv.Environment
 	ts  *topo.Server
 	tmc tmclient.TabletManagerClient
 	ws  *workflow.Server
+
+	scheduledReparents scheduledReparents
 }
 
 // NewVtctldServer returns a new VtctldServer for the given topo server.
@@ -98,6 +121,7 @@ func NewVtctldServer(env *vtenv.Environment, ts *topo.Server) *VtctldServer {
 	tmc := tmclient.NewTabletManagerClient()
 
 	return &VtctldServer{
+		env: env,
 		ts:  ts,
 		tmc: tmc,
 		ws:  workflow.NewServer(env, ts, tmc),
@@ -107,10 +131,12 @@ func NewVtctldServer(env *vtenv.Environment, ts *topo.Server) *VtctldServer {
 // NewTestVtctldServer returns a new VtctldServer for the given topo server
 // AND tmclient for use in tests. This should NOT be used in production.
 func NewTestVtctldServer(ts *topo.Server, tmc tmclient.TabletManagerClient) *VtctldServer {
+	env := vtenv.NewTestEnv()
 	return &VtctldServer{
+		env: env,
 		ts:  ts,
 		tmc: tmc,
-		ws:  workflow.NewServer(vtenv.NewTestEnv(), ts, tmc),
+		ws:  workflow.NewServer(env, ts, tmc),
 	}
 }
 
@@ -175,13 +201,34 @@ func (s *VtctldServer) ApplyRoutingRules(ctx context.Context, req *vtctldatapb.A
 
 	span.Annotate("skip_rebuild", req.SkipRebuild)
 	span.Annotate("rebuild_cells", strings.Join(req.RebuildCells, ","))
+	span.Annotate("staged", req.Staged)
+
+	resp = &vtctldatapb.ApplyRoutingRulesResponse{}
+
+	if req.Staged {
+		if len(req.RebuildCells) == 0 || req.SkipRebuild {
+			err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "staged routing rules require rebuild cells and may not skip the rebuild")
+			return nil, err
+		}
+
+		err = stageSrvVSchema(ctx, s.ts, req.RebuildCells, func(staged *vschemapb.SrvVSchema) {
+			// A staged SrvVSchema only has routing rules if they are staged.
+			staged.RoutingRules = req.RoutingRules
+			if staged.RoutingRules == nil {
+				staged.RoutingRules = &vschemapb.RoutingRules{}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return resp, nil
+	}
 
 	if err = s.ts.SaveRoutingRules(ctx, req.RoutingRules); err != nil {
 		return nil, err
 	}
 
-	resp = &vtctldatapb.ApplyRoutingRulesResponse{}
-
 	if req.SkipRebuild {
 		log.Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
 		return resp, nil
@@ -221,6 +268,63 @@ func (s *VtctldServer) ApplyShardRoutingRules(ctx context.Context, req *vtctldat
 	return resp, nil
 }
 
+// ApplyStatementACL is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ApplyStatementACL(ctx context.Context, req *vtctldatapb.ApplyStatementACLRequest) (resp *vtctldatapb.ApplyStatementACLResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ApplyStatementACL")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("rules", len(req.StatementAcl.GetRules()))
+
+	if err = statementacl.Validate(req.StatementAcl); err != nil {
+		return nil, err
+	}
+
+	if err = s.ts.SaveStatementACL(ctx, req.StatementAcl); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ApplyStatementACLResponse{}, nil
+}
+
+// ApplyTenantCatalog is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ApplyTenantCatalog(ctx context.Context, req *vtctldatapb.ApplyTenantCatalogRequest) (resp *vtctldatapb.ApplyTenantCatalogResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ApplyTenantCatalog")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tenants", len(req.TenantCatalog.GetTenants()))
+
+	if err = tenants.Validate(req.TenantCatalog); err != nil {
+		return nil, err
+	}
+
+	// The tenants must belong to multi-tenant keyspaces.
+	specs := make(map[string]*vschemapb.MultiTenantSpec)
+	for database, tenant := range req.TenantCatalog.GetTenants() {
+		spec, ok := specs[tenant.Keyspace]
+		if !ok {
+			vs, err := s.ts.GetVSchema(ctx, tenant.Keyspace)
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "tenant database %s", database)
+			}
+			spec = vs.MultiTenantSpec
+			specs[tenant.Keyspace] = spec
+		}
+		if err = tenants.ValidateTenantID(tenant.TenantId, spec); err != nil {
+			return nil, vterrors.Wrapf(err, "tenant database %s in keyspace %s", database, tenant.Keyspace)
+		}
+	}
+
+	if err = s.ts.SaveTenantCatalog(ctx, req.TenantCatalog); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ApplyTenantCatalogResponse{}, nil
+}
+
 // ApplySchema is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ApplySchema(ctx context.Context, req *vtctldatapb.ApplySchemaRequest) (resp *vtctldatapb.ApplySchemaResponse, err error) {
 	log.Infof("VtctldServer.ApplySchema: keyspace=%s, migrationContext=%v, ddlStrategy=%v, batchSize=%v", req.Keyspace, req.MigrationContext, req.DdlStrategy, req.BatchSize)
@@ -233,6 +337,43 @@ func (s *VtctldServer) ApplySchema(ctx context.Context, req *vtctldatapb.ApplySc
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("ddl_strategy", req.DdlStrategy)
 
+	resp, err = s.applySchema(ctx, span, req, nil)
+	return resp, err
+}
+
+// ApplySchemaStream is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ApplySchemaStream(req *vtctldatapb.ApplySchemaRequest, stream vtctlservicepb.Vtctld_ApplySchemaStreamServer) (err error) {
+	log.Infof("VtctldServer.ApplySchemaStream: keyspace=%s, migrationContext=%v, ddlStrategy=%v, batchSize=%v", req.Keyspace, req.MigrationContext, req.DdlStrategy, req.BatchSize)
+
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.ApplySchemaStream")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("ddl_strategy", req.DdlStrategy)
+
+	// The progress is reported from the goroutines of the shards, one at a
+	// time, so the sends never overlap.
+	var sendErr error
+	resp, err := s.applySchema(ctx, span, req, func(progress *vtctldatapb.ApplySchemaShardProgress) {
+		if sendErr == nil {
+			sendErr = stream.Send(&vtctldatapb.ApplySchemaStreamResponse{Progress: progress})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&vtctldatapb.ApplySchemaStreamResponse{Result: resp})
+}
+
+// applySchema applies the schema change of the request. When progress is
+// set, the schema change is rolled out shard by shard, even without a
+// ShardRollout in the request, and the progress of every shard is reported.
+func (s *VtctldServer) applySchema(ctx context.Context, span trace.Span, req *vtctldatapb.ApplySchemaRequest, progress func(*vtctldatapb.ApplySchemaShardProgress)) (resp *vtctldatapb.ApplySchemaResponse, err error) {
 	if len(req.Sql) == 0 {
 		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "Sql must be a non-empty array")
 		return nil, err
@@ -272,7 +413,7 @@ func (s *VtctldServer) ApplySchema(ctx context.Context, req *vtctldatapb.ApplySc
 		logstream = append(logstream, e)
 	})
 
-	executor := schemamanager.NewTabletExecutor(migrationContext, s.ts, s.tmc, logger, waitReplicasTimeout, req.BatchSize, s.ws.SQLParser())
+	executor := schemamanager.NewTabletExecutor(migrationContext, s.ts, s.tmc, logger, waitReplicasTimeout, req.BatchSize, s.env)
 
 	if err = executor.SetDDLStrategy(req.DdlStrategy); err != nil {
 		err = vterrors.Wrapf(err, "invalid DdlStrategy: %s", req.DdlStrategy)
@@ -286,6 +427,15 @@ func (s *VtctldServer) ApplySchema(ctx context.Context, req *vtctldatapb.ApplySc
 		}
 	}
 
+	if req.ShardRollout != nil || progress != nil {
+		rollout, err := schemamanager.NewShardRollout(req.GetShardRollout())
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "invalid ShardRollout")
+		}
+		rollout.Progress = progress
+		executor.SetShardRollout(rollout)
+	}
+
 	execResult, err := schemamanager.Run(
 		ctx,
 		schemamanager.NewPlainController(req.Sql, req.Keyspace),
@@ -319,6 +469,13 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 	span.Annotate("cells", strings.Join(req.Cells, ","))
 	span.Annotate("skip_rebuild", req.SkipRebuild)
 	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("staged", req.Staged)
+	span.Annotate("author", req.Author)
+
+	if req.Staged && (len(req.Cells) == 0 || req.SkipRebuild) {
+		err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "staged VSchema changes require cells and may not skip the rebuild")
+		return nil, err
+	}
 
 	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
 		if topo.IsErrType(err, topo.NoNode) {
@@ -406,10 +563,26 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 		return response, err
 	}
 
-	if err = s.ts.SaveVSchema(ctx, req.Keyspace, vs); err != nil {
-		err = vterrors.Wrapf(err, "SaveVSchema(%s, %v)", req.Keyspace, req.VSchema)
+	if req.Staged {
+		err = stageSrvVSchema(ctx, s.ts, req.Cells, func(staged *vschemapb.SrvVSchema) {
+			staged.Keyspaces[req.Keyspace] = vs
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+
+	version, err := s.ts.SaveVSchemaVersion(ctx, req.Keyspace, vs, topo.VSchemaChange{
+		Author:  req.Author,
+		Comment: req.Comment,
+	})
+	if err != nil {
+		err = vterrors.Wrapf(err, "SaveVSchemaVersion(%s, %v)", req.Keyspace, req.VSchema)
 		return nil, err
 	}
+	response.Version = version.GetVersion()
 
 	if !req.SkipRebuild {
 		if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
@@ -552,6 +725,81 @@ func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tabl
 	}
 }
 
+// BackupVerify is part of the vtctlservicepb.VtctldServer interface. The
+// backup is read, and restored in the scratch directory if requested, by this
+// vtctld, on its own host.
+func (s *VtctldServer) BackupVerify(ctx context.Context, req *vtctldatapb.BackupVerifyRequest) (resp *vtctldatapb.BackupVerifyResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.BackupVerify")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	bucket := filepath.Join(req.Keyspace, req.Shard)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("bucket", bucket)
+	span.Annotate("backup_name", req.Name)
+	span.Annotate("scratch_directory", req.ScratchDirectory)
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	bhs, err := bs.ListBackups(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var bh backupstorage.BackupHandle
+	for _, h := range bhs {
+		if h.Name() == req.Name {
+			bh = h
+			break
+		}
+	}
+	if bh == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "backup %v not found in %v", req.Name, bucket)
+	}
+
+	verification, err := mysqlctl.VerifyBackup(ctx, bh, req.ScratchDirectory, logutil.NewConsoleLogger())
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := mysqlctl.RecordBackupVerification(ctx, bs, bh, verification)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.BackupVerifyResponse{
+		Valid:         verification.Valid,
+		Errors:        verification.Errors,
+		VerifiedFiles: uint32(verification.VerifiedFiles),
+		RestoreTested: verification.RestoreTested,
+		Recorded:      recorded,
+	}, nil
+}
+
+// CancelScheduledReparent is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CancelScheduledReparent(ctx context.Context, req *vtctldatapb.CancelScheduledReparentRequest) (resp *vtctldatapb.CancelScheduledReparentResponse, err error) {
+	span, _ := trace.NewSpan(ctx, "VtctldServer.CancelScheduledReparent")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("id", req.Id)
+
+	sr, err := s.scheduledReparents.cancelReparent(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyScheduledReparent(sr)
+
+	return &vtctldatapb.CancelScheduledReparentResponse{ScheduledReparent: sr}, nil
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CancelSchemaMigration(ctx context.Context, req *vtctldatapb.CancelSchemaMigrationRequest) (resp *vtctldatapb.CancelSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CancelSchemaMigration")
@@ -604,6 +852,11 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 
 	span.Annotate("before_tablet_type", topoproto.TabletTypeLString(tablet.Type))
 
+	if topo.IsQuarantined(tablet.Tablet) && req.DbType != topodatapb.TabletType_DRAINED {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "tablet %v is quarantined for its errant GTIDs %v, use ClearTabletQuarantine to change its type", topoproto.TabletAliasString(req.TabletAlias), tablet.Tags[topo.ErrantGTIDsTag])
+		return nil, err
+	}
+
 	if !topo.IsTrivialTypeChange(tablet.Type, req.DbType) {
 		err = fmt.Errorf("tablet %v type change %v -> %v is not an allowed transition for ChangeTabletType", req.TabletAlias, tablet.Type, req.DbType)
 		return nil, err
@@ -681,6 +934,116 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	}, nil
 }
 
+// ChecksumTable is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ChecksumTable(ctx context.Context, req *vtctldatapb.ChecksumTableRequest) (resp *vtctldatapb.ChecksumTableResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ChecksumTable")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+	span.Annotate("max_rows_per_second", req.MaxRowsPerSecond)
+
+	if req.Table == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table must be specified")
+	}
+	chunkSize := req.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultTableChecksumChunkSize
+	}
+	tabletType := req.TabletType
+	if tabletType == topodatapb.TabletType_UNKNOWN {
+		tabletType = topodatapb.TabletType_REPLICA
+	}
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace, nil)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*topo.ShardInfo
+	if len(req.Shards) == 0 {
+		for _, si := range shards {
+			if si.IsPrimaryServing {
+				sources = append(sources, si)
+			}
+		}
+	} else {
+		for _, name := range req.Shards {
+			si, ok := shards[name]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "shard %s/%s not found", req.Keyspace, name)
+			}
+			sources = append(sources, si)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no serving shard", req.Keyspace)
+	}
+
+	tablets := make([]*topodatapb.Tablet, 0, len(sources))
+	for _, si := range sources {
+		tablet, err := s.readTablet(ctx, si, tabletType)
+		if err != nil {
+			return nil, err
+		}
+		tablets = append(tablets, tablet)
+	}
+	table, err := s.checksumTableDefinition(ctx, tablets, req.Table)
+	if err != nil {
+		return nil, err
+	}
+	executors := make([]tablechecksum.Executor, 0, len(tablets))
+	for _, tablet := range tablets {
+		executors = append(executors, s.checksumTableExecutor(tablet))
+	}
+
+	boundaries := make([]tablechecksum.Boundary, 0, len(req.Boundaries))
+	for _, boundary := range req.Boundaries {
+		if len(boundary.Values) != len(table.PrimaryKeyColumns) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "boundary has %d values but the primary key of table %s has %d columns", len(boundary.Values), req.Table, len(table.PrimaryKeyColumns))
+		}
+		values := make(tablechecksum.Boundary, 0, len(boundary.Values))
+		for _, value := range boundary.Values {
+			values = append(values, sqltypes.ProtoToValue(value))
+		}
+		boundaries = append(boundaries, values)
+	}
+	if len(boundaries) == 0 {
+		boundaries, err = tablechecksum.Boundaries(ctx, s.env.CollationEnv(), executors, table, chunkSize, req.MaxRowsPerSecond)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chunks, err := tablechecksum.Checksum(ctx, executors, table, boundaries, req.MaxRowsPerSecond)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.ChecksumTableResponse{
+		Columns:           table.Columns,
+		PrimaryKeyColumns: table.PrimaryKeyColumns,
+		Boundaries:        make([]*vtctldatapb.TableChecksumBoundary, 0, len(boundaries)),
+		Chunks:            make([]*vtctldatapb.TableChecksumChunk, 0, len(chunks)),
+	}
+	for _, boundary := range boundaries {
+		values := make([]*querypb.Value, 0, len(boundary))
+		for _, value := range boundary {
+			values = append(values, sqltypes.ValueToProto(value))
+		}
+		resp.Boundaries = append(resp.Boundaries, &vtctldatapb.TableChecksumBoundary{Values: values})
+	}
+	for _, chunk := range chunks {
+		resp.Chunks = append(resp.Chunks, &vtctldatapb.TableChecksumChunk{RowCount: chunk.Rows, Checksum: chunk.Checksum})
+		resp.RowCount += chunk.Rows
+		resp.Checksum += chunk.Checksum
+	}
+
+	return resp, nil
+}
+
 // CleanupSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CleanupSchemaMigration(ctx context.Context, req *vtctldatapb.CleanupSchemaMigrationRequest) (resp *vtctldatapb.CleanupSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CleanupSchemaMigration")
@@ -712,6 +1075,58 @@ func (s *VtctldServer) CleanupSchemaMigration(ctx context.Context, req *vtctldat
 	return resp, nil
 }
 
+// ClearTabletQuarantine is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ClearTabletQuarantine(ctx context.Context, req *vtctldatapb.ClearTabletQuarantineRequest) (resp *vtctldatapb.ClearTabletQuarantineResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ClearTabletQuarantine")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	tabletType := req.TabletType
+	if tabletType == topodatapb.TabletType_UNKNOWN {
+		tabletType = topodatapb.TabletType_REPLICA
+	}
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("tablet_type", topoproto.TabletTypeLString(tabletType))
+
+	tablet, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+	if !topo.IsQuarantined(tablet.Tablet) {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "tablet %v is not quarantined", topoproto.TabletAliasString(req.TabletAlias))
+		return nil, err
+	}
+	if !topo.IsTrivialTypeChange(tablet.Type, tabletType) || tabletType == topodatapb.TabletType_DRAINED {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot change the type of quarantined tablet %v to %v", topoproto.TabletAliasString(req.TabletAlias), topoproto.TabletTypeLString(tabletType))
+		return nil, err
+	}
+
+	log.Infof("Clearing the quarantine of tablet %v for its errant GTIDs %v", topoproto.TabletAliasString(req.TabletAlias), tablet.Tags[topo.ErrantGTIDsTag])
+	if _, err = s.ts.UpdateTabletFields(ctx, req.TabletAlias, func(tablet *topodatapb.Tablet) error {
+		if _, ok := tablet.Tags[topo.ErrantGTIDsTag]; !ok {
+			return topo.NewError(topo.NoUpdateNeeded, topoproto.TabletAliasString(req.TabletAlias))
+		}
+		delete(tablet.Tags, topo.ErrantGTIDsTag)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	changed, err := s.ChangeTabletType(ctx, &vtctldatapb.ChangeTabletTypeRequest{
+		TabletAlias: req.TabletAlias,
+		DbType:      tabletType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ClearTabletQuarantineResponse{
+		Tablet: changed.AfterTablet,
+	}, nil
+}
+
 // ForceCutOverSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ForceCutOverSchemaMigration(ctx context.Context, req *vtctldatapb.ForceCutOverSchemaMigrationRequest) (resp *vtctldatapb.ForceCutOverSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ForceCutOverSchemaMigration")
@@ -786,9 +1201,16 @@ func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.Crea
 	span.Annotate("force", req.Force)
 	span.Annotate("allow_empty_vschema", req.AllowEmptyVSchema)
 	span.Annotate("durability_policy", req.DurabilityPolicy)
+	span.Annotate("twopc_enabled", req.TwopcEnabled)
+	span.Annotate("default_charset", req.DefaultCharset)
+	span.Annotate("default_collation", req.DefaultCollation)
 
 	switch req.Type {
 	case topodatapb.KeyspaceType_NORMAL:
+		if req.SnapshotBinlogSource != nil {
+			err = errors.New("SnapshotBinlogSource is only supported for SNAPSHOT keyspaces")
+			return nil, err
+		}
 	case topodatapb.KeyspaceType_SNAPSHOT:
 		if req.BaseKeyspace == "" {
 			err = errors.New("BaseKeyspace is required for SNAPSHOT keyspaces")
@@ -802,16 +1224,28 @@ func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.Crea
 
 		span.Annotate("base_keyspace", req.BaseKeyspace)
 		span.Annotate("snapshot_time", protoutil.TimeFromProto(req.SnapshotTime).String())
+		if req.SnapshotBinlogSource != nil {
+			span.Annotate("snapshot_binlog_source", netutil.JoinHostPort(req.SnapshotBinlogSource.Host, req.SnapshotBinlogSource.Port))
+		}
 	default:
 		return nil, fmt.Errorf("unknown keyspace type %v", req.Type)
 	}
 
+	charset, collation, err := schema.ValidateKeyspaceCharset(s.env.CollationEnv(), req.DefaultCharset, req.DefaultCollation)
+	if err != nil {
+		return nil, err
+	}
+
 	ki := &topodatapb.Keyspace{
-		KeyspaceType:     req.Type,
-		BaseKeyspace:     req.BaseKeyspace,
-		SnapshotTime:     req.SnapshotTime,
-		DurabilityPolicy: req.DurabilityPolicy,
-		SidecarDbName:    req.SidecarDbName,
+		KeyspaceType:         req.Type,
+		BaseKeyspace:         req.BaseKeyspace,
+		SnapshotTime:         req.SnapshotTime,
+		SnapshotBinlogSource: req.SnapshotBinlogSource,
+		DurabilityPolicy:     req.DurabilityPolicy,
+		SidecarDbName:        req.SidecarDbName,
+		TwopcEnabled:         req.TwopcEnabled,
+		DefaultCharset:       charset,
+		DefaultCollation:     collation,
 	}
 
 	err = s.ts.CreateKeyspace(ctx, req.Name, ki)
@@ -1132,6 +1566,50 @@ func (s *VtctldServer) DeleteTablets(ctx context.Context, req *vtctldatapb.Delet
 	return &vtctldatapb.DeleteTabletsResponse{}, nil
 }
 
+// DiffVSchemaVersions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DiffVSchemaVersions(ctx context.Context, req *vtctldatapb.DiffVSchemaVersionsRequest) (resp *vtctldatapb.DiffVSchemaVersionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DiffVSchemaVersions")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("from_version", req.FromVersion)
+	span.Annotate("to_version", req.ToVersion)
+
+	if req.FromVersion <= 0 || req.ToVersion < 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid versions %d and %d of the vschema history", req.FromVersion, req.ToVersion)
+		return nil, err
+	}
+
+	from, err := s.ts.GetVSchemaVersion(ctx, req.Keyspace, req.FromVersion)
+	if err != nil {
+		err = vterrors.Wrapf(err, "GetVSchemaVersion(%s, %d)", req.Keyspace, req.FromVersion)
+		return nil, err
+	}
+
+	var to *vschemapb.Keyspace
+	if req.ToVersion == 0 {
+		to, err = s.ts.GetVSchema(ctx, req.Keyspace)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			err = vterrors.Wrapf(err, "GetVSchema(%s)", req.Keyspace)
+			return nil, err
+		}
+	} else {
+		var version *vschemapb.VSchemaVersion
+		version, err = s.ts.GetVSchemaVersion(ctx, req.Keyspace, req.ToVersion)
+		if err != nil {
+			err = vterrors.Wrapf(err, "GetVSchemaVersion(%s, %d)", req.Keyspace, req.ToVersion)
+			return nil, err
+		}
+		to = version.Vschema
+	}
+
+	return &vtctldatapb.DiffVSchemaVersionsResponse{
+		Diff: topo.DiffVSchemas(from.Vschema, to),
+	}, nil
+}
+
 // EmergencyReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldatapb.EmergencyReparentShardRequest) (resp *vtctldatapb.EmergencyReparentShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.EmergencyReparentShard")
@@ -1156,6 +1634,7 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 	span.Annotate("wait_replicas_timeout_sec", waitReplicasTimeout.Seconds())
 	span.Annotate("prevent_cross_cell_promotion", req.PreventCrossCellPromotion)
 	span.Annotate("wait_for_all_tablets", req.WaitForAllTablets)
+	span.Annotate("quarantine_errant_replicas", req.QuarantineErrantReplicas)
 
 	m := sync.RWMutex{}
 	logstream := []*logutilpb.Event{}
@@ -1175,6 +1654,7 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 			WaitReplicasTimeout:       waitReplicasTimeout,
 			WaitAllTablets:            req.WaitForAllTablets,
 			PreventCrossCellPromotion: req.PreventCrossCellPromotion,
+			QuarantineErrantReplicas:  req.QuarantineErrantReplicas,
 		},
 	)
 
@@ -1355,8 +1835,64 @@ func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctlda
 	}, nil
 }
 
-// GetBackups is part of the vtctldservicepb.VtctldServer interface.
-func (s *VtctldServer) GetBackups(ctx context.Context, req *vtctldatapb.GetBackupsRequest) (resp *vtctldatapb.GetBackupsResponse, err error) {
+// FreezeShardWrites is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) FreezeShardWrites(ctx context.Context, req *vtctldatapb.FreezeShardWritesRequest) (resp *vtctldatapb.FreezeShardWritesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.FreezeShardWrites")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("reason", req.Reason)
+	span.Annotate("buffer", req.Buffer)
+
+	if req.Reason == "" {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "FreezeShardWrites.Reason is required")
+		return nil, err
+	}
+
+	ctx, unlock, lockErr := s.ts.LockShard(ctx, req.Keyspace, req.Shard, "FreezeShardWrites")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	si, err := s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+	if err != nil {
+		return nil, err
+	}
+	if si.WriteFreeze != nil {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "writes to shard %s/%s are already frozen: %s", req.Keyspace, req.Shard, si.WriteFreeze.Reason)
+		return nil, err
+	}
+	if si.PrimaryAlias == nil {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "shard %s/%s has no primary", req.Keyspace, req.Shard)
+		return nil, err
+	}
+
+	si, err = setShardWriteFreeze(ctx, s.ts, s.tmc, si, &topodatapb.Shard_WriteFreeze{
+		Reason:   req.Reason,
+		FrozenAt: protoutil.TimeToProto(time.Now()),
+		Buffer:   req.Buffer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.FreezeShardWritesResponse{
+		Shard: &vtctldatapb.Shard{
+			Keyspace: req.Keyspace,
+			Name:     req.Shard,
+			Shard:    si.Shard,
+		},
+	}, nil
+}
+
+// GetBackups is part of the vtctldservicepb.VtctldServer interface.
+func (s *VtctldServer) GetBackups(ctx context.Context, req *vtctldatapb.GetBackupsRequest) (resp *vtctldatapb.GetBackupsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetBackups")
 	defer span.Finish()
 
@@ -1522,6 +2058,114 @@ func (s *VtctldServer) GetKeyspace(ctx context.Context, req *vtctldatapb.GetKeys
 	}, nil
 }
 
+// GetKeyspaceCharsetReport is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetKeyspaceCharsetReport(ctx context.Context, req *vtctldatapb.GetKeyspaceCharsetReportRequest) (resp *vtctldatapb.GetKeyspaceCharsetReportResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaceCharsetReport")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("exclude_tables", strings.Join(req.ExcludeTables, ","))
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if ki.DefaultCharset == "" && ki.DefaultCollation == "" {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no default character set or collation", req.Keyspace)
+		return nil, err
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	resp = &vtctldatapb.GetKeyspaceCharsetReportResponse{
+		DefaultCharset:    ki.DefaultCharset,
+		DefaultCollation:  ki.DefaultCollation,
+		ConvertStatements: map[string]string{},
+	}
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			deviations, err := s.shardCharsetDeviations(ctx, ki, shard, req.ExcludeTables)
+			if err != nil {
+				rec.RecordError(fmt.Errorf("%s/%s: %w", req.Keyspace, shard, err))
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			for _, deviation := range deviations {
+				resp.Deviations = append(resp.Deviations, &vtctldatapb.GetKeyspaceCharsetReportResponse_Deviation{
+					Shard:     shard,
+					Table:     deviation.Table,
+					Column:    deviation.Column,
+					Charset:   deviation.Charset,
+					Collation: deviation.Collation,
+				})
+				resp.ConvertStatements[deviation.Table] = schema.ConvertCharsetStatement(s.env.CollationEnv(), deviation.Table, ki.DefaultCharset, ki.DefaultCollation)
+			}
+		}(shard)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		err = rec.Error()
+		return nil, err
+	}
+
+	slices.SortFunc(resp.Deviations, func(a, b *vtctldatapb.GetKeyspaceCharsetReportResponse_Deviation) int {
+		if c := strings.Compare(a.Table, b.Table); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Column, b.Column); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Shard, b.Shard)
+	})
+	return resp, nil
+}
+
+// shardCharsetDeviations returns the character sets and collations of the
+// tables of the shard which differ from the default ones of the keyspace, as
+// shown by the CREATE TABLE statements of its primary.
+func (s *VtctldServer) shardCharsetDeviations(ctx context.Context, ki *topo.KeyspaceInfo, shard string, excludeTables []string) ([]*schema.CharsetDeviation, error) {
+	si, err := s.ts.GetShard(ctx, ki.KeyspaceName(), shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "shard has no primary")
+	}
+
+	sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, si.PrimaryAlias, &tabletmanagerdatapb.GetSchemaRequest{ExcludeTables: excludeTables, TableSchemaOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var deviations []*schema.CharsetDeviation
+	for _, td := range sd.TableDefinitions {
+		if td.Type != tmutils.TableBaseTable || schema.IsInternalOperationTableName(td.Name) {
+			continue
+		}
+		stmt, err := s.env.Parser().ParseStrictDDL(td.Schema)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot parse the schema of table %s", td.Name)
+		}
+		deviations = append(deviations, schema.CharsetDeviations(s.env.CollationEnv(), stmt, ki.DefaultCharset, ki.DefaultCollation)...)
+	}
+	return deviations, nil
+}
+
 // GetKeyspaces is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKeyspacesRequest) (resp *vtctldatapb.GetKeyspacesResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaces")
@@ -1573,6 +2217,59 @@ func (s *VtctldServer) GetPermissions(ctx context.Context, req *vtctldatapb.GetP
 	}, nil
 }
 
+// GetPlanCacheControl is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetPlanCacheControl(ctx context.Context, req *vtctldatapb.GetPlanCacheControlRequest) (resp *vtctldatapb.GetPlanCacheControlResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetPlanCacheControl")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	control, err := s.ts.GetPlanCacheControl(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetPlanCacheControlResponse{
+		PlanCacheControl: control,
+	}, nil
+}
+
+// GetQuarantinedTablets is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetQuarantinedTablets(ctx context.Context, req *vtctldatapb.GetQuarantinedTabletsRequest) (resp *vtctldatapb.GetQuarantinedTabletsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetQuarantinedTablets")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+
+	shards := []string{req.Shard}
+	if req.Shard == "" {
+		shards, err = s.ts.GetShardNames(ctx, req.Keyspace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp = &vtctldatapb.GetQuarantinedTabletsResponse{}
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, fmt.Errorf("GetTabletMapForShard(%s, %s) failed: %w", req.Keyspace, shard, err)
+		}
+		for _, ti := range tabletMap {
+			if topo.IsQuarantined(ti.Tablet) {
+				resp.Tablets = append(resp.Tablets, ti.Tablet)
+			}
+		}
+	}
+	sort.Slice(resp.Tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(resp.Tablets[i].Alias) < topoproto.TabletAliasString(resp.Tablets[j].Alias)
+	})
+	return resp, nil
+}
+
 // GetRoutingRules is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetRoutingRules(ctx context.Context, req *vtctldatapb.GetRoutingRulesRequest) (resp *vtctldatapb.GetRoutingRulesResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetRoutingRules")
@@ -1605,6 +2302,21 @@ func (s *VtctldServer) GetShardRoutingRules(ctx context.Context, req *vtctldatap
 	}, nil
 }
 
+// GetScheduledReparents is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetScheduledReparents(ctx context.Context, req *vtctldatapb.GetScheduledReparentsRequest) (resp *vtctldatapb.GetScheduledReparentsResponse, err error) {
+	span, _ := trace.NewSpan(ctx, "VtctldServer.GetScheduledReparents")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+
+	return &vtctldatapb.GetScheduledReparentsResponse{
+		ScheduledReparents: s.scheduledReparents.list(req.Keyspace, req.Shard),
+	}, nil
+}
+
 // GetSchema is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchemaRequest) (resp *vtctldatapb.GetSchemaResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchema")
@@ -1641,10 +2353,12 @@ func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchema
 
 		for i, td := range sd.TableDefinitions {
 			sizeTds[i] = &tabletmanagerdatapb.TableDefinition{
-				Name:       td.Name,
-				Type:       td.Type,
-				RowCount:   td.RowCount,
-				DataLength: td.DataLength,
+				Name:        td.Name,
+				Type:        td.Type,
+				RowCount:    td.RowCount,
+				DataLength:  td.DataLength,
+				IndexLength: td.IndexLength,
+				Partitions:  td.Partitions,
 			}
 		}
 
@@ -1656,6 +2370,178 @@ func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchema
 	}, nil
 }
 
+// GetSchemaAtPosition is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSchemaAtPosition(ctx context.Context, req *vtctldatapb.GetSchemaAtPositionRequest) (resp *vtctldatapb.GetSchemaAtPositionResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchemaAtPosition")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("position", req.Position)
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+
+	pos, err := replication.DecodePosition(req.Position)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid position %s: %v", req.Position, err)
+	}
+	if pos.IsZero() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "position is required")
+	}
+	if _, ok := pos.GTIDSet.(replication.Mysql56GTIDSet); !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "GetSchemaAtPosition only supports MySQL GTID positions, got %s", req.Position)
+	}
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	ki, err := s.ts.GetKeyspace(ctx, ti.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sidecarDBName := ki.SidecarDbName
+	if sidecarDBName == "" {
+		sidecarDBName = sidecar.DefaultName
+	}
+
+	query, err := selectSchemaVersionAtPositionQuery(sidecarDBName, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	qr, err := s.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(query),
+		MaxRows: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = rowToSchemaVersion(sqltypes.Proto3ToResult(qr))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no schema version tracked at or before position %s on tablet %v (is --track_schema_versions enabled?)", req.Position, topoproto.TabletAliasString(req.TabletAlias))
+	}
+
+	if len(req.Tables) > 0 {
+		tables := sets.New(req.Tables...)
+		filtered := make([]*binlogdatapb.MinimalTable, 0, len(req.Tables))
+		for _, t := range resp.Tables {
+			if tables.Has(t.Name) {
+				filtered = append(filtered, t)
+			}
+		}
+		resp.Tables = filtered
+	}
+
+	return resp, nil
+}
+
+// GetSchemaDrift is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSchemaDrift(ctx context.Context, req *vtctldatapb.GetSchemaDriftRequest) (resp *vtctldatapb.GetSchemaDriftResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchemaDrift")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("exclude_tables", strings.Join(req.ExcludeTables, ","))
+	span.Annotate("include_views", req.IncludeViews)
+	span.Annotate("concurrency", req.Concurrency)
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+
+	var (
+		referenceAlias *topodatapb.TabletAlias
+		tablets        []*topo.TabletInfo
+	)
+	for _, shard := range shards {
+		si, err := s.ts.GetShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		if referenceAlias == nil && si.HasPrimary() {
+			referenceAlias = si.PrimaryAlias
+		}
+
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, req.Keyspace, shard)
+		if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+			return nil, fmt.Errorf("GetTabletMapForShard(%s, %s) failed: %w", req.Keyspace, shard, err)
+		}
+		shardTablets := make([]*topo.TabletInfo, 0, len(tabletMap))
+		for _, ti := range tabletMap {
+			shardTablets = append(shardTablets, ti)
+		}
+		sort.Slice(shardTablets, func(i, j int) bool {
+			return topoproto.TabletAliasString(shardTablets[i].Alias) < topoproto.TabletAliasString(shardTablets[j].Alias)
+		})
+		tablets = append(tablets, shardTablets...)
+	}
+	if referenceAlias == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no primary in keyspace %s", req.Keyspace)
+	}
+
+	r := &tabletmanagerdatapb.GetSchemaRequest{ExcludeTables: req.ExcludeTables, IncludeViews: req.IncludeViews, TableSchemaOnly: true}
+	referenceSchema, err := schematools.GetSchema(ctx, s.ts, s.tmc, referenceAlias, r)
+	if err != nil {
+		return nil, fmt.Errorf("GetSchema(%v) failed: %w", topoproto.TabletAliasString(referenceAlias), err)
+	}
+
+	concurrency := defaultGetSchemaDriftConcurrency
+	if req.Concurrency > 0 {
+		concurrency = int(req.Concurrency)
+	}
+
+	env := schemadiff.NewEnv(s.env, s.env.CollationEnv().DefaultConnectionCharset())
+	drifts := make([]*vtctldatapb.TabletSchemaDrift, len(tablets))
+	eg := errgroup.Group{}
+	eg.SetLimit(concurrency)
+	for i, ti := range tablets {
+		if topoproto.TabletAliasEqual(ti.Alias, referenceAlias) {
+			continue
+		}
+		eg.Go(func() error {
+			drift := &vtctldatapb.TabletSchemaDrift{
+				TabletAlias: ti.Alias,
+				Shard:       ti.Shard,
+				TabletType:  ti.Type,
+			}
+			sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, ti.Alias, r)
+			if err != nil {
+				drift.Error = err.Error()
+			} else {
+				drift.Tables = diffSchemaDrift(env, referenceSchema, sd)
+			}
+			if drift.Error != "" || len(drift.Tables) > 0 {
+				drifts[i] = drift
+			}
+			// The tablets whose schema could not be read are reported, without
+			// failing the whole comparison.
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	resp = &vtctldatapb.GetSchemaDriftResponse{
+		ReferenceTablet: referenceAlias,
+	}
+	for _, drift := range drifts {
+		if drift != nil {
+			resp.Tablets = append(resp.Tablets, drift)
+		}
+	}
+
+	return resp, nil
+}
+
 func (s *VtctldServer) GetSchemaMigrations(ctx context.Context, req *vtctldatapb.GetSchemaMigrationsRequest) (resp *vtctldatapb.GetSchemaMigrationsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShard")
 	defer span.Finish()
@@ -1835,6 +2721,115 @@ func (s *VtctldServer) GetShardReplication(ctx context.Context, req *vtctldatapb
 	}, nil
 }
 
+// GetShardWriteFreezeStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetShardWriteFreezeStatus(ctx context.Context, req *vtctldatapb.GetShardWriteFreezeStatusRequest) (resp *vtctldatapb.GetShardWriteFreezeStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShardWriteFreezeStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+
+	si, err := s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.GetShardWriteFreezeStatusResponse{
+		WriteFreeze:  si.WriteFreeze,
+		PrimaryAlias: si.PrimaryAlias,
+	}
+	if si.PrimaryAlias == nil {
+		return resp, nil
+	}
+
+	primary, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		resp.PrimaryError = err.Error()
+		return resp, nil
+	}
+	status, err := s.tmc.FullStatus(ctx, primary.Tablet)
+	if err != nil {
+		resp.PrimaryError = err.Error()
+		return resp, nil
+	}
+	resp.PrimarySuperReadOnly = status.SuperReadOnly
+
+	return resp, nil
+}
+
+// GetSnapshotKeyspaceStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSnapshotKeyspaceStatus(ctx context.Context, req *vtctldatapb.GetSnapshotKeyspaceStatusRequest) (resp *vtctldatapb.GetSnapshotKeyspaceStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSnapshotKeyspaceStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if ki.KeyspaceType != topodatapb.KeyspaceType_SNAPSHOT {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is not a SNAPSHOT keyspace", req.Keyspace)
+		return nil, err
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m  sync.Mutex
+		wg sync.WaitGroup
+	)
+	resp = &vtctldatapb.GetSnapshotKeyspaceStatusResponse{}
+	for _, shard := range shards {
+		tablets, err := s.ts.GetTabletMapForShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		for _, ti := range tablets {
+			wg.Add(1)
+			go func(ti *topo.TabletInfo) {
+				defer wg.Done()
+				tabletStatus := &vtctldatapb.GetSnapshotKeyspaceStatusResponse_TabletStatus{
+					TabletAlias: ti.Alias,
+					Shard:       ti.Shard,
+					TabletType:  ti.Type,
+				}
+				// The status of the tablets which are restoring a backup can't be
+				// fetched, as their mysqld is stopped.
+				fullStatus, err := s.tmc.FullStatus(ctx, ti.Tablet)
+				if err != nil {
+					tabletStatus.Error = err.Error()
+				} else {
+					tabletStatus.Status = fullStatus.SnapshotRestore
+				}
+
+				m.Lock()
+				defer m.Unlock()
+				resp.Tablets = append(resp.Tablets, tabletStatus)
+			}(ti)
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(resp.Tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(resp.Tablets[i].TabletAlias) < topoproto.TabletAliasString(resp.Tablets[j].TabletAlias)
+	})
+	resp.CaughtUp = len(resp.Tablets) > 0
+	for _, tablet := range resp.Tablets {
+		if tablet.Status.GetPhase() != replicationdatapb.SnapshotRestoreStatus_CAUGHT_UP {
+			resp.CaughtUp = false
+		}
+	}
+	return resp, nil
+}
+
 // GetSrvKeyspaceNames is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetSrvKeyspaceNames(ctx context.Context, req *vtctldatapb.GetSrvKeyspaceNamesRequest) (resp *vtctldatapb.GetSrvKeyspaceNamesResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvKeyspaceNames")
@@ -1989,22 +2984,75 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 	return &vtctldatapb.UpdateThrottlerConfigResponse{}, err
 }
 
-// GetSrvVSchema is part of the vtctlservicepb.VtctldServer interface.
-func (s *VtctldServer) GetSrvVSchema(ctx context.Context, req *vtctldatapb.GetSrvVSchemaRequest) (resp *vtctldatapb.GetSrvVSchemaResponse, err error) {
-	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvVSchema")
+// GetStatementACL is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetStatementACL(ctx context.Context, req *vtctldatapb.GetStatementACLRequest) (resp *vtctldatapb.GetStatementACLResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetStatementACL")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	acl, err := s.ts.GetStatementACL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetStatementACLResponse{
+		StatementAcl: acl,
+	}, nil
+}
+
+// GetTenantCatalog is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetTenantCatalog(ctx context.Context, req *vtctldatapb.GetTenantCatalogRequest) (resp *vtctldatapb.GetTenantCatalogResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTenantCatalog")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	catalog, err := s.ts.GetTenantCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetTenantCatalogResponse{
+		TenantCatalog: catalog,
+	}, nil
+}
+
+// GetSrvVSchema is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSrvVSchema(ctx context.Context, req *vtctldatapb.GetSrvVSchemaRequest) (resp *vtctldatapb.GetSrvVSchemaResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvVSchema")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cell", req.Cell)
+
+	vschema, err := s.ts.GetSrvVSchema(ctx, req.Cell)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetSrvVSchemaResponse{
+		SrvVSchema: vschema,
+	}, nil
+}
+
+// GetSrvVSchemaDiffs is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSrvVSchemaDiffs(ctx context.Context, req *vtctldatapb.GetSrvVSchemaDiffsRequest) (resp *vtctldatapb.GetSrvVSchemaDiffsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSrvVSchemaDiffs")
 	defer span.Finish()
 
 	defer panicHandler(&err)
 
-	span.Annotate("cell", req.Cell)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
 
-	vschema, err := s.ts.GetSrvVSchema(ctx, req.Cell)
+	diffs, _, err := getSrvVSchemaDiffs(ctx, s.ts, req.Cells)
 	if err != nil {
 		return nil, err
 	}
 
-	return &vtctldatapb.GetSrvVSchemaResponse{
-		SrvVSchema: vschema,
+	return &vtctldatapb.GetSrvVSchemaDiffsResponse{
+		Diffs: diffs,
 	}, nil
 }
 
@@ -2087,6 +3135,9 @@ func (s *VtctldServer) GetTablets(ctx context.Context, req *vtctldatapb.GetTable
 	if req.TabletType != topodatapb.TabletType_UNKNOWN {
 		span.Annotate("tablet_type", topodatapb.TabletType_name[int32(req.TabletType)])
 	}
+	if len(req.Tags) > 0 {
+		span.Annotate("tags", fmt.Sprintf("%v", req.Tags))
+	}
 	span.Annotate("strict", req.Strict)
 
 	// It is possible that an old primary has not yet updated its type in the
@@ -2156,6 +3207,9 @@ func (s *VtctldServer) GetTablets(ctx context.Context, req *vtctldatapb.GetTable
 			if req.TabletType != topodatapb.TabletType_UNKNOWN && ti.Type != req.TabletType {
 				continue
 			}
+			if !topoproto.TabletHasTags(ti.Tablet, req.Tags) {
+				continue
+			}
 			adjustTypeForStalePrimary(ti, truePrimaryTimestamp)
 			tablets = append(tablets, ti.Tablet)
 		}
@@ -2224,6 +3278,9 @@ func (s *VtctldServer) GetTablets(ctx context.Context, req *vtctldatapb.GetTable
 		if req.TabletType != topodatapb.TabletType_UNKNOWN && tablet.Type != req.TabletType {
 			continue
 		}
+		if !topoproto.TabletHasTags(tablet.Tablet, req.Tags) {
+			continue
+		}
 
 		key := tablet.Keyspace + "." + tablet.Shard
 		if v, ok := PrimaryTermStartTimes[key]; ok {
@@ -2329,6 +3386,25 @@ func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSche
 	}, nil
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVSchemaHistory(ctx context.Context, req *vtctldatapb.GetVSchemaHistoryRequest) (resp *vtctldatapb.GetVSchemaHistoryResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVSchemaHistory")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	versions, err := s.ts.GetVSchemaHistory(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetVSchemaHistoryResponse{
+		Versions: versions,
+	}, nil
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (resp *vtctldatapb.GetWorkflowsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetWorkflows")
@@ -2344,6 +3420,41 @@ func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWor
 	return resp, err
 }
 
+// InvalidateQueryPlans is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) InvalidateQueryPlans(ctx context.Context, req *vtctldatapb.InvalidateQueryPlansRequest) (resp *vtctldatapb.InvalidateQueryPlansResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.InvalidateQueryPlans")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+
+	if len(req.Tables) == 0 || slices.Contains(req.Tables, "") {
+		err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "tables are required")
+		return nil, err
+	}
+
+	invalidation := &vtgatepb.PlanInvalidation{Tables: req.Tables}
+	_, err = s.ts.UpdatePlanCacheControl(ctx, func(control *vtgatepb.PlanCacheControl) error {
+		invalidation.Id = 1
+		if n := len(control.Invalidations); n > 0 {
+			invalidation.Id = control.Invalidations[n-1].Id + 1
+		}
+		control.Invalidations = append(control.Invalidations, invalidation)
+		if n := len(control.Invalidations); n > planInvalidationHistorySize {
+			control.Invalidations = control.Invalidations[n-planInvalidationHistorySize:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.InvalidateQueryPlansResponse{
+		Invalidation: invalidation,
+	}, nil
+}
+
 // InitShardPrimary is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) InitShardPrimary(ctx context.Context, req *vtctldatapb.InitShardPrimaryRequest) (resp *vtctldatapb.InitShardPrimaryResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.InitShardPrimary")
@@ -2685,6 +3796,52 @@ func (s *VtctldServer) LookupVindexExternalize(ctx context.Context, req *vtctlda
 	return resp, err
 }
 
+// LookupVindexComplete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexComplete(ctx context.Context, req *vtctldatapb.LookupVindexCompleteRequest) (resp *vtctldatapb.LookupVindexCompleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexComplete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+
+	resp, err = s.ws.LookupVindexComplete(ctx, req)
+	return resp, err
+}
+
+// LookupVindexRevert is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexRevert(ctx context.Context, req *vtctldatapb.LookupVindexRevertRequest) (resp *vtctldatapb.LookupVindexRevertResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexRevert")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+	span.Annotate("keep_data", req.KeepData)
+
+	resp, err = s.ws.LookupVindexRevert(ctx, req)
+	return resp, err
+}
+
+// LookupVindexStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexStatus(ctx context.Context, req *vtctldatapb.LookupVindexStatusRequest) (resp *vtctldatapb.LookupVindexStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+
+	resp, err = s.ws.LookupVindexStatus(ctx, req)
+	return resp, err
+}
+
 // MaterializeCreate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) MaterializeCreate(ctx context.Context, req *vtctldatapb.MaterializeCreateRequest) (resp *vtctldatapb.MaterializeCreateResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.MaterializeCreate")
@@ -2829,6 +3986,175 @@ func (s *VtctldServer) PingTablet(ctx context.Context, req *vtctldatapb.PingTabl
 	return &vtctldatapb.PingTabletResponse{}, nil
 }
 
+// PinQueryPlan is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) PinQueryPlan(ctx context.Context, req *vtctldatapb.PinQueryPlanRequest) (resp *vtctldatapb.PinQueryPlanResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.PinQueryPlan")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	pin, err := s.pinnedPlan(req.Keyspace, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	control, err := s.ts.UpdatePlanCacheControl(ctx, func(control *vtgatepb.PlanCacheControl) error {
+		if !slices.ContainsFunc(control.PinnedPlans, pin.matches) {
+			control.PinnedPlans = append(control.PinnedPlans, pin.PinnedPlan)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.PinQueryPlanResponse{
+		PlanCacheControl: control,
+	}, nil
+}
+
+type pinnedPlan struct {
+	*vtgatepb.PinnedPlan
+}
+
+func (p pinnedPlan) matches(other *vtgatepb.PinnedPlan) bool {
+	return p.Keyspace == other.Keyspace && p.Query == other.Query
+}
+
+// pinnedPlan returns the pinned plan of query in keyspace, formatting the
+// query like vtgate formats the queries of its plans.
+func (s *VtctldServer) pinnedPlan(keyspace string, query string) (pinnedPlan, error) {
+	stmt, err := s.ws.SQLParser().Parse(query)
+	if err != nil {
+		return pinnedPlan{}, vterrors.Wrapf(err, "Parse(%s)", query)
+	}
+	return pinnedPlan{&vtgatepb.PinnedPlan{
+		Keyspace: keyspace,
+		Query:    sqlparser.String(stmt),
+	}}, nil
+}
+
+// PlanReshard is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) PlanReshard(ctx context.Context, req *vtctldatapb.PlanReshardRequest) (resp *vtctldatapb.PlanReshardResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.PlanReshard")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("source_shards", strings.Join(req.SourceShards, ","))
+	span.Annotate("shard_count", req.ShardCount)
+
+	if req.ShardCount < 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "shard count must be positive, got %d", req.ShardCount)
+	}
+	sampleSize := req.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultReshardPlanSampleSize
+	}
+	keyBytes := int(req.KeyBytes)
+	if keyBytes <= 0 {
+		keyBytes = defaultReshardPlanKeyBytes
+	}
+	tabletType := req.TabletType
+	if tabletType == topodatapb.TabletType_UNKNOWN {
+		tabletType = topodatapb.TabletType_REPLICA
+	}
+
+	vs, err := s.ts.GetVSchema(ctx, req.Keyspace)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "GetVSchema(%s)", req.Keyspace)
+	}
+	if !vs.Sharded {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is not sharded", req.Keyspace)
+	}
+	ksVs, err := vindexes.BuildKeyspace(vs, s.ws.SQLParser())
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "BuildKeyspace(%s)", req.Keyspace)
+	}
+	tables, err := reshardPlanTables(ksVs, req.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace, nil)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*topo.ShardInfo
+	if len(req.SourceShards) == 0 {
+		for _, si := range shards {
+			if si.IsPrimaryServing {
+				sources = append(sources, si)
+			}
+		}
+	} else {
+		for _, name := range req.SourceShards {
+			si, ok := shards[name]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "shard %s/%s not found", req.Keyspace, name)
+			}
+			sources = append(sources, si)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s has no serving shard", req.Keyspace)
+	}
+	keyRange, err := reshardPlanSourceKeyRange(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m       sync.Mutex
+		wg      sync.WaitGroup
+		rec     concurrency.AllErrorRecorder
+		samples []reshardSample
+	)
+	for _, si := range sources {
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+
+			tablet, err := s.readTablet(ctx, si, tabletType)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			shardSamples, err := s.sampleReshardShard(ctx, tablet, tables, sampleSize)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			samples = append(samples, shardSamples...)
+		}(si)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	planned, err := planReshardShards(keyRange, samples, int(req.ShardCount), keyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.PlanReshardResponse{
+		PlannedShards: planned,
+		SampledRows:   int64(len(samples)),
+	}
+	for _, shard := range planned {
+		resp.Shards = append(resp.Shards, shard.Name)
+	}
+
+	return resp, nil
+}
+
 // PlannedReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) PlannedReparentShard(ctx context.Context, req *vtctldatapb.PlannedReparentShardRequest) (resp *vtctldatapb.PlannedReparentShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.PlannedReparentShard")
@@ -2913,27 +4239,161 @@ func (s *VtctldServer) RebuildKeyspaceGraph(ctx context.Context, req *vtctldatap
 	span.Annotate("cells", strings.Join(req.Cells, ","))
 	span.Annotate("allow_partial", req.AllowPartial)
 
-	if err = topotools.RebuildKeyspace(ctx, logutil.NewCallbackLogger(func(e *logutilpb.Event) {}), s.ts, req.Keyspace, req.Cells, req.AllowPartial); err != nil {
+	if err = topotools.RebuildKeyspace(ctx, logutil.NewCallbackLogger(func(e *logutilpb.Event) {}), s.ts, req.Keyspace, req.Cells, req.AllowPartial); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.RebuildKeyspaceGraphResponse{}, nil
+}
+
+// RebuildVSchemaGraph is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RebuildVSchemaGraph(ctx context.Context, req *vtctldatapb.RebuildVSchemaGraphRequest) (resp *vtctldatapb.RebuildVSchemaGraphResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RebuildVSchemaGraph")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+
+	if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.RebuildVSchemaGraphResponse{}, nil
+}
+
+// ReconcileSrvVSchemas is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReconcileSrvVSchemas(ctx context.Context, req *vtctldatapb.ReconcileSrvVSchemasRequest) (resp *vtctldatapb.ReconcileSrvVSchemasResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReconcileSrvVSchemas")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("promote_from_cell", req.PromoteFromCell)
+	span.Annotate("dry_run", req.DryRun)
+
+	diffs, _, err := getSrvVSchemaDiffs(ctx, s.ts, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.ReconcileSrvVSchemasResponse{
+		Diffs: diffs,
+	}
+
+	if req.PromoteFromCell != "" {
+		promoteDiffs, svs, err := getSrvVSchemaDiffs(ctx, s.ts, []string{req.PromoteFromCell})
+		if err != nil {
+			return nil, err
+		}
+
+		promoted, sv := promoteDiffs[req.PromoteFromCell], svs[req.PromoteFromCell]
+		if promoted != nil {
+			// Only the changes ApplyVSchema and ApplyRoutingRules can stage are
+			// promoted; other routing rules are managed by their own workflows.
+			if promoted.ShardRoutingRules || promoted.KeyspaceRoutingRules {
+				err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cell %s has diverging shard or keyspace routing rules, which cannot be promoted", req.PromoteFromCell)
+				return nil, err
+			}
+			if sv == nil {
+				err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cell %s has no SrvVSchema to promote", req.PromoteFromCell)
+				return nil, err
+			}
+			for _, ks := range promoted.Keyspaces {
+				if _, ok := sv.Keyspaces[ks]; !ok {
+					err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is missing from the SrvVSchema in cell %s, refusing to promote its removal", ks, req.PromoteFromCell)
+					return nil, err
+				}
+			}
+		}
+
+		resp.Promoted = promoted
+		if promoted != nil && !req.DryRun {
+			for _, ks := range promoted.Keyspaces {
+				if err = s.ts.SaveVSchema(ctx, ks, sv.Keyspaces[ks]); err != nil {
+					err = vterrors.Wrapf(err, "SaveVSchema(%s)", ks)
+					return nil, err
+				}
+			}
+			if promoted.RoutingRules {
+				if err = s.ts.SaveRoutingRules(ctx, sv.RoutingRules); err != nil {
+					err = vterrors.Wrapf(err, "SaveRoutingRules")
+					return nil, err
+				}
+			}
+
+			// The promoted changes are global now, so they are no longer staged.
+			if err = unstageSrvVSchema(ctx, s.ts, []string{req.PromoteFromCell}); err != nil {
+				return nil, err
+			}
+
+			// Promoting changes the global VSchema, so every requested cell
+			// needs to be rebuilt, not only the ones diverging before.
+			if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
+				return nil, err
+			}
+
+			return resp, nil
+		}
+	}
+
+	if req.DryRun {
+		return resp, nil
+	}
+
+	// Reverting drops the changes staged in every requested cell, even the
+	// ones matching the global VSchema, which the rebuild would apply again.
+	if err = unstageSrvVSchema(ctx, s.ts, req.Cells); err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		return resp, nil
+	}
+
+	cells := make([]string, 0, len(diffs))
+	for cell := range diffs {
+		cells = append(cells, cell)
+	}
+	sort.Strings(cells)
+
+	if err = s.ts.RebuildSrvVSchema(ctx, cells); err != nil {
 		return nil, err
 	}
 
-	return &vtctldatapb.RebuildKeyspaceGraphResponse{}, nil
+	return resp, nil
 }
 
-// RebuildVSchemaGraph is part of the vtctlservicepb.VtctldServer interface.
-func (s *VtctldServer) RebuildVSchemaGraph(ctx context.Context, req *vtctldatapb.RebuildVSchemaGraphRequest) (resp *vtctldatapb.RebuildVSchemaGraphResponse, err error) {
-	span, ctx := trace.NewSpan(ctx, "VtctldServer.RebuildVSchemaGraph")
+// ReferenceTablesCheck is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReferenceTablesCheck(ctx context.Context, req *vtctldatapb.ReferenceTablesCheckRequest) (resp *vtctldatapb.ReferenceTablesCheckResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReferenceTablesCheck")
 	defer span.Finish()
 
 	defer panicHandler(&err)
 
-	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
 
-	if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
-		return nil, err
-	}
+	resp, err = s.ws.ReferenceTablesCheck(ctx, req)
+	return resp, err
+}
 
-	return &vtctldatapb.RebuildVSchemaGraphResponse{}, nil
+// ReferenceTablesCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReferenceTablesCreate(ctx context.Context, req *vtctldatapb.ReferenceTablesCreateRequest) (resp *vtctldatapb.ReferenceTablesCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReferenceTablesCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+
+	resp, err = s.ws.ReferenceTablesCreate(ctx, req)
+	return resp, err
 }
 
 // RefreshState is part of the vtctldservicepb.VtctldServer interface.
@@ -3397,6 +4857,70 @@ func (s *VtctldServer) RetrySchemaMigration(ctx context.Context, req *vtctldatap
 	return resp, nil
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RollbackVSchema(ctx context.Context, req *vtctldatapb.RollbackVSchemaRequest) (resp *vtctldatapb.RollbackVSchemaResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RollbackVSchema")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("version", req.Version)
+	span.Annotate("author", req.Author)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("skip_rebuild", req.SkipRebuild)
+
+	if req.Version <= 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid version %d of the vschema history", req.Version)
+		return nil, err
+	}
+
+	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		err = vterrors.Wrapf(err, "GetKeyspace(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	target, err := s.ts.GetVSchemaVersion(ctx, req.Keyspace, req.Version)
+	if err != nil {
+		err = vterrors.Wrapf(err, "GetVSchemaVersion(%s, %d)", req.Keyspace, req.Version)
+		return nil, err
+	}
+
+	// The vindexes of the version may no longer be valid.
+	if _, err = vindexes.BuildKeyspace(target.Vschema, s.ws.SQLParser()); err != nil {
+		err = vterrors.Wrapf(err, "BuildKeyspace(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	current, err := s.ts.GetVSchema(ctx, req.Keyspace)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		err = vterrors.Wrapf(err, "GetVSchema(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	version, err := s.ts.SaveVSchemaVersion(ctx, req.Keyspace, target.Vschema, topo.VSchemaChange{
+		Author:       req.Author,
+		Comment:      req.Comment,
+		RolledBackTo: req.Version,
+	})
+	if err != nil {
+		err = vterrors.Wrapf(err, "SaveVSchemaVersion(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	if !req.SkipRebuild {
+		if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
+			err = vterrors.Wrapf(err, "RebuildSrvVSchema")
+			return nil, err
+		}
+	}
+
+	return &vtctldatapb.RollbackVSchemaResponse{
+		Version: version,
+		Diff:    topo.DiffVSchemas(current, target.Vschema),
+	}, nil
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunHealthCheckRequest) (resp *vtctldatapb.RunHealthCheckResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RunHealthCheck")
@@ -3419,6 +4943,46 @@ func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunH
 	return &vtctldatapb.RunHealthCheckResponse{}, nil
 }
 
+// SchedulePlannedReparentShard is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SchedulePlannedReparentShard(ctx context.Context, req *vtctldatapb.SchedulePlannedReparentShardRequest) (resp *vtctldatapb.SchedulePlannedReparentShardResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SchedulePlannedReparentShard")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Request != nil {
+		span.Annotate("keyspace", req.Request.Keyspace)
+		span.Annotate("shard", req.Request.Shard)
+	}
+
+	start, window, interval, err := validateScheduledReparent(ctx, s.ts, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &vtctldatapb.ScheduledReparent{
+		Id:            uuid.NewString(),
+		Request:       req.Request,
+		StartTime:     protoutil.TimeToProto(start),
+		RetryWindow:   protoutil.DurationToProto(window),
+		RetryInterval: protoutil.DurationToProto(interval),
+		Preconditions: req.Preconditions,
+		State:         scheduledReparentScheduled,
+	}
+	// The reparent outlives the request.
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.scheduledReparents.add(sr, cancel)
+	resp = &vtctldatapb.SchedulePlannedReparentShardResponse{ScheduledReparent: sr.CloneVT()}
+
+	log.Infof("Scheduled reparent %s of %s/%s at %v", sr.Id, req.Request.Keyspace, req.Request.Shard, start)
+	go func() {
+		s.notifyScheduledReparent(resp.ScheduledReparent)
+		s.runScheduledReparent(runCtx, sr.Id, req, start, window, interval)
+	}()
+
+	return resp, nil
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtctldatapb.SetKeyspaceDurabilityPolicyRequest) (resp *vtctldatapb.SetKeyspaceDurabilityPolicyResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceDurabilityPolicy")
@@ -3460,6 +5024,89 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 	}, nil
 }
 
+// SetKeyspaceTwoPC is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetKeyspaceTwoPC(ctx context.Context, req *vtctldatapb.SetKeyspaceTwoPCRequest) (resp *vtctldatapb.SetKeyspaceTwoPCResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceTwoPC")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("enabled", req.Enabled)
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetKeyspaceTwoPC")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	ki.TwopcEnabled = req.Enabled
+
+	err = s.ts.UpdateKeyspace(ctx, ki)
+	if err != nil {
+		return nil, err
+	}
+
+	// The vtgates read it from the SrvKeyspaces, which are updated in place
+	// rather than rebuilt, as for the throttler config.
+	if _, err = s.ts.UpdateSrvKeyspaceTwoPC(ctx, req.Keyspace, nil, req.Enabled); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetKeyspaceTwoPCResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
+// SetKeyspaceCharset is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetKeyspaceCharset(ctx context.Context, req *vtctldatapb.SetKeyspaceCharsetRequest) (resp *vtctldatapb.SetKeyspaceCharsetResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceCharset")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("charset", req.Charset)
+	span.Annotate("collation", req.Collation)
+
+	charset, collation, err := schema.ValidateKeyspaceCharset(s.env.CollationEnv(), req.Charset, req.Collation)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetKeyspaceCharset")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	ki.DefaultCharset = charset
+	ki.DefaultCollation = collation
+
+	err = s.ts.UpdateKeyspace(ctx, ki)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetKeyspaceCharsetResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctldatapb.SetShardIsPrimaryServingRequest) (resp *vtctldatapb.SetShardIsPrimaryServingResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardIsPrimaryServing")
@@ -4083,6 +5730,78 @@ func (s *VtctldServer) TabletExternallyReparented(ctx context.Context, req *vtct
 	return resp, nil
 }
 
+// UnfreezeShardWrites is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) UnfreezeShardWrites(ctx context.Context, req *vtctldatapb.UnfreezeShardWritesRequest) (resp *vtctldatapb.UnfreezeShardWritesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.UnfreezeShardWrites")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+
+	ctx, unlock, lockErr := s.ts.LockShard(ctx, req.Keyspace, req.Shard, "UnfreezeShardWrites")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	si, err := s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+	if err != nil {
+		return nil, err
+	}
+	if si.WriteFreeze == nil {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "writes to shard %s/%s are not frozen", req.Keyspace, req.Shard)
+		return nil, err
+	}
+
+	si, err = setShardWriteFreeze(ctx, s.ts, s.tmc, si, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.UnfreezeShardWritesResponse{
+		Shard: &vtctldatapb.Shard{
+			Keyspace: req.Keyspace,
+			Name:     req.Shard,
+			Shard:    si.Shard,
+		},
+	}, nil
+}
+
+// UnpinQueryPlan is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) UnpinQueryPlan(ctx context.Context, req *vtctldatapb.UnpinQueryPlanRequest) (resp *vtctldatapb.UnpinQueryPlanResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.UnpinQueryPlan")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	pin, err := s.pinnedPlan(req.Keyspace, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	control, err := s.ts.UpdatePlanCacheControl(ctx, func(control *vtgatepb.PlanCacheControl) error {
+		i := slices.IndexFunc(control.PinnedPlans, pin.matches)
+		if i < 0 {
+			return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "query %q is not pinned in keyspace %q", pin.Query, pin.Keyspace)
+		}
+		control.PinnedPlans = slices.Delete(control.PinnedPlans, i, i+1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.UnpinQueryPlanResponse{
+		PlanCacheControl: control,
+	}, nil
+}
+
 // UpdateCellInfo is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) UpdateCellInfo(ctx context.Context, req *vtctldatapb.UpdateCellInfoRequest) (resp *vtctldatapb.UpdateCellInfoResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.UpdateCellInfo")
@@ -4093,6 +5812,8 @@ func (s *VtctldServer) UpdateCellInfo(ctx context.Context, req *vtctldatapb.Upda
 	span.Annotate("cell", req.Name)
 	span.Annotate("cell_server_address", req.CellInfo.ServerAddress)
 	span.Annotate("cell_root", req.CellInfo.Root)
+	span.Annotate("cell_region", req.CellInfo.Region)
+	span.Annotate("cell_zone", req.CellInfo.Zone)
 
 	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
 	defer cancel()
@@ -4113,6 +5834,33 @@ func (s *VtctldServer) UpdateCellInfo(ctx context.Context, req *vtctldatapb.Upda
 			ci.Root = req.CellInfo.Root
 		}
 
+		region := req.CellInfo.Region
+		if region == "" && !req.ClearRegion {
+			region = ci.Region
+		}
+		if region != ci.Region {
+			changed = true
+			ci.Region = region
+		}
+
+		zone := req.CellInfo.Zone
+		if zone == "" && !req.ClearZone {
+			zone = ci.Zone
+		}
+		if zone != ci.Zone {
+			changed = true
+			ci.Zone = zone
+		}
+
+		tabletTags := req.CellInfo.TabletTags
+		if len(tabletTags) == 0 && !req.ClearTabletTags {
+			tabletTags = ci.TabletTags
+		}
+		if !maps.Equal(tabletTags, ci.TabletTags) {
+			changed = true
+			ci.TabletTags = tabletTags
+		}
+
 		if !changed {
 			return topo.NewError(topo.NoUpdateNeeded, req.Name)
 		}
@@ -4997,6 +6745,66 @@ func (s *VtctldServer) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStop
 	return resp, err
 }
 
+// WatchTopologyPath is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WatchTopologyPath(req *vtctldatapb.WatchTopologyPathRequest, stream vtctlservicepb.Vtctld_WatchTopologyPathServer) (err error) {
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.WatchTopologyPath")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("path", req.Path)
+
+	cell, relativePath, err := splitTopologyPath(req.Path)
+	if err != nil {
+		return err
+	}
+	conn, err := s.ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "error fetching connection to cell %s: %v", cell, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	current, changes, err := conn.Watch(ctx, relativePath)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no file at path %s, only files can be watched", req.Path)
+		}
+		return err
+	}
+
+	send := func(wd *topo.WatchData) error {
+		topoCell := &vtctldatapb.TopologyCell{Name: req.Path[strings.LastIndex(req.Path, "/")+1:], Path: req.Path}
+		if topoCell.Version, err = strconv.ParseInt(wd.Version.String(), 10, 64); err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "error decoding file version for cell %s: %v", req.Path, err)
+		}
+		if topoCell.Data, err = topo.DecodeContent(relativePath, wd.Contents, req.AsJson); err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "error decoding file content for cell %s: %v", req.Path, err)
+		}
+		return stream.Send(&vtctldatapb.WatchTopologyPathResponse{Cell: topoCell})
+	}
+
+	if err := send(current); err != nil {
+		return err
+	}
+	for wd := range changes {
+		switch {
+		case wd.Err == nil:
+			if err := send(wd); err != nil {
+				return err
+			}
+		case topo.IsErrType(wd.Err, topo.Interrupted):
+			// The client went away.
+			return nil
+		case topo.IsErrType(wd.Err, topo.NoNode):
+			return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "file at path %s was deleted", req.Path)
+		default:
+			return wd.Err
+		}
+	}
+	return nil
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) WorkflowDelete(ctx context.Context, req *vtctldatapb.WorkflowDeleteRequest) (resp *vtctldatapb.WorkflowDeleteResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowDelete")
@@ -5065,17 +6873,25 @@ func StartServer(s *grpc.Server, env *vtenv.Environment, ts *topo.Server) {
 	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(env, ts))
 }
 
+// splitTopologyPath splits a path of the topology server into the cell and
+// the path relative to the cell.
+func splitTopologyPath(cellPath string) (cell string, relativePath string, err error) {
+	parts := strings.Split(cellPath, "/")
+	if parts[0] != "" || len(parts) < 2 {
+		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid path: %s", cellPath)
+	}
+	cell = parts[1]
+	return cell, cellPath[len(cell)+1:], nil
+}
+
 // getTopologyCell is a helper method that returns a topology cell given its path.
 func (s *VtctldServer) getTopologyCell(ctx context.Context, cellPath string, version int64, asJSON bool) (*vtctldatapb.TopologyCell, error) {
 	// extract cell and relative path
-	parts := strings.Split(cellPath, "/")
-	if parts[0] != "" || len(parts) < 2 {
-		err := vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid path: %s", cellPath)
+	cell, relativePath, err := splitTopologyPath(cellPath)
+	if err != nil {
 		return nil, err
 	}
-	cell := parts[1]
-	relativePath := cellPath[len(cell)+1:]
-	topoCell := &vtctldatapb.TopologyCell{Name: parts[len(parts)-1], Path: cellPath}
+	topoCell := &vtctldatapb.TopologyCell{Name: cellPath[strings.LastIndex(cellPath, "/")+1:], Path: cellPath}
 
 	conn, err := s.ts.ConnForCell(ctx, cell)
 	if err != nil {
@@ -40,6 +40,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	"vitess.io/vitess/go/vt/proto/vttime"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
@@ -48,9 +49,11 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -60,6 +63,8 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func init() {
@@ -345,6 +350,107 @@ func TestApplyRoutingRules(t *testing.T) {
 	}
 }
 
+func TestApplyStatementACL(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.GetStatementACL(ctx, &vtctldatapb.GetStatementACLRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.StatementAcl.GetRules())
+
+	_, err = vtctld.ApplyStatementACL(ctx, &vtctldatapb.ApplyStatementACLRequest{
+		StatementAcl: &vtgatepb.StatementACL{
+			Rules: []*vtgatepb.StatementACLRule{{Users: []string{"app"}, Deny: []string{"truncate"}}},
+		},
+	})
+	assert.Error(t, err)
+
+	acl := &vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{{Users: []string{"app"}, Deny: []string{"ddl"}}},
+	}
+	_, err = vtctld.ApplyStatementACL(ctx, &vtctldatapb.ApplyStatementACLRequest{
+		StatementAcl: acl,
+	})
+	require.NoError(t, err)
+
+	resp, err = vtctld.GetStatementACL(ctx, &vtctldatapb.GetStatementACLRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, acl, resp.StatementAcl)
+
+	_, err = vtctld.ApplyStatementACL(ctx, &vtctldatapb.ApplyStatementACLRequest{})
+	require.NoError(t, err)
+
+	resp, err = vtctld.GetStatementACL(ctx, &vtctldatapb.GetStatementACLRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.StatementAcl.GetRules())
+}
+
+func TestApplyTenantCatalog(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	require.NoError(t, ts.SaveVSchema(ctx, "customers", &vschemapb.Keyspace{
+		Sharded: true,
+		MultiTenantSpec: &vschemapb.MultiTenantSpec{
+			TenantIdColumnName: "tenant_id",
+			TenantIdColumnType: querypb.Type_INT64,
+		},
+	}))
+	require.NoError(t, ts.SaveVSchema(ctx, "commerce", &vschemapb.Keyspace{}))
+
+	resp, err := vtctld.GetTenantCatalog(ctx, &vtctldatapb.GetTenantCatalogRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.TenantCatalog.GetTenants())
+
+	for _, tenant := range []*vtgatepb.Tenant{
+		{Keyspace: "commerce", TenantId: "1"},
+		{Keyspace: "customers", TenantId: "acme"},
+		{Keyspace: "customers"},
+	} {
+		_, err = vtctld.ApplyTenantCatalog(ctx, &vtctldatapb.ApplyTenantCatalogRequest{
+			TenantCatalog: &vtgatepb.TenantCatalog{
+				Tenants: map[string]*vtgatepb.Tenant{"customer1": tenant},
+			},
+		})
+		assert.Error(t, err, "%v", tenant)
+	}
+
+	catalog := &vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{
+			"customer1": {Keyspace: "customers", TenantId: "1"},
+			"customer2": {Keyspace: "customers", TenantId: "2"},
+		},
+	}
+	_, err = vtctld.ApplyTenantCatalog(ctx, &vtctldatapb.ApplyTenantCatalogRequest{
+		TenantCatalog: catalog,
+	})
+	require.NoError(t, err)
+
+	resp, err = vtctld.GetTenantCatalog(ctx, &vtctldatapb.GetTenantCatalogRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, catalog, resp.TenantCatalog)
+
+	_, err = vtctld.ApplyTenantCatalog(ctx, &vtctldatapb.ApplyTenantCatalogRequest{})
+	require.NoError(t, err)
+
+	resp, err = vtctld.GetTenantCatalog(ctx, &vtctldatapb.GetTenantCatalogRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.TenantCatalog.GetTenants())
+}
+
 func TestApplyVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -364,6 +470,7 @@ func TestApplyVSchema(t *testing.T) {
 				},
 			},
 			exp: &vtctldatapb.ApplyVSchemaResponse{
+				Version: 2,
 				VSchema: &vschemapb.Keyspace{
 					Sharded: false,
 				},
@@ -379,6 +486,7 @@ func TestApplyVSchema(t *testing.T) {
 				SkipRebuild: true,
 			},
 			exp: &vtctldatapb.ApplyVSchemaResponse{
+				Version: 2,
 				VSchema: &vschemapb.Keyspace{
 					Sharded: false,
 				},
@@ -419,6 +527,7 @@ func TestApplyVSchema(t *testing.T) {
 				SkipRebuild: true,
 			},
 			exp: &vtctldatapb.ApplyVSchemaResponse{
+				Version: 2,
 				VSchema: &vschemapb.Keyspace{
 					Sharded: true,
 					Vindexes: map[string]*vschemapb.Vindex{
@@ -1250,6 +1359,40 @@ func TestBackupShard(t *testing.T) {
 	}
 }
 
+func TestBackupVerify(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.BackupStorage.Backups = map[string][]string{
+		"testkeyspace/-": {"backup1"},
+	}
+
+	t.Run("no backup found in bucket", func(t *testing.T) {
+		_, err := vtctld.BackupVerify(ctx, &vtctldatapb.BackupVerifyRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Name:     "notfound",
+		})
+		assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	})
+
+	t.Run("listbackups error", func(t *testing.T) {
+		testutil.BackupStorage.ListBackupsError = assert.AnError
+		defer func() { testutil.BackupStorage.ListBackupsError = nil }()
+
+		_, err := vtctld.BackupVerify(ctx, &vtctldatapb.BackupVerifyRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Name:     "backup1",
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
 func TestCancelSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -1753,6 +1896,84 @@ func TestChangeTabletType(t *testing.T) {
 	})
 }
 
+func TestChecksumTable(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	tablets := []*topodatapb.Tablet{
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_PRIMARY},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_REPLICA},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_PRIMARY},
+	}
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, tablets...)
+
+	table := func(columns ...string) *tabletmanagerdatapb.SchemaDefinition {
+		return &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{Name: "t1", Columns: columns, PrimaryKeyColumns: []string{"id"}}},
+		}
+	}
+	checksum := func(rows, sum string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)|sum", "int64|decimal"), rows+"|"+sum))
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {Schema: table("id", "name", "created")},
+			"zone1-0000000101": {Schema: table("id", "name")},
+			"zone1-0000000200": {Schema: table("id", "name")},
+		},
+		// The rows of -80 are read from the replica, and those of 80- from the
+		// primary, as the shard has no replica. Each chunk of each shard has
+		// the same rows in this test.
+		ExecuteFetchAsAppResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000101": {Response: checksum("2", "100")},
+			"zone1-0000000200": {Response: checksum("3", "7")},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	boundaries := []*vtctldatapb.TableChecksumBoundary{{Values: []*querypb.Value{sqltypes.ValueToProto(sqltypes.NewInt64(10))}}}
+	resp, err := vtctld.ChecksumTable(ctx, &vtctldatapb.ChecksumTableRequest{Keyspace: "testkeyspace", Table: "t1", Boundaries: boundaries})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ChecksumTableResponse{
+		Columns:           []string{"id", "name"},
+		PrimaryKeyColumns: []string{"id"},
+		Boundaries:        boundaries,
+		Chunks: []*vtctldatapb.TableChecksumChunk{
+			{RowCount: 5, Checksum: 107},
+			{RowCount: 5, Checksum: 107},
+		},
+		RowCount: 10,
+		Checksum: 214,
+	}, resp)
+
+	resp, err = vtctld.ChecksumTable(ctx, &vtctldatapb.ChecksumTableRequest{Keyspace: "testkeyspace", Table: "t1", Shards: []string{"80-"}, Boundaries: boundaries})
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, resp.RowCount)
+
+	// The primary of -80 has another column.
+	_, err = vtctld.ChecksumTable(ctx, &vtctldatapb.ChecksumTableRequest{Keyspace: "testkeyspace", Table: "t1", TabletType: topodatapb.TabletType_PRIMARY, Boundaries: boundaries})
+	assert.ErrorContains(t, err, "table t1 has different columns or primary keys")
+
+	_, err = vtctld.ChecksumTable(ctx, &vtctldatapb.ChecksumTableRequest{Keyspace: "testkeyspace", Table: "t1", Boundaries: []*vtctldatapb.TableChecksumBoundary{{}}})
+	assert.ErrorContains(t, err, "boundary has 0 values but the primary key of table t1 has 1 columns")
+
+	_, err = vtctld.ChecksumTable(ctx, &vtctldatapb.ChecksumTableRequest{Keyspace: "testkeyspace"})
+	assert.ErrorContains(t, err, "table must be specified")
+}
+
 func TestCleanupSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -2161,6 +2382,71 @@ func TestForceCutOverSchemaMigration(t *testing.T) {
 	}
 }
 
+func TestClearTabletQuarantine(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &testutil.TabletManagerClient{
+		TopoServer: ts,
+	}, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_DRAINED,
+		Tags:     map[string]string{topo.ErrantGTIDsTag: "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1"},
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_DRAINED,
+	})
+	quarantined := &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}
+
+	// The type of a quarantined tablet is only changed by clearing its
+	// quarantine.
+	_, err := vtctld.ChangeTabletType(ctx, &vtctldatapb.ChangeTabletTypeRequest{
+		TabletAlias: quarantined,
+		DbType:      topodatapb.TabletType_REPLICA,
+	})
+	assert.ErrorContains(t, err, "tablet zone1-0000000101 is quarantined for its errant GTIDs aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1")
+
+	_, err = vtctld.ClearTabletQuarantine(ctx, &vtctldatapb.ClearTabletQuarantineRequest{
+		TabletAlias: quarantined,
+		TabletType:  topodatapb.TabletType_PRIMARY,
+	})
+	assert.ErrorContains(t, err, "cannot change the type of quarantined tablet zone1-0000000101 to primary")
+
+	resp, err := vtctld.ClearTabletQuarantine(ctx, &vtctldatapb.ClearTabletQuarantineRequest{
+		TabletAlias: quarantined,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, resp.Tablet.Type)
+	assert.NotContains(t, resp.Tablet.Tags, topo.ErrantGTIDsTag)
+
+	tablet, err := ts.GetTablet(ctx, quarantined)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tablet.Type)
+	assert.False(t, topo.IsQuarantined(tablet.Tablet))
+
+	_, err = vtctld.ClearTabletQuarantine(ctx, &vtctldatapb.ClearTabletQuarantineRequest{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+	})
+	assert.ErrorContains(t, err, "tablet zone1-0000000102 is not quarantined")
+}
+
 func TestCompleteSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -2471,6 +2757,60 @@ func TestCreateKeyspace(t *testing.T) {
 			expected:  nil,
 			shouldErr: true,
 		},
+		{
+			name: "snapshot keyspace with binlog source",
+			topo: nil,
+			req: &vtctldatapb.CreateKeyspaceRequest{
+				Name:         "testsnapshot",
+				Type:         topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "testkeyspace",
+				SnapshotTime: &vttime.Time{
+					Seconds: 1,
+				},
+				SnapshotBinlogSource: &topodatapb.SnapshotBinlogSource{
+					Host: "ripple-{{.Shard}}",
+					Port: 3306,
+				},
+			},
+			expected: &vtctldatapb.CreateKeyspaceResponse{
+				Keyspace: &vtctldatapb.Keyspace{
+					Name: "testsnapshot",
+					Keyspace: &topodatapb.Keyspace{
+						KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+						BaseKeyspace: "testkeyspace",
+						SnapshotTime: &vttime.Time{
+							Seconds: 1,
+						},
+						SnapshotBinlogSource: &topodatapb.SnapshotBinlogSource{
+							Host: "ripple-{{.Shard}}",
+							Port: 3306,
+						},
+					},
+				},
+			},
+			vschemaShouldExist: true,
+			expectedVSchema: &vschemapb.Keyspace{
+				Sharded:                false,
+				Tables:                 map[string]*vschemapb.Table{},
+				Vindexes:               map[string]*vschemapb.Vindex{},
+				RequireExplicitRouting: true,
+			},
+			shouldErr: false,
+		},
+		{
+			name: "normal keyspace with binlog source",
+			topo: nil,
+			req: &vtctldatapb.CreateKeyspaceRequest{
+				Name: "testkeyspace",
+				Type: topodatapb.KeyspaceType_NORMAL,
+				SnapshotBinlogSource: &topodatapb.SnapshotBinlogSource{
+					Host: "ripple",
+					Port: 3306,
+				},
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
 		{
 			name: "snapshot keyspace with nonexistent base keyspace",
 			topo: nil,
@@ -5208,6 +5548,104 @@ func TestFindAllShardsInKeyspace(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// newWriteFreezeTestServer creates a shard ks/- whose primary is zone1-100 and
+// replica is zone1-101, with a tablet manager client reporting the given
+// super_read_only for the primary.
+func newWriteFreezeTestServer(ctx context.Context, t *testing.T, superReadOnly bool) (*topo.Server, vtctlservicepb.VtctldServer) {
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname: "zone1-100",
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Hostname: "zone1-101",
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	})
+	tmc := &testutil.TabletManagerClient{
+		RefreshStateResults: map[string]error{
+			"zone1-0000000100": nil,
+			"zone1-0000000101": nil,
+		},
+		FullStatusResults: map[string]struct {
+			Status *replicationdatapb.FullStatus
+			Error  error
+		}{
+			"zone1-0000000100": {
+				Status: &replicationdatapb.FullStatus{SuperReadOnly: superReadOnly},
+			},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	return ts, vtctld
+}
+
+func TestFreezeShardWrites(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("ok", func(t *testing.T) {
+		ts, vtctld := newWriteFreezeTestServer(ctx, t, true)
+		resp, err := vtctld.FreezeShardWrites(ctx, &vtctldatapb.FreezeShardWritesRequest{
+			Keyspace: "ks",
+			Shard:    "-",
+			Reason:   "maintenance",
+			Buffer:   true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Shard.Shard.WriteFreeze)
+		assert.Equal(t, "maintenance", resp.Shard.Shard.WriteFreeze.Reason)
+		assert.True(t, resp.Shard.Shard.WriteFreeze.Buffer)
+		assert.NotNil(t, resp.Shard.Shard.WriteFreeze.FrozenAt)
+
+		si, err := ts.GetShard(ctx, "ks", "-")
+		require.NoError(t, err)
+		utils.MustMatch(t, resp.Shard.Shard.WriteFreeze, si.WriteFreeze)
+
+		// The writes can't be frozen twice.
+		_, err = vtctld.FreezeShardWrites(ctx, &vtctldatapb.FreezeShardWritesRequest{
+			Keyspace: "ks",
+			Shard:    "-",
+			Reason:   "other maintenance",
+		})
+		assert.ErrorContains(t, err, "writes to shard ks/- are already frozen: maintenance")
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		_, vtctld := newWriteFreezeTestServer(ctx, t, true)
+		_, err := vtctld.FreezeShardWrites(ctx, &vtctldatapb.FreezeShardWritesRequest{
+			Keyspace: "ks",
+			Shard:    "-",
+		})
+		assert.ErrorContains(t, err, "FreezeShardWrites.Reason is required")
+	})
+
+	t.Run("primary not super_read_only", func(t *testing.T) {
+		ts, vtctld := newWriteFreezeTestServer(ctx, t, false)
+		_, err := vtctld.FreezeShardWrites(ctx, &vtctldatapb.FreezeShardWritesRequest{
+			Keyspace: "ks",
+			Shard:    "-",
+			Reason:   "maintenance",
+		})
+		assert.ErrorContains(t, err, "primary zone1-0000000100 has super_read_only=false while the write freeze requires true")
+
+		// The freeze is rolled back.
+		si, err := ts.GetShard(ctx, "ks", "-")
+		require.NoError(t, err)
+		assert.Nil(t, si.WriteFreeze)
+	})
+}
+
 func TestGetBackups(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -5533,23 +5971,87 @@ func TestGetFullStatus(t *testing.T) {
 	}
 }
 
-func TestGetKeyspaces(t *testing.T) {
+func TestGetKeyspaceCharsetReport(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ts, topofactory := memorytopo.NewServerAndFactory(ctx, "cell1")
-	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_PRIMARY},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_PRIMARY},
+	)
+
+	table := func(name, schema string) *tabletmanagerdatapb.TableDefinition {
+		return &tabletmanagerdatapb.TableDefinition{Name: name, Schema: schema, Type: tmutils.TableBaseTable}
+	}
+	users := table("users", "CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(64) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci")
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {Schema: &tabletmanagerdatapb.SchemaDefinition{
+				TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+					users,
+					table("orders", "CREATE TABLE `orders` (\n  `id` int NOT NULL,\n  `code` varchar(8) CHARACTER SET latin1 COLLATE latin1_bin DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"),
+				},
+			}},
+			"zone1-0000000200": {Schema: &tabletmanagerdatapb.SchemaDefinition{
+				TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+					table("users", "CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(64) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3"),
+					table("orders", "CREATE TABLE `orders` (\n  `id` int NOT NULL,\n  `code` varchar(8) CHARACTER SET latin1 COLLATE latin1_bin DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"),
+				},
+			}},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 		return NewVtctldServer(vtenv.NewTestEnv(), ts)
 	})
 
-	resp, err := vtctld.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
-	assert.NoError(t, err)
-	assert.Empty(t, resp.Keyspaces)
+	_, err := vtctld.GetKeyspaceCharsetReport(ctx, &vtctldatapb.GetKeyspaceCharsetReportRequest{Keyspace: "testkeyspace"})
+	assert.EqualError(t, err, "keyspace testkeyspace has no default character set or collation")
 
-	expected := []*vtctldatapb.Keyspace{
-		{
-			Name:     "ks1",
+	_, err = vtctld.SetKeyspaceCharset(ctx, &vtctldatapb.SetKeyspaceCharsetRequest{Keyspace: "testkeyspace", Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"})
+	require.NoError(t, err)
+
+	resp, err := vtctld.GetKeyspaceCharsetReport(ctx, &vtctldatapb.GetKeyspaceCharsetReportRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.GetKeyspaceCharsetReportResponse{
+		DefaultCharset:   "utf8mb4",
+		DefaultCollation: "utf8mb4_0900_ai_ci",
+		Deviations: []*vtctldatapb.GetKeyspaceCharsetReportResponse_Deviation{
+			{Shard: "-80", Table: "orders", Column: "code", Charset: "latin1", Collation: "latin1_bin"},
+			{Shard: "80-", Table: "orders", Column: "code", Charset: "latin1", Collation: "latin1_bin"},
+			{Shard: "80-", Table: "users", Charset: "utf8mb3"},
+		},
+		ConvertStatements: map[string]string{
+			"orders": "ALTER TABLE orders CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+			"users":  "ALTER TABLE users CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+		},
+	}, resp)
+}
+
+func TestGetKeyspaces(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, topofactory := memorytopo.NewServerAndFactory(ctx, "cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Keyspaces)
+
+	expected := []*vtctldatapb.Keyspace{
+		{
+			Name:     "ks1",
 			Keyspace: &topodatapb.Keyspace{},
 		},
 		{
@@ -5723,6 +6225,63 @@ func TestGetPermissions(t *testing.T) {
 	}
 }
 
+func TestGetQuarantinedTablets(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	errantGTIDs := map[string]string{topo.ErrantGTIDsTag: "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1"}
+	testutil.AddTablets(ctx, t, ts, nil, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_DRAINED,
+		Tags:     errantGTIDs,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Keyspace: "ks",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_DRAINED,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "ks",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_DRAINED,
+		Tags:     errantGTIDs,
+	}, &topodatapb.Tablet{
+		// The quarantine is lifted when the type of the tablet changes.
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 201},
+		Keyspace: "ks",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_REPLICA,
+		Tags:     errantGTIDs,
+	})
+
+	aliases := func(resp *vtctldatapb.GetQuarantinedTabletsResponse) []string {
+		var aliases []string
+		for _, tablet := range resp.Tablets {
+			aliases = append(aliases, topoproto.TabletAliasString(tablet.Alias))
+		}
+		return aliases
+	}
+
+	resp, err := vtctld.GetQuarantinedTablets(ctx, &vtctldatapb.GetQuarantinedTabletsRequest{Keyspace: "ks"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone1-0000000100", "zone1-0000000200"}, aliases(resp))
+
+	resp, err = vtctld.GetQuarantinedTablets(ctx, &vtctldatapb.GetQuarantinedTabletsRequest{Keyspace: "ks", Shard: "80-"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone1-0000000200"}, aliases(resp))
+
+	_, err = vtctld.GetQuarantinedTablets(ctx, &vtctldatapb.GetQuarantinedTabletsRequest{Keyspace: "unknown"})
+	assert.Error(t, err)
+}
+
 func TestGetRoutingRules(t *testing.T) {
 	t.Parallel()
 
@@ -5990,6 +6549,289 @@ func TestGetSchema(t *testing.T) {
 	}
 }
 
+func TestGetSchemaAtPosition(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	tablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+	}
+	testutil.AddTablet(ctx, t, ts, tablet, nil)
+
+	t1v1 := &binlogdatapb.MinimalTable{Name: "t1", Fields: []*querypb.Field{{Name: "id", Type: querypb.Type_INT64}}}
+	t1v2 := &binlogdatapb.MinimalTable{Name: "t1", Fields: []*querypb.Field{{Name: "id", Type: querypb.Type_INT64}, {Name: "val", Type: querypb.Type_VARBINARY}}}
+	t2 := &binlogdatapb.MinimalTable{Name: "t2", Fields: []*querypb.Field{{Name: "id", Type: querypb.Type_INT64}}}
+	schemax := func(tables ...*binlogdatapb.MinimalTable) sqltypes.Value {
+		blob, err := (&binlogdatapb.MinimalSchema{Tables: tables}).MarshalVT()
+		require.NoError(t, err)
+		return sqltypes.NewVarBinary(string(blob))
+	}
+
+	const (
+		pos1 = "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10"
+		pos2 = "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-20"
+	)
+	fields := sqltypes.MakeTestFields("id|pos|ddl|time_updated|schemax", "int64|varchar|varchar|int64|varbinary")
+	v1 := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar(pos1), sqltypes.NewVarChar("create table t2(id bigint)"), sqltypes.NewInt64(1000), schemax(t1v1, t2)}
+	v2 := []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar(pos2), sqltypes.NewVarChar("alter table t1 add column val varbinary(10)"), sqltypes.NewInt64(2000), schemax(t1v2, t2)}
+
+	tests := []struct {
+		name string
+		req  *vtctldatapb.GetSchemaAtPositionRequest
+		// row is the schema version the tablet selects for the position.
+		row       []sqltypes.Value
+		expected  *vtctldatapb.GetSchemaAtPositionResponse
+		shouldErr bool
+	}{
+		{
+			name: "exact match",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    pos1,
+			},
+			row: v1,
+			expected: &vtctldatapb.GetSchemaAtPositionResponse{
+				Position:    pos1,
+				Ddl:         "create table t2(id bigint)",
+				TimeUpdated: 1000,
+				Tables:      []*binlogdatapb.MinimalTable{t1v1, t2},
+			},
+		},
+		{
+			name: "between versions",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-15",
+				Tables:      []string{"t1"},
+			},
+			row: v1,
+			expected: &vtctldatapb.GetSchemaAtPositionResponse{
+				Position:    pos1,
+				Ddl:         "create table t2(id bigint)",
+				TimeUpdated: 1000,
+				Tables:      []*binlogdatapb.MinimalTable{t1v1},
+			},
+		},
+		{
+			name: "after latest version",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-100",
+				Tables:      []string{"t1"},
+			},
+			row: v2,
+			expected: &vtctldatapb.GetSchemaAtPositionResponse{
+				Position:    pos2,
+				Ddl:         "alter table t1 add column val varbinary(10)",
+				TimeUpdated: 2000,
+				Tables:      []*binlogdatapb.MinimalTable{t1v2},
+			},
+		},
+		{
+			name: "before first version",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-5",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid position",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    "not a position",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "unsupported flavor",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: tablet.Alias,
+				Position:    "FilePos/binlog.000001:4",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet not found",
+			req: &vtctldatapb.GetSchemaAtPositionRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  404,
+				},
+				Position: pos1,
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := &sqltypes.Result{Fields: fields}
+			if tt.row != nil {
+				qr.Rows = [][]sqltypes.Value{tt.row}
+			}
+			tmc := &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(qr),
+					},
+				},
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+
+			resp, err := vtctld.GetSchemaAtPosition(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestGetSchemaDrift(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	tablets := []*topodatapb.Tablet{
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_PRIMARY},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_REPLICA},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_PRIMARY},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 201}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_REPLICA},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 202}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_RDONLY},
+	}
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, tablets...)
+
+	const (
+		t1     = "CREATE TABLE `t1` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"
+		t1Name = "CREATE TABLE `t1` (\n  `id` int NOT NULL,\n  `name` varchar(64),\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"
+		t2     = "CREATE TABLE `t2` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"
+		t3     = "CREATE TABLE `t3` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"
+	)
+	table := func(name, schema string) *tabletmanagerdatapb.TableDefinition {
+		return &tabletmanagerdatapb.TableDefinition{Name: name, Schema: schema, Type: tmutils.TableBaseTable}
+	}
+	reference := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{table("t1", t1), table("t2", t2)},
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {Schema: reference},
+			"zone1-0000000101": {Schema: reference},
+			"zone1-0000000200": {
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{table("t1", t1Name), table("t2", t2), table("t3", t3)},
+				},
+			},
+			"zone1-0000000201": {
+				// Same table, only formatted differently.
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{table("t1", "create table t1 (id int not null primary key) engine innodb")},
+				},
+			},
+			"zone1-0000000202": {Error: assert.AnError},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.GetSchemaDrift(ctx, &vtctldatapb.GetSchemaDriftRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	require.Len(t, resp.Tablets, 3)
+	assert.Contains(t, resp.Tablets[2].Error, assert.AnError.Error())
+	resp.Tablets[2].Error = ""
+
+	expected := &vtctldatapb.GetSchemaDriftResponse{
+		ReferenceTablet: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Tablets: []*vtctldatapb.TabletSchemaDrift{
+			{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+				Shard:       "80-",
+				TabletType:  topodatapb.TabletType_PRIMARY,
+				Tables: []*vtctldatapb.TableSchemaDrift{
+					{
+						Name:            "t1",
+						Kind:            vtctldatapb.TableSchemaDrift_DIFFERENT,
+						ReferenceSchema: t1,
+						Schema:          t1Name,
+						Diff:            "ALTER TABLE `t1` DROP COLUMN `name`",
+					},
+					{
+						Name:   "t3",
+						Kind:   vtctldatapb.TableSchemaDrift_EXTRA,
+						Schema: t3,
+						Diff:   "DROP TABLE `t3`",
+					},
+				},
+			},
+			{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 201},
+				Shard:       "80-",
+				TabletType:  topodatapb.TabletType_REPLICA,
+				Tables: []*vtctldatapb.TableSchemaDrift{
+					{
+						Name:            "t2",
+						Kind:            vtctldatapb.TableSchemaDrift_MISSING,
+						ReferenceSchema: t2,
+						Diff:            "CREATE TABLE `t2` (\n\t`id` int NOT NULL,\n\tPRIMARY KEY (`id`)\n) ENGINE InnoDB",
+					},
+				},
+			},
+			{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 202},
+				Shard:       "80-",
+				TabletType:  topodatapb.TabletType_RDONLY,
+			},
+		},
+	}
+	utils.MustMatch(t, expected, resp)
+
+	t.Run("concurrency", func(t *testing.T) {
+		resp, err := vtctld.GetSchemaDrift(ctx, &vtctldatapb.GetSchemaDriftRequest{Keyspace: "testkeyspace", Concurrency: 1})
+		require.NoError(t, err)
+		require.Len(t, resp.Tablets, 3)
+		assert.Contains(t, resp.Tablets[2].Error, assert.AnError.Error())
+		resp.Tablets[2].Error = ""
+		utils.MustMatch(t, expected, resp)
+	})
+
+	t.Run("no primary", func(t *testing.T) {
+		testutil.AddTablets(ctx, t, ts, nil, &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 300},
+			Keyspace: "noprimary",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_REPLICA,
+		})
+
+		_, err := vtctld.GetSchemaDrift(ctx, &vtctldatapb.GetSchemaDriftRequest{Keyspace: "noprimary"})
+		assert.ErrorContains(t, err, "no primary in keyspace noprimary")
+	})
+}
+
 func TestGetSchemaMigrations(t *testing.T) {
 	t.Parallel()
 
@@ -6485,6 +7327,111 @@ func TestGetShardReplication(t *testing.T) {
 	}
 }
 
+func TestGetShardWriteFreezeStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, vtctld := newWriteFreezeTestServer(ctx, t, true)
+	writeFreeze := &topodatapb.Shard_WriteFreeze{Reason: "maintenance"}
+	_, err := ts.UpdateShardFields(ctx, "ks", "-", func(si *topo.ShardInfo) error {
+		si.WriteFreeze = writeFreeze
+		return nil
+	})
+	require.NoError(t, err)
+
+	resp, err := vtctld.GetShardWriteFreezeStatus(ctx, &vtctldatapb.GetShardWriteFreezeStatusRequest{
+		Keyspace: "ks",
+		Shard:    "-",
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.GetShardWriteFreezeStatusResponse{
+		WriteFreeze:          writeFreeze,
+		PrimaryAlias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		PrimarySuperReadOnly: true,
+	}, resp)
+
+	_, err = vtctld.GetShardWriteFreezeStatus(ctx, &vtctldatapb.GetShardWriteFreezeStatusRequest{
+		Keyspace: "ks",
+		Shard:    "-80",
+	})
+	assert.Error(t, err)
+}
+
+func TestGetSnapshotKeyspaceStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	tmc := &testutil.TabletManagerClient{
+		FullStatusResults: map[string]struct {
+			Status *replicationdatapb.FullStatus
+			Error  error
+		}{
+			"zone1-0000000100": {
+				Status: &replicationdatapb.FullStatus{SnapshotRestore: &replicationdatapb.SnapshotRestoreStatus{
+					Phase:          replicationdatapb.SnapshotRestoreStatus_CAUGHT_UP,
+					Position:       "MySQL56/00000000-0000-0000-0000-000000000000:1-10",
+					TargetPosition: "MySQL56/00000000-0000-0000-0000-000000000000:1-10",
+				}},
+			},
+			"zone1-0000000101": {
+				Error: assert.AnError,
+			},
+			"zone1-0000000200": {
+				Status: &replicationdatapb.FullStatus{SnapshotRestore: &replicationdatapb.SnapshotRestoreStatus{
+					Phase:          replicationdatapb.SnapshotRestoreStatus_CATCHING_UP,
+					Position:       "MySQL56/00000000-0000-0000-0000-000000000000:1-5",
+					TargetPosition: "MySQL56/00000000-0000-0000-0000-000000000000:1-10",
+				}},
+			},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name: "snapshot",
+		Keyspace: &topodatapb.Keyspace{
+			KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+			BaseKeyspace: "ks",
+			SnapshotTime: &vttime.Time{Seconds: 1000},
+		},
+	})
+	testutil.AddTablets(ctx, t, ts, nil,
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, Keyspace: "snapshot", Shard: "80-", Type: topodatapb.TabletType_RESTORE},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "snapshot", Shard: "-80", Type: topodatapb.TabletType_REPLICA},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}, Keyspace: "snapshot", Shard: "-80", Type: topodatapb.TabletType_RESTORE},
+		&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 300}, Keyspace: "ks", Shard: "0", Type: topodatapb.TabletType_PRIMARY},
+	)
+
+	resp, err := vtctld.GetSnapshotKeyspaceStatus(ctx, &vtctldatapb.GetSnapshotKeyspaceStatusRequest{Keyspace: "snapshot"})
+	require.NoError(t, err)
+	require.Len(t, resp.Tablets, 3)
+	assert.False(t, resp.CaughtUp)
+	utils.MustMatch(t, &vtctldatapb.GetSnapshotKeyspaceStatusResponse_TabletStatus{
+		TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Shard:       "-80",
+		TabletType:  topodatapb.TabletType_REPLICA,
+		Status:      tmc.FullStatusResults["zone1-0000000100"].Status.SnapshotRestore,
+	}, resp.Tablets[0])
+	assert.Equal(t, assert.AnError.Error(), resp.Tablets[1].Error)
+	assert.Nil(t, resp.Tablets[1].Status)
+	assert.Equal(t, replicationdatapb.SnapshotRestoreStatus_CATCHING_UP, resp.Tablets[2].Status.Phase)
+
+	tmc.FullStatusResults["zone1-0000000101"] = tmc.FullStatusResults["zone1-0000000100"]
+	tmc.FullStatusResults["zone1-0000000200"] = tmc.FullStatusResults["zone1-0000000100"]
+	resp, err = vtctld.GetSnapshotKeyspaceStatus(ctx, &vtctldatapb.GetSnapshotKeyspaceStatusRequest{Keyspace: "snapshot"})
+	require.NoError(t, err)
+	assert.True(t, resp.CaughtUp)
+
+	_, err = vtctld.GetSnapshotKeyspaceStatus(ctx, &vtctldatapb.GetSnapshotKeyspaceStatusRequest{Keyspace: "ks"})
+	assert.ErrorContains(t, err, "keyspace ks is not a SNAPSHOT keyspace")
+}
+
 func TestGetSrvKeyspaceNames(t *testing.T) {
 	t.Parallel()
 
@@ -7198,6 +8145,49 @@ func TestGetTablets(t *testing.T) {
 			},
 			shouldErr: false,
 		},
+		{
+			name:  "tags filter",
+			cells: []string{"cell1"},
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  100,
+					},
+					Keyspace: "ks1",
+					Tags:     map[string]string{"role": "reporting", "team": "analytics"},
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  101,
+					},
+					Keyspace: "ks1",
+					Tags:     map[string]string{"role": "reporting"},
+				},
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  102,
+					},
+					Keyspace: "ks1",
+				},
+			},
+			req: &vtctldatapb.GetTabletsRequest{
+				Tags: map[string]string{"role": "reporting", "team": "analytics"},
+			},
+			expected: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "cell1",
+						Uid:  100,
+					},
+					Keyspace: "ks1",
+					Tags:     map[string]string{"role": "reporting", "team": "analytics"},
+				},
+			},
+			shouldErr: false,
+		},
 		{
 			name:  "keyspace and shard filter - stale primary",
 			cells: []string{"cell1"},
@@ -7888,6 +8878,136 @@ func TestGetTopologyPath(t *testing.T) {
 	}
 }
 
+func TestPlanReshard(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	tablets := []*topodatapb.Tablet{
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_PRIMARY},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}, Keyspace: "testkeyspace", Shard: "-80", Type: topodatapb.TabletType_REPLICA},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}, Keyspace: "testkeyspace", Shard: "80-", Type: topodatapb.TabletType_PRIMARY},
+	}
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, tablets...)
+	err := ts.SaveVSchema(ctx, "testkeyspace", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"binary": {Type: "binary"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "binary", Column: "id"}}},
+			"t2": {Type: "reference"},
+		},
+	})
+	require.NoError(t, err)
+
+	table := func(dataLength, rowCount uint64) *tabletmanagerdatapb.SchemaDefinition {
+		return &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{Name: "t1", DataLength: dataLength, RowCount: rowCount}},
+		}
+	}
+	rows := func(ids ...string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "varbinary"), ids...))
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000101": {Schema: table(6000, 6)},
+			"zone1-0000000200": {Schema: table(2000, 2)},
+		},
+		// The rows of -80 are sampled from the replica, and those of 80- from
+		// the primary, as the shard has no replica.
+		ExecuteFetchAsAppResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000101": {Response: rows("\x10", "\x11", "\x12", "\x13", "\x14", "\x15")},
+			"zone1-0000000200": {Response: rows("\x90", "\xa0")},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.PlanReshard(ctx, &vtctldatapb.PlanReshardRequest{Keyspace: "testkeyspace", ShardCount: 2})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.PlanReshardResponse{
+		Shards: []string{"-14", "14-"},
+		PlannedShards: []*vtctldatapb.PlannedShard{
+			{Name: "-14", KeyRange: &topodatapb.KeyRange{End: []byte{0x14}}, EstimatedDataSize: 4000, EstimatedRowCount: 4, SampledRows: 4},
+			{Name: "14-", KeyRange: &topodatapb.KeyRange{Start: []byte{0x14}}, EstimatedDataSize: 4000, EstimatedRowCount: 4, SampledRows: 4},
+		},
+		SampledRows: 8,
+	}, resp)
+
+	resp, err = vtctld.PlanReshard(ctx, &vtctldatapb.PlanReshardRequest{Keyspace: "testkeyspace", ShardCount: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-13", "13-90", "90-"}, resp.Shards)
+
+	// Merging the shards into one.
+	resp, err = vtctld.PlanReshard(ctx, &vtctldatapb.PlanReshardRequest{Keyspace: "testkeyspace", SourceShards: []string{"80-", "-80"}, ShardCount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-"}, resp.Shards)
+
+	// There are not enough distinct keyspace ids in -80 to split it into 8
+	// shards.
+	_, err = vtctld.PlanReshard(ctx, &vtctldatapb.PlanReshardRequest{Keyspace: "testkeyspace", SourceShards: []string{"-80"}, ShardCount: 8})
+	assert.ErrorContains(t, err, "cannot split key range -80 into 8 shards")
+
+	_, err = vtctld.PlanReshard(ctx, &vtctldatapb.PlanReshardRequest{Keyspace: "testkeyspace", ShardCount: 2, Tables: []string{"t2"}})
+	assert.ErrorContains(t, err, "table t2 has no primary vindex")
+}
+
+func TestWatchTopologyPath(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	client := localvtctldclient.New(vtctld)
+
+	err := ts.SaveVSchema(ctx, "keyspace1", &vschemapb.Keyspace{})
+	require.NoError(t, err)
+
+	t.Run("missing file", func(t *testing.T) {
+		stream, err := client.WatchTopologyPath(ctx, &vtctldatapb.WatchTopologyPathRequest{Path: "/global/keyspaces/keyspace2/VSchema"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.ErrorContains(t, err, "no file at path /global/keyspaces/keyspace2/VSchema, only files can be watched")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		stream, err := client.WatchTopologyPath(ctx, &vtctldatapb.WatchTopologyPathRequest{Path: "/global/keyspaces/keyspace1/VSchema"})
+		require.NoError(t, err)
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "VSchema", resp.Cell.Name)
+		assert.Equal(t, "/global/keyspaces/keyspace1/VSchema", resp.Cell.Path)
+		assert.Equal(t, "", resp.Cell.Data)
+
+		err = ts.SaveVSchema(ctx, "keyspace1", &vschemapb.Keyspace{Sharded: true})
+		require.NoError(t, err)
+		resp, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "sharded:true", resp.Cell.Data)
+
+		err = ts.DeleteVSchema(ctx, "keyspace1")
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.ErrorContains(t, err, "file at path /global/keyspaces/keyspace1/VSchema was deleted")
+	})
+}
+
 func TestGetVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -7937,6 +9057,34 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestInvalidateQueryPlans(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err := vtctld.InvalidateQueryPlans(ctx, &vtctldatapb.InvalidateQueryPlansRequest{})
+	assert.Error(t, err)
+
+	for i := 1; i <= planInvalidationHistorySize+1; i++ {
+		resp, err := vtctld.InvalidateQueryPlans(ctx, &vtctldatapb.InvalidateQueryPlansRequest{Tables: []string{"commerce.customer", "corder"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, i, resp.Invalidation.Id)
+	}
+
+	// Only the latest invalidations are kept.
+	control, err := ts.GetPlanCacheControl(ctx)
+	require.NoError(t, err)
+	require.Len(t, control.Invalidations, planInvalidationHistorySize)
+	assert.EqualValues(t, 2, control.Invalidations[0].Id)
+	assert.Equal(t, []string{"commerce.customer", "corder"}, control.Invalidations[0].Tables)
+}
+
 func TestLaunchSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -8223,10 +9371,49 @@ func TestPingTablet(t *testing.T) {
 				return
 			}
 
-			require.NoError(t, err)
-			assert.Equal(t, tt.expected, resp)
-		})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestPinQueryPlan(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err := vtctld.PinQueryPlan(ctx, &vtctldatapb.PinQueryPlanRequest{Keyspace: "commerce", Query: "select from"})
+	assert.Error(t, err)
+
+	// The queries are formatted like vtgate formats them, and pinned once.
+	for i := 0; i < 2; i++ {
+		_, err = vtctld.PinQueryPlan(ctx, &vtctldatapb.PinQueryPlanRequest{Keyspace: "commerce", Query: "SELECT id FROM customer WHERE email = :vtg1"})
+		require.NoError(t, err)
 	}
+	_, err = vtctld.PinQueryPlan(ctx, &vtctldatapb.PinQueryPlanRequest{Keyspace: "commerce", Query: "select 1 from dual"})
+	require.NoError(t, err)
+
+	resp, err := vtctld.GetPlanCacheControl(ctx, &vtctldatapb.GetPlanCacheControlRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtgatepb.PinnedPlan{
+		{Keyspace: "commerce", Query: "select id from customer where email = :vtg1"},
+		{Keyspace: "commerce", Query: "select 1 from dual"},
+	}, resp.PlanCacheControl.PinnedPlans)
+
+	unpinned, err := vtctld.UnpinQueryPlan(ctx, &vtctldatapb.UnpinQueryPlanRequest{Keyspace: "commerce", Query: "select id from customer where email = :vtg1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtgatepb.PinnedPlan{
+		{Keyspace: "commerce", Query: "select 1 from dual"},
+	}, unpinned.PlanCacheControl.PinnedPlans)
+
+	_, err = vtctld.UnpinQueryPlan(ctx, &vtctldatapb.UnpinQueryPlanRequest{Keyspace: "customer", Query: "select 1 from dual"})
+	assert.Error(t, err)
 }
 
 func TestPlannedReparentShard(t *testing.T) {
@@ -8623,6 +9810,116 @@ func TestRebuildVSchemaGraph(t *testing.T) {
 	}
 }
 
+func TestReconcileSrvVSchemas(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: false}))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	getDiffs := func() map[string]*vtctldatapb.SrvVSchemaDiff {
+		resp, err := vtctld.GetSrvVSchemaDiffs(ctx, &vtctldatapb.GetSrvVSchemaDiffsRequest{})
+		require.NoError(t, err)
+		return resp.Diffs
+	}
+	assert.Empty(t, getDiffs())
+
+	// Staging requires cells.
+	_, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace: "ks",
+		VSchema:  &vschemapb.Keyspace{Sharded: true},
+		Staged:   true,
+	})
+	assert.Error(t, err)
+
+	staged := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1": {},
+		},
+	}
+	_, err = vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace: "ks",
+		VSchema:  staged,
+		Cells:    []string{"zone1"},
+		Staged:   true,
+	})
+	require.NoError(t, err)
+
+	vs, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	utils.MustMatch(t, &vschemapb.Keyspace{Sharded: false}, vs, "global vschema changed by staged ApplyVSchema")
+	utils.MustMatch(t, map[string]*vtctldatapb.SrvVSchemaDiff{
+		"zone1": {Keyspaces: []string{"ks"}},
+	}, getDiffs())
+
+	// A rebuild between stage and promote keeps the staged vschema.
+	_, err = vtctld.RebuildVSchemaGraph(ctx, &vtctldatapb.RebuildVSchemaGraphRequest{})
+	require.NoError(t, err)
+	sv, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	utils.MustMatch(t, staged, sv.Keyspaces["ks"], "staged vschema reverted by rebuild")
+	utils.MustMatch(t, map[string]*vtctldatapb.SrvVSchemaDiff{
+		"zone1": {Keyspaces: []string{"ks"}},
+	}, getDiffs())
+
+	// Dry run reports but does not change anything.
+	resp, err := vtctld.ReconcileSrvVSchemas(ctx, &vtctldatapb.ReconcileSrvVSchemasRequest{
+		PromoteFromCell: "zone1",
+		DryRun:          true,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.SrvVSchemaDiff{Keyspaces: []string{"ks"}}, resp.Promoted)
+	assert.Len(t, getDiffs(), 1)
+
+	// Promote the staged vschema to the global topo and every cell.
+	_, err = vtctld.ReconcileSrvVSchemas(ctx, &vtctldatapb.ReconcileSrvVSchemasRequest{
+		PromoteFromCell: "zone1",
+	})
+	require.NoError(t, err)
+
+	vs, err = ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	utils.MustMatch(t, staged, vs, "staged vschema was not promoted")
+	assert.Empty(t, getDiffs())
+	_, err = ts.GetStagedSrvVSchema(ctx, "zone1")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "promoted vschema is still staged: %v", err)
+
+	// Stage routing rules in zone2, then revert them.
+	_, err = vtctld.ApplyRoutingRules(ctx, &vtctldatapb.ApplyRoutingRulesRequest{
+		RoutingRules: &vschemapb.RoutingRules{
+			Rules: []*vschemapb.RoutingRule{{FromTable: "t2", ToTables: []string{"ks.t1"}}},
+		},
+		RebuildCells: []string{"zone2"},
+		Staged:       true,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]*vtctldatapb.SrvVSchemaDiff{
+		"zone2": {RoutingRules: true},
+	}, getDiffs())
+
+	resp, err = vtctld.ReconcileSrvVSchemas(ctx, &vtctldatapb.ReconcileSrvVSchemasRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Diffs, 1)
+	assert.Nil(t, resp.Promoted)
+	assert.Empty(t, getDiffs())
+
+	// The reverted routing rules do not come back with a rebuild.
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+	assert.Empty(t, getDiffs())
+
+	rr, err := ts.GetRoutingRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rr.Rules)
+}
+
 func TestRefreshState(t *testing.T) {
 	t.Parallel()
 
@@ -10720,6 +12017,73 @@ func TestRetrySchemaMigration(t *testing.T) {
 	}
 }
 
+func TestRollbackVSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+
+	v1 := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{"t1": {}},
+	}
+	v2 := &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{"t1": {}, "t2": {}},
+	}
+	for i, vs := range []*vschemapb.Keyspace{v1, v2} {
+		resp, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+			Keyspace: "testkeyspace",
+			VSchema:  vs,
+			Author:   "alice",
+			Comment:  fmt.Sprintf("change %d", i+1),
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, i+1, resp.Version)
+	}
+
+	history, err := vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	require.Len(t, history.Versions, 2)
+	assert.Equal(t, "alice", history.Versions[1].Author)
+	assert.Equal(t, "change 2", history.Versions[1].Comment)
+
+	diff, err := vtctld.DiffVSchemaVersions(ctx, &vtctldatapb.DiffVSchemaVersionsRequest{Keyspace: "testkeyspace", FromVersion: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "+ table t2: {}", diff.Diff)
+	_, err = vtctld.DiffVSchemaVersions(ctx, &vtctldatapb.DiffVSchemaVersionsRequest{Keyspace: "testkeyspace", FromVersion: 3})
+	assert.Error(t, err)
+
+	_, err = vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{Keyspace: "testkeyspace", Version: 5})
+	assert.Error(t, err)
+
+	resp, err := vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace: "testkeyspace",
+		Version:  1,
+		Author:   "bob",
+		Comment:  "revert t2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "- table t2: {}", resp.Diff)
+	assert.EqualValues(t, 3, resp.Version.Version)
+	assert.EqualValues(t, 1, resp.Version.RolledBackTo)
+	assert.Equal(t, "bob", resp.Version.Author)
+
+	vs, err := ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, vs)
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, srvVSchema.Keyspaces["testkeyspace"])
+}
+
 func TestRunHealthCheck(t *testing.T) {
 	t.Parallel()
 
@@ -10824,6 +12188,91 @@ func TestRunHealthCheck(t *testing.T) {
 	}
 }
 
+func TestSchedulePlannedReparentShard(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	tmc := &testutil.TabletManagerClient{
+		ReplicationStatusResults: map[string]struct {
+			Position *replicationdatapb.Status
+			Error    error
+		}{
+			"zone1-0000000101": {Position: &replicationdatapb.Status{ReplicationLagSeconds: 60}},
+		},
+	}
+	vtctld := NewTestVtctldServer(ts, tmc)
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	})
+
+	_, err := vtctld.SchedulePlannedReparentShard(ctx, &vtctldatapb.SchedulePlannedReparentShardRequest{
+		Request: &vtctldatapb.PlannedReparentShardRequest{Keyspace: "ks"},
+	})
+	assert.ErrorContains(t, err, "keyspace and shard of the PlannedReparentShard request are required")
+	_, err = vtctld.SchedulePlannedReparentShard(ctx, &vtctldatapb.SchedulePlannedReparentShardRequest{
+		Request: &vtctldatapb.PlannedReparentShardRequest{Keyspace: "ks", Shard: "80-"},
+	})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode error, got %v", err)
+
+	// A reparent scheduled in an hour can be cancelled.
+	later, err := vtctld.SchedulePlannedReparentShard(ctx, &vtctldatapb.SchedulePlannedReparentShardRequest{
+		Request:   &vtctldatapb.PlannedReparentShardRequest{Keyspace: "ks", Shard: "-"},
+		StartTime: protoutil.TimeToProto(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, scheduledReparentScheduled, later.ScheduledReparent.State)
+	assert.Equal(t, protoutil.DurationToProto(defaultScheduledReparentRetryInterval), later.ScheduledReparent.RetryInterval)
+
+	// A reparent scheduled right away fails on its preconditions, and is not
+	// retried without a retry window.
+	now, err := vtctld.SchedulePlannedReparentShard(ctx, &vtctldatapb.SchedulePlannedReparentShardRequest{
+		Request: &vtctldatapb.PlannedReparentShardRequest{Keyspace: "ks", Shard: "-"},
+		Preconditions: &vtctldatapb.ReparentPreconditions{
+			MaxReplicationLag: protoutil.DurationToProto(10 * time.Second),
+		},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		resp, err := vtctld.GetScheduledReparents(ctx, &vtctldatapb.GetScheduledReparentsRequest{Keyspace: "ks", Shard: "-"})
+		require.NoError(t, err)
+		require.Len(t, resp.ScheduledReparents, 2)
+		return resp.ScheduledReparents[0].Id == now.ScheduledReparent.Id && resp.ScheduledReparents[0].State == scheduledReparentFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp, err := vtctld.GetScheduledReparents(ctx, &vtctldatapb.GetScheduledReparentsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.ScheduledReparents, 2)
+	assert.EqualValues(t, 1, resp.ScheduledReparents[0].Attempts)
+	assert.Equal(t, "replica zone1-0000000101 lags by 1m0s, more than 10s", resp.ScheduledReparents[0].Message)
+	assert.Equal(t, later.ScheduledReparent.Id, resp.ScheduledReparents[1].Id)
+	assert.Equal(t, scheduledReparentScheduled, resp.ScheduledReparents[1].State)
+
+	resp, err = vtctld.GetScheduledReparents(ctx, &vtctldatapb.GetScheduledReparentsRequest{Keyspace: "other"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.ScheduledReparents)
+
+	cancelled, err := vtctld.CancelScheduledReparent(ctx, &vtctldatapb.CancelScheduledReparentRequest{Id: later.ScheduledReparent.Id})
+	require.NoError(t, err)
+	assert.Equal(t, scheduledReparentCancelled, cancelled.ScheduledReparent.State)
+
+	_, err = vtctld.CancelScheduledReparent(ctx, &vtctldatapb.CancelScheduledReparentRequest{Id: later.ScheduledReparent.Id})
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	_, err = vtctld.CancelScheduledReparent(ctx, &vtctldatapb.CancelScheduledReparentRequest{Id: now.ScheduledReparent.Id})
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	_, err = vtctld.CancelScheduledReparent(ctx, &vtctldatapb.CancelScheduledReparentRequest{Id: "unknown"})
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+}
+
 func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -10856,6 +12305,24 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "declarative rules",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "rules:ackers=1;different_tag=region",
+			},
+			expected: &vtctldatapb.SetKeyspaceDurabilityPolicyResponse{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: "rules:ackers=1;different_tag=region",
+				},
+			},
+		},
 		{
 			name: "keyspace not found",
 			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
@@ -10863,6 +12330,20 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 			},
 			expectedErr: "node doesn't exist: keyspaces/ks1",
 		},
+		{
+			name: "invalid declarative rules",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "rules:quorum=2",
+			},
+			expectedErr: "durability policy <rules:quorum=2> is not a valid policy. Please register it as a policy first",
+		},
 		{
 			name: "fail to update durability policy",
 			keyspaces: []*vtctldatapb.Keyspace{
@@ -10904,6 +12385,82 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	}
 }
 
+func TestSetKeyspaceTwoPC(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "ks1",
+		Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "none"},
+	})
+	// Only zone1 serves the keyspace.
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks1", &topodatapb.SrvKeyspace{}))
+
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.SetKeyspaceTwoPC(ctx, &vtctldatapb.SetKeyspaceTwoPCRequest{Keyspace: "ks1", Enabled: true})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.SetKeyspaceTwoPCResponse{
+		Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "none", TwopcEnabled: true},
+	}, resp)
+	srvKeyspace, err := ts.GetSrvKeyspace(ctx, "zone1", "ks1")
+	require.NoError(t, err)
+	assert.True(t, srvKeyspace.TwopcEnabled)
+	_, err = ts.GetSrvKeyspace(ctx, "zone2", "ks1")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "zone2 SrvKeyspace should not be created: %v", err)
+
+	resp, err = vtctld.SetKeyspaceTwoPC(ctx, &vtctldatapb.SetKeyspaceTwoPCRequest{Keyspace: "ks1", Enabled: false})
+	require.NoError(t, err)
+	assert.False(t, resp.Keyspace.TwopcEnabled)
+	srvKeyspace, err = ts.GetSrvKeyspace(ctx, "zone1", "ks1")
+	require.NoError(t, err)
+	assert.False(t, srvKeyspace.TwopcEnabled)
+
+	_, err = vtctld.SetKeyspaceTwoPC(ctx, &vtctldatapb.SetKeyspaceTwoPCRequest{Keyspace: "ks2", Enabled: true})
+	assert.EqualError(t, err, "node doesn't exist: keyspaces/ks2")
+}
+
+func TestSetKeyspaceCharset(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddKeyspaces(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "ks1",
+		Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "none"},
+	})
+
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.SetKeyspaceCharset(ctx, &vtctldatapb.SetKeyspaceCharsetRequest{Keyspace: "ks1", Charset: "UTF8"})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.SetKeyspaceCharsetResponse{
+		Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "none", DefaultCharset: "utf8mb3"},
+	}, resp)
+
+	_, err = vtctld.SetKeyspaceCharset(ctx, &vtctldatapb.SetKeyspaceCharsetRequest{Keyspace: "ks1", Collation: "utf8mb4_bin"})
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(ctx, "ks1")
+	require.NoError(t, err)
+	assert.Equal(t, "", ki.DefaultCharset)
+	assert.Equal(t, "utf8mb4_bin", ki.DefaultCollation)
+
+	_, err = vtctld.SetKeyspaceCharset(ctx, &vtctldatapb.SetKeyspaceCharsetRequest{Keyspace: "ks1", Charset: "latin1", Collation: "utf8mb4_bin"})
+	assert.EqualError(t, err, "collation utf8mb4_bin is not a collation of character set latin1")
+
+	_, err = vtctld.SetKeyspaceCharset(ctx, &vtctldatapb.SetKeyspaceCharsetRequest{Keyspace: "ks2", Charset: "utf8mb4"})
+	assert.EqualError(t, err, "node doesn't exist: keyspaces/ks2")
+}
+
 func TestSetShardIsPrimaryServing(t *testing.T) {
 	t.Parallel()
 
@@ -12837,6 +14394,37 @@ func TestTabletExternallyReparented(t *testing.T) {
 	}
 }
 
+func TestUnfreezeShardWrites(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, vtctld := newWriteFreezeTestServer(ctx, t, false)
+	_, err := vtctld.UnfreezeShardWrites(ctx, &vtctldatapb.UnfreezeShardWritesRequest{
+		Keyspace: "ks",
+		Shard:    "-",
+	})
+	assert.ErrorContains(t, err, "writes to shard ks/- are not frozen")
+
+	_, err = ts.UpdateShardFields(ctx, "ks", "-", func(si *topo.ShardInfo) error {
+		si.WriteFreeze = &topodatapb.Shard_WriteFreeze{Reason: "maintenance"}
+		return nil
+	})
+	require.NoError(t, err)
+
+	resp, err := vtctld.UnfreezeShardWrites(ctx, &vtctldatapb.UnfreezeShardWritesRequest{
+		Keyspace: "ks",
+		Shard:    "-",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, resp.Shard.Shard.WriteFreeze)
+
+	si, err := ts.GetShard(ctx, "ks", "-")
+	require.NoError(t, err)
+	assert.Nil(t, si.WriteFreeze)
+}
+
 func TestUpdateCellInfo(t *testing.T) {
 	t.Parallel()
 
@@ -12893,6 +14481,60 @@ func TestUpdateCellInfo(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "keep location",
+			cells: map[string]*topodatapb.CellInfo{
+				"zone1": {
+					ServerAddress: ":1111",
+					Root:          "/zone1",
+					Region:        "us-east",
+					Zone:          "us-east-1a",
+					TabletTags:    map[string]string{"rack": "r1"},
+				},
+			},
+			req: &vtctldatapb.UpdateCellInfoRequest{
+				Name: "zone1",
+				CellInfo: &topodatapb.CellInfo{
+					Root: "/zones/zone1",
+				},
+			},
+			expected: &vtctldatapb.UpdateCellInfoResponse{
+				Name: "zone1",
+				CellInfo: &topodatapb.CellInfo{
+					ServerAddress: ":1111",
+					Root:          "/zones/zone1",
+					Region:        "us-east",
+					Zone:          "us-east-1a",
+					TabletTags:    map[string]string{"rack": "r1"},
+				},
+			},
+		},
+		{
+			name: "clear location",
+			cells: map[string]*topodatapb.CellInfo{
+				"zone1": {
+					ServerAddress: ":1111",
+					Root:          "/zone1",
+					Region:        "us-east",
+					Zone:          "us-east-1a",
+					TabletTags:    map[string]string{"rack": "r1"},
+				},
+			},
+			req: &vtctldatapb.UpdateCellInfoRequest{
+				Name:            "zone1",
+				CellInfo:        &topodatapb.CellInfo{},
+				ClearRegion:     true,
+				ClearZone:       true,
+				ClearTabletTags: true,
+			},
+			expected: &vtctldatapb.UpdateCellInfoResponse{
+				Name: "zone1",
+				CellInfo: &topodatapb.CellInfo{
+					ServerAddress: ":1111",
+					Root:          "/zone1",
+				},
+			},
+		},
 		{
 			name: "no update",
 			cells: map[string]*topodatapb.CellInfo{
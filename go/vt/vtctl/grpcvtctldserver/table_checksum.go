@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/tablechecksum"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const defaultTableChecksumChunkSize = 10000
+
+// checksumTableDefinition returns the columns and the primary key of a table,
+// which must be the same on all the tablets.
+func (s *VtctldServer) checksumTableDefinition(ctx context.Context, tablets []*topodatapb.Tablet, name string) (tablechecksum.Table, error) {
+	var table tablechecksum.Table
+	for i, tablet := range tablets {
+		alias := topoproto.TabletAliasString(tablet.Alias)
+		sd, err := s.tmc.GetSchema(ctx, tablet, &tabletmanagerdatapb.GetSchemaRequest{Tables: []string{name}, TableSchemaOnly: true})
+		if err != nil {
+			return table, fmt.Errorf("GetSchema(%v) failed: %w", alias, err)
+		}
+		var td *tabletmanagerdatapb.TableDefinition
+		for _, definition := range sd.TableDefinitions {
+			if definition.Name == name {
+				td = definition
+			}
+		}
+		if td == nil {
+			return table, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s not found on tablet %v", name, alias)
+		}
+		if len(td.PrimaryKeyColumns) == 0 {
+			return table, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s has no primary key on tablet %v", name, alias)
+		}
+
+		if i == 0 {
+			table = tablechecksum.Table{Name: name, Columns: td.Columns, PrimaryKeyColumns: td.PrimaryKeyColumns}
+			continue
+		}
+		if !slices.Equal(table.Columns, td.Columns) || !slices.Equal(table.PrimaryKeyColumns, td.PrimaryKeyColumns) {
+			return table, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s has different columns or primary keys on tablets %v and %v", name, topoproto.TabletAliasString(tablets[0].Alias), alias)
+		}
+	}
+	return table, nil
+}
+
+// checksumTableExecutor returns the executor running the checksum queries on
+// the tablet.
+func (s *VtctldServer) checksumTableExecutor(tablet *topodatapb.Tablet) tablechecksum.Executor {
+	return func(ctx context.Context, query string) (*sqltypes.Result, error) {
+		p3qr, err := s.tmc.ExecuteFetchAsApp(ctx, tablet, true, &tabletmanagerdatapb.ExecuteFetchAsAppRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ExecuteFetchAsApp(%v, %s) failed: %w", topoproto.TabletAliasString(tablet.Alias), query, err)
+		}
+		return sqltypes.Proto3ToResult(p3qr), nil
+	}
+}
@@ -232,6 +232,11 @@ type TabletManagerClient struct {
 	// FullStatus result
 	FullStatusResult *replicationdatapb.FullStatus
 	// keyed by tablet alias.
+	FullStatusResults map[string]struct {
+		Status *replicationdatapb.FullStatus
+		Error  error
+	}
+	// keyed by tablet alias.
 	GetPermissionsDelays map[string]time.Duration
 	// keyed by tablet alias.
 	GetPermissionsResults map[string]struct {
@@ -654,6 +659,10 @@ func (fake *TabletManagerClient) FullStatus(ctx context.Context, tablet *topodat
 		return fake.FullStatusResult, nil
 	}
 
+	if result, ok := fake.FullStatusResults[topoproto.TabletAliasString(tablet.Alias)]; ok {
+		return result.Status, result.Error
+	}
+
 	if fake.TopoServer == nil {
 		return nil, assert.AnError
 	}
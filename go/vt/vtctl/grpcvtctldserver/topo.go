@@ -19,16 +19,24 @@ package grpcvtctldserver
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -356,3 +364,184 @@ func removeShardCell(ctx context.Context, ts *topo.Server, cell string, keyspace
 
 	return err
 }
+
+// diffSrvVSchema returns how the SrvVSchema of a cell diverges from the one
+// built from the global topo, or nil if they are the same.
+func diffSrvVSchema(global, cell *vschemapb.SrvVSchema) *vtctldatapb.SrvVSchemaDiff {
+	if cell == nil {
+		cell = &vschemapb.SrvVSchema{}
+	}
+
+	diff := &vtctldatapb.SrvVSchemaDiff{}
+	for ks, vs := range global.Keyspaces {
+		if !proto.Equal(vs, cell.Keyspaces[ks]) {
+			diff.Keyspaces = append(diff.Keyspaces, ks)
+		}
+	}
+	for ks := range cell.Keyspaces {
+		if _, ok := global.Keyspaces[ks]; !ok {
+			diff.Keyspaces = append(diff.Keyspaces, ks)
+		}
+	}
+	sort.Strings(diff.Keyspaces)
+
+	diff.RoutingRules = !equalOrEmpty(global.RoutingRules, cell.RoutingRules)
+	diff.ShardRoutingRules = !equalOrEmpty(global.ShardRoutingRules, cell.ShardRoutingRules)
+	diff.KeyspaceRoutingRules = !equalOrEmpty(global.KeyspaceRoutingRules, cell.KeyspaceRoutingRules)
+
+	if len(diff.Keyspaces) == 0 && !diff.RoutingRules && !diff.ShardRoutingRules && !diff.KeyspaceRoutingRules {
+		return nil
+	}
+	return diff
+}
+
+// equalOrEmpty is like proto.Equal, but considers a nil message equal to an
+// empty one, since the topo does not distinguish between the two.
+func equalOrEmpty(a, b proto.Message) bool {
+	if proto.Equal(a, b) {
+		return true
+	}
+	return proto.Size(a) == 0 && proto.Size(b) == 0
+}
+
+// getSrvVSchemaDiffs returns the divergence from the global VSchema of each of
+// the given cells (or all cells, if empty), along with the SrvVSchema of each
+// diverging cell. Cells without a SrvVSchema are compared to an empty one.
+func getSrvVSchemaDiffs(ctx context.Context, ts *topo.Server, cells []string) (map[string]*vtctldatapb.SrvVSchemaDiff, map[string]*vschemapb.SrvVSchema, error) {
+	if len(cells) == 0 {
+		var err error
+		cells, err = ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	global, err := ts.BuildSrvVSchema(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diffs := make(map[string]*vtctldatapb.SrvVSchemaDiff)
+	svs := make(map[string]*vschemapb.SrvVSchema)
+	for _, cell := range cells {
+		sv, err := ts.GetSrvVSchema(ctx, cell)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return nil, nil, fmt.Errorf("GetSrvVSchema(%s) failed: %w", cell, err)
+		}
+
+		if diff := diffSrvVSchema(global, sv); diff != nil {
+			diffs[cell] = diff
+			svs[cell] = sv
+		}
+	}
+
+	return diffs, svs, nil
+}
+
+// stageSrvVSchema applies update to the changes staged in each of the given
+// cells, without touching the global topo, and rebuilds their SrvVSchema with
+// them. The staged changes are kept apart from the SrvVSchema, so that later
+// rebuilds keep them until they are promoted or reverted. They are updated
+// with a versioned write, so concurrent stagings don't overwrite each other.
+func stageSrvVSchema(ctx context.Context, ts *topo.Server, cells []string, update func(staged *vschemapb.SrvVSchema)) error {
+	for _, cell := range cells {
+		err := ts.UpdateStagedSrvVSchemaFields(ctx, cell, func(staged *vschemapb.SrvVSchema) error {
+			if staged.Keyspaces == nil {
+				staged.Keyspaces = map[string]*vschemapb.Keyspace{}
+			}
+			update(staged)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("UpdateStagedSrvVSchemaFields(%s) failed: %w", cell, err)
+		}
+	}
+
+	if err := ts.RebuildSrvVSchema(ctx, cells); err != nil {
+		return err
+	}
+	log.Infof("staged SrvVSchema change in cells %s", strings.Join(cells, ","))
+
+	return nil
+}
+
+// unstageSrvVSchema deletes the changes staged in each of the given cells (or
+// all cells, if empty), so that rebuilding their SrvVSchema reverts them.
+func unstageSrvVSchema(ctx context.Context, ts *topo.Server, cells []string) error {
+	if len(cells) == 0 {
+		var err error
+		cells, err = ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, cell := range cells {
+		if err := ts.DeleteStagedSrvVSchema(ctx, cell); err != nil {
+			return fmt.Errorf("DeleteStagedSrvVSchema(%s) failed: %w", cell, err)
+		}
+	}
+
+	return nil
+}
+
+// setShardWriteFreeze sets the write freeze of a shard, which must be locked,
+// and makes its tablets apply it. If the primary fails to apply it, the
+// previous write freeze is restored, so that the writes are never left half
+// frozen.
+func setShardWriteFreeze(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, si *topo.ShardInfo, writeFreeze *topodatapb.Shard_WriteFreeze) (*topo.ShardInfo, error) {
+	previous := si.WriteFreeze
+	updated, err := updateShardWriteFreeze(ctx, ts, si, writeFreeze)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyShardWriteFreeze(ctx, ts, tmc, updated); err != nil {
+		log.Errorf("failed to apply the write freeze of shard %s/%s, restoring the previous one: %v", si.Keyspace(), si.ShardName(), err)
+		restored, rerr := updateShardWriteFreeze(ctx, ts, updated, previous)
+		if rerr == nil {
+			rerr = applyShardWriteFreeze(ctx, ts, tmc, restored)
+		}
+		if rerr != nil {
+			log.Errorf("failed to restore the write freeze of shard %s/%s: %v", si.Keyspace(), si.ShardName(), rerr)
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func updateShardWriteFreeze(ctx context.Context, ts *topo.Server, si *topo.ShardInfo, writeFreeze *topodatapb.Shard_WriteFreeze) (*topo.ShardInfo, error) {
+	return ts.UpdateShardFields(ctx, si.Keyspace(), si.ShardName(), func(si *topo.ShardInfo) error {
+		si.WriteFreeze = writeFreeze
+		return nil
+	})
+}
+
+// applyShardWriteFreeze refreshes the tablets of a shard, so that they pick up
+// its write freeze, and checks that the MySQL of the primary is
+// super_read_only if and only if the writes are frozen.
+func applyShardWriteFreeze(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, si *topo.ShardInfo) error {
+	// The replicas are refreshed as well, so that they enforce the freeze if
+	// they get promoted.
+	if _, _, err := topotools.RefreshTabletsByShard(ctx, ts, tmc, si, nil, logutil.NewConsoleLogger()); err != nil {
+		return err
+	}
+	if si.PrimaryAlias == nil {
+		return nil
+	}
+
+	primary, err := ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	status, err := tmc.FullStatus(ctx, primary.Tablet)
+	if err != nil {
+		return fmt.Errorf("FullStatus(%v) failed: %w", primary.AliasString(), err)
+	}
+	if frozen := si.WriteFreeze != nil; status.SuperReadOnly != frozen {
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "primary %v has super_read_only=%v while the write freeze requires %v", primary.AliasString(), status.SuperReadOnly, frozen)
+	}
+
+	return nil
+}
@@ -54,11 +54,72 @@ func (client *localVtctldClient) ApplySchema(ctx context.Context, in *vtctldatap
 	return client.s.ApplySchema(ctx, in)
 }
 
+type applySchemaStreamStreamAdapter struct {
+	*grpcshim.BidiStream
+	ch chan *vtctldatapb.ApplySchemaStreamResponse
+}
+
+func (stream *applySchemaStreamStreamAdapter) Recv() (*vtctldatapb.ApplySchemaStreamResponse, error) {
+	select {
+	case <-stream.Context().Done():
+		return nil, stream.Context().Err()
+	case <-stream.Closed():
+		// Stream has been closed for future sends. If there are messages that
+		// have already been sent, receive them until there are no more. After
+		// all sent messages have been received, Recv will return the CloseErr.
+		select {
+		case msg := <-stream.ch:
+			return msg, nil
+		default:
+			return nil, stream.CloseErr()
+		}
+	case err := <-stream.ErrCh:
+		return nil, err
+	case msg := <-stream.ch:
+		return msg, nil
+	}
+}
+
+func (stream *applySchemaStreamStreamAdapter) Send(msg *vtctldatapb.ApplySchemaStreamResponse) error {
+	select {
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-stream.Closed():
+		return grpcshim.ErrStreamClosed
+	case stream.ch <- msg:
+		return nil
+	}
+}
+
+// ApplySchemaStream is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ApplySchemaStream(ctx context.Context, in *vtctldatapb.ApplySchemaRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_ApplySchemaStreamClient, error) {
+	stream := &applySchemaStreamStreamAdapter{
+		BidiStream: grpcshim.NewBidiStream(ctx),
+		ch:         make(chan *vtctldatapb.ApplySchemaStreamResponse, 1),
+	}
+	go func() {
+		err := client.s.ApplySchemaStream(in, stream)
+		stream.CloseWithError(err)
+	}()
+
+	return stream, nil
+}
+
 // ApplyShardRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ApplyShardRoutingRules(ctx context.Context, in *vtctldatapb.ApplyShardRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyShardRoutingRulesResponse, error) {
 	return client.s.ApplyShardRoutingRules(ctx, in)
 }
 
+// ApplyStatementACL is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ApplyStatementACL(ctx context.Context, in *vtctldatapb.ApplyStatementACLRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyStatementACLResponse, error) {
+	return client.s.ApplyStatementACL(ctx, in)
+}
+
+// ApplyTenantCatalog is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ApplyTenantCatalog(ctx context.Context, in *vtctldatapb.ApplyTenantCatalogRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyTenantCatalogResponse, error) {
+	return client.s.ApplyTenantCatalog(ctx, in)
+}
+
 // ApplyVSchema is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ApplyVSchema(ctx context.Context, in *vtctldatapb.ApplyVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.ApplyVSchemaResponse, error) {
 	return client.s.ApplyVSchema(ctx, in)
@@ -166,6 +227,16 @@ func (client *localVtctldClient) BackupShard(ctx context.Context, in *vtctldatap
 	return stream, nil
 }
 
+// BackupVerify is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) BackupVerify(ctx context.Context, in *vtctldatapb.BackupVerifyRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupVerifyResponse, error) {
+	return client.s.BackupVerify(ctx, in)
+}
+
+// CancelScheduledReparent is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) CancelScheduledReparent(ctx context.Context, in *vtctldatapb.CancelScheduledReparentRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelScheduledReparentResponse, error) {
+	return client.s.CancelScheduledReparent(ctx, in)
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CancelSchemaMigration(ctx context.Context, in *vtctldatapb.CancelSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelSchemaMigrationResponse, error) {
 	return client.s.CancelSchemaMigration(ctx, in)
@@ -181,11 +252,21 @@ func (client *localVtctldClient) CheckThrottler(ctx context.Context, in *vtctlda
 	return client.s.CheckThrottler(ctx, in)
 }
 
+// ChecksumTable is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ChecksumTable(ctx context.Context, in *vtctldatapb.ChecksumTableRequest, opts ...grpc.CallOption) (*vtctldatapb.ChecksumTableResponse, error) {
+	return client.s.ChecksumTable(ctx, in)
+}
+
 // CleanupSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CleanupSchemaMigration(ctx context.Context, in *vtctldatapb.CleanupSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CleanupSchemaMigrationResponse, error) {
 	return client.s.CleanupSchemaMigration(ctx, in)
 }
 
+// ClearTabletQuarantine is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ClearTabletQuarantine(ctx context.Context, in *vtctldatapb.ClearTabletQuarantineRequest, opts ...grpc.CallOption) (*vtctldatapb.ClearTabletQuarantineResponse, error) {
+	return client.s.ClearTabletQuarantine(ctx, in)
+}
+
 // CompleteSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CompleteSchemaMigration(ctx context.Context, in *vtctldatapb.CompleteSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CompleteSchemaMigrationResponse, error) {
 	return client.s.CompleteSchemaMigration(ctx, in)
@@ -231,6 +312,11 @@ func (client *localVtctldClient) DeleteTablets(ctx context.Context, in *vtctldat
 	return client.s.DeleteTablets(ctx, in)
 }
 
+// DiffVSchemaVersions is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DiffVSchemaVersions(ctx context.Context, in *vtctldatapb.DiffVSchemaVersionsRequest, opts ...grpc.CallOption) (*vtctldatapb.DiffVSchemaVersionsResponse, error) {
+	return client.s.DiffVSchemaVersions(ctx, in)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	return client.s.EmergencyReparentShard(ctx, in)
@@ -266,6 +352,11 @@ func (client *localVtctldClient) ForceCutOverSchemaMigration(ctx context.Context
 	return client.s.ForceCutOverSchemaMigration(ctx, in)
 }
 
+// FreezeShardWrites is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) FreezeShardWrites(ctx context.Context, in *vtctldatapb.FreezeShardWritesRequest, opts ...grpc.CallOption) (*vtctldatapb.FreezeShardWritesResponse, error) {
+	return client.s.FreezeShardWrites(ctx, in)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	return client.s.GetBackups(ctx, in)
@@ -296,6 +387,11 @@ func (client *localVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatap
 	return client.s.GetKeyspace(ctx, in)
 }
 
+// GetKeyspaceCharsetReport is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetKeyspaceCharsetReport(ctx context.Context, in *vtctldatapb.GetKeyspaceCharsetReportRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceCharsetReportResponse, error) {
+	return client.s.GetKeyspaceCharsetReport(ctx, in)
+}
+
 // GetKeyspaceRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetKeyspaceRoutingRules(ctx context.Context, in *vtctldatapb.GetKeyspaceRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceRoutingRulesResponse, error) {
 	return client.s.GetKeyspaceRoutingRules(ctx, in)
@@ -311,16 +407,41 @@ func (client *localVtctldClient) GetPermissions(ctx context.Context, in *vtctlda
 	return client.s.GetPermissions(ctx, in)
 }
 
+// GetPlanCacheControl is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetPlanCacheControl(ctx context.Context, in *vtctldatapb.GetPlanCacheControlRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPlanCacheControlResponse, error) {
+	return client.s.GetPlanCacheControl(ctx, in)
+}
+
+// GetQuarantinedTablets is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetQuarantinedTablets(ctx context.Context, in *vtctldatapb.GetQuarantinedTabletsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetQuarantinedTabletsResponse, error) {
+	return client.s.GetQuarantinedTablets(ctx, in)
+}
+
 // GetRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetRoutingRules(ctx context.Context, in *vtctldatapb.GetRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetRoutingRulesResponse, error) {
 	return client.s.GetRoutingRules(ctx, in)
 }
 
+// GetScheduledReparents is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetScheduledReparents(ctx context.Context, in *vtctldatapb.GetScheduledReparentsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetScheduledReparentsResponse, error) {
+	return client.s.GetScheduledReparents(ctx, in)
+}
+
 // GetSchema is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetSchema(ctx context.Context, in *vtctldatapb.GetSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaResponse, error) {
 	return client.s.GetSchema(ctx, in)
 }
 
+// GetSchemaAtPosition is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSchemaAtPosition(ctx context.Context, in *vtctldatapb.GetSchemaAtPositionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaAtPositionResponse, error) {
+	return client.s.GetSchemaAtPosition(ctx, in)
+}
+
+// GetSchemaDrift is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSchemaDrift(ctx context.Context, in *vtctldatapb.GetSchemaDriftRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaDriftResponse, error) {
+	return client.s.GetSchemaDrift(ctx, in)
+}
+
 // GetSchemaMigrations is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetSchemaMigrations(ctx context.Context, in *vtctldatapb.GetSchemaMigrationsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaMigrationsResponse, error) {
 	return client.s.GetSchemaMigrations(ctx, in)
@@ -341,6 +462,16 @@ func (client *localVtctldClient) GetShardRoutingRules(ctx context.Context, in *v
 	return client.s.GetShardRoutingRules(ctx, in)
 }
 
+// GetShardWriteFreezeStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetShardWriteFreezeStatus(ctx context.Context, in *vtctldatapb.GetShardWriteFreezeStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardWriteFreezeStatusResponse, error) {
+	return client.s.GetShardWriteFreezeStatus(ctx, in)
+}
+
+// GetSnapshotKeyspaceStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSnapshotKeyspaceStatus(ctx context.Context, in *vtctldatapb.GetSnapshotKeyspaceStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSnapshotKeyspaceStatusResponse, error) {
+	return client.s.GetSnapshotKeyspaceStatus(ctx, in)
+}
+
 // GetSrvKeyspaceNames is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetSrvKeyspaceNames(ctx context.Context, in *vtctldatapb.GetSrvKeyspaceNamesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvKeyspaceNamesResponse, error) {
 	return client.s.GetSrvKeyspaceNames(ctx, in)
@@ -356,11 +487,21 @@ func (client *localVtctldClient) GetSrvVSchema(ctx context.Context, in *vtctldat
 	return client.s.GetSrvVSchema(ctx, in)
 }
 
+// GetSrvVSchemaDiffs is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSrvVSchemaDiffs(ctx context.Context, in *vtctldatapb.GetSrvVSchemaDiffsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvVSchemaDiffsResponse, error) {
+	return client.s.GetSrvVSchemaDiffs(ctx, in)
+}
+
 // GetSrvVSchemas is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctldatapb.GetSrvVSchemasRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSrvVSchemasResponse, error) {
 	return client.s.GetSrvVSchemas(ctx, in)
 }
 
+// GetStatementACL is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetStatementACL(ctx context.Context, in *vtctldatapb.GetStatementACLRequest, opts ...grpc.CallOption) (*vtctldatapb.GetStatementACLResponse, error) {
+	return client.s.GetStatementACL(ctx, in)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	return client.s.GetTablet(ctx, in)
@@ -371,6 +512,11 @@ func (client *localVtctldClient) GetTablets(ctx context.Context, in *vtctldatapb
 	return client.s.GetTablets(ctx, in)
 }
 
+// GetTenantCatalog is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetTenantCatalog(ctx context.Context, in *vtctldatapb.GetTenantCatalogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTenantCatalogResponse, error) {
+	return client.s.GetTenantCatalog(ctx, in)
+}
+
 // GetThrottlerStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetThrottlerStatus(ctx context.Context, in *vtctldatapb.GetThrottlerStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetThrottlerStatusResponse, error) {
 	return client.s.GetThrottlerStatus(ctx, in)
@@ -386,6 +532,11 @@ func (client *localVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb
 	return client.s.GetVSchema(ctx, in)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	return client.s.GetVSchemaHistory(ctx, in)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	return client.s.GetVersion(ctx, in)
@@ -401,11 +552,21 @@ func (client *localVtctldClient) InitShardPrimary(ctx context.Context, in *vtctl
 	return client.s.InitShardPrimary(ctx, in)
 }
 
+// InvalidateQueryPlans is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) InvalidateQueryPlans(ctx context.Context, in *vtctldatapb.InvalidateQueryPlansRequest, opts ...grpc.CallOption) (*vtctldatapb.InvalidateQueryPlansResponse, error) {
+	return client.s.InvalidateQueryPlans(ctx, in)
+}
+
 // LaunchSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) LaunchSchemaMigration(ctx context.Context, in *vtctldatapb.LaunchSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.LaunchSchemaMigrationResponse, error) {
 	return client.s.LaunchSchemaMigration(ctx, in)
 }
 
+// LookupVindexComplete is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) LookupVindexComplete(ctx context.Context, in *vtctldatapb.LookupVindexCompleteRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCompleteResponse, error) {
+	return client.s.LookupVindexComplete(ctx, in)
+}
+
 // LookupVindexCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) LookupVindexCreate(ctx context.Context, in *vtctldatapb.LookupVindexCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCreateResponse, error) {
 	return client.s.LookupVindexCreate(ctx, in)
@@ -416,6 +577,16 @@ func (client *localVtctldClient) LookupVindexExternalize(ctx context.Context, in
 	return client.s.LookupVindexExternalize(ctx, in)
 }
 
+// LookupVindexRevert is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) LookupVindexRevert(ctx context.Context, in *vtctldatapb.LookupVindexRevertRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexRevertResponse, error) {
+	return client.s.LookupVindexRevert(ctx, in)
+}
+
+// LookupVindexStatus is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) LookupVindexStatus(ctx context.Context, in *vtctldatapb.LookupVindexStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexStatusResponse, error) {
+	return client.s.LookupVindexStatus(ctx, in)
+}
+
 // MaterializeCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) MaterializeCreate(ctx context.Context, in *vtctldatapb.MaterializeCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.MaterializeCreateResponse, error) {
 	return client.s.MaterializeCreate(ctx, in)
@@ -456,11 +627,21 @@ func (client *localVtctldClient) MoveTablesCreate(ctx context.Context, in *vtctl
 	return client.s.MoveTablesCreate(ctx, in)
 }
 
+// PinQueryPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) PinQueryPlan(ctx context.Context, in *vtctldatapb.PinQueryPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.PinQueryPlanResponse, error) {
+	return client.s.PinQueryPlan(ctx, in)
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.PingTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTabletResponse, error) {
 	return client.s.PingTablet(ctx, in)
 }
 
+// PlanReshard is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) PlanReshard(ctx context.Context, in *vtctldatapb.PlanReshardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlanReshardResponse, error) {
+	return client.s.PlanReshard(ctx, in)
+}
+
 // PlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) PlannedReparentShard(ctx context.Context, in *vtctldatapb.PlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlannedReparentShardResponse, error) {
 	return client.s.PlannedReparentShard(ctx, in)
@@ -476,6 +657,21 @@ func (client *localVtctldClient) RebuildVSchemaGraph(ctx context.Context, in *vt
 	return client.s.RebuildVSchemaGraph(ctx, in)
 }
 
+// ReconcileSrvVSchemas is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReconcileSrvVSchemas(ctx context.Context, in *vtctldatapb.ReconcileSrvVSchemasRequest, opts ...grpc.CallOption) (*vtctldatapb.ReconcileSrvVSchemasResponse, error) {
+	return client.s.ReconcileSrvVSchemas(ctx, in)
+}
+
+// ReferenceTablesCheck is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReferenceTablesCheck(ctx context.Context, in *vtctldatapb.ReferenceTablesCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.ReferenceTablesCheckResponse, error) {
+	return client.s.ReferenceTablesCheck(ctx, in)
+}
+
+// ReferenceTablesCreate is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReferenceTablesCreate(ctx context.Context, in *vtctldatapb.ReferenceTablesCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.ReferenceTablesCreateResponse, error) {
+	return client.s.ReferenceTablesCreate(ctx, in)
+}
+
 // RefreshState is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RefreshState(ctx context.Context, in *vtctldatapb.RefreshStateRequest, opts ...grpc.CallOption) (*vtctldatapb.RefreshStateResponse, error) {
 	return client.s.RefreshState(ctx, in)
@@ -582,16 +778,36 @@ func (client *localVtctldClient) RetrySchemaMigration(ctx context.Context, in *v
 	return client.s.RetrySchemaMigration(ctx, in)
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	return client.s.RollbackVSchema(ctx, in)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	return client.s.RunHealthCheck(ctx, in)
 }
 
+// SchedulePlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SchedulePlannedReparentShard(ctx context.Context, in *vtctldatapb.SchedulePlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.SchedulePlannedReparentShardResponse, error) {
+	return client.s.SchedulePlannedReparentShard(ctx, in)
+}
+
+// SetKeyspaceCharset is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetKeyspaceCharset(ctx context.Context, in *vtctldatapb.SetKeyspaceCharsetRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceCharsetResponse, error) {
+	return client.s.SetKeyspaceCharset(ctx, in)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	return client.s.SetKeyspaceDurabilityPolicy(ctx, in)
 }
 
+// SetKeyspaceTwoPC is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetKeyspaceTwoPC(ctx context.Context, in *vtctldatapb.SetKeyspaceTwoPCRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceTwoPCResponse, error) {
+	return client.s.SetKeyspaceTwoPC(ctx, in)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	return client.s.SetShardIsPrimaryServing(ctx, in)
@@ -657,6 +873,16 @@ func (client *localVtctldClient) TabletExternallyReparented(ctx context.Context,
 	return client.s.TabletExternallyReparented(ctx, in)
 }
 
+// UnfreezeShardWrites is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) UnfreezeShardWrites(ctx context.Context, in *vtctldatapb.UnfreezeShardWritesRequest, opts ...grpc.CallOption) (*vtctldatapb.UnfreezeShardWritesResponse, error) {
+	return client.s.UnfreezeShardWrites(ctx, in)
+}
+
+// UnpinQueryPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) UnpinQueryPlan(ctx context.Context, in *vtctldatapb.UnpinQueryPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.UnpinQueryPlanResponse, error) {
+	return client.s.UnpinQueryPlan(ctx, in)
+}
+
 // UpdateCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) UpdateCellInfo(ctx context.Context, in *vtctldatapb.UpdateCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.UpdateCellInfoResponse, error) {
 	return client.s.UpdateCellInfo(ctx, in)
@@ -732,6 +958,57 @@ func (client *localVtctldClient) ValidateVersionShard(ctx context.Context, in *v
 	return client.s.ValidateVersionShard(ctx, in)
 }
 
+type watchTopologyPathStreamAdapter struct {
+	*grpcshim.BidiStream
+	ch chan *vtctldatapb.WatchTopologyPathResponse
+}
+
+func (stream *watchTopologyPathStreamAdapter) Recv() (*vtctldatapb.WatchTopologyPathResponse, error) {
+	select {
+	case <-stream.Context().Done():
+		return nil, stream.Context().Err()
+	case <-stream.Closed():
+		// Stream has been closed for future sends. If there are messages that
+		// have already been sent, receive them until there are no more. After
+		// all sent messages have been received, Recv will return the CloseErr.
+		select {
+		case msg := <-stream.ch:
+			return msg, nil
+		default:
+			return nil, stream.CloseErr()
+		}
+	case err := <-stream.ErrCh:
+		return nil, err
+	case msg := <-stream.ch:
+		return msg, nil
+	}
+}
+
+func (stream *watchTopologyPathStreamAdapter) Send(msg *vtctldatapb.WatchTopologyPathResponse) error {
+	select {
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-stream.Closed():
+		return grpcshim.ErrStreamClosed
+	case stream.ch <- msg:
+		return nil
+	}
+}
+
+// WatchTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WatchTopologyPath(ctx context.Context, in *vtctldatapb.WatchTopologyPathRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_WatchTopologyPathClient, error) {
+	stream := &watchTopologyPathStreamAdapter{
+		BidiStream: grpcshim.NewBidiStream(ctx),
+		ch:         make(chan *vtctldatapb.WatchTopologyPathResponse, 1),
+	}
+	go func() {
+		err := client.s.WatchTopologyPath(in, stream)
+		stream.CloseWithError(err)
+	}()
+
+	return stream, nil
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) WorkflowDelete(ctx context.Context, in *vtctldatapb.WorkflowDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowDeleteResponse, error) {
 	return client.s.WorkflowDelete(ctx, in)
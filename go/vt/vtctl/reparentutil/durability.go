@@ -18,6 +18,7 @@ package reparentutil
 
 import (
 	"fmt"
+	"strings"
 
 	"vitess.io/vitess/go/vt/log"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -78,7 +79,13 @@ type Durabler interface {
 	IsReplicaSemiSync(primary, replica *topodatapb.Tablet) bool
 }
 
+// RegisterDurability registers a durability policy under the name, which
+// keyspaces can then use. Custom policies are compiled in by registering them
+// from the init function of a plugin package.
 func RegisterDurability(name string, newDurablerFunc NewDurabler) {
+	if strings.HasPrefix(name, DurabilityRulesPrefix) {
+		log.Fatalf("durability policy %v cannot start with %v", name, DurabilityRulesPrefix)
+	}
 	if durabilityPolicies[name] != nil {
 		log.Fatalf("durability policy %v already registered", name)
 	}
@@ -87,8 +94,12 @@ func RegisterDurability(name string, newDurablerFunc NewDurabler) {
 
 //=======================================================================
 
-// GetDurabilityPolicy is used to get a new durability policy from the registered policies
+// GetDurabilityPolicy is used to get a new durability policy from the registered policies,
+// or, for the names starting with DurabilityRulesPrefix, from the rules they define.
 func GetDurabilityPolicy(name string) (Durabler, error) {
+	if spec, ok := strings.CutPrefix(name, DurabilityRulesPrefix); ok {
+		return parseDurabilityRules(spec)
+	}
 	newDurabilityCreationFunc, found := durabilityPolicies[name]
 	if !found {
 		return nil, fmt.Errorf("durability policy %v not found", name)
@@ -96,18 +107,32 @@ func GetDurabilityPolicy(name string) (Durabler, error) {
 	return newDurabilityCreationFunc(), nil
 }
 
-// CheckDurabilityPolicyExists is used to check if the durability policy is part of the registered policies
+// CheckDurabilityPolicyExists is used to check if the durability policy is part of the registered policies,
+// or is a valid set of rules starting with DurabilityRulesPrefix
 func CheckDurabilityPolicyExists(name string) bool {
+	if spec, ok := strings.CutPrefix(name, DurabilityRulesPrefix); ok {
+		_, err := parseDurabilityRules(spec)
+		return err == nil
+	}
 	_, found := durabilityPolicies[name]
 	return found
 }
 
+// PromotionRuleTag is the tablet tag that, when set to a valid promotion rule
+// (e.g. "must_not"), overrides the promotion rule from the durability policy.
+const PromotionRuleTag = "promotion_rule"
+
 // PromotionRule returns the promotion rule for the instance.
 func PromotionRule(durability Durabler, tablet *topodatapb.Tablet) promotionrule.CandidatePromotionRule {
 	// Prevent panics.
 	if tablet == nil || tablet.Alias == nil {
 		return promotionrule.MustNot
 	}
+	if tag, ok := tablet.Tags[PromotionRuleTag]; ok {
+		if rule, err := promotionrule.Parse(tag); err == nil {
+			return rule
+		}
+	}
 	return durability.PromotionRule(tablet)
 }
 
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reparentutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+)
+
+// DurabilityRulesPrefix is the prefix of the durability policies which are
+// defined declaratively in the keyspace record instead of being registered,
+// e.g. "rules:ackers=1;different_tag=region". The rules are separated by
+// semicolons:
+//
+//   - ackers=N: the number of semi-sync acks the primary waits for. Defaults to 1.
+//   - rdonly_ack=true: RDONLY tablets send semi-sync acks, in addition to the
+//     PRIMARY and REPLICA ones.
+//   - different_cell=true: only the tablets in another cell than the primary
+//     send semi-sync acks.
+//   - different_tag=KEY: only the tablets whose tag KEY is set to another value
+//     than the one of the primary send semi-sync acks. Only the tags of the
+//     tablet records are compared: the region of the CellInfo is not used, so
+//     e.g. different_tag=region requires a region tag on the tablets, which
+//     can be set with the default tablet tags of their CellInfo.
+//   - acker_tags=KEY:VALUE,...: only the tablets carrying all the tags send
+//     semi-sync acks.
+//   - candidate_tags=KEY:VALUE,...: only the tablets carrying all the tags can
+//     be promoted.
+const DurabilityRulesPrefix = "rules:"
+
+// durabilityRules is a durability policy defined declaratively. It returns
+// NeutralPromoteRule for the Primary and Replica tablets which carry the
+// candidate tags, MustNotPromoteRule for everything else.
+type durabilityRules struct {
+	ackers        int
+	rdonlyAck     bool
+	differentCell bool
+	differentTag  string
+	ackerTags     map[string]string
+	candidateTags map[string]string
+}
+
+// parseDurabilityRules parses the rules of a declarative durability policy,
+// without the DurabilityRulesPrefix.
+func parseDurabilityRules(spec string) (*durabilityRules, error) {
+	d := &durabilityRules{ackers: 1}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid durability rule %q, must be key=value", rule)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "ackers":
+			d.ackers, err = strconv.Atoi(value)
+			if err == nil && d.ackers < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "rdonly_ack":
+			d.rdonlyAck, err = strconv.ParseBool(value)
+		case "different_cell":
+			d.differentCell, err = strconv.ParseBool(value)
+		case "different_tag":
+			d.differentTag = value
+		case "acker_tags":
+			d.ackerTags, err = parseDurabilityTags(value)
+		case "candidate_tags":
+			d.candidateTags, err = parseDurabilityTags(value)
+		default:
+			return nil, fmt.Errorf("unknown durability rule %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid durability rule %q: %v", rule, err)
+		}
+	}
+	return d, nil
+}
+
+// parseDurabilityTags parses a comma-separated list of KEY:VALUE tags.
+func parseDurabilityTags(value string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(tag), ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag %q, must be key:value", tag)
+		}
+		tags[key] = val
+	}
+	return tags, nil
+}
+
+// hasTags returns whether the tablet carries all the tags.
+func hasTags(tablet *topodatapb.Tablet, tags map[string]string) bool {
+	for key, value := range tags {
+		if v, ok := tablet.Tags[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// PromotionRule implements the Durabler interface
+func (d *durabilityRules) PromotionRule(tablet *topodatapb.Tablet) promotionrule.CandidatePromotionRule {
+	switch tablet.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+		if hasTags(tablet, d.candidateTags) {
+			return promotionrule.Neutral
+		}
+	}
+	return promotionrule.MustNot
+}
+
+// SemiSyncAckers implements the Durabler interface
+func (d *durabilityRules) SemiSyncAckers(tablet *topodatapb.Tablet) int {
+	return d.ackers
+}
+
+// IsReplicaSemiSync implements the Durabler interface
+func (d *durabilityRules) IsReplicaSemiSync(primary, replica *topodatapb.Tablet) bool {
+	if d.ackers == 0 {
+		return false
+	}
+	switch replica.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+	case topodatapb.TabletType_RDONLY:
+		if !d.rdonlyAck {
+			return false
+		}
+	default:
+		return false
+	}
+	if d.differentCell && primary.Alias.Cell == replica.Alias.Cell {
+		return false
+	}
+	if d.differentTag != "" {
+		value, ok := replica.Tags[d.differentTag]
+		if !ok || value == primary.Tags[d.differentTag] {
+			return false
+		}
+	}
+	return hasTags(replica, d.ackerTags)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reparentutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+)
+
+func newRulesTablet(cell string, tabletType topodatapb.TabletType, tags map[string]string) *topodatapb.Tablet {
+	return &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: cell,
+			Uid:  100,
+		},
+		Type: tabletType,
+		Tags: tags,
+	}
+}
+
+func TestParseDurabilityRules(t *testing.T) {
+	testcases := []struct {
+		name     string
+		policy   string
+		expected *durabilityRules
+		errorMsg string
+	}{
+		{
+			name:     "defaults",
+			policy:   "rules:",
+			expected: &durabilityRules{ackers: 1},
+		},
+		{
+			name:   "all rules",
+			policy: "rules:ackers=2; rdonly_ack=true;different_cell=true;different_tag=region;acker_tags=disk:ssd,rack:a;candidate_tags=candidate:true",
+			expected: &durabilityRules{
+				ackers:        2,
+				rdonlyAck:     true,
+				differentCell: true,
+				differentTag:  "region",
+				ackerTags:     map[string]string{"disk": "ssd", "rack": "a"},
+				candidateTags: map[string]string{"candidate": "true"},
+			},
+		},
+		{
+			name:     "unknown rule",
+			policy:   "rules:quorum=2",
+			errorMsg: `unknown durability rule "quorum"`,
+		},
+		{
+			name:     "missing value",
+			policy:   "rules:ackers",
+			errorMsg: `invalid durability rule "ackers", must be key=value`,
+		},
+		{
+			name:     "negative ackers",
+			policy:   "rules:ackers=-1",
+			errorMsg: `invalid durability rule "ackers=-1": must not be negative`,
+		},
+		{
+			name:     "invalid tag",
+			policy:   "rules:acker_tags=disk",
+			errorMsg: `invalid durability rule "acker_tags=disk": invalid tag "disk", must be key:value`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			durability, err := GetDurabilityPolicy(tt.policy)
+			if tt.errorMsg != "" {
+				assert.EqualError(t, err, tt.errorMsg)
+				assert.False(t, CheckDurabilityPolicyExists(tt.policy))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, durability)
+			assert.True(t, CheckDurabilityPolicyExists(tt.policy))
+		})
+	}
+}
+
+func TestDurabilityRules(t *testing.T) {
+	durability, err := GetDurabilityPolicy("rules:ackers=1;different_tag=region;candidate_tags=candidate:true")
+	require.NoError(t, err)
+
+	primary := newRulesTablet("cell1", topodatapb.TabletType_PRIMARY, map[string]string{"region": "east", "candidate": "true"})
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, primary))
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, newRulesTablet("cell1", topodatapb.TabletType_REPLICA, nil)))
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, newRulesTablet("cell1", topodatapb.TabletType_RDONLY, map[string]string{"candidate": "true"})))
+	assert.Equal(t, 1, SemiSyncAckers(durability, primary))
+
+	testcases := []struct {
+		name     string
+		replica  *topodatapb.Tablet
+		expected bool
+	}{
+		{
+			name:     "same region",
+			replica:  newRulesTablet("cell2", topodatapb.TabletType_REPLICA, map[string]string{"region": "east"}),
+			expected: false,
+		},
+		{
+			name:     "no region",
+			replica:  newRulesTablet("cell2", topodatapb.TabletType_REPLICA, nil),
+			expected: false,
+		},
+		{
+			name:     "different region",
+			replica:  newRulesTablet("cell1", topodatapb.TabletType_REPLICA, map[string]string{"region": "west"}),
+			expected: true,
+		},
+		{
+			name:     "rdonly in a different region",
+			replica:  newRulesTablet("cell2", topodatapb.TabletType_RDONLY, map[string]string{"region": "west"}),
+			expected: false,
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsReplicaSemiSync(durability, primary, tt.replica))
+		})
+	}
+}
+
+func TestDurabilityRulesAckerTags(t *testing.T) {
+	durability, err := GetDurabilityPolicy("rules:ackers=2;different_cell=true;rdonly_ack=true;acker_tags=ack:true")
+	require.NoError(t, err)
+
+	primary := newRulesTablet("cell1", topodatapb.TabletType_PRIMARY, nil)
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, primary))
+	assert.Equal(t, 2, SemiSyncAckers(durability, primary))
+	assert.True(t, IsReplicaSemiSync(durability, primary, newRulesTablet("cell2", topodatapb.TabletType_RDONLY, map[string]string{"ack": "true"})))
+	assert.False(t, IsReplicaSemiSync(durability, primary, newRulesTablet("cell1", topodatapb.TabletType_REPLICA, map[string]string{"ack": "true"})))
+	assert.False(t, IsReplicaSemiSync(durability, primary, newRulesTablet("cell2", topodatapb.TabletType_REPLICA, map[string]string{"ack": "false"})))
+	assert.False(t, IsReplicaSemiSync(durability, primary, newRulesTablet("cell2", topodatapb.TabletType_BACKUP, map[string]string{"ack": "true"})))
+
+	// No tablet sends semi-sync acks without ackers.
+	durability, err = GetDurabilityPolicy("rules:ackers=0")
+	require.NoError(t, err)
+	assert.False(t, IsReplicaSemiSync(durability, primary, newRulesTablet("cell2", topodatapb.TabletType_REPLICA, nil)))
+}
@@ -72,6 +72,30 @@ func TestDurabilityNone(t *testing.T) {
 	assert.Equal(t, false, IsReplicaSemiSync(durability, nil, nil))
 }
 
+func TestPromotionRuleTag(t *testing.T) {
+	durability, err := GetDurabilityPolicy("none")
+	require.NoError(t, err)
+
+	tablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  100,
+		},
+		Type: topodatapb.TabletType_REPLICA,
+	}
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, tablet))
+
+	tablet.Tags = map[string]string{PromotionRuleTag: "must_not"}
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, tablet))
+
+	tablet.Tags = map[string]string{PromotionRuleTag: "prefer"}
+	assert.Equal(t, promotionrule.Prefer, PromotionRule(durability, tablet))
+
+	// Unknown values fall back to the durability policy.
+	tablet.Tags = map[string]string{PromotionRuleTag: "bogus"}
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, tablet))
+}
+
 func TestDurabilitySemiSync(t *testing.T) {
 	testcases := []struct {
 		durabilityPolicy string
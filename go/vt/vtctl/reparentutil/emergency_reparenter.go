@@ -60,6 +60,11 @@ type EmergencyReparentOptions struct {
 	WaitAllTablets            bool
 	WaitReplicasTimeout       time.Duration
 	PreventCrossCellPromotion bool
+	// QuarantineErrantReplicas changes the type of the replicas with errant
+	// GTIDs to DRAINED once the new primary is promoted, and tags them with
+	// their errant GTIDs, so that they are not promoted by a later reparent
+	// until the quarantine is cleared.
+	QuarantineErrantReplicas bool
 
 	// Private options managed internally. We use value passing to avoid leaking
 	// these details back out.
@@ -72,6 +77,17 @@ var ersCounter = stats.NewCountersWithMultiLabels("EmergencyReparentCounts", "Nu
 	[]string{"Keyspace", "Shard", "Result"},
 )
 
+// counters for the tablets with errant GTIDs Emergency Reparent Shard excludes
+// from the candidates, and for the quarantines of these tablets
+var (
+	ersErrantTabletsCounter = stats.NewCountersWithMultiLabels("EmergencyReparentErrantTablets", "Number of tablets Emergency Reparent Shard found to have errant GTIDs",
+		[]string{"Keyspace", "Shard"},
+	)
+	ersQuarantineCounter = stats.NewCountersWithMultiLabels("EmergencyReparentQuarantines", "Number of tablets with errant GTIDs Emergency Reparent Shard quarantined",
+		[]string{"Keyspace", "Shard", "Result"},
+	)
+)
+
 // NewEmergencyReparenter returns a new EmergencyReparenter object, ready to
 // perform EmergencyReparentShard operations using the given topo.Server,
 // TabletManagerClient, and logger.
@@ -152,6 +168,7 @@ func (erp *EmergencyReparenter) reparentShardLocked(ctx context.Context, ev *eve
 		prevPrimary                *topodatapb.Tablet
 		tabletMap                  map[string]*topo.TabletInfo
 		validCandidates            map[string]replication.Position
+		errantGTIDs                map[string]replication.Mysql56GTIDSet
 		intermediateSource         *topodatapb.Tablet
 		validCandidateTablets      []*topodatapb.Tablet
 		validReplacementCandidates []*topodatapb.Tablet
@@ -207,10 +224,14 @@ func (erp *EmergencyReparenter) reparentShardLocked(ctx context.Context, ev *eve
 
 	// find the valid candidates for becoming the primary
 	// this is where we check for errant GTIDs and remove the tablets that have them from consideration
-	validCandidates, err = FindValidEmergencyReparentCandidates(stoppedReplicationSnapshot.statusMap, stoppedReplicationSnapshot.primaryStatusMap)
+	validCandidates, errantGTIDs, err = FindValidEmergencyReparentCandidates(stoppedReplicationSnapshot.statusMap, stoppedReplicationSnapshot.primaryStatusMap)
 	if err != nil {
 		return err
 	}
+	for alias, errant := range errantGTIDs {
+		erp.logger.Errorf("tablet %v has errant GTIDs %v, it is not a candidate for the reparent", alias, errant)
+		ersErrantTabletsCounter.Add([]string{keyspace, shard}, 1)
+	}
 	// Restrict the valid candidates list. We remove any tablet which is of the type DRAINED, RESTORE or BACKUP.
 	validCandidates, err = restrictValidCandidates(validCandidates, tabletMap)
 	if err != nil {
@@ -303,6 +324,60 @@ func (erp *EmergencyReparenter) reparentShardLocked(ctx context.Context, ev *eve
 		return err
 	}
 	ev.NewPrimary = newPrimary.CloneVT()
+
+	if opts.QuarantineErrantReplicas && len(errantGTIDs) > 0 {
+		event.DispatchUpdate(ev, "quarantining the tablets with errant GTIDs")
+		erp.quarantineErrantReplicas(ctx, keyspace, shard, newPrimary, errantGTIDs, tabletMap, opts)
+	}
+	return err
+}
+
+// quarantineErrantReplicas changes the type of the tablets with errant GTIDs
+// to DRAINED, and tags them with their errant GTIDs. It is done once the new
+// primary is promoted, so the failures are only logged: the shard has a
+// primary, and the tablets are already excluded from this reparent.
+func (erp *EmergencyReparenter) quarantineErrantReplicas(
+	ctx context.Context,
+	keyspace string,
+	shard string,
+	newPrimary *topodatapb.Tablet,
+	errantGTIDs map[string]replication.Mysql56GTIDSet,
+	tabletMap map[string]*topo.TabletInfo,
+	opts EmergencyReparentOptions,
+) {
+	for alias, errant := range errantGTIDs {
+		tabletInfo, ok := tabletMap[alias]
+		if !ok {
+			continue
+		}
+
+		drained := tabletInfo.Tablet.CloneVT()
+		drained.Type = topodatapb.TabletType_DRAINED
+		semiSync := IsReplicaSemiSync(opts.durability, newPrimary, drained)
+		if err := erp.quarantineTablet(ctx, tabletInfo.Tablet, errant, semiSync); err != nil {
+			ersQuarantineCounter.Add([]string{keyspace, shard, failureResult}, 1)
+			erp.logger.Errorf("failed to quarantine tablet %v with errant GTIDs %v: %v", alias, errant, err)
+			continue
+		}
+		ersQuarantineCounter.Add([]string{keyspace, shard, successResult}, 1)
+		erp.logger.Warningf("quarantined tablet %v with errant GTIDs %v, clear its quarantine once they are removed", alias, errant)
+	}
+}
+
+func (erp *EmergencyReparenter) quarantineTablet(ctx context.Context, tablet *topodatapb.Tablet, errant replication.Mysql56GTIDSet, semiSync bool) error {
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	if err := erp.tmc.ChangeType(ctx, tablet, topodatapb.TabletType_DRAINED, semiSync); err != nil {
+		return err
+	}
+	_, err := erp.ts.UpdateTabletFields(ctx, tablet.Alias, func(tablet *topodatapb.Tablet) error {
+		if tablet.Tags == nil {
+			tablet.Tags = map[string]string{}
+		}
+		tablet.Tags[topo.ErrantGTIDsTag] = errant.String()
+		return nil
+	})
 	return err
 }
 
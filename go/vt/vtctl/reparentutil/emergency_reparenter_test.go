@@ -2725,6 +2725,124 @@ func TestEmergencyReparenterStats(t *testing.T) {
 	require.EqualValues(t, map[string]int64{"All": 2, "EmergencyReparentShard": 2}, reparentShardOpTimings.Counts())
 }
 
+func TestEmergencyReparenterQuarantine(t *testing.T) {
+	ersErrantTabletsCounter.ResetAll()
+	ersQuarantineCounter.ResetAll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := logutil.NewMemoryLogger()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	tmc := &testutil.TabletManagerClient{
+		TopoServer: ts,
+		PopulateReparentJournalResults: map[string]error{
+			"zone1-0000000102": nil,
+		},
+		PromoteReplicaResults: map[string]struct {
+			Result string
+			Error  error
+		}{
+			"zone1-0000000102": {
+				Result: "ok",
+				Error:  nil,
+			},
+		},
+		SetReplicationSourceResults: map[string]error{
+			"zone1-0000000100": nil,
+			"zone1-0000000101": nil,
+		},
+		StopReplicationAndGetStatusResults: map[string]struct {
+			StopStatus *replicationdatapb.StopReplicationStatus
+			Error      error
+		}{
+			"zone1-0000000100": {
+				StopStatus: &replicationdatapb.StopReplicationStatus{
+					Before: &replicationdatapb.Status{IoState: int32(replication.ReplicationStateRunning), SqlState: int32(replication.ReplicationStateRunning)},
+					After: &replicationdatapb.Status{
+						SourceUuid:       "3E11FA47-71CA-11E1-9E33-C80AA9429562",
+						RelayLogPosition: "MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-21",
+					},
+				},
+			},
+			"zone1-0000000101": {
+				StopStatus: &replicationdatapb.StopReplicationStatus{
+					Before: &replicationdatapb.Status{IoState: int32(replication.ReplicationStateRunning), SqlState: int32(replication.ReplicationStateRunning)},
+					After: &replicationdatapb.Status{
+						SourceUuid:       "3E11FA47-71CA-11E1-9E33-C80AA9429562",
+						RelayLogPosition: "MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-21,AAAAAAAA-71CA-11E1-9E33-C80AA9429562:1",
+					},
+				},
+			},
+			"zone1-0000000102": {
+				StopStatus: &replicationdatapb.StopReplicationStatus{
+					Before: &replicationdatapb.Status{IoState: int32(replication.ReplicationStateRunning), SqlState: int32(replication.ReplicationStateRunning)},
+					After: &replicationdatapb.Status{
+						SourceUuid:       "3E11FA47-71CA-11E1-9E33-C80AA9429562",
+						RelayLogPosition: "MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-26",
+					},
+				},
+			},
+		},
+		WaitForPositionResults: map[string]map[string]error{
+			"zone1-0000000100": {
+				"MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-21": nil,
+			},
+			"zone1-0000000102": {
+				"MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-26": nil,
+			},
+		},
+	}
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Type:     topodatapb.TabletType_PRIMARY,
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  101,
+			},
+			Type:     topodatapb.TabletType_REPLICA,
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Hostname: "errant GTIDs",
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  102,
+			},
+			Type:     topodatapb.TabletType_REPLICA,
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Hostname: "most up-to-date position, wins election",
+		},
+	}
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, tablets...)
+
+	erp := NewEmergencyReparenter(ts, tmc, logger)
+	ev, err := erp.ReparentShard(ctx, "testkeyspace", "-", EmergencyReparentOptions{QuarantineErrantReplicas: true})
+	require.NoError(t, err)
+	assert.Equal(t, "zone1-0000000102", topoproto.TabletAliasString(ev.NewPrimary.Alias))
+
+	errant, err := ts.GetTablet(ctx, tablets[1].Alias)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_DRAINED, errant.Type)
+	assert.Equal(t, "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1", errant.Tags[topo.ErrantGTIDsTag])
+	assert.True(t, topo.IsQuarantined(errant.Tablet))
+
+	assert.EqualValues(t, map[string]int64{"testkeyspace.-": 1}, ersErrantTabletsCounter.Counts())
+	assert.EqualValues(t, map[string]int64{"testkeyspace.-.success": 1}, ersQuarantineCounter.Counts())
+}
+
 func TestEmergencyReparenter_findMostAdvanced(t *testing.T) {
 	sid1 := replication.SID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
 	mysqlGTID1 := replication.Mysql56GTID{
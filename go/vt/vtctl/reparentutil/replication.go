@@ -41,13 +41,16 @@ import (
 
 // FindValidEmergencyReparentCandidates will find candidates for an emergency
 // reparent, and, if successful, return a mapping of those tablet aliases (as
-// raw strings) to their replication positions for later comparison.
+// raw strings) to their replication positions for later comparison. It also
+// returns the errant GTIDs of the tablets which are not candidates because
+// they have some.
 func FindValidEmergencyReparentCandidates(
 	statusMap map[string]*replicationdatapb.StopReplicationStatus,
 	primaryStatusMap map[string]*replicationdatapb.PrimaryStatus,
-) (map[string]replication.Position, error) {
+) (map[string]replication.Position, map[string]replication.Mysql56GTIDSet, error) {
 	replicationStatusMap := make(map[string]*replication.ReplicationStatus, len(statusMap))
 	positionMap := make(map[string]replication.Position)
+	errantGTIDsMap := make(map[string]replication.Mysql56GTIDSet)
 
 	// Build out replication status list from proto types.
 	for alias, statuspb := range statusMap {
@@ -79,11 +82,11 @@ func FindValidEmergencyReparentCandidates(
 	}
 
 	if isGTIDBased && emptyRelayPosErrorRecorder.HasErrors() {
-		return nil, emptyRelayPosErrorRecorder.Error()
+		return nil, nil, emptyRelayPosErrorRecorder.Error()
 	}
 
 	if isGTIDBased && isNonGTIDBased {
-		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "encountered mix of GTID-based and non GTID-based relay logs")
+		return nil, nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "encountered mix of GTID-based and non GTID-based relay logs")
 	}
 
 	// Create relevant position list of errant GTID-based positions for later
@@ -101,7 +104,7 @@ func FindValidEmergencyReparentCandidates(
 		// in the earlier loop, but let's be doubly sure.
 		relayLogGTIDSet, ok := status.RelayLogPosition.GTIDSet.(replication.Mysql56GTIDSet)
 		if !ok {
-			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "we got a filled-in relay log position, but it's not of type Mysql56GTIDSet, even though we've determined we need to use GTID based assesment")
+			return nil, nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "we got a filled-in relay log position, but it's not of type Mysql56GTIDSet, even though we've determined we need to use GTID based assesment")
 		}
 
 		// We need to remove this alias's status from the list, otherwise the
@@ -119,11 +122,12 @@ func FindValidEmergencyReparentCandidates(
 		case err != nil:
 			// Could not look up GTIDs to determine if we have any. It's not
 			// safe to continue.
-			return nil, err
+			return nil, nil, err
 		case len(errantGTIDs) != 0:
 			// This tablet has errant GTIDs. It's not a valid candidate for
 			// reparent, so don't insert it into the final mapping.
 			log.Errorf("skipping %v because we detected errant GTIDs - %v", alias, errantGTIDs)
+			errantGTIDsMap[alias] = errantGTIDs
 			continue
 		}
 
@@ -134,13 +138,13 @@ func FindValidEmergencyReparentCandidates(
 	for alias, primaryStatus := range primaryStatusMap {
 		executedPosition, err := replication.DecodePosition(primaryStatus.Position)
 		if err != nil {
-			return nil, vterrors.Wrapf(err, "could not decode a primary status executed position for tablet %v: %v", alias, err)
+			return nil, nil, vterrors.Wrapf(err, "could not decode a primary status executed position for tablet %v: %v", alias, err)
 		}
 
 		positionMap[alias] = executedPosition
 	}
 
-	return positionMap, nil
+	return positionMap, errantGTIDsMap, nil
 }
 
 // ReplicaWasRunning returns true if a StopReplicationStatus indicates that the
@@ -66,6 +66,8 @@ func TestFindValidEmergencyReparentCandidates(t *testing.T) {
 		// the correctness of the behavior of this functional unit.
 		expected  []string
 		shouldErr bool
+		// expectedErrant maps the tablets with errant GTIDs to them.
+		expectedErrant map[string]string
 	}{
 		{
 			name: "success",
@@ -183,6 +185,9 @@ func TestFindValidEmergencyReparentCandidates(t *testing.T) {
 			},
 			expected:  []string{"r1", "p1"},
 			shouldErr: false,
+			expectedErrant: map[string]string{
+				"errant": "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1",
+			},
 		},
 		{
 			name: "bad primary position fails the call",
@@ -208,7 +213,7 @@ func TestFindValidEmergencyReparentCandidates(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			actual, err := FindValidEmergencyReparentCandidates(tt.statusMap, tt.primaryStatusMap)
+			actual, errant, err := FindValidEmergencyReparentCandidates(tt.statusMap, tt.primaryStatusMap)
 			if tt.shouldErr {
 				assert.Error(t, err)
 				return
@@ -221,6 +226,15 @@ func TestFindValidEmergencyReparentCandidates(t *testing.T) {
 				keys = append(keys, key)
 			}
 			assert.ElementsMatch(t, tt.expected, keys)
+
+			errantGTIDs := make(map[string]string, len(errant))
+			for alias, gtids := range errant {
+				errantGTIDs[alias] = gtids.String()
+			}
+			if tt.expectedErrant == nil {
+				tt.expectedErrant = map[string]string{}
+			}
+			assert.Equal(t, tt.expectedErrant, errantGTIDs)
 		})
 	}
 }
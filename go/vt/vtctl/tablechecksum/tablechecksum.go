@@ -0,0 +1,390 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tablechecksum computes the checksums of the rows of a table in
+// chunks of primary key ranges, so that two copies of the table, sharded the
+// same way or not, in Vitess or in an external MySQL, can be compared chunk by
+// chunk.
+//
+// The checksum of a chunk is the sum of the CRC32 of its rows. As it does not
+// depend on the order of the rows, the checksums of the chunk computed on each
+// shard of a copy of the table add up to the checksum of the chunk.
+package tablechecksum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Executor runs a query against a copy of the table, or against one of its
+// shards. The queries return at most one row.
+type Executor func(ctx context.Context, query string) (*sqltypes.Result, error)
+
+// Table is the table to checksum.
+type Table struct {
+	Name string
+	// Columns are the columns checksummed, in the order their values are
+	// concatenated in.
+	Columns           []string
+	PrimaryKeyColumns []string
+}
+
+// Boundary holds the values of the primary key columns of the first row of a
+// chunk.
+type Boundary []sqltypes.Value
+
+// Chunk is the checksum of the rows of a chunk.
+type Chunk struct {
+	Rows     int64
+	Checksum uint64
+}
+
+// Mismatch is a chunk whose checksums differ between two copies of a table.
+type Mismatch struct {
+	// Chunk is the index of the chunk. Its rows are those from the boundary
+	// Chunk-1, if any, and before the boundary Chunk, if any.
+	Chunk  int
+	Source Chunk
+	Target Chunk
+}
+
+// Pacer limits the rate at which rows are read from a copy of the table, or
+// from one of its shards. A nil Pacer does not limit it.
+type Pacer struct {
+	rowsPerSecond int64
+	start         time.Time
+	rows          int64
+}
+
+// NewPacer returns a Pacer limiting the reads to rowsPerSecond rows per
+// second, or nil if rowsPerSecond is not positive.
+func NewPacer(rowsPerSecond int64) *Pacer {
+	if rowsPerSecond <= 0 {
+		return nil
+	}
+	return &Pacer{rowsPerSecond: rowsPerSecond}
+}
+
+// Wait accounts for rows read, and waits until reading them complies with the
+// rate limit.
+func (p *Pacer) Wait(ctx context.Context, rows int64) error {
+	if p == nil {
+		return nil
+	}
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	p.rows += rows
+	due := p.start.Add(time.Duration(float64(p.rows) / float64(p.rowsPerSecond) * float64(time.Second)))
+	wait := time.Until(due)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Boundaries splits the rows of a table, spread across the shards queried by
+// executors, into chunks of about chunkSize rows, and returns the boundaries
+// of the chunks in ascending order. The primary key of each shard is read at
+// up to maxRowsPerSecond rows per second.
+func Boundaries(ctx context.Context, env *collations.Environment, executors []Executor, table Table, chunkSize int64, maxRowsPerSecond int64) ([]Boundary, error) {
+	if chunkSize <= 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "chunk size must be positive, got %d", chunkSize)
+	}
+
+	// Each shard holds about 1/len(executors) of the rows of a range of
+	// primary keys, so the boundaries of the chunks of chunkSize rows of all
+	// the shards split the table into chunks of about chunkSize rows.
+	var (
+		m          sync.Mutex
+		wg         sync.WaitGroup
+		rec        concurrency.AllErrorRecorder
+		boundaries []Boundary
+		colls      []collations.ID
+	)
+	for _, exec := range executors {
+		wg.Add(1)
+		go func(exec Executor) {
+			defer wg.Done()
+
+			shardBoundaries, shardColls, err := shardBoundaries(ctx, exec, table, chunkSize, NewPacer(maxRowsPerSecond))
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			boundaries = append(boundaries, shardBoundaries...)
+			if shardColls != nil {
+				colls = shardColls
+			}
+		}(exec)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+	if len(executors) == 1 {
+		return boundaries, nil
+	}
+
+	var err error
+	sort.SliceStable(boundaries, func(i, j int) bool {
+		cmp, cmpErr := compareBoundaries(env, colls, boundaries[i], boundaries[j])
+		if cmpErr != nil && err == nil {
+			err = cmpErr
+		}
+		return cmp < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := boundaries[:0]
+	for _, boundary := range boundaries {
+		if len(res) > 0 {
+			cmp, err := compareBoundaries(env, colls, res[len(res)-1], boundary)
+			if err != nil {
+				return nil, err
+			}
+			if cmp == 0 {
+				continue
+			}
+		}
+		res = append(res, boundary)
+	}
+	return res, nil
+}
+
+// shardBoundaries returns the boundaries of the chunks of chunkSize rows of a
+// shard, and the collations of the primary key columns.
+func shardBoundaries(ctx context.Context, exec Executor, table Table, chunkSize int64, pacer *Pacer) ([]Boundary, []collations.ID, error) {
+	var (
+		boundaries []Boundary
+		colls      []collations.ID
+	)
+	for {
+		var last Boundary
+		if len(boundaries) > 0 {
+			last = boundaries[len(boundaries)-1]
+		}
+		query := boundaryQuery(table, last, chunkSize)
+		qr, err := exec(ctx, query)
+		if err != nil {
+			return nil, nil, vterrors.Wrapf(err, "reading the chunk boundaries of table %s", table.Name)
+		}
+		if len(qr.Rows) == 0 {
+			return boundaries, colls, nil
+		}
+		if colls == nil {
+			for _, field := range qr.Fields {
+				colls = append(colls, collations.ID(field.Charset))
+			}
+		}
+		boundaries = append(boundaries, Boundary(qr.Rows[0]))
+		if err := pacer.Wait(ctx, chunkSize); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func compareBoundaries(env *collations.Environment, colls []collations.ID, a, b Boundary) (int, error) {
+	for i := range min(len(a), len(b)) {
+		var coll collations.ID = collations.CollationBinaryID
+		if i < len(colls) {
+			coll = colls[i]
+		}
+		cmp, err := evalengine.NullsafeCompare(a[i], b[i], env, coll, nil)
+		if err != nil || cmp != 0 {
+			return cmp, err
+		}
+	}
+	return len(a) - len(b), nil
+}
+
+// boundaryQuery returns the query reading the primary key of the first row of
+// the chunk of chunkSize rows which follows the chunk starting at last.
+func boundaryQuery(table Table, last Boundary, chunkSize int64) string {
+	pk := escapeIDs(table.PrimaryKeyColumns)
+	var b strings.Builder
+	fmt.Fprintf(&b, "select %s from %s", pk, sqlescape.EscapeID(table.Name))
+	if last != nil {
+		fmt.Fprintf(&b, " where %s >= %s", tuple(pk, len(table.PrimaryKeyColumns)), encodeBoundary(last))
+	}
+	fmt.Fprintf(&b, " order by %s limit %d, 1", pk, chunkSize)
+	return b.String()
+}
+
+// Checksum returns the checksums of the chunks of a table split at the
+// boundaries, summed across the shards queried by executors. Each shard is
+// read at up to maxRowsPerSecond rows per second.
+func Checksum(ctx context.Context, executors []Executor, table Table, boundaries []Boundary, maxRowsPerSecond int64) ([]Chunk, error) {
+	var (
+		m      sync.Mutex
+		wg     sync.WaitGroup
+		rec    concurrency.AllErrorRecorder
+		chunks = make([]Chunk, len(boundaries)+1)
+	)
+	for _, exec := range executors {
+		wg.Add(1)
+		go func(exec Executor) {
+			defer wg.Done()
+
+			pacer := NewPacer(maxRowsPerSecond)
+			for i := range chunks {
+				chunk, err := checksumChunk(ctx, exec, table, boundaries, i)
+				if err != nil {
+					rec.RecordError(err)
+					return
+				}
+				m.Lock()
+				chunks[i].Rows += chunk.Rows
+				chunks[i].Checksum += chunk.Checksum
+				m.Unlock()
+				if err := pacer.Wait(ctx, chunk.Rows); err != nil {
+					rec.RecordError(err)
+					return
+				}
+			}
+		}(exec)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+	return chunks, nil
+}
+
+func checksumChunk(ctx context.Context, exec Executor, table Table, boundaries []Boundary, i int) (Chunk, error) {
+	query := checksumQuery(table, boundaries, i)
+	qr, err := exec(ctx, query)
+	if err != nil {
+		return Chunk{}, vterrors.Wrapf(err, "checksumming chunk %d of table %s", i, table.Name)
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 2 {
+		return Chunk{}, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result of %s: %v", query, qr.Rows)
+	}
+	rows, err := qr.Rows[0][0].ToInt64()
+	if err != nil {
+		return Chunk{}, vterrors.Wrapf(err, "parsing the row count of chunk %d of table %s", i, table.Name)
+	}
+	chunk := Chunk{Rows: rows}
+	// The sum is NULL if the chunk is empty.
+	if sum := qr.Rows[0][1]; !sum.IsNull() {
+		if chunk.Checksum, err = strconv.ParseUint(sum.ToString(), 10, 64); err != nil {
+			return Chunk{}, vterrors.Wrapf(err, "parsing the checksum of chunk %d of table %s", i, table.Name)
+		}
+	}
+	return chunk, nil
+}
+
+// checksumQuery returns the query computing the row count and the checksum of
+// chunk i. The values of a row are separated by '#', and followed by the
+// flags of the NULL values, which concat_ws skips.
+func checksumQuery(table Table, boundaries []Boundary, i int) string {
+	columns := make([]string, 0, len(table.Columns))
+	nulls := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		column = sqlescape.EscapeID(column)
+		columns = append(columns, column)
+		nulls = append(nulls, fmt.Sprintf("isnull(%s)", column))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "select count(*), sum(crc32(concat_ws('#', %s, concat(%s)))) from %s",
+		strings.Join(columns, ", "), strings.Join(nulls, ", "), sqlescape.EscapeID(table.Name))
+
+	pk := tuple(escapeIDs(table.PrimaryKeyColumns), len(table.PrimaryKeyColumns))
+	var conditions []string
+	if i > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", pk, encodeBoundary(boundaries[i-1])))
+	}
+	if i < len(boundaries) {
+		conditions = append(conditions, fmt.Sprintf("%s < %s", pk, encodeBoundary(boundaries[i])))
+	}
+	if len(conditions) > 0 {
+		fmt.Fprintf(&b, " where %s", strings.Join(conditions, " and "))
+	}
+	return b.String()
+}
+
+// Compare returns the chunks whose checksums differ between the source and
+// the target copies of a table, split at the same boundaries.
+func Compare(source, target []Chunk) ([]Mismatch, error) {
+	if len(source) != len(target) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the source has %d chunks but the target has %d", len(source), len(target))
+	}
+	var mismatches []Mismatch
+	for i := range source {
+		if source[i] != target[i] {
+			mismatches = append(mismatches, Mismatch{Chunk: i, Source: source[i], Target: target[i]})
+		}
+	}
+	return mismatches, nil
+}
+
+func escapeIDs(ids []string) string {
+	escaped := make([]string, 0, len(ids))
+	for _, id := range ids {
+		escaped = append(escaped, sqlescape.EscapeID(id))
+	}
+	return strings.Join(escaped, ", ")
+}
+
+func tuple(expr string, n int) string {
+	if n == 1 {
+		return expr
+	}
+	return "(" + expr + ")"
+}
+
+func encodeBoundary(boundary Boundary) string {
+	var b strings.Builder
+	if len(boundary) > 1 {
+		b.WriteByte('(')
+	}
+	for i, value := range boundary {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value.EncodeSQLStringBuilder(&b)
+	}
+	if len(boundary) > 1 {
+		b.WriteByte(')')
+	}
+	return b.String()
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tablechecksum
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+var testTable = Table{
+	Name:              "t1",
+	Columns:           []string{"id", "name"},
+	PrimaryKeyColumns: []string{"id"},
+}
+
+// fakeExecutor returns the results of the queries, and an empty result for the
+// other queries.
+func fakeExecutor(results map[string]*sqltypes.Result) Executor {
+	return func(ctx context.Context, query string) (*sqltypes.Result, error) {
+		if qr, ok := results[query]; ok {
+			return qr, nil
+		}
+		return &sqltypes.Result{}, nil
+	}
+}
+
+func ids(values ...int64) []Boundary {
+	boundaries := make([]Boundary, 0, len(values))
+	for _, value := range values {
+		boundaries = append(boundaries, Boundary{sqltypes.NewInt64(value)})
+	}
+	return boundaries
+}
+
+func TestBoundaries(t *testing.T) {
+	ctx := context.Background()
+	id := func(v string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), v)
+	}
+	shard1 := fakeExecutor(map[string]*sqltypes.Result{
+		"select `id` from `t1` order by `id` limit 2, 1":                 id("3"),
+		"select `id` from `t1` where `id` >= 3 order by `id` limit 2, 1": id("10"),
+	})
+	shard2 := fakeExecutor(map[string]*sqltypes.Result{
+		"select `id` from `t1` order by `id` limit 2, 1":                  id("10"),
+		"select `id` from `t1` where `id` >= 10 order by `id` limit 2, 1": id("20"),
+	})
+
+	boundaries, err := Boundaries(ctx, collations.MySQL8(), []Executor{shard1}, testTable, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, ids(3, 10), boundaries)
+
+	// The boundaries of the shards are merged, without duplicates.
+	boundaries, err = Boundaries(ctx, collations.MySQL8(), []Executor{shard2, shard1}, testTable, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, ids(3, 10, 20), boundaries)
+
+	_, err = Boundaries(ctx, collations.MySQL8(), []Executor{shard1}, testTable, 0, 0)
+	assert.ErrorContains(t, err, "chunk size must be positive")
+}
+
+func TestChecksum(t *testing.T) {
+	ctx := context.Background()
+	checksum := func(rows, sum string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)|sum", "int64|decimal"), rows+"|"+sum)
+	}
+	const columns = "select count(*), sum(crc32(concat_ws('#', `id`, `name`, concat(isnull(`id`), isnull(`name`))))) from `t1`"
+	shard1 := fakeExecutor(map[string]*sqltypes.Result{
+		columns + " where `id` < 3":                checksum("2", "100"),
+		columns + " where `id` >= 3 and `id` < 10": checksum("0", "null"),
+		columns + " where `id` >= 10":              checksum("1", "7"),
+	})
+	shard2 := fakeExecutor(map[string]*sqltypes.Result{
+		columns + " where `id` < 3":                checksum("1", "5"),
+		columns + " where `id` >= 3 and `id` < 10": checksum("4", "400"),
+		columns + " where `id` >= 10":              checksum("0", "null"),
+	})
+
+	source, err := Checksum(ctx, []Executor{shard1, shard2}, testTable, ids(3, 10), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []Chunk{{Rows: 3, Checksum: 105}, {Rows: 4, Checksum: 400}, {Rows: 1, Checksum: 7}}, source)
+
+	unsharded := fakeExecutor(map[string]*sqltypes.Result{
+		columns + " where `id` < 3":                checksum("3", "105"),
+		columns + " where `id` >= 3 and `id` < 10": checksum("4", "401"),
+		columns + " where `id` >= 10":              checksum("1", "7"),
+	})
+	target, err := Checksum(ctx, []Executor{unsharded}, testTable, ids(3, 10), 0)
+	require.NoError(t, err)
+
+	mismatches, err := Compare(source, target)
+	require.NoError(t, err)
+	assert.Equal(t, []Mismatch{{Chunk: 1, Source: Chunk{Rows: 4, Checksum: 400}, Target: Chunk{Rows: 4, Checksum: 401}}}, mismatches)
+
+	_, err = Compare(source, target[:2])
+	assert.ErrorContains(t, err, "the source has 3 chunks but the target has 2")
+
+	failing := func(ctx context.Context, query string) (*sqltypes.Result, error) {
+		return nil, fmt.Errorf("table t1 does not exist")
+	}
+	_, err = Checksum(ctx, []Executor{shard1, failing}, testTable, nil, 0)
+	assert.ErrorContains(t, err, "checksumming chunk 0 of table t1: table t1 does not exist")
+}
+
+func TestChecksumQuery(t *testing.T) {
+	table := Table{
+		Name:              "t1",
+		Columns:           []string{"a", "b", "c"},
+		PrimaryKeyColumns: []string{"a", "b"},
+	}
+	boundaries := []Boundary{{sqltypes.NewInt64(1), sqltypes.NewVarChar("x")}}
+
+	assert.Equal(t,
+		"select count(*), sum(crc32(concat_ws('#', `a`, `b`, `c`, concat(isnull(`a`), isnull(`b`), isnull(`c`))))) from `t1` where (`a`, `b`) < (1, 'x')",
+		checksumQuery(table, boundaries, 0))
+	assert.Equal(t,
+		"select count(*), sum(crc32(concat_ws('#', `a`, `b`, `c`, concat(isnull(`a`), isnull(`b`), isnull(`c`))))) from `t1` where (`a`, `b`) >= (1, 'x')",
+		checksumQuery(table, boundaries, 1))
+	assert.Equal(t,
+		"select `a`, `b` from `t1` where (`a`, `b`) >= (1, 'x') order by `a`, `b` limit 100, 1",
+		boundaryQuery(table, boundaries[0], 100))
+}
+
+func TestPacer(t *testing.T) {
+	ctx := context.Background()
+
+	var pacer *Pacer
+	assert.Nil(t, NewPacer(0))
+	assert.NoError(t, pacer.Wait(ctx, 1000))
+
+	pacer = NewPacer(1000)
+	start := time.Now()
+	require.NoError(t, pacer.Wait(ctx, 50))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, pacer.Wait(ctx, 1000), context.Canceled)
+}
@@ -39,6 +39,7 @@ import (
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	_flag "vitess.io/vitess/go/internal/flag"
@@ -190,6 +191,8 @@ type testTMClient struct {
 	vrQueries                          map[int][]*queryResult
 	createVReplicationWorkflowRequests map[uint32]*tabletmanagerdatapb.CreateVReplicationWorkflowRequest
 	readVReplicationWorkflowRequests   map[uint32]*tabletmanagerdatapb.ReadVReplicationWorkflowRequest
+	// vdiffShowResponse is returned for the VDiff show actions, if set.
+	vdiffShowResponse *tabletmanagerdatapb.VDiffResponse
 
 	env     *testEnv    // For access to the env config from tmc methods.
 	reverse atomic.Bool // Are we reversing traffic?
@@ -249,7 +252,8 @@ func (tmc *testTMClient) ReadVReplicationWorkflow(ctx context.Context, tablet *t
 	}
 	for i, shard := range blsKs.ShardNames {
 		stream := &tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{
-			Id: int32(i + 1),
+			Id:    int32(i + 1),
+			State: binlogdatapb.VReplicationWorkflowState_Running,
 			Bls: &binlogdatapb.BinlogSource{
 				Keyspace: blsKs.KeyspaceName,
 				Shard:    shard,
@@ -374,6 +378,12 @@ func (tmc *testTMClient) ApplySchema(ctx context.Context, tablet *topodatapb.Tab
 }
 
 func (tmc *testTMClient) VDiff(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.VDiffRequest) (*tabletmanagerdatapb.VDiffResponse, error) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+
+	if req.Action == string(vdiff.ShowAction) && tmc.vdiffShowResponse != nil {
+		return tmc.vdiffShowResponse, nil
+	}
 	return &tabletmanagerdatapb.VDiffResponse{
 		Id:        1,
 		VdiffUuid: req.VdiffUuid,
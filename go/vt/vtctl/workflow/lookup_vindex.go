@@ -0,0 +1,388 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// LookupVindexComplete externalizes a lookup vindex once the last VDiff of
+// the workflow backfilling its lookup table completed without finding any
+// mismatch. If the vindex has an owner then the workflow is also deleted, as
+// VTGate keeps the lookup table up to date from then on.
+func (s *Server) LookupVindexComplete(ctx context.Context, req *vtctldatapb.LookupVindexCompleteRequest) (*vtctldatapb.LookupVindexCompleteResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexComplete")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+
+	vindex, err := s.getLookupVindex(ctx, req.Keyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !isWriteOnlyVindex(vindex) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "vindex %s in the %s keyspace is already externalized", req.Name, req.Keyspace)
+	}
+
+	summary, err := s.getLastVDiffSummary(ctx, req.TableKeyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case summary == nil:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the lookup table of vindex %s has not been verified, run a VDiff of the %s workflow first", req.Name, req.Name)
+	case summary.state != vdiff.CompletedState:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the last VDiff %s of the %s workflow is %s: %s", summary.uuid, req.Name, summary.state, summary.message())
+	case len(summary.mismatches) > 0:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the last VDiff %s of the %s workflow found mismatches: %s", summary.uuid, req.Name, summary.message())
+	}
+
+	resp, err := s.LookupVindexExternalize(ctx, &vtctldatapb.LookupVindexExternalizeRequest{
+		Keyspace:      req.Keyspace,
+		Name:          req.Name,
+		TableKeyspace: req.TableKeyspace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.LookupVindexCompleteResponse{
+		WorkflowDeleted: resp.WorkflowDeleted,
+	}, nil
+}
+
+// LookupVindexRevert removes a lookup vindex which is not externalized yet:
+// the workflow backfilling it is deleted, its lookup table is dropped unless
+// requested otherwise, and the vindex is removed from the VSchema along with
+// the lookup table. Each step is skipped when it was already done so that a
+// failed revert can be run again.
+func (s *Server) LookupVindexRevert(ctx context.Context, req *vtctldatapb.LookupVindexRevertRequest) (*vtctldatapb.LookupVindexRevertResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexRevert")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+	span.Annotate("keep_data", req.KeepData)
+
+	sourceVSchema, err := s.ts.GetVSchema(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	vindex := sourceVSchema.Vindexes[req.Name]
+	if vindex != nil && !isWriteOnlyVindex(vindex) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "vindex %s in the %s keyspace is already externalized and cannot be reverted", req.Name, req.Keyspace)
+	}
+
+	wf, err := s.getLookupVindexWorkflow(ctx, req.TableKeyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if vindex == nil && wf == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "vindex %s not found in the %s keyspace", req.Name, req.Keyspace)
+	}
+	if wf != nil {
+		if _, err := s.WorkflowDelete(ctx, &vtctldatapb.WorkflowDeleteRequest{
+			Keyspace:         req.TableKeyspace,
+			Workflow:         req.Name,
+			KeepData:         true, // Not relevant
+			KeepRoutingRules: true, // Not relevant
+		}); err != nil {
+			return nil, vterrors.Wrapf(err, "failed to delete workflow %s", req.Name)
+		}
+	}
+	if vindex == nil {
+		return &vtctldatapb.LookupVindexRevertResponse{}, nil
+	}
+
+	tableKeyspace, tableName, err := s.env.Parser().ParseTable(vindex.Params["table"])
+	if err != nil || tableKeyspace == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vindex table name (%s) must be in the form <keyspace>.<table>", vindex.Params["table"])
+	}
+	if !req.KeepData {
+		if err := s.dropLookupTable(ctx, tableKeyspace, tableName); err != nil {
+			return nil, err
+		}
+	}
+
+	removeVindex(sourceVSchema, req.Name)
+	if tableKeyspace == req.Keyspace {
+		delete(sourceVSchema.Tables, tableName)
+	} else {
+		targetVSchema, err := s.ts.GetVSchema(ctx, tableKeyspace)
+		if err != nil {
+			return nil, err
+		}
+		delete(targetVSchema.Tables, tableName)
+		if err := s.ts.SaveVSchema(ctx, tableKeyspace, targetVSchema); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ts.SaveVSchema(ctx, req.Keyspace, sourceVSchema); err != nil {
+		return nil, err
+	}
+	if err := s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.LookupVindexRevertResponse{}, nil
+}
+
+// LookupVindexStatus returns the phase of the lifecycle of a lookup vindex,
+// from the backfill of its lookup table to its verification with a VDiff and
+// its externalization.
+func (s *Server) LookupVindexStatus(ctx context.Context, req *vtctldatapb.LookupVindexStatusRequest) (*vtctldatapb.LookupVindexStatusResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexStatus")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+	span.Annotate("table_keyspace", req.TableKeyspace)
+
+	vindex, err := s.getLookupVindex(ctx, req.Keyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := s.getLookupVindexWorkflow(ctx, req.TableKeyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	resp := &vtctldatapb.LookupVindexStatusResponse{
+		Workflow: wf,
+	}
+	if !isWriteOnlyVindex(vindex) {
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_COMPLETED
+		return resp, nil
+	}
+	if wf == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "the %s workflow backfilling vindex %s does not exist in the %s keyspace", req.Name, req.Name, req.TableKeyspace)
+	}
+
+	backfilling := false
+	var streamErrors []string
+	shards := maps.Keys(wf.ShardStreams)
+	sort.Strings(shards)
+	for _, shard := range shards {
+		for _, stream := range wf.ShardStreams[shard].Streams {
+			if len(stream.CopyStates) > 0 || stream.State == binlogdatapb.VReplicationWorkflowState_Copying.String() {
+				backfilling = true
+			}
+			if stream.State == binlogdatapb.VReplicationWorkflowState_Error.String() {
+				streamErrors = append(streamErrors, fmt.Sprintf("stream %d on shard %s: %s", stream.Id, shard, stream.Message))
+			}
+		}
+	}
+	resp.Message = strings.Join(streamErrors, "; ")
+	if backfilling {
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_BACKFILLING
+		return resp, nil
+	}
+
+	summary, err := s.getLastVDiffSummary(ctx, req.TableKeyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case summary == nil:
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_BACKFILLED
+		return resp, nil
+	case summary.state == vdiff.CompletedState && len(summary.mismatches) == 0:
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_VERIFIED
+	case summary.state == vdiff.CompletedState, summary.state == vdiff.ErrorState:
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_VERIFICATION_FAILED
+		resp.Message = summary.message()
+	default:
+		resp.Phase = vtctldatapb.LookupVindexStatusResponse_VERIFYING
+	}
+	resp.VdiffUuid = summary.uuid
+	return resp, nil
+}
+
+// getLookupVindex returns the vindex with the given name of the keyspace.
+func (s *Server) getLookupVindex(ctx context.Context, keyspace, name string) (*vschemapb.Vindex, error) {
+	vschema, err := s.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "failed to get vschema for the %s keyspace", keyspace)
+	}
+	vindex := vschema.Vindexes[name]
+	if vindex == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "vindex %s not found in the %s keyspace", name, keyspace)
+	}
+	return vindex, nil
+}
+
+// getLookupVindexWorkflow returns the workflow backfilling a lookup vindex,
+// nil if it does not exist.
+func (s *Server) getLookupVindexWorkflow(ctx context.Context, keyspace, name string) (*vtctldatapb.Workflow, error) {
+	resp, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{
+		Keyspace: keyspace,
+		Workflow: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range resp.Workflows {
+		if wf.Name == name {
+			return wf, nil
+		}
+	}
+	return nil, nil
+}
+
+// dropLookupTable drops the lookup table on all the shards of the keyspace.
+func (s *Server) dropLookupTable(ctx context.Context, keyspace, table string) error {
+	shards, err := s.ts.GetServingShards(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	escapedTable, err := sqlescape.EnsureEscaped(table)
+	if err != nil {
+		return err
+	}
+	return forAllShards(shards, func(shard *topo.ShardInfo) error {
+		primary, err := s.ts.GetTablet(ctx, shard.PrimaryAlias)
+		if err != nil {
+			return err
+		}
+		dbName, err := sqlescape.EnsureEscaped(primary.DbName())
+		if err != nil {
+			return err
+		}
+		log.Infof("Dropping lookup table %s.%s on %s", primary.DbName(), table, topoproto.TabletAliasString(primary.Alias))
+		_, err = s.tmc.ExecuteFetchAsDba(ctx, primary.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:                   []byte(fmt.Sprintf("drop table if exists %s.%s", dbName, escapedTable)),
+			MaxRows:                 1,
+			ReloadSchema:            true,
+			DisableForeignKeyChecks: true,
+		})
+		if mysqlErr, ok := err.(*sqlerror.SQLError); ok && mysqlErr.Num == sqlerror.ERNoSuchTable {
+			return nil
+		}
+		return err
+	})
+}
+
+// isWriteOnlyVindex returns whether a lookup vindex is still write only,
+// i.e. it is not externalized yet.
+func isWriteOnlyVindex(vindex *vschemapb.Vindex) bool {
+	return vindex.Params["write_only"] == "true"
+}
+
+// removeVindex removes a vindex from the VSchema of a keyspace, along with
+// the column vindexes of the tables using it.
+func removeVindex(vschema *vschemapb.Keyspace, name string) {
+	delete(vschema.Vindexes, name)
+	for _, table := range vschema.Tables {
+		table.ColumnVindexes = slices.DeleteFunc(table.ColumnVindexes, func(cv *vschemapb.ColumnVindex) bool {
+			return cv.Name == name
+		})
+	}
+}
+
+// vdiffSummary summarizes the last VDiff of a workflow on all of its target
+// shards.
+type vdiffSummary struct {
+	uuid  string
+	state vdiff.VDiffState
+	// mismatches are the tables with mismatches.
+	mismatches []string
+	// errors are the errors of the VDiff on the shards.
+	errors []string
+}
+
+func (vs *vdiffSummary) message() string {
+	var msgs []string
+	if len(vs.mismatches) > 0 {
+		msgs = append(msgs, fmt.Sprintf("mismatches found in tables %s", strings.Join(vs.mismatches, ",")))
+	}
+	return strings.Join(append(msgs, vs.errors...), "; ")
+}
+
+// getLastVDiffSummary returns the summary of the last VDiff of the workflow,
+// nil if there is none. The VDiff is failed when the last one of a shard is
+// missing or is a different one.
+func (s *Server) getLastVDiffSummary(ctx context.Context, keyspace, workflow string) (*vdiffSummary, error) {
+	resp, err := s.VDiffShow(ctx, &vtctldatapb.VDiffShowRequest{
+		Workflow:       workflow,
+		TargetKeyspace: keyspace,
+		Arg:            vdiff.LastActionArg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shards := maps.Keys(resp.TabletResponses)
+	sort.Strings(shards)
+	summary := &vdiffSummary{state: vdiff.CompletedState}
+	for _, shard := range shards {
+		if uuid := resp.TabletResponses[shard].GetVdiffUuid(); uuid != "" {
+			summary.uuid = uuid
+			break
+		}
+	}
+	if summary.uuid == "" {
+		return nil, nil
+	}
+	for _, shard := range shards {
+		tresp := resp.TabletResponses[shard]
+		if tresp.GetVdiffUuid() != summary.uuid {
+			summary.state = vdiff.ErrorState
+			summary.errors = append(summary.errors, fmt.Sprintf("shard %s: VDiff %s not found", shard, summary.uuid))
+			continue
+		}
+		for _, row := range sqltypes.Proto3ToResult(tresp.Output).Named().Rows {
+			state := vdiff.VDiffState(strings.ToLower(row.AsString("vdiff_state", "")))
+			switch {
+			case state == vdiff.ErrorState:
+				summary.state = vdiff.ErrorState
+			case state != vdiff.CompletedState && summary.state != vdiff.ErrorState:
+				summary.state = state
+			}
+			if lastError := row.AsString("last_error", ""); lastError != "" {
+				if msg := fmt.Sprintf("shard %s: %s", shard, lastError); !slices.Contains(summary.errors, msg) {
+					summary.errors = append(summary.errors, msg)
+				}
+			}
+			if table := row.AsString("table_name", ""); row.AsInt64("has_mismatch", 0) == 1 && !slices.Contains(summary.mismatches, table) {
+				summary.mismatches = append(summary.mismatches, table)
+			}
+		}
+	}
+	sort.Strings(summary.mismatches)
+	return summary, nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func setupLookupVindexEnv(t *testing.T, ctx context.Context) *testEnv {
+	sourceKeyspace := &testKeyspace{
+		KeyspaceName: "sourceks",
+		ShardNames:   []string{"0"},
+	}
+	targetKeyspace := &testKeyspace{
+		KeyspaceName: "targetks",
+		ShardNames:   []string{"-80", "80-"},
+	}
+	env := newTestEnv(t, ctx, defaultCellName, sourceKeyspace, targetKeyspace)
+	env.tmc.schema = map[string]*tabletmanagerdatapb.SchemaDefinition{
+		"t1_lookup": {
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+				Name:   "t1_lookup",
+				Schema: "CREATE TABLE t1_lookup (c1 BIGINT, keyspace_id VARBINARY(128), PRIMARY KEY (c1))",
+			}},
+		},
+	}
+
+	err := env.ts.SaveVSchema(ctx, sourceKeyspace.KeyspaceName, &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"xxhash": {
+				Type: "xxhash",
+			},
+			"t1_lookup": {
+				Type: "consistent_lookup_unique",
+				Params: map[string]string{
+					"table":      "targetks.t1_lookup",
+					"from":       "c1",
+					"to":         "keyspace_id",
+					"write_only": "true",
+				},
+				Owner: "t1",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{
+					Name:   "xxhash",
+					Column: "id",
+				}, {
+					Name:   "t1_lookup",
+					Column: "c1",
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	err = env.ts.SaveVSchema(ctx, targetKeyspace.KeyspaceName, &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"xxhash": {
+				Type: "xxhash",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1_lookup": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{
+					Name:   "xxhash",
+					Column: "c1",
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return env
+}
+
+func expectLookupVindexCopyStates(env *testEnv) {
+	env.tmc.expectVRQueryResultOnKeyspaceTablets(env.targetKeyspace.KeyspaceName, &queryResult{
+		query:  "select vrepl_id, table_name, lastpk from _vt.copy_state where vrepl_id in (1) and id in (select max(id) from _vt.copy_state where vrepl_id in (1) group by vrepl_id, table_name)",
+		result: &querypb.QueryResult{},
+	})
+}
+
+func lookupVindexVDiffShowResponse(state string, mismatch int) *tabletmanagerdatapb.VDiffResponse {
+	res := sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"vdiff_state|last_error|table_name|uuid|has_mismatch",
+		"varchar|varchar|varchar|varchar|int64"),
+		"completed||t1_lookup|7f7a2a3f-0c1d-11ef-9c5b-0a43f95f28a3|"+string(rune('0'+mismatch)),
+	)
+	res.Rows[0][0] = sqltypes.NewVarChar(state)
+	return &tabletmanagerdatapb.VDiffResponse{
+		Id:        1,
+		VdiffUuid: "7f7a2a3f-0c1d-11ef-9c5b-0a43f95f28a3",
+		Output:    sqltypes.ResultToProto3(res),
+	}
+}
+
+func TestLookupVindexComplete(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	env := setupLookupVindexEnv(t, ctx)
+	defer env.close()
+
+	statusReq := &vtctldatapb.LookupVindexStatusRequest{
+		Keyspace:      "sourceks",
+		Name:          "t1_lookup",
+		TableKeyspace: "targetks",
+	}
+	completeReq := &vtctldatapb.LookupVindexCompleteRequest{
+		Keyspace:      "sourceks",
+		Name:          "t1_lookup",
+		TableKeyspace: "targetks",
+	}
+
+	// The lookup table is backfilled but not verified yet.
+	expectLookupVindexCopyStates(env)
+	status, err := env.ws.LookupVindexStatus(ctx, statusReq)
+	require.NoError(t, err)
+	require.Equal(t, vtctldatapb.LookupVindexStatusResponse_BACKFILLED, status.Phase)
+	require.NotNil(t, status.Workflow)
+	_, err = env.ws.LookupVindexComplete(ctx, completeReq)
+	require.EqualError(t, err, "the lookup table of vindex t1_lookup has not been verified, run a VDiff of the t1_lookup workflow first")
+
+	// The VDiff is running.
+	env.tmc.vdiffShowResponse = lookupVindexVDiffShowResponse("started", 0)
+	expectLookupVindexCopyStates(env)
+	status, err = env.ws.LookupVindexStatus(ctx, statusReq)
+	require.NoError(t, err)
+	require.Equal(t, vtctldatapb.LookupVindexStatusResponse_VERIFYING, status.Phase)
+	require.Equal(t, "7f7a2a3f-0c1d-11ef-9c5b-0a43f95f28a3", status.VdiffUuid)
+	_, err = env.ws.LookupVindexComplete(ctx, completeReq)
+	require.EqualError(t, err, "the last VDiff 7f7a2a3f-0c1d-11ef-9c5b-0a43f95f28a3 of the t1_lookup workflow is started: ")
+
+	// The VDiff found mismatches.
+	env.tmc.vdiffShowResponse = lookupVindexVDiffShowResponse("completed", 1)
+	expectLookupVindexCopyStates(env)
+	status, err = env.ws.LookupVindexStatus(ctx, statusReq)
+	require.NoError(t, err)
+	require.Equal(t, vtctldatapb.LookupVindexStatusResponse_VERIFICATION_FAILED, status.Phase)
+	require.Equal(t, "mismatches found in tables t1_lookup", status.Message)
+	_, err = env.ws.LookupVindexComplete(ctx, completeReq)
+	require.EqualError(t, err, "the last VDiff 7f7a2a3f-0c1d-11ef-9c5b-0a43f95f28a3 of the t1_lookup workflow found mismatches: mismatches found in tables t1_lookup")
+
+	// The lookup table is verified, the vindex is externalized and the
+	// workflow deleted as the vindex has an owner.
+	env.tmc.vdiffShowResponse = lookupVindexVDiffShowResponse("completed", 0)
+	expectLookupVindexCopyStates(env)
+	status, err = env.ws.LookupVindexStatus(ctx, statusReq)
+	require.NoError(t, err)
+	require.Equal(t, vtctldatapb.LookupVindexStatusResponse_VERIFIED, status.Phase)
+	resp, err := env.ws.LookupVindexComplete(ctx, completeReq)
+	require.NoError(t, err)
+	require.True(t, resp.WorkflowDeleted)
+	vschema, err := env.ts.GetVSchema(ctx, "sourceks")
+	require.NoError(t, err)
+	require.NotContains(t, vschema.Vindexes["t1_lookup"].Params, "write_only")
+
+	expectLookupVindexCopyStates(env)
+	status, err = env.ws.LookupVindexStatus(ctx, statusReq)
+	require.NoError(t, err)
+	require.Equal(t, vtctldatapb.LookupVindexStatusResponse_COMPLETED, status.Phase)
+	_, err = env.ws.LookupVindexComplete(ctx, completeReq)
+	require.EqualError(t, err, "vindex t1_lookup in the sourceks keyspace is already externalized")
+	_, err = env.ws.LookupVindexRevert(ctx, &vtctldatapb.LookupVindexRevertRequest{
+		Keyspace:      "sourceks",
+		Name:          "t1_lookup",
+		TableKeyspace: "targetks",
+	})
+	require.EqualError(t, err, "vindex t1_lookup in the sourceks keyspace is already externalized and cannot be reverted")
+}
+
+func TestLookupVindexRevert(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	env := setupLookupVindexEnv(t, ctx)
+	defer env.close()
+
+	expectLookupVindexCopyStates(env)
+	env.tmc.expectVRQueryResultOnKeyspaceTablets(env.targetKeyspace.KeyspaceName, &queryResult{
+		query:  "drop table if exists `vt_targetks`.`t1_lookup`",
+		result: &querypb.QueryResult{},
+	})
+	_, err := env.ws.LookupVindexRevert(ctx, &vtctldatapb.LookupVindexRevertRequest{
+		Keyspace:      "sourceks",
+		Name:          "t1_lookup",
+		TableKeyspace: "targetks",
+	})
+	require.NoError(t, err)
+
+	// The vindex and the lookup table are removed from the VSchemas.
+	vschema, err := env.ts.GetVSchema(ctx, "sourceks")
+	require.NoError(t, err)
+	require.NotContains(t, vschema.Vindexes, "t1_lookup")
+	require.Len(t, vschema.Tables["t1"].ColumnVindexes, 1)
+	require.Equal(t, "xxhash", vschema.Tables["t1"].ColumnVindexes[0].Name)
+	vschema, err = env.ts.GetVSchema(ctx, "targetks")
+	require.NoError(t, err)
+	require.NotContains(t, vschema.Tables, "t1_lookup")
+	require.Contains(t, vschema.Vindexes, "xxhash")
+}
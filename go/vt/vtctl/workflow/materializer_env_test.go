@@ -158,6 +158,8 @@ type testMaterializerTMClient struct {
 	mu                                 sync.Mutex
 	vrQueries                          map[int][]*queryResult
 	createVReplicationWorkflowRequests map[uint32]*tabletmanagerdatapb.CreateVReplicationWorkflowRequest
+	// Responses to ReadVReplicationWorkflow by tablet UID, overriding the default one.
+	readVReplicationWorkflowResponses map[uint32]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse
 
 	// Used to confirm the number of times WorkflowDelete was called.
 	workflowDeleteCalls int
@@ -168,6 +170,7 @@ func newTestMaterializerTMClient() *testMaterializerTMClient {
 		schema:                             make(map[string]*tabletmanagerdatapb.SchemaDefinition),
 		vrQueries:                          make(map[int][]*queryResult),
 		createVReplicationWorkflowRequests: make(map[uint32]*tabletmanagerdatapb.CreateVReplicationWorkflowRequest),
+		readVReplicationWorkflowResponses:  make(map[uint32]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse),
 	}
 }
 
@@ -182,6 +185,9 @@ func (tmc *testMaterializerTMClient) CreateVReplicationWorkflow(ctx context.Cont
 }
 
 func (tmc *testMaterializerTMClient) ReadVReplicationWorkflow(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.ReadVReplicationWorkflowRequest) (*tabletmanagerdatapb.ReadVReplicationWorkflowResponse, error) {
+	if res, ok := tmc.readVReplicationWorkflowResponses[tablet.Alias.Uid]; ok {
+		return res, nil
+	}
 	workflowType := binlogdatapb.VReplicationWorkflowType_MoveTables
 	if strings.Contains(request.Workflow, "lookup") {
 		workflowType = binlogdatapb.VReplicationWorkflowType_CreateLookupIndex
@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ReferenceTablesCreate creates a Materialize workflow replicating the
+// reference tables declared in the VSchema of the target keyspace from the
+// unsharded source keyspace to every shard of the target keyspace.
+func (s *Server) ReferenceTablesCreate(ctx context.Context, req *vtctldatapb.ReferenceTablesCreateRequest) (*vtctldatapb.ReferenceTablesCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReferenceTablesCreate")
+	defer span.Finish()
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("tables", req.Tables)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+
+	sourceVSchema, err := s.ts.GetVSchema(ctx, req.SourceKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if sourceVSchema.Sharded {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "source keyspace %s must be unsharded", req.SourceKeyspace)
+	}
+	targetVSchema, err := s.ts.GetVSchema(ctx, req.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	declared, err := s.declaredReferenceTables(targetVSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := req.Tables
+	if len(tables) == 0 {
+		for table, source := range declared {
+			if source.Qualifier.String() == req.SourceKeyspace {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) == 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no reference table of keyspace %s has a source in keyspace %s", req.TargetKeyspace, req.SourceKeyspace)
+		}
+	}
+	sort.Strings(tables)
+
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:                  req.Workflow,
+		MaterializationIntent:     vtctldatapb.MaterializationIntent_CUSTOM,
+		SourceKeyspace:            req.SourceKeyspace,
+		TargetKeyspace:            req.TargetKeyspace,
+		Cell:                      strings.Join(req.Cells, ","),
+		TabletTypes:               topoproto.MakeStringTypeCSV(req.TabletTypes),
+		TabletSelectionPreference: req.TabletSelectionPreference,
+	}
+	for _, table := range tables {
+		source, ok := declared[table]
+		if !ok || source.Qualifier.String() != req.SourceKeyspace {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s is not declared as a reference table with a source in keyspace %s", table, req.SourceKeyspace)
+		}
+		// Copying the schema requires the names to match.
+		if source.Name.String() != table {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "reference table %s must have the same name as its source %s", table, sqlparser.String(source))
+		}
+		ms.TableSettings = append(ms.TableSettings, &vtctldatapb.TableMaterializeSettings{
+			TargetTable:      table,
+			SourceExpression: "select * from " + sqlparser.String(source.Name),
+			CreateDdl:        createDDLAsCopy,
+		})
+	}
+
+	if err := s.Materialize(ctx, ms); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.ReferenceTablesCreateResponse{Tables: tables}, nil
+}
+
+// ReferenceTablesCheck compares the streams of a reference tables workflow
+// with the reference tables declared in the VSchema of its keyspace, and
+// reports the shards where tables are missing or superfluous, and where
+// the streams are not running.
+func (s *Server) ReferenceTablesCheck(ctx context.Context, req *vtctldatapb.ReferenceTablesCheckRequest) (*vtctldatapb.ReferenceTablesCheckResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReferenceTablesCheck")
+	defer span.Finish()
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+
+	targetVSchema, err := s.ts.GetVSchema(ctx, req.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	declared, err := s.declaredReferenceTables(targetVSchema)
+	if err != nil {
+		return nil, err
+	}
+	targetShards, err := s.ts.GetServingShards(ctx, req.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		allErrors = &concurrency.AllErrorRecorder{}
+		resp      = &vtctldatapb.ReferenceTablesCheckResponse{}
+	)
+	for _, targetShard := range targetShards {
+		wg.Add(1)
+		go func(targetShard *topo.ShardInfo) {
+			defer wg.Done()
+
+			drifts, err := s.checkReferenceTablesShard(ctx, req.Workflow, targetShard, declared)
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Drifts = append(resp.Drifts, drifts...)
+		}(targetShard)
+	}
+	wg.Wait()
+	if allErrors.HasErrors() {
+		return nil, allErrors.AggrError(vterrors.Aggregate)
+	}
+
+	sort.Slice(resp.Drifts, func(i, j int) bool {
+		if resp.Drifts[i].Shard != resp.Drifts[j].Shard {
+			return resp.Drifts[i].Shard < resp.Drifts[j].Shard
+		}
+		return resp.Drifts[i].Table < resp.Drifts[j].Table
+	})
+	return resp, nil
+}
+
+func (s *Server) checkReferenceTablesShard(ctx context.Context, workflow string, targetShard *topo.ShardInfo, declared map[string]sqlparser.TableName) ([]*vtctldatapb.ReferenceTablesCheckResponse_Drift, error) {
+	shard := targetShard.ShardName()
+	targetPrimary, err := s.ts.GetTablet(ctx, targetShard.PrimaryAlias)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.tmc.ReadVReplicationWorkflow(ctx, targetPrimary.Tablet, &tabletmanagerdatapb.ReadVReplicationWorkflowRequest{
+		Workflow: workflow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || len(res.Streams) == 0 {
+		return []*vtctldatapb.ReferenceTablesCheckResponse_Drift{{
+			Shard:   shard,
+			Message: fmt.Sprintf("workflow %s not found", workflow),
+		}}, nil
+	}
+
+	var drifts []*vtctldatapb.ReferenceTablesCheckResponse_Drift
+	replicated := make(map[string]bool)
+	sourceKeyspaces := make(map[string]bool)
+	for _, stream := range res.Streams {
+		if stream.State != binlogdatapb.VReplicationWorkflowState_Running {
+			drifts = append(drifts, &vtctldatapb.ReferenceTablesCheckResponse_Drift{
+				Shard:   shard,
+				Message: fmt.Sprintf("stream %d is in state %s: %s", stream.Id, stream.State, stream.Message),
+			})
+		}
+		if stream.Bls == nil || stream.Bls.Filter == nil {
+			continue
+		}
+		sourceKeyspaces[stream.Bls.Keyspace] = true
+		for _, rule := range stream.Bls.Filter.Rules {
+			replicated[rule.Match] = true
+		}
+	}
+	for table := range replicated {
+		source, ok := declared[table]
+		if !ok || !sourceKeyspaces[source.Qualifier.String()] {
+			drifts = append(drifts, &vtctldatapb.ReferenceTablesCheckResponse_Drift{
+				Shard:   shard,
+				Table:   table,
+				Message: "table is replicated but is not declared as a reference table with a source in the source keyspace",
+			})
+		}
+	}
+	for table, source := range declared {
+		if !replicated[table] && sourceKeyspaces[source.Qualifier.String()] {
+			drifts = append(drifts, &vtctldatapb.ReferenceTablesCheckResponse_Drift{
+				Shard:   shard,
+				Table:   table,
+				Message: fmt.Sprintf("reference table is declared with source %s but is not replicated", sqlparser.String(source)),
+			})
+		}
+	}
+	return drifts, nil
+}
+
+// declaredReferenceTables returns the reference tables of the VSchema
+// which have a keyspace-qualified source, mapped to their source.
+func (s *Server) declaredReferenceTables(vschema *vschemapb.Keyspace) (map[string]sqlparser.TableName, error) {
+	declared := make(map[string]sqlparser.TableName)
+	for name, table := range vschema.Tables {
+		if table.Type != vindexes.TypeReference || table.Source == "" {
+			continue
+		}
+		keyspace, tableName, err := s.env.Parser().ParseTable(table.Source)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "invalid source for reference table %s", name)
+		}
+		if keyspace == "" {
+			continue
+		}
+		declared[name] = sqlparser.TableName{
+			Qualifier: sqlparser.NewIdentifierCS(keyspace),
+			Name:      sqlparser.NewIdentifierCS(tableName),
+		}
+	}
+	return declared, nil
+}
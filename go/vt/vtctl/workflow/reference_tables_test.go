@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func newReferenceTablesEnv(t *testing.T, ctx context.Context) *testMaterializerEnv {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "ref",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      "t1",
+			SourceExpression: "select * from t1",
+		}, {
+			TargetTable:      "t2",
+			SourceExpression: "select * from t2",
+		}},
+	}
+	env := newTestMaterializerEnv(t, ctx, ms, []string{"0"}, []string{"-80", "80-"})
+	err := env.topoServ.SaveVSchema(ctx, "sourceks", &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"t1":   {},
+			"t2":   {},
+			"t3":   {},
+			"seq1": {Type: "sequence"},
+		},
+	})
+	require.NoError(t, err)
+	err = env.topoServ.SaveVSchema(ctx, "targetks", &vschemapb.Keyspace{
+		Sharded: true,
+		Tables: map[string]*vschemapb.Table{
+			"t1":    {Type: "reference", Source: "sourceks.t1"},
+			"t2":    {Type: "reference", Source: "sourceks.t2"},
+			"local": {Type: "reference"},
+			"other": {Type: "reference", Source: "sourceks.t3"},
+		},
+	})
+	require.NoError(t, err)
+	return env
+}
+
+func TestReferenceTablesCreate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	env := newReferenceTablesEnv(t, ctx)
+	defer env.close()
+
+	for _, uid := range []uint32{200, 210} {
+		// The tables are copied to every shard, without a keyrange filter.
+		env.tmc.expectCreateVReplicationWorkflowRequest(uid, &tabletmanagerdatapb.CreateVReplicationWorkflowRequest{
+			Workflow:                  "ref",
+			WorkflowType:              binlogdatapb.VReplicationWorkflowType_Materialize,
+			Cells:                     []string{""},
+			TabletSelectionPreference: tabletmanagerdatapb.TabletSelectionPreference_INORDER,
+			AutoStart:                 true,
+			BinlogSource: []*binlogdatapb.BinlogSource{{
+				Keyspace: "sourceks",
+				Shard:    "0",
+				Filter: &binlogdatapb.Filter{
+					Rules: []*binlogdatapb.Rule{
+						{Match: "t1", Filter: "select * from t1"},
+						{Match: "t2", Filter: "select * from t2"},
+					},
+				},
+			}},
+			Options: "{}",
+		})
+	}
+	resp, err := env.ws.ReferenceTablesCreate(ctx, &vtctldatapb.ReferenceTablesCreateRequest{
+		Workflow:                  "ref",
+		TargetKeyspace:            "targetks",
+		SourceKeyspace:            "sourceks",
+		Tables:                    []string{"t2", "t1"},
+		TabletSelectionPreference: tabletmanagerdatapb.TabletSelectionPreference_INORDER,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"t1", "t2"}, resp.Tables)
+}
+
+func TestReferenceTablesCreateErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	env := newReferenceTablesEnv(t, ctx)
+	defer env.close()
+
+	tcs := []struct {
+		name string
+		req  *vtctldatapb.ReferenceTablesCreateRequest
+		err  string
+	}{{
+		name: "sharded source",
+		req:  &vtctldatapb.ReferenceTablesCreateRequest{SourceKeyspace: "targetks", TargetKeyspace: "targetks"},
+		err:  "source keyspace targetks must be unsharded",
+	}, {
+		name: "no reference tables",
+		req:  &vtctldatapb.ReferenceTablesCreateRequest{SourceKeyspace: "sourceks", TargetKeyspace: "sourceks"},
+		err:  "no reference table of keyspace sourceks has a source in keyspace sourceks",
+	}, {
+		name: "undeclared table",
+		req:  &vtctldatapb.ReferenceTablesCreateRequest{SourceKeyspace: "sourceks", TargetKeyspace: "targetks", Tables: []string{"local"}},
+		err:  "table local is not declared as a reference table with a source in keyspace sourceks",
+	}, {
+		name: "renamed table",
+		req:  &vtctldatapb.ReferenceTablesCreateRequest{SourceKeyspace: "sourceks", TargetKeyspace: "targetks", Tables: []string{"other"}},
+		err:  "reference table other must have the same name as its source sourceks.t3",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := env.ws.ReferenceTablesCreate(ctx, tc.req)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestReferenceTablesCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	env := newReferenceTablesEnv(t, ctx)
+	defer env.close()
+
+	stream := func(state binlogdatapb.VReplicationWorkflowState, tables ...string) *tabletmanagerdatapb.ReadVReplicationWorkflowResponse {
+		bls := &binlogdatapb.BinlogSource{
+			Keyspace: "sourceks",
+			Shard:    "0",
+			Filter:   &binlogdatapb.Filter{},
+		}
+		for _, table := range tables {
+			bls.Filter.Rules = append(bls.Filter.Rules, &binlogdatapb.Rule{Match: table, Filter: "select * from " + table})
+		}
+		return &tabletmanagerdatapb.ReadVReplicationWorkflowResponse{
+			Workflow: "ref",
+			Streams: []*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{{
+				Id:    1,
+				Bls:   bls,
+				State: state,
+			}},
+		}
+	}
+
+	// "other" has a source in sourceks but is not replicated on -80, while
+	// 80- replicates a table which is no longer declared and is stopped.
+	env.tmc.readVReplicationWorkflowResponses[200] = stream(binlogdatapb.VReplicationWorkflowState_Running, "t1", "t2")
+	env.tmc.readVReplicationWorkflowResponses[210] = stream(binlogdatapb.VReplicationWorkflowState_Stopped, "t1", "t2", "other", "t4")
+	resp, err := env.ws.ReferenceTablesCheck(ctx, &vtctldatapb.ReferenceTablesCheckRequest{
+		Workflow:       "ref",
+		TargetKeyspace: "targetks",
+	})
+	require.NoError(t, err)
+	want := &vtctldatapb.ReferenceTablesCheckResponse{
+		Drifts: []*vtctldatapb.ReferenceTablesCheckResponse_Drift{{
+			Shard:   "-80",
+			Table:   "other",
+			Message: "reference table is declared with source sourceks.t3 but is not replicated",
+		}, {
+			Shard:   "80-",
+			Message: "stream 1 is in state Stopped: ",
+		}, {
+			Shard:   "80-",
+			Table:   "t4",
+			Message: "table is replicated but is not declared as a reference table with a source in the source keyspace",
+		}},
+	}
+	require.True(t, proto.Equal(want, resp), "got %v, want %v", resp, want)
+
+	env.tmc.readVReplicationWorkflowResponses[200] = nil
+	env.tmc.readVReplicationWorkflowResponses[210] = stream(binlogdatapb.VReplicationWorkflowState_Running, "t1", "t2", "other")
+	resp, err = env.ws.ReferenceTablesCheck(ctx, &vtctldatapb.ReferenceTablesCheckRequest{
+		Workflow:       "ref",
+		TargetKeyspace: "targetks",
+	})
+	require.NoError(t, err)
+	want = &vtctldatapb.ReferenceTablesCheckResponse{
+		Drifts: []*vtctldatapb.ReferenceTablesCheckResponse_Drift{{
+			Shard:   "-80",
+			Message: "workflow ref not found",
+		}},
+	}
+	require.True(t, proto.Equal(want, resp), "got %v, want %v", resp, want)
+}
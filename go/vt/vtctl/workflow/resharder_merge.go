@@ -0,0 +1,281 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// mergeTable is a table whose rows may have the same primary key on several
+// source shards, because its primary key does not include the columns of its
+// primary vindex and is not generated by a sequence.
+type mergeTable struct {
+	name string
+	// column is the first column of the primary key, whose ranges of values
+	// are compared across the source shards.
+	column string
+	// vindexColumns are the columns of the primary vindex.
+	vindexColumns []string
+}
+
+// autoIncrementSettings are the auto_increment_increment and
+// auto_increment_offset of a source shard.
+type autoIncrementSettings struct {
+	increment, offset int64
+}
+
+// interleaves returns true if the auto increment values generated with both
+// settings never collide.
+func (s autoIncrementSettings) interleaves(other autoIncrementSettings) bool {
+	return s.increment > 1 && s.increment == other.increment && s.offset%s.increment != other.offset%other.increment
+}
+
+// valueRange is the range of the values of the column of a table on a source
+// shard.
+type valueRange struct {
+	min, max int64
+}
+
+// isMerge returns true if some target shard merges the rows of several source
+// shards.
+func (rs *resharder) isMerge() bool {
+	for _, target := range rs.targetShards {
+		if len(rs.mergedSources(target)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedSources returns the source shards whose rows are copied to the target
+// shard.
+func (rs *resharder) mergedSources(target *topo.ShardInfo) []*topo.ShardInfo {
+	var sources []*topo.ShardInfo
+	for _, source := range rs.sourceShards {
+		if key.KeyRangeIntersect(target.KeyRange, source.KeyRange) {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// validateMerge checks that the rows of the source shards merged into the same
+// target shard cannot have the same primary key, which would fail the copy or
+// the replication of the workflow once they collide in the target shard.
+//
+// The rows of a table cannot collide when its primary key includes the columns
+// of its primary vindex, as the rows with the same primary key then live in
+// the same shard, or when its primary key is generated by a sequence. Else,
+// the ranges of the values of the first column of its primary key must not
+// overlap across the merged shards, unless they are generated with the same
+// auto_increment_increment and distinct auto_increment_offset. The tables
+// whose primary key is not an integer cannot be checked and are only logged.
+func (rs *resharder) validateMerge(ctx context.Context) error {
+	sd, err := schematools.GetSchema(ctx, rs.s.ts, rs.s.tmc, rs.sourceShards[0].PrimaryAlias, &tabletmanagerdatapb.GetSchemaRequest{})
+	if err != nil {
+		return vterrors.Wrap(err, "GetSchema")
+	}
+	tables := mergeTables(rs.vschema, sd.TableDefinitions)
+	if len(tables) == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	settings := make(map[string]autoIncrementSettings)
+	ranges := make(map[string]map[string]valueRange)
+	err = rs.forAll(rs.sourceShards, func(source *topo.ShardInfo) error {
+		shardSettings, shardRanges, err := rs.readMergeRanges(ctx, source, tables)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		settings[source.ShardName()] = shardSettings
+		for table, r := range shardRanges {
+			if ranges[table] == nil {
+				ranges[table] = make(map[string]valueRange)
+			}
+			ranges[table][source.ShardName()] = r
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for _, target := range rs.targetShards {
+		var sources []string
+		for _, source := range rs.mergedSources(target) {
+			sources = append(sources, source.ShardName())
+		}
+		conflicts = append(conflicts, mergeConflicts(target.ShardName(), sources, tables, settings, ranges)...)
+	}
+	if len(conflicts) > 0 {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the merged shards may have rows with the same primary key, which would collide in the target shards:\n%s\nuse --skip-merge-validation to merge them anyway",
+			strings.Join(conflicts, "\n"))
+	}
+	return nil
+}
+
+// mergeTables returns the tables whose rows may have the same primary key on
+// several source shards, and whose primary key can be checked.
+func mergeTables(vschema *vschemapb.Keyspace, tds []*tabletmanagerdatapb.TableDefinition) []*mergeTable {
+	var tables []*mergeTable
+	for _, td := range tds {
+		if td.Type != tmutils.TableBaseTable || len(td.PrimaryKeyColumns) == 0 || schema.IsInternalOperationTableName(td.Name) {
+			continue
+		}
+		vtable := vschema.GetTables()[td.Name]
+		if vtable == nil || vtable.Type == vindexes.TypeReference || len(vtable.ColumnVindexes) == 0 {
+			continue
+		}
+		vindexColumns := vtable.ColumnVindexes[0].Columns
+		if column := vtable.ColumnVindexes[0].Column; column != "" {
+			vindexColumns = []string{column}
+		}
+		if includesColumns(td.PrimaryKeyColumns, vindexColumns) {
+			continue
+		}
+		column := td.PrimaryKeyColumns[0]
+		if autoInc := vtable.AutoIncrement; autoInc != nil && autoInc.Sequence != "" && strings.EqualFold(autoInc.Column, column) {
+			continue
+		}
+		if !isIntegralColumn(td, column) {
+			log.Warningf("Cannot check that the rows of table %s of the merged shards do not have the same primary key: its primary key (%s) does not include the columns of its primary vindex (%s) and is not an integer",
+				td.Name, strings.Join(td.PrimaryKeyColumns, ", "), strings.Join(vindexColumns, ", "))
+			continue
+		}
+		tables = append(tables, &mergeTable{name: td.Name, column: column, vindexColumns: vindexColumns})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].name < tables[j].name })
+	return tables
+}
+
+func includesColumns(columns, included []string) bool {
+	for _, col := range included {
+		if !slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, col) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIntegralColumn(td *tabletmanagerdatapb.TableDefinition, column string) bool {
+	for _, field := range td.Fields {
+		if strings.EqualFold(field.Name, column) {
+			return sqltypes.IsIntegral(field.Type)
+		}
+	}
+	return false
+}
+
+// readMergeRanges reads the auto increment settings of the source shard, and
+// the ranges of the values of the tables which have rows.
+func (rs *resharder) readMergeRanges(ctx context.Context, source *topo.ShardInfo, tables []*mergeTable) (autoIncrementSettings, map[string]valueRange, error) {
+	primary := rs.sourcePrimaries[source.ShardName()]
+	fetch := func(query string) ([][]sqltypes.Value, error) {
+		qr, err := rs.s.tmc.ExecuteFetchAsDba(ctx, primary.Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+			DbName:  primary.DbName(),
+		})
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "ExecuteFetchAsDba(%v, %s)", primary.Tablet, query)
+		}
+		return sqltypes.Proto3ToResult(qr).Rows, nil
+	}
+
+	var settings autoIncrementSettings
+	rows, err := fetch("select @@global.auto_increment_increment, @@global.auto_increment_offset")
+	if err != nil {
+		return settings, nil, err
+	}
+	if len(rows) == 1 {
+		settings.increment, _ = rows[0][0].ToInt64()
+		settings.offset, _ = rows[0][1].ToInt64()
+	}
+
+	ranges := make(map[string]valueRange, len(tables))
+	for _, table := range tables {
+		column := sqlescape.EscapeID(table.column)
+		rows, err := fetch(fmt.Sprintf("select min(%s), max(%s) from %s", column, column, sqlescape.EscapeID(table.name)))
+		if err != nil {
+			return settings, nil, err
+		}
+		if len(rows) != 1 || rows[0][0].IsNull() {
+			continue
+		}
+		var r valueRange
+		var minErr, maxErr error
+		r.min, minErr = rows[0][0].ToInt64()
+		r.max, maxErr = rows[0][1].ToInt64()
+		if minErr != nil || maxErr != nil {
+			// The unsigned values over the range of int64.
+			log.Warningf("Cannot check that the rows of table %s of the merged shards do not have the same primary key: the values of %s on shard %s are out of range",
+				table.name, table.column, source.ShardName())
+			continue
+		}
+		ranges[table.name] = r
+	}
+	return settings, ranges, nil
+}
+
+// mergeConflicts returns the tables whose ranges of values overlap on two of
+// the source shards merged into the target shard, and are not interleaved by
+// their auto increment settings.
+func mergeConflicts(target string, sources []string, tables []*mergeTable, settings map[string]autoIncrementSettings, ranges map[string]map[string]valueRange) []string {
+	var conflicts []string
+	for _, table := range tables {
+		for i, a := range sources {
+			ra, ok := ranges[table.name][a]
+			if !ok {
+				continue
+			}
+			for _, b := range sources[i+1:] {
+				rb, ok := ranges[table.name][b]
+				if !ok || ra.max < rb.min || rb.max < ra.min || settings[a].interleaves(settings[b]) {
+					continue
+				}
+				conflicts = append(conflicts, fmt.Sprintf("table %s: the values of %s on shards %s [%d, %d] and %s [%d, %d] merged into shard %s overlap, and its primary key does not include the columns of its primary vindex (%s)",
+					table.name, table.column, a, ra.min, ra.max, b, rb.min, rb.max, target, strings.Join(table.vindexColumns, ", ")))
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func newTestShards(t *testing.T, spec string) []*topo.ShardInfo {
+	keyRanges, err := key.ParseShardingSpec(spec)
+	require.NoError(t, err)
+	shards := make([]*topo.ShardInfo, 0, len(keyRanges))
+	for _, kr := range keyRanges {
+		shards = append(shards, topo.NewShardInfo("ks", key.KeyRangeString(kr), &topodatapb.Shard{KeyRange: kr}, nil))
+	}
+	return shards
+}
+
+func TestResharderIsMerge(t *testing.T) {
+	tests := []struct {
+		sources, targets string
+		want             bool
+	}{
+		{sources: "-80-", targets: "-40-80-", want: false},
+		{sources: "-40-80-", targets: "-80-", want: true},
+		{sources: "-80-c0-", targets: "-80-", want: true},
+		{sources: "-80-", targets: "-40-c0-", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sources+" to "+tt.targets, func(t *testing.T) {
+			rs := &resharder{
+				sourceShards: newTestShards(t, tt.sources),
+				targetShards: newTestShards(t, tt.targets),
+			}
+			assert.Equal(t, tt.want, rs.isMerge())
+		})
+	}
+}
+
+func TestMergeTables(t *testing.T) {
+	vschema := &vschemapb.Keyspace{
+		Sharded: true,
+		Tables: map[string]*vschemapb.Table{
+			"by_id":     {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+			"by_user":   {ColumnVindexes: []*vschemapb.ColumnVindex{{Columns: []string{"user_id"}, Name: "hash"}}},
+			"by_name":   {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "user_id", Name: "hash"}}},
+			"sequenced": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "user_id", Name: "hash"}}, AutoIncrement: &vschemapb.AutoIncrement{Column: "id", Sequence: "seq"}},
+			"ref":       {Type: "reference"},
+			"view":      {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "user_id", Name: "hash"}}},
+		},
+	}
+	table := func(name string, pk []string, fields ...*querypb.Field) *tabletmanagerdatapb.TableDefinition {
+		return &tabletmanagerdatapb.TableDefinition{Name: name, Type: tmutils.TableBaseTable, PrimaryKeyColumns: pk, Fields: fields}
+	}
+	id := &querypb.Field{Name: "id", Type: querypb.Type_INT64}
+	name := &querypb.Field{Name: "name", Type: querypb.Type_VARCHAR}
+	tds := []*tabletmanagerdatapb.TableDefinition{
+		table("by_user", []string{"id"}, id),
+		table("by_id", []string{"id"}, id),
+		table("by_name", []string{"name"}, name),
+		table("sequenced", []string{"id"}, id),
+		table("ref", []string{"id"}, id),
+		table("unknown", []string{"id"}, id),
+		table("no_pk", nil, id),
+		{Name: "view", Type: tmutils.TableView, PrimaryKeyColumns: []string{"id"}, Fields: []*querypb.Field{id}},
+	}
+
+	tables := mergeTables(vschema, tds)
+	assert.Equal(t, []*mergeTable{{name: "by_user", column: "id", vindexColumns: []string{"user_id"}}}, tables)
+}
+
+func TestMergeConflicts(t *testing.T) {
+	tables := []*mergeTable{{name: "t1", column: "id", vindexColumns: []string{"user_id"}}}
+	sources := []string{"-40", "40-80"}
+	tests := []struct {
+		name     string
+		settings map[string]autoIncrementSettings
+		ranges   map[string]valueRange
+		want     []string
+	}{
+		{
+			name:   "disjoint",
+			ranges: map[string]valueRange{"-40": {1, 100}, "40-80": {101, 200}},
+		},
+		{
+			name:   "single shard with rows",
+			ranges: map[string]valueRange{"-40": {1, 100}},
+		},
+		{
+			name:   "overlapping",
+			ranges: map[string]valueRange{"-40": {1, 100}, "40-80": {50, 200}},
+			want: []string{
+				"table t1: the values of id on shards -40 [1, 100] and 40-80 [50, 200] merged into shard -80 overlap, and its primary key does not include the columns of its primary vindex (user_id)",
+			},
+		},
+		{
+			name:     "interleaved",
+			settings: map[string]autoIncrementSettings{"-40": {2, 1}, "40-80": {2, 2}},
+			ranges:   map[string]valueRange{"-40": {1, 99}, "40-80": {2, 100}},
+		},
+		{
+			name:     "same offset",
+			settings: map[string]autoIncrementSettings{"-40": {2, 1}, "40-80": {2, 3}},
+			ranges:   map[string]valueRange{"-40": {1, 99}, "40-80": {3, 101}},
+			want: []string{
+				"table t1: the values of id on shards -40 [1, 99] and 40-80 [3, 101] merged into shard -80 overlap, and its primary key does not include the columns of its primary vindex (user_id)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := mergeConflicts("-80", sources, tables, tt.settings, map[string]map[string]valueRange{"t1": tt.ranges})
+			assert.Equal(t, tt.want, conflicts)
+		})
+	}
+}
@@ -562,6 +562,10 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 					ComponentThrottled: rstream.ComponentThrottled,
 					TimeThrottled:      rstream.TimeThrottled,
 				},
+				Metrics: rstream.Metrics,
+			}
+			if rstream.State == binlogdatapb.VReplicationWorkflowState_Error {
+				stream.ErrorClass, _ = binlogplayer.TerminalErrorClass(rstream.Message)
 			}
 
 			// Merge in copy states, which we've already fetched.
@@ -1011,28 +1015,40 @@ func (s *Server) getWorkflowState(ctx context.Context, targetKeyspace, workflowN
 				}
 			}
 		} else {
-			state.RdonlyCellsSwitched, state.RdonlyCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, targetKeyspace, table, topodatapb.TabletType_RDONLY)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			state.ReplicaCellsSwitched, state.ReplicaCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, targetKeyspace, table, topodatapb.TabletType_REPLICA)
-			if err != nil {
-				return nil, nil, err
-			}
 			globalRules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
 			if err != nil {
 				return nil, nil, err
 			}
+			var tablesNotSwitched []string
 			for _, table := range ts.Tables() {
 				rr := globalRules[table]
 				// If a rule exists for the table and points to the target keyspace, then
-				// writes have been switched.
+				// writes have been switched for the table.
 				if len(rr) > 0 && rr[0] == fmt.Sprintf("%s.%s", targetKeyspace, table) {
-					state.WritesSwitched = true
-					break
+					state.TablesSwitched = append(state.TablesSwitched, table)
+				} else {
+					tablesNotSwitched = append(tablesNotSwitched, table)
 				}
 			}
+			if len(tablesNotSwitched) == 0 {
+				state.WritesSwitched = true
+				state.TablesSwitched = nil
+			} else {
+				// The reads of the tables switched individually are switched along
+				// with their writes, so use a table which is not switched yet.
+				table = tablesNotSwitched[0]
+			}
+			ts.tablesSwitched = state.TablesSwitched
+
+			state.RdonlyCellsSwitched, state.RdonlyCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, targetKeyspace, table, topodatapb.TabletType_RDONLY)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			state.ReplicaCellsSwitched, state.ReplicaCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, targetKeyspace, table, topodatapb.TabletType_REPLICA)
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 	} else {
 		state.WorkflowType = TypeReshard
@@ -1340,6 +1356,10 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no vschema found for target keyspace %s", targetKeyspace)
 	}
 
+	if _, err := discovery.ParseTabletPickerPreferences(req.GetWorkflowOptions().GetTabletPickerPreferences()); err != nil {
+		return nil, err
+	}
+
 	if workflowType == binlogdatapb.VReplicationWorkflowType_MoveTables &&
 		req.GetWorkflowOptions().GetTenantId() != "" {
 		multiTenantSpec := vschema.MultiTenantSpec
@@ -1718,6 +1738,7 @@ func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCrea
 	span.Annotate("cells", req.Cells)
 	span.Annotate("tablet_types", req.TabletTypes)
 	span.Annotate("on_ddl", req.OnDdl)
+	span.Annotate("skip_merge_validation", req.SkipMergeValidation)
 
 	keyspace := req.Keyspace
 	cells := req.Cells
@@ -1736,6 +1757,11 @@ func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCrea
 	rs.onDDL = req.OnDdl
 	rs.stopAfterCopy = req.StopAfterCopy
 	rs.deferSecondaryKeys = req.DeferSecondaryKeys
+	if rs.isMerge() && !req.SkipMergeValidation {
+		if err := rs.validateMerge(ctx); err != nil {
+			return nil, vterrors.Wrap(err, "validateMerge")
+		}
+	}
 	if !req.SkipSchemaCopy {
 		if err := rs.copySchema(ctx); err != nil {
 			return nil, vterrors.Wrap(err, "copySchema")
@@ -2016,7 +2042,7 @@ func (s *Server) WorkflowDelete(ctx context.Context, req *vtctldatapb.WorkflowDe
 
 	if ts.workflowType != binlogdatapb.VReplicationWorkflowType_CreateLookupIndex {
 		// Return an error if the workflow traffic is partially switched.
-		if state.WritesSwitched || len(state.ReplicaCellsSwitched) > 0 || len(state.RdonlyCellsSwitched) > 0 {
+		if state.WritesSwitched || len(state.TablesSwitched) > 0 || len(state.ReplicaCellsSwitched) > 0 || len(state.RdonlyCellsSwitched) > 0 {
 			return nil, ErrWorkflowPartiallySwitched
 		}
 	}
@@ -2172,6 +2198,8 @@ func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowSt
 			ts.Position = st.Position
 			ts.Status = st.State
 			ts.Info = strings.Join(info, "; ")
+			ts.Metrics = st.Metrics
+			ts.ErrorClass = st.ErrorClass
 			resp.ShardStreams[ksShard].Streams[i] = ts
 		}
 	}
@@ -3058,6 +3086,15 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 		maxReplicationLagAllowed = defaultDuration
 	}
 	direction := TrafficSwitchDirection(req.Direction)
+	if direction == DirectionBackward && len(req.Tables) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot reverse traffic for individual tables")
+	}
+	if direction == DirectionBackward && len(startState.TablesSwitched) > 0 {
+		// The traffic of the tables switched individually can only be reversed
+		// once the traffic of the whole workflow is switched.
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot reverse traffic for workflow %s as traffic was switched individually for tables %s, switch traffic for all the tables first",
+			startState.Workflow, strings.Join(startState.TablesSwitched, ","))
+	}
 	if direction == DirectionBackward {
 		ts, startState, err = s.getWorkflowState(ctx, startState.SourceKeyspace, ts.reverseWorkflow)
 		if err != nil {
@@ -3081,22 +3118,29 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 	if err != nil {
 		return nil, err
 	}
-	if hasReplica || hasRdonly {
-		// If we're going to switch writes immediately after then we don't need to
-		// rebuild the SrvVSchema here as we will do it after switching writes.
-		if rdDryRunResults, err = s.switchReads(ctx, req, ts, startState, !hasPrimary /* rebuildSrvVSchema */, direction); err != nil {
+	if len(req.Tables) > 0 {
+		if wrDryRunResults, err = s.switchTablesTraffic(ctx, req, ts, startState, hasPrimary, timeout); err != nil {
 			return nil, err
 		}
-		log.Infof("Switch Reads done for workflow %s.%s", req.Keyspace, req.Workflow)
-	}
-	if rdDryRunResults != nil {
-		dryRunResults = append(dryRunResults, *rdDryRunResults...)
-	}
-	if hasPrimary {
-		if _, wrDryRunResults, err = s.switchWrites(ctx, req, ts, timeout, false); err != nil {
-			return nil, err
+		log.Infof("Switch Traffic done for tables %s of workflow %s.%s", strings.Join(req.Tables, ","), req.Keyspace, req.Workflow)
+	} else {
+		if hasReplica || hasRdonly {
+			// If we're going to switch writes immediately after then we don't need to
+			// rebuild the SrvVSchema here as we will do it after switching writes.
+			if rdDryRunResults, err = s.switchReads(ctx, req, ts, startState, !hasPrimary /* rebuildSrvVSchema */, direction); err != nil {
+				return nil, err
+			}
+			log.Infof("Switch Reads done for workflow %s.%s", req.Keyspace, req.Workflow)
+		}
+		if rdDryRunResults != nil {
+			dryRunResults = append(dryRunResults, *rdDryRunResults...)
+		}
+		if hasPrimary {
+			if _, wrDryRunResults, err = s.switchWrites(ctx, req, ts, timeout, false); err != nil {
+				return nil, err
+			}
+			log.Infof("Switch Writes done for workflow %s.%s", req.Keyspace, req.Workflow)
 		}
-		log.Infof("Switch Writes done for workflow %s.%s", req.Keyspace, req.Workflow)
 	}
 
 	if wrDryRunResults != nil {
@@ -3277,6 +3321,124 @@ func (s *Server) switchReads(ctx context.Context, req *vtctldatapb.WorkflowSwitc
 	return sw.logs(), nil
 }
 
+// switchTablesTraffic switches the reads and the writes of a subset of the
+// tables of a MoveTables workflow, so that large migrations can be cut over
+// incrementally. The streams keep running for the other tables, and the
+// writes of the switched tables are replicated back to the source by streams
+// of the reverse workflow which are created for them.
+func (s *Server) switchTablesTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest, ts *trafficSwitcher, state *State,
+	hasPrimary bool, timeout time.Duration,
+) (dryRunResults *[]string, err error) {
+	// Consistently handle errors by logging and returning them.
+	handleError := func(message string, err error) (*[]string, error) {
+		werr := vterrors.Wrapf(err, message)
+		ts.Logger().Error(werr)
+		return nil, werr
+	}
+
+	if ts.MigrationType() != binlogdatapb.MigrationType_TABLES || ts.isPartialMigration || ts.IsMultiTenantMigration() {
+		return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "traffic can only be switched for individual tables in MoveTables workflows which are neither partial nor multi-tenant"))
+	}
+	if !hasPrimary || len(req.Cells) > 0 {
+		return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the reads and writes of individual tables are switched together in all cells, so the tablet types must include PRIMARY and no cells can be specified"))
+	}
+	if state.WritesSwitched {
+		return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "writes have already been switched for all the tables of workflow %s", ts.WorkflowName()))
+	}
+	tables := slices.Clone(req.Tables)
+	slices.Sort(tables)
+	tables = slices.Compact(tables)
+	for _, table := range tables {
+		if !slices.Contains(ts.Tables(), table) {
+			return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s is not part of workflow %s", table, ts.WorkflowName()))
+		}
+		if slices.Contains(state.TablesSwitched, table) {
+			return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "traffic has already been switched for table %s", table))
+		}
+	}
+	if len(tables)+len(state.TablesSwitched) == len(ts.Tables()) {
+		// Switching the last tables completes the cutover, which also freezes the
+		// workflow and sets up the reverse replication.
+		return handleError("invalid request", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "tables %s are the last ones whose traffic is not switched, switch traffic for the whole workflow instead",
+			strings.Join(tables, ",")))
+	}
+
+	var sw iswitcher
+	if req.DryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+
+	if err := ts.validate(ctx); err != nil {
+		return handleError("workflow validation failed", err)
+	}
+
+	// Need to lock both source and target keyspaces.
+	tctx, sourceUnlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "SwitchTraffic")
+	if lockErr != nil {
+		return handleError(fmt.Sprintf("failed to lock the %s keyspace", ts.SourceKeyspaceName()), lockErr)
+	}
+	ctx = tctx
+	defer sourceUnlock(&err)
+	if ts.TargetKeyspaceName() != ts.SourceKeyspaceName() {
+		tctx, targetUnlock, lockErr := sw.lockKeyspace(ctx, ts.TargetKeyspaceName(), "SwitchTraffic")
+		if lockErr != nil {
+			return handleError(fmt.Sprintf("failed to lock the %s keyspace", ts.TargetKeyspaceName()), lockErr)
+		}
+		ctx = tctx
+		defer targetUnlock(&err)
+	}
+
+	// From here on the traffic switcher only operates on the tables whose
+	// traffic is switched.
+	ts.tables = tables
+
+	ts.Logger().Infof("Stopping source writes for tables %s", strings.Join(tables, ","))
+	if err := sw.stopSourceWrites(ctx); err != nil {
+		sw.cancelTablesSwitch(ctx)
+		return handleError(fmt.Sprintf("failed to stop writes in the %s keyspace", ts.SourceKeyspaceName()), err)
+	}
+
+	ts.Logger().Infof("Executing LOCK TABLES on source tables %d times", lockTablesCycles)
+	// Doing this twice with a pause in-between to catch any writes that may have raced in between
+	// the tablet's deny list check and the first mysqld side table lock.
+	for cnt := 1; cnt <= lockTablesCycles; cnt++ {
+		if err := ts.executeLockTablesOnSource(ctx); err != nil {
+			sw.cancelTablesSwitch(ctx)
+			return handleError(fmt.Sprintf("failed to execute LOCK TABLES (attempt %d of %d) on sources", cnt, lockTablesCycles), err)
+		}
+		time.Sleep(lockTablesCycleDelay)
+	}
+
+	ts.Logger().Infof("Waiting for streams to catchup")
+	if err := sw.waitForTablesCatchup(ctx, timeout); err != nil {
+		sw.cancelTablesSwitch(ctx)
+		return handleError("failed to sync up replication between the source and target", err)
+	}
+
+	if err := sw.switchTableReads(ctx, nil, []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY}, false, DirectionForward); err != nil {
+		sw.cancelTablesSwitch(ctx)
+		return handleError("failed to switch read traffic for the tables", err)
+	}
+
+	ts.Logger().Infof("Creating reverse streams for tables %s", strings.Join(tables, ","))
+	if err := sw.createReverseTablesVReplication(ctx); err != nil {
+		sw.cancelTablesSwitch(ctx)
+		return handleError("failed to create the reverse vreplication streams", err)
+	}
+	if err := sw.changeRouting(ctx); err != nil {
+		return handleError("failed to update the routing rules", err)
+	}
+	if req.EnableReverseReplication {
+		if err := sw.startReverseVReplication(ctx); err != nil {
+			return handleError("failed to start the reverse workflow", err)
+		}
+	}
+
+	return sw.logs(), nil
+}
+
 // switchWrites is a generic way of migrating write traffic for a workflow.
 func (s *Server) switchWrites(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest, ts *trafficSwitcher, timeout time.Duration,
 	cancel bool,
@@ -34,6 +34,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
@@ -610,6 +611,175 @@ func TestMoveTablesTrafficSwitching(t *testing.T) {
 	}
 }
 
+func TestMoveTablesTrafficSwitchingTables(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	workflowName := "wf1"
+	table1Name := "t1"
+	table2Name := "t2"
+	sourceKeyspace := &testKeyspace{
+		KeyspaceName: "sourceks",
+		ShardNames:   []string{"0"},
+	}
+	targetKeyspace := &testKeyspace{
+		KeyspaceName: "targetks",
+		ShardNames:   []string{"-80", "80-"},
+	}
+	vrID := 1
+	tabletTypes := []topodatapb.TabletType{
+		topodatapb.TabletType_PRIMARY,
+		topodatapb.TabletType_REPLICA,
+		topodatapb.TabletType_RDONLY,
+	}
+	schema := map[string]*tabletmanagerdatapb.SchemaDefinition{}
+	for _, tableName := range []string{table1Name, table2Name} {
+		schema[tableName] = &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:   tableName,
+					Schema: fmt.Sprintf("CREATE TABLE %s (id BIGINT, name VARCHAR(64), PRIMARY KEY (id))", tableName),
+				},
+			},
+		}
+	}
+	copyTableQR := &queryResult{
+		query: fmt.Sprintf("select vrepl_id, table_name, lastpk from _vt.copy_state where vrepl_id in (%d) and id in (select max(id) from _vt.copy_state where vrepl_id in (%d) group by vrepl_id, table_name)",
+			vrID, vrID),
+		result: &querypb.QueryResult{},
+	}
+	lockTableQR := &queryResult{
+		query:  fmt.Sprintf("LOCK TABLES `%s` READ", table1Name),
+		result: &querypb.QueryResult{},
+	}
+	// The forward streams stop replicating the switched table.
+	forwardSourceQR := &queryResult{
+		query: fmt.Sprintf(`/update _vt\.vreplication set source = '.*rules:\s*{match:\s*"%s"\s+filter:\s*"exclude"}\s*rules:\s*{match:\s*"%s"\s+filter:\s*"select \* from %s"}.*' where id = %d`,
+			table1Name, table2Name, table2Name, vrID),
+		result: &querypb.QueryResult{},
+	}
+	// A reverse stream replicating only the switched table is created for each
+	// forward stream, and started.
+	reverseStreamQR := &queryResult{
+		query: fmt.Sprintf(`/insert into _vt\.vreplication .*'%s', 'keyspace:\s*"%s".*rules:\s*{match:\s*"%s"\s+filter:\s*"exclude"}\s*rules:\s*{match:\s*"%s"\s+filter:\s*"select \* from `+"`%s`"+`"}.*'Stopped'`,
+			ReverseWorkflowName(workflowName), targetKeyspace.KeyspaceName, table2Name, table1Name, table1Name),
+		result: &querypb.QueryResult{},
+	}
+	startReverseQR := &queryResult{
+		query: fmt.Sprintf("update _vt.vreplication set state='Running', message='' where db_name='vt_%s' and workflow='%s'",
+			sourceKeyspace.KeyspaceName, ReverseWorkflowName(workflowName)),
+		result: &querypb.QueryResult{},
+	}
+
+	env := newTestEnv(t, ctx, defaultCellName, sourceKeyspace, targetKeyspace)
+	defer env.close()
+	env.tmc.schema = schema
+	env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspace.KeyspaceName, copyTableQR)
+	for i := 0; i < lockTablesCycles; i++ {
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspace.KeyspaceName, lockTableQR)
+	}
+	env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspace.KeyspaceName, forwardSourceQR)
+	for range targetKeyspace.ShardNames {
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspace.KeyspaceName, reverseStreamQR)
+	}
+	env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspace.KeyspaceName, startReverseQR)
+
+	got, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:                 targetKeyspace.KeyspaceName,
+		Workflow:                 workflowName,
+		Direction:                int32(DirectionForward),
+		TabletTypes:              tabletTypes,
+		Tables:                   []string{table1Name},
+		EnableReverseReplication: true,
+	})
+	require.NoError(t, err)
+	for _, tablet := range env.tablets[sourceKeyspace.KeyspaceName] {
+		require.Empty(t, env.tmc.vrQueries[int(tablet.Alias.Uid)], "unexpected queries left on tablet %d", tablet.Alias.Uid)
+	}
+	for _, tablet := range env.tablets[targetKeyspace.KeyspaceName] {
+		require.Empty(t, env.tmc.vrQueries[int(tablet.Alias.Uid)], "unexpected queries left on tablet %d", tablet.Alias.Uid)
+	}
+	require.Equal(t, "Reads Not Switched. Writes Not Switched", got.StartState)
+	require.Equal(t, fmt.Sprintf("Reads Not Switched. Writes partially switched, for tables: %s", table1Name), got.CurrentState)
+
+	// Only the reads and writes of the switched table are routed to the target.
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	to := []string{fmt.Sprintf("%s.%s", targetKeyspace.KeyspaceName, table1Name)}
+	for _, from := range []string{table1Name, table1Name + "@replica", table1Name + "@rdonly",
+		fmt.Sprintf("%s.%s", sourceKeyspace.KeyspaceName, table1Name)} {
+		require.Equal(t, to, rules[from], "routing rule for %s", from)
+	}
+	for from := range rules {
+		require.False(t, strings.HasPrefix(from, table2Name) || strings.Contains(from, "."+table2Name), "unexpected routing rule for %s", from)
+	}
+	// Only the writes of the switched table are denied on the source.
+	si, err := env.ts.GetShard(ctx, sourceKeyspace.KeyspaceName, "0")
+	require.NoError(t, err)
+	require.Equal(t, []string{table1Name}, si.GetTabletControl(topodatapb.TabletType_PRIMARY).GetDeniedTables())
+	for _, shardName := range targetKeyspace.ShardNames {
+		si, err := env.ts.GetShard(ctx, targetKeyspace.KeyspaceName, shardName)
+		require.NoError(t, err)
+		require.Empty(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY).GetDeniedTables())
+	}
+
+	testcases := []struct {
+		name    string
+		req     *vtctldatapb.WorkflowSwitchTrafficRequest
+		wantErr string
+	}{
+		{
+			name: "already switched",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				TabletTypes: tabletTypes,
+				Tables:      []string{table1Name},
+			},
+			wantErr: fmt.Sprintf("traffic has already been switched for table %s", table1Name),
+		},
+		{
+			name: "last table",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				TabletTypes: tabletTypes,
+				Tables:      []string{table2Name},
+			},
+			wantErr: fmt.Sprintf("tables %s are the last ones whose traffic is not switched", table2Name),
+		},
+		{
+			name: "unknown table",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				TabletTypes: tabletTypes,
+				Tables:      []string{"t3"},
+			},
+			wantErr: fmt.Sprintf("table t3 is not part of workflow %s", workflowName),
+		},
+		{
+			name: "reads only",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+				Tables:      []string{table2Name},
+			},
+			wantErr: "the tablet types must include PRIMARY",
+		},
+		{
+			name: "reverse",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				Direction:   int32(DirectionBackward),
+				TabletTypes: tabletTypes,
+			},
+			wantErr: fmt.Sprintf("traffic was switched individually for tables %s", table1Name),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Keyspace = targetKeyspace.KeyspaceName
+			tc.req.Workflow = workflowName
+			env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspace.KeyspaceName, copyTableQR)
+			_, err := env.ws.WorkflowSwitchTraffic(ctx, tc.req)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
 func TestMoveTablesTrafficSwitchingDryRun(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
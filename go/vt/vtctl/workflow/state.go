@@ -74,6 +74,11 @@ type State struct {
 	IsPartialMigration    bool
 	ShardsAlreadySwitched []string
 	ShardsNotYetSwitched  []string
+
+	// TablesSwitched are the tables of a MoveTables workflow whose reads and
+	// writes were switched individually, while the writes of the other tables
+	// are not switched yet.
+	TablesSwitched []string
 }
 
 func (s *State) String() string {
@@ -119,6 +124,8 @@ func (s *State) String() string {
 				stateInfo = append(stateInfo, "All Writes Switched")
 			}
 		}
+	} else if len(s.TablesSwitched) > 0 {
+		stateInfo = append(stateInfo, fmt.Sprintf("Writes partially switched, for tables: %s", strings.Join(s.TablesSwitched, ",")))
 	} else {
 		stateInfo = append(stateInfo, "Writes Not Switched")
 	}
@@ -114,6 +114,10 @@ func (r *switcher) waitForCatchup(ctx context.Context, filteredReplicationWaitTi
 	return r.ts.waitForCatchup(ctx, filteredReplicationWaitTime)
 }
 
+func (r *switcher) waitForTablesCatchup(ctx context.Context, filteredReplicationWaitTime time.Duration) error {
+	return r.ts.waitForTablesCatchup(ctx, filteredReplicationWaitTime)
+}
+
 func (r *switcher) stopSourceWrites(ctx context.Context) error {
 	return r.ts.stopSourceWrites(ctx)
 }
@@ -126,6 +130,14 @@ func (r *switcher) cancelMigration(ctx context.Context, sm *StreamMigrator) {
 	r.ts.cancelMigration(ctx, sm)
 }
 
+func (r *switcher) cancelTablesSwitch(ctx context.Context) {
+	r.ts.cancelTablesSwitch(ctx)
+}
+
+func (r *switcher) createReverseTablesVReplication(ctx context.Context) error {
+	return r.ts.createReverseTablesVReplication(ctx)
+}
+
 func (r *switcher) lockKeyspace(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
 	return r.s.ts.LockKeyspace(ctx, keyspace, action)
 }
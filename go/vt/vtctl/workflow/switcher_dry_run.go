@@ -196,6 +196,12 @@ func (dr *switcherDryRun) createReverseVReplication(ctx context.Context) error {
 	return nil
 }
 
+func (dr *switcherDryRun) createReverseTablesVReplication(ctx context.Context) error {
+	sort.Strings(dr.ts.Tables()) // For deterministic output
+	dr.drLog.Logf("Create reverse vreplication streams in workflow %s for tables [%s]", dr.ts.ReverseWorkflowName(), strings.Join(dr.ts.Tables(), ","))
+	return nil
+}
+
 func (dr *switcherDryRun) migrateStreams(ctx context.Context, sm *StreamMigrator) error {
 	templates := sm.Templates()
 
@@ -248,6 +254,11 @@ func (dr *switcherDryRun) waitForCatchup(ctx context.Context, filteredReplicatio
 	return nil
 }
 
+func (dr *switcherDryRun) waitForTablesCatchup(ctx context.Context, filteredReplicationWaitTime time.Duration) error {
+	dr.drLog.Logf("Wait for vreplication on running streams to catchup for up to %v", filteredReplicationWaitTime)
+	return nil
+}
+
 func (dr *switcherDryRun) stopSourceWrites(ctx context.Context) error {
 	logs := make([]string, 0)
 	sources := maps.Values(dr.ts.Sources())
@@ -293,6 +304,11 @@ func (dr *switcherDryRun) cancelMigration(ctx context.Context, sm *StreamMigrato
 	dr.drLog.Log("Cancel migration as requested")
 }
 
+func (dr *switcherDryRun) cancelTablesSwitch(ctx context.Context) {
+	sort.Strings(dr.ts.Tables()) // For deterministic output
+	dr.drLog.Logf("Cancel traffic switch for tables [%s]", strings.Join(dr.ts.Tables(), ","))
+}
+
 func (dr *switcherDryRun) lockKeyspace(ctx context.Context, keyspace, _ string) (context.Context, func(*error), error) {
 	dr.drLog.Logf("Lock keyspace %s", keyspace)
 	return ctx, func(e *error) {
@@ -29,8 +29,11 @@ type iswitcher interface {
 	stopStreams(ctx context.Context, sm *StreamMigrator) ([]string, error)
 	stopSourceWrites(ctx context.Context) error
 	waitForCatchup(ctx context.Context, filteredReplicationWaitTime time.Duration) error
+	waitForTablesCatchup(ctx context.Context, filteredReplicationWaitTime time.Duration) error
+	cancelTablesSwitch(ctx context.Context)
 	migrateStreams(ctx context.Context, sm *StreamMigrator) error
 	createReverseVReplication(ctx context.Context) error
+	createReverseTablesVReplication(ctx context.Context) error
 	createJournals(ctx context.Context, sourceWorkflows []string) error
 	allowTargetWrites(ctx context.Context) error
 	changeRouting(ctx context.Context) error
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -28,9 +29,12 @@ import (
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/json2"
 	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
@@ -235,6 +239,7 @@ type trafficSwitcher struct {
 	sourceKeyspace   string
 	targetKeyspace   string
 	tables           []string
+	tablesSwitched   []string // tables whose traffic was switched individually
 	keepRoutingRules bool
 	sourceKSSchema   *vindexes.KeyspaceSchema
 	optCells         string // cells option passed to MoveTables/Reshard Create
@@ -827,6 +832,18 @@ func (ts *trafficSwitcher) getReverseVReplicationUpdateQuery(targetCell string,
 
 func (ts *trafficSwitcher) deleteReverseVReplication(ctx context.Context) error {
 	return ts.ForAllSources(func(source *MigrationSource) error {
+		if len(ts.tablesSwitched) > 0 {
+			// The reverse streams of the tables whose traffic was switched
+			// individually are already replicating their writes back to the
+			// source, so they are kept.
+			if err := ts.deleteReverseStreams(ctx, source, func(bls *binlogdatapb.BinlogSource) bool {
+				return !replicatesOnly(bls, ts.tablesSwitched)
+			}); err != nil {
+				return err
+			}
+			ts.ws.optimizeCopyStateTable(source.GetPrimary().Tablet)
+			return nil
+		}
 		query := fmt.Sprintf(sqlDeleteWorkflow, encodeString(source.GetPrimary().DbName()), encodeString(ts.reverseWorkflow))
 		if _, err := ts.TabletManagerClient().VReplicationExec(ctx, source.GetPrimary().Tablet, query); err != nil {
 			// vreplication.exec returns no error on delete if the rows do not exist.
@@ -838,6 +855,48 @@ func (ts *trafficSwitcher) deleteReverseVReplication(ctx context.Context) error
 	})
 }
 
+// deleteReverseStreams deletes the streams of the reverse workflow on the
+// source primary for which the given function returns true.
+func (ts *trafficSwitcher) deleteReverseStreams(ctx context.Context, source *MigrationSource, shouldDelete func(bls *binlogdatapb.BinlogSource) bool) error {
+	query := fmt.Sprintf("select id, source from _vt.vreplication where db_name = %s and workflow = %s",
+		encodeString(source.GetPrimary().DbName()), encodeString(ts.reverseWorkflow))
+	p3qr, err := ts.TabletManagerClient().VReplicationExec(ctx, source.GetPrimary().Tablet, query)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, row := range sqltypes.Proto3ToResult(p3qr).Rows {
+		blsBytes, err := row[1].ToBytes()
+		if err != nil {
+			return err
+		}
+		bls := &binlogdatapb.BinlogSource{}
+		if err := prototext.Unmarshal(blsBytes, bls); err != nil {
+			return err
+		}
+		if shouldDelete(bls) {
+			ids = append(ids, row[0].ToString())
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	query = fmt.Sprintf("delete from _vt.vreplication where id in (%s)", strings.Join(ids, ", "))
+	_, err = ts.TabletManagerClient().VReplicationExec(ctx, source.GetPrimary().Tablet, query)
+	return err
+}
+
+// replicatesOnly returns true if all the rows replicated by the stream belong
+// to the given tables.
+func replicatesOnly(bls *binlogdatapb.BinlogSource, tables []string) bool {
+	for _, rule := range bls.GetFilter().GetRules() {
+		if rule.Filter != "exclude" && !slices.Contains(tables, rule.Match) {
+			return false
+		}
+	}
+	return true
+}
+
 func (ts *trafficSwitcher) ForAllUIDs(f func(target *MigrationTarget, uid int32) error) error {
 	var wg sync.WaitGroup
 	allErrors := &concurrency.AllErrorRecorder{}
@@ -873,79 +932,95 @@ func (ts *trafficSwitcher) createReverseVReplication(ctx context.Context) error
 			SourceTimeZone: bls.TargetTimeZone,
 			TargetTimeZone: bls.SourceTimeZone,
 		}
-		var err error
 		for _, rule := range bls.Filter.Rules {
 			if rule.Filter == "exclude" {
 				reverseBls.Filter.Rules = append(reverseBls.Filter.Rules, rule)
 				continue
 			}
-			var filter string
-			if strings.HasPrefix(rule.Match, "/") {
-				if ts.SourceKeyspaceSchema().Keyspace.Sharded {
-					filter = key.KeyRangeString(source.GetShard().KeyRange)
-				}
-			} else {
-				var inKeyrange string
-				if ts.SourceKeyspaceSchema().Keyspace.Sharded {
-					vtable, ok := ts.SourceKeyspaceSchema().Tables[rule.Match]
-					if !ok {
-						return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "table %s not found in vschema", rule.Match)
-					}
-					// We currently assume the primary vindex is the best way to filter rows
-					// for the table, which may not always be true.
-					// TODO: handle more of these edge cases explicitly, e.g. sequence tables.
-					switch vtable.Type {
-					case vindexes.TypeReference:
-						// For reference tables there are no vindexes and thus no filter to apply.
-					default:
-						// For non-reference tables we return an error if there's no primary
-						// vindex as it's not clear what to do.
-						if len(vtable.ColumnVindexes) > 0 && len(vtable.ColumnVindexes[0].Columns) > 0 {
-							inKeyrange = fmt.Sprintf(" where in_keyrange(%s, '%s.%s', '%s')", sqlparser.String(vtable.ColumnVindexes[0].Columns[0]),
-								ts.SourceKeyspaceName(), vtable.ColumnVindexes[0].Name, key.KeyRangeString(source.GetShard().KeyRange))
-						} else {
-							return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no primary vindex found for the %s table in the %s keyspace",
-								vtable.Name.String(), ts.SourceKeyspaceName())
-						}
-					}
-				}
-				filter = fmt.Sprintf("select * from %s%s", sqlescape.EscapeID(rule.Match), inKeyrange)
-				if ts.IsMultiTenantMigration() {
-					filter, err = ts.addTenantFilter(ctx, filter)
-					if err != nil {
-						return err
-					}
-				}
+			reverseRule, err := ts.reverseRule(ctx, source, rule)
+			if err != nil {
+				return err
 			}
-			reverseBls.Filter.Rules = append(reverseBls.Filter.Rules, &binlogdatapb.Rule{
-				Match:  rule.Match,
-				Filter: filter,
-			})
-		}
-		log.Infof("Creating reverse workflow vreplication stream on tablet %s: workflow %s, startPos %s",
-			source.GetPrimary().GetAlias(), ts.ReverseWorkflowName(), target.Position)
-		_, err = ts.VReplicationExec(ctx, source.GetPrimary().GetAlias(),
-			binlogplayer.CreateVReplicationState(ts.ReverseWorkflowName(), reverseBls, target.Position,
-				binlogdatapb.VReplicationWorkflowState_Stopped, source.GetPrimary().DbName(), ts.workflowType, ts.workflowSubType))
-		if err != nil {
-			return err
+			reverseBls.Filter.Rules = append(reverseBls.Filter.Rules, reverseRule)
 		}
+		return ts.createReverseStream(ctx, source, target, reverseBls)
+	})
+	return err
+}
 
-		// if user has defined the cell/tablet_types parameters in the forward workflow, update the reverse workflow as well
-		optionsJSON, err := json.Marshal(ts.options)
-		if err != nil {
-			return err
+// createReverseStream creates a stopped stream of the reverse workflow on the
+// source primary, replicating from the target position.
+func (ts *trafficSwitcher) createReverseStream(ctx context.Context, source *MigrationSource, target *MigrationTarget, reverseBls *binlogdatapb.BinlogSource) error {
+	log.Infof("Creating reverse workflow vreplication stream on tablet %s: workflow %s, startPos %s",
+		source.GetPrimary().GetAlias(), ts.ReverseWorkflowName(), target.Position)
+	_, err := ts.VReplicationExec(ctx, source.GetPrimary().GetAlias(),
+		binlogplayer.CreateVReplicationState(ts.ReverseWorkflowName(), reverseBls, target.Position,
+			binlogdatapb.VReplicationWorkflowState_Stopped, source.GetPrimary().DbName(), ts.workflowType, ts.workflowSubType))
+	if err != nil {
+		return err
+	}
+
+	// if user has defined the cell/tablet_types parameters in the forward workflow, update the reverse workflow as well
+	optionsJSON, err := json.Marshal(ts.options)
+	if err != nil {
+		return err
+	}
+	updateQuery := ts.getReverseVReplicationUpdateQuery(target.GetPrimary().GetAlias().GetCell(),
+		source.GetPrimary().GetAlias().GetCell(), source.GetPrimary().DbName(), string(optionsJSON))
+	if updateQuery != "" {
+		log.Infof("Updating vreplication stream entry on %s with: %s", source.GetPrimary().GetAlias(), updateQuery)
+		_, err = ts.VReplicationExec(ctx, source.GetPrimary().GetAlias(), updateQuery)
+		return err
+	}
+	return nil
+}
+
+// reverseRule returns the rule replicating the rows matched by the given rule
+// of a forward stream from the target back to the source shard.
+func (ts *trafficSwitcher) reverseRule(ctx context.Context, source *MigrationSource, rule *binlogdatapb.Rule) (*binlogdatapb.Rule, error) {
+	var filter string
+	if strings.HasPrefix(rule.Match, "/") {
+		if ts.SourceKeyspaceSchema().Keyspace.Sharded {
+			filter = key.KeyRangeString(source.GetShard().KeyRange)
 		}
-		updateQuery := ts.getReverseVReplicationUpdateQuery(target.GetPrimary().GetAlias().GetCell(),
-			source.GetPrimary().GetAlias().GetCell(), source.GetPrimary().DbName(), string(optionsJSON))
-		if updateQuery != "" {
-			log.Infof("Updating vreplication stream entry on %s with: %s", source.GetPrimary().GetAlias(), updateQuery)
-			_, err = ts.VReplicationExec(ctx, source.GetPrimary().GetAlias(), updateQuery)
-			return err
+	} else {
+		var inKeyrange string
+		if ts.SourceKeyspaceSchema().Keyspace.Sharded {
+			vtable, ok := ts.SourceKeyspaceSchema().Tables[rule.Match]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "table %s not found in vschema", rule.Match)
+			}
+			// We currently assume the primary vindex is the best way to filter rows
+			// for the table, which may not always be true.
+			// TODO: handle more of these edge cases explicitly, e.g. sequence tables.
+			switch vtable.Type {
+			case vindexes.TypeReference:
+				// For reference tables there are no vindexes and thus no filter to apply.
+			default:
+				// For non-reference tables we return an error if there's no primary
+				// vindex as it's not clear what to do.
+				if len(vtable.ColumnVindexes) > 0 && len(vtable.ColumnVindexes[0].Columns) > 0 {
+					inKeyrange = fmt.Sprintf(" where in_keyrange(%s, '%s.%s', '%s')", sqlparser.String(vtable.ColumnVindexes[0].Columns[0]),
+						ts.SourceKeyspaceName(), vtable.ColumnVindexes[0].Name, key.KeyRangeString(source.GetShard().KeyRange))
+				} else {
+					return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no primary vindex found for the %s table in the %s keyspace",
+						vtable.Name.String(), ts.SourceKeyspaceName())
+				}
+			}
 		}
-		return nil
-	})
-	return err
+		filter = fmt.Sprintf("select * from %s%s", sqlescape.EscapeID(rule.Match), inKeyrange)
+		if ts.IsMultiTenantMigration() {
+			var err error
+			filter, err = ts.addTenantFilter(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &binlogdatapb.Rule{
+		Match:  rule.Match,
+		Filter: filter,
+	}, nil
 }
 
 func (ts *trafficSwitcher) addTenantFilter(ctx context.Context, filter string) (string, error) {
@@ -1010,6 +1085,117 @@ func (ts *trafficSwitcher) waitForCatchup(ctx context.Context, filteredReplicati
 	})
 }
 
+// waitForTablesCatchup waits for all the streams on the targets to catch up
+// with the source positions. Unlike waitForCatchup, the streams are not
+// stopped as they keep replicating the tables which are not switched.
+func (ts *trafficSwitcher) waitForTablesCatchup(ctx context.Context, filteredReplicationWaitTime time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, filteredReplicationWaitTime)
+	defer cancel()
+	return ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		source := ts.Sources()[target.Sources[uid].Shard]
+		ts.Logger().Infof("Waiting for keyspace:shard: %v:%v to reach source position %v, uid %d",
+			ts.TargetKeyspaceName(), target.GetShard().ShardName(), source.Position, uid)
+		return ts.TabletManagerClient().VReplicationWaitForPos(ctx, target.GetPrimary().Tablet, uid, source.Position)
+	})
+}
+
+// cancelTablesSwitch allows the writes on the source side again for the
+// tables whose traffic switch failed, and denies them on the target side.
+func (ts *trafficSwitcher) cancelTablesSwitch(ctx context.Context) {
+	if err := ts.changeDeniedTables(ctx, true); err != nil {
+		ts.Logger().Errorf("Cancel traffic switch for tables %s failed: %v", strings.Join(ts.Tables(), ","), err)
+	}
+}
+
+// createReverseTablesVReplication sets up the replication of the writes of
+// the tables whose traffic is switched back to the source. The forward streams
+// stop replicating these tables, and a reverse stream replicating them from the
+// current target position is added to the reverse workflow for each forward
+// stream. The other tables of the workflow are kept as exclude rules, so that
+// all the streams of a workflow keep the same table list.
+// The reverse streams are created in the stopped state.
+func (ts *trafficSwitcher) createReverseTablesVReplication(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			ts.cancelReverseTablesVReplication(ctx)
+		}
+	}()
+	if err := ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		bls := proto.Clone(target.Sources[uid]).(*binlogdatapb.BinlogSource)
+		for _, rule := range bls.Filter.Rules {
+			if slices.Contains(ts.Tables(), rule.Match) {
+				rule.Filter = "exclude"
+			}
+		}
+		return ts.updateForwardSource(ctx, target, uid, bls)
+	}); err != nil {
+		return err
+	}
+	// The forward streams no longer replicate the tables, record the target
+	// positions to start the reverse streams from.
+	if err := ts.ForAllTargets(func(target *MigrationTarget) error {
+		var err error
+		target.Position, err = ts.TabletManagerClient().PrimaryPosition(ctx, target.GetPrimary().Tablet)
+		return err
+	}); err != nil {
+		return err
+	}
+	return ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		bls := target.Sources[uid]
+		source := ts.Sources()[bls.Shard]
+		reverseBls := &binlogdatapb.BinlogSource{
+			Keyspace:       ts.TargetKeyspaceName(),
+			Shard:          target.GetShard().ShardName(),
+			TabletType:     bls.TabletType,
+			Filter:         &binlogdatapb.Filter{},
+			OnDdl:          bls.OnDdl,
+			SourceTimeZone: bls.TargetTimeZone,
+			TargetTimeZone: bls.SourceTimeZone,
+		}
+		for _, rule := range bls.Filter.Rules {
+			if rule.Filter == "exclude" || !slices.Contains(ts.Tables(), rule.Match) {
+				reverseBls.Filter.Rules = append(reverseBls.Filter.Rules, &binlogdatapb.Rule{
+					Match:  rule.Match,
+					Filter: "exclude",
+				})
+				continue
+			}
+			reverseRule, err := ts.reverseRule(ctx, source, rule)
+			if err != nil {
+				return err
+			}
+			reverseBls.Filter.Rules = append(reverseBls.Filter.Rules, reverseRule)
+		}
+		return ts.createReverseStream(ctx, source, target, reverseBls)
+	})
+}
+
+// cancelReverseTablesVReplication deletes the reverse streams of the tables
+// whose traffic switch failed, and replicates them again in the forward
+// streams.
+func (ts *trafficSwitcher) cancelReverseTablesVReplication(ctx context.Context) {
+	if err := ts.ForAllSources(func(source *MigrationSource) error {
+		return ts.deleteReverseStreams(ctx, source, func(bls *binlogdatapb.BinlogSource) bool {
+			return replicatesOnly(bls, ts.Tables())
+		})
+	}); err != nil {
+		ts.Logger().Errorf("Deleting the reverse streams of tables %s failed: %v", strings.Join(ts.Tables(), ","), err)
+	}
+	if err := ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		return ts.updateForwardSource(ctx, target, uid, target.Sources[uid])
+	}); err != nil {
+		ts.Logger().Errorf("Restoring the forward streams of tables %s failed: %v", strings.Join(ts.Tables(), ","), err)
+	}
+}
+
+// updateForwardSource updates the binlog source of a forward stream.
+func (ts *trafficSwitcher) updateForwardSource(ctx context.Context, target *MigrationTarget, uid int32, bls *binlogdatapb.BinlogSource) error {
+	protoutil.SortBinlogSourceTables(bls)
+	query := fmt.Sprintf("update _vt.vreplication set source = %s where id = %d", encodeString(bls.String()), uid)
+	_, err := ts.TabletManagerClient().VReplicationExec(ctx, target.GetPrimary().Tablet, query)
+	return err
+}
+
 func (ts *trafficSwitcher) stopSourceWrites(ctx context.Context) error {
 	var err error
 	if ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
@@ -1034,8 +1220,15 @@ func (ts *trafficSwitcher) stopSourceWrites(ctx context.Context) error {
 }
 
 // switchDeniedTables switches the denied tables rules for the traffic switch.
-// They are removed on the source side and added on the target side.
+// They are added on the source side and removed on the target side.
 func (ts *trafficSwitcher) switchDeniedTables(ctx context.Context) error {
+	return ts.changeDeniedTables(ctx, false)
+}
+
+// changeDeniedTables adds the denied tables rules on the source side and
+// removes them on the target side, or the other way around when reverting a
+// traffic switch.
+func (ts *trafficSwitcher) changeDeniedTables(ctx context.Context, revert bool) error {
 	if ts.MigrationType() != binlogdatapb.MigrationType_TABLES {
 		return nil
 	}
@@ -1044,7 +1237,7 @@ func (ts *trafficSwitcher) switchDeniedTables(ctx context.Context) error {
 	egrp.Go(func() error {
 		return ts.ForAllSources(func(source *MigrationSource) error {
 			if _, err := ts.TopoServer().UpdateShardFields(ctx, ts.SourceKeyspaceName(), source.GetShard().ShardName(), func(si *topo.ShardInfo) error {
-				return si.UpdateDeniedTables(ectx, topodatapb.TabletType_PRIMARY, nil, false, ts.Tables())
+				return si.UpdateDeniedTables(ectx, topodatapb.TabletType_PRIMARY, nil, revert, ts.Tables())
 			}); err != nil {
 				return err
 			}
@@ -1061,7 +1254,7 @@ func (ts *trafficSwitcher) switchDeniedTables(ctx context.Context) error {
 	egrp.Go(func() error {
 		return ts.ForAllTargets(func(target *MigrationTarget) error {
 			if _, err := ts.TopoServer().UpdateShardFields(ectx, ts.TargetKeyspaceName(), target.GetShard().ShardName(), func(si *topo.ShardInfo) error {
-				return si.UpdateDeniedTables(ctx, topodatapb.TabletType_PRIMARY, nil, true, ts.Tables())
+				return si.UpdateDeniedTables(ctx, topodatapb.TabletType_PRIMARY, nil, !revert, ts.Tables())
 			}); err != nil {
 				return err
 			}
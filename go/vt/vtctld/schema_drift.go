@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+var (
+	schemaDriftTablets = stats.NewGaugesWithMultiLabels(
+		"SchemaDriftTablets",
+		"Number of tablets whose schema drifted from the schema of the primary of the first shard of their keyspace",
+		[]string{"Keyspace", "Shard", "TabletType"})
+	schemaDriftTables = stats.NewGaugesWithSingleLabel(
+		"SchemaDriftTables",
+		"Number of tables whose definition drifted on at least one tablet of their keyspace",
+		"Keyspace")
+	schemaDriftCheckErrors = stats.NewCountersWithSingleLabel(
+		"SchemaDriftCheckErrors",
+		"Number of errors while checking the schema drift of a keyspace, including the tablets whose schema could not be read",
+		"Keyspace")
+)
+
+// CheckSchemaDrift checks the schema drift of all the keyspaces, and exports
+// it in the SchemaDriftTablets and SchemaDriftTables metrics. The details of
+// the drift are returned by the GetSchemaDrift RPC.
+func CheckSchemaDrift(ctx context.Context, ts *topo.Server, vtctld vtctlservicepb.VtctldServer) {
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Schema drift check failed to get the keyspaces: %v", err)
+		return
+	}
+
+	type tabletKey struct {
+		keyspace, shard, tabletType string
+	}
+	tablets := make(map[tabletKey]int64)
+	tables := make(map[string]int64, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		resp, err := vtctld.GetSchemaDrift(ctx, &vtctldatapb.GetSchemaDriftRequest{Keyspace: keyspace})
+		if err != nil {
+			log.Errorf("Schema drift check failed for keyspace %v: %v", keyspace, err)
+			schemaDriftCheckErrors.Add(keyspace, 1)
+			continue
+		}
+
+		driftedTables := make(map[string]bool)
+		for _, tablet := range resp.Tablets {
+			if tablet.Error != "" {
+				log.Warningf("Schema drift check failed to read the schema of %v: %v", topoproto.TabletAliasString(tablet.TabletAlias), tablet.Error)
+				schemaDriftCheckErrors.Add(keyspace, 1)
+			}
+			if len(tablet.Tables) == 0 {
+				continue
+			}
+			tablets[tabletKey{keyspace, tablet.Shard, topoproto.TabletTypeLString(tablet.TabletType)}]++
+			for _, table := range tablet.Tables {
+				driftedTables[table.Name] = true
+			}
+		}
+		tables[keyspace] = int64(len(driftedTables))
+		if len(driftedTables) > 0 {
+			log.Warningf("Schema drift in keyspace %v: %d tables differ from the schema of %v", keyspace, len(driftedTables), topoproto.TabletAliasString(resp.ReferenceTablet))
+		}
+	}
+
+	schemaDriftTablets.ResetAll()
+	for key, count := range tablets {
+		schemaDriftTablets.Set([]string{key.keyspace, key.shard, key.tabletType}, count)
+	}
+	schemaDriftTables.ResetAll()
+	for keyspace, count := range tables {
+		schemaDriftTables.Set(keyspace, count)
+	}
+}
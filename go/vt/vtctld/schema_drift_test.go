@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+type fakeSchemaDriftServer struct {
+	vtctlservicepb.UnimplementedVtctldServer
+	drifts map[string]*vtctldatapb.GetSchemaDriftResponse
+}
+
+func (s *fakeSchemaDriftServer) GetSchemaDrift(ctx context.Context, req *vtctldatapb.GetSchemaDriftRequest) (*vtctldatapb.GetSchemaDriftResponse, error) {
+	resp, ok := s.drifts[req.Keyspace]
+	if !ok {
+		return nil, errors.New("no primary")
+	}
+	return resp, nil
+}
+
+func TestCheckSchemaDrift(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	defer ts.Close()
+
+	for _, keyspace := range []string{"ks1", "ks2", "ks3"} {
+		require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	}
+
+	server := &fakeSchemaDriftServer{
+		drifts: map[string]*vtctldatapb.GetSchemaDriftResponse{
+			"ks1": {
+				ReferenceTablet: &topodatapb.TabletAlias{Cell: "cell1", Uid: 100},
+				Tablets: []*vtctldatapb.TabletSchemaDrift{
+					{
+						TabletAlias: &topodatapb.TabletAlias{Cell: "cell1", Uid: 200},
+						Shard:       "80-",
+						TabletType:  topodatapb.TabletType_PRIMARY,
+						Tables:      []*vtctldatapb.TableSchemaDrift{{Name: "t1"}, {Name: "t2"}},
+					},
+					{
+						TabletAlias: &topodatapb.TabletAlias{Cell: "cell1", Uid: 201},
+						Shard:       "80-",
+						TabletType:  topodatapb.TabletType_REPLICA,
+						Tables:      []*vtctldatapb.TableSchemaDrift{{Name: "t1"}},
+					},
+					{
+						TabletAlias: &topodatapb.TabletAlias{Cell: "cell1", Uid: 202},
+						Shard:       "80-",
+						TabletType:  topodatapb.TabletType_REPLICA,
+						Error:       "unreachable",
+					},
+				},
+			},
+			"ks2": {
+				ReferenceTablet: &topodatapb.TabletAlias{Cell: "cell1", Uid: 300},
+			},
+		},
+	}
+
+	schemaDriftCheckErrors.ResetAll()
+	CheckSchemaDrift(ctx, ts, server)
+
+	assert.Equal(t, map[string]int64{
+		"ks1.80-.primary": 1,
+		"ks1.80-.replica": 1,
+	}, schemaDriftTablets.Counts())
+	assert.Equal(t, map[string]int64{
+		"ks1": 2,
+		"ks2": 0,
+	}, schemaDriftTables.Counts())
+	assert.Equal(t, map[string]int64{
+		"ks1": 1,
+		"ks3": 1,
+	}, schemaDriftCheckErrors.Counts())
+
+	// The drift is gone once fixed.
+	server.drifts["ks1"].Tablets = nil
+	CheckSchemaDrift(ctx, ts, server)
+
+	assert.Empty(t, schemaDriftTablets.Counts())
+	assert.Equal(t, map[string]int64{
+		"ks1": 0,
+		"ks2": 0,
+	}, schemaDriftTables.Counts())
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"strings"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var (
+	srvVSchemaReconciledCells = stats.NewCountersWithSingleLabel(
+		"SrvVSchemaReconciledCells",
+		"Number of times the SrvVSchema of a cell diverged from the global VSchema with the cell's staged changes, and was rebuilt",
+		"Cell")
+	srvVSchemaReconcileErrors = stats.NewCounter(
+		"SrvVSchemaReconcileErrors",
+		"Number of errors while reconciling the SrvVSchema of the cells")
+)
+
+// ReconcileSrvVSchemas rebuilds the SrvVSchema of the cells that diverged from
+// the global VSchema with their staged changes applied, and counts them in the
+// SrvVSchemaReconciledCells metric. Staged changes are kept; they are only
+// promoted or reverted by the ReconcileSrvVSchemas RPC.
+func ReconcileSrvVSchemas(ctx context.Context, ts *topo.Server) {
+	cells, err := ts.ReconcileSrvVSchemas(ctx, nil)
+	if err != nil {
+		log.Errorf("SrvVSchema reconcile failed: %v", err)
+		srvVSchemaReconcileErrors.Add(1)
+		return
+	}
+	if len(cells) == 0 {
+		return
+	}
+
+	log.Warningf("Rebuilt the diverging SrvVSchema in cells %s", strings.Join(cells, ","))
+	for _, cell := range cells {
+		srvVSchemaReconciledCells.Add(cell, 1)
+	}
+}
@@ -111,6 +111,7 @@ var (
 	VT14003 = errorWithoutState("VT14003", vtrpcpb.Code_UNAVAILABLE, "no connection for tablet %v", "No connection for the given tablet.")
 	VT14004 = errorWithoutState("VT14004", vtrpcpb.Code_UNAVAILABLE, "cannot find keyspace for: %s", "The specified keyspace could not be found.")
 	VT14005 = errorWithoutState("VT14005", vtrpcpb.Code_UNAVAILABLE, "cannot lookup sidecar database for keyspace: %s", "Failed to read sidecar database identifier.")
+	VT14006 = errorWithoutState("VT14006", vtrpcpb.Code_UNAVAILABLE, "replication lag of %v exceeds the maximum staleness of %v of the query", "The tablet is lagging behind its primary more than the query accepts, as set by the MAX_STALENESS_MS query directive. The query can be retried on a fresher tablet.")
 
 	// Errors is a list of errors that must match all the variables
 	// defined above to enable auto-documentation of error codes.
@@ -191,6 +192,7 @@ var (
 		VT14003,
 		VT14004,
 		VT14005,
+		VT14006,
 	}
 )
 
@@ -0,0 +1,609 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// AdvisorOptions are the options of AdviseSharding.
+type AdvisorOptions struct {
+	// MaxLookupVindexes is the maximum number of lookup vindexes recommended
+	// per table.
+	MaxLookupVindexes int
+
+	// MinLookupRatio is the minimum ratio of the queries of a table which a
+	// lookup vindex must route to a single shard, besides the queries routed
+	// by the primary vindex and the previous lookup vindexes, to be
+	// recommended.
+	MinLookupRatio float64
+}
+
+// ShardingAdvice is the result of the analysis of the queries of a query log
+// by AdviseSharding.
+type ShardingAdvice struct {
+	// Queries is the number of executions of the analyzed queries.
+	Queries uint64
+
+	// SkippedQueries is the number of executions of the queries which could
+	// not be parsed.
+	SkippedQueries uint64
+
+	Tables []*TableShardingAdvice
+}
+
+// TableShardingAdvice reports the predicates of the queries of a table, and
+// recommends its vindexes.
+type TableShardingAdvice struct {
+	Table string
+
+	// Queries is the number of selects, updates and deletes of the table.
+	// Inserts are counted apart, since they are routed by the values of the
+	// vindex columns of their rows.
+	Queries uint64
+	Inserts uint64
+
+	// ScatterQueries is the number of queries of the table which were sent to
+	// several shards, according to the shard queries recorded in the query
+	// log. ScatterRatio is their ratio among the queries whose shard queries
+	// were recorded.
+	ScatterQueries uint64
+	ScatterRatio   float64
+
+	// Columns are the columns of the table used in predicates, by decreasing
+	// number of equality predicates.
+	Columns []*ColumnPredicates
+
+	// PrimaryVindex is nil when no query of the table filters on a column by
+	// equality.
+	PrimaryVindex  *VindexAdvice   `json:",omitempty"`
+	LookupVindexes []*VindexAdvice `json:",omitempty"`
+
+	// ProjectedSingleShardRatio is the ratio of the queries of the table which
+	// the recommended vindexes would route to a single shard.
+	ProjectedSingleShardRatio float64
+}
+
+// ColumnPredicates counts the queries using a column in predicates.
+type ColumnPredicates struct {
+	Column string
+
+	// Equality counts the = and IN comparisons with values.
+	Equality uint64
+	// Range counts the <, <=, >, >=, BETWEEN and LIKE comparisons with values.
+	Range uint64
+	// Join counts the equality comparisons with the columns of other tables.
+	Join uint64
+}
+
+// VindexAdvice is a recommended vindex.
+type VindexAdvice struct {
+	Column string
+	Type   string
+
+	// RoutedQueries is the number of queries of the table which the vindex
+	// routes to a single shard, and which are not routed by the previously
+	// recommended vindexes. RoutedRatio is their ratio among all the queries
+	// of the table.
+	RoutedQueries uint64
+	RoutedRatio   float64
+}
+
+// tableUsage accumulates the usage of a table by the analyzed queries.
+type tableUsage struct {
+	queries  uint64
+	inserts  uint64
+	observed uint64
+	scatter  uint64
+	columns  map[string]*ColumnPredicates
+
+	// filters counts the queries by the set of columns they filter on by
+	// equality, as a sorted list joined by NUL characters.
+	filters map[string]uint64
+}
+
+// tableSchema is what the advisor needs to know about a table schema.
+type tableSchema struct {
+	types  map[string]string
+	unique map[string]bool
+}
+
+type shardingAdvisor struct {
+	parser  *sqlparser.Parser
+	schemas map[string]*tableSchema
+	tables  map[string]*tableUsage
+	advice  *ShardingAdvice
+}
+
+// AdviseSharding analyzes the predicates of the queries of a query log, and
+// recommends the primary and lookup vindexes of their tables which would route
+// most queries to a single shard. The optional SQL schema sets the types of the
+// columns, which select the vindex types, and resolves the unqualified columns
+// of joins.
+func AdviseSharding(parser *sqlparser.Parser, entries []*QueryLogEntry, sqlSchema string, opts AdvisorOptions) (*ShardingAdvice, error) {
+	sa := &shardingAdvisor{
+		parser:  parser,
+		schemas: make(map[string]*tableSchema),
+		tables:  make(map[string]*tableUsage),
+		advice:  &ShardingAdvice{},
+	}
+	if sqlSchema != "" {
+		ddls, err := parseSchema(sqlSchema, &Options{}, parser)
+		if err != nil {
+			return nil, err
+		}
+		for _, ddl := range ddls {
+			if spec := ddl.GetTableSpec(); spec != nil {
+				sa.schemas[ddl.GetTable().Name.String()] = newTableSchema(spec)
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		sa.analyze(entry)
+	}
+
+	names := make([]string, 0, len(sa.tables))
+	for name := range sa.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sa.advice.Tables = append(sa.advice.Tables, sa.adviseTable(name, sa.tables[name], opts))
+	}
+	return sa.advice, nil
+}
+
+func newTableSchema(spec *sqlparser.TableSpec) *tableSchema {
+	ts := &tableSchema{
+		types:  make(map[string]string, len(spec.Columns)),
+		unique: make(map[string]bool),
+	}
+	for _, col := range spec.Columns {
+		name := col.Name.Lowered()
+		ts.types[name] = strings.ToLower(col.Type.Type)
+		if opts := col.Type.Options; opts != nil {
+			switch opts.KeyOpt {
+			case sqlparser.ColKeyPrimary, sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+				ts.unique[name] = true
+			}
+		}
+	}
+	for _, index := range spec.Indexes {
+		if index.Info.IsUnique() && len(index.Columns) == 1 && index.Columns[0].Expression == nil {
+			ts.unique[index.Columns[0].Column.Lowered()] = true
+		}
+	}
+	return ts
+}
+
+// analyze adds the usage of the tables by the query of entry.
+func (sa *shardingAdvisor) analyze(entry *QueryLogEntry) {
+	sa.advice.Queries += entry.Count
+	stmt, err := sa.parser.Parse(entry.SQL)
+	if err != nil {
+		sa.advice.SkippedQueries += entry.Count
+		return
+	}
+
+	// filters holds the columns filtered by equality of each table of the
+	// statement. A table used several times in the statement is routed to a
+	// single shard only by the columns filtered in all its uses.
+	filters := make(map[string]map[string]bool)
+	addFilters := func(table string, columns map[string]bool) {
+		previous, ok := filters[table]
+		if !ok {
+			filters[table] = columns
+			return
+		}
+		for col := range previous {
+			if !columns[col] {
+				delete(previous, col)
+			}
+		}
+	}
+
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert:
+		if name, ok := stmt.Table.Expr.(sqlparser.TableName); ok {
+			sa.usage(name.Name.String()).inserts += entry.Count
+		}
+		return
+	case *sqlparser.Select, *sqlparser.Union:
+	case *sqlparser.Update:
+		sa.analyzeScope(stmt.TableExprs, stmt.Where, entry.Count, addFilters)
+	case *sqlparser.Delete:
+		sa.analyzeScope(stmt.TableExprs, stmt.Where, entry.Count, addFilters)
+	default:
+		return
+	}
+	// The selects include the subqueries of all the statements.
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if sel, ok := node.(*sqlparser.Select); ok {
+			sa.analyzeScope(sel.From, sel.Where, entry.Count, addFilters)
+		}
+		return true, nil
+	}, stmt)
+
+	// The average number of shards per execution tells whether the query was
+	// scattered, when the query log records it.
+	observed := entry.ShardQueries > 0
+	scatter := entry.ShardQueries > entry.Count
+	for table, columns := range filters {
+		usage := sa.usage(table)
+		usage.queries += entry.Count
+		if observed {
+			usage.observed += entry.Count
+		}
+		if scatter {
+			usage.scatter += entry.Count
+		}
+		usage.filters[filterKey(columns)] += entry.Count
+	}
+}
+
+func (sa *shardingAdvisor) usage(table string) *tableUsage {
+	usage, ok := sa.tables[table]
+	if !ok {
+		usage = &tableUsage{
+			columns: make(map[string]*ColumnPredicates),
+			filters: make(map[string]uint64),
+		}
+		sa.tables[table] = usage
+	}
+	return usage
+}
+
+func (tu *tableUsage) column(name string) *ColumnPredicates {
+	col, ok := tu.columns[name]
+	if !ok {
+		col = &ColumnPredicates{Column: name}
+		tu.columns[name] = col
+	}
+	return col
+}
+
+// analyzeScope counts the predicates of the tables of a FROM clause in the
+// WHERE clause and join conditions, and reports the columns filtered by
+// equality of each table.
+func (sa *shardingAdvisor) analyzeScope(tableExprs []sqlparser.TableExpr, where *sqlparser.Where, count uint64, addFilters func(table string, columns map[string]bool)) {
+	// aliases maps the names and aliases of the tables to their names.
+	aliases := make(map[string]string)
+	var tables []string
+	var predicates []sqlparser.Expr
+	var walk func(sqlparser.TableExpr)
+	walk = func(expr sqlparser.TableExpr) {
+		switch expr := expr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			name, ok := expr.Expr.(sqlparser.TableName)
+			if !ok {
+				// Derived tables are analyzed as subqueries.
+				return
+			}
+			table := name.Name.String()
+			alias := table
+			if !expr.As.IsEmpty() {
+				alias = expr.As.String()
+			}
+			aliases[alias] = table
+			tables = append(tables, table)
+		case *sqlparser.JoinTableExpr:
+			walk(expr.LeftExpr)
+			walk(expr.RightExpr)
+			if expr.Condition != nil && expr.Condition.On != nil {
+				predicates = sqlparser.SplitAndExpression(predicates, expr.Condition.On)
+			}
+		case *sqlparser.ParenTableExpr:
+			for _, expr := range expr.Exprs {
+				walk(expr)
+			}
+		}
+	}
+	for _, expr := range tableExprs {
+		walk(expr)
+	}
+	if len(tables) == 0 {
+		return
+	}
+	if where != nil {
+		predicates = sqlparser.SplitAndExpression(predicates, where.Expr)
+	}
+
+	// resolve returns the table of a column of the scope.
+	resolve := func(col *sqlparser.ColName) (string, bool) {
+		if !col.Qualifier.IsEmpty() {
+			table, ok := aliases[col.Qualifier.Name.String()]
+			return table, ok
+		}
+		if len(tables) == 1 {
+			return tables[0], true
+		}
+		found := ""
+		for _, table := range tables {
+			if schema, ok := sa.schemas[table]; ok && schema.types[col.Name.Lowered()] != "" {
+				if found != "" && found != table {
+					return "", false
+				}
+				found = table
+			}
+		}
+		return found, found != ""
+	}
+
+	filters := make(map[string]map[string]bool, len(tables))
+	for _, table := range tables {
+		filters[table] = make(map[string]bool)
+	}
+	for _, predicate := range predicates {
+		col, kind := predicateColumn(predicate)
+		switch kind {
+		case predicateEquality, predicateRange:
+			table, ok := resolve(col)
+			if !ok {
+				continue
+			}
+			name := col.Name.Lowered()
+			if kind == predicateEquality {
+				sa.usage(table).column(name).Equality += count
+				filters[table][name] = true
+			} else {
+				sa.usage(table).column(name).Range += count
+			}
+		case predicateJoin:
+			cmp := predicate.(*sqlparser.ComparisonExpr)
+			for _, expr := range []sqlparser.Expr{cmp.Left, cmp.Right} {
+				col := expr.(*sqlparser.ColName)
+				if table, ok := resolve(col); ok {
+					sa.usage(table).column(col.Name.Lowered()).Join += count
+				}
+			}
+		}
+	}
+	for table, columns := range filters {
+		addFilters(table, columns)
+	}
+}
+
+type predicateKind int
+
+const (
+	predicateNone predicateKind = iota
+	predicateEquality
+	predicateRange
+	predicateJoin
+)
+
+// predicateColumn returns the kind of a predicate, and its column when it
+// compares a column with values.
+func predicateColumn(expr sqlparser.Expr) (*sqlparser.ColName, predicateKind) {
+	switch expr := expr.(type) {
+	case *sqlparser.ComparisonExpr:
+		left, leftIsCol := expr.Left.(*sqlparser.ColName)
+		right, rightIsCol := expr.Right.(*sqlparser.ColName)
+		switch expr.Operator {
+		case sqlparser.EqualOp, sqlparser.NullSafeEqualOp:
+			switch {
+			case leftIsCol && rightIsCol:
+				return nil, predicateJoin
+			case leftIsCol && sqlparser.IsValue(expr.Right):
+				return left, predicateEquality
+			case rightIsCol && sqlparser.IsValue(expr.Left):
+				return right, predicateEquality
+			}
+		case sqlparser.InOp:
+			if leftIsCol && sqlparser.IsSimpleTuple(expr.Right) {
+				return left, predicateEquality
+			}
+		case sqlparser.LessThanOp, sqlparser.LessEqualOp, sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp, sqlparser.LikeOp:
+			switch {
+			case leftIsCol && sqlparser.IsValue(expr.Right):
+				return left, predicateRange
+			case rightIsCol && sqlparser.IsValue(expr.Left):
+				return right, predicateRange
+			}
+		}
+	case *sqlparser.BetweenExpr:
+		if col, ok := expr.Left.(*sqlparser.ColName); ok && expr.IsBetween {
+			return col, predicateRange
+		}
+	}
+	return nil, predicateNone
+}
+
+func filterKey(columns map[string]bool) string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\x00")
+}
+
+// adviseTable recommends the vindexes of a table: the column filtered by
+// equality in most queries is recommended as primary vindex, then the columns
+// routing most of the remaining queries as lookup vindexes.
+func (sa *shardingAdvisor) adviseTable(name string, usage *tableUsage, opts AdvisorOptions) *TableShardingAdvice {
+	advice := &TableShardingAdvice{
+		Table:          name,
+		Queries:        usage.queries,
+		Inserts:        usage.inserts,
+		ScatterQueries: usage.scatter,
+		ScatterRatio:   ratio(usage.scatter, usage.observed),
+	}
+	for _, col := range usage.columns {
+		advice.Columns = append(advice.Columns, col)
+	}
+	sort.Slice(advice.Columns, func(i, j int) bool {
+		a, b := advice.Columns[i], advice.Columns[j]
+		if a.Equality != b.Equality {
+			return a.Equality > b.Equality
+		}
+		if a.Join != b.Join {
+			return a.Join > b.Join
+		}
+		return a.Column < b.Column
+	})
+
+	filters := make([][]string, 0, len(usage.filters))
+	counts := make([]uint64, 0, len(usage.filters))
+	for key, count := range usage.filters {
+		if key == "" {
+			continue
+		}
+		filters = append(filters, strings.Split(key, "\x00"))
+		counts = append(counts, count)
+	}
+
+	schema := sa.schemas[name]
+	routedBy := make(map[string]bool)
+	var routed uint64
+	for {
+		// Count the queries which each column would route, besides the ones
+		// routed by the columns already chosen.
+		gains := make(map[string]uint64)
+		for i, columns := range filters {
+			if anyOf(columns, routedBy) {
+				continue
+			}
+			for _, col := range columns {
+				gains[col] += counts[i]
+			}
+		}
+		best, gain := "", uint64(0)
+		for col, g := range gains {
+			if g > gain || (g == gain && col < best) {
+				best, gain = col, g
+			}
+		}
+		if gain == 0 {
+			break
+		}
+		vindex := &VindexAdvice{
+			Column:        best,
+			RoutedQueries: gain,
+			RoutedRatio:   ratio(gain, usage.queries),
+		}
+		if advice.PrimaryVindex == nil {
+			vindex.Type = primaryVindexType(schema, best)
+			advice.PrimaryVindex = vindex
+		} else {
+			if len(advice.LookupVindexes) >= opts.MaxLookupVindexes || vindex.RoutedRatio < opts.MinLookupRatio {
+				break
+			}
+			vindex.Type = lookupVindexType(schema, best)
+			advice.LookupVindexes = append(advice.LookupVindexes, vindex)
+		}
+		routedBy[best] = true
+		routed += gain
+	}
+	advice.ProjectedSingleShardRatio = ratio(routed, usage.queries)
+	return advice
+}
+
+func anyOf(columns []string, set map[string]bool) bool {
+	for _, col := range columns {
+		if set[col] {
+			return true
+		}
+	}
+	return false
+}
+
+func ratio(n, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// primaryVindexType returns the functional vindex suited to the type of a
+// column: hash for integers, unicode_loose_xxhash for strings, which are
+// usually compared with a case insensitive collation, and xxhash otherwise.
+func primaryVindexType(schema *tableSchema, column string) string {
+	if schema == nil {
+		return "xxhash"
+	}
+	switch schema.types[column] {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return "hash"
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return "unicode_loose_xxhash"
+	default:
+		return "xxhash"
+	}
+}
+
+// lookupVindexType returns the unique lookup vindex for the unique columns.
+func lookupVindexType(schema *tableSchema, column string) string {
+	if schema != nil && schema.unique[column] {
+		return "consistent_lookup_unique"
+	}
+	return "consistent_lookup"
+}
+
+// ShardingAdviceAsText returns the sharding advice in a human readable format.
+func ShardingAdviceAsText(advice *ShardingAdvice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Analyzed %d queries", advice.Queries)
+	if advice.SkippedQueries > 0 {
+		fmt.Fprintf(&b, ", skipped %d queries which could not be parsed", advice.SkippedQueries)
+	}
+	b.WriteString("\n")
+
+	for _, table := range advice.Tables {
+		fmt.Fprintf(&b, "\n----------------------------------------------------------------------\n")
+		fmt.Fprintf(&b, "Table %s: %d queries, %d inserts, scatter ratio %s\n\n", table.Table, table.Queries, table.Inserts, percent(table.ScatterRatio))
+		if len(table.Columns) > 0 {
+			w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+			fmt.Fprintf(w, "  column\tequality\trange\tjoin\n")
+			for _, col := range table.Columns {
+				fmt.Fprintf(w, "  %s\t%d\t%d\t%d\n", col.Column, col.Equality, col.Range, col.Join)
+			}
+			w.Flush()
+			b.WriteString("\n")
+		}
+		if table.PrimaryVindex == nil {
+			b.WriteString("No vindex recommended: no query filters the table by equality\n")
+			continue
+		}
+		fmt.Fprintf(&b, "Primary vindex: %s (%s), routing %s of the queries to a single shard\n", table.PrimaryVindex.Column, table.PrimaryVindex.Type, percent(table.PrimaryVindex.RoutedRatio))
+		for _, lookup := range table.LookupVindexes {
+			fmt.Fprintf(&b, "Lookup vindex: %s (%s), routing %s more of the queries to a single shard\n", lookup.Column, lookup.Type, percent(lookup.RoutedRatio))
+		}
+		fmt.Fprintf(&b, "Projected single shard ratio: %s\n", percent(table.ProjectedSingleShardRatio))
+	}
+	return b.String()
+}
+
+// ShardingAdviceAsJSON returns the sharding advice in JSON.
+func ShardingAdviceAsJSON(advice *ShardingAdvice) (string, error) {
+	out, err := json.MarshalIndent(advice, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+func percent(r float64) string {
+	return fmt.Sprintf("%.1f%%", 100*r)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestReadQueryLogEntries(t *testing.T) {
+	text := "Execute\t127.0.0.1:1234\tapp\t'app'\t''\t2024-01-01 00:00:00.000000\t2024-01-01 00:00:00.000100\t0.000100\t0.000010\t0.000080\t0.000000\tSELECT\t\"select * from user\"\t{}\t4\t0\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[\"ks.user\"]\t\"ks\""
+	entries, err := ReadQueryLogEntries(strings.NewReader(text), QueryLogFormatText)
+	require.NoError(t, err)
+	assert.Equal(t, []*QueryLogEntry{{SQL: "select * from user", Count: 1, ShardQueries: 4}}, entries)
+
+	json := `{"Method": "Execute", "StmtType": "SELECT", "SQL": "select * from user where id = 1", "ShardQueries": 1}`
+	entries, err = ReadQueryLogEntries(strings.NewReader(json), QueryLogFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []*QueryLogEntry{{SQL: "select * from user where id = 1", Count: 1, ShardQueries: 1}}, entries)
+
+	plans := `{
+ "select * from user where id = :id /* INT64 */": {"QueryType": "SELECT", "ExecCount": 10, "ShardQueries": 10},
+ "select * from user where name = :name /* VARCHAR */": {"QueryType": "SELECT", "ExecCount": 5, "ShardQueries": 20},
+ "select 1 from dual": {"QueryType": "SELECT"}
+}`
+	entries, err = ReadQueryLogEntries(strings.NewReader(plans), QueryLogFormatPlans)
+	require.NoError(t, err)
+	assert.Equal(t, []*QueryLogEntry{
+		{SQL: "select * from user where id = :id /* INT64 */", Count: 10, ShardQueries: 10},
+		{SQL: "select * from user where name = :name /* VARCHAR */", Count: 5, ShardQueries: 20},
+	}, entries)
+
+	_, err = ReadQueryLogEntries(strings.NewReader(json), "csv")
+	require.EqualError(t, err, `invalid query log format "csv", must be text, json or plans`)
+}
+
+func TestAdviseSharding(t *testing.T) {
+	schema := `
+create table user (id bigint, name varchar(64), email varchar(128) unique, created datetime, primary key (id));
+create table orders (id bigint, user_id bigint, primary key (id));
+`
+	entries := []*QueryLogEntry{
+		{SQL: "select * from user where id = :id", Count: 40, ShardQueries: 40},
+		{SQL: "select * from user where id in ::ids and created > :created", Count: 10, ShardQueries: 10},
+		{SQL: "select * from user where email = :email", Count: 30, ShardQueries: 120},
+		{SQL: "update user set name = :name where email = :email", Count: 5, ShardQueries: 20},
+		{SQL: "select * from user where name like :name", Count: 10, ShardQueries: 40},
+		{SQL: "select * from user where created between :a and :b", Count: 5, ShardQueries: 20},
+		{SQL: "insert into user (id, name) values (:id, :name)", Count: 100, ShardQueries: 100},
+		{SQL: "select o.id from user u join orders o on u.id = o.user_id where u.email = :email", Count: 20},
+		{SQL: "select * from orders where user_id = :user_id and id in (select max(id) from orders where user_id = :user_id)", Count: 10},
+		{SQL: "select from where", Count: 3},
+	}
+	advice, err := AdviseSharding(sqlparser.NewTestParser(), entries, schema, AdvisorOptions{MaxLookupVindexes: 2, MinLookupRatio: 0.1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 233, advice.Queries)
+	assert.EqualValues(t, 3, advice.SkippedQueries)
+	require.Len(t, advice.Tables, 2)
+
+	orders := advice.Tables[0]
+	assert.Equal(t, "orders", orders.Table)
+	assert.EqualValues(t, 30, orders.Queries)
+	assert.Zero(t, orders.ScatterRatio)
+	assert.Equal(t, []*ColumnPredicates{{Column: "user_id", Equality: 20, Join: 20}}, orders.Columns)
+	assert.Equal(t, &VindexAdvice{Column: "user_id", Type: "hash", RoutedQueries: 10, RoutedRatio: 10.0 / 30}, orders.PrimaryVindex)
+	assert.Empty(t, orders.LookupVindexes)
+
+	user := advice.Tables[1]
+	assert.Equal(t, "user", user.Table)
+	assert.EqualValues(t, 120, user.Queries)
+	assert.EqualValues(t, 100, user.Inserts)
+	assert.EqualValues(t, 50, user.ScatterQueries)
+	assert.InDelta(t, 0.5, user.ScatterRatio, 1e-9)
+	assert.Equal(t, []*ColumnPredicates{
+		{Column: "email", Equality: 55},
+		{Column: "id", Equality: 50, Join: 20},
+		{Column: "created", Range: 15},
+		{Column: "name", Range: 10},
+	}, user.Columns)
+	assert.Equal(t, &VindexAdvice{Column: "email", Type: "unicode_loose_xxhash", RoutedQueries: 55, RoutedRatio: 55.0 / 120}, user.PrimaryVindex)
+	assert.Equal(t, []*VindexAdvice{{Column: "id", Type: "consistent_lookup_unique", RoutedQueries: 50, RoutedRatio: 50.0 / 120}}, user.LookupVindexes)
+	assert.InDelta(t, 105.0/120, user.ProjectedSingleShardRatio, 1e-9)
+
+	text := ShardingAdviceAsText(advice)
+	assert.Contains(t, text, "Analyzed 233 queries, skipped 3 queries which could not be parsed")
+	assert.Contains(t, text, "Table user: 120 queries, 100 inserts, scatter ratio 50.0%")
+	assert.Contains(t, text, "Primary vindex: email (unicode_loose_xxhash), routing 45.8% of the queries to a single shard")
+	assert.Contains(t, text, "Lookup vindex: id (consistent_lookup_unique), routing 41.7% more of the queries to a single shard")
+	assert.Contains(t, text, "Projected single shard ratio: 87.5%")
+}
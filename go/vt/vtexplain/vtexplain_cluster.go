@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+const (
+	// QueryLogFormatText is the tab-separated format of the vtgate query log.
+	QueryLogFormatText = "text"
+
+	// QueryLogFormatJSON is the JSON format of the vtgate query log.
+	QueryLogFormatJSON = "json"
+
+	// QueryLogFormatPlans is the JSON format of the query plans of vtgate,
+	// returned by its /debug/query_plans page.
+	QueryLogFormatPlans = "plans"
+
+	// queryLogTextSQLField is the index of the SQL field in the text format
+	// of the vtgate query log, see (*logstats.LogStats).Logf.
+	queryLogTextSQLField = 12
+
+	// queryLogTextShardQueriesField is the index of the ShardQueries field
+	// in the text format of the vtgate query log.
+	queryLogTextShardQueriesField = 14
+)
+
+// ClusterInputs holds the VSchema, SQL schema and keyspace shard map of a
+// running cluster, in the format expected by Init.
+type ClusterInputs struct {
+	VSchema    string
+	Schema     string
+	KsShardMap string
+}
+
+// LoadClusterInputs fetches the VSchema, the schema and the shards of the given
+// keyspaces from a running cluster, or of all its keyspaces if none are given.
+// The schema of a keyspace is read from the primary tablet of its first serving
+// shard. Tables defined in several keyspaces keep their first definition, as
+// vtexplain uses a single schema for all the tablets.
+func LoadClusterInputs(ctx context.Context, client vtctldclient.VtctldClient, keyspaces []string) (*ClusterInputs, error) {
+	if len(keyspaces) == 0 {
+		resp, err := client.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("GetKeyspaces: %v", err)
+		}
+		for _, ks := range resp.Keyspaces {
+			keyspaces = append(keyspaces, ks.Name)
+		}
+	}
+	sort.Strings(keyspaces)
+
+	var (
+		vschemas   = make(map[string]json.RawMessage, len(keyspaces))
+		ksShardMap = make(map[string]map[string]*topo.ShardInfo, len(keyspaces))
+		schema     strings.Builder
+		tables     = make(map[string]bool)
+	)
+	for _, ks := range keyspaces {
+		vschemaResp, err := client.GetVSchema(ctx, &vtctldatapb.GetVSchemaRequest{Keyspace: ks})
+		if err != nil {
+			return nil, fmt.Errorf("GetVSchema(%s): %v", ks, err)
+		}
+		vschema, err := json2.MarshalPB(vschemaResp.VSchema)
+		if err != nil {
+			return nil, err
+		}
+		vschemas[ks] = vschema
+
+		shardsResp, err := client.FindAllShardsInKeyspace(ctx, &vtctldatapb.FindAllShardsInKeyspaceRequest{Keyspace: ks})
+		if err != nil {
+			return nil, fmt.Errorf("FindAllShardsInKeyspace(%s): %v", ks, err)
+		}
+		if len(shardsResp.Shards) == 0 {
+			return nil, fmt.Errorf("keyspace %s has no shards", ks)
+		}
+		shardNames := make([]string, 0, len(shardsResp.Shards))
+		ksShardMap[ks] = make(map[string]*topo.ShardInfo, len(shardsResp.Shards))
+		for name, shard := range shardsResp.Shards {
+			shardNames = append(shardNames, name)
+			ksShardMap[ks][name] = topo.NewShardInfo(ks, name, shard.Shard, nil)
+		}
+		sort.Strings(shardNames)
+
+		var primary *vtctldatapb.Shard
+		for _, name := range shardNames {
+			if shard := shardsResp.Shards[name]; shard.Shard.IsPrimaryServing && shard.Shard.PrimaryAlias != nil {
+				primary = shard
+				break
+			}
+		}
+		if primary == nil {
+			return nil, fmt.Errorf("keyspace %s has no serving shard with a primary tablet", ks)
+		}
+		schemaResp, err := client.GetSchema(ctx, &vtctldatapb.GetSchemaRequest{TabletAlias: primary.Shard.PrimaryAlias})
+		if err != nil {
+			return nil, fmt.Errorf("GetSchema(%s): %v", topoproto.TabletAliasString(primary.Shard.PrimaryAlias), err)
+		}
+		for _, td := range schemaResp.Schema.GetTableDefinitions() {
+			if tables[td.Name] {
+				continue
+			}
+			tables[td.Name] = true
+			schema.WriteString(td.Schema)
+			schema.WriteString(";\n")
+		}
+	}
+
+	vschema, err := json.Marshal(vschemas)
+	if err != nil {
+		return nil, err
+	}
+	shardMap, err := json.Marshal(ksShardMap)
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterInputs{
+		VSchema:    string(vschema),
+		Schema:     schema.String(),
+		KsShardMap: string(shardMap),
+	}, nil
+}
+
+// ReadQueryLog reads the queries of a vtgate query log in the given format,
+// and returns them as a list of semicolon-delimited statements which can be
+// passed to Run.
+func ReadQueryLog(r io.Reader, format string) (string, error) {
+	if format != QueryLogFormatText && format != QueryLogFormatJSON {
+		return "", fmt.Errorf("invalid query log format %q, must be %s or %s", format, QueryLogFormatText, QueryLogFormatJSON)
+	}
+	entries, err := ReadQueryLogEntries(r, format)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	for _, entry := range entries {
+		sql.WriteString(entry.SQL)
+		sql.WriteString(";\n")
+	}
+	return sql.String(), nil
+}
+
+// QueryLogEntry is a query of a query log.
+type QueryLogEntry struct {
+	SQL string
+
+	// Count is the number of executions of the query, and ShardQueries the
+	// total number of queries sent to the shards by these executions. It is
+	// 0 when the query log does not record it.
+	Count        uint64
+	ShardQueries uint64
+}
+
+// ReadQueryLogEntries reads the queries of a vtgate query log in the given
+// format. The query plans format is the output of the /debug/query_plans
+// page of vtgate, whose queries are normalized and aggregated.
+func ReadQueryLogEntries(r io.Reader, format string) ([]*QueryLogEntry, error) {
+	switch format {
+	case QueryLogFormatText, QueryLogFormatJSON:
+	case QueryLogFormatPlans:
+		return readQueryPlans(r)
+	default:
+		return nil, fmt.Errorf("invalid query log format %q, must be %s, %s or %s", format, QueryLogFormatText, QueryLogFormatJSON, QueryLogFormatPlans)
+	}
+
+	var entries []*QueryLogEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var entry QueryLogEntry
+		switch format {
+		case QueryLogFormatJSON:
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNum, err)
+			}
+		case QueryLogFormatText:
+			fields := strings.Split(line, "\t")
+			if len(fields) <= queryLogTextSQLField {
+				return nil, fmt.Errorf("line %d: expected at least %d fields, got %d", lineNum, queryLogTextSQLField+1, len(fields))
+			}
+			var err error
+			entry.SQL, err = strconv.Unquote(fields[queryLogTextSQLField])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid SQL field %s: %v", lineNum, fields[queryLogTextSQLField], err)
+			}
+			if len(fields) > queryLogTextShardQueriesField {
+				// Query logs of older versions may not have the field.
+				entry.ShardQueries, _ = strconv.ParseUint(fields[queryLogTextShardQueriesField], 10, 64)
+			}
+		}
+
+		entry.SQL = strings.TrimRight(strings.TrimSpace(entry.SQL), ";")
+		if entry.SQL == "" {
+			continue
+		}
+		entry.Count = 1
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readQueryPlans reads the queries of the plans of the query plan cache of
+// vtgate, as returned by its /debug/query_plans page.
+func readQueryPlans(r io.Reader) ([]*QueryLogEntry, error) {
+	var plans map[string]struct {
+		ExecCount    uint64
+		ShardQueries uint64
+	}
+	if err := json.NewDecoder(r).Decode(&plans); err != nil {
+		return nil, fmt.Errorf("cannot parse the query plans: %v", err)
+	}
+
+	entries := make([]*QueryLogEntry, 0, len(plans))
+	for sql, plan := range plans {
+		sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+		if sql == "" || plan.ExecCount == 0 {
+			continue
+		}
+		entries = append(entries, &QueryLogEntry{
+			SQL:          sql,
+			Count:        plan.ExecCount,
+			ShardQueries: plan.ShardQueries,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].SQL < entries[j].SQL
+	})
+	return entries, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// fakeClusterClient serves the RPCs used by LoadClusterInputs.
+type fakeClusterClient struct {
+	vtctldclient.VtctldClient
+
+	vschemas map[string]*vschemapb.Keyspace
+	shards   map[string]map[string]*topodatapb.Shard
+	schemas  map[uint32]*tabletmanagerdatapb.SchemaDefinition
+}
+
+func (c *fakeClusterClient) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKeyspacesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspacesResponse, error) {
+	resp := &vtctldatapb.GetKeyspacesResponse{}
+	for ks := range c.vschemas {
+		resp.Keyspaces = append(resp.Keyspaces, &vtctldatapb.Keyspace{Name: ks})
+	}
+	return resp, nil
+}
+
+func (c *fakeClusterClient) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaResponse, error) {
+	return &vtctldatapb.GetVSchemaResponse{VSchema: c.vschemas[req.Keyspace]}, nil
+}
+
+func (c *fakeClusterClient) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
+	resp := &vtctldatapb.FindAllShardsInKeyspaceResponse{Shards: map[string]*vtctldatapb.Shard{}}
+	for name, shard := range c.shards[req.Keyspace] {
+		resp.Shards[name] = &vtctldatapb.Shard{Keyspace: req.Keyspace, Name: name, Shard: shard}
+	}
+	return resp, nil
+}
+
+func (c *fakeClusterClient) GetSchema(ctx context.Context, req *vtctldatapb.GetSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaResponse, error) {
+	return &vtctldatapb.GetSchemaResponse{Schema: c.schemas[req.TabletAlias.Uid]}, nil
+}
+
+func newFakeClusterClient(t *testing.T) *fakeClusterClient {
+	shard := func(start, end string, uid uint32, serving bool) *topodatapb.Shard {
+		kr, err := key.ParseKeyRangeParts(start, end)
+		require.NoError(t, err)
+		return &topodatapb.Shard{
+			KeyRange:         kr,
+			IsPrimaryServing: serving,
+			PrimaryAlias:     &topodatapb.TabletAlias{Cell: "zone1", Uid: uid},
+		}
+	}
+	return &fakeClusterClient{
+		vschemas: map[string]*vschemapb.Keyspace{
+			"ks_unsharded": {
+				Tables: map[string]*vschemapb.Table{"t1": {}},
+			},
+			"ks_sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"user": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+				},
+			},
+		},
+		shards: map[string]map[string]*topodatapb.Shard{
+			"ks_unsharded": {"0": shard("", "", 100, true)},
+			"ks_sharded": {
+				"-40":   shard("", "40", 200, true),
+				"40-":   shard("40", "", 300, true),
+				"-":     shard("", "", 400, false),
+				"-80":   shard("", "80", 500, false),
+				"80-":   shard("80", "", 600, false),
+				"40-80": shard("40", "80", 700, false),
+			},
+		},
+		schemas: map[uint32]*tabletmanagerdatapb.SchemaDefinition{
+			100: {TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{Name: "t1", Schema: "create table t1 (id bigint primary key, val varchar(64))"},
+			}},
+			200: {TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{Name: "user", Schema: "create table user (id bigint primary key, name varchar(64))"},
+				{Name: "t1", Schema: "create table t1 (id bigint primary key)"},
+			}},
+		},
+	}
+}
+
+func TestLoadClusterInputs(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	inputs, err := LoadClusterInputs(ctx, newFakeClusterClient(t), nil)
+	require.NoError(t, err)
+	// The keyspaces are read in order, so the definition of t1 in ks_sharded is kept.
+	assert.Equal(t, "create table user (id bigint primary key, name varchar(64));\ncreate table t1 (id bigint primary key);\n", inputs.Schema)
+
+	ts := memorytopo.NewServer(ctx, Cell)
+	srvTopoCounts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
+	opts := defaultTestOpts()
+	opts.ExecutionMode = ModeMulti
+	vte, err := Init(ctx, vtenv.NewTestEnv(), ts, inputs.VSchema, inputs.Schema, inputs.KsShardMap, opts, srvTopoCounts)
+	require.NoError(t, err)
+	defer vte.Stop()
+
+	explains, err := vte.Run(`select * from user where id = 1; select * from user; select * from t1`)
+	require.NoError(t, err)
+	require.Len(t, explains, 3)
+	// The queries are routed to the serving shards of the running cluster.
+	assert.Len(t, explains[0].TabletActions, 1)
+	assert.Contains(t, explains[0].TabletActions, "ks_sharded/-40")
+	assert.Len(t, explains[1].TabletActions, 2)
+	assert.Contains(t, explains[1].TabletActions, "ks_sharded/-40")
+	assert.Contains(t, explains[1].TabletActions, "ks_sharded/40-")
+	assert.Contains(t, explains[2].TabletActions, "ks_unsharded/0")
+
+	t.Run("keyspaces", func(t *testing.T) {
+		inputs, err := LoadClusterInputs(ctx, newFakeClusterClient(t), []string{"ks_unsharded"})
+		require.NoError(t, err)
+		assert.Equal(t, "create table t1 (id bigint primary key, val varchar(64));\n", inputs.Schema)
+		assert.NotContains(t, inputs.VSchema, "ks_sharded")
+	})
+
+	t.Run("no serving primary", func(t *testing.T) {
+		client := newFakeClusterClient(t)
+		client.shards["ks_unsharded"]["0"].IsPrimaryServing = false
+		_, err := LoadClusterInputs(ctx, client, nil)
+		require.EqualError(t, err, "keyspace ks_unsharded has no serving shard with a primary tablet")
+	})
+}
+
+func TestReadQueryLog(t *testing.T) {
+	text := strings.Join([]string{
+		"Execute\t127.0.0.1:1234\tapp\t'app'\t''\t2024-01-01 00:00:00.000000\t2024-01-01 00:00:00.000100\t0.000100\t0.000010\t0.000080\t0.000000\tSELECT\t\"select * from user where name = 'a\\tb'\"\t{}\t1\t0\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[\"ks.user\"]\t\"ks\"",
+		"",
+		"Execute\t127.0.0.1:1234\tapp\t'app'\t''\t2024-01-01 00:00:00.000000\t2024-01-01 00:00:00.000100\t0.000100\t0.000010\t0.000080\t0.000000\tINSERT\t\"insert into t1 (id) values (1);\"\t{}\t1\t1\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[\"ks.t1\"]\t\"ks\"",
+	}, "\n")
+	sql, err := ReadQueryLog(strings.NewReader(text), QueryLogFormatText)
+	require.NoError(t, err)
+	assert.Equal(t, "select * from user where name = 'a\tb';\ninsert into t1 (id) values (1);\n", sql)
+
+	json := `{"Method": "Execute", "StmtType": "SELECT", "SQL": "select * from user", "BindVars": {}}
+{"Method": "Execute", "StmtType": "BEGIN", "SQL": "begin"}
+`
+	sql, err = ReadQueryLog(strings.NewReader(json), QueryLogFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, "select * from user;\nbegin;\n", sql)
+
+	_, err = ReadQueryLog(strings.NewReader("Execute\tselect 1"), QueryLogFormatText)
+	require.EqualError(t, err, "line 1: expected at least 13 fields, got 2")
+
+	_, err = ReadQueryLog(strings.NewReader(json), "csv")
+	require.EqualError(t, err, `invalid query log format "csv", must be text or json`)
+}
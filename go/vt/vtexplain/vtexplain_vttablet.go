@@ -264,6 +264,19 @@ func (t *explainTablet) ReadTransaction(ctx context.Context, target *querypb.Tar
 	return t.tsv.ReadTransaction(ctx, target, dtid)
 }
 
+// TransactionLockWaits is part of the QueryService interface.
+func (t *explainTablet) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	return nil, nil
+}
+
+// AbortTransaction is part of the QueryService interface.
+func (t *explainTablet) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	t.mu.Lock()
+	t.currentTime = t.vte.batchTime.Wait()
+	t.mu.Unlock()
+	return t.tsv.AbortTransaction(ctx, target, transactionID, reason)
+}
+
 // BeginExecute is part of the QueryService interface.
 func (t *explainTablet) BeginExecute(ctx context.Context, target *querypb.Target, preQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions) (queryservice.TransactionState, *sqltypes.Result, error) {
 	t.mu.Lock()
@@ -0,0 +1,369 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	// deadlockDetectionInterval is the interval at which the lock waits of
+	// the multi-shard transactions are checked for cross-shard deadlocks.
+	// The detection is disabled when it is 0.
+	deadlockDetectionInterval time.Duration
+
+	deadlocksDetected      = stats.NewCounter("DeadlocksDetected", "Number of cross-shard deadlocks detected")
+	deadlockVictimsAborted = stats.NewCounter("DeadlockVictimsAborted", "Number of transactions aborted to resolve a cross-shard deadlock")
+	deadlockDetectorErrors = stats.NewCountersWithSingleLabel("DeadlockDetectorErrors", "Errors of the cross-shard deadlock detector, per operation", "Operation")
+)
+
+func init() {
+	servenv.OnParseFor("vtgate", func(fs *pflag.FlagSet) {
+		fs.DurationVar(&deadlockDetectionInterval, "deadlock-detection-interval", deadlockDetectionInterval, "If set, the interval at which vtgate collects the lock waits of the shards of the transactions which have been executing a statement for longer than this interval, and aborts the youngest transaction of the cross-shard deadlocks found. Requires vttablets which support the TransactionLockWaits RPC.")
+	})
+}
+
+// deadlockDetector detects the deadlocks between transactions spanning
+// several shards, which MySQL cannot detect as each shard only sees a part of
+// the cycle, and which otherwise resolve only when a lock wait or transaction
+// times out.
+//
+// The statements of the sessions in a transaction are tracked while they
+// execute. At each interval, the lock waits between the transactions of the
+// shards of the sessions executing a statement for longer than the interval
+// are collected. A transaction is identified across shards by the UUID of its
+// session, which vtgate passes to the tablets on begin. A cycle of lock waits
+// spanning several shards, found in two consecutive rounds, is a deadlock:
+// the youngest transaction of the cycle is aborted on the shards where it
+// waits or blocks. As the transactions of other vtgates are identified by
+// their session UUID too, the deadlocks between sessions of different vtgates
+// are detected as long as the shards of the cycle are shards of a session of
+// this vtgate.
+type deadlockDetector struct {
+	qs       queryservice.QueryService
+	interval time.Duration
+	ticks    *timer.Timer
+
+	mu sync.Mutex
+	// executing holds the start time of the statements which the sessions
+	// in a transaction are executing.
+	executing map[*SafeSession]time.Time
+	// suspects are the cycles found by the previous round, by key.
+	suspects map[string]bool
+}
+
+// lockWaitNode is a transaction in the lock wait graph.
+type lockWaitNode struct {
+	id string
+	// startTime is the earliest start time of the transaction on its shards.
+	startTime int64
+	// transactions are the ids of the transaction per target.
+	transactions map[string]lockWaitTransaction
+	// waitsFor are the ids of the nodes the transaction waits for, with the
+	// shards of the waits.
+	waitsFor map[string]map[string]bool
+}
+
+type lockWaitTransaction struct {
+	target        *querypb.Target
+	transactionID int64
+}
+
+func newDeadlockDetector(qs queryservice.QueryService, interval time.Duration) *deadlockDetector {
+	return &deadlockDetector{
+		qs:        qs,
+		interval:  interval,
+		ticks:     timer.NewTimer(interval),
+		executing: make(map[*SafeSession]time.Time),
+	}
+}
+
+// Open starts the detection.
+func (dd *deadlockDetector) Open() {
+	dd.ticks.Start(dd.detect)
+}
+
+// Close stops the detection.
+func (dd *deadlockDetector) Close() {
+	dd.ticks.Stop()
+}
+
+// startStatement records that the session started executing a statement, and
+// returns the function to call when it completes.
+func (dd *deadlockDetector) startStatement(session *SafeSession) func() {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	if _, ok := dd.executing[session]; ok {
+		// The session is already executing a statement, e.g. the
+		// statement is executed by several primitives.
+		return func() {}
+	}
+	dd.executing[session] = time.Now()
+	return func() {
+		dd.mu.Lock()
+		defer dd.mu.Unlock()
+		delete(dd.executing, session)
+	}
+}
+
+// waitingTargets returns the primary targets of the transactions of the
+// sessions which have been executing a statement for longer than the interval.
+func (dd *deadlockDetector) waitingTargets() map[string]*querypb.Target {
+	dd.mu.Lock()
+	sessions := make([]*SafeSession, 0, len(dd.executing))
+	for session, start := range dd.executing {
+		if time.Since(start) >= dd.interval {
+			sessions = append(sessions, session)
+		}
+	}
+	dd.mu.Unlock()
+
+	targets := make(map[string]*querypb.Target)
+	for _, session := range sessions {
+		for _, target := range session.transactionTargets() {
+			if target.TabletType != topodatapb.TabletType_PRIMARY {
+				continue
+			}
+			targets[targetKey(target)] = target
+		}
+	}
+	return targets
+}
+
+// detect runs a round of detection.
+func (dd *deadlockDetector) detect() {
+	targets := dd.waitingTargets()
+	if len(targets) < 2 {
+		// A deadlock on a single shard is detected by MySQL.
+		dd.setSuspects(nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dd.interval)
+	defer cancel()
+	graph := dd.collectLockWaits(ctx, targets)
+
+	cycles := findLockWaitCycles(graph)
+	suspects := make(map[string]bool, len(cycles))
+	for _, cycle := range cycles {
+		key := lockWaitCycleKey(cycle)
+		suspects[key] = true
+		if !dd.isSuspect(key) {
+			// Wait for the next round to confirm the cycle, as the lock
+			// waits of the shards are not collected at the same time.
+			continue
+		}
+		deadlocksDetected.Add(1)
+		dd.abort(ctx, graph, cycle)
+	}
+	dd.setSuspects(suspects)
+}
+
+func (dd *deadlockDetector) isSuspect(key string) bool {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	return dd.suspects[key]
+}
+
+func (dd *deadlockDetector) setSuspects(suspects map[string]bool) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	dd.suspects = suspects
+}
+
+// collectLockWaits returns the lock wait graph of the transactions of the targets.
+func (dd *deadlockDetector) collectLockWaits(ctx context.Context, targets map[string]*querypb.Target) map[string]*lockWaitNode {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		graph = make(map[string]*lockWaitNode)
+	)
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target *querypb.Target) {
+			defer wg.Done()
+			lockWaits, err := dd.qs.TransactionLockWaits(ctx, target)
+			if err != nil {
+				deadlockDetectorErrors.Add("TransactionLockWaits", 1)
+				log.Warningf("Failed to read the transaction lock waits of %s: %v", targetKey(target), err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			addLockWaits(graph, target, lockWaits)
+		}(target)
+	}
+	wg.Wait()
+	return graph
+}
+
+// addLockWaits adds the lock waits of a target to the lock wait graph.
+func addLockWaits(graph map[string]*lockWaitNode, target *querypb.Target, lockWaits []*querypb.TransactionLockWait) {
+	shard := targetKey(target)
+	node := func(sessionUUID string, transactionID, startTime int64) *lockWaitNode {
+		id := sessionUUID
+		if id == "" {
+			// The transaction was not started by a vtgate detecting the
+			// deadlocks, so it can only be identified on this shard.
+			id = fmt.Sprintf("%s:%d", shard, transactionID)
+		}
+		n, ok := graph[id]
+		if !ok {
+			n = &lockWaitNode{
+				id:           id,
+				startTime:    startTime,
+				transactions: make(map[string]lockWaitTransaction),
+				waitsFor:     make(map[string]map[string]bool),
+			}
+			graph[id] = n
+		}
+		n.startTime = min(n.startTime, startTime)
+		n.transactions[shard] = lockWaitTransaction{target: target, transactionID: transactionID}
+		return n
+	}
+	for _, lw := range lockWaits {
+		waiting := node(lw.WaitingSessionUuid, lw.WaitingTransactionId, lw.WaitingStartTime)
+		blocking := node(lw.BlockingSessionUuid, lw.BlockingTransactionId, lw.BlockingStartTime)
+		if waiting == blocking {
+			continue
+		}
+		if waiting.waitsFor[blocking.id] == nil {
+			waiting.waitsFor[blocking.id] = make(map[string]bool)
+		}
+		waiting.waitsFor[blocking.id][shard] = true
+	}
+}
+
+// findLockWaitCycles returns the cycles of the lock wait graph which span
+// several shards, as the ids of their nodes. Each node belongs to one cycle at
+// most, which is enough as aborting a transaction of the cycle breaks it.
+func findLockWaitCycles(graph map[string]*lockWaitNode) [][]string {
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(graph))
+	var (
+		path   []string
+		cycles [][]string
+		visit  func(id string)
+	)
+	visit = func(id string) {
+		state[id] = visiting
+		path = append(path, id)
+		next := make([]string, 0, len(graph[id].waitsFor))
+		for to := range graph[id].waitsFor {
+			next = append(next, to)
+		}
+		sort.Strings(next)
+		for _, to := range next {
+			switch state[to] {
+			case unvisited:
+				visit(to)
+			case visiting:
+				cycle := slices.Clone(path[slices.Index(path, to):])
+				if spansSeveralShards(graph, cycle) {
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+	}
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+// spansSeveralShards returns true if the lock waits of the cycle are not all
+// on the same shard, as MySQL resolves the deadlocks within a shard.
+func spansSeveralShards(graph map[string]*lockWaitNode, cycle []string) bool {
+	shards := make(map[string]bool)
+	for i, id := range cycle {
+		for shard := range graph[id].waitsFor[cycle[(i+1)%len(cycle)]] {
+			shards[shard] = true
+		}
+	}
+	return len(shards) > 1
+}
+
+// lockWaitCycleKey identifies a cycle regardless of the node it starts from.
+func lockWaitCycleKey(cycle []string) string {
+	sorted := slices.Clone(cycle)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// deadlockVictim returns the youngest transaction of the cycle, i.e. the one
+// which started last, which has likely done the least work.
+func deadlockVictim(graph map[string]*lockWaitNode, cycle []string) *lockWaitNode {
+	var victim *lockWaitNode
+	for _, id := range cycle {
+		n := graph[id]
+		if victim == nil || n.startTime > victim.startTime || (n.startTime == victim.startTime && n.id > victim.id) {
+			victim = n
+		}
+	}
+	return victim
+}
+
+// abort aborts the youngest transaction of the cycle on all its shards.
+func (dd *deadlockDetector) abort(ctx context.Context, graph map[string]*lockWaitNode, cycle []string) {
+	victim := deadlockVictim(graph, cycle)
+	log.Warningf("Cross-shard deadlock detected between transactions %s, aborting the youngest transaction %s", strings.Join(cycle, ", "), victim.id)
+	reason := fmt.Sprintf("cross-shard deadlock detected by vtgate, transaction %s chosen as the victim", victim.id)
+	for shard, t := range victim.transactions {
+		if err := dd.qs.AbortTransaction(ctx, t.target, t.transactionID, reason); err != nil {
+			deadlockDetectorErrors.Add("AbortTransaction", 1)
+			log.Warningf("Failed to abort transaction %d of %s: %v", t.transactionID, shard, err)
+		}
+	}
+	deadlockVictimsAborted.Add(1)
+}
+
+// targetKey returns a key identifying the shard and tablet type of the target.
+func targetKey(target *querypb.Target) string {
+	return fmt.Sprintf("%s/%s@%s", target.Keyspace, target.Shard, topoproto.TabletTypeLString(target.TabletType))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestFindLockWaitCycles(t *testing.T) {
+	target0 := &querypb.Target{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY}
+	target1 := &querypb.Target{Keyspace: "ks", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY}
+
+	graph := make(map[string]*lockWaitNode)
+	addLockWaits(graph, target0, []*querypb.TransactionLockWait{
+		// s1 waits for s2 on -80, and s2 waits for s1 on 80-.
+		{WaitingTransactionId: 1, WaitingSessionUuid: "s1", WaitingStartTime: 100, BlockingTransactionId: 2, BlockingSessionUuid: "s2", BlockingStartTime: 200},
+		// s3 and s4 wait for each other on -80 only, which MySQL resolves.
+		{WaitingTransactionId: 3, WaitingSessionUuid: "s3", WaitingStartTime: 300, BlockingTransactionId: 4, BlockingSessionUuid: "s4", BlockingStartTime: 400},
+		{WaitingTransactionId: 4, WaitingSessionUuid: "s4", WaitingStartTime: 400, BlockingTransactionId: 3, BlockingSessionUuid: "s3", BlockingStartTime: 300},
+		// A transaction without session waits for s1.
+		{WaitingTransactionId: 5, WaitingStartTime: 500, BlockingTransactionId: 1, BlockingSessionUuid: "s1", BlockingStartTime: 100},
+	})
+	addLockWaits(graph, target1, []*querypb.TransactionLockWait{
+		{WaitingTransactionId: 12, WaitingSessionUuid: "s2", WaitingStartTime: 150, BlockingTransactionId: 11, BlockingSessionUuid: "s1", BlockingStartTime: 110},
+	})
+
+	require.Contains(t, graph, "ks/-80@primary:5")
+	cycles := findLockWaitCycles(graph)
+	require.Len(t, cycles, 1)
+	assert.ElementsMatch(t, []string{"s1", "s2"}, cycles[0])
+	assert.Equal(t, "s1,s2", lockWaitCycleKey(cycles[0]))
+
+	// The start time of a transaction is its earliest start time on its shards.
+	victim := deadlockVictim(graph, cycles[0])
+	assert.Equal(t, "s2", victim.id)
+	assert.EqualValues(t, 150, victim.startTime)
+	assert.Equal(t, map[string]lockWaitTransaction{
+		"ks/-80@primary": {target: target0, transactionID: 2},
+		"ks/80-@primary": {target: target1, transactionID: 12},
+	}, victim.transactions)
+}
+
+func TestDeadlockDetectorAbortsYoungestTransaction(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	keyspace := "TestDeadlockDetector"
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+	sbc0 := hc.AddTestTablet("aa", "0", 1, keyspace, "-80", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	sbc1 := hc.AddTestTablet("aa", "1", 1, keyspace, "80-", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	target0 := &querypb.Target{Keyspace: keyspace, Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY}
+	target1 := &querypb.Target{Keyspace: keyspace, Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY}
+
+	sbc0.LockWaits = []*querypb.TransactionLockWait{
+		{WaitingTransactionId: 1, WaitingSessionUuid: "s1", WaitingStartTime: 100, BlockingTransactionId: 2, BlockingSessionUuid: "s2", BlockingStartTime: 200},
+	}
+	sbc1.LockWaits = []*querypb.TransactionLockWait{
+		{WaitingTransactionId: 12, WaitingSessionUuid: "s2", WaitingStartTime: 210, BlockingTransactionId: 11, BlockingSessionUuid: "s1", BlockingStartTime: 110},
+	}
+
+	dd := newDeadlockDetector(sc.gateway, time.Second)
+	session := NewSafeSession(&vtgatepb.Session{
+		InTransaction: true,
+		SessionUUID:   "s1",
+		ShardSessions: []*vtgatepb.Session_ShardSession{
+			{Target: target0, TransactionId: 1},
+			{Target: target1, TransactionId: 11},
+		},
+	})
+	done := dd.startStatement(session)
+	defer done()
+
+	// The statement is not executing for long enough yet.
+	dd.detect()
+	assert.Zero(t, sbc0.TransactionLockWaitsCount.Load())
+
+	dd.executing[session] = time.Now().Add(-time.Minute)
+	detected, aborted := deadlocksDetected.Get(), deadlockVictimsAborted.Get()
+	// The cycle must be found twice to be a deadlock.
+	dd.detect()
+	assert.EqualValues(t, 1, sbc0.TransactionLockWaitsCount.Load())
+	assert.EqualValues(t, 1, sbc1.TransactionLockWaitsCount.Load())
+	assert.Empty(t, sbc0.AbortedTransactions)
+	assert.Empty(t, sbc1.AbortedTransactions)
+
+	dd.detect()
+	assert.Equal(t, []int64{2}, sbc0.AbortedTransactions)
+	assert.Equal(t, []int64{12}, sbc1.AbortedTransactions)
+	assert.EqualValues(t, 1, deadlocksDetected.Get()-detected)
+	assert.EqualValues(t, 1, deadlockVictimsAborted.Get()-aborted)
+}
+
+func TestExecuteMultiShardSessionUUID(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	keyspace := "TestExecuteMultiShardSessionUUID"
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	rss := []*srvtopo.ResolvedShard{{
+		Target:  &querypb.Target{Keyspace: keyspace, Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+		Gateway: sbc,
+	}}
+	queries := []*querypb.BoundQuery{{Sql: "update t set a = 1"}}
+
+	// The session UUID is not passed when the detection is disabled.
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	_, errs := sc.ExecuteMultiShard(ctx, nil, rss, queries, session, false /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	require.Len(t, sbc.Options, 1)
+	assert.Empty(t, sbc.Options[0].GetSessionUuid())
+
+	sc.deadlockDetector = newDeadlockDetector(sc.gateway, time.Hour)
+	session = NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss, queries, session, false /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	require.Len(t, sbc.Options, 2)
+	assert.NotEmpty(t, session.GetSessionUUID())
+	assert.Equal(t, session.GetSessionUUID(), sbc.Options[1].GetSessionUuid())
+	// The statement is not tracked once completed.
+	assert.Empty(t, sc.deadlockDetector.executing)
+}
@@ -1068,6 +1068,28 @@ func (cached *ScalarAggregate) CachedSize(alloc bool) int64 {
 }
 
 //go:nocheckptr
+func (cached *SchemaShow) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(96)
+	}
+	// field Keyspace string
+	size += hack.RuntimeAllocSize(int64(len(cached.Keyspace)))
+	// field DbName string
+	size += hack.RuntimeAllocSize(int64(len(cached.DbName)))
+	// field Table string
+	size += hack.RuntimeAllocSize(int64(len(cached.Table)))
+	// field Filter *vitess.io/vitess/go/vt/sqlparser.ShowFilter
+	size += cached.Filter.CachedSize(true)
+	// field Fallback vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Fallback.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *SemiJoin) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
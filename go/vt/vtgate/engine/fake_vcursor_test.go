@@ -223,6 +223,10 @@ func (t *noopVCursor) SetReadAfterWriteTimeout(f float64) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) ImportSession(string) error {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetSessionTrackGTIDs(b bool) {
 	panic("implement me")
 }
@@ -247,6 +251,10 @@ func (t *noopVCursor) FindRoutedTable(sqlparser.TableName) (*vindexes.Table, err
 	panic("implement me")
 }
 
+func (t *noopVCursor) TrackedSchema(string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool) {
+	return nil, nil, false
+}
+
 func (t *noopVCursor) ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedShard, query *querypb.BoundQuery, lockFuncType sqlparser.LockingFuncType) (*sqltypes.Result, error) {
 	panic("implement me")
 }
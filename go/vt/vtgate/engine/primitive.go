@@ -100,6 +100,11 @@ type (
 
 		FindRoutedTable(tablename sqlparser.TableName) (*vindexes.Table, error)
 
+		// TrackedSchema returns the tables and views of the keyspace tracked
+		// by the schema tracker, or false if they may not be used to answer
+		// the metadata queries, e.g. because they are not fresh enough.
+		TrackedSchema(keyspace string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool)
+
 		// GetDBDDLPlugin gets the configured plugin for DROP/CREATE DATABASE
 		GetDBDDLPluginName() string
 
@@ -184,6 +189,10 @@ type (
 		SetReadAfterWriteTimeout(float64)
 		SetSessionTrackGTIDs(bool)
 
+		// ImportSession replaces the state of the session with the one of a
+		// session exported by SHOW VITESS_SESSION.
+		ImportSession(encoded string) error
+
 		// HasCreatedTempTable will mark the session as having created temp tables
 		HasCreatedTempTable()
 		GetWarnings() []*querypb.QueryWarning
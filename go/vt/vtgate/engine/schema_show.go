@@ -0,0 +1,336 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ Primitive = (*SchemaShow)(nil)
+
+// SchemaShow is a primitive which answers SHOW TABLES and SHOW COLUMNS from
+// the schema tracked by vtgate. It executes Fallback, which sends the query to
+// a tablet, when the tracked schema may not be used or does not know the
+// table.
+type SchemaShow struct {
+	noTxNeeded
+
+	// Command is either sqlparser.Table or sqlparser.Column.
+	Command sqlparser.ShowCommandType
+	// Keyspace is the keyspace whose tracked schema is used.
+	Keyspace string
+	// DbName is the database name shown in the Tables_in_ column.
+	DbName string
+	// Table is the table whose columns are shown.
+	Table string
+	// Full is set by SHOW FULL TABLES.
+	Full bool
+	// Filter is nil or a LIKE filter.
+	Filter *sqlparser.ShowFilter
+
+	Fallback Primitive
+}
+
+// RouteType implements the Primitive interface
+func (s *SchemaShow) RouteType() string {
+	return "SchemaShow"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (s *SchemaShow) GetKeyspaceName() string {
+	return s.Keyspace
+}
+
+// GetTableName implements the Primitive interface
+func (s *SchemaShow) GetTableName() string {
+	return s.Table
+}
+
+// TryExecute implements the Primitive interface
+func (s *SchemaShow) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	qr, ok := s.answer(vcursor)
+	if !ok {
+		return vcursor.ExecutePrimitive(ctx, s.Fallback, bindVars, wantfields)
+	}
+	return qr, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (s *SchemaShow) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	qr, ok := s.answer(vcursor)
+	if !ok {
+		return vcursor.StreamExecutePrimitive(ctx, s.Fallback, bindVars, wantfields, callback)
+	}
+	return callback(qr)
+}
+
+// GetFields implements the Primitive interface
+func (s *SchemaShow) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return s.Fallback.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs implements the Primitive interface
+func (s *SchemaShow) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{s.Fallback}, nil
+}
+
+// answer returns the result built from the tracked schema, or false if the
+// query has to be sent to a tablet.
+func (s *SchemaShow) answer(vcursor VCursor) (*sqltypes.Result, bool) {
+	tables, views, ok := vcursor.TrackedSchema(s.Keyspace)
+	if !ok {
+		return nil, false
+	}
+	filter := regexp.MustCompile(".*")
+	if s.Filter != nil {
+		filter = sqlparser.LikeToRegexp(s.Filter.Like)
+	}
+	switch s.Command {
+	case sqlparser.Table:
+		return s.showTables(tables, views, filter), true
+	case sqlparser.Column:
+		info := tables[s.Table]
+		if info == nil || len(info.ColumnDefinitions) == 0 {
+			return nil, false
+		}
+		return showColumns(info, filter), true
+	}
+	return nil, false
+}
+
+func (s *SchemaShow) showTables(tables map[string]*vindexes.TableInfo, views map[string]sqlparser.SelectStatement, filter *regexp.Regexp) *sqltypes.Result {
+	tableType := make(map[string]string, len(tables)+len(views))
+	for name := range tables {
+		tableType[name] = "BASE TABLE"
+	}
+	for name := range views {
+		tableType[name] = "VIEW"
+	}
+	names := make([]string, 0, len(tableType))
+	for name := range tableType {
+		if filter.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fieldNames := []string{"Tables_in_" + s.DbName}
+	if s.Full {
+		fieldNames = append(fieldNames, "Table_type")
+	}
+	qr := &sqltypes.Result{Fields: schemaShowFields(fieldNames...)}
+	for _, name := range names {
+		row := []sqltypes.Value{sqltypes.NewVarChar(name)}
+		if s.Full {
+			row = append(row, sqltypes.NewVarChar(tableType[name]))
+		}
+		qr.Rows = append(qr.Rows, row)
+	}
+	return qr
+}
+
+func showColumns(info *vindexes.TableInfo, filter *regexp.Regexp) *sqltypes.Result {
+	keys := columnKeys(info)
+	fields := schemaShowFields("Field", "Type", "Null", "Key", "Default", "Extra")
+	// Default is NULL for the columns without a default.
+	fields[4].Flags = 0
+	qr := &sqltypes.Result{Fields: fields}
+	for _, col := range info.ColumnDefinitions {
+		name := col.Name.String()
+		if !filter.MatchString(name) {
+			continue
+		}
+		key := keys[col.Name.Lowered()]
+		nullable := "YES"
+		if key == "PRI" {
+			nullable = "NO"
+		}
+		def := sqltypes.NULL
+		var extra []string
+		if opts := col.Type.Options; opts != nil {
+			if opts.Null != nil && !*opts.Null {
+				nullable = "NO"
+			}
+			if opts.Default != nil {
+				var generated bool
+				def, generated = columnDefault(opts.Default)
+				if generated {
+					extra = append(extra, "DEFAULT_GENERATED")
+				}
+			}
+			if opts.Autoincrement {
+				extra = append(extra, "auto_increment")
+			}
+			if opts.OnUpdate != nil {
+				extra = append(extra, "on update "+columnExpr(opts.OnUpdate))
+			}
+			if opts.As != nil {
+				if opts.Storage == sqlparser.StoredStorage {
+					extra = append(extra, "STORED GENERATED")
+				} else {
+					extra = append(extra, "VIRTUAL GENERATED")
+				}
+			}
+			if opts.Invisible != nil && *opts.Invisible {
+				extra = append(extra, "INVISIBLE")
+			}
+		}
+		qr.Rows = append(qr.Rows, []sqltypes.Value{
+			sqltypes.NewVarChar(name),
+			sqltypes.NewVarChar(columnType(col.Type)),
+			sqltypes.NewVarChar(nullable),
+			sqltypes.NewVarChar(key),
+			def,
+			sqltypes.NewVarChar(strings.Join(extra, " ")),
+		})
+	}
+	return qr
+}
+
+// columnType renders the type of the column the way SHOW COLUMNS does.
+func columnType(ct *sqlparser.ColumnType) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(ct.Type))
+	switch {
+	case ct.Length != nil && ct.Scale != nil:
+		fmt.Fprintf(&sb, "(%d,%d)", *ct.Length, *ct.Scale)
+	case ct.Length != nil:
+		fmt.Fprintf(&sb, "(%d)", *ct.Length)
+	case ct.EnumValues != nil:
+		fmt.Fprintf(&sb, "(%s)", strings.Join(ct.EnumValues, ","))
+	}
+	if ct.Unsigned {
+		sb.WriteString(" unsigned")
+	}
+	if ct.Zerofill {
+		sb.WriteString(" zerofill")
+	}
+	return sb.String()
+}
+
+// columnDefault returns the default value of the column, and whether it is
+// an expression rather than a literal.
+func columnDefault(expr sqlparser.Expr) (sqltypes.Value, bool) {
+	switch expr := expr.(type) {
+	case *sqlparser.NullVal:
+		return sqltypes.NULL, false
+	case *sqlparser.Literal:
+		return sqltypes.NewVarChar(expr.Val), false
+	case sqlparser.BoolVal:
+		if expr {
+			return sqltypes.NewVarChar("1"), false
+		}
+		return sqltypes.NewVarChar("0"), false
+	}
+	return sqltypes.NewVarChar(columnExpr(expr)), true
+}
+
+// columnExpr renders the default or on update expression of the column the
+// way SHOW COLUMNS does.
+func columnExpr(expr sqlparser.Expr) string {
+	if fn, ok := expr.(*sqlparser.CurTimeFuncExpr); ok {
+		if fn.Fsp > 0 {
+			return fmt.Sprintf("%s(%d)", strings.ToUpper(fn.Name.String()), fn.Fsp)
+		}
+		return strings.ToUpper(fn.Name.String())
+	}
+	return sqlparser.String(expr)
+}
+
+// columnKeys returns the Key of the indexed columns, keyed by their lowered
+// name: PRI for the columns of the primary key, UNI for the column of a
+// single-column unique index, and MUL for the first column of any other index.
+func columnKeys(info *vindexes.TableInfo) map[string]string {
+	keys := make(map[string]string)
+	rank := map[string]int{"": 0, "MUL": 1, "UNI": 2, "PRI": 3}
+	set := func(col, key string) {
+		if rank[key] > rank[keys[col]] {
+			keys[col] = key
+		}
+	}
+	for _, idx := range info.Indexes {
+		if idx.Info == nil || len(idx.Columns) == 0 {
+			continue
+		}
+		switch {
+		case idx.Info.Type == sqlparser.IndexTypePrimary:
+			for _, col := range idx.Columns {
+				set(col.Column.Lowered(), "PRI")
+			}
+		case idx.Info.IsUnique() && len(idx.Columns) == 1:
+			set(idx.Columns[0].Column.Lowered(), "UNI")
+		default:
+			set(idx.Columns[0].Column.Lowered(), "MUL")
+		}
+	}
+	for _, col := range info.ColumnDefinitions {
+		if col.Type.Options == nil {
+			continue
+		}
+		switch col.Type.Options.KeyOpt {
+		case sqlparser.ColKeyPrimary:
+			set(col.Name.Lowered(), "PRI")
+		case sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+			set(col.Name.Lowered(), "UNI")
+		case sqlparser.ColKey, sqlparser.ColKeySpatialKey, sqlparser.ColKeyFulltextKey:
+			set(col.Name.Lowered(), "MUL")
+		}
+	}
+	return keys
+}
+
+func schemaShowFields(names ...string) []*querypb.Field {
+	fields := make([]*querypb.Field, len(names))
+	for i, name := range names {
+		fields[i] = &querypb.Field{
+			Name:    name,
+			Type:    sqltypes.VarChar,
+			Charset: uint32(collations.SystemCollation.Collation),
+			Flags:   uint32(querypb.MySqlFlag_NOT_NULL_FLAG),
+		}
+	}
+	return fields
+}
+
+func (s *SchemaShow) description() PrimitiveDescription {
+	other := map[string]any{}
+	if s.Table != "" {
+		other["Table"] = s.Table
+	}
+	if s.Full {
+		other["Full"] = true
+	}
+	if s.Filter != nil {
+		other["Filter"] = sqlparser.String(s.Filter)
+	}
+	return PrimitiveDescription{
+		OperatorType: "SchemaShow",
+		Variant:      s.Command.ToString(),
+		Keyspace:     &vindexes.Keyspace{Name: s.Keyspace},
+		Other:        other,
+	}
+}
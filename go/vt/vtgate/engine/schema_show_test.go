@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+type trackedSchemaVCursor struct {
+	noopVCursor
+
+	tables map[string]*vindexes.TableInfo
+	views  map[string]sqlparser.SelectStatement
+	fresh  bool
+}
+
+func (vc *trackedSchemaVCursor) TrackedSchema(string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool) {
+	return vc.tables, vc.views, vc.fresh
+}
+
+func trackedTableInfo(t *testing.T, create string) *vindexes.TableInfo {
+	stmt, err := sqlparser.NewTestParser().Parse(create)
+	require.NoError(t, err)
+	spec := stmt.(*sqlparser.CreateTable).TableSpec
+	return &vindexes.TableInfo{Indexes: spec.Indexes, ColumnDefinitions: spec.Columns}
+}
+
+func TestSchemaShowTables(t *testing.T) {
+	fallback := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("Tables_in_ks", "varchar"), "from_tablet"),
+	}}
+	vc := &trackedSchemaVCursor{
+		tables: map[string]*vindexes.TableInfo{"t1": {}, "t2": {}, "u1": {}},
+		views:  map[string]sqlparser.SelectStatement{"v1": nil},
+		fresh:  true,
+	}
+	show := &SchemaShow{
+		Command:  sqlparser.Table,
+		Keyspace: "ks",
+		DbName:   "ks",
+		Fallback: fallback,
+	}
+
+	qr, err := show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, "Tables_in_ks", qr.Fields[0].Name)
+	require.Equal(t, `[[VARCHAR("t1")] [VARCHAR("t2")] [VARCHAR("u1")] [VARCHAR("v1")]]`, fmt.Sprintf("%v", qr.Rows))
+
+	show.Full = true
+	show.Filter = &sqlparser.ShowFilter{Like: "%1"}
+	qr, err = show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Len(t, qr.Fields, 2)
+	require.Equal(t, `[[VARCHAR("t1") VARCHAR("BASE TABLE")] [VARCHAR("u1") VARCHAR("BASE TABLE")] [VARCHAR("v1") VARCHAR("VIEW")]]`, fmt.Sprintf("%v", qr.Rows))
+
+	// A stale tracked schema sends the query to a tablet.
+	vc.fresh = false
+	qr, err = show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, `[[VARCHAR("from_tablet")]]`, fmt.Sprintf("%v", qr.Rows))
+}
+
+func TestSchemaShowColumns(t *testing.T) {
+	info := trackedTableInfo(t, "create table t1 ("+
+		"id bigint unsigned not null auto_increment, "+
+		"name varchar(64) not null default 'x', "+
+		"price decimal(10,2), "+
+		"kind enum('a','b') default null, "+
+		"owner int, "+
+		"updated timestamp not null default current_timestamp on update current_timestamp, "+
+		"total int as (id + 1) stored, "+
+		"primary key (id), unique key (name), key (owner, kind))")
+	fallback := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("Field", "varchar"), "from_tablet"),
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("Field", "varchar"), "from_tablet"),
+	}}
+	vc := &trackedSchemaVCursor{
+		tables: map[string]*vindexes.TableInfo{"t1": info},
+		fresh:  true,
+	}
+	show := &SchemaShow{
+		Command:  sqlparser.Column,
+		Keyspace: "ks",
+		Table:    "t1",
+		Fallback: fallback,
+	}
+
+	qr, err := show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Len(t, qr.Fields, 6)
+	expected := []string{
+		`[VARCHAR("id") VARCHAR("bigint unsigned") VARCHAR("NO") VARCHAR("PRI") NULL VARCHAR("auto_increment")]`,
+		`[VARCHAR("name") VARCHAR("varchar(64)") VARCHAR("NO") VARCHAR("UNI") VARCHAR("x") VARCHAR("")]`,
+		`[VARCHAR("price") VARCHAR("decimal(10,2)") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")]`,
+		`[VARCHAR("kind") VARCHAR("enum('a','b')") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")]`,
+		`[VARCHAR("owner") VARCHAR("int") VARCHAR("YES") VARCHAR("MUL") NULL VARCHAR("")]`,
+		`[VARCHAR("updated") VARCHAR("timestamp") VARCHAR("NO") VARCHAR("") VARCHAR("CURRENT_TIMESTAMP") VARCHAR("DEFAULT_GENERATED on update CURRENT_TIMESTAMP")]`,
+		`[VARCHAR("total") VARCHAR("int") VARCHAR("YES") VARCHAR("") NULL VARCHAR("STORED GENERATED")]`,
+	}
+	require.Len(t, qr.Rows, len(expected))
+	for i, row := range qr.Rows {
+		require.Equal(t, expected[i], fmt.Sprintf("%v", row))
+	}
+
+	show.Filter = &sqlparser.ShowFilter{Like: "n%"}
+	qr, err = show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 1)
+
+	// The tables the tracker does not know are sent to a tablet.
+	show.Table = "t2"
+	qr, err = show.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, `[[VARCHAR("from_tablet")]]`, fmt.Sprintf("%v", qr.Rows))
+
+	show.Table = "t1"
+	vc.fresh = false
+	qr, err = wrapStreamExecute(show, vc, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, `[[VARCHAR("from_tablet")]]`, fmt.Sprintf("%v", qr.Rows))
+}
@@ -526,6 +526,12 @@ func (svss *SysVarSetAware) Execute(ctx context.Context, vcursor VCursor, env *e
 			return err
 		}
 		vcursor.Session().SetReadAfterWriteTimeout(val)
+	case sysvars.VitessSession.Name:
+		str, err := svss.evalAsString(env, vcursor)
+		if err != nil {
+			return err
+		}
+		return vcursor.Session().ImportSession(str)
 	case sysvars.SessionTrackGTIDs.Name:
 		str, err := svss.evalAsString(env, vcursor)
 		if err != nil {
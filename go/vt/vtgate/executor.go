@@ -40,6 +40,7 @@ import (
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
@@ -77,6 +78,8 @@ var (
 	queriesProcessedByTable = stats.NewCountersWithMultiLabels("QueriesProcessedByTable", "Queries processed at vtgate by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
 	queriesRoutedByTable    = stats.NewCountersWithMultiLabels("QueriesRoutedByTable", "Queries routed from vtgate to vttablet by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
 
+	trackedSchemaQueries = stats.NewCountersWithSingleLabel("TrackedSchemaQueries", "Number of the metadata queries which could be answered from the tracked schema, by whether it was fresh enough", "Result")
+
 	exceedMemoryRowsLogger = logutil.NewThrottledLogger("ExceedMemoryRows", 1*time.Minute)
 
 	errorTransform errorTransformer = nullErrorTransformer{}
@@ -115,6 +118,11 @@ type Executor struct {
 
 	plans *PlanCache
 	epoch atomic.Uint32
+	// pins are the plans of the queries pinned in the PlanCacheControl, and
+	// previousPlans the fingerprints of the plans cached before the last
+	// vschema or schema change.
+	pins          planPins
+	previousPlans previousPlans
 
 	normalize       bool
 	warnShardedOnly bool
@@ -130,6 +138,10 @@ type Executor struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// queryMetrics records the queries per keyspace, shard and table. It is
+	// nil when --query-metrics-dimensions is not set.
+	queryMetrics *queryMetrics
 }
 
 var executorOnce sync.Once
@@ -210,6 +222,9 @@ func NewExecutor(
 		stats.NewCounterFunc("QueryPlanCacheMisses", "Query plan cache misses", func() int64 {
 			return e.plans.Metrics.Hits()
 		})
+		stats.NewGaugeFunc("QueryPlanCachePinned", "Number of pinned query plans", func() int64 {
+			return int64(e.pins.len())
+		})
 		servenv.HTTPHandle(pathQueryPlans, e)
 		servenv.HTTPHandle(pathScatterStats, e)
 		servenv.HTTPHandle(pathVSchema, e)
@@ -225,6 +240,7 @@ func (e *Executor) Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 	defer span.Finish()
 
 	logStats := logstats.NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
+	logStats.ClientProgram, logStats.ClientVersion = safeSession.GetClientProgram()
 	stmtType, result, err := e.execute(ctx, mysqlCtx, safeSession, sql, bindVars, logStats)
 	logStats.Error = err
 	if result == nil {
@@ -262,6 +278,14 @@ type streaminResultReceiver struct {
 	rowsReturned int
 	insertID     uint64
 	callback     func(*sqltypes.Result) error
+
+	// lastInsertIDColumn is the column holding the value of the
+	// LAST_INSERT_ID(expr) select expression, lastInsertIDOffset its offset
+	// in the fields once they are received and lastInsertIDExpr its value
+	// in the last row received.
+	lastInsertIDColumn string
+	lastInsertIDOffset int
+	lastInsertIDExpr   *uint64
 }
 
 func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, qr *sqltypes.Result) error {
@@ -269,9 +293,18 @@ func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, q
 	defer s.mu.Unlock()
 	s.rowsAffected += qr.RowsAffected
 	s.rowsReturned += len(qr.Rows)
-	if qr.InsertID != 0 {
+	if qr.InsertID != 0 && (s.insertID == 0 || qr.InsertID < s.insertID) {
 		s.insertID = qr.InsertID
 	}
+	if s.lastInsertIDColumn != "" {
+		if len(qr.Fields) > 0 {
+			s.lastInsertIDOffset = fieldOffset(qr.Fields, s.lastInsertIDColumn)
+		}
+		if len(qr.Rows) > 0 && s.lastInsertIDOffset >= 0 {
+			value := lastInsertIDValue(qr.Rows[len(qr.Rows)-1], s.lastInsertIDOffset)
+			s.lastInsertIDExpr = &value
+		}
+	}
 	s.stmtType = typ
 	return s.callback(qr)
 }
@@ -292,6 +325,7 @@ func (e *Executor) StreamExecute(
 	defer span.Finish()
 
 	logStats := logstats.NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
+	logStats.ClientProgram, logStats.ClientVersion = safeSession.GetClientProgram()
 	srr := &streaminResultReceiver{callback: callback}
 	var err error
 
@@ -335,6 +369,9 @@ func (e *Executor) StreamExecute(
 			}
 		}
 
+		srr.lastInsertIDColumn = lastInsertIDColumn(plan)
+		srr.lastInsertIDOffset = -1
+
 		// 4: Execute!
 		err := vc.StreamExecutePrimitive(ctx, plan.Instructions, bindVars, true, func(qr *sqltypes.Result) error {
 			return srr.storeResultStats(plan.Type, qr)
@@ -366,6 +403,7 @@ func (e *Executor) StreamExecute(
 		logStats.ActiveKeyspace = vc.keyspace
 
 		e.updateQueryCounts(plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
+		e.recordQueryMetrics(plan, vc, logStats)
 
 		return err
 	}
@@ -374,6 +412,9 @@ func (e *Executor) StreamExecute(
 
 	logStats.Error = err
 	saveSessionStats(safeSession, srr.stmtType, srr.rowsAffected, srr.insertID, srr.rowsReturned, err)
+	if err == nil && srr.lastInsertIDExpr != nil {
+		safeSession.LastInsertId = *srr.lastInsertIDExpr
+	}
 	if srr.rowsReturned > warnMemoryRows {
 		warnings.Add("ResultsExceeded", 1)
 		piiSafeSQL, err := e.env.Parser().RedactSQLQuery(sql)
@@ -425,6 +466,34 @@ func saveSessionStats(safeSession *SafeSession, stmtType sqlparser.StatementType
 	}
 }
 
+// lastInsertIDColumn returns the column holding the value of the
+// LAST_INSERT_ID(expr) select expression of the plan, if any.
+func lastInsertIDColumn(plan *engine.Plan) string {
+	if plan.BindVarNeeds == nil {
+		return ""
+	}
+	return plan.BindVarNeeds.LastInsertIDColumn
+}
+
+func fieldOffset(fields []*querypb.Field, name string) int {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastInsertIDValue returns the value of the LAST_INSERT_ID(expr) select
+// expression in the row, which is 0 when expr is NULL.
+func lastInsertIDValue(row sqltypes.Row, offset int) uint64 {
+	if offset >= len(row) || row[offset].IsNull() {
+		return 0
+	}
+	value, _ := row[offset].ToCastUint64()
+	return value
+}
+
 func (e *Executor) execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConnection, safeSession *SafeSession, sql string, bindVars map[string]*querypb.BindVariable, logStats *logstats.LogStats) (sqlparser.StatementType, *sqltypes.Result, error) {
 	var err error
 	var qr *sqltypes.Result
@@ -443,7 +512,7 @@ func (e *Executor) execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 }
 
 // addNeededBindVars adds bind vars that are needed by the plan
-func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlparser.BindVarNeeds, bindVars map[string]*querypb.BindVariable, session *SafeSession) error {
+func (e *Executor) addNeededBindVars(ctx context.Context, vcursor *vcursorImpl, bindVarNeeds *sqlparser.BindVarNeeds, bindVars map[string]*querypb.BindVariable, session *SafeSession) error {
 	for _, funcName := range bindVarNeeds.NeedFunctionResult {
 		switch funcName {
 		case sqlparser.DBVarName:
@@ -454,6 +523,11 @@ func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlpars
 			bindVars[sqlparser.FoundRowsName] = sqltypes.Int64BindVariable(int64(session.FoundRows))
 		case sqlparser.RowCountName:
 			bindVars[sqlparser.RowCountName] = sqltypes.Int64BindVariable(session.RowCount)
+		case sqlparser.ConnectionIDName:
+			// The ID of the MySQL connection of the client, which can be
+			// killed with KILL, or 0 for the other protocols.
+			connectionID, _ := callinfo.MysqlConnectionID(ctx)
+			bindVars[sqlparser.ConnectionIDName] = sqltypes.Uint64BindVariable(uint64(connectionID))
 		}
 	}
 
@@ -500,6 +574,12 @@ func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlpars
 			bindVars[key] = sqltypes.StringBindVariable(session.MigrationContext)
 		case sysvars.SessionUUID.Name:
 			bindVars[key] = sqltypes.StringBindVariable(session.SessionUUID)
+		case sysvars.VitessSession.Name:
+			exported, err := session.Export()
+			if err != nil {
+				return err
+			}
+			bindVars[key] = sqltypes.StringBindVariable(exported)
 		case sysvars.SessionEnableSystemSettings.Name:
 			bindVars[key] = sqltypes.BoolBindVariable(session.EnableSystemSettings)
 		case sysvars.ReadAfterWriteGTID.Name:
@@ -701,7 +781,7 @@ func (e *Executor) handleKill(ctx context.Context, mysqlCtx vtgateservice.MySQLC
 	killStmt := stmt.(*sqlparser.Kill)
 	switch killStmt.Type {
 	case sqlparser.QueryType:
-		err = mysqlCtx.KillQuery(uint32(killStmt.ProcesslistID))
+		err = mysqlCtx.KillQuery(ctx, uint32(killStmt.ProcesslistID))
 	default:
 		err = mysqlCtx.KillConnection(ctx, uint32(killStmt.ProcesslistID))
 	}
@@ -1048,6 +1128,22 @@ func (e *Executor) VSchema() *vindexes.VSchema {
 	return e.vschema
 }
 
+// trackedSchema returns the tracked tables and views of the keyspace, or false
+// if the schema tracker does not know them to have been up to date within
+// --schema-tracker-metadata-max-staleness.
+func (e *Executor) trackedSchema(keyspace string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool) {
+	if e.schemaTracker == nil || schemaTrackerMetadataMaxStaleness <= 0 {
+		return nil, nil, false
+	}
+	synced, ok := e.schemaTracker.LastSynced(keyspace)
+	if !ok || time.Since(synced) > schemaTrackerMetadataMaxStaleness {
+		trackedSchemaQueries.Add("Stale", 1)
+		return nil, nil, false
+	}
+	trackedSchemaQueries.Add("Fresh", 1)
+	return e.schemaTracker.Tables(keyspace), e.schemaTracker.Views(keyspace), true
+}
+
 // SaveVSchema updates the vschema and stats
 func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	e.mu.Lock()
@@ -1056,6 +1152,7 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 		e.vschema = vschema
 	}
 	e.vschemaStats = stats
+	e.snapshotPlans()
 	e.ClearPlans()
 
 	if vschemaCounters != nil {
@@ -1114,12 +1211,28 @@ func (e *Executor) getPlan(
 		return nil, err
 	}
 	vcursor.SetPriority(priority)
+	maxStaleness, err := sqlparser.GetMaxStalenessFromStatement(stmt)
+	if err != nil {
+		return nil, err
+	}
+	vcursor.SetMaxStaleness(maxStaleness)
+	if err := vcursor.SetPinnedTablet(callerid.ImmediateCallerIDFromContext(ctx)); err != nil {
+		return nil, err
+	}
 
 	setVarComment, err := prepareSetVarComment(vcursor, stmt)
 	if err != nil {
 		return nil, err
 	}
 
+	if vcursor.tenant != nil {
+		// Restrict the statement to the rows of the tenant before normalizing
+		// it, so that the tenants of a keyspace share the same plans.
+		if err := vcursor.tenant.Restrict(stmt, vcursor.vschema.Keyspaces[vcursor.keyspace], bindVars); err != nil {
+			return nil, err
+		}
+	}
+
 	// Normalize if possible
 	shouldNormalize := e.canNormalizeStatement(stmt, setVarComment)
 	parameterize := allowParameterization && shouldNormalize
@@ -1141,7 +1254,7 @@ func (e *Executor) getPlan(
 	}
 	stmt = rewriteASTResult.AST
 	bindVarNeeds := rewriteASTResult.BindVarNeeds
-	if shouldNormalize {
+	if shouldNormalize || vcursor.tenant != nil {
 		query = sqlparser.String(stmt)
 	}
 
@@ -1196,7 +1309,16 @@ func (e *Executor) cacheAndBuildStatement(
 		var plan *engine.Plan
 		var err error
 		plan, logStats.CachedPlan, err = e.plans.GetOrLoad(planKey, e.epoch.Load(), func() (*engine.Plan, error) {
-			return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds)
+			if plan := e.pins.get(planKey); plan != nil {
+				return plan, nil
+			}
+			plan, err := e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds)
+			if err != nil {
+				return nil, err
+			}
+			e.recordPlanRebuild(planKey, plan)
+			e.pins.pin(planKey, vcursor.keyspace, query, plan, e.env.Parser())
+			return plan, nil
 		})
 		return plan, err
 	}
@@ -1299,6 +1421,19 @@ func (e *Executor) updateQueryCounts(planType, keyspace, tableName string, shard
 	}
 }
 
+// recordQueryMetrics records the query in the query metrics, if they are
+// enabled.
+func (e *Executor) recordQueryMetrics(plan *engine.Plan, vc *vcursorImpl, logStats *logstats.LogStats) {
+	if e.queryMetrics == nil {
+		return
+	}
+	var shard string
+	if vc.queryShard != nil {
+		shard = vc.queryShard.get()
+	}
+	e.queryMetrics.record(plan.Instructions.GetKeyspaceName(), shard, plan.Instructions.GetTableName(), time.Since(logStats.StartTime))
+}
+
 // VSchemaStats returns the loaded vschema stats.
 func (e *Executor) VSchemaStats() *VSchemaStats {
 	e.mu.Lock()
@@ -1350,6 +1485,7 @@ func isValidPayloadSize(query string) bool {
 // Prepare executes a prepare statements.
 func (e *Executor) Prepare(ctx context.Context, method string, safeSession *SafeSession, sql string, bindVars map[string]*querypb.BindVariable) (fld []*querypb.Field, err error) {
 	logStats := logstats.NewLogStats(ctx, method, sql, safeSession.GetSessionUUID(), bindVars)
+	logStats.ClientProgram, logStats.ClientVersion = safeSession.GetClientProgram()
 	fld, err = e.prepare(ctx, safeSession, sql, bindVars, logStats)
 	logStats.Error = err
 
@@ -1421,7 +1557,7 @@ func (e *Executor) handlePrepare(ctx context.Context, safeSession *SafeSession,
 		return nil, err
 	}
 
-	err = e.addNeededBindVars(vcursor, plan.BindVarNeeds, bindVars, safeSession)
+	err = e.addNeededBindVars(ctx, vcursor, plan.BindVarNeeds, bindVars, safeSession)
 	if err != nil {
 		logStats.Error = err
 		return nil, err
@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -792,6 +793,36 @@ func TestDeleteScatter(t *testing.T) {
 	assertQueries(t, sbc2, wantQueries)
 }
 
+func TestUpdateScatterVersionConflict(t *testing.T) {
+	var conns []*sandboxconn.SandboxConn
+	executor, ctx := createExecutorEnvCallback(t, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		if ks == KsTestSharded {
+			conns = append(conns, conn)
+		}
+	})
+	conflictErr := sqlerror.NewSQLError(sqlerror.ERVersionConflict, sqlerror.SSLockDeadlock, "version conflict")
+
+	session := &vtgatepb.Session{
+		TargetString: "@primary",
+		Options:      &querypb.ExecuteOptions{VersionConflictError: true},
+	}
+	// The row matched the version on one of the shards only.
+	for _, conn := range conns[1:] {
+		conn.EphemeralShardErr = conflictErr
+	}
+	qr, err := executorExec(ctx, executor, session, "update user_extra set col = 2 where version = 3", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, qr.RowsAffected)
+
+	// The row matched the version on none of the shards.
+	for _, conn := range conns {
+		conn.EphemeralShardErr = conflictErr
+	}
+	_, err = executorExec(ctx, executor, session, "update user_extra set col = 2 where version = 3", nil)
+	require.ErrorContains(t, err, "version conflict")
+	assert.Equal(t, sqlerror.ERVersionConflict, sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number())
+}
+
 func TestUpdateEqualWithMultipleLookupVindex(t *testing.T) {
 	executor, sbc1, sbc2, sbcLookup, ctx := createExecutorEnv(t)
 
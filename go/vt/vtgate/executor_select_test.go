@@ -31,10 +31,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	_flag "vitess.io/vitess/go/internal/flag"
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/discovery"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -755,6 +757,49 @@ func TestSelectLastInsertId(t *testing.T) {
 	utils.MustMatch(t, wantResult, result, "Mismatch")
 }
 
+func TestSelectLastInsertIdExpr(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+	session := &vtgatepb.Session{
+		TargetString: "@primary",
+		LastInsertId: 52,
+	}
+	executor.normalize = true
+
+	result, err := executorExec(ctx, executor, session, "select last_insert_id(7)", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[UINT64(7)]]`, fmt.Sprintf("%v", result.Rows))
+	assert.EqualValues(t, 7, session.LastInsertId)
+
+	result, err = executorExec(ctx, executor, session, "select last_insert_id()", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[UINT64(7)]]`, fmt.Sprintf("%v", result.Rows))
+
+	_, err = executorExec(ctx, executor, session, "select last_insert_id(null) as x", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, session.LastInsertId)
+
+	// A LAST_INSERT_ID(expr) that is not a select expression of its own is
+	// left to the shards.
+	_, err = executorExec(ctx, executor, session, "select last_insert_id(9) + 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, session.LastInsertId)
+}
+
+func TestStreamSelectLastInsertIdExpr(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+	session := &vtgatepb.Session{
+		TargetString: "@primary",
+	}
+	executor.normalize = true
+
+	err := executor.StreamExecute(ctx, nil, "TestExecuteStream", NewSafeSession(session), "select last_insert_id(11)", nil,
+		func(qr *sqltypes.Result) error {
+			return nil
+		})
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, session.LastInsertId)
+}
+
 func TestSelectSystemVariables(t *testing.T) {
 	executor, _, _, _, ctx := createExecutorEnv(t)
 
@@ -943,6 +988,27 @@ func testRowCount(t *testing.T, ctx context.Context, executor *Executor, session
 	utils.MustMatch(t, wantResult, result, "Mismatch")
 }
 
+func TestSelectConnectionID(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	executor.normalize = true
+	session := &vtgatepb.Session{TargetString: "@primary"}
+
+	// CONNECTION_ID() is the ID of the MySQL connection to vtgate, which
+	// can be killed, and not the one of a connection of a tablet.
+	conn := mysql.GetTestConn()
+	conn.ConnectionID = 42
+	result, err := executorExec(callinfo.MysqlCallInfo(ctx, conn), executor, session, "select connection_id()", nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "42", result.Rows[0][0].ToString())
+	assert.Empty(t, sbc1.Queries)
+
+	// It is 0 for the other protocols.
+	result, err = executorExec(ctx, executor, session, "select connection_id()", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0", result.Rows[0][0].ToString())
+}
+
 func TestSelectLastInsertIdInUnion(t *testing.T) {
 	executor, sbc1, _, _, ctx := createExecutorEnv(t)
 	executor.normalize = true
@@ -44,9 +44,12 @@ import (
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/logstats"
+	"vitess.io/vitess/go/vt/vtgate/tenants"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
@@ -1137,6 +1140,43 @@ func TestExecutorUse(t *testing.T) {
 	require.EqualError(t, err, "VT05003: unknown database 'UnexistentKeyspace' in vschema")
 }
 
+func TestExecutorTenant(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+	executor.VSchema().Keyspaces[KsTestSharded].MultiTenantSpec = &vschemapb.MultiTenantSpec{
+		TenantIdColumnName: "id",
+		TenantIdColumnType: querypb.Type_INT64,
+	}
+	require.NoError(t, tenants.Set(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{
+			"customer1": {Keyspace: KsTestSharded, TenantId: "1"},
+		},
+	}))
+	defer func() { _ = tenants.Set(nil) }()
+
+	session := NewSafeSession(&vtgatepb.Session{Autocommit: true, TargetString: "@primary"})
+	_, err := executor.Execute(ctx, nil, "TestExecute", session, "use customer1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "customer1", session.TargetString)
+
+	// The tenant predicate routes the query to the shard of the tenant.
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "select name from customer1.user where name = 'foo'", nil)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Queries, 1)
+	assert.Equal(t, "select `name` from `user` where `name` = 'foo' and `user`.id = 1", sbc1.Queries[0].Sql)
+	assert.Empty(t, sbc2.Queries)
+
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, "update user set id = 2 where name = 'foo'", nil)
+	assert.ErrorContains(t, err, "id must be 1 in tenant database customer1")
+
+	// The session cannot leave the database of the tenant, whose keyspace it
+	// would then read without the tenant predicate.
+	for _, keyspace := range []string{KsTestSharded, "customer2", "information_schema"} {
+		_, err = executor.Execute(ctx, nil, "TestExecute", session, "use "+keyspace, nil)
+		require.EqualError(t, err, "cannot change the database of tenant database customer1 to "+keyspace)
+		assert.Equal(t, "customer1", session.TargetString)
+	}
+}
+
 func TestExecutorComment(t *testing.T) {
 	executor, _, _, _, ctx := createExecutorEnv(t)
 
@@ -1793,6 +1833,115 @@ func TestGetPlanPriority(t *testing.T) {
 
 }
 
+func TestExecutorMaxStaleness(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	session := &vtgatepb.Session{TargetString: "@primary"}
+
+	_, err := executorExec(ctx, executor, session, "select /*vt+ MAX_STALENESS_MS=500 */ id from `user` where id = 1", nil)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Options, 1)
+	assert.EqualValues(t, 500, sbc1.Options[0].GetMaxStalenessMs())
+
+	// The directive only applies to the query it is set on.
+	_, err = executorExec(ctx, executor, session, "select id from `user` where id = 1", nil)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Options, 2)
+	assert.Zero(t, sbc1.Options[1].GetMaxStalenessMs())
+
+	_, err = executorExec(ctx, executor, session, "select /*vt+ MAX_STALENESS_MS=soon */ id from `user` where id = 1", nil)
+	assert.ErrorIs(t, err, sqlparser.ErrInvalidMaxStaleness)
+}
+
+func TestExecutorLoadShedding(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	ls, err := newLoadShedder(0.5, time.Minute, 1, 50, map[string]string{"batch": "100"})
+	require.NoError(t, err)
+	ls.random = func() float64 { return 0.99 }
+	executor.resolver.scatterConn.gateway.loadShedder = ls
+	exec := func(session *vtgatepb.Session, query string) error {
+		_, err := executorExec(ctx, executor, session, query, nil)
+		return err
+	}
+
+	// Only the lowest priority is shed under a low pressure.
+	ls.pressure = 0.5
+	err = exec(&vtgatepb.Session{TargetString: "@primary"}, "select /*vt+ WORKLOAD_NAME=batch */ id from `user` where id = 1")
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.ErrorContains(t, err, `vtgate is shedding load: statement of priority 100 of workload "batch" rejected, retry after 10m0s`)
+	require.NoError(t, exec(&vtgatepb.Session{TargetString: "@primary"}, "select id from `user` where id = 1"))
+
+	// The statements of priority 0 are never shed.
+	ls.pressure = 1
+	require.NoError(t, exec(&vtgatepb.Session{TargetString: "@primary"}, "select /*vt+ WORKLOAD_NAME=batch PRIORITY=0 */ id from `user` where id = 1"))
+	assert.ErrorContains(t, exec(&vtgatepb.Session{TargetString: "@primary"}, "select id from `user` where id = 1"), "vtgate is shedding load")
+
+	// Nor are the statements of a transaction which already did some work.
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	require.NoError(t, exec(session, "begin"))
+	require.NoError(t, exec(session, "select /*vt+ PRIORITY=0 */ id from `user` where id = 1"))
+	require.NoError(t, exec(session, "select id from `user` where id = 1"))
+	require.NoError(t, exec(session, "commit"))
+	assert.EqualValues(t, 1, sbc1.CommitCount.Load())
+}
+
+func TestExecutorTabletPinning(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, ctx, 0)
+	defer func(users []string) {
+		tabletPinningAuthorizedUsers = users
+	}(tabletPinningAuthorizedUsers)
+
+	replicaAlias := topoproto.TabletAliasString(replica.Tablet().Alias)
+	session := &vtgatepb.Session{TargetString: KsTestUnsharded + ":0@replica|" + replicaAlias, Autocommit: true}
+	query := "select id from t1"
+
+	// Tablet pinning is denied by default.
+	_, err := executorExec(ctx, executor, session, query, nil)
+	require.ErrorContains(t, err, "is not authorized to pin the session to tablet "+replicaAlias)
+	assert.Empty(t, replica.Queries)
+
+	tabletPinningAuthorizedUsers = []string{"%"}
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	require.Len(t, replica.Options, 1)
+	utils.MustMatch(t, replica.Tablet().Alias, replica.Options[0].GetPinnedTabletAlias())
+
+	// The queries are routed to the pinned tablet, which rejects them if its
+	// type is not the one of the target.
+	primaryAlias := topoproto.TabletAliasString(primary.Tablet().Alias)
+	session.TargetString = KsTestUnsharded + ":0@replica|" + primaryAlias
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.ErrorContains(t, err, "wrong tablet type")
+	assert.Len(t, replica.Queries, 1)
+
+	session.TargetString = KsTestUnsharded + ":0@primary|" + primaryAlias
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	require.Len(t, primary.Options, 1)
+	utils.MustMatch(t, primary.Tablet().Alias, primary.Options[0].GetPinnedTabletAlias())
+
+	// The queries of the session are no longer pinned once it targets a tablet type.
+	session.TargetString = KsTestUnsharded + "@replica"
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	require.Len(t, replica.Options, 2)
+	assert.Nil(t, replica.Options[1].GetPinnedTabletAlias())
+
+	session.TargetString = KsTestUnsharded + "@replica|" + replicaAlias
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.ErrorContains(t, err, "must target a shard")
+
+	// A session cannot be pinned to a tablet in a transaction.
+	_, err = executorExec(ctx, executor, &vtgatepb.Session{TargetString: KsTestUnsharded, InTransaction: true}, "use `"+KsTestUnsharded+":0@primary|"+primaryAlias+"`", nil)
+	require.ErrorContains(t, err, "active transaction")
+
+	// The pinned tablet must be known by the healthcheck.
+	session.TargetString = KsTestUnsharded + ":0@replica|aa-0000009999"
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.ErrorContains(t, err, "tablet aa-0000009999 not found")
+}
+
 func TestPassthroughDDL(t *testing.T) {
 	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
 	session := &vtgatepb.Session{
@@ -2649,6 +2798,68 @@ func TestExecutorShowVitessMigrations(t *testing.T) {
 	assert.Contains(t, sbc2.StringQueries(), "show vitess_migrations")
 }
 
+func TestExecutorShowVitessWorkflows(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+
+	showQuery := "show vitess_workflows like 'wf%'"
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "TestExecutor"})
+	_, err := executor.Execute(ctx, nil, "", session, showQuery, nil)
+	require.NoError(t, err)
+	assert.Contains(t, sbc1.StringQueries(), "show vitess_workflows like 'wf%'")
+	assert.Contains(t, sbc2.StringQueries(), "show vitess_workflows like 'wf%'")
+
+	// The workflows are only served by the primary tablets.
+	session = NewSafeSession(&vtgatepb.Session{TargetString: "TestExecutor@replica"})
+	_, err = executor.Execute(ctx, nil, "", session, showQuery, nil)
+	require.ErrorContains(t, err, "VT09006")
+}
+
+func TestExecutorShowVitessSession(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "TestExecutor", Autocommit: true, LastInsertId: 7})
+	for _, query := range []string{"set @foo = 42", "set transaction_mode = 'single'", "set ddl_strategy = 'online'", "begin", "savepoint a"} {
+		_, err := executor.Execute(ctx, nil, "", session, query, nil)
+		require.NoError(t, err, query)
+	}
+	qr, err := executor.Execute(ctx, nil, "", session, "show vitess_session", nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 1)
+	exported := qr.Rows[0][0].ToString()
+	assert.Contains(t, qr.Rows[0][1].ToString(), `"targetString":"TestExecutor"`)
+
+	// The transaction did not start on any tablet yet, so it is exported with
+	// its savepoints.
+	imported := NewSafeSession(&vtgatepb.Session{})
+	_, err = executor.Execute(ctx, nil, "", imported, fmt.Sprintf("set vitess_session = '%s'", exported), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "TestExecutor", imported.TargetString)
+	assert.EqualValues(t, 7, imported.LastInsertId)
+	assert.Equal(t, vtgatepb.TransactionMode_SINGLE, imported.TransactionMode)
+	assert.Equal(t, "online", imported.DDLStrategy)
+	assert.Equal(t, sqltypes.Int64BindVariable(42), imported.UserDefinedVariables["foo"])
+	assert.True(t, imported.InTransaction())
+	assert.Equal(t, []string{"savepoint a"}, imported.Savepoints)
+
+	qr, err = executor.Execute(ctx, nil, "", imported, "select @@vitess_session from dual", nil)
+	require.NoError(t, err)
+	reimported, err := ImportSession(qr.Rows[0][0].ToString())
+	require.NoError(t, err)
+	assert.Equal(t, "TestExecutor", reimported.TargetString)
+	assert.Equal(t, []string{"savepoint a"}, reimported.Savepoints)
+
+	// Once the transaction started on a tablet, the session cannot be exported.
+	_, err = executor.Execute(ctx, nil, "", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "", session, "show vitess_session", nil)
+	require.ErrorContains(t, err, "cannot export a session with a transaction open on the tablets")
+	_, err = executor.Execute(ctx, nil, "", session, fmt.Sprintf("set vitess_session = '%s'", exported), nil)
+	require.ErrorContains(t, err, "cannot import a session into a session with a transaction or reserved connections")
+
+	_, err = executor.Execute(ctx, nil, "", imported, "set vitess_session = 'not a session'", nil)
+	require.ErrorContains(t, err, "invalid exported session")
+}
+
 func TestExecutorDescHash(t *testing.T) {
 	executor, _, _, _, ctx := createExecutorEnv(t)
 
@@ -2912,7 +3123,7 @@ type fakeMysqlConnection struct {
 	Log    []string
 }
 
-func (f *fakeMysqlConnection) KillQuery(connID uint32) error {
+func (f *fakeMysqlConnection) KillQuery(ctx context.Context, connID uint32) error {
 	if f.ErrMsg != "" {
 		return errors.New(f.ErrMsg)
 	}
@@ -2937,3 +3148,82 @@ func exec(executor *Executor, session *SafeSession, sql string) (*sqltypes.Resul
 func makeComments(text string) sqlparser.MarginComments {
 	return sqlparser.MarginComments{Trailing: text}
 }
+
+func TestExecutorReadOnlyTransactionOnReplica(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, ctx, 0)
+
+	defer func(old bool) { readOnlyTxOnReplicas = old }(readOnlyTxOnReplicas)
+	readOnlyTxOnReplicas = true
+
+	session := &vtgatepb.Session{TargetString: KsTestUnsharded}
+	_, err := executorExec(ctx, executor, session, "start transaction read only", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select id from main1", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select id from main1 where id = 1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+	assert.EqualValues(t, 1, replica.BeginCount.Load())
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+	require.Len(t, session.ShardSessions, 1)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, session.ShardSessions[0].Target.TabletType)
+
+	_, err = executorExec(ctx, executor, session, "commit", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, replica.CommitCount.Load())
+
+	// the next statements run on the primary again
+	_, err = executorExec(ctx, executor, session, "select id from main1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+
+	// read write transactions run on the primary
+	_, err = executorExec(ctx, executor, session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select id from main1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, primary.ExecCount.Load())
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+}
+
+func TestReadCellPreferences(t *testing.T) {
+	prefs := []*vschemapb.ReadCellPreference{{Cells: []string{"us-west"}}}
+	vs := vindexes.BuildVSchema(&vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {},
+					"t2": {ReadCellPreferences: prefs},
+				},
+			},
+		},
+	}, sqlparser.NewTestParser())
+
+	assert.Equal(t, prefs, readCellPreferences(vs, []string{"ks.t1", "ks.t2"}))
+	assert.Nil(t, readCellPreferences(vs, []string{"ks.t1", "other.t2"}))
+	assert.Nil(t, readCellPreferences(nil, []string{"ks.t2"}))
+}
+
+func TestKeyspaceTabletTags(t *testing.T) {
+	tags := map[string]string{"role": "reporting"}
+	vs := vindexes.BuildVSchema(&vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"ks1": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {},
+				},
+			},
+			"ks2": {
+				Tables: map[string]*vschemapb.Table{
+					"t2": {},
+				},
+				TabletTags: tags,
+			},
+		},
+	}, sqlparser.NewTestParser())
+
+	assert.Equal(t, map[string]map[string]string{"ks2": tags}, keyspaceTabletTags(vs, []string{"ks1.t1", "ks2.t2"}))
+	assert.Nil(t, keyspaceTabletTags(vs, []string{"ks1.t1", "other.t2"}))
+	assert.Nil(t, keyspaceTabletTags(nil, []string{"ks2.t2"}))
+}
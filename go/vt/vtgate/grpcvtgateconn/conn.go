@@ -38,11 +38,12 @@ import (
 )
 
 var (
-	cert string
-	key  string
-	ca   string
-	crl  string
-	name string
+	cert      string
+	key       string
+	ca        string
+	crl       string
+	name      string
+	spiffeIDs []string
 )
 
 func init() {
@@ -65,6 +66,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca, "vtgate_grpc_ca", "", "the server ca to use to validate servers when connecting")
 	fs.StringVar(&crl, "vtgate_grpc_crl", "", "the server crl to use to validate server certificates when connecting")
 	fs.StringVar(&name, "vtgate_grpc_server_name", "", "the server name to use to validate server certificate")
+	fs.StringSliceVar(&spiffeIDs, "vtgate_grpc_spiffe_ids", nil, "comma-separated list of SPIFFE IDs allowed in the server certificate, checked instead of the server name. An ID ending with /* allows all the IDs under it")
 }
 
 type vtgateConn struct {
@@ -79,7 +81,7 @@ func dial(ctx context.Context, addr string) (vtgateconn.Impl, error) {
 // Dial produces a vtgateconn.DialerFunc with custom options.
 func Dial(opts ...grpc.DialOption) vtgateconn.DialerFunc {
 	return func(ctx context.Context, address string) (vtgateconn.Impl, error) {
-		opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+		opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 		if err != nil {
 			return nil, err
 		}
@@ -274,6 +276,16 @@ func (conn *vtgateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	}, nil
 }
 
+func (conn *vtgateConn) VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error {
+	request := &vtgatepb.VStreamAckRequest{
+		CallerId:      callerid.EffectiveCallerIDFromContext(ctx),
+		ConsumerGroup: consumerGroup,
+		Vgtid:         vgtid,
+	}
+	_, err := conn.c.VStreamAck(ctx, request)
+	return vterrors.FromGRPC(err)
+}
+
 func (conn *vtgateConn) Close() {
 	conn.cc.Close()
 }
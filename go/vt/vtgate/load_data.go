@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/loaddata"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	// mysqlServerLocalInfile lets the clients load their files with LOAD
+	// DATA LOCAL INFILE.
+	mysqlServerLocalInfile bool
+	// mysqlServerLocalInfileBatchSize is the number of rows of the files
+	// inserted by each statement.
+	mysqlServerLocalInfileBatchSize = 1000
+
+	loadDataRows = stats.NewCountersWithSingleLabel("LoadDataRows", "Number of rows inserted by LOAD DATA LOCAL INFILE statements, by table", "Table")
+)
+
+// loadDataProgressInterval is how often the progress of a LOAD DATA LOCAL
+// INFILE statement is logged.
+const loadDataProgressInterval = 30 * time.Second
+
+// loadData runs a LOAD DATA LOCAL INFILE statement: it reads the rows of the
+// file the client sends, and inserts them in batches of
+// mysqlServerLocalInfileBatchSize rows, which the executor routes to the
+// shards of the rows and which maintain the lookup vindexes, as any insert
+// does.
+//
+// Outside of a transaction, each batch commits on its own: if the statement
+// fails, its error tells how many lines of the file to ignore to load the
+// rest of it.
+func (vh *vtgateHandler) loadData(ctx context.Context, c *mysql.Conn, session *vtgatepb.Session, stmt *loaddata.Statement) (*vtgatepb.Session, *sqltypes.Result, error) {
+	file, err := c.RequestLocalInfile(stmt.Filename)
+	if err != nil {
+		return session, nil, err
+	}
+	// Whatever happens, the client sends the whole file before reading the
+	// result of the statement.
+	defer file.Close()
+
+	table := stmt.Table.Name.String()
+	rows := loaddata.NewReader(file, stmt)
+	if err := rows.Skip(stmt.IgnoreLines); err != nil {
+		return session, nil, err
+	}
+
+	var (
+		records, affected uint64
+		batch             = make([][]sqltypes.Value, 0, mysqlServerLocalInfileBatchSize)
+		lastProgress      = time.Now()
+	)
+	insert := func() error {
+		var qr *sqltypes.Result
+		session, qr, err = vh.vtg.Execute(ctx, vh, session, stmt.Insert(batch), nil)
+		if err != nil {
+			return err
+		}
+		records += uint64(len(batch))
+		affected += qr.RowsAffected
+		loadDataRows.Add(table, int64(len(batch)))
+		batch = batch[:0]
+		if time.Since(lastProgress) > loadDataProgressInterval {
+			lastProgress = time.Now()
+			log.Infof("LOAD DATA LOCAL INFILE '%s' INTO TABLE %s: %d rows inserted", stmt.Filename, table, records)
+		}
+		return nil
+	}
+	// failed annotates the errors with the progress of the statement.
+	failed := func(err error) error {
+		if session.InTransaction || records == 0 {
+			return err
+		}
+		return vterrors.Wrapf(err, "LOAD DATA LOCAL INFILE stopped after %d rows were inserted and committed, IGNORE %d LINES to load the rest of the file", records, stmt.IgnoreLines+int(records))
+	}
+
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return session, nil, failed(err)
+		}
+		batch = append(batch, row)
+		if len(batch) >= mysqlServerLocalInfileBatchSize {
+			if err := insert(); err != nil {
+				return session, nil, failed(err)
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := insert(); err != nil {
+			return session, nil, failed(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return session, nil, err
+	}
+
+	// REPLACE counts the rows it deletes as affected too, and INSERT IGNORE
+	// does not count the rows it skips.
+	var deleted, skipped uint64
+	switch {
+	case stmt.Replace && affected > records:
+		deleted = affected - records
+	case !stmt.Replace && affected < records:
+		skipped = records - affected
+	}
+	return session, &sqltypes.Result{
+		RowsAffected: affected,
+		Info:         fmt.Sprintf("Records: %d  Deleted: %d  Skipped: %d  Warnings: %d", records, deleted, skipped, len(session.GetWarnings())),
+	}, nil
+}
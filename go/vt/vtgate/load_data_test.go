@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+type testLocalInfile string
+
+func (content testLocalInfile) OpenLocalInfile(filename string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(content))), nil
+}
+
+func TestLoadData(t *testing.T) {
+	executor, sbc1, sbc2, sbclookup, _ := createExecutorEnv(t)
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+
+	defer func(enabled bool, batchSize int) {
+		mysqlServerLocalInfile = enabled
+		mysqlServerLocalInfileBatchSize = batchSize
+	}(mysqlServerLocalInfile, mysqlServerLocalInfileBatchSize)
+	mysqlServerLocalInfile = true
+	mysqlServerLocalInfileBatchSize = 2
+
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), vh, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	listener.LocalInfile = true
+	defer listener.Close()
+	go listener.Accept()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	params := &mysql.ConnParams{
+		Host:            host,
+		EnableQueryInfo: true,
+		LocalInfile:     testLocalInfile("id\tname\n1\tname1\n3\tname3\n5\tname5\n"),
+	}
+	params.Port, _ = strconv.Atoi(port)
+	conn, err := mysql.Connect(context.Background(), params)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	qr, err := conn.ExecuteFetch("load data local infile 'users.tsv' into table user ignore 1 lines (id, name)", 0, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, qr.RowsAffected)
+	assert.Equal(t, "Records: 3  Deleted: 0  Skipped: 0  Warnings: 0", qr.Info)
+	assert.EqualValues(t, 3, loadDataRows.Counts()["user"])
+
+	// The rows are inserted in batches of 2, routed to their shards, and
+	// their names added to the lookup vindex.
+	require.Len(t, sbc1.Queries, 1)
+	assert.Equal(t, "insert ignore into `user`(id, `name`) values (:_Id_0, :_name_0)", sbc1.Queries[0].Sql)
+	require.Len(t, sbc2.Queries, 1)
+	assert.Equal(t, "insert ignore into `user`(id, `name`) values (:_Id_1, :_name_1)", sbc2.Queries[0].Sql)
+	// INSERT IGNORE checks the lookup rows it inserted.
+	require.Len(t, sbclookup.Queries, 5)
+	assert.Equal(t, "insert ignore into name_user_map(`name`, user_id) values (:name_0, :user_id_0), (:name_1, :user_id_1)", sbclookup.Queries[0].Sql)
+	assert.Equal(t, "insert ignore into name_user_map(`name`, user_id) values (:name_0, :user_id_0)", sbclookup.Queries[3].Sql)
+
+	// Outside of a transaction, the error tells how many lines of the file
+	// were loaded.
+	mysqlServerLocalInfileBatchSize = 1
+	sbc2.MustFailCodes[vtrpcpb.Code_INVALID_ARGUMENT] = 1
+	_, err = conn.ExecuteFetch("load data local infile 'users.tsv' into table user ignore 1 lines (id, name)", 0, false)
+	assert.ErrorContains(t, err, "LOAD DATA LOCAL INFILE stopped after 1 rows were inserted and committed, IGNORE 2 LINES to load the rest of the file")
+
+	// The connection is still usable.
+	qr, err = conn.ExecuteFetch("select 1 from dual", 1, false)
+	require.NoError(t, err)
+	assert.Len(t, qr.Rows, 1)
+
+	_, err = conn.ExecuteFetch("load data local infile 'users.tsv' into table user (id, @name)", 0, false)
+	assert.ErrorContains(t, err, "unsupported: user variable in LOAD DATA LOCAL INFILE")
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/flagutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	// loadSheddingThreshold is the fraction of the requests to the tablets
+	// failing with saturation errors during an interval above which the
+	// pressure of the load shedder increases. 0 disables the load shedding.
+	loadSheddingThreshold = 0.0
+	// loadSheddingInterval is how often the load shedder adjusts its pressure.
+	loadSheddingInterval = time.Second
+	// loadSheddingMinRequests is the number of requests to the tablets below
+	// which an interval does not count as saturated.
+	loadSheddingMinRequests = 20
+	// loadSheddingDefaultPriority is the priority of the statements with
+	// neither a PRIORITY directive nor a workload priority.
+	loadSheddingDefaultPriority = sqlparser.MaxPriorityValue / 2
+	// loadSheddingWorkloadPriorities maps workload names to the priority of
+	// their statements.
+	loadSheddingWorkloadPriorities flagutil.StringMapValue
+
+	loadShedStatements = stats.NewCountersWithSingleLabel("LoadShedStatements", "Number of statements rejected by the load shedder, by workload", "Workload")
+)
+
+const (
+	// loadSheddingRaiseStep is how much the pressure increases after a
+	// saturated interval, and loadSheddingDecayStep how much it decreases after
+	// another interval.
+	loadSheddingRaiseStep = 0.1
+	loadSheddingDecayStep = 0.05
+)
+
+// loadShedder rejects the statements of the lowest priorities while the
+// tablets keep reporting saturation, so that the traffic which matters still
+// gets served instead of every statement timing out.
+//
+// Every interval, the pressure of the shedder, between 0 and 1, increases if
+// more than threshold of the requests to the tablets failed with saturation
+// errors, and decays otherwise. A statement of priority p, between 0, the
+// highest, and sqlparser.MaxPriorityValue, the lowest, is rejected with a
+// probability of 2*pressure - 1 + p/MaxPriorityValue, so that the lowest
+// priorities are shed first and entirely before the next ones are. The
+// statements of priority 0 are never rejected.
+type loadShedder struct {
+	threshold          float64
+	interval           time.Duration
+	minRequests        int
+	defaultPriority    int
+	workloadPriorities map[string]int
+	now                func() time.Time
+	random             func() float64
+
+	mu            sync.Mutex
+	pressure      float64
+	intervalStart time.Time
+	requests      int
+	saturated     int
+}
+
+func newLoadShedder(threshold float64, interval time.Duration, minRequests, defaultPriority int, workloadPriorities map[string]string) (*loadShedder, error) {
+	if threshold <= 0 {
+		return nil, nil
+	}
+	if threshold >= 1 {
+		return nil, fmt.Errorf("load shedding threshold must be lower than 1, got %v", threshold)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("load shedding interval must be positive, got %v", interval)
+	}
+	if defaultPriority < 0 || defaultPriority > sqlparser.MaxPriorityValue {
+		return nil, fmt.Errorf("load shedding default priority must be between 0 and %d, got %d", sqlparser.MaxPriorityValue, defaultPriority)
+	}
+	ls := &loadShedder{
+		threshold:          threshold,
+		interval:           interval,
+		minRequests:        minRequests,
+		defaultPriority:    defaultPriority,
+		workloadPriorities: make(map[string]int, len(workloadPriorities)),
+		now:                time.Now,
+		random:             rand.Float64,
+	}
+	for workload, value := range workloadPriorities {
+		priority, err := strconv.Atoi(value)
+		if err != nil || priority < 0 || priority > sqlparser.MaxPriorityValue {
+			return nil, fmt.Errorf("invalid load shedding priority %q of workload %s: must be between 0 and %d", value, workload, sqlparser.MaxPriorityValue)
+		}
+		ls.workloadPriorities[workload] = priority
+	}
+	ls.intervalStart = ls.now()
+	return ls, nil
+}
+
+func (ls *loadShedder) enabled() bool {
+	return ls != nil
+}
+
+// record records the outcome of a request to a tablet.
+func (ls *loadShedder) record(err error) {
+	if !ls.enabled() {
+		return
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.adjustLocked()
+	ls.requests++
+	if isSaturationError(err) {
+		ls.saturated++
+	}
+}
+
+// isSaturationError returns true if the error tells that the tablet is out of
+// capacity, e.g. a connection pool timeout or a throttled transaction.
+func isSaturationError(err error) bool {
+	return err != nil && vterrors.Code(err) == vtrpcpb.Code_RESOURCE_EXHAUSTED
+}
+
+// adjustLocked adjusts the pressure for each interval elapsed since the last
+// adjustment. The intervals after the first one had no request.
+func (ls *loadShedder) adjustLocked() {
+	now := ls.now()
+	for now.Sub(ls.intervalStart) >= ls.interval {
+		previous := ls.pressure
+		if ls.requests >= ls.minRequests && float64(ls.saturated) > ls.threshold*float64(ls.requests) {
+			ls.pressure = min(1, ls.pressure+loadSheddingRaiseStep)
+		} else {
+			ls.pressure = max(0, ls.pressure-loadSheddingDecayStep)
+		}
+		switch {
+		case previous == 0 && ls.pressure > 0:
+			log.Warningf("Shedding load: %d of the %d requests to the tablets failed with saturation errors", ls.saturated, ls.requests)
+		case previous > 0 && ls.pressure == 0:
+			log.Infof("Stopped shedding load")
+		}
+		ls.intervalStart = ls.intervalStart.Add(ls.interval)
+		ls.requests = 0
+		ls.saturated = 0
+		if ls.pressure == 0 {
+			// Skip the idle intervals.
+			ls.intervalStart = now
+		}
+	}
+}
+
+// priority returns the priority of a statement: its PRIORITY directive, or
+// else the priority of its workload, or else the default priority.
+func (ls *loadShedder) priority(options *querypb.ExecuteOptions) int {
+	if value := options.GetPriority(); value != "" {
+		if priority, err := strconv.Atoi(value); err == nil {
+			return priority
+		}
+	}
+	if priority, ok := ls.workloadPriorities[options.GetWorkloadName()]; ok {
+		return priority
+	}
+	return ls.defaultPriority
+}
+
+// sheddingProbability returns the probability to reject a statement of the
+// priority under the pressure.
+func sheddingProbability(pressure float64, priority int) float64 {
+	if priority <= 0 || pressure <= 0 {
+		return 0
+	}
+	return min(1, max(0, 2*pressure-1+float64(priority)/sqlparser.MaxPriorityValue))
+}
+
+// admit returns a RESOURCE_EXHAUSTED error if the statement is shed. The
+// error tells how long it takes for the statements of its priority to stop
+// being shed if the tablets recover.
+func (ls *loadShedder) admit(options *querypb.ExecuteOptions) error {
+	if !ls.enabled() {
+		return nil
+	}
+	ls.mu.Lock()
+	ls.adjustLocked()
+	pressure := ls.pressure
+	ls.mu.Unlock()
+
+	priority := ls.priority(options)
+	probability := sheddingProbability(pressure, priority)
+	if probability == 0 || ls.random() >= probability {
+		return nil
+	}
+
+	workload := options.GetWorkloadName()
+	loadShedStatements.Add(workload, 1)
+	// The priority stops being shed once the pressure decays to (1 - p/MaxPriorityValue) / 2.
+	target := (1 - float64(priority)/sqlparser.MaxPriorityValue) / 2
+	intervals := max(1, math.Ceil((pressure-target)/loadSheddingDecayStep-1e-9))
+	retryAfter := time.Duration(intervals) * ls.interval
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "vtgate is shedding load: statement of priority %d of workload %q rejected, retry after %v", priority, workload, retryAfter)
+}
+
+// pressurePercent returns the pressure of the load shedder, in percent.
+func (ls *loadShedder) pressurePercent() int64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.adjustLocked()
+	return int64(math.Round(ls.pressure * 100))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestNewLoadShedder(t *testing.T) {
+	ls, err := newLoadShedder(0, time.Second, 20, 50, nil)
+	require.NoError(t, err)
+	assert.False(t, ls.enabled())
+	assert.NoError(t, ls.admit(nil))
+	ls.record(errors.New("ignored"))
+
+	_, err = newLoadShedder(1, time.Second, 20, 50, nil)
+	assert.ErrorContains(t, err, "load shedding threshold must be lower than 1")
+	_, err = newLoadShedder(0.1, 0, 20, 50, nil)
+	assert.ErrorContains(t, err, "load shedding interval must be positive")
+	_, err = newLoadShedder(0.1, time.Second, 20, 101, nil)
+	assert.ErrorContains(t, err, "load shedding default priority must be between 0 and 100")
+	_, err = newLoadShedder(0.1, time.Second, 20, 50, map[string]string{"olap": "high"})
+	assert.ErrorContains(t, err, `invalid load shedding priority "high" of workload olap`)
+
+	ls, err = newLoadShedder(0.1, time.Second, 20, 50, map[string]string{"olap": "80"})
+	require.NoError(t, err)
+	assert.Equal(t, 80, ls.priority(&querypb.ExecuteOptions{WorkloadName: "olap"}))
+	assert.Equal(t, 10, ls.priority(&querypb.ExecuteOptions{WorkloadName: "olap", Priority: "10"}))
+	assert.Equal(t, 50, ls.priority(&querypb.ExecuteOptions{WorkloadName: "oltp"}))
+	assert.Equal(t, 50, ls.priority(nil))
+}
+
+func TestLoadShedderPressure(t *testing.T) {
+	ls, err := newLoadShedder(0.1, time.Second, 10, 50, nil)
+	require.NoError(t, err)
+	now := ls.intervalStart
+	ls.now = func() time.Time { return now }
+	saturated := vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "connection pool timed out")
+
+	record := func(requests, saturatedRequests int) {
+		for i := 0; i < requests; i++ {
+			if i < saturatedRequests {
+				ls.record(saturated)
+			} else {
+				ls.record(nil)
+			}
+		}
+		now = now.Add(time.Second)
+	}
+
+	// Too few requests, or too few saturation errors.
+	record(5, 5)
+	record(20, 2)
+	record(20, 1)
+	assert.EqualValues(t, 0, ls.pressurePercent())
+
+	// The pressure increases with each saturated interval.
+	record(20, 3)
+	assert.EqualValues(t, 10, ls.pressurePercent())
+	record(20, 20)
+	record(20, 20)
+	assert.EqualValues(t, 30, ls.pressurePercent())
+
+	// Then decays, including when there is no traffic.
+	record(20, 0)
+	assert.EqualValues(t, 25, ls.pressurePercent())
+	now = now.Add(2 * time.Second)
+	assert.EqualValues(t, 15, ls.pressurePercent())
+	now = now.Add(time.Minute)
+	assert.EqualValues(t, 0, ls.pressurePercent())
+
+	// The errors which don't tell about saturation don't count.
+	record(20, 0)
+	for i := 0; i < 20; i++ {
+		ls.record(vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tablet is not serving"))
+	}
+	now = now.Add(time.Second)
+	assert.EqualValues(t, 0, ls.pressurePercent())
+}
+
+func TestLoadShedderAdmit(t *testing.T) {
+	ls, err := newLoadShedder(0.1, time.Second, 10, 50, map[string]string{"batch": "100", "olap": "80"})
+	require.NoError(t, err)
+	now := ls.intervalStart
+	ls.now = func() time.Time { return now }
+	random := 0.5
+	ls.random = func() float64 { return random }
+	batch := &querypb.ExecuteOptions{WorkloadName: "batch"}
+	olap := &querypb.ExecuteOptions{WorkloadName: "olap"}
+	critical := &querypb.ExecuteOptions{WorkloadName: "batch", Priority: "0"}
+
+	assert.NoError(t, ls.admit(batch))
+
+	ls.pressure = 0.3
+	// batch is shed with a probability of 0.6, olap of 0.4.
+	err = ls.admit(batch)
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, `vtgate is shedding load: statement of priority 100 of workload "batch" rejected, retry after 6s`)
+	assert.NoError(t, ls.admit(olap))
+	random = 0.3
+	assert.ErrorContains(t, ls.admit(olap), "retry after 4s")
+	assert.NoError(t, ls.admit(&querypb.ExecuteOptions{WorkloadName: "oltp"}))
+
+	ls.pressure = 1
+	random = 0.99
+	assert.Error(t, ls.admit(&querypb.ExecuteOptions{WorkloadName: "oltp"}))
+	assert.NoError(t, ls.admit(critical))
+}
+
+func TestSheddingProbability(t *testing.T) {
+	testcases := []struct {
+		pressure float64
+		priority int
+		want     float64
+	}{
+		{pressure: 0, priority: 100, want: 0},
+		{pressure: 0.1, priority: 100, want: 0.2},
+		{pressure: 0.1, priority: 80, want: 0},
+		{pressure: 0.5, priority: 100, want: 1},
+		{pressure: 0.5, priority: 50, want: 0.5},
+		{pressure: 1, priority: 1, want: 1},
+		{pressure: 1, priority: 0, want: 0},
+	}
+	for _, tc := range testcases {
+		assert.InDelta(t, tc.want, sheddingProbability(tc.pressure, tc.priority), 1e-9, "pressure %v, priority %d", tc.pressure, tc.priority)
+	}
+}
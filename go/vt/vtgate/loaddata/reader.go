@@ -0,0 +1,276 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loaddata
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Reader reads the rows of a file with the format of a statement, the way
+// MySQL does:
+//   - the escape character escapes the terminators and the enclosing
+//     character, and stands for a NUL, backspace, newline, carriage return,
+//     tab and Control+Z when followed by 0, b, n, r, t and Z,
+//   - the fields starting with the enclosing character end with it, which
+//     is doubled inside them, and may contain the terminators,
+//   - \N, and NULL if the fields may be enclosed, are NULL,
+//   - the lines not starting with the line prefix are skipped.
+type Reader struct {
+	r    *bufio.Reader
+	stmt *Statement
+
+	fieldTerm []byte
+	lineTerm  []byte
+	enclosed  byte
+	escaped   byte
+
+	// rows is the number of rows read.
+	rows int
+}
+
+// NewReader returns a Reader of the rows of r.
+func NewReader(r io.Reader, stmt *Statement) *Reader {
+	rd := &Reader{
+		r:         bufio.NewReaderSize(r, 64*1024),
+		stmt:      stmt,
+		fieldTerm: []byte(stmt.FieldsTerminatedBy),
+		lineTerm:  []byte(stmt.LinesTerminatedBy),
+	}
+	if stmt.FieldsEnclosedBy != "" {
+		rd.enclosed = stmt.FieldsEnclosedBy[0]
+	}
+	if stmt.FieldsEscapedBy != "" {
+		rd.escaped = stmt.FieldsEscapedBy[0]
+	}
+	return rd
+}
+
+// Rows returns the number of rows read so far, including the skipped ones.
+func (rd *Reader) Rows() int {
+	return rd.rows
+}
+
+// Skip skips n rows.
+func (rd *Reader) Skip(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := rd.Next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Next returns the values of the next row, or io.EOF at the end of the file.
+func (rd *Reader) Next() ([]sqltypes.Value, error) {
+	if prefix := rd.stmt.LinesStartingBy; prefix != "" {
+		if err := rd.skipPast([]byte(prefix)); err != nil {
+			return nil, err
+		}
+	} else if _, err := rd.r.Peek(1); err != nil {
+		return nil, err
+	}
+
+	var row []sqltypes.Value
+	for {
+		value, endOfLine, err := rd.field()
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "row %d", rd.rows+1)
+		}
+		row = append(row, value)
+		if endOfLine {
+			rd.rows++
+			return row, nil
+		}
+	}
+}
+
+// skipPast reads up to the end of the first occurrence of s.
+func (rd *Reader) skipPast(s []byte) error {
+	for {
+		ok, err := rd.consume(s)
+		if ok || err != nil {
+			return err
+		}
+		if _, err := rd.r.ReadByte(); err != nil {
+			return err
+		}
+	}
+}
+
+// consume reads s if the next bytes are s. It returns io.EOF at the end of
+// the file only.
+func (rd *Reader) consume(s []byte) (bool, error) {
+	next, err := rd.r.Peek(len(s))
+	if err == io.EOF && len(next) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(next, s) {
+		return false, nil
+	}
+	_, err = rd.r.Discard(len(s))
+	return true, err
+}
+
+// terminator reads the terminator of a field, if the next bytes are one.
+// The longest terminator is looked for first, in case one starts the other.
+func (rd *Reader) terminator() (found, endOfLine bool, err error) {
+	first, second := rd.fieldTerm, rd.lineTerm
+	if len(first) < len(second) {
+		first, second = second, first
+	}
+	for _, term := range [][]byte{first, second} {
+		if len(term) == 0 {
+			continue
+		}
+		ok, err := rd.consume(term)
+		if err == io.EOF {
+			return true, true, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		if ok {
+			return true, bytes.Equal(term, rd.lineTerm), nil
+		}
+	}
+	return false, false, nil
+}
+
+// field reads the next field of the row, and its terminator.
+func (rd *Reader) field() (value sqltypes.Value, endOfLine bool, err error) {
+	if rd.enclosed != 0 {
+		if next, err := rd.r.Peek(1); err == nil && next[0] == rd.enclosed {
+			rd.r.Discard(1)
+			return rd.enclosedField()
+		}
+	}
+
+	var buf []byte
+	// escapedN is set if the field is \N.
+	escapedN := false
+	for {
+		found, endOfLine, err := rd.terminator()
+		if err != nil {
+			return sqltypes.Value{}, false, err
+		}
+		if found {
+			switch {
+			case escapedN:
+				return sqltypes.NULL, endOfLine, nil
+			case rd.enclosed != 0 && string(buf) == "NULL":
+				return sqltypes.NULL, endOfLine, nil
+			}
+			return sqltypes.NewVarChar(string(buf)), endOfLine, nil
+		}
+
+		b, err := rd.r.ReadByte()
+		if err != nil {
+			return sqltypes.Value{}, false, err
+		}
+		if b == rd.escaped && rd.escaped != 0 {
+			c, err := rd.r.ReadByte()
+			if err == io.EOF {
+				buf = append(buf, b)
+				continue
+			}
+			if err != nil {
+				return sqltypes.Value{}, false, err
+			}
+			escapedN = c == 'N' && len(buf) == 0
+			buf = append(buf, unescape(c))
+			continue
+		}
+		escapedN = false
+		buf = append(buf, b)
+	}
+}
+
+// enclosedField reads a field after its enclosing character, up to the
+// enclosing character followed by a terminator.
+func (rd *Reader) enclosedField() (value sqltypes.Value, endOfLine bool, err error) {
+	var buf []byte
+	for {
+		b, err := rd.r.ReadByte()
+		if err == io.EOF {
+			return sqltypes.Value{}, false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "field enclosed by %q is not terminated", rd.enclosed)
+		}
+		if err != nil {
+			return sqltypes.Value{}, false, err
+		}
+		// The escape character is the enclosing one if they are the same.
+		if b == rd.escaped && rd.escaped != rd.enclosed {
+			c, err := rd.r.ReadByte()
+			if err != nil {
+				return sqltypes.Value{}, false, err
+			}
+			buf = append(buf, unescape(c))
+			continue
+		}
+		if b != rd.enclosed {
+			buf = append(buf, b)
+			continue
+		}
+		if ok, err := rd.consume([]byte{rd.enclosed}); err != nil && err != io.EOF {
+			return sqltypes.Value{}, false, err
+		} else if ok {
+			buf = append(buf, b)
+			continue
+		}
+		found, endOfLine, err := rd.terminator()
+		if err != nil {
+			return sqltypes.Value{}, false, err
+		}
+		if found {
+			return sqltypes.NewVarChar(string(buf)), endOfLine, nil
+		}
+		// The enclosing character is part of the field when it is not
+		// followed by a terminator.
+		buf = append(buf, b)
+	}
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 0x1a
+	default:
+		return c
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loaddata
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestReader(t *testing.T) {
+	null := "<null>"
+	tcases := []struct {
+		name     string
+		options  string
+		file     string
+		expected [][]string
+		err      string
+	}{{
+		name:     "defaults",
+		file:     "1\ta\n2\tb\\tc\\\\\n3\t\\N\n4\tN\n",
+		expected: [][]string{{"1", "a"}, {"2", "b\tc\\"}, {"3", null}, {"4", "N"}},
+	}, {
+		name:     "no last terminator",
+		file:     "1\ta\n2\tb",
+		expected: [][]string{{"1", "a"}, {"2", "b"}},
+	}, {
+		name:     "empty fields",
+		file:     "1\t\n\t\n",
+		expected: [][]string{{"1", ""}, {"", ""}},
+	}, {
+		name:    "csv",
+		options: "fields terminated by ',' optionally enclosed by '\"' lines terminated by '\\r\\n'",
+		file:    "1,\"a,b\"\r\n2,\"say \"\"hi\"\"\r\n\"\r\n3,NULL\r\n4,\"NULL\"\r\n5,\"\\\"\"\r\n6,b\"c\r\n",
+		expected: [][]string{
+			{"1", "a,b"},
+			{"2", "say \"hi\"\r\n"},
+			{"3", null},
+			{"4", "NULL"},
+			{"5", "\""},
+			{"6", "b\"c"},
+		},
+	}, {
+		name:     "enclosing character not followed by a terminator",
+		options:  "fields terminated by ',' enclosed by '\"'",
+		file:     "\"a\"b\",c\n",
+		expected: [][]string{{"a\"b", "c"}},
+	}, {
+		name:     "escaped by the enclosing character",
+		options:  "fields terminated by ',' enclosed by '\"' escaped by '\"'",
+		file:     "\"a\"\"b\",\"c\"\n",
+		expected: [][]string{{"a\"b", "c"}},
+	}, {
+		name:     "no escape character",
+		options:  "fields terminated by ',' escaped by ''",
+		file:     "a\\b,\\N\n",
+		expected: [][]string{{"a\\b", "\\N"}},
+	}, {
+		name:     "line prefix",
+		options:  "fields terminated by ',' lines starting by 'xxx'",
+		file:     "xxx1,a\nskipped\nyy xxx2,b\nxx",
+		expected: [][]string{{"1", "a"}, {"2", "b"}},
+	}, {
+		name:     "field terminator prefix of the line terminator",
+		options:  "fields terminated by '|' lines terminated by '||'",
+		file:     "1|a||2|b||",
+		expected: [][]string{{"1", "a"}, {"2", "b"}},
+	}, {
+		name:    "unterminated enclosed field",
+		options: "fields terminated by ',' enclosed by '\"'",
+		file:    "1,a\n2,\"b\n",
+		err:     "row 2: field enclosed by '\"' is not terminated",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			stmt, err := Parse(sqlparser.NewTestParser(), "load data local infile 'f' into table t "+tcase.options)
+			require.NoError(t, err)
+			rd := NewReader(strings.NewReader(tcase.file), stmt)
+			var rows [][]string
+			for {
+				row, err := rd.Next()
+				if err == io.EOF {
+					break
+				}
+				if tcase.err != "" && err != nil {
+					assert.ErrorContains(t, err, tcase.err)
+					return
+				}
+				require.NoError(t, err)
+				values := make([]string, 0, len(row))
+				for _, value := range row {
+					if value.IsNull() {
+						values = append(values, null)
+					} else {
+						values = append(values, value.ToString())
+					}
+				}
+				rows = append(rows, values)
+			}
+			require.Empty(t, tcase.err)
+			assert.Equal(t, tcase.expected, rows)
+			assert.Equal(t, len(tcase.expected), rd.Rows())
+		})
+	}
+}
+
+func TestReaderSkip(t *testing.T) {
+	stmt, err := Parse(sqlparser.NewTestParser(), "load data local infile 'f' into table t fields terminated by ',' enclosed by '\"' ignore 2 lines")
+	require.NoError(t, err)
+	rd := NewReader(strings.NewReader("id,name\n1,\"a\nb\"\n2,c\n"), stmt)
+	require.NoError(t, rd.Skip(stmt.IgnoreLines))
+	row, err := rd.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.NewVarChar("2"), sqltypes.NewVarChar("c")}, row)
+	assert.Equal(t, 3, rd.Rows())
+
+	// Skipping past the end of the file is not an error.
+	require.NoError(t, rd.Skip(10))
+	_, err = rd.Next()
+	assert.Equal(t, io.EOF, err)
+}
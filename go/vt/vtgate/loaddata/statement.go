@@ -0,0 +1,397 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loaddata runs the LOAD DATA LOCAL INFILE statements at the gate:
+// it reads the rows of the file the client sends, and turns them into
+// INSERT statements which the gate routes to the shards of the rows like
+// any other.
+package loaddata
+
+import (
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Statement is a parsed LOAD DATA LOCAL INFILE statement.
+type Statement struct {
+	// Filename is the name of the file on the client.
+	Filename string
+	// Replace is set if the rows replace the existing rows with the same
+	// unique keys. Otherwise, the rows duplicating existing ones are skipped,
+	// as MySQL does for LOCAL files whether IGNORE is given or not.
+	Replace bool
+
+	Table      sqlparser.TableName
+	Partitions sqlparser.Partitions
+	// Charset is the character set of the file, empty for the one of the
+	// connection.
+	Charset string
+
+	FieldsTerminatedBy string
+	FieldsEnclosedBy   string
+	FieldsEscapedBy    string
+	LinesStartingBy    string
+	LinesTerminatedBy  string
+
+	// IgnoreLines is the number of lines skipped at the start of the file.
+	IgnoreLines int
+	// Columns are the columns the fields of the rows are assigned to, all the
+	// columns of the table in their order if empty.
+	Columns sqlparser.Columns
+}
+
+// Parse parses sql if it is a LOAD DATA LOCAL INFILE statement, and returns
+// nil otherwise. The user variables and the SET clause are not supported.
+func Parse(parser *sqlparser.Parser, sql string) (*Statement, error) {
+	p := &stmtParser{tkn: parser.NewStringTokenizer(sql)}
+	p.next()
+	if !p.accept(sqlparser.LOAD) || !p.accept(sqlparser.DATA) {
+		return nil, nil
+	}
+	if !p.accept(sqlparser.LOW_PRIORITY) {
+		p.acceptWord("concurrent")
+	}
+	if !p.accept(sqlparser.LOCAL) {
+		return nil, nil
+	}
+
+	stmt := &Statement{
+		FieldsTerminatedBy: "\t",
+		FieldsEscapedBy:    "\\",
+		LinesTerminatedBy:  "\n",
+	}
+	var err error
+	if !p.acceptWord("infile") {
+		return nil, p.unexpected()
+	}
+	if stmt.Filename, err = p.string(); err != nil {
+		return nil, err
+	}
+	if p.accept(sqlparser.REPLACE) {
+		stmt.Replace = true
+	} else {
+		p.accept(sqlparser.IGNORE)
+	}
+	if !p.accept(sqlparser.INTO) || !p.accept(sqlparser.TABLE) {
+		return nil, p.unexpected()
+	}
+	if stmt.Table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.accept(sqlparser.PARTITION) {
+		names, err := p.identifierList()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			stmt.Partitions = append(stmt.Partitions, sqlparser.NewIdentifierCI(name))
+		}
+	}
+	charset := p.accept(sqlparser.CHARSET)
+	if !charset && p.accept(sqlparser.CHARACTER) {
+		if !p.accept(sqlparser.SET) {
+			return nil, p.unexpected()
+		}
+		charset = true
+	}
+	if charset {
+		if stmt.Charset, err = p.charset(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(sqlparser.FIELDS) || p.accept(sqlparser.COLUMNS) {
+		if err := p.fieldsOptions(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(sqlparser.LINES) {
+		if err := p.linesOptions(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(sqlparser.IGNORE) {
+		if stmt.IgnoreLines, err = p.integer(); err != nil {
+			return nil, err
+		}
+		if !p.accept(sqlparser.LINES) && !p.accept(sqlparser.ROWS) {
+			return nil, p.unexpected()
+		}
+	}
+	if p.typ == '(' {
+		names, err := p.identifierList()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			stmt.Columns = append(stmt.Columns, sqlparser.NewIdentifierCI(name))
+		}
+	}
+	if p.typ == sqlparser.SET {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: SET clause in LOAD DATA LOCAL INFILE")
+	}
+	p.accept(';')
+	if p.typ != 0 {
+		return nil, p.unexpected()
+	}
+	if stmt.FieldsTerminatedBy == "" && stmt.FieldsEnclosedBy == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: fixed-row format in LOAD DATA LOCAL INFILE")
+	}
+	if stmt.LinesTerminatedBy == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: empty LINES TERMINATED BY in LOAD DATA LOCAL INFILE")
+	}
+	return stmt, nil
+}
+
+// Insert returns the statement inserting the rows.
+func (stmt *Statement) Insert(rows [][]sqltypes.Value) string {
+	values := make(sqlparser.Values, 0, len(rows))
+	for _, row := range rows {
+		tuple := make(sqlparser.ValTuple, 0, len(row))
+		for _, value := range row {
+			tuple = append(tuple, stmt.literal(value))
+		}
+		values = append(values, tuple)
+	}
+	ins := &sqlparser.Insert{
+		Action:     sqlparser.InsertAct,
+		Ignore:     sqlparser.Ignore(!stmt.Replace),
+		Table:      &sqlparser.AliasedTableExpr{Expr: stmt.Table},
+		Partitions: stmt.Partitions,
+		Columns:    stmt.Columns,
+		Rows:       values,
+	}
+	if stmt.Replace {
+		ins.Action = sqlparser.ReplaceAct
+	}
+	return sqlparser.String(ins)
+}
+
+func (stmt *Statement) literal(value sqltypes.Value) sqlparser.Expr {
+	if value.IsNull() {
+		return &sqlparser.NullVal{}
+	}
+	lit := sqlparser.NewStrLiteral(value.ToString())
+	if stmt.Charset == "" {
+		return lit
+	}
+	return &sqlparser.IntroducerExpr{CharacterSet: "_" + stmt.Charset, Expr: lit}
+}
+
+// stmtParser parses the statement from its tokens.
+type stmtParser struct {
+	tkn *sqlparser.Tokenizer
+	typ int
+	val string
+}
+
+func (p *stmtParser) next() {
+	for {
+		p.typ, p.val = p.tkn.Scan()
+		if p.typ != sqlparser.COMMENT {
+			return
+		}
+	}
+}
+
+// accept skips the current token if it is of type typ.
+func (p *stmtParser) accept(typ int) bool {
+	if p.typ != typ {
+		return false
+	}
+	p.next()
+	return true
+}
+
+// acceptWord skips the current token if it is the word, which the tokenizer
+// may scan as an identifier or as an unused keyword.
+func (p *stmtParser) acceptWord(word string) bool {
+	if p.typ == sqlparser.STRING || !strings.EqualFold(p.val, word) {
+		return false
+	}
+	p.next()
+	return true
+}
+
+func (p *stmtParser) unexpected() error {
+	if p.typ == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "syntax error in LOAD DATA LOCAL INFILE: unexpected end of statement")
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "syntax error in LOAD DATA LOCAL INFILE at position %d near '%s'", p.tkn.Pos, p.val)
+}
+
+func (p *stmtParser) string() (string, error) {
+	val := p.val
+	if !p.accept(sqlparser.STRING) {
+		return "", p.unexpected()
+	}
+	return val, nil
+}
+
+func (p *stmtParser) integer() (int, error) {
+	if p.typ != sqlparser.INTEGRAL {
+		return 0, p.unexpected()
+	}
+	n, err := strconv.Atoi(p.val)
+	if err != nil {
+		return 0, p.unexpected()
+	}
+	p.next()
+	return n, nil
+}
+
+// identifier accepts the identifiers, and the keywords which may be used
+// as such.
+func (p *stmtParser) identifier() (string, error) {
+	val := p.val
+	if p.typ != sqlparser.ID && (val == "" || sqlparser.KeywordString(p.typ) == "") {
+		return "", p.unexpected()
+	}
+	p.next()
+	return val, nil
+}
+
+func (p *stmtParser) tableName() (sqlparser.TableName, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return sqlparser.TableName{}, err
+	}
+	if !p.accept('.') {
+		return sqlparser.NewTableName(name), nil
+	}
+	qualifier := name
+	if name, err = p.identifier(); err != nil {
+		return sqlparser.TableName{}, err
+	}
+	return sqlparser.NewTableNameWithQualifier(name, qualifier), nil
+}
+
+func (p *stmtParser) identifierList() ([]string, error) {
+	if !p.accept('(') {
+		return nil, p.unexpected()
+	}
+	var names []string
+	for {
+		if p.typ == sqlparser.AT_ID {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: user variable in LOAD DATA LOCAL INFILE")
+		}
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.accept(')') {
+			return names, nil
+		}
+		if !p.accept(',') {
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func (p *stmtParser) charset() (string, error) {
+	if p.typ == sqlparser.STRING {
+		return p.string()
+	}
+	if p.typ == sqlparser.BINARY {
+		p.next()
+		return "binary", nil
+	}
+	return p.identifier()
+}
+
+func (p *stmtParser) fieldsOptions(stmt *Statement) (err error) {
+	matched := false
+	if p.accept(sqlparser.TERMINATED) {
+		if !p.accept(sqlparser.BY) {
+			return p.unexpected()
+		}
+		if stmt.FieldsTerminatedBy, err = p.string(); err != nil {
+			return err
+		}
+		matched = true
+	}
+	optionally := p.accept(sqlparser.OPTIONALLY)
+	if p.accept(sqlparser.ENCLOSED) {
+		if !p.accept(sqlparser.BY) {
+			return p.unexpected()
+		}
+		if stmt.FieldsEnclosedBy, err = p.char(); err != nil {
+			return err
+		}
+		matched = true
+	} else if optionally {
+		return p.unexpected()
+	}
+	if p.accept(sqlparser.ESCAPED) {
+		if !p.accept(sqlparser.BY) {
+			return p.unexpected()
+		}
+		if stmt.FieldsEscapedBy, err = p.char(); err != nil {
+			return err
+		}
+		matched = true
+	}
+	if !matched {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *stmtParser) linesOptions(stmt *Statement) (err error) {
+	matched := false
+	if p.accept(sqlparser.STARTING) {
+		if !p.accept(sqlparser.BY) {
+			return p.unexpected()
+		}
+		if stmt.LinesStartingBy, err = p.string(); err != nil {
+			return err
+		}
+		matched = true
+	}
+	if p.accept(sqlparser.TERMINATED) {
+		if !p.accept(sqlparser.BY) {
+			return p.unexpected()
+		}
+		if stmt.LinesTerminatedBy, err = p.string(); err != nil {
+			return err
+		}
+		matched = true
+	}
+	if !matched {
+		return p.unexpected()
+	}
+	return nil
+}
+
+// char parses the strings of at most one character, as MySQL requires for
+// the enclosing and escaping characters.
+func (p *stmtParser) char() (string, error) {
+	pos := p.tkn.Pos
+	val, err := p.string()
+	if err != nil {
+		return "", err
+	}
+	if len(val) > 1 {
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Field separator argument is not what is expected; check the manual (near position %d)", pos)
+	}
+	return val, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loaddata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestParse(t *testing.T) {
+	tcases := []struct {
+		sql      string
+		expected *Statement
+		err      string
+	}{{
+		sql: "select 1",
+	}, {
+		// The files on the server are not read by the gate.
+		sql: "load data infile '/tmp/t.csv' into table t",
+	}, {
+		sql: "load data local infile '/tmp/t.csv' into table t",
+		expected: &Statement{
+			Filename:           "/tmp/t.csv",
+			Table:              sqlparser.NewTableName("t"),
+			FieldsTerminatedBy: "\t",
+			FieldsEscapedBy:    "\\",
+			LinesTerminatedBy:  "\n",
+		},
+	}, {
+		sql: "/* load */ LOAD DATA CONCURRENT LOCAL INFILE 't.csv' REPLACE INTO TABLE ks.`order` PARTITION (p0, p1) CHARACTER SET latin1 " +
+			"COLUMNS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' LINES STARTING BY 'xx' TERMINATED BY '\\r\\n' IGNORE 1 LINES (id, name);",
+		expected: &Statement{
+			Filename:           "t.csv",
+			Replace:            true,
+			Table:              sqlparser.NewTableNameWithQualifier("order", "ks"),
+			Partitions:         sqlparser.Partitions{sqlparser.NewIdentifierCI("p0"), sqlparser.NewIdentifierCI("p1")},
+			Charset:            "latin1",
+			FieldsTerminatedBy: ",",
+			FieldsEnclosedBy:   "\"",
+			LinesStartingBy:    "xx",
+			LinesTerminatedBy:  "\r\n",
+			IgnoreLines:        1,
+			Columns:            sqlparser.Columns{sqlparser.NewIdentifierCI("id"), sqlparser.NewIdentifierCI("name")},
+		},
+	}, {
+		sql: "load data local infile 't.csv' ignore into table t charset binary ignore 2 rows",
+		expected: &Statement{
+			Filename:           "t.csv",
+			Table:              sqlparser.NewTableName("t"),
+			Charset:            "binary",
+			FieldsTerminatedBy: "\t",
+			FieldsEscapedBy:    "\\",
+			LinesTerminatedBy:  "\n",
+			IgnoreLines:        2,
+		},
+	}, {
+		sql: "load data local infile 't.csv' into table t (id, @name) set name = upper(@name)",
+		err: "unsupported: user variable in LOAD DATA LOCAL INFILE",
+	}, {
+		sql: "load data local infile 't.csv' into table t (id, name) set name = 'x'",
+		err: "unsupported: SET clause in LOAD DATA LOCAL INFILE",
+	}, {
+		sql: "load data local infile 't.csv' into table t fields terminated by '' enclosed by ''",
+		err: "unsupported: fixed-row format in LOAD DATA LOCAL INFILE",
+	}, {
+		sql: "load data local infile 't.csv' into table t fields enclosed by '||'",
+		err: "Field separator argument is not what is expected",
+	}, {
+		sql: "load data local infile 't.csv' into t",
+		err: "syntax error in LOAD DATA LOCAL INFILE at position 37 near 't'",
+	}, {
+		sql: "load data local infile 't.csv' into table",
+		err: "syntax error in LOAD DATA LOCAL INFILE: unexpected end of statement",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			stmt, err := Parse(sqlparser.NewTestParser(), tcase.sql)
+			if tcase.err != "" {
+				assert.ErrorContains(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expected, stmt)
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	rows := [][]sqltypes.Value{
+		{sqltypes.NewVarChar("1"), sqltypes.NewVarChar("it's")},
+		{sqltypes.NewVarChar("2"), sqltypes.NULL},
+	}
+	tcases := []struct {
+		sql      string
+		expected string
+	}{{
+		sql:      "load data local infile 't.csv' into table t",
+		expected: "insert ignore into t values ('1', 'it\\'s'), ('2', null)",
+	}, {
+		sql:      "load data local infile 't.csv' replace into table ks.t partition (p0) character set latin1 (id, name)",
+		expected: "replace into ks.t partition (p0)(id, `name`) values (_latin1 '1', _latin1 'it\\'s'), (_latin1 '2', null)",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			stmt, err := Parse(sqlparser.NewTestParser(), tcase.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expected, stmt.Insert(rows))
+		})
+	}
+}
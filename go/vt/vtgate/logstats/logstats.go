@@ -18,6 +18,9 @@ package logstats
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
 	"io"
 	"net/url"
 	"time"
@@ -25,6 +28,7 @@ import (
 	"github.com/google/safehtml"
 
 	"vitess.io/vitess/go/logstats"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
@@ -44,6 +48,7 @@ type LogStats struct {
 	ShardQueries   uint64
 	RowsAffected   uint64
 	RowsReturned   uint64
+	ResultChecksum string // ResultChecksum is set when --querylog-result-checksum is enabled
 	PlanTime       time.Duration
 	ExecuteTime    time.Duration
 	CommitTime     time.Duration
@@ -52,6 +57,8 @@ type LogStats struct {
 	SessionUUID    string
 	CachedPlan     bool
 	ActiveKeyspace string // ActiveKeyspace is the selected keyspace `use ks`
+	ClientProgram  string // ClientProgram is the program of the MySQL client, from its connection attributes
+	ClientVersion  string // ClientVersion is the version of the MySQL client library
 }
 
 // NewLogStats constructs a new LogStats with supplied Method and ctx
@@ -127,8 +134,9 @@ func (stats *LogStats) Logf(w io.Writer, params url.Values) error {
 	_, fullBindParams := params["full"]
 	remoteAddr, username := stats.RemoteAddrUsername()
 
+	json := streamlog.GetQueryLogFormat() == streamlog.QueryLogFormatJSON
 	log := logstats.NewLogger()
-	log.Init(streamlog.GetQueryLogFormat() == streamlog.QueryLogFormatJSON)
+	log.Init(json)
 	log.Key("Method")
 	log.StringUnquoted(stats.Method)
 	log.Key("RemoteAddr")
@@ -177,6 +185,46 @@ func (stats *LogStats) Logf(w io.Writer, params url.Values) error {
 	log.Strings(stats.TablesUsed)
 	log.Key("ActiveKeyspace")
 	log.String(stats.ActiveKeyspace)
+	if json {
+		// The fields below are only logged in the JSON format, which is keyed,
+		// so that the columns of the text format stay the same.
+		log.Key("RowsReturned")
+		log.Uint(stats.RowsReturned)
+		log.Key("ResultChecksum")
+		log.String(stats.ResultChecksum)
+		log.Key("ClientProgram")
+		log.String(stats.ClientProgram)
+		log.Key("ClientVersion")
+		log.String(stats.ClientVersion)
+	}
 
 	return log.Flush(w)
 }
+
+// ResultChecksum returns a checksum of the values of the given rows. The
+// checksum does not depend on the order of the rows, so that the results of
+// queries without an ORDER BY can be compared across clusters, nor on the
+// types of the values.
+func ResultChecksum(rows [][]sqltypes.Value) string {
+	var (
+		sum uint64
+		buf [binary.MaxVarintLen64]byte
+	)
+	h := fnv.New64a()
+	for _, row := range rows {
+		h.Reset()
+		for _, v := range row {
+			if v.IsNull() {
+				// A NULL is distinct from any value, including the empty string.
+				h.Write([]byte{0})
+				continue
+			}
+			raw := v.Raw()
+			n := binary.PutUvarint(buf[:], uint64(len(raw)+1))
+			h.Write(buf[:n])
+			h.Write(raw)
+		}
+		sum += h.Sum64()
+	}
+	return fmt.Sprintf("%016x", sum)
+}
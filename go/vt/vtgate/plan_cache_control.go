@@ -0,0 +1,385 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"hash/maphash"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	// enablePlanCacheControl watches the PlanCacheControl of the global topo.
+	enablePlanCacheControl bool
+
+	planCacheControlRetryDelay = 10 * time.Second
+
+	planCacheRebuilds      = stats.NewCounter("QueryPlanCacheRebuilds", "Number of cached query plans rebuilt after a vschema or schema change")
+	planCacheChanges       = stats.NewCounter("QueryPlanCacheChanges", "Number of cached query plans rebuilt after a vschema or schema change into a different plan")
+	planCacheInvalidations = stats.NewCounter("QueryPlanCacheInvalidations", "Number of cached query plans invalidated by table")
+)
+
+// pinnedQuery is a query pinned in the PlanCacheControl, formatted like the
+// queries of the plans, for the sessions targeting keyspace.
+type pinnedQuery struct {
+	keyspace string
+	query    string
+}
+
+type pinnedPlan struct {
+	pinnedQuery
+	plan *engine.Plan
+}
+
+// planPins are the plans of the pinned queries. They are kept apart from the
+// plan cache, so that they are neither evicted nor rebuilt when the vschema or
+// the schema changes, until they are unpinned or invalidated by table.
+type planPins struct {
+	queries atomic.Pointer[map[pinnedQuery]bool]
+
+	mu    sync.Mutex
+	plans map[PlanCacheKey]pinnedPlan
+	// lastInvalidation is the id of the last invalidation of the
+	// PlanCacheControl seen, and loaded whether it was loaded at least once.
+	lastInvalidation int64
+	loaded           bool
+}
+
+// get returns the pinned plan of key, or nil if it has none.
+func (p *planPins) get(key PlanCacheKey) *engine.Plan {
+	if len(p.pinned()) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.plans[key].plan
+}
+
+// pin keeps plan as the pinned plan of key, if its query is pinned for the
+// keyspace.
+func (p *planPins) pin(key PlanCacheKey, keyspace, query string, plan *engine.Plan, parser *sqlparser.Parser) {
+	pinned := p.pinned()
+	if len(pinned) == 0 {
+		return
+	}
+	// The pinned queries are formatted by the vtctld without the types of
+	// their bind variables.
+	stmt, err := parser.Parse(query)
+	if err != nil {
+		return
+	}
+	pq := pinnedQuery{keyspace: keyspace, query: sqlparser.String(stmt)}
+	if !pinned[pq] {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.plans == nil {
+		p.plans = make(map[PlanCacheKey]pinnedPlan)
+	}
+	p.plans[key] = pinnedPlan{pinnedQuery: pq, plan: plan}
+}
+
+func (p *planPins) pinned() map[pinnedQuery]bool {
+	if queries := p.queries.Load(); queries != nil {
+		return *queries
+	}
+	return nil
+}
+
+func (p *planPins) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.plans)
+}
+
+// SetPlanCacheControl applies the PlanCacheControl: it replaces the pinned
+// queries, dropping the plans of the queries which are no longer pinned, and
+// invalidates the plans of the tables of the invalidations not seen yet. The
+// invalidations are not applied the first time, as the plans they were meant
+// for were built by a previous vtgate process. A nil PlanCacheControl pins no
+// query.
+func (e *Executor) SetPlanCacheControl(control *vtgatepb.PlanCacheControl) {
+	previous := e.pins.pinned()
+	queries := make(map[pinnedQuery]bool, len(control.GetPinnedPlans()))
+	added := make(map[string]bool)
+	for _, pin := range control.GetPinnedPlans() {
+		pq := pinnedQuery{keyspace: pin.Keyspace, query: pin.Query}
+		queries[pq] = true
+		if !previous[pq] {
+			added[pin.Query] = true
+		}
+	}
+	e.pins.queries.Store(&queries)
+	if len(added) > 0 {
+		// The cached plans of the queries just pinned are removed, so that
+		// they are pinned when they are built again.
+		e.removePlans(func(plan *engine.Plan) bool {
+			stmt, err := e.env.Parser().Parse(plan.Original)
+			return err == nil && added[sqlparser.String(stmt)]
+		})
+	}
+
+	var invalidations []*vtgatepb.PlanInvalidation
+	e.pins.mu.Lock()
+	for key, pinned := range e.pins.plans {
+		if !queries[pinned.pinnedQuery] {
+			delete(e.pins.plans, key)
+		}
+	}
+	for _, invalidation := range control.GetInvalidations() {
+		if invalidation.Id <= e.pins.lastInvalidation {
+			continue
+		}
+		if e.pins.loaded {
+			invalidations = append(invalidations, invalidation)
+		}
+		e.pins.lastInvalidation = invalidation.Id
+	}
+	if len(control.GetInvalidations()) == 0 {
+		// The PlanCacheControl was deleted, so the ids start over.
+		e.pins.lastInvalidation = 0
+	}
+	e.pins.loaded = true
+	e.pins.mu.Unlock()
+
+	for _, invalidation := range invalidations {
+		n := e.InvalidatePlans(invalidation.Tables)
+		log.Infof("Invalidated %d query plans of tables %v (PlanCacheControl invalidation %d)", n, invalidation.Tables, invalidation.Id)
+	}
+}
+
+// InvalidatePlans removes the plans using any of the tables from the plan
+// cache, including the pinned plans, and returns how many were removed. A
+// table qualified by its keyspace only matches the table of that keyspace.
+func (e *Executor) InvalidatePlans(tables []string) int {
+	usesTables := func(plan *engine.Plan) bool {
+		for _, used := range plan.TablesUsed {
+			for _, table := range tables {
+				if used == table || strings.HasSuffix(used, "."+table) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	keys := e.removePlans(usesTables)
+	e.pins.mu.Lock()
+	for key, pinned := range e.pins.plans {
+		if usesTables(pinned.plan) {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+			delete(e.pins.plans, key)
+		}
+	}
+	e.pins.mu.Unlock()
+
+	planCacheInvalidations.Add(int64(len(keys)))
+	return len(keys)
+}
+
+// removePlans removes the plans matching remove from the plan cache, and
+// returns their keys.
+func (e *Executor) removePlans(remove func(plan *engine.Plan) bool) []PlanCacheKey {
+	var keys []PlanCacheKey
+	e.plans.Range(e.epoch.Load(), func(key PlanCacheKey, plan *engine.Plan) bool {
+		if remove(plan) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	// The plans are removed once the range is done, as it holds the locks of
+	// the cache.
+	for _, key := range keys {
+		e.plans.Delete(key)
+	}
+	return keys
+}
+
+// previousPlanTTL is how long the plans cached before a vschema or schema
+// change are remembered, to count those which are rebuilt after it.
+const previousPlanTTL = 10 * time.Minute
+
+// planFingerprintSeed seeds the fingerprints of the plans.
+var planFingerprintSeed = maphash.MakeSeed()
+
+// previousPlans are the fingerprints of the plans cached before the last
+// vschema or schema change. A fingerprint is forgotten once its plan is
+// rebuilt, and all of them once they expire, so that plans which are never
+// rebuilt are not remembered forever.
+type previousPlans struct {
+	mu           sync.Mutex
+	fingerprints map[PlanCacheKey]uint64
+	expires      time.Time
+}
+
+// snapshotPlans keeps the fingerprints of the plans of the cache before it is
+// cleared, to count those which are rebuilt, and those which are rebuilt
+// differently.
+func (e *Executor) snapshotPlans() {
+	fingerprints := make(map[PlanCacheKey]uint64)
+	e.plans.Range(e.epoch.Load(), func(key PlanCacheKey, plan *engine.Plan) bool {
+		fingerprints[key] = planFingerprint(plan)
+		return true
+	})
+
+	e.previousPlans.mu.Lock()
+	defer e.previousPlans.mu.Unlock()
+	e.previousPlans.fingerprints = fingerprints
+	e.previousPlans.expires = time.Now().Add(previousPlanTTL)
+}
+
+// recordPlanRebuild records the churn of the plan built for key, if a plan was
+// cached for key before the last vschema or schema change.
+func (e *Executor) recordPlanRebuild(key PlanCacheKey, plan *engine.Plan) {
+	e.previousPlans.mu.Lock()
+	if time.Now().After(e.previousPlans.expires) {
+		e.previousPlans.fingerprints = nil
+	}
+	previous, ok := e.previousPlans.fingerprints[key]
+	delete(e.previousPlans.fingerprints, key)
+	e.previousPlans.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	planCacheRebuilds.Add(1)
+	if previous != planFingerprint(plan) {
+		planCacheChanges.Add(1)
+	}
+}
+
+// planFingerprint returns a hash of the description of the plan.
+func planFingerprint(plan *engine.Plan) uint64 {
+	if plan.Instructions == nil {
+		return 0
+	}
+	data, err := json.Marshal(engine.PrimitiveToPlanDescription(plan.Instructions))
+	if err != nil {
+		return maphash.String(planFingerprintSeed, err.Error())
+	}
+	return maphash.Bytes(planFingerprintSeed, data)
+}
+
+// showVitessPlans serves `SHOW VITESS_PLANS`: the plans of the cache and the
+// pinned plans. The LIKE filter applies to the queries.
+func (e *Executor) showVitessPlans(filter *sqlparser.ShowFilter) (*sqltypes.Result, error) {
+	type planRow struct {
+		plan     *engine.Plan
+		keyspace string
+		pinned   bool
+	}
+	plans := make(map[PlanCacheKey]*planRow)
+	e.plans.Range(e.epoch.Load(), func(key PlanCacheKey, plan *engine.Plan) bool {
+		plans[key] = &planRow{plan: plan}
+		return true
+	})
+	e.pins.mu.Lock()
+	for key, pinned := range e.pins.plans {
+		plans[key] = &planRow{plan: pinned.plan, keyspace: pinned.keyspace, pinned: true}
+	}
+	e.pins.mu.Unlock()
+
+	if filter != nil && filter.Filter != nil {
+		log.Infof("SHOW VITESS_PLANS where clause %+v. Ignoring this (for now).", filter.Filter)
+	}
+	match := func(string) bool { return true }
+	if filter != nil && filter.Like != "" {
+		match = sqlparser.LikeToRegexp(filter.Like).MatchString
+	}
+
+	rows := make([]*planRow, 0, len(plans))
+	for _, row := range plans {
+		if match(row.plan.Original) {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *planRow) int {
+		if c := strings.Compare(a.plan.Original, b.plan.Original); c != 0 {
+			return c
+		}
+		return strings.Compare(a.keyspace, b.keyspace)
+	})
+
+	result := &sqltypes.Result{
+		Fields: buildVarCharFields("Query", "Type", "Tables", "Exec Count", "Exec Time", "Pinned", "Keyspace"),
+	}
+	for _, row := range rows {
+		execCount, execTime, _, _, _, _ := row.plan.Stats()
+		result.Rows = append(result.Rows, buildVarCharRow(
+			row.plan.Original,
+			row.plan.Type.String(),
+			strings.Join(row.plan.TablesUsed, ","),
+			strconv.FormatUint(execCount, 10),
+			execTime.String(),
+			strconv.FormatBool(row.pinned),
+			row.keyspace,
+		))
+	}
+	return result, nil
+}
+
+// watchPlanCacheControl applies the PlanCacheControl of the global topo, and
+// its changes, until ctx is done.
+func (e *Executor) watchPlanCacheControl(ctx context.Context, ts *topo.Server, retryDelay time.Duration) {
+	for {
+		initial, changes, err := ts.WatchPlanCacheControl(ctx)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			e.SetPlanCacheControl(nil)
+		case err != nil:
+			log.Warningf("Error watching PlanCacheControl, keeping the current one: %v", err)
+		default:
+			e.SetPlanCacheControl(initial.Value)
+			for c := range changes {
+				if c.Err != nil {
+					if topo.IsErrType(c.Err, topo.NoNode) {
+						e.SetPlanCacheControl(nil)
+					} else if !topo.IsErrType(c.Err, topo.Interrupted) {
+						log.Warningf("Error watching PlanCacheControl, keeping the current one: %v", c.Err)
+					}
+					break
+				}
+				e.SetPlanCacheControl(c.Value)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
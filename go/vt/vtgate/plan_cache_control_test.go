@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestPlanCacheControlPinning(t *testing.T) {
+	r, _, _, _, ctx := createExecutorEnv(t)
+	r.normalize = true
+	vc, _ := newVCursorImpl(NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded + "@unknown"}), makeComments(""), r, nil, r.vm, r.VSchema(), r.resolver.resolver, nil, false, pv)
+	query := "select * from music_user_map where id = 1"
+
+	getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assertCacheSize(t, r.plans, 1)
+
+	// Pinning the query removes its cached plan, to pin it when it is built
+	// again.
+	r.SetPlanCacheControl(&vtgatepb.PlanCacheControl{
+		PinnedPlans: []*vtgatepb.PinnedPlan{{Keyspace: KsTestUnsharded, Query: "select * from music_user_map where id = :id"}},
+	})
+	assertCacheSize(t, r.plans, 0)
+	pinned, _ := getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assert.Equal(t, 1, r.pins.len())
+
+	// The pinned plan survives the vschema changes.
+	r.ClearPlans()
+	plan, logStats := getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assert.False(t, logStats.CachedPlan)
+	assert.Same(t, pinned, plan)
+
+	result, err := r.showVitessPlans(nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "select * from music_user_map where id = :id /* INT64 */", result.Rows[0][0].ToString())
+	assert.Equal(t, "true", result.Rows[0][5].ToString())
+	assert.Equal(t, KsTestUnsharded, result.Rows[0][6].ToString())
+
+	result, err = r.showVitessPlans(&sqlparser.ShowFilter{Like: "%user_extra%"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+
+	// Unpinning the query drops its pinned plan.
+	r.SetPlanCacheControl(nil)
+	assert.Equal(t, 0, r.pins.len())
+}
+
+func TestPlanCacheControlInvalidations(t *testing.T) {
+	r, _, _, _, ctx := createExecutorEnv(t)
+	vc, _ := newVCursorImpl(NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded + "@unknown"}), makeComments(""), r, nil, r.vm, r.VSchema(), r.resolver.resolver, nil, false, pv)
+
+	getPlanCached(t, ctx, r, vc, "select * from music_user_map where id = 1", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	getPlanCached(t, ctx, r, vc, "select * from user_extra where id = 1", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assertCacheSize(t, r.plans, 2)
+
+	// The invalidations already there when the PlanCacheControl is first
+	// loaded are not applied.
+	r.SetPlanCacheControl(&vtgatepb.PlanCacheControl{
+		Invalidations: []*vtgatepb.PlanInvalidation{{Id: 1, Tables: []string{"user_extra"}}},
+	})
+	assertCacheSize(t, r.plans, 2)
+
+	r.SetPlanCacheControl(&vtgatepb.PlanCacheControl{
+		Invalidations: []*vtgatepb.PlanInvalidation{
+			{Id: 1, Tables: []string{"user_extra"}},
+			{Id: 2, Tables: []string{"music_user_map"}},
+		},
+	})
+	assertCacheSize(t, r.plans, 1)
+	assertCacheContains(t, r, vc, "select * from user_extra where id = 1")
+
+	// A table qualified by another keyspace does not match.
+	assert.Equal(t, 0, r.InvalidatePlans([]string{"other.user_extra"}))
+	assert.Equal(t, 1, r.InvalidatePlans([]string{KsTestUnsharded + ".user_extra"}))
+}
+
+func TestPlanCacheControlRebuilds(t *testing.T) {
+	r, _, _, _, ctx := createExecutorEnv(t)
+	vc, _ := newVCursorImpl(NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded + "@unknown"}), makeComments(""), r, nil, r.vm, r.VSchema(), r.resolver.resolver, nil, false, pv)
+	query := "select * from music_user_map where id = 1"
+
+	rebuilds, changes := planCacheRebuilds.Get(), planCacheChanges.Get()
+	getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assert.Equal(t, rebuilds, planCacheRebuilds.Get())
+
+	r.SaveVSchema(r.VSchema(), r.VSchemaStats())
+	getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assert.Equal(t, rebuilds+1, planCacheRebuilds.Get())
+	assert.Equal(t, changes, planCacheChanges.Get())
+	// A plan is forgotten once it is rebuilt.
+	assert.Empty(t, r.previousPlans.fingerprints)
+
+	// The plans are forgotten once they expire.
+	r.SaveVSchema(r.VSchema(), r.VSchemaStats())
+	r.previousPlans.expires = time.Now()
+	getPlanCached(t, ctx, r, vc, query, makeComments(""), map[string]*querypb.BindVariable{}, false)
+	assert.Equal(t, rebuilds+1, planCacheRebuilds.Get())
+	assert.Empty(t, r.previousPlans.fingerprints)
+}
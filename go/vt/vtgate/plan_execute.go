@@ -23,14 +23,18 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/logstats"
+	"vitess.io/vitess/go/vt/vtgate/statementacl"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -84,6 +88,9 @@ func (e *Executor) newExecute(
 	if err != nil {
 		return err
 	}
+	if err := statementacl.Authorized(callerid.ImmediateCallerIDFromContext(ctx), stmt); err != nil {
+		return err
+	}
 
 	var (
 		vs                 = e.VSchema()
@@ -115,6 +122,9 @@ func (e *Executor) newExecute(
 		if err != nil {
 			return err
 		}
+		if e.queryMetrics.tracksShards() {
+			vcursor.queryShard = &queryShard{}
+		}
 
 		// 3: Create a plan for the query.
 		// If we are retrying, it is likely that the routing rules have changed and hence we need to
@@ -148,21 +158,32 @@ func (e *Executor) newExecute(
 			return recResult(plan.Type, result)
 		}
 
+		// Shed the statement under load, unless it would abort a transaction
+		// which already did some work.
+		if !safeSession.isTxOpen() {
+			if err := e.resolver.scatterConn.gateway.loadShedder.admit(safeSession.GetOptions()); err != nil {
+				return err
+			}
+		}
+
 		// 4: Prepare for execution.
-		err = e.addNeededBindVars(vcursor, plan.BindVarNeeds, bindVars, safeSession)
+		err = e.addNeededBindVars(ctx, vcursor, plan.BindVarNeeds, bindVars, safeSession)
 		if err != nil {
 			logStats.Error = err
 			return err
 		}
 
-		// 5: Execute the plan.
+		// 5: Execute the plan, with the read cell preferences of its tables
+		// and the tablet tags of their keyspaces.
+		execCtx := withReadCellPreferences(ctx, readCellPreferences(vs, plan.TablesUsed))
+		execCtx = withKeyspaceTabletTags(execCtx, keyspaceTabletTags(vs, plan.TablesUsed))
 		if plan.Instructions.NeedsTransaction() {
 			err = e.insideTransaction(ctx, safeSession, logStats,
 				func() error {
-					return execPlan(ctx, plan, vcursor, bindVars, execStart)
+					return execPlan(execCtx, plan, vcursor, bindVars, execStart)
 				})
 		} else {
-			err = execPlan(ctx, plan, vcursor, bindVars, execStart)
+			err = execPlan(execCtx, plan, vcursor, bindVars, execStart)
 		}
 
 		if err == nil || safeSession.InTransaction() {
@@ -327,6 +348,11 @@ func (e *Executor) executePlan(
 	if err != nil {
 		return nil, e.rollbackExecIfNeeded(ctx, safeSession, bindVars, logStats, err)
 	}
+	if column := lastInsertIDColumn(plan); column != "" && len(qr.Rows) > 0 {
+		if offset := fieldOffset(qr.Fields, column); offset >= 0 {
+			safeSession.LastInsertId = lastInsertIDValue(qr.Rows[len(qr.Rows)-1], offset)
+		}
+	}
 	return qr, nil
 }
 
@@ -377,19 +403,65 @@ func (e *Executor) rollbackPartialExec(ctx context.Context, safeSession *SafeSes
 	return vterrors.New(vtrpcpb.Code_ABORTED, errMsg.String())
 }
 
+// readCellPreferences returns the read cell preferences of the first of the
+// tables used by a plan, in alphabetical order, which declares some in the
+// vschema.
+func readCellPreferences(vs *vindexes.VSchema, tablesUsed []string) []*vschemapb.ReadCellPreference {
+	if vs == nil {
+		return nil
+	}
+	for _, tableUsed := range tablesUsed {
+		keyspace, tableName, ok := strings.Cut(tableUsed, ".")
+		if !ok {
+			continue
+		}
+		ks := vs.Keyspaces[keyspace]
+		if ks == nil {
+			continue
+		}
+		if table := ks.Tables[tableName]; table != nil && len(table.ReadCellPreferences) > 0 {
+			return table.ReadCellPreferences
+		}
+	}
+	return nil
+}
+
+// keyspaceTabletTags returns the tablet tags of the keyspaces of the tables
+// used by a plan which declare some in the vschema.
+func keyspaceTabletTags(vs *vindexes.VSchema, tablesUsed []string) map[string]map[string]string {
+	if vs == nil {
+		return nil
+	}
+	var tags map[string]map[string]string
+	for _, tableUsed := range tablesUsed {
+		keyspace, _, ok := strings.Cut(tableUsed, ".")
+		if !ok {
+			continue
+		}
+		if ks := vs.Keyspaces[keyspace]; ks != nil && len(ks.TabletTags) > 0 {
+			if tags == nil {
+				tags = make(map[string]map[string]string)
+			}
+			tags[keyspace] = ks.TabletTags
+		}
+	}
+	return tags
+}
+
 func (e *Executor) setLogStats(logStats *logstats.LogStats, plan *engine.Plan, vcursor *vcursorImpl, execStart time.Time, err error, qr *sqltypes.Result) {
 	logStats.StmtType = plan.Type.String()
 	logStats.ActiveKeyspace = vcursor.keyspace
 	logStats.TablesUsed = plan.TablesUsed
 	logStats.TabletType = vcursor.TabletType().String()
-	errCount := e.logExecutionEnd(logStats, execStart, plan, err, qr)
+	errCount := e.logExecutionEnd(logStats, execStart, plan, vcursor, err, qr)
 	plan.AddStats(1, time.Since(logStats.StartTime), logStats.ShardQueries, logStats.RowsAffected, logStats.RowsReturned, errCount)
 }
 
-func (e *Executor) logExecutionEnd(logStats *logstats.LogStats, execStart time.Time, plan *engine.Plan, err error, qr *sqltypes.Result) uint64 {
+func (e *Executor) logExecutionEnd(logStats *logstats.LogStats, execStart time.Time, plan *engine.Plan, vcursor *vcursorImpl, err error, qr *sqltypes.Result) uint64 {
 	logStats.ExecuteTime = time.Since(execStart)
 
 	e.updateQueryCounts(plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
+	e.recordQueryMetrics(plan, vcursor, logStats)
 
 	var errCount uint64
 	if err != nil {
@@ -398,6 +470,9 @@ func (e *Executor) logExecutionEnd(logStats *logstats.LogStats, execStart time.T
 	} else {
 		logStats.RowsAffected = qr.RowsAffected
 		logStats.RowsReturned = uint64(len(qr.Rows))
+		if queryLogResultChecksum {
+			logStats.ResultChecksum = logstats.ResultChecksum(qr.Rows)
+		}
 	}
 	return errCount
 }
@@ -288,7 +288,7 @@ func buildDBDDLPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, vschema
 		if !ksExists {
 			return nil, vterrors.VT05001(ksName)
 		}
-		return newPlanResult(engine.NewDBDDL(ksName, false, queryTimeout(dbDDL.Comments.Directives()))), nil
+		return newPlanResult(engine.NewDBDDL(ksName, false, queryTimeout(dbDDL.Comments))), nil
 	case *sqlparser.AlterDatabase:
 		if !ksExists {
 			return nil, vterrors.VT05002(ksName)
@@ -301,7 +301,7 @@ func buildDBDDLPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, vschema
 		if !dbDDL.IfNotExists && ksExists {
 			return nil, vterrors.VT06001(ksName)
 		}
-		return newPlanResult(engine.NewDBDDL(ksName, true, queryTimeout(dbDDL.Comments.Directives()))), nil
+		return newPlanResult(engine.NewDBDDL(ksName, true, queryTimeout(dbDDL.Comments))), nil
 	}
 	return nil, vterrors.VT13001(fmt.Sprintf("database DDL not recognized: %s", sqlparser.String(dbDDLstmt)))
 }
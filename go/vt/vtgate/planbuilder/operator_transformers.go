@@ -546,7 +546,7 @@ func getHints(cmt *sqlparser.ParsedComments) *queryHints {
 	}
 	directives := cmt.Directives()
 	scatterAsWarns := directives.IsSet(sqlparser.DirectiveScatterErrorsAsWarnings)
-	timeout := queryTimeout(directives)
+	timeout := queryTimeout(cmt)
 	multiShardAutoCommit := directives.IsSet(sqlparser.DirectiveMultiShardAutocommit)
 	return &queryHints{
 		scatterErrorsAsWarnings: scatterAsWarns,
@@ -55,12 +55,12 @@ func ToSQL(ctx *plancontext.PlanningContext, op Operator) (_ sqlparser.Statement
 	return q.stmt, q.dmlOperator, nil
 }
 
-func (qb *queryBuilder) addTable(db, tableName, alias string, tableID semantics.TableSet, hints sqlparser.IndexHints) {
+func (qb *queryBuilder) addTable(db, tableName, alias string, tableID semantics.TableSet, hints sqlparser.IndexHints, partitions sqlparser.Partitions) {
 	tableExpr := sqlparser.TableName{
 		Name:      sqlparser.NewIdentifierCS(tableName),
 		Qualifier: sqlparser.NewIdentifierCS(db),
 	}
-	qb.addTableExpr(tableName, alias, tableID, tableExpr, hints, nil)
+	qb.addTableExpr(tableName, alias, tableID, tableExpr, hints, partitions, nil)
 }
 
 func (qb *queryBuilder) addTableExpr(
@@ -68,6 +68,7 @@ func (qb *queryBuilder) addTableExpr(
 	tableID semantics.TableSet,
 	tblExpr sqlparser.SimpleTableExpr,
 	hints sqlparser.IndexHints,
+	partitions sqlparser.Partitions,
 	columnAliases sqlparser.Columns,
 ) {
 	if qb.stmt == nil {
@@ -75,7 +76,7 @@ func (qb *queryBuilder) addTableExpr(
 	}
 	tbl := &sqlparser.AliasedTableExpr{
 		Expr:       tblExpr,
-		Partitions: nil,
+		Partitions: partitions,
 		As:         sqlparser.NewIdentifierCS(alias),
 		Hints:      hints,
 		Columns:    columnAliases,
@@ -487,7 +488,7 @@ func buildTable(op *Table, qb *queryBuilder) {
 	if op.QTable.IsInfSchema {
 		dbName = op.QTable.Table.Qualifier.String()
 	}
-	qb.addTable(dbName, op.QTable.Table.Name.String(), op.QTable.Alias.As.String(), TableID(op), op.QTable.Alias.Hints)
+	qb.addTable(dbName, op.QTable.Table.Name.String(), op.QTable.Alias.As.String(), TableID(op), op.QTable.Alias.Hints, op.QTable.Alias.Partitions)
 	for _, pred := range op.QTable.Predicates {
 		qb.addPredicate(pred)
 	}
@@ -515,7 +516,7 @@ func buildProjection(op *Projection, qb *queryBuilder) {
 		qb.stmt = nil
 		qb.addTableExpr(op.DT.Alias, op.DT.Alias, TableID(op), &sqlparser.DerivedTable{
 			Select: sel,
-		}, nil, op.DT.Columns)
+		}, nil, nil, op.DT.Columns)
 	}
 
 	if !isSel {
@@ -598,7 +599,7 @@ func buildDerivedUnion(op *Horizon, qb *queryBuilder, union *sqlparser.Union) {
 
 	qb.addTableExpr(op.Alias, op.Alias, TableID(op), &sqlparser.DerivedTable{
 		Select: union,
-	}, nil, op.ColumnAliases)
+	}, nil, nil, op.ColumnAliases)
 }
 
 func buildDerivedSelect(op *Horizon, qb *queryBuilder, sel *sqlparser.Select) {
@@ -614,7 +615,7 @@ func buildDerivedSelect(op *Horizon, qb *queryBuilder, sel *sqlparser.Select) {
 	sel.Distinct = opQuery.Distinct
 	qb.addTableExpr(op.Alias, op.Alias, TableID(op), &sqlparser.DerivedTable{
 		Select: sel,
-	}, nil, op.ColumnAliases)
+	}, nil, nil, op.ColumnAliases)
 	for _, col := range op.Columns {
 		qb.addProjection(&sqlparser.AliasedExpr{Expr: col})
 	}
@@ -79,6 +79,10 @@ type VSchema interface {
 	// IsViewsEnabled returns true if Vitess manages the views.
 	IsViewsEnabled() bool
 
+	// IsSchemaTrackerMetadataEnabled returns true if the metadata queries
+	// may be answered from the tracked schema.
+	IsSchemaTrackerMetadataEnabled() bool
+
 	// GetUDV returns user defined value from the variable passed.
 	GetUDV(name string) *querypb.BindVariable
 
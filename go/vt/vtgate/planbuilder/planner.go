@@ -54,7 +54,7 @@ func setCommentDirectivesOnPlan(plan engine.Primitive, stmt sqlparser.Statement)
 
 	directives = cmt.GetParsedComments().Directives()
 	scatterAsWarns := directives.IsSet(sqlparser.DirectiveScatterErrorsAsWarnings)
-	timeout := queryTimeout(directives)
+	timeout := queryTimeout(cmt.GetParsedComments())
 	multiShardAutoCommit := directives.IsSet(sqlparser.DirectiveMultiShardAutocommit)
 
 	setDirective(plan, multiShardAutoCommit, timeout, scatterAsWarns)
@@ -77,9 +77,13 @@ func setDirective(prim engine.Primitive, msac bool, timeout int, scatterAsWarns
 	}
 }
 
-// queryTimeout returns DirectiveQueryTimeout value if set, otherwise returns 0.
-func queryTimeout(d *sqlparser.CommentDirectives) int {
-	val, _ := d.GetString(sqlparser.DirectiveQueryTimeout, "0")
+// queryTimeout returns DirectiveQueryTimeout value if set, otherwise the value
+// of the MySQL MAX_EXECUTION_TIME optimizer hint if set, otherwise returns 0.
+func queryTimeout(cmt *sqlparser.ParsedComments) int {
+	val, ok := cmt.Directives().GetString(sqlparser.DirectiveQueryTimeout, "0")
+	if !ok {
+		val = cmt.GetMySQLOptimizerHint(sqlparser.OptimizerHintMaxExecutionTime)
+	}
 	if intVal, err := strconv.Atoi(val); err == nil {
 		return intVal
 	}
@@ -56,6 +56,10 @@ func gen4SelectStmtPlanner(
 		}
 
 		if sel.SQLCalcFoundRows && sel.Limit != nil {
+			if sel.GetParsedComments().Directives().IsSet(sqlparser.DirectiveScatterErrorsAsWarnings) {
+				// FOUND_ROWS() would silently leave out the rows of the shards which failed
+				return nil, vterrors.VT12001("SQL_CALC_FOUND_ROWS with SCATTER_ERRORS_AS_WARNINGS")
+			}
 			return gen4planSQLCalcFoundRows(vschema, sel, query, reservedVars)
 		}
 		// if there was no limit, we can safely ignore the SQLCalcFoundRows directive
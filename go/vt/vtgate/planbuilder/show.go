@@ -96,8 +96,8 @@ func buildShowBasicPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema)
 		return buildPlanWithDB(show, vschema)
 	case sqlparser.StatusGlobal, sqlparser.StatusSession:
 		return buildSendAnywherePlan(show, vschema)
-	case sqlparser.VitessMigrations:
-		return buildShowVitessMigrationsPlan(show, vschema)
+	case sqlparser.VitessMigrations, sqlparser.VitessWorkflows:
+		return buildShowVitessPrimariesPlan(show, vschema)
 	case sqlparser.VGtidExecGlobal:
 		return buildShowVGtidPlan(show, vschema)
 	case sqlparser.GtidExecGlobal:
@@ -108,7 +108,7 @@ func buildShowBasicPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema)
 		return buildPluginsPlan()
 	case sqlparser.Engines:
 		return buildEnginesPlan()
-	case sqlparser.VitessReplicationStatus, sqlparser.VitessShards, sqlparser.VitessTablets, sqlparser.VitessVariables:
+	case sqlparser.VitessPlans, sqlparser.VitessReplicationStatus, sqlparser.VitessSession, sqlparser.VitessShards, sqlparser.VitessTablets, sqlparser.VitessVariables:
 		return &engine.ShowExec{
 			Command:    show.Command,
 			ShowFilter: show.Filter,
@@ -176,6 +176,7 @@ func buildShowTblPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (e
 	dest := key.Destination(key.DestinationAnyShard{})
 	var ks *vindexes.Keyspace
 	var err error
+	tracked := false
 
 	if show.Tbl.Qualifier.NotEmpty() && sqlparser.SystemSchema(show.Tbl.Qualifier.String()) {
 		ks, err = vschema.AnyKeyspace()
@@ -198,15 +199,33 @@ func buildShowTblPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (e
 			dest = destination
 		}
 		ks = table.Keyspace
+		tracked = destination == nil && show.Command == sqlparser.Column && !show.Full
 	}
 
-	return &engine.Send{
+	var plan engine.Primitive
+	plan = &engine.Send{
 		Keyspace:          ks,
 		TargetDestination: dest,
 		Query:             sqlparser.String(show),
 		IsDML:             false,
 		SingleShardOnly:   true,
-	}, nil
+	}
+	if tracked && canUseTrackedSchema(show, vschema) {
+		plan = &engine.SchemaShow{
+			Command:  show.Command,
+			Keyspace: ks.Name,
+			Table:    show.Tbl.Name.String(),
+			Filter:   show.Filter,
+			Fallback: plan,
+		}
+	}
+	return plan, nil
+}
+
+// canUseTrackedSchema returns true if the SHOW query may be answered from the
+// schema tracked by vtgate, which only supports the LIKE filters.
+func canUseTrackedSchema(show *sqlparser.ShowBasic, vschema plancontext.VSchema) bool {
+	return vschema.IsSchemaTrackerMetadataEnabled() && (show.Filter == nil || show.Filter.Filter == nil)
 }
 
 func buildDBPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
@@ -244,9 +263,9 @@ func buildDBPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine
 	return engine.NewRowsPrimitive(rows, buildVarCharFields("Database")), nil
 }
 
-// buildShowVitessMigrationsPlan serves `SHOW VITESS_MIGRATIONS ...` queries.
+// buildShowVitessPrimariesPlan serves `SHOW VITESS_MIGRATIONS ...` and `SHOW VITESS_WORKFLOWS ...` queries.
 // It sends down the SHOW command to the PRIMARY shard tablets (on all shards)
-func buildShowVitessMigrationsPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
+func buildShowVitessPrimariesPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
 	dest, ks, tabletType, err := vschema.TargetDestination(show.DbName.String())
 	if err != nil {
 		return nil, err
@@ -274,7 +293,8 @@ func buildShowVitessMigrationsPlan(show *sqlparser.ShowBasic, vschema plancontex
 func buildPlanWithDB(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
 	dbName := show.DbName
 	dbDestination := show.DbName.String()
-	if sqlparser.SystemSchema(dbDestination) {
+	systemSchema := sqlparser.SystemSchema(dbDestination)
+	if systemSchema {
 		ks, err := vschema.AnyKeyspace()
 		if err != nil {
 			return nil, err
@@ -288,6 +308,8 @@ func buildPlanWithDB(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (en
 	if err != nil {
 		return nil, err
 	}
+	// The tracked schema only knows the views when vtgate manages them.
+	tracked := destination == nil && !systemSchema && vschema.IsViewsEnabled()
 	if destination == nil {
 		destination = key.DestinationAnyShard{}
 	}
@@ -310,6 +332,16 @@ func buildPlanWithDB(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (en
 		if err != nil {
 			return nil, err
 		}
+		if tracked && canUseTrackedSchema(show, vschema) {
+			plan = &engine.SchemaShow{
+				Command:  show.Command,
+				Keyspace: keyspace.Name,
+				DbName:   dbName.String(),
+				Full:     show.Full,
+				Filter:   show.Filter,
+				Fallback: plan,
+			}
+		}
 	}
 	return plan, nil
 
@@ -28,6 +28,7 @@ import (
 	"vitess.io/vitess/go/test/vschemawrapper"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
@@ -120,3 +121,46 @@ func TestGenerateCharsetRows(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildShowTrackedSchemaPlan(t *testing.T) {
+	vschema := &vschemawrapper.VSchemaWrapper{
+		V:                           loadSchema(t, "vschemas/schema.json", true),
+		Keyspace:                    &vindexes.Keyspace{Name: "user"},
+		EnableViews:                 true,
+		EnableSchemaTrackerMetadata: true,
+		Env:                         vtenv.NewTestEnv(),
+	}
+
+	testCases := []struct {
+		query   string
+		tracked bool
+	}{
+		{query: "show tables", tracked: true},
+		{query: "show full tables from user like 'u%'", tracked: true},
+		{query: "show tables where Tables_in_user = 'user'", tracked: false},
+		{query: "show tables from information_schema", tracked: false},
+		{query: "show columns from user", tracked: true},
+		{query: "show columns from user like 'i%'", tracked: true},
+		{query: "show full columns from user", tracked: false},
+		{query: "show columns from user where Field = 'id'", tracked: false},
+		{query: "show index from user", tracked: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := sqlparser.NewTestParser().Parse(tc.query)
+			require.NoError(t, err)
+			plan, err := buildShowBasicPlan(stmt.(*sqlparser.Show).Internal.(*sqlparser.ShowBasic), vschema)
+			require.NoError(t, err)
+			_, tracked := plan.(*engine.SchemaShow)
+			require.Equal(t, tc.tracked, tracked)
+		})
+	}
+
+	vschema.EnableSchemaTrackerMetadata = false
+	stmt, err := sqlparser.NewTestParser().Parse("show columns from user")
+	require.NoError(t, err)
+	plan, err := buildShowBasicPlan(stmt.(*sqlparser.Show).Internal.(*sqlparser.ShowBasic), vschema)
+	require.NoError(t, err)
+	require.IsType(t, &engine.Send{}, plan)
+}
@@ -18,11 +18,13 @@ package vtgate
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -37,16 +39,19 @@ import (
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/loaddata"
 	"vitess.io/vitess/go/vt/vttls"
 )
 
@@ -58,12 +63,14 @@ var (
 	mysqlAuthServerImpl               = "static"
 	mysqlAllowClearTextWithoutTLS     bool
 	mysqlProxyProtocol                bool
+	mysqlProxyProtocolAllowedSources  []string
 	mysqlServerRequireSecureTransport bool
 	mysqlSslCert                      string
 	mysqlSslKey                       string
 	mysqlSslCa                        string
 	mysqlSslCrl                       string
 	mysqlSslServerCA                  string
+	mysqlSslSpiffeIDs                 []string
 	mysqlTLSMinVersion                string
 
 	mysqlKeepAlivePeriod          time.Duration
@@ -72,6 +79,7 @@ var (
 	mysqlQueryTimeout             time.Duration
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
+	mysqlCompressionAlgorithms    []string
 
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
@@ -84,9 +92,10 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&mysqlServerBindAddress, "mysql_server_bind_address", mysqlServerBindAddress, "Binds on this address when listening to MySQL binary protocol. Useful to restrict listening to 'localhost' only for instance.")
 	fs.StringVar(&mysqlServerSocketPath, "mysql_server_socket_path", mysqlServerSocketPath, "This option specifies the Unix socket file to use when listening for local connections. By default it will be empty and it won't listen to a unix socket")
 	fs.StringVar(&mysqlTCPVersion, "mysql_tcp_version", mysqlTCPVersion, "Select tcp, tcp4, or tcp6 to control the socket type.")
-	fs.StringVar(&mysqlAuthServerImpl, "mysql_auth_server_impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, clientcert, static, vault.")
+	fs.StringVar(&mysqlAuthServerImpl, "mysql_auth_server_impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, oidc, clientcert, static, vault.")
 	fs.BoolVar(&mysqlAllowClearTextWithoutTLS, "mysql_allow_clear_text_without_tls", mysqlAllowClearTextWithoutTLS, "If set, the server will allow the use of a clear text password over non-SSL connections.")
 	fs.BoolVar(&mysqlProxyProtocol, "proxy_protocol", mysqlProxyProtocol, "Enable HAProxy PROXY protocol on MySQL listener socket")
+	fs.StringSliceVar(&mysqlProxyProtocolAllowedSources, "proxy_protocol_allowed_sources", mysqlProxyProtocolAllowedSources, "comma-separated list of the IP addresses and CIDR ranges of the load balancers allowed to send a PROXY protocol header, requires proxy_protocol. The connections from other addresses which send one are refused. All the addresses are allowed by default.")
 	fs.BoolVar(&mysqlServerRequireSecureTransport, "mysql_server_require_secure_transport", mysqlServerRequireSecureTransport, "Reject insecure connections but only if mysql_server_ssl_cert and mysql_server_ssl_key are provided")
 	fs.StringVar(&mysqlSslCert, "mysql_server_ssl_cert", mysqlSslCert, "Path to the ssl cert for mysql server plugin SSL")
 	fs.StringVar(&mysqlSslKey, "mysql_server_ssl_key", mysqlSslKey, "Path to ssl key for mysql server plugin SSL")
@@ -94,6 +103,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&mysqlSslCrl, "mysql_server_ssl_crl", mysqlSslCrl, "Path to ssl CRL for mysql server plugin SSL")
 	fs.StringVar(&mysqlTLSMinVersion, "mysql_server_tls_min_version", mysqlTLSMinVersion, "Configures the minimal TLS version negotiated when SSL is enabled. Defaults to TLSv1.2. Options: TLSv1.0, TLSv1.1, TLSv1.2, TLSv1.3.")
 	fs.StringVar(&mysqlSslServerCA, "mysql_server_ssl_server_ca", mysqlSslServerCA, "path to server CA in PEM format, which will be combine with server cert, return full certificate chain to clients")
+	fs.StringSliceVar(&mysqlSslSpiffeIDs, "mysql_server_ssl_spiffe_ids", mysqlSslSpiffeIDs, "comma-separated list of SPIFFE IDs allowed in client certs for mysql server plugin SSL, requires mysql_server_ssl_ca. An ID ending with /* allows all the IDs under it")
 	fs.DurationVar(&mysqlSlowConnectWarnThreshold, "mysql_slow_connect_warn_threshold", mysqlSlowConnectWarnThreshold, "Warn if it takes more than the given threshold for a mysql connection to establish")
 	fs.DurationVar(&mysqlConnReadTimeout, "mysql_server_read_timeout", mysqlConnReadTimeout, "connection read timeout")
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
@@ -102,6 +112,9 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	fs.DurationVar(&mysqlServerFlushDelay, "mysql_server_flush_delay", mysqlServerFlushDelay, "Delay after which buffered response will be flushed to the client.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
+	fs.StringSliceVar(&mysqlCompressionAlgorithms, "mysql_server_compression_algorithms", mysqlCompressionAlgorithms, "comma-separated list of the algorithms clients may compress their connections with: zlib, zstd. Connections are not compressed by default.")
+	fs.BoolVar(&mysqlServerLocalInfile, "mysql_server_local_infile", mysqlServerLocalInfile, "If set, the clients may load their files with LOAD DATA LOCAL INFILE, whose rows are inserted in batches routed to their shards.")
+	fs.IntVar(&mysqlServerLocalInfileBatchSize, "mysql_server_local_infile_batch_size", mysqlServerLocalInfileBatchSize, "Number of rows of the files of LOAD DATA LOCAL INFILE statements inserted by each statement. Outside of a transaction, each batch commits on its own.")
 }
 
 // vtgateHandler implements the Listener interface.
@@ -170,6 +183,44 @@ func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 	_ = vh.vtg.CloseSession(ctx, session)
 }
 
+// maxClientStatsLabels is the number of programs and versions of the MySQL
+// clients which get their own labels in queriesByClient: the clients choose
+// their connection attributes, so the other ones are counted as "other".
+const maxClientStatsLabels = 100
+
+var (
+	queriesByClient = stats.NewCountersWithMultiLabels(
+		"MysqlServerQueriesByClient",
+		"Queries received by the MySQL server per client program and version, from the connection attributes of the clients",
+		[]string{"Program", "ClientVersion"})
+
+	clientStatsLabelsMu   sync.Mutex
+	clientStatsLabelsSeen = make(map[[2]string]bool)
+)
+
+// clientStatsLabels returns the labels of queriesByClient for the client of
+// a session.
+func clientStatsLabels(session *vtgatepb.Session) []string {
+	program, version := mysql.ClientProgram(session.ConnectionAttributes)
+	if program == "" {
+		program = "unknown"
+	}
+	if version == "" {
+		version = "unknown"
+	}
+	labels := [2]string{program, version}
+
+	clientStatsLabelsMu.Lock()
+	defer clientStatsLabelsMu.Unlock()
+	if !clientStatsLabelsSeen[labels] {
+		if len(clientStatsLabelsSeen) >= maxClientStatsLabels {
+			return []string{"other", "other"}
+		}
+		clientStatsLabelsSeen[labels] = true
+	}
+	return labels[:]
+}
+
 // Regexp to extract parent span id over the sql query
 var r = regexp.MustCompile(`/\*VT_SPAN_CONTEXT=(.*)\*/`)
 
@@ -206,6 +257,7 @@ func startSpan(ctx context.Context, query, label string) (trace.Span, context.Co
 
 func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
 	session := vh.session(c)
+	queriesByClient.Add(clientStatsLabels(session), 1)
 	if c.IsShuttingDown() && !session.InTransaction {
 		c.MarkForClose()
 		return sqlerror.NewSQLError(sqlerror.ERServerShutdown, sqlerror.SSNetError, "Server shutdown in progress")
@@ -248,6 +300,21 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		}
 	}()
 
+	if mysqlServerLocalInfile {
+		stmt, err := loaddata.Parse(vh.Env().Parser(), query)
+		if err != nil {
+			return sqlerror.NewSQLErrorFromError(err)
+		}
+		if stmt != nil {
+			session, result, err := vh.loadData(ctx, c, session, stmt)
+			if err := sqlerror.NewSQLErrorFromError(err); err != nil {
+				return err
+			}
+			fillInTxStatusFlags(c, session)
+			return callback(result)
+		}
+	}
+
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
 		session, err := vh.vtg.StreamExecute(ctx, vh, session, query, make(map[string]*querypb.BindVariable), callback)
 		if err != nil {
@@ -345,6 +412,7 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 	ctx = callerid.NewContext(ctx, ef, im)
 
 	session := vh.session(c)
+	queriesByClient.Add(clientStatsLabels(session), 1)
 	if !session.InTransaction {
 		vh.busyConnections.Add(1)
 	}
@@ -395,9 +463,9 @@ func (vh *vtgateHandler) KillConnection(ctx context.Context, connectionID uint32
 	vh.mu.Lock()
 	defer vh.mu.Unlock()
 
-	c, exists := vh.connections[connectionID]
-	if !exists {
-		return sqlerror.NewSQLError(sqlerror.ERNoSuchThread, sqlerror.SSUnknownSQLState, "Unknown thread id: %d", connectionID)
+	c, err := vh.connectionToKill(ctx, connectionID)
+	if err != nil {
+		return err
 	}
 
 	// First, we mark the connection for close, so that even when the context is cancelled, while returning the response back to client,
@@ -405,22 +473,42 @@ func (vh *vtgateHandler) KillConnection(ctx context.Context, connectionID uint32
 	// Closing the connection will trigger ConnectionClosed method which rollback any open transaction.
 	c.MarkForClose()
 	c.CancelCtx()
+	// An idle connection waits for the next command of its client, closing
+	// its socket ends it right away, so that its transaction is rolled back
+	// and its reserved connections are released, like MySQL does.
+	c.Close()
 
 	return nil
 }
 
 // KillQuery cancels any execution query on the provided connection ID.
-func (vh *vtgateHandler) KillQuery(connectionID uint32) error {
+func (vh *vtgateHandler) KillQuery(ctx context.Context, connectionID uint32) error {
 	vh.mu.Lock()
 	defer vh.mu.Unlock()
-	c, exists := vh.connections[connectionID]
-	if !exists {
-		return sqlerror.NewSQLError(sqlerror.ERNoSuchThread, sqlerror.SSUnknownSQLState, "Unknown thread id: %d", connectionID)
+	c, err := vh.connectionToKill(ctx, connectionID)
+	if err != nil {
+		return err
 	}
 	c.CancelCtx()
 	return nil
 }
 
+// connectionToKill returns the connection to kill for the caller of ctx.
+// Like in MySQL, the users can kill their own connections, and only the
+// users of --kill-statement-admin-users can kill the connections of the
+// other users. vh.mu must be held.
+func (vh *vtgateHandler) connectionToKill(ctx context.Context, connectionID uint32) (*mysql.Conn, error) {
+	c, exists := vh.connections[connectionID]
+	if !exists {
+		return nil, sqlerror.NewSQLError(sqlerror.ERNoSuchThread, sqlerror.SSUnknownSQLState, "Unknown thread id: %d", connectionID)
+	}
+	user := callerid.ImmediateCallerIDFromContext(ctx).GetUsername()
+	if user != c.UserData.Get().GetUsername() && !slices.Contains(killStmtAdminUsers, user) {
+		return nil, sqlerror.NewSQLError(sqlerror.ERKillDenied, sqlerror.SSUnknownSQLState, "You are not owner of thread %d", connectionID)
+	}
+	return c, nil
+}
+
 func (vh *vtgateHandler) Env() *vtenv.Environment {
 	return vh.vtg.executor.env
 }
@@ -441,6 +529,7 @@ func (vh *vtgateHandler) session(c *mysql.Conn) *vtgatepb.Session {
 			MigrationContext:     "",
 			SessionUUID:          u.String(),
 			EnableSystemSettings: sysVarSetEnabled,
+			ConnectionAttributes: c.Attributes,
 		}
 		if c.Capabilities&mysql.CapabilityClientFoundRows != 0 {
 			session.Options.ClientFoundRows = true
@@ -457,9 +546,35 @@ type mysqlServer struct {
 	vtgateHandle *vtgateHandler
 }
 
+// newMySQLServerTLSConfig returns the tls config for the mysql listener. The
+// config is rebuilt for each connection, so that changes to the cert, key, ca
+// and crl files are picked up without a restart.
+func newMySQLServerTLSConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA string, mysqlSslSpiffeIDs []string, mysqlMinTLSVersion uint16) (*tls.Config, error) {
+	if len(mysqlSslSpiffeIDs) > 0 && mysqlSslCa == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "mysql_server_ssl_spiffe_ids requires mysql_server_ssl_ca to verify client certs")
+	}
+
+	build := func() (*tls.Config, error) {
+		serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+		if err != nil {
+			return nil, err
+		}
+		return serverConfig, vttls.RequireSPIFFEIDs(serverConfig, mysqlSslSpiffeIDs)
+	}
+
+	serverConfig, err := build()
+	if err != nil {
+		return nil, err
+	}
+	serverConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return build()
+	}
+	return serverConfig, nil
+}
+
 // initTLSConfig inits tls config for the given mysql listener
-func initTLSConfig(ctx context.Context, srv *mysqlServer, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA string, mysqlServerRequireSecureTransport bool, mysqlMinTLSVersion uint16) error {
-	serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+func initTLSConfig(ctx context.Context, srv *mysqlServer, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA string, mysqlSslSpiffeIDs []string, mysqlServerRequireSecureTransport bool, mysqlMinTLSVersion uint16) error {
+	serverConfig, err := newMySQLServerTLSConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlSslSpiffeIDs, mysqlMinTLSVersion)
 	if err != nil {
 		log.Exitf("grpcutils.TLSServerConfig failed: %v", err)
 		return err
@@ -474,7 +589,7 @@ func initTLSConfig(ctx context.Context, srv *mysqlServer, mysqlSslCert, mysqlSsl
 			case <-ctx.Done():
 				return
 			case <-srv.sigChan:
-				serverConfig, err := vttls.ServerConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlMinTLSVersion)
+				serverConfig, err := newMySQLServerTLSConfig(mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlSslSpiffeIDs, mysqlMinTLSVersion)
 				if err != nil {
 					log.Errorf("grpcutils.TLSServerConfig failed: %v", err)
 				} else {
@@ -519,8 +634,12 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 		log.Exitf("-mysql_tcp_version must be one of [tcp, tcp4, tcp6]")
 	}
 
+	compressionAlgorithms, err := parseCompressionAlgorithms(mysqlCompressionAlgorithms)
+	if err != nil {
+		log.Exitf("-mysql_server_compression_algorithms: %v", err)
+	}
+
 	// Create a Listener.
-	var err error
 	srv := &mysqlServer{}
 	srv.vtgateHandle = newVtgateHandler(vtgate)
 	if mysqlServerPort >= 0 {
@@ -539,15 +658,22 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 		if err != nil {
 			log.Exitf("mysql.NewListener failed: %v", err)
 		}
+		if len(mysqlProxyProtocolAllowedSources) > 0 {
+			if err := srv.tcpListener.SetProxyProtocolAllowedSources(mysqlProxyProtocolAllowedSources); err != nil {
+				log.Exitf("-proxy_protocol_allowed_sources: %v", err)
+			}
+		}
 		if mysqlSslCert != "" && mysqlSslKey != "" {
 			tlsVersion, err := vttls.TLSVersionToNumber(mysqlTLSMinVersion)
 			if err != nil {
 				log.Exitf("mysql.NewListener failed: %v", err)
 			}
 
-			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
+			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlSslSpiffeIDs, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		srv.tcpListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		srv.tcpListener.CompressionAlgorithms = compressionAlgorithms
+		srv.tcpListener.LocalInfile = mysqlServerLocalInfile
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
@@ -566,6 +692,22 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 	return srv
 }
 
+// parseCompressionAlgorithms parses the compression algorithms the clients
+// may use.
+func parseCompressionAlgorithms(names []string) ([]mysql.CompressionAlgorithm, error) {
+	var algorithms []mysql.CompressionAlgorithm
+	for _, name := range names {
+		algorithm, err := mysql.ParseCompressionAlgorithm(name)
+		if err != nil {
+			return nil, err
+		}
+		if algorithm != mysql.CompressionNone {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	return algorithms, nil
+}
+
 // newMysqlUnixSocket creates a new unix socket mysql listener. If a socket file already exists, attempts
 // to clean it up.
 func newMysqlUnixSocket(address string, authServer mysql.AuthServer, handler mysql.Handler) (*mysql.Listener, error) {
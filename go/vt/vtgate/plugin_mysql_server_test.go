@@ -35,7 +35,9 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/callerid"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	"vitess.io/vitess/go/vt/tlstest"
 	"vitess.io/vitess/go/vt/vtenv"
 )
@@ -290,7 +292,7 @@ func testInitTLSConfig(t *testing.T, serverCA bool) {
 	}
 
 	srv := &mysqlServer{tcpListener: &mysql.Listener{}}
-	if err := initTLSConfig(ctx, srv, path.Join(root, "server-cert.pem"), path.Join(root, "server-key.pem"), path.Join(root, "ca-cert.pem"), path.Join(root, "ca-crl.pem"), serverCACert, true, tls.VersionTLS12); err != nil {
+	if err := initTLSConfig(ctx, srv, path.Join(root, "server-cert.pem"), path.Join(root, "server-key.pem"), path.Join(root, "ca-cert.pem"), path.Join(root, "ca-crl.pem"), serverCACert, nil, true, tls.VersionTLS12); err != nil {
 		t.Fatalf("init tls config failure due to: +%v", err)
 	}
 
@@ -311,17 +313,19 @@ func testInitTLSConfig(t *testing.T, serverCA bool) {
 func TestKillMethods(t *testing.T) {
 	executor, _, _, _, _ := createExecutorEnv(t)
 	vh := newVtgateHandler(&VTGate{executor: executor})
+	ctx := callerid.NewContext(context.Background(), nil, callerid.NewImmediateCallerID("user1"))
 
 	// connection does not exist
-	err := vh.KillQuery(12345)
+	err := vh.KillQuery(ctx, 12345)
 	assert.ErrorContains(t, err, "Unknown thread id: 12345 (errno 1094) (sqlstate HY000)")
 
-	err = vh.KillConnection(context.Background(), 12345)
+	err = vh.KillConnection(ctx, 12345)
 	assert.ErrorContains(t, err, "Unknown thread id: 12345 (errno 1094) (sqlstate HY000)")
 
 	// add a connection
 	mysqlConn := mysql.GetTestConn()
 	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{Username: "user1"}
 	vh.connections[1] = mysqlConn
 
 	// connection exists
@@ -330,8 +334,27 @@ func TestKillMethods(t *testing.T) {
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	mysqlConn.UpdateCancelCtx(cancelFunc)
 
+	// the connections of the other users cannot be killed
+	otherCtx := callerid.NewContext(context.Background(), nil, callerid.NewImmediateCallerID("user2"))
+	err = vh.KillQuery(otherCtx, 1)
+	assert.ErrorContains(t, err, "You are not owner of thread 1 (errno 1095) (sqlstate HY000)")
+	err = vh.KillConnection(otherCtx, 1)
+	assert.ErrorContains(t, err, "You are not owner of thread 1 (errno 1095) (sqlstate HY000)")
+	require.NoError(t, cancelCtx.Err())
+
+	// unless by the admin users
+	defer func(users []string) { killStmtAdminUsers = users }(killStmtAdminUsers)
+	killStmtAdminUsers = []string{"user2"}
+	err = vh.KillQuery(otherCtx, 1)
+	assert.NoError(t, err)
+	require.EqualError(t, cancelCtx.Err(), "context canceled")
+
+	// updating context.
+	cancelCtx, cancelFunc = context.WithCancel(context.Background())
+	mysqlConn.UpdateCancelCtx(cancelFunc)
+
 	// kill query
-	err = vh.KillQuery(1)
+	err = vh.KillQuery(ctx, 1)
 	assert.NoError(t, err)
 	require.EqualError(t, cancelCtx.Err(), "context canceled")
 
@@ -340,10 +363,11 @@ func TestKillMethods(t *testing.T) {
 	mysqlConn.UpdateCancelCtx(cancelFunc)
 
 	// kill connection
-	err = vh.KillConnection(context.Background(), 1)
+	err = vh.KillConnection(ctx, 1)
 	assert.NoError(t, err)
 	require.EqualError(t, cancelCtx.Err(), "context canceled")
 	require.True(t, mysqlConn.IsMarkedForClose())
+	require.True(t, mysqlConn.IsClosed())
 }
 
 func TestGracefulShutdown(t *testing.T) {
@@ -422,3 +446,63 @@ func TestGracefulShutdownWithTransaction(t *testing.T) {
 
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+func TestConnectionAttributes(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	mysqlConn.Attributes = map[string]string{
+		mysql.ConnAttrProgramName:   "billing",
+		mysql.ConnAttrClientName:    "libmysql",
+		mysql.ConnAttrClientVersion: "8.0.36",
+	}
+	vh.connections[1] = mysqlConn
+
+	before := queriesByClient.Counts()["billing.8_0_36"]
+	err = vh.ComQuery(mysqlConn, "select 1", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, mysqlConn.Attributes, vh.session(mysqlConn).ConnectionAttributes)
+	assert.EqualValues(t, 1, queriesByClient.Counts()["billing.8_0_36"]-before)
+}
+
+func TestClientStatsLabels(t *testing.T) {
+	clientStatsLabelsMu.Lock()
+	clientStatsLabelsSeen = make(map[[2]string]bool)
+	clientStatsLabelsMu.Unlock()
+
+	assert.Equal(t, []string{"unknown", "unknown"}, clientStatsLabels(&vtgatepb.Session{}))
+	assert.Equal(t, []string{"libmysql", "8.0.36"}, clientStatsLabels(&vtgatepb.Session{ConnectionAttributes: map[string]string{
+		mysql.ConnAttrClientName:    "libmysql",
+		mysql.ConnAttrClientVersion: "8.0.36",
+	}}))
+
+	for i := len(clientStatsLabelsSeen); i < maxClientStatsLabels; i++ {
+		clientStatsLabels(&vtgatepb.Session{ConnectionAttributes: map[string]string{mysql.ConnAttrProgramName: fmt.Sprintf("program%d", i)}})
+	}
+	assert.Equal(t, []string{"other", "other"}, clientStatsLabels(&vtgatepb.Session{ConnectionAttributes: map[string]string{mysql.ConnAttrProgramName: "one too many"}}))
+	// The programs seen before keep their labels.
+	assert.Equal(t, []string{"unknown", "unknown"}, clientStatsLabels(&vtgatepb.Session{}))
+}
+
+func TestParseCompressionAlgorithms(t *testing.T) {
+	algorithms, err := parseCompressionAlgorithms([]string{"zstd", "uncompressed", "zlib"})
+	require.NoError(t, err)
+	assert.Equal(t, []mysql.CompressionAlgorithm{mysql.CompressionZstd, mysql.CompressionZlib}, algorithms)
+
+	algorithms, err = parseCompressionAlgorithms(nil)
+	require.NoError(t, err)
+	assert.Empty(t, algorithms)
+
+	_, err = parseCompressionAlgorithms([]string{"zlib", "lz4"})
+	assert.ErrorContains(t, err, "unknown compression algorithm")
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/srvtopo"
+)
+
+const (
+	queryMetricsKeyspace = "keyspace"
+	queryMetricsShard    = "shard"
+	queryMetricsTable    = "table"
+
+	// queryMetricsOther is reported for the values which are not in the
+	// allow-list, or exceed the maximum number of values of a dimension.
+	queryMetricsOther = "other"
+
+	// queryMetricsMultipleShards is reported for the queries sent to more
+	// than one shard.
+	queryMetricsMultipleShards = "multiple"
+)
+
+var (
+	// queryMetricsDimensions lists the dimensions of the query metrics. They
+	// are disabled when it is empty.
+	queryMetricsDimensions []string
+	// queryMetricsKeyspaces is the allow-list of the keyspaces reported by the
+	// query metrics.
+	queryMetricsKeyspaces []string
+	// queryMetricsMaxValues is the maximum number of values of each dimension
+	// of the query metrics.
+	queryMetricsMaxValues = 100
+
+	queryCounts = stats.NewCountersWithMultiLabels(
+		"VtgateQueryCounts",
+		"Queries executed through the VTGate API per keyspace, shard and table, see --query-metrics-dimensions",
+		[]string{"Keyspace", "Shard", "Table"})
+
+	queryTimings = stats.NewMultiTimings(
+		"VtgateQueryTimings",
+		"Timings of the queries executed through the VTGate API per keyspace, shard and table, see --query-metrics-dimensions",
+		[]string{"Keyspace", "Shard", "Table"})
+)
+
+// queryMetrics records the counts and timings of the queries with the
+// keyspace, shard and table they target. To bound the cardinality of the
+// metrics, the dimensions which are not enabled are reported as "all", the
+// keyspaces which are not in the allow-list as "other", and so are the values
+// of a dimension which are not among its most frequent ones.
+type queryMetrics struct {
+	keyspace, shard, table bool
+	keyspaces              map[string]bool
+
+	counts  *stats.CountersWithMultiLabels
+	timings *stats.MultiTimings
+
+	mu     sync.Mutex
+	values [3]*topValues
+}
+
+// newQueryMetrics returns the query metrics for the given dimensions, or nil if
+// there are none.
+func newQueryMetrics(dimensions, keyspaces []string, maxValues int, counts *stats.CountersWithMultiLabels, timings *stats.MultiTimings) (*queryMetrics, error) {
+	if len(dimensions) == 0 {
+		return nil, nil
+	}
+	qm := &queryMetrics{
+		counts:  counts,
+		timings: timings,
+	}
+	for _, dimension := range dimensions {
+		switch strings.ToLower(strings.TrimSpace(dimension)) {
+		case queryMetricsKeyspace:
+			qm.keyspace = true
+		case queryMetricsShard:
+			qm.shard = true
+		case queryMetricsTable:
+			qm.table = true
+		default:
+			return nil, fmt.Errorf("invalid query metrics dimension %q, must be one of %s, %s or %s", dimension, queryMetricsKeyspace, queryMetricsShard, queryMetricsTable)
+		}
+	}
+	if len(keyspaces) > 0 {
+		qm.keyspaces = make(map[string]bool, len(keyspaces))
+		for _, ks := range keyspaces {
+			qm.keyspaces[ks] = true
+		}
+	}
+	for i := range qm.values {
+		qm.values[i] = newTopValues(maxValues)
+	}
+	return qm, nil
+}
+
+// tracksShards returns whether the shards targeted by the queries must be
+// recorded.
+func (qm *queryMetrics) tracksShards() bool {
+	return qm != nil && qm.shard
+}
+
+// record records a query executed on the given keyspace, shard and table.
+func (qm *queryMetrics) record(keyspace, shard, table string, elapsed time.Duration) {
+	if qm == nil {
+		return
+	}
+	labels := []string{stats.StatsAllStr, stats.StatsAllStr, stats.StatsAllStr}
+	if qm.keyspaces != nil && !qm.keyspaces[keyspace] {
+		// The shards and tables of the keyspaces which are not in the
+		// allow-list are not reported either.
+		keyspace, shard, table = queryMetricsOther, queryMetricsOther, queryMetricsOther
+	}
+
+	qm.mu.Lock()
+	if qm.keyspace {
+		labels[0] = qm.values[0].observe(keyspace)
+	}
+	if qm.shard {
+		labels[1] = qm.values[1].observe(shard)
+	}
+	if qm.table {
+		labels[2] = qm.values[2].observe(table)
+	}
+	qm.mu.Unlock()
+
+	qm.counts.Add(labels, 1)
+	qm.timings.Add(labels, elapsed)
+}
+
+// topValue is a value of a dimension of the query metrics, with the number of
+// times it was seen.
+type topValue struct {
+	value   string
+	count   int64
+	tracked bool
+	index   int
+}
+
+// topValueHeap is a min-heap of values by count.
+type topValueHeap []*topValue
+
+func (h topValueHeap) Len() int           { return len(h) }
+func (h topValueHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+
+func (h topValueHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topValueHeap) Push(x any) {
+	v := x.(*topValue)
+	v.index = len(*h)
+	*h = append(*h, v)
+}
+
+func (h *topValueHeap) Pop() any {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+// topValues keeps track of the most frequent values of a dimension of the
+// query metrics, in bounded memory. Up to max values are tracked, and reported
+// as themselves. The other values are candidates, reported as "other", of which
+// at most max are counted, a new candidate replacing the least frequent one. A
+// candidate seen more often than the least frequent tracked value takes its
+// place, and that value is reported as "other" from then on.
+type topValues struct {
+	max        int
+	tracked    topValueHeap
+	candidates topValueHeap
+	values     map[string]*topValue
+}
+
+// newTopValues returns a topValues tracking up to max values, or every value
+// if max is not positive.
+func newTopValues(max int) *topValues {
+	return &topValues{
+		max:    max,
+		values: make(map[string]*topValue),
+	}
+}
+
+// observe counts the value and returns the value to report for it, which is
+// "other" unless it is among the most frequent values.
+func (tv *topValues) observe(value string) string {
+	v, ok := tv.values[value]
+	switch {
+	case ok && v.tracked:
+		v.count++
+		heap.Fix(&tv.tracked, v.index)
+		return value
+	case !ok && (tv.max <= 0 || len(tv.tracked) < tv.max):
+		v = &topValue{value: value, count: 1, tracked: true}
+		tv.values[value] = v
+		heap.Push(&tv.tracked, v)
+		return value
+	case ok:
+		v.count++
+		heap.Fix(&tv.candidates, v.index)
+	case len(tv.candidates) < tv.max:
+		v = &topValue{value: value, count: 1}
+		tv.values[value] = v
+		heap.Push(&tv.candidates, v)
+	default:
+		// The new value replaces the least frequent candidate. It does not
+		// inherit its count, so that many rare values cannot add up to a
+		// frequent one and churn the reported values.
+		v = tv.candidates[0]
+		delete(tv.values, v.value)
+		v.value = value
+		v.count = 1
+		tv.values[value] = v
+		heap.Fix(&tv.candidates, v.index)
+	}
+
+	least := tv.tracked[0]
+	if v.count <= least.count {
+		return queryMetricsOther
+	}
+
+	// The candidate and the least frequent tracked value swap places.
+	ti, ci := least.index, v.index
+	tv.tracked[ti], tv.candidates[ci] = v, least
+	v.index, least.index = ti, ci
+	v.tracked, least.tracked = true, false
+	heap.Fix(&tv.tracked, ti)
+	heap.Fix(&tv.candidates, ci)
+	return value
+}
+
+// queryShard records the shards targeted by the queries of a statement, and
+// returns the value of the shard dimension of its query metrics.
+type queryShard struct {
+	mu    sync.Mutex
+	shard string
+}
+
+func (qs *queryShard) add(rss []*srvtopo.ResolvedShard) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	for _, rs := range rss {
+		switch qs.shard {
+		case "":
+			qs.shard = rs.Target.Shard
+		case rs.Target.Shard, queryMetricsMultipleShards:
+		default:
+			qs.shard = queryMetricsMultipleShards
+		}
+	}
+}
+
+func (qs *queryShard) get() string {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return qs.shard
+}
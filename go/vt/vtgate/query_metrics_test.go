@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func newTestQueryMetrics(t *testing.T, dimensions, keyspaces []string, maxValues int) *queryMetrics {
+	labels := []string{"Keyspace", "Shard", "Table"}
+	qm, err := newQueryMetrics(dimensions, keyspaces, maxValues, stats.NewCountersWithMultiLabels("", "", labels), stats.NewMultiTimings("", "", labels))
+	require.NoError(t, err)
+	return qm
+}
+
+func TestQueryMetrics(t *testing.T) {
+	qm, err := newQueryMetrics(nil, nil, 0, queryCounts, queryTimings)
+	require.NoError(t, err)
+	assert.Nil(t, qm)
+	assert.False(t, qm.tracksShards())
+	qm.record("ks", "-80", "t1", time.Millisecond)
+
+	_, err = newQueryMetrics([]string{"keyspace", "column"}, nil, 0, queryCounts, queryTimings)
+	require.EqualError(t, err, `invalid query metrics dimension "column", must be one of keyspace, shard or table`)
+
+	qm = newTestQueryMetrics(t, []string{"keyspace", "table"}, nil, 2)
+	assert.False(t, qm.tracksShards())
+	qm.record("ks1", "-80", "t1", time.Millisecond)
+	qm.record("ks1", "80-", "t2", time.Millisecond)
+	qm.record("ks2", "-80", "t3", time.Millisecond)
+	qm.record("ks1", "-80", "t1", time.Millisecond)
+	assert.Equal(t, map[string]int64{
+		"ks1.all.t1":    2,
+		"ks1.all.t2":    1,
+		"ks2.all.other": 1,
+	}, qm.counts.Counts())
+	assert.Equal(t, int64(4), qm.timings.Count())
+
+	qm = newTestQueryMetrics(t, []string{"keyspace", "shard"}, []string{"ks1"}, 0)
+	assert.True(t, qm.tracksShards())
+	qm.record("ks1", "-80", "t1", time.Millisecond)
+	qm.record("ks2", "-80", "t3", time.Millisecond)
+	assert.Equal(t, map[string]int64{
+		"ks1.-80.all":     1,
+		"other.other.all": 1,
+	}, qm.counts.Counts())
+}
+
+func TestTopValues(t *testing.T) {
+	tv := newTopValues(2)
+	assert.Equal(t, "a", tv.observe("a"))
+	assert.Equal(t, "b", tv.observe("b"))
+	assert.Equal(t, "a", tv.observe("a"))
+
+	// c is reported once it is seen more often than b.
+	assert.Equal(t, "other", tv.observe("c"))
+	assert.Equal(t, "c", tv.observe("c"))
+	assert.Equal(t, "other", tv.observe("b"))
+	assert.Equal(t, "a", tv.observe("a"))
+
+	// Rare values are not reported, and only max of them are remembered.
+	for i := range 100 {
+		assert.Equal(t, "other", tv.observe(fmt.Sprintf("v%d", i)))
+	}
+	assert.Len(t, tv.values, 4)
+	assert.Equal(t, "a", tv.observe("a"))
+	assert.Equal(t, "c", tv.observe("c"))
+
+	tv = newTopValues(0)
+	for i := range 100 {
+		value := fmt.Sprintf("v%d", i)
+		assert.Equal(t, value, tv.observe(value))
+	}
+}
+
+func TestExecutorQueryMetrics(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+	executor.queryMetrics = newTestQueryMetrics(t, []string{"keyspace", "shard", "table"}, nil, 0)
+
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	_, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, "select id from user", nil)
+	require.NoError(t, err)
+	_, err = executorStream(ctx, executor, "select id from music_user_map where id = 1")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{
+		"TestExecutor.-20.`user`":        1,
+		"TestExecutor.multiple.`user`":   1,
+		"TestUnsharded.0.music_user_map": 1,
+	}, executor.queryMetrics.counts.Counts())
+}
@@ -56,6 +56,15 @@ func (e *Executor) defaultQueryLogger() error {
 		}
 	}
 
+	if sinks := streamlog.GetQueryLogSinks(); len(sinks) > 0 {
+		stop, err := queryLogger.LogToSinks(sinks, streamlog.GetFormatter(queryLogger))
+		if err != nil {
+			return err
+		}
+		// Write the buffered query logs to the sinks before exiting.
+		servenv.OnClose(stop)
+	}
+
 	e.queryLogger = queryLogger
 	return nil
 }
@@ -18,13 +18,16 @@ package vtgate
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/datetime"
 
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -345,6 +348,18 @@ func (session *SafeSession) InTransaction() bool {
 	return session.Session.InTransaction
 }
 
+// InReadOnlyTransaction returns true if the session is in a transaction
+// started with START TRANSACTION READ ONLY.
+func (session *SafeSession) InReadOnlyTransaction() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.Session.InTransaction && session.hasTransactionAccessMode(querypb.ExecuteOptions_READ_ONLY)
+}
+
+func (session *SafeSession) hasTransactionAccessMode(mode querypb.ExecuteOptions_TransactionAccessMode) bool {
+	return slices.Contains(session.GetOptions().GetTransactionAccessMode(), mode)
+}
+
 // FindAndChangeSessionIfInSingleTxMode returns the transactionId and tabletAlias, if any, for a session
 // modifies the shard session in a specific case for single mode transaction.
 func (session *SafeSession) FindAndChangeSessionIfInSingleTxMode(keyspace, shard string, tabletType topodatapb.TabletType, txMode vtgatepb.TransactionMode) (int64, int64, *topodatapb.TabletAlias, error) {
@@ -445,6 +460,11 @@ func (session *SafeSession) AppendOrUpdate(shardSession *vtgatepb.Session_ShardS
 			session.mustRollback = true
 			return vterrors.Errorf(vtrpcpb.Code_ABORTED, "multi-db transaction attempted: %v", session.ShardSessions)
 		}
+		// a consistent snapshot is only consistent within a shard.
+		if session.hasTransactionAccessMode(querypb.ExecuteOptions_CONSISTENT_SNAPSHOT) && actualNoOfShardSession(session.ShardSessions) > 1 {
+			session.mustRollback = true
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "transaction started WITH CONSISTENT SNAPSHOT cannot span multiple shards: %v", session.ShardSessions)
+		}
 	case vtgatepb.CommitOrder_PRE:
 		newSessions, err := addOrUpdate(shardSession, session.PreSessions)
 		if err != nil {
@@ -758,6 +778,40 @@ func (session *SafeSession) GetSessionUUID() string {
 	return session.SessionUUID
 }
 
+// GetClientProgram returns the program and version of the MySQL client of the
+// session, from its connection attributes.
+func (session *SafeSession) GetClientProgram() (program, version string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return mysql.ClientProgram(session.ConnectionAttributes)
+}
+
+// getOrCreateSessionUUID returns the SessionUUID value, setting a new one if
+// the session has none, e.g. for the sessions of the gRPC API.
+func (session *SafeSession) getOrCreateSessionUUID() string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.SessionUUID == "" {
+		session.SessionUUID = uuid.NewString()
+	}
+	return session.SessionUUID
+}
+
+// transactionTargets returns the targets of the shard sessions in a transaction.
+func (session *SafeSession) transactionTargets() []*querypb.Target {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	var targets []*querypb.Target
+	for _, shardSessions := range [][]*vtgatepb.Session_ShardSession{session.PreSessions, session.ShardSessions, session.PostSessions} {
+		for _, shardSession := range shardSessions {
+			if shardSession.TransactionId != 0 {
+				targets = append(targets, shardSession.Target)
+			}
+		}
+	}
+	return targets
+}
+
 // SetSessionEnableSystemSettings set the SessionEnableSystemSettings setting.
 func (session *SafeSession) SetSessionEnableSystemSettings(allow bool) {
 	session.mu.Lock()
@@ -818,6 +872,15 @@ func removeShard(tabletAlias *topodatapb.TabletAlias, sessions []*vtgatepb.Sessi
 	return append(sessions[:idx], sessions[idx+1:]...), nil
 }
 
+// PinnedTabletAlias returns the alias of the tablet the session is pinned to,
+// or nil.
+func (session *SafeSession) PinnedTabletAlias() *topodatapb.TabletAlias {
+	if session == nil || session.Session == nil {
+		return nil
+	}
+	return session.Options.GetPinnedTabletAlias()
+}
+
 // GetOrCreateOptions will return the current options struct, or create one and return it if no-one exists
 func (session *SafeSession) GetOrCreateOptions() *querypb.ExecuteOptions {
 	if session.Session.Options == nil {
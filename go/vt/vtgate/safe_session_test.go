@@ -51,6 +51,75 @@ func TestFailToMultiShardWhenSetToSingleDb(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestConsistentSnapshotSingleShard(t *testing.T) {
+	session := NewSafeSession(&vtgatepb.Session{
+		InTransaction: true,
+		Options: &querypb.ExecuteOptions{
+			TransactionAccessMode: []querypb.ExecuteOptions_TransactionAccessMode{querypb.ExecuteOptions_CONSISTENT_SNAPSHOT},
+		},
+	})
+
+	sess0 := &vtgatepb.Session_ShardSession{
+		Target:        &querypb.Target{Keyspace: "keyspace", Shard: "0", TabletType: topodatapb.TabletType_REPLICA},
+		TabletAlias:   &topodatapb.TabletAlias{Cell: "cell", Uid: 0},
+		TransactionId: 1,
+	}
+	sess1 := &vtgatepb.Session_ShardSession{
+		Target:        &querypb.Target{Keyspace: "keyspace", Shard: "1", TabletType: topodatapb.TabletType_REPLICA},
+		TabletAlias:   &topodatapb.TabletAlias{Cell: "cell", Uid: 1},
+		TransactionId: 1,
+	}
+
+	require.NoError(t, session.AppendOrUpdate(sess0, vtgatepb.TransactionMode_MULTI))
+	// the same shard can be used again
+	require.NoError(t, session.AppendOrUpdate(sess0, vtgatepb.TransactionMode_MULTI))
+	err := session.AppendOrUpdate(sess1, vtgatepb.TransactionMode_MULTI)
+	require.ErrorContains(t, err, "transaction started WITH CONSISTENT SNAPSHOT cannot span multiple shards")
+	assert.True(t, session.MustRollback())
+}
+
+func TestRouteToReplica(t *testing.T) {
+	readOnly := &querypb.ExecuteOptions{
+		TransactionAccessMode: []querypb.ExecuteOptions_TransactionAccessMode{querypb.ExecuteOptions_READ_ONLY},
+	}
+	tcs := []struct {
+		name       string
+		session    *vtgatepb.Session
+		tabletType topodatapb.TabletType
+		want       bool
+	}{{
+		name:       "read only transaction",
+		session:    &vtgatepb.Session{InTransaction: true, Options: readOnly, TargetString: "ks"},
+		tabletType: topodatapb.TabletType_PRIMARY,
+		want:       true,
+	}, {
+		name:       "read write transaction",
+		session:    &vtgatepb.Session{InTransaction: true, TargetString: "ks"},
+		tabletType: topodatapb.TabletType_PRIMARY,
+	}, {
+		name:       "no transaction",
+		session:    &vtgatepb.Session{Options: readOnly, TargetString: "ks"},
+		tabletType: topodatapb.TabletType_PRIMARY,
+	}, {
+		name:       "explicit primary",
+		session:    &vtgatepb.Session{InTransaction: true, Options: readOnly, TargetString: "ks@primary"},
+		tabletType: topodatapb.TabletType_PRIMARY,
+	}, {
+		name:       "explicit rdonly",
+		session:    &vtgatepb.Session{InTransaction: true, Options: readOnly, TargetString: "ks@rdonly"},
+		tabletType: topodatapb.TabletType_RDONLY,
+	}, {
+		name:       "reserved connection",
+		session:    &vtgatepb.Session{InTransaction: true, InReservedConn: true, Options: readOnly},
+		tabletType: topodatapb.TabletType_PRIMARY,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, routeToReplica(NewSafeSession(tc.session), tc.tabletType))
+		})
+	}
+}
+
 func TestPrequeries(t *testing.T) {
 	session := NewSafeSession(&vtgatepb.Session{
 		SystemVariables: map[string]string{
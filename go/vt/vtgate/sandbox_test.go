@@ -116,6 +116,9 @@ type sandbox struct {
 
 	// VSchema specifies the vschema in JSON format.
 	VSchema string
+
+	// TwoPCEnabled specifies the TwopcEnabled of the SrvKeyspace.
+	TwoPCEnabled bool
 }
 
 // Reset cleans up sandbox internal state.
@@ -129,6 +132,7 @@ func (s *sandbox) Reset() {
 	s.KeyspaceServedFrom = ""
 	s.ShardSpec = DefaultShardSpec
 	s.SrvKeyspaceCallback = nil
+	s.TwoPCEnabled = false
 }
 
 // DefaultShardSpec is the default sharding scheme for testing.
@@ -245,12 +249,18 @@ func (sct *sandboxTopo) GetSrvKeyspace(ctx context.Context, cell, keyspace strin
 		sand.SrvKeyspaceMustFail--
 		return nil, fmt.Errorf("topo error GetSrvKeyspace")
 	}
+	var srvKeyspace *topodatapb.SrvKeyspace
+	var err error
 	switch keyspace {
 	case KsTestUnsharded:
-		return createUnshardedKeyspace()
+		srvKeyspace, err = createUnshardedKeyspace()
 	default:
-		return createShardedSrvKeyspace(sand.ShardSpec, sand.KeyspaceServedFrom)
+		srvKeyspace, err = createShardedSrvKeyspace(sand.ShardSpec, sand.KeyspaceServedFrom)
+	}
+	if srvKeyspace != nil {
+		srvKeyspace.TwopcEnabled = sand.TwoPCEnabled
 	}
+	return srvKeyspace, err
 }
 
 func (sct *sandboxTopo) WatchSrvKeyspace(ctx context.Context, cell, keyspace string, callback func(*topodatapb.SrvKeyspace, error) bool) {
@@ -27,6 +27,7 @@ import (
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/vt/sqlparser"
 
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -53,6 +54,8 @@ type ScatterConn struct {
 	tabletCallErrorCount *stats.CountersWithMultiLabels
 	txConn               *TxConn
 	gateway              *TabletGateway
+	// deadlockDetector is set when the cross-shard deadlock detection is enabled.
+	deadlockDetector *deadlockDetector
 }
 
 // shardActionFunc defines the contract for a shard action
@@ -160,6 +163,12 @@ func (stc *ScatterConn) ExecuteMultiShard(
 		go stc.runLockQuery(ctx, session)
 	}
 
+	var sessionUUID string
+	if stc.deadlockDetector != nil && session.InTransaction() {
+		sessionUUID = session.getOrCreateSessionUUID()
+		defer stc.deadlockDetector.startStatement(session)()
+	}
+
 	allErrors := stc.multiGoTransaction(
 		ctx,
 		"Execute",
@@ -207,9 +216,18 @@ func (stc *ScatterConn) ExecuteMultiShard(
 				}
 			}
 
+			if sessionUUID != "" && (info.actionNeeded == begin || info.actionNeeded == reserveBegin) {
+				opts = withSessionUUID(opts, sessionUUID)
+			}
+
 			switch info.actionNeeded {
 			case nothing:
-				innerqr, err = qs.Execute(ctx, rs.Target, queries[i].Sql, queries[i].BindVariables, info.transactionID, info.reservedID, opts)
+				execCtx := ctx
+				if retryAutocommitWritesOnFailover && autocommit && len(rss) == 1 && reservedID == 0 && rs.Target.TabletType == topodatapb.TabletType_PRIMARY && isIdempotentWrite(queries[i].Sql) {
+					opts = withIdempotencyToken(opts)
+					execCtx = withIdempotentRetry(ctx)
+				}
+				innerqr, err = qs.Execute(execCtx, rs.Target, queries[i].Sql, queries[i].BindVariables, info.transactionID, info.reservedID, opts)
 				if err != nil {
 					retryRequest(func() {
 						// we seem to have lost our connection. it was a reserved connection, let's try to recreate it
@@ -257,12 +275,24 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			if err != nil {
 				return newInfo, err
 			}
+			// The warnings of the tablet, e.g. about an idle transaction, are
+			// returned to the client by SHOW WARNINGS.
+			for _, warning := range innerqr.Warnings {
+				session.RecordWarning(warning)
+			}
 			mu.Lock()
 			defer mu.Unlock()
 
 			// Don't append more rows if row count is exceeded.
 			if ignoreMaxMemoryRows || len(qr.Rows) <= maxMemoryRows {
+				insertID := qr.InsertID
 				qr.AppendResult(innerqr)
+				// The insert id, i.e. the LAST_INSERT_ID of the session, is the
+				// smallest one of the shards: it is the first id generated by a
+				// multi-shard insert, whatever the order the shards answer in.
+				if insertID != 0 && insertID < qr.InsertID {
+					qr.InsertID = insertID
+				}
 			}
 			return newInfo, nil
 		},
@@ -294,6 +324,10 @@ func checkAndResetShardSession(info *shardActionInfo, err error, session *SafeSe
 			retry = newQS
 		}
 	}
+	if retry == newQS && session.PinnedTabletAlias() != nil {
+		// A pinned session never falls back to another tablet.
+		retry = shard
+	}
 	if retry != none {
 		_ = session.ResetShard(info.alias)
 	}
@@ -309,8 +343,8 @@ func getQueryService(ctx context.Context, rs *srvtopo.ResolvedShard, info *shard
 		return qs, err
 	}
 	// If the session info has only reserved connection and no transaction then we will route it through gateway
-	// Otherwise, we will fail.
-	if info.reservedID == 0 || info.transactionID != 0 {
+	// Otherwise, or if the session is pinned to the tablet, we will fail.
+	if info.reservedID == 0 || info.transactionID != 0 || session.PinnedTabletAlias() != nil {
 		return nil, err
 	}
 	err = session.ResetShard(info.alias)
@@ -605,6 +639,43 @@ func (stc *ScatterConn) multiGo(
 	return allErrors
 }
 
+// withIdempotencyToken returns a copy of the options carrying a new
+// idempotency token, for the primary to apply an autocommit write at most
+// once even if the gateway retries it after a failover.
+func withIdempotencyToken(opts *querypb.ExecuteOptions) *querypb.ExecuteOptions {
+	if opts == nil {
+		opts = &querypb.ExecuteOptions{}
+	} else {
+		opts = opts.CloneVT()
+	}
+	opts.IdempotencyToken = uuid.NewString()
+	return opts
+}
+
+// isIdempotentWrite returns whether the query is a DML the primary applies at
+// most once with an idempotency token. The other statements, such as CALL,
+// LOAD DATA or DDLs, could be applied twice if retried, and the reads need no
+// token.
+func isIdempotentWrite(sql string) bool {
+	switch sqlparser.Preview(sql) {
+	case sqlparser.StmtInsert, sqlparser.StmtReplace, sqlparser.StmtUpdate, sqlparser.StmtDelete:
+		return true
+	}
+	return false
+}
+
+// withSessionUUID returns a copy of the options carrying the session UUID, for
+// the tablet to report it in the lock waits of the transaction.
+func withSessionUUID(opts *querypb.ExecuteOptions, sessionUUID string) *querypb.ExecuteOptions {
+	if opts == nil {
+		opts = &querypb.ExecuteOptions{}
+	} else {
+		opts = opts.CloneVT()
+	}
+	opts.SessionUuid = sessionUUID
+	return opts
+}
+
 // panicData is used to capture panics during parallel execution.
 type panicData struct {
 	p     any
@@ -636,6 +707,9 @@ func (stc *ScatterConn) multiGoTransaction(
 	if numShards == 0 {
 		return allErrors
 	}
+	if session != nil && session.Session != nil {
+		ctx = withTabletTags(ctx, session.GetOptions().GetTabletTags())
+	}
 	oneShard := func(rs *srvtopo.ResolvedShard, i int) {
 		var err error
 		startTime, statsKey := stc.startAction(name, rs.Target)
@@ -645,6 +719,12 @@ func (stc *ScatterConn) multiGoTransaction(
 		if err != nil {
 			return
 		}
+		if shardActionInfo.actionNeeded == begin || shardActionInfo.actionNeeded == reserveBegin {
+			if err = stc.txConn.checkTwoPC(ctx, session, rs.Target); err != nil {
+				session.SetRollback()
+				return
+			}
+		}
 		updated, err := action(rs, i, shardActionInfo)
 		if updated == nil {
 			return
@@ -810,12 +890,14 @@ func requireNewQS(err error, target *querypb.Target) bool {
 
 // actionInfo looks at the current session, and returns information about what needs to be done for this tablet
 func actionInfo(ctx context.Context, target *querypb.Target, session *SafeSession, autocommit bool, txMode vtgatepb.TransactionMode) (*shardActionInfo, error) {
+	// The queries of a session pinned to a tablet are sent to this tablet.
+	pinnedTablet := session.PinnedTabletAlias()
 	if !(session.InTransaction() || session.InReservedConn()) {
-		return &shardActionInfo{}, nil
+		return &shardActionInfo{alias: pinnedTablet}, nil
 	}
 	ignoreSession := ctx.Value(engine.IgnoreReserveTxn)
 	if ignoreSession != nil {
-		return &shardActionInfo{}, nil
+		return &shardActionInfo{alias: pinnedTablet}, nil
 	}
 	// No need to protect ourselves from the race condition between
 	// Find and AppendOrUpdate. The higher level functions ensure that no
@@ -826,6 +908,10 @@ func actionInfo(ctx context.Context, target *querypb.Target, session *SafeSessio
 		return nil, err
 	}
 
+	if alias == nil {
+		alias = pinnedTablet
+	}
+
 	shouldReserve := session.InReservedConn() && reservedID == 0
 	shouldBegin := session.InTransaction() && transactionID == 0 && !autocommit
 
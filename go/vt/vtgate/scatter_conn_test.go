@@ -108,6 +108,124 @@ func TestExecuteFailOnAutocommit(t *testing.T) {
 	utils.MustMatch(t, []*querypb.BoundQuery{queries[1]}, sbc1.Queries, "")
 }
 
+func TestExecuteAutocommitIdempotencyToken(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	keyspace := "TestExecuteAutocommitIdempotencyToken"
+	createSandbox(keyspace)
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+
+	rss := []*srvtopo.ResolvedShard{{
+		Target:  &querypb.Target{Keyspace: keyspace, Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+		Gateway: sbc,
+	}}
+	queries := []*querypb.BoundQuery{{Sql: "update t set a = 1"}}
+	sessionOptions := &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_OLTP}
+	session := NewSafeSession(&vtgatepb.Session{Autocommit: true, Options: sessionOptions})
+
+	defer func(retry bool) {
+		retryAutocommitWritesOnFailover = retry
+	}(retryAutocommitWritesOnFailover)
+
+	// No token is set by default.
+	_, errs := sc.ExecuteMultiShard(ctx, nil, rss, queries, session, true /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	require.Len(t, sbc.Options, 1)
+	assert.Empty(t, sbc.Options[0].GetIdempotencyToken())
+
+	retryAutocommitWritesOnFailover = true
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss, queries, session, true /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss, queries, session, true /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	require.Len(t, sbc.Options, 3)
+	assert.NotEmpty(t, sbc.Options[1].GetIdempotencyToken())
+	assert.NotEqual(t, sbc.Options[1].GetIdempotencyToken(), sbc.Options[2].GetIdempotencyToken())
+	assert.Equal(t, querypb.ExecuteOptions_OLTP, sbc.Options[1].GetWorkload())
+	// The options of the session are not modified.
+	assert.Empty(t, sessionOptions.GetIdempotencyToken())
+
+	// The statements which are not approved for autocommit do not get a token.
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss, queries, session, false /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	require.Len(t, sbc.Options, 4)
+	assert.Empty(t, sbc.Options[3].GetIdempotencyToken())
+
+	// Neither do the statements the primary cannot apply at most once, nor the
+	// reads.
+	for _, sql := range []string{"call p()", "load data infile 'f' into table t", "alter table t add column b int", "select a from t"} {
+		sbc.Options = nil
+		_, errs = sc.ExecuteMultiShard(ctx, nil, rss, []*querypb.BoundQuery{{Sql: sql}}, session, true /*autocommit*/, false)
+		require.NoError(t, vterrors.Aggregate(errs))
+		require.Len(t, sbc.Options, 1)
+		assert.Empty(t, sbc.Options[0].GetIdempotencyToken(), sql)
+	}
+	for _, sql := range []string{"insert into t(a) values (1)", "replace into t(a) values (1)", "/* c */ delete from t"} {
+		sbc.Options = nil
+		_, errs = sc.ExecuteMultiShard(ctx, nil, rss, []*querypb.BoundQuery{{Sql: sql}}, session, true /*autocommit*/, false)
+		require.NoError(t, vterrors.Aggregate(errs))
+		require.Len(t, sbc.Options, 1)
+		assert.NotEmpty(t, sbc.Options[0].GetIdempotencyToken(), sql)
+	}
+}
+
+func TestExecuteRecordsTabletWarnings(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	keyspace := "TestExecuteRecordsTabletWarnings"
+	createSandbox(keyspace)
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+	sbc := hc.AddTestTablet("aa", "0", 1, keyspace, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	warning := &querypb.QueryWarning{Code: uint32(sqlerror.ERIdleTransaction), Message: "transaction 1 was idle for 10s, longer than 5s"}
+	sbc.SetResults([]*sqltypes.Result{{RowsAffected: 1, Warnings: []*querypb.QueryWarning{warning}}})
+
+	rss := []*srvtopo.ResolvedShard{{
+		Target:  &querypb.Target{Keyspace: keyspace, Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+		Gateway: sbc,
+	}}
+	queries := []*querypb.BoundQuery{{Sql: "update t set a = 1"}}
+	session := NewSafeSession(&vtgatepb.Session{})
+	qr, errs := sc.ExecuteMultiShard(ctx, nil, rss, queries, session, false /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	assert.EqualValues(t, 1, qr.RowsAffected)
+	assert.Empty(t, qr.Warnings)
+	utils.MustMatch(t, []*querypb.QueryWarning{warning}, session.Warnings)
+}
+
+func TestExecuteMultiShardInsertID(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	keyspace := "TestExecuteMultiShardInsertID"
+	createSandbox(keyspace)
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+
+	var (
+		rss     []*srvtopo.ResolvedShard
+		queries []*querypb.BoundQuery
+	)
+	for i, insertID := range []uint64{5, 0, 3, 4} {
+		shard := fmt.Sprintf("%d", i)
+		sbc := hc.AddTestTablet("aa", shard, 1, keyspace, shard, topodatapb.TabletType_PRIMARY, true, 1, nil)
+		sbc.SetResults([]*sqltypes.Result{{RowsAffected: 1, InsertID: insertID}})
+		rss = append(rss, &srvtopo.ResolvedShard{
+			Target:  &querypb.Target{Keyspace: keyspace, Shard: shard, TabletType: topodatapb.TabletType_PRIMARY},
+			Gateway: sbc,
+		})
+		queries = append(queries, &querypb.BoundQuery{Sql: "insert into t(a) values (1)"})
+	}
+
+	// The smallest non-zero insert id is kept, whatever the order the shards answer in.
+	session := NewSafeSession(&vtgatepb.Session{})
+	qr, errs := sc.ExecuteMultiShard(ctx, nil, rss, queries, session, false /*autocommit*/, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	assert.EqualValues(t, 4, qr.RowsAffected)
+	assert.EqualValues(t, 3, qr.InsertID)
+}
+
 func TestExecutePanic(t *testing.T) {
 	ctx := utils.LeakCheckContext(t)
 
@@ -18,6 +18,7 @@ package schema
 
 import (
 	"context"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
@@ -251,6 +252,20 @@ func (t *Tracker) GetColumns(ks string, tbl string) []vindexes.Column {
 	return tblInfo.Columns
 }
 
+// LastSynced returns when the tracked schema of the keyspace was last known to
+// be up to date: when it was loaded, when its changes were applied, or when
+// a primary of the keyspace last reported no schema change. It returns false
+// if the schema of the keyspace is not loaded or has changes being applied.
+func (t *Tracker) LastSynced(ks string) (time.Time, bool) {
+	t.mu.Lock()
+	uc := t.tracked[ks]
+	t.mu.Unlock()
+	if uc == nil {
+		return time.Time{}, false
+	}
+	return uc.lastSynced()
+}
+
 // GetForeignKeys returns the foreign keys for table in the given keyspace.
 func (t *Tracker) GetForeignKeys(ks string, tbl string) []*sqlparser.ForeignKeyDefinition {
 	t.mu.Lock()
@@ -366,7 +381,14 @@ func (t *Tracker) updateTables(keyspace string, res map[string]string) {
 
 		cols := getColumns(ddl.TableSpec)
 		fks := getForeignKeys(ddl.TableSpec)
-		t.tables.set(keyspace, tableName, cols, fks, ddl.TableSpec.Indexes)
+		partitions := getPartitions(ddl.TableSpec)
+		t.tables.set(keyspace, tableName, &vindexes.TableInfo{
+			Columns:           cols,
+			ForeignKeys:       fks,
+			Indexes:           ddl.TableSpec.Indexes,
+			Partitions:        partitions,
+			ColumnDefinitions: ddl.TableSpec.Columns,
+		})
 	}
 }
 
@@ -409,6 +431,47 @@ func getForeignKeys(tblSpec *sqlparser.TableSpec) []*sqlparser.ForeignKeyDefinit
 	return fks
 }
 
+// getPartitions returns the names of the partitions and subpartitions of a partitioned table, which MySQL
+// generates as p0, p1, ... and p0sp0, p0sp1, ... when the table does not name them.
+func getPartitions(tblSpec *sqlparser.TableSpec) []sqlparser.IdentifierCI {
+	partitionOption := tblSpec.PartitionOption
+	if partitionOption == nil {
+		return nil
+	}
+	var partitions []sqlparser.IdentifierCI
+	subPartitions := 0
+	if partitionOption.SubPartition != nil {
+		subPartitions = max(partitionOption.SubPartition.SubPartitions, 1)
+	}
+	addSubPartitions := func(partition string, definitions sqlparser.SubPartitionDefinitions) {
+		for _, definition := range definitions {
+			partitions = append(partitions, definition.Name)
+		}
+		if len(definitions) > 0 {
+			return
+		}
+		for i := 0; i < subPartitions; i++ {
+			partitions = append(partitions, sqlparser.NewIdentifierCI(fmt.Sprintf("%ssp%d", partition, i)))
+		}
+	}
+	for _, definition := range partitionOption.Definitions {
+		partitions = append(partitions, definition.Name)
+		var subDefinitions sqlparser.SubPartitionDefinitions
+		if definition.Options != nil {
+			subDefinitions = definition.Options.SubPartitionDefinitions
+		}
+		addSubPartitions(definition.Name.String(), subDefinitions)
+	}
+	if len(partitionOption.Definitions) == 0 {
+		for i := 0; i < max(partitionOption.Partitions, 1); i++ {
+			partition := fmt.Sprintf("p%d", i)
+			partitions = append(partitions, sqlparser.NewIdentifierCI(partition))
+			addSubPartitions(partition, nil)
+		}
+	}
+	return partitions
+}
+
 func getTableCollation(tblSpec *sqlparser.TableSpec) string {
 	if tblSpec.Options == nil {
 		return ""
@@ -490,13 +553,13 @@ type tableMap struct {
 	m map[keyspaceStr]map[tableNameStr]*vindexes.TableInfo
 }
 
-func (tm *tableMap) set(ks, tbl string, cols []vindexes.Column, fks []*sqlparser.ForeignKeyDefinition, indexes []*sqlparser.IndexDefinition) {
+func (tm *tableMap) set(ks, tbl string, info *vindexes.TableInfo) {
 	m := tm.m[ks]
 	if m == nil {
 		m = make(map[tableNameStr]*vindexes.TableInfo)
 		tm.m[ks] = m
 	}
-	m[tbl] = &vindexes.TableInfo{Columns: cols, ForeignKeys: fks, Indexes: indexes}
+	m[tbl] = info
 }
 
 func (tm *tableMap) get(ks, tbl string) *vindexes.TableInfo {
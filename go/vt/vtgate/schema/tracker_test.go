@@ -170,6 +170,36 @@ func TestTrackerGetKeyspaceUpdateController(t *testing.T) {
 	assert.Nil(t, ks3.reloadKeyspace, "ks3 already initialized")
 }
 
+func TestTrackerLastSynced(t *testing.T) {
+	uc := &updateController{}
+	tracker := Tracker{
+		tracked: map[keyspaceStr]*updateController{
+			"ks": uc,
+		},
+	}
+
+	_, ok := tracker.LastSynced("unknown")
+	assert.False(t, ok, "unknown keyspace")
+	_, ok = tracker.LastSynced("ks")
+	assert.False(t, ok, "keyspace not loaded")
+
+	before := time.Now()
+	uc.setLoaded(true)
+	synced, ok := tracker.LastSynced("ks")
+	require.True(t, ok)
+	assert.False(t, synced.Before(before))
+
+	// Changes being applied make the schema stale.
+	uc.queue = &queue{}
+	_, ok = tracker.LastSynced("ks")
+	assert.False(t, ok, "changes being applied")
+
+	uc.queue = nil
+	uc.setLoaded(false)
+	_, ok = tracker.LastSynced("ks")
+	assert.False(t, ok, "keyspace unloaded")
+}
+
 type myTable struct {
 	name, create string
 }
@@ -403,6 +433,38 @@ func TestIndexInfoRetrieval(t *testing.T) {
 	testTracker(t, false, schemaDefResult, testcases)
 }
 
+func TestGetPartitions(t *testing.T) {
+	testcases := []struct {
+		create     string
+		partitions []string
+	}{{
+		create: "create table t (id bigint, primary key (id))",
+	}, {
+		create:     "create table t (id bigint, primary key (id)) partition by range (id) (partition p_old values less than (100), partition p_new values less than maxvalue)",
+		partitions: []string{"p_old", "p_new"},
+	}, {
+		create:     "create table t (id bigint, primary key (id)) partition by hash (id) partitions 3",
+		partitions: []string{"p0", "p1", "p2"},
+	}, {
+		create:     "create table t (id bigint, ts int, primary key (id, ts)) partition by range (ts) subpartition by hash (id) subpartitions 2 (partition p0 values less than (100), partition p1 values less than maxvalue)",
+		partitions: []string{"p0", "p0sp0", "p0sp1", "p1", "p1sp0", "p1sp1"},
+	}, {
+		create:     "create table t (id bigint, ts int, primary key (id, ts)) partition by range (ts) subpartition by hash (id) (partition p0 values less than (100) (subpartition s0, subpartition s1))",
+		partitions: []string{"p0", "s0", "s1"},
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.create, func(t *testing.T) {
+			stmt, err := sqlparser.NewTestParser().Parse(tc.create)
+			require.NoError(t, err)
+			var partitions []string
+			for _, partition := range getPartitions(stmt.(*sqlparser.CreateTable).TableSpec) {
+				partitions = append(partitions, partition.String())
+			}
+			require.Equal(t, tc.partitions, partitions)
+		})
+	}
+}
+
 func empty() sandboxconn.SchemaResult {
 	return sandboxconn.SchemaResult{TablesAndViews: map[string]string{}}
 }
@@ -40,6 +40,9 @@ type (
 		reloadKeyspace func(th *discovery.TabletHealth) error
 		signal         func()
 		loaded         bool
+		// synced is when the schema of the keyspace was last known to be up
+		// to date.
+		synced time.Time
 
 		// we'll only log a failed keyspace loading once
 		ignore bool
@@ -53,6 +56,9 @@ func (u *updateController) consume() {
 		u.mu.Lock()
 		if len(u.queue.items) == 0 {
 			u.queue = nil
+			if u.loaded {
+				u.synced = time.Now()
+			}
 			u.mu.Unlock()
 			return
 		}
@@ -149,6 +155,9 @@ func (u *updateController) add(th *discovery.TabletHealth) {
 
 	// If the keyspace schema is loaded and there is no schema change detected. Then there is nothing to process.
 	if len(th.Stats.TableSchemaChanged) == 0 && len(th.Stats.ViewSchemaChanged) == 0 && !th.Stats.UdfsChanged && u.loaded {
+		if u.queue == nil {
+			u.synced = time.Now()
+		}
 		return
 	}
 
@@ -174,6 +183,20 @@ func (u *updateController) setLoaded(loaded bool) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 	u.loaded = loaded
+	if loaded {
+		u.synced = time.Now()
+	}
+}
+
+// lastSynced returns when the schema of the keyspace was last known to be up
+// to date, or false if it is not loaded or has changes being applied.
+func (u *updateController) lastSynced() (time.Time, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !u.loaded || u.queue != nil || u.synced.IsZero() {
+		return time.Time{}, false
+	}
+	return u.synced, true
 }
 
 func (u *updateController) setIgnore(i bool) {
@@ -678,6 +678,43 @@ func TestVindexHints(t *testing.T) {
 	}
 }
 
+func TestPartitionSelection(t *testing.T) {
+	// tests that the selected partitions exist when the partitions of the table are tracked
+	tcases := []struct {
+		sql         string
+		expectedErr string
+	}{{
+		sql:         "select col from t1 partition (p0, p9)",
+		expectedErr: "Unknown partition 'p9' in table 't1'",
+	}, {
+		sql: "select id from t1 partition (p0, P1)",
+	}, {
+		sql: "select id from t1 partition (p1sp0)",
+	}, {
+		// the partitions of t are not tracked
+		sql: "select id from t partition (p9)",
+	}}
+	for _, tc := range tcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			parse, err := sqlparser.NewTestParser().Parse(tc.sql)
+			require.NoError(t, err)
+
+			si := fakeSchemaInfo()
+			si.Tables["t1"].Partitions = sqlparser.Partitions{
+				sqlparser.NewIdentifierCI("p0"),
+				sqlparser.NewIdentifierCI("p1"),
+				sqlparser.NewIdentifierCI("p1sp0"),
+			}
+			_, err = AnalyzeStrict(parse, "d", si)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
 func TestGroupByBinding(t *testing.T) {
 	tcases := []struct {
 		sql  string
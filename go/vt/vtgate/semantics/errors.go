@@ -60,6 +60,11 @@ type (
 		VindexName string
 	}
 
+	NoSuchPartitionFound struct {
+		Table     string
+		Partition string
+	}
+
 	UnsupportedMultiTablesInUpdateError struct {
 		ExprCount int
 		NotAlias  bool
@@ -298,6 +303,15 @@ func (c *NoSuchVindexFound) ErrorCode() vtrpcpb.Code {
 	return vtrpcpb.Code_FAILED_PRECONDITION
 }
 
+// NoSuchPartitionFound
+func (c *NoSuchPartitionFound) Error() string {
+	return fmt.Sprintf("Unknown partition '%s' in table '%s'", c.Partition, c.Table)
+}
+
+func (c *NoSuchPartitionFound) ErrorCode() vtrpcpb.Code {
+	return vtrpcpb.Code_INVALID_ARGUMENT
+}
+
 // InvalidUseOfGroupFunction
 func (*InvalidUseOfGroupFunction) Error() string {
 	return "Invalid use of group function"
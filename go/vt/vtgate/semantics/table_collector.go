@@ -451,6 +451,9 @@ func createTable(
 	if err := checkValidVindexHints(hint, tbl); err != nil {
 		return nil, err
 	}
+	if err := checkValidPartitions(alias.Partitions, tbl); err != nil {
+		return nil, err
+	}
 
 	table := &RealTable{
 		tableName:    alias.As.String(),
@@ -500,6 +503,27 @@ outer:
 	return nil
 }
 
+// checkValidPartitions checks the partitions selected from the table exist, when the partitions of the
+// table are known from the schema tracking.
+func checkValidPartitions(partitions sqlparser.Partitions, tbl *vindexes.Table) error {
+	if tbl == nil || len(tbl.Partitions) == 0 {
+		return nil
+	}
+outer:
+	for _, partition := range partitions {
+		for _, tblPartition := range tbl.Partitions {
+			if partition.Equal(tblPartition) {
+				continue outer
+			}
+		}
+		return &NoSuchPartitionFound{
+			Table:     tbl.Name.String(),
+			Partition: partition.String(),
+		}
+	}
+	return nil
+}
+
 // getVindexHint gets the vindex hint from the list of IndexHints.
 func getVindexHint(hints sqlparser.IndexHints) *sqlparser.IndexHint {
 	for _, hint := range hints {
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"encoding/base64"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ExportSession encodes the state of the session which can be imported by
+// another vtgate, so that a proxy can move a client connection from a vtgate
+// to another one, e.g. while the first one is drained: its target, options,
+// transaction mode, system and user defined variables, prepared statements,
+// last insert id, found rows and row count, and the savepoints of a
+// transaction which did not start on any tablet yet.
+//
+// The connections of the session to the tablets are not exported: the
+// reserved connections are reserved again by the other vtgate with the
+// system variables of the session, but the state only held by them, such as
+// temporary tables, is lost. A session cannot be exported while it has a
+// transaction open on a tablet or holds advisory locks.
+func ExportSession(session *vtgatepb.Session) (string, error) {
+	exported, err := exportedSession(session)
+	if err != nil {
+		return "", err
+	}
+	return encodeSession(exported)
+}
+
+func exportedSession(session *vtgatepb.Session) (*vtgatepb.Session, error) {
+	for _, shardSessions := range [][]*vtgatepb.Session_ShardSession{session.PreSessions, session.ShardSessions, session.PostSessions} {
+		for _, shardSession := range shardSessions {
+			if shardSession.TransactionId != 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot export a session with a transaction open on the tablets")
+			}
+		}
+	}
+	if session.LockSession != nil || len(session.AdvisoryLock) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot export a session holding advisory locks")
+	}
+
+	exported := proto.Clone(session).(*vtgatepb.Session)
+	exported.PreSessions = nil
+	exported.ShardSessions = nil
+	exported.PostSessions = nil
+	exported.Warnings = nil
+	exported.LastLockHeartbeat = 0
+	exported.ConnectionAttributes = nil
+	return exported, nil
+}
+
+func encodeSession(session *vtgatepb.Session) (string, error) {
+	data, err := session.MarshalVT()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// ImportSession decodes a session encoded by ExportSession.
+func ImportSession(encoded string) (*vtgatepb.Session, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid exported session: %v", err)
+	}
+	session := &vtgatepb.Session{}
+	if err := session.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid exported session: %v", err)
+	}
+	// The connections of a session cannot be taken over by another one.
+	if len(session.PreSessions) > 0 || len(session.ShardSessions) > 0 || len(session.PostSessions) > 0 || session.LockSession != nil || len(session.AdvisoryLock) > 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid exported session: it holds connections to the tablets")
+	}
+	return session, nil
+}
+
+// Export encodes the state of the session with ExportSession.
+func (session *SafeSession) Export() (string, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return ExportSession(session.Session)
+}
+
+// Import replaces the state of the session with the one of an exported
+// session, but its connection attributes, which describe the connection of
+// its client. The session must not have any connection to the tablets, which
+// would be leaked.
+func (session *SafeSession) Import(encoded string) error {
+	imported, err := ImportSession(encoded)
+	if err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if len(session.PreSessions) > 0 || len(session.ShardSessions) > 0 || len(session.PostSessions) > 0 || session.LockSession != nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot import a session into a session with a transaction or reserved connections")
+	}
+	attributes := session.ConnectionAttributes
+	proto.Reset(session.Session)
+	proto.Merge(session.Session, imported)
+	session.ConnectionAttributes = attributes
+	return nil
+}
+
+// showVitessSession returns the session exported by ExportSession, and its
+// state in JSON.
+func showVitessSession(session *SafeSession) (*sqltypes.Result, error) {
+	session.mu.Lock()
+	exported, err := exportedSession(session.Session)
+	session.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encodeSession(exported)
+	if err != nil {
+		return nil, err
+	}
+	state, err := json2.MarshalPB(exported)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{
+		Fields: buildVarCharFields("Session", "State"),
+		Rows:   [][]sqltypes.Value{buildVarCharRow(encoded, string(state))},
+	}, nil
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statementacl restricts the classes of statements vtgate executes per
+// caller, according to a StatementACL stored in the global topo.
+package statementacl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Statement classes that can be denied by a StatementACLRule.
+const (
+	ClassDDL  = "ddl"
+	ClassDML  = "dml"
+	ClassSet  = "set"
+	ClassShow = "show"
+)
+
+// allUsers is the special user name matching every caller.
+const allUsers = "%"
+
+var (
+	enabled bool
+	// retryDelay is how long to wait before watching the StatementACL again
+	// after the watch failed, or found no StatementACL; it doubles after each
+	// consecutive failure, up to maxRetryDelay.
+	retryDelay    = time.Second
+	maxRetryDelay = time.Minute
+
+	current atomic.Pointer[policy]
+
+	denials = stats.NewCountersWithMultiLabels("StatementACLDenials", "Number of statements denied by the statement ACL", []string{"User", "Class"})
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&enabled, "enable-statement-acl", enabled, "If set, restrict the statements callers may execute according to the StatementACL stored in the global topo, reloading it on change.")
+}
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vtgate"} {
+		servenv.OnParseFor(cmd, registerFlags)
+	}
+}
+
+type rule struct {
+	allUsers bool
+	users    map[string]struct{}
+	groups   map[string]struct{}
+	deny     map[string]struct{}
+	denyShow map[string]struct{}
+}
+
+func (r *rule) matches(caller *querypb.VTGateCallerID) bool {
+	if r.allUsers {
+		return true
+	}
+	if _, ok := r.users[caller.GetUsername()]; ok {
+		return true
+	}
+	for _, group := range caller.GetGroups() {
+		if _, ok := r.groups[group]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type policy struct {
+	rules []*rule
+	// pending is set until the first StatementACL is loaded, to deny every
+	// statement the ACL could deny.
+	pending bool
+}
+
+func toSet(values []string, normalize func(string) string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[normalize(v)] = struct{}{}
+	}
+	return set
+}
+
+func identity(s string) string { return s }
+
+// Validate returns an error if the ACL denies unknown statement classes.
+func Validate(acl *vtgatepb.StatementACL) error {
+	for i, r := range acl.GetRules() {
+		for _, class := range r.Deny {
+			switch strings.ToLower(class) {
+			case ClassDDL, ClassDML, ClassSet, ClassShow:
+			default:
+				return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "rule %d: unknown statement class %q", i, class)
+			}
+		}
+		if len(r.Users) == 0 && len(r.Groups) == 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "rule %d: must match at least one user or group", i)
+		}
+	}
+	return nil
+}
+
+// Set replaces the statement ACL enforced by Authorized. A nil or empty ACL
+// allows every statement.
+func Set(acl *vtgatepb.StatementACL) error {
+	if err := Validate(acl); err != nil {
+		return err
+	}
+
+	p := &policy{}
+	for _, r := range acl.GetRules() {
+		compiled := &rule{
+			users:    toSet(r.Users, identity),
+			groups:   toSet(r.Groups, identity),
+			deny:     toSet(r.Deny, strings.ToLower),
+			denyShow: toSet(r.DenyShow, normalizeShowTarget),
+		}
+		_, compiled.allUsers = compiled.users[allUsers]
+		p.rules = append(p.rules, compiled)
+	}
+	current.Store(p)
+	return nil
+}
+
+// Init starts watching the StatementACL in the global topo, if the statement
+// ACL is enabled. It returns immediately; the watch stops when ctx is done.
+func Init(ctx context.Context, ts *topo.Server) {
+	if !enabled {
+		return
+	}
+
+	// Deny every statement the ACL could deny until the first ACL is loaded,
+	// so that an unreachable global topo does not lift the restrictions.
+	current.Store(&policy{pending: true})
+	go watch(ctx, ts, retryDelay, maxRetryDelay)
+}
+
+func watch(ctx context.Context, ts *topo.Server, minRetryDelay, maxRetryDelay time.Duration) {
+	delay := minRetryDelay
+	for {
+		initial, changes, err := ts.WatchStatementACL(ctx)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			load(nil)
+		case err != nil:
+			log.Warningf("Error watching StatementACL, keeping the current one: %v", err)
+		default:
+			delay = minRetryDelay
+			load(initial.Value)
+			for c := range changes {
+				if c.Err != nil {
+					if topo.IsErrType(c.Err, topo.NoNode) {
+						load(nil)
+					} else if !topo.IsErrType(c.Err, topo.Interrupted) {
+						log.Warningf("Error watching StatementACL, keeping the current one: %v", c.Err)
+					}
+					break
+				}
+				load(c.Value)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+func load(acl *vtgatepb.StatementACL) {
+	if err := Set(acl); err != nil {
+		log.Errorf("Ignoring invalid StatementACL, keeping the current one: %v", err)
+		return
+	}
+	log.Infof("Loaded StatementACL with %d rules", len(acl.GetRules()))
+}
+
+// Classify returns the class of the statement, or the empty string if the
+// statement does not belong to a class the ACL can deny. For SHOW statements,
+// it also returns the SHOW target.
+func Classify(stmt sqlparser.Statement) (class string, showTarget string) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Load:
+		return ClassDML, ""
+	case *sqlparser.AlterVschema, *sqlparser.Flush:
+		// ALTER VSCHEMA and FLUSH change the schema or server state like DDLs do.
+		return ClassDDL, ""
+	case *sqlparser.Show:
+		switch show := stmt.Internal.(type) {
+		case *sqlparser.ShowBasic:
+			showTarget = show.Command.ToString()
+		case *sqlparser.ShowCreate:
+			showTarget = show.Command.ToString()
+		case *sqlparser.ShowOther:
+			showTarget = show.Command
+		}
+		return ClassShow, normalizeShowTarget(showTarget)
+	}
+
+	switch sqlparser.ASTToStatementType(stmt) {
+	case sqlparser.StmtDDL, sqlparser.StmtRevert, sqlparser.StmtFlush:
+		return ClassDDL, ""
+	case sqlparser.StmtInsert, sqlparser.StmtReplace, sqlparser.StmtUpdate, sqlparser.StmtDelete:
+		return ClassDML, ""
+	case sqlparser.StmtSet:
+		return ClassSet, ""
+	case sqlparser.StmtShowMigrationLogs:
+		return ClassShow, "vitess_migration_logs"
+	}
+	return "", ""
+}
+
+func normalizeShowTarget(target string) string {
+	return strings.Join(strings.Fields(strings.ToLower(target)), " ")
+}
+
+// Authorized returns a PERMISSION_DENIED error if the statement ACL denies the
+// statement to the caller, and an UNAVAILABLE error for every statement the
+// ACL could deny while it is not loaded yet. Denials are logged for auditing.
+func Authorized(caller *querypb.VTGateCallerID, stmt sqlparser.Statement) error {
+	p := current.Load()
+	if p == nil || (len(p.rules) == 0 && !p.pending) {
+		return nil
+	}
+
+	class, showTarget := Classify(stmt)
+	if class == "" {
+		return nil
+	}
+	if p.pending {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "statement ACL is not loaded yet, denying %s statements", class)
+	}
+
+	for _, r := range p.rules {
+		if !r.matches(caller) {
+			continue
+		}
+		_, denied := r.deny[class]
+		if !denied && class == ClassShow {
+			_, denied = r.denyShow[showTarget]
+		}
+		if !denied {
+			continue
+		}
+
+		user := caller.GetUsername()
+		denials.Add([]string{user, class}, 1)
+
+		what := class
+		if class == ClassShow && showTarget != "" {
+			what = fmt.Sprintf("%s %s", class, showTarget)
+		}
+		log.Warningf("StatementACL: denied %s statement to user %q (groups %v)", what, user, caller.GetGroups())
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "statement ACL denies %s statements to user %s", what, user)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statementacl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		sql        string
+		class      string
+		showTarget string
+	}{
+		{sql: "select 1 from t", class: ""},
+		{sql: "insert into t values (1)", class: ClassDML},
+		{sql: "replace into t values (1)", class: ClassDML},
+		{sql: "update t set a = 1", class: ClassDML},
+		{sql: "delete from t", class: ClassDML},
+		{sql: "create table t (id int)", class: ClassDDL},
+		{sql: "alter vschema add table t", class: ClassDDL},
+		{sql: "alter vschema on t add vindex hash(id)", class: ClassDDL},
+		{sql: "flush tables", class: ClassDDL},
+		{sql: "flush local binary logs", class: ClassDDL},
+		{sql: "set @@autocommit = 1", class: ClassSet},
+		{sql: "show vitess_tablets", class: ClassShow, showTarget: "vitess_tablets"},
+		{sql: "show global variables", class: ClassShow, showTarget: "global variables"},
+		{sql: "show create table t", class: ClassShow, showTarget: "create table"},
+		{sql: "begin", class: ""},
+	}
+
+	parser := sqlparser.NewTestParser()
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := parser.Parse(tt.sql)
+			require.NoError(t, err)
+
+			class, showTarget := Classify(stmt)
+			assert.Equal(t, tt.class, class)
+			assert.Equal(t, tt.showTarget, showTarget)
+		})
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	defer current.Store(nil)
+
+	require.NoError(t, Set(&vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{
+			{Users: []string{"app"}, Deny: []string{"DDL", "set"}},
+			{Groups: []string{"analysts"}, Deny: []string{"dml"}, DenyShow: []string{"vitess_tablets"}},
+		},
+	}))
+
+	parser := sqlparser.NewTestParser()
+	check := func(caller *querypb.VTGateCallerID, sql string) error {
+		stmt, err := parser.Parse(sql)
+		require.NoError(t, err)
+		return Authorized(caller, stmt)
+	}
+
+	app := &querypb.VTGateCallerID{Username: "app"}
+	analyst := &querypb.VTGateCallerID{Username: "alice", Groups: []string{"analysts"}}
+	admin := &querypb.VTGateCallerID{Username: "admin"}
+
+	err := check(app, "create table t (id int)")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	assert.ErrorContains(t, check(app, "set @@autocommit = 0"), "denies set statements to user app")
+	assert.NoError(t, check(app, "insert into t values (1)"))
+
+	assert.Error(t, check(analyst, "delete from t"))
+	assert.ErrorContains(t, check(analyst, "show vitess_tablets"), "denies show vitess_tablets statements")
+	assert.NoError(t, check(analyst, "show vitess_shards"))
+	assert.NoError(t, check(analyst, "select * from t"))
+
+	assert.NoError(t, check(admin, "create table t (id int)"))
+	assert.NoError(t, check(nil, "create table t (id int)"))
+
+	require.NoError(t, Set(&vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{
+			{Users: []string{"%"}, Deny: []string{"ddl"}},
+		},
+	}))
+	assert.Error(t, check(admin, "drop table t"))
+	assert.Error(t, check(nil, "drop table t"))
+
+	require.NoError(t, Set(nil))
+	assert.NoError(t, check(admin, "drop table t"))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(&vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{{Users: []string{"u"}, Deny: []string{"ddl", "DML", "set", "show"}}},
+	}))
+	assert.ErrorContains(t, Validate(&vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{{Users: []string{"u"}, Deny: []string{"select"}}},
+	}), `unknown statement class "select"`)
+	assert.ErrorContains(t, Validate(&vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{{Deny: []string{"ddl"}}},
+	}), "must match at least one user or group")
+}
+
+func TestWatch(t *testing.T) {
+	defer current.Store(nil)
+
+	oldEnabled, oldRetryDelay, oldMaxRetryDelay := enabled, retryDelay, maxRetryDelay
+	defer func() { enabled, retryDelay, maxRetryDelay = oldEnabled, oldRetryDelay, oldMaxRetryDelay }()
+	enabled, retryDelay, maxRetryDelay = true, 10*time.Millisecond, 20*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	Init(ctx, ts)
+
+	stmt, err := sqlparser.NewTestParser().Parse("drop table t")
+	require.NoError(t, err)
+	caller := &querypb.VTGateCallerID{Username: "app"}
+	// Nothing is denied once vtgate finds there is no StatementACL.
+	assert.Eventually(t, func() bool {
+		return Authorized(caller, stmt) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ts.SaveStatementACL(ctx, &vtgatepb.StatementACL{
+		Rules: []*vtgatepb.StatementACLRule{{Users: []string{"app"}, Deny: []string{"ddl"}}},
+	}))
+	assert.Eventually(t, func() bool {
+		return Authorized(caller, stmt) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ts.SaveStatementACL(ctx, &vtgatepb.StatementACL{}))
+	assert.Eventually(t, func() bool {
+		return Authorized(caller, stmt) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAuthorizedPending(t *testing.T) {
+	defer current.Store(nil)
+
+	// Until the first StatementACL is loaded, every class of statements is
+	// denied.
+	current.Store(&policy{pending: true})
+
+	parser := sqlparser.NewTestParser()
+	caller := &querypb.VTGateCallerID{Username: "app"}
+	for _, sql := range []string{"drop table t", "insert into t values (1)", "set @@autocommit = 1", "show vitess_tablets"} {
+		stmt, err := parser.Parse(sql)
+		require.NoError(t, err)
+		err = Authorized(caller, stmt)
+		assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err), sql)
+	}
+
+	stmt, err := parser.Parse("select 1 from t")
+	require.NoError(t, err)
+	assert.NoError(t, Authorized(caller, stmt))
+
+	require.NoError(t, Set(nil))
+	stmt, err = parser.Parse("drop table t")
+	require.NoError(t, err)
+	assert.NoError(t, Authorized(caller, stmt))
+}
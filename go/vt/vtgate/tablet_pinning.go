@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"slices"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	// tabletPinningAuthorizedUsers are the users who may pin their session to
+	// a tablet, e.g. with USE `ks:-80@replica|zone1-0000000101`, or "%" for
+	// all the users.
+	tabletPinningAuthorizedUsers []string
+
+	pinnedQueries = stats.NewCountersWithSingleLabel(
+		"VtgatePinnedQueries",
+		"Queries of the sessions pinned to a tablet, per tablet alias",
+		"TabletAlias")
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vtgate"} {
+		servenv.OnParseFor(cmd, func(fs *pflag.FlagSet) {
+			fs.StringSliceVar(&tabletPinningAuthorizedUsers, "tablet-pinning-authorized-users", tabletPinningAuthorizedUsers, "List of users authorized to pin their session to a tablet with a target of the form keyspace:shard@tablet_type|tablet_alias, or '%' to allow all users. Tablet pinning is meant to debug a tablet, and is denied to all users by default.")
+		})
+	}
+}
+
+// authorizeTabletPinning returns an error unless the caller may pin its
+// session to the tablet.
+func authorizeTabletPinning(caller *querypb.VTGateCallerID, tabletAlias *topodatapb.TabletAlias) error {
+	if slices.Contains(tabletPinningAuthorizedUsers, "%") || slices.Contains(tabletPinningAuthorizedUsers, caller.GetUsername()) {
+		return nil
+	}
+	return vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "User '%s' is not authorized to pin the session to tablet %s", caller.GetUsername(), topoproto.TabletAliasString(tabletAlias))
+}
@@ -19,9 +19,12 @@ package vtgate
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math/rand/v2"
 	"runtime/debug"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,6 +32,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
@@ -42,6 +46,7 @@ import (
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -50,9 +55,26 @@ var (
 	// CellsToWatch is the list of cells the healthcheck operates over. If it is empty, only the local cell is watched
 	CellsToWatch string
 
+	// regionFallbackOrder is the list of regions, in order of preference, that are used to
+	// pick a tablet when none are available in the local cell or the local cell's region.
+	regionFallbackOrder []string
+
+	// cellRegionsRetryDelay is how long to wait before watching the CellInfo
+	// of a cell again after the watch failed. The delay doubles, up to
+	// cellRegionsMaxRetryDelay, while the watch keeps failing.
+	cellRegionsRetryDelay    = time.Second
+	cellRegionsMaxRetryDelay = time.Minute
+
 	initialTabletTimeout = 30 * time.Second
 	// retryCount is the number of times a query will be retried on error
 	retryCount = 2
+	// maxStalenessPrimaryFallback makes the queries which no tablet of a
+	// non-PRIMARY target is fresh enough for, fall back to the primary.
+	maxStalenessPrimaryFallback = false
+	// retryAutocommitWritesOnFailover makes the single-shard autocommit
+	// writes carry an idempotency token, and retries them once against the
+	// new primary when buffering fails during a failover.
+	retryAutocommitWritesOnFailover = false
 
 	logCollations = logutil.NewThrottledLogger("CollationInconsistent", 1*time.Minute)
 )
@@ -62,6 +84,17 @@ func init() {
 		fs.StringVar(&CellsToWatch, "cells_to_watch", "", "comma-separated list of cells for watching tablets")
 		fs.DurationVar(&initialTabletTimeout, "gateway_initial_tablet_timeout", 30*time.Second, "At startup, the tabletGateway will wait up to this duration to get at least one tablet per keyspace/shard/tablet type")
 		fs.IntVar(&retryCount, "retry-count", 2, "retry count")
+		fs.BoolVar(&maxStalenessPrimaryFallback, "max-staleness-primary-fallback", maxStalenessPrimaryFallback, "If set, the queries sent to REPLICA or RDONLY tablets which are rejected by all the tablets of the shard for lagging more than their MAX_STALENESS_MS directive are sent to the PRIMARY instead.")
+		fs.BoolVar(&retryAutocommitWritesOnFailover, "retry-autocommit-writes-on-failover", retryAutocommitWritesOnFailover, "If set, the single-shard autocommit writes carry an idempotency token, which the primary records with the write, and are retried once against the new primary when they fail during a failover that buffering could not absorb. The write is not applied again if the token shows it was already applied. Requires vttablets which support idempotency tokens.")
+		fs.IntVar(&tabletBreakerThreshold, "tablet-circuit-breaker-threshold", tabletBreakerThreshold, "Number of consecutive errors or timeouts of a REPLICA or RDONLY tablet which trip its circuit breaker. The queries then skip the tablet, except for a probe every --tablet-circuit-breaker-probe-interval, until a probe succeeds. 0 disables the circuit breakers.")
+		fs.DurationVar(&tabletBreakerProbeInterval, "tablet-circuit-breaker-probe-interval", tabletBreakerProbeInterval, "How long a tripped tablet circuit breaker keeps its tablet out of the rotation before sending it a probe query.")
+		fs.DurationVar(&tabletSlowStartWindow, "tablet-slow-start-window", tabletSlowStartWindow, "How long the REPLICA and RDONLY tablets which become healthy, such as restarted replicas, take to ramp up linearly to their full share of the queries, which go to the other tablets of their target in the meantime. 0 disables the slow start.")
+		fs.Float64Var(&loadSheddingThreshold, "load-shedding-threshold", loadSheddingThreshold, "Fraction of the requests to the tablets failing with RESOURCE_EXHAUSTED errors, such as connection pool timeouts or throttled transactions, during a --load-shedding-interval above which vtgate sheds more of the statements of the lowest priorities, which fail with a RESOURCE_EXHAUSTED error telling when to retry. 0 disables the load shedding.")
+		fs.DurationVar(&loadSheddingInterval, "load-shedding-interval", loadSheddingInterval, "How often the load shedding adjusts to the saturation of the tablets.")
+		fs.IntVar(&loadSheddingMinRequests, "load-shedding-min-requests", loadSheddingMinRequests, "Number of requests to the tablets during a --load-shedding-interval below which the tablets are not considered saturated.")
+		fs.IntVar(&loadSheddingDefaultPriority, "load-shedding-default-priority", loadSheddingDefaultPriority, "Load shedding priority of the statements with neither a PRIORITY directive nor a --load-shedding-workload-priorities priority, between 0, never shed, and 100, shed first.")
+		fs.Var(&loadSheddingWorkloadPriorities, "load-shedding-workload-priorities", "Comma-separated list of workload:priority pairs setting the load shedding priority of the statements of each workload, as named by the WORKLOAD_NAME directive, when they have no PRIORITY directive, e.g. olap:80,batch:100.")
+		fs.StringSliceVar(&regionFallbackOrder, "region-fallback-order", regionFallbackOrder, "comma-separated list of regions, in order of preference, used to pick tablets in other cells when none are available in the local cell or the local cell's region. Cells in regions that are not listed are used last.")
 	})
 }
 
@@ -76,6 +109,12 @@ type TabletGateway struct {
 	retryCount           int
 	defaultConnCollation atomic.Uint32
 
+	// cellRegions maps each cell to the region set in its CellInfo. It is used
+	// to rank the tablets of other cells by region affinity.
+	cellRegions atomic.Pointer[map[string]string]
+	// cellRegionsMu serializes the updates of cellRegions.
+	cellRegionsMu sync.Mutex
+
 	// mu protects the fields of this group.
 	mu sync.Mutex
 	// statusAggregators is a map indexed by the key
@@ -84,6 +123,18 @@ type TabletGateway struct {
 
 	// buffer, if enabled, buffers requests during a detected PRIMARY failover.
 	buffer *buffer.Buffer
+
+	// breakers keep the REPLICA and RDONLY tablets which keep failing out of
+	// the rotation.
+	breakers *tabletBreakers
+
+	// slowStart ramps up the traffic of the REPLICA and RDONLY tablets which
+	// became healthy.
+	slowStart *tabletSlowStart
+
+	// loadShedder, if enabled, rejects the statements of the lowest
+	// priorities while the tablets are saturated.
+	loadShedder *loadShedder
 }
 
 func createHealthCheck(ctx context.Context, retryDelay, timeout time.Duration, ts *topo.Server, cell, cellsToWatch string) discovery.HealthCheck {
@@ -110,12 +161,143 @@ func NewTabletGateway(ctx context.Context, hc discovery.HealthCheck, serv srvtop
 		localCell:         localCell,
 		retryCount:        retryCount,
 		statusAggregators: make(map[string]*TabletStatusAggregator),
+		breakers:          newTabletBreakers(tabletBreakerThreshold, tabletBreakerProbeInterval),
+		slowStart:         newTabletSlowStart(tabletSlowStartWindow),
+	}
+	var err error
+	gw.loadShedder, err = newLoadShedder(loadSheddingThreshold, loadSheddingInterval, loadSheddingMinRequests, loadSheddingDefaultPriority, loadSheddingWorkloadPriorities)
+	if err != nil {
+		log.Exitf("Unable to create new TabletGateway: %v", err)
 	}
+	gw.watchCellRegions(ctx)
+	gw.slowStart.follow(ctx, hc)
 	gw.setupBuffering(ctx)
 	gw.QueryService = queryservice.Wrap(nil, gw.withRetry)
 	return gw
 }
 
+// watchCellRegions watches the CellInfo of the local cell and of the cells
+// whose tablets are watched, so that tablets in the same region as the local
+// cell can be preferred. The initial regions are read before it returns.
+func (gw *TabletGateway) watchCellRegions(ctx context.Context) {
+	if gw.srvTopoServer == nil {
+		return
+	}
+	ts, err := gw.srvTopoServer.GetTopoServer()
+	if err != nil || ts == nil {
+		return
+	}
+
+	cells := []string{gw.localCell}
+	for _, cell := range strings.Split(CellsToWatch, ",") {
+		cell = strings.TrimSpace(cell)
+		if cell != "" && !slices.Contains(cells, cell) {
+			cells = append(cells, cell)
+		}
+	}
+	for _, cell := range cells {
+		changes := gw.startCellRegionWatch(ctx, ts, cell)
+		go gw.watchCellRegion(ctx, ts, cell, changes)
+	}
+}
+
+// startCellRegionWatch starts watching the CellInfo of a cell and records its
+// current region. It returns nil if the watch could not be started.
+func (gw *TabletGateway) startCellRegionWatch(ctx context.Context, ts *topo.Server, cell string) <-chan *topo.WatchCellInfoData {
+	current, changes, err := ts.WatchCellInfo(ctx, cell)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		gw.setCellRegion(cell, "")
+		return nil
+	case err != nil:
+		log.Warningf("Unable to watch CellInfo for cell %v, keeping its current region: %v", cell, err)
+		return nil
+	}
+	gw.setCellRegion(cell, current.Value.Region)
+	return changes
+}
+
+// watchCellRegion keeps the region of a cell up to date with its CellInfo
+// until ctx is done. The watch is started again, with an increasing delay,
+// whenever it fails.
+func (gw *TabletGateway) watchCellRegion(ctx context.Context, ts *topo.Server, cell string, changes <-chan *topo.WatchCellInfoData) {
+	retryDelay := cellRegionsRetryDelay
+	for {
+		// changes is nil if the watch could not be started
+		for changes != nil {
+			c, ok := <-changes
+			if !ok {
+				break
+			}
+			if c.Err != nil {
+				if topo.IsErrType(c.Err, topo.NoNode) {
+					gw.setCellRegion(cell, "")
+				} else if !topo.IsErrType(c.Err, topo.Interrupted) {
+					log.Warningf("Error watching CellInfo for cell %v, keeping its current region: %v", cell, c.Err)
+				}
+				break
+			}
+			retryDelay = cellRegionsRetryDelay
+			gw.setCellRegion(cell, c.Value.Region)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+		retryDelay = min(2*retryDelay, cellRegionsMaxRetryDelay)
+		changes = gw.startCellRegionWatch(ctx, ts, cell)
+	}
+}
+
+// setCellRegion records the region of a cell. An empty region means the cell
+// has none.
+func (gw *TabletGateway) setCellRegion(cell, region string) {
+	gw.cellRegionsMu.Lock()
+	defer gw.cellRegionsMu.Unlock()
+
+	var regions map[string]string
+	if current := gw.cellRegions.Load(); current != nil {
+		if (*current)[cell] == region {
+			return
+		}
+		regions = maps.Clone(*current)
+	} else {
+		regions = make(map[string]string)
+	}
+	if region == "" {
+		delete(regions, cell)
+	} else {
+		regions[cell] = region
+	}
+	gw.cellRegions.Store(&regions)
+}
+
+// cellRank returns the preference rank of tablets in the given cell, as seen
+// from localCell. Lower ranks are preferred: tablets in the local cell come
+// first, then tablets in cells of the same region, then tablets in the
+// regions listed in --region-fallback-order, in that order, then the rest.
+func (gw *TabletGateway) cellRank(localCell, cell string) int {
+	if cell == localCell {
+		return 0
+	}
+	regions := gw.cellRegions.Load()
+	if regions == nil || len(*regions) == 0 {
+		return 1
+	}
+	region := (*regions)[cell]
+	if region != "" && region == (*regions)[localCell] {
+		return 1
+	}
+	for i, r := range regionFallbackOrder {
+		if region == r {
+			return 2 + i
+		}
+	}
+	return 2 + len(regionFallbackOrder)
+}
+
 func (gw *TabletGateway) setupBuffering(ctx context.Context) {
 	cfg := buffer.NewConfigFromFlags()
 	if !cfg.Enabled {
@@ -163,6 +345,16 @@ func (gw *TabletGateway) GetServingKeyspaces() []string {
 // and the checksum of the topology
 func (gw *TabletGateway) RegisterStats() {
 	gw.hc.RegisterStats()
+	if gw.breakers.enabled() {
+		stats.NewGaugesFuncWithMultiLabels("TabletBreakers", "Number of tablet circuit breakers by state", []string{"State"}, gw.breakers.stateCounts)
+		servenv.HTTPHandle(pathTabletBreakers, gw.breakers)
+	}
+	if gw.slowStart.enabled() {
+		stats.NewGaugesFuncWithMultiLabels("TabletSlowStartWeights", "Share of their traffic the tablets ramping up after becoming healthy receive, in percent", []string{"Tablet"}, gw.slowStart.weights)
+	}
+	if gw.loadShedder.enabled() {
+		stats.NewGaugeFunc("LoadSheddingPressure", "Pressure of the load shedder, in percent", gw.loadShedder.pressurePercent)
+	}
 }
 
 // WaitForTablets is part of the Gateway interface.
@@ -229,7 +421,7 @@ func (gw *TabletGateway) CacheStatus() TabletCacheStatusList {
 // withRetry also adds shard information to errors returned from the inner QueryService, so
 // withShardError should not be combined with withRetry.
 func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target, _ queryservice.QueryService,
-	_ string, inTransaction bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
+	name string, inTransaction bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
 
 	// for transactions, we connect to a specific tablet instead of letting gateway choose one
 	if inTransaction && target.TabletType != topodatapb.TabletType_PRIMARY {
@@ -252,6 +444,9 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		}
 	}
 
+	// The primary has no other tablet to send the queries to.
+	useBreakers := gw.breakers.enabled() && target.TabletType != topodatapb.TabletType_PRIMARY
+	useSlowStart := gw.slowStart.enabled() && target.TabletType != topodatapb.TabletType_PRIMARY
 	bufferedOnce := false
 	for i := 0; i < gw.retryCount+1; i++ {
 		// Check if we should buffer PRIMARY queries which failed due to an ongoing failover.
@@ -274,11 +469,34 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 				err = vterrors.Wrapf(bufferErr,
 					"failed to automatically buffer and retry failed request during failover. original err (type=%T): %v",
 					err, err)
-				break
+				if !idempotentRetryFromContext(ctx) {
+					break
+				}
+				// The request carries an idempotency token: the new primary can tell
+				// whether it was already applied, so retry it once instead of failing.
+				bufferedOnce = true
 			}
 		}
 
 		tablets := gw.hc.GetHealthyTabletStats(target)
+		if tags := tabletTagsFromContext(ctx, target.Keyspace); len(tags) > 0 && target.TabletType != topodatapb.TabletType_PRIMARY {
+			tablets = filterTabletsByTags(tablets, tags)
+			if len(tablets) == 0 {
+				err = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet available for '%s' with tags %v", target.String(), tags)
+				break
+			}
+		}
+		var readCells []string
+		if target.TabletType != topodatapb.TabletType_PRIMARY {
+			readCells = gw.readCellOrder(readCellPreferencesFromContext(ctx))
+		}
+		if len(readCells) > 0 {
+			tablets = gw.filterTabletsByReadCells(tablets, readCells)
+			if len(tablets) == 0 {
+				err = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet available for '%s' in cells %v", target.String(), readCells)
+				break
+			}
+		}
 		if len(tablets) == 0 {
 			// if we have a keyspace event watcher, check if the reason why our primary is not available is that it's currently being resharded
 			// or if a reparent operation is in progress.
@@ -307,13 +525,50 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		}
 
 		gw.shuffleTablets(gw.localCell, tablets)
+		if len(readCells) > 0 {
+			gw.sortTabletsByReadCells(tablets, readCells)
+		}
 
-		var th *discovery.TabletHealth
-		// skip tablets we tried before
+		var th, tripped, ramping *discovery.TabletHealth
+		var skipped, rampingSkipped []string
+		// skip tablets we tried before, those with an open circuit breaker,
+		// and those ramping up their traffic as often as their share tells
 		for _, t := range tablets {
-			if _, ok := invalidTablets[topoproto.TabletAliasString(t.Tablet.Alias)]; !ok {
-				th = t
-				break
+			alias := topoproto.TabletAliasString(t.Tablet.Alias)
+			if _, ok := invalidTablets[alias]; ok {
+				continue
+			}
+			if useBreakers && !gw.breakers.allow(alias) {
+				if tripped == nil {
+					tripped = t
+				}
+				skipped = append(skipped, alias)
+				continue
+			}
+			if useSlowStart && rand.Float64() >= gw.slowStart.weight(alias) {
+				if ramping == nil {
+					ramping = t
+				}
+				rampingSkipped = append(rampingSkipped, alias)
+				continue
+			}
+			th = t
+			break
+		}
+		if th == nil {
+			// All the remaining tablets are ramping up their traffic or have
+			// an open circuit breaker: rather than failing the query, send it
+			// to one of them anyway, preferring the healthy ones.
+			th = ramping
+			if th == nil {
+				th = tripped
+			}
+		} else {
+			for _, alias := range skipped {
+				tabletBreakerRejections.Add(alias, 1)
+			}
+			for _, alias := range rampingSkipped {
+				tabletSlowStartSkips.Add(alias, 1)
 			}
 		}
 		if th == nil {
@@ -338,15 +593,31 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		var canRetry bool
 		canRetry, err = inner(ctx, target, th.Conn)
 		gw.updateStats(target, startTime, err)
+		if useBreakers {
+			gw.breakers.record(topoproto.TabletAliasString(tabletLastUsed.Alias), target, err)
+		}
+		gw.loadShedder.record(err)
 		if canRetry {
 			invalidTablets[topoproto.TabletAliasString(tabletLastUsed.Alias)] = true
 			continue
 		}
 		break
 	}
+	if maxStalenessPrimaryFallback && !inTransaction && target.TabletType != topodatapb.TabletType_PRIMARY && isStaleTabletError(err) {
+		// None of the tablets we tried is fresh enough for the query.
+		primaryTarget := target.CloneVT()
+		primaryTarget.TabletType = topodatapb.TabletType_PRIMARY
+		return gw.withRetry(ctx, primaryTarget, nil, name, inTransaction, inner)
+	}
 	return NewShardError(err, target)
 }
 
+// isStaleTabletError returns true if the error is a tablet rejecting a query
+// for lagging behind its primary more than the query accepts.
+func isStaleTabletError(err error) bool {
+	return err != nil && vterrors.Code(err) == vtrpcpb.Code_UNAVAILABLE && strings.Contains(err.Error(), "VT14006")
+}
+
 // withShardError adds shard information to errors returned from the inner QueryService.
 func (gw *TabletGateway) withShardError(ctx context.Context, target *querypb.Target, conn queryservice.QueryService,
 	_ string, _ bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
@@ -376,25 +647,171 @@ func (gw *TabletGateway) getStatsAggregator(target *querypb.Target) *TabletStatu
 	return aggr
 }
 
-func (gw *TabletGateway) shuffleTablets(cell string, tablets []*discovery.TabletHealth) {
+type tabletTagsKey struct{}
 
-	// Randomly shuffle the list of tablets, putting the same-cell hosts at the front
-	// of the list and the other-cell hosts at the back
-	//
-	// Only need to do n-1 swaps since the last tablet is always in the right place.
-	n := len(tablets)
-	head := 0
-	tail := n - 1
-	for i := 0; i < n-1; i++ {
-		j := head + rand.IntN(tail-head+1)
-
-		if tablets[j].Tablet.Alias.Cell == cell {
-			tablets[head], tablets[j] = tablets[j], tablets[head]
-			head++
-		} else {
-			tablets[tail], tablets[j] = tablets[j], tablets[tail]
-			tail--
+// withTabletTags returns a context that restricts the non-PRIMARY tablets the
+// gateway routes to, to those carrying all of the given tags.
+func withTabletTags(ctx context.Context, tags map[string]string) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, tabletTagsKey{}, tags)
+}
+
+type keyspaceTabletTagsKey struct{}
+
+// withKeyspaceTabletTags returns a context that restricts the non-PRIMARY
+// tablets the gateway routes to in each keyspace, to those carrying all of
+// the tags of the keyspace in the vschema. The tags set with withTabletTags
+// take precedence.
+func withKeyspaceTabletTags(ctx context.Context, tags map[string]map[string]string) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, keyspaceTabletTagsKey{}, tags)
+}
+
+// tabletTagsFromContext returns the tags the non-PRIMARY tablets of the given
+// keyspace must carry.
+func tabletTagsFromContext(ctx context.Context, keyspace string) map[string]string {
+	if tags, _ := ctx.Value(tabletTagsKey{}).(map[string]string); len(tags) > 0 {
+		return tags
+	}
+	tags, _ := ctx.Value(keyspaceTabletTagsKey{}).(map[string]map[string]string)
+	return tags[keyspace]
+}
+
+type readCellPreferencesKey struct{}
+
+// withReadCellPreferences returns a context that orders the cells of the
+// non-PRIMARY tablets the gateway routes to, after the read cell preferences
+// of the vschema of a table.
+func withReadCellPreferences(ctx context.Context, prefs []*vschemapb.ReadCellPreference) context.Context {
+	if len(prefs) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, readCellPreferencesKey{}, prefs)
+}
+
+func readCellPreferencesFromContext(ctx context.Context) []*vschemapb.ReadCellPreference {
+	prefs, _ := ctx.Value(readCellPreferencesKey{}).([]*vschemapb.ReadCellPreference)
+	return prefs
+}
+
+// readCellOrder returns the cells and regions of the read cell preference
+// applying to the local cell: the preference of the local cell, else the one
+// of its region, else the one without a client cell.
+func (gw *TabletGateway) readCellOrder(prefs []*vschemapb.ReadCellPreference) []string {
+	region := gw.cellRegion(gw.localCell)
+	var regionCells, defaultCells []string
+	for _, pref := range prefs {
+		switch {
+		case pref.ClientCell == gw.localCell:
+			return pref.Cells
+		case region != "" && pref.ClientCell == region:
+			regionCells = pref.Cells
+		case pref.ClientCell == "":
+			defaultCells = pref.Cells
+		}
+	}
+	if regionCells != nil {
+		return regionCells
+	}
+	return defaultCells
+}
+
+// cellRegion returns the region of a cell, or an empty string if it has none.
+func (gw *TabletGateway) cellRegion(cell string) string {
+	regions := gw.cellRegions.Load()
+	if regions == nil {
+		return ""
+	}
+	return (*regions)[cell]
+}
+
+// readCellRank returns the position in readCells of the given cell, or of its
+// region, or -1 if neither is listed.
+func (gw *TabletGateway) readCellRank(readCells []string, cell string) int {
+	region := gw.cellRegion(cell)
+	for i, readCell := range readCells {
+		if readCell == cell || (region != "" && readCell == region) {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterTabletsByReadCells returns the subset of tablets in the given cells or
+// regions. The returned slice does not alias the input.
+func (gw *TabletGateway) filterTabletsByReadCells(tablets []*discovery.TabletHealth, readCells []string) []*discovery.TabletHealth {
+	filtered := make([]*discovery.TabletHealth, 0, len(tablets))
+	for _, th := range tablets {
+		if gw.readCellRank(readCells, th.Tablet.Alias.Cell) >= 0 {
+			filtered = append(filtered, th)
+		}
+	}
+	return filtered
+}
+
+// sortTabletsByReadCells orders the tablets after the position of their cell in
+// readCells, so that the tablets of a cell are only used when those of the
+// previous cells failed. The tablets of the same position keep their order.
+func (gw *TabletGateway) sortTabletsByReadCells(tablets []*discovery.TabletHealth, readCells []string) {
+	sort.SliceStable(tablets, func(i, j int) bool {
+		return gw.readCellRank(readCells, tablets[i].Tablet.Alias.Cell) < gw.readCellRank(readCells, tablets[j].Tablet.Alias.Cell)
+	})
+}
+
+type idempotentRetryKey struct{}
+
+// withIdempotentRetry returns a context that lets the gateway retry a request
+// carrying an idempotency token once after buffering failed during a failover.
+func withIdempotentRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentRetryKey{}, true)
+}
+
+func idempotentRetryFromContext(ctx context.Context) bool {
+	retry, _ := ctx.Value(idempotentRetryKey{}).(bool)
+	return retry
+}
+
+// filterTabletsByTags returns the subset of tablets carrying all of the given
+// tags. The returned slice does not alias the input.
+func filterTabletsByTags(tablets []*discovery.TabletHealth, tags map[string]string) []*discovery.TabletHealth {
+	filtered := make([]*discovery.TabletHealth, 0, len(tablets))
+	for _, th := range tablets {
+		if topoproto.TabletHasTags(th.Tablet, tags) {
+			filtered = append(filtered, th)
+		}
+	}
+	return filtered
+}
+
+func (gw *TabletGateway) shuffleTablets(cell string, tablets []*discovery.TabletHealth) {
+	// Order the list of tablets by cell preference so that same-cell hosts are
+	// at the front of the list, followed by hosts in the same region, and
+	// other-region hosts at the back, then randomly shuffle the hosts of each
+	// rank. The rank of each tablet is computed once, and there are only a few
+	// ranks, so the tablets are partitioned by rank rather than sorted.
+	ranks := make([]int, len(tablets))
+	for i, th := range tablets {
+		ranks[i] = gw.cellRank(cell, th.Tablet.Alias.Cell)
+	}
+	for start := 0; start < len(tablets); {
+		lowest := slices.Min(ranks[start:])
+		end := start
+		for i := start; i < len(tablets); i++ {
+			if ranks[i] == lowest {
+				tablets[i], tablets[end] = tablets[end], tablets[i]
+				ranks[i], ranks[end] = ranks[end], ranks[i]
+				end++
+			}
 		}
+		group := tablets[start:end]
+		rand.Shuffle(len(group), func(i, j int) {
+			group[i], group[j] = group[j], group[i]
+		})
+		start = end
 	}
 }
 
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const pathTabletBreakers = "/debug/tablet_breakers"
+
+var (
+	// tabletBreakerThreshold is the number of consecutive failures of a
+	// REPLICA or RDONLY tablet which trip its circuit breaker. 0 disables the
+	// circuit breakers.
+	tabletBreakerThreshold = 0
+	// tabletBreakerProbeInterval is how long a tripped circuit breaker keeps
+	// its tablet out of the rotation before letting a probe query through.
+	tabletBreakerProbeInterval = 5 * time.Second
+
+	tabletBreakerTrips      = stats.NewCountersWithSingleLabel("TabletBreakerTrips", "Number of times the circuit breaker of a tablet was tripped", "Tablet")
+	tabletBreakerRejections = stats.NewCountersWithSingleLabel("TabletBreakerRejections", "Number of queries which skipped a tablet because its circuit breaker was open", "Tablet")
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// tabletBreaker is the circuit breaker of a tablet which failed recently.
+type tabletBreaker struct {
+	target   *querypb.Target
+	state    breakerState
+	failures int
+	trips    int
+	// since is when the breaker was last opened, or when its last probe
+	// was let through if it is half-open.
+	since time.Time
+}
+
+// tabletBreakers keeps the circuit breakers of the tablets, keyed by tablet
+// alias. A breaker is tripped by threshold consecutive failures of its
+// tablet, which then receives no queries but a probe every probeInterval,
+// until a probe succeeds. Only the tablets which failed since their last
+// success have a breaker.
+type tabletBreakers struct {
+	threshold     int
+	probeInterval time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*tabletBreaker
+}
+
+func newTabletBreakers(threshold int, probeInterval time.Duration) *tabletBreakers {
+	return &tabletBreakers{
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		now:           time.Now,
+		breakers:      make(map[string]*tabletBreaker),
+	}
+}
+
+func (tb *tabletBreakers) enabled() bool {
+	return tb != nil && tb.threshold > 0
+}
+
+// allow returns whether a query may be sent to the tablet. The breakers which
+// were open for probeInterval turn half-open, and let one probe through.
+func (tb *tabletBreakers) allow(alias string) bool {
+	if !tb.enabled() {
+		return true
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	b := tb.breakers[alias]
+	if b == nil || b.state == breakerClosed {
+		return true
+	}
+	// A half-open breaker lets another probe through if the result of the
+	// previous one was never recorded.
+	now := tb.now()
+	if now.Sub(b.since) < tb.probeInterval {
+		return false
+	}
+	b.state = breakerHalfOpen
+	b.since = now
+	return true
+}
+
+// record records the outcome of a query sent to the tablet. Any answer of the
+// tablet, even an error, closes its breaker, except for the errors which tell
+// it is unreachable, overloaded or too slow.
+func (tb *tabletBreakers) record(alias string, target *querypb.Target, err error) {
+	if !tb.enabled() {
+		return
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	b := tb.breakers[alias]
+	if !isTabletBreakerFailure(err) {
+		if b != nil && b.state != breakerClosed {
+			log.Infof("Closing the circuit breaker of tablet %s after a successful probe", alias)
+		}
+		delete(tb.breakers, alias)
+		return
+	}
+
+	if b == nil {
+		b = &tabletBreaker{target: target}
+		tb.breakers[alias] = b
+	}
+	b.failures++
+	switch b.state {
+	case breakerClosed:
+		if b.failures >= tb.threshold {
+			log.Warningf("Tripping the circuit breaker of tablet %s after %d consecutive failures: %v", alias, b.failures, err)
+			b.state = breakerOpen
+			b.since = tb.now()
+			b.trips++
+			tabletBreakerTrips.Add(alias, 1)
+		}
+	case breakerHalfOpen:
+		b.state = breakerOpen
+		b.since = tb.now()
+	}
+}
+
+// isTabletBreakerFailure returns true if the error counts against the circuit
+// breaker of the tablet which returned it.
+func isTabletBreakerFailure(err error) bool {
+	if err == nil || isStaleTabletError(err) {
+		return false
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_DEADLINE_EXCEEDED, vtrpcpb.Code_RESOURCE_EXHAUSTED, vtrpcpb.Code_INTERNAL:
+		return true
+	}
+	return false
+}
+
+// stateCounts returns the number of open and half-open breakers.
+func (tb *tabletBreakers) stateCounts() map[string]int64 {
+	counts := map[string]int64{breakerOpen.String(): 0, breakerHalfOpen.String(): 0}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	for _, b := range tb.breakers {
+		if b.state != breakerClosed {
+			counts[b.state.String()]++
+		}
+	}
+	return counts
+}
+
+// TabletBreakerStatus is the state of the circuit breaker of a tablet, as
+// listed by the tablet breakers debug page.
+type TabletBreakerStatus struct {
+	Tablet     string
+	Keyspace   string
+	Shard      string
+	TabletType string
+	State      string
+	// Failures is the number of consecutive failures of the tablet.
+	Failures int
+	Trips    int
+	Since    time.Time `json:",omitempty"`
+}
+
+func (tb *tabletBreakers) status() []TabletBreakerStatus {
+	tb.mu.Lock()
+	res := make([]TabletBreakerStatus, 0, len(tb.breakers))
+	for alias, b := range tb.breakers {
+		status := TabletBreakerStatus{
+			Tablet:     alias,
+			Keyspace:   b.target.GetKeyspace(),
+			Shard:      b.target.GetShard(),
+			TabletType: b.target.GetTabletType().String(),
+			State:      b.state.String(),
+			Failures:   b.failures,
+			Trips:      b.trips,
+		}
+		if b.state != breakerClosed {
+			status.Since = b.since
+		}
+		res = append(res, status)
+	}
+	tb.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Tablet < res[j].Tablet })
+	return res
+}
+
+// ServeHTTP lists the circuit breakers of the tablets which failed since they
+// last succeeded.
+func (tb *tabletBreakers) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	returnAsJSON(response, tb.status())
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+)
+
+var (
+	// tabletSlowStartWindow is how long the REPLICA and RDONLY tablets which
+	// became healthy take to ramp up to their full share of the traffic. 0
+	// disables the slow start.
+	tabletSlowStartWindow time.Duration
+
+	tabletSlowStartRamps = stats.NewCountersWithSingleLabel("TabletSlowStartRamps", "Number of times a tablet started ramping up its share of the traffic after becoming healthy", "Tablet")
+	tabletSlowStartSkips = stats.NewCountersWithSingleLabel("TabletSlowStartSkips", "Number of queries which skipped a tablet because it was ramping up its share of the traffic", "Tablet")
+)
+
+// tabletSlowStart ramps up the share of the traffic of the tablets which
+// became healthy, such as restarted replicas whose buffer pool is cold, over
+// window rather than sending them their full share at once. The share of a
+// tablet grows linearly from 0, when it starts serving, to 1 once window
+// elapsed.
+//
+// The tablets are followed through the health check: a tablet starts ramping
+// up when it starts serving, including when the health check discovers it.
+// A tablet which stops serving loses the share it ramped up at the same rate
+// while it is not serving, so that a short outage only delays its ramp up,
+// and one longer than window restarts it.
+type tabletSlowStart struct {
+	window time.Duration
+	now    func() time.Time
+
+	// ramping maps the alias of each tablet ramping up to the time it started
+	// ramping up. It is only replaced by the goroutine following the health of
+	// the tablets, so that the queries read it without locking.
+	ramping atomic.Pointer[map[string]time.Time]
+
+	// tablets holds the serving state of each tablet, keyed by alias. It is
+	// only accessed by the goroutine following the health of the tablets.
+	tablets map[string]*slowStartTablet
+}
+
+type slowStartTablet struct {
+	serving bool
+	// start is when the tablet started ramping up, moved forward by the
+	// time it was not serving.
+	start time.Time
+	// stopped is when the serving tablet stopped serving.
+	stopped time.Time
+}
+
+func newTabletSlowStart(window time.Duration) *tabletSlowStart {
+	return &tabletSlowStart{
+		window:  window,
+		now:     time.Now,
+		tablets: make(map[string]*slowStartTablet),
+	}
+}
+
+func (ss *tabletSlowStart) enabled() bool {
+	return ss != nil && ss.window > 0
+}
+
+// follow updates the tablets ramping up with the health updates of hc until
+// ctx is done.
+func (ss *tabletSlowStart) follow(ctx context.Context, hc discovery.HealthCheck) {
+	if !ss.enabled() {
+		return
+	}
+	updates := hc.Subscribe()
+	go func() {
+		defer hc.Unsubscribe(updates)
+		// The tablets which completed their ramp up are forgotten even if no
+		// health update comes.
+		ticker := time.NewTicker(ss.window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case th := <-updates:
+				ss.update(th)
+			case <-ticker.C:
+				ss.publish()
+			}
+		}
+	}()
+}
+
+// update records the serving state of the tablet of th, which starts ramping
+// up if it starts serving.
+func (ss *tabletSlowStart) update(th *discovery.TabletHealth) {
+	if th == nil || th.Tablet == nil {
+		return
+	}
+	alias := topoproto.TabletAliasString(th.Tablet.Alias)
+	serving := th.Serving && th.LastError == nil
+	t, known := ss.tablets[alias]
+	if known && t.serving == serving {
+		return
+	}
+	if !known {
+		t = &slowStartTablet{}
+		ss.tablets[alias] = t
+	}
+	now := ss.now()
+	if !serving {
+		if t.serving {
+			t.stopped = now
+		}
+		t.serving = false
+		return
+	}
+
+	// The share the tablet ramped up before it stopped serving, if it did,
+	// decreases with the time it was not serving.
+	var rampedUp time.Duration
+	if !t.stopped.IsZero() {
+		rampedUp = max(min(t.stopped.Sub(t.start), ss.window)-now.Sub(t.stopped), 0)
+	}
+	t.serving = true
+	t.start = now.Add(-rampedUp)
+	t.stopped = time.Time{}
+	if rampedUp < ss.window {
+		log.Infof("Tablet %s became healthy, ramping up its share of the traffic over %v", alias, ss.window-rampedUp)
+		tabletSlowStartRamps.Add(alias, 1)
+	}
+	ss.publish()
+}
+
+// publish replaces the tablets ramping up read by the queries, and forgets
+// the tablets which stopped serving longer than window ago, whose ramp up
+// would restart anyway.
+func (ss *tabletSlowStart) publish() {
+	now := ss.now()
+	ramping := make(map[string]time.Time)
+	for alias, t := range ss.tablets {
+		switch {
+		case t.serving && now.Sub(t.start) < ss.window:
+			ramping[alias] = t.start
+		case !t.serving && (t.stopped.IsZero() || now.Sub(t.stopped) >= ss.window):
+			delete(ss.tablets, alias)
+		}
+	}
+	if current := ss.ramping.Load(); current == nil && len(ramping) == 0 {
+		return
+	}
+	ss.ramping.Store(&ramping)
+}
+
+// weight returns the share of its traffic the tablet receives, between 0 when
+// it just became healthy and 1 once it is ramped up.
+func (ss *tabletSlowStart) weight(alias string) float64 {
+	if !ss.enabled() {
+		return 1
+	}
+	ramping := ss.ramping.Load()
+	if ramping == nil {
+		return 1
+	}
+	since, ok := (*ramping)[alias]
+	if !ok {
+		return 1
+	}
+	return ss.weightSince(since)
+}
+
+func (ss *tabletSlowStart) weightSince(since time.Time) float64 {
+	elapsed := ss.now().Sub(since)
+	if elapsed >= ss.window {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(ss.window)
+}
+
+// weights returns the share of their traffic the tablets ramping up receive,
+// in percent, keyed by alias.
+func (ss *tabletSlowStart) weights() map[string]int64 {
+	res := make(map[string]int64)
+	ramping := ss.ramping.Load()
+	if ramping == nil {
+		return res
+	}
+	for alias, since := range *ramping {
+		if weight := ss.weightSince(since); weight < 1 {
+			res[alias] = int64(weight * 100)
+		}
+	}
+	return res
+}
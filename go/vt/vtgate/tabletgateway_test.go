@@ -18,9 +18,11 @@ package vtgate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,9 +33,12 @@ import (
 	"vitess.io/vitess/go/vt/discovery"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/buffer"
 )
 
 func TestTabletGatewayExecute(t *testing.T) {
@@ -152,6 +157,457 @@ func TestTabletGatewayShuffleTablets(t *testing.T) {
 	}
 }
 
+func TestTabletGatewayShuffleTabletsRegionAffinity(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "local")
+	defer tg.Close(ctx)
+
+	oldRegionFallbackOrder := regionFallbackOrder
+	defer func() { regionFallbackOrder = oldRegionFallbackOrder }()
+	regionFallbackOrder = []string{"us-west", "eu"}
+
+	tg.cellRegions.Store(&map[string]string{
+		"cell1": "us-east",
+		"cell2": "us-east",
+		"cell3": "eu",
+		"cell4": "us-west",
+		"cell5": "ap",
+	})
+
+	newTablet := func(uid uint32, cell string) *discovery.TabletHealth {
+		return &discovery.TabletHealth{
+			Tablet:  topo.NewTablet(uid, cell, fmt.Sprintf("host%d", uid)),
+			Target:  &querypb.Target{Keyspace: "k", Shard: "s", TabletType: topodatapb.TabletType_REPLICA},
+			Serving: true,
+		}
+	}
+	tablets := []*discovery.TabletHealth{
+		newTablet(1, "cell5"),
+		newTablet(2, "cell3"),
+		newTablet(3, "cell4"),
+		newTablet(4, "cell2"),
+		newTablet(5, "cell1"),
+		newTablet(6, "cell6"),
+	}
+
+	for i := 0; i < 10; i++ {
+		tg.shuffleTablets("cell1", tablets)
+		var cells []string
+		for _, th := range tablets[:4] {
+			cells = append(cells, th.Tablet.Alias.Cell)
+		}
+		assert.Equal(t, []string{"cell1", "cell2", "cell4", "cell3"}, cells)
+		assert.ElementsMatch(t, []string{"cell5", "cell6"}, []string{tablets[4].Tablet.Alias.Cell, tablets[5].Tablet.Alias.Cell})
+	}
+}
+
+func TestTabletGatewayWatchCellRegions(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	oldCellsToWatch := CellsToWatch
+	defer func() { CellsToWatch = oldCellsToWatch }()
+	CellsToWatch = "cell1,cell2"
+
+	serv := newSandboxForCells(ctx, []string{"cell1", "cell2", "cell3"})
+	setRegion := func(cell, region string) {
+		err := serv.topoServer.UpdateCellInfoFields(ctx, cell, func(ci *topodatapb.CellInfo) error {
+			ci.Region = region
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	setRegion("cell1", "us-east")
+	setRegion("cell2", "us-west")
+	setRegion("cell3", "us-east")
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	tg := NewTabletGateway(ctx, hc, serv, "cell1")
+	defer tg.Close(ctx)
+
+	// Only the regions of the watched cells are read, before the gateway is
+	// returned.
+	assert.Equal(t, "us-east", tg.cellRegion("cell1"))
+	assert.Equal(t, "us-west", tg.cellRegion("cell2"))
+	assert.Empty(t, tg.cellRegion("cell3"))
+	assert.Equal(t, 2, tg.cellRank("cell1", "cell2"))
+
+	// The changes of the regions are picked up, and an empty region clears it.
+	setRegion("cell2", "us-east")
+	assert.Eventually(t, func() bool {
+		return tg.cellRank("cell1", "cell2") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	setRegion("cell2", "")
+	assert.Eventually(t, func() bool {
+		return tg.cellRegion("cell2") == ""
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTabletGatewayTabletTags(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell", "1.1.1.1", 1002, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2.Tablet().Tags = map[string]string{"role": "reporting"}
+
+	tagCtx := withTabletTags(ctx, map[string]string{"role": "reporting"})
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(tagCtx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 0, sc1.ExecCount.Load())
+	assert.EqualValues(t, 10, sc2.ExecCount.Load())
+
+	_, err := tg.Execute(withTabletTags(ctx, map[string]string{"role": "batch"}), target, "query", nil, 0, 0, nil)
+	verifyContainsError(t, err, "with tags", vtrpcpb.Code_UNAVAILABLE)
+
+	// The tags of the keyspace in the vschema only apply to its own tablets,
+	// and the tags of the session take precedence.
+	ksCtx := withKeyspaceTabletTags(ctx, map[string]map[string]string{
+		"ks":    {"role": "reporting"},
+		"other": {"role": "batch"},
+	})
+	_, err = tg.Execute(ksCtx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, sc1.ExecCount.Load())
+	assert.EqualValues(t, 11, sc2.ExecCount.Load())
+
+	_, err = tg.Execute(withTabletTags(ksCtx, map[string]string{"role": "batch"}), target, "query", nil, 0, 0, nil)
+	verifyContainsError(t, err, "with tags", vtrpcpb.Code_UNAVAILABLE)
+}
+
+func TestTabletGatewayReadCellPreferences(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell1")
+	defer tg.Close(ctx)
+	tg.cellRegions.Store(&map[string]string{
+		"cell1": "us-east",
+		"cell2": "us-east",
+		"cell3": "us-west",
+		"cell4": "eu",
+	})
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell1", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell2", "1.1.1.2", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc3 := hc.AddTestTablet("cell3", "1.1.1.3", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc4 := hc.AddTestTablet("cell4", "1.1.1.4", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+
+	// The preference of the local cell applies over those of its region and
+	// of the other vtgates.
+	prefs := []*vschemapb.ReadCellPreference{
+		{Cells: []string{"eu"}},
+		{ClientCell: "us-east", Cells: []string{"us-west"}},
+		{ClientCell: "cell1", Cells: []string{"cell2", "us-west"}},
+	}
+	assert.Equal(t, []string{"cell2", "us-west"}, tg.readCellOrder(prefs))
+	assert.Equal(t, []string{"us-west"}, tg.readCellOrder(prefs[:2]))
+	assert.Equal(t, []string{"eu"}, tg.readCellOrder(prefs[:1]))
+	assert.Empty(t, tg.readCellOrder(nil))
+
+	prefCtx := withReadCellPreferences(ctx, prefs)
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(prefCtx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 0, sc1.ExecCount.Load())
+	assert.EqualValues(t, 10, sc2.ExecCount.Load())
+
+	// The reads fail over to the next cells in order, and never to the cells
+	// which are not listed.
+	sc2.MustFailCodes[vtrpcpb.Code_UNAVAILABLE] = 1
+	_, err := tg.Execute(prefCtx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sc3.ExecCount.Load())
+
+	hc.RemoveTablet(sc2.Tablet())
+	hc.RemoveTablet(sc3.Tablet())
+	_, err = tg.Execute(prefCtx, target, "query", nil, 0, 0, nil)
+	verifyContainsError(t, err, "in cells [cell2 us-west]", vtrpcpb.Code_UNAVAILABLE)
+	assert.EqualValues(t, 0, sc1.ExecCount.Load())
+	assert.EqualValues(t, 0, sc4.ExecCount.Load())
+
+	// The preferences do not apply to the primary.
+	primary := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_PRIMARY}
+	scPrimary := hc.AddTestTablet("cell1", "1.1.1.5", 1001, "ks", "0", topodatapb.TabletType_PRIMARY, true, 10, nil)
+	_, err = tg.Execute(prefCtx, primary, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, scPrimary.ExecCount.Load())
+}
+
+func TestTabletGatewayMaxStalenessPrimaryFallback(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell", "1.1.1.1", 1002, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	primary := hc.AddTestTablet("cell", "1.1.1.1", 1003, "ks", "0", topodatapb.TabletType_PRIMARY, true, 0, nil)
+
+	stale := func() {
+		sc1.EphemeralShardErr = vterrors.VT14006("10s", "1s")
+		sc2.EphemeralShardErr = vterrors.VT14006("10s", "1s")
+	}
+
+	// The stale replicas are retried, but the query does not go to the primary
+	// by default.
+	stale()
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	verifyContainsError(t, err, "VT14006", vtrpcpb.Code_UNAVAILABLE)
+	assert.EqualValues(t, 1, sc1.ExecCount.Load())
+	assert.EqualValues(t, 1, sc2.ExecCount.Load())
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+
+	defer func(fallback bool) {
+		maxStalenessPrimaryFallback = fallback
+	}(maxStalenessPrimaryFallback)
+	maxStalenessPrimaryFallback = true
+
+	stale()
+	_, err = tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+}
+
+func TestTabletGatewayIdempotentRetryAfterBufferError(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+	// A buffer without room fails to buffer the requests during a failover.
+	cfg := buffer.NewDefaultConfig()
+	cfg.Enabled = true
+	cfg.Size = 0
+	tg.buffer = buffer.New(cfg)
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_PRIMARY}
+	// The old primary is in the local cell, and is tried first.
+	oldPrimary := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_PRIMARY, true, 10, nil)
+	newPrimary := hc.AddTestTablet("cell2", "1.1.1.2", 1001, "ks", "0", topodatapb.TabletType_PRIMARY, true, 10, nil)
+
+	oldPrimary.MustFailCodes[vtrpcpb.Code_CLUSTER_EVENT] = 1
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.ErrorContains(t, err, "failed to automatically buffer and retry failed request during failover")
+	assert.EqualValues(t, 1, oldPrimary.ExecCount.Load())
+	assert.EqualValues(t, 0, newPrimary.ExecCount.Load())
+
+	oldPrimary.MustFailCodes[vtrpcpb.Code_CLUSTER_EVENT] = 1
+	_, err = tg.Execute(withIdempotentRetry(ctx), target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, oldPrimary.ExecCount.Load())
+	assert.EqualValues(t, 1, newPrimary.ExecCount.Load())
+}
+
+func TestTabletBreakers(t *testing.T) {
+	now := time.Now()
+	tb := newTabletBreakers(2, time.Minute)
+	tb.now = func() time.Time { return now }
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	unavailable := vterrors.New(vtrpcpb.Code_UNAVAILABLE, "unavailable")
+
+	// The errors of the queries do not count.
+	tb.record("cell-1", target, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "syntax error"))
+	assert.Empty(t, tb.status())
+
+	tb.record("cell-1", target, unavailable)
+	assert.True(t, tb.allow("cell-1"))
+	tb.record("cell-1", target, vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "timeout"))
+	assert.False(t, tb.allow("cell-1"))
+	assert.True(t, tb.allow("cell-2"))
+	assert.Equal(t, []TabletBreakerStatus{{
+		Tablet:     "cell-1",
+		Keyspace:   "ks",
+		Shard:      "0",
+		TabletType: "REPLICA",
+		State:      "open",
+		Failures:   2,
+		Trips:      1,
+		Since:      now,
+	}}, tb.status())
+	assert.Equal(t, map[string]int64{"open": 1, "half_open": 0}, tb.stateCounts())
+
+	// A single probe goes through after probeInterval, and its failure opens
+	// the breaker again.
+	now = now.Add(time.Minute)
+	assert.True(t, tb.allow("cell-1"))
+	assert.False(t, tb.allow("cell-1"))
+	assert.Equal(t, map[string]int64{"open": 0, "half_open": 1}, tb.stateCounts())
+	tb.record("cell-1", target, unavailable)
+	assert.False(t, tb.allow("cell-1"))
+
+	// A successful probe closes the breaker.
+	now = now.Add(time.Minute)
+	assert.True(t, tb.allow("cell-1"))
+	tb.record("cell-1", target, nil)
+	assert.True(t, tb.allow("cell-1"))
+	assert.Empty(t, tb.status())
+}
+
+func TestTabletGatewayCircuitBreaker(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+	now := time.Now()
+	tg.breakers = newTabletBreakers(2, time.Minute)
+	tg.breakers.now = func() time.Time { return now }
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell", "1.1.1.1", 1002, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	alias1 := topoproto.TabletAliasString(sc1.Tablet().Alias)
+
+	// The queries failing on sc1 are retried on sc2, until the breaker of
+	// sc1 trips.
+	sc1.MustFailCodes[vtrpcpb.Code_UNAVAILABLE] = 1000
+	for i := 0; i < 100 && sc1.ExecCount.Load() < 2; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 2, sc1.ExecCount.Load())
+	rejections := tabletBreakerRejections.Counts()[alias1]
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, sc1.ExecCount.Load())
+	assert.Greater(t, tabletBreakerRejections.Counts()[alias1], rejections)
+
+	// sc1 gets the queries when it is the only tablet left.
+	hc.RemoveTablet(sc2.Tablet())
+	sc1.MustFailCodes[vtrpcpb.Code_UNAVAILABLE] = 0
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, sc1.ExecCount.Load())
+	assert.Empty(t, tg.breakers.status())
+
+	// The breakers do not apply to the primary.
+	primary := hc.AddTestTablet("cell", "1.1.1.1", 1003, "ks", "0", topodatapb.TabletType_PRIMARY, true, 0, nil)
+	primary.MustFailCodes[vtrpcpb.Code_INTERNAL] = 3
+	primaryTarget := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_PRIMARY}
+	for i := 0; i < 3; i++ {
+		_, err = tg.Execute(ctx, primaryTarget, "query", nil, 0, 0, nil)
+		require.Error(t, err)
+	}
+	assert.Empty(t, tg.breakers.status())
+}
+
+func TestTabletSlowStart(t *testing.T) {
+	now := time.Now()
+	ss := newTabletSlowStart(10 * time.Second)
+	ss.now = func() time.Time { return now }
+	health := func(uid uint32, serving bool) *discovery.TabletHealth {
+		return &discovery.TabletHealth{Tablet: &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "cell", Uid: uid}}, Serving: serving}
+	}
+
+	// The tablets are ramped up until they serve.
+	assert.EqualValues(t, 1, ss.weight("cell-0000000001"))
+	assert.Empty(t, ss.weights())
+
+	// A tablet which starts serving, including one the health check just
+	// discovered, ramps up over the window.
+	ss.update(health(1, false))
+	ss.update(health(1, true))
+	ss.update(health(2, true))
+	assert.EqualValues(t, 0, ss.weight("cell-0000000001"))
+	assert.EqualValues(t, 0, ss.weight("cell-0000000002"))
+	now = now.Add(5 * time.Second)
+	assert.EqualValues(t, 0.5, ss.weight("cell-0000000001"))
+	assert.Equal(t, map[string]int64{"cell-0000000001": 50, "cell-0000000002": 50}, ss.weights())
+	now = now.Add(5 * time.Second)
+	assert.EqualValues(t, 1, ss.weight("cell-0000000001"))
+
+	// The updates of a tablet which keeps serving do not restart its ramp up.
+	ss.update(health(1, true))
+	assert.EqualValues(t, 1, ss.weight("cell-0000000001"))
+
+	// A short outage only loses the share ramped up during the outage.
+	ss.update(health(1, false))
+	now = now.Add(2 * time.Second)
+	ss.update(health(1, true))
+	assert.EqualValues(t, 0.8, ss.weight("cell-0000000001"))
+
+	// An outage longer than the window restarts the ramp up.
+	now = now.Add(2 * time.Second)
+	ss.update(&discovery.TabletHealth{Tablet: health(1, true).Tablet, Serving: true, LastError: errors.New("unreachable")})
+	now = now.Add(20 * time.Second)
+	ss.update(health(1, true))
+	assert.EqualValues(t, 0, ss.weight("cell-0000000001"))
+
+	// The tablets ramped up are forgotten.
+	now = now.Add(10 * time.Second)
+	ss.publish()
+	assert.Empty(t, *ss.ramping.Load())
+}
+
+func TestTabletGatewaySlowStart(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+	now := time.Now()
+	tg.slowStart = newTabletSlowStart(time.Minute)
+	tg.slowStart.now = func() time.Time { return now }
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sc1.ExecCount.Load())
+
+	// The tablet which just became healthy gets no queries while another
+	// tablet can take them.
+	sc2 := hc.AddTestTablet("cell", "1.1.1.1", 1002, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	th2, err := hc.GetTabletHealthByAlias(sc2.Tablet().Alias)
+	require.NoError(t, err)
+	tg.slowStart.update(th2)
+	alias2 := topoproto.TabletAliasString(sc2.Tablet().Alias)
+	skips := tabletSlowStartSkips.Counts()[alias2]
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 0, sc2.ExecCount.Load())
+	assert.EqualValues(t, 11, sc1.ExecCount.Load())
+	assert.Greater(t, tabletSlowStartSkips.Counts()[alias2], skips)
+
+	// It gets the queries when it is the only tablet left.
+	hc.RemoveTablet(sc1.Tablet())
+	_, err = tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sc2.ExecCount.Load())
+
+	// Once ramped up, it gets its full share of the queries.
+	now = now.Add(time.Minute)
+	sc1 = hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	for i := 0; i < 100 && sc2.ExecCount.Load() == 1; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.Greater(t, sc2.ExecCount.Load(), int64(1))
+}
+
 func TestTabletGatewayReplicaTransactionError(t *testing.T) {
 	ctx := utils.LeakCheckContext(t)
 
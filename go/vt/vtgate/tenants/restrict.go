@@ -0,0 +1,364 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenants
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Restrict rewrites stmt in place so that it only reads and writes the rows of
+// the tenant in ks, the keyspace of the tenant:
+//   - the tables qualified with the database of the tenant are qualified with
+//     its keyspace instead,
+//   - the reads, updates and deletes of the tables of the keyspace are
+//     restricted to the rows whose tenant id column is the id of the tenant,
+//   - the inserts set the tenant id column to the id of the tenant,
+//   - the statements setting the tenant id column to another tenant, the
+//     tables of other keyspaces, DDLs, which would change the tables of every
+//     tenant, and the statements the tenant predicate cannot restrict, such
+//     as STREAM, VSTREAM, CALL and LOAD, or the REPLACEs and ON DUPLICATE KEY
+//     UPDATEs into tables with a key without the tenant id column, are
+//     rejected.
+//
+// The bind variables are those of the statement, against which the tenant ids
+// set by the statement are checked.
+func (t *Tenant) Restrict(stmt sqlparser.Statement, ks *vindexes.KeyspaceSchema, bindVars map[string]*querypb.BindVariable) error {
+	if ks == nil {
+		return vterrors.VT05003(t.Keyspace)
+	}
+	if sqlparser.ASTToStatementType(stmt) == sqlparser.StmtDDL {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "DDL is not allowed in tenant database %s", t.Database)
+	}
+	switch stmt.(type) {
+	case *sqlparser.Stream:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "STREAM is not allowed in tenant database %s", t.Database)
+	case *sqlparser.VStream:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "VSTREAM is not allowed in tenant database %s", t.Database)
+	case *sqlparser.CallProc:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "CALL is not allowed in tenant database %s", t.Database)
+	case *sqlparser.Load:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "LOAD is not allowed in tenant database %s", t.Database)
+	}
+	id, err := tenantIDLiteral(t.ID, ks.MultiTenantSpec)
+	if err != nil {
+		return vterrors.Wrapf(err, "tenant database %s", t.Database)
+	}
+
+	r := &restrictor{
+		tenant:   t,
+		ks:       ks,
+		column:   sqlparser.NewIdentifierCI(ks.MultiTenantSpec.TenantIdColumnName),
+		id:       id,
+		bindVars: bindVars,
+		ctes:     map[string]struct{}{},
+	}
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if cte, ok := node.(*sqlparser.CommonTableExpr); ok {
+			r.ctes[cte.ID.String()] = struct{}{}
+		}
+		return true, nil
+	}, stmt)
+
+	restricted := false
+	sqlparser.Rewrite(stmt, func(cursor *sqlparser.Cursor) bool {
+		if err != nil {
+			return false
+		}
+		switch node := cursor.Node().(type) {
+		case sqlparser.TableName:
+			if node.Qualifier.String() == t.Database {
+				node.Qualifier = sqlparser.NewIdentifierCS(t.Keyspace)
+				cursor.Replace(node)
+			}
+		case *sqlparser.Select:
+			restricted = r.restrictFrom(node.From, node.AddWhere) || restricted
+			err = r.err
+		case *sqlparser.Update:
+			if err = r.checkUpdateExprs(node.Exprs); err != nil {
+				return false
+			}
+			restricted = r.restrictFrom(node.TableExprs, node.AddWhere) || restricted
+			err = r.err
+		case *sqlparser.Delete:
+			restricted = r.restrictFrom(node.TableExprs, node.AddWhere) || restricted
+			err = r.err
+		case *sqlparser.Insert:
+			var ok bool
+			if ok, err = r.restrictInsert(node); err != nil {
+				return false
+			}
+			restricted = ok || restricted
+		}
+		return true
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if restricted {
+		tenantQueries.Add(t.Keyspace, 1)
+	}
+	return nil
+}
+
+type restrictor struct {
+	tenant   *Tenant
+	ks       *vindexes.KeyspaceSchema
+	column   sqlparser.IdentifierCI
+	id       *sqlparser.Literal
+	bindVars map[string]*querypb.BindVariable
+
+	// ctes are the names of the common table expressions of the statement,
+	// which are not tables of the keyspace.
+	ctes map[string]struct{}
+
+	// err is the error restricting a join.
+	err error
+}
+
+// isTenantTable returns whether the table is a table of the keyspace holding
+// the rows of several tenants. The tables of other keyspaces, which the tenant
+// predicate cannot restrict, are an error.
+func (r *restrictor) isTenantTable(table sqlparser.TableName) (bool, error) {
+	switch qualifier := table.Qualifier.String(); qualifier {
+	case "":
+		if _, ok := r.ctes[table.Name.String()]; ok || table.Name.String() == "dual" {
+			return false, nil
+		}
+	case r.tenant.Database, r.tenant.Keyspace:
+	default:
+		if sqlparser.SystemSchema(qualifier) {
+			return false, nil
+		}
+		return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s of another keyspace is not allowed in tenant database %s", sqlparser.String(table), r.tenant.Database)
+	}
+	if vt, ok := r.ks.Tables[table.Name.String()]; ok {
+		return vt.Type != vindexes.TypeReference && vt.Type != vindexes.TypeSequence, nil
+	}
+	// The tables of a sharded keyspace are all in its vschema, so the others
+	// would be routed to another keyspace.
+	if r.ks.Keyspace.Sharded {
+		return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s is not in keyspace %s of tenant database %s", table.Name.String(), r.tenant.Keyspace, r.tenant.Database)
+	}
+	return true, nil
+}
+
+// restrictFrom adds the tenant predicates of the tables of from with addWhere,
+// or to the join conditions of the outer joins for their inner tables. It
+// returns whether a predicate was added.
+func (r *restrictor) restrictFrom(from []sqlparser.TableExpr, addWhere func(sqlparser.Expr)) bool {
+	var predicates []sqlparser.Expr
+	for _, expr := range from {
+		predicates = append(predicates, r.tablePredicates(expr)...)
+	}
+	if len(predicates) == 0 {
+		return false
+	}
+	addWhere(sqlparser.AndExpressions(predicates...))
+	return true
+}
+
+// tablePredicates returns the tenant predicates of the tables of expr which
+// must be added to the enclosing WHERE or join condition.
+func (r *restrictor) tablePredicates(expr sqlparser.TableExpr) []sqlparser.Expr {
+	switch expr := expr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		table, ok := expr.Expr.(sqlparser.TableName)
+		if !ok {
+			return nil
+		}
+		isTenantTable, err := r.isTenantTable(table)
+		if err != nil {
+			r.err = err
+			return nil
+		}
+		if !isTenantTable {
+			return nil
+		}
+		qualifier := sqlparser.TableName{Name: table.Name}
+		if !expr.As.IsEmpty() {
+			qualifier.Name = expr.As
+		}
+		return []sqlparser.Expr{sqlparser.NewComparisonExpr(sqlparser.EqualOp, sqlparser.NewColNameWithQualifier(r.column.String(), qualifier), sqlparser.Clone(r.id), nil)}
+	case *sqlparser.ParenTableExpr:
+		var predicates []sqlparser.Expr
+		for _, inner := range expr.Exprs {
+			predicates = append(predicates, r.tablePredicates(inner)...)
+		}
+		return predicates
+	case *sqlparser.JoinTableExpr:
+		left := r.tablePredicates(expr.LeftExpr)
+		right := r.tablePredicates(expr.RightExpr)
+		switch expr.Join {
+		case sqlparser.LeftJoinType, sqlparser.NaturalLeftJoinType:
+			r.addToJoinCondition(expr, right)
+			return left
+		case sqlparser.RightJoinType, sqlparser.NaturalRightJoinType:
+			r.addToJoinCondition(expr, left)
+			return right
+		}
+		return append(left, right...)
+	}
+	return nil
+}
+
+// addToJoinCondition adds the tenant predicates of the inner tables of an
+// outer join to its ON condition. MySQL does not allow an ON condition on
+// joins with USING or NATURAL, so those are rejected when their inner tables
+// must be restricted.
+func (r *restrictor) addToJoinCondition(join *sqlparser.JoinTableExpr, predicates []sqlparser.Expr) {
+	if len(predicates) == 0 {
+		return
+	}
+	if join.Join == sqlparser.NaturalLeftJoinType || join.Join == sqlparser.NaturalRightJoinType || (join.Condition != nil && join.Condition.Using != nil) {
+		r.err = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "outer joins with USING or NATURAL are not supported in tenant database %s", r.tenant.Database)
+		return
+	}
+	if join.Condition == nil {
+		join.Condition = &sqlparser.JoinCondition{}
+	}
+	join.Condition.On = sqlparser.AndExpressions(append([]sqlparser.Expr{join.Condition.On}, predicates...)...)
+}
+
+// checkUpdateExprs returns an error if the expressions set the tenant id
+// column to another tenant.
+func (r *restrictor) checkUpdateExprs(exprs sqlparser.UpdateExprs) error {
+	for _, expr := range exprs {
+		if expr.Name.Name.Equal(r.column) {
+			if err := r.checkTenantID(expr.Expr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restrictInsert sets the tenant id column of the rows inserted in a table
+// of the keyspace. It returns whether the insert is into such a table.
+func (r *restrictor) restrictInsert(insert *sqlparser.Insert) (bool, error) {
+	table, err := insert.Table.TableName()
+	if err != nil {
+		return false, nil
+	}
+	if ok, err := r.isTenantTable(table); err != nil || !ok {
+		return false, err
+	}
+	if insert.Action == sqlparser.ReplaceAct || len(insert.OnDup) > 0 {
+		// A REPLACE or an ON DUPLICATE KEY UPDATE would delete or update the
+		// row of another tenant conflicting on a key without the tenant id.
+		if !r.keysHaveTenantID(table) {
+			return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "REPLACE and INSERT ... ON DUPLICATE KEY UPDATE into %s require the %s column in its primary and unique keys in tenant database %s", table.Name.String(), r.column.String(), r.tenant.Database)
+		}
+	}
+	if err := r.checkUpdateExprs(sqlparser.UpdateExprs(insert.OnDup)); err != nil {
+		return false, err
+	}
+	if len(insert.Columns) == 0 {
+		return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "INSERT into %s must list its columns in tenant database %s", table.Name.String(), r.tenant.Database)
+	}
+
+	col := insert.Columns.FindColumn(r.column)
+	switch rows := insert.Rows.(type) {
+	case sqlparser.Values:
+		for i, row := range rows {
+			if col >= 0 {
+				if col < len(row) {
+					if err := r.checkTenantID(row[col]); err != nil {
+						return false, err
+					}
+				}
+				continue
+			}
+			rows[i] = append(row, sqlparser.Clone(r.id))
+		}
+	case *sqlparser.Select:
+		// The rows read by the SELECT are restricted with it, but the values
+		// it selects for the tenant id column are forced to the tenant.
+		for _, expr := range rows.SelectExprs {
+			if _, ok := expr.(*sqlparser.StarExpr); ok {
+				return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "INSERT ... SELECT into %s must list the columns of its SELECT in tenant database %s", table.Name.String(), r.tenant.Database)
+			}
+		}
+		if col < 0 {
+			rows.SelectExprs = append(rows.SelectExprs, &sqlparser.AliasedExpr{Expr: sqlparser.Clone(r.id)})
+		} else if col < len(rows.SelectExprs) {
+			expr, ok := rows.SelectExprs[col].(*sqlparser.AliasedExpr)
+			if !ok {
+				return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "INSERT ... SELECT into %s must select the %s column in tenant database %s", table.Name.String(), r.column.String(), r.tenant.Database)
+			}
+			rows.SelectExprs[col] = &sqlparser.AliasedExpr{Expr: sqlparser.Clone(r.id), As: expr.As}
+		}
+	default:
+		return false, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "INSERT ... SELECT with UNION into %s is not supported in tenant database %s", table.Name.String(), r.tenant.Database)
+	}
+	if col < 0 {
+		insert.Columns = append(insert.Columns, r.column)
+	}
+	return true, nil
+}
+
+// keysHaveTenantID returns whether the primary key and every unique key of the
+// table, as tracked from its schema, include the tenant id column. A table
+// whose primary key is unknown has no such guarantee.
+func (r *restrictor) keysHaveTenantID(table sqlparser.TableName) bool {
+	vt, ok := r.ks.Tables[table.Name.String()]
+	if !ok || vt.PrimaryKey.FindColumn(r.column) < 0 {
+		return false
+	}
+	for _, key := range vt.UniqueKeys {
+		found := false
+		for _, expr := range key {
+			if col, ok := expr.(*sqlparser.ColName); ok && col.Name.Equal(r.column) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTenantID returns an error if expr is not the id of the tenant, as a
+// literal or a bind variable.
+func (r *restrictor) checkTenantID(expr sqlparser.Expr) error {
+	var value string
+	switch expr := expr.(type) {
+	case *sqlparser.Literal:
+		value = expr.Val
+	case *sqlparser.Argument:
+		bv, ok := r.bindVars[expr.Name]
+		if !ok {
+			break
+		}
+		v, err := sqltypes.BindVariableToValue(bv)
+		if err != nil {
+			return err
+		}
+		value = v.ToString()
+	}
+	if value != r.tenant.ID {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s must be %s in tenant database %s", r.column.String(), r.tenant.ID, r.tenant.Database)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenants
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func testKeyspaceSchema() *vindexes.KeyspaceSchema {
+	return &vindexes.KeyspaceSchema{
+		Keyspace: &vindexes.Keyspace{Name: "customers", Sharded: true},
+		Tables: map[string]*vindexes.Table{
+			"orders": {
+				Name:       sqlparser.NewIdentifierCS("orders"),
+				PrimaryKey: sqlparser.Columns{sqlparser.NewIdentifierCI("tenant_id"), sqlparser.NewIdentifierCI("id")},
+			},
+			"users": {
+				Name:       sqlparser.NewIdentifierCS("users"),
+				PrimaryKey: sqlparser.Columns{sqlparser.NewIdentifierCI("id")},
+			},
+			"items": {
+				Name:       sqlparser.NewIdentifierCS("items"),
+				PrimaryKey: sqlparser.Columns{sqlparser.NewIdentifierCI("tenant_id"), sqlparser.NewIdentifierCI("id")},
+				UniqueKeys: []sqlparser.Exprs{{sqlparser.NewColName("sku")}},
+			},
+			"countries": {Name: sqlparser.NewIdentifierCS("countries"), Type: vindexes.TypeReference},
+		},
+		MultiTenantSpec: &vschemapb.MultiTenantSpec{
+			TenantIdColumnName: "tenant_id",
+			TenantIdColumnType: querypb.Type_INT64,
+		},
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+		err  string
+	}{{
+		sql:  "select * from orders where id = 1",
+		want: "select * from orders where id = 1 and orders.tenant_id = 42",
+	}, {
+		sql:  "select * from customer42.orders as o join users on o.user_id = users.id",
+		want: "select * from customers.orders as o join users on o.user_id = users.id where o.tenant_id = 42 and users.tenant_id = 42",
+	}, {
+		sql:  "select * from users left join orders on orders.user_id = users.id",
+		want: "select * from users left join orders on orders.user_id = users.id and orders.tenant_id = 42 where users.tenant_id = 42",
+	}, {
+		sql:  "select * from orders join countries on orders.country_id = countries.id",
+		want: "select * from orders join countries on orders.country_id = countries.id where orders.tenant_id = 42",
+	}, {
+		sql:  "select * from users where id in (select user_id from orders)",
+		want: "select * from users where id in (select user_id from orders where orders.tenant_id = 42) and users.tenant_id = 42",
+	}, {
+		sql:  "with o as (select * from orders) select * from o",
+		want: "with o as (select * from orders where orders.tenant_id = 42) select * from o",
+	}, {
+		sql:  "select 1 from dual",
+		want: "select 1 from dual",
+	}, {
+		sql:  "select * from information_schema.tables",
+		want: "select * from information_schema.`tables`",
+	}, {
+		sql: "select * from other.t",
+		err: "table other.t of another keyspace is not allowed in tenant database customer42",
+	}, {
+		sql: "select * from orders join other.t on orders.id = t.id",
+		err: "table other.t of another keyspace is not allowed in tenant database customer42",
+	}, {
+		sql: "select * from unknown",
+		err: "table unknown is not in keyspace customers of tenant database customer42",
+	}, {
+		sql: "insert into other.t(id) values (1)",
+		err: "table other.t of another keyspace is not allowed in tenant database customer42",
+	}, {
+		sql:  "update orders set status = 'done' where id = 1",
+		want: "update orders set `status` = 'done' where id = 1 and orders.tenant_id = 42",
+	}, {
+		sql:  "delete from orders where id = 1",
+		want: "delete from orders where id = 1 and orders.tenant_id = 42",
+	}, {
+		sql:  "insert into orders(id, user_id) values (1, 2), (3, 4)",
+		want: "insert into orders(id, user_id, tenant_id) values (1, 2, 42), (3, 4, 42)",
+	}, {
+		sql:  "insert into orders(id, tenant_id) values (1, 42), (2, :tenant)",
+		want: "insert into orders(id, tenant_id) values (1, 42), (2, :tenant)",
+	}, {
+		sql:  "insert into orders(id, tenant_id) select id, tenant_id from users",
+		want: "insert into orders(id, tenant_id) select id, 42 from users where users.tenant_id = 42",
+	}, {
+		sql:  "insert into orders(id, tenant_id) select id, 43 as t from users",
+		want: "insert into orders(id, tenant_id) select id, 42 as t from users where users.tenant_id = 42",
+	}, {
+		sql:  "insert into orders(id) select id from users",
+		want: "insert into orders(id, tenant_id) select id, 42 from users where users.tenant_id = 42",
+	}, {
+		sql: "insert into orders(id, tenant_id) select * from users",
+		err: "INSERT ... SELECT into orders must list the columns of its SELECT in tenant database customer42",
+	}, {
+		sql: "insert into orders(id, tenant_id) select id, tenant_id from users union select id, tenant_id from orders",
+		err: "INSERT ... SELECT with UNION into orders is not supported in tenant database customer42",
+	}, {
+		sql: "insert into orders(id, tenant_id) values (1, 43)",
+		err: "tenant_id must be 42 in tenant database customer42",
+	}, {
+		sql: "insert into orders(id, tenant_id) values (1, :other)",
+		err: "tenant_id must be 42 in tenant database customer42",
+	}, {
+		sql: "insert into orders values (1, 2)",
+		err: "INSERT into orders must list its columns in tenant database customer42",
+	}, {
+		sql:  "replace into orders(id, status) values (1, 'new')",
+		want: "replace into orders(id, `status`, tenant_id) values (1, 'new', 42)",
+	}, {
+		sql:  "insert into orders(id, status) values (1, 'new') on duplicate key update status = 'new'",
+		want: "insert into orders(id, `status`, tenant_id) values (1, 'new', 42) on duplicate key update `status` = 'new'",
+	}, {
+		sql: "replace into users(id, name) values (1, 'a')",
+		err: "REPLACE and INSERT ... ON DUPLICATE KEY UPDATE into users require the tenant_id column in its primary and unique keys in tenant database customer42",
+	}, {
+		sql: "insert into users(id, name) values (1, 'a') on duplicate key update name = 'a'",
+		err: "REPLACE and INSERT ... ON DUPLICATE KEY UPDATE into users require the tenant_id column in its primary and unique keys in tenant database customer42",
+	}, {
+		sql: "replace into items(id, sku) values (1, 'a')",
+		err: "REPLACE and INSERT ... ON DUPLICATE KEY UPDATE into items require the tenant_id column in its primary and unique keys in tenant database customer42",
+	}, {
+		sql: "update orders set tenant_id = 43",
+		err: "tenant_id must be 42 in tenant database customer42",
+	}, {
+		sql: "select * from users left join orders using (id)",
+		err: "outer joins with USING or NATURAL are not supported in tenant database customer42",
+	}, {
+		sql: "alter table orders add column c int",
+		err: "DDL is not allowed in tenant database customer42",
+	}, {
+		sql: "stream * from orders",
+		err: "STREAM is not allowed in tenant database customer42",
+	}, {
+		sql: "vstream * from orders",
+		err: "VSTREAM is not allowed in tenant database customer42",
+	}, {
+		sql: "call proc()",
+		err: "CALL is not allowed in tenant database customer42",
+	}, {
+		sql: "load data infile 'f' into table orders",
+		err: "LOAD is not allowed in tenant database customer42",
+	}}
+
+	tenant := &Tenant{Database: "customer42", Keyspace: "customers", ID: "42"}
+	bindVars := map[string]*querypb.BindVariable{
+		"tenant": sqltypes.Int64BindVariable(42),
+		"other":  sqltypes.Int64BindVariable(43),
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := parser.Parse(tt.sql)
+			require.NoError(t, err)
+
+			err = tenant.Restrict(stmt, testKeyspaceSchema(), bindVars)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sqlparser.String(stmt))
+		})
+	}
+}
+
+func TestRestrictNotMultiTenant(t *testing.T) {
+	ks := testKeyspaceSchema()
+	ks.MultiTenantSpec = nil
+	stmt, err := sqlparser.NewTestParser().Parse("select * from orders")
+	require.NoError(t, err)
+
+	tenant := &Tenant{Database: "customer42", Keyspace: "customers", ID: "42"}
+	assert.ErrorContains(t, tenant.Restrict(stmt, ks, nil), "keyspace has no multi_tenant_spec")
+	assert.ErrorContains(t, tenant.Restrict(stmt, nil, nil), "customers")
+}
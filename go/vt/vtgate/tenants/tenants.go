@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenants routes the sessions using the database of a tenant to the
+// multi-tenant keyspace of the tenant, according to a TenantCatalog stored in
+// the global topo, and restricts their statements to the rows of the tenant.
+package tenants
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	enabled    bool
+	retryDelay = 10 * time.Second
+
+	current atomic.Pointer[vtgatepb.TenantCatalog]
+
+	tenantCount = stats.NewGaugeFunc("TenantCatalogTenants", "Number of tenants in the tenant catalog", func() int64 {
+		return int64(len(current.Load().GetTenants()))
+	})
+	tenantQueries = stats.NewCountersWithSingleLabel("TenantQueries", "Number of statements restricted to a tenant, by keyspace", "Keyspace")
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&enabled, "enable-tenant-catalog", enabled, "If set, route the sessions using the database of a tenant of the TenantCatalog stored in the global topo to the keyspace of the tenant, restricted to its rows, reloading the catalog on change.")
+}
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vtgate"} {
+		servenv.OnParseFor(cmd, registerFlags)
+	}
+}
+
+// Tenant is the tenant of a database.
+type Tenant struct {
+	Database string
+	Keyspace string
+	ID       string
+}
+
+// Lookup returns the tenant of database, or nil if the database is not in the
+// tenant catalog.
+func Lookup(database string) *Tenant {
+	tenant, ok := current.Load().GetTenants()[database]
+	if !ok {
+		return nil
+	}
+	return &Tenant{
+		Database: database,
+		Keyspace: tenant.Keyspace,
+		ID:       tenant.TenantId,
+	}
+}
+
+// Validate returns an error if the catalog has an invalid database name or an
+// incomplete tenant.
+func Validate(catalog *vtgatepb.TenantCatalog) error {
+	for database, tenant := range catalog.GetTenants() {
+		if database == "" || sqlparser.SystemSchema(database) {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tenant database name %q", database)
+		}
+		if tenant.GetKeyspace() == "" {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "tenant database %s: keyspace is required", database)
+		}
+		if tenant.GetTenantId() == "" {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "tenant database %s: tenant_id is required", database)
+		}
+	}
+	return nil
+}
+
+// ValidateTenantID returns an error if the keyspace of spec is not multi-tenant,
+// or if id is not a valid value of its tenant id column.
+func ValidateTenantID(id string, spec *vschemapb.MultiTenantSpec) error {
+	_, err := tenantIDLiteral(id, spec)
+	return err
+}
+
+func tenantIDLiteral(id string, spec *vschemapb.MultiTenantSpec) (*sqlparser.Literal, error) {
+	if spec.GetTenantIdColumnName() == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace has no multi_tenant_spec")
+	}
+	typ := spec.GetTenantIdColumnType()
+	switch {
+	case sqltypes.IsSigned(typ):
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "tenant id %q is not a valid %s", id, typ)
+		}
+		return sqlparser.NewIntLiteral(id), nil
+	case sqltypes.IsUnsigned(typ):
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "tenant id %q is not a valid %s", id, typ)
+		}
+		return sqlparser.NewIntLiteral(id), nil
+	}
+	return sqlparser.NewStrLiteral(id), nil
+}
+
+// Set replaces the tenant catalog used by Lookup. A nil catalog maps no
+// database to a tenant.
+func Set(catalog *vtgatepb.TenantCatalog) error {
+	if err := Validate(catalog); err != nil {
+		return err
+	}
+	current.Store(catalog)
+	return nil
+}
+
+// Init starts watching the TenantCatalog in the global topo, if the tenant
+// catalog is enabled. It returns immediately; the watch stops when ctx is done.
+func Init(ctx context.Context, ts *topo.Server) {
+	if !enabled {
+		return
+	}
+	go watch(ctx, ts, retryDelay)
+}
+
+func watch(ctx context.Context, ts *topo.Server, retryDelay time.Duration) {
+	for {
+		initial, changes, err := ts.WatchTenantCatalog(ctx)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			load(nil)
+		case err != nil:
+			log.Warningf("Error watching TenantCatalog, keeping the current one: %v", err)
+		default:
+			load(initial.Value)
+			for c := range changes {
+				if c.Err != nil {
+					if topo.IsErrType(c.Err, topo.NoNode) {
+						load(nil)
+					} else if !topo.IsErrType(c.Err, topo.Interrupted) {
+						log.Warningf("Error watching TenantCatalog, keeping the current one: %v", c.Err)
+					}
+					break
+				}
+				load(c.Value)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+func load(catalog *vtgatepb.TenantCatalog) {
+	if err := Set(catalog); err != nil {
+		log.Errorf("Ignoring invalid TenantCatalog, keeping the current one: %v", err)
+		return
+	}
+	log.Infof("Loaded TenantCatalog with %d tenants", len(catalog.GetTenants()))
+}
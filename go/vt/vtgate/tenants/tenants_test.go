@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenants
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestLookup(t *testing.T) {
+	defer current.Store(nil)
+
+	assert.Nil(t, Lookup("customer1"))
+
+	require.NoError(t, Set(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{
+			"customer1": {Keyspace: "customers", TenantId: "1"},
+		},
+	}))
+	assert.Equal(t, &Tenant{Database: "customer1", Keyspace: "customers", ID: "1"}, Lookup("customer1"))
+	assert.Nil(t, Lookup("customer2"))
+
+	require.NoError(t, Set(nil))
+	assert.Nil(t, Lookup("customer1"))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{"customer1": {Keyspace: "customers", TenantId: "1"}},
+	}))
+	assert.ErrorContains(t, Validate(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{"mysql": {Keyspace: "customers", TenantId: "1"}},
+	}), `invalid tenant database name "mysql"`)
+	assert.ErrorContains(t, Validate(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{"customer1": {TenantId: "1"}},
+	}), "tenant database customer1: keyspace is required")
+	assert.ErrorContains(t, Validate(&vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{"customer1": {Keyspace: "customers"}},
+	}), "tenant database customer1: tenant_id is required")
+}
+
+func TestValidateTenantID(t *testing.T) {
+	intSpec := &vschemapb.MultiTenantSpec{TenantIdColumnName: "tenant_id", TenantIdColumnType: querypb.Type_INT64}
+	uintSpec := &vschemapb.MultiTenantSpec{TenantIdColumnName: "tenant_id", TenantIdColumnType: querypb.Type_UINT32}
+	strSpec := &vschemapb.MultiTenantSpec{TenantIdColumnName: "tenant_id", TenantIdColumnType: querypb.Type_VARCHAR}
+
+	assert.NoError(t, ValidateTenantID("-1", intSpec))
+	assert.ErrorContains(t, ValidateTenantID("acme", intSpec), `tenant id "acme" is not a valid INT64`)
+	assert.NoError(t, ValidateTenantID("1", uintSpec))
+	assert.Error(t, ValidateTenantID("-1", uintSpec))
+	assert.NoError(t, ValidateTenantID("acme", strSpec))
+	assert.ErrorContains(t, ValidateTenantID("1", nil), "keyspace has no multi_tenant_spec")
+}
+
+func TestWatch(t *testing.T) {
+	defer current.Store(nil)
+
+	oldEnabled, oldRetryDelay := enabled, retryDelay
+	defer func() { enabled, retryDelay = oldEnabled, oldRetryDelay }()
+	enabled, retryDelay = true, 10*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	Init(ctx, ts)
+	assert.Nil(t, Lookup("customer1"))
+
+	require.NoError(t, ts.SaveTenantCatalog(ctx, &vtgatepb.TenantCatalog{
+		Tenants: map[string]*vtgatepb.Tenant{"customer1": {Keyspace: "customers", TenantId: "1"}},
+	}))
+	assert.Eventually(t, func() bool {
+		return Lookup("customer1") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ts.SaveTenantCatalog(ctx, &vtgatepb.TenantCatalog{}))
+	assert.Eventually(t, func() bool {
+		return Lookup("customer1") == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
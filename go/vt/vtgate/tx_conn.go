@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/vt/concurrency"
@@ -90,20 +91,39 @@ func (txc *TxConn) Commit(ctx context.Context, session *SafeSession) error {
 		return nil
 	}
 
-	twopc := false
-	switch session.TransactionMode {
-	case vtgatepb.TransactionMode_TWOPC:
-		twopc = true
-	case vtgatepb.TransactionMode_UNSPECIFIED:
-		twopc = txc.mode == vtgatepb.TransactionMode_TWOPC
+	mode := session.TransactionMode
+	if mode == vtgatepb.TransactionMode_UNSPECIFIED {
+		mode = txc.mode
 	}
+	defer commitModeTimings.Record(mode.String(), time.Now())
 
-	if twopc {
+	if mode == vtgatepb.TransactionMode_TWOPC {
 		return txc.commit2PC(ctx, session)
 	}
 	return txc.commitNormal(ctx, session)
 }
 
+// checkTwoPC returns an error if the session set its transaction_mode to
+// TWOPC and the SrvKeyspace of the target does not have 2PC enabled, so that
+// the transaction fails before it begins on the keyspace rather than when it
+// is committed. The sessions which use the --transaction_mode of vtgate are
+// not checked, for the keyspaces created before their 2PC capability was
+// recorded to keep working.
+func (txc *TxConn) checkTwoPC(ctx context.Context, session *SafeSession, target *querypb.Target) error {
+	if session.TransactionMode != vtgatepb.TransactionMode_TWOPC || txc.tabletGateway.srvTopoServer == nil {
+		return nil
+	}
+	srvKeyspace, err := txc.tabletGateway.srvTopoServer.GetSrvKeyspace(ctx, txc.tabletGateway.localCell, target.Keyspace)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot check that keyspace %s has 2PC enabled", target.Keyspace)
+	}
+	if !srvKeyspace.GetTwopcEnabled() {
+		twoPCKeyspaceRejections.Add(target.Keyspace, 1)
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "transaction_mode TWOPC cannot be used with keyspace %s, which does not have 2PC enabled: use transaction_mode MULTI, or enable it with SetKeyspaceTwoPC", target.Keyspace)
+	}
+	return nil
+}
+
 func (txc *TxConn) queryService(ctx context.Context, alias *topodatapb.TabletAlias) (queryservice.QueryService, error) {
 	if alias == nil {
 		return txc.tabletGateway, nil
@@ -971,6 +971,39 @@ func TestTxConnCommit2PC(t *testing.T) {
 	assert.EqualValues(t, 1, sbc0.ConcludeTransactionCount.Load(), "sbc0.ConcludeTransactionCount")
 }
 
+func TestTxConnTwoPCKeyspaceCapability(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	sc, sbc0, sbc1, rss0, rss1, _ := newTestTxConnEnv(t, ctx, "TestTxConnTwoPCKeyspaceCapability")
+
+	// The sessions relying on the transaction mode of vtgate are not checked.
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	sc.txConn.mode = vtgatepb.TransactionMode_TWOPC
+	_, errs := sc.ExecuteMultiShard(ctx, nil, rss0, queries, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Rollback(ctx, session))
+
+	// The sessions which set their transaction mode to TWOPC cannot begin a
+	// transaction on a keyspace without 2PC enabled.
+	session = NewSafeSession(&vtgatepb.Session{InTransaction: true, TransactionMode: vtgatepb.TransactionMode_TWOPC})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss0, queries, session, false, false)
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "transaction_mode TWOPC cannot be used with keyspace TestTxConnTwoPCKeyspaceCapability, which does not have 2PC enabled")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(errs[0]))
+	assert.EqualValues(t, 1, sbc0.BeginCount.Load(), "sbc0.BeginCount")
+	assert.EqualValues(t, 1, twoPCKeyspaceRejections.Counts()["TestTxConnTwoPCKeyspaceCapability"])
+
+	getSandbox("TestTxConnTwoPCKeyspaceCapability").TwoPCEnabled = true
+	session = NewSafeSession(&vtgatepb.Session{InTransaction: true, TransactionMode: vtgatepb.TransactionMode_TWOPC})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss0, queries, session, false, false)
+	require.Empty(t, errs)
+	_, errs = sc.ExecuteMultiShard(ctx, nil, rss1, queries, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Commit(ctx, session))
+	assert.EqualValues(t, 1, sbc0.CreateTransactionCount.Load(), "sbc0.CreateTransactionCount")
+	assert.EqualValues(t, 1, sbc1.CommitPreparedCount.Load(), "sbc1.CommitPreparedCount")
+}
+
 func TestTxConnCommit2PCOneParticipant(t *testing.T) {
 	ctx := utils.LeakCheckContext(t)
 
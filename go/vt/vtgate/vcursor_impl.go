@@ -53,6 +53,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/logstats"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/tenants"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
@@ -78,6 +79,8 @@ type iExecute interface {
 	showShards(ctx context.Context, filter *sqlparser.ShowFilter, destTabletType topodatapb.TabletType) (*sqltypes.Result, error)
 	showTablets(filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	showVitessMetadata(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
+	showVitessPlans(filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
+	trackedSchema(keyspace string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool)
 	setVitessMetadata(ctx context.Context, name, value string) error
 
 	// TODO: remove when resolver is gone
@@ -97,10 +100,12 @@ type VSchemaOperator interface {
 // vcursorImpl implements the VCursor functionality used by dependent
 // packages to call back into VTGate.
 type vcursorImpl struct {
-	safeSession    *SafeSession
-	keyspace       string
-	tabletType     topodatapb.TabletType
-	destination    key.Destination
+	safeSession *SafeSession
+	keyspace    string
+	tabletType  topodatapb.TabletType
+	destination key.Destination
+	// pinnedTablet is the tablet alias of a target pinned to a tablet.
+	pinnedTablet   *topodatapb.TabletAlias
 	marginComments sqlparser.MarginComments
 	executor       iExecute
 	resolver       *srvtopo.Resolver
@@ -124,6 +129,14 @@ type vcursorImpl struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// queryShard records the shards targeted by the query for the query
+	// metrics. It is nil unless they have a shard dimension.
+	queryShard *queryShard
+
+	// tenant is the tenant of the database of the session, whose statements
+	// are restricted to its rows, if any.
+	tenant *tenants.Tenant
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -142,10 +155,19 @@ func newVCursorImpl(
 	warnShardedOnly bool,
 	pv plancontext.PlannerVersion,
 ) (*vcursorImpl, error) {
-	keyspace, tabletType, destination, err := parseDestinationTarget(safeSession.TargetString, vschema)
+	keyspace, tabletType, destination, pinnedTablet, err := parseDestinationTarget(safeSession.TargetString, vschema)
 	if err != nil {
 		return nil, err
 	}
+	if readOnlyTxOnReplicas && routeToReplica(safeSession, tabletType) {
+		tabletType = topodatapb.TabletType_REPLICA
+	}
+	var tenant *tenants.Tenant
+	if _, ok := vschema.Keyspaces[keyspace]; !ok && !ignoreKeyspace(keyspace) {
+		if tenant = tenants.Lookup(keyspace); tenant != nil {
+			keyspace = tenant.Keyspace
+		}
+	}
 
 	var ts *topo.Server
 	// We don't have access to the underlying TopoServer if this vtgate is
@@ -179,6 +201,7 @@ func newVCursorImpl(
 		keyspace:            keyspace,
 		tabletType:          tabletType,
 		destination:         destination,
+		pinnedTablet:        pinnedTablet,
 		marginComments:      marginComments,
 		executor:            executor,
 		logStats:            logStats,
@@ -191,6 +214,7 @@ func newVCursorImpl(
 		pv:                  pv,
 		warmingReadsPercent: warmingReadsPct,
 		warmingReadsChannel: warmingReadsChan,
+		tenant:              tenant,
 	}, nil
 }
 
@@ -572,21 +596,44 @@ const txRollback = "Rollback Transaction"
 func (vc *vcursorImpl) ExecuteMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error) {
 	noOfShards := len(rss)
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
+	vc.recordQueryShards(rss)
 	err := vc.markSavepoint(ctx, rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
 	if err != nil {
 		return nil, []error{err}
 	}
 
 	qr, errs := vc.executor.ExecuteMultiShard(ctx, primitive, rss, commentedShardQueries(queries, vc.marginComments), vc.safeSession, canAutocommit, vc.ignoreMaxMemoryRows)
+	if noOfShards > 1 {
+		errs = vc.aggregateVersionConflicts(qr, errs)
+	}
 	vc.setRollbackOnPartialExecIfRequired(len(errs) != len(rss), rollbackOnError)
 
 	return qr, errs
 }
 
+// aggregateVersionConflicts decides on the version conflict errors of a DML
+// sent to several shards. Each tablet only checks the rows it affected, so the
+// statement conflicts only when none of the shards affected a row.
+func (vc *vcursorImpl) aggregateVersionConflicts(qr *sqltypes.Result, errs []error) []error {
+	if len(errs) == 0 || !vc.safeSession.GetOptions().GetVersionConflictError() {
+		return errs
+	}
+	for _, err := range errs {
+		if sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number() != sqlerror.ERVersionConflict {
+			return errs
+		}
+	}
+	if qr != nil && qr.RowsAffected > 0 {
+		return nil
+	}
+	return errs[:1]
+}
+
 // StreamExecuteMulti is the streaming version of ExecuteMultiShard.
 func (vc *vcursorImpl) StreamExecuteMulti(ctx context.Context, primitive engine.Primitive, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
 	noOfShards := len(rss)
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfShards))
+	vc.recordQueryShards(rss)
 	err := vc.markSavepoint(ctx, rollbackOnError && (noOfShards > 1), map[string]*querypb.BindVariable{})
 	if err != nil {
 		return []error{err}
@@ -598,6 +645,14 @@ func (vc *vcursorImpl) StreamExecuteMulti(ctx context.Context, primitive engine.
 	return errs
 }
 
+// recordQueryShards records the shards targeted by the query, when the query
+// metrics have a shard dimension.
+func (vc *vcursorImpl) recordQueryShards(rss []*srvtopo.ResolvedShard) {
+	if vc.queryShard != nil {
+		vc.queryShard.add(rss)
+	}
+}
+
 // ExecuteLock is for executing advisory lock statements.
 func (vc *vcursorImpl) ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedShard, query *querypb.BoundQuery, lockFuncType sqlparser.LockingFuncType) (*sqltypes.Result, error) {
 	query.Sql = vc.marginComments.Leading + query.Sql + vc.marginComments.Trailing
@@ -607,6 +662,7 @@ func (vc *vcursorImpl) ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedShar
 // ExecuteStandalone is part of the engine.VCursor interface.
 func (vc *vcursorImpl) ExecuteStandalone(ctx context.Context, primitive engine.Primitive, query string, bindVars map[string]*querypb.BindVariable, rs *srvtopo.ResolvedShard) (*sqltypes.Result, error) {
 	rss := []*srvtopo.ResolvedShard{rs}
+	vc.recordQueryShards(rss)
 	bqs := []*querypb.BoundQuery{
 		{
 			Sql:           vc.marginComments.Leading + query + vc.marginComments.Trailing,
@@ -732,15 +788,20 @@ func (vc *vcursorImpl) Session() engine.SessionActions {
 }
 
 func (vc *vcursorImpl) SetTarget(target string) error {
-	keyspace, tabletType, _, err := topoprotopb.ParseDestination(target, defaultTabletType)
+	keyspace, tabletType, _, tabletAlias, err := topoprotopb.ParseDestinationWithTabletAlias(target, defaultTabletType)
 	if err != nil {
 		return err
 	}
-	if _, ok := vc.vschema.Keyspaces[keyspace]; !ignoreKeyspace(keyspace) && !ok {
+	// A session restricted to a tenant must not leave its database, which
+	// would lift the restriction.
+	if vc.tenant != nil && keyspace != vc.tenant.Database {
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "cannot change the database of tenant database %s to %s", vc.tenant.Database, keyspace)
+	}
+	if _, ok := vc.vschema.Keyspaces[keyspace]; !ignoreKeyspace(keyspace) && !ok && tenants.Lookup(keyspace) == nil {
 		return vterrors.VT05003(keyspace)
 	}
 
-	if vc.safeSession.InTransaction() && tabletType != topodatapb.TabletType_PRIMARY {
+	if vc.safeSession.InTransaction() && (tabletType != topodatapb.TabletType_PRIMARY || tabletAlias != nil) {
 		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.LockOrActiveTransaction, "can't execute the given command because you have an active transaction")
 	}
 	vc.safeSession.SetTargetString(target)
@@ -902,6 +963,34 @@ func (vc *vcursorImpl) SetPriority(priority string) {
 
 }
 
+// SetMaxStaleness sets the maximum replication lag, in milliseconds, of the
+// tablets executing the current query, or clears it if maxStaleness is 0.
+func (vc *vcursorImpl) SetMaxStaleness(maxStaleness int64) {
+	if maxStaleness > 0 {
+		vc.safeSession.GetOrCreateOptions().MaxStalenessMs = maxStaleness
+	} else if vc.safeSession.Options != nil && vc.safeSession.Options.MaxStalenessMs != 0 {
+		vc.safeSession.Options.MaxStalenessMs = 0
+	}
+}
+
+// SetPinnedTablet annotates the queries of a session pinned to a tablet with
+// the tablet alias, which routes them to the tablet, or clears it. The caller
+// must be authorized to pin its session.
+func (vc *vcursorImpl) SetPinnedTablet(caller *querypb.VTGateCallerID) error {
+	if vc.pinnedTablet == nil {
+		if vc.safeSession.Options != nil && vc.safeSession.Options.PinnedTabletAlias != nil {
+			vc.safeSession.Options.PinnedTabletAlias = nil
+		}
+		return nil
+	}
+	if err := authorizeTabletPinning(caller, vc.pinnedTablet); err != nil {
+		return err
+	}
+	vc.safeSession.GetOrCreateOptions().PinnedTabletAlias = vc.pinnedTablet
+	pinnedQueries.Add(topoprotopb.TabletAliasString(vc.pinnedTablet), 1)
+	return nil
+}
+
 // SetConsolidator implements the SessionActions interface
 func (vc *vcursorImpl) SetConsolidator(consolidator querypb.ExecuteOptions_Consolidator) {
 	// Avoid creating session Options when they do not yet exist and the
@@ -934,6 +1023,11 @@ func (vc *vcursorImpl) GetDDLStrategy() string {
 	return vc.safeSession.GetDDLStrategy()
 }
 
+// ImportSession implements the SessionActions interface
+func (vc *vcursorImpl) ImportSession(encoded string) error {
+	return vc.safeSession.Import(encoded)
+}
+
 // SetMigrationContext implements the SessionActions interface
 func (vc *vcursorImpl) SetMigrationContext(migrationContext string) {
 	vc.safeSession.SetMigrationContext(migrationContext)
@@ -1080,15 +1174,27 @@ func (vc *vcursorImpl) GetAggregateUDFs() []string {
 }
 
 // ParseDestinationTarget parses destination target string and sets default keyspace if possible.
-func parseDestinationTarget(targetString string, vschema *vindexes.VSchema) (string, topodatapb.TabletType, key.Destination, error) {
-	destKeyspace, destTabletType, dest, err := topoprotopb.ParseDestination(targetString, defaultTabletType)
+func parseDestinationTarget(targetString string, vschema *vindexes.VSchema) (string, topodatapb.TabletType, key.Destination, *topodatapb.TabletAlias, error) {
+	destKeyspace, destTabletType, dest, tabletAlias, err := topoprotopb.ParseDestinationWithTabletAlias(targetString, defaultTabletType)
 	// Set default keyspace
 	if destKeyspace == "" && len(vschema.Keyspaces) == 1 {
 		for k := range vschema.Keyspaces {
 			destKeyspace = k
 		}
 	}
-	return destKeyspace, destTabletType, dest, err
+	return destKeyspace, destTabletType, dest, tabletAlias, err
+}
+
+// routeToReplica returns whether the statement of a session targeting the given
+// tablet type by default can run on a replica, because it's part of a read-only
+// transaction.
+func routeToReplica(safeSession *SafeSession, tabletType topodatapb.TabletType) bool {
+	if tabletType != topodatapb.TabletType_PRIMARY || strings.Contains(safeSession.TargetString, "@") {
+		// the session targets a tablet type explicitly
+		return false
+	}
+	// reserved connections are held on the primary
+	return safeSession.InReadOnlyTransaction() && !safeSession.InReservedConn()
 }
 
 func (vc *vcursorImpl) keyForPlan(ctx context.Context, query string, buf io.StringWriter) {
@@ -1180,12 +1286,16 @@ func (vc *vcursorImpl) ShowExec(ctx context.Context, command sqlparser.ShowComma
 	switch command {
 	case sqlparser.VitessReplicationStatus:
 		return vc.executor.showVitessReplicationStatus(ctx, filter)
+	case sqlparser.VitessSession:
+		return showVitessSession(vc.safeSession)
 	case sqlparser.VitessShards:
 		return vc.executor.showShards(ctx, filter, vc.tabletType)
 	case sqlparser.VitessTablets:
 		return vc.executor.showTablets(filter)
 	case sqlparser.VitessVariables:
 		return vc.executor.showVitessMetadata(ctx, filter)
+	case sqlparser.VitessPlans:
+		return vc.executor.showVitessPlans(filter)
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "bug: unexpected show command: %v", command)
 	}
@@ -1278,6 +1388,7 @@ func (vc *vcursorImpl) cloneWithAutocommitSession() *vcursorImpl {
 		topoServer:      vc.topoServer,
 		warnShardedOnly: vc.warnShardedOnly,
 		pv:              vc.pv,
+		queryShard:      vc.queryShard,
 	}
 }
 
@@ -1296,6 +1407,15 @@ func (vc *vcursorImpl) IsViewsEnabled() bool {
 	return enableViews
 }
 
+func (vc *vcursorImpl) IsSchemaTrackerMetadataEnabled() bool {
+	return schemaTrackerMetadataMaxStaleness > 0
+}
+
+// TrackedSchema implements the VCursor interface
+func (vc *vcursorImpl) TrackedSchema(keyspace string) (map[string]*vindexes.TableInfo, map[string]sqlparser.SelectStatement, bool) {
+	return vc.executor.trackedSchema(keyspace)
+}
+
 func (vc *vcursorImpl) GetUDV(name string) *querypb.BindVariable {
 	return vc.safeSession.GetUDV(name)
 }
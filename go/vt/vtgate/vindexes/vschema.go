@@ -58,6 +58,10 @@ const (
 	TypeTable     = ""
 	TypeSequence  = "sequence"
 	TypeReference = "reference"
+	// TypeExternalReference is a reference to a table of another keyspace
+	// which, unlike a reference table, is not copied into the keyspace.
+	// Queries are routed to the source table as if by a routing rule.
+	TypeExternalReference = "external_reference"
 )
 
 // VSchema represents the denormalized version of SrvVSchema,
@@ -128,6 +132,12 @@ type Table struct {
 	// MySQL error message: ERROR 3756 (HY000): The primary key cannot be a functional index
 	PrimaryKey sqlparser.Columns `json:"primary_key,omitempty"`
 	UniqueKeys []sqlparser.Exprs `json:"unique_keys,omitempty"`
+
+	// Partitions are the names of the MySQL partitions and subpartitions of the table, as tracked from its schema.
+	Partitions []sqlparser.IdentifierCI `json:"partitions,omitempty"`
+
+	// ReadCellPreferences order the cells whose replicas serve the reads of the table, per cell or region of vtgate.
+	ReadCellPreferences []*vschemapb.ReadCellPreference `json:"read_cell_preferences,omitempty"`
 }
 
 // GetTableName gets the sqlparser.TableName for the vindex Table.
@@ -159,6 +169,10 @@ type TableInfo struct {
 	Columns     []Column
 	ForeignKeys []*sqlparser.ForeignKeyDefinition
 	Indexes     []*sqlparser.IndexDefinition
+	Partitions  []sqlparser.IdentifierCI
+	// ColumnDefinitions are the definitions of the columns the table was
+	// created with, which describe the table to the metadata queries.
+	ColumnDefinitions []*sqlparser.ColumnDefinition
 }
 
 // IsUnique is used to tell whether the ColumnVindex
@@ -245,6 +259,9 @@ type KeyspaceSchema struct {
 	Views           map[string]sqlparser.SelectStatement
 	Error           error
 	MultiTenantSpec *vschemapb.MultiTenantSpec
+	// TabletTags restricts the REPLICA and RDONLY tablets the queries of the
+	// keyspace are routed to, to the ones carrying all of these tags.
+	TabletTags map[string]string
 
 	// These are the UDFs that exist in the schema and are aggregations
 	AggregateUDFs []string
@@ -258,6 +275,7 @@ type ksJSON struct {
 	Views           map[string]string          `json:"views,omitempty"`
 	Error           string                     `json:"error,omitempty"`
 	MultiTenantSpec *vschemapb.MultiTenantSpec `json:"multi_tenant_spec,omitempty"`
+	TabletTags      map[string]string          `json:"tablet_tags,omitempty"`
 }
 
 // findTable looks for the table with the requested tablename in the keyspace.
@@ -291,6 +309,7 @@ func (ks *KeyspaceSchema) MarshalJSON() ([]byte, error) {
 		ForeignKeyMode:  ks.ForeignKeyMode.String(),
 		Vindexes:        ks.Vindexes,
 		MultiTenantSpec: ks.MultiTenantSpec,
+		TabletTags:      ks.TabletTags,
 	}
 	if ks.Error != nil {
 		ksJ.Error = ks.Error.Error()
@@ -336,6 +355,9 @@ func BuildVSchema(source *vschemapb.SrvVSchema, parser *sqlparser.Parser) (vsche
 	buildGlobalTables(source, vschema)
 	buildReferences(source, vschema)
 	buildRoutingRule(source, vschema, parser)
+	// buildExternalReferenceRoutingRules after buildRoutingRule so that explicit
+	// routing rules take precedence.
+	buildExternalReferenceRoutingRules(source, vschema)
 	buildShardRoutingRule(source, vschema)
 	buildKeyspaceRoutingRule(source, vschema)
 	// Resolve auto-increments after routing rules are built since sequence tables also obey routing rules.
@@ -382,6 +404,7 @@ func buildKeyspaces(source *vschemapb.SrvVSchema, vschema *VSchema, parser *sqlp
 			Tables:          make(map[string]*Table),
 			Vindexes:        make(map[string]Vindex),
 			MultiTenantSpec: ks.MultiTenantSpec,
+			TabletTags:      ks.TabletTags,
 		}
 		vschema.Keyspaces[ksname] = ksvschema
 		ksvschema.Error = buildTables(ks, vschema, ksvschema, parser)
@@ -451,6 +474,10 @@ func buildGlobalTables(source *vschemapb.SrvVSchema, vschema *VSchema) {
 
 func buildKeyspaceGlobalTables(vschema *VSchema, ksvschema *KeyspaceSchema) {
 	for tname, t := range ksvschema.Tables {
+		// External references are globally routable through their source.
+		if t.Type == TypeExternalReference {
+			continue
+		}
 		if gt, ok := vschema.globalTables[tname]; ok {
 			// There is already an entry table stored in global tables
 			// with this name.
@@ -496,7 +523,7 @@ func buildKeyspaceReferences(vschema *VSchema, ksvschema *KeyspaceSchema) error
 	for tname, t := range ksvschema.Tables {
 		source := t.Source
 
-		if t.Type != TypeReference || source == nil {
+		if !(t.Type == TypeReference || t.Type == TypeExternalReference) || source == nil {
 			continue
 		}
 
@@ -513,6 +540,18 @@ func buildKeyspaceReferences(vschema *VSchema, ksvschema *KeyspaceSchema) error
 			)
 		}
 
+		// Queries on external references are routed to the source keyspace,
+		// which must be usable.
+		if sourceKs := vschema.Keyspaces[sourceKsname]; t.Type == TypeExternalReference && sourceKs != nil && sourceKs.Error != nil {
+			return vterrors.Errorf(
+				vtrpcpb.Code_FAILED_PRECONDITION,
+				"source %q references keyspace %q, whose VSchema is invalid: %v",
+				source,
+				sourceKsname,
+				sourceKs.Error,
+			)
+		}
+
 		// Verify the reference source can be resolved.
 		sourceT, err := vschema.findTable(
 			sourceKsname,
@@ -552,6 +591,12 @@ func buildKeyspaceReferences(vschema *VSchema, ksvschema *KeyspaceSchema) error
 			)
 		}
 
+		// External references have no copy of the source in the keyspace
+		// for the planner to use.
+		if t.Type == TypeExternalReference {
+			continue
+		}
+
 		// Update inverse reference table map.
 		if ot := sourceT.getReferenceInKeyspace(keyspace.Name); ot != nil {
 			names := []string{ot.Name.String(), tname}
@@ -627,6 +672,25 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 				t.Source = &Source{TableName: tableName}
 			}
 			t.Type = table.Type
+		case TypeExternalReference:
+			tableName, err := parseTable(table.Source)
+			if err != nil || tableName.Qualifier.IsEmpty() {
+				return vterrors.Errorf(
+					vtrpcpb.Code_INVALID_ARGUMENT,
+					"external reference table %s must have a keyspace-qualified source: %q",
+					tname,
+					table.Source,
+				)
+			}
+			if table.Pinned != "" || len(table.ColumnVindexes) > 0 || table.AutoIncrement != nil {
+				return vterrors.Errorf(
+					vtrpcpb.Code_INVALID_ARGUMENT,
+					"external reference table %s may not have vindexes, a pin or an auto-increment",
+					tname,
+				)
+			}
+			t.Source = &Source{TableName: tableName}
+			t.Type = table.Type
 		case TypeSequence:
 			if keyspace.Sharded && table.Pinned == "" {
 				return vterrors.Errorf(
@@ -655,8 +719,31 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 			t.Pinned = decoded
 		}
 
+		// Validate the read cell preferences.
+		clientCells := make(map[string]bool)
+		for _, pref := range table.ReadCellPreferences {
+			if clientCells[pref.ClientCell] {
+				return vterrors.Errorf(
+					vtrpcpb.Code_INVALID_ARGUMENT,
+					"duplicate read cell preference for client cell '%s' for table: %s",
+					pref.ClientCell,
+					tname,
+				)
+			}
+			if len(pref.Cells) == 0 {
+				return vterrors.Errorf(
+					vtrpcpb.Code_INVALID_ARGUMENT,
+					"read cell preference for client cell '%s' must list at least one cell for table: %s",
+					pref.ClientCell,
+					tname,
+				)
+			}
+			clientCells[pref.ClientCell] = true
+		}
+		t.ReadCellPreferences = table.ReadCellPreferences
+
 		// If keyspace is sharded, then any table that's not a reference or pinned must have vindexes.
-		if keyspace.Sharded && t.Type != TypeReference && table.Pinned == "" && len(table.ColumnVindexes) == 0 {
+		if keyspace.Sharded && t.Type != TypeReference && t.Type != TypeExternalReference && table.Pinned == "" && len(table.ColumnVindexes) == 0 {
 			return vterrors.Errorf(
 				vtrpcpb.Code_NOT_FOUND,
 				"missing primary col vindex for table: %s",
@@ -928,6 +1015,32 @@ func parseTable(tableName string) (sqlparser.TableName, error) {
 	}, nil
 }
 
+// buildExternalReferenceRoutingRules routes the external reference tables to
+// their source, unless a routing rule already exists for them.
+func buildExternalReferenceRoutingRules(source *vschemapb.SrvVSchema, vschema *VSchema) {
+	for ksname := range source.Keyspaces {
+		ksvschema := vschema.Keyspaces[ksname]
+		if ksvschema.Error != nil {
+			continue
+		}
+		for tname, t := range ksvschema.Tables {
+			if t.Type != TypeExternalReference {
+				continue
+			}
+			fromTable := ksname + "." + tname
+			if _, ok := vschema.RoutingRules[fromTable]; ok {
+				continue
+			}
+			sourceT, err := vschema.findTable(t.Source.Qualifier.String(), t.Source.Name.String(), false /* constructUnshardedIfNotFound */)
+			if err != nil || sourceT == nil {
+				// buildReferences reports the error in the keyspace.
+				continue
+			}
+			vschema.RoutingRules[fromTable] = &RoutingRule{Tables: []*Table{sourceT}}
+		}
+	}
+}
+
 func buildRoutingRule(source *vschemapb.SrvVSchema, vschema *VSchema, parser *sqlparser.Parser) {
 	var err error
 	if source.RoutingRules == nil {
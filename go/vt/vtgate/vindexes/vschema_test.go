@@ -1688,6 +1688,50 @@ func TestBuildVSchemaNoindexFail(t *testing.T) {
 	}
 }
 
+func TestBuildVSchemaReadCellPreferences(t *testing.T) {
+	prefs := []*vschemapb.ReadCellPreference{
+		{ClientCell: "us-east", Cells: []string{"us-east", "us-west"}},
+		{Cells: []string{"us-west"}},
+	}
+	srvVSchema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"unsharded": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {ReadCellPreferences: prefs},
+					"t2": {},
+				},
+			},
+		},
+	}
+	vschema := BuildVSchema(srvVSchema, sqlparser.NewTestParser())
+	ks := vschema.Keyspaces["unsharded"]
+	require.NoError(t, ks.Error)
+	assert.Equal(t, prefs, ks.Tables["t1"].ReadCellPreferences)
+	assert.Empty(t, ks.Tables["t2"].ReadCellPreferences)
+
+	tcs := []struct {
+		prefs []*vschemapb.ReadCellPreference
+		err   string
+	}{
+		{
+			prefs: []*vschemapb.ReadCellPreference{
+				{ClientCell: "us-east", Cells: []string{"us-east"}},
+				{ClientCell: "us-east", Cells: []string{"us-west"}},
+			},
+			err: "duplicate read cell preference for client cell 'us-east' for table: t1",
+		},
+		{
+			prefs: []*vschemapb.ReadCellPreference{{ClientCell: "us-east"}},
+			err:   "read cell preference for client cell 'us-east' must list at least one cell for table: t1",
+		},
+	}
+	for _, tc := range tcs {
+		srvVSchema.Keyspaces["unsharded"].Tables["t1"].ReadCellPreferences = tc.prefs
+		vschema := BuildVSchema(srvVSchema, sqlparser.NewTestParser())
+		assert.EqualError(t, vschema.Keyspaces["unsharded"].Error, tc.err)
+	}
+}
+
 func TestBuildVSchemaColumnAndColumnsFail(t *testing.T) {
 	bad := vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
@@ -3139,6 +3183,135 @@ func TestOtherTablesMakeReferenceTableAndSourceAmbiguous(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestExternalReferenceTableRoutesToSource(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"unsharded": {
+				Sharded: false,
+				Tables: map[string]*vschemapb.Table{
+					"src": {},
+				},
+			},
+			"sharded": {
+				Sharded: true,
+				Tables: map[string]*vschemapb.Table{
+					"ref": {
+						Type:   "external_reference",
+						Source: "unsharded.src",
+					},
+				},
+			},
+		},
+	}
+	vs := BuildVSchema(&input, sqlparser.NewTestParser())
+	require.NoError(t, vs.Keyspaces["unsharded"].Error)
+	require.NoError(t, vs.Keyspaces["sharded"].Error)
+
+	src, err := vs.FindTable("unsharded", "src")
+	require.NoError(t, err)
+	assert.Empty(t, src.ReferencedBy)
+
+	ref, err := vs.FindRoutedTable("sharded", "ref", topodatapb.TabletType_PRIMARY)
+	require.NoError(t, err)
+	assert.Equal(t, src, ref)
+
+	// The external reference is not globally routable.
+	_, err = vs.FindTable("", "ref")
+	require.EqualError(t, err, "table ref not found")
+
+	// Explicit routing rules take precedence.
+	input.RoutingRules = &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{
+			FromTable: "sharded.ref",
+			ToTables:  []string{"unsharded.other"},
+		}},
+	}
+	input.Keyspaces["unsharded"].Tables["other"] = &vschemapb.Table{}
+	vs = BuildVSchema(&input, sqlparser.NewTestParser())
+	other, err := vs.FindTable("unsharded", "other")
+	require.NoError(t, err)
+	ref, err = vs.FindRoutedTable("sharded", "ref", topodatapb.TabletType_PRIMARY)
+	require.NoError(t, err)
+	assert.Equal(t, other, ref)
+}
+
+func TestExternalReferenceTableValidation(t *testing.T) {
+	tcs := []struct {
+		name  string
+		table *vschemapb.Table
+		err   string
+	}{{
+		name:  "missing source",
+		table: &vschemapb.Table{Type: "external_reference"},
+		err:   "external reference table ref must have a keyspace-qualified source: \"\"",
+	}, {
+		name:  "unqualified source",
+		table: &vschemapb.Table{Type: "external_reference", Source: "src"},
+		err:   "external reference table ref must have a keyspace-qualified source: \"src\"",
+	}, {
+		name:  "same keyspace",
+		table: &vschemapb.Table{Type: "external_reference", Source: "sharded.ref"},
+		err:   "source \"sharded.ref\" may not reference a table in the same keyspace as table: ref",
+	}, {
+		name:  "missing keyspace",
+		table: &vschemapb.Table{Type: "external_reference", Source: "foo.src"},
+		err:   "source \"foo.src\" references a non-existent keyspace \"foo\"",
+	}, {
+		name:  "invalid keyspace",
+		table: &vschemapb.Table{Type: "external_reference", Source: "invalid.src"},
+		err:   "source \"invalid.src\" references keyspace \"invalid\", whose VSchema is invalid: vindexType \"unknown\" not found",
+	}, {
+		name:  "missing table",
+		table: &vschemapb.Table{Type: "external_reference", Source: "unsharded.foo"},
+		err:   "source \"unsharded.foo\" references a table \"foo\" that is not present in the VSchema of keyspace \"unsharded\"",
+	}, {
+		name:  "sequence source",
+		table: &vschemapb.Table{Type: "external_reference", Source: "unsharded.seq"},
+		err:   "source \"unsharded.seq\" may not reference a table of type \"sequence\": ref",
+	}, {
+		name: "vindexes",
+		table: &vschemapb.Table{
+			Type:           "external_reference",
+			Source:         "unsharded.src",
+			ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+		},
+		err: "external reference table ref may not have vindexes, a pin or an auto-increment",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			input := vschemapb.SrvVSchema{
+				Keyspaces: map[string]*vschemapb.Keyspace{
+					"unsharded": {
+						Tables: map[string]*vschemapb.Table{
+							"src": {},
+							"seq": {Type: "sequence"},
+						},
+					},
+					"invalid": {
+						Vindexes: map[string]*vschemapb.Vindex{
+							"bad": {Type: "unknown"},
+						},
+						Tables: map[string]*vschemapb.Table{
+							"src": {},
+						},
+					},
+					"sharded": {
+						Sharded: true,
+						Vindexes: map[string]*vschemapb.Vindex{
+							"hash": {Type: "binary_md5"},
+						},
+						Tables: map[string]*vschemapb.Table{
+							"ref": tc.table,
+						},
+					},
+				},
+			}
+			vs := BuildVSchema(&input, sqlparser.NewTestParser())
+			require.EqualError(t, vs.Keyspaces["sharded"].Error, tc.err)
+		})
+	}
+}
+
 // TestFindTableWithSequences tests tables with an autoincrement column that are associated with a sequence.
 // It validates that sequences obey routing rules, which might be set, for example, during a MoveTables
 // when sequence tables are being migrated to a new cluster.
@@ -19,6 +19,7 @@ package vtgate
 import (
 	"context"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/vt/graph"
 	"vitess.io/vitess/go/vt/log"
@@ -53,6 +54,9 @@ type SchemaInfo interface {
 	Tables(ks string) map[string]*vindexes.TableInfo
 	Views(ks string) map[string]sqlparser.SelectStatement
 	UDFs(ks string) []string
+	// LastSynced returns when the schema of the keyspace was last known to
+	// be up to date, or false if it is not.
+	LastSynced(ks string) (time.Time, bool)
 }
 
 // GetCurrentSrvVschema returns a copy of the latest SrvVschema from the
@@ -247,6 +251,7 @@ func (vm *VSchemaManager) updateTableInfo(vschema *vindexes.VSchema, ks *vindexe
 			rTbl.ParentForeignKeys = append(rTbl.ParentForeignKeys, vindexes.NewParentFkInfo(parentTbl, fkDef))
 			parentTbl.ChildForeignKeys = append(parentTbl.ChildForeignKeys, vindexes.NewChildFkInfo(rTbl, fkDef))
 		}
+		rTbl.Partitions = tblInfo.Partitions
 		for _, idxDef := range tblInfo.Indexes {
 			switch idxDef.Info.Type {
 			case sqlparser.IndexTypePrimary:
@@ -2,6 +2,7 @@ package vtgate
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -858,4 +859,6 @@ func (f *fakeSchema) Views(string) map[string]sqlparser.SelectStatement {
 }
 func (f *fakeSchema) UDFs(string) []string { return f.udfs }
 
+func (f *fakeSchema) LastSynced(string) (time.Time, bool) { return time.Time{}, false }
+
 var _ SchemaInfo = (*fakeSchema)(nil)
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The positions acknowledged by a consumer group are stored per shard, in the
+// sidecar database of the primary of the shard, so that they survive the
+// restarts of the vtgates and of the consumers, and follow the failovers.
+
+// VStreamAck persists the positions of the shards of vgtid as the positions
+// processed by the consumer group. The streams of the consumer group resume
+// from them, which gives at-least-once delivery: the events after the last
+// acknowledged position are streamed again after a reconnection.
+func (vsm *vstreamManager) VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error {
+	if consumerGroup == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "consumer group must be specified")
+	}
+	if len(vgtid.GetShardGtids()) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one position to acknowledge")
+	}
+	for _, sgtid := range vgtid.ShardGtids {
+		if sgtid.Keyspace == "" || sgtid.Shard == "" {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace and shard must be specified for the acknowledged positions: %v", sgtid)
+		}
+		if sgtid.Gtid == "" || sgtid.Gtid == "current" {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "acknowledged position of %s/%s must be a position received from the stream, got: %q", sgtid.Keyspace, sgtid.Shard, sgtid.Gtid)
+		}
+	}
+
+	var wg sync.WaitGroup
+	allErrors := new(concurrency.AllErrorRecorder)
+	for _, sgtid := range vgtid.ShardGtids {
+		wg.Add(1)
+		go func(sgtid *binlogdatapb.ShardGtid) {
+			defer wg.Done()
+			rs, err := vsm.resolvePrimary(ctx, sgtid)
+			if err == nil {
+				err = rs.Gateway.SaveVStreamPosition(ctx, rs.Target, consumerGroup, sgtid)
+			}
+			if err != nil {
+				allErrors.RecordError(vterrors.Wrapf(err, "failed to acknowledge the position of %s/%s for consumer group %s", sgtid.Keyspace, sgtid.Shard, consumerGroup))
+			}
+		}(sgtid)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
+
+// resumeConsumerGroup returns vgtid with the position of each shard replaced
+// by the one acknowledged by the consumer group, if any. The shards without an
+// acknowledged position start from the position of the request.
+func (vsm *vstreamManager) resumeConsumerGroup(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) (*binlogdatapb.VGtid, error) {
+	newvgtid := &binlogdatapb.VGtid{ShardGtids: make([]*binlogdatapb.ShardGtid, len(vgtid.ShardGtids))}
+	var wg sync.WaitGroup
+	allErrors := new(concurrency.AllErrorRecorder)
+	for i, sgtid := range vgtid.ShardGtids {
+		wg.Add(1)
+		go func(i int, sgtid *binlogdatapb.ShardGtid) {
+			defer wg.Done()
+			newvgtid.ShardGtids[i] = sgtid
+			rs, err := vsm.resolvePrimary(ctx, sgtid)
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			acked, err := rs.Gateway.ReadVStreamPosition(ctx, rs.Target, consumerGroup)
+			if err != nil {
+				allErrors.RecordError(vterrors.Wrapf(err, "failed to read the position of %s/%s for consumer group %s", sgtid.Keyspace, sgtid.Shard, consumerGroup))
+				return
+			}
+			if acked == nil {
+				return
+			}
+			log.Infof("VStream of consumer group %s resumes %s/%s from its acknowledged position %s", consumerGroup, sgtid.Keyspace, sgtid.Shard, acked.Gtid)
+			newvgtid.ShardGtids[i] = &binlogdatapb.ShardGtid{
+				Keyspace: sgtid.Keyspace,
+				Shard:    sgtid.Shard,
+				Gtid:     acked.Gtid,
+				TablePKs: acked.TablePKs,
+			}
+		}(i, sgtid)
+	}
+	wg.Wait()
+	if allErrors.HasErrors() {
+		return nil, allErrors.AggrError(vterrors.Aggregate)
+	}
+	return newvgtid, nil
+}
+
+// resolvePrimary returns the primary of the shard of sgtid, which stores the
+// positions acknowledged for the shard.
+func (vsm *vstreamManager) resolvePrimary(ctx context.Context, sgtid *binlogdatapb.ShardGtid) (*srvtopo.ResolvedShard, error) {
+	rss, err := vsm.resolver.ResolveDestination(ctx, sgtid.Keyspace, topodatapb.TabletType_PRIMARY, key.DestinationShard(sgtid.Shard))
+	if err != nil {
+		return nil, err
+	}
+	if len(rss) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected number of shards for %s/%s: %d", sgtid.Keyspace, sgtid.Shard, len(rss))
+	}
+	return rss[0], nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/discovery"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestVStreamAck(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	cell := "aa"
+	ks := "TestVStreamAck"
+	_ = createSandbox(ks)
+	hc := discovery.NewFakeHealthCheck(nil)
+	st := getSandboxTopo(ctx, cell, ks, []string{"-20", "20-40"})
+	vsm := newTestVStreamManager(ctx, hc, st, cell)
+	sbc0 := hc.AddTestTablet(cell, "1.1.1.1", 1001, ks, "-20", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "-20", sbc0.Tablet())
+	sbc1 := hc.AddTestTablet(cell, "1.1.1.1", 1002, ks, "20-40", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "20-40", sbc1.Tablet())
+
+	err := vsm.VStreamAck(ctx, "", &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "gtid01"}}})
+	assert.ErrorContains(t, err, "consumer group must be specified")
+	err = vsm.VStreamAck(ctx, "cg1", &binlogdatapb.VGtid{})
+	assert.ErrorContains(t, err, "vgtid must have at least one position to acknowledge")
+	err = vsm.VStreamAck(ctx, "cg1", &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "current"}}})
+	assert.ErrorContains(t, err, "must be a position received from the stream")
+
+	// Only the first shard is acknowledged.
+	err = vsm.VStreamAck(ctx, "cg1", &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "gtid01"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "gtid01", sbc0.VStreamPositions["cg1"].Gtid)
+	assert.Empty(t, sbc1.VStreamPositions)
+
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{
+		{Keyspace: ks, Shard: "-20", Gtid: "pos0"},
+		{Keyspace: ks, Shard: "20-40", Gtid: "pos1"},
+	}}
+	got, err := vsm.resumeConsumerGroup(ctx, "cg1", vgtid)
+	require.NoError(t, err)
+	want := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{
+		{Keyspace: ks, Shard: "-20", Gtid: "gtid01"},
+		{Keyspace: ks, Shard: "20-40", Gtid: "pos1"},
+	}}
+	utils.MustMatch(t, want, got)
+
+	// Another consumer group starts from the positions of the request.
+	got, err = vsm.resumeConsumerGroup(ctx, "cg2", vgtid)
+	require.NoError(t, err)
+	utils.MustMatch(t, vgtid, got)
+
+	// The stream of the consumer group starts from its acknowledged position.
+	sbc0.StartPos = "gtid01"
+	sbc0.AddVStreamEvents([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_GTID, Gtid: "gtid02"},
+		{Type: binlogdatapb.VEventType_DDL},
+	}, nil)
+	ch := startVStream(ctx, t, vsm, &binlogdatapb.VGtid{ShardGtids: vgtid.ShardGtids[:1]}, &vtgatepb.VStreamFlags{ConsumerGroup: "cg1"})
+	verifyEvents(t, ch, &binlogdatapb.VStreamResponse{Events: []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "gtid02"}},
+		}},
+		{Type: binlogdatapb.VEventType_DDL},
+	}})
+}
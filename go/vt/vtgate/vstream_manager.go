@@ -154,6 +154,12 @@ func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.Ta
 	if err != nil {
 		return err
 	}
+	if consumerGroup := flags.GetConsumerGroup(); consumerGroup != "" {
+		vgtid, err = vsm.resumeConsumerGroup(ctx, consumerGroup, vgtid)
+		if err != nil {
+			return err
+		}
+	}
 	ts, err := vsm.toposerv.GetTopoServer()
 	if err != nil {
 		return err
@@ -182,6 +188,7 @@ func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.Ta
 		tabletPickerOptions: discovery.TabletPickerOptions{
 			CellPreference: flags.GetCellPreference(),
 			TabletOrder:    flags.GetTabletOrder(),
+			Preferences:    flags.GetTabletPickerPreferences(),
 		},
 	}
 	return vs.stream(ctx)
@@ -253,7 +260,9 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "if shards are unspecified, the Gtid value must be 'current' or empty; got: %v", vgtid)
 			}
 			// TODO(sougou): this should work with the new Migrate workflow
-			_, _, allShards, err := vsm.resolver.GetKeyspaceShards(ctx, sgtid.Keyspace, tabletType)
+			// The streams of the shards of a keyspace being resharded must not
+			// be started from a stale SrvKeyspace.
+			_, _, allShards, err := vsm.resolver.GetKeyspaceShards(srvtopo.WithMustRevalidate(ctx), sgtid.Keyspace, tabletType)
 			if err != nil {
 				return nil, nil, nil, err
 			}
@@ -533,12 +542,14 @@ func (vs *vstream) streamFromTablet(ctx context.Context, sgtid *binlogdatapb.Sha
 			return tabletPickerErr(err)
 		}
 		log.Infof("Picked a %s tablet for VStream in %s/%s within the %s cell(s)",
-			vs.tabletType.String(), sgtid.GetKeyspace(), sgtid.GetShard(), strings.Join(cells, ","))
+			tablet.Type.String(), sgtid.GetKeyspace(), sgtid.GetShard(), strings.Join(cells, ","))
 
+		// The type of the tablet is the one of the request, unless the tablet
+		// picker preferences of the request gave another one.
 		target := &querypb.Target{
 			Keyspace:   sgtid.Keyspace,
 			Shard:      sgtid.Shard,
-			TabletType: vs.tabletType,
+			TabletType: tablet.Type,
 			Cell:       vs.vsm.cell,
 		}
 		tabletConn, err := vs.vsm.resolver.GetGateway().QueryServiceByAlias(ctx, tablet.Alias, target)
@@ -556,9 +567,9 @@ func (vs *vstream) streamFromTablet(ctx context.Context, sgtid *binlogdatapb.Sha
 					err = fmt.Errorf("context has ended")
 				case shr == nil || shr.RealtimeStats == nil || shr.Target == nil:
 					err = fmt.Errorf("health check failed on %s", topoproto.TabletAliasString(tablet.Alias))
-				case vs.tabletType != shr.Target.TabletType:
+				case tablet.Type != shr.Target.TabletType:
 					err = fmt.Errorf("tablet %s type has changed from %s to %s, restarting vstream",
-						topoproto.TabletAliasString(tablet.Alias), vs.tabletType, shr.Target.TabletType)
+						topoproto.TabletAliasString(tablet.Alias), tablet.Type, shr.Target.TabletType)
 				case shr.RealtimeStats.HealthError != "":
 					err = fmt.Errorf("tablet %s is no longer healthy: %s, restarting vstream",
 						topoproto.TabletAliasString(tablet.Alias), shr.RealtimeStats.HealthError)
@@ -36,6 +36,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -54,6 +55,8 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	vtschema "vitess.io/vitess/go/vt/vtgate/schema"
+	"vitess.io/vitess/go/vt/vtgate/statementacl"
+	"vitess.io/vitess/go/vt/vtgate/tenants"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 )
 
@@ -100,6 +103,10 @@ var (
 	enableSchemaChangeSignal = true
 	enableViews              bool
 	enableUdfs               bool
+	// schemaTrackerMetadataMaxStaleness, if set, makes vtgate answer the
+	// metadata queries of the tracked keyspaces from the tracked schema, as
+	// long as it was last known to be up to date within this duration.
+	schemaTrackerMetadataMaxStaleness time.Duration
 
 	// vtgate views flags
 	queryTimeout int
@@ -108,11 +115,20 @@ var (
 	queryLogToFile string
 	// queryLogBufferSize controls how many query logs will be buffered before dropping them if logging is not fast enough
 	queryLogBufferSize = 10
+	// queryLogResultChecksum controls whether a checksum of the results of queries is added to the query logs
+	queryLogResultChecksum bool
 
 	messageStreamGracePeriod = 30 * time.Second
 
 	// allowKillStmt to allow execution of kill statement.
 	allowKillStmt bool
+	// killStmtAdminUsers are the users allowed to kill the connections of
+	// the other users.
+	killStmtAdminUsers []string
+
+	// readOnlyTxOnReplicas routes the read-only transactions of sessions that
+	// don't target a tablet type to replicas.
+	readOnlyTxOnReplicas bool
 
 	warmingReadsPercent      = 0
 	warmingReadsQueryTimeout = 5 * time.Second
@@ -147,10 +163,18 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&queryTimeout, "query-timeout", queryTimeout, "Sets the default query timeout (in ms). Can be overridden by session variable (query_timeout) or comment directive (QUERY_TIMEOUT_MS)")
 	fs.StringVar(&queryLogToFile, "log_queries_to_file", queryLogToFile, "Enable query logging to the specified file")
 	fs.IntVar(&queryLogBufferSize, "querylog-buffer-size", queryLogBufferSize, "Maximum number of buffered query logs before throttling log output")
+	fs.StringSliceVar(&queryMetricsDimensions, "query-metrics-dimensions", queryMetricsDimensions, "Comma-separated list of the dimensions of the VtgateQueryCounts and VtgateQueryTimings metrics, among keyspace, shard and table. The dimensions which are not listed are reported as 'all'. The metrics are not recorded if empty.")
+	fs.StringSliceVar(&queryMetricsKeyspaces, "query-metrics-keyspaces", queryMetricsKeyspaces, "Comma-separated allow-list of the keyspaces reported by the VtgateQueryCounts and VtgateQueryTimings metrics. The queries on other keyspaces are reported as 'other'. All the keyspaces are reported if empty.")
+	fs.IntVar(&queryMetricsMaxValues, "query-metrics-max-values", queryMetricsMaxValues, "Maximum number of values of each dimension of the VtgateQueryCounts and VtgateQueryTimings metrics. Only the most frequent values are reported, the others as 'other'. 0 means no limit.")
+	fs.BoolVar(&queryLogResultChecksum, "querylog-result-checksum", queryLogResultChecksum, "Add a checksum of the rows returned by each query to the query logs, so that the queries can be replayed and their results compared with vtqueryreplay")
 	fs.DurationVar(&messageStreamGracePeriod, "message_stream_grace_period", messageStreamGracePeriod, "the amount of time to give for a vttablet to resume if it ends a message stream, usually because of a reparent.")
 	fs.BoolVar(&enableViews, "enable-views", enableViews, "Enable views support in vtgate.")
 	fs.BoolVar(&enableUdfs, "track-udfs", enableUdfs, "Track UDFs in vtgate.")
+	fs.DurationVar(&schemaTrackerMetadataMaxStaleness, "schema-tracker-metadata-max-staleness", schemaTrackerMetadataMaxStaleness, "If set, the SHOW COLUMNS queries, and the SHOW TABLES queries when --enable-views is set, of the keyspaces tracked by the schema tracker are answered by vtgate from the tracked schema, as long as it was last known to be up to date within this duration, and are sent to a tablet otherwise. Requires --schema_change_signal.")
+	fs.BoolVar(&enablePlanCacheControl, "enable-plan-cache-control", enablePlanCacheControl, "If set, apply the PlanCacheControl stored in the global topo: keep the plans of its pinned queries across cache evictions and vschema or schema changes, and invalidate the plans of the tables of its invalidations.")
 	fs.BoolVar(&allowKillStmt, "allow-kill-statement", allowKillStmt, "Allows the execution of kill statement")
+	fs.StringSliceVar(&killStmtAdminUsers, "kill-statement-admin-users", killStmtAdminUsers, "Comma-separated list of the users allowed to kill the queries and connections of the other users with the kill statement. The users can always kill their own.")
+	fs.BoolVar(&readOnlyTxOnReplicas, "route-read-only-transactions-to-replicas", readOnlyTxOnReplicas, "Execute transactions started with START TRANSACTION READ ONLY on replicas instead of the primary, for sessions that don't target a tablet type.")
 	fs.IntVar(&warmingReadsPercent, "warming-reads-percent", 0, "Percentage of reads on the primary to forward to replicas. Useful for keeping buffer pools warm")
 	fs.IntVar(&warmingReadsConcurrency, "warming-reads-concurrency", 500, "Number of concurrent warming reads allowed")
 	fs.DurationVar(&warmingReadsQueryTimeout, "warming-reads-query-timeout", 5*time.Second, "Timeout of warming read queries")
@@ -190,6 +214,10 @@ var (
 
 	warnings = stats.NewCountersWithSingleLabel("VtGateWarnings", "Vtgate warnings", "type", "IgnoredSet", "NonAtomicCommit", "ResultsExceeded", "WarnPayloadSizeExceeded", "WarnUnshardedOnly")
 
+	commitModeTimings = stats.NewTimings("CommitModeTimings", "Vtgate commit timings per transaction mode", "Mode")
+
+	twoPCKeyspaceRejections = stats.NewCountersWithSingleLabel("TwoPCKeyspaceRejections", "Transactions with transaction_mode TWOPC rejected because a keyspace does not have 2PC enabled", "Keyspace")
+
 	vstreamSkewDelayCount = stats.NewCounter("VStreamEventsDelayedBySkewAlignment",
 		"Number of events that had to wait because the skew across shards was too high")
 
@@ -284,6 +312,11 @@ func Init(
 	tc := NewTxConn(gw, getTxMode())
 	// ScatterConn depends on TxConn to perform forced rollbacks.
 	sc := NewScatterConn("VttabletCall", tc, gw)
+	if deadlockDetectionInterval > 0 {
+		sc.deadlockDetector = newDeadlockDetector(gw, deadlockDetectionInterval)
+		sc.deadlockDetector.Open()
+		servenv.OnTerm(sc.deadlockDetector.Close)
+	}
 	srvResolver := srvtopo.NewResolver(serv, gw, cell)
 	resolver := NewResolver(srvResolver, serv, cell, sc)
 	vsm := newVStreamManager(srvResolver, serv, cell)
@@ -292,6 +325,8 @@ func Init(
 	if err != nil {
 		log.Fatalf("Unable to get Topo server: %v", err)
 	}
+	statementacl.Init(ctx, ts)
+	tenants.Init(ctx, ts)
 	// Create a global cache to use for lookups of the sidecar database
 	// identifier in use by each keyspace.
 	_, created := sidecardb.NewIdentifierCache(func(ctx context.Context, keyspace string) (string, error) {
@@ -336,6 +371,15 @@ func Init(
 		log.Fatalf("error initializing query logger: %v", err)
 	}
 
+	executor.queryMetrics, err = newQueryMetrics(queryMetricsDimensions, queryMetricsKeyspaces, queryMetricsMaxValues, queryCounts, queryTimings)
+	if err != nil {
+		log.Fatalf("Invalid value for --query-metrics-dimensions: %v", err)
+	}
+
+	if enablePlanCacheControl {
+		go executor.watchPlanCacheControl(ctx, ts, planCacheControlRetryDelay)
+	}
+
 	// connect the schema tracker with the vschema manager
 	if enableSchemaChangeSignal {
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
@@ -453,7 +497,8 @@ func (vtg *VTGate) Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Execute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	traceID, _ := trace.TraceID(ctx)
+	defer vtg.timings.RecordWithTraceID(statsKey, time.Now(), traceID)
 
 	if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -483,7 +528,8 @@ func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session,
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"ExecuteBatch", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	traceID, _ := trace.TraceID(ctx)
+	defer vtg.timings.RecordWithTraceID(statsKey, time.Now(), traceID)
 
 	for _, bindVariables := range bindVariablesList {
 		if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
@@ -513,7 +559,8 @@ func (vtg *VTGate) StreamExecute(ctx context.Context, mysqlCtx vtgateservice.MyS
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"StreamExecute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
 
-	defer vtg.timings.Record(statsKey, time.Now())
+	traceID, _ := trace.TraceID(ctx)
+	defer vtg.timings.RecordWithTraceID(statsKey, time.Now(), traceID)
 
 	safeSession := NewSafeSession(session)
 	var err error
@@ -562,7 +609,8 @@ func (vtg *VTGate) Prepare(ctx context.Context, session *vtgatepb.Session, sql s
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Prepare", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	traceID, _ := trace.TraceID(ctx)
+	defer vtg.timings.RecordWithTraceID(statsKey, time.Now(), traceID)
 
 	if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -589,6 +637,11 @@ func (vtg *VTGate) VStream(ctx context.Context, tabletType topodatapb.TabletType
 	return vtg.vsm.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+// VStreamAck persists the positions processed by a VStream consumer group.
+func (vtg *VTGate) VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error {
+	return vtg.vsm.VStreamAck(ctx, consumerGroup, vgtid)
+}
+
 // GetGatewayCacheStatus returns a displayable version of the Gateway cache.
 func (vtg *VTGate) GetGatewayCacheStatus() TabletCacheStatusList {
 	return vtg.gw.CacheStatus()
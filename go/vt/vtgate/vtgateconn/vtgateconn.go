@@ -111,6 +111,12 @@ func (conn *VTGateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	return conn.impl.VStream(ctx, tabletType, vgtid, filter, flags)
 }
 
+// VStreamAck acknowledges the positions processed by a consumer group, from
+// which the VStreams started with the same consumer group resume.
+func (conn *VTGateConn) VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error {
+	return conn.impl.VStreamAck(ctx, consumerGroup, vgtid)
+}
+
 // VTGateSession exposes the Vitess Execution API to the clients.
 // The object maintains client-side state and is comparable to a native MySQL connection.
 // For example, if you enable autocommit on a Session object, all subsequent calls will respect this.
@@ -184,6 +190,9 @@ type Impl interface {
 	// VStream streams binlogevents
 	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (VStreamReader, error)
 
+	// VStreamAck acknowledges the positions processed by a consumer group.
+	VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error
+
 	// Close must be called for releasing resources.
 	Close()
 }
@@ -47,6 +47,9 @@ type VTGateService interface {
 
 	// Update Stream methods
 	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func([]*binlogdatapb.VEvent) error) error
+	// VStreamAck persists the positions processed by a consumer group,
+	// from which the VStreams of the consumer group resume.
+	VStreamAck(ctx context.Context, consumerGroup string, vgtid *binlogdatapb.VGtid) error
 
 	// HandlePanic should be called with defer at the beginning of each
 	// RPC implementation method, before calling any of the previous methods
@@ -57,7 +60,7 @@ type VTGateService interface {
 // This is used by vtgate executor to execute kill queries.
 type MySQLConnection interface {
 	// KillQuery stops the an executing query on the connection.
-	KillQuery(uint32) error
+	KillQuery(context.Context, uint32) error
 	// KillConnection closes the connection and also stops any executing query on it.
 	KillConnection(context.Context, uint32) error
 }
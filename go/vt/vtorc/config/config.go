@@ -20,11 +20,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var configurationLoaded = make(chan bool)
@@ -50,6 +56,7 @@ var (
 	auditToBackend                 = false
 	auditToSyslog                  = false
 	auditPurgeDuration             = 7 * 24 * time.Hour // Equivalent of 7 days
+	recoveryHistoryRetention       = 0 * time.Hour
 	recoveryPeriodBlockDuration    = 30 * time.Second
 	preventCrossCellFailover       = false
 	waitReplicasTimeout            = 30 * time.Second
@@ -58,6 +65,11 @@ var (
 	recoveryPollDuration           = 1 * time.Second
 	ersEnabled                     = true
 	convertTabletsWithErrantGTIDs  = false
+	tabletJanitorThreshold         = 0 * time.Hour
+	tabletJanitorQuarantine        = 1 * time.Hour
+	tabletJanitorDryRun            = false
+	tabletTypeTargets              TabletTypeTargets
+	tabletTypeBalancerDryRun       = false
 )
 
 // RegisterFlags registers the flags required by VTOrc
@@ -70,6 +82,7 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&auditToBackend, "audit-to-backend", auditToBackend, "Whether to store the audit log in the VTOrc database")
 	fs.BoolVar(&auditToSyslog, "audit-to-syslog", auditToSyslog, "Whether to store the audit log in the syslog")
 	fs.DurationVar(&auditPurgeDuration, "audit-purge-duration", auditPurgeDuration, "Duration for which audit logs are held before being purged. Should be in multiples of days")
+	fs.DurationVar(&recoveryHistoryRetention, "recovery-history-retention", recoveryHistoryRetention, "Duration for which the history of the detections and recoveries is held before being purged. Should be in multiples of days. Defaults to --audit-purge-duration")
 	fs.DurationVar(&recoveryPeriodBlockDuration, "recovery-period-block-duration", recoveryPeriodBlockDuration, "Duration for which a new recovery is blocked on an instance after running a recovery")
 	fs.MarkDeprecated("recovery-period-block-duration", "As of v20 this is ignored and will be removed in a future release.")
 	fs.BoolVar(&preventCrossCellFailover, "prevent-cross-cell-failover", preventCrossCellFailover, "Prevent VTOrc from promoting a primary in a different cell than the current primary in case of a failover")
@@ -79,6 +92,83 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&recoveryPollDuration, "recovery-poll-duration", recoveryPollDuration, "Timer duration on which VTOrc polls its database to run a recovery")
 	fs.BoolVar(&ersEnabled, "allow-emergency-reparent", ersEnabled, "Whether VTOrc should be allowed to run emergency reparent operation when it detects a dead primary")
 	fs.BoolVar(&convertTabletsWithErrantGTIDs, "change-tablets-with-errant-gtid-to-drained", convertTabletsWithErrantGTIDs, "Whether VTOrc should be changing the type of tablets with errant GTIDs to DRAINED")
+	fs.DurationVar(&tabletJanitorThreshold, "tablet-janitor-threshold", tabletJanitorThreshold, "Duration for which a tablet must be unreachable before VTOrc quarantines its record, and later deletes it from the topology server. 0 disables the tablet janitor")
+	fs.DurationVar(&tabletJanitorQuarantine, "tablet-janitor-quarantine-duration", tabletJanitorQuarantine, "Duration for which the record of an unreachable tablet stays quarantined before the tablet janitor deletes it")
+	fs.BoolVar(&tabletJanitorDryRun, "tablet-janitor-dry-run", tabletJanitorDryRun, "Whether the tablet janitor only reports the tablet records it would delete, without deleting them")
+	fs.Var(&tabletTypeTargets, "tablet-type-targets", "Comma-separated list of the target number of REPLICA and RDONLY tablets of each shard, as tablet_type:count, or keyspace/tablet_type:count to override the target of a keyspace, e.g. replica:3,rdonly:2,commerce/rdonly:0. VTOrc changes the type of the REPLICA, RDONLY and SPARE tablets to reach them. Empty disables the tablet type balancer")
+	fs.BoolVar(&tabletTypeBalancerDryRun, "tablet-type-balancer-dry-run", tabletTypeBalancerDryRun, "Whether the tablet type balancer only reports the tablet type changes it would make, without making them")
+}
+
+// TabletTypeTargets is the target number of tablets of each type per shard,
+// keyed by the lower case tablet type, or keyspace/tablet type for the
+// targets of a keyspace. Only REPLICA and RDONLY have targets.
+type TabletTypeTargets map[string]int
+
+// Set is part of the pflag.Value interface.
+func (targets *TabletTypeTargets) Set(value string) error {
+	parsed := make(TabletTypeTargets)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, countStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return fmt.Errorf("invalid tablet type target %q, expected [keyspace/]tablet_type:count", pair)
+		}
+		keyspace, typeStr, hasKeyspace := strings.Cut(key, "/")
+		if !hasKeyspace {
+			keyspace, typeStr = "", key
+		}
+		tabletType, err := topoproto.ParseTabletType(typeStr)
+		if err != nil {
+			return err
+		}
+		if tabletType != topodatapb.TabletType_REPLICA && tabletType != topodatapb.TabletType_RDONLY {
+			return fmt.Errorf("invalid tablet type target %q: only REPLICA and RDONLY tablets have targets", pair)
+		}
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 0 {
+			return fmt.Errorf("invalid tablet type target %q: the count must be a non-negative integer", pair)
+		}
+		parsed[TabletTypeTargetKey(keyspace, tabletType)] = count
+	}
+	*targets = parsed
+	return nil
+}
+
+// String is part of the pflag.Value interface.
+func (targets *TabletTypeTargets) String() string {
+	pairs := make([]string, 0, len(*targets))
+	for key, count := range *targets {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", key, count))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (targets *TabletTypeTargets) Type() string {
+	return "string"
+}
+
+// Target returns the target number of tablets of the type in each shard of
+// the keyspace, if it has one.
+func (targets TabletTypeTargets) Target(keyspace string, tabletType topodatapb.TabletType) (int, bool) {
+	if count, ok := targets[TabletTypeTargetKey(keyspace, tabletType)]; ok {
+		return count, true
+	}
+	count, ok := targets[TabletTypeTargetKey("", tabletType)]
+	return count, ok
+}
+
+// TabletTypeTargetKey returns the key of the target of the tablet type in the
+// keyspace, or in all the keyspaces if keyspace is empty.
+func TabletTypeTargetKey(keyspace string, tabletType topodatapb.TabletType) string {
+	key := strings.ToLower(tabletType.String())
+	if keyspace != "" {
+		key = keyspace + "/" + key
+	}
+	return key
 }
 
 // Configuration makes for vtorc configuration input, which can be provided by user via JSON formatted file.
@@ -86,20 +176,26 @@ func RegisterFlags(fs *pflag.FlagSet) {
 // strictly expected from user.
 // TODO(sougou): change this to yaml parsing, and possible merge with tabletenv.
 type Configuration struct {
-	SQLite3DataFile                       string // full path to sqlite3 datafile
-	InstancePollSeconds                   uint   // Number of seconds between instance reads
-	SnapshotTopologiesIntervalHours       uint   // Interval in hour between snapshot-topologies invocation. Default: 0 (disabled)
-	ReasonableReplicationLagSeconds       int    // Above this value is considered a problem
-	AuditLogFile                          string // Name of log file for audit operations. Disabled when empty.
-	AuditToSyslog                         bool   // If true, audit messages are written to syslog
-	AuditToBackendDB                      bool   // If true, audit messages are written to the backend DB's `audit` table (default: true)
-	AuditPurgeDays                        uint   // Days after which audit entries are purged from the database
-	RecoveryPeriodBlockSeconds            int    // (overrides `RecoveryPeriodBlockMinutes`) The time for which an instance's recovery is kept "active", so as to avoid concurrent recoveries on smae instance as well as flapping
-	PreventCrossDataCenterPrimaryFailover bool   // When true (default: false), cross-DC primary failover are not allowed, vtorc will do all it can to only fail over within same DC, or else not fail over at all.
-	WaitReplicasTimeoutSeconds            int    // Timeout on amount of time to wait for the replicas in case of ERS. Should be a small value because we should fail-fast. Should not be larger than LockTimeout since that is the total time we use for an ERS.
-	TolerableReplicationLagSeconds        int    // Amount of replication lag that is considered acceptable for a tablet to be eligible for promotion when Vitess makes the choice of a new primary in PRS.
-	TopoInformationRefreshSeconds         int    // Timer duration on which VTOrc refreshes the keyspace and vttablet records from the topo-server.
-	RecoveryPollSeconds                   int    // Timer duration on which VTOrc recovery analysis runs
+	SQLite3DataFile                       string            // full path to sqlite3 datafile
+	InstancePollSeconds                   uint              // Number of seconds between instance reads
+	SnapshotTopologiesIntervalHours       uint              // Interval in hour between snapshot-topologies invocation. Default: 0 (disabled)
+	ReasonableReplicationLagSeconds       int               // Above this value is considered a problem
+	AuditLogFile                          string            // Name of log file for audit operations. Disabled when empty.
+	AuditToSyslog                         bool              // If true, audit messages are written to syslog
+	AuditToBackendDB                      bool              // If true, audit messages are written to the backend DB's `audit` table (default: true)
+	AuditPurgeDays                        uint              // Days after which audit entries are purged from the database
+	RecoveryHistoryRetentionDays          uint              // Days after which the detections and recoveries are purged from the database. Defaults to AuditPurgeDays when 0
+	RecoveryPeriodBlockSeconds            int               // (overrides `RecoveryPeriodBlockMinutes`) The time for which an instance's recovery is kept "active", so as to avoid concurrent recoveries on smae instance as well as flapping
+	PreventCrossDataCenterPrimaryFailover bool              // When true (default: false), cross-DC primary failover are not allowed, vtorc will do all it can to only fail over within same DC, or else not fail over at all.
+	WaitReplicasTimeoutSeconds            int               // Timeout on amount of time to wait for the replicas in case of ERS. Should be a small value because we should fail-fast. Should not be larger than LockTimeout since that is the total time we use for an ERS.
+	TolerableReplicationLagSeconds        int               // Amount of replication lag that is considered acceptable for a tablet to be eligible for promotion when Vitess makes the choice of a new primary in PRS.
+	TopoInformationRefreshSeconds         int               // Timer duration on which VTOrc refreshes the keyspace and vttablet records from the topo-server.
+	RecoveryPollSeconds                   int               // Timer duration on which VTOrc recovery analysis runs
+	TabletJanitorThresholdSeconds         int               // Time for which a tablet must be unreachable before the tablet janitor quarantines its record. 0 disables the tablet janitor
+	TabletJanitorQuarantineSeconds        int               // Time for which the record of an unreachable tablet stays quarantined before the tablet janitor deletes it
+	TabletJanitorDryRun                   bool              // When true, the tablet janitor only reports the tablet records it would delete
+	TabletTypeTargets                     TabletTypeTargets // Target number of REPLICA and RDONLY tablets per shard, keyed by tablet type or keyspace/tablet type. Empty disables the tablet type balancer
+	TabletTypeBalancerDryRun              bool              // When true, the tablet type balancer only reports the tablet type changes it would make
 }
 
 // ToJSONString will marshal this configuration as JSON
@@ -124,12 +220,18 @@ func UpdateConfigValuesFromFlags() {
 	Config.AuditToBackendDB = auditToBackend
 	Config.AuditToSyslog = auditToSyslog
 	Config.AuditPurgeDays = uint(auditPurgeDuration / (time.Hour * 24))
+	Config.RecoveryHistoryRetentionDays = uint(recoveryHistoryRetention / (time.Hour * 24))
 	Config.RecoveryPeriodBlockSeconds = int(recoveryPeriodBlockDuration / time.Second)
 	Config.PreventCrossDataCenterPrimaryFailover = preventCrossCellFailover
 	Config.WaitReplicasTimeoutSeconds = int(waitReplicasTimeout / time.Second)
 	Config.TolerableReplicationLagSeconds = int(tolerableReplicationLag / time.Second)
 	Config.TopoInformationRefreshSeconds = int(topoInformationRefreshDuration / time.Second)
 	Config.RecoveryPollSeconds = int(recoveryPollDuration / time.Second)
+	Config.TabletJanitorThresholdSeconds = int(tabletJanitorThreshold / time.Second)
+	Config.TabletJanitorQuarantineSeconds = int(tabletJanitorQuarantine / time.Second)
+	Config.TabletJanitorDryRun = tabletJanitorDryRun
+	Config.TabletTypeTargets = tabletTypeTargets
+	Config.TabletTypeBalancerDryRun = tabletTypeBalancerDryRun
 }
 
 // ERSEnabled reports whether VTOrc is allowed to run ERS or not.
@@ -173,6 +275,7 @@ func newConfiguration() *Configuration {
 		WaitReplicasTimeoutSeconds:            30,
 		TopoInformationRefreshSeconds:         15,
 		RecoveryPollSeconds:                   1,
+		TabletJanitorQuarantineSeconds:        3600,
 	}
 }
 
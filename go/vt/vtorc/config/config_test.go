@@ -21,6 +21,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 func TestUpdateConfigValuesFromFlags(t *testing.T) {
@@ -231,4 +233,94 @@ func TestUpdateConfigValuesFromFlags(t *testing.T) {
 		UpdateConfigValuesFromFlags()
 		require.Equal(t, testConfig, Config)
 	})
+
+	t.Run("override tabletJanitorThreshold", func(t *testing.T) {
+		oldTabletJanitorThreshold := tabletJanitorThreshold
+		tabletJanitorThreshold = 2 * time.Hour
+		// Restore the changes we make
+		defer func() {
+			Config = newConfiguration()
+			tabletJanitorThreshold = oldTabletJanitorThreshold
+		}()
+
+		testConfig := newConfiguration()
+		testConfig.TabletJanitorThresholdSeconds = 7200
+		UpdateConfigValuesFromFlags()
+		require.Equal(t, testConfig, Config)
+	})
+	t.Run("override tabletTypeTargets", func(t *testing.T) {
+		oldTabletTypeTargets := tabletTypeTargets
+		require.NoError(t, tabletTypeTargets.Set("replica:3,rdonly:2"))
+		// Restore the changes we make
+		defer func() {
+			Config = newConfiguration()
+			tabletTypeTargets = oldTabletTypeTargets
+		}()
+
+		testConfig := newConfiguration()
+		testConfig.TabletTypeTargets = TabletTypeTargets{"replica": 3, "rdonly": 2}
+		UpdateConfigValuesFromFlags()
+		require.Equal(t, testConfig, Config)
+	})
+}
+
+func TestTabletTypeTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      TabletTypeTargets
+		wantErr   string
+		keyspace  string
+		wantCount map[topodatapb.TabletType]int
+	}{
+		{
+			name:      "global targets",
+			value:     "replica:3, rdonly:2",
+			want:      TabletTypeTargets{"replica": 3, "rdonly": 2},
+			keyspace:  "ks",
+			wantCount: map[topodatapb.TabletType]int{topodatapb.TabletType_REPLICA: 3, topodatapb.TabletType_RDONLY: 2},
+		}, {
+			name:      "keyspace override",
+			value:     "replica:3,rdonly:2,ks/RDONLY:0",
+			want:      TabletTypeTargets{"replica": 3, "rdonly": 2, "ks/rdonly": 0},
+			keyspace:  "ks",
+			wantCount: map[topodatapb.TabletType]int{topodatapb.TabletType_REPLICA: 3, topodatapb.TabletType_RDONLY: 0},
+		}, {
+			name:      "keyspace only",
+			value:     "ks/replica:2",
+			want:      TabletTypeTargets{"ks/replica": 2},
+			keyspace:  "other",
+			wantCount: map[topodatapb.TabletType]int{},
+		}, {
+			name:    "unsupported tablet type",
+			value:   "primary:1",
+			wantErr: "only REPLICA and RDONLY tablets have targets",
+		}, {
+			name:    "missing count",
+			value:   "replica",
+			wantErr: "expected [keyspace/]tablet_type:count",
+		}, {
+			name:    "negative count",
+			value:   "replica:-1",
+			wantErr: "must be a non-negative integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var targets TabletTypeTargets
+			err := targets.Set(tt.value)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, targets)
+			for _, tabletType := range []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY} {
+				count, ok := targets.Target(tt.keyspace, tabletType)
+				wantCount, wantOk := tt.wantCount[tabletType]
+				require.Equal(t, wantOk, ok, tabletType)
+				require.Equal(t, wantCount, count, tabletType)
+			}
+		})
+	}
 }
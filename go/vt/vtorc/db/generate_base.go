@@ -32,6 +32,7 @@ var TableNames = []string{
 	"vitess_tablet",
 	"vitess_keyspace",
 	"vitess_shard",
+	"tablet_janitor",
 }
 
 // vtorcBackend is a list of SQL statements required to build the vtorc backend
@@ -308,6 +309,18 @@ CREATE TABLE vitess_shard (
 	PRIMARY KEY (keyspace, shard)
 )`,
 	`
+DROP TABLE IF EXISTS tablet_janitor
+`,
+	`
+CREATE TABLE tablet_janitor (
+	alias varchar(256) NOT NULL,
+	keyspace varchar(128) NOT NULL,
+	shard varchar(128) NOT NULL,
+	unreachable_timestamp timestamp not null default (''),
+	quarantine_timestamp timestamp NULL DEFAULT NULL,
+	PRIMARY KEY (alias)
+)`,
+	`
 CREATE INDEX source_host_port_idx_database_instance_database_instance on database_instance (source_host, source_port)
 	`,
 	`
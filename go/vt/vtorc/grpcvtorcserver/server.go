@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package grpcvtorcserver contains the gRPC implementation of the server side
+of the VTOrc RPC interface.
+*/
+package grpcvtorcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/logic"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vtorcservicepb "vitess.io/vitess/go/vt/proto/vtorcservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// defaultRecoveriesLimit is the number of recoveries returned by
+// GetRecoveryTimelines when no limit is provided, like the /api/recoveries
+// endpoint.
+const defaultRecoveriesLimit = 50
+
+// server is our gRPC server.
+type server struct {
+	vtorcservicepb.UnimplementedVtorcServer
+}
+
+// GetRecoveryTimelines implements the server side of the VtorcClient interface.
+func (s *server) GetRecoveryTimelines(ctx context.Context, request *vtorcdatapb.GetRecoveryTimelinesRequest) (*vtorcdatapb.GetRecoveryTimelinesResponse, error) {
+	filter := logic.RecoveryTimelineFilter{
+		Keyspace: request.Keyspace,
+		Shard:    request.Shard,
+		Limit:    int(request.Limit),
+	}
+	if filter.Shard != "" && filter.Keyspace == "" {
+		return nil, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "filtering by shard without keyspace isn't supported")
+	}
+	if filter.Limit < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid limit %d", filter.Limit)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultRecoveriesLimit
+	}
+	since, _, err := protoutil.DurationFromProto(request.Since)
+	if err != nil || since < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid since %v", request.Since)
+	}
+	filter.Since = since
+
+	timelines, err := logic.ReadRecoveryTimelines(filter)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "failed to read the recoveries")
+	}
+	response := &vtorcdatapb.GetRecoveryTimelinesResponse{}
+	for _, timeline := range timelines {
+		response.Timelines = append(response.Timelines, timeline.ToProto())
+	}
+	return response, nil
+}
+
+// StartServer registers the server for RPCs.
+func StartServer(s *grpc.Server) {
+	vtorcservicepb.RegisterVtorcServer(s, &server{})
+}
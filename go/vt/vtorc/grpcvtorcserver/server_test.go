@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtorcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/db"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestGetRecoveryTimelines(t *testing.T) {
+	// Clear the database after the test. The easiest way to do that is to run all the initialization commands again.
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	_, err := db.ExecVTOrc(`insert into recovery_detection (detection_id, detection_timestamp, alias, analysis, keyspace, shard) values
+(1, '2024-01-01 00:00:00', 'zone1-0000000100', 'DeadPrimary', 'ks', '0')`)
+	require.NoError(t, err)
+	_, err = db.ExecVTOrc(`insert into topology_recovery (recovery_id, detection_id, alias, analysis, keyspace, shard, start_recovery, end_recovery, is_successful, successor_alias, all_errors) values
+(1, 1, 'zone1-0000000100', 'DeadPrimary', 'ks', '0', '2024-01-01 00:00:02', '2024-01-01 00:00:12', 1, 'zone1-0000000101', '')`)
+	require.NoError(t, err)
+	_, err = db.ExecVTOrc(`insert into topology_recovery_steps (recovery_step_id, recovery_id, audit_at, message) values
+(1, 1, '2024-01-01 00:00:03', 'starting ERS')`)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	s := &server{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	response, err := s.GetRecoveryTimelines(ctx, &vtorcdatapb.GetRecoveryTimelinesRequest{Keyspace: "ks", Shard: "0"})
+	require.NoError(t, err)
+	require.Len(t, response.Timelines, 1)
+	timeline := response.Timelines[0]
+	require.EqualValues(t, 1, timeline.RecoveryId)
+	require.Equal(t, "DeadPrimary", timeline.Analysis)
+	require.True(t, timeline.IsSuccessful)
+	require.Equal(t, "zone1-0000000101", timeline.SuccessorAlias)
+	require.Equal(t, protoutil.TimeToProto(start), timeline.DetectedAt)
+	require.Equal(t, protoutil.TimeToProto(start.Add(12*time.Second)), timeline.EndedAt)
+	require.Equal(t, protoutil.DurationToProto(2*time.Second), timeline.DetectionToRecovery)
+	require.Equal(t, protoutil.DurationToProto(10*time.Second), timeline.RecoveryDuration)
+	require.Equal(t, []*vtorcdatapb.RecoveryEvent{
+		{Timestamp: protoutil.TimeToProto(start), Type: "ProblemDetected", Message: "DeadPrimary detected on zone1-0000000100"},
+		{Timestamp: protoutil.TimeToProto(start.Add(2 * time.Second)), Type: "RecoveryStarted"},
+		{Timestamp: protoutil.TimeToProto(start.Add(3 * time.Second)), Type: "Action", Message: "starting ERS"},
+		{Timestamp: protoutil.TimeToProto(start.Add(12 * time.Second)), Type: "RecoverySucceeded", Message: "promoted zone1-0000000101"},
+	}, timeline.Events)
+
+	// The recovery is older than an hour.
+	response, err = s.GetRecoveryTimelines(ctx, &vtorcdatapb.GetRecoveryTimelinesRequest{Since: protoutil.DurationToProto(time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, response.Timelines)
+
+	_, err = s.GetRecoveryTimelines(ctx, &vtorcdatapb.GetRecoveryTimelinesRequest{Shard: "0"})
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = s.GetRecoveryTimelines(ctx, &vtorcdatapb.GetRecoveryTimelinesRequest{Limit: -1})
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = s.GetRecoveryTimelines(ctx, &vtorcdatapb.GetRecoveryTimelinesRequest{Since: protoutil.DurationToProto(-time.Hour)})
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+}
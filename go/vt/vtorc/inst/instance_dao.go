@@ -112,10 +112,15 @@ func ExecDBWriteFunc(f func() error) error {
 }
 
 func ExpireTableData(tableName string, timestampColumn string) error {
+	return ExpireTableDataAfterDays(tableName, timestampColumn, config.Config.AuditPurgeDays)
+}
+
+// ExpireTableDataAfterDays deletes the rows of a table which are older than the given number of days.
+func ExpireTableDataAfterDays(tableName string, timestampColumn string, days uint) error {
 	writeFunc := func() error {
 		_, err := db.ExecVTOrc(
 			fmt.Sprintf("delete from %s where %s < NOW() - INTERVAL ? DAY", tableName, timestampColumn),
-			config.Config.AuditPurgeDays,
+			days,
 		)
 		return err
 	}
@@ -0,0 +1,326 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// RecoveryEventType is the type of an event of the timeline of a recovery.
+type RecoveryEventType string
+
+const (
+	// RecoveryEventProblemDetected is the detection of the problem the
+	// recovery fixes.
+	RecoveryEventProblemDetected RecoveryEventType = "ProblemDetected"
+	// RecoveryEventRecoveryStarted is the start of the recovery, once VTOrc
+	// registered it for the shard.
+	RecoveryEventRecoveryStarted RecoveryEventType = "RecoveryStarted"
+	// RecoveryEventAction is an action taken, or skipped, by the recovery.
+	RecoveryEventAction RecoveryEventType = "Action"
+	// RecoveryEventRecoverySucceeded is the successful end of the recovery.
+	RecoveryEventRecoverySucceeded RecoveryEventType = "RecoverySucceeded"
+	// RecoveryEventRecoveryFailed is the unsuccessful end of the recovery.
+	RecoveryEventRecoveryFailed RecoveryEventType = "RecoveryFailed"
+)
+
+// RecoveryEvent is an event of the timeline of a recovery.
+type RecoveryEvent struct {
+	Timestamp string
+	Type      RecoveryEventType
+	Message   string `json:",omitempty"`
+}
+
+// RecoveryTimeline is the structured history of a recovery, from the
+// detection of the problem to its outcome.
+type RecoveryTimeline struct {
+	RecoveryID     int64
+	DetectionID    int64
+	TabletAlias    string
+	Analysis       inst.AnalysisCode
+	Keyspace       string
+	Shard          string
+	DetectedAt     string `json:",omitempty"`
+	StartedAt      string
+	EndedAt        string `json:",omitempty"`
+	IsSuccessful   bool
+	SuccessorAlias string   `json:",omitempty"`
+	Errors         []string `json:",omitempty"`
+	// DetectionToRecoverySeconds is the time between the detection of the
+	// problem and the start of the recovery.
+	DetectionToRecoverySeconds float64
+	// RecoveryDurationSeconds is the duration of the recovery, which is 0
+	// while it is running.
+	RecoveryDurationSeconds float64
+	Events                  []*RecoveryEvent
+}
+
+// RecoveryTimelineFilter selects the recoveries returned by
+// ReadRecoveryTimelines.
+type RecoveryTimelineFilter struct {
+	Keyspace string
+	Shard    string
+	// Since excludes the recoveries started before now minus Since, when set.
+	Since time.Duration
+	// Limit is the maximum number of recoveries returned, the most recent
+	// first.
+	Limit int
+}
+
+// ReadRecoveryTimelines reads the timelines of the recoveries matching the
+// filter, the most recent first.
+func ReadRecoveryTimelines(filter RecoveryTimelineFilter) ([]*RecoveryTimeline, error) {
+	var conditions []string
+	var args []any
+	if filter.Keyspace != "" {
+		conditions = append(conditions, "topology_recovery.keyspace = ?")
+		args = append(args, filter.Keyspace)
+	}
+	if filter.Shard != "" {
+		conditions = append(conditions, "topology_recovery.shard = ?")
+		args = append(args, filter.Shard)
+	}
+	if filter.Since > 0 {
+		conditions = append(conditions, "topology_recovery.start_recovery >= NOW() - INTERVAL ? SECOND")
+		args = append(args, int64(filter.Since/time.Second))
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "where " + strings.Join(conditions, " and ")
+	}
+	limitClause := ""
+	if filter.Limit > 0 {
+		limitClause = "limit ?"
+		args = append(args, filter.Limit)
+	}
+
+	query := fmt.Sprintf(`
+		select
+			topology_recovery.recovery_id,
+			topology_recovery.detection_id,
+			topology_recovery.alias,
+			topology_recovery.analysis,
+			topology_recovery.keyspace,
+			topology_recovery.shard,
+			ifnull(recovery_detection.detection_timestamp, '') as detection_timestamp,
+			topology_recovery.start_recovery,
+			ifnull(topology_recovery.end_recovery, '') as end_recovery,
+			topology_recovery.is_successful,
+			ifnull(topology_recovery.successor_alias, '') as successor_alias,
+			topology_recovery.all_errors
+		from
+			topology_recovery
+			left join recovery_detection on (recovery_detection.detection_id = topology_recovery.detection_id)
+		%s
+		order by
+			topology_recovery.recovery_id desc
+		%s
+		`, whereClause, limitClause)
+	var timelines []*RecoveryTimeline
+	err := db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		timeline := &RecoveryTimeline{
+			RecoveryID:     m.GetInt64("recovery_id"),
+			DetectionID:    m.GetInt64("detection_id"),
+			TabletAlias:    m.GetString("alias"),
+			Analysis:       inst.AnalysisCode(m.GetString("analysis")),
+			Keyspace:       m.GetString("keyspace"),
+			Shard:          m.GetString("shard"),
+			DetectedAt:     normalizeTimestamp(m.GetString("detection_timestamp")),
+			StartedAt:      normalizeTimestamp(m.GetString("start_recovery")),
+			EndedAt:        normalizeTimestamp(m.GetString("end_recovery")),
+			IsSuccessful:   m.GetBool("is_successful"),
+			SuccessorAlias: m.GetString("successor_alias"),
+		}
+		if allErrors := m.GetString("all_errors"); allErrors != "" {
+			timeline.Errors = strings.Split(allErrors, "\n")
+		}
+		timelines = append(timelines, timeline)
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if len(timelines) == 0 {
+		return timelines, nil
+	}
+
+	// The steps of all the recoveries are read at once.
+	steps := make(map[int64][]*TopologyRecoveryStep)
+	placeholders := make([]string, 0, len(timelines))
+	stepArgs := make([]any, 0, len(timelines))
+	for _, timeline := range timelines {
+		placeholders = append(placeholders, "?")
+		stepArgs = append(stepArgs, timeline.RecoveryID)
+	}
+	query = fmt.Sprintf(`
+		select
+			recovery_step_id,
+			recovery_id,
+			audit_at,
+			message
+		from
+			topology_recovery_steps
+		where
+			recovery_id in (%s)
+		order by
+			recovery_step_id
+		`, strings.Join(placeholders, ", "))
+	err = db.QueryVTOrc(query, stepArgs, func(m sqlutils.RowMap) error {
+		step := &TopologyRecoveryStep{
+			ID:         m.GetInt64("recovery_step_id"),
+			RecoveryID: m.GetInt64("recovery_id"),
+			AuditAt:    normalizeTimestamp(m.GetString("audit_at")),
+			Message:    m.GetString("message"),
+		}
+		steps[step.RecoveryID] = append(steps[step.RecoveryID], step)
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	for _, timeline := range timelines {
+		timeline.buildEvents(steps[timeline.RecoveryID])
+	}
+	return timelines, nil
+}
+
+// buildEvents builds the events and durations of the timeline from the
+// recovery and its steps.
+func (timeline *RecoveryTimeline) buildEvents(steps []*TopologyRecoveryStep) {
+	if timeline.DetectedAt != "" {
+		timeline.Events = append(timeline.Events, &RecoveryEvent{
+			Timestamp: timeline.DetectedAt,
+			Type:      RecoveryEventProblemDetected,
+			Message:   fmt.Sprintf("%s detected on %s", timeline.Analysis, timeline.TabletAlias),
+		})
+	}
+	timeline.Events = append(timeline.Events, &RecoveryEvent{
+		Timestamp: timeline.StartedAt,
+		Type:      RecoveryEventRecoveryStarted,
+	})
+	for _, step := range steps {
+		timeline.Events = append(timeline.Events, &RecoveryEvent{
+			Timestamp: step.AuditAt,
+			Type:      RecoveryEventAction,
+			Message:   step.Message,
+		})
+	}
+	if timeline.EndedAt != "" {
+		event := &RecoveryEvent{
+			Timestamp: timeline.EndedAt,
+			Type:      RecoveryEventRecoveryFailed,
+			Message:   strings.Join(timeline.Errors, "; "),
+		}
+		if timeline.IsSuccessful {
+			event.Type = RecoveryEventRecoverySucceeded
+			event.Message = ""
+			if timeline.SuccessorAlias != "" {
+				event.Message = fmt.Sprintf("promoted %s", timeline.SuccessorAlias)
+			}
+		}
+		timeline.Events = append(timeline.Events, event)
+	}
+
+	timeline.DetectionToRecoverySeconds = secondsBetween(timeline.DetectedAt, timeline.StartedAt)
+	timeline.RecoveryDurationSeconds = secondsBetween(timeline.StartedAt, timeline.EndedAt)
+}
+
+// ToProto returns the timeline in the format of the VTOrc RPC interface.
+func (timeline *RecoveryTimeline) ToProto() *vtorcdatapb.RecoveryTimeline {
+	timelinepb := &vtorcdatapb.RecoveryTimeline{
+		RecoveryId:          timeline.RecoveryID,
+		DetectionId:         timeline.DetectionID,
+		TabletAlias:         timeline.TabletAlias,
+		Analysis:            string(timeline.Analysis),
+		Keyspace:            timeline.Keyspace,
+		Shard:               timeline.Shard,
+		DetectedAt:          timestampToProto(timeline.DetectedAt),
+		StartedAt:           timestampToProto(timeline.StartedAt),
+		EndedAt:             timestampToProto(timeline.EndedAt),
+		IsSuccessful:        timeline.IsSuccessful,
+		SuccessorAlias:      timeline.SuccessorAlias,
+		Errors:              timeline.Errors,
+		DetectionToRecovery: protoutil.DurationToProto(secondsToDuration(timeline.DetectionToRecoverySeconds)),
+		RecoveryDuration:    protoutil.DurationToProto(secondsToDuration(timeline.RecoveryDurationSeconds)),
+	}
+	for _, event := range timeline.Events {
+		timelinepb.Events = append(timelinepb.Events, &vtorcdatapb.RecoveryEvent{
+			Timestamp: timestampToProto(event.Timestamp),
+			Type:      string(event.Type),
+			Message:   event.Message,
+		})
+	}
+	return timelinepb
+}
+
+// timestampToProto returns a timestamp of the timelines as a proto, or nil
+// if it is missing.
+func timestampToProto(timestamp string) *vttimepb.Time {
+	t, err := parseTimestamp(timestamp)
+	if err != nil {
+		return nil
+	}
+	return protoutil.TimeToProto(t)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// normalizeTimestamp returns a timestamp of the VTOrc database in the RFC 3339
+// format. The timestamps are read in either format, depending on whether they
+// go through an expression of the query.
+func normalizeTimestamp(timestamp string) string {
+	if t, err := parseTimestamp(timestamp); err == nil {
+		return t.Format(time.RFC3339)
+	}
+	return timestamp
+}
+
+func parseTimestamp(timestamp string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		return t, nil
+	}
+	return time.Parse(sqlutils.DateTimeFormat, timestamp)
+}
+
+// secondsBetween returns the number of seconds between two timestamps, or 0
+// if either is missing.
+func secondsBetween(from, to string) float64 {
+	fromTime, err := parseTimestamp(from)
+	if err != nil {
+		return 0
+	}
+	toTime, err := parseTimestamp(to)
+	if err != nil || toTime.Before(fromTime) {
+		return 0
+	}
+	return toTime.Sub(fromTime).Seconds()
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestReadRecoveryTimelines(t *testing.T) {
+	// Clear the database after the test. The easiest way to do that is to run all the initialization commands again.
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	_, err := db.ExecVTOrc(`insert into recovery_detection (detection_id, detection_timestamp, alias, analysis, keyspace, shard) values
+(1, '2024-01-01 00:00:00', 'zone1-0000000100', 'DeadPrimary', 'ks', '0'),
+(2, '2024-01-01 00:00:00', 'zone1-0000000200', 'ReplicationStopped', 'ks2', '0')`)
+	require.NoError(t, err)
+	_, err = db.ExecVTOrc(`insert into topology_recovery (recovery_id, detection_id, alias, analysis, keyspace, shard, start_recovery, end_recovery, is_successful, successor_alias, all_errors) values
+(1, 1, 'zone1-0000000100', 'DeadPrimary', 'ks', '0', '2024-01-01 00:00:02', '2024-01-01 00:00:12', 1, 'zone1-0000000101', ''),
+(2, 2, 'zone1-0000000200', 'ReplicationStopped', 'ks2', '0', '2024-01-01 00:00:01', '2024-01-01 00:00:03', 0, NULL, 'fix replication failed')`)
+	require.NoError(t, err)
+	_, err = db.ExecVTOrc(`insert into topology_recovery_steps (recovery_step_id, recovery_id, audit_at, message) values
+(1, 1, '2024-01-01 00:00:03', 'starting ERS'),
+(2, 1, '2024-01-01 00:00:11', 'promoted zone1-0000000101'),
+(3, 2, '2024-01-01 00:00:02', 'starting replication')`)
+	require.NoError(t, err)
+
+	timelines, err := ReadRecoveryTimelines(RecoveryTimelineFilter{})
+	require.NoError(t, err)
+	require.Len(t, timelines, 2)
+	require.EqualValues(t, 2, timelines[0].RecoveryID)
+	require.Equal(t, []string{"fix replication failed"}, timelines[0].Errors)
+	require.Equal(t, []*RecoveryEvent{
+		{Timestamp: "2024-01-01T00:00:00Z", Type: RecoveryEventProblemDetected, Message: "ReplicationStopped detected on zone1-0000000200"},
+		{Timestamp: "2024-01-01T00:00:01Z", Type: RecoveryEventRecoveryStarted},
+		{Timestamp: "2024-01-01T00:00:02Z", Type: RecoveryEventAction, Message: "starting replication"},
+		{Timestamp: "2024-01-01T00:00:03Z", Type: RecoveryEventRecoveryFailed, Message: "fix replication failed"},
+	}, timelines[0].Events)
+
+	timeline := timelines[1]
+	require.EqualValues(t, 1, timeline.RecoveryID)
+	require.Equal(t, inst.DeadPrimary, timeline.Analysis)
+	require.True(t, timeline.IsSuccessful)
+	require.Empty(t, timeline.Errors)
+	require.EqualValues(t, 2, timeline.DetectionToRecoverySeconds)
+	require.EqualValues(t, 10, timeline.RecoveryDurationSeconds)
+	require.Len(t, timeline.Events, 5)
+	require.Equal(t, &RecoveryEvent{Timestamp: "2024-01-01T00:00:12Z", Type: RecoveryEventRecoverySucceeded, Message: "promoted zone1-0000000101"}, timeline.Events[4])
+
+	timelines, err = ReadRecoveryTimelines(RecoveryTimelineFilter{Keyspace: "ks", Shard: "0"})
+	require.NoError(t, err)
+	require.Len(t, timelines, 1)
+	require.EqualValues(t, 1, timelines[0].RecoveryID)
+
+	timelines, err = ReadRecoveryTimelines(RecoveryTimelineFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, timelines, 1)
+	require.EqualValues(t, 2, timelines[0].RecoveryID)
+
+	// The recoveries are older than an hour.
+	timelines, err = ReadRecoveryTimelines(RecoveryTimelineFilter{Since: time.Hour})
+	require.NoError(t, err)
+	require.Empty(t, timelines)
+}
@@ -0,0 +1,357 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The tablet janitor deletes the records of the tablets whose processes are
+// gone. A tablet which VTOrc cannot reach is quarantined once it has been
+// unreachable for --tablet-janitor-threshold. Its record is deleted from the
+// topology server, with its entry in the replication graph of the shard,
+// once it has been quarantined for --tablet-janitor-quarantine-duration and
+// the tablet still doesn't answer a ping. A tablet which becomes reachable
+// again is released. The primary tablets are never deleted.
+
+var (
+	tabletJanitorQuarantinesCounter     = stats.NewCounter("TabletJanitorQuarantines", "Number of tablet records quarantined by the tablet janitor")
+	tabletJanitorReleasesCounter        = stats.NewCounter("TabletJanitorReleases", "Number of tablet records released by the tablet janitor because the tablet was reachable again")
+	tabletJanitorDeletionsCounter       = stats.NewCounter("TabletJanitorDeletions", "Number of tablet records deleted by the tablet janitor")
+	tabletJanitorDryRunDeletionsCounter = stats.NewCounter("TabletJanitorDryRunDeletions", "Number of tablet records the tablet janitor would have deleted without --tablet-janitor-dry-run")
+	tabletJanitorPrunedNodesCounter     = stats.NewCounter("TabletJanitorPrunedReplicationNodes", "Number of orphaned replication graph entries pruned by the tablet janitor")
+	tabletJanitorErrorsCounter          = stats.NewCounter("TabletJanitorErrors", "Number of errors of the tablet janitor")
+	tabletJanitorQuarantinedGauge       = stats.NewGauge("TabletJanitorQuarantinedTablets", "Number of tablet records quarantined by the tablet janitor")
+
+	tabletJanitorEntrance int32
+)
+
+// JanitorTablet is a tablet tracked by the tablet janitor.
+type JanitorTablet struct {
+	TabletAlias          string
+	Keyspace             string
+	Shard                string
+	UnreachableTimestamp string
+	QuarantineTimestamp  string `json:",omitempty"`
+}
+
+// RunTabletJanitor runs a cycle of the tablet janitor, if it is enabled.
+// It is non re-entrant.
+func RunTabletJanitor() {
+	if config.Config.TabletJanitorThresholdSeconds <= 0 {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&tabletJanitorEntrance, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&tabletJanitorEntrance, 0)
+
+	if err := runTabletJanitor(context.Background()); err != nil {
+		tabletJanitorErrorsCounter.Add(1)
+		log.Errorf("tablet janitor: %v", err)
+	}
+}
+
+func runTabletJanitor(ctx context.Context) error {
+	unreachable, err := readUnreachableTablets()
+	if err != nil {
+		return err
+	}
+	tracked, err := ReadJanitorTablets()
+	if err != nil {
+		return err
+	}
+	for _, tablet := range tracked {
+		if _, ok := unreachable[tablet.TabletAlias]; ok {
+			continue
+		}
+		if err := releaseJanitorTablet(tablet.TabletAlias, "tablet is reachable"); err != nil {
+			return err
+		}
+	}
+	for _, tablet := range unreachable {
+		if _, err := db.ExecVTOrc(`
+			insert ignore
+				into tablet_janitor (
+					alias, keyspace, shard, unreachable_timestamp
+				) values (
+					?, ?, ?, NOW()
+				)`,
+			tablet.TabletAlias, tablet.Keyspace, tablet.Shard,
+		); err != nil {
+			return err
+		}
+	}
+	if err := quarantineUnreachableTablets(); err != nil {
+		return err
+	}
+
+	var toDelete []string
+	err = db.QueryVTOrc(`
+		select
+			alias
+		from
+			tablet_janitor
+		where
+			quarantine_timestamp < NOW() - interval ? second`,
+		sqlutils.Args(config.Config.TabletJanitorQuarantineSeconds),
+		func(row sqlutils.RowMap) error {
+			toDelete = append(toDelete, row.GetString("alias"))
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	for _, tabletAlias := range toDelete {
+		if err := deleteQuarantinedTablet(ctx, tabletAlias); err != nil {
+			tabletJanitorErrorsCounter.Add(1)
+			log.Errorf("tablet janitor: cannot delete the record of tablet %v: %v", tabletAlias, err)
+		}
+	}
+	return updateTabletJanitorGauge()
+}
+
+// readUnreachableTablets reads the tablets which VTOrc cannot reach, apart
+// from the primary tablets.
+func readUnreachableTablets() (map[string]*JanitorTablet, error) {
+	query := `
+		select
+			vitess_tablet.alias,
+			vitess_tablet.keyspace,
+			vitess_tablet.shard
+		from
+			vitess_tablet
+			left join database_instance on (
+				vitess_tablet.alias = database_instance.alias
+			)
+			left join vitess_shard on (
+				vitess_tablet.keyspace = vitess_shard.keyspace
+				and vitess_tablet.shard = vitess_shard.shard
+			)
+		where
+			vitess_tablet.tablet_type != ?
+			and (
+				vitess_shard.primary_alias is null
+				or vitess_shard.primary_alias != vitess_tablet.alias
+			)
+			and (
+				database_instance.alias is null
+				or database_instance.last_seen is null
+				or database_instance.last_seen < database_instance.last_checked
+			)`
+	tablets := make(map[string]*JanitorTablet)
+	err := db.QueryVTOrc(query, sqlutils.Args(int(topodatapb.TabletType_PRIMARY)), func(row sqlutils.RowMap) error {
+		tablet := &JanitorTablet{
+			TabletAlias: row.GetString("alias"),
+			Keyspace:    row.GetString("keyspace"),
+			Shard:       row.GetString("shard"),
+		}
+		tablets[tablet.TabletAlias] = tablet
+		return nil
+	})
+	return tablets, err
+}
+
+// quarantineUnreachableTablets quarantines the tablets which have been
+// unreachable for longer than the threshold.
+func quarantineUnreachableTablets() error {
+	var toQuarantine []string
+	err := db.QueryVTOrc(`
+		select
+			alias
+		from
+			tablet_janitor
+		where
+			quarantine_timestamp is null
+			and unreachable_timestamp < NOW() - interval ? second`,
+		sqlutils.Args(config.Config.TabletJanitorThresholdSeconds),
+		func(row sqlutils.RowMap) error {
+			toQuarantine = append(toQuarantine, row.GetString("alias"))
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	for _, tabletAlias := range toQuarantine {
+		if _, err := db.ExecVTOrc(`
+			update
+				tablet_janitor
+			set
+				quarantine_timestamp = NOW()
+			where
+				alias = ?`,
+			tabletAlias,
+		); err != nil {
+			return err
+		}
+		tabletJanitorQuarantinesCounter.Add(1)
+		log.Infof("tablet janitor: quarantining the record of tablet %v, unreachable for more than %ds", tabletAlias, config.Config.TabletJanitorThresholdSeconds)
+		_ = inst.AuditOperation("tablet-janitor-quarantine", tabletAlias, fmt.Sprintf("unreachable for more than %ds", config.Config.TabletJanitorThresholdSeconds))
+	}
+	return nil
+}
+
+// deleteQuarantinedTablet deletes the record of a quarantined tablet from
+// the topology server, unless the tablet turns out to be alive or a primary.
+func deleteQuarantinedTablet(ctx context.Context, tabletAlias string) error {
+	alias, err := topoproto.ParseTabletAlias(tabletAlias)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	ti, err := ts.GetTablet(ctx, alias)
+	if topo.IsErrType(err, topo.NoNode) {
+		// The record was deleted by someone else, the tablet is forgotten
+		// on the next refresh of the tablets.
+		return releaseJanitorTablet(tabletAlias, "tablet record is gone")
+	}
+	if err != nil {
+		return err
+	}
+	if ti.Type == topodatapb.TabletType_PRIMARY {
+		return releaseJanitorTablet(tabletAlias, "tablet is a primary")
+	}
+	si, err := ts.GetShard(ctx, ti.Keyspace, ti.Shard)
+	if err != nil {
+		return err
+	}
+	if topoproto.TabletAliasEqual(si.PrimaryAlias, alias) {
+		return releaseJanitorTablet(tabletAlias, "tablet is the primary of its shard")
+	}
+	if err := tmc.Ping(ctx, ti.Tablet); err == nil {
+		return releaseJanitorTablet(tabletAlias, "tablet answers pings")
+	}
+
+	if config.Config.TabletJanitorDryRun {
+		tabletJanitorDryRunDeletionsCounter.Add(1)
+		log.Infof("tablet janitor: dry run, not deleting the record of tablet %v", tabletAlias)
+		_ = inst.AuditOperation("tablet-janitor-dry-run", tabletAlias, "tablet record would be deleted")
+		// Quarantine the tablet again, so that it is reported once per
+		// quarantine period.
+		_, err := db.ExecVTOrc(`
+			update
+				tablet_janitor
+			set
+				quarantine_timestamp = NOW()
+			where
+				alias = ?`,
+			tabletAlias,
+		)
+		return err
+	}
+
+	// topotools.DeleteTablet also removes the tablet from the replication
+	// graph of its shard.
+	if err := topotools.DeleteTablet(ctx, ts, ti.Tablet); err != nil {
+		return err
+	}
+	tabletJanitorDeletionsCounter.Add(1)
+	log.Infof("tablet janitor: deleted the record of tablet %v", tabletAlias)
+	_ = inst.AuditOperation("tablet-janitor-delete", tabletAlias, "tablet record deleted")
+	pruneShardReplication(ctx, alias.Cell, ti.Keyspace, ti.Shard)
+
+	_, err = db.ExecVTOrc("delete from tablet_janitor where alias = ?", tabletAlias)
+	return err
+}
+
+// pruneShardReplication removes the entries of the replication graph of the
+// shard in the cell whose tablet records do not exist anymore.
+func pruneShardReplication(ctx context.Context, cell, keyspace, shard string) {
+	logger := logutil.NewMemoryLogger()
+	for {
+		problem, err := topo.FixShardReplication(ctx, ts, logger, cell, keyspace, shard)
+		if err != nil {
+			if !topo.IsErrType(err, topo.NoNode) {
+				tabletJanitorErrorsCounter.Add(1)
+				log.Warningf("tablet janitor: cannot prune the replication graph of %v/%v in cell %v: %v", keyspace, shard, cell, err)
+			}
+			return
+		}
+		if problem == nil {
+			return
+		}
+		tabletJanitorPrunedNodesCounter.Add(1)
+		log.Infof("tablet janitor: pruned tablet %v from the replication graph of %v/%v in cell %v: %v",
+			topoproto.TabletAliasString(problem.TabletAlias), keyspace, shard, cell, problem.Type)
+	}
+}
+
+// releaseJanitorTablet stops tracking the tablet.
+func releaseJanitorTablet(tabletAlias string, reason string) error {
+	sqlResult, err := db.ExecVTOrc("delete from tablet_janitor where alias = ?", tabletAlias)
+	if err != nil {
+		return err
+	}
+	if rows, err := sqlResult.RowsAffected(); err == nil && rows > 0 {
+		tabletJanitorReleasesCounter.Add(1)
+		log.Infof("tablet janitor: releasing tablet %v: %v", tabletAlias, reason)
+	}
+	return nil
+}
+
+// updateTabletJanitorGauge updates the number of quarantined tablets.
+func updateTabletJanitorGauge() error {
+	return db.QueryVTOrc("select count(*) as quarantined from tablet_janitor where quarantine_timestamp is not null", nil, func(row sqlutils.RowMap) error {
+		tabletJanitorQuarantinedGauge.Set(row.GetInt64("quarantined"))
+		return nil
+	})
+}
+
+// ReadJanitorTablets reads the tablets tracked by the tablet janitor.
+func ReadJanitorTablets() ([]*JanitorTablet, error) {
+	var tablets []*JanitorTablet
+	err := db.QueryVTOrc(`
+		select
+			alias,
+			keyspace,
+			shard,
+			unreachable_timestamp,
+			quarantine_timestamp
+		from
+			tablet_janitor
+		order by
+			alias`,
+		nil,
+		func(row sqlutils.RowMap) error {
+			tablets = append(tablets, &JanitorTablet{
+				TabletAlias:          row.GetString("alias"),
+				Keyspace:             row.GetString("keyspace"),
+				Shard:                row.GetString("shard"),
+				UnreachableTimestamp: row.GetString("unreachable_timestamp"),
+				QuarantineTimestamp:  row.GetString("quarantine_timestamp"),
+			})
+			return nil
+		})
+	return tablets, err
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func init() {
+	// The instances can only be written once the configuration is loaded.
+	config.MarkConfigurationLoaded()
+}
+
+func TestTabletJanitor(t *testing.T) {
+	oldTs, oldTmc := ts, tmc
+	oldConfig := *config.Config
+	defer func() {
+		ts, tmc = oldTs, oldTmc
+		*config.Config = oldConfig
+		db.ClearVTOrcDatabase()
+	}()
+	config.Config.TabletJanitorThresholdSeconds = 3600
+	config.Config.TabletJanitorQuarantineSeconds = 3600
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, keyspace, shard))
+	_, err := ts.UpdateShardFields(ctx, keyspace, shard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = tab100.Alias
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, inst.SaveShard(topo.NewShardInfo(keyspace, shard, &topodatapb.Shard{PrimaryAlias: tab100.Alias}, nil)))
+
+	// tab100 is the unreachable primary, tab101 is reachable, tab102 is gone
+	// and tab104 doesn't answer the discovery but answers pings.
+	tab104 := proto.Clone(tab101).(*topodatapb.Tablet)
+	tab104.Alias.Uid = 104
+	tab104.MysqlPort = 104
+	for _, tablet := range []*topodatapb.Tablet{tab100, tab101, tab102, tab104} {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+		require.NoError(t, inst.SaveTablet(tablet))
+	}
+	require.NoError(t, inst.WriteInstance(&inst.Instance{InstanceAlias: topoproto.TabletAliasString(tab101.Alias), Hostname: hostname, Port: 101}, true, nil))
+	require.NoError(t, inst.WriteInstance(&inst.Instance{InstanceAlias: topoproto.TabletAliasString(tab102.Alias), Hostname: hostname, Port: 102}, true, nil))
+	_, err = db.ExecVTOrc("update database_instance set last_seen = '2000-01-01 00:00:00' where alias = ?", topoproto.TabletAliasString(tab102.Alias))
+	require.NoError(t, err)
+	tmc = &testutil.TabletManagerClient{
+		PingResults: map[string]error{
+			topoproto.TabletAliasString(tab102.Alias): errors.New("connection refused"),
+			topoproto.TabletAliasString(tab104.Alias): nil,
+		},
+	}
+
+	// The unreachable tablets are tracked, but not quarantined yet.
+	require.NoError(t, runTabletJanitor(ctx))
+	tablets, err := ReadJanitorTablets()
+	require.NoError(t, err)
+	require.Len(t, tablets, 2)
+	require.Equal(t, "zone-1-0000000102", tablets[0].TabletAlias)
+	require.Equal(t, "zone-1-0000000104", tablets[1].TabletAlias)
+	require.Empty(t, tablets[0].QuarantineTimestamp)
+
+	// The tablets are quarantined once they are unreachable for longer than the threshold.
+	_, err = db.ExecVTOrc("update tablet_janitor set unreachable_timestamp = '2000-01-01 00:00:00'")
+	require.NoError(t, err)
+	require.NoError(t, runTabletJanitor(ctx))
+	tablets, err = ReadJanitorTablets()
+	require.NoError(t, err)
+	require.Len(t, tablets, 2)
+	require.NotEmpty(t, tablets[0].QuarantineTimestamp)
+	require.NotEmpty(t, tablets[1].QuarantineTimestamp)
+
+	// The dry run doesn't delete anything.
+	config.Config.TabletJanitorDryRun = true
+	_, err = db.ExecVTOrc("update tablet_janitor set quarantine_timestamp = '2000-01-01 00:00:00'")
+	require.NoError(t, err)
+	dryRunDeletions := tabletJanitorDryRunDeletionsCounter.Get()
+	require.NoError(t, runTabletJanitor(ctx))
+	require.EqualValues(t, dryRunDeletions+1, tabletJanitorDryRunDeletionsCounter.Get())
+	_, err = ts.GetTablet(ctx, tab102.Alias)
+	require.NoError(t, err)
+	tablets, err = ReadJanitorTablets()
+	require.NoError(t, err)
+	require.Len(t, tablets, 1, "tab104 answers pings and is released")
+	require.Equal(t, "zone-1-0000000102", tablets[0].TabletAlias)
+
+	// The record of tab102 is deleted with its replication graph entry.
+	config.Config.TabletJanitorDryRun = false
+	_, err = db.ExecVTOrc("update tablet_janitor set quarantine_timestamp = '2000-01-01 00:00:00'")
+	require.NoError(t, err)
+	require.NoError(t, runTabletJanitor(ctx))
+	_, err = ts.GetTablet(ctx, tab102.Alias)
+	require.True(t, topo.IsErrType(err, topo.NoNode), err)
+	sri, err := ts.GetShardReplication(ctx, cell1, keyspace, shard)
+	require.NoError(t, err)
+	for _, node := range sri.Nodes {
+		require.False(t, topoproto.TabletAliasEqual(node.TabletAlias, tab102.Alias))
+	}
+	_, err = ts.GetTablet(ctx, tab100.Alias)
+	require.NoError(t, err, "the primary is never deleted")
+}
@@ -0,0 +1,329 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The tablet type balancer keeps the number of REPLICA and RDONLY tablets of
+// each shard at the targets of --tablet-type-targets, as tablets come and go.
+// A shard short of tablets of a type gets a SPARE tablet, or else a tablet of
+// the other type if that type is above its own target. REPLICA deficits are
+// filled first. A tablet type is never changed when no other type needs the
+// tablet, and never if the change would leave the primary with fewer semi-sync
+// ackers than its durability policy requires, or the shard without a tablet
+// which can be promoted. Only the reachable tablets count, and at most one
+// tablet per shard changes type per cycle.
+
+var (
+	tabletTypeBalancerChangesCounter       = stats.NewCounter("TabletTypeBalancerChanges", "Number of tablet type changes made by the tablet type balancer")
+	tabletTypeBalancerDryRunChangesCounter = stats.NewCounter("TabletTypeBalancerDryRunChanges", "Number of tablet type changes the tablet type balancer would have made without --tablet-type-balancer-dry-run")
+	tabletTypeBalancerBlockedCounter       = stats.NewCounter("TabletTypeBalancerDurabilityBlocks", "Number of tablet type deficits the tablet type balancer could not fill without breaking the durability policy")
+	tabletTypeBalancerErrorsCounter        = stats.NewCounter("TabletTypeBalancerErrors", "Number of errors of the tablet type balancer")
+
+	tabletTypeBalancerEntrance int32
+)
+
+// TabletTypeChange is a change of the type of a tablet planned by the tablet
+// type balancer.
+type TabletTypeChange struct {
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	From        topodatapb.TabletType
+	To          topodatapb.TabletType
+	Reason      string
+}
+
+// balancedTabletTypes are the tablet types with targets, in the order their
+// deficits are filled.
+var balancedTabletTypes = []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY}
+
+// RunTabletTypeBalancer runs a cycle of the tablet type balancer, if it is
+// enabled. It is non re-entrant.
+func RunTabletTypeBalancer() {
+	if len(config.Config.TabletTypeTargets) == 0 {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&tabletTypeBalancerEntrance, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&tabletTypeBalancerEntrance, 0)
+
+	changes, blocked, err := planTabletTypeChanges()
+	if err != nil {
+		tabletTypeBalancerErrorsCounter.Add(1)
+		log.Errorf("tablet type balancer: %v", err)
+		return
+	}
+	tabletTypeBalancerBlockedCounter.Add(int64(blocked))
+	for _, change := range changes {
+		if err := applyTabletTypeChange(context.Background(), change); err != nil {
+			tabletTypeBalancerErrorsCounter.Add(1)
+			log.Errorf("tablet type balancer: cannot change the type of tablet %v to %v: %v", change.TabletAlias, change.To, err)
+		}
+	}
+}
+
+// PlanTabletTypeChanges returns the tablet type changes the next cycle of the
+// tablet type balancer makes, at most one per shard.
+func PlanTabletTypeChanges() ([]*TabletTypeChange, error) {
+	changes, _, err := planTabletTypeChanges()
+	return changes, err
+}
+
+// planTabletTypeChanges also returns the number of deficits which cannot be
+// filled without breaking the durability policy.
+func planTabletTypeChanges() (changes []*TabletTypeChange, blocked int, err error) {
+	shards, err := readBalancedTablets()
+	if err != nil {
+		return nil, 0, err
+	}
+	keys := make([]string, 0, len(shards))
+	for key := range shards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		tablets := shards[key]
+		keyspace, shard := tablets[0].Keyspace, tablets[0].Shard
+		primaryAlias, _, err := inst.ReadShardPrimaryInformation(keyspace, shard)
+		if err != nil {
+			return nil, 0, err
+		}
+		if primaryAlias == "" {
+			// Leave the shards without a primary to the recoveries.
+			continue
+		}
+		primary, err := inst.ReadTablet(primaryAlias)
+		if err != nil {
+			return nil, 0, err
+		}
+		durability, err := inst.GetDurabilityPolicy(keyspace)
+		if err != nil {
+			return nil, 0, err
+		}
+		change, shardBlocked := planShardTabletTypeChange(config.Config.TabletTypeTargets, durability, primary, tablets)
+		if change != nil {
+			changes = append(changes, change)
+		}
+		blocked += shardBlocked
+	}
+	return changes, blocked, nil
+}
+
+// readBalancedTablets reads the reachable REPLICA, RDONLY and SPARE tablets,
+// by keyspace/shard, sorted by alias.
+func readBalancedTablets() (map[string][]*topodatapb.Tablet, error) {
+	query := `
+		select
+			vitess_tablet.info
+		from
+			vitess_tablet
+			join database_instance on (
+				vitess_tablet.alias = database_instance.alias
+			)
+		where
+			vitess_tablet.tablet_type in (?, ?, ?)
+			and database_instance.last_seen is not null
+			and database_instance.last_seen >= database_instance.last_checked
+		order by
+			vitess_tablet.alias`
+	args := sqlutils.Args(int(topodatapb.TabletType_REPLICA), int(topodatapb.TabletType_RDONLY), int(topodatapb.TabletType_SPARE))
+	shards := make(map[string][]*topodatapb.Tablet)
+	opts := prototext.UnmarshalOptions{DiscardUnknown: true}
+	err := db.QueryVTOrc(query, args, func(row sqlutils.RowMap) error {
+		tablet := &topodatapb.Tablet{}
+		if err := opts.Unmarshal([]byte(row.GetString("info")), tablet); err != nil {
+			return err
+		}
+		key := topoproto.KeyspaceShardString(tablet.Keyspace, tablet.Shard)
+		shards[key] = append(shards[key], tablet)
+		return nil
+	})
+	return shards, err
+}
+
+// planShardTabletTypeChange returns the change which fills the first tablet
+// type deficit of the shard without breaking the durability policy, if any,
+// and the number of deficits which cannot be filled because of the policy.
+func planShardTabletTypeChange(targets config.TabletTypeTargets, durability reparentutil.Durabler, primary *topodatapb.Tablet, tablets []*topodatapb.Tablet) (*TabletTypeChange, int) {
+	counts := make(map[topodatapb.TabletType]int)
+	for _, tablet := range tablets {
+		counts[tablet.Type]++
+	}
+	blocked := 0
+	for _, tabletType := range balancedTabletTypes {
+		target, ok := targets.Target(primary.Keyspace, tabletType)
+		if !ok || counts[tabletType] >= target {
+			continue
+		}
+		isBlocked := false
+		for _, candidate := range tabletTypeCandidates(targets, primary.Keyspace, tabletType, counts, tablets) {
+			if reason := tabletTypeChangeBreaksDurability(durability, primary, tablets, candidate, tabletType); reason != "" {
+				isBlocked = true
+				log.Infof("tablet type balancer: not changing the type of tablet %v to %v: %v", topoproto.TabletAliasString(candidate.Alias), tabletType, reason)
+				continue
+			}
+			return &TabletTypeChange{
+				TabletAlias: topoproto.TabletAliasString(candidate.Alias),
+				Keyspace:    candidate.Keyspace,
+				Shard:       candidate.Shard,
+				From:        candidate.Type,
+				To:          tabletType,
+				Reason:      fmt.Sprintf("%d %v tablets, target is %d", counts[tabletType], tabletType, target),
+			}, blocked
+		}
+		if isBlocked {
+			blocked++
+		}
+	}
+	return nil, blocked
+}
+
+// tabletTypeCandidates returns the tablets which can fill a deficit of the
+// tablet type: the SPARE tablets first, then the tablets of the types above
+// their targets.
+func tabletTypeCandidates(targets config.TabletTypeTargets, keyspace string, tabletType topodatapb.TabletType, counts map[topodatapb.TabletType]int, tablets []*topodatapb.Tablet) []*topodatapb.Tablet {
+	var spares, surplus []*topodatapb.Tablet
+	for _, tablet := range tablets {
+		switch {
+		case tablet.Type == topodatapb.TabletType_SPARE:
+			spares = append(spares, tablet)
+		case tablet.Type != tabletType:
+			if target, ok := targets.Target(keyspace, tablet.Type); ok && counts[tablet.Type] > target {
+				surplus = append(surplus, tablet)
+			}
+		}
+	}
+	return append(spares, surplus...)
+}
+
+// tabletTypeChangeBreaksDurability returns why changing the type of the
+// tablet breaks the durability policy of the shard, or an empty string if it
+// does not. A change is allowed if it does not make things worse.
+func tabletTypeChangeBreaksDurability(durability reparentutil.Durabler, primary *topodatapb.Tablet, tablets []*topodatapb.Tablet, tablet *topodatapb.Tablet, tabletType topodatapb.TabletType) string {
+	changed := make([]*topodatapb.Tablet, 0, len(tablets))
+	for _, t := range tablets {
+		if t == tablet {
+			t = tablet.CloneVT()
+			t.Type = tabletType
+		}
+		changed = append(changed, t)
+	}
+	ackersBefore, promotableBefore := durabilityCounts(durability, primary, tablets)
+	ackersAfter, promotableAfter := durabilityCounts(durability, primary, changed)
+	if required := reparentutil.SemiSyncAckers(durability, primary); ackersAfter < required && ackersAfter < ackersBefore {
+		return fmt.Sprintf("the primary would have %d semi-sync ackers, %d required", ackersAfter, required)
+	}
+	if promotableAfter == 0 && promotableBefore > 0 {
+		return "no tablet could be promoted primary"
+	}
+	return ""
+}
+
+// durabilityCounts returns the number of tablets which can ack the writes of
+// the primary, and the number of tablets which can be promoted primary.
+func durabilityCounts(durability reparentutil.Durabler, primary *topodatapb.Tablet, tablets []*topodatapb.Tablet) (ackers int, promotable int) {
+	for _, tablet := range tablets {
+		if reparentutil.IsReplicaSemiSync(durability, primary, tablet) {
+			ackers++
+		}
+		if reparentutil.PromotionRule(durability, tablet) != promotionrule.MustNot {
+			promotable++
+		}
+	}
+	return ackers, promotable
+}
+
+// applyTabletTypeChange changes the type of the tablet, under the shard lock,
+// unless it changed since the change was planned.
+func applyTabletTypeChange(ctx context.Context, change *TabletTypeChange) (err error) {
+	if config.Config.TabletTypeBalancerDryRun {
+		tabletTypeBalancerDryRunChangesCounter.Add(1)
+		log.Infof("tablet type balancer: dry run, not changing the type of tablet %v from %v to %v: %v", change.TabletAlias, change.From, change.To, change.Reason)
+		_ = inst.AuditOperation("tablet-type-balancer-dry-run", change.TabletAlias, fmt.Sprintf("type would be changed from %v to %v: %v", change.From, change.To, change.Reason))
+		return nil
+	}
+
+	ctx, unlock, err := LockShard(ctx, change.TabletAlias, "TabletTypeBalancer")
+	if err != nil {
+		return err
+	}
+	defer unlock(&err)
+
+	alias, err := topoproto.ParseTabletAlias(change.TabletAlias)
+	if err != nil {
+		return err
+	}
+	tabletInfo, err := ts.GetTablet(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if tabletInfo.Type != change.From {
+		log.Infof("tablet type balancer: not changing the type of tablet %v, it is %v now", change.TabletAlias, tabletInfo.Type)
+		return nil
+	}
+	si, err := ts.GetShard(ctx, change.Keyspace, change.Shard)
+	if err != nil {
+		return err
+	}
+	if si.PrimaryAlias == nil {
+		return topo.NewError(topo.NoNode, fmt.Sprintf("primary of %v/%v", change.Keyspace, change.Shard))
+	}
+	primary, err := ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	durability, err := inst.GetDurabilityPolicy(change.Keyspace)
+	if err != nil {
+		return err
+	}
+
+	tablet := tabletInfo.Tablet.CloneVT()
+	tablet.Type = change.To
+	if err = changeTabletType(ctx, tabletInfo.Tablet, change.To, reparentutil.IsReplicaSemiSync(durability, primary.Tablet, tablet)); err != nil {
+		return err
+	}
+	if err = inst.SaveTablet(tablet); err != nil {
+		return err
+	}
+	tabletTypeBalancerChangesCounter.Add(1)
+	log.Infof("tablet type balancer: changed the type of tablet %v from %v to %v: %v", change.TabletAlias, change.From, change.To, change.Reason)
+	_ = inst.AuditOperation("tablet-type-balancer", change.TabletAlias, fmt.Sprintf("type changed from %v to %v: %v", change.From, change.To, change.Reason))
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func balancerTablet(uid uint32, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+	return &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: cell1, Uid: uid},
+		Hostname:      hostname,
+		MysqlHostname: hostname,
+		MysqlPort:     int32(uid),
+		Keyspace:      keyspace,
+		Shard:         shard,
+		Type:          tabletType,
+	}
+}
+
+func TestPlanShardTabletTypeChange(t *testing.T) {
+	primary := balancerTablet(100, topodatapb.TabletType_PRIMARY)
+	tests := []struct {
+		name        string
+		durability  string
+		targets     string
+		tablets     []*topodatapb.Tablet
+		wantAlias   string
+		wantFrom    topodatapb.TabletType
+		wantTo      topodatapb.TabletType
+		wantBlocked int
+	}{
+		{
+			name:       "spare fills a replica deficit",
+			durability: "none",
+			targets:    "replica:2",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_SPARE),
+			},
+			wantAlias: "zone-1-0000000102",
+			wantFrom:  topodatapb.TabletType_SPARE,
+			wantTo:    topodatapb.TabletType_REPLICA,
+		}, {
+			name:       "replica deficit is filled first",
+			durability: "none",
+			targets:    "replica:2,rdonly:1",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_SPARE),
+			},
+			wantAlias: "zone-1-0000000102",
+			wantFrom:  topodatapb.TabletType_SPARE,
+			wantTo:    topodatapb.TabletType_REPLICA,
+		}, {
+			name:       "surplus replica fills an rdonly deficit",
+			durability: "semi_sync",
+			targets:    "replica:2,rdonly:1",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_REPLICA),
+				balancerTablet(103, topodatapb.TabletType_REPLICA),
+			},
+			wantAlias: "zone-1-0000000101",
+			wantFrom:  topodatapb.TabletType_REPLICA,
+			wantTo:    topodatapb.TabletType_RDONLY,
+		}, {
+			name:       "keyspace target overrides the global one",
+			durability: "none",
+			targets:    "rdonly:0,ks/rdonly:1,replica:1",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_REPLICA),
+			},
+			wantAlias: "zone-1-0000000101",
+			wantFrom:  topodatapb.TabletType_REPLICA,
+			wantTo:    topodatapb.TabletType_RDONLY,
+		}, {
+			name:       "surplus without a deficit is left alone",
+			durability: "none",
+			targets:    "replica:1,rdonly:0",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_REPLICA),
+				balancerTablet(103, topodatapb.TabletType_RDONLY),
+			},
+		}, {
+			name:       "semi-sync ackers are kept",
+			durability: "semi_sync",
+			targets:    "replica:0,rdonly:1",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+			},
+			wantBlocked: 1,
+		}, {
+			name:       "a promotable tablet is kept",
+			durability: "none",
+			targets:    "replica:0,rdonly:2",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_RDONLY),
+			},
+			wantBlocked: 1,
+		}, {
+			name:       "untargeted types are not used",
+			durability: "none",
+			targets:    "rdonly:1",
+			tablets: []*topodatapb.Tablet{
+				balancerTablet(101, topodatapb.TabletType_REPLICA),
+				balancerTablet(102, topodatapb.TabletType_REPLICA),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var targets config.TabletTypeTargets
+			require.NoError(t, targets.Set(tt.targets))
+			durability, err := reparentutil.GetDurabilityPolicy(tt.durability)
+			require.NoError(t, err)
+
+			change, blocked := planShardTabletTypeChange(targets, durability, primary, tt.tablets)
+			require.Equal(t, tt.wantBlocked, blocked)
+			if tt.wantAlias == "" {
+				require.Nil(t, change)
+				return
+			}
+			require.NotNil(t, change)
+			require.Equal(t, tt.wantAlias, change.TabletAlias)
+			require.Equal(t, tt.wantFrom, change.From)
+			require.Equal(t, tt.wantTo, change.To)
+		})
+	}
+}
+
+func TestTabletTypeBalancer(t *testing.T) {
+	oldTs, oldTmc := ts, tmc
+	oldConfig := *config.Config
+	defer func() {
+		ts, tmc = oldTs, oldTmc
+		*config.Config = oldConfig
+		db.ClearVTOrcDatabase()
+	}()
+	require.NoError(t, config.Config.TabletTypeTargets.Set("replica:2,rdonly:1"))
+	config.Config.TabletTypeBalancerDryRun = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	tmc = &testutil.TabletManagerClient{TopoServer: ts}
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, ts.CreateShard(ctx, keyspace, shard))
+	keyspaceInfo := &topo.KeyspaceInfo{Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}}
+	keyspaceInfo.SetKeyspaceName(keyspace)
+	require.NoError(t, inst.SaveKeyspace(keyspaceInfo))
+	primary := balancerTablet(100, topodatapb.TabletType_PRIMARY)
+	require.NoError(t, inst.SaveShard(topo.NewShardInfo(keyspace, shard, &topodatapb.Shard{PrimaryAlias: primary.Alias}, nil)))
+	_, err := ts.UpdateShardFields(ctx, keyspace, shard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primary.Alias
+		return nil
+	})
+	require.NoError(t, err)
+
+	// tab101 is a replica, tab102 a spare and tab103 an unreachable spare.
+	spare := balancerTablet(102, topodatapb.TabletType_SPARE)
+	tablets := []*topodatapb.Tablet{
+		primary,
+		balancerTablet(101, topodatapb.TabletType_REPLICA),
+		spare,
+		balancerTablet(103, topodatapb.TabletType_SPARE),
+	}
+	for _, tablet := range tablets {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+		require.NoError(t, inst.SaveTablet(tablet))
+	}
+	for _, tablet := range tablets[:3] {
+		require.NoError(t, inst.WriteInstance(&inst.Instance{InstanceAlias: topoproto.TabletAliasString(tablet.Alias), Hostname: hostname, Port: int(tablet.MysqlPort)}, true, nil))
+	}
+
+	changes, err := PlanTabletTypeChanges()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "zone-1-0000000102", changes[0].TabletAlias)
+	require.Equal(t, topodatapb.TabletType_REPLICA, changes[0].To)
+
+	// The dry run doesn't change anything.
+	dryRunChanges := tabletTypeBalancerDryRunChangesCounter.Get()
+	RunTabletTypeBalancer()
+	require.EqualValues(t, dryRunChanges+1, tabletTypeBalancerDryRunChangesCounter.Get())
+	tabletInfo, err := ts.GetTablet(ctx, spare.Alias)
+	require.NoError(t, err)
+	require.Equal(t, topodatapb.TabletType_SPARE, tabletInfo.Type)
+
+	// The spare becomes a replica, and there is no tablet left for the rdonly target.
+	config.Config.TabletTypeBalancerDryRun = false
+	balancerChanges := tabletTypeBalancerChangesCounter.Get()
+	RunTabletTypeBalancer()
+	require.EqualValues(t, balancerChanges+1, tabletTypeBalancerChangesCounter.Get())
+	tabletInfo, err = ts.GetTablet(ctx, spare.Alias)
+	require.NoError(t, err)
+	require.Equal(t, topodatapb.TabletType_REPLICA, tabletInfo.Type)
+	tablet, err := inst.ReadTablet(topoproto.TabletAliasString(spare.Alias))
+	require.NoError(t, err)
+	require.Equal(t, topodatapb.TabletType_REPLICA, tablet.Type)
+
+	changes, err = PlanTabletTypeChanges()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -255,15 +255,23 @@ func writeTopologyRecoveryStep(topologyRecoveryStep *TopologyRecoveryStep) error
 
 // ExpireRecoveryDetectionHistory removes old rows from the recovery_detection table
 func ExpireRecoveryDetectionHistory() error {
-	return inst.ExpireTableData("recovery_detection", "detection_timestamp")
+	return inst.ExpireTableDataAfterDays("recovery_detection", "detection_timestamp", recoveryHistoryRetentionDays())
 }
 
 // ExpireTopologyRecoveryHistory removes old rows from the topology_recovery table
 func ExpireTopologyRecoveryHistory() error {
-	return inst.ExpireTableData("topology_recovery", "start_recovery")
+	return inst.ExpireTableDataAfterDays("topology_recovery", "start_recovery", recoveryHistoryRetentionDays())
 }
 
 // ExpireTopologyRecoveryStepsHistory removes old rows from the topology_recovery_steps table
 func ExpireTopologyRecoveryStepsHistory() error {
-	return inst.ExpireTableData("topology_recovery_steps", "audit_at")
+	return inst.ExpireTableDataAfterDays("topology_recovery_steps", "audit_at", recoveryHistoryRetentionDays())
+}
+
+// recoveryHistoryRetentionDays returns the number of days for which the detections and recoveries are held.
+func recoveryHistoryRetentionDays() uint {
+	if config.Config.RecoveryHistoryRetentionDays > 0 {
+		return config.Config.RecoveryHistoryRetentionDays
+	}
+	return config.Config.AuditPurgeDays
 }
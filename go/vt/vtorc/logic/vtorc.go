@@ -308,6 +308,8 @@ func ContinuousDiscovery() {
 				go ExpireRecoveryDetectionHistory()
 				go ExpireTopologyRecoveryHistory()
 				go ExpireTopologyRecoveryStepsHistory()
+				go RunTabletJanitor()
+				go RunTabletTypeBalancer()
 			}()
 		case <-recoveryTick:
 			go func() {
@@ -46,11 +46,19 @@ const (
 	enableGlobalRecoveriesAPI     = "/api/enable-global-recoveries"
 	replicationAnalysisAPI        = "/api/replication-analysis"
 	databaseStateAPI              = "/api/database-state"
+	recoveriesAPI                 = "/api/recoveries"
+	tabletJanitorAPI              = "/api/tablet-janitor"
+	tabletTypeBalancerAPI         = "/api/tablet-type-balancer"
 	healthAPI                     = "/debug/health"
 	AggregatedDiscoveryMetricsAPI = "/api/aggregated-discovery-metrics"
 
 	shardWithoutKeyspaceFilteringErrorStr = "Filtering by shard without keyspace isn't supported"
 	notAValidValueForSeconds              = "Invalid value for seconds"
+	notAValidValueForSince                = "Invalid value for since"
+	notAValidValueForLimit                = "Invalid value for limit"
+
+	// defaultRecoveriesLimit is the number of recoveries returned by the recoveriesAPI when no limit is provided.
+	defaultRecoveriesLimit = 50
 )
 
 var (
@@ -62,6 +70,9 @@ var (
 		enableGlobalRecoveriesAPI,
 		replicationAnalysisAPI,
 		databaseStateAPI,
+		recoveriesAPI,
+		tabletJanitorAPI,
+		tabletTypeBalancerAPI,
 		healthAPI,
 		AggregatedDiscoveryMetricsAPI,
 	}
@@ -90,6 +101,12 @@ func (v *vtorcAPI) ServeHTTP(response http.ResponseWriter, request *http.Request
 		replicationAnalysisAPIHandler(response, request)
 	case databaseStateAPI:
 		databaseStateAPIHandler(response)
+	case recoveriesAPI:
+		recoveriesAPIHandler(response, request)
+	case tabletJanitorAPI:
+		tabletJanitorAPIHandler(response)
+	case tabletTypeBalancerAPI:
+		tabletTypeBalancerAPIHandler(response)
 	case AggregatedDiscoveryMetricsAPI:
 		AggregatedDiscoveryMetricsAPIHandler(response, request)
 	default:
@@ -106,7 +123,7 @@ func getACLPermissionLevelForAPI(apiEndpoint string) string {
 		return acl.MONITORING
 	case disableGlobalRecoveriesAPI, enableGlobalRecoveriesAPI:
 		return acl.ADMIN
-	case replicationAnalysisAPI:
+	case replicationAnalysisAPI, recoveriesAPI, tabletJanitorAPI, tabletTypeBalancerAPI:
 		return acl.MONITORING
 	case healthAPI, databaseStateAPI:
 		return acl.MONITORING
@@ -245,6 +262,63 @@ func replicationAnalysisAPIHandler(response http.ResponseWriter, request *http.R
 	returnAsJSON(response, http.StatusOK, analysis)
 }
 
+// recoveriesAPIHandler is the handler for the recoveriesAPI endpoint
+func recoveriesAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api also supports filtering by shard and keyspace provided, the recoveries started
+	// in the given duration, and the maximum number of recoveries to return.
+	filter := logic.RecoveryTimelineFilter{
+		Keyspace: request.URL.Query().Get("keyspace"),
+		Shard:    request.URL.Query().Get("shard"),
+		Limit:    defaultRecoveriesLimit,
+	}
+	if filter.Shard != "" && filter.Keyspace == "" {
+		http.Error(response, shardWithoutKeyspaceFilteringErrorStr, http.StatusBadRequest)
+		return
+	}
+	if since := request.URL.Query().Get("since"); since != "" {
+		var err error
+		filter.Since, err = time.ParseDuration(since)
+		if err != nil || filter.Since < 0 {
+			http.Error(response, notAValidValueForSince, http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 0 {
+			http.Error(response, notAValidValueForLimit, http.StatusBadRequest)
+			return
+		}
+	}
+	timelines, err := logic.ReadRecoveryTimelines(filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, timelines)
+}
+
+// tabletJanitorAPIHandler is the handler for the tabletJanitorAPI endpoint
+func tabletJanitorAPIHandler(response http.ResponseWriter) {
+	tablets, err := logic.ReadJanitorTablets()
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, tablets)
+}
+
+// tabletTypeBalancerAPIHandler is the handler for the tabletTypeBalancerAPI endpoint
+func tabletTypeBalancerAPIHandler(response http.ResponseWriter) {
+	changes, err := logic.PlanTabletTypeChanges()
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, changes)
+}
+
 // healthAPIHandler is the handler for the healthAPI endpoint
 func healthAPIHandler(response http.ResponseWriter, request *http.Request) {
 	health, discoveredOnce := process.HealthTest()
@@ -28,6 +28,15 @@ func TestGetACLPermissionLevelForAPI(t *testing.T) {
 		}, {
 			apiEndpoint: replicationAnalysisAPI,
 			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: recoveriesAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: tabletJanitorAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: tabletTypeBalancerAPI,
+			want:        acl.MONITORING,
 		}, {
 			apiEndpoint: healthAPI,
 			want:        acl.MONITORING,
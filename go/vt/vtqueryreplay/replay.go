@@ -0,0 +1,343 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtqueryreplay replays the queries captured in the JSON query logs
+// of vtgate against a target cluster, and compares their results with the
+// recorded ones.
+package vtqueryreplay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtgate/logstats"
+)
+
+// logTimeFormat is the format of the Start and End fields of the query logs.
+const logTimeFormat = "2006-01-02 15:04:05.000000"
+
+// Entry is a query read from a vtgate query log in the JSON format.
+type Entry struct {
+	// Line is the line of the entry in the query log.
+	Line int `json:"-"`
+	// StartTime is the time the query was received by vtgate.
+	StartTime time.Time `json:"-"`
+
+	Method         string
+	Start          string
+	SQL            string
+	BindVars       json.RawMessage
+	SessionUUID    string
+	ActiveKeyspace string
+	Error          string
+	RowsAffected   uint64
+	// RowsReturned is nil for the logs of vtgates which did not record it.
+	RowsReturned *uint64
+	// ResultChecksum is only recorded by vtgates running with
+	// --querylog-result-checksum.
+	ResultChecksum string
+}
+
+// replayable returns whether the entry is a query which can be replayed.
+// Queries executed with bind variables are not, as the logs don't record
+// their types.
+func (e *Entry) replayable() bool {
+	if e.Method != "Execute" && e.Method != "StreamExecute" {
+		return false
+	}
+	if e.SQL == "" {
+		return false
+	}
+	var bindVars map[string]json.RawMessage
+	if err := json.Unmarshal(e.BindVars, &bindVars); err == nil && len(bindVars) > 0 {
+		return false
+	}
+	return true
+}
+
+// ReadLog reads the entries of a vtgate query log in the JSON format, ordered
+// by the time vtgate received them.
+func ReadLog(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		entry := &Entry{Line: line}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		startTime, err := time.Parse(logTimeFormat, entry.Start)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid Start: %v", line, err)
+		}
+		entry.StartTime = startTime
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// The queries are logged when they complete.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartTime.Before(entries[j].StartTime)
+	})
+	return entries, nil
+}
+
+// Conn executes the queries of a replayed session.
+type Conn interface {
+	ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error)
+	Close()
+}
+
+// Dialer opens a connection to the target cluster.
+type Dialer func(ctx context.Context) (Conn, error)
+
+// Options control how the queries are replayed.
+type Options struct {
+	// Speed is the speed of the replay relative to the recorded traffic, e.g.
+	// 2 replays the queries twice as fast. 0 replays them as fast as possible.
+	Speed float64
+	// MaxRows is the maximum number of rows fetched for a query.
+	MaxRows int
+	// MaxDivergences is the maximum number of divergences kept in the report.
+	// 0 keeps them all.
+	MaxDivergences int
+}
+
+// Divergence describes a replayed query whose result differs from the
+// recorded one.
+type Divergence struct {
+	Line        int
+	SessionUUID string
+	SQL         string
+	Messages    []string
+}
+
+// Report summarizes a replay.
+type Report struct {
+	// Queries is the number of replayed queries.
+	Queries int
+	// Skipped is the number of entries of the log which could not be replayed.
+	Skipped int
+	// Errors is the number of replayed queries which failed.
+	Errors int
+	// Diverged is the number of replayed queries whose result differs from
+	// the recorded one.
+	Diverged int
+	// Divergences lists the first divergences, up to Options.MaxDivergences.
+	Divergences []*Divergence
+	// MaxLag is the longest delay of a query with regards to its scheduled time.
+	MaxLag   time.Duration
+	Duration time.Duration
+}
+
+// Replay replays the entries against the target cluster. The queries of a
+// session are executed in order on a connection of their own, and sessions
+// are replayed concurrently. Each query is executed at the time it was
+// recorded, relative to the first query and scaled by the speed.
+func Replay(ctx context.Context, dial Dialer, entries []*Entry, opts Options) (*Report, error) {
+	report := &Report{}
+	sessions := make(map[string][]*Entry)
+	var order []string
+	for _, e := range entries {
+		if !e.replayable() {
+			report.Skipped++
+			continue
+		}
+		if _, ok := sessions[e.SessionUUID]; !ok {
+			order = append(order, e.SessionUUID)
+		}
+		sessions[e.SessionUUID] = append(sessions[e.SessionUUID], e)
+	}
+	if len(order) == 0 {
+		return report, nil
+	}
+
+	var logStart time.Time
+	for _, session := range order {
+		if first := sessions[session][0].StartTime; logStart.IsZero() || first.Before(logStart) {
+			logStart = first
+		}
+	}
+
+	r := &replayer{
+		dial:     dial,
+		opts:     opts,
+		start:    time.Now(),
+		logStart: logStart,
+		report:   report,
+	}
+	var wg sync.WaitGroup
+	for _, session := range order {
+		wg.Add(1)
+		go func(entries []*Entry) {
+			defer wg.Done()
+			r.replaySession(ctx, entries)
+		}(sessions[session])
+	}
+	wg.Wait()
+	report.Duration = time.Since(r.start)
+
+	sort.Slice(report.Divergences, func(i, j int) bool {
+		return report.Divergences[i].Line < report.Divergences[j].Line
+	})
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if r.dialErr != nil {
+		return report, r.dialErr
+	}
+	return report, nil
+}
+
+type replayer struct {
+	dial     Dialer
+	opts     Options
+	start    time.Time
+	logStart time.Time
+
+	mu      sync.Mutex
+	report  *Report
+	dialErr error
+}
+
+func (r *replayer) replaySession(ctx context.Context, entries []*Entry) {
+	var (
+		conn     Conn
+		keyspace string
+	)
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for _, e := range entries {
+		lag, err := r.wait(ctx, e)
+		if err != nil {
+			return
+		}
+		if conn == nil {
+			conn, err = r.dial(ctx)
+			if err != nil {
+				r.mu.Lock()
+				if r.dialErr == nil {
+					r.dialErr = fmt.Errorf("cannot connect to replay session %s: %v", e.SessionUUID, err)
+				}
+				r.mu.Unlock()
+				return
+			}
+		}
+		if e.ActiveKeyspace != "" && e.ActiveKeyspace != keyspace {
+			if _, err := conn.ExecuteFetch("use "+sqlescape.EscapeID(e.ActiveKeyspace), 0, false); err != nil {
+				log.Warningf("cannot use keyspace %s to replay line %d: %v", e.ActiveKeyspace, e.Line, err)
+			} else {
+				keyspace = e.ActiveKeyspace
+			}
+		}
+		qr, err := conn.ExecuteFetch(e.SQL, r.opts.MaxRows, false)
+		r.record(e, lag, Compare(e, qr, err), err)
+	}
+}
+
+// wait blocks until the scheduled time of the entry, and returns how late
+// the entry is.
+func (r *replayer) wait(ctx context.Context, e *Entry) (time.Duration, error) {
+	if r.opts.Speed <= 0 {
+		return 0, ctx.Err()
+	}
+	scheduled := r.start.Add(time.Duration(float64(e.StartTime.Sub(r.logStart)) / r.opts.Speed))
+	delay := time.Until(scheduled)
+	if delay <= 0 {
+		return -delay, ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+		return 0, nil
+	}
+}
+
+func (r *replayer) record(e *Entry, lag time.Duration, messages []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.Queries++
+	if err != nil {
+		r.report.Errors++
+	}
+	if lag > r.report.MaxLag {
+		r.report.MaxLag = lag
+	}
+	if len(messages) == 0 {
+		return
+	}
+	r.report.Diverged++
+	if r.opts.MaxDivergences > 0 && len(r.report.Divergences) >= r.opts.MaxDivergences {
+		return
+	}
+	r.report.Divergences = append(r.report.Divergences, &Divergence{
+		Line:        e.Line,
+		SessionUUID: e.SessionUUID,
+		SQL:         e.SQL,
+		Messages:    messages,
+	})
+}
+
+// Compare compares the result of a replayed query with the recorded one, and
+// returns the differences. The error messages are not compared, as they
+// depend on the target cluster.
+func Compare(e *Entry, qr *sqltypes.Result, err error) []string {
+	if err != nil {
+		if e.Error == "" {
+			return []string{fmt.Sprintf("query failed: %v", err)}
+		}
+		return nil
+	}
+	if e.Error != "" {
+		return []string{fmt.Sprintf("query succeeded but had failed with: %s", e.Error)}
+	}
+
+	var messages []string
+	if qr.RowsAffected != e.RowsAffected {
+		messages = append(messages, fmt.Sprintf("rows affected: recorded %d, replayed %d", e.RowsAffected, qr.RowsAffected))
+	}
+	if e.RowsReturned != nil && uint64(len(qr.Rows)) != *e.RowsReturned {
+		messages = append(messages, fmt.Sprintf("rows returned: recorded %d, replayed %d", *e.RowsReturned, len(qr.Rows)))
+	}
+	if e.ResultChecksum != "" {
+		if checksum := logstats.ResultChecksum(qr.Rows); checksum != e.ResultChecksum {
+			messages = append(messages, fmt.Sprintf("result checksum: recorded %s, replayed %s", e.ResultChecksum, checksum))
+		}
+	}
+	return messages
+}
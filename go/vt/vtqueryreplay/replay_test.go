@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtqueryreplay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/logstats"
+)
+
+// fakeCluster serves canned results, and records the queries executed on
+// each connection.
+type fakeCluster struct {
+	mu      sync.Mutex
+	results map[string]*sqltypes.Result
+	conns   [][]string
+}
+
+type fakeConn struct {
+	cluster *fakeCluster
+	id      int
+}
+
+func (c *fakeCluster) dial(ctx context.Context) (Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns = append(c.conns, nil)
+	return &fakeConn{cluster: c, id: len(c.conns) - 1}, nil
+}
+
+func (c *fakeConn) ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
+	c.cluster.mu.Lock()
+	defer c.cluster.mu.Unlock()
+	c.cluster.conns[c.id] = append(c.cluster.conns[c.id], query)
+	if strings.HasPrefix(query, "use ") {
+		return &sqltypes.Result{}, nil
+	}
+	qr, ok := c.cluster.results[query]
+	if !ok {
+		return nil, errors.New("table not found")
+	}
+	return qr, nil
+}
+
+func (c *fakeConn) Close() {}
+
+const testLog = `{"Method": "Execute", "Start": "2024-01-01 00:00:00.100000", "SQL": "select * from t1", "BindVars": {}, "SessionUUID": "s1", "ActiveKeyspace": "ks", "Error": "", "RowsAffected": 0, "RowsReturned": 2, "ResultChecksum": "%s"}
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.000000", "SQL": "insert into t1 values (3)", "BindVars": {}, "SessionUUID": "s2", "ActiveKeyspace": "", "Error": "", "RowsAffected": 1}
+
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.200000", "SQL": "select * from t2", "BindVars": {}, "SessionUUID": "s1", "ActiveKeyspace": "ks", "Error": "", "RowsAffected": 0, "RowsReturned": 1}
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.300000", "SQL": "select * from t1 where id = :id", "BindVars": {"id": {"type": "INT64", "value": 1}}, "SessionUUID": "s2"}
+{"Method": "Prepare", "Start": "2024-01-01 00:00:00.300000", "SQL": "select * from t1", "BindVars": {}, "SessionUUID": "s2"}
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.400000", "SQL": "select * from t3", "BindVars": {}, "SessionUUID": "s2", "Error": "table not found"}
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.500000", "SQL": "delete from t1", "BindVars": {}, "SessionUUID": "s2", "Error": "", "RowsAffected": 3}
+{"Method": "Execute", "Start": "2024-01-01 00:00:00.600000", "SQL": "select * from t4", "BindVars": {}, "SessionUUID": "s2", "Error": ""}
+`
+
+func TestReplay(t *testing.T) {
+	t1Rows := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")
+	cluster := &fakeCluster{
+		results: map[string]*sqltypes.Result{
+			"select * from t1":          t1Rows,
+			"insert into t1 values (3)": {RowsAffected: 1},
+			"select * from t2":          sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2"),
+			"delete from t1":            {RowsAffected: 2},
+		},
+	}
+	checksum := logstats.ResultChecksum(t1Rows.Rows)
+
+	entries, err := ReadLog(strings.NewReader(strings.Replace(testLog, "%s", checksum, 1)))
+	require.NoError(t, err)
+	require.Len(t, entries, 8)
+	assert.Equal(t, 2, entries[0].Line, "entries are ordered by start time")
+
+	report, err := Replay(context.Background(), cluster.dial, entries, Options{Speed: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Queries)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 2, report.Errors)
+	assert.Equal(t, 3, report.Diverged)
+	assert.GreaterOrEqual(t, report.Duration, 50*time.Millisecond)
+	require.Len(t, report.Divergences, 3)
+	assert.Equal(t, &Divergence{Line: 4, SessionUUID: "s1", SQL: "select * from t2", Messages: []string{"rows returned: recorded 1, replayed 2"}}, report.Divergences[0])
+	assert.Equal(t, &Divergence{Line: 8, SessionUUID: "s2", SQL: "delete from t1", Messages: []string{"rows affected: recorded 3, replayed 2"}}, report.Divergences[1])
+	assert.Equal(t, &Divergence{Line: 9, SessionUUID: "s2", SQL: "select * from t4", Messages: []string{"query failed: table not found"}}, report.Divergences[2])
+
+	// Each session is replayed in order on its own connection.
+	require.Len(t, cluster.conns, 2)
+	assert.ElementsMatch(t, [][]string{
+		{"insert into t1 values (3)", "select * from t3", "delete from t1", "select * from t4"},
+		{"use `ks`", "select * from t1", "select * from t2"},
+	}, cluster.conns)
+
+	report, err = Replay(context.Background(), cluster.dial, entries, Options{MaxDivergences: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Diverged)
+	assert.Len(t, report.Divergences, 1)
+}
+
+func TestCompare(t *testing.T) {
+	rowsReturned := uint64(1)
+	qr := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	entry := &Entry{RowsReturned: &rowsReturned, ResultChecksum: logstats.ResultChecksum(qr.Rows)}
+	assert.Empty(t, Compare(entry, qr, nil))
+
+	other := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "2")
+	assert.Equal(t, []string{"result checksum: recorded " + entry.ResultChecksum + ", replayed " + logstats.ResultChecksum(other.Rows)}, Compare(entry, other, nil))
+
+	assert.Empty(t, Compare(&Entry{Error: "duplicate key"}, nil, errors.New("Duplicate entry")))
+	assert.Equal(t, []string{"query succeeded but had failed with: duplicate key"}, Compare(&Entry{Error: "duplicate key"}, &sqltypes.Result{}, nil))
+}
+
+func TestReadLogErrors(t *testing.T) {
+	_, err := ReadLog(strings.NewReader("{\"Start\": \"2024-01-01 00:00:00.000000\"}\nselect 1"))
+	require.ErrorContains(t, err, "line 2: ")
+
+	_, err = ReadLog(strings.NewReader(`{"Start": "yesterday"}`))
+	require.ErrorContains(t, err, "line 1: invalid Start: ")
+}
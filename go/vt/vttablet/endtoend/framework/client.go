@@ -178,6 +178,11 @@ func (client *QueryClient) ReadTransaction(dtid string) (*querypb.TransactionMet
 	return client.server.ReadTransaction(client.ctx, client.target, dtid)
 }
 
+// TransactionLockWaits returns the lock waits between the transactions of the tablet.
+func (client *QueryClient) TransactionLockWaits() ([]*querypb.TransactionLockWait, error) {
+	return client.server.TransactionLockWaits(client.ctx, client.target)
+}
+
 // SetServingType is for testing transitions.
 // It currently supports only primary->replica and back.
 func (client *QueryClient) SetServingType(tabletType topodatapb.TabletType) error {
@@ -133,6 +133,11 @@ func (client *FakeTabletManagerClient) GetGlobalStatusVars(ctx context.Context,
 	return make(map[string]string), nil
 }
 
+// SelfCheck is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) SelfCheck(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.SelfCheckResponse, error) {
+	return &tabletmanagerdatapb.SelfCheckResponse{}, nil
+}
+
 // LockTables is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) LockTables(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return nil
@@ -365,6 +370,13 @@ func (client *FakeTabletManagerClient) RestoreFromBackup(ctx context.Context, ta
 	return &eofEventStream{}, nil
 }
 
+// MySQL related methods
+
+// RestartMySQL is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) RestartMySQL(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestartMySQLRequest) (logutil.EventStream, error) {
+	return &eofEventStream{}, nil
+}
+
 // Throttler related methods
 
 func (client *FakeTabletManagerClient) CheckThrottler(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.CheckThrottlerRequest) (*tabletmanagerdatapb.CheckThrottlerResponse, error) {
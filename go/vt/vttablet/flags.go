@@ -37,6 +37,7 @@ var (
 	VReplicationNetReadTimeout    = 300
 	VReplicationNetWriteTimeout   = 600
 	CopyPhaseDuration             = 1 * time.Hour
+	VDiffMaxMemorySortRows        = 1000000
 )
 
 func init() {
@@ -51,4 +52,5 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&VReplicationNetReadTimeout, "vreplication_net_read_timeout", VReplicationNetReadTimeout, "Session value of net_read_timeout for vreplication, in seconds")
 	fs.IntVar(&VReplicationNetWriteTimeout, "vreplication_net_write_timeout", VReplicationNetWriteTimeout, "Session value of net_write_timeout for vreplication, in seconds")
 	fs.DurationVar(&CopyPhaseDuration, "vreplication_copy_phase_duration", CopyPhaseDuration, "Duration for each copy phase loop (before running the next catchup: default 1h)")
+	fs.IntVar(&VDiffMaxMemorySortRows, "vdiff-max-memory-sort-rows", VDiffMaxMemorySortRows, "Maximum number of source rows that a VDiff sorts in memory, as for the lookup table of a CreateLookupIndex workflow")
 }
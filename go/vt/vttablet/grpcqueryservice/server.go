@@ -233,6 +233,35 @@ func (q *query) ReadTransaction(ctx context.Context, request *querypb.ReadTransa
 	return &querypb.ReadTransactionResponse{Metadata: result}, nil
 }
 
+// TransactionLockWaits is part of the queryservice.QueryServer interface
+func (q *query) TransactionLockWaits(ctx context.Context, request *querypb.TransactionLockWaitsRequest) (response *querypb.TransactionLockWaitsResponse, err error) {
+	defer q.server.HandlePanic(&err)
+	ctx = callerid.NewContext(callinfo.GRPCCallInfo(ctx),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	lockWaits, err := q.server.TransactionLockWaits(ctx, request.Target)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+
+	return &querypb.TransactionLockWaitsResponse{LockWaits: lockWaits}, nil
+}
+
+// AbortTransaction is part of the queryservice.QueryServer interface
+func (q *query) AbortTransaction(ctx context.Context, request *querypb.AbortTransactionRequest) (response *querypb.AbortTransactionResponse, err error) {
+	defer q.server.HandlePanic(&err)
+	ctx = callerid.NewContext(callinfo.GRPCCallInfo(ctx),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	if err := q.server.AbortTransaction(ctx, request.Target, request.TransactionId, request.Reason); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+
+	return &querypb.AbortTransactionResponse{}, nil
+}
+
 // BeginExecute is part of the queryservice.QueryServer interface
 func (q *query) BeginExecute(ctx context.Context, request *querypb.BeginExecuteRequest) (response *querypb.BeginExecuteResponse, err error) {
 	defer q.server.HandlePanic(&err)
@@ -373,6 +402,35 @@ func (q *query) VStreamResults(request *binlogdatapb.VStreamResultsRequest, stre
 	return vterrors.ToGRPC(err)
 }
 
+// SaveVStreamPosition is part of the queryservice.QueryServer interface
+func (q *query) SaveVStreamPosition(ctx context.Context, request *binlogdatapb.SaveVStreamPositionRequest) (response *binlogdatapb.SaveVStreamPositionResponse, err error) {
+	defer q.server.HandlePanic(&err)
+	ctx = callerid.NewContext(callinfo.GRPCCallInfo(ctx),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	if err := q.server.SaveVStreamPosition(ctx, request.Target, request.ConsumerGroup, request.ShardGtid); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+
+	return &binlogdatapb.SaveVStreamPositionResponse{}, nil
+}
+
+// ReadVStreamPosition is part of the queryservice.QueryServer interface
+func (q *query) ReadVStreamPosition(ctx context.Context, request *binlogdatapb.ReadVStreamPositionRequest) (response *binlogdatapb.ReadVStreamPositionResponse, err error) {
+	defer q.server.HandlePanic(&err)
+	ctx = callerid.NewContext(callinfo.GRPCCallInfo(ctx),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	shardGtid, err := q.server.ReadVStreamPosition(ctx, request.Target, request.ConsumerGroup)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+
+	return &binlogdatapb.ReadVStreamPositionResponse{ShardGtid: shardGtid}, nil
+}
+
 // ReserveExecute implements the QueryServer interface
 func (q *query) ReserveExecute(ctx context.Context, request *querypb.ReserveExecuteRequest) (response *querypb.ReserveExecuteResponse, err error) {
 	defer q.server.HandlePanic(&err)
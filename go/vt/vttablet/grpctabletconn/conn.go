@@ -41,11 +41,12 @@ import (
 const protocolName = "grpc"
 
 var (
-	cert string
-	key  string
-	ca   string
-	crl  string
-	name string
+	cert      string
+	key       string
+	ca        string
+	crl       string
+	name      string
+	spiffeIDs []string
 )
 
 func registerFlags(fs *pflag.FlagSet) {
@@ -54,6 +55,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca, "tablet_grpc_ca", ca, "the server ca to use to validate servers when connecting")
 	fs.StringVar(&crl, "tablet_grpc_crl", crl, "the server crl to use to validate server certificates when connecting")
 	fs.StringVar(&name, "tablet_grpc_server_name", name, "the server name to use to validate server certificate")
+	fs.StringSliceVar(&spiffeIDs, "tablet_grpc_spiffe_ids", spiffeIDs, "comma-separated list of SPIFFE IDs allowed in the server certificate, checked instead of the server name. An ID ending with /* allows all the IDs under it")
 }
 
 func init() {
@@ -91,7 +93,7 @@ func DialTablet(ctx context.Context, tablet *topodatapb.Tablet, failFast grpccli
 	} else {
 		addr = tablet.Hostname
 	}
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -438,6 +440,48 @@ func (conn *gRPCQueryClient) ReadTransaction(ctx context.Context, target *queryp
 	return response.Metadata, nil
 }
 
+// TransactionLockWaits returns the lock waits between the transactions of the tablet.
+func (conn *gRPCQueryClient) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+	if conn.cc == nil {
+		return nil, tabletconn.ConnClosed
+	}
+
+	req := &querypb.TransactionLockWaitsRequest{
+		Target:            target,
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
+	}
+	response, err := conn.c.TransactionLockWaits(ctx, req)
+	if err != nil {
+		return nil, tabletconn.ErrorFromGRPC(err)
+	}
+	return response.LockWaits, nil
+}
+
+// AbortTransaction kills the connection of the specified transaction.
+func (conn *gRPCQueryClient) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+	if conn.cc == nil {
+		return tabletconn.ConnClosed
+	}
+
+	req := &querypb.AbortTransactionRequest{
+		Target:            target,
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
+		TransactionId:     transactionID,
+		Reason:            reason,
+	}
+	_, err := conn.c.AbortTransaction(ctx, req)
+	if err != nil {
+		return tabletconn.ErrorFromGRPC(err)
+	}
+	return nil
+}
+
 // BeginExecute starts a transaction and runs an Execute.
 func (conn *gRPCQueryClient) BeginExecute(ctx context.Context, target *querypb.Target, preQueries []string, query string, bindVars map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions) (state queryservice.TransactionState, result *sqltypes.Result, err error) {
 	conn.mu.RLock()
@@ -831,6 +875,49 @@ func (conn *gRPCQueryClient) VStreamResults(ctx context.Context, target *querypb
 	}
 }
 
+// SaveVStreamPosition persists the position acknowledged by a VStream consumer group.
+func (conn *gRPCQueryClient) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+	if conn.cc == nil {
+		return tabletconn.ConnClosed
+	}
+
+	req := &binlogdatapb.SaveVStreamPositionRequest{
+		Target:            target,
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
+		ConsumerGroup:     consumerGroup,
+		ShardGtid:         shardGtid,
+	}
+	_, err := conn.c.SaveVStreamPosition(ctx, req)
+	if err != nil {
+		return tabletconn.ErrorFromGRPC(err)
+	}
+	return nil
+}
+
+// ReadVStreamPosition returns the position acknowledged by a VStream consumer group.
+func (conn *gRPCQueryClient) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+	if conn.cc == nil {
+		return nil, tabletconn.ConnClosed
+	}
+
+	req := &binlogdatapb.ReadVStreamPositionRequest{
+		Target:            target,
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
+		ConsumerGroup:     consumerGroup,
+	}
+	response, err := conn.c.ReadVStreamPosition(ctx, req)
+	if err != nil {
+		return nil, tabletconn.ErrorFromGRPC(err)
+	}
+	return response.ShardGtid, nil
+}
+
 // HandlePanic is a no-op.
 func (conn *gRPCQueryClient) HandlePanic(err *error) {
 }
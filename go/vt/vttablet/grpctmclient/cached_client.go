@@ -237,7 +237,7 @@ func (dialer *cachedConnDialer) pollOnce(ctx context.Context, addr string) (clie
 // It returns the three-tuple of client-interface, closer, and error that the
 // main dial func returns.
 func (dialer *cachedConnDialer) newdial(ctx context.Context, addr string) (tabletmanagerservicepb.TabletManagerClient, io.Closer, error) {
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		dialer.connWaitSema.Release(1)
 		return nil, nil, err
@@ -61,6 +61,7 @@ var (
 	ca          string
 	crl         string
 	name        string
+	spiffeIDs   []string
 )
 
 func registerFlags(fs *pflag.FlagSet) {
@@ -70,6 +71,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&ca, "tablet_manager_grpc_ca", ca, "the server ca to use to validate servers when connecting")
 	fs.StringVar(&crl, "tablet_manager_grpc_crl", crl, "the server crl to use to validate server certificates when connecting")
 	fs.StringVar(&name, "tablet_manager_grpc_server_name", name, "the server name to use to validate server certificate")
+	fs.StringSliceVar(&spiffeIDs, "tablet_manager_grpc_spiffe_ids", spiffeIDs, "comma-separated list of SPIFFE IDs allowed in the server certificate, checked instead of the server name. An ID ending with /* allows all the IDs under it")
 }
 
 var _binaries = []string{ // binaries that require the flags in this package
@@ -153,7 +155,7 @@ func NewClient() *Client {
 // dial returns a client to use
 func (client *grpcClient) dial(ctx context.Context, tablet *topodatapb.Tablet) (tabletmanagerservicepb.TabletManagerClient, io.Closer, error) {
 	addr := netutil.JoinHostPort(tablet.Hostname, int32(tablet.PortMap["grpc"]))
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -178,7 +180,7 @@ func (client *grpcClient) createTmc(ctx context.Context, addr string, opt grpc.D
 
 func (client *grpcClient) dialPool(ctx context.Context, tablet *topodatapb.Tablet) (tabletmanagerservicepb.TabletManagerClient, error) {
 	addr := netutil.JoinHostPort(tablet.Hostname, int32(tablet.PortMap["grpc"]))
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +213,7 @@ func (client *grpcClient) dialPool(ctx context.Context, tablet *topodatapb.Table
 
 func (client *grpcClient) dialDedicatedPool(ctx context.Context, dialPoolGroup DialPoolGroup, tablet *topodatapb.Tablet) (tabletmanagerservicepb.TabletManagerClient, invalidatorFunc, error) {
 	addr := netutil.JoinHostPort(tablet.Hostname, int32(tablet.PortMap["grpc"]))
-	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name, spiffeIDs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -358,6 +360,16 @@ func (client *Client) GetGlobalStatusVars(ctx context.Context, tablet *topodatap
 	return response.GetStatusValues(), nil
 }
 
+// SelfCheck is part of the tmclient.TabletManagerClient interface.
+func (client *Client) SelfCheck(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.SelfCheckResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.SelfCheck(ctx, &tabletmanagerdatapb.SelfCheckRequest{})
+}
+
 //
 // Various read-write methods
 //
@@ -1237,6 +1249,39 @@ func (client *Client) RestoreFromBackup(ctx context.Context, tablet *topodatapb.
 	}, nil
 }
 
+// MySQL related methods
+type restartMySQLStreamAdapter struct {
+	stream tabletmanagerservicepb.TabletManager_RestartMySQLClient
+	closer io.Closer
+}
+
+func (e *restartMySQLStreamAdapter) Recv() (*logutilpb.Event, error) {
+	br, err := e.stream.Recv()
+	if err != nil {
+		e.closer.Close()
+		return nil, err
+	}
+	return br.Event, nil
+}
+
+// RestartMySQL is part of the tmclient.TabletManagerClient interface.
+func (client *Client) RestartMySQL(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestartMySQLRequest) (logutil.EventStream, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.RestartMySQL(ctx, req)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	return &restartMySQLStreamAdapter{
+		stream: stream,
+		closer: closer,
+	}, nil
+}
+
 // Close is part of the tmclient.TabletManagerClient interface.
 func (client *Client) Close() {
 	client.dialer.Close()
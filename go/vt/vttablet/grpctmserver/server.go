@@ -109,6 +109,12 @@ func (s *server) GetGlobalStatusVars(ctx context.Context, request *tabletmanager
 	return response, err
 }
 
+func (s *server) SelfCheck(ctx context.Context, request *tabletmanagerdatapb.SelfCheckRequest) (response *tabletmanagerdatapb.SelfCheckResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "SelfCheck", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.SelfCheck(ctx), nil
+}
+
 //
 // Various read-write methods
 //
@@ -589,6 +595,24 @@ func (s *server) RestoreFromBackup(request *tabletmanagerdatapb.RestoreFromBacku
 	return s.tm.RestoreFromBackup(ctx, logger, request)
 }
 
+func (s *server) RestartMySQL(request *tabletmanagerdatapb.RestartMySQLRequest, stream tabletmanagerservicepb.TabletManager_RestartMySQLServer) (err error) {
+	ctx := stream.Context()
+	defer s.tm.HandleRPCPanic(ctx, "RestartMySQL", request, nil, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+
+	// create a logger, send the result back to the caller
+	logger := logutil.NewCallbackLogger(func(e *logutilpb.Event) {
+		// If the client disconnects, we will just fail
+		// to send the log events, but won't interrupt
+		// the restart.
+		stream.Send(&tabletmanagerdatapb.RestartMySQLResponse{
+			Event: e,
+		})
+	})
+
+	return s.tm.RestartMySQL(ctx, logger, request)
+}
+
 func (s *server) CheckThrottler(ctx context.Context, request *tabletmanagerdatapb.CheckThrottlerRequest) (response *tabletmanagerdatapb.CheckThrottlerResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "CheckThrottler", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
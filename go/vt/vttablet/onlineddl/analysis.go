@@ -19,6 +19,7 @@ package onlineddl
 import (
 	"context"
 	"encoding/json"
+	"strings"
 
 	"vitess.io/vitess/go/mysql/capabilities"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -31,8 +32,9 @@ import (
 type specialAlterOperation string
 
 const (
-	instantDDLSpecialOperation     specialAlterOperation = "instant-ddl"
-	rangePartitionSpecialOperation specialAlterOperation = "range-partition"
+	instantDDLSpecialOperation           specialAlterOperation = "instant-ddl"
+	rangePartitionSpecialOperation       specialAlterOperation = "range-partition"
+	partitionMaintenanceSpecialOperation specialAlterOperation = "partition-maintenance"
 )
 
 type SpecialAlterPlan struct {
@@ -82,6 +84,33 @@ func analyzeInstantDDL(alterTable *sqlparser.AlterTable, createTable *sqlparser.
 	return op, nil
 }
 
+// analyzePartitionMaintenance checks whether the ALTER is a maintenance operation on partitions of the table, such
+// as TRUNCATE PARTITION or OPTIMIZE PARTITION, which does not change the schema of the table.
+func analyzePartitionMaintenance(alterTable *sqlparser.AlterTable, createTable *sqlparser.CreateTable) *SpecialAlterPlan {
+	spec := alterTable.PartitionSpec
+	if spec == nil || len(alterTable.AlterOptions) > 0 || alterTable.PartitionOption != nil {
+		return nil
+	}
+	switch spec.Action {
+	case sqlparser.TruncateAction, sqlparser.ExchangeAction,
+		sqlparser.DiscardAction, sqlparser.ImportAction,
+		sqlparser.AnalyzeAction, sqlparser.CheckAction, sqlparser.OptimizeAction,
+		sqlparser.RebuildAction, sqlparser.RepairAction:
+		op := NewSpecialAlterOperation(partitionMaintenanceSpecialOperation, alterTable, createTable)
+		if spec.IsAll {
+			op.SetDetail("partitions", "all")
+		} else {
+			names := make([]string, 0, len(spec.Names))
+			for _, name := range spec.Names {
+				names = append(names, name.String())
+			}
+			op.SetDetail("partitions", strings.Join(names, ", "))
+		}
+		return op
+	}
+	return nil
+}
+
 // analyzeSpecialAlterPlan checks if the given ALTER onlineDDL, and for the current state of affected table,
 // can be executed in a special way. If so, it returns with a "special plan"
 func (e *Executor) analyzeSpecialAlterPlan(ctx context.Context, onlineDDL *schema.OnlineDDL, capableOf capabilities.CapableOf) (*SpecialAlterPlan, error) {
@@ -121,6 +150,13 @@ func (e *Executor) analyzeSpecialAlterPlan(ctx context.Context, onlineDDL *schem
 			op := NewSpecialAlterOperation(rangePartitionSpecialOperation, alterTable, createTable)
 			return op, nil
 		}
+		// Maintenance operations on partitions have to run directly as well. Running them on the shadow
+		// table of Online DDL is incorrect: the copy would bring back the rows of a truncated partition,
+		// or miss the rows of an exchanged one. The others, e.g. OPTIMIZE PARTITION, are pointless with a
+		// full table copy.
+		if op := analyzePartitionMaintenance(alterTable, createTable); op != nil {
+			return op, nil
+		}
 	}
 	// special plans which do not support reverts are flag protected:
 	if onlineDDL.StrategySetting().IsPreferInstantDDL() {
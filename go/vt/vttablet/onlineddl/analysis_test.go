@@ -238,3 +238,58 @@ func TestAnalyzeInstantDDL(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzePartitionMaintenance(t *testing.T) {
+	tt := []struct {
+		alter      string
+		partitions string
+	}{
+		{
+			alter:      "alter table t truncate partition p0, p1",
+			partitions: "p0, p1",
+		},
+		{
+			alter:      "alter table t optimize partition all",
+			partitions: "all",
+		},
+		{
+			alter:      "alter table t exchange partition p0 with table t_archive",
+			partitions: "p0",
+		},
+		{
+			alter:      "alter table t analyze partition p1",
+			partitions: "p1",
+		},
+		{
+			alter: "alter table t coalesce partition 2",
+		},
+		{
+			alter: "alter table t reorganize partition p1 into (partition p1 values less than (200), partition p2 values less than maxvalue)",
+		},
+		{
+			alter: "alter table t add column i2 int not null",
+		},
+	}
+	parser := sqlparser.NewTestParser()
+	stmt, err := parser.ParseStrictDDL("create table t(id int, primary key(id)) partition by range (id) (partition p0 values less than (100), partition p1 values less than maxvalue)")
+	require.NoError(t, err)
+	createTable, ok := stmt.(*sqlparser.CreateTable)
+	require.True(t, ok)
+	for _, tc := range tt {
+		t.Run(tc.alter, func(t *testing.T) {
+			stmt, err := parser.ParseStrictDDL(tc.alter)
+			require.NoError(t, err)
+			alterTable, ok := stmt.(*sqlparser.AlterTable)
+			require.True(t, ok)
+
+			plan := analyzePartitionMaintenance(alterTable, createTable)
+			if tc.partitions == "" {
+				require.Nil(t, plan)
+				return
+			}
+			require.NotNil(t, plan)
+			assert.Equal(t, partitionMaintenanceSpecialOperation, plan.operation)
+			assert.Equal(t, tc.partitions, plan.Detail("partitions"))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/syscallutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// diskSpaceCheckRefuse fails the migrations whose projected disk usage exceeds the free disk space.
+	diskSpaceCheckRefuse = "refuse"
+	// diskSpaceCheckWarn only warns about them, in the message of the migration.
+	diskSpaceCheckWarn = "warn"
+	// diskSpaceCheckDisabled does not project the disk usage of the migrations. This is the default.
+	diskSpaceCheckDisabled = "disabled"
+)
+
+var (
+	diskSpaceCheck  = diskSpaceCheckDisabled
+	diskSpaceMargin = 0.1
+)
+
+// diskUsage is the projected disk usage of a migration on a file system.
+type diskUsage struct {
+	path     string
+	required uint64
+	free     uint64
+	total    uint64
+}
+
+// validateDiskSpaceCheck validates the value of the --online-ddl-disk-space-check flag.
+func validateDiskSpaceCheck(check string) error {
+	switch check {
+	case diskSpaceCheckRefuse, diskSpaceCheckWarn, diskSpaceCheckDisabled:
+		return nil
+	}
+	return fmt.Errorf("invalid --online-ddl-disk-space-check value %q, expected one of %q, %q or %q", check, diskSpaceCheckRefuse, diskSpaceCheckWarn, diskSpaceCheckDisabled)
+}
+
+// mergeDiskUsages merges the usages of the directories which are on the same file system. Without the
+// ID of the file systems, which is not portable, two directories are assumed to share their file system
+// when they report the exact same free and total bytes.
+func mergeDiskUsages(usages []diskUsage) []diskUsage {
+	var merged []diskUsage
+	for _, usage := range usages {
+		found := false
+		for i := range merged {
+			if merged[i].free == usage.free && merged[i].total == usage.total {
+				merged[i].required += usage.required
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, usage)
+		}
+	}
+	return merged
+}
+
+// diskSpaceShortage returns a description of the first file system whose free space, less the safety
+// margin, cannot hold the projected usage, or an empty string if they all can.
+func diskSpaceShortage(usages []diskUsage, margin float64) string {
+	for _, usage := range mergeDiskUsages(usages) {
+		available := uint64(float64(usage.free) * (1 - margin))
+		if usage.required > available {
+			return fmt.Sprintf("projected disk usage of %d bytes on %s exceeds its %d free bytes less a %.0f%% safety margin",
+				usage.required, usage.path, usage.free, margin*100)
+		}
+	}
+	return ""
+}
+
+// readTableDiskUsage returns the data and index bytes of a table, as estimated by the table statistics.
+// ok is false if the table does not exist.
+func (e *Executor) readTableDiskUsage(ctx context.Context, schemaName string, tableName string) (dataBytes, indexBytes uint64, ok bool, err error) {
+	query, err := sqlparser.ParseAndBind(sqlSelectTableDiskUsage,
+		sqltypes.StringBindVariable(schemaName),
+		sqltypes.StringBindVariable(tableName),
+	)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	rs, err := e.execQuery(ctx, query)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	row := rs.Named().Row()
+	if row == nil {
+		return 0, 0, false, nil
+	}
+	return row.AsUint64("data_length", 0), row.AsUint64("index_length", 0), true, nil
+}
+
+// projectMigrationDiskUsage estimates the disk usage of a migration which copies a table: the shadow
+// table takes as much space as the original table in the data directory, and the copy of its rows
+// writes about as many bytes to the binary logs, if they are enabled.
+func (e *Executor) projectMigrationDiskUsage(ctx context.Context, onlineDDL *schema.OnlineDDL) (usages []diskUsage, err error) {
+	dataBytes, indexBytes, ok, err := e.readTableDiskUsage(ctx, onlineDDL.Schema, onlineDDL.Table)
+	if err != nil || !ok {
+		return nil, err
+	}
+	rs, err := e.execQuery(ctx, sqlSelectDiskDirectories)
+	if err != nil {
+		return nil, err
+	}
+	row := rs.Named().Row()
+	if row == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "unexpected result from query: %s", sqlSelectDiskDirectories)
+	}
+	usages = append(usages, diskUsage{path: row.AsString("datadir", ""), required: dataBytes + indexBytes})
+	if logBinBasename := row.AsString("log_bin_basename", ""); logBinBasename != "" {
+		usages = append(usages, diskUsage{path: filepath.Dir(logBinBasename), required: dataBytes})
+	}
+	return usages, nil
+}
+
+// checkMigrationDiskSpace projects the disk usage of a migration which copies a table, records it in
+// the migration, and refuses the migration or warns about it if the projected usage exceeds the free
+// disk space of the data directory or of the binary logs, less a safety margin.
+func (e *Executor) checkMigrationDiskSpace(ctx context.Context, onlineDDL *schema.OnlineDDL) error {
+	if diskSpaceCheck == diskSpaceCheckDisabled {
+		return nil
+	}
+	usages, err := e.projectMigrationDiskUsage(ctx, onlineDDL)
+	if err != nil {
+		return vterrors.Wrapf(err, "error while projecting the disk usage of migration %s", onlineDDL.UUID)
+	}
+	if len(usages) == 0 {
+		// The table does not exist, and the migration fails on its own.
+		return nil
+	}
+	var projected uint64
+	for _, usage := range usages {
+		projected += usage.required
+	}
+	if err := e.updateMigrationProjectedDiskBytes(ctx, onlineDDL.UUID, projected); err != nil {
+		return err
+	}
+
+	for i := range usages {
+		free, total, err := syscallutil.DiskStat(usages[i].path)
+		if err != nil {
+			// MySQL may run on another host, or in another mount namespace, than vttablet.
+			log.Warningf("cannot read the free disk space of %s for migration %s, skipping the disk space check: %v", usages[i].path, onlineDDL.UUID, err)
+			return nil
+		}
+		usages[i].free, usages[i].total = free, total
+	}
+	shortage := diskSpaceShortage(usages, diskSpaceMargin)
+	if shortage == "" {
+		return nil
+	}
+	if diskSpaceCheck == diskSpaceCheckWarn {
+		log.Warningf("migration %s: %s", onlineDDL.UUID, shortage)
+		_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, "warning: "+shortage)
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "%s; see --online-ddl-disk-space-check", shortage)
+}
+
+func (e *Executor) updateMigrationProjectedDiskBytes(ctx context.Context, uuid string, projectedDiskBytes uint64) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateMigrationProjectedDiskBytes,
+		sqltypes.Uint64BindVariable(projectedDiskBytes),
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = e.execQuery(ctx, query)
+	return err
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDiskSpaceCheck(t *testing.T) {
+	for _, check := range []string{diskSpaceCheckRefuse, diskSpaceCheckWarn, diskSpaceCheckDisabled} {
+		assert.NoError(t, validateDiskSpaceCheck(check))
+	}
+	assert.ErrorContains(t, validateDiskSpaceCheck("ignore"), "invalid --online-ddl-disk-space-check value")
+}
+
+func TestDiskSpaceShortage(t *testing.T) {
+	tcs := []struct {
+		name     string
+		usages   []diskUsage
+		margin   float64
+		shortage string
+	}{
+		{
+			name: "enough space",
+			usages: []diskUsage{
+				{path: "/data", required: 800, free: 1000, total: 4000},
+			},
+			margin: 0.1,
+		},
+		{
+			name: "exceeds the margin",
+			usages: []diskUsage{
+				{path: "/data", required: 950, free: 1000, total: 4000},
+			},
+			margin:   0.1,
+			shortage: "projected disk usage of 950 bytes on /data exceeds its 1000 free bytes less a 10% safety margin",
+		},
+		{
+			name: "no margin",
+			usages: []diskUsage{
+				{path: "/data", required: 1000, free: 1000, total: 4000},
+			},
+		},
+		{
+			name: "binary logs on the same file system",
+			usages: []diskUsage{
+				{path: "/data", required: 600, free: 1000, total: 4000},
+				{path: "/data/binlogs", required: 400, free: 1000, total: 4000},
+			},
+			margin:   0.1,
+			shortage: "projected disk usage of 1000 bytes on /data exceeds its 1000 free bytes less a 10% safety margin",
+		},
+		{
+			name: "binary logs on another file system",
+			usages: []diskUsage{
+				{path: "/data", required: 600, free: 1000, total: 4000},
+				{path: "/binlogs", required: 400, free: 2000, total: 4000},
+			},
+			margin: 0.1,
+		},
+		{
+			name: "binary logs on a full file system",
+			usages: []diskUsage{
+				{path: "/data", required: 400, free: 1000, total: 4000},
+				{path: "/binlogs", required: 400, free: 100, total: 4000},
+			},
+			margin:   0.5,
+			shortage: "projected disk usage of 400 bytes on /binlogs exceeds its 100 free bytes less a 50% safety margin",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.shortage, diskSpaceShortage(tc.usages, tc.margin))
+		})
+	}
+}
@@ -112,6 +112,9 @@ func registerOnlineDDLFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&migrationCheckInterval, "migration_check_interval", migrationCheckInterval, "Interval between migration checks")
 	fs.DurationVar(&retainOnlineDDLTables, "retain_online_ddl_tables", retainOnlineDDLTables, "How long should vttablet keep an old migrated table before purging it")
 	fs.IntVar(&maxConcurrentOnlineDDLs, "max_concurrent_online_ddl", maxConcurrentOnlineDDLs, "Maximum number of online DDL changes that may run concurrently")
+	fs.StringVar(&diskSpaceCheck, "online-ddl-disk-space-check", diskSpaceCheck, "What to do when the projected disk usage of a vitess or gh-ost migration exceeds the free disk space less the safety margin: refuse, warn or disabled")
+	fs.Float64Var(&diskSpaceMargin, "online-ddl-disk-space-margin", diskSpaceMargin, "Fraction of the free disk space of the data directory and of the binary logs kept as a safety margin when projecting the disk usage of a migration")
+	fs.StringVar(&impactAnalysis, "online-ddl-impact-analysis", impactAnalysis, "What to do when the recent query digests or cached plans of the tablet reference a table or columns dropped by a migration: block, warn or disabled")
 }
 
 const (
@@ -179,6 +182,7 @@ type Executor struct {
 	lagThrottler          *throttle.Throttler
 	toggleBufferTableFunc func(cancelCtx context.Context, tableName string, timeout time.Duration, bufferQueries bool)
 	requestGCChecksFunc   func()
+	recentQueriesFunc     func() []*RecentQuery
 	tabletAlias           *topodatapb.TabletAlias
 
 	keyspace string
@@ -238,6 +242,7 @@ func NewExecutor(env tabletenv.Env, tabletAlias *topodatapb.TabletAlias, ts *top
 	tabletTypeFunc func() topodatapb.TabletType,
 	toggleBufferTableFunc func(cancelCtx context.Context, tableName string, timeout time.Duration, bufferQueries bool),
 	requestGCChecksFunc func(),
+	recentQueriesFunc func() []*RecentQuery,
 ) *Executor {
 	// sanitize flags
 	if maxConcurrentOnlineDDLs < 1 {
@@ -256,6 +261,7 @@ func NewExecutor(env tabletenv.Env, tabletAlias *topodatapb.TabletAlias, ts *top
 		lagThrottler:          lagThrottler,
 		toggleBufferTableFunc: toggleBufferTableFunc,
 		requestGCChecksFunc:   requestGCChecksFunc,
+		recentQueriesFunc:     recentQueriesFunc,
 		ticks:                 timer.NewTimer(migrationCheckInterval),
 		// Gracefully return an error if any caller tries to execute
 		// a query before the executor has been fully opened.
@@ -314,6 +320,12 @@ func (e *Executor) Open() error {
 		return nil
 	}
 	log.Infof("onlineDDL Executor Open()")
+	if err := validateDiskSpaceCheck(diskSpaceCheck); err != nil {
+		return err
+	}
+	if err := validateImpactAnalysis(impactAnalysis); err != nil {
+		return err
+	}
 
 	e.reviewedRunningMigrationsFlag = false // will be set as "true" by reviewRunningMigrations()
 	e.ownedRunningMigrations.Range(func(k, _ any) bool {
@@ -3229,7 +3241,7 @@ func (e *Executor) executeSpecialAlterDDLActionMigrationIfApplicable(ctx context
 		if _, err := e.executeDirectly(ctx, onlineDDL); err != nil {
 			return false, err
 		}
-	case rangePartitionSpecialOperation:
+	case rangePartitionSpecialOperation, partitionMaintenanceSpecialOperation:
 		if _, err := e.executeDirectly(ctx, onlineDDL); err != nil {
 			return false, err
 		}
@@ -3283,7 +3295,16 @@ func (e *Executor) executeAlterDDLActionMigration(ctx context.Context, onlineDDL
 		return nil
 	}
 
-	// OK, nothing special about this ALTER. Let's go ahead and execute it.
+	// OK, nothing special about this ALTER. The strategies which copy the table need the disk space
+	// to do so.
+	switch onlineDDL.Strategy {
+	case schema.DDLStrategyOnline, schema.DDLStrategyVitess, schema.DDLStrategyGhost:
+		if err := e.checkMigrationDiskSpace(ctx, onlineDDL); err != nil {
+			return failMigration(err)
+		}
+	}
+
+	// Let's go ahead and execute it.
 	switch onlineDDL.Strategy {
 	case schema.DDLStrategyOnline, schema.DDLStrategyVitess:
 		if err := e.ExecuteWithVReplication(ctx, onlineDDL, nil); err != nil {
@@ -3433,6 +3454,14 @@ func (e *Executor) executeMigration(ctx context.Context, onlineDDL *schema.Onlin
 	} // endif onlineDDL.IsDeclarative()
 	// Noting that if the migration is declarative, then it may have been modified in the above block, to meet the next operations.
 
+	switch ddlAction {
+	case sqlparser.DropDDLAction, sqlparser.AlterDDLAction:
+		// Destructive changes may break the queries still using what they drop.
+		if err := e.analyzeMigrationImpact(ctx, onlineDDL); err != nil {
+			return failMigration(err)
+		}
+	}
+
 	switch ddlAction {
 	case sqlparser.DropDDLAction:
 		go func() error {
@@ -5027,6 +5056,16 @@ func (e *Executor) submitCallbackIfNonConflicting(
 	return callback()
 }
 
+// validateCharset validates the character sets and collations set by the
+// statement of the migration against the default ones of the keyspace.
+func (e *Executor) validateCharset(ctx context.Context, stmt sqlparser.Statement, onlineDDL *schema.OnlineDDL) error {
+	ki, err := e.ts.GetKeyspace(ctx, e.keyspace)
+	if err != nil {
+		return vterrors.Wrapf(err, "while reading keyspace %s", e.keyspace)
+	}
+	return schema.ValidateCharset(e.env.Environment().CollationEnv(), stmt, onlineDDL.StrategySetting(), ki.DefaultCharset, ki.DefaultCollation)
+}
+
 // SubmitMigration inserts a new migration request
 func (e *Executor) SubmitMigration(
 	ctx context.Context,
@@ -5049,6 +5088,9 @@ func (e *Executor) SubmitMigration(
 	if err != nil {
 		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Error submitting migration %s: %v", sqlparser.String(stmt), err)
 	}
+	if err := e.validateCharset(ctx, stmt, onlineDDL); err != nil {
+		return nil, err
+	}
 
 	// The logic below has multiple steps. We hence protect the rest of the code with a mutex, only used by this function.
 	e.submitMutex.Lock()
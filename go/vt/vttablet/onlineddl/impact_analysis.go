@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// impactAnalysisBlock fails the migrations dropping a table or columns referenced by recent queries.
+	impactAnalysisBlock = "block"
+	// impactAnalysisWarn only warns about them, in the message of the migration.
+	impactAnalysisWarn = "warn"
+	// impactAnalysisDisabled does not analyze the impact of the migrations.
+	impactAnalysisDisabled = "disabled"
+)
+
+var impactAnalysis = impactAnalysisDisabled
+
+// RecentQuery is a query recently served by the tablet, which the impact analysis searches for
+// references to the tables and columns dropped by the migrations.
+type RecentQuery struct {
+	// Query is the normalized query of a query digest, or the query of a cached plan.
+	Query string
+	// Source is where the query comes from: "query_digests" or "plan_cache".
+	Source string
+	// Caller is the immediate caller of a query digest.
+	Caller   string     `json:",omitempty"`
+	Count    uint64     `json:",omitempty"`
+	LastSeen *time.Time `json:",omitempty"`
+}
+
+// impactReference is a recent query referencing the dropped table, or some of the dropped columns.
+type impactReference struct {
+	*RecentQuery
+	Columns []string `json:",omitempty"`
+}
+
+// impactReport is the evidence recorded in the impact_analysis of a migration.
+type impactReport struct {
+	Table      string
+	Columns    []string `json:",omitempty"`
+	References []*impactReference
+}
+
+// validateImpactAnalysis validates the value of the --online-ddl-impact-analysis flag.
+func validateImpactAnalysis(mode string) error {
+	switch mode {
+	case impactAnalysisBlock, impactAnalysisWarn, impactAnalysisDisabled:
+		return nil
+	}
+	return fmt.Errorf("invalid --online-ddl-impact-analysis value %q, expected one of %q, %q or %q", mode, impactAnalysisBlock, impactAnalysisWarn, impactAnalysisDisabled)
+}
+
+// droppedObjects returns what a migration destroys: the table of a DROP TABLE, or the table and the
+// dropped columns of an ALTER TABLE. table is empty if the migration does not drop anything.
+func droppedObjects(onlineDDL *schema.OnlineDDL, parser *sqlparser.Parser) (table string, columns []string, err error) {
+	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL, parser)
+	if err != nil {
+		return "", nil, err
+	}
+	switch ddlStmt := ddlStmt.(type) {
+	case *sqlparser.DropTable:
+		return onlineDDL.Table, nil, nil
+	case *sqlparser.AlterTable:
+		for _, option := range ddlStmt.AlterOptions {
+			if dropColumn, ok := option.(*sqlparser.DropColumn); ok {
+				columns = append(columns, dropColumn.Name.Name.String())
+			}
+		}
+		if len(columns) > 0 {
+			return onlineDDL.Table, columns, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// queryReferences tells whether a statement references the table, and which of the given columns of
+// the table it references. A column is referenced when qualified by the table or one of its aliases,
+// or when unqualified in a statement referencing the table, which may also match a column of the same
+// name in another table of the statement. An INSERT into the table without a column list references
+// all its columns.
+func queryReferences(stmt sqlparser.Statement, table string, columns []string) (referenced bool, referencedColumns []string) {
+	qualifiers := map[string]bool{}
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if aliased, ok := node.(*sqlparser.AliasedTableExpr); ok {
+			if tableName, ok := aliased.Expr.(sqlparser.TableName); ok && tableName.Name.String() == table {
+				referenced = true
+				if aliased.As.IsEmpty() {
+					qualifiers[table] = true
+				} else {
+					qualifiers[aliased.As.String()] = true
+				}
+			}
+		}
+		return true, nil
+	}, stmt)
+	if !referenced || len(columns) == 0 {
+		return referenced, nil
+	}
+
+	found := map[string]bool{}
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			qualifier := node.Qualifier.Name.String()
+			if qualifier != "" && !qualifiers[qualifier] {
+				return true, nil
+			}
+			for _, column := range columns {
+				if node.Name.EqualString(column) {
+					found[column] = true
+				}
+			}
+		case *sqlparser.Insert:
+			if tableName, err := node.Table.TableName(); err != nil || tableName.Name.String() != table {
+				return true, nil
+			}
+			for _, column := range columns {
+				if len(node.Columns) == 0 || node.Columns.FindColumn(sqlparser.NewIdentifierCI(column)) >= 0 {
+					found[column] = true
+				}
+			}
+		}
+		return true, nil
+	}, stmt)
+	for _, column := range columns {
+		if found[column] {
+			referencedColumns = append(referencedColumns, column)
+		}
+	}
+	return len(referencedColumns) > 0, referencedColumns
+}
+
+// analyzeImpact returns the report of the recent queries referencing the dropped table, or some of
+// the dropped columns of the table. The queries which do not parse, such as truncated digests, are
+// skipped.
+func analyzeImpact(parser *sqlparser.Parser, queries []*RecentQuery, table string, columns []string) *impactReport {
+	report := &impactReport{Table: table, Columns: columns}
+	for _, query := range queries {
+		stmt, err := parser.Parse(query.Query)
+		if err != nil {
+			continue
+		}
+		if ok, referencedColumns := queryReferences(stmt, table, columns); ok {
+			report.References = append(report.References, &impactReference{RecentQuery: query, Columns: referencedColumns})
+		}
+	}
+	return report
+}
+
+// analyzeMigrationImpact searches the recent queries of the tablet for references to the table or the
+// columns dropped by a migration, records them in the migration, and refuses the migration or warns
+// about it if there are any. Queries served rarely, e.g. by a daily job, may not be recent enough to
+// be found.
+func (e *Executor) analyzeMigrationImpact(ctx context.Context, onlineDDL *schema.OnlineDDL) error {
+	if impactAnalysis == impactAnalysisDisabled || e.recentQueriesFunc == nil {
+		return nil
+	}
+	parser := e.env.Environment().Parser()
+	table, columns, err := droppedObjects(onlineDDL, parser)
+	if err != nil || table == "" {
+		return err
+	}
+	report := analyzeImpact(parser, e.recentQueriesFunc(), table, columns)
+	if len(report.References) == 0 {
+		return nil
+	}
+	evidence, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if err := e.updateMigrationImpactAnalysis(ctx, onlineDDL.UUID, string(evidence)); err != nil {
+		return err
+	}
+
+	dropped := "table " + table
+	if len(columns) > 0 {
+		dropped = fmt.Sprintf("columns %s of table %s", strings.Join(columns, ", "), table)
+	}
+	summary := fmt.Sprintf("%d recent queries reference the dropped %s, see the impact_analysis of the migration", len(report.References), dropped)
+	if impactAnalysis == impactAnalysisWarn {
+		log.Warningf("migration %s: %s", onlineDDL.UUID, summary)
+		_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, "warning: "+summary)
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s; see --online-ddl-impact-analysis", summary)
+}
+
+func (e *Executor) updateMigrationImpactAnalysis(ctx context.Context, uuid string, impactAnalysis string) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateMigrationImpactAnalysis,
+		sqltypes.StringBindVariable(impactAnalysis),
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = e.execQuery(ctx, query)
+	return err
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestValidateImpactAnalysis(t *testing.T) {
+	for _, mode := range []string{impactAnalysisBlock, impactAnalysisWarn, impactAnalysisDisabled} {
+		assert.NoError(t, validateImpactAnalysis(mode))
+	}
+	assert.ErrorContains(t, validateImpactAnalysis("refuse"), "invalid --online-ddl-impact-analysis value")
+}
+
+func TestDroppedObjects(t *testing.T) {
+	tcs := []struct {
+		sql     string
+		table   string
+		columns []string
+	}{
+		{
+			sql:   "drop table t1",
+			table: "t1",
+		},
+		{
+			sql:     "alter table t1 drop column c1, add column c3 int, drop column c2",
+			table:   "t1",
+			columns: []string{"c1", "c2"},
+		},
+		{
+			sql: "alter table t1 add column c3 int",
+		},
+		{
+			sql: "create table t1 (id int primary key)",
+		},
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tc := range tcs {
+		t.Run(tc.sql, func(t *testing.T) {
+			table, columns, err := droppedObjects(&schema.OnlineDDL{Table: "t1", SQL: tc.sql}, parser)
+			require.NoError(t, err)
+			assert.Equal(t, tc.table, table)
+			assert.Equal(t, tc.columns, columns)
+		})
+	}
+}
+
+func TestQueryReferences(t *testing.T) {
+	tcs := []struct {
+		query      string
+		columns    []string
+		referenced bool
+		expect     []string
+	}{
+		{
+			query:      "select * from t1 where id = :v1",
+			referenced: true,
+		},
+		{
+			query: "select * from t2 where id = :v1",
+		},
+		{
+			query:      "select c1 from t1 where id = :v1",
+			columns:    []string{"c1", "c2"},
+			referenced: true,
+			expect:     []string{"c1"},
+		},
+		{
+			query:   "select id from t1 where id = :v1",
+			columns: []string{"c1", "c2"},
+		},
+		{
+			query:      "select a.id from t1 as a join t2 on a.id = t2.id where a.c2 = :v1",
+			columns:    []string{"c1", "c2"},
+			referenced: true,
+			expect:     []string{"c2"},
+		},
+		{
+			query:   "select t1.id from t1 join t2 on t1.id = t2.id where t2.c2 = :v1",
+			columns: []string{"c1", "c2"},
+		},
+		{
+			query:   "select c1 from t2 where id = :v1",
+			columns: []string{"c1", "c2"},
+		},
+		{
+			query:      "update t1 set C1 = :v1 where id = :v2",
+			columns:    []string{"c1", "c2"},
+			referenced: true,
+			expect:     []string{"c1"},
+		},
+		{
+			query:      "insert into t1(id, c2) values (:v1, :v2)",
+			columns:    []string{"c1", "c2"},
+			referenced: true,
+			expect:     []string{"c2"},
+		},
+		{
+			query:      "insert into t1 values (:v1, :v2, :v3)",
+			columns:    []string{"c1", "c2"},
+			referenced: true,
+			expect:     []string{"c1", "c2"},
+		},
+		{
+			query:   "insert into t1(id) values (:v1)",
+			columns: []string{"c1", "c2"},
+		},
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tc := range tcs {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tc.query)
+			require.NoError(t, err)
+			referenced, columns := queryReferences(stmt, "t1", tc.columns)
+			assert.Equal(t, tc.referenced, referenced)
+			assert.Equal(t, tc.expect, columns)
+		})
+	}
+}
+
+func TestAnalyzeImpact(t *testing.T) {
+	queries := []*RecentQuery{
+		{Query: "select c1 from t1 where id = :v1", Source: "query_digests", Caller: "cron", Count: 1},
+		{Query: "select id from t1 where id = :v1", Source: "query_digests", Caller: "app", Count: 1000},
+		{Query: "select c1 from t1 where id = 1 [TRUNCATED]", Source: "query_digests", Caller: "app", Count: 1},
+		{Query: "select c1 from t1 where id = 1", Source: "plan_cache", Count: 1},
+	}
+	report := analyzeImpact(sqlparser.NewTestParser(), queries, "t1", []string{"c1"})
+	assert.Equal(t, "t1", report.Table)
+	assert.Equal(t, []string{"c1"}, report.Columns)
+	require.Len(t, report.References, 2)
+	assert.Equal(t, "cron", report.References[0].Caller)
+	assert.Equal(t, []string{"c1"}, report.References[0].Columns)
+	assert.Equal(t, "plan_cache", report.References[1].Source)
+}
@@ -219,6 +219,16 @@ const (
 		WHERE
 			migration_uuid=%a
 	`
+	sqlUpdateMigrationProjectedDiskBytes = `UPDATE _vt.schema_migrations
+			SET projected_disk_bytes=%a
+		WHERE
+			migration_uuid=%a
+	`
+	sqlUpdateMigrationImpactAnalysis = `UPDATE _vt.schema_migrations
+			SET impact_analysis=%a
+		WHERE
+			migration_uuid=%a
+	`
 	sqlUpdateMigrationProgressByRowsCopied = `UPDATE _vt.schema_migrations
 			SET
 				table_rows=GREATEST(table_rows, %a),
@@ -471,6 +481,15 @@ const (
 			REFERENCED_TABLE_SCHEMA=%a AND REFERENCED_TABLE_NAME=%a
 			AND REFERENCED_TABLE_NAME IS NOT NULL
 		`
+	sqlSelectTableDiskUsage = `
+		SELECT
+			DATA_LENGTH AS data_length,
+			INDEX_LENGTH AS index_length
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE
+			TABLE_SCHEMA=%a AND TABLE_NAME=%a
+		`
+	sqlSelectDiskDirectories         = `SELECT @@global.datadir AS datadir, @@global.log_bin_basename AS log_bin_basename`
 	selSelectCountFKChildConstraints = `
 		SELECT
 			COUNT(*) as num_fk_constraints
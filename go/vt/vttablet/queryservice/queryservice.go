@@ -76,6 +76,14 @@ type QueryService interface {
 	// ReadTransaction returns the metadata for the specified dtid.
 	ReadTransaction(ctx context.Context, target *querypb.Target, dtid string) (metadata *querypb.TransactionMetadata, err error)
 
+	// TransactionLockWaits returns the lock waits between the transactions
+	// of the tablet.
+	TransactionLockWaits(ctx context.Context, target *querypb.Target) (lockWaits []*querypb.TransactionLockWait, err error)
+
+	// AbortTransaction kills the connection of the specified transaction,
+	// even if it is executing a query, which rolls the transaction back.
+	AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) (err error)
+
 	// Execute for query execution
 	Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, error)
 	// StreamExecute for query execution with streaming
@@ -105,6 +113,14 @@ type QueryService interface {
 	// VStreamResults streams results along with the gtid of the snapshot.
 	VStreamResults(ctx context.Context, target *querypb.Target, query string, send func(*binlogdatapb.VStreamResultsResponse) error) error
 
+	// SaveVStreamPosition persists the position acknowledged by a VStream
+	// consumer group, from which its streams resume.
+	SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error
+
+	// ReadVStreamPosition returns the position acknowledged by a VStream
+	// consumer group, or nil if it has none.
+	ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error)
+
 	// StreamHealth streams health status.
 	StreamHealth(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error
 
@@ -176,6 +176,22 @@ func (ws *wrappedService) ReadTransaction(ctx context.Context, target *querypb.T
 	return metadata, err
 }
 
+func (ws *wrappedService) TransactionLockWaits(ctx context.Context, target *querypb.Target) (lockWaits []*querypb.TransactionLockWait, err error) {
+	err = ws.wrapper(ctx, target, ws.impl, "TransactionLockWaits", false, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
+		var innerErr error
+		lockWaits, innerErr = conn.TransactionLockWaits(ctx, target)
+		return canRetry(ctx, innerErr), innerErr
+	})
+	return lockWaits, err
+}
+
+func (ws *wrappedService) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) (err error) {
+	return ws.wrapper(ctx, target, ws.impl, "AbortTransaction", true, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
+		innerErr := conn.AbortTransaction(ctx, target, transactionID, reason)
+		return canRetry(ctx, innerErr), innerErr
+	})
+}
+
 func (ws *wrappedService) Execute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (qr *sqltypes.Result, err error) {
 	inDedicatedConn := transactionID != 0 || reservedID != 0
 	err = ws.wrapper(ctx, target, ws.impl, "Execute", inDedicatedConn, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
@@ -268,6 +284,22 @@ func (ws *wrappedService) VStreamResults(ctx context.Context, target *querypb.Ta
 	})
 }
 
+func (ws *wrappedService) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	return ws.wrapper(ctx, target, ws.impl, "SaveVStreamPosition", false, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
+		innerErr := conn.SaveVStreamPosition(ctx, target, consumerGroup, shardGtid)
+		return canRetry(ctx, innerErr), innerErr
+	})
+}
+
+func (ws *wrappedService) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (shardGtid *binlogdatapb.ShardGtid, err error) {
+	err = ws.wrapper(ctx, target, ws.impl, "ReadVStreamPosition", false, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
+		var innerErr error
+		shardGtid, innerErr = conn.ReadVStreamPosition(ctx, target, consumerGroup)
+		return canRetry(ctx, innerErr), innerErr
+	})
+	return shardGtid, err
+}
+
 func (ws *wrappedService) StreamHealth(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error {
 	return ws.wrapper(ctx, nil, ws.impl, "StreamHealth", false, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
 		innerErr := conn.StreamHealth(ctx, callback)
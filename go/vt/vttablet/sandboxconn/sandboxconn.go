@@ -65,22 +65,24 @@ type SandboxConn struct {
 
 	// These Count vars report how often the corresponding
 	// functions were called.
-	ExecCount                atomic.Int64
-	BeginCount               atomic.Int64
-	CommitCount              atomic.Int64
-	RollbackCount            atomic.Int64
-	AsTransactionCount       atomic.Int64
-	PrepareCount             atomic.Int64
-	CommitPreparedCount      atomic.Int64
-	RollbackPreparedCount    atomic.Int64
-	CreateTransactionCount   atomic.Int64
-	StartCommitCount         atomic.Int64
-	SetRollbackCount         atomic.Int64
-	ConcludeTransactionCount atomic.Int64
-	ReadTransactionCount     atomic.Int64
-	ReserveCount             atomic.Int64
-	ReleaseCount             atomic.Int64
-	GetSchemaCount           atomic.Int64
+	ExecCount                 atomic.Int64
+	BeginCount                atomic.Int64
+	CommitCount               atomic.Int64
+	RollbackCount             atomic.Int64
+	AsTransactionCount        atomic.Int64
+	PrepareCount              atomic.Int64
+	CommitPreparedCount       atomic.Int64
+	RollbackPreparedCount     atomic.Int64
+	CreateTransactionCount    atomic.Int64
+	StartCommitCount          atomic.Int64
+	SetRollbackCount          atomic.Int64
+	ConcludeTransactionCount  atomic.Int64
+	ReadTransactionCount      atomic.Int64
+	TransactionLockWaitsCount atomic.Int64
+	AbortTransactionCount     atomic.Int64
+	ReserveCount              atomic.Int64
+	ReleaseCount              atomic.Int64
+	GetSchemaCount            atomic.Int64
 
 	queriesRequireLocking bool
 	queriesMu             sync.Mutex
@@ -102,6 +104,16 @@ type SandboxConn struct {
 	// ReadTransactionResults is used for returning results for ReadTransaction.
 	ReadTransactionResults []*querypb.TransactionMetadata
 
+	// LockWaits is returned by TransactionLockWaits.
+	LockWaits []*querypb.TransactionLockWait
+
+	// AbortedTransactions stores the transaction ids received by AbortTransaction.
+	AbortedTransactions []int64
+
+	// VStreamPositions stores the positions received by SaveVStreamPosition,
+	// keyed by consumer group, and returned by ReadVStreamPosition.
+	VStreamPositions map[string]*binlogdatapb.ShardGtid
+
 	MessageIDs []*querypb.Value
 
 	// vstream expectations.
@@ -427,6 +439,25 @@ func (sbc *SandboxConn) ReadTransaction(ctx context.Context, target *querypb.Tar
 	return nil, nil
 }
 
+// TransactionLockWaits returns the lock waits set in LockWaits.
+func (sbc *SandboxConn) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	sbc.TransactionLockWaitsCount.Add(1)
+	if err := sbc.getError(); err != nil {
+		return nil, err
+	}
+	return sbc.LockWaits, nil
+}
+
+// AbortTransaction records the aborted transaction id in AbortedTransactions.
+func (sbc *SandboxConn) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	sbc.AbortTransactionCount.Add(1)
+	if err := sbc.getError(); err != nil {
+		return err
+	}
+	sbc.AbortedTransactions = append(sbc.AbortedTransactions, transactionID)
+	return nil
+}
+
 // BeginExecute is part of the QueryService interface.
 func (sbc *SandboxConn) BeginExecute(ctx context.Context, target *querypb.Target, preQueries []string, query string, bindVars map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions) (queryservice.TransactionState, *sqltypes.Result, error) {
 	sbc.panicIfNeeded()
@@ -585,6 +616,26 @@ func (sbc *SandboxConn) VStreamResults(ctx context.Context, target *querypb.Targ
 	return fmt.Errorf("not implemented in test")
 }
 
+// SaveVStreamPosition stores the position in VStreamPositions.
+func (sbc *SandboxConn) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	if err := sbc.getError(); err != nil {
+		return err
+	}
+	if sbc.VStreamPositions == nil {
+		sbc.VStreamPositions = make(map[string]*binlogdatapb.ShardGtid)
+	}
+	sbc.VStreamPositions[consumerGroup] = shardGtid
+	return nil
+}
+
+// ReadVStreamPosition returns the position stored in VStreamPositions.
+func (sbc *SandboxConn) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	if err := sbc.getError(); err != nil {
+		return nil, err
+	}
+	return sbc.VStreamPositions[consumerGroup], nil
+}
+
 // QueryServiceByAlias is part of the Gateway interface.
 func (sbc *SandboxConn) QueryServiceByAlias(_ context.Context, _ *topodatapb.TabletAlias, _ *querypb.Target) (queryservice.QueryService, error) {
 	return sbc, nil
@@ -359,6 +359,90 @@ func (f *FakeQueryService) ReadTransaction(ctx context.Context, target *querypb.
 	return Metadata, nil
 }
 
+// LockWaits is a test list of lock waits.
+var LockWaits = []*querypb.TransactionLockWait{{
+	WaitingTransactionId:  1,
+	WaitingSessionUuid:    "session1",
+	WaitingStartTime:      1,
+	BlockingTransactionId: 2,
+	BlockingSessionUuid:   "session2",
+	BlockingStartTime:     2,
+	WaitSeconds:           3,
+}}
+
+// TransactionLockWaits is part of the queryservice.QueryService interface
+func (f *FakeQueryService) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	if f.HasError {
+		return nil, f.TabletError
+	}
+	if f.Panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	f.checkTargetCallerID(ctx, "TransactionLockWaits", target)
+	return LockWaits, nil
+}
+
+// abortTransactionID is a test transaction id for AbortTransaction.
+const abortTransactionID int64 = 1000
+
+// AbortTransaction is part of the queryservice.QueryService interface
+func (f *FakeQueryService) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	if f.HasError {
+		return f.TabletError
+	}
+	if f.Panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	f.checkTargetCallerID(ctx, "AbortTransaction", target)
+	if transactionID != abortTransactionID {
+		f.t.Errorf("AbortTransaction: invalid TransactionId: got %v expected %v", transactionID, abortTransactionID)
+	}
+	return nil
+}
+
+// VStreamConsumerGroup is a test consumer group.
+const VStreamConsumerGroup = "consumer_group"
+
+// VStreamPosition is a test position of a VStream consumer group.
+var VStreamPosition = &binlogdatapb.ShardGtid{
+	Keyspace: "test_keyspace",
+	Shard:    "test_shard",
+	Gtid:     "MySQL56/e1b1f06e-47a6-11ee-8f8b-0242ac120002:1-10",
+}
+
+// SaveVStreamPosition is part of the queryservice.QueryService interface
+func (f *FakeQueryService) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	if f.HasError {
+		return f.TabletError
+	}
+	if f.Panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	f.checkTargetCallerID(ctx, "SaveVStreamPosition", target)
+	if consumerGroup != VStreamConsumerGroup {
+		f.t.Errorf("SaveVStreamPosition: invalid ConsumerGroup: got %v expected %v", consumerGroup, VStreamConsumerGroup)
+	}
+	if !proto.Equal(shardGtid, VStreamPosition) {
+		f.t.Errorf("SaveVStreamPosition: invalid ShardGtid: got %v expected %v", shardGtid, VStreamPosition)
+	}
+	return nil
+}
+
+// ReadVStreamPosition is part of the queryservice.QueryService interface
+func (f *FakeQueryService) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	if f.HasError {
+		return nil, f.TabletError
+	}
+	if f.Panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	f.checkTargetCallerID(ctx, "ReadVStreamPosition", target)
+	if consumerGroup != VStreamConsumerGroup {
+		f.t.Errorf("ReadVStreamPosition: invalid ConsumerGroup: got %v expected %v", consumerGroup, VStreamConsumerGroup)
+	}
+	return VStreamPosition, nil
+}
+
 // ExecuteQuery is a fake test query.
 const ExecuteQuery = "executeQuery"
 
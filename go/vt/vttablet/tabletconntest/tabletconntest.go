@@ -399,6 +399,120 @@ func testReadTransactionPanics(t *testing.T, conn queryservice.QueryService, f *
 	})
 }
 
+func testTransactionLockWaits(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testTransactionLockWaits")
+	ctx := context.Background()
+	ctx = callerid.NewContext(ctx, TestCallerID, TestVTGateCallerID)
+	lockWaits, err := conn.TransactionLockWaits(ctx, TestTarget)
+	if err != nil {
+		t.Fatalf("TransactionLockWaits failed: %v", err)
+	}
+	if len(lockWaits) != len(LockWaits) || !proto.Equal(lockWaits[0], LockWaits[0]) {
+		t.Errorf("Unexpected result from TransactionLockWaits: got %v wanted %v", lockWaits, LockWaits)
+	}
+}
+
+func testTransactionLockWaitsError(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testTransactionLockWaitsError")
+	f.HasError = true
+	testErrorHelper(t, f, "TransactionLockWaits", func(ctx context.Context) error {
+		_, err := conn.TransactionLockWaits(ctx, TestTarget)
+		return err
+	})
+	f.HasError = false
+}
+
+func testTransactionLockWaitsPanics(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testTransactionLockWaitsPanics")
+	testPanicHelper(t, f, "TransactionLockWaits", func(ctx context.Context) error {
+		_, err := conn.TransactionLockWaits(ctx, TestTarget)
+		return err
+	})
+}
+
+func testAbortTransaction(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testAbortTransaction")
+	ctx := context.Background()
+	ctx = callerid.NewContext(ctx, TestCallerID, TestVTGateCallerID)
+	err := conn.AbortTransaction(ctx, TestTarget, abortTransactionID, "deadlock")
+	if err != nil {
+		t.Fatalf("AbortTransaction failed: %v", err)
+	}
+}
+
+func testAbortTransactionError(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testAbortTransactionError")
+	f.HasError = true
+	testErrorHelper(t, f, "AbortTransaction", func(ctx context.Context) error {
+		return conn.AbortTransaction(ctx, TestTarget, abortTransactionID, "deadlock")
+	})
+	f.HasError = false
+}
+
+func testAbortTransactionPanics(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testAbortTransactionPanics")
+	testPanicHelper(t, f, "AbortTransaction", func(ctx context.Context) error {
+		return conn.AbortTransaction(ctx, TestTarget, abortTransactionID, "deadlock")
+	})
+}
+
+func testSaveVStreamPosition(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testSaveVStreamPosition")
+	ctx := context.Background()
+	ctx = callerid.NewContext(ctx, TestCallerID, TestVTGateCallerID)
+	err := conn.SaveVStreamPosition(ctx, TestTarget, VStreamConsumerGroup, VStreamPosition)
+	if err != nil {
+		t.Fatalf("SaveVStreamPosition failed: %v", err)
+	}
+}
+
+func testSaveVStreamPositionError(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testSaveVStreamPositionError")
+	f.HasError = true
+	testErrorHelper(t, f, "SaveVStreamPosition", func(ctx context.Context) error {
+		return conn.SaveVStreamPosition(ctx, TestTarget, VStreamConsumerGroup, VStreamPosition)
+	})
+	f.HasError = false
+}
+
+func testSaveVStreamPositionPanics(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testSaveVStreamPositionPanics")
+	testPanicHelper(t, f, "SaveVStreamPosition", func(ctx context.Context) error {
+		return conn.SaveVStreamPosition(ctx, TestTarget, VStreamConsumerGroup, VStreamPosition)
+	})
+}
+
+func testReadVStreamPosition(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testReadVStreamPosition")
+	ctx := context.Background()
+	ctx = callerid.NewContext(ctx, TestCallerID, TestVTGateCallerID)
+	shardGtid, err := conn.ReadVStreamPosition(ctx, TestTarget, VStreamConsumerGroup)
+	if err != nil {
+		t.Fatalf("ReadVStreamPosition failed: %v", err)
+	}
+	if !proto.Equal(shardGtid, VStreamPosition) {
+		t.Errorf("Unexpected result from ReadVStreamPosition: got %v wanted %v", shardGtid, VStreamPosition)
+	}
+}
+
+func testReadVStreamPositionError(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testReadVStreamPositionError")
+	f.HasError = true
+	testErrorHelper(t, f, "ReadVStreamPosition", func(ctx context.Context) error {
+		_, err := conn.ReadVStreamPosition(ctx, TestTarget, VStreamConsumerGroup)
+		return err
+	})
+	f.HasError = false
+}
+
+func testReadVStreamPositionPanics(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testReadVStreamPositionPanics")
+	testPanicHelper(t, f, "ReadVStreamPosition", func(ctx context.Context) error {
+		_, err := conn.ReadVStreamPosition(ctx, TestTarget, VStreamConsumerGroup)
+		return err
+	})
+}
+
 func testExecute(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
 	t.Log("testExecute")
 	f.ExpectedTransactionID = ExecuteTransactionID
@@ -936,6 +1050,10 @@ func TestSuite(ctx context.Context, t *testing.T, protocol string, tablet *topod
 		testSetRollback,
 		testConcludeTransaction,
 		testReadTransaction,
+		testTransactionLockWaits,
+		testAbortTransaction,
+		testSaveVStreamPosition,
+		testReadVStreamPosition,
 		testExecute,
 		testBeginExecute,
 		testStreamExecute,
@@ -956,6 +1074,10 @@ func TestSuite(ctx context.Context, t *testing.T, protocol string, tablet *topod
 		testSetRollbackError,
 		testConcludeTransactionError,
 		testReadTransactionError,
+		testTransactionLockWaitsError,
+		testAbortTransactionError,
+		testSaveVStreamPositionError,
+		testReadVStreamPositionError,
 		testExecuteError,
 		testBeginExecuteErrorInBegin,
 		testBeginExecuteErrorInExecute,
@@ -979,6 +1101,10 @@ func TestSuite(ctx context.Context, t *testing.T, protocol string, tablet *topod
 		testSetRollbackPanics,
 		testConcludeTransactionPanics,
 		testReadTransactionPanics,
+		testTransactionLockWaitsPanics,
+		testAbortTransactionPanics,
+		testSaveVStreamPositionPanics,
+		testReadVStreamPositionPanics,
 		testExecutePanics,
 		testBeginExecutePanics,
 		testStreamExecutePanics,
@@ -0,0 +1,324 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	bootstrapStateWaiting = "Waiting"
+	bootstrapStateBlocked = "Blocked"
+	bootstrapStateReplica = "Replica"
+	bootstrapStatePrimary = "Primary"
+)
+
+var (
+	// bootstrap makes the tablet join its shard without manual steps, see
+	// bootstrapLoop.
+	bootstrap bool
+	// bootstrapDurabilityPolicy is the durability policy set for the
+	// keyspace if it has none.
+	bootstrapDurabilityPolicy string
+	// bootstrapRetryInterval is the interval at which the bootstrap checks
+	// the shard until the tablet replicates from its primary or became it.
+	bootstrapRetryInterval = 5 * time.Second
+
+	statsBootstrapState = stats.NewString("BootstrapState")
+)
+
+func registerBootstrapFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&bootstrap, "bootstrap", bootstrap, "Bootstrap the tablet without manual steps: create the keyspace and shard records, restore the latest backup if --backup_storage_implementation is set, then replicate from the primary of the shard, or become its primary if the shard never had one.")
+	fs.StringVar(&bootstrapDurabilityPolicy, "bootstrap-durability-policy", bootstrapDurabilityPolicy, "Durability policy set for the keyspace with --bootstrap if it has none. The tablet fails to start if the keyspace has another policy.")
+	fs.DurationVar(&bootstrapRetryInterval, "bootstrap-retry-interval", bootstrapRetryInterval, "Interval at which a tablet started with --bootstrap checks its shard until it replicates from the primary or became it.")
+}
+
+func init() {
+	servenv.OnParseFor("vttablet", registerBootstrapFlags)
+}
+
+// bootstrapRestore returns whether the tablet started with --bootstrap must
+// restore the latest backup of its shard, like with --restore_from_backup.
+func (tm *TabletManager) bootstrapRestore() bool {
+	return bootstrap && tm.Cnf != nil && backupstorage.BackupStorageImplementation != ""
+}
+
+// bootstrapKeyspace sets the durability policy of the keyspace created for
+// the tablet, or checks the one of an existing keyspace.
+func (tm *TabletManager) bootstrapKeyspace(ctx context.Context) error {
+	if !bootstrap || bootstrapDurabilityPolicy == "" {
+		return nil
+	}
+	if _, err := reparentutil.GetDurabilityPolicy(bootstrapDurabilityPolicy); err != nil {
+		return vterrors.Wrapf(err, "invalid --bootstrap-durability-policy")
+	}
+
+	keyspace := tm.Tablet().Keyspace
+	ki, err := tm.TopoServer.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return vterrors.Wrap(err, "bootstrap: cannot read keyspace")
+	}
+	if ki.DurabilityPolicy == bootstrapDurabilityPolicy {
+		return nil
+	}
+	if ki.DurabilityPolicy != "" {
+		return fmt.Errorf("bootstrap: keyspace %v has durability policy %v, not %v", keyspace, ki.DurabilityPolicy, bootstrapDurabilityPolicy)
+	}
+
+	lockCtx, unlock, lockErr := tm.TopoServer.LockKeyspace(ctx, keyspace, "Setting durability policy")
+	if lockErr != nil {
+		return vterrors.Wrap(lockErr, "bootstrap: cannot lock keyspace")
+	}
+	defer unlock(&lockErr)
+	// Read the keyspace again, under the lock.
+	ki, err = tm.TopoServer.GetKeyspace(lockCtx, keyspace)
+	if err != nil {
+		return vterrors.Wrap(err, "bootstrap: cannot read keyspace")
+	}
+	switch ki.DurabilityPolicy {
+	case bootstrapDurabilityPolicy:
+		return nil
+	case "":
+	default:
+		return fmt.Errorf("bootstrap: keyspace %v has durability policy %v, not %v", keyspace, ki.DurabilityPolicy, bootstrapDurabilityPolicy)
+	}
+	log.Infof("Bootstrap: setting the durability policy of keyspace %v to %v", keyspace, bootstrapDurabilityPolicy)
+	ki.DurabilityPolicy = bootstrapDurabilityPolicy
+	if err := tm.TopoServer.UpdateKeyspace(lockCtx, ki); err != nil {
+		return vterrors.Wrap(err, "bootstrap: cannot update keyspace")
+	}
+	return nil
+}
+
+func (tm *TabletManager) startBootstrap() {
+	if !bootstrap || mysqlctl.DisableActiveReparents {
+		return
+	}
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	tm._bootstrapDone = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	tm._bootstrapCancel = cancel
+	statsBootstrapState.Set(bootstrapStateWaiting)
+	go tm.bootstrapLoop(ctx, tm._bootstrapDone)
+}
+
+func (tm *TabletManager) stopBootstrap() {
+	tm.mutex.Lock()
+	if tm._bootstrapCancel != nil {
+		tm._bootstrapCancel()
+	}
+	doneChan := tm._bootstrapDone
+	tm.mutex.Unlock()
+
+	// If the bootstrap loop was running, wait for it to fully stop.
+	if doneChan != nil {
+		<-doneChan
+	}
+}
+
+// bootstrapLoop checks the shard until the tablet replicates from its
+// primary, or became its primary.
+func (tm *TabletManager) bootstrapLoop(ctx context.Context, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(bootstrapRetryInterval)
+	defer ticker.Stop()
+	var lastErr string
+	for {
+		done, err := tm.bootstrapShard(ctx)
+		switch {
+		case done:
+			return
+		case err != nil && err.Error() != lastErr:
+			// Log the reason the tablet waits when it changes only.
+			log.Warningf("Bootstrap: %v", err)
+			statsBootstrapState.Set(bootstrapStateBlocked)
+			lastErr = err.Error()
+		case err == nil:
+			statsBootstrapState.Set(bootstrapStateWaiting)
+			lastErr = ""
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// bootstrapShard makes the tablet replicate from the primary of the shard,
+// or become its primary if the shard never had one. It returns true when the
+// bootstrap is done.
+func (tm *TabletManager) bootstrapShard(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	tablet := tm.Tablet()
+	if tablet.Type == topodatapb.TabletType_PRIMARY {
+		statsBootstrapState.Set(bootstrapStatePrimary)
+		return true, nil
+	}
+	si, err := tm.TopoServer.GetShard(ctx, tablet.Keyspace, tablet.Shard)
+	if err != nil {
+		return false, vterrors.Wrap(err, "cannot read shard")
+	}
+	if si.PrimaryAlias == nil {
+		return tm.bootstrapPrimary(ctx)
+	}
+	if topoproto.TabletAliasEqual(si.PrimaryAlias, tablet.Alias) {
+		// The shard sync switches the tablet to primary.
+		return false, nil
+	}
+	return tm.bootstrapReplica(ctx)
+}
+
+// bootstrapReplica starts the replication from the primary of the shard,
+// unless it is already configured, e.g. by the restore.
+func (tm *TabletManager) bootstrapReplica(ctx context.Context) (bool, error) {
+	status, err := tm.MysqlDaemon.ReplicationStatus(ctx)
+	switch {
+	case err == nil && status.SourceHost != "":
+		statsBootstrapState.Set(bootstrapStateReplica)
+		return true, nil
+	case err != nil && !errors.Is(err, mysql.ErrNotReplica):
+		return false, vterrors.Wrap(err, "cannot read replication status")
+	}
+	if err := tm.lock(ctx); err != nil {
+		return false, err
+	}
+	defer tm.unlock()
+	primary, err := tm.initializeReplication(ctx, tm.Tablet().Type)
+	if err != nil {
+		return false, err
+	}
+	if primary == nil {
+		// The primary tablet has no MySQL hostname yet.
+		return false, nil
+	}
+	log.Infof("Bootstrap: replicating from primary %v", topoproto.TabletAliasString(primary.Alias))
+	statsBootstrapState.Set(bootstrapStateReplica)
+	return true, nil
+}
+
+// bootstrapPrimary makes the tablet the primary of a shard which never had
+// one. The checks and the promotion are done under the shard lock, so that
+// a single tablet of the shard becomes its primary.
+func (tm *TabletManager) bootstrapPrimary(ctx context.Context) (done bool, err error) {
+	tablet := tm.Tablet()
+	durabilityName, err := tm.TopoServer.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot read keyspace durability policy %v", tablet.Keyspace)
+	}
+	durability, err := reparentutil.GetDurabilityPolicy(durabilityName)
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot get durability policy %v", durabilityName)
+	}
+	if tablet.Type != topodatapb.TabletType_REPLICA || reparentutil.PromotionRule(durability, tablet) == promotionrule.MustNot {
+		// The tablet waits for another one to become the primary.
+		return false, nil
+	}
+
+	lockCtx, unlock, lockErr := tm.TopoServer.LockShard(ctx, tablet.Keyspace, tablet.Shard, "BootstrapShard")
+	if lockErr != nil {
+		return false, vterrors.Wrap(lockErr, "cannot lock shard")
+	}
+	defer unlock(&err)
+
+	if err := tm.checkBootstrapPrimary(lockCtx, tablet); err != nil {
+		return false, err
+	}
+
+	log.Infof("Bootstrap: shard %v/%v never had a primary, promoting tablet %v", tablet.Keyspace, tablet.Shard, topoproto.TabletAliasString(tablet.Alias))
+	pos, err := tm.InitPrimary(lockCtx, reparentutil.SemiSyncAckers(durability, tablet) > 0)
+	if err != nil {
+		return false, vterrors.Wrap(err, "InitPrimary failed")
+	}
+	if err := tm.PopulateReparentJournal(lockCtx, time.Now().UnixNano(), "BootstrapShard", tablet.Alias, pos); err != nil {
+		return false, vterrors.Wrap(err, "PopulateReparentJournal failed")
+	}
+	// Update the shard record under the lock, rather than letting the shard
+	// sync do it, so that the other tablets see the primary when they get
+	// the lock.
+	primaryTermStartTime := tm.Tablet().PrimaryTermStartTime
+	if _, err := tm.TopoServer.UpdateShardFields(lockCtx, tablet.Keyspace, tablet.Shard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = tablet.Alias
+		si.PrimaryTermStartTime = primaryTermStartTime
+		return nil
+	}); err != nil {
+		return false, vterrors.Wrap(err, "cannot update shard record")
+	}
+	statsBootstrapState.Set(bootstrapStatePrimary)
+	return true, nil
+}
+
+// checkBootstrapPrimary returns an error if promoting the tablet could lead
+// to a split brain or lose data. It must be called under the shard lock.
+func (tm *TabletManager) checkBootstrapPrimary(ctx context.Context, tablet *topodatapb.Tablet) error {
+	// Read the shard again, under the lock.
+	si, err := tm.TopoServer.GetShard(ctx, tablet.Keyspace, tablet.Shard)
+	if err != nil {
+		return vterrors.Wrap(err, "cannot read shard")
+	}
+	if si.PrimaryAlias != nil {
+		return fmt.Errorf("shard %v/%v got primary %v", tablet.Keyspace, tablet.Shard, topoproto.TabletAliasString(si.PrimaryAlias))
+	}
+	if si.PrimaryTermStartTime != nil {
+		// The primary of the shard is gone, its data may only be on the
+		// other tablets.
+		return fmt.Errorf("shard %v/%v had a primary until %v, use EmergencyReparentShard or PlannedReparentShard to elect a new one",
+			tablet.Keyspace, tablet.Shard, protoutil.TimeFromProto(si.PrimaryTermStartTime).UTC())
+	}
+	tablets, err := tm.TopoServer.GetTabletMapForShard(ctx, tablet.Keyspace, tablet.Shard)
+	if err != nil && !topo.IsErrType(err, topo.PartialResult) {
+		return vterrors.Wrap(err, "cannot read the tablets of the shard")
+	}
+	if err != nil {
+		// A tablet which could not be read could be a primary.
+		return vterrors.Wrap(err, "cannot read all the tablets of the shard")
+	}
+	for alias, ti := range tablets {
+		if ti.Type == topodatapb.TabletType_PRIMARY && !topoproto.TabletAliasEqual(ti.Alias, tablet.Alias) {
+			return fmt.Errorf("tablet %v of shard %v/%v is a primary but the shard record has none", alias, tablet.Keyspace, tablet.Shard)
+		}
+	}
+	status, err := tm.MysqlDaemon.ReplicationStatus(ctx)
+	if err == nil && status.SourceHost != "" {
+		return fmt.Errorf("MySQL replicates from %v:%v", status.SourceHost, status.SourcePort)
+	}
+	return nil
+}
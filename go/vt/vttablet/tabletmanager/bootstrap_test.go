@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// setBootstrapFlags enables --bootstrap with the durability policy, without
+// starting the bootstrap loop, and restores the flags at the end of the test.
+func setBootstrapFlags(t *testing.T, durability string) {
+	oldBootstrap, oldDurability, oldDisable := bootstrap, bootstrapDurabilityPolicy, mysqlctl.DisableActiveReparents
+	bootstrap, bootstrapDurabilityPolicy, mysqlctl.DisableActiveReparents = true, durability, true
+	t.Cleanup(func() {
+		bootstrap, bootstrapDurabilityPolicy, mysqlctl.DisableActiveReparents = oldBootstrap, oldDurability, oldDisable
+	})
+}
+
+func TestBootstrapKeyspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	setBootstrapFlags(t, "semi_sync")
+
+	// The policy is set on the keyspace created for the tablet.
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	ki, err := ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+
+	// The policy of an existing keyspace is not changed.
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "test")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "none"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+	assert.ErrorContains(t, tm.bootstrapKeyspace(ctx), "bootstrap: keyspace ks has durability policy none, not semi_sync")
+
+	bootstrapDurabilityPolicy = "unknown"
+	assert.ErrorContains(t, tm.bootstrapKeyspace(ctx), "invalid --bootstrap-durability-policy")
+}
+
+func TestCheckBootstrapPrimary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	setBootstrapFlags(t, "none")
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	tablet := tm.Tablet()
+
+	require.NoError(t, tm.checkBootstrapPrimary(ctx, tablet))
+
+	// Another tablet claims to be the primary.
+	other := newTestTablet(t, 2, "ks", "0")
+	other.Type = topodatapb.TabletType_PRIMARY
+	require.NoError(t, ts.CreateTablet(ctx, other))
+	assert.ErrorContains(t, tm.checkBootstrapPrimary(ctx, tablet), "tablet cell1-0000000002 of shard ks/0 is a primary but the shard record has none")
+	require.NoError(t, ts.DeleteTablet(ctx, other.Alias))
+
+	// MySQL already replicates from somewhere.
+	fakeMysql := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	fakeMysql.Replicating = true
+	fakeMysql.CurrentSourceHost = "mysql-host"
+	fakeMysql.CurrentSourcePort = 3306
+	assert.ErrorContains(t, tm.checkBootstrapPrimary(ctx, tablet), "MySQL replicates from mysql-host:3306")
+	fakeMysql.Replicating = false
+	fakeMysql.CurrentSourceHost = ""
+
+	// The shard had a primary.
+	_, err := ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.PrimaryTermStartTime = protoutil.TimeToProto(time.Unix(1000, 0))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, tm.checkBootstrapPrimary(ctx, tablet), "shard ks/0 had a primary until")
+
+	// The shard has a primary.
+	_, err = ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = other.Alias
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, tm.checkBootstrapPrimary(ctx, tablet), "shard ks/0 got primary cell1-0000000002")
+}
@@ -260,6 +260,16 @@ func (ftc *fakeTabletConn) ReadTransaction(ctx context.Context, target *querypb.
 	return nil, nil
 }
 
+// fakeTabletConn implements the QueryService interface.
+func (ftc *fakeTabletConn) TransactionLockWaits(ctx context.Context, target *querypb.Target) ([]*querypb.TransactionLockWait, error) {
+	return nil, nil
+}
+
+// fakeTabletConn implements the QueryService interface.
+func (ftc *fakeTabletConn) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) error {
+	return nil
+}
+
 // fakeTabletConn implements the QueryService interface.
 func (ftc *fakeTabletConn) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, error) {
 	return nil, nil
@@ -309,6 +319,16 @@ func (ftc *fakeTabletConn) VStreamResults(ctx context.Context, target *querypb.T
 	return nil
 }
 
+// fakeTabletConn implements the QueryService interface.
+func (ftc *fakeTabletConn) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	return nil
+}
+
+// fakeTabletConn implements the QueryService interface.
+func (ftc *fakeTabletConn) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	return nil, nil
+}
+
 // fakeTabletConn implements the QueryService interface.
 func (ftc *fakeTabletConn) HandlePanic(err *error) {
 }
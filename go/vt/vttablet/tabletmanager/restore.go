@@ -25,6 +25,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
 
 	"vitess.io/vitess/go/stats"
@@ -36,7 +37,6 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstats"
-	"vitess.io/vitess/go/vt/proto/vttime"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -45,6 +45,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -88,6 +89,7 @@ var (
 	binlogUser           string
 	binlogPwd            string
 	timeoutForGTIDLookup = 60 * time.Second
+	pitrCatchupTimeout   time.Duration
 	binlogSslCa          string
 	binlogSslCert        string
 	binlogSslKey         string
@@ -95,11 +97,12 @@ var (
 )
 
 func registerPointInTimeRestoreFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&binlogHost, "binlog_host", binlogHost, "PITR restore parameter: hostname/IP of binlog server.")
+	fs.StringVar(&binlogHost, "binlog_host", binlogHost, "PITR restore parameter: hostname/IP of binlog server. If not set, the binlog source of the snapshot keyspace is used, or else the primary of the shard in the base keyspace.")
 	fs.IntVar(&binlogPort, "binlog_port", binlogPort, "PITR restore parameter: port of binlog server.")
-	fs.StringVar(&binlogUser, "binlog_user", binlogUser, "PITR restore parameter: username of binlog server.")
+	fs.StringVar(&binlogUser, "binlog_user", binlogUser, "PITR restore parameter: username of binlog server. If not set, the replication user of the tablet is used.")
 	fs.StringVar(&binlogPwd, "binlog_password", binlogPwd, "PITR restore parameter: password of binlog server.")
 	fs.DurationVar(&timeoutForGTIDLookup, "pitr_gtid_lookup_timeout", timeoutForGTIDLookup, "PITR restore parameter: timeout for fetching gtid from timestamp.")
+	fs.DurationVar(&pitrCatchupTimeout, "pitr-catchup-timeout", pitrCatchupTimeout, "PITR restore parameter: timeout for replicating up to the snapshot time of the keyspace from the binlog server. 0 waits until it is reached.")
 	fs.StringVar(&binlogSslCa, "binlog_ssl_ca", binlogSslCa, "PITR restore parameter: Filename containing TLS CA certificate to verify binlog server TLS certificate against.")
 	fs.StringVar(&binlogSslCert, "binlog_ssl_cert", binlogSslCert, "PITR restore parameter: Filename containing mTLS client certificate to present to binlog server as authentication.")
 	fs.StringVar(&binlogSslKey, "binlog_ssl_key", binlogSslKey, "PITR restore parameter: Filename containing mTLS client private key for use in binlog server authentication.")
@@ -267,6 +270,11 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 	if err := tm.tmState.ChangeTabletType(ctx, topodatapb.TabletType_RESTORE, DBActionNone); err != nil {
 		return err
 	}
+	if keyspaceInfo.SnapshotTime != nil {
+		tm.setSnapshotRestoreStatus(&replicationdatapb.SnapshotRestoreStatus{
+			Phase: replicationdatapb.SnapshotRestoreStatus_RESTORING_BACKUP,
+		})
+	}
 	// Loop until a backup exists, unless we were told to give up immediately.
 	var backupManifest *mysqlctl.BackupManifest
 	for {
@@ -300,10 +308,17 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 	// If SnapshotTime is set , then apply the incremental change
 	if keyspaceInfo.SnapshotTime != nil {
 		params.Logger.Infof("Restore: Restoring to time %v from binlog", keyspaceInfo.SnapshotTime)
-		err = tm.restoreToTimeFromBinlog(ctx, pos, keyspaceInfo.SnapshotTime)
+		err = tm.restoreToTimeFromBinlog(ctx, keyspaceInfo, tablet.Shard, pos)
 		if err != nil {
 			log.Errorf("unable to restore to the specified time %s, error : %v", keyspaceInfo.SnapshotTime.String(), err)
-			return nil
+			tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+				status.Phase = replicationdatapb.SnapshotRestoreStatus_FAILED
+				status.Error = err.Error()
+			})
+			// The data is not at the snapshot time, so the tablet stays in
+			// the non-serving RESTORE type rather than going back to its
+			// original type.
+			return vterrors.Wrapf(err, "unable to restore to the snapshot time %s", keyspaceInfo.SnapshotTime.String())
 		}
 	}
 	switch {
@@ -365,17 +380,24 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 
 // restoreToTimeFromBinlog restores to the snapshot time of the keyspace
 // currently this works with mysql based database only (as it uses mysql specific queries for restoring)
-func (tm *TabletManager) restoreToTimeFromBinlog(ctx context.Context, pos replication.Position, restoreTime *vttime.Time) error {
-	// validate the minimal settings necessary for connecting to binlog server
-	if binlogHost == "" || binlogPort <= 0 || binlogUser == "" {
-		log.Warning("invalid binlog server setting, restoring to last available backup.")
-		return nil
+func (tm *TabletManager) restoreToTimeFromBinlog(ctx context.Context, keyspaceInfo *topo.KeyspaceInfo, shard string, pos replication.Position) error {
+	restoreTime := keyspaceInfo.SnapshotTime
+	source, err := tm.snapshotBinlogSource(ctx, keyspaceInfo, shard)
+	if err != nil {
+		return err
 	}
+	sourceAddr := netutil.JoinHostPort(source.Host, int32(source.Port))
+	log.Infof("restoring to time %s from binlog source %s", restoreTime.String(), sourceAddr)
+	tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+		status.Phase = replicationdatapb.SnapshotRestoreStatus_LOCATING_POSITION
+		status.BinlogSource = sourceAddr
+		status.Position = replication.EncodePosition(pos)
+	})
 
 	timeoutCtx, cancelFnc := context.WithTimeout(ctx, timeoutForGTIDLookup)
 	defer cancelFnc()
 
-	afterGTIDPos, beforeGTIDPos, err := tm.getGTIDFromTimestamp(timeoutCtx, pos, restoreTime.Seconds)
+	afterGTIDPos, beforeGTIDPos, err := tm.getGTIDFromTimestamp(timeoutCtx, source, pos, restoreTime.Seconds)
 	if err != nil {
 		return err
 	}
@@ -392,11 +414,26 @@ func (tm *TabletManager) restoreToTimeFromBinlog(ctx context.Context, pos replic
 	if beforeGTIDPos == "" {
 		beforeGTIDPos = pos.GTIDSet.Last()
 	}
-	err = tm.catchupToGTID(timeoutCtx, afterGTIDPos, beforeGTIDPos)
+	tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+		status.Phase = replicationdatapb.SnapshotRestoreStatus_CATCHING_UP
+		status.TargetPosition = beforeGTIDPos
+	})
+
+	// Catching up can take much longer than looking up the GTIDs, as it
+	// replicates all the transactions up to the snapshot time.
+	catchupCtx, catchupCancel := context.WithCancel(ctx)
+	if pitrCatchupTimeout > 0 {
+		catchupCtx, catchupCancel = context.WithTimeout(ctx, pitrCatchupTimeout)
+	}
+	defer catchupCancel()
+	err = tm.catchupToGTID(catchupCtx, source, afterGTIDPos, beforeGTIDPos)
 	if err != nil {
 		return vterrors.Wrapf(err, "unable to replicate upto desired GTID : %s", afterGTIDPos)
 	}
 
+	tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+		status.Phase = replicationdatapb.SnapshotRestoreStatus_CAUGHT_UP
+	})
 	return nil
 }
 
@@ -405,22 +442,7 @@ func (tm *TabletManager) restoreToTimeFromBinlog(ctx context.Context, pos replic
 // beforePos is the GTID of the last event before restoreTime. This is the GTID upto which replication will be applied
 // afterPos can be used directly in the query `START SLAVE UNTIL SQL_BEFORE_GTIDS = ”`
 // beforePos will be used to check if replication was able to catch up from the binlog server
-func (tm *TabletManager) getGTIDFromTimestamp(ctx context.Context, pos replication.Position, restoreTime int64) (afterPos string, beforePos string, err error) {
-	connParams := &mysql.ConnParams{
-		Host:       binlogHost,
-		Port:       binlogPort,
-		Uname:      binlogUser,
-		SslCa:      binlogSslCa,
-		SslCert:    binlogSslCert,
-		SslKey:     binlogSslKey,
-		ServerName: binlogSslServerName,
-	}
-	if binlogPwd != "" {
-		connParams.Pass = binlogPwd
-	}
-	if binlogSslCa != "" || binlogSslCert != "" {
-		connParams.EnableSSL()
-	}
+func (tm *TabletManager) getGTIDFromTimestamp(ctx context.Context, connParams *mysql.ConnParams, pos replication.Position, restoreTime int64) (afterPos string, beforePos string, err error) {
 	dbCfgs := &dbconfigs.DBConfigs{
 		Host: connParams.Host,
 		Port: connParams.Port,
@@ -492,7 +514,7 @@ func (tm *TabletManager) getGTIDFromTimestamp(ctx context.Context, pos replicati
 // copies the data from binlog server by pointing to as replica
 // waits till all events to GTID replicated
 // once done, it will reset the replication
-func (tm *TabletManager) catchupToGTID(ctx context.Context, afterGTIDPos string, beforeGTIDPos string) error {
+func (tm *TabletManager) catchupToGTID(ctx context.Context, source *mysql.ConnParams, afterGTIDPos string, beforeGTIDPos string) error {
 	var afterGTID replication.Position
 	if afterGTIDPos != "" {
 		var err error
@@ -507,50 +529,52 @@ func (tm *TabletManager) catchupToGTID(ctx context.Context, afterGTIDPos string,
 		return err
 	}
 
-	if err := tm.MysqlDaemon.CatchupToGTID(ctx, afterGTID); err != nil {
+	if err := tm.MysqlDaemon.CatchupToGTID(ctx, source, afterGTID); err != nil {
 		return vterrors.Wrap(err, fmt.Sprintf("failed to restart the replication until %s GTID", afterGTID.GTIDSet.Last()))
 	}
-	log.Infof("Waiting for position to reach", beforeGTIDPosParsed.GTIDSet.Last())
+	log.Infof("Waiting for position to reach %v", beforeGTIDPosParsed.GTIDSet.Last())
 	// Could not use `agent.MysqlDaemon.WaitSourcePos` as replication is stopped with `START REPLICA UNTIL SQL_BEFORE_GTIDS`
 	// this is as per https://dev.mysql.com/doc/refman/8.0/en/start-replica.html
 	// We need to wait until replication catches upto the specified afterGTIDPos
-	chGTIDCaughtup := make(chan bool)
-	go func() {
-		timeToWait := time.Now().Add(timeoutForGTIDLookup)
-		for time.Now().Before(timeToWait) {
-			pos, err := tm.MysqlDaemon.PrimaryPosition(ctx)
-			if err != nil {
-				chGTIDCaughtup <- false
-			}
-
-			if pos.AtLeast(beforeGTIDPosParsed) {
-				chGTIDCaughtup <- true
-			}
-			select {
-			case <-ctx.Done():
-				chGTIDCaughtup <- false
-			default:
-				time.Sleep(300 * time.Millisecond)
-			}
+	ticker := time.NewTicker(catchupPollInterval)
+	defer ticker.Stop()
+	for {
+		pos, err := tm.MysqlDaemon.PrimaryPosition(ctx)
+		if err != nil {
+			return vterrors.Wrap(err, "error while fetching the current GTID position")
 		}
-	}()
-	select {
-	case resp := <-chGTIDCaughtup:
-		if resp {
-			if err := tm.MysqlDaemon.StopReplication(ctx, nil); err != nil {
-				return vterrors.Wrap(err, "failed to stop replication")
+		tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+			status.Position = replication.EncodePosition(pos)
+		})
+		if pos.AtLeast(beforeGTIDPosParsed) {
+			break
+		}
+		// The replication threads stop on errors, in which case the position
+		// would never be reached. The IO thread keeps retrying to connect to
+		// the source otherwise.
+		if status, err := tm.MysqlDaemon.ReplicationStatus(ctx); err == nil {
+			if status.LastSQLError != "" {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "replication failed: %s", status.LastSQLError)
 			}
-			if err := tm.MysqlDaemon.ResetReplicationParameters(ctx); err != nil {
-				return vterrors.Wrap(err, "failed to reset replication")
+			if status.LastIOError != "" && status.IOState == replication.ReplicationStateStopped {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "replication failed: %s", status.LastIOError)
 			}
-
-			return nil
 		}
-		return vterrors.Wrap(err, "error while fetching the current GTID position")
-	case <-ctx.Done():
-		log.Warningf("Could not copy up to GTID.")
-		return vterrors.Wrapf(err, "context timeout while restoring up to specified GTID - %s", beforeGTIDPos)
+		select {
+		case <-ctx.Done():
+			log.Warningf("Could not copy up to GTID.")
+			return vterrors.Wrapf(ctx.Err(), "context timeout while restoring up to specified GTID - %s", beforeGTIDPos)
+		case <-ticker.C:
+		}
+	}
+
+	if err := tm.MysqlDaemon.StopReplication(ctx, nil); err != nil {
+		return vterrors.Wrap(err, "failed to stop replication")
 	}
+	if err := tm.MysqlDaemon.ResetReplicationParameters(ctx); err != nil {
+		return vterrors.Wrap(err, "failed to reset replication")
+	}
+	return nil
 }
 
 // disableReplication stops and resets replication on the mysql server. It moreover sets impossible replication
@@ -47,6 +47,8 @@ type RPCTM interface {
 	// An empty/nil variable name parameter slice means you want all of them.
 	GetGlobalStatusVars(ctx context.Context, variables []string) (map[string]string, error)
 
+	SelfCheck(ctx context.Context) *tabletmanagerdatapb.SelfCheckResponse
+
 	// Various read-write methods
 
 	SetReadOnly(ctx context.Context, rdonly bool) error
@@ -150,6 +152,10 @@ type RPCTM interface {
 
 	RestoreFromBackup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestoreFromBackupRequest) error
 
+	// MySQL related methods
+
+	RestartMySQL(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestartMySQLRequest) error
+
 	// HandleRPCPanic is to be called in a defer statement in each
 	// RPC input point.
 	HandleRPCPanic(ctx context.Context, name string, args, reply any, verbose bool, err *error)
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// restartMySQLReplicationDeadline is how many seconds RestartMySQL waits for
+// the replication to run again once MySQL is back.
+const restartMySQLReplicationDeadline = 30
+
+// RestartMySQL restarts MySQL without racing with the query service and the
+// health check: it drains the query service, which reports the tablet as not
+// serving while MySQL restarts, shuts MySQL down cleanly and starts it again,
+// through mysqlctld if the tablet uses it. Once MySQL accepts connections, it
+// restores the read-only, semi-sync and replication settings MySQL does not
+// keep across restarts, checks that the replication runs again, and lets the
+// query service serve again.
+func (tm *TabletManager) RestartMySQL(ctx context.Context, logger logutil.Logger, req *tabletmanagerdatapb.RestartMySQLRequest) error {
+	if tm.Cnf == nil {
+		return fmt.Errorf("cannot restart MySQL without my.cnf, please restart vttablet with a my.cnf file specified")
+	}
+	shutdownTimeout := mysqlShutdownTimeout
+	if timeout, ok, err := protoutil.DurationFromProto(req.ShutdownTimeout); err != nil {
+		return vterrors.Wrap(err, "invalid shutdown timeout")
+	} else if ok {
+		shutdownTimeout = timeout
+	}
+
+	if err := tm.lock(ctx); err != nil {
+		return err
+	}
+	defer tm.unlock()
+
+	tablet := tm.Tablet()
+	if !req.AllowPrimary && tablet.Type == topodatapb.TabletType_PRIMARY {
+		return fmt.Errorf("type PRIMARY cannot restart MySQL without stopping the writes to the shard. if you really need to do this, set allow_primary")
+	}
+
+	// Create the logger: tee to console and source.
+	l := logutil.NewTeeLogger(logutil.NewConsoleLogger(), logger)
+	alias := topoproto.TabletAliasString(tablet.Alias)
+
+	// Record the settings to restore: MySQL starts with the ones of its
+	// configuration, and without replication.
+	readOnly, err := tm.MysqlDaemon.IsReadOnly(ctx)
+	if err != nil {
+		return vterrors.Wrap(err, "can't get read_only")
+	}
+	superReadOnly, err := tm.MysqlDaemon.IsSuperReadOnly(ctx)
+	if err != nil {
+		return vterrors.Wrap(err, "can't get super_read_only")
+	}
+	semiSyncSource, semiSyncReplica := tm.MysqlDaemon.SemiSyncEnabled(ctx)
+	replicating := false
+	status, err := tm.MysqlDaemon.ReplicationStatus(ctx)
+	switch {
+	case err == nil:
+		replicating = status.SQLHealthy()
+	case !errors.Is(err, mysql.ErrNotReplica):
+		return vterrors.Wrap(err, "can't get replication status")
+	}
+
+	l.Infof("Draining the query service of tablet %v", alias)
+	if err := tm.tmState.SetRestartingMySQL(ctx, true); err != nil {
+		return vterrors.Wrap(err, "can't drain the query service")
+	}
+	defer func() {
+		// The query service serves again whatever happened, so that the
+		// tablet is not left drained: its health check reports MySQL if it
+		// did not come back.
+		l.Infof("Restoring the serving state of tablet %v", alias)
+		if err := tm.tmState.SetRestartingMySQL(context.Background(), false); err != nil {
+			l.Errorf("Failed to restore the serving state of tablet %v: %v", alias, err)
+		}
+		tm.QueryServiceControl.BroadcastHealth()
+	}()
+
+	if replicating {
+		l.Infof("Stopping replication")
+		if err := tm.MysqlDaemon.StopReplication(ctx, tm.hookExtraEnv()); err != nil {
+			return vterrors.Wrap(err, "can't stop replication")
+		}
+	}
+
+	l.Infof("Shutting down MySQL, waiting up to %v", shutdownTimeout)
+	if err := tm.MysqlDaemon.Shutdown(ctx, tm.Cnf, true, shutdownTimeout); err != nil {
+		return vterrors.Wrap(err, "can't shut down MySQL")
+	}
+
+	l.Infof("Starting MySQL")
+	if err := tm.MysqlDaemon.Start(ctx, tm.Cnf); err != nil {
+		return vterrors.Wrap(err, "can't start MySQL")
+	}
+	l.Infof("Waiting for MySQL to accept connections")
+	if err := tm.MysqlDaemon.Wait(ctx, tm.Cnf); err != nil {
+		return vterrors.Wrap(err, "MySQL did not recover")
+	}
+
+	l.Infof("Restoring read_only=%v, super_read_only=%v", readOnly, superReadOnly)
+	if _, err := tm.MysqlDaemon.SetSuperReadOnly(ctx, superReadOnly); err != nil {
+		return vterrors.Wrap(err, "can't restore super_read_only")
+	}
+	if err := tm.MysqlDaemon.SetReadOnly(ctx, readOnly); err != nil {
+		return vterrors.Wrap(err, "can't restore read_only")
+	}
+	// Only do this if one of them was on, since both being off could mean
+	// the plugin isn't even loaded, and the server variables don't exist.
+	if semiSyncSource || semiSyncReplica {
+		l.Infof("Restoring semi-sync settings: primary=%v, replica=%v", semiSyncSource, semiSyncReplica)
+		if err := tm.MysqlDaemon.SetSemiSyncEnabled(ctx, semiSyncSource, semiSyncReplica); err != nil {
+			return vterrors.Wrap(err, "can't restore semi-sync settings")
+		}
+	}
+
+	if replicating {
+		l.Infof("Starting replication")
+		if err := tm.MysqlDaemon.StartReplication(ctx, tm.hookExtraEnv()); err != nil {
+			return vterrors.Wrap(err, "can't start replication")
+		}
+		if err := mysqlctl.WaitForReplicationStart(ctx, tm.MysqlDaemon, restartMySQLReplicationDeadline); err != nil {
+			return vterrors.Wrap(err, "replication is not running")
+		}
+		status, err := tm.MysqlDaemon.ReplicationStatus(ctx)
+		if err != nil {
+			return vterrors.Wrap(err, "can't get replication status")
+		}
+		if !status.Healthy() {
+			return fmt.Errorf("replication is not healthy after the restart: Last_IO_Error: %q, Last_SQL_Error: %q", status.LastIOError, status.LastSQLError)
+		}
+	}
+
+	l.Infof("MySQL of tablet %v restarted", alias)
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestRestartMySQL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	tm.Cnf = &mysqlctl.Mycnf{}
+	qsc := tm.QueryServiceControl.(*tabletservermock.Controller)
+
+	// stateChanges returns the serving states the query service went
+	// through since it was last called.
+	stateChanges := func() []bool {
+		var serving []bool
+		for {
+			select {
+			case change := <-qsc.StateChanges:
+				serving = append(serving, change.Serving)
+			default:
+				return serving
+			}
+		}
+	}
+
+	fmd := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	fmd.Replicating = true
+	fmd.SuperReadOnly.Store(true)
+	fmd.ReadOnly = true
+	fmd.SemiSyncReplicaEnabled = true
+	fmd.ExpectedExecuteSuperQueryList = []string{"STOP REPLICA", "START REPLICA"}
+	stateChanges()
+
+	logger := logutil.NewMemoryLogger()
+	err := tm.RestartMySQL(ctx, logger, &tabletmanagerdatapb.RestartMySQLRequest{})
+	require.NoError(t, err)
+	// The query service was drained while MySQL restarted, and serves again.
+	assert.Equal(t, []bool{false, true}, stateChanges())
+	assert.True(t, qsc.IsServing())
+	// MySQL is back with its settings and replication.
+	assert.True(t, fmd.Running)
+	assert.True(t, fmd.Replicating)
+	assert.True(t, fmd.SuperReadOnly.Load())
+	assert.True(t, fmd.ReadOnly)
+	assert.True(t, fmd.SemiSyncReplicaEnabled)
+	assert.Equal(t, 2, fmd.ExpectedExecuteSuperQueryCurrent)
+	assert.Contains(t, logger.String(), "Shutting down MySQL")
+	assert.Contains(t, logger.String(), "restarted")
+
+	// If the replication does not start again, the query service serves
+	// again anyway.
+	fmd.ExpectedExecuteSuperQueryList = []string{"STOP REPLICA"}
+	fmd.ExpectedExecuteSuperQueryCurrent = 0
+	fmd.StartReplicationError = errors.New("replication failed")
+	err = tm.RestartMySQL(ctx, logutil.NewMemoryLogger(), &tabletmanagerdatapb.RestartMySQLRequest{})
+	assert.ErrorContains(t, err, "can't start replication: replication failed")
+	assert.Equal(t, []bool{false, true}, stateChanges())
+	assert.True(t, qsc.IsServing())
+	assert.True(t, fmd.Running)
+
+	// The primary only restarts its MySQL if it is allowed to.
+	fmd.StartReplicationError = nil
+	fmd.Replicating = false
+	require.NoError(t, tm.tmState.ChangeTabletType(ctx, topodatapb.TabletType_PRIMARY, DBActionNone))
+	stateChanges()
+	err = tm.RestartMySQL(ctx, logutil.NewMemoryLogger(), &tabletmanagerdatapb.RestartMySQLRequest{})
+	assert.ErrorContains(t, err, "type PRIMARY cannot restart MySQL")
+	assert.Empty(t, stateChanges())
+	err = tm.RestartMySQL(ctx, logutil.NewMemoryLogger(), &tabletmanagerdatapb.RestartMySQLRequest{AllowPrimary: true})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, stateChanges())
+}
@@ -173,6 +173,7 @@ func (tm *TabletManager) FullStatus(ctx context.Context) (*replicationdatapb.Ful
 		SemiSyncWaitForReplicaCount: semiSyncNumReplicas,
 		SuperReadOnly:               superReadOnly,
 		ReplicationConfiguration:    replConfiguration,
+		SnapshotRestore:             tm.snapshotRestoreStatus(),
 	}, nil
 }
 
@@ -910,6 +911,10 @@ func (tm *TabletManager) PromoteReplica(ctx context.Context, semiSync bool) (str
 	}
 	defer tm.unlock()
 
+	if err := tm.checkSemiSyncPromotion(ctx, semiSync); err != nil {
+		return "", err
+	}
+
 	pos, err := tm.MysqlDaemon.Promote(ctx, tm.hookExtraEnv())
 	if err != nil {
 		return "", err
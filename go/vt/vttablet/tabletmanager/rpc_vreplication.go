@@ -269,6 +269,7 @@ func (tm *TabletManager) ReadVReplicationWorkflows(ctx context.Context, req *tab
 		}
 		stream.TimeThrottled = &vttime.Time{Seconds: timeThrottled}
 		stream.ComponentThrottled = row["component_throttled"].ToString()
+		stream.Metrics = tm.VREngine.StreamMetrics(stream.Id)
 		workflows[workflow].Streams = append(workflows[workflow].Streams, stream)
 	}
 	resp.Workflows = maps.Values(workflows)
@@ -375,6 +376,7 @@ func (tm *TabletManager) ReadVReplicationWorkflow(ctx context.Context, req *tabl
 		}
 		streams[i].TimeThrottled = &vttime.Time{Seconds: timeThrottled}
 		streams[i].ComponentThrottled = row["component_throttled"].ToString()
+		streams[i].Metrics = tm.VREngine.StreamMetrics(streams[i].Id)
 	}
 	resp.Streams = streams
 
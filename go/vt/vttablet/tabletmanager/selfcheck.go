@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sidecardb"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// SelfCheck runs the self checks of the tablet and of its tablet server, and
+// returns their results along with the worst of their statuses. A check which
+// cannot be run is reported as yellow, so that SelfCheck never fails.
+func (tm *TabletManager) SelfCheck(ctx context.Context) *tabletmanagerdatapb.SelfCheckResponse {
+	tabletType := tm.Tablet().Type
+	checks := tm.QueryServiceControl.SelfCheck(ctx)
+	checks = append(checks,
+		tm.checkReadOnly(ctx, tabletType),
+		tm.checkReplication(ctx, tabletType),
+		tm.checkSidecarSchema(),
+		tm.checkThrottler(ctx),
+	)
+
+	resp := &tabletmanagerdatapb.SelfCheckResponse{
+		Status: tabletmanagerdatapb.SelfCheckResult_GREEN,
+		Checks: checks,
+	}
+	for _, check := range checks {
+		resp.Status = max(resp.Status, check.Status)
+	}
+	return resp
+}
+
+// checkReadOnly reports a read_only state of MySQL which does not match the
+// tablet type: a primary must be writable, and other tablets should not be.
+func (tm *TabletManager) checkReadOnly(ctx context.Context, tabletType topodatapb.TabletType) *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{Name: "read_only"}
+	readOnly, err := tm.MysqlDaemon.IsReadOnly(ctx)
+	switch {
+	case err != nil:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+		result.Message = fmt.Sprintf("cannot read the read_only state of MySQL: %v", err)
+	case tabletType == topodatapb.TabletType_PRIMARY && readOnly:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+		result.Message = "MySQL is read-only on a PRIMARY tablet, writes fail"
+	case tabletType != topodatapb.TabletType_PRIMARY && !readOnly:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+		result.Message = fmt.Sprintf("MySQL is writable on a %v tablet, writes to it would create errant GTIDs", tabletType)
+	default:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_GREEN
+		result.Message = fmt.Sprintf("MySQL read_only is %v on a %v tablet", readOnly, tabletType)
+	}
+	return result
+}
+
+// checkReplication reports the errors of replication, and replication not
+// running on a replica type tablet.
+func (tm *TabletManager) checkReplication(ctx context.Context, tabletType topodatapb.TabletType) *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{
+		Name:   "replication",
+		Status: tabletmanagerdatapb.SelfCheckResult_GREEN,
+	}
+	if tabletType == topodatapb.TabletType_PRIMARY {
+		result.Message = "PRIMARY tablet, not replicating"
+		return result
+	}
+
+	// Stopped replication is expected on the tablets which are not serving
+	// reads, e.g. while taking a backup, so it is only a warning for them.
+	stoppedStatus := tabletmanagerdatapb.SelfCheckResult_YELLOW
+	if topo.IsReplicaType(tabletType) {
+		stoppedStatus = tabletmanagerdatapb.SelfCheckResult_RED
+	}
+
+	status, err := tm.MysqlDaemon.ReplicationStatus(ctx)
+	if errors.Is(err, mysql.ErrNotReplica) {
+		result.Status = stoppedStatus
+		result.Message = "replication is not configured"
+		return result
+	}
+	if err != nil {
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+		result.Message = fmt.Sprintf("cannot read the replication status: %v", err)
+		return result
+	}
+
+	var problems []string
+	if status.LastIOError != "" {
+		result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+		problems = append(problems, "IO thread error: "+status.LastIOError)
+	}
+	if status.LastSQLError != "" {
+		result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+		problems = append(problems, "SQL thread error: "+status.LastSQLError)
+	}
+	if !status.Healthy() {
+		result.Status = max(result.Status, stoppedStatus)
+		problems = append(problems, fmt.Sprintf("IO thread %v, SQL thread %v", status.IOState, status.SQLState))
+	}
+	if len(problems) == 0 {
+		result.Message = fmt.Sprintf("replicating from %s:%d", status.SourceHost, status.SourcePort)
+		return result
+	}
+	result.Message = strings.Join(problems, "; ")
+	return result
+}
+
+// checkSidecarSchema reports the sidecar tables which could not be created or
+// upgraded to their current schema when the tablet started.
+func (tm *TabletManager) checkSidecarSchema() *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{
+		Name:    "sidecar_schema",
+		Status:  tabletmanagerdatapb.SelfCheckResult_GREEN,
+		Message: "all the sidecar tables are up to date",
+	}
+	ddlErrors := sidecardb.GetDDLErrors()
+	if len(ddlErrors) == 0 {
+		return result
+	}
+
+	tables := make([]string, 0, len(ddlErrors))
+	for table := range ddlErrors {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	problems := make([]string, 0, len(tables))
+	for _, table := range tables {
+		problems = append(problems, fmt.Sprintf("%s: %s", table, ddlErrors[table]))
+	}
+	result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+	result.Message = "the schema of some sidecar tables could not be upgraded: " + strings.Join(problems, "; ")
+	return result
+}
+
+// checkThrottler reports the tablet throttler throttling its own metrics.
+// Throttling is expected under load, so it is only a warning.
+func (tm *TabletManager) checkThrottler(ctx context.Context) *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{Name: "throttler"}
+	flags := &throttle.CheckFlags{
+		SkipRequestHeartbeats: true,
+	}
+	checkResult := tm.QueryServiceControl.CheckThrottler(ctx, throttlerapp.VitessName.String(), flags)
+	switch {
+	case checkResult == nil:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+		result.Message = "the throttler returned no check result"
+	case checkResult.StatusCode != http.StatusOK:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+		result.Message = fmt.Sprintf("throttling, metric value %v over threshold %v: %s", checkResult.Value, checkResult.Threshold, checkResult.Message)
+	default:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_GREEN
+		result.Message = "not throttling"
+	}
+	return result
+}
+
+// RegisterSelfCheckHandler registers the /debug/selfcheck endpoint, which
+// returns the result of SelfCheck as JSON.
+func (tm *TabletManager) RegisterSelfCheckHandler() {
+	servenv.HTTPHandleFunc("/debug/selfcheck", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), topo.RemoteOperationTimeout)
+		defer cancel()
+		data, err := protojson.MarshalOptions{
+			Multiline:       true,
+			Indent:          "  ",
+			EmitUnpopulated: true,
+		}.Marshal(tm.SelfCheck(ctx))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("cannot marshal the self checks: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestSelfCheck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	fakeMysql := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	qsc := tm.QueryServiceControl.(*tabletservermock.Controller)
+
+	green := tabletmanagerdatapb.SelfCheckResult_GREEN
+	yellow := tabletmanagerdatapb.SelfCheckResult_YELLOW
+	red := tabletmanagerdatapb.SelfCheckResult_RED
+
+	testcases := []struct {
+		name       string
+		setup      func()
+		wantStatus tabletmanagerdatapb.SelfCheckResult_Status
+		wantChecks map[string]tabletmanagerdatapb.SelfCheckResult_Status
+	}{{
+		name:       "healthy",
+		setup:      func() {},
+		wantStatus: green,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"pools":          green,
+			"read_only":      green,
+			"replication":    green,
+			"sidecar_schema": green,
+			"throttler":      green,
+		},
+	}, {
+		name: "writable replica",
+		setup: func() {
+			fakeMysql.ReadOnly = false
+		},
+		wantStatus: yellow,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"read_only": yellow,
+		},
+	}, {
+		name: "replication stopped",
+		setup: func() {
+			fakeMysql.Replicating = false
+		},
+		wantStatus: red,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"replication": red,
+		},
+	}, {
+		name: "replication not configured",
+		setup: func() {
+			fakeMysql.ReplicationStatusError = mysql.ErrNotReplica
+		},
+		wantStatus: red,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"replication": red,
+		},
+	}, {
+		name: "throttling",
+		setup: func() {
+			qsc.CheckThrottlerResult = throttle.NewCheckResult(http.StatusTooManyRequests, 10, 5, nil)
+		},
+		wantStatus: yellow,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"throttler": yellow,
+		},
+	}, {
+		name: "pool exhausted",
+		setup: func() {
+			qsc.SelfCheckResults[0].Status = red
+		},
+		wantStatus: red,
+		wantChecks: map[string]tabletmanagerdatapb.SelfCheckResult_Status{
+			"pools":       red,
+			"replication": green,
+		},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fakeMysql.ReadOnly = true
+			fakeMysql.Replicating = true
+			fakeMysql.IOThreadRunning = true
+			fakeMysql.ReplicationStatusError = nil
+			qsc.CheckThrottlerResult = throttle.NewCheckResult(http.StatusOK, 1, 5, nil)
+			qsc.SelfCheckResults = []*tabletmanagerdatapb.SelfCheckResult{{
+				Name:   "pools",
+				Status: green,
+			}}
+			tcase.setup()
+
+			resp := tm.SelfCheck(ctx)
+			assert.Equal(t, tcase.wantStatus, resp.Status)
+			checks := make(map[string]tabletmanagerdatapb.SelfCheckResult_Status, len(resp.Checks))
+			for _, check := range resp.Checks {
+				checks[check.Name] = check.Status
+			}
+			for name, want := range tcase.wantChecks {
+				assert.Equal(t, want, checks[name], "check %s", name)
+			}
+		})
+	}
+}
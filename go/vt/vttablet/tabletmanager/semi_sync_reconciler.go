@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	// semiSyncReconcileInterval is the interval at which the semi-sync
+	// settings of MySQL are reconciled with the durability policy of the
+	// keyspace. 0 disables the reconciliation.
+	semiSyncReconcileInterval time.Duration
+	// semiSyncEnforceDurabilityPolicy makes PromoteReplica refuse the
+	// promotions which would violate the durability policy of the keyspace.
+	semiSyncEnforceDurabilityPolicy bool
+
+	semiSyncReconciliations = stats.NewCounter("SemiSyncReconciliations", "Number of times the semi-sync settings of MySQL were changed to match the durability policy of the keyspace")
+	semiSyncDivergent       = stats.NewGauge("SemiSyncDivergent", "Whether the semi-sync settings of MySQL diverge from the durability policy of the keyspace, see --semi-sync-reconcile-interval")
+)
+
+func registerSemiSyncFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&semiSyncReconcileInterval, "semi-sync-reconcile-interval", semiSyncReconcileInterval, "Interval at which the semi-sync settings of MySQL are reconciled with the durability policy of the keyspace. Divergences which cannot be fixed are reported in the health stream. 0 disables the reconciliation.")
+	fs.BoolVar(&semiSyncEnforceDurabilityPolicy, "semi-sync-enforce-durability-policy", semiSyncEnforceDurabilityPolicy, "Refuse to promote the tablet when its semi-sync settings, or the number of tablets of the shard which can send semi-sync acks, would violate the durability policy of the keyspace.")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerSemiSyncFlags)
+	servenv.OnParseFor("vttablet", registerSemiSyncFlags)
+}
+
+// semiSyncPolicy is the semi-sync setup the durability policy of the keyspace
+// requires for a tablet.
+type semiSyncPolicy struct {
+	durability string
+	// source and replica are whether the source and replica sides of
+	// semi-sync must be enabled.
+	source, replica bool
+	// ackers is the number of semi-sync acks the tablet waits for as a
+	// primary.
+	ackers int
+}
+
+// semiSyncPolicyFor returns the semi-sync setup required for the tablet, or
+// nil if there is none because the shard has no primary.
+func (tm *TabletManager) semiSyncPolicyFor(ctx context.Context, tablet *topodatapb.Tablet) (*semiSyncPolicy, error) {
+	durabilityName, err := tm.TopoServer.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read keyspace durability policy %v", tablet.Keyspace)
+	}
+	durability, err := reparentutil.GetDurabilityPolicy(durabilityName)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot get durability policy %v", durabilityName)
+	}
+
+	policy := &semiSyncPolicy{durability: durabilityName}
+	if tablet.Type == topodatapb.TabletType_PRIMARY {
+		// The replica side is always enabled along with the source side, see fixSemiSync.
+		policy.ackers = reparentutil.SemiSyncAckers(durability, tablet)
+		policy.source = policy.ackers > 0
+		policy.replica = policy.source
+		return policy, nil
+	}
+
+	si, err := tm.TopoServer.GetShard(ctx, tablet.Keyspace, tablet.Shard)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "cannot read shard")
+	}
+	if si.PrimaryAlias == nil || topoproto.TabletAliasEqual(si.PrimaryAlias, tablet.Alias) {
+		return nil, nil
+	}
+	primary, err := tm.TopoServer.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read primary tablet %v", si.PrimaryAlias)
+	}
+	policy.replica = reparentutil.IsReplicaSemiSync(durability, primary.Tablet, tablet)
+	return policy, nil
+}
+
+// semiSyncReconcileLoop periodically reconciles the semi-sync settings of
+// MySQL with the durability policy, and reports the divergences which could
+// not be fixed in the health stream.
+func (tm *TabletManager) semiSyncReconcileLoop(ctx context.Context, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(semiSyncReconcileInterval)
+	defer ticker.Stop()
+	var lastDivergence string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		divergence, err := tm.reconcileSemiSync(ctx)
+		if err != nil {
+			log.Warningf("Cannot reconcile the semi-sync settings with the durability policy: %v", err)
+			continue
+		}
+		if divergence == lastDivergence {
+			continue
+		}
+		if divergence != "" {
+			log.Warningf("The semi-sync settings diverge from the durability policy: %s", divergence)
+			semiSyncDivergent.Set(1)
+		} else {
+			log.Infof("The semi-sync settings match the durability policy")
+			semiSyncDivergent.Set(0)
+		}
+		lastDivergence = divergence
+		tm.QueryServiceControl.SetSemiSyncDivergence(divergence)
+		tm.QueryServiceControl.BroadcastHealth()
+	}
+}
+
+// reconcileSemiSync changes the semi-sync settings of MySQL to match the
+// durability policy of the keyspace, and returns a description of the
+// divergences which could not be fixed, or an empty string.
+func (tm *TabletManager) reconcileSemiSync(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	tablet := tm.Tablet()
+	switch tablet.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+	default:
+		// The tablets of the other types do not take part in the
+		// replication of the shard, or are being changed by an RPC.
+		return "", nil
+	}
+	policy, err := tm.semiSyncPolicyFor(ctx, tablet)
+	if err != nil || policy == nil {
+		return "", err
+	}
+
+	semiSyncType, err := tm.MysqlDaemon.SemiSyncExtensionLoaded(ctx)
+	if err != nil {
+		return "", err
+	}
+	if semiSyncType != mysql.SemiSyncTypeSource && semiSyncType != mysql.SemiSyncTypeMaster {
+		if policy.source || policy.replica {
+			return fmt.Sprintf("the semi-sync plugin is not loaded, but durability policy %s requires semi-sync", policy.durability), nil
+		}
+		return "", nil
+	}
+
+	var divergences []string
+	source, replica := tm.MysqlDaemon.SemiSyncEnabled(ctx)
+	if source != policy.source || replica != policy.replica {
+		log.Infof("Semi-sync is enabled on the source side: %t, replica side: %t, durability policy %s requires %t, %t", source, replica, policy.durability, policy.source, policy.replica)
+		if err := tm.fixSemiSyncForPolicy(ctx, tablet.Type, policy); err != nil {
+			divergences = append(divergences, fmt.Sprintf("semi-sync is enabled on the source side: %t, replica side: %t, durability policy %s requires %t, %t: %v", source, replica, policy.durability, policy.source, policy.replica, err))
+		}
+	}
+
+	if policy.ackers > 0 {
+		// The number of acks MySQL waits for is not managed by Vitess, and
+		// the writes block when fewer replicas can send them.
+		if _, numReplicas := tm.MysqlDaemon.SemiSyncSettings(ctx); numReplicas != uint32(policy.ackers) {
+			divergences = append(divergences, fmt.Sprintf("the primary waits for %d semi-sync acks, durability policy %s requires %d", numReplicas, policy.durability, policy.ackers))
+		}
+		if clients := tm.MysqlDaemon.SemiSyncClients(ctx); clients < uint32(policy.ackers) {
+			divergences = append(divergences, fmt.Sprintf("%d semi-sync replicas are connected, durability policy %s requires %d", clients, policy.durability, policy.ackers))
+		}
+	}
+	return strings.Join(divergences, "; "), nil
+}
+
+// fixSemiSyncForPolicy changes the semi-sync settings of MySQL to the ones of
+// the policy, unless an RPC is running or changed the type of the tablet.
+func (tm *TabletManager) fixSemiSyncForPolicy(ctx context.Context, tabletType topodatapb.TabletType, policy *semiSyncPolicy) error {
+	if !tm.actionSema.TryAcquire(1) {
+		// The RPC sets semi-sync itself if needed, the next reconciliation
+		// checks the result.
+		return nil
+	}
+	defer tm.unlock()
+	if tm.Tablet().Type != tabletType {
+		return nil
+	}
+
+	action := SemiSyncActionUnset
+	if policy.replica {
+		action = SemiSyncActionSet
+	}
+	var err error
+	if tabletType == topodatapb.TabletType_PRIMARY {
+		err = tm.fixSemiSync(ctx, tabletType, action)
+	} else {
+		err = tm.fixSemiSyncAndReplication(ctx, tabletType, action)
+	}
+	if err != nil {
+		return err
+	}
+	semiSyncReconciliations.Add(1)
+	return nil
+}
+
+// checkSemiSyncPromotion returns an error if promoting the tablet with the
+// given semi-sync setting would violate the durability policy of the
+// keyspace, when --semi-sync-enforce-durability-policy is set.
+func (tm *TabletManager) checkSemiSyncPromotion(ctx context.Context, semiSync bool) error {
+	if !semiSyncEnforceDurabilityPolicy {
+		return nil
+	}
+	primary := tm.Tablet()
+	primary.Type = topodatapb.TabletType_PRIMARY
+	policy, err := tm.semiSyncPolicyFor(ctx, primary)
+	if err != nil {
+		return err
+	}
+	if semiSync != policy.source {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot promote %v with semi-sync %t: durability policy %s requires %t", topoproto.TabletAliasString(primary.Alias), semiSync, policy.durability, policy.source)
+	}
+	if policy.ackers == 0 {
+		return nil
+	}
+
+	durability, err := reparentutil.GetDurabilityPolicy(policy.durability)
+	if err != nil {
+		return err
+	}
+	tablets, err := tm.TopoServer.GetTabletMapForShard(ctx, primary.Keyspace, primary.Shard)
+	if err != nil {
+		return vterrors.Wrap(err, "cannot read the tablets of the shard")
+	}
+	ackers := 0
+	for _, ti := range tablets {
+		if topoproto.TabletAliasEqual(ti.Alias, primary.Alias) {
+			continue
+		}
+		if reparentutil.IsReplicaSemiSync(durability, primary, ti.Tablet) {
+			ackers++
+		}
+	}
+	if ackers < policy.ackers {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot promote %v: %d tablets can send semi-sync acks, durability policy %s requires %d", topoproto.TabletAliasString(primary.Alias), ackers, policy.durability, policy.ackers)
+	}
+	return nil
+}
+
+func (tm *TabletManager) startSemiSyncReconcile() {
+	if semiSyncReconcileInterval <= 0 {
+		return
+	}
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	tm._semiSyncReconcileDone = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	tm._semiSyncReconcileCancel = cancel
+	go tm.semiSyncReconcileLoop(ctx, tm._semiSyncReconcileDone)
+}
+
+func (tm *TabletManager) stopSemiSyncReconcile() {
+	tm.mutex.Lock()
+	if tm._semiSyncReconcileCancel != nil {
+		tm._semiSyncReconcileCancel()
+	}
+	doneChan := tm._semiSyncReconcileDone
+	tm.mutex.Unlock()
+
+	// If the reconciliation loop was running, wait for it to fully stop.
+	if doneChan != nil {
+		<-doneChan
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// newSemiSyncTestShard creates a keyspace with the durability policy, and a
+// shard whose primary is the tablet primaryUID.
+func newSemiSyncTestShard(t *testing.T, ctx context.Context, ts *topo.Server, keyspace, durability string, primaryUID int) {
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{DurabilityPolicy: durability}))
+	primary := newTestTablet(t, primaryUID, keyspace, "0")
+	primary.Type = topodatapb.TabletType_PRIMARY
+	require.NoError(t, ts.CreateTablet(ctx, primary))
+	require.NoError(t, ts.CreateShard(ctx, keyspace, "0"))
+	_, err := ts.UpdateShardFields(ctx, keyspace, "0", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primary.Alias
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestReconcileSemiSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	newSemiSyncTestShard(t, ctx, ts, "ks", "semi_sync", 100)
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	fakeMysql := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+
+	// The replica side of semi-sync is enabled on the replica.
+	fakeMysql.SemiSyncReplicaEnabled = false
+	reconciliations := semiSyncReconciliations.Get()
+	divergence, err := tm.reconcileSemiSync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, divergence)
+	assert.True(t, fakeMysql.SemiSyncReplicaEnabled)
+	assert.False(t, fakeMysql.SemiSyncPrimaryEnabled)
+	assert.EqualValues(t, reconciliations+1, semiSyncReconciliations.Get())
+
+	// Nothing changes once the settings match.
+	divergence, err = tm.reconcileSemiSync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, divergence)
+	assert.EqualValues(t, reconciliations+1, semiSyncReconciliations.Get())
+
+	// The reconciliation is skipped while an RPC runs.
+	fakeMysql.SemiSyncReplicaEnabled = false
+	require.NoError(t, tm.lock(ctx))
+	divergence, err = tm.reconcileSemiSync(ctx)
+	tm.unlock()
+	require.NoError(t, err)
+	assert.Empty(t, divergence)
+	assert.False(t, fakeMysql.SemiSyncReplicaEnabled)
+}
+
+func TestSemiSyncPolicyForPrimary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	newSemiSyncTestShard(t, ctx, ts, "ks", "semi_sync", 100)
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	fakeMysql := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	policy, err := tm.semiSyncPolicyFor(ctx, &topodatapb.Tablet{Keyspace: "ks", Shard: "0", Type: topodatapb.TabletType_PRIMARY})
+	require.NoError(t, err)
+	assert.Equal(t, &semiSyncPolicy{durability: "semi_sync", source: true, replica: true, ackers: 1}, policy)
+
+	require.NoError(t, tm.fixSemiSyncForPolicy(ctx, topodatapb.TabletType_REPLICA, policy))
+	assert.False(t, fakeMysql.SemiSyncPrimaryEnabled, "the source side is only enabled on primaries")
+	assert.True(t, fakeMysql.SemiSyncReplicaEnabled)
+}
+
+func TestCheckSemiSyncPromotion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	newSemiSyncTestShard(t, ctx, ts, "ks", "semi_sync", 100)
+	newSemiSyncTestShard(t, ctx, ts, "ks2", "cross_cell", 200)
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+	tm2 := newTestTM(t, ts, 2, "ks2", "0")
+	defer tm2.Stop()
+
+	// The promotions are only checked with --semi-sync-enforce-durability-policy.
+	require.NoError(t, tm.checkSemiSyncPromotion(ctx, false))
+
+	oldEnforce := semiSyncEnforceDurabilityPolicy
+	semiSyncEnforceDurabilityPolicy = true
+	defer func() {
+		semiSyncEnforceDurabilityPolicy = oldEnforce
+	}()
+
+	require.NoError(t, tm.checkSemiSyncPromotion(ctx, true))
+	assert.ErrorContains(t, tm.checkSemiSyncPromotion(ctx, false), "cannot promote cell1-0000000001 with semi-sync false: durability policy semi_sync requires true")
+
+	// No tablet of the shard is in another cell to send the acks.
+	assert.ErrorContains(t, tm2.checkSemiSyncPromotion(ctx, true), "cannot promote cell1-0000000002: 0 tablets can send semi-sync acks, durability policy cross_cell requires 1")
+}
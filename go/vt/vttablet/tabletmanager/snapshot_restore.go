@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"strings"
+	"text/template"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// catchupPollInterval is the interval at which the position of a tablet of a
+// snapshot keyspace is checked while it catches up to the snapshot time.
+const catchupPollInterval = 300 * time.Millisecond
+
+// snapshotBinlogSource returns the connection parameters of the source of the
+// binary logs a tablet of a snapshot keyspace catches up from. The source is,
+// in order of precedence, the one set with --binlog_host, the binlog source of
+// the keyspace, or the primary of the shard in the base keyspace.
+func (tm *TabletManager) snapshotBinlogSource(ctx context.Context, keyspaceInfo *topo.KeyspaceInfo, shard string) (*mysql.ConnParams, error) {
+	params := &mysql.ConnParams{
+		Uname:      binlogUser,
+		Pass:       binlogPwd,
+		SslCa:      binlogSslCa,
+		SslCert:    binlogSslCert,
+		SslKey:     binlogSslKey,
+		ServerName: binlogSslServerName,
+	}
+
+	switch {
+	case binlogHost != "":
+		params.Host, params.Port = binlogHost, binlogPort
+	case keyspaceInfo.SnapshotBinlogSource.GetHost() != "":
+		host, err := expandBinlogSourceHost(keyspaceInfo.SnapshotBinlogSource.Host, keyspaceInfo.BaseKeyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		params.Host, params.Port = host, int(keyspaceInfo.SnapshotBinlogSource.Port)
+	default:
+		si, err := tm.TopoServer.GetShard(ctx, keyspaceInfo.BaseKeyspace, shard)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot locate the binlog source in base keyspace %s", keyspaceInfo.BaseKeyspace)
+		}
+		if !si.HasPrimary() {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot locate the binlog source: shard %s/%s has no primary", keyspaceInfo.BaseKeyspace, shard)
+		}
+		ti, err := tm.TopoServer.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot locate the binlog source: primary %s of shard %s/%s", topoproto.TabletAliasString(si.PrimaryAlias), keyspaceInfo.BaseKeyspace, shard)
+		}
+		params.Host, params.Port = ti.MysqlHostname, int(ti.MysqlPort)
+	}
+	if params.Host == "" || params.Port <= 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid binlog source %s:%d", params.Host, params.Port)
+	}
+
+	if params.Uname == "" && tm.DBConfigs != nil {
+		// Read the binary logs as the replication user of the tablet.
+		repl, err := tm.DBConfigs.ReplConnector().MysqlParams()
+		if err != nil {
+			return nil, err
+		}
+		params.Uname, params.Pass = repl.Uname, repl.Pass
+	}
+	if params.Uname == "" {
+		return nil, vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "no user to connect to the binlog source, see --binlog_user")
+	}
+	if binlogSslCa != "" || binlogSslCert != "" {
+		params.EnableSSL()
+	}
+	return params, nil
+}
+
+// expandBinlogSourceHost replaces the {{.Keyspace}} and {{.Shard}} placeholders
+// of the host of a binlog source.
+func expandBinlogSourceHost(host, keyspace, shard string) (string, error) {
+	tmpl, err := template.New("binlog_source").Option("missingkey=error").Parse(host)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "invalid binlog source host %q", host)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, struct{ Keyspace, Shard string }{keyspace, shard}); err != nil {
+		return "", vterrors.Wrapf(err, "invalid binlog source host %q", host)
+	}
+	return b.String(), nil
+}
+
+// setSnapshotRestoreStatus sets the progress of the restore of the tablet of a
+// snapshot keyspace.
+func (tm *TabletManager) setSnapshotRestoreStatus(status *replicationdatapb.SnapshotRestoreStatus) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	tm._snapshotRestore = status
+}
+
+// updateSnapshotRestoreStatus updates the progress of the restore of the
+// tablet of a snapshot keyspace.
+func (tm *TabletManager) updateSnapshotRestoreStatus(update func(status *replicationdatapb.SnapshotRestoreStatus)) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	if tm._snapshotRestore == nil {
+		tm._snapshotRestore = &replicationdatapb.SnapshotRestoreStatus{}
+	}
+	update(tm._snapshotRestore)
+}
+
+// snapshotRestoreStatus returns a copy of the progress of the restore of the
+// tablet of a snapshot keyspace, or nil if the tablet did not restore one.
+func (tm *TabletManager) snapshotRestoreStatus() *replicationdatapb.SnapshotRestoreStatus {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	return tm._snapshotRestore.CloneVT()
+}
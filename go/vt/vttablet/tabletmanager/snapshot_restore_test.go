@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestSnapshotBinlogSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+
+	primary := newTestTablet(t, 100, "base", "-80")
+	primary.Type = topodatapb.TabletType_PRIMARY
+	primary.MysqlHostname = "base-primary"
+	primary.MysqlPort = 3306
+	require.NoError(t, ts.CreateTablet(ctx, primary))
+	_, err := ts.GetOrCreateShard(ctx, "base", "-80")
+	require.NoError(t, err)
+	_, err = ts.UpdateShardFields(ctx, "base", "-80", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primary.Alias
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = ts.GetOrCreateShard(ctx, "base", "80-")
+	require.NoError(t, err)
+
+	tm := &TabletManager{
+		TopoServer: ts,
+		DBConfigs:  dbconfigs.NewTestDBConfigs(mysql.ConnParams{Uname: "vt_repl", Pass: "secret"}, mysql.ConnParams{}, ""),
+	}
+	keyspace := &topo.KeyspaceInfo{Keyspace: &topodatapb.Keyspace{
+		KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+		BaseKeyspace: "base",
+	}}
+
+	// The primary of the shard in the base keyspace is the default source.
+	params, err := tm.snapshotBinlogSource(ctx, keyspace, "-80")
+	require.NoError(t, err)
+	assert.Equal(t, "base-primary", params.Host)
+	assert.Equal(t, 3306, params.Port)
+	assert.Equal(t, "vt_repl", params.Uname)
+	assert.Equal(t, "secret", params.Pass)
+
+	_, err = tm.snapshotBinlogSource(ctx, keyspace, "80-")
+	assert.ErrorContains(t, err, "shard base/80- has no primary")
+
+	// The binlog source of the keyspace takes precedence.
+	keyspace.SnapshotBinlogSource = &topodatapb.SnapshotBinlogSource{
+		Host: "binlog-{{.Keyspace}}-{{.Shard}}",
+		Port: 4000,
+	}
+	params, err = tm.snapshotBinlogSource(ctx, keyspace, "80-")
+	require.NoError(t, err)
+	assert.Equal(t, "binlog-base-80-", params.Host)
+	assert.Equal(t, 4000, params.Port)
+
+	keyspace.SnapshotBinlogSource.Port = 0
+	_, err = tm.snapshotBinlogSource(ctx, keyspace, "80-")
+	assert.ErrorContains(t, err, "invalid binlog source binlog-base-80-:0")
+
+	// And so does the source set with the flags.
+	oldHost, oldPort, oldUser := binlogHost, binlogPort, binlogUser
+	defer func() {
+		binlogHost, binlogPort, binlogUser = oldHost, oldPort, oldUser
+	}()
+	binlogHost, binlogPort, binlogUser = "binlog-server", 5000, "binlog_user"
+	params, err = tm.snapshotBinlogSource(ctx, keyspace, "80-")
+	require.NoError(t, err)
+	assert.Equal(t, "binlog-server", params.Host)
+	assert.Equal(t, 5000, params.Port)
+	assert.Equal(t, "binlog_user", params.Uname)
+}
+
+func TestExpandBinlogSourceHost(t *testing.T) {
+	host, err := expandBinlogSourceHost("{{.Keyspace}}-{{.Shard}}.binlog", "ks", "-80")
+	require.NoError(t, err)
+	assert.Equal(t, "ks--80.binlog", host)
+
+	host, err = expandBinlogSourceHost("binlog-server", "ks", "-80")
+	require.NoError(t, err)
+	assert.Equal(t, "binlog-server", host)
+
+	_, err = expandBinlogSourceHost("{{.Cell}}", "ks", "-80")
+	assert.Error(t, err)
+
+	_, err = expandBinlogSourceHost("{{.Shard", "ks", "-80")
+	assert.Error(t, err)
+}
+
+func TestSnapshotRestoreStatus(t *testing.T) {
+	tm := &TabletManager{}
+	assert.Nil(t, tm.snapshotRestoreStatus())
+
+	tm.updateSnapshotRestoreStatus(func(status *replicationdatapb.SnapshotRestoreStatus) {
+		status.Phase = replicationdatapb.SnapshotRestoreStatus_CATCHING_UP
+	})
+	status := tm.snapshotRestoreStatus()
+	assert.Equal(t, replicationdatapb.SnapshotRestoreStatus_CATCHING_UP, status.Phase)
+
+	// The returned status is a copy.
+	status.Phase = replicationdatapb.SnapshotRestoreStatus_FAILED
+	assert.Equal(t, replicationdatapb.SnapshotRestoreStatus_CATCHING_UP, tm.snapshotRestoreStatus().Phase)
+}
@@ -62,6 +62,7 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
@@ -195,6 +196,23 @@ type TabletManager struct {
 	// _shardSyncCancel is the function to stop the background shard sync goroutine.
 	_shardSyncCancel context.CancelFunc
 
+	// _semiSyncReconcileDone is a channel for waiting until the semi-sync
+	// reconciliation goroutine has really finished after
+	// _semiSyncReconcileCancel was called.
+	_semiSyncReconcileDone chan struct{}
+
+	// _semiSyncReconcileCancel is the function to stop the background
+	// semi-sync reconciliation goroutine.
+	_semiSyncReconcileCancel context.CancelFunc
+
+	// _bootstrapDone is a channel for waiting until the bootstrap goroutine
+	// has really finished after _bootstrapCancel was called.
+	_bootstrapDone chan struct{}
+
+	// _bootstrapCancel is the function to stop the background bootstrap
+	// goroutine.
+	_bootstrapCancel context.CancelFunc
+
 	// _rebuildKeyspaceDone is a channel for waiting until the current keyspace
 	// has been rebuilt
 	_rebuildKeyspaceDone chan struct{}
@@ -208,6 +226,9 @@ type TabletManager struct {
 	_lockTablesTimer      *time.Timer
 	// _isBackupRunning tells us whether there is a backup that is currently running
 	_isBackupRunning bool
+	// _snapshotRestore is the progress of the restore of the tablet of a
+	// snapshot keyspace, reported by FullStatus.
+	_snapshotRestore *replicationdatapb.SnapshotRestoreStatus
 }
 
 // BuildTabletFromInput builds a tablet record from input parameters.
@@ -343,12 +364,30 @@ func mergeTags(a, b map[string]string) map[string]string {
 	return result
 }
 
+// applyCellTabletTags adds the default tablet tags configured on the CellInfo
+// of the tablet's cell. Tags already set on the tablet take precedence.
+func (tm *TabletManager) applyCellTabletTags(ctx context.Context, tablet *topodatapb.Tablet) {
+	ci, err := tm.TopoServer.GetCellInfo(ctx, tablet.Alias.Cell, false /* strongRead */)
+	if err != nil {
+		log.Warningf("Unable to read CellInfo for cell %v, not applying its default tablet tags: %v", tablet.Alias.Cell, err)
+		return
+	}
+	if len(ci.TabletTags) == 0 {
+		return
+	}
+	tablet.Tags = mergeTags(ci.TabletTags, tablet.Tags)
+}
+
 // Start starts the TabletManager.
 func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.TabletConfig) error {
 	defer func() {
 		log.Infof("TabletManager Start took ~%d ms", time.Since(servenv.GetInitStartTime()).Milliseconds())
 	}()
 	log.Infof("TabletManager Start")
+	ctx, cancel := context.WithTimeout(tm.BatchCtx, initTimeout)
+	defer cancel()
+
+	tm.applyCellTabletTags(ctx, tablet)
 	tm.DBConfigs.DBName = topoproto.TabletDbName(tablet)
 	tm.tabletAlias = tablet.Alias
 	tm.tmState = newTMState(tm, tablet)
@@ -357,15 +396,19 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.Tabl
 
 	tm.baseTabletType = tablet.Type
 
-	ctx, cancel := context.WithTimeout(tm.BatchCtx, initTimeout)
-	defer cancel()
 	si, err := tm.createKeyspaceShard(ctx)
 	if err != nil {
 		return err
 	}
+	if err := tm.bootstrapKeyspace(ctx); err != nil {
+		return err
+	}
 	if err := tm.checkPrimaryShip(ctx, si); err != nil {
 		return err
 	}
+	if err := tm.checkQuarantine(ctx); err != nil {
+		return err
+	}
 	if err := tm.checkMysql(ctx); err != nil {
 		return err
 	}
@@ -402,6 +445,7 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.Tabl
 	// The following initializations don't need to be done
 	// in any specific order.
 	tm.startShardSync()
+	tm.startSemiSyncReconcile()
 	tm.exportStats()
 	servenv.OnRun(tm.registerTabletManager)
 
@@ -428,6 +472,7 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.Tabl
 		return err
 	}
 	tm.tmState.Open()
+	tm.startBootstrap()
 	return nil
 }
 
@@ -439,6 +484,8 @@ func (tm *TabletManager) Close() {
 	// rather than registering it as an OnTerm hook so the shard sync loop keeps
 	// running during lame duck.
 	tm.stopShardSync()
+	tm.stopSemiSyncReconcile()
+	tm.stopBootstrap()
 	tm.stopRebuildKeyspace()
 
 	// cleanup initialized fields in the tablet entry
@@ -470,6 +517,8 @@ func (tm *TabletManager) Stop() {
 	// Stop the shard sync loop and wait for it to exit. This needs to be done
 	// here in addition to in Close() because tests do not call Close().
 	tm.stopShardSync()
+	tm.stopSemiSyncReconcile()
+	tm.stopBootstrap()
 	tm.stopRebuildKeyspace()
 
 	if tm.QueryServiceControl != nil {
@@ -751,6 +800,36 @@ func (tm *TabletManager) findMysqlPort(retryInterval time.Duration) {
 	}
 }
 
+// checkQuarantine keeps the tablet DRAINED if EmergencyReparentShard
+// quarantined it for its errant GTIDs before it restarted, until the
+// quarantine is cleared.
+func (tm *TabletManager) checkQuarantine(ctx context.Context) error {
+	if tm.Tablet().Type == topodatapb.TabletType_PRIMARY {
+		return nil
+	}
+	oldTablet, err := tm.TopoServer.GetTablet(ctx, tm.tabletAlias)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil
+	case err != nil:
+		return vterrors.Wrap(err, "checkQuarantine failed to read existing tablet record")
+	}
+	if !topo.IsQuarantined(oldTablet.Tablet) {
+		return nil
+	}
+
+	errantGTIDs := oldTablet.Tags[topo.ErrantGTIDsTag]
+	log.Warningf("Tablet is quarantined for its errant GTIDs %v, staying DRAINED until the quarantine is cleared", errantGTIDs)
+	tm.tmState.UpdateTablet(func(tablet *topodatapb.Tablet) {
+		tablet.Type = topodatapb.TabletType_DRAINED
+		if tablet.Tags == nil {
+			tablet.Tags = map[string]string{}
+		}
+		tablet.Tags[topo.ErrantGTIDsTag] = errantGTIDs
+	})
+	return nil
+}
+
 func (tm *TabletManager) initTablet(ctx context.Context) error {
 	tablet := tm.Tablet()
 	err := tm.TopoServer.CreateTablet(ctx, tablet)
@@ -801,7 +880,7 @@ func (tm *TabletManager) handleRestore(ctx context.Context, config *tabletenv.Ta
 	}
 
 	// Restore in the background
-	if restoreFromBackup {
+	if restoreFromBackup || tm.bootstrapRestore() {
 		go func() {
 			// Zero date will cause us to use the latest, which is the default
 			backupTime := time.Time{}
@@ -836,6 +915,7 @@ func (tm *TabletManager) handleRestore(ctx context.Context, config *tabletenv.Ta
 
 			// Open the state manager after restore is done.
 			tm.tmState.Open()
+			tm.startBootstrap()
 		}()
 		return true, nil
 	}
@@ -420,6 +420,68 @@ func TestStartCheckMysql(t *testing.T) {
 	assert.Equal(t, "foo", ti.MysqlHostname)
 }
 
+func TestStartAppliesCellTabletTags(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "cell1"
+	ts := memorytopo.NewServer(ctx, cell)
+	err := ts.UpdateCellInfoFields(ctx, cell, func(ci *topodatapb.CellInfo) error {
+		ci.TabletTags = map[string]string{"role": "oltp", "team": "storage"}
+		return nil
+	})
+	require.NoError(t, err)
+
+	tablet := newTestTablet(t, 1, "ks", "0")
+	tablet.Tags = map[string]string{"role": "reporting"}
+	tm := &TabletManager{
+		BatchCtx:            context.Background(),
+		TopoServer:          ts,
+		MysqlDaemon:         newTestMysqlDaemon(t, 1),
+		DBConfigs:           &dbconfigs.DBConfigs{},
+		QueryServiceControl: tabletservermock.NewController(),
+	}
+	err = tm.Start(tablet, nil)
+	require.NoError(t, err)
+	defer tm.Stop()
+
+	ti, err := ts.GetTablet(ctx, tm.tabletAlias)
+	require.NoError(t, err)
+	// Tags set on the tablet take precedence over the cell defaults.
+	assert.Equal(t, map[string]string{"role": "reporting", "team": "storage"}, ti.Tags)
+}
+
+func TestStartKeepsQuarantine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "cell1"
+	ts := memorytopo.NewServer(ctx, cell)
+
+	// The tablet was quarantined before it restarted.
+	tablet := newTestTablet(t, 1, "ks", "0")
+	quarantined := tablet.CloneVT()
+	quarantined.Type = topodatapb.TabletType_DRAINED
+	quarantined.Tags = map[string]string{topo.ErrantGTIDsTag: "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1"}
+	_, err := ts.GetOrCreateShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	require.NoError(t, ts.CreateTablet(ctx, quarantined))
+
+	tm := &TabletManager{
+		BatchCtx:            context.Background(),
+		TopoServer:          ts,
+		MysqlDaemon:         newTestMysqlDaemon(t, 1),
+		DBConfigs:           &dbconfigs.DBConfigs{},
+		QueryServiceControl: tabletservermock.NewController(),
+	}
+	err = tm.Start(tablet, nil)
+	require.NoError(t, err)
+	defer tm.Stop()
+
+	ti, err := ts.GetTablet(ctx, tm.tabletAlias)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_DRAINED, ti.Type)
+	assert.Equal(t, "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1", ti.Tags[topo.ErrantGTIDsTag])
+}
+
 // TestStartFindMysqlPort tests the functionality of findMySQLPort on tablet startup
 func TestStartFindMysqlPort(t *testing.T) {
 	defer func(saved time.Duration) { mysqlPortRetryInterval = saved }(mysqlPortRetryInterval)
@@ -37,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -76,8 +77,13 @@ type tmState struct {
 	isShardServing  map[topodatapb.TabletType]bool
 	tabletControls  map[topodatapb.TabletType]bool
 	deniedTables    map[topodatapb.TabletType][]string
+	writeFreeze     *topodatapb.Shard_WriteFreeze
+	isWriteFrozen   bool
 	tablet          *topodatapb.Tablet
 	isPublishing    bool
+	// isRestartingMySQL keeps the query service drained while
+	// RestartMySQL restarts MySQL.
+	isRestartingMySQL bool
 
 	// displayState contains the current snapshot of the internal state
 	// and has its own mutex.
@@ -145,6 +151,7 @@ func (ts *tmState) RefreshFromTopoInfo(ctx context.Context, shardInfo *topo.Shar
 
 	if shardInfo != nil {
 		ts.isResharding = len(shardInfo.SourceShards) > 0
+		ts.writeFreeze = shardInfo.WriteFreeze
 
 		ts.deniedTables = make(map[topodatapb.TabletType][]string)
 		for _, tc := range shardInfo.TabletControls {
@@ -247,6 +254,16 @@ func (ts *tmState) SetMysqlPort(mport int32) {
 	ts.publishStateLocked(ts.ctx)
 }
 
+// SetRestartingMySQL drains the query service before MySQL restarts, and
+// lets it serve again once MySQL is back.
+func (ts *tmState) SetRestartingMySQL(ctx context.Context, restarting bool) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.isRestartingMySQL = restarting
+	return ts.updateLocked(ctx)
+}
+
 // UpdateTablet must be called during initialization only.
 func (ts *tmState) UpdateTablet(update func(tablet *topodatapb.Tablet)) {
 	ts.mu.Lock()
@@ -292,6 +309,13 @@ func (ts *tmState) updateLocked(ctx context.Context) error {
 		returnErr = vterrors.Wrapf(err, errStr)
 	}
 
+	if err := ts.applyWriteFreeze(ctx); err != nil {
+		errStr := fmt.Sprintf("Cannot apply the write freeze: %v", err)
+		log.Errorf(errStr)
+		// No need to short circuit. Apply all steps and return error in the end.
+		returnErr = vterrors.Wrapf(err, errStr)
+	}
+
 	if ts.tm.UpdateStream != nil {
 		if topo.IsRunningUpdateStream(ts.tablet.Type) {
 			ts.tm.UpdateStream.Enable()
@@ -346,6 +370,9 @@ func (ts *tmState) canServe(tabletType topodatapb.TabletType) string {
 	if tabletType == topodatapb.TabletType_PRIMARY && ts.isResharding {
 		return "primary tablet with filtered replication on"
 	}
+	if ts.isRestartingMySQL {
+		return "MySQL is restarting"
+	}
 	return ""
 }
 
@@ -369,6 +396,20 @@ func (ts *tmState) applyDenyList(ctx context.Context) (err error) {
 			denyListRules.Add(qr)
 		}
 	}
+	if ts.writeFreeze != nil && ts.tablet.Type == topodatapb.TabletType_PRIMARY {
+		// The vtgates buffer the queries failing with QRFailRetry, as they
+		// do during a MoveTables switch of the writes.
+		action := rules.QRFail
+		if ts.writeFreeze.Buffer {
+			action = rules.QRFailRetry
+		}
+		log.Infof("Denying writes: %v", ts.writeFreeze.Reason)
+		qr := rules.NewQueryRule(fmt.Sprintf("shard writes are frozen: %v", ts.writeFreeze.Reason), "write_freeze", action)
+		for _, plan := range writeFreezePlans {
+			qr.AddPlanCond(plan)
+		}
+		denyListRules.Add(qr)
+	}
 
 	loadRuleErr := ts.tm.QueryServiceControl.SetQueryRules(denyListQueryList, denyListRules)
 	if loadRuleErr != nil {
@@ -377,6 +418,51 @@ func (ts *tmState) applyDenyList(ctx context.Context) (err error) {
 	return nil
 }
 
+// writeFreezePlans are the plans denied on the primary while the writes to
+// the shard are frozen.
+var writeFreezePlans = []planbuilder.PlanType{
+	planbuilder.PlanInsert,
+	planbuilder.PlanInsertMessage,
+	planbuilder.PlanUpdate,
+	planbuilder.PlanUpdateLimit,
+	planbuilder.PlanDelete,
+	planbuilder.PlanDeleteLimit,
+	planbuilder.PlanDDL,
+	planbuilder.PlanLoad,
+}
+
+// applyWriteFreeze makes the MySQL of the primary super_read_only while the
+// writes to the shard are frozen, and writable again once they are unfrozen.
+// isWriteFrozen records whether the freeze is applied, which is reported in
+// the health stream so that the vtgates retry the writes they buffered as
+// soon as the freeze is lifted.
+// A primary which got demoted in the meantime is left alone, since the
+// demotion already made it read-only.
+func (ts *tmState) applyWriteFreeze(ctx context.Context) error {
+	isPrimary := ts.tablet.Type == topodatapb.TabletType_PRIMARY
+	frozen := ts.writeFreeze != nil && isPrimary
+	if frozen == ts.isWriteFrozen {
+		return nil
+	}
+	if frozen {
+		log.Infof("Writes to the shard are frozen, setting super_read_only")
+		if _, err := ts.tm.MysqlDaemon.SetSuperReadOnly(ctx, true); err != nil {
+			return err
+		}
+	} else if isPrimary {
+		log.Infof("Writes to the shard are unfrozen, unsetting super_read_only")
+		if _, err := ts.tm.MysqlDaemon.SetSuperReadOnly(ctx, false); err != nil {
+			return err
+		}
+		if err := ts.tm.MysqlDaemon.SetReadOnly(ctx, false); err != nil {
+			return err
+		}
+	}
+	ts.isWriteFrozen = frozen
+	ts.tm.QueryServiceControl.SetWritesFrozen(frozen)
+	return nil
+}
+
 func (ts *tmState) publishStateLocked(ctx context.Context) {
 	log.Infof("Publishing state: %v", ts.tablet)
 	// If retry is in progress, there's nothing to do.
@@ -391,8 +477,10 @@ func (ts *tmState) publishStateLocked(ctx context.Context) {
 			log.Error(err)
 			return topo.NewError(topo.NoUpdateNeeded, "")
 		}
+		errantGTIDs := tablet.Tags[topo.ErrantGTIDsTag]
 		proto.Reset(tablet)
 		proto.Merge(tablet, ts.tablet)
+		keepQuarantine(tablet, errantGTIDs)
 		return nil
 	})
 	if err != nil {
@@ -423,8 +511,10 @@ func (ts *tmState) retryPublish() {
 				log.Error(err)
 				return topo.NewError(topo.NoUpdateNeeded, "")
 			}
+			errantGTIDs := tablet.Tags[topo.ErrantGTIDsTag]
 			proto.Reset(tablet)
 			proto.Merge(tablet, ts.tablet)
+			keepQuarantine(tablet, errantGTIDs)
 			return nil
 		})
 		cancel()
@@ -445,6 +535,23 @@ func (ts *tmState) retryPublish() {
 	}
 }
 
+// keepQuarantine keeps the errant GTIDs tag EmergencyReparentShard added to
+// the tablet record when it quarantined the tablet, in the record the tablet
+// publishes. Changing the type of the tablet lifts the quarantine.
+func keepQuarantine(tablet *topodatapb.Tablet, errantGTIDs string) {
+	if tablet.Type != topodatapb.TabletType_DRAINED {
+		delete(tablet.Tags, topo.ErrantGTIDsTag)
+		return
+	}
+	if errantGTIDs == "" {
+		return
+	}
+	if tablet.Tags == nil {
+		tablet.Tags = map[string]string{}
+	}
+	tablet.Tags[topo.ErrantGTIDsTag] = errantGTIDs
+}
+
 // displayState is the externalized version of tmState
 // that can be used for observability. The internal version
 // of tmState may not be accessible due to longer mutex holds.
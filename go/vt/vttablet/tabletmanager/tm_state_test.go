@@ -136,6 +136,55 @@ func TestStateDenyList(t *testing.T) {
 	assert.Equal(t, `[{"Description":"enforce denied tables","Name":"denied_table","TableNames":["t1"],"Action":"FAIL_RETRY"}]`, string(b))
 }
 
+func TestStateWriteFreeze(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+
+	fmd := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	qsc := tm.QueryServiceControl.(*tabletservermock.Controller)
+	si := &topo.ShardInfo{
+		Shard: &topodatapb.Shard{
+			WriteFreeze: &topodatapb.Shard_WriteFreeze{
+				Reason: "maintenance",
+				Buffer: true,
+			},
+		},
+	}
+
+	// Only the primary enforces the freeze.
+	tm.tmState.RefreshFromTopoInfo(ctx, si, nil)
+	assert.False(t, fmd.SuperReadOnly.Load())
+	assert.False(t, qsc.WritesFrozen())
+	b, _ := json.Marshal(qsc.GetQueryRules(denyListQueryList))
+	assert.Equal(t, `[]`, string(b))
+
+	tm.tmState.mu.Lock()
+	tm.tmState.tablet.Type = topodatapb.TabletType_PRIMARY
+	tm.tmState.mu.Unlock()
+	tm.tmState.RefreshFromTopoInfo(ctx, si, nil)
+	assert.True(t, fmd.SuperReadOnly.Load())
+	assert.True(t, qsc.WritesFrozen())
+	b, _ = json.Marshal(qsc.GetQueryRules(denyListQueryList))
+	assert.Equal(t, `[{"Description":"shard writes are frozen: maintenance","Name":"write_freeze","Plans":["Insert","InsertMessage","Update","UpdateLimit","Delete","DeleteLimit","DDL","Load"],"Action":"FAIL_RETRY"}]`, string(b))
+
+	// Without buffering, the writes fail right away.
+	si.WriteFreeze.Buffer = false
+	tm.tmState.RefreshFromTopoInfo(ctx, si, nil)
+	b, _ = json.Marshal(qsc.GetQueryRules(denyListQueryList))
+	assert.Contains(t, string(b), `"Action":"FAIL"`)
+
+	si.WriteFreeze = nil
+	tm.tmState.RefreshFromTopoInfo(ctx, si, nil)
+	assert.False(t, fmd.SuperReadOnly.Load())
+	assert.False(t, fmd.ReadOnly)
+	assert.False(t, qsc.WritesFrozen())
+	b, _ = json.Marshal(qsc.GetQueryRules(denyListQueryList))
+	assert.Equal(t, `[]`, string(b))
+}
+
 func TestStateTabletControls(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -391,6 +440,44 @@ func TestStateChangeTabletType(t *testing.T) {
 	assert.Equal(t, int64(2), statsTabletTypeCount.Counts()["replica"])
 }
 
+func TestStateKeepsQuarantine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	tm := newTestTM(t, ts, 2, "ks", "0")
+	defer tm.Stop()
+
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell1",
+		Uid:  2,
+	}
+	quarantine := func() {
+		err := tm.tmState.ChangeTabletType(ctx, topodatapb.TabletType_DRAINED, DBActionNone)
+		require.NoError(t, err)
+		_, err = ts.UpdateTabletFields(ctx, alias, func(tablet *topodatapb.Tablet) error {
+			tablet.Tags = map[string]string{topo.ErrantGTIDsTag: "aaaaaaaa-71ca-11e1-9e33-c80aa9429562:1"}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	quarantine()
+
+	// The tablet publishing its state keeps the quarantine.
+	tm.tmState.SetMysqlPort(3306)
+	ti, err := ts.GetTablet(ctx, alias)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3306, ti.MysqlPort)
+	assert.True(t, topo.IsQuarantined(ti.Tablet))
+
+	// Changing its type lifts it.
+	err = tm.tmState.ChangeTabletType(ctx, topodatapb.TabletType_REPLICA, DBActionNone)
+	require.NoError(t, err)
+	ti, err = ts.GetTablet(ctx, alias)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, ti.Type)
+	assert.NotContains(t, ti.Tags, topo.ErrantGTIDsTag)
+}
+
 /*
 	This test verifies, even if SetServingType returns error we should still publish
 
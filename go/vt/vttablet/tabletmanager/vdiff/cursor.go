@@ -23,6 +23,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vttablet"
 )
 
 // contextVCursor satisfies VCursor, but only implements Context().
@@ -36,6 +37,18 @@ func (vc *contextVCursor) ConnCollation() collations.ID {
 	return collations.CollationBinaryID
 }
 
+// MaxMemoryRows is used by MemorySort to report the limit of rows to sort in
+// memory.
+func (vc *contextVCursor) MaxMemoryRows() int {
+	return vttablet.VDiffMaxMemorySortRows
+}
+
+// ExceedsMaxMemoryRows is used by MemorySort to bound the number of rows it
+// sorts in memory.
+func (vc *contextVCursor) ExceedsMaxMemoryRows(numRows int) bool {
+	return numRows > vttablet.VDiffMaxMemorySortRows
+}
+
 func (vc *contextVCursor) ExecutePrimitive(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	return primitive.TryExecute(ctx, vc, bindVars, wantfields)
 }
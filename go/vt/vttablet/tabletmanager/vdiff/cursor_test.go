@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vttablet"
+)
+
+func TestMemorySortMaxRows(t *testing.T) {
+	oldMaxRows := vttablet.VDiffMaxMemorySortRows
+	defer func() {
+		vttablet.VDiffMaxMemorySortRows = oldMaxRows
+	}()
+	vttablet.VDiffMaxMemorySortRows = 2
+
+	ctx := context.Background()
+	collationEnv := collations.MySQL8()
+	comparePKs := []compareColInfo{{colIndex: 0, isPK: true}}
+	newSourceSorter := func() *primitiveExecutor {
+		source := &shardStreamer{shard: "0", result: make(chan *sqltypes.Result, 1)}
+		source.result <- sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1", "int64"), "3", "1", "2")
+		close(source.result)
+		mergeSorter := newMergeSorter(map[string]*shardStreamer{"0": source}, comparePKs, collationEnv)
+		return newPrimitiveExecutor(ctx, &engine.MemorySort{
+			OrderBy: mergeSorter.OrderBy,
+			Input:   mergeSorter,
+		}, "source")
+	}
+
+	_, err := newSourceSorter().next()
+	require.ErrorContains(t, err, "in-memory row count exceeded allowed limit of 2")
+
+	vttablet.VDiffMaxMemorySortRows = 3
+	row, err := newSourceSorter().next()
+	require.NoError(t, err)
+	require.Equal(t, "1", row[0].ToString())
+}
@@ -92,9 +92,9 @@ var (
 				Fields:            sqltypes.MakeTestFields("c1|c2", "int64|int64"),
 			}, {
 				Name:              "aggr",
-				Columns:           []string{"c1", "c2", "c3", "c4"},
+				Columns:           []string{"c1", "c2", "c3", "c4", "c5", "c6"},
 				PrimaryKeyColumns: []string{"c1"},
-				Fields:            sqltypes.MakeTestFields("c1|c2|c3|c4", "int64|int64|int64|int64"),
+				Fields:            sqltypes.MakeTestFields("c1|c2|c3|c4|c5|c6", "int64|int64|int64|int64|int64|int64"),
 			}, {
 				Name:              "datze",
 				Columns:           []string{"id", "dt"},
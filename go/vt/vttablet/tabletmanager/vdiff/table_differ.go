@@ -348,7 +348,7 @@ func (td *tableDiffer) startTargetDataStream(ctx context.Context) error {
 	ct := td.wd.ct
 	gtidch := make(chan string, 1)
 	ct.targetShardStreamer.result = make(chan *sqltypes.Result, 1)
-	go td.streamOneShard(ctx, ct.targetShardStreamer, td.tablePlan.targetQuery, td.lastPK, gtidch)
+	go td.streamOneShard(ctx, ct.targetShardStreamer, td.tablePlan.targetQuery, td.streamLastPK(), gtidch)
 	gtid, ok := <-gtidch
 	if !ok {
 		log.Infof("streaming error: %v", ct.targetShardStreamer.err)
@@ -363,7 +363,7 @@ func (td *tableDiffer) startSourceDataStreams(ctx context.Context) error {
 	if err := td.forEachSource(func(source *migrationSource) error {
 		gtidch := make(chan string, 1)
 		source.result = make(chan *sqltypes.Result, 1)
-		go td.streamOneShard(ctx, source.shardStreamer, td.tablePlan.sourceQuery, td.lastPK, gtidch)
+		go td.streamOneShard(ctx, source.shardStreamer, td.tablePlan.sourceQuery, td.streamLastPK(), gtidch)
 
 		gtid, ok := <-gtidch
 		if !ok {
@@ -396,6 +396,16 @@ func (td *tableDiffer) restartTargetVReplicationStreams(ctx context.Context) err
 	return err
 }
 
+// streamLastPK returns the last PK to stream the rows from. The rows of the
+// source are streamed in the order of the primary key of the target table,
+// unless they are sorted in memory in which case they are all streamed.
+func (td *tableDiffer) streamLastPK() *querypb.QueryResult {
+	if td.tablePlan.sortSource {
+		return nil
+	}
+	return td.lastPK
+}
+
 func (td *tableDiffer) streamOneShard(ctx context.Context, participant *shardStreamer, query string, lastPK *querypb.QueryResult, gtidch chan string) {
 	log.Infof("streamOneShard Start on %s using query: %s", participant.tablet.Alias.String(), query)
 	td.wgShardStreamers.Add(1)
@@ -467,13 +477,31 @@ func (td *tableDiffer) setupRowSorters() {
 	for shard, source := range td.wd.ct.sources {
 		sources[shard] = source.shardStreamer
 	}
-	td.sourcePrimitive = newMergeSorter(sources, td.tablePlan.comparePKs, td.wd.collationEnv)
+	sourceMergeSorter := newMergeSorter(sources, td.tablePlan.comparePKs, td.wd.collationEnv)
+	td.sourcePrimitive = sourceMergeSorter
 
 	// Create a merge sorter for the target.
 	targets := make(map[string]*shardStreamer)
 	targets[td.wd.ct.targetShardStreamer.shard] = td.wd.ct.targetShardStreamer
 	td.targetPrimitive = newMergeSorter(targets, td.tablePlan.comparePKs, td.wd.collationEnv)
 
+	// The rows of the lookup table of a vindex are not streamed from the source
+	// in the order of its primary key, so they are sorted in memory, up to
+	// --vdiff-max-memory-sort-rows rows. The rows of the owner table with the
+	// same lookup values are also deduplicated.
+	if td.tablePlan.sortSource {
+		td.sourcePrimitive = &engine.MemorySort{
+			OrderBy: sourceMergeSorter.OrderBy,
+			Input:   td.sourcePrimitive,
+		}
+		if len(td.tablePlan.aggregates) == 0 {
+			td.sourcePrimitive = &engine.OrderedAggregate{
+				GroupByKeys: pkColsToGroupByParams(td.tablePlan.pkCols, td.wd.collationEnv),
+				Input:       td.sourcePrimitive,
+			}
+		}
+	}
+
 	// If there were aggregate expressions, we have to re-aggregate
 	// the results, which engine.OrderedAggregate can do.
 	if len(td.tablePlan.aggregates) != 0 {
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -59,6 +60,12 @@ type tablePlan struct {
 	table      *tabletmanagerdatapb.TableDefinition
 	orderBy    sqlparser.OrderBy
 	aggregates []*engine.AggregateParams
+	// sortSource is set when the rows of the source are not streamed in the
+	// order of the primary key of the target table, as for the lookup table
+	// of a CreateLookupIndex workflow. The rows of the source are then sorted
+	// in memory, up to --vdiff-max-memory-sort-rows rows, and the table is
+	// always diffed from the start.
+	sortSource bool
 }
 
 func (td *tableDiffer) buildTablePlan(dbClient binlogplayer.DBClient, dbName string, collationEnv *collations.Environment) (*tablePlan, error) {
@@ -105,14 +112,22 @@ func (td *tableDiffer) buildTablePlan(dbClient binlogplayer.DBClient, dbName str
 
 			// Check if it's an aggregate expression
 			if expr, ok := selExpr.Expr.(sqlparser.AggrFunc); ok {
+				// this will only work as long as aggregates can be pushed down to tablets
+				// this won't work: "select count(*) from (select id from t limit 1)"
+				// since vreplication only handles simple tables (no joins/derived tables) this is fine for now
+				// but will need to be revisited when we add such support to vreplication
+				var aggrOpcode opcode.AggregateOpcode
 				switch fname := expr.AggrName(); fname {
 				case "count", "sum":
-					// this will only work as long as aggregates can be pushed down to tablets
-					// this won't work: "select count(*) from (select id from t limit 1)"
-					// since vreplication only handles simple tables (no joins/derived tables) this is fine for now
-					// but will need to be revisited when we add such support to vreplication
+					aggrOpcode = opcode.AggregateSum
+				case "min":
+					aggrOpcode = opcode.AggregateMin
+				case "max":
+					aggrOpcode = opcode.AggregateMax
+				}
+				if aggrOpcode != opcode.AggregateUnassigned {
 					aggregates = append(aggregates, engine.NewAggregateParam(
-						/*opcode*/ opcode.AggregateSum,
+						/*opcode*/ aggrOpcode,
 						/*offset*/ len(sourceSelect.SelectExprs)-1,
 						/*alias*/ "", collationEnv),
 					)
@@ -189,6 +204,7 @@ func (td *tableDiffer) buildTablePlan(dbClient binlogplayer.DBClient, dbName str
 	log.Infof("VDiff query on target: %v", tp.targetQuery)
 
 	tp.aggregates = aggregates
+	tp.sortSource = td.wd.ct.workflowType == binlogdatapb.VReplicationWorkflowType_CreateLookupIndex
 	td.tablePlan = tp
 	return tp, err
 }
@@ -475,15 +475,15 @@ func TestBuildPlanSuccess(t *testing.T) {
 		// Aggregations.
 		input: &binlogdatapb.Rule{
 			Match:  "aggr",
-			Filter: "select c1, c2, count(*) as c3, sum(c4) as c4 from t1 group by c1",
+			Filter: "select c1, c2, count(*) as c3, sum(c4) as c4, min(c5) as c5, max(c6) as c6 from t1 group by c1",
 		},
 		table: "aggr",
 		tablePlan: &tablePlan{
 			dbName:      vdiffDBName,
 			table:       testSchema.TableDefinitions[tableDefMap["aggr"]],
-			sourceQuery: "select c1, c2, count(*) as c3, sum(c4) as c4 from t1 group by c1 order by c1 asc",
-			targetQuery: "select c1, c2, c3, c4 from aggr order by c1 asc",
-			compareCols: []compareColInfo{{0, collations.MySQL8().LookupByName(sqltypes.NULL.String()), true, "c1"}, {1, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c2"}, {2, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c3"}, {3, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c4"}},
+			sourceQuery: "select c1, c2, count(*) as c3, sum(c4) as c4, min(c5) as c5, max(c6) as c6 from t1 group by c1 order by c1 asc",
+			targetQuery: "select c1, c2, c3, c4, c5, c6 from aggr order by c1 asc",
+			compareCols: []compareColInfo{{0, collations.MySQL8().LookupByName(sqltypes.NULL.String()), true, "c1"}, {1, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c2"}, {2, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c3"}, {3, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c4"}, {4, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c5"}, {5, collations.MySQL8().LookupByName(sqltypes.NULL.String()), false, "c6"}},
 			comparePKs:  []compareColInfo{{0, collations.MySQL8().LookupByName(sqltypes.NULL.String()), true, "c1"}},
 			pkCols:      []int{0},
 			selectPks:   []int{0},
@@ -494,6 +494,8 @@ func TestBuildPlanSuccess(t *testing.T) {
 			aggregates: []*engine.AggregateParams{
 				engine.NewAggregateParam(opcode.AggregateSum, 2, "", collations.MySQL8()),
 				engine.NewAggregateParam(opcode.AggregateSum, 3, "", collations.MySQL8()),
+				engine.NewAggregateParam(opcode.AggregateMin, 4, "", collations.MySQL8()),
+				engine.NewAggregateParam(opcode.AggregateMax, 5, "", collations.MySQL8()),
 			},
 		},
 	}, {
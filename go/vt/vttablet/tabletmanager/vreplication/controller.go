@@ -18,6 +18,7 @@ package vreplication
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ import (
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 const (
@@ -68,6 +70,7 @@ type controller struct {
 	sourceTablet atomic.Value
 
 	lastWorkflowError *vterrors.LastError
+	retries           streamRetries
 }
 
 // newController creates a new controller. Unless a stream is explicitly 'Stopped',
@@ -119,6 +122,11 @@ func newController(ctx context.Context, params map[string]string, dbClientFactor
 		if v := params["tablet_types"]; v != "" {
 			tabletTypesStr = v
 		}
+		if v, err := workflowTabletPickerPreferences(params); err != nil {
+			return nil, err
+		} else if v != "" {
+			tpo.Preferences = v
+		}
 		log.Infof("creating tablet picker for source keyspace/shard %v/%v with cell: %v and tabletTypes: %v", ct.source.Keyspace, ct.source.Shard, cell, tabletTypesStr)
 		cells := strings.Split(cell, ",")
 
@@ -143,6 +151,25 @@ func newController(ctx context.Context, params map[string]string, dbClientFactor
 	return ct, nil
 }
 
+// workflowTabletPickerPreferences returns the tablet picker preferences set
+// in the options of a workflow, if any. Otherwise the workflows which do not
+// set their own tablet types use the --vreplication-tablet-picker-preferences.
+func workflowTabletPickerPreferences(params map[string]string) (string, error) {
+	if options := params["options"]; options != "" {
+		var workflowOptions vtctldatapb.WorkflowOptions
+		if err := json.Unmarshal([]byte(options), &workflowOptions); err != nil {
+			return "", vterrors.Wrapf(err, "failed to parse the options of the workflow: %s", options)
+		}
+		if workflowOptions.TabletPickerPreferences != "" {
+			return workflowOptions.TabletPickerPreferences, nil
+		}
+	}
+	if params["tablet_types"] != "" {
+		return "", nil
+	}
+	return tabletPickerPreferences, nil
+}
+
 func (ct *controller) run(ctx context.Context) {
 	defer func() {
 		log.Infof("stream %v: stopped", ct.id)
@@ -150,6 +177,7 @@ func (ct *controller) run(ctx context.Context) {
 	}()
 
 	for {
+		ct.retries.start(time.Now())
 		err := ct.runBlp(ctx)
 		if err == nil {
 			return
@@ -164,8 +192,10 @@ func (ct *controller) run(ctx context.Context) {
 		}
 
 		ct.blpStats.ErrorCounts.Add([]string{"Stream Error"}, 1)
-		binlogplayer.LogError(fmt.Sprintf("error in stream %v, will retry after %v", ct.id, retryDelay), err)
-		timer := time.NewTimer(retryDelay)
+		class := classifyError(err)
+		delay := ct.retries.fail(class, time.Now())
+		binlogplayer.LogError(fmt.Sprintf("%s error in stream %v, will retry after %v", class, ct.id, delay), err)
+		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
 			log.Warningf("context canceled: %s", err.Error())
@@ -257,16 +287,23 @@ func (ct *controller) runBlp(ctx context.Context) (err error) {
 		err = vr.Replicate(ctx)
 		ct.lastWorkflowError.Record(err)
 
-		// If this is a MySQL error that we know needs manual intervention or
-		// it's a FAILED_PRECONDITION vterror, OR we cannot identify this as
-		// non-recoverable BUT it has persisted beyond the retry limit
+		if err == nil {
+			return nil
+		}
+
+		// If the error class has exhausted its maximum attempts, e.g. a MySQL
+		// error that we know needs manual intervention or a FAILED_PRECONDITION
+		// vterror, OR it has persisted beyond the retry limit
 		// (maxTimeToRetryError). In addition, we cannot restart a workflow
 		// started with AtomicCopy which has _any_ error.
-		if (err != nil && vr.WorkflowSubType == int32(binlogdatapb.VReplicationWorkflowSubType_AtomicCopy)) ||
-			isUnrecoverableError(err) ||
+		class := classifyError(err)
+		now := time.Now()
+		if vr.WorkflowSubType == int32(binlogdatapb.VReplicationWorkflowSubType_AtomicCopy) ||
+			ct.retries.exhausted(class, err, now) ||
 			!ct.lastWorkflowError.ShouldRetry() {
 
-			if errSetState := vr.setState(binlogdatapb.VReplicationWorkflowState_Error, err.Error()); errSetState != nil {
+			message := binlogplayer.TerminalErrorMessage(string(class), ct.retries.attempt(class, now), err)
+			if errSetState := vr.setState(binlogdatapb.VReplicationWorkflowState_Error, message); errSetState != nil {
 				log.Errorf("INTERNAL: unable to setState() in controller: %v. Could not set error text to: %v.", errSetState, err)
 				return err // yes, err and not errSetState.
 			}
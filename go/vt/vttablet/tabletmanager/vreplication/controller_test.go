@@ -23,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/discovery"
@@ -239,6 +241,37 @@ func TestControllerOverrides(t *testing.T) {
 	expectFBCRequest(t, wantTablet, testPos, nil, &topodatapb.KeyRange{End: []byte{0x80}})
 }
 
+func TestWorkflowTabletPickerPreferences(t *testing.T) {
+	oldPreferences := tabletPickerPreferences
+	defer func() {
+		tabletPickerPreferences = oldPreferences
+	}()
+	tabletPickerPreferences = "replica@local"
+
+	preferences, err := workflowTabletPickerPreferences(map[string]string{})
+	require.NoError(t, err)
+	require.Equal(t, "replica@local", preferences)
+
+	preferences, err = workflowTabletPickerPreferences(map[string]string{"options": `{"tenant_id":"1"}`})
+	require.NoError(t, err)
+	require.Equal(t, "replica@local", preferences)
+
+	// The tablet types of the workflow take precedence over the default preferences.
+	preferences, err = workflowTabletPickerPreferences(map[string]string{"tablet_types": "rdonly"})
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+
+	preferences, err = workflowTabletPickerPreferences(map[string]string{
+		"tablet_types": "rdonly",
+		"options":      `{"tablet_picker_preferences":"rdonly@local,replica@any"}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "rdonly@local,replica@any", preferences)
+
+	_, err = workflowTabletPickerPreferences(map[string]string{"options": "{"})
+	require.ErrorContains(t, err, "failed to parse the options of the workflow: {")
+}
+
 func TestControllerCanceledContext(t *testing.T) {
 	wantTablet := addTablet(100)
 	defer deleteTablet(wantTablet)
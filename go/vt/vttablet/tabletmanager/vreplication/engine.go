@@ -739,6 +739,18 @@ func (vre *Engine) transitionJournal(je *journalEvent) {
 	log.Infof("Completed transition for journal:workload %v", je)
 }
 
+// StreamMetrics returns the metrics of the stream, or nil if the stream
+// does not run on this tablet.
+func (vre *Engine) StreamMetrics(id int32) *binlogdatapb.VReplicationStreamMetrics {
+	vre.mu.Lock()
+	defer vre.mu.Unlock()
+	ct, ok := vre.controllers[id]
+	if !ok {
+		return nil
+	}
+	return streamMetrics(ct.blpStats)
+}
+
 // WaitForPos waits for the replication to reach the specified position.
 func (vre *Engine) WaitForPos(ctx context.Context, id int32, pos string) error {
 	start := time.Now()
@@ -27,8 +27,15 @@ import (
 var (
 	retryDelay          = 5 * time.Second
 	maxTimeToRetryError time.Duration // Default behavior is to keep retrying, for backward compatibility
+	retryMaxDelay       = 5 * time.Minute
+	// retryMaxAttemptsByClass are the maximum attempts of the error classes,
+	// see streamRetries.exhausted.
+	retryMaxAttemptsByClass = map[string]int{}
 
 	tabletTypesStr = "in_order:REPLICA,PRIMARY" // Default value
+	// tabletPickerPreferences is the default ordered list of tablet picker
+	// preferences of the workflows, see discovery.ParseTabletPickerPreferences.
+	tabletPickerPreferences string
 
 	relayLogMaxSize  = 250000
 	relayLogMaxItems = 5000
@@ -39,11 +46,16 @@ var (
 
 	vreplicationStoreCompressedGTID   = false
 	vreplicationParallelInsertWorkers = 1
+	vreplicationParallelApplyWorkers  = 1
 )
 
 func registerVReplicationFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&retryDelay, "vreplication_retry_delay", retryDelay, "delay before retrying a failed workflow event in the replication phase")
 	fs.DurationVar(&maxTimeToRetryError, "vreplication_max_time_to_retry_on_error", maxTimeToRetryError, "stop automatically retrying when we've had consecutive failures with the same error for this long after the first occurrence")
+	fs.DurationVar(&retryMaxDelay, "vreplication-retry-max-delay", retryMaxDelay, "maximum delay before retrying a failed workflow, the retry delay doubling on each consecutive failure")
+	fs.StringToIntVar(&retryMaxAttemptsByClass, "vreplication-retry-max-attempts", retryMaxAttemptsByClass, "maximum consecutive attempts of a workflow failing with the errors of a class before it goes into the error state, 0 to retry them until --vreplication_max_time_to_retry_on_error. The classes are network, mysql_gone, duplicate_key, schema_mismatch, unrecoverable and unknown, e.g. duplicate_key=3,schema_mismatch=5. The errors of the classes which are not set are retried unless they need a manual intervention")
+
+	fs.StringVar(&tabletPickerPreferences, "vreplication-tablet-picker-preferences", tabletPickerPreferences, "ordered list of <tablet_type>@<cells> preferences for the source tablets of the workflows which set neither their own preferences nor their own tablet types, where <cells> is local, alias, any or a cell name, e.g. rdonly@local,replica@local,replica@any. When set, it overrides the default tablet types, in_order:REPLICA,PRIMARY")
 
 	fs.IntVar(&relayLogMaxSize, "relay_log_max_size", relayLogMaxSize, "Maximum buffer size (in bytes) for VReplication target buffering. If single rows are larger than this, a single row is buffered at a time.")
 	fs.IntVar(&relayLogMaxItems, "relay_log_max_items", relayLogMaxItems, "Maximum number of rows for VReplication target buffering.")
@@ -59,6 +71,7 @@ func registerVReplicationFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&vreplicationStoreCompressedGTID, "vreplication_store_compressed_gtid", vreplicationStoreCompressedGTID, "Store compressed gtids in the pos column of the sidecar database's vreplication table")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationParallelApplyWorkers, "vreplication-parallel-apply-workers", vreplicationParallelApplyWorkers, "Number of connections used during the replication phase to apply in parallel the transactions which write disjoint rows, committing them in their source order. Set <= 1 to apply the transactions one at a time.")
 }
 
 func init() {
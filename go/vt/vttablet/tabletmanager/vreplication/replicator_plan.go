@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"vitess.io/vitess/go/bytes2"
 	"vitess.io/vitess/go/mysql/collations"
@@ -30,6 +31,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vttablet"
@@ -204,6 +206,16 @@ type TablePlan struct {
 	// PKReferences is used to check if an event changed
 	// a primary key column (row move).
 	PKReferences []string
+	// MinMaxReferences are the columns referenced by the MIN and MAX
+	// aggregations, if any. They can only be maintained incrementally from
+	// the inserts and the updates which do not remove their values, otherwise
+	// they are recomputed from the rows of the source, see streamSourceRows.
+	MinMaxReferences []string
+	// MinMaxCheck returns whether the before image of a row holds the value
+	// of one of the MIN and MAX aggregations of its group, and
+	// MinMaxRecompute sets them to their recomputed values.
+	MinMaxCheck     *sqlparser.ParsedQuery
+	MinMaxRecompute *sqlparser.ParsedQuery
 	// PKIndices is an array, length = #columns, true if column is part of the PK
 	PKIndices               []bool
 	Stats                   *binlogplayer.Stats
@@ -218,8 +230,19 @@ type TablePlan struct {
 	PartialInserts map[string]*sqlparser.ParsedQuery
 	// PartialUpdates are same as PartialInserts, but for update statements
 	PartialUpdates map[string]*sqlparser.ParsedQuery
+	// partialQueriesMu protects PartialInserts and PartialUpdates, which
+	// the vplayer can fill from several connections when it applies
+	// transactions in parallel. It is shared, like the maps, by the copies
+	// of the plan.
+	partialQueriesMu *sync.Mutex
 
 	CollationEnv *collations.Environment
+
+	// env and streamSourceRows are set by vplayer when the plan has MIN and
+	// MAX aggregations. streamSourceRows streams the rows of the source
+	// table selected by a query.
+	env              *vtenv.Environment
+	streamSourceRows func(query string, send func(*binlogdatapb.VStreamRowsResponse) error) error
 }
 
 // MarshalJSON performs a custom JSON Marshalling.
@@ -234,6 +257,7 @@ func (tp *TablePlan) MarshalJSON() ([]byte, error) {
 		Update       *sqlparser.ParsedQuery `json:",omitempty"`
 		Delete       *sqlparser.ParsedQuery `json:",omitempty"`
 		PKReferences []string               `json:",omitempty"`
+		MinMaxRefs   []string               `json:",omitempty"`
 	}{
 		TargetName:   tp.TargetName,
 		SendRule:     tp.SendRule.Match,
@@ -244,6 +268,7 @@ func (tp *TablePlan) MarshalJSON() ([]byte, error) {
 		Update:       tp.Update,
 		Delete:       tp.Delete,
 		PKReferences: tp.PKReferences,
+		MinMaxRefs:   tp.MinMaxReferences,
 	}
 	return json.Marshal(&v)
 }
@@ -398,32 +423,246 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 		if tp.Delete == nil {
 			return nil, nil
 		}
+		if len(tp.MinMaxReferences) > 0 && tp.isCopied(bindvars) {
+			return tp.applyMinMaxChange(rowChange, bindvars, executor, func() (*sqltypes.Result, error) {
+				return execParsedQuery(tp.Delete, bindvars, executor)
+			})
+		}
 		return execParsedQuery(tp.Delete, bindvars, executor)
 	case before && after:
-		if !tp.pkChanged(bindvars) && !tp.HasExtraSourcePkColumns {
-			if tp.isPartial(rowChange) {
-				upd, err := tp.getPartialUpdateQuery(rowChange.DataColumns)
-				if err != nil {
-					return nil, err
+		if len(tp.MinMaxReferences) > 0 && tp.isCopied(bindvars) && (tp.pkChanged(bindvars) || tp.minMaxChanged(bindvars)) {
+			return tp.applyMinMaxChange(rowChange, bindvars, executor, func() (*sqltypes.Result, error) {
+				return tp.applyUpdate(rowChange, bindvars, executor)
+			})
+		}
+		return tp.applyUpdate(rowChange, bindvars, executor)
+	}
+	// Unreachable.
+	return nil, nil
+}
+
+// applyUpdate applies the update of a row, which is applied as a delete and
+// an insert if the update changes the primary key.
+func (tp *TablePlan) applyUpdate(rowChange *binlogdatapb.RowChange, bindvars map[string]*querypb.BindVariable, executor func(string) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	if !tp.pkChanged(bindvars) && !tp.HasExtraSourcePkColumns {
+		if tp.isPartial(rowChange) {
+			upd, err := tp.getPartialUpdateQuery(rowChange.DataColumns)
+			if err != nil {
+				return nil, err
+			}
+			tp.Stats.PartialQueryCount.Add([]string{"update"}, 1)
+			return execParsedQuery(upd, bindvars, executor)
+		} else {
+			return execParsedQuery(tp.Update, bindvars, executor)
+		}
+	}
+	if tp.Delete != nil {
+		if _, err := execParsedQuery(tp.Delete, bindvars, executor); err != nil {
+			return nil, err
+		}
+	}
+	if tp.isOutsidePKRange(bindvars, true, true, "insert") {
+		return nil, nil
+	}
+	return execParsedQuery(tp.Insert, bindvars, executor)
+}
+
+// applyMinMaxChange applies the delete or the update of a row of a table with
+// MIN and MAX aggregations. If the row held the value of one of the
+// aggregations of its group, they are recomputed from the rows of the source.
+func (tp *TablePlan) applyMinMaxChange(rowChange *binlogdatapb.RowChange, bindvars map[string]*querypb.BindVariable, executor func(string) (*sqltypes.Result, error),
+	apply func() (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	qr, err := execParsedQuery(tp.MinMaxCheck, bindvars, executor)
+	if err != nil {
+		return nil, err
+	}
+	held := false
+	if len(qr.Rows) == 1 {
+		for _, val := range qr.Rows[0] {
+			if b, err := val.ToBool(); err == nil && b {
+				held = true
+			}
+		}
+	}
+	result, err := apply()
+	if err != nil || !held {
+		return result, err
+	}
+	if err := tp.recomputeMinMax(rowChange, bindvars, executor); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// recomputeMinMax recomputes the MIN and MAX aggregations of the group of the
+// before image of a row from the rows of the source table. The rows are read
+// as of the current position of the source, which may be ahead of the row
+// change: the following row changes are applied on top of the recomputed
+// values, which converge once the stream has caught up.
+func (tp *TablePlan) recomputeMinMax(rowChange *binlogdatapb.RowChange, bindvars map[string]*querypb.BindVariable, executor func(string) (*sqltypes.Result, error)) error {
+	if tp.streamSourceRows == nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot recompute the MIN and MAX aggregations of table %s, the rows of the source cannot be streamed", tp.TargetName)
+	}
+	collationID := tp.CollationEnv.DefaultConnectionCharset()
+	cfg := &evalengine.Config{
+		ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+			for i, field := range tp.Fields {
+				if col.Name.EqualString(field.Name) {
+					return i, nil
 				}
-				tp.Stats.PartialQueryCount.Add([]string{"update"}, 1)
-				return execParsedQuery(upd, bindvars, executor)
-			} else {
-				return execParsedQuery(tp.Update, bindvars, executor)
 			}
+			return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "column %s not found in the fields of table %s", sqlparser.String(col), tp.TargetName)
+		},
+		Collation:   collationID,
+		Environment: tp.env,
+	}
+	var groupBy, aggregations []evalengine.Expr
+	var isMax []bool
+	for _, cexpr := range tp.TablePlanBuilder.colExprs {
+		var exprs *[]evalengine.Expr
+		switch {
+		case cexpr.isGrouped:
+			exprs = &groupBy
+		case cexpr.operation == opMin, cexpr.operation == opMax:
+			exprs = &aggregations
+			isMax = append(isMax, cexpr.operation == opMax)
+		default:
+			continue
+		}
+		expr, err := evalengine.Translate(cexpr.expr, cfg)
+		if err != nil {
+			return err
 		}
-		if tp.Delete != nil {
-			if _, err := execParsedQuery(tp.Delete, bindvars, executor); err != nil {
+		*exprs = append(*exprs, expr)
+	}
+	env := evalengine.EmptyExpressionEnv(tp.env)
+	evaluate := func(row []sqltypes.Value, exprs []evalengine.Expr) ([]sqltypes.Value, error) {
+		env.Row = row
+		vals := make([]sqltypes.Value, len(exprs))
+		for i, expr := range exprs {
+			res, err := env.Evaluate(expr)
+			if err != nil {
 				return nil, err
 			}
+			vals[i] = res.Value(collationID)
 		}
-		if tp.isOutsidePKRange(bindvars, before, after, "insert") {
-			return nil, nil
+		return vals, nil
+	}
+	compare := func(v1, v2 sqltypes.Value) (int, error) {
+		return evalengine.NullsafeCompare(v1, v2, tp.CollationEnv, collationID, nil)
+	}
+
+	group, err := evaluate(sqltypes.MakeRowTrusted(tp.Fields, rowChange.Before), groupBy)
+	if err != nil {
+		return err
+	}
+	query, err := tp.sourceRowsQuery(group)
+	if err != nil {
+		return err
+	}
+	values := make([]sqltypes.Value, len(aggregations))
+	var fields []*querypb.Field
+	// The offsets of the fields of the plan in the streamed rows.
+	var offsets []int
+	err = tp.streamSourceRows(query, func(resp *binlogdatapb.VStreamRowsResponse) error {
+		if len(resp.Fields) > 0 {
+			fields = resp.Fields
+			offsets = make([]int, len(tp.Fields))
+			for i, field := range tp.Fields {
+				offsets[i] = -1
+				for j, f := range fields {
+					if strings.EqualFold(strings.Trim(f.Name, "`"), field.Name) {
+						offsets[i] = j
+					}
+				}
+			}
+		}
+		for _, prow := range resp.Rows {
+			streamed := sqltypes.MakeRowTrusted(fields, prow)
+			row := make([]sqltypes.Value, len(tp.Fields))
+			for i, offset := range offsets {
+				if offset >= 0 {
+					row[i] = streamed[offset]
+				}
+			}
+			rowGroup, err := evaluate(row, groupBy)
+			if err != nil {
+				return err
+			}
+			inGroup := true
+			for i := range group {
+				if cmp, err := compare(rowGroup[i], group[i]); err != nil {
+					return err
+				} else if cmp != 0 {
+					inGroup = false
+					break
+				}
+			}
+			if !inGroup {
+				continue
+			}
+			rowValues, err := evaluate(row, aggregations)
+			if err != nil {
+				return err
+			}
+			for i, val := range rowValues {
+				if val.IsNull() {
+					continue
+				}
+				if values[i].IsNull() {
+					values[i] = val
+					continue
+				}
+				cmp, err := compare(val, values[i])
+				if err != nil {
+					return err
+				}
+				if (isMax[i] && cmp > 0) || (!isMax[i] && cmp < 0) {
+					values[i] = val
+				}
+			}
 		}
-		return execParsedQuery(tp.Insert, bindvars, executor)
+		return nil
+	})
+	if err != nil {
+		return vterrors.Wrapf(err, "failed to recompute the MIN and MAX aggregations of table %s", tp.TargetName)
 	}
-	// Unreachable.
-	return nil, nil
+	for i, val := range values {
+		bindvars[fmt.Sprintf("minmax_%d", i)] = sqltypes.ValueBindVariable(val)
+	}
+	_, err = execParsedQuery(tp.MinMaxRecompute, bindvars, executor)
+	return err
+}
+
+// sourceRowsQuery returns the query selecting the rows of the source table to
+// recompute the MIN and MAX aggregations of a group. The rows are filtered on
+// the source by the grouping columns with non negative integral values, which
+// the source supports as filters. The other grouping expressions are evaluated
+// on the streamed rows.
+func (tp *TablePlan) sourceRowsQuery(group []sqltypes.Value) (string, error) {
+	stmt, err := tp.env.Parser().Parse(tp.SendRule.Filter)
+	if err != nil {
+		return "", err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return "", vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected query to stream the rows of table %s: %s", tp.TargetName, tp.SendRule.Filter)
+	}
+	i := 0
+	for _, cexpr := range tp.TablePlanBuilder.colExprs {
+		if !cexpr.isGrouped {
+			continue
+		}
+		if col, ok := cexpr.expr.(*sqlparser.ColName); ok && group[i].IsIntegral() && !strings.HasPrefix(group[i].ToString(), "-") {
+			sel.AddWhere(&sqlparser.ComparisonExpr{
+				Operator: sqlparser.EqualOp,
+				Left:     sqlparser.NewColName(col.Name.String()),
+				Right:    sqlparser.NewIntLiteral(group[i].ToString()),
+			})
+		}
+		i++
+	}
+	return sqlparser.String(sel), nil
 }
 
 // applyBulkDeleteChanges applies a bulk DELETE statement from the row changes
@@ -572,6 +811,38 @@ func (tp *TablePlan) pkChanged(bindvars map[string]*querypb.BindVariable) bool {
 	return false
 }
 
+// minMaxChanged returns whether an update changed a column referenced
+// by the MIN and MAX aggregations.
+func (tp *TablePlan) minMaxChanged(bindvars map[string]*querypb.BindVariable) bool {
+	for _, ref := range tp.MinMaxReferences {
+		v1, _ := sqltypes.BindVariableToValue(bindvars["b_"+ref])
+		v2, _ := sqltypes.BindVariableToValue(bindvars["a_"+ref])
+		if !valsEqual(v1, v2) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCopied returns whether the row of the before image was already copied to
+// the target table, i.e. it is not beyond the lastpk of a table being copied.
+func (tp *TablePlan) isCopied(bindvars map[string]*querypb.BindVariable) bool {
+	if tp.Lastpk == nil || len(tp.Lastpk.Rows) != 1 {
+		return true
+	}
+	for i, field := range tp.Lastpk.Fields {
+		val, _ := sqltypes.BindVariableToValue(bindvars["b_"+field.Name])
+		result, err := evalengine.NullsafeCompare(val, tp.Lastpk.Rows[0][i], tp.CollationEnv, collations.Unknown, nil)
+		if err != nil {
+			return true
+		}
+		if result != 0 {
+			return result < 0
+		}
+	}
+	return true
+}
+
 func valsEqual(v1, v2 sqltypes.Value) bool {
 	if v1.IsNull() && v2.IsNull() {
 		return true
@@ -28,8 +28,10 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
 type TestReplicatorPlan struct {
@@ -48,6 +50,7 @@ type TestTablePlan struct {
 	Update       string   `json:",omitempty"`
 	Delete       string   `json:",omitempty"`
 	PKReferences []string `json:",omitempty"`
+	MinMaxRefs   []string `json:",omitempty"`
 }
 
 func TestBuildPlayerPlan(t *testing.T) {
@@ -319,6 +322,60 @@ func TestBuildPlayerPlan(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		// aggregations
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select c1, count(*) as c2, count(c3) as c3, sum(c4 * c5) as c4, min(c6) as c6, max(c7) as c7 from t2 group by c1",
+			}},
+		},
+		plan: &TestReplicatorPlan{
+			VStreamFilter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{
+					Match:  "t2",
+					Filter: "select c1, c3, c4, c5, c6, c7 from t2",
+				}},
+			},
+			TargetTables: []string{"t1"},
+			TablePlans: map[string]*TestTablePlan{
+				"t2": {
+					TargetName:   "t1",
+					SendRule:     "t2",
+					PKReferences: []string{"c1"},
+					MinMaxRefs:   []string{"c6", "c7"},
+					InsertFront:  "insert into t1(c1,c2,c3,c4,c6,c7)",
+					InsertValues: "(:a_c1,1,ifnull(:a_c3 is not null, 0),ifnull(:a_c4 * :a_c5, 0),:a_c6,:a_c7)",
+					InsertOnDup:  " on duplicate key update c2=c2+1, c3=c3+ifnull(values(c3), 0), c4=c4+ifnull(values(c4), 0), c6=least(ifnull(c6, values(c6)), ifnull(values(c6), c6)), c7=greatest(ifnull(c7, values(c7)), ifnull(values(c7), c7))",
+					Insert:       "insert into t1(c1,c2,c3,c4,c6,c7) values (:a_c1,1,ifnull(:a_c3 is not null, 0),ifnull(:a_c4 * :a_c5, 0),:a_c6,:a_c7) on duplicate key update c2=c2+1, c3=c3+ifnull(values(c3), 0), c4=c4+ifnull(values(c4), 0), c6=least(ifnull(c6, values(c6)), ifnull(values(c6), c6)), c7=greatest(ifnull(c7, values(c7)), ifnull(values(c7), c7))",
+					Update:       "update t1 set c2=c2, c3=c3-ifnull(:b_c3 is not null, 0)+ifnull(:a_c3 is not null, 0), c4=c4-ifnull(:b_c4 * :b_c5, 0)+ifnull(:a_c4 * :a_c5, 0), c6=least(ifnull(c6, :a_c6), ifnull(:a_c6, c6)), c7=greatest(ifnull(c7, :a_c7), ifnull(:a_c7, c7)) where c1=:b_c1",
+					Delete:       "update t1 set c2=c2-1, c3=c3-ifnull(:b_c3 is not null, 0), c4=c4-ifnull(:b_c4 * :b_c5, 0), c6=c6, c7=c7 where c1=:b_c1",
+				},
+			},
+		},
+		planpk: &TestReplicatorPlan{
+			VStreamFilter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{
+					Match:  "t2",
+					Filter: "select c1, c3, c4, c5, c6, c7, pk1, pk2 from t2",
+				}},
+			},
+			TargetTables: []string{"t1"},
+			TablePlans: map[string]*TestTablePlan{
+				"t2": {
+					TargetName:   "t1",
+					SendRule:     "t2",
+					PKReferences: []string{"c1", "pk1", "pk2"},
+					MinMaxRefs:   []string{"c6", "c7"},
+					InsertFront:  "insert into t1(c1,c2,c3,c4,c6,c7)",
+					InsertValues: "(:a_c1,1,ifnull(:a_c3 is not null, 0),ifnull(:a_c4 * :a_c5, 0),:a_c6,:a_c7)",
+					InsertOnDup:  " on duplicate key update c2=c2+1, c3=c3+ifnull(values(c3), 0), c4=c4+ifnull(values(c4), 0), c6=least(ifnull(c6, values(c6)), ifnull(values(c6), c6)), c7=greatest(ifnull(c7, values(c7)), ifnull(values(c7), c7))",
+					Insert:       "insert into t1(c1,c2,c3,c4,c6,c7) select :a_c1, 1, ifnull(:a_c3 is not null, 0), ifnull(:a_c4 * :a_c5, 0), :a_c6, :a_c7 from dual where (:a_pk1,:a_pk2) <= (1,'aaa') on duplicate key update c2=c2+1, c3=c3+ifnull(values(c3), 0), c4=c4+ifnull(values(c4), 0), c6=least(ifnull(c6, values(c6)), ifnull(values(c6), c6)), c7=greatest(ifnull(c7, values(c7)), ifnull(values(c7), c7))",
+					Update:       "update t1 set c2=c2, c3=c3-ifnull(:b_c3 is not null, 0)+ifnull(:a_c3 is not null, 0), c4=c4-ifnull(:b_c4 * :b_c5, 0)+ifnull(:a_c4 * :a_c5, 0), c6=least(ifnull(c6, :a_c6), ifnull(:a_c6, c6)), c7=greatest(ifnull(c7, :a_c7), ifnull(:a_c7, c7)) where c1=:b_c1 and (:b_pk1,:b_pk2) <= (1,'aaa')",
+					Delete:       "update t1 set c2=c2-1, c3=c3-ifnull(:b_c3 is not null, 0), c4=c4-ifnull(:b_c4 * :b_c5, 0), c6=c6, c7=c7 where c1=:b_c1 and (:b_pk1,:b_pk2) <= (1,'aaa')",
+				},
+			},
+		},
 	}, {
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
@@ -657,14 +714,23 @@ func TestBuildPlayerPlan(t *testing.T) {
 		},
 		err: "expression needs an alias: hour(c1) in query: select hour(c1) from t1",
 	}, {
-		// only count(*)
+		// unsupported aggregation
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select avg(c1) as c from t1",
+			}},
+		},
+		err: "unsupported aggregation function: avg(c1) in query: select avg(c1) as c from t1",
+	}, {
+		// no distinct in aggregations
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
 				Match:  "t1",
-				Filter: "select count(c1) as c from t1",
+				Filter: "select count(distinct c1) as c from t1",
 			}},
 		},
-		err: "only count(*) is supported: count(c1) in query: select count(c1) as c from t1",
+		err: "unsupported distinct expression usage: count(distinct c1) in query: select count(distinct c1) as c from t1",
 	}, {
 		// no sum(*)
 		input: &binlogdatapb.Filter{
@@ -684,14 +750,14 @@ func TestBuildPlayerPlan(t *testing.T) {
 		},
 		err: "syntax error at position 14 in query: select sum(a, b) as c from t1",
 	}, {
-		// no complex expr in sum
+		// no subquery in aggregations
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
 				Match:  "t1",
-				Filter: "select sum(a + b) as c from t1",
+				Filter: "select max((select a from t2)) as c from t1",
 			}},
 		},
-		err: "unsupported non-column name in sum clause: sum(a + b) in query: select sum(a + b) as c from t1",
+		err: "unsupported subquery: (select a from t2) in query: select max((select a from t2)) as c from t1",
 	}, {
 		// no complex expr in group by
 		input: &binlogdatapb.Filter{
@@ -818,3 +884,95 @@ func TestBuildPlayerPlanExclude(t *testing.T) {
 	wantPlan, _ := json.Marshal(want)
 	assert.Equal(t, string(gotPlan), string(wantPlan))
 }
+
+func TestApplyChangeMinMax(t *testing.T) {
+	input := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select c1, count(*) as c2, min(c3) as c3, max(c4) as c4 from t2 group by c1",
+		}},
+	}
+	PrimaryKeyInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "c1", IsPK: true}},
+	}
+	plan, err := buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+	require.NoError(t, err)
+	tp := plan.TablePlans["t2"]
+	require.Equal(t, []string{"c3", "c4"}, tp.MinMaxReferences)
+	fields := sqltypes.MakeTestFields("c1|c3|c4|c5", "int64|int64|int64|int64")
+	tp.Fields = fields
+
+	// The rows of the source, the last one is in another group.
+	var streamed []string
+	tp.env = vtenv.NewTestEnv()
+	tp.streamSourceRows = func(query string, send func(*binlogdatapb.VStreamRowsResponse) error) error {
+		streamed = append(streamed, query)
+		qr := sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields, "1|5|6|0", "1|4|9|0", "2|1|20|0"))
+		return send(&binlogdatapb.VStreamRowsResponse{Fields: qr.Fields, Rows: qr.Rows})
+	}
+	var queries []string
+	// The result of the query checking whether the before image of a row
+	// holds the MIN or the MAX of its group.
+	var held string
+	executor := func(query string) (*sqltypes.Result, error) {
+		queries = append(queries, query)
+		if strings.HasPrefix(query, "select ") {
+			return sqltypes.MakeTestResult(sqltypes.MakeTestFields("c3|c4", "int64|int64"), held), nil
+		}
+		return &sqltypes.Result{}, nil
+	}
+	row := func(vals ...string) *querypb.Row {
+		return sqltypes.RowToProto3(sqltypes.MakeTestResult(fields, strings.Join(vals, "|")).Rows[0])
+	}
+
+	_, err = tp.applyChange(&binlogdatapb.RowChange{After: row("1", "2", "3", "4")}, executor)
+	require.NoError(t, err)
+	// The columns which are not aggregated by MIN and MAX are updated directly.
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row("1", "2", "3", "4"), After: row("1", "2", "3", "5")}, executor)
+	require.NoError(t, err)
+	// The aggregations are not recomputed when the row does not hold them.
+	held = "0|0"
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row("1", "2", "3", "4"), After: row("1", "1", "3", "4")}, executor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"insert into t1(c1,c2,c3,c4) values (1,1,2,3) on duplicate key update c2=c2+1, c3=least(ifnull(c3, values(c3)), ifnull(values(c3), c3)), c4=greatest(ifnull(c4, values(c4)), ifnull(values(c4), c4))",
+		"update t1 set c2=c2, c3=least(ifnull(c3, 2), ifnull(2, c3)), c4=greatest(ifnull(c4, 3), ifnull(3, c4)) where c1=1",
+		"select c3=(2), c4=(3) from t1 where c1=1",
+		"update t1 set c2=c2, c3=least(ifnull(c3, 1), ifnull(1, c3)), c4=greatest(ifnull(c4, 3), ifnull(3, c4)) where c1=1",
+	}, queries)
+	assert.Empty(t, streamed)
+
+	// Deleting the row holding the MIN of its group recomputes the aggregations
+	// of the group from the rows of the source.
+	queries = nil
+	held = "1|0"
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row("1", "1", "3", "4")}, executor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"select c3=(1), c4=(3) from t1 where c1=1",
+		"update t1 set c2=c2-1, c3=c3, c4=c4 where c1=1",
+		"update t1 set c3=4, c4=9 where c1=1",
+	}, queries)
+	assert.Equal(t, []string{"select c1, c3, c4 from t2 where c1 = 1"}, streamed)
+
+	// So does moving the row holding the MAX of its group to another group.
+	queries, streamed = nil, nil
+	held = "0|1"
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row("1", "4", "9", "0"), After: row("2", "4", "9", "0")}, executor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"select c3=(4), c4=(9) from t1 where c1=1",
+		"update t1 set c2=c2-1, c3=c3, c4=c4 where c1=1",
+		"insert into t1(c1,c2,c3,c4) values (2,1,4,9) on duplicate key update c2=c2+1, c3=least(ifnull(c3, values(c3)), ifnull(values(c3), c3)), c4=greatest(ifnull(c4, values(c4)), ifnull(values(c4), c4))",
+		"update t1 set c3=4, c4=9 where c1=1",
+	}, queries)
+	assert.Len(t, streamed, 1)
+
+	// The changes of the rows which are not copied yet are applied directly,
+	// the queries of a plan built with the copy state filter them out.
+	queries = nil
+	tp.Lastpk = sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1", "int64"), "1")
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row("2", "2", "3", "4")}, executor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update t1 set c2=c2-1, c3=c3, c4=c4 where c1=2"}, queries)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// errorClass is the class of an error of a stream, which defines how the
+// error is retried.
+type errorClass string
+
+const (
+	// errorClassNetwork are the errors reaching the source or the target,
+	// e.g. an unavailable source tablet.
+	errorClassNetwork errorClass = "network"
+	// errorClassMySQLGone are the errors of a MySQL server which went away
+	// or is shutting down.
+	errorClassMySQLGone errorClass = "mysql_gone"
+	// errorClassDuplicateKey are the duplicate key errors on the target.
+	errorClassDuplicateKey errorClass = "duplicate_key"
+	// errorClassSchemaMismatch are the errors of a target schema which does
+	// not match the rows, e.g. a missing table or column.
+	errorClassSchemaMismatch errorClass = "schema_mismatch"
+	// errorClassUnrecoverable are the other errors which need a manual
+	// intervention, see isUnrecoverableError.
+	errorClassUnrecoverable errorClass = "unrecoverable"
+	// errorClassUnknown are all the other errors.
+	errorClassUnknown errorClass = "unknown"
+)
+
+// classifyError returns the class of an error of a stream.
+func classifyError(err error) errorClass {
+	if sqlErr, ok := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError); ok {
+		switch sqlErr.Num {
+		case sqlerror.CRServerGone, sqlerror.CRServerLost, sqlerror.ERServerShutdown:
+			return errorClassMySQLGone
+		case sqlerror.CRConnectionError, sqlerror.CRConnHostError, sqlerror.ERConCount, sqlerror.ERTooManyUserConnections:
+			return errorClassNetwork
+		case sqlerror.ERDupEntry, sqlerror.ERDupUnique:
+			return errorClassDuplicateKey
+		case sqlerror.ERBadFieldError, sqlerror.ERNoSuchTable, sqlerror.ERUnknownTable, sqlerror.ERWrongValueCountOnRow, sqlerror.ERNoDefaultForField:
+			return errorClassSchemaMismatch
+		}
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_DEADLINE_EXCEEDED:
+		return errorClassNetwork
+	}
+	if isUnrecoverableError(err) {
+		return errorClassUnrecoverable
+	}
+	return errorClassUnknown
+}
+
+// retryMaxAttempts returns the maximum number of consecutive attempts of a
+// stream failing with errors of the class, 0 if they are not limited, and
+// whether it is set with --vreplication-retry-max-attempts.
+func retryMaxAttempts(class errorClass) (int, bool) {
+	attempts, ok := retryMaxAttemptsByClass[string(class)]
+	return attempts, ok
+}
+
+// retryBackoff returns the delay before the next attempt of a stream after
+// the consecutive failed attempts: --vreplication_retry_delay doubled on each
+// failure, up to --vreplication-retry-max-delay.
+func retryBackoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return max(min(delay, retryMaxDelay), retryDelay)
+}
+
+// streamRetries tracks the consecutive failed attempts of a stream with the
+// errors of the same class. They are only accessed by the goroutine running
+// the stream.
+type streamRetries struct {
+	// started is when the current attempt started.
+	started  time.Time
+	class    errorClass
+	attempts int
+}
+
+// start records the start of an attempt.
+func (r *streamRetries) start(now time.Time) {
+	r.started = now
+}
+
+// attempt returns the number of consecutive attempts failed with the class if
+// the current attempt failed now. The failures are not consecutive anymore
+// once the stream ran for --vreplication-retry-max-delay.
+func (r *streamRetries) attempt(class errorClass, now time.Time) int {
+	if class != r.class || now.Sub(r.started) >= retryMaxDelay {
+		return 1
+	}
+	return r.attempts + 1
+}
+
+// exhausted returns whether the maximum attempts of the class of err are
+// exhausted if the current attempt failed now. The classes whose maximum
+// attempts are not set with --vreplication-retry-max-attempts are not
+// retried if the error needs a manual intervention, see isUnrecoverableError,
+// and retried until --vreplication_max_time_to_retry_on_error otherwise.
+func (r *streamRetries) exhausted(class errorClass, err error, now time.Time) bool {
+	maxAttempts, ok := retryMaxAttempts(class)
+	if !ok {
+		return isUnrecoverableError(err)
+	}
+	return maxAttempts > 0 && r.attempt(class, now) >= maxAttempts
+}
+
+// fail records the failure of the current attempt and returns the delay
+// before the next one.
+func (r *streamRetries) fail(class errorClass, now time.Time) time.Duration {
+	r.attempts = r.attempt(class, now)
+	r.class = class
+	return retryBackoff(r.attempts)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestClassifyError(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want errorClass
+	}{
+		{
+			err:  sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "MySQL server has gone away"),
+			want: errorClassMySQLGone,
+		},
+		{
+			err:  vterrors.Wrap(sqlerror.NewSQLError(sqlerror.CRServerLost, sqlerror.SSUnknownSQLState, "Lost connection to MySQL server during query"), "vstream ended"),
+			want: errorClassMySQLGone,
+		},
+		{
+			err:  vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "connection refused"),
+			want: errorClassNetwork,
+		},
+		{
+			err:  sqlerror.NewSQLError(sqlerror.ERDupEntry, sqlerror.SSConstraintViolation, "Duplicate entry '1' for key 'PRIMARY'"),
+			want: errorClassDuplicateKey,
+		},
+		{
+			err:  sqlerror.NewSQLError(sqlerror.ERBadFieldError, sqlerror.SSBadFieldError, "Unknown column 'c1' in 'field list'"),
+			want: errorClassSchemaMismatch,
+		},
+		{
+			err:  sqlerror.NewSQLError(sqlerror.ERDataTooLong, sqlerror.SSDataTooLong, "Data too long for column 'c1'"),
+			want: errorClassUnrecoverable,
+		},
+		{
+			err:  vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported"),
+			want: errorClassUnrecoverable,
+		},
+		{
+			err:  errors.New("unexpected"),
+			want: errorClassUnknown,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.want, classifyError(tc.err))
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	savedDelay, savedMaxDelay := retryDelay, retryMaxDelay
+	defer func() {
+		retryDelay, retryMaxDelay = savedDelay, savedMaxDelay
+	}()
+	retryDelay, retryMaxDelay = time.Second, 10*time.Second
+
+	require.Equal(t, time.Second, retryBackoff(1))
+	require.Equal(t, 2*time.Second, retryBackoff(2))
+	require.Equal(t, 8*time.Second, retryBackoff(4))
+	require.Equal(t, 10*time.Second, retryBackoff(5))
+	require.Equal(t, 10*time.Second, retryBackoff(100))
+
+	// There is no backoff when the maximum delay is lower than the delay.
+	retryMaxDelay = time.Millisecond
+	require.Equal(t, time.Second, retryBackoff(3))
+}
+
+func TestStreamRetries(t *testing.T) {
+	savedDelay, savedMaxDelay, savedMaxAttempts := retryDelay, retryMaxDelay, retryMaxAttemptsByClass
+	defer func() {
+		retryDelay, retryMaxDelay, retryMaxAttemptsByClass = savedDelay, savedMaxDelay, savedMaxAttempts
+	}()
+	retryDelay, retryMaxDelay = time.Second, time.Minute
+	retryMaxAttemptsByClass = map[string]int{"duplicate_key": 3}
+
+	// The classes without maximum attempts are retried with a backoff.
+	networkErr := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "connection refused")
+	dupErr := sqlerror.NewSQLError(sqlerror.ERDupEntry, sqlerror.SSConstraintViolation, "Duplicate entry '1' for key 'PRIMARY'")
+	now := time.Now()
+	var retries streamRetries
+	for attempts := 1; attempts <= 3; attempts++ {
+		retries.start(now)
+		require.False(t, retries.exhausted(errorClassNetwork, networkErr, now))
+		require.Equal(t, retryBackoff(attempts), retries.fail(errorClassNetwork, now))
+	}
+
+	// Another class starts over.
+	retries.start(now)
+	require.False(t, retries.exhausted(errorClassDuplicateKey, dupErr, now))
+	require.Equal(t, time.Second, retries.fail(errorClassDuplicateKey, now))
+	retries.start(now)
+	require.False(t, retries.exhausted(errorClassDuplicateKey, dupErr, now))
+	require.Equal(t, 2*time.Second, retries.fail(errorClassDuplicateKey, now))
+	retries.start(now)
+	require.True(t, retries.exhausted(errorClassDuplicateKey, dupErr, now))
+	require.Equal(t, 3, retries.attempt(errorClassDuplicateKey, now))
+
+	// The failures are not consecutive anymore once the stream ran for the
+	// maximum delay.
+	require.False(t, retries.exhausted(errorClassDuplicateKey, dupErr, now.Add(time.Minute)))
+
+	// The classes which are not set are not retried if the error needs a
+	// manual intervention, as before the classification.
+	for _, err := range []error{
+		sqlerror.NewSQLError(sqlerror.ERUnknownTable, sqlerror.SSUnknownTable, "Unknown table 't1'"),
+		sqlerror.NewSQLError(sqlerror.ERWrongValueCountOnRow, sqlerror.SSWrongValueCountOnRow, "Column count doesn't match value count at row 1"),
+		sqlerror.NewSQLError(sqlerror.ERDataTooLong, sqlerror.SSDataTooLong, "Data too long for column 'c1'"),
+	} {
+		require.True(t, retries.exhausted(classifyError(err), err, now))
+	}
+	require.False(t, retries.exhausted(errorClassNetwork, networkErr, now))
+	require.False(t, retries.exhausted(errorClassUnknown, errors.New("unexpected"), now))
+
+	// Setting the maximum attempts of a class overrides it.
+	retryMaxAttemptsByClass = map[string]int{"schema_mismatch": 2}
+	unknownTableErr := sqlerror.NewSQLError(sqlerror.ERUnknownTable, sqlerror.SSUnknownTable, "Unknown table 't1'")
+	retries = streamRetries{}
+	retries.start(now)
+	require.False(t, retries.exhausted(errorClassSchemaMismatch, unknownTableErr, now))
+	retries.fail(errorClassSchemaMismatch, now)
+	retries.start(now)
+	require.True(t, retries.exhausted(errorClassSchemaMismatch, unknownTableErr, now))
+}
@@ -18,6 +18,7 @@ package vreplication
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/servenv"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -458,6 +460,44 @@ func (st *vrStats) register() {
 			return result
 		})
 
+	stats.NewGaugesFuncWithMultiLabels(
+		"VReplicationTableRowsApplied",
+		"vreplication row changes applied in replication phase per table per stream",
+		[]string{"source_keyspace", "source_shard", "workflow", "counts", "table"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64, len(st.controllers))
+			for _, ct := range st.controllers {
+				for table, count := range ct.blpStats.TableRowsApplied.Counts() {
+					if table == "" {
+						continue
+					}
+					result[ct.source.Keyspace+"."+ct.source.Shard+"."+ct.workflow+"."+fmt.Sprintf("%v", ct.id)+"."+table] = count
+				}
+			}
+			return result
+		})
+
+	stats.NewGaugesFuncWithMultiLabels(
+		"VReplicationTableErrors",
+		"vreplication errors while copying or applying rows per table per stream",
+		[]string{"source_keyspace", "source_shard", "workflow", "counts", "table"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64, len(st.controllers))
+			for _, ct := range st.controllers {
+				for table, count := range ct.blpStats.TableErrorCounts.Counts() {
+					if table == "" {
+						continue
+					}
+					result[ct.source.Keyspace+"."+ct.source.Shard+"."+ct.workflow+"."+fmt.Sprintf("%v", ct.id)+"."+table] = count
+				}
+			}
+			return result
+		})
+
 	stats.NewGaugesFuncWithMultiLabels(
 		"VReplicationTableCopyTimings",
 		"vreplication copy phase timings per table per stream",
@@ -545,6 +585,53 @@ func (st *vrStats) register() {
 		})
 }
 
+// streamMetrics returns the metrics of a stream from the stats of its
+// controller.
+func streamMetrics(bps *binlogplayer.Stats) *binlogdatapb.VReplicationStreamMetrics {
+	metrics := &binlogdatapb.VReplicationStreamMetrics{
+		Tables:                make(map[string]*binlogdatapb.VReplicationStreamMetrics_TableMetrics),
+		ReplicationLagSeconds: bps.ReplicationLagSeconds.Load(),
+		Retries:               bps.ErrorCounts.Counts()["Stream Error"],
+	}
+	if metrics.ReplicationLagSeconds == math.MaxInt64 {
+		metrics.ReplicationLagSeconds = -1
+	}
+	tableMetrics := func(table string) *binlogdatapb.VReplicationStreamMetrics_TableMetrics {
+		tm, ok := metrics.Tables[table]
+		if !ok {
+			tm = &binlogdatapb.VReplicationStreamMetrics_TableMetrics{}
+			metrics.Tables[table] = tm
+		}
+		return tm
+	}
+	// The rates are ordered from the least recent to the most recent one.
+	lastRate := func(rates []float64) float64 {
+		if len(rates) == 0 {
+			return 0
+		}
+		return rates[len(rates)-1]
+	}
+	copyRates := bps.TableCopyRowRates.Get()
+	for table, count := range bps.TableCopyRowCounts.Counts() {
+		tm := tableMetrics(table)
+		tm.RowsCopied = count
+		tm.RowsCopiedPerSecond = lastRate(copyRates[table])
+	}
+	appliedRates := bps.TableRowsAppliedRates.Get()
+	for table, count := range bps.TableRowsApplied.Counts() {
+		tm := tableMetrics(table)
+		tm.RowsApplied = count
+		tm.RowsAppliedPerSecond = lastRate(appliedRates[table])
+	}
+	for table, count := range bps.TableErrorCounts.Counts() {
+		tableMetrics(table).Errors = count
+	}
+	for table, lastError := range bps.TableLastErrors() {
+		tableMetrics(table).LastError = lastError
+	}
+	return metrics
+}
+
 func (st *vrStats) numControllers() int64 {
 	st.mu.Lock()
 	defer st.mu.Unlock()
@@ -18,6 +18,7 @@ package vreplication
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -207,3 +208,33 @@ func TestVReplicationStats(t *testing.T) {
 	blpStats.RecordHeartbeat(tm)
 	require.Equal(t, tm, blpStats.Heartbeat())
 }
+
+func TestStreamMetrics(t *testing.T) {
+	blpStats := binlogplayer.NewStats()
+	defer blpStats.Stop()
+
+	// The lag is unknown until the stream replicates.
+	metrics := streamMetrics(blpStats)
+	require.Equal(t, int64(-1), metrics.ReplicationLagSeconds)
+	require.Empty(t, metrics.Tables)
+
+	blpStats.ReplicationLagSeconds.Store(3)
+	blpStats.ErrorCounts.Add([]string{"Stream Error"}, 2)
+	blpStats.ErrorCounts.Add([]string{"Copy"}, 1)
+	blpStats.TableCopyRowCounts.Add("t1", 100)
+	blpStats.TableRowsApplied.Add("t1", 10)
+	blpStats.TableRowsApplied.Add("t2", 20)
+	blpStats.RecordTableError("t2", errors.New("duplicate entry"))
+	blpStats.RecordTableError("t2", errors.New("lock wait timeout"))
+
+	metrics = streamMetrics(blpStats)
+	require.Equal(t, int64(3), metrics.ReplicationLagSeconds)
+	require.Equal(t, int64(2), metrics.Retries)
+	require.Len(t, metrics.Tables, 2)
+	require.Equal(t, int64(100), metrics.Tables["t1"].RowsCopied)
+	require.Equal(t, int64(10), metrics.Tables["t1"].RowsApplied)
+	require.Zero(t, metrics.Tables["t1"].Errors)
+	require.Equal(t, int64(20), metrics.Tables["t2"].RowsApplied)
+	require.Equal(t, int64(2), metrics.Tables["t2"].Errors)
+	require.Equal(t, "lock wait timeout", metrics.Tables["t2"].LastError)
+}
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
@@ -71,7 +72,9 @@ type colExpr struct {
 	colType querypb.Type
 	// operation==opExpr: full expression is set
 	// operation==opCount: nothing is set.
-	// operation==opSum: for 'sum(a)', expr is set to 'a'.
+	// operation==opSum: for 'sum(a)', expr is set to 'a'. For 'count(a)',
+	// expr is set to 'a is not null'.
+	// operation==opMin, opMax: for 'min(a)', expr is set to 'a'.
 	operation operation
 	// expr stores the expected field name from vstreamer and dictates
 	// the generated bindvar names, like a_col or b_col.
@@ -93,6 +96,8 @@ const (
 	opExpr = operation(iota)
 	opCount
 	opSum
+	opMin
+	opMax
 )
 
 // insertType describes the type of insert statement to generate.
@@ -350,6 +355,20 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 	}
 	sort.Strings(pkrefs)
 
+	minMaxRefmap := make(map[string]bool)
+	for _, cexpr := range tpb.colExprs {
+		if cexpr.operation == opMin || cexpr.operation == opMax {
+			for k := range cexpr.references {
+				minMaxRefmap[k] = true
+			}
+		}
+	}
+	var minMaxRefs []string
+	for k := range minMaxRefmap {
+		minMaxRefs = append(minMaxRefs, k)
+	}
+	sort.Strings(minMaxRefs)
+
 	bvf := &bindvarFormatter{}
 
 	fieldsToSkip := make(map[string]bool)
@@ -369,7 +388,10 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		Update:                  tpb.generateUpdateStatement(),
 		Delete:                  tpb.generateDeleteStatement(),
 		MultiDelete:             tpb.generateMultiDeleteStatement(),
+		MinMaxCheck:             tpb.generateMinMaxCheckStatement(),
+		MinMaxRecompute:         tpb.generateMinMaxRecomputeStatement(),
 		PKReferences:            pkrefs,
+		MinMaxReferences:        minMaxRefs,
 		PKIndices:               tpb.pkIndices,
 		Stats:                   tpb.stats,
 		FieldsToSkip:            fieldsToSkip,
@@ -377,6 +399,7 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		TablePlanBuilder:        tpb,
 		PartialInserts:          make(map[string]*sqlparser.ParsedQuery, 0),
 		PartialUpdates:          make(map[string]*sqlparser.ParsedQuery, 0),
+		partialQueriesMu:        &sync.Mutex{},
 		CollationEnv:            tpb.collationEnv,
 	}
 }
@@ -482,32 +505,48 @@ func (tpb *tablePlanBuilder) analyzeExpr(selExpr sqlparser.SelectExpr) (*colExpr
 		if sqlparser.IsDistinct(expr) {
 			return nil, fmt.Errorf("unsupported distinct expression usage: %v", sqlparser.String(expr))
 		}
-		switch fname := expr.AggrName(); fname {
-		case "count":
-			if _, ok := expr.(*sqlparser.CountStar); !ok {
-				return nil, fmt.Errorf("only count(*) is supported: %v", sqlparser.String(expr))
-			}
+		if _, ok := expr.(*sqlparser.CountStar); ok {
 			cexpr.operation = opCount
 			return cexpr, nil
-		case "sum":
+		}
+		switch fname := expr.AggrName(); fname {
+		case "count", "sum", "min", "max":
 			if len(expr.GetArgs()) != 1 {
-				return nil, fmt.Errorf("unsupported multiple columns in sum clause: %v", sqlparser.String(expr))
+				return nil, fmt.Errorf("unsupported multiple expressions in %s clause: %v", fname, sqlparser.String(expr))
 			}
-			innerCol, ok := expr.GetArg().(*sqlparser.ColName)
-			if !ok {
-				return nil, fmt.Errorf("unsupported non-column name in sum clause: %v", sqlparser.String(expr))
+			arg := expr.GetArg()
+			if err := tpb.analyzeColumns(arg, cexpr); err != nil {
+				return nil, err
 			}
-			if !innerCol.Qualifier.IsEmpty() {
-				return nil, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(innerCol))
+			switch fname {
+			case "count":
+				// count(a) is maintained as the sum of the non null values of a.
+				cexpr.operation = opSum
+				cexpr.expr = &sqlparser.IsExpr{Left: arg, Right: sqlparser.IsNotNullOp}
+			case "sum":
+				cexpr.operation = opSum
+				cexpr.expr = arg
+			case "min":
+				cexpr.operation = opMin
+				cexpr.expr = arg
+			case "max":
+				cexpr.operation = opMax
+				cexpr.expr = arg
 			}
-			cexpr.operation = opSum
-			cexpr.expr = innerCol
-			tpb.addCol(innerCol.Name)
-			cexpr.references[innerCol.Name.String()] = true
 			return cexpr, nil
 		}
 	}
-	err := sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
+	if err := tpb.analyzeColumns(aliased.Expr, cexpr); err != nil {
+		return nil, err
+	}
+	cexpr.expr = aliased.Expr
+	return cexpr, nil
+}
+
+// analyzeColumns adds the columns referenced by a scalar expression
+// to the send query and to the references of the colExpr.
+func (tpb *tablePlanBuilder) analyzeColumns(expr sqlparser.Expr, cexpr *colExpr) error {
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
 		case *sqlparser.ColName:
 			if !node.Qualifier.IsEmpty() {
@@ -521,12 +560,7 @@ func (tpb *tablePlanBuilder) analyzeExpr(selExpr sqlparser.SelectExpr) (*colExpr
 			return false, fmt.Errorf("unsupported aggregation function: %v", sqlparser.String(node))
 		}
 		return true, nil
-	}, aliased.Expr)
-	if err != nil {
-		return nil, err
-	}
-	cexpr.expr = aliased.Expr
-	return cexpr, nil
+	}, expr)
 }
 
 // addCol adds the specified column to the send query
@@ -729,6 +763,8 @@ func (tpb *tablePlanBuilder) generateValuesPart(buf *sqlparser.TrackedBuffer, bv
 		case opSum:
 			// NULL values must be treated as 0 for SUM.
 			buf.Myprintf("ifnull(%v, 0)", cexpr.expr)
+		case opMin, opMax:
+			buf.Myprintf("%v", cexpr.expr)
 		}
 	}
 	buf.Myprintf(")")
@@ -752,6 +788,8 @@ func (tpb *tablePlanBuilder) generateSelectPart(buf *sqlparser.TrackedBuffer, bv
 			buf.WriteString("1")
 		case opSum:
 			buf.Myprintf("ifnull(%v, 0)", cexpr.expr)
+		case opMin, opMax:
+			buf.Myprintf("%v", cexpr.expr)
 		}
 	}
 	buf.WriteString(" from dual where ")
@@ -789,6 +827,13 @@ func (tpb *tablePlanBuilder) generateOnDupPart(buf *sqlparser.TrackedBuffer) *sq
 		case opSum:
 			buf.Myprintf("%v", cexpr.colName)
 			buf.Myprintf("+ifnull(values(%v), 0)", cexpr.colName)
+		case opMin, opMax:
+			// NULL values are ignored by MIN and MAX, unlike by LEAST and GREATEST.
+			fname := "least"
+			if cexpr.operation == opMax {
+				fname = "greatest"
+			}
+			buf.Myprintf("%s(ifnull(%v, values(%v)), ifnull(values(%v), %v))", fname, cexpr.colName, cexpr.colName, cexpr.colName, cexpr.colName)
 		}
 	}
 	return buf.ParsedQuery()
@@ -834,6 +879,15 @@ func (tpb *tablePlanBuilder) generateUpdateStatement() *sqlparser.ParsedQuery {
 			}
 		case opCount:
 			buf.Myprintf("%v", cexpr.colName)
+		case opMin, opMax:
+			// The new value is merged into the aggregation. If the old value was
+			// the aggregation, the TablePlan recomputes it, see MinMaxReferences.
+			fname := "least"
+			if cexpr.operation == opMax {
+				fname = "greatest"
+			}
+			bvf.mode = bvAfter
+			buf.Myprintf("%s(ifnull(%v, %v), ifnull(%v, %v))", fname, cexpr.colName, cexpr.expr, cexpr.expr, cexpr.colName)
 		case opSum:
 			buf.Myprintf("%v", cexpr.colName)
 			bvf.mode = bvBefore
@@ -870,6 +924,8 @@ func (tpb *tablePlanBuilder) generateDeleteStatement() *sqlparser.ParsedQuery {
 				buf.Myprintf("%v-1", cexpr.colName)
 			case opSum:
 				buf.Myprintf("%v-ifnull(%v, 0)", cexpr.colName, cexpr.expr)
+			case opMin, opMax:
+				buf.Myprintf("%v", cexpr.colName)
 			}
 		}
 		tpb.generateWhere(buf, bvf)
@@ -879,9 +935,67 @@ func (tpb *tablePlanBuilder) generateDeleteStatement() *sqlparser.ParsedQuery {
 	return buf.ParsedQuery()
 }
 
+// generateMinMaxCheckStatement generates the query returning, for each MIN
+// and MAX aggregation of the group of the before image of a row, whether the
+// row holds the value of the aggregation.
+func (tpb *tablePlanBuilder) generateMinMaxCheckStatement() *sqlparser.ParsedQuery {
+	if !tpb.hasMinMax() {
+		return nil
+	}
+	bvf := &bindvarFormatter{mode: bvBefore}
+	buf := sqlparser.NewTrackedBuffer(bvf.formatter)
+	buf.WriteString("select ")
+	separator := ""
+	for _, cexpr := range tpb.colExprs {
+		if cexpr.operation != opMin && cexpr.operation != opMax {
+			continue
+		}
+		buf.Myprintf("%s%v=(%v)", separator, cexpr.colName, cexpr.expr)
+		separator = ", "
+	}
+	buf.Myprintf(" from %v", tpb.name)
+	tpb.generateWhere(buf, bvf)
+	return buf.ParsedQuery()
+}
+
+// generateMinMaxRecomputeStatement generates the statement setting the MIN and
+// MAX aggregations of the group of the before image of a row to the values of
+// the minmax_<n> bind variables, n being the position of the aggregation.
+func (tpb *tablePlanBuilder) generateMinMaxRecomputeStatement() *sqlparser.ParsedQuery {
+	if !tpb.hasMinMax() {
+		return nil
+	}
+	bvf := &bindvarFormatter{}
+	buf := sqlparser.NewTrackedBuffer(bvf.formatter)
+	buf.Myprintf("update %v set ", tpb.name)
+	separator := ""
+	n := 0
+	for _, cexpr := range tpb.colExprs {
+		if cexpr.operation != opMin && cexpr.operation != opMax {
+			continue
+		}
+		buf.Myprintf("%s%v=", separator, cexpr.colName)
+		buf.WriteArg(":", fmt.Sprintf("minmax_%d", n))
+		separator = ", "
+		n++
+	}
+	tpb.generateWhere(buf, bvf)
+	return buf.ParsedQuery()
+}
+
+func (tpb *tablePlanBuilder) hasMinMax() bool {
+	for _, cexpr := range tpb.colExprs {
+		if cexpr.operation == opMin || cexpr.operation == opMax {
+			return true
+		}
+	}
+	return false
+}
+
 func (tpb *tablePlanBuilder) generateMultiDeleteStatement() *sqlparser.ParsedQuery {
+	// Deletes are applied as updates of the aggregations for grouped tables.
 	if vttablet.VReplicationExperimentalFlags&vttablet.VReplicationExperimentalFlagVPlayerBatching == 0 ||
-		(len(tpb.pkCols)+len(tpb.extraSourcePkCols)) != 1 {
+		tpb.onInsert != insertNormal || (len(tpb.pkCols)+len(tpb.extraSourcePkCols)) != 1 {
 		return nil
 	}
 	return sqlparser.BuildParsedQuery("delete from %s where %s in %a",
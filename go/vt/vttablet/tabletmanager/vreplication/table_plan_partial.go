@@ -179,6 +179,8 @@ func (tpb *tablePlanBuilder) createPartialUpdateQuery(dataColumns *binlogdatapb.
 	return buf.ParsedQuery()
 }
 func (tp *TablePlan) getPartialInsertQuery(dataColumns *binlogdatapb.RowChange_Bitmap) (*sqlparser.ParsedQuery, error) {
+	tp.partialQueriesMu.Lock()
+	defer tp.partialQueriesMu.Unlock()
 	key := fmt.Sprintf("%x", dataColumns.Cols)
 	ins, ok := tp.PartialInserts[key]
 	if ok {
@@ -194,6 +196,8 @@ func (tp *TablePlan) getPartialInsertQuery(dataColumns *binlogdatapb.RowChange_B
 }
 
 func (tp *TablePlan) getPartialUpdateQuery(dataColumns *binlogdatapb.RowChange_Bitmap) (*sqlparser.ParsedQuery, error) {
+	tp.partialQueriesMu.Lock()
+	defer tp.partialQueriesMu.Unlock()
 	key := fmt.Sprintf("%x", dataColumns.Cols)
 	upd, ok := tp.PartialUpdates[key]
 	if ok {
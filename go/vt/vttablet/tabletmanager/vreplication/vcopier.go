@@ -547,6 +547,9 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		currT.lifecycle.onResult().do(func(_ context.Context, result *vcopierCopyTaskResult) {
 			if result.state == vcopierCopyTaskFail {
 				vc.vr.stats.ErrorCounts.Add([]string{"Copy"}, 1)
+				if result.err != nil {
+					vc.vr.stats.RecordTableError(tableName, result.err)
+				}
 			}
 			if result.state == vcopierCopyTaskComplete {
 				vc.vr.stats.CopyRowCount.Add(int64(len(result.args.rows)))
@@ -90,15 +90,20 @@ type vplayer struct {
 
 	throttlerAppName string
 
-	// See updateFKCheck for more details on how the two fields below are used.
+	// foreignKeyChecks is the state of the foreign key checks of the session of the vplayer.
+	// See updateFKCheck for more details on how it is used.
+	foreignKeyChecks foreignKeyChecksState
+}
 
-	// foreignKeyChecksEnabled is the current state of the foreign key checks for the current session.
+// foreignKeyChecksState is the state of the foreign key checks of a session.
+type foreignKeyChecksState struct {
+	// enabled is the current state of the foreign key checks for the session.
 	// It reflects what we have set the @@session.foreign_key_checks session variable to.
-	foreignKeyChecksEnabled bool
+	enabled bool
 
-	// foreignKeyChecksStateInitialized is set to true once we have initialized the foreignKeyChecksEnabled.
-	// The initialization is done on the first row event that this vplayer sees.
-	foreignKeyChecksStateInitialized bool
+	// initialized is set to true once we have initialized enabled.
+	// The initialization is done on the first row event that the session sees.
+	initialized bool
 }
 
 // NoForeignKeyCheckFlagBitmask is the bitmask for the 2nd bit (least significant) of the flags in a binlog row event.
@@ -198,6 +203,8 @@ func (vp *vplayer) play(ctx context.Context) error {
 		vp.vr.stats.ErrorCounts.Add([]string{"Plan"}, 1)
 		return err
 	}
+	// The workflow name lets the source tablet attribute its metrics.
+	plan.VStreamFilter.WorkflowName = vp.vr.WorkflowName
 	vp.replicatorPlan = plan
 
 	// We can't run in statement mode if there are filters defined.
@@ -219,6 +226,12 @@ func (vp *vplayer) play(ctx context.Context) error {
 // - If unset (0), foreign key checks are enabled.
 // updateFKCheck also updates the state for the first row event that this vplayer, and hence the db connection, sees.
 func (vp *vplayer) updateFKCheck(ctx context.Context, flags2 uint32) error {
+	return vp.updateSessionFKCheck(ctx, flags2, vp.query, &vp.foreignKeyChecks)
+}
+
+// updateSessionFKCheck is updateFKCheck for the session of the query function, whose foreign key checks
+// are in state.
+func (vp *vplayer) updateSessionFKCheck(ctx context.Context, flags2 uint32, query func(ctx context.Context, sql string) (*sqltypes.Result, error), state *foreignKeyChecksState) error {
 	mustUpdate := false
 	if vp.vr.WorkflowSubType == int32(binlogdatapb.VReplicationWorkflowSubType_AtomicCopy) {
 		// If this is an atomic copy, we must update the foreign_key_checks state even when the vplayer runs during
@@ -237,18 +250,18 @@ func (vp *vplayer) updateFKCheck(ctx context.Context, flags2 uint32) error {
 		dbForeignKeyChecksEnabled = false
 	}
 
-	if vp.foreignKeyChecksStateInitialized /* already set earlier */ &&
-		dbForeignKeyChecksEnabled == vp.foreignKeyChecksEnabled /* no change in the state, no need to update */ {
+	if state.initialized /* already set earlier */ &&
+		dbForeignKeyChecksEnabled == state.enabled /* no change in the state, no need to update */ {
 		return nil
 	}
 	log.Infof("Setting this session's foreign_key_checks to %s", strconv.FormatBool(dbForeignKeyChecksEnabled))
-	if _, err := vp.query(ctx, "set @@session.foreign_key_checks="+strconv.FormatBool(dbForeignKeyChecksEnabled)); err != nil {
+	if _, err := query(ctx, "set @@session.foreign_key_checks="+strconv.FormatBool(dbForeignKeyChecksEnabled)); err != nil {
 		return fmt.Errorf("failed to set session foreign_key_checks: %w", err)
 	}
-	vp.foreignKeyChecksEnabled = dbForeignKeyChecksEnabled
-	if !vp.foreignKeyChecksStateInitialized {
+	state.enabled = dbForeignKeyChecksEnabled
+	if !state.initialized {
 		log.Infof("First foreign_key_checks update to: %s", strconv.FormatBool(dbForeignKeyChecksEnabled))
-		vp.foreignKeyChecksStateInitialized = true
+		state.initialized = true
 	}
 	return nil
 }
@@ -481,6 +494,14 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	// can estimate this value more accurately.
 	defer vp.vr.stats.ReplicationLagSeconds.Store(math.MaxInt64)
 	defer vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), math.MaxInt64)
+	var parallel *parallelApplier
+	if workers := vp.parallelApplyWorkers(); workers > 1 {
+		var err error
+		if parallel, err = newParallelApplier(ctx, vp, workers); err != nil {
+			return err
+		}
+		defer parallel.close()
+	}
 	var sbm int64 = -1
 	for {
 		if ctx.Err() != nil {
@@ -502,6 +523,13 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 			behind := time.Now().UnixNano() - vp.lastTimestampNs - vp.timeOffsetNs
 			vp.vr.stats.ReplicationLagSeconds.Store(behind / 1e9)
 			vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), time.Duration(behind/1e9)*time.Second)
+			if parallel != nil {
+				// Let the transactions in flight commit, so that the position
+				// of a trailing empty transaction can be saved.
+				if err := parallel.drain(ctx); err != nil {
+					return err
+				}
+			}
 		}
 		// Empty transactions are saved at most once every idleTimeout.
 		// This covers two situations:
@@ -527,6 +555,15 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 					vp.timeOffsetNs = time.Now().UnixNano() - event.CurrentTime
 					sbm = event.CurrentTime/1e9 - event.Timestamp
 				}
+				if parallel != nil {
+					handled, err := parallel.addEvent(ctx, event)
+					if err != nil {
+						return err
+					}
+					if handled {
+						continue
+					}
+				}
 				mustSave := false
 				switch event.Type {
 				case binlogdatapb.VEventType_COMMIT:
@@ -542,29 +579,22 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 					// applying the next set of events as part of the current transaction. This approach
 					// also handles the case where the last transaction is partial. In that case,
 					// we only group the transactions with commits we've seen so far.
-					if hasAnotherCommit(items, i, j+1) {
+					// The transactions applied in parallel are never grouped.
+					if parallel == nil && hasAnotherCommit(items, i, j+1) {
 						continue
 					}
 				}
 				if err := vp.applyEvent(ctx, event, mustSave); err != nil {
-					if err != io.EOF {
-						vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
-						var table, tableLogMsg string
-						switch {
-						case event.GetFieldEvent() != nil:
-							table = event.GetFieldEvent().TableName
-						case event.GetRowEvent() != nil:
-							table = event.GetRowEvent().TableName
-						}
-						if table != "" {
-							tableLogMsg = fmt.Sprintf(" for table %s", table)
-						}
-						log.Errorf("Error applying event%s: %s", tableLogMsg, err.Error())
-					}
+					vp.recordApplyError(event, err)
 					return err
 				}
 			}
 		}
+		if parallel != nil {
+			if err := parallel.endOfFetch(ctx); err != nil {
+				return err
+			}
+		}
 
 		if sbm >= 0 {
 			vp.vr.stats.ReplicationLagSeconds.Store(sbm)
@@ -574,6 +604,33 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	}
 }
 
+// recordApplyError records the failure to apply the event, unless err is io.EOF.
+func (vp *vplayer) recordApplyError(event *binlogdatapb.VEvent, err error) {
+	var table string
+	switch {
+	case event.GetFieldEvent() != nil:
+		table = event.GetFieldEvent().TableName
+	case event.GetRowEvent() != nil:
+		table = event.GetRowEvent().TableName
+	}
+	vp.recordTableApplyError(table, err)
+}
+
+// recordTableApplyError records the failure to apply an event of the table,
+// unless err is io.EOF.
+func (vp *vplayer) recordTableApplyError(table string, err error) {
+	if err == io.EOF {
+		return
+	}
+	vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+	var tableLogMsg string
+	if table != "" {
+		vp.vr.stats.RecordTableError(table, err)
+		tableLogMsg = fmt.Sprintf(" for table %s", table)
+	}
+	log.Errorf("Error applying event%s: %s", tableLogMsg, err.Error())
+}
+
 func hasAnotherCommit(items [][]*binlogdatapb.VEvent, i, j int) bool {
 	for i < len(items) {
 		for j < len(items[i]) {
@@ -592,6 +649,18 @@ func hasAnotherCommit(items [][]*binlogdatapb.VEvent, i, j int) bool {
 	return false
 }
 
+// setSourceRowStreamer allows the table plan to recompute its MIN and MAX
+// aggregations from the rows of the source table.
+func (vp *vplayer) setSourceRowStreamer(ctx context.Context, tplan *TablePlan) {
+	if len(tplan.MinMaxReferences) == 0 {
+		return
+	}
+	tplan.env = vp.vr.vre.env
+	tplan.streamSourceRows = func(query string, send func(*binlogdatapb.VStreamRowsResponse) error) error {
+		return vp.vr.sourceVStreamer.VStreamRows(ctx, query, nil, send)
+	}
+}
+
 func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, mustSave bool) error {
 	stats := NewVrLogStats(event.Type.String())
 	switch event.Type {
@@ -638,6 +707,7 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 		if err != nil {
 			return err
 		}
+		vp.setSourceRowStreamer(ctx, tplan)
 		vp.tablePlans[event.FieldEvent.TableName] = tplan
 		stats.Send(fmt.Sprintf("%v", event.FieldEvent))
 
@@ -668,6 +738,7 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			log.Infof("Error applying row event: %s", err.Error())
 			return err
 		}
+		vp.vr.stats.TableRowsApplied.Add(event.RowEvent.TableName, int64(len(event.RowEvent.RowChanges)))
 		// Row event is logged AFTER RowChanges are applied so as to calculate the total elapsed
 		// time for the Row event.
 		stats.Send(fmt.Sprintf("%v", event.RowEvent))
@@ -2400,6 +2400,94 @@ func TestPlayerTransactions(t *testing.T) {
 	))
 }
 
+// TestPlayerParallelApply applies transactions on several connections, some
+// of them writing the same rows or conflicting on a secondary unique key, and
+// checks that the target ends up with the same rows as the source.
+func TestPlayerParallelApply(t *testing.T) {
+	doNotLogDBQueries = true
+	defer func() { doNotLogDBQueries = false }()
+	oldWorkers := vreplicationParallelApplyWorkers
+	vreplicationParallelApplyWorkers = 4
+	defer func() { vreplicationParallelApplyWorkers = oldWorkers }()
+
+	defer deleteTablet(addTablet(100))
+	execStatements(t, []string{
+		"create table t1(id int, val varbinary(128), primary key(id))",
+		fmt.Sprintf("create table %s.t1(id int, val varbinary(128), primary key(id))", vrepldb),
+		"create table t2(id int, val varbinary(128), primary key(id), unique key(val))",
+		fmt.Sprintf("create table %s.t2(id int, val varbinary(128), primary key(id), unique key(val))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		fmt.Sprintf("drop table %s.t1", vrepldb),
+		"drop table t2",
+		fmt.Sprintf("drop table %s.t2", vrepldb),
+	})
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/.*",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_EXEC,
+	}
+	cancel, _ := startVReplication(t, bls, "")
+	defer cancel()
+
+	var statements []string
+	for i := 1; i <= 20; i++ {
+		statements = append(statements,
+			fmt.Sprintf("insert into t1 values(%d, 'a%d')", i, i),
+			fmt.Sprintf("insert into t2 values(%d, 'v%d')", i, i),
+		)
+	}
+	for i := 1; i <= 20; i++ {
+		statements = append(statements,
+			fmt.Sprintf("update t1 set val='b%d' where id=%d", i, i%5+1),
+			// Frees the unique value the next insert takes.
+			fmt.Sprintf("update t2 set val='w%d' where id=%d", i, i),
+			fmt.Sprintf("insert into t2 values(%d, 'v%d')", 100+i, i),
+		)
+	}
+	statements = append(statements,
+		"delete from t1 where id > 10",
+		// A DDL is applied once the transactions before it are committed.
+		"alter table t1 add column val2 varbinary(128)",
+		"insert into t1 values(11, 'c11', 'd11')",
+	)
+	execStatements(t, statements)
+
+	var want1 [][]string
+	for i := 1; i <= 11; i++ {
+		val := fmt.Sprintf("a%d", i)
+		// The last update of t1 row i%5+1 is at the largest j <= 20 with j%5+1 == i.
+		for j := 20; j >= 1 && i <= 5; j-- {
+			if j%5+1 == i {
+				val = fmt.Sprintf("b%d", j)
+				break
+			}
+		}
+		val2 := "NULL"
+		if i == 11 {
+			val, val2 = "c11", "d11"
+		}
+		want1 = append(want1, []string{strconv.Itoa(i), val, val2})
+	}
+	expectData(t, "t1", want1)
+	var want2 [][]string
+	for i := 1; i <= 20; i++ {
+		want2 = append(want2, []string{strconv.Itoa(i), fmt.Sprintf("w%d", i)})
+	}
+	for i := 1; i <= 20; i++ {
+		want2 = append(want2, []string{strconv.Itoa(100 + i), fmt.Sprintf("v%d", i)})
+	}
+	expectData(t, "t2", want2)
+}
+
 func TestPlayerRelayLogMaxSize(t *testing.T) {
 	defer deleteTablet(addTablet(100))
 
@@ -0,0 +1,641 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// parallelApplyMaxWriteSetSize is the number of rows written by the
+// transactions in flight above which the vplayer waits for them to commit
+// before applying more, which bounds the memory used by the write sets.
+const parallelApplyMaxWriteSetSize = 100000
+
+// errParallelApplyAborted is the error of the transactions which are not
+// committed because a transaction before them failed.
+var errParallelApplyAborted = errors.New("transaction aborted because a previous transaction failed")
+
+// parallelApplyWorkers returns the number of connections the vplayer applies
+// its transactions on. The transactions are only applied in parallel in the
+// replication phase, when there is no stop position.
+func (vp *vplayer) parallelApplyWorkers() int {
+	if vreplicationParallelApplyWorkers <= 1 || vp.phase != "replicate" || !vp.stopPos.IsZero() || len(vp.copyState) != 0 {
+		return 1
+	}
+	return vreplicationParallelApplyWorkers
+}
+
+// parallelApplier applies the row based transactions of a vplayer on several
+// connections.
+//
+// The write set of a transaction is the primary key values of the rows it
+// changes, as referenced by the table plans, or its whole tables if they have
+// no primary key references. A transaction only starts once the last
+// transaction before it whose write set intersects its own is committed. The
+// transactions are committed in their source order, each along with the
+// update of the position, so that the saved position never gets ahead of a
+// transaction which is not committed: a transaction whose statements are
+// applied waits for the transaction before it to commit.
+//
+// A transaction which fails while the transactions before it are not all
+// committed, or with a lock error, may have conflicted with the transactions
+// in flight on a row out of its write set, e.g. through a secondary unique
+// key or a foreign key. It is rolled back, the transactions after it are
+// rolled back as well to release their locks, and they are all applied again
+// once the transactions before them are committed.
+//
+// The other events, e.g. DDLs, statements, journals and the transactions
+// which do not fit in a single fetch of the relay log, are applied by the
+// vplayer on its own connection once the transactions in flight are
+// committed.
+type parallelApplier struct {
+	vp     *vplayer
+	cancel context.CancelFunc
+	txns   chan *parallelTxn
+	wg     sync.WaitGroup
+
+	workers []*parallelApplyWorker
+
+	mu sync.Mutex
+	// err is the first error of a transaction.
+	err error
+	// timeLastSaved is the last time a worker saved the position.
+	timeLastSaved time.Time
+
+	// The fields below are only used by the vplayer goroutine.
+
+	// current is the transaction being received, nil between transactions.
+	current *parallelTxn
+	// serial is true while the vplayer applies the current transaction on
+	// its own connection.
+	serial bool
+	// last is the last transaction handed to the workers, nil once it is
+	// committed and the vplayer is in sync.
+	last *parallelTxn
+	// seq is the sequence number of the last transaction handed to the
+	// workers.
+	seq int64
+	// lastEmpty is the last empty transaction received after last.
+	lastEmpty *parallelTxn
+	// rowWriters are the last transactions in flight writing the rows of
+	// the write set keys, tableWriters the last ones writing whole tables,
+	// and tableTouches the last ones writing rows of the tables.
+	rowWriters   map[string]*parallelTxn
+	tableWriters map[string]*parallelTxn
+	tableTouches map[string]*parallelTxn
+}
+
+// parallelTxn is a transaction applied by the parallelApplier.
+type parallelTxn struct {
+	seq int64
+	// events are the events of the transaction, in case it has to be
+	// applied by the vplayer.
+	events    []*binlogdatapb.VEvent
+	rowEvents []*binlogdatapb.RowEvent
+	plans     []*TablePlan
+	// rowKeys are the write set keys of the rows of the transaction, and
+	// tables the tables written without primary key references.
+	rowKeys   []string
+	tables    []string
+	pos       replication.Position
+	hasPos    bool
+	commit    *binlogdatapb.VEvent
+	timestamp int64
+
+	// dependency is the last transaction before this one whose write set
+	// intersects its own, and predecessor the transaction before this one.
+	dependency  *parallelTxn
+	predecessor *parallelTxn
+
+	// done is closed once the transaction is committed, or has failed with err.
+	done chan struct{}
+	err  error
+	// restarted is closed when the transaction is rolled back after its
+	// statements were applied, for the transactions after it to roll back.
+	restarted   chan struct{}
+	restartOnce sync.Once
+}
+
+// parallelApplyWorker applies transactions on its own connection.
+type parallelApplyWorker struct {
+	vp               *vplayer
+	dbClient         *vdbClient
+	foreignKeyChecks foreignKeyChecksState
+}
+
+func newParallelApplier(ctx context.Context, vp *vplayer, workers int) (*parallelApplier, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	pa := &parallelApplier{
+		vp:            vp,
+		cancel:        cancel,
+		txns:          make(chan *parallelTxn, workers),
+		timeLastSaved: vp.timeLastSaved,
+		rowWriters:    make(map[string]*parallelTxn),
+		tableWriters:  make(map[string]*parallelTxn),
+		tableTouches:  make(map[string]*parallelTxn),
+	}
+	for i := 0; i < workers; i++ {
+		dbClient, err := vp.vr.newClientConnection(ctx)
+		if err != nil {
+			pa.close()
+			return nil, fmt.Errorf("failed to create the connections to apply transactions in parallel: %w", err)
+		}
+		pa.workers = append(pa.workers, &parallelApplyWorker{vp: vp, dbClient: dbClient})
+	}
+	for _, worker := range pa.workers {
+		pa.wg.Add(1)
+		go func(worker *parallelApplyWorker) {
+			defer pa.wg.Done()
+			for txn := range pa.txns {
+				worker.apply(ctx, pa, txn)
+			}
+		}(worker)
+	}
+	log.Infof("VReplication player id: %v applies transactions on %d connections", vp.vr.id, workers)
+	return pa, nil
+}
+
+// close stops the workers and closes their connections.
+func (pa *parallelApplier) close() {
+	pa.cancel()
+	close(pa.txns)
+	pa.wg.Wait()
+	for _, worker := range pa.workers {
+		_ = worker.dbClient.Rollback()
+		worker.dbClient.Close()
+	}
+}
+
+// addEvent takes the event if it is part of a transaction applied in
+// parallel. Otherwise, the vplayer has to apply it, and the transactions in
+// flight are committed first if the event needs it.
+func (pa *parallelApplier) addEvent(ctx context.Context, event *binlogdatapb.VEvent) (handled bool, err error) {
+	if pa.serial {
+		if event.Type == binlogdatapb.VEventType_COMMIT {
+			pa.serial = false
+		}
+		return false, nil
+	}
+	switch event.Type {
+	case binlogdatapb.VEventType_BEGIN:
+		if pa.current == nil {
+			pa.current = &parallelTxn{}
+		}
+		pa.current.events = append(pa.current.events, event)
+		return true, nil
+	case binlogdatapb.VEventType_GTID, binlogdatapb.VEventType_FIELD, binlogdatapb.VEventType_ROW, binlogdatapb.VEventType_COMMIT:
+		if pa.current == nil {
+			return false, pa.drain(ctx)
+		}
+		txn := pa.current
+		txn.events = append(txn.events, event)
+		if event.Type == binlogdatapb.VEventType_COMMIT {
+			pa.current = nil
+			txn.commit = event
+			txn.timestamp = event.Timestamp
+			return true, pa.dispatch(ctx, txn)
+		}
+		if err := pa.addTxnEvent(ctx, txn, event); err != nil {
+			pa.vp.recordApplyError(event, err)
+			return true, err
+		}
+		return true, nil
+	case binlogdatapb.VEventType_INSERT, binlogdatapb.VEventType_DELETE, binlogdatapb.VEventType_UPDATE,
+		binlogdatapb.VEventType_REPLACE, binlogdatapb.VEventType_SAVEPOINT,
+		binlogdatapb.VEventType_OTHER, binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_JOURNAL:
+		if pa.current != nil {
+			return false, pa.applySerially(ctx)
+		}
+		return false, pa.drain(ctx)
+	}
+	// The other events, e.g. heartbeats, do not touch the position.
+	return false, nil
+}
+
+// addTxnEvent adds the GTID, FIELD or ROW event to the transaction.
+func (pa *parallelApplier) addTxnEvent(ctx context.Context, txn *parallelTxn, event *binlogdatapb.VEvent) error {
+	switch event.Type {
+	case binlogdatapb.VEventType_GTID:
+		pos, err := binlogplayer.DecodePosition(event.Gtid)
+		if err != nil {
+			return err
+		}
+		txn.pos = pos
+		txn.hasPos = true
+	case binlogdatapb.VEventType_FIELD:
+		tplan, err := pa.vp.replicatorPlan.buildExecutionPlan(event.FieldEvent)
+		if err != nil {
+			return err
+		}
+		pa.vp.setSourceRowStreamer(ctx, tplan)
+		pa.vp.tablePlans[event.FieldEvent.TableName] = tplan
+		NewVrLogStats(event.Type.String()).Send(fmt.Sprintf("%v", event.FieldEvent))
+	case binlogdatapb.VEventType_ROW:
+		tplan := pa.vp.tablePlans[event.RowEvent.TableName]
+		if tplan == nil {
+			return fmt.Errorf("unexpected event on table %s", event.RowEvent.TableName)
+		}
+		txn.rowEvents = append(txn.rowEvents, event.RowEvent)
+		txn.plans = append(txn.plans, tplan)
+		pkIndices := writeSetPKIndices(tplan)
+		if len(pkIndices) == 0 {
+			txn.tables = append(txn.tables, tplan.TargetName)
+			break
+		}
+		for _, change := range event.RowEvent.RowChanges {
+			for _, row := range []*querypb.Row{change.Before, change.After} {
+				if row != nil {
+					txn.rowKeys = append(txn.rowKeys, writeSetKey(tplan, pkIndices, row))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dispatch hands the transaction to the workers.
+func (pa *parallelApplier) dispatch(ctx context.Context, txn *parallelTxn) error {
+	if !txn.hasPos {
+		// Unreachable: the position of a transaction is sent before its commit.
+		if err := pa.drain(ctx); err != nil {
+			return err
+		}
+		return pa.applyEventsSerially(ctx, txn.events)
+	}
+	if len(txn.rowEvents) == 0 {
+		// An empty transaction only moves the position, which is saved
+		// along with the next transaction, or on inactivity.
+		pa.lastEmpty = txn
+		return nil
+	}
+	if err := pa.getErr(); err != nil {
+		return err
+	}
+	if len(pa.rowWriters) > parallelApplyMaxWriteSetSize {
+		if err := pa.drain(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range txn.rowKeys {
+		table, _, _ := strings.Cut(key, "\x00")
+		txn.dependOn(pa.rowWriters[key])
+		txn.dependOn(pa.tableWriters[table])
+	}
+	for _, table := range txn.tables {
+		txn.dependOn(pa.tableTouches[table])
+	}
+	for _, key := range txn.rowKeys {
+		table, _, _ := strings.Cut(key, "\x00")
+		pa.rowWriters[key] = txn
+		pa.tableTouches[table] = txn
+	}
+	for _, table := range txn.tables {
+		pa.tableWriters[table] = txn
+		pa.tableTouches[table] = txn
+	}
+	pa.seq++
+	txn.seq = pa.seq
+	txn.predecessor = pa.last
+	txn.done = make(chan struct{})
+	txn.restarted = make(chan struct{})
+	pa.last = txn
+	pa.lastEmpty = nil
+
+	select {
+	case pa.txns <- txn:
+		return nil
+	case <-ctx.Done():
+		return io.EOF
+	}
+}
+
+// endOfFetch is called once the events of a fetch of the relay log are
+// added. A transaction which does not fit in a fetch is applied by the
+// vplayer, so that its events do not have to be held in memory.
+func (pa *parallelApplier) endOfFetch(ctx context.Context) error {
+	if pa.current != nil {
+		return pa.applySerially(ctx)
+	}
+	if pa.last != nil {
+		select {
+		case <-pa.last.done:
+		default:
+			return nil
+		}
+	}
+	return pa.drain(ctx)
+}
+
+// applySerially applies the events received so far of the current
+// transaction on the connection of the vplayer, which applies the rest of the
+// transaction.
+func (pa *parallelApplier) applySerially(ctx context.Context) error {
+	if err := pa.drain(ctx); err != nil {
+		return err
+	}
+	events := pa.current.events
+	pa.current = nil
+	pa.serial = true
+	return pa.applyEventsSerially(ctx, events)
+}
+
+func (pa *parallelApplier) applyEventsSerially(ctx context.Context, events []*binlogdatapb.VEvent) error {
+	for _, event := range events {
+		if err := pa.vp.applyEvent(ctx, event, false); err != nil {
+			pa.vp.recordApplyError(event, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// drain waits for the transactions in flight to be committed, and brings
+// the position of the vplayer up to date.
+func (pa *parallelApplier) drain(ctx context.Context) error {
+	if pa.last != nil {
+		select {
+		case <-pa.last.done:
+		case <-ctx.Done():
+			return io.EOF
+		}
+	}
+	if err := pa.getErr(); err != nil {
+		return err
+	}
+	vp := pa.vp
+	if pa.last != nil {
+		vp.pos = pa.last.pos
+		vp.unsavedEvent = nil
+		vp.numAccumulatedHeartbeats = 0
+	}
+	if pa.lastEmpty != nil {
+		vp.pos = pa.lastEmpty.pos
+		vp.unsavedEvent = pa.lastEmpty.commit
+	}
+	pa.mu.Lock()
+	if pa.timeLastSaved.After(vp.timeLastSaved) {
+		vp.timeLastSaved = pa.timeLastSaved
+	}
+	pa.mu.Unlock()
+	pa.last = nil
+	pa.lastEmpty = nil
+	clear(pa.rowWriters)
+	clear(pa.tableWriters)
+	clear(pa.tableTouches)
+	return nil
+}
+
+func (pa *parallelApplier) getErr() error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.err
+}
+
+func (pa *parallelApplier) setErr(err error) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	if pa.err == nil {
+		pa.err = err
+	}
+}
+
+func (pa *parallelApplier) recordSaved(pos replication.Position) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.timeLastSaved = time.Now()
+	pa.vp.vr.stats.SetLastPosition(pos)
+}
+
+// dependOn makes the transaction wait for other, if it is a later dependency.
+func (txn *parallelTxn) dependOn(other *parallelTxn) {
+	if other == nil {
+		return
+	}
+	if txn.dependency == nil || txn.dependency.seq < other.seq {
+		txn.dependency = other
+	}
+}
+
+func (txn *parallelTxn) restart() {
+	txn.restartOnce.Do(func() {
+		close(txn.restarted)
+	})
+}
+
+// waitCommitted waits for the transaction, if any, to be committed.
+func waitCommitted(ctx context.Context, txn *parallelTxn) error {
+	if txn == nil {
+		return nil
+	}
+	select {
+	case <-txn.done:
+		if txn.err != nil {
+			return errParallelApplyAborted
+		}
+		return nil
+	case <-ctx.Done():
+		return io.EOF
+	}
+}
+
+// isCommitted returns true if the transaction, if any, is committed.
+func isCommitted(txn *parallelTxn) bool {
+	if txn == nil {
+		return true
+	}
+	select {
+	case <-txn.done:
+		return txn.err == nil
+	default:
+		return false
+	}
+}
+
+// waitPredecessor waits for the transaction, if any, to be committed, and
+// returns true if it was rolled back to be applied again instead.
+func waitPredecessor(ctx context.Context, txn *parallelTxn) (restarted bool, err error) {
+	if txn == nil {
+		return false, nil
+	}
+	select {
+	case <-txn.done:
+		// A transaction can be restarted, and then committed.
+		return false, waitCommitted(ctx, txn)
+	default:
+	}
+	select {
+	case <-txn.done:
+		return false, waitCommitted(ctx, txn)
+	case <-txn.restarted:
+		return true, nil
+	case <-ctx.Done():
+		return false, io.EOF
+	}
+}
+
+func (w *parallelApplyWorker) apply(ctx context.Context, pa *parallelApplier, txn *parallelTxn) {
+	defer close(txn.done)
+	table, err := w.applyTxn(ctx, pa, txn)
+	if err == nil {
+		for _, rowEvent := range txn.rowEvents {
+			w.vp.vr.stats.TableRowsApplied.Add(rowEvent.TableName, int64(len(rowEvent.RowChanges)))
+		}
+		return
+	}
+	_ = w.dbClient.Rollback()
+	// Let the transactions after this one roll back.
+	txn.restart()
+	txn.err = err
+	if err != errParallelApplyAborted && err != io.EOF {
+		w.vp.recordTableApplyError(table, err)
+		pa.setErr(err)
+	}
+}
+
+// applyTxn applies and commits the transaction, and returns the table of
+// the row event which failed, if any.
+func (w *parallelApplyWorker) applyTxn(ctx context.Context, pa *parallelApplier, txn *parallelTxn) (string, error) {
+	if err := waitCommitted(ctx, txn.dependency); err != nil {
+		return "", err
+	}
+	for retry := false; ; retry = true {
+		table, err := w.execute(ctx, txn, retry)
+		if err == nil {
+			restarted, err := waitPredecessor(ctx, txn.predecessor)
+			if err != nil {
+				return "", err
+			}
+			if !restarted {
+				break
+			}
+		} else if retry || (isCommitted(txn.predecessor) && !isLockError(err)) {
+			return table, err
+		}
+		// The transaction may hold locks the transactions before it wait
+		// for, or wait for the locks of the transactions after it.
+		if err := w.dbClient.Rollback(); err != nil {
+			return "", err
+		}
+		txn.restart()
+		if err := waitCommitted(ctx, txn.predecessor); err != nil {
+			return "", err
+		}
+	}
+
+	update := binlogplayer.GenerateUpdatePos(w.vp.vr.id, txn.pos, time.Now().Unix(), txn.timestamp, w.vp.vr.stats.CopyRowCount.Get(), vreplicationStoreCompressedGTID)
+	if _, err := w.dbClient.Execute(update); err != nil {
+		return "", fmt.Errorf("error %v updating position", err)
+	}
+	if err := w.dbClient.Commit(); err != nil {
+		return "", err
+	}
+	pa.recordSaved(txn.pos)
+	return "", nil
+}
+
+// execute applies the statements of the transaction, without committing it.
+// Once the transactions before it are committed, the lock errors are retried.
+func (w *parallelApplyWorker) execute(ctx context.Context, txn *parallelTxn, retry bool) (string, error) {
+	w.dbClient.queries = nil
+	if err := w.dbClient.Begin(); err != nil {
+		return "", err
+	}
+	query := func(ctx context.Context, sql string) (*sqltypes.Result, error) {
+		if retry {
+			return w.dbClient.ExecuteWithRetry(ctx, sql)
+		}
+		return w.dbClient.Execute(sql)
+	}
+	applyFunc := func(sql string) (*sqltypes.Result, error) {
+		stats := NewVrLogStats("ROWCHANGE")
+		start := time.Now()
+		qr, err := query(ctx, sql)
+		w.vp.vr.stats.QueryCount.Add(w.vp.phase, 1)
+		w.vp.vr.stats.QueryTimings.Record(w.vp.phase, start)
+		stats.Send(sql)
+		return qr, err
+	}
+	for i, rowEvent := range txn.rowEvents {
+		if err := w.vp.updateSessionFKCheck(ctx, rowEvent.Flags, query, &w.foreignKeyChecks); err != nil {
+			return rowEvent.TableName, err
+		}
+		for _, change := range rowEvent.RowChanges {
+			if _, err := txn.plans[i].applyChange(change, applyFunc); err != nil {
+				return rowEvent.TableName, err
+			}
+		}
+	}
+	return "", nil
+}
+
+func isLockError(err error) bool {
+	sqlErr, ok := err.(*sqlerror.SQLError)
+	return ok && (sqlErr.Number() == sqlerror.ERLockDeadlock || sqlErr.Number() == sqlerror.ERLockWaitTimeout)
+}
+
+// writeSetPKIndices returns the indices of the fields of the plan referenced
+// by the primary key of the target table.
+func writeSetPKIndices(tplan *TablePlan) []int {
+	var indices []int
+	for i, field := range tplan.Fields {
+		for _, ref := range tplan.PKReferences {
+			if field.Name == ref {
+				indices = append(indices, i)
+				break
+			}
+		}
+	}
+	return indices
+}
+
+// writeSetKey returns the write set key of the row: its target table and the
+// values of the fields referenced by the primary key. The text values are
+// lower cased, since most collations are case insensitive.
+func writeSetKey(tplan *TablePlan, pkIndices []int, row *querypb.Row) string {
+	vals := sqltypes.MakeRowTrusted(tplan.Fields, row)
+	var key strings.Builder
+	key.WriteString(tplan.TargetName)
+	for _, i := range pkIndices {
+		key.WriteByte(0)
+		if vals[i].IsNull() {
+			key.WriteString("NULL")
+			continue
+		}
+		value := vals[i].ToString()
+		if vals[i].IsText() {
+			value = strings.ToLower(strings.TrimRight(value, " "))
+		}
+		fmt.Fprintf(&key, "%d:%s", len(value), value)
+	}
+	return key.String()
+}
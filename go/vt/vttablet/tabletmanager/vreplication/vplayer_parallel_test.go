@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestWriteSetKey(t *testing.T) {
+	tplan := &TablePlan{
+		TargetName: "t1",
+		Fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "val", Type: querypb.Type_VARBINARY},
+			{Name: "name", Type: querypb.Type_VARCHAR},
+		},
+		PKReferences: []string{"id", "name"},
+	}
+	pkIndices := writeSetPKIndices(tplan)
+	require.Equal(t, []int{0, 2}, pkIndices)
+
+	key := func(values ...sqltypes.Value) string {
+		return writeSetKey(tplan, pkIndices, sqltypes.RowToProto3(values))
+	}
+	require.Equal(t, key(sqltypes.NewInt64(1), sqltypes.NewVarBinary("a"), sqltypes.NewVarChar("Ab ")),
+		key(sqltypes.NewInt64(1), sqltypes.NewVarBinary("b"), sqltypes.NewVarChar("ab")))
+	require.NotEqual(t, key(sqltypes.NewInt64(1), sqltypes.NULL, sqltypes.NewVarChar("ab")),
+		key(sqltypes.NewInt64(2), sqltypes.NULL, sqltypes.NewVarChar("ab")))
+	require.NotEqual(t, key(sqltypes.NewInt64(1), sqltypes.NULL, sqltypes.NULL),
+		key(sqltypes.NewInt64(1), sqltypes.NULL, sqltypes.NewVarChar("NULL")))
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package authz consults an external authorization service, such as Open
+// Policy Agent or a custom gRPC service, before the tablet executes a query.
+package authz
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/cache"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Authorizer is the client of an external authorization service.
+type Authorizer interface {
+	// Authorize returns the decision of the service for the request.
+	Authorize(ctx context.Context, req *authzpb.AuthorizeRequest) (*authzpb.AuthorizeResponse, error)
+
+	// Close releases the resources of the client.
+	Close() error
+}
+
+// NewAuthorizer returns the client of the service at address: an OPA
+// client for http(s) URLs, a gRPC client otherwise.
+func NewAuthorizer(config tabletenv.ExternalAuthzConfig) (Authorizer, error) {
+	if strings.HasPrefix(config.Address, "http://") || strings.HasPrefix(config.Address, "https://") {
+		return newOPAAuthorizer(config)
+	}
+	return newGRPCAuthorizer(config)
+}
+
+// decision is a cached decision of the service.
+type decision struct {
+	allowed bool
+	reason  string
+	expires time.Time
+}
+
+// Checker checks the queries against an external authorization service.
+// It caches the decisions of the service and applies the fail-open or
+// fail-closed policy when the service cannot be reached.
+type Checker struct {
+	newAuthorizer func() (Authorizer, error)
+
+	// mu protects authorizer, which is nil while the Checker is closed.
+	mu         sync.Mutex
+	authorizer Authorizer
+
+	timeout  time.Duration
+	cacheTTL time.Duration
+	failOpen bool
+
+	// cache is nil when the decisions are not cached.
+	cache *cache.LRUCache[*decision]
+
+	results *stats.CountersWithSingleLabel
+	logger  *logutil.ThrottledLogger
+}
+
+// New creates a closed Checker for the service configured in the tablet
+// config. It returns nil if no service is configured.
+func New(env tabletenv.Env) *Checker {
+	config := env.Config().ExternalAuthz
+	if config.Address == "" {
+		return nil
+	}
+	return newChecker(env, func() (Authorizer, error) {
+		return NewAuthorizer(config)
+	})
+}
+
+// NewChecker creates an open Checker consulting authorizer.
+func NewChecker(env tabletenv.Env, authorizer Authorizer) *Checker {
+	c := newChecker(env, func() (Authorizer, error) {
+		return authorizer, nil
+	})
+	c.authorizer = authorizer
+	return c
+}
+
+func newChecker(env tabletenv.Env, newAuthorizer func() (Authorizer, error)) *Checker {
+	config := env.Config().ExternalAuthz
+	c := &Checker{
+		newAuthorizer: newAuthorizer,
+		timeout:       config.Timeout,
+		cacheTTL:      config.CacheTTL,
+		failOpen:      config.FailOpen,
+		results: env.Exporter().NewCountersWithSingleLabel(
+			"ExternalAuthzResults",
+			"Decisions of the external authorization service, by result",
+			"Result",
+			"Allowed", "Denied", "CachedAllowed", "CachedDenied", "FailedOpen", "FailedClosed",
+		),
+		logger: logutil.NewThrottledLogger("ExternalAuthz", 5*time.Second),
+	}
+	if c.cacheTTL > 0 && config.CacheSize > 0 {
+		c.cache = cache.NewLRUCache[*decision](int64(config.CacheSize))
+	}
+	return c
+}
+
+// Open creates the client of the service, if the Checker is closed.
+func (c *Checker) Open() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authorizer != nil {
+		return nil
+	}
+	authorizer, err := c.newAuthorizer()
+	if err != nil {
+		return err
+	}
+	c.authorizer = authorizer
+	return nil
+}
+
+// Close closes the client of the service. The Checker can be reopened.
+func (c *Checker) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authorizer == nil {
+		return
+	}
+	if err := c.authorizer.Close(); err != nil {
+		c.logger.Warningf("Cannot close the external authorization client: %v", err)
+	}
+	c.authorizer = nil
+}
+
+// Check returns a PERMISSION_DENIED error if the service denies the
+// request. If the service fails, the request is allowed in fail-open
+// mode, and an UNAVAILABLE error is returned otherwise.
+func (c *Checker) Check(ctx context.Context, req *authzpb.AuthorizeRequest) error {
+	key := cacheKey(req)
+	if c.cache != nil {
+		if d, ok := c.cache.Get(key); ok && time.Now().Before(d.expires) {
+			if d.allowed {
+				c.results.Add("CachedAllowed", 1)
+				return nil
+			}
+			c.results.Add("CachedDenied", 1)
+			return deniedError(req, d.reason)
+		}
+	}
+
+	c.mu.Lock()
+	authorizer := c.authorizer
+	c.mu.Unlock()
+	if authorizer == nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "external authorization is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := authorizer.Authorize(ctx, req)
+	if err != nil {
+		// Errors are not cached, so that the service is consulted again
+		// as soon as it recovers.
+		c.logger.Warningf("External authorization of %s by user '%s' failed: %v", req.StatementType, req.GetImmediateCallerId().GetUsername(), err)
+		if c.failOpen {
+			c.results.Add("FailedOpen", 1)
+			return nil
+		}
+		c.results.Add("FailedClosed", 1)
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "external authorization failed: %v", err)
+	}
+
+	if c.cache != nil {
+		c.cache.Set(key, &decision{
+			allowed: resp.Allowed,
+			reason:  resp.Reason,
+			expires: time.Now().Add(c.cacheTTL),
+		})
+	}
+	if !resp.Allowed {
+		c.results.Add("Denied", 1)
+		err := deniedError(req, resp.Reason)
+		c.logger.Infof("%v", err)
+		return err
+	}
+	c.results.Add("Allowed", 1)
+	return nil
+}
+
+func deniedError(req *authzpb.AuthorizeRequest, reason string) error {
+	if reason == "" {
+		reason = "no reason given"
+	}
+	return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "%s command denied to user '%s' by the external authorization service: %s", req.StatementType, req.GetImmediateCallerId().GetUsername(), reason)
+}
+
+// cacheKey returns the key of the decisions for the callers, statement type
+// and tables of req.
+func cacheKey(req *authzpb.AuthorizeRequest) string {
+	var b strings.Builder
+	immediate := req.GetImmediateCallerId()
+	effective := req.GetEffectiveCallerId()
+	for _, s := range []string{
+		immediate.GetUsername(),
+		strings.Join(immediate.GetGroups(), ","),
+		effective.GetPrincipal(),
+		effective.GetComponent(),
+		effective.GetSubcomponent(),
+		strings.Join(effective.GetGroups(), ","),
+		req.GetTarget().GetTabletType().String(),
+		req.StatementType,
+	} {
+		b.WriteString(s)
+		b.WriteByte('\x00')
+	}
+	for _, table := range req.Tables {
+		b.WriteString(table.Role)
+		b.WriteByte(':')
+		b.WriteString(table.Name)
+		b.WriteByte('\x00')
+	}
+	return b.String()
+}
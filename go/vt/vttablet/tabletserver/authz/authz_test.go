@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+type fakeAuthorizer struct {
+	calls   int
+	closed  int
+	allowed map[string]bool
+	err     error
+}
+
+func (fa *fakeAuthorizer) Authorize(ctx context.Context, req *authzpb.AuthorizeRequest) (*authzpb.AuthorizeResponse, error) {
+	fa.calls++
+	if fa.err != nil {
+		return nil, fa.err
+	}
+	username := req.GetImmediateCallerId().GetUsername()
+	if !fa.allowed[username] {
+		return &authzpb.AuthorizeResponse{Reason: "not in the policy"}, nil
+	}
+	return &authzpb.AuthorizeResponse{Allowed: true}, nil
+}
+
+func (fa *fakeAuthorizer) Close() error {
+	fa.closed++
+	return nil
+}
+
+func newTestChecker(authorizer Authorizer, configure func(config *tabletenv.ExternalAuthzConfig)) *Checker {
+	cfg := tabletenv.NewDefaultConfig()
+	configure(&cfg.ExternalAuthz)
+	return NewChecker(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "ExternalAuthzTest"), authorizer)
+}
+
+func newTestRequest(username, statementType string) *authzpb.AuthorizeRequest {
+	return &authzpb.AuthorizeRequest{
+		ImmediateCallerId: &querypb.VTGateCallerID{Username: username},
+		StatementType:     statementType,
+		Tables:            []*authzpb.TableAccess{{Name: "t", Role: "READER"}},
+	}
+}
+
+func TestCheckerCache(t *testing.T) {
+	ctx := context.Background()
+	fa := &fakeAuthorizer{allowed: map[string]bool{"u1": true}}
+	c := newTestChecker(fa, func(config *tabletenv.ExternalAuthzConfig) {})
+
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+	assert.Equal(t, 1, fa.calls)
+
+	err := c.Check(ctx, newTestRequest("u2", "Select"))
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	assert.ErrorContains(t, err, "Select command denied to user 'u2' by the external authorization service: not in the policy")
+	// Denials are cached too.
+	err = c.Check(ctx, newTestRequest("u2", "Select"))
+	assert.ErrorContains(t, err, "not in the policy")
+	assert.Equal(t, 2, fa.calls)
+
+	// Another statement type is a different decision.
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Insert")))
+	assert.Equal(t, 3, fa.calls)
+
+	assert.Equal(t, map[string]int64{"Allowed": 2, "Denied": 1, "CachedAllowed": 1, "CachedDenied": 1}, c.results.Counts())
+
+	// Expired decisions are not used.
+	c.cacheTTL = time.Nanosecond
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Delete")))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Delete")))
+	assert.Equal(t, 5, fa.calls)
+}
+
+func TestCheckerNoCache(t *testing.T) {
+	ctx := context.Background()
+	fa := &fakeAuthorizer{allowed: map[string]bool{"u1": true}}
+	c := newTestChecker(fa, func(config *tabletenv.ExternalAuthzConfig) {
+		config.CacheTTL = 0
+	})
+
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+	assert.Equal(t, 2, fa.calls)
+}
+
+func TestCheckerFailure(t *testing.T) {
+	ctx := context.Background()
+	fa := &fakeAuthorizer{err: errors.New("connection refused")}
+
+	c := newTestChecker(fa, func(config *tabletenv.ExternalAuthzConfig) {})
+	err := c.Check(ctx, newTestRequest("u1", "Select"))
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.ErrorContains(t, err, "external authorization failed: connection refused")
+	// Failures are not cached.
+	err = c.Check(ctx, newTestRequest("u1", "Select"))
+	assert.Error(t, err)
+	assert.Equal(t, 2, fa.calls)
+
+	c = newTestChecker(fa, func(config *tabletenv.ExternalAuthzConfig) {
+		config.FailOpen = true
+	})
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+	assert.EqualValues(t, 1, c.results.Counts()["FailedOpen"])
+}
+
+func TestCheckerOpenClose(t *testing.T) {
+	ctx := context.Background()
+	fa := &fakeAuthorizer{allowed: map[string]bool{"u1": true}}
+	created := 0
+	cfg := tabletenv.NewDefaultConfig()
+	c := newChecker(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "ExternalAuthzTest"), func() (Authorizer, error) {
+		created++
+		return fa, nil
+	})
+
+	// A closed Checker denies the queries without consulting the service.
+	err := c.Check(ctx, newTestRequest("u1", "Select"))
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Equal(t, 0, fa.calls)
+
+	require.NoError(t, c.Open())
+	require.NoError(t, c.Open())
+	assert.Equal(t, 1, created)
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Select")))
+
+	c.Close()
+	c.Close()
+	assert.Equal(t, 1, fa.closed)
+
+	// The client is created again when the Checker is reopened.
+	require.NoError(t, c.Open())
+	assert.Equal(t, 2, created)
+	require.NoError(t, c.Check(ctx, newTestRequest("u1", "Insert")))
+	c.Close()
+	assert.Equal(t, 2, fa.closed)
+}
+
+func TestOPAAuthorizer(t *testing.T) {
+	var result string
+	var input map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input map[string]any `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		input = body.Input
+		w.Write([]byte(result))
+	}))
+	defer server.Close()
+
+	authorizer, err := NewAuthorizer(tabletenv.ExternalAuthzConfig{Address: server.URL + "/v1/data/vitess/allow"})
+	require.NoError(t, err)
+	defer authorizer.Close()
+	ctx := context.Background()
+
+	result = `{"result": true}`
+	resp, err := authorizer.Authorize(ctx, newTestRequest("u1", "Select"))
+	require.NoError(t, err)
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, map[string]any{
+		"immediate_caller_id": map[string]any{"username": "u1"},
+		"statement_type":      "Select",
+		"tables":              []any{map[string]any{"name": "t", "role": "READER"}},
+	}, input)
+
+	result = `{"result": {"allow": false, "reason": "read only"}}`
+	resp, err = authorizer.Authorize(ctx, newTestRequest("u1", "Insert"))
+	require.NoError(t, err)
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, "read only", resp.Reason)
+
+	// An undefined decision denies the request.
+	result = `{}`
+	resp, err = authorizer.Authorize(ctx, newTestRequest("u1", "Insert"))
+	require.NoError(t, err)
+	assert.False(t, resp.Allowed)
+
+	result = `{"result": "yes"}`
+	_, err = authorizer.Authorize(ctx, newTestRequest("u1", "Insert"))
+	assert.ErrorContains(t, err, "cannot parse the OPA decision")
+}
+
+func TestOPAAuthorizerTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// The certificate of the server is not trusted without its CA.
+	authorizer, err := NewAuthorizer(tabletenv.ExternalAuthzConfig{Address: server.URL})
+	require.NoError(t, err)
+	defer authorizer.Close()
+	_, err = authorizer.Authorize(ctx, newTestRequest("u1", "Select"))
+	assert.ErrorContains(t, err, "certificate")
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	authorizer, err = NewAuthorizer(tabletenv.ExternalAuthzConfig{Address: server.URL, CA: ca})
+	require.NoError(t, err)
+	defer authorizer.Close()
+	resp, err := authorizer.Authorize(ctx, newTestRequest("u1", "Select"))
+	require.NoError(t, err)
+	assert.True(t, resp.Allowed)
+
+	_, err = NewAuthorizer(tabletenv.ExternalAuthzConfig{Address: server.URL, CA: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
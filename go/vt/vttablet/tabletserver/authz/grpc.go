@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
+)
+
+// grpcAuthorizer is the client of a gRPC service implementing
+// authz.Authorizer.
+type grpcAuthorizer struct {
+	cc     *grpc.ClientConn
+	client authzpb.AuthorizerClient
+}
+
+func newGRPCAuthorizer(config tabletenv.ExternalAuthzConfig) (*grpcAuthorizer, error) {
+	opt, err := grpcclient.SecureDialOption(config.Cert, config.Key, config.CA, "", config.ServerName, nil)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := grpcclient.DialContext(context.Background(), config.Address, grpcclient.FailFast(false), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcAuthorizer{
+		cc:     cc,
+		client: authzpb.NewAuthorizerClient(cc),
+	}, nil
+}
+
+// Authorize is part of the Authorizer interface.
+func (ga *grpcAuthorizer) Authorize(ctx context.Context, req *authzpb.AuthorizeRequest) (*authzpb.AuthorizeResponse, error) {
+	return ga.client.Authorize(ctx, req)
+}
+
+// Close is part of the Authorizer interface.
+func (ga *grpcAuthorizer) Close() error {
+	return ga.cc.Close()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttls"
+
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
+)
+
+// opaAuthorizer queries a decision of Open Policy Agent through its data
+// API. The request is the input of the decision, e.g.:
+//
+//	{"input": {"immediate_caller_id": {"username": "app"}, "statement_type": "Select", "tables": [{"name": "t", "role": "READER"}]}}
+//
+// The result of the decision is either a boolean, or an object with an
+// "allow" boolean and an optional "reason" string. An undefined decision
+// denies the request.
+type opaAuthorizer struct {
+	url    string
+	client *http.Client
+}
+
+// newOPAAuthorizer returns the client of the OPA decision at the address of
+// config. The TLS options of config apply to https addresses.
+func newOPAAuthorizer(config tabletenv.ExternalAuthzConfig) (*opaAuthorizer, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if strings.HasPrefix(config.Address, "https://") {
+		tlsConfig, err := vttls.ClientConfig(vttls.VerifyIdentity, config.Cert, config.Key, config.CA, "", config.ServerName, tls.VersionTLS12)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &opaAuthorizer{
+		url:    config.Address,
+		client: &http.Client{Transport: transport},
+	}, nil
+}
+
+type opaResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+type opaResult struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// Authorize is part of the Authorizer interface.
+func (oa *opaAuthorizer) Authorize(ctx context.Context, req *authzpb.AuthorizeRequest) (*authzpb.AuthorizeResponse, error) {
+	input, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]json.RawMessage{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, oa.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := oa.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA returned %s: %s", httpResp.Status, bytes.TrimSpace(data))
+	}
+
+	var resp opaResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("cannot parse the OPA response: %v", err)
+	}
+	if len(resp.Result) == 0 {
+		return &authzpb.AuthorizeResponse{Reason: "undefined policy decision"}, nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(resp.Result, &allowed); err == nil {
+		return &authzpb.AuthorizeResponse{Allowed: allowed}, nil
+	}
+	var result opaResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("cannot parse the OPA decision %s: %v", resp.Result, err)
+	}
+	return &authzpb.AuthorizeResponse{Allowed: result.Allow, Reason: result.Reason}, nil
+}
+
+// Close is part of the Authorizer interface.
+func (oa *opaAuthorizer) Close() error {
+	oa.client.CloseIdleConnections()
+	return nil
+}
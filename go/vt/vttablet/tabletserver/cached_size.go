@@ -25,7 +25,7 @@ func (cached *TabletPlan) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(112)
+		size += int64(192)
 	}
 	// field Plan *vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder.Plan
 	size += cached.Plan.CachedSize(true)
@@ -40,5 +40,21 @@ func (cached *TabletPlan) CachedSize(alloc bool) int64 {
 			size += elem.CachedSize(true)
 		}
 	}
+	// field HintRules []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.HintRules)) * int64(16))
+		for _, elem := range cached.HintRules {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	// field DryRunHintRules []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.DryRunHintRules)) * int64(16))
+		for _, elem := range cached.DryRunHintRules {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	// field Digest string
+	size += hack.RuntimeAllocSize(int64(len(cached.Digest)))
 	return size
 }
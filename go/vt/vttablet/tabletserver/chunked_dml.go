@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
+)
+
+// dmlChunker executes the UPDATE and DELETE statements without LIMIT of the
+// tables listed by --dml-chunk-size-tables, or with the DML_CHUNK_SIZE
+// directive, in a series of transactions modifying a chunk of rows each, so
+// that a statement modifying millions of rows neither holds their locks for
+// long nor lags the replicas. The throttler is checked between the chunks.
+//
+// The chunks are ranges of the primary key of the table, see
+// planbuilder.ChunkedDML. The rows modified by the chunks already committed
+// stay modified if a chunk fails.
+type dmlChunker struct {
+	tables    map[string]int64
+	throttler *throttle.Throttler
+
+	chunks    *stats.CountersWithSingleLabel
+	throttled *stats.CountersWithSingleLabel
+}
+
+func newDMLChunker(env tabletenv.Env, throttler *throttle.Throttler) *dmlChunker {
+	// The chunk sizes are checked by the config verification.
+	tables, _ := tabletenv.ParseDMLChunkSizes(env.Config().DMLChunkSizeTables)
+	return &dmlChunker{
+		tables:    tables,
+		throttler: throttler,
+		chunks:    env.Exporter().NewCountersWithSingleLabel("ChunkedDMLChunks", "Number of chunks of rows modified by the UPDATE and DELETE statements executed in chunks", "Table"),
+		throttled: env.Exporter().NewCountersWithSingleLabel("ChunkedDMLThrottled", "Number of times the UPDATE and DELETE statements executed in chunks waited for the throttler", "Table"),
+	}
+}
+
+// dmlChunkSize returns the number of rows of the chunks the statement is
+// executed in, or 0 if it is executed at once. The statements with a version
+// predicate are executed at once, for their version conflicts to be detected.
+func (qre *QueryExecutor) dmlChunkSize() int64 {
+	chunked := qre.plan.ChunkedDML
+	if chunked == nil || qre.plan.VersionPredicate || qre.options.GetIdempotencyToken() != "" {
+		return 0
+	}
+	size := chunked.Size
+	if size == 0 {
+		size = qre.tsv.dmlChunker.tables[qre.plan.TableName().String()]
+	}
+	// The primary keys of the rows of a chunk are fetched at once.
+	return min(size, qre.tsv.qe.maxResultSize.Load())
+}
+
+// execChunkedDML executes the UPDATE or DELETE statement in chunks of size
+// rows, and returns the total number of rows they modified.
+func (qre *QueryExecutor) execChunkedDML(size int64) (*sqltypes.Result, error) {
+	chunked := qre.plan.ChunkedDML
+	table := qre.plan.TableName().String()
+	client := throttle.NewBackgroundClient(qre.tsv.dmlChunker.throttler, throttlerapp.ChunkedDMLName, throttle.ThrottleCheckPrimaryWrite)
+
+	result := &sqltypes.Result{}
+	failed := func(err error) error {
+		return vterrors.Wrapf(err, "%s executed in chunks failed after modifying %d rows", qre.plan.PlanID.String(), result.RowsAffected)
+	}
+	qre.bindVars[planbuilder.ChunkSizeBindVar] = sqltypes.Int64BindVariable(size)
+	bounds, chunk := chunked.FirstBounds, chunked.FirstChunk
+	for {
+		rows, err := qre.execChunkBounds(bounds)
+		if err != nil {
+			return nil, failed(err)
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+		last := rows[len(rows)-1]
+		for i := 0; i < chunked.PKColumns; i++ {
+			qre.bindVars[planbuilder.ChunkUpperBindVar(i)] = sqltypes.ValueBindVariable(last[i])
+		}
+		qr, err := qre.execAsTransaction(func(conn *StatefulConnection) (*sqltypes.Result, error) {
+			return qre.execChunk(conn, chunk)
+		})
+		if err != nil {
+			return nil, failed(err)
+		}
+		result.RowsAffected += qr.RowsAffected
+		qre.tsv.dmlChunker.chunks.Add(table, 1)
+		if int64(len(rows)) < size {
+			return result, nil
+		}
+
+		for i := 0; i < chunked.PKColumns; i++ {
+			qre.bindVars[planbuilder.ChunkLowerBindVar(i)] = sqltypes.ValueBindVariable(last[i])
+		}
+		bounds, chunk = chunked.NextBounds, chunked.NextChunk
+		for !client.ThrottleCheckOKOrWait(qre.ctx) {
+			qre.tsv.dmlChunker.throttled.Add(table, 1)
+			if err := qre.ctx.Err(); err != nil {
+				return nil, failed(err)
+			}
+		}
+	}
+}
+
+// execChunkBounds returns the primary keys of the rows of the next chunk.
+func (qre *QueryExecutor) execChunkBounds(bounds *sqlparser.ParsedQuery) ([][]sqltypes.Value, error) {
+	sql, _, err := qre.generateFinalSQL(bounds, qre.bindVars)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := qre.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+	qr, err := qre.execDBConn(conn.Conn, sql, false)
+	if err != nil {
+		return nil, err
+	}
+	return qr.Rows, nil
+}
+
+// execChunk modifies the rows of a chunk in the transaction of conn.
+func (qre *QueryExecutor) execChunk(conn *StatefulConnection, chunk *sqlparser.ParsedQuery) (*sqltypes.Result, error) {
+	sql, _, err := qre.generateFinalSQL(chunk, qre.bindVars)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	qr, err := qre.execStatefulConn(conn, sql, false)
+	if err != nil {
+		return nil, err
+	}
+	conn.TxProperties().RecordStatement(sql, time.Since(start), qr.RowsAffected)
+	if err := qre.verifyTxSize(conn, qr); err != nil {
+		return nil, err
+	}
+	return qr, nil
+}
@@ -62,6 +62,10 @@ type Conn struct {
 	err   error
 
 	killTimeout time.Duration
+
+	// timeToKill is the TimeToKill histogram of the pool of the connection;
+	// it is nil for connections outside of a named pool.
+	timeToKill *servenv.TimingsWrapper
 }
 
 // NewConnection creates a new DBConn. It triggers a CheckMySQL if creation fails.
@@ -72,6 +76,7 @@ func newPooledConn(ctx context.Context, pool *Pool, appParams dbconfigs.Connecto
 	c, err := dbconnpool.NewDBConnection(ctx, appParams)
 	if err != nil {
 		pool.env.Stats().MySQLTimings.Record("ConnectError", start)
+		pool.recordConnectError(err)
 		pool.env.CheckMySQL()
 		return nil, err
 	}
@@ -81,6 +86,7 @@ func newPooledConn(ctx context.Context, pool *Pool, appParams dbconfigs.Connecto
 		stats:       pool.env.Stats(),
 		dbaPool:     pool.dbaPool,
 		killTimeout: defaultKillTimeout,
+		timeToKill:  pool.timeToKill,
 	}
 	return db, nil
 }
@@ -211,11 +217,23 @@ func (dbc *Conn) terminate(ctx context.Context, insideTxn bool, now time.Time) {
 	default:
 		errMsg = ctx.Err().Error()
 	}
+	// the query timed out at the deadline of the context, which can be a
+	// little earlier than when we noticed it
+	timedOut := time.Now()
+	if deadline, ok := ctx.Deadline(); ok && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		timedOut = deadline
+	}
+	var err error
+	kill := killQuery
 	if insideTxn {
 		// we can't safely kill a query in a transaction, we need to kill the connection
-		_ = dbc.Kill(errMsg, time.Since(now))
+		kill = killConnection
+		err = dbc.Kill(errMsg, time.Since(now))
 	} else {
-		_ = dbc.KillQuery(errMsg, time.Since(now))
+		err = dbc.KillQuery(errMsg, time.Since(now))
+	}
+	if err == nil && dbc.timeToKill != nil {
+		dbc.timeToKill.Record(kill, timedOut)
 	}
 }
 
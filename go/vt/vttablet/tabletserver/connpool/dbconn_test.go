@@ -438,6 +438,40 @@ func TestDBConnStreamCtxError(t *testing.T) {
 	})
 }
 
+func TestDBConnTimeToKill(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	connPool := newPool()
+	params := dbconfigs.New(db.ConnParams())
+	connPool.Open(params, params, params)
+	defer connPool.Close()
+	connPool.timeToKill.Reset()
+
+	query := "sleep"
+	db.AddQuery(query, &sqltypes.Result{})
+	db.SetBeforeFunc(query, func() {
+		time.Sleep(100 * time.Millisecond)
+	})
+	db.AddQueryPattern(`kill query \d+`, &sqltypes.Result{})
+	db.AddQueryPattern(`kill \d+`, &sqltypes.Result{})
+
+	dbConn, err := newPooledConn(context.Background(), connPool, params)
+	require.NoError(t, err)
+	defer dbConn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = dbConn.Exec(ctx, query, 1, false)
+	require.Error(t, err)
+	assert.Equal(t, map[string]int64{"All": 1, "PoolTest.Query": 1}, connPool.timeToKill.Counts())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = dbConn.ExecOnce(ctx, query, 1, false)
+	require.Error(t, err)
+	assert.Equal(t, map[string]int64{"All": 2, "PoolTest.Query": 1, "PoolTest.Connection": 1}, connPool.timeToKill.Counts())
+}
+
 func testContextError(t *testing.T,
 	ctx context.Context,
 	exec func(context.Context, string, *Conn) error,
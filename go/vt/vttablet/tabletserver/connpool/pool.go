@@ -19,12 +19,15 @@ package connpool
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"strings"
 	"time"
 
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/pools/smartconnpool"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/dbconfigs"
@@ -37,6 +40,11 @@ import (
 const (
 	getWithoutS = "GetWithoutSettings"
 	getWithS    = "GetWithSettings"
+
+	// killQuery and killConnection are the labels of the TimeToKill
+	// histogram.
+	killQuery      = "Query"
+	killConnection = "Connection"
 )
 
 type PooledConn = smartconnpool.Pooled[*Conn]
@@ -56,6 +64,8 @@ type Pool struct {
 
 	appDebugParams dbconfigs.Connector
 	getConnTime    *servenv.TimingsWrapper
+	connectErrors  *stats.CountersWithSingleLabel
+	timeToKill     *servenv.TimingsWrapper
 }
 
 // NewPool creates a new Pool. The name is used
@@ -71,6 +81,14 @@ func NewPool(env tabletenv.Env, name string, cfg tabletenv.ConnPoolConfig) *Pool
 		IdleTimeout:     cfg.IdleTimeout,
 		MaxLifetime:     cfg.MaxLifetime,
 		RefreshInterval: mysqlctl.PoolDynamicHostnameResolution,
+		MinCapacity:     int64(cfg.MinSize),
+		GrowInterval:    cfg.GrowInterval,
+		ShrinkInterval:  cfg.ShrinkInterval,
+		ResizeStep:      int64(cfg.ResizeStep),
+	}
+	if tabletConfig := env.Config(); tabletConfig != nil {
+		config.ConnectBackoff = tabletConfig.ConnectBackoff.Initial
+		config.MaxConnectBackoff = tabletConfig.ConnectBackoff.Max
 	}
 
 	if name != "" {
@@ -79,6 +97,8 @@ func NewPool(env tabletenv.Env, name string, cfg tabletenv.ConnPoolConfig) *Pool
 		}
 
 		cp.getConnTime = env.Exporter().NewTimings(name+"GetConnTime", "Tracks the amount of time it takes to get a connection", "Settings")
+		cp.connectErrors = env.Exporter().NewCountersWithSingleLabel(name+"ConnectErrors", "Number of failed attempts to connect to MySQL, by reason", "Reason")
+		cp.timeToKill = env.Exporter().NewTimings(name+"TimeToKill", "Time between the timeout of a query and its successful kill, by whether the query or the connection was killed", "Kill")
 	}
 
 	cp.ConnPool = smartconnpool.NewPool(&config)
@@ -170,6 +190,44 @@ func (cp *Pool) StatsJSON() string {
 	return buf.String()
 }
 
+// recordConnectError counts a failed attempt to connect to MySQL by the
+// reason it failed.
+func (cp *Pool) recordConnectError(err error) {
+	if cp.connectErrors != nil {
+		cp.connectErrors.Add(classifyConnectError(err), 1)
+	}
+}
+
+// classifyConnectError returns the reason of a failed attempt to connect to
+// MySQL, to tell apart a server that is down from one that is overloaded or
+// misconfigured.
+func classifyConnectError(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "Timeout"
+	}
+	var sqlErr *sqlerror.SQLError
+	if !errors.As(err, &sqlErr) {
+		return "Other"
+	}
+	if sqlerror.IsTooManyConnectionsErr(sqlErr) {
+		return "TooManyConnections"
+	}
+	switch sqlErr.Number() {
+	case sqlerror.CRConnectionError, sqlerror.CRConnHostError:
+		return "ConnectionRefused"
+	case sqlerror.CRServerGone, sqlerror.CRServerLost, sqlerror.CRServerHandshakeErr:
+		return "ConnectionLost"
+	case sqlerror.ERServerShutdown:
+		return "ServerShutdown"
+	case sqlerror.ERConCount, sqlerror.ERTooManyUserConnections:
+		return "TooManyConnections"
+	case sqlerror.ERAccessDeniedError, sqlerror.ERDBAccessDenied:
+		return "AccessDenied"
+	default:
+		return "Other"
+	}
+}
+
 func (cp *Pool) isCallerIDAppDebug(ctx context.Context) bool {
 	params, err := cp.appDebugParams.MysqlParams()
 	if err != nil {
@@ -18,6 +18,7 @@ package connpool
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/callerid"
@@ -342,3 +344,83 @@ func newPoolWithCapacity(capacity int) *Pool {
 		IdleTimeout: 10 * time.Second,
 	})
 }
+
+func TestPoolConnectBackoff(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.ConnectBackoff.Initial = time.Hour
+	connPool := NewPool(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "PoolTest"), "BackoffPool", tabletenv.ConnPoolConfig{
+		Size:        10,
+		IdleTimeout: 10 * time.Second,
+	})
+	params := dbconfigs.New(db.ConnParams())
+	connPool.Open(params, params, params)
+	defer connPool.Close()
+
+	db.EnableConnFail()
+	_, err := connPool.Get(context.Background(), nil)
+	require.Error(t, err)
+	assert.Equal(t, smartconnpool.CircuitOpen, connPool.CircuitState())
+	assert.EqualValues(t, map[string]int64{"ConnectionLost": 1}, connPool.connectErrors.Counts())
+
+	// the pool does not connect again while it is backing off
+	db.DisableConnFail()
+	_, err = connPool.Get(context.Background(), nil)
+	assert.ErrorContains(t, err, "connection pool BackoffPool is backing off after 1 failed connection attempts")
+	assert.EqualValues(t, map[string]int64{"ConnectionLost": 1}, connPool.connectErrors.Counts())
+	assert.Error(t, connPool.ConnectError())
+}
+
+func TestPoolElasticSize(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+
+	connPool := NewPool(tabletenv.NewEnv(vtenv.NewTestEnv(), nil, "PoolTest"), "ElasticPool", tabletenv.ConnPoolConfig{
+		Size:           2,
+		IdleTimeout:    10 * time.Second,
+		MinSize:        1,
+		GrowInterval:   20 * time.Millisecond,
+		ShrinkInterval: 40 * time.Millisecond,
+	})
+	params := dbconfigs.New(db.ConnParams())
+	connPool.Open(params, params, params)
+	defer connPool.Close()
+	assert.EqualValues(t, 1, connPool.Capacity())
+
+	dbConn1, err := connPool.Get(context.Background(), nil)
+	require.NoError(t, err)
+
+	// the pool grows for the query that keeps waiting for a connection
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	dbConn2, err := connPool.Get(ctx, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, connPool.Capacity())
+	assert.EqualValues(t, 1, connPool.GrownCount())
+
+	// and shrinks back once the connections are idle
+	dbConn1.Recycle()
+	dbConn2.Recycle()
+	require.Eventually(t, func() bool { return connPool.Capacity() == 1 }, time.Second, time.Millisecond)
+	assert.EqualValues(t, 1, connPool.ShrunkCount())
+}
+
+func TestClassifyConnectError(t *testing.T) {
+	tcs := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, "Timeout"},
+		{sqlerror.NewSQLError(sqlerror.CRConnectionError, sqlerror.SSUnknownSQLState, "connection refused"), "ConnectionRefused"},
+		{sqlerror.NewSQLError(sqlerror.CRServerLost, sqlerror.SSUnknownSQLState, "lost"), "ConnectionLost"},
+		{sqlerror.NewSQLError(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "Too many connections"), "TooManyConnections"},
+		{sqlerror.NewSQLError(sqlerror.ERAccessDeniedError, sqlerror.SSAccessDeniedError, "denied"), "AccessDenied"},
+		{sqlerror.NewSQLError(sqlerror.ERServerShutdown, sqlerror.SSUnknownSQLState, "shutdown"), "ServerShutdown"},
+		{errors.New("boom"), "Other"},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.want, classifyConnectError(tc.err), tc.err.Error())
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -87,11 +88,23 @@ type Controller interface {
 	// BroadcastHealth sends the current health to all listeners
 	BroadcastHealth()
 
+	// SetSemiSyncDivergence sets the description of how the semi-sync settings
+	// of MySQL diverge from the durability policy, which is reported in the
+	// health stream. It is empty when they match.
+	SetSemiSyncDivergence(divergence string)
+
+	// SetWritesFrozen sets whether the writes to the shard are frozen, which
+	// is reported in the health stream.
+	SetWritesFrozen(frozen bool)
+
 	// TopoServer returns the topo server.
 	TopoServer() *topo.Server
 
 	// CheckThrottler
 	CheckThrottler(ctx context.Context, appName string, flags *throttle.CheckFlags) *throttle.CheckResult
+
+	// SelfCheck runs the self checks of the tablet server.
+	SelfCheck(ctx context.Context) []*tabletmanagerdatapb.SelfCheckResult
 }
 
 // Ensure TabletServer satisfies Controller interface.
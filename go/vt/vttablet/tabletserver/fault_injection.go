@@ -0,0 +1,328 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The kinds of faults the fault injector injects.
+const (
+	// FaultLatency delays the matching queries by the duration of the fault.
+	FaultLatency = "latency"
+	// FaultConnectionError fails the matching queries as if the connection to
+	// MySQL was lost.
+	FaultConnectionError = "connection_error"
+	// FaultPoolExhaustion fails the matching queries as if no connection of
+	// the connection pools was available in time.
+	FaultPoolExhaustion = "pool_exhaustion"
+	// FaultReplicationStall makes the tablet report a replication lag growing
+	// since the fault was injected, as if replication had stopped.
+	FaultReplicationStall = "replication_stall"
+)
+
+// Fault is a failure injected into the queries of the tablet, or into its
+// replication lag. Caller, Table and Percent do not apply to the replication
+// stalls.
+type Fault struct {
+	Name string
+	Kind string
+	// Caller restricts the fault to the queries whose immediate caller user
+	// or effective caller principal is Caller.
+	Caller string `json:",omitempty"`
+	// Table restricts the fault to the queries on Table.
+	Table string `json:",omitempty"`
+	// Percent is the percentage of the matching queries the fault is
+	// injected into. 100 if 0.
+	Percent int `json:",omitempty"`
+	// Duration is the latency added by a latency fault.
+	Duration time.Duration `json:",omitempty"`
+	Created  time.Time
+	// Expires is when the fault is removed. Never if zero.
+	Expires  time.Time `json:",omitempty"`
+	Injected int64
+}
+
+// faultInjector injects faults into the queries of the tablet, for the
+// endtoend and chaos tests of the retries and the buffering of vtgate. It is
+// only enabled by --enable-fault-injection, and its faults are managed through
+// the /debug/faults admin endpoint.
+type faultInjector struct {
+	enabled  bool
+	injected *stats.CountersWithSingleLabel
+	now      func() time.Time
+
+	mu     sync.Mutex
+	faults map[string]*Fault
+}
+
+func newFaultInjector(exporter *servenv.Exporter, enabled bool) *faultInjector {
+	fi := &faultInjector{
+		enabled: enabled,
+		now:     time.Now,
+		faults:  make(map[string]*Fault),
+	}
+	if enabled {
+		fi.injected = exporter.NewCountersWithSingleLabel("FaultsInjected", "Number of times each fault was injected", "Fault")
+	}
+	return fi
+}
+
+// add injects the fault, replacing the fault of the same name.
+func (fi *faultInjector) add(fault Fault) error {
+	switch fault.Kind {
+	case FaultLatency:
+		if fault.Duration <= 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "latency fault %s needs a positive duration", fault.Name)
+		}
+	case FaultConnectionError, FaultPoolExhaustion, FaultReplicationStall:
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown fault kind %q", fault.Kind)
+	}
+	if fault.Name == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "fault name must be specified")
+	}
+	if fault.Percent < 0 || fault.Percent > 100 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "fault percentage must be between 0 and 100, got %d", fault.Percent)
+	}
+	if fault.Percent == 0 {
+		fault.Percent = 100
+	}
+	fault.Created = fi.now()
+	fault.Injected = 0
+
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	log.Warningf("Injecting fault %s: %+v", fault.Name, fault)
+	fi.faults[fault.Name] = &fault
+	return nil
+}
+
+// remove removes the fault of the given name, or all the faults if name is
+// empty.
+func (fi *faultInjector) remove(name string) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	if name == "" {
+		clear(fi.faults)
+		return
+	}
+	delete(fi.faults, name)
+}
+
+func (fi *faultInjector) list() []Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.expireLocked()
+	faults := make([]Fault, 0, len(fi.faults))
+	for _, fault := range fi.faults {
+		faults = append(faults, *fault)
+	}
+	sort.Slice(faults, func(i, j int) bool { return faults[i].Name < faults[j].Name })
+	return faults
+}
+
+func (fi *faultInjector) expireLocked() {
+	now := fi.now()
+	for name, fault := range fi.faults {
+		if !fault.Expires.IsZero() && now.After(fault.Expires) {
+			log.Infof("Fault %s expired", name)
+			delete(fi.faults, name)
+		}
+	}
+}
+
+// injectQuery injects the matching faults into a query on the tables: it
+// waits for the latency faults, then returns the error of the first failing
+// fault, if any.
+func (fi *faultInjector) injectQuery(ctx context.Context, tables []string) error {
+	if fi == nil || !fi.enabled {
+		return nil
+	}
+
+	var (
+		latency time.Duration
+		err     error
+	)
+	fi.mu.Lock()
+	fi.expireLocked()
+	if len(fi.faults) == 0 {
+		fi.mu.Unlock()
+		return nil
+	}
+	for _, fault := range fi.faults {
+		if fault.Kind == FaultReplicationStall || !fault.matches(ctx, tables) {
+			continue
+		}
+		if fault.Percent < 100 && rand.IntN(100) >= fault.Percent {
+			continue
+		}
+		switch fault.Kind {
+		case FaultLatency:
+			latency = max(latency, fault.Duration)
+		case FaultConnectionError:
+			if err != nil {
+				continue
+			}
+			err = sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "MySQL server has gone away (injected by fault %s)", fault.Name)
+		case FaultPoolExhaustion:
+			if err != nil {
+				continue
+			}
+			err = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "connection pool timed out (injected by fault %s)", fault.Name)
+		}
+		fault.Injected++
+		fi.injected.Add(fault.Name, 1)
+	}
+	fi.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func (fault *Fault) matches(ctx context.Context, tables []string) bool {
+	if fault.Table != "" && !slices.Contains(tables, fault.Table) {
+		return false
+	}
+	if fault.Caller != "" {
+		immediate := callerid.GetUsername(callerid.ImmediateCallerIDFromContext(ctx))
+		effective := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(ctx))
+		if fault.Caller != immediate && fault.Caller != effective {
+			return false
+		}
+	}
+	return true
+}
+
+// replicationLag returns the replication lag added by the replication stall
+// faults: the time since the oldest of them was injected.
+func (fi *faultInjector) replicationLag() time.Duration {
+	if fi == nil || !fi.enabled {
+		return 0
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.expireLocked()
+	var lag time.Duration
+	for _, fault := range fi.faults {
+		if fault.Kind == FaultReplicationStall {
+			lag = max(lag, fi.now().Sub(fault.Created))
+		}
+	}
+	return lag
+}
+
+// faultReplTracker adds the replication lag of the replication stall faults
+// to the lag reported by the replication tracker.
+type faultReplTracker struct {
+	replTracker
+	faults *faultInjector
+}
+
+// Status is part of the replTracker interface.
+func (rt faultReplTracker) Status() (time.Duration, error) {
+	lag, err := rt.replTracker.Status()
+	if err != nil {
+		return lag, err
+	}
+	return lag + rt.faults.replicationLag(), nil
+}
+
+// ServeHTTP lists the injected faults as JSON. A POST adds the fault described
+// by the form values name, kind, caller, table, percent, duration and ttl, or
+// removes the fault of the given name, or all of them, with action=remove.
+func (fi *faultInjector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if err := fi.handlePost(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fi.list()); err != nil {
+		log.Errorf("faults: couldn't encode the faults: %v", err)
+	}
+}
+
+func (fi *faultInjector) handlePost(r *http.Request) error {
+	if r.FormValue("action") == "remove" {
+		fi.remove(r.FormValue("name"))
+		return nil
+	}
+
+	fault := Fault{
+		Name:   r.FormValue("name"),
+		Kind:   r.FormValue("kind"),
+		Caller: r.FormValue("caller"),
+		Table:  r.FormValue("table"),
+	}
+	if value := r.FormValue("percent"); value != "" {
+		percent, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid percent %q: %v", value, err)
+		}
+		fault.Percent = percent
+	}
+	if value := r.FormValue("duration"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %v", value, err)
+		}
+		fault.Duration = duration
+	}
+	if value := r.FormValue("ttl"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid ttl %q: %v", value, err)
+		}
+		fault.Expires = fi.now().Add(ttl)
+	}
+	return fi.add(fault)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestFaultInjector(t *testing.T) {
+	fi := newFaultInjector(servenv.NewExporter("TestFaultInjector", "Tablet"), true)
+	now := time.Now()
+	fi.now = func() time.Time { return now }
+	ctx := callerid.NewContext(context.Background(), nil, callerid.NewImmediateCallerID("app"))
+
+	assert.ErrorContains(t, fi.add(Fault{Name: "f", Kind: "meteor"}), `unknown fault kind "meteor"`)
+	assert.ErrorContains(t, fi.add(Fault{Name: "f", Kind: FaultLatency}), "latency fault f needs a positive duration")
+	assert.ErrorContains(t, fi.add(Fault{Kind: FaultConnectionError}), "fault name must be specified")
+	assert.ErrorContains(t, fi.add(Fault{Name: "f", Kind: FaultConnectionError, Percent: 101}), "fault percentage must be between 0 and 100")
+
+	require.NoError(t, fi.add(Fault{Name: "gone", Kind: FaultConnectionError, Table: "t1"}))
+	require.NoError(t, fi.add(Fault{Name: "pool", Kind: FaultPoolExhaustion, Caller: "batch", Expires: now.Add(time.Minute)}))
+	require.NoError(t, fi.add(Fault{Name: "slow", Kind: FaultLatency, Duration: 10 * time.Millisecond, Table: "t2"}))
+
+	assert.NoError(t, fi.injectQuery(ctx, []string{"t3"}))
+
+	err := fi.injectQuery(ctx, []string{"t1"})
+	require.Error(t, err)
+	assert.Equal(t, sqlerror.CRServerGone, sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number())
+	assert.Contains(t, err.Error(), "injected by fault gone")
+
+	batchCtx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("batch", "", ""), callerid.NewImmediateCallerID("app"))
+	err = fi.injectQuery(batchCtx, []string{"t3"})
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+
+	start := time.Now()
+	assert.NoError(t, fi.injectQuery(ctx, []string{"t2"}))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	// The latency is cut short by the deadline of the query.
+	require.NoError(t, fi.add(Fault{Name: "slow", Kind: FaultLatency, Duration: time.Hour, Table: "t2"}))
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, fi.injectQuery(shortCtx, []string{"t2"}), context.DeadlineExceeded)
+
+	faults := fi.list()
+	require.Len(t, faults, 3)
+	assert.Equal(t, "gone", faults[0].Name)
+	assert.EqualValues(t, 1, faults[0].Injected)
+	assert.Equal(t, 100, faults[0].Percent)
+	assert.EqualValues(t, 1, fi.injected.Counts()["pool"])
+
+	// The pool fault expires.
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, fi.injectQuery(batchCtx, []string{"t3"}))
+	assert.Len(t, fi.list(), 2)
+
+	fi.remove("gone")
+	assert.NoError(t, fi.injectQuery(ctx, []string{"t1"}))
+	fi.remove("")
+	assert.Empty(t, fi.list())
+
+	var disabled *faultInjector
+	assert.NoError(t, disabled.injectQuery(ctx, nil))
+	assert.Zero(t, disabled.replicationLag())
+}
+
+func TestFaultInjectorReplicationStall(t *testing.T) {
+	fi := newFaultInjector(servenv.NewExporter("TestFaultInjectorReplicationStall", "Tablet"), true)
+	now := time.Now()
+	fi.now = func() time.Time { return now }
+	rt := faultReplTracker{replTracker: &testReplTracker{lag: time.Second}, faults: fi}
+
+	lag, err := rt.Status()
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lag)
+
+	require.NoError(t, fi.add(Fault{Name: "stall", Kind: FaultReplicationStall}))
+	now = now.Add(30 * time.Second)
+	lag, err = rt.Status()
+	require.NoError(t, err)
+	assert.Equal(t, 31*time.Second, lag)
+
+	// The replication stall does not fail the queries.
+	assert.NoError(t, fi.injectQuery(context.Background(), []string{"t1"}))
+}
+
+func TestFaultInjectorHTTP(t *testing.T) {
+	fi := newFaultInjector(servenv.NewExporter("TestFaultInjectorHTTP", "Tablet"), true)
+
+	post := func(values url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debug/faults", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		fi.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(url.Values{"name": {"slow"}, "kind": {FaultLatency}, "table": {"t1"}, "duration": {"100ms"}, "percent": {"50"}, "ttl": {"1m"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var faults []Fault
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &faults))
+	require.Len(t, faults, 1)
+	assert.Equal(t, "slow", faults[0].Name)
+	assert.Equal(t, 100*time.Millisecond, faults[0].Duration)
+	assert.Equal(t, 50, faults[0].Percent)
+	assert.False(t, faults[0].Expires.IsZero())
+
+	w = post(url.Values{"name": {"slow"}, "kind": {FaultLatency}, "duration": {"soon"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post(url.Values{"action": {"remove"}, "name": {"slow"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]\n", w.Body.String())
+}
+
+func TestQueryExecutorFaultInjection(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	// Faults are not injected unless enabled.
+	require.NoError(t, tsv.faults.add(Fault{Name: "gone", Kind: FaultConnectionError}))
+	got, err := newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	tsv.faults = newFaultInjector(tsv.exporter, true)
+	require.NoError(t, tsv.faults.add(Fault{Name: "gone", Kind: FaultConnectionError, Table: "test_table"}))
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	assert.ErrorContains(t, err, "injected by fault gone")
+
+	err = newTestQueryExecutor(ctx, tsv, query, 0).Stream(func(*sqltypes.Result) error { return nil })
+	assert.ErrorContains(t, err, "injected by fault gone")
+}
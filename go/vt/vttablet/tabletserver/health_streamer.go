@@ -51,6 +51,10 @@ var (
 	errUnintialized = "tabletserver uninitialized"
 
 	streamHealthBufferSize = uint(20)
+
+	// resourceMetricsInterval is the interval at which the resource metrics
+	// of the RealtimeStats are sampled. They are not sampled if it is 0.
+	resourceMetricsInterval time.Duration
 )
 
 func init() {
@@ -60,6 +64,7 @@ func init() {
 
 func registerHealthStreamerFlags(fs *pflag.FlagSet) {
 	fs.UintVar(&streamHealthBufferSize, "stream_health_buffer_size", streamHealthBufferSize, "max streaming health entries to buffer per streaming health client")
+	fs.DurationVar(&resourceMetricsInterval, "health-resource-metrics-interval", resourceMetricsInterval, "Interval at which the CPU usage, load average, free disk space of the MySQL data directory, MySQL threads running and InnoDB history list length are sampled and reported in the health stream. They are not sampled if 0.")
 }
 
 // healthStreamer streams health information to callers.
@@ -78,6 +83,7 @@ type healthStreamer struct {
 
 	se      *schema.Engine
 	history *history.History
+	sampler *resourceSampler
 
 	dbConfig               dbconfigs.Connector
 	conns                  *connpool.Pool
@@ -110,6 +116,7 @@ func newHealthStreamer(env tabletenv.Env, alias *topodatapb.TabletAlias, engine
 		},
 
 		history:                history.New(5),
+		sampler:                newResourceSampler(resourceMetricsInterval),
 		conns:                  pool,
 		signalWhenSchemaChange: env.Config().SignalWhenSchemaChange,
 		reloadTimeout:          env.Config().SchemaChangeReloadTimeout,
@@ -137,6 +144,7 @@ func (hs *healthStreamer) Open() {
 		// if we don't have a live conns object, it means we are not configured to signal when the schema changes
 		hs.conns.Open(hs.dbConfig, hs.dbConfig, hs.dbConfig)
 	}
+	hs.sampler.Open(hs.dbConfig)
 }
 
 func (hs *healthStreamer) Close() {
@@ -152,6 +160,7 @@ func (hs *healthStreamer) Close() {
 		hs.conns.Close()
 		hs.conns = nil
 	}
+	hs.sampler.Close()
 }
 
 func (hs *healthStreamer) Stream(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error {
@@ -224,6 +233,7 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimes
 
 	hs.state.RealtimeStats.FilteredReplicationLagSeconds, hs.state.RealtimeStats.BinlogPlayersCount = blpFunc()
 	hs.state.RealtimeStats.Qps = hs.stats.QPSRates.TotalRate()
+	hs.sampler.fill(hs.state.RealtimeStats)
 	shr := hs.state.CloneVT()
 	hs.broadCastToClients(shr)
 	hs.history.Add(&historyRecord{
@@ -235,6 +245,26 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimes
 	})
 }
 
+// SetSemiSyncDivergence sets the divergence of the semi-sync settings
+// reported in the RealtimeStats, from the next state change on.
+func (hs *healthStreamer) SetSemiSyncDivergence(divergence string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.SemiSyncDivergence = divergence
+}
+
+// SetWritesFrozen sets whether the writes to the shard are frozen, which is
+// reported in the RealtimeStats, and broadcasts the change right away.
+func (hs *healthStreamer) SetWritesFrozen(frozen bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.state.RealtimeStats.WritesFrozen == frozen {
+		return
+	}
+	hs.state.RealtimeStats.WritesFrozen = frozen
+	hs.broadCastToClients(hs.state.CloneVT())
+}
+
 func (hs *healthStreamer) broadCastToClients(shr *querypb.StreamHealthResponse) {
 	for ch := range hs.clients {
 		select {
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/constants/sidecar"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+const (
+	// idempotencyTokenPurgeInterval is the minimum interval between two
+	// purges of the expired idempotency tokens.
+	idempotencyTokenPurgeInterval = time.Minute
+	// idempotencyTokenPurgeLimit is the maximum number of expired tokens
+	// deleted by a purge, to keep it short.
+	idempotencyTokenPurgeLimit = 10000
+	// idempotencyTokenPurgeTimeout is the timeout of a purge.
+	idempotencyTokenPurgeTimeout = 30 * time.Second
+)
+
+const (
+	sqlInsertIdempotencyToken = "insert into %s.idempotency_tokens(token, time_created) values (%a, %a)"
+	sqlSelectIdempotencyToken = "select rows_affected, insert_id from %s.idempotency_tokens where token = %a"
+	sqlUpdateIdempotencyToken = "update %s.idempotency_tokens set rows_affected = %a, insert_id = %a where token = %a"
+	sqlPurgeIdempotencyTokens = "delete from %s.idempotency_tokens where time_created < %a limit %a"
+)
+
+// withIdempotencyToken wraps the execution of a write in a transaction so
+// that it is applied at most once per idempotency token: the token is
+// recorded in the sidecar database in the same transaction as the write,
+// along with its result. If the token was already recorded, the write was
+// applied by a previous attempt, which vtgate retried after a failover, and
+// the recorded result is returned without executing the write again.
+func (qre *QueryExecutor) withIdempotencyToken(f func(conn *StatefulConnection) (*sqltypes.Result, error)) func(conn *StatefulConnection) (*sqltypes.Result, error) {
+	return func(conn *StatefulConnection) (*sqltypes.Result, error) {
+		token := qre.options.GetIdempotencyToken()
+		insert := sqlparser.BuildParsedQuery(sqlInsertIdempotencyToken, sidecar.GetIdentifier(), ":token", ":time_created")
+		query, err := insert.GenerateQuery(map[string]*querypb.BindVariable{
+			"token":        sqltypes.StringBindVariable(token),
+			"time_created": sqltypes.Int64BindVariable(time.Now().UnixNano()),
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.Exec(qre.ctx, query, 1, false); err != nil {
+			if sqlErr, ok := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError); ok && sqlErr.Num == sqlerror.ERDupEntry {
+				return qre.readIdempotentWriteResult(conn, token)
+			}
+			return nil, err
+		}
+
+		result, err := f(conn)
+		if err != nil {
+			return nil, err
+		}
+		update := sqlparser.BuildParsedQuery(sqlUpdateIdempotencyToken, sidecar.GetIdentifier(), ":rows_affected", ":insert_id", ":token")
+		query, err = update.GenerateQuery(map[string]*querypb.BindVariable{
+			"rows_affected": sqltypes.Uint64BindVariable(result.RowsAffected),
+			"insert_id":     sqltypes.Uint64BindVariable(result.InsertID),
+			"token":         sqltypes.StringBindVariable(token),
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.Exec(qre.ctx, query, 1, false); err != nil {
+			return nil, err
+		}
+		qre.tsv.stats.IdempotentWrites.Add("Executed", 1)
+		qre.tsv.maybePurgeIdempotencyTokens()
+		return result, nil
+	}
+}
+
+// readIdempotentWriteResult returns the result recorded for a write which
+// was already applied with the given token.
+func (qre *QueryExecutor) readIdempotentWriteResult(conn *StatefulConnection, token string) (*sqltypes.Result, error) {
+	sel := sqlparser.BuildParsedQuery(sqlSelectIdempotencyToken, sidecar.GetIdentifier(), ":token")
+	query, err := sel.GenerateQuery(map[string]*querypb.BindVariable{
+		"token": sqltypes.StringBindVariable(token),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := conn.Exec(qre.ctx, query, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &sqltypes.Result{}
+	if len(qr.Rows) == 1 {
+		result.RowsAffected, _ = qr.Rows[0][0].ToCastUint64()
+		result.InsertID, _ = qr.Rows[0][1].ToCastUint64()
+	}
+	log.Infof("Write with idempotency token %s was already applied, returning its recorded result", token)
+	qre.tsv.stats.IdempotentWrites.Add("Deduplicated", 1)
+	return result, nil
+}
+
+// maybePurgeIdempotencyTokens purges the tokens older than the retention
+// period in the background, at most once per idempotencyTokenPurgeInterval.
+func (tsv *TabletServer) maybePurgeIdempotencyTokens() {
+	now := time.Now()
+	last := tsv.lastIdempotencyTokenPurge.Load()
+	if now.Sub(time.Unix(0, last)) < idempotencyTokenPurgeInterval {
+		return
+	}
+	if !tsv.lastIdempotencyTokenPurge.CompareAndSwap(last, now.UnixNano()) {
+		// Another write is purging the tokens.
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencyTokenPurgeTimeout)
+		defer cancel()
+		if err := tsv.purgeIdempotencyTokens(ctx, now.Add(-tsv.config.IdempotencyTokenRetention)); err != nil {
+			tsv.stats.InternalErrors.Add("IdempotencyTokenPurge", 1)
+			log.Warningf("Failed to purge the expired idempotency tokens: %v", err)
+		}
+	}()
+}
+
+// purgeIdempotencyTokens deletes the tokens created before cutoff.
+func (tsv *TabletServer) purgeIdempotencyTokens(ctx context.Context, cutoff time.Time) error {
+	conn, err := tsv.qe.conns.Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Recycle()
+
+	purge := sqlparser.BuildParsedQuery(sqlPurgeIdempotencyTokens, sidecar.GetIdentifier(), ":cutoff", ":limit")
+	query, err := purge.GenerateQuery(map[string]*querypb.BindVariable{
+		"cutoff": sqltypes.Int64BindVariable(cutoff.UnixNano()),
+		"limit":  sqltypes.Int64BindVariable(idempotencyTokenPurgeLimit),
+	}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn.Exec(ctx, query, 0, false)
+	return err
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+)
+
+// sqlTransactionLockWaits returns the MySQL connection ids of the waiting and
+// blocking transactions of the InnoDB lock waits.
+const sqlTransactionLockWaits = `select r.trx_mysql_thread_id as waiting_thread_id, b.trx_mysql_thread_id as blocking_thread_id,
+	timestampdiff(second, r.trx_wait_started, now()) as wait_seconds
+from performance_schema.data_lock_waits w
+	join information_schema.innodb_trx r on r.trx_id = w.requesting_engine_transaction_id
+	join information_schema.innodb_trx b on b.trx_id = w.blocking_engine_transaction_id`
+
+// maxTransactionLockWaits is the maximum number of lock waits read by
+// transactionLockWaits.
+const maxTransactionLockWaits = 10000
+
+// transactionLockWaits returns the lock waits between the transactions of the
+// transaction pool. The lock waits involving other connections, e.g. the
+// autocommit queries or the connections of other clients, are ignored.
+func (tsv *TabletServer) transactionLockWaits(ctx context.Context) ([]*querypb.TransactionLockWait, error) {
+	conn, err := tsv.qe.conns.Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.Conn.Exec(ctx, sqlTransactionLockWaits, maxTransactionLockWaits, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) == 0 {
+		return nil, nil
+	}
+
+	type transaction struct {
+		id    tx.ConnID
+		props *tx.Properties
+	}
+	transactions := make(map[int64]transaction)
+	for _, sc := range tsv.te.txPool.scp.GetAll() {
+		dbConn, props := sc.UnderlyingDBConn(), sc.TxProperties()
+		if dbConn == nil || props == nil {
+			continue
+		}
+		transactions[dbConn.Conn.ID()] = transaction{id: sc.ConnID, props: props}
+	}
+
+	var lockWaits []*querypb.TransactionLockWait
+	for _, row := range qr.Rows {
+		waitingID, err := row[0].ToCastInt64()
+		if err != nil {
+			return nil, err
+		}
+		blockingID, err := row[1].ToCastInt64()
+		if err != nil {
+			return nil, err
+		}
+		waiting, ok := transactions[waitingID]
+		if !ok {
+			continue
+		}
+		blocking, ok := transactions[blockingID]
+		if !ok {
+			continue
+		}
+		waitSeconds, _ := row[2].ToCastInt64()
+		lockWaits = append(lockWaits, &querypb.TransactionLockWait{
+			WaitingTransactionId:  waiting.id,
+			WaitingSessionUuid:    waiting.props.SessionUUID,
+			WaitingStartTime:      waiting.props.StartTime.UnixNano(),
+			BlockingTransactionId: blocking.id,
+			BlockingSessionUuid:   blocking.props.SessionUUID,
+			BlockingStartTime:     blocking.props.StartTime.UnixNano(),
+			WaitSeconds:           waitSeconds,
+		})
+	}
+	return lockWaits, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"sync/atomic"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// unknownRowSize is the size in bytes charged per row to the SELECTs of plans
+// without previous results, whose result size is unknown.
+const unknownRowSize = 64
+
+// memoryGovernor protects the tablet from running out of memory when several
+// concurrent queries return large result sets, which the max result size
+// alone does not prevent.
+//
+// It tracks the bytes of the result sets held by in-flight queries. A SELECT
+// reserves its expected result size, estimated from the previous executions
+// of its plan, for as long as it runs, and its actual result size once it is
+// fetched. Streaming queries reserve each packet while it is being sent. When
+// the reservations exceed the budget, new SELECTs are limited to the rows
+// that fit in the remaining budget, and new streaming queries are rejected.
+type memoryGovernor struct {
+	budget atomic.Int64
+	inUse  atomic.Int64
+
+	rejections *stats.CountersWithSingleLabel
+}
+
+func newMemoryGovernor(env tabletenv.Env) *memoryGovernor {
+	mg := &memoryGovernor{}
+	mg.budget.Store(env.Config().MemoryBudget)
+	env.Exporter().NewGaugeFunc("MemoryGovernorBudget", "Memory governor budget in bytes for the result sets of in-flight queries", mg.budget.Load)
+	env.Exporter().NewGaugeFunc("MemoryGovernorInUse", "Bytes of the result sets of in-flight queries tracked by the memory governor", mg.inUse.Load)
+	mg.rejections = env.Exporter().NewCountersWithSingleLabel("MemoryGovernorRejections", "Number of queries limited or rejected by the memory governor", "Reason")
+	return mg
+}
+
+// memoryReservation is memory reserved by a query.
+type memoryReservation struct {
+	mg    *memoryGovernor
+	bytes int64
+}
+
+func (mg *memoryGovernor) reserve(bytes int64) *memoryReservation {
+	mg.inUse.Add(bytes)
+	return &memoryReservation{mg: mg, bytes: bytes}
+}
+
+// resize changes the size of the reservation.
+func (mr *memoryReservation) resize(bytes int64) {
+	if mr == nil {
+		return
+	}
+	mr.mg.inUse.Add(bytes - mr.bytes)
+	mr.bytes = bytes
+}
+
+// release releases the reservation.
+func (mr *memoryReservation) release() {
+	mr.resize(0)
+}
+
+// admitSelect returns the maximum number of rows a SELECT of the plan may
+// return given the remaining budget, capped at maxRows, and the reservation
+// of its expected result size. It fails if the budget is exceeded and not even
+// one row of the plan fits. A plan without previous results is expected to
+// return one row of unknownRowSize bytes, so that it is rejected too once the
+// budget is exhausted. A nil reservation means the governor is disabled.
+func (mg *memoryGovernor) admitSelect(plan *TabletPlan, maxRows int64) (int64, *memoryReservation, error) {
+	budget := mg.budget.Load()
+	if budget <= 0 {
+		return maxRows, nil, nil
+	}
+
+	rowSize, rowsPerQuery := plan.resultRowStats()
+	if rowSize == 0 {
+		rowSize, rowsPerQuery = unknownRowSize, 1
+	}
+	expected := rowSize * min(rowsPerQuery, maxRows)
+	available := budget - mg.inUse.Load()
+	if expected <= available {
+		return maxRows, mg.reserve(expected), nil
+	}
+
+	limit := available / rowSize
+	if limit < 1 {
+		mg.rejections.Add("Rejected", 1)
+		return 0, nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "memory budget of %d bytes exceeded by in-flight results, try again later", budget)
+	}
+	mg.rejections.Add("Limited", 1)
+	return min(limit, maxRows), mg.reserve(limit * rowSize), nil
+}
+
+// rowLimitError returns the error of a SELECT returning more rows than the
+// limit admitSelect returned, if that limit was lower than maxRows.
+func (mg *memoryGovernor) rowLimitError(limit int64) error {
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "row count exceeded %d, the limit allowed by the remaining memory budget of in-flight results", limit)
+}
+
+// admitStream fails if the budget is exceeded.
+func (mg *memoryGovernor) admitStream() error {
+	budget := mg.budget.Load()
+	if budget <= 0 || mg.inUse.Load() < budget {
+		return nil
+	}
+	mg.rejections.Add("RejectedStream", 1)
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "memory budget of %d bytes exceeded by in-flight results, try again later", budget)
+}
+
+// trackStream returns a callback reserving the size of each result while the
+// given callback sends it.
+func (mg *memoryGovernor) trackStream(callback StreamCallback) StreamCallback {
+	if mg.budget.Load() <= 0 {
+		return callback
+	}
+	return func(result *sqltypes.Result) error {
+		reservation := mg.reserve(result.CachedSize(true))
+		defer reservation.release()
+		return callback(result)
+	}
+}
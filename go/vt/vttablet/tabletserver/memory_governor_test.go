@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestMemoryGovernorSelect(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.MemoryBudget = 1000
+	mg := newMemoryGovernor(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "MemoryGovernorTest"))
+
+	// Without previous results, the size of the result is unknown, so one row
+	// of unknownRowSize is reserved.
+	plan := &TabletPlan{}
+	limit, reservation, err := mg.admitSelect(plan, 10000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10000, limit)
+	assert.EqualValues(t, unknownRowSize, mg.inUse.Load())
+	reservation.resize(900)
+	assert.EqualValues(t, 900, mg.inUse.Load())
+
+	// 10 rows of 10 bytes fit in the remaining budget.
+	plan.AddResultStats(10, 100)
+	limit, small, err := mg.admitSelect(plan, 10000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10000, limit)
+	assert.EqualValues(t, 1000, mg.inUse.Load())
+	small.release()
+
+	// 100 rows of 10 bytes don't, so the query is limited to 10 rows.
+	plan.AddResultStats(190, 1900)
+	limit, limited, err := mg.admitSelect(plan, 10000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, limit)
+	assert.EqualValues(t, 1000, mg.inUse.Load())
+
+	// No row fits anymore, not even for a plan without previous results.
+	_, _, err = mg.admitSelect(plan, 10000)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	_, _, err = mg.admitSelect(&TabletPlan{}, 10000)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.Equal(t, map[string]int64{"Limited": 1, "Rejected": 2}, mg.rejections.Counts())
+
+	limited.release()
+	reservation.release()
+	assert.EqualValues(t, 0, mg.inUse.Load())
+}
+
+func TestMemoryGovernorStream(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.MemoryBudget = 1000
+	mg := newMemoryGovernor(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "MemoryGovernorTest"))
+
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "varchar"), "aaaaaaaaaa")
+	callback := mg.trackStream(func(qr *sqltypes.Result) error {
+		assert.EqualValues(t, qr.CachedSize(true), mg.inUse.Load())
+		return nil
+	})
+	require.NoError(t, mg.admitStream())
+	require.NoError(t, callback(result))
+	assert.EqualValues(t, 0, mg.inUse.Load())
+
+	reservation := mg.reserve(1000)
+	err := mg.admitStream()
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	reservation.release()
+	require.NoError(t, mg.admitStream())
+}
+
+func TestMemoryGovernorDisabled(t *testing.T) {
+	mg := newMemoryGovernor(tabletenv.NewEnv(vtenv.NewTestEnv(), tabletenv.NewDefaultConfig(), "MemoryGovernorTest"))
+	plan := &TabletPlan{}
+	plan.AddResultStats(1000, 1<<30)
+
+	limit, reservation, err := mg.admitSelect(plan, 10000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10000, limit)
+	assert.Nil(t, reservation)
+	assert.NoError(t, mg.admitStream())
+}
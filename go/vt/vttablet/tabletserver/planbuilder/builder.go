@@ -79,6 +79,10 @@ func analyzeUpdate(upd *sqlparser.Update, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", upd.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.VersionPredicate = hasVersionPredicate(upd.Where, plan.Table)
+	}
+	if plan.ChunkedDML, err = buildChunkedDML(upd, plan.Table); err != nil {
+		return nil, err
 	}
 
 	// Situations when we pass-through:
@@ -108,6 +112,10 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", del.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.VersionPredicate = hasVersionPredicate(del.Where, plan.Table)
+	}
+	if plan.ChunkedDML, err = buildChunkedDML(del, plan.Table); err != nil {
+		return nil, err
 	}
 
 	if PassthroughDMLs || plan.Table == nil || del.Limit != nil {
@@ -121,6 +129,33 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 	return plan, nil
 }
 
+// hasVersionPredicate returns true if the WHERE clause compares the version
+// column of the table to a value, in one of its top level AND predicates.
+func hasVersionPredicate(where *sqlparser.Where, table *schema.Table) bool {
+	if table == nil || table.VersionColumn.IsEmpty() {
+		return false
+	}
+	for _, expr := range sqlparser.SplitAndExpression(nil, where.Expr) {
+		cmp, ok := expr.(*sqlparser.ComparisonExpr)
+		if !ok || cmp.Operator != sqlparser.EqualOp {
+			continue
+		}
+		left, right := cmp.Left, cmp.Right
+		if _, ok := right.(*sqlparser.ColName); ok {
+			left, right = right, left
+		}
+		col, ok := left.(*sqlparser.ColName)
+		if !ok || !col.Name.Equal(table.VersionColumn) {
+			continue
+		}
+		switch right.(type) {
+		case *sqlparser.Literal, *sqlparser.Argument:
+			return true
+		}
+	}
+	return false
+}
+
 func analyzeInsert(ins *sqlparser.Insert, tables map[string]*schema.Table) (plan *Plan, err error) {
 	plan = &Plan{
 		PlanID:    PlanInsert,
@@ -141,6 +176,8 @@ func analyzeShow(show *sqlparser.Show, dbName string) (plan *Plan, err error) {
 		switch showInternal.Command {
 		case sqlparser.VitessMigrations:
 			return &Plan{PlanID: PlanShowMigrations, FullStmt: show}, nil
+		case sqlparser.VitessWorkflows:
+			return &Plan{PlanID: PlanShowWorkflows, FullStmt: show}, nil
 		case sqlparser.Table:
 			// rewrite WHERE clause if it exists
 			// `where Tables_in_Keyspace` => `where Tables_in_DbName`
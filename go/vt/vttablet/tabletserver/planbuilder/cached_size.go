@@ -23,6 +23,24 @@ type cachedObject interface {
 	CachedSize(alloc bool) int64
 }
 
+func (cached *ChunkedDML) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field FirstBounds *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
+	size += cached.FirstBounds.CachedSize(true)
+	// field NextBounds *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
+	size += cached.NextBounds.CachedSize(true)
+	// field FirstChunk *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
+	size += cached.FirstChunk.CachedSize(true)
+	// field NextChunk *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
+	size += cached.NextChunk.CachedSize(true)
+	return size
+}
 func (cached *Permission) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -41,7 +59,7 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(128)
+		size += int64(144)
 	}
 	// field Table *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.Table
 	size += cached.Table.CachedSize(true)
@@ -71,5 +89,7 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	if cc, ok := cached.FullStmt.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
+	// field ChunkedDML *vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder.ChunkedDML
+	size += cached.ChunkedDML.CachedSize(true)
 	return size
 }
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"strconv"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ChunkSizeBindVar is the bind variable of the number of rows of a chunk.
+const ChunkSizeBindVar = "#chunk_size"
+
+// ChunkedDML holds the queries which execute an UPDATE or DELETE statement
+// without LIMIT in chunks of rows, by ranges of the primary key of its table.
+//
+// Each chunk selects the primary key of the next ChunkSizeBindVar rows the
+// statement modifies, following those of the previous chunk, and then
+// executes the statement for the range of the primary key they span.
+type ChunkedDML struct {
+	// Size is the number of rows of the chunks set by the DML_CHUNK_SIZE
+	// directive, or 0 if it is not set.
+	Size int64
+	// PKColumns is the number of columns of the primary key.
+	PKColumns int
+	// FirstBounds and NextBounds select the primary key of the rows of the
+	// first chunk, and of the following ones.
+	FirstBounds, NextBounds *sqlparser.ParsedQuery
+	// FirstChunk and NextChunk execute the statement for the rows of the
+	// first chunk, and of the following ones.
+	FirstChunk, NextChunk *sqlparser.ParsedQuery
+}
+
+// ChunkLowerBindVar is the bind variable of the i-th column of the primary
+// key of the last row of the previous chunk.
+func ChunkLowerBindVar(i int) string {
+	return "#chunk_lower_" + strconv.Itoa(i)
+}
+
+// ChunkUpperBindVar is the bind variable of the i-th column of the primary
+// key of the last row of the chunk.
+func ChunkUpperBindVar(i int) string {
+	return "#chunk_upper_" + strconv.Itoa(i)
+}
+
+// buildChunkedDML returns the ChunkedDML of the UPDATE or DELETE statement,
+// or nil if it cannot be executed in chunks: when it has a LIMIT or an ORDER
+// BY, modifies more than one table, or updates the primary key. The statements
+// which require to be executed in chunks by the DML_CHUNK_SIZE directive fail
+// instead.
+func buildChunkedDML(stmt sqlparser.Statement, table *schema.Table) (*ChunkedDML, error) {
+	size, err := sqlparser.GetDMLChunkSizeFromStatement(stmt)
+	if err != nil {
+		return nil, err
+	}
+	chunked, reason := newChunkedDML(stmt, table)
+	if chunked == nil {
+		if size > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s cannot be used with a statement which %s", sqlparser.DirectiveDMLChunkSize, reason)
+		}
+		return nil, nil
+	}
+	chunked.Size = size
+	return chunked, nil
+}
+
+func newChunkedDML(stmt sqlparser.Statement, table *schema.Table) (*ChunkedDML, string) {
+	var (
+		with       *sqlparser.With
+		tableExprs []sqlparser.TableExpr
+		where      *sqlparser.Where
+		orderBy    sqlparser.OrderBy
+		limit      *sqlparser.Limit
+	)
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		with, tableExprs, where, orderBy, limit = stmt.With, stmt.TableExprs, stmt.Where, stmt.OrderBy, stmt.Limit
+		if hasPrimaryKey(table) {
+			for _, expr := range stmt.Exprs {
+				for i := range table.PKColumns {
+					if expr.Name.Name.EqualString(table.GetPKColumn(i).Name) {
+						return nil, "updates the primary key"
+					}
+				}
+			}
+		}
+	case *sqlparser.Delete:
+		with, tableExprs, where, orderBy, limit = stmt.With, stmt.TableExprs, stmt.Where, stmt.OrderBy, stmt.Limit
+		if len(stmt.Targets) > 0 {
+			return nil, "modifies more than one table"
+		}
+	default:
+		return nil, "is not an UPDATE or DELETE"
+	}
+	switch {
+	case limit != nil:
+		return nil, "has a LIMIT"
+	case orderBy != nil:
+		return nil, "has an ORDER BY"
+	case with != nil:
+		return nil, "has a WITH clause"
+	case len(tableExprs) != 1:
+		return nil, "modifies more than one table"
+	case table == nil:
+		return nil, "modifies an unknown table"
+	case !hasPrimaryKey(table):
+		return nil, "modifies a table without primary key"
+	}
+	if _, ok := tableExprs[0].(*sqlparser.AliasedTableExpr); !ok {
+		return nil, "modifies more than one table"
+	}
+
+	pk := make(sqlparser.ValTuple, 0, len(table.PKColumns))
+	lower := make(sqlparser.ValTuple, 0, len(table.PKColumns))
+	upper := make(sqlparser.ValTuple, 0, len(table.PKColumns))
+	selectExprs := make(sqlparser.SelectExprs, 0, len(table.PKColumns))
+	orderByPK := make(sqlparser.OrderBy, 0, len(table.PKColumns))
+	for i := range table.PKColumns {
+		col := sqlparser.NewColName(table.GetPKColumn(i).Name)
+		pk = append(pk, col)
+		lower = append(lower, sqlparser.NewArgument(ChunkLowerBindVar(i)))
+		upper = append(upper, sqlparser.NewArgument(ChunkUpperBindVar(i)))
+		selectExprs = append(selectExprs, &sqlparser.AliasedExpr{Expr: col})
+		orderByPK = append(orderByPK, &sqlparser.Order{Expr: col, Direction: sqlparser.AscOrder})
+	}
+	// The primary keys of a single column are compared as values rather than
+	// tuples.
+	var pkExpr, lowerExpr, upperExpr sqlparser.Expr = pk, lower, upper
+	if len(pk) == 1 {
+		pkExpr, lowerExpr, upperExpr = pk[0], lower[0], upper[0]
+	}
+	afterLower := &sqlparser.ComparisonExpr{Operator: sqlparser.GreaterThanOp, Left: pkExpr, Right: lowerExpr}
+	upToUpper := &sqlparser.ComparisonExpr{Operator: sqlparser.LessEqualOp, Left: pkExpr, Right: upperExpr}
+
+	var cond sqlparser.Expr
+	if where != nil {
+		cond = where.Expr
+	}
+	restrict := func(exprs ...sqlparser.Expr) *sqlparser.Where {
+		if cond != nil {
+			exprs = append([]sqlparser.Expr{cond}, exprs...)
+		}
+		return sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(exprs...))
+	}
+	bounds := func(exprs ...sqlparser.Expr) *sqlparser.ParsedQuery {
+		sel := &sqlparser.Select{
+			SelectExprs: selectExprs,
+			From:        tableExprs,
+			OrderBy:     orderByPK,
+			Limit:       &sqlparser.Limit{Rowcount: sqlparser.NewArgument(ChunkSizeBindVar)},
+		}
+		if len(exprs) > 0 || cond != nil {
+			sel.Where = restrict(exprs...)
+		}
+		return GenerateFullQuery(sel)
+	}
+	chunk := func(exprs ...sqlparser.Expr) *sqlparser.ParsedQuery {
+		switch stmt := stmt.(type) {
+		case *sqlparser.Update:
+			stmt = sqlparser.CloneRefOfUpdate(stmt)
+			stmt.Where = restrict(exprs...)
+			return GenerateFullQuery(stmt)
+		case *sqlparser.Delete:
+			stmt = sqlparser.CloneRefOfDelete(stmt)
+			stmt.Where = restrict(exprs...)
+			return GenerateFullQuery(stmt)
+		}
+		return nil
+	}
+	return &ChunkedDML{
+		PKColumns:   len(pk),
+		FirstBounds: bounds(),
+		NextBounds:  bounds(afterLower),
+		FirstChunk:  chunk(upToUpper),
+		NextChunk:   chunk(afterLower, upToUpper),
+	}, ""
+}
+
+// hasPrimaryKey returns true if the table is known to have a primary key.
+func hasPrimaryKey(table *schema.Table) bool {
+	if table == nil || !table.HasPrimary() {
+		return false
+	}
+	for _, pk := range table.PKColumns {
+		if pk >= len(table.Fields) {
+			return false
+		}
+	}
+	return true
+}
@@ -80,6 +80,7 @@ const (
 	PlanShowMigrationLogs
 	PlanShowThrottledApps
 	PlanShowThrottlerStatus
+	PlanShowWorkflows
 	NumPlans
 )
 
@@ -116,6 +117,7 @@ var planName = []string{
 	"ShowMigrationLogs",
 	"ShowThrottledApps",
 	"ShowThrottlerStatus",
+	"ShowWorkflows",
 }
 
 func (pt PlanType) String() string {
@@ -176,6 +178,15 @@ type Plan struct {
 	// FullStmt can be used when the query does not operate on tables
 	FullStmt sqlparser.Statement
 
+	// VersionPredicate is set for the UPDATE and DELETE statements whose
+	// WHERE clause compares the version column of their table to a value.
+	// It is used to detect the optimistic concurrency conflicts.
+	VersionPredicate bool
+
+	// ChunkedDML is set for the UPDATE and DELETE statements which can be
+	// executed in chunks of rows.
+	ChunkedDML *ChunkedDML
+
 	// NeedsReservedConn indicates at a reserved connection is needed to execute this plan
 	NeedsReservedConn bool
 }
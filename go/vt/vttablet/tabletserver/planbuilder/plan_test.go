@@ -28,11 +28,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/vtenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 )
 
@@ -291,6 +294,108 @@ func TestLockPlan(t *testing.T) {
 	}
 }
 
+func TestVersionPredicate(t *testing.T) {
+	table := schema.NewTable("t", schema.NoType)
+	table.VersionColumn = sqlparser.NewIdentifierCI("version")
+	tables := map[string]*schema.Table{"t": table, "u": schema.NewTable("u", schema.NoType)}
+	testcases := []struct {
+		query string
+		want  bool
+	}{
+		{query: "update t set a = 1, version = version + 1 where id = 1 and version = 2", want: true},
+		{query: "update t set a = 1 where id = :id and :version = t.Version", want: true},
+		{query: "delete from t where version = 'a' and id = 1", want: true},
+		{query: "update t set a = 1 where id = 1", want: false},
+		{query: "update t set a = 1", want: false},
+		{query: "update t set a = 1 where id = 1 or version = 2", want: false},
+		{query: "update t set a = 1 where version > 2", want: false},
+		{query: "update t set a = 1 where version = id", want: false},
+		{query: "delete from u where version = 2", want: false},
+		{query: "update t, u set t.a = 1 where t.id = u.id and t.version = 2", want: false},
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tcase := range testcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			statement, err := parser.Parse(tcase.query)
+			require.NoError(t, err)
+			plan, err := Build(vtenv.NewTestEnv(), statement, tables, "dbName", false)
+			require.NoError(t, err)
+			require.Equal(t, tcase.want, plan.VersionPredicate)
+		})
+	}
+}
+
+func TestChunkedDML(t *testing.T) {
+	newTable := func(name string, fields []string, pkColumns ...int) *schema.Table {
+		table := schema.NewTable(name, schema.NoType)
+		for _, field := range fields {
+			table.Fields = append(table.Fields, &querypb.Field{Name: field})
+		}
+		table.PKColumns = pkColumns
+		return table
+	}
+	testSchema := map[string]*schema.Table{
+		"a": newTable("a", []string{"eid", "id", "name"}, 0, 1),
+		"b": newTable("b", []string{"eid", "id", "name"}, 0, 1),
+		"c": newTable("c", []string{"eid", "id"}),
+		"d": newTable("d", []string{"name", "id", "foo", "bar"}, 0),
+	}
+	parser := sqlparser.NewTestParser()
+	build := func(t *testing.T, query string) (*Plan, error) {
+		statement, err := parser.Parse(query)
+		require.NoError(t, err)
+		return Build(vtenv.NewTestEnv(), statement, testSchema, "dbName", false)
+	}
+
+	plan, err := build(t, "delete /*vt+ DML_CHUNK_SIZE=1000 */ from d where foo = 1 or bar = :bar")
+	require.NoError(t, err)
+	require.NotNil(t, plan.ChunkedDML)
+	assert.EqualValues(t, 1000, plan.ChunkedDML.Size)
+	assert.Equal(t, 1, plan.ChunkedDML.PKColumns)
+	assert.Equal(t, "select `name` from d where foo = 1 or bar = :bar order by `name` asc limit :#chunk_size", plan.ChunkedDML.FirstBounds.Query)
+	assert.Equal(t, "select `name` from d where (foo = 1 or bar = :bar) and `name` > :#chunk_lower_0 order by `name` asc limit :#chunk_size", plan.ChunkedDML.NextBounds.Query)
+	assert.Equal(t, "delete /*vt+ DML_CHUNK_SIZE=1000 */ from d where (foo = 1 or bar = :bar) and `name` <= :#chunk_upper_0", plan.ChunkedDML.FirstChunk.Query)
+	assert.Equal(t, "delete /*vt+ DML_CHUNK_SIZE=1000 */ from d where (foo = 1 or bar = :bar) and `name` > :#chunk_lower_0 and `name` <= :#chunk_upper_0", plan.ChunkedDML.NextChunk.Query)
+
+	// The primary keys of several columns are compared as tuples.
+	plan, err = build(t, "update a set name = 'x'")
+	require.NoError(t, err)
+	require.NotNil(t, plan.ChunkedDML)
+	assert.Zero(t, plan.ChunkedDML.Size)
+	assert.Equal(t, 2, plan.ChunkedDML.PKColumns)
+	assert.Equal(t, "select eid, id from a order by eid asc, id asc limit :#chunk_size", plan.ChunkedDML.FirstBounds.Query)
+	assert.Equal(t, "update a set `name` = 'x' where (eid, id) > (:#chunk_lower_0, :#chunk_lower_1) and (eid, id) <= (:#chunk_upper_0, :#chunk_upper_1)", plan.ChunkedDML.NextChunk.Query)
+
+	// The statements which cannot be executed in chunks only fail when the
+	// directive requires it.
+	testcases := []struct {
+		query string
+		err   string
+	}{
+		{query: "delete from d where foo = 1 limit 10", err: "has a LIMIT"},
+		{query: "update d set foo = 1 order by bar", err: "has an ORDER BY"},
+		{query: "update a set id = id + 1", err: "updates the primary key"},
+		{query: "delete a from a join b on a.id = b.id", err: "modifies more than one table"},
+		{query: "update a, b set a.name = b.name where a.id = b.id", err: "modifies more than one table"},
+		{query: "delete from c", err: "modifies a table without primary key"},
+		{query: "delete from unknown_table", err: "modifies an unknown table"},
+	}
+	for _, tcase := range testcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			plan, err := build(t, tcase.query)
+			require.NoError(t, err)
+			assert.Nil(t, plan.ChunkedDML)
+
+			query := strings.Replace(tcase.query, " ", " /*vt+ DML_CHUNK_SIZE=100 */ ", 1)
+			_, err = build(t, query)
+			assert.ErrorContains(t, err, "DML_CHUNK_SIZE cannot be used with a statement which "+tcase.err)
+		})
+	}
+
+	_, err = build(t, "delete /*vt+ DML_CHUNK_SIZE=0 */ from d")
+	assert.ErrorIs(t, err, sqlparser.ErrInvalidDMLChunkSize)
+}
+
 func loadSchema(name string) map[string]*schema.Table {
 	b, err := os.ReadFile(locateFile(name))
 	if err != nil {
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// The orders of the digests served by /debug/query_digests.
+const (
+	queryDigestsByTime  = "time"
+	queryDigestsByCount = "count"
+	queryDigestsByRows  = "rows"
+
+	// defaultQueryDigestsLimit is the number of digests served when no limit
+	// is given.
+	defaultQueryDigestsLimit = 20
+)
+
+// queryDigests aggregates the executions of the queries per caller and
+// normalized query, the digest, so that the top queries of each caller by
+// total time, count or rows can be found during an incident, like the
+// statements summary of performance_schema.
+//
+// It keeps up to size digests. When it is full, the digest with the lowest
+// total time is evicted for a new one, so that the heaviest digests are kept.
+type queryDigests struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[queryDigestKey]*queryDigestEntry
+	since   time.Time
+	// evicted counts the digests evicted because the table is full.
+	evicted int64
+}
+
+type queryDigestKey struct {
+	caller string
+	digest string
+}
+
+// queryDigestEntry reports the executions of a digest by a caller. Rows are
+// the rows returned or affected, MySQL does not report the rows examined by
+// a query to vttablet.
+type queryDigestEntry struct {
+	Caller     string
+	Digest     string
+	Count      int64
+	ErrorCount int64
+	TotalTime  time.Duration
+	MaxTime    time.Duration
+	Rows       int64
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+func newQueryDigests(size int) *queryDigests {
+	return &queryDigests{
+		size:    size,
+		entries: make(map[queryDigestKey]*queryDigestEntry),
+		since:   time.Now(),
+	}
+}
+
+// queryDigest returns the digest of the query of a plan: the query without
+// its comments and with its literals replaced by bind variables, truncated
+// for display. It is empty if the digests are not tracked.
+func (qe *QueryEngine) queryDigest(sql string) string {
+	if !qe.digests.enabled() {
+		return ""
+	}
+	parser := qe.env.Environment().Parser()
+	stripped, _ := sqlparser.SplitMarginComments(sql)
+	digest, err := parser.RedactSQLQuery(stripped)
+	if err != nil {
+		digest = stripped
+	}
+	return parser.TruncateForUI(digest)
+}
+
+// enabled returns true if the digests are tracked.
+func (qd *queryDigests) enabled() bool {
+	return qd.size > 0
+}
+
+// record records an execution of the digest by the caller.
+func (qd *queryDigests) record(caller, digest string, duration time.Duration, rows int64, failed bool) {
+	if !qd.enabled() || digest == "" {
+		return
+	}
+	now := time.Now()
+	key := queryDigestKey{caller: caller, digest: digest}
+
+	qd.mu.Lock()
+	defer qd.mu.Unlock()
+	entry, ok := qd.entries[key]
+	if !ok {
+		if len(qd.entries) >= qd.size {
+			qd.evictLocked()
+		}
+		entry = &queryDigestEntry{
+			Caller:    caller,
+			Digest:    digest,
+			FirstSeen: now,
+		}
+		qd.entries[key] = entry
+	}
+	entry.Count++
+	if failed {
+		entry.ErrorCount++
+	}
+	entry.TotalTime += duration
+	entry.MaxTime = max(entry.MaxTime, duration)
+	entry.Rows += rows
+	entry.LastSeen = now
+}
+
+// evictLocked evicts the digest with the lowest total time.
+func (qd *queryDigests) evictLocked() {
+	var lowest *queryDigestEntry
+	for _, entry := range qd.entries {
+		if lowest == nil || entry.TotalTime < lowest.TotalTime {
+			lowest = entry
+		}
+	}
+	if lowest != nil {
+		delete(qd.entries, queryDigestKey{caller: lowest.Caller, digest: lowest.Digest})
+		qd.evicted++
+	}
+}
+
+// topK returns a copy of the k first digests of the callers containing
+// callerFilter, in the given order, for each caller.
+func (qd *queryDigests) topK(callerFilter, order string, k int) map[string][]*queryDigestEntry {
+	qd.mu.Lock()
+	perCaller := make(map[string][]*queryDigestEntry)
+	for _, entry := range qd.entries {
+		if !strings.Contains(entry.Caller, callerFilter) {
+			continue
+		}
+		e := *entry
+		perCaller[entry.Caller] = append(perCaller[entry.Caller], &e)
+	}
+	qd.mu.Unlock()
+
+	value := func(e *queryDigestEntry) int64 {
+		switch order {
+		case queryDigestsByCount:
+			return e.Count
+		case queryDigestsByRows:
+			return e.Rows
+		default:
+			return int64(e.TotalTime)
+		}
+	}
+	for caller, entries := range perCaller {
+		slices.SortFunc(entries, func(a, b *queryDigestEntry) int {
+			if va, vb := value(a), value(b); va != vb {
+				if va > vb {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.Digest, b.Digest)
+		})
+		if k > 0 && len(entries) > k {
+			entries = entries[:k]
+		}
+		perCaller[caller] = entries
+	}
+	return perCaller
+}
+
+func (qd *queryDigests) reset() {
+	qd.mu.Lock()
+	defer qd.mu.Unlock()
+	qd.entries = make(map[queryDigestKey]*queryDigestEntry)
+	qd.since = time.Now()
+	qd.evicted = 0
+}
+
+// counts returns the number of digests in the table, the number of digests
+// evicted from it, and when it was last reset.
+func (qd *queryDigests) counts() (entries int, evicted int64, since time.Time) {
+	qd.mu.Lock()
+	defer qd.mu.Unlock()
+	return len(qd.entries), qd.evicted, qd.since
+}
+
+// handleHTTPQueryDigests serves the top digests of each caller as JSON. The
+// caller parameter filters the callers containing it, order is one of time
+// (the default), count and rows, and limit is the number of digests of each
+// caller, 20 by default. A POST request clears the digests.
+func (qe *QueryEngine) handleHTTPQueryDigests(response http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPost {
+		if err := acl.CheckAccessHTTP(request, acl.ADMIN); err != nil {
+			acl.SendError(response, err)
+			return
+		}
+		qe.digests.reset()
+		response.Write([]byte("ok\n"))
+		return
+	}
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+
+	order := request.FormValue("order")
+	switch order {
+	case "":
+		order = queryDigestsByTime
+	case queryDigestsByTime, queryDigestsByCount, queryDigestsByRows:
+	default:
+		http.Error(response, "order must be one of time, count and rows", http.StatusBadRequest)
+		return
+	}
+	limit := defaultQueryDigestsLimit
+	if value := request.FormValue("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			http.Error(response, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, evicted, since := qe.digests.counts()
+	report := struct {
+		Enabled bool
+		Since   time.Time
+		Entries int
+		Evicted int64
+		Order   string
+		Callers map[string][]*queryDigestEntry
+	}{
+		Enabled: qe.digests.enabled(),
+		Since:   since,
+		Entries: entries,
+		Evicted: evicted,
+		Order:   order,
+		Callers: qe.digests.topK(request.FormValue("caller"), order, limit),
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(report, "", " ")
+	if err != nil {
+		response.Write([]byte(err.Error()))
+		return
+	}
+	response.Write(b)
+}
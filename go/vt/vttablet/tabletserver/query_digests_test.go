@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestQueryDigests(t *testing.T) {
+	qd := newQueryDigests(4)
+	for i := 0; i < 3; i++ {
+		qd.record("u1", "select * from t1 where id = :redacted1", 10*time.Millisecond, 1, false)
+	}
+	qd.record("u1", "select * from t2", 100*time.Millisecond, 1000, false)
+	qd.record("u1", "insert into t1 values (:redacted1)", 5*time.Millisecond, 1, true)
+	qd.record("u2", "select * from t2", 20*time.Millisecond, 10, false)
+
+	top := qd.topK("", queryDigestsByTime, 2)
+	require.Len(t, top, 2)
+	require.Len(t, top["u1"], 2)
+	assert.Equal(t, "select * from t2", top["u1"][0].Digest)
+	assert.Equal(t, "select * from t1 where id = :redacted1", top["u1"][1].Digest)
+	assert.EqualValues(t, 3, top["u1"][1].Count)
+	assert.Equal(t, 30*time.Millisecond, top["u1"][1].TotalTime)
+	assert.Equal(t, 10*time.Millisecond, top["u1"][1].MaxTime)
+	require.Len(t, top["u2"], 1)
+	assert.EqualValues(t, 10, top["u2"][0].Rows)
+
+	top = qd.topK("u1", queryDigestsByCount, 0)
+	require.Len(t, top, 1)
+	require.Len(t, top["u1"], 3)
+	assert.Equal(t, "select * from t1 where id = :redacted1", top["u1"][0].Digest)
+	// The ties are ordered by digest.
+	assert.Equal(t, "insert into t1 values (:redacted1)", top["u1"][1].Digest)
+	assert.EqualValues(t, 1, top["u1"][1].ErrorCount)
+
+	top = qd.topK("u1", queryDigestsByRows, 1)
+	assert.Equal(t, "select * from t2", top["u1"][0].Digest)
+
+	// The top digests are copies.
+	top["u1"][0].Count = 100
+	assert.EqualValues(t, 1, qd.topK("u1", queryDigestsByRows, 1)["u1"][0].Count)
+
+	// Once full, the digest with the lowest total time is evicted.
+	qd.record("u3", "select 1", time.Millisecond, 1, false)
+	entries, evicted, _ := qd.counts()
+	assert.Equal(t, 4, entries)
+	assert.EqualValues(t, 1, evicted)
+	top = qd.topK("u1", queryDigestsByTime, 0)
+	require.Len(t, top["u1"], 2)
+	assert.Equal(t, "select * from t1 where id = :redacted1", top["u1"][1].Digest)
+
+	qd.reset()
+	entries, evicted, _ = qd.counts()
+	assert.Zero(t, entries)
+	assert.Zero(t, evicted)
+
+	// Nothing is recorded when the digests are disabled.
+	qd = newQueryDigests(0)
+	qd.record("u1", "select 1", time.Millisecond, 1, false)
+	entries, _, _ = qd.counts()
+	assert.Zero(t, entries)
+}
+
+func TestQueryDigest(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	qe := &QueryEngine{
+		env:     tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "QueryDigestTest"),
+		digests: newQueryDigests(10),
+	}
+	assert.Equal(t, "select * from t1 where id = :id /* INT64 */", qe.queryDigest("/* caller */ select * from t1 where id = 1"))
+	assert.Equal(t, qe.queryDigest("select * from t1 where id = 1"), qe.queryDigest("select * from t1 where id = 2"))
+
+	qe.digests = newQueryDigests(0)
+	assert.Empty(t, qe.queryDigest("select * from t1 where id = 1"))
+}
+
+func TestQueryDigestsHandler(t *testing.T) {
+	qe := &QueryEngine{digests: newQueryDigests(10)}
+	qe.digests.record("u1", "select * from t1", time.Millisecond, 1, false)
+	qe.digests.record("u2", "select * from t2", time.Millisecond, 1, false)
+
+	resp := httptest.NewRecorder()
+	qe.handleHTTPQueryDigests(resp, httptest.NewRequest(http.MethodGet, "/debug/query_digests?caller=u2&order=count&limit=5", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	var report struct {
+		Enabled bool
+		Entries int
+		Order   string
+		Callers map[string][]*queryDigestEntry
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+	assert.True(t, report.Enabled)
+	assert.Equal(t, 2, report.Entries)
+	assert.Equal(t, queryDigestsByCount, report.Order)
+	require.Len(t, report.Callers, 1)
+	require.Len(t, report.Callers["u2"], 1)
+	assert.Equal(t, "select * from t2", report.Callers["u2"][0].Digest)
+
+	resp = httptest.NewRecorder()
+	qe.handleHTTPQueryDigests(resp, httptest.NewRequest(http.MethodGet, "/debug/query_digests?order=size", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	qe.handleHTTPQueryDigests(resp, httptest.NewRequest(http.MethodGet, "/debug/query_digests?limit=0", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	qe.handleHTTPQueryDigests(resp, httptest.NewRequest(http.MethodPost, "/debug/query_digests", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	entries, _, _ := qe.digests.counts()
+	assert.Zero(t, entries)
+}
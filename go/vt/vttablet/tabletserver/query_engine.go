@@ -45,6 +45,8 @@ import (
 	"vitess.io/vitess/go/vt/tableacl"
 	tacl "vitess.io/vitess/go/vt/tableacl/acl"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/onlineddl"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/authz"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
@@ -63,12 +65,28 @@ type TabletPlan struct {
 	Rules      *rules.Rules
 	Authorized []*tableacl.ACLResult
 
+	// HintRules are the names of the query rules whose hints were injected
+	// into the plan, and DryRunHintRules the ones in dry run mode which
+	// would have injected theirs.
+	HintRules       []string
+	DryRunHintRules []string
+
+	// Digest is the normalized query of the plan, under which its executions
+	// are aggregated in the query digests.
+	Digest string
+
 	QueryCount   uint64
 	Time         uint64
 	MysqlTime    uint64
 	RowsAffected uint64
 	RowsReturned uint64
 	ErrorCount   uint64
+
+	// ResultCount, ResultRows and ResultBytes track the non-streaming results
+	// of the plan, to estimate their size.
+	ResultCount uint64
+	ResultRows  uint64
+	ResultBytes uint64
 }
 
 // AddStats updates the stats for the current TabletPlan.
@@ -92,6 +110,24 @@ func (ep *TabletPlan) Stats() (queryCount uint64, duration, mysqlTime time.Durat
 	return
 }
 
+// AddResultStats records the number of rows and size of a result of the plan.
+func (ep *TabletPlan) AddResultStats(rows, bytes uint64) {
+	atomic.AddUint64(&ep.ResultCount, 1)
+	atomic.AddUint64(&ep.ResultRows, rows)
+	atomic.AddUint64(&ep.ResultBytes, bytes)
+}
+
+// resultRowStats returns the average row size and number of rows of the
+// results of the plan.
+func (ep *TabletPlan) resultRowStats() (rowSize, rowsPerResult int64) {
+	count := atomic.LoadUint64(&ep.ResultCount)
+	rows := atomic.LoadUint64(&ep.ResultRows)
+	if count == 0 || rows == 0 {
+		return 0, 0
+	}
+	return int64(atomic.LoadUint64(&ep.ResultBytes) / rows), int64(rows / count)
+}
+
 // buildAuthorized builds 'Authorized', which is the runtime part for 'Permissions'.
 func (ep *TabletPlan) buildAuthorized() {
 	ep.Authorized = make([]*tableacl.ACLResult, len(ep.Permissions))
@@ -152,6 +188,7 @@ type QueryEngine struct {
 	schema   atomic.Pointer[currentSchema]
 
 	plans            *PlanCache
+	planCacheTTL     time.Duration
 	settings         *SettingsCache
 	queryRuleSources *rules.Map
 
@@ -169,17 +206,32 @@ type QueryEngine struct {
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
 
+	// memoryGovernor limits the memory used by the results of in-flight
+	// queries.
+	memoryGovernor *memoryGovernor
+
 	// Vars
 	maxResultSize    atomic.Int64
 	warnResultSize   atomic.Int64
 	streamBufferSize atomic.Int64
+
+	// streamSpoolSize is the number of results read ahead by streaming
+	// queries, and streamNetWriteTimeout the net_write_timeout of their
+	// connections, see streamSpool.
+	streamSpoolSize       int
+	streamNetWriteTimeout time.Duration
 	// tableaclExemptCount count the number of accesses allowed
 	// based on membership in the superuser ACL
 	tableaclExemptCount  atomic.Int64
 	strictTableACL       bool
 	enableTableACLDryRun bool
+	aclDryRun            *aclDryRunReport
+	digests              *queryDigests
 	// TODO(sougou) There are two acl packages. Need to rename.
 	exemptACL tacl.ACL
+	// externalAuthz is nil when no external authorization service is
+	// configured.
+	externalAuthz *authz.Checker
 
 	strictTransTables bool
 
@@ -213,6 +265,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	// Cache for query plans: user configured size with a doorkeeper by default to prevent one-off queries
 	// from thrashing the cache.
 	qe.plans = theine.NewStore[PlanCacheKey, *TabletPlan](config.QueryCacheMemory, config.QueryCacheDoorkeeper)
+	qe.planCacheTTL = config.QueryCacheTTL
 
 	// cache for connection settings: default to 1/4th of the size for the query cache and do
 	// not use a doorkeeper because custom connection settings are rarely one-off and we always
@@ -237,9 +290,12 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		log.Info("Stream consolidator is not enabled.")
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.memoryGovernor = newMemoryGovernor(env)
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
+	qe.aclDryRun = newACLDryRunReport()
+	qe.digests = newQueryDigests(config.QueryDigestsSize)
 
 	qe.strictTransTables = config.EnforceStrictTransTables
 
@@ -256,9 +312,13 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		}
 	}
 
+	qe.externalAuthz = authz.New(env)
+
 	qe.maxResultSize.Store(int64(config.Oltp.MaxRows))
 	qe.warnResultSize.Store(int64(config.Oltp.WarnRows))
 	qe.streamBufferSize.Store(int64(config.StreamBufferSize))
+	qe.streamSpoolSize = config.StreamSpoolSize
+	qe.streamNetWriteTimeout = config.StreamNetWriteTimeout
 
 	planbuilder.PassthroughDMLs = config.PassthroughDML
 
@@ -268,6 +328,22 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	env.Exporter().NewGaugeFunc("WarnResultSize", "Query engine warn result size", qe.warnResultSize.Load)
 	env.Exporter().NewGaugeFunc("StreamBufferSize", "Query engine stream buffer size", qe.streamBufferSize.Load)
 	env.Exporter().NewCounterFunc("TableACLExemptCount", "Query engine table ACL exempt count", qe.tableaclExemptCount.Load)
+	env.Exporter().NewGaugeFunc("TableACLDryRunReportEntries", "Number of callers and tables in the table ACL dry run report", func() int64 {
+		entries, _ := qe.aclDryRun.counts()
+		return int64(entries)
+	})
+	env.Exporter().NewCounterFunc("TableACLDryRunReportDropped", "Table ACL dry run denials which are not in the full report", func() int64 {
+		_, dropped := qe.aclDryRun.counts()
+		return dropped
+	})
+	env.Exporter().NewGaugeFunc("QueryDigestsEntries", "Number of callers and query digests tracked by the query digests", func() int64 {
+		entries, _, _ := qe.digests.counts()
+		return int64(entries)
+	})
+	env.Exporter().NewCounterFunc("QueryDigestsEvicted", "Query digests evicted because the query digests are full", func() int64 {
+		_, evicted, _ := qe.digests.counts()
+		return evicted
+	})
 
 	env.Exporter().NewGaugeFunc("QueryCacheLength", "Query engine query cache length", func() int64 {
 		return int64(qe.plans.Len())
@@ -308,6 +384,8 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	env.Exporter().HandleFunc("/debug/query_rules", qe.handleHTTPQueryRules)
 	env.Exporter().HandleFunc("/debug/consolidations", qe.handleHTTPConsolidations)
 	env.Exporter().HandleFunc("/debug/acl", qe.handleHTTPAclJSON)
+	env.Exporter().HandleFunc("/debug/tableacl_dry_run", qe.handleHTTPACLDryRun)
+	env.Exporter().HandleFunc("/debug/query_digests", qe.handleHTTPQueryDigests)
 
 	return qe
 }
@@ -338,6 +416,13 @@ func (qe *QueryEngine) Open() error {
 		return err
 	}
 
+	if qe.externalAuthz != nil {
+		if err := qe.externalAuthz.Open(); err != nil {
+			qe.conns.Close()
+			return vterrors.Wrapf(err, "cannot create the external authorization client for %v", config.ExternalAuthz.Address)
+		}
+	}
+
 	qe.streamConns.Open(config.DB.AppWithDB(), config.DB.DbaWithDB(), config.DB.AppDebugWithDB())
 	qe.se.RegisterNotifier("qe", qe.schemaChanged, true)
 	qe.plans.EnsureOpen()
@@ -360,6 +445,9 @@ func (qe *QueryEngine) Close() {
 	qe.settings.Close()
 
 	qe.streamConns.Close()
+	if qe.externalAuthz != nil {
+		qe.externalAuthz.Close()
+	}
 	qe.conns.Close()
 	log.Info("Query Engine: closed")
 }
@@ -375,8 +463,11 @@ func (qe *QueryEngine) getPlan(curSchema *currentSchema, sql string) (*TabletPla
 	if err != nil {
 		return nil, err
 	}
-	plan := &TabletPlan{Plan: splan, Original: sql}
+	plan := &TabletPlan{Plan: splan, Original: sql, Digest: qe.queryDigest(sql)}
 	plan.Rules = qe.queryRuleSources.FilterByPlan(sql, plan.PlanID, plan.TableNames()...)
+	qe.applyHintRules(plan, statement, func(hinted sqlparser.Statement) (*planbuilder.Plan, error) {
+		return planbuilder.Build(qe.env.Environment(), hinted, curSchema.tables, qe.env.Config().DB.DBName, qe.env.Config().EnableViews)
+	})
 	plan.buildAuthorized()
 	if sqlparser.CachePlan(statement) {
 		return plan, nil
@@ -398,7 +489,7 @@ func (qe *QueryEngine) GetPlan(ctx context.Context, logStats *tabletenv.LogStats
 	if skipQueryPlanCache {
 		plan, err = qe.getPlan(curSchema, sql)
 	} else {
-		plan, logStats.CachedPlan, err = qe.plans.GetOrLoad(PlanCacheKey(sql), curSchema.epoch, func() (*TabletPlan, error) {
+		plan, logStats.CachedPlan, err = qe.plans.GetOrLoadWithTTL(PlanCacheKey(sql), curSchema.epoch, qe.planCacheTTL, func() (*TabletPlan, error) {
 			return qe.getPlan(curSchema, sql)
 		})
 	}
@@ -421,8 +512,11 @@ func (qe *QueryEngine) getStreamPlan(curSchema *currentSchema, sql string) (*Tab
 		return nil, err
 	}
 
-	plan := &TabletPlan{Plan: splan, Original: sql}
+	plan := &TabletPlan{Plan: splan, Original: sql, Digest: qe.queryDigest(sql)}
 	plan.Rules = qe.queryRuleSources.FilterByPlan(sql, plan.PlanID, plan.TableName().String())
+	qe.applyHintRules(plan, statement, func(hinted sqlparser.Statement) (*planbuilder.Plan, error) {
+		return planbuilder.BuildStreaming(hinted, curSchema.tables)
+	})
 	plan.buildAuthorized()
 
 	if sqlparser.CachePlan(statement) {
@@ -445,7 +539,7 @@ func (qe *QueryEngine) GetStreamPlan(ctx context.Context, logStats *tabletenv.Lo
 	if skipQueryPlanCache {
 		plan, err = qe.getStreamPlan(curSchema, sql)
 	} else {
-		plan, logStats.CachedPlan, err = qe.plans.GetOrLoad(PlanCacheKey(qe.getStreamPlanCacheKey(sql)), curSchema.epoch, func() (*TabletPlan, error) {
+		plan, logStats.CachedPlan, err = qe.plans.GetOrLoadWithTTL(PlanCacheKey(qe.getStreamPlanCacheKey(sql)), curSchema.epoch, qe.planCacheTTL, func() (*TabletPlan, error) {
 			return qe.getStreamPlan(curSchema, sql)
 		})
 	}
@@ -517,6 +611,34 @@ func (qe *QueryEngine) ForEachPlan(each func(plan *TabletPlan) bool) {
 	})
 }
 
+// RecentQueries returns the query digests and the queries of the cached
+// plans, which the Online DDL executor searches for references to the tables
+// and columns dropped by the migrations.
+func (qe *QueryEngine) RecentQueries() []*onlineddl.RecentQuery {
+	var queries []*onlineddl.RecentQuery
+	for _, entries := range qe.digests.topK("", queryDigestsByTime, 0) {
+		for _, entry := range entries {
+			queries = append(queries, &onlineddl.RecentQuery{
+				Query:    entry.Digest,
+				Source:   "query_digests",
+				Caller:   entry.Caller,
+				Count:    uint64(entry.Count),
+				LastSeen: &entry.LastSeen,
+			})
+		}
+	}
+	qe.ForEachPlan(func(plan *TabletPlan) bool {
+		queryCount, _, _, _, _, _ := plan.Stats()
+		queries = append(queries, &onlineddl.RecentQuery{
+			Query:  plan.Original,
+			Source: "plan_cache",
+			Count:  queryCount,
+		})
+		return true
+	})
+	return queries
+}
+
 // IsMySQLReachable returns an error if it cannot connect to MySQL.
 // This can be called before opening the QueryEngine.
 func (qe *QueryEngine) IsMySQLReachable() error {
@@ -531,6 +653,15 @@ func (qe *QueryEngine) IsMySQLReachable() error {
 	return nil
 }
 
+// ConnectError returns an error if the connection pools of the query engine
+// are backing off after failing to connect to MySQL.
+func (qe *QueryEngine) ConnectError() error {
+	if err := qe.conns.ConnectError(); err != nil {
+		return err
+	}
+	return qe.streamConns.ConnectError()
+}
+
 func (qe *QueryEngine) schemaChanged(tables map[string]*schema.Table, created, altered, dropped []*schema.Table, _ bool) {
 	qe.schemaMu.Lock()
 	defer qe.schemaMu.Unlock()
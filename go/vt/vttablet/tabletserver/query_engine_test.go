@@ -221,6 +221,65 @@ func TestQueryPlanCache(t *testing.T) {
 	qe.ClearQueryPlanCache()
 }
 
+func TestQueryPlanCacheTTL(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	schematest.AddDefaultQueries(db)
+	db.AddQuery("select * from test_table_01 where 1 != 1", &sqltypes.Result{})
+
+	qe := newTestQueryEngine(10*time.Second, true, newDBConfigs(db))
+	qe.planCacheTTL = time.Millisecond
+	qe.se.Open()
+	qe.Open()
+	defer qe.Close()
+
+	ctx := context.Background()
+	logStats := tabletenv.NewLogStats(ctx, "GetPlanStats")
+
+	initialMisses := qe.queryCacheMisses.Get()
+
+	_, err := qe.GetPlan(ctx, logStats, "select * from test_table_01", false)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	// The plan expired, so it is planned again.
+	_, err = qe.GetPlan(ctx, logStats, "select * from test_table_01", false)
+	require.NoError(t, err)
+	require.False(t, logStats.CachedPlan)
+	require.Equal(t, int64(2), qe.queryCacheMisses.Get()-initialMisses)
+}
+
+func TestRecentQueries(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	schematest.AddDefaultQueries(db)
+	db.AddQuery("select * from test_table_01 where 1 != 1", &sqltypes.Result{})
+
+	qe := newTestQueryEngine(10*time.Second, true, newDBConfigs(db))
+	qe.se.Open()
+	qe.Open()
+	defer qe.Close()
+
+	ctx := context.Background()
+	logStats := tabletenv.NewLogStats(ctx, "GetPlanStats")
+	plan, err := qe.GetPlan(ctx, logStats, "select * from test_table_01", false)
+	require.NoError(t, err)
+	plan.AddStats(3, time.Millisecond, time.Millisecond, 0, 1, 0)
+	assertPlanCacheSize(t, qe, 1)
+	qe.digests = newQueryDigests(10)
+	qe.digests.record("cron", "select c1 from test_table_01 where id = :id", time.Millisecond, 1, false)
+
+	queries := qe.RecentQueries()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "select c1 from test_table_01 where id = :id", queries[0].Query)
+	assert.Equal(t, "query_digests", queries[0].Source)
+	assert.Equal(t, "cron", queries[0].Caller)
+	assert.EqualValues(t, 1, queries[0].Count)
+	assert.Equal(t, "select * from test_table_01", queries[1].Query)
+	assert.Equal(t, "plan_cache", queries[1].Source)
+	assert.EqualValues(t, 3, queries[1].Count)
+}
+
 func TestNoQueryPlanCache(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -21,16 +21,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
@@ -38,6 +43,7 @@ import (
 	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
@@ -46,6 +52,7 @@ import (
 	eschema "vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -67,6 +74,8 @@ type QueryExecutor struct {
 	// The target type we requested might be different from tsv's tablet type, if we had a change to the tablet type recently.
 	targetTabletType topodatapb.TabletType
 	setting          *smartconnpool.Setting
+	// resourceGroup is the MySQL resource group the query rules assigned the query to.
+	resourceGroup string
 }
 
 const (
@@ -141,8 +150,10 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if reply == nil {
 			qre.tsv.qe.AddStats(qre.plan, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, 0, 0, 1, errCode)
 			qre.plan.AddStats(1, duration, mysqlTime, 0, 0, 1)
+			qre.recordQueryDigest(duration, 0, true)
 			return
 		}
+		qre.recordQueryDigest(duration, int64(reply.RowsAffected)+int64(len(reply.Rows)), err != nil)
 
 		qre.tsv.qe.AddStats(qre.plan, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, int64(reply.RowsAffected), int64(len(reply.Rows)), 0, errCode)
 		qre.plan.AddStats(1, duration, mysqlTime, reply.RowsAffected, uint64(len(reply.Rows)), 0)
@@ -155,6 +166,18 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return nil, err
 	}
 
+	if err = qre.checkStaleness(); err != nil {
+		return nil, err
+	}
+
+	if err = qre.checkPinnedTablet(); err != nil {
+		return nil, err
+	}
+
+	if err = qre.tsv.faults.injectQuery(qre.ctx, qre.plan.TableNames()); err != nil {
+		return nil, err
+	}
+
 	if qre.plan.PlanID == p.PlanNextval {
 		return qre.execNextval()
 	}
@@ -167,18 +190,28 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 			return nil, err
 		}
 		defer conn.Unlock()
+		idleWarning := qre.tsv.te.txPool.idleTransactionWarning(conn)
 		if qre.setting != nil {
 			if err = conn.ApplySetting(qre.ctx, qre.setting); err != nil {
 				return nil, vterrors.Wrap(err, "failed to execute system setting on the connection")
 			}
 		}
-		return qre.txConnExec(conn)
+		reply, err = qre.txConnExec(conn)
+		if err == nil && idleWarning != nil {
+			reply.Warnings = append(reply.Warnings, idleWarning)
+		}
+		return reply, err
 	}
 
 	switch qre.plan.PlanID {
 	case p.PlanSelect, p.PlanSelectImpossible, p.PlanShow:
 		maxrows := qre.getSelectLimit()
-		qre.bindVars["#maxLimit"] = sqltypes.Int64BindVariable(maxrows + 1)
+		limit, reservation, err := qre.tsv.qe.memoryGovernor.admitSelect(qre.plan, maxrows)
+		if err != nil {
+			return nil, err
+		}
+		defer reservation.release()
+		qre.bindVars["#maxLimit"] = sqltypes.Int64BindVariable(limit + 1)
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
 			qre.bindVars[sqltypes.BvSchemaName] = sqltypes.StringBindVariable(qre.tsv.config.DB.DBName)
 		}
@@ -186,15 +219,44 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if err != nil {
 			return nil, err
 		}
+		// Sizing the result walks all its rows, so it is only done when the
+		// memory governor is enabled, i.e. it reserved memory for the query.
+		if reservation != nil {
+			size := qr.CachedSize(true)
+			reservation.resize(size)
+			qre.plan.AddResultStats(uint64(len(qr.Rows)), uint64(size))
+		}
+		if limit < maxrows && int64(len(qr.Rows)) > limit {
+			return nil, qre.tsv.qe.memoryGovernor.rowLimitError(limit)
+		}
 		if err := qre.verifyRowCount(int64(len(qr.Rows)), maxrows); err != nil {
 			return nil, err
 		}
 		return qr, nil
 	case p.PlanOtherRead, p.PlanOtherAdmin, p.PlanFlush, p.PlanSavepoint, p.PlanRelease, p.PlanSRollback:
 		return qre.execOther()
-	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanInsertMessage, p.PlanDDL, p.PlanLoad:
+	case p.PlanInsert, p.PlanUpdate, p.PlanDelete:
+		if size := qre.dmlChunkSize(); size > 0 {
+			return qre.execChunkedDML(size)
+		}
+		if qre.options.GetIdempotencyToken() != "" {
+			return qre.execAsTransaction(qre.withIdempotencyToken(qre.txConnExec))
+		}
+		return qre.execAutocommitDML()
+	case p.PlanInsertMessage:
+		if qre.options.GetIdempotencyToken() != "" {
+			return qre.execAsTransaction(qre.withIdempotencyToken(qre.txConnExec))
+		}
+		return qre.execAutocommitDML()
+	case p.PlanDDL, p.PlanLoad:
 		return qre.execAutocommit(qre.txConnExec)
 	case p.PlanUpdateLimit, p.PlanDeleteLimit:
+		if size := qre.dmlChunkSize(); size > 0 {
+			return qre.execChunkedDML(size)
+		}
+		if qre.options.GetIdempotencyToken() != "" {
+			return qre.execAsTransaction(qre.withIdempotencyToken(qre.txConnExec))
+		}
 		return qre.execAsTransaction(qre.txConnExec)
 	case p.PlanCallProc:
 		return qre.execCallProc()
@@ -210,6 +272,8 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return qre.execShowThrottledApps()
 	case p.PlanShowThrottlerStatus:
 		return qre.execShowThrottlerStatus()
+	case p.PlanShowWorkflows:
+		return qre.execShowWorkflows()
 	case p.PlanUnlockTables:
 		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unlock tables should be executed with an existing connection")
 	case p.PlanSet:
@@ -244,6 +308,17 @@ func (qre *QueryExecutor) execAutocommit(f func(conn *StatefulConnection) (*sqlt
 	return f(conn)
 }
 
+// execAutocommitDML executes a DML outside of a transaction. It runs in a
+// transaction of its own when the transaction size limits apply to its
+// table, so that it is rolled back, instead of committed, when it exceeds
+// them.
+func (qre *QueryExecutor) execAutocommitDML() (*sqltypes.Result, error) {
+	if qre.tsv.te.txPool.sizeGuard.limits(callerid.ImmediateCallerIDFromContext(qre.ctx), callerid.EffectiveCallerIDFromContext(qre.ctx), qre.plan.TableName().String()) {
+		return qre.execAsTransaction(qre.txConnExec)
+	}
+	return qre.execAutocommit(qre.txConnExec)
+}
+
 func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
 	if qre.tsv.txThrottler.Throttle(qre.tsv.getPriorityFromOptions(qre.options), qre.options.GetWorkloadName()) {
 		return nil, errTxThrottled
@@ -277,11 +352,39 @@ func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*s
 
 func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result, error) {
 	switch qre.plan.PlanID {
-	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanSet:
+	case p.PlanSet:
 		return qre.txFetch(conn, true)
+	case p.PlanInsert:
+		result, err := qre.txFetch(conn, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := qre.verifyTxSize(conn, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case p.PlanUpdate, p.PlanDelete:
+		result, err := qre.txFetch(conn, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := qre.verifyTxSize(conn, result); err != nil {
+			return nil, err
+		}
+		if err := qre.verifyVersionConflict(result); err != nil {
+			return nil, err
+		}
+		return result, nil
 	case p.PlanInsertMessage:
 		qre.bindVars["#time_now"] = sqltypes.Int64BindVariable(time.Now().UnixNano())
-		return qre.txFetch(conn, true)
+		result, err := qre.txFetch(conn, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := qre.verifyTxSize(conn, result); err != nil {
+			return nil, err
+		}
+		return result, nil
 	case p.PlanUpdateLimit, p.PlanDeleteLimit:
 		return qre.execDMLLimit(conn)
 	case p.PlanOtherRead, p.PlanOtherAdmin, p.PlanFlush, p.PlanUnlockTables:
@@ -308,24 +411,56 @@ func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result
 		return qre.execLoad(conn)
 	case p.PlanCallProc:
 		return qre.execProc(conn)
+	case p.PlanShowWorkflows:
+		query, err := qre.showWorkflowsQuery()
+		if err != nil {
+			return nil, err
+		}
+		return qre.execStatefulConn(conn, query, true)
 	}
 	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] %s unexpected plan type", qre.plan.PlanID.String())
 }
 
 // Stream performs a streaming query execution.
-func (qre *QueryExecutor) Stream(callback StreamCallback) error {
+func (qre *QueryExecutor) Stream(callback StreamCallback) (err error) {
 	qre.logStats.PlanType = qre.plan.PlanID.String()
 
+	var rows int64
+	if qre.tsv.qe.digests.enabled() {
+		streamCallback := callback
+		callback = func(result *sqltypes.Result) error {
+			rows += int64(len(result.Rows))
+			return streamCallback(result)
+		}
+	}
 	defer func(start time.Time) {
 		qre.tsv.stats.QueryTimings.Record(qre.plan.PlanID.String(), start)
 		qre.tsv.stats.QueryTimingsByTabletType.Record(qre.targetTabletType.String(), start)
 		qre.recordUserQuery("Stream", int64(time.Since(start)))
+		qre.recordQueryDigest(time.Since(start), rows, err != nil)
 	}(time.Now())
 
 	if err := qre.checkPermissions(); err != nil {
 		return err
 	}
 
+	if err := qre.checkStaleness(); err != nil {
+		return err
+	}
+
+	if err := qre.checkPinnedTablet(); err != nil {
+		return err
+	}
+
+	if err := qre.tsv.faults.injectQuery(qre.ctx, qre.plan.TableNames()); err != nil {
+		return err
+	}
+
+	if err := qre.tsv.qe.memoryGovernor.admitStream(); err != nil {
+		return err
+	}
+	callback = qre.tsv.qe.memoryGovernor.trackStream(callback)
+
 	switch qre.plan.PlanID {
 	case p.PlanSelectStream:
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
@@ -431,8 +566,44 @@ func (qre *QueryExecutor) MessageStream(callback StreamCallback) error {
 	return nil
 }
 
+// checkStaleness rejects the query if it requires a maximum replication lag,
+// and this tablet is not a primary and lags further behind, or does not know
+// its lag. The error is retryable, so that vtgate retries the query on a
+// fresher tablet.
+func (qre *QueryExecutor) checkStaleness() error {
+	maxStalenessMs := qre.options.GetMaxStalenessMs()
+	if maxStalenessMs <= 0 || qre.targetTabletType == topodatapb.TabletType_PRIMARY {
+		return nil
+	}
+
+	maxStaleness := time.Duration(maxStalenessMs) * time.Millisecond
+	lag, err := qre.tsv.sm.rt.Status()
+	if err != nil {
+		return vterrors.VT14006(fmt.Sprintf("unknown (%v)", err), maxStaleness)
+	}
+	if lag > maxStaleness {
+		return vterrors.VT14006(lag, maxStaleness)
+	}
+	return nil
+}
+
+// checkPinnedTablet rejects the query if vtgate pinned it to another tablet,
+// and counts the queries pinned to this tablet.
+func (qre *QueryExecutor) checkPinnedTablet() error {
+	pinnedTablet := qre.options.GetPinnedTabletAlias()
+	if pinnedTablet == nil {
+		return nil
+	}
+	if !proto.Equal(pinnedTablet, qre.tsv.alias) {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "query pinned to tablet %s received by tablet %s", topoproto.TabletAliasString(pinnedTablet), topoproto.TabletAliasString(qre.tsv.alias))
+	}
+	qre.tsv.Stats().UserPinnedQueryCount.Add(callerid.GetUsername(callerid.ImmediateCallerIDFromContext(qre.ctx)), 1)
+	return nil
+}
+
 // checkPermissions returns an error if the query does not pass all checks
-// (denied query, table ACL).
+// (denied query, table ACL). It also assigns the query to the MySQL resource
+// group of the first matching rule that has one.
 func (qre *QueryExecutor) checkPermissions() error {
 	// Skip permissions check if the context is local.
 	if tabletenv.IsLocalContext(qre.ctx) {
@@ -448,7 +619,7 @@ func (qre *QueryExecutor) checkPermissions() error {
 		username = ci.Username()
 	}
 
-	action, ruleCancelCtx, timeout, desc := qre.plan.Rules.GetAction(remoteAddr, username, qre.bindVars, qre.marginComments)
+	action, ruleCancelCtx, timeout, desc := qre.plan.Rules.GetAction(remoteAddr, username, qre.options.GetWorkloadName(), qre.bindVars, qre.marginComments)
 
 	bufferingTimeoutCtx, cancel := context.WithTimeout(qre.ctx, timeout) // aborts buffering at given timeout
 	defer cancel()
@@ -473,6 +644,7 @@ func (qre *QueryExecutor) checkPermissions() error {
 	default:
 		// no rules against this query. Good to proceed
 	}
+	qre.resourceGroup = qre.plan.Rules.GetResourceGroup(remoteAddr, username, qre.options.GetWorkloadName(), qre.bindVars, qre.marginComments)
 	// Skip ACL check for queries against the dummy dual table
 	if qre.plan.TableName().String() == "dual" {
 		return nil
@@ -489,7 +661,12 @@ func (qre *QueryExecutor) checkPermissions() error {
 		if qre.tsv.qe.strictTableACL {
 			return vterrors.Errorf(vtrpcpb.Code_UNAUTHENTICATED, "missing caller id")
 		}
-		return nil
+		if qre.tsv.qe.enableTableACLDryRun {
+			for i, auth := range qre.plan.Authorized {
+				qre.tsv.qe.aclDryRun.record("", nil, qre.plan.Permissions[i].TableName, auth.GroupName, qre.plan.PlanID.String(), qre.aclDryRunSample)
+			}
+		}
+		return qre.checkExternalAuthz(nil)
 	}
 
 	// Skip the ACL check if the caller id is an exempted superuser.
@@ -504,7 +681,28 @@ func (qre *QueryExecutor) checkPermissions() error {
 		}
 	}
 
-	return nil
+	return qre.checkExternalAuthz(callerID)
+}
+
+// checkExternalAuthz returns an error if the external authorization service,
+// when one is configured, denies the query.
+func (qre *QueryExecutor) checkExternalAuthz(callerID *querypb.VTGateCallerID) error {
+	if qre.tsv.qe.externalAuthz == nil {
+		return nil
+	}
+	req := &authzpb.AuthorizeRequest{
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(qre.ctx),
+		ImmediateCallerId: callerID,
+		Target:            qre.tsv.sm.Target(),
+		StatementType:     qre.plan.PlanID.String(),
+	}
+	for _, permission := range qre.plan.Permissions {
+		req.Tables = append(req.Tables, &authzpb.TableAccess{
+			Name: permission.TableName,
+			Role: permission.Role.Name(),
+		})
+	}
+	return qre.tsv.qe.externalAuthz.Check(qre.ctx, req)
 }
 
 func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName string, callerID *querypb.VTGateCallerID) error {
@@ -512,6 +710,7 @@ func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName
 	if !authorized.IsMember(callerID) {
 		if qre.tsv.qe.enableTableACLDryRun {
 			qre.tsv.Stats().TableaclPseudoDenied.Add(statsKey, 1)
+			qre.tsv.qe.aclDryRun.record(callerID.Username, callerID.Groups, tableName, authorized.GroupName, qre.plan.PlanID.String(), qre.aclDryRunSample)
 			return nil
 		}
 
@@ -536,6 +735,17 @@ func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName
 	return nil
 }
 
+// aclDryRunSample returns the query as a sample of the table ACL dry run
+// report, redacted as in the debug UIs.
+func (qre *QueryExecutor) aclDryRunSample() string {
+	parser := qre.tsv.env.Parser()
+	query := qre.query
+	if streamlog.GetRedactDebugUIQueries() || qre.tsv.config.SanitizeLogMessages {
+		query, _ = parser.RedactSQLQuery(query)
+	}
+	return parser.TruncateForUI(query)
+}
+
 func (qre *QueryExecutor) execDDL(conn *StatefulConnection) (*sqltypes.Result, error) {
 	// Let's see if this is a normal DDL statement or an Online DDL statement.
 	// An Online DDL statement is identified by /*vt+ .. */ comment with expected directives, like uuid etc.
@@ -668,11 +878,12 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 				newLast += cache
 			}
 			query = fmt.Sprintf("update %s set next_id = %d where id = 0", sqlparser.String(tableName), newLast)
-			conn.TxProperties().RecordQuery(query)
-			_, err = qre.execStatefulConn(conn, query, false)
+			start := time.Now()
+			qr, err = qre.execStatefulConn(conn, query, false)
 			if err != nil {
 				return nil, err
 			}
+			conn.TxProperties().RecordStatement(query, time.Since(start), qr.RowsAffected)
 			t.SequenceInfo.LastVal = newLast
 			return nil, nil
 		})
@@ -747,9 +958,43 @@ func (qre *QueryExecutor) execDMLLimit(conn *StatefulConnection) (*sqltypes.Resu
 		_ = qre.tsv.te.txPool.Rollback(qre.ctx, conn)
 		return nil, err
 	}
+	if err := qre.verifyTxSize(conn, result); err != nil {
+		return nil, err
+	}
+	if err := qre.verifyVersionConflict(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// verifyVersionConflict returns a version conflict error if the UPDATE or
+// DELETE statement compares the version column of its table to a value but
+// affected no row, when it is requested by the execute options.
+func (qre *QueryExecutor) verifyVersionConflict(result *sqltypes.Result) error {
+	if !qre.options.GetVersionConflictError() || !qre.plan.VersionPredicate || result.RowsAffected != 0 {
+		return nil
+	}
+	return sqlerror.NewSQLError(sqlerror.ERVersionConflict, sqlerror.SSLockDeadlock, "version conflict: no row of table %s matched the value of the %s version column",
+		qre.plan.TableName().String(), qre.plan.Table.VersionColumn.String())
+}
+
+// verifyTxSize accounts for the rows the DML statement modified in the
+// transaction, and returns an error if the transaction exceeded the
+// transaction size limits of the table. The binlog bytes are estimated from
+// the size of the rows, counted twice for the updates which log the rows
+// before and after they change.
+func (qre *QueryExecutor) verifyTxSize(conn *StatefulConnection, result *sqltypes.Result) error {
+	guard := qre.tsv.te.txPool.sizeGuard
+	if !guard.enabled() {
+		return nil
+	}
+	bytes := result.RowsAffected * rowImageSize(qre.plan.Table)
+	if qre.plan.PlanID == p.PlanUpdate || qre.plan.PlanID == p.PlanUpdateLimit {
+		bytes *= 2
+	}
+	return guard.record(conn.TxProperties(), qre.plan.TableName().String(), result.RowsAffected, bytes)
+}
+
 func (qre *QueryExecutor) verifyRowCount(count, maxrows int64) error {
 	if count > maxrows {
 		callerID := callerid.ImmediateCallerIDFromContext(qre.ctx)
@@ -799,13 +1044,14 @@ func (qre *QueryExecutor) txFetch(conn *StatefulConnection, record bool) (*sqlty
 	if err != nil {
 		return nil, err
 	}
+	start := time.Now()
 	qr, err := qre.execStatefulConn(conn, sql, true)
 	if err != nil {
 		return nil, err
 	}
 	// Only record successful queries.
 	if record {
-		conn.TxProperties().RecordQuery(sql)
+		conn.TxProperties().RecordStatement(sql, time.Since(start), qr.RowsAffected)
 	}
 	return qr, nil
 }
@@ -815,6 +1061,27 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	if err != nil {
 		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
 	}
+	if qre.resourceGroup != "" {
+		var ok bool
+		if query, ok = addResourceGroupHint(query, qre.resourceGroup); ok {
+			qre.tsv.Stats().ResourceGroupQueryCount.Add(qre.resourceGroup, 1)
+		}
+	}
+	for _, name := range qre.plan.HintRules {
+		qre.tsv.Stats().HintRuleQueryCount.Add([]string{name, "applied"}, 1)
+	}
+	for _, name := range qre.plan.DryRunHintRules {
+		qre.tsv.Stats().HintRuleQueryCount.Add([]string{name, "dry_run"}, 1)
+	}
+	// The deadline hint is left out of the query returned without comments,
+	// which is the key of the consolidators.
+	sql := query
+	if qre.tsv.config.PropagateDeadline {
+		if deadline, ok := qre.ctx.Deadline(); ok {
+			sql, _ = addMaxExecutionTimeHint(sql, time.Until(deadline))
+		}
+	}
+	leading := qre.marginComments.Leading
 	if qre.tsv.config.AnnotateQueries {
 		username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 		if username == "" {
@@ -828,22 +1095,79 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 		buf.WriteString("@")
 		buf.WriteString(tabletTypeStr)
 		buf.WriteString(" */ ")
-		buf.WriteString(qre.marginComments.Leading)
-		qre.marginComments.Leading = buf.String()
+		buf.WriteString(leading)
+		leading = buf.String()
 	}
 
-	if qre.marginComments.Leading == "" && qre.marginComments.Trailing == "" {
-		return query, query, nil
+	if leading == "" && qre.marginComments.Trailing == "" {
+		return sql, query, nil
 	}
 
 	var buf strings.Builder
-	buf.Grow(len(qre.marginComments.Leading) + len(query) + len(qre.marginComments.Trailing))
-	buf.WriteString(qre.marginComments.Leading)
-	buf.WriteString(query)
+	buf.Grow(len(leading) + len(sql) + len(qre.marginComments.Trailing))
+	buf.WriteString(leading)
+	buf.WriteString(sql)
 	buf.WriteString(qre.marginComments.Trailing)
 	return buf.String(), query, nil
 }
 
+// addResourceGroupHint adds the RESOURCE_GROUP optimizer hint to the query,
+// right after its leading SELECT, INSERT, REPLACE, UPDATE or DELETE keyword,
+// and merges it into an existing optimizer hint comment since MySQL only
+// honors the first one. It returns false for the other statements, which
+// can't carry the hint.
+func addResourceGroupHint(query, resourceGroup string) (string, bool) {
+	pos, keyword := leadingKeyword(query)
+	switch keyword {
+	case "select", "insert", "replace", "update", "delete":
+	default:
+		return query, false
+	}
+	return addOptimizerHint(query, pos, "RESOURCE_GROUP("+resourceGroup+")"), true
+}
+
+// addMaxExecutionTimeHint adds the MAX_EXECUTION_TIME optimizer hint to the
+// query, so that MySQL stops executing it once the timeout, rounded up to a
+// millisecond, expires. It returns false for the statements other than
+// SELECT, which MySQL doesn't time out, and for the queries that already
+// carry the hint.
+func addMaxExecutionTimeHint(query string, timeout time.Duration) (string, bool) {
+	pos, keyword := leadingKeyword(query)
+	if keyword != "select" {
+		return query, false
+	}
+	rest := strings.TrimLeft(query[pos:], " \t\r\n")
+	if strings.HasPrefix(rest, "/*+") {
+		if end := strings.Index(rest, "*/"); end > 0 && strings.Contains(strings.ToUpper(rest[:end]), sqlparser.OptimizerHintMaxExecutionTime) {
+			return query, false
+		}
+	}
+	ms := max((timeout+time.Millisecond-1)/time.Millisecond, 1)
+	return addOptimizerHint(query, pos, fmt.Sprintf("%s(%d)", sqlparser.OptimizerHintMaxExecutionTime, ms)), true
+}
+
+// leadingKeyword returns the leading keyword of the query in lower case, and
+// the position right after it.
+func leadingKeyword(query string) (int, string) {
+	trimmed := strings.TrimLeft(query, " \t\r\n(")
+	end := strings.IndexAny(trimmed, " \t\r\n/")
+	if end <= 0 {
+		return 0, ""
+	}
+	return len(query) - len(trimmed) + end, strings.ToLower(trimmed[:end])
+}
+
+// addOptimizerHint adds the optimizer hint to the query at pos, merging it
+// into the optimizer hint comment found there since MySQL only honors the
+// first one.
+func addOptimizerHint(query string, pos int, hint string) string {
+	rest := strings.TrimLeft(query[pos:], " \t\r\n")
+	if strings.HasPrefix(rest, "/*+") {
+		return query[:pos] + " /*+ " + hint + " " + strings.TrimLeft(rest[3:], " \t\r\n")
+	}
+	return query[:pos] + " /*+ " + hint + " */ " + rest
+}
+
 func rewriteOUTParamError(err error) error {
 	sqlErr, ok := err.(*sqlerror.SQLError)
 	if !ok {
@@ -1046,6 +1370,54 @@ func (qre *QueryExecutor) execShowThrottlerStatus() (*sqltypes.Result, error) {
 	return result, nil
 }
 
+// sqlShowWorkflows reads the vreplication streams of the tablet, with the
+// keyspace and shard of the tablet so that the rows of all the shards can be
+// told apart once vtgate merges them.
+const sqlShowWorkflows = "select %s as keyspace, %s as shard, id, workflow, source, pos, stop_pos, state, message, db_name, " +
+	"workflow_type, workflow_sub_type, time_updated, transaction_timestamp, time_heartbeat, time_throttled, component_throttled, " +
+	"rows_copied, tags, max_tps, max_replication_lag, cell, tablet_types, defer_secondary_keys " +
+	"from %s.vreplication%s"
+
+func (qre *QueryExecutor) execShowWorkflows() (*sqltypes.Result, error) {
+	query, err := qre.showWorkflowsQuery()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := qre.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+	return qre.execDBConn(conn.Conn, query, true)
+}
+
+// showWorkflowsQuery returns the query of a SHOW VITESS_WORKFLOWS statement.
+// The LIKE pattern matches the workflow or its state, like the one of SHOW
+// VITESS_MIGRATIONS matches the migration or its status.
+func (qre *QueryExecutor) showWorkflowsQuery() (string, error) {
+	show, ok := qre.plan.FullStmt.(*sqlparser.Show)
+	if !ok {
+		return "", vterrors.New(vtrpcpb.Code_INTERNAL, "Expecting SHOW VITESS_WORKFLOWS plan")
+	}
+	showBasic, ok := show.Internal.(*sqlparser.ShowBasic)
+	if !ok || showBasic.Command != sqlparser.VitessWorkflows {
+		return "", vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] expecting a SHOW VITESS_WORKFLOWS statement, got: %s", sqlparser.String(show))
+	}
+	whereExpr := ""
+	if showBasic.Filter != nil {
+		if showBasic.Filter.Filter != nil {
+			whereExpr = " where " + sqlparser.String(showBasic.Filter.Filter)
+		} else if showBasic.Filter.Like != "" {
+			lit := sqlparser.String(sqlparser.NewStrLiteral(showBasic.Filter.Like))
+			whereExpr = fmt.Sprintf(" where workflow like %s or state like %s", lit, lit)
+		}
+	}
+	return sqlparser.BuildParsedQuery(sqlShowWorkflows,
+		sqlparser.String(sqlparser.NewStrLiteral(qre.tsv.sm.target.Keyspace)),
+		sqlparser.String(sqlparser.NewStrLiteral(qre.tsv.sm.target.Shard)),
+		sidecar.GetIdentifier(), whereExpr).Query, nil
+}
+
 func (qre *QueryExecutor) drainResultSetOnConn(conn *connpool.Conn) error {
 	more := true
 	for more {
@@ -1117,24 +1489,92 @@ func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction
 			return err
 		}
 		defer qre.tsv.statefulql.Remove(qd)
-		return conn.Conn.StreamOnce(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+	} else {
+		err := qre.tsv.olapql.Add(qd)
+		if err != nil {
+			return err
+		}
+		defer qre.tsv.olapql.Remove(qd)
 	}
-	err := qre.tsv.olapql.Add(qd)
+
+	if timeout := qre.tsv.qe.streamNetWriteTimeout; timeout > 0 {
+		// MySQL must keep the query while reading its results is paused.
+		restore, err := setNetWriteTimeout(ctx, conn.Conn, timeout)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	var spool *streamSpool
+	if size := qre.tsv.qe.streamSpoolSize; size > 0 {
+		spool = newStreamSpool(size, callBackClosingSpan, qre.tsv.qe.memoryGovernor, qre.tsv.stats.WaitTimings)
+		callBackClosingSpan = spool.add
+	}
+	var err error
+	if isTransaction {
+		err = conn.Conn.StreamOnce(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+	} else {
+		err = conn.Conn.Stream(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+	}
+	if spool != nil {
+		return spool.close(err)
+	}
+	return err
+}
+
+// setNetWriteTimeout sets the net_write_timeout of the session of conn, and
+// returns a function restoring the previous one once the stream is over. The
+// connection is closed if it cannot be restored, so that the pool does not
+// hand it out with the timeout of the stream.
+func setNetWriteTimeout(ctx context.Context, conn *connpool.Conn, timeout time.Duration) (func(), error) {
+	qr, err := conn.Exec(ctx, "select @@session.net_write_timeout", 1, false)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for net_write_timeout: %v", qr.Rows)
+	}
+	previous, err := qr.Rows[0][0].ToInt64()
+	if err != nil {
+		return nil, err
 	}
-	defer qre.tsv.olapql.Remove(qd)
-	return conn.Conn.Stream(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+	if _, err := conn.Exec(ctx, fmt.Sprintf("set @@session.net_write_timeout = %d", int64(math.Ceil(timeout.Seconds()))), 1, false); err != nil {
+		return nil, err
+	}
+	return func() {
+		// The context of the stream may be done already.
+		if _, err := conn.Exec(tabletenv.LocalContext(), fmt.Sprintf("set @@session.net_write_timeout = %d", previous), 1, false); err != nil {
+			log.Warningf("Cannot restore the net_write_timeout of the stream connection, closing it: %v", err)
+			conn.Close()
+		}
+	}, nil
 }
 
 func (qre *QueryExecutor) recordUserQuery(queryType string, duration int64) {
+	username := qre.username()
+	tableName := qre.plan.TableName().String()
+	qre.tsv.Stats().UserTableQueryCount.Add([]string{tableName, username, queryType}, 1)
+	qre.tsv.Stats().UserTableQueryTimesNs.Add([]string{tableName, username, queryType}, duration)
+}
+
+// recordQueryDigest records the execution of the query in the query digests
+// of its caller.
+func (qre *QueryExecutor) recordQueryDigest(duration time.Duration, rows int64, failed bool) {
+	if !qre.tsv.qe.digests.enabled() {
+		return
+	}
+	qre.tsv.qe.digests.record(qre.username(), qre.plan.Digest, duration, rows, failed)
+}
+
+// username returns the effective caller principal of the query, or its
+// immediate caller user if it has none.
+func (qre *QueryExecutor) username() string {
 	username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 	if username == "" {
 		username = callerid.GetUsername(callerid.ImmediateCallerIDFromContext(qre.ctx))
 	}
-	tableName := qre.plan.TableName().String()
-	qre.tsv.Stats().UserTableQueryCount.Add([]string{tableName, username, queryType}, 1)
-	qre.tsv.Stats().UserTableQueryTimesNs.Add([]string{tableName, username, queryType}, duration)
+	return username
 }
 
 func (qre *QueryExecutor) GetSchemaDefinitions(tableType querypb.SchemaTableType, tableNames []string, callback func(schemaRes *querypb.GetSchemaResponse) error) error {
@@ -18,6 +18,7 @@ package tabletserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
@@ -34,6 +35,7 @@ import (
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/callerid"
@@ -44,12 +46,14 @@ import (
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/authz"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txthrottler"
 
+	authzpb "vitess.io/vitess/go/vt/proto/authz"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	tableaclpb "vitess.io/vitess/go/vt/proto/tableacl"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -119,6 +123,21 @@ func TestQueryExecutorPlans(t *testing.T) {
 		resultWant: dmlResult,
 		planWant:   "Show",
 		logWant:    "show engines",
+	}, {
+		input: "show vitess_workflows like 'wf%'",
+		dbResponses: []dbResponse{{
+			query: "select '' as keyspace, '' as shard, id, workflow, source, pos, stop_pos, state, message, db_name, " +
+				"workflow_type, workflow_sub_type, time_updated, transaction_timestamp, time_heartbeat, time_throttled, component_throttled, " +
+				"rows_copied, tags, max_tps, max_replication_lag, cell, tablet_types, defer_secondary_keys " +
+				"from _vt.vreplication where workflow like 'wf%' or state like 'wf%'",
+			result: selectResult,
+		}},
+		resultWant: selectResult,
+		planWant:   "ShowWorkflows",
+		logWant: "select '' as keyspace, '' as shard, id, workflow, source, pos, stop_pos, state, message, db_name, " +
+			"workflow_type, workflow_sub_type, time_updated, transaction_timestamp, time_heartbeat, time_throttled, component_throttled, " +
+			"rows_copied, tags, max_tps, max_replication_lag, cell, tablet_types, defer_secondary_keys " +
+			"from _vt.vreplication where workflow like 'wf%' or state like 'wf%'",
 	}, {
 		input: "repair t",
 		dbResponses: []dbResponse{{
@@ -649,6 +668,313 @@ func TestQueryExecutorLimitFailure(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorVersionConflict(t *testing.T) {
+	versionConflictOptions := &querypb.ExecuteOptions{VersionConflictError: true}
+	testcases := []struct {
+		name        string
+		input       string
+		query       string
+		passThrough bool
+		options     *querypb.ExecuteOptions
+		result      *sqltypes.Result
+		err         string
+	}{{
+		name:    "conflict",
+		input:   "update test_table set name = 2, addr = addr + 1 where pk = 1 and addr = 3",
+		query:   "update test_table set `name` = 2, addr = addr + 1 where pk = 1 and addr = 3 limit 10001",
+		options: versionConflictOptions,
+		result:  &sqltypes.Result{},
+		err:     "version conflict: no row of table test_table matched the value of the addr version column (errno 302) (sqlstate 40001)",
+	}, {
+		name:        "conflict without limit",
+		input:       "delete from test_table where pk = :pk and addr = :addr",
+		query:       "delete from test_table where pk = 1 and addr = 3",
+		passThrough: true,
+		options:     versionConflictOptions,
+		result:      &sqltypes.Result{},
+		err:         "version conflict: no row of table test_table matched the value of the addr version column (errno 302) (sqlstate 40001)",
+	}, {
+		name:    "no conflict",
+		input:   "update test_table set name = 2, addr = addr + 1 where pk = 1 and addr = 3",
+		query:   "update test_table set `name` = 2, addr = addr + 1 where pk = 1 and addr = 3 limit 10001",
+		options: versionConflictOptions,
+		result:  &sqltypes.Result{RowsAffected: 1},
+	}, {
+		name:   "not requested",
+		input:  "update test_table set name = 2, addr = addr + 1 where pk = 1 and addr = 3",
+		query:  "update test_table set `name` = 2, addr = addr + 1 where pk = 1 and addr = 3 limit 10001",
+		result: &sqltypes.Result{},
+	}, {
+		name:    "no version predicate",
+		input:   "update test_table set name = 2 where pk = 1 or addr = 3",
+		query:   "update test_table set `name` = 2 where pk = 1 or addr = 3 limit 10001",
+		options: versionConflictOptions,
+		result:  &sqltypes.Result{},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			db := setUpQueryExecutorTest(t)
+			defer db.Close()
+			db.AddQuery(tcase.query, tcase.result)
+			ctx := context.Background()
+			tsv := newTestTabletServer(ctx, noFlags, db)
+			defer tsv.StopService()
+			tsv.SetPassthroughDMLs(tcase.passThrough)
+
+			check := func(qre *QueryExecutor) {
+				qre.options = tcase.options
+				qre.bindVars = map[string]*querypb.BindVariable{
+					"pk":   sqltypes.Int64BindVariable(1),
+					"addr": sqltypes.Int64BindVariable(3),
+				}
+				got, err := qre.Execute()
+				if tcase.err == "" {
+					require.NoError(t, err)
+					assert.Equal(t, tcase.result, got)
+					return
+				}
+				require.EqualError(t, err, tcase.err)
+				assert.Equal(t, vtrpcpb.Code_ABORTED, convertErrorCode(err))
+			}
+
+			// Test outside a transaction.
+			check(newTestQueryExecutor(ctx, tsv, tcase.input, 0))
+
+			// Test inside a transaction.
+			txID := newTransaction(tsv, nil)
+			defer tsv.Rollback(ctx, tsv.sm.Target(), txID)
+			check(newTestQueryExecutor(ctx, tsv, tcase.input, txID))
+		})
+	}
+}
+
+func TestQueryExecutorTxSizeLimit(t *testing.T) {
+	input := "update test_table set name = 2 where addr = 3"
+	query := "update test_table set `name` = 2 where addr = 3 limit 10001"
+	ctx := context.Background()
+	newGuard := func(dryRun bool) *txSizeGuard {
+		return &txSizeGuard{
+			limit:    tabletenv.TransactionSizeLimit{Rows: 5},
+			tables:   map[string]tabletenv.TransactionSizeLimit{"other_table": {Rows: 1}},
+			dryRun:   dryRun,
+			exceeded: stats.NewCountersWithMultiLabels("", "", []string{"TableName", "CallerID", "Mode"}),
+		}
+	}
+
+	t.Run("enforced", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 3})
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+		guard := newGuard(false)
+		tsv.te.txPool.sizeGuard = guard
+
+		// The statement outside a transaction runs in a transaction of its
+		// own, which is rolled back instead of committed.
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 10})
+		_, err := newTestQueryExecutor(ctx, tsv, input, 0).Execute()
+		require.ErrorContains(t, err, "the transaction modified 10 rows of table test_table, over the limit of 5")
+
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 3})
+		txID := newTransaction(tsv, nil)
+		_, err = newTestQueryExecutor(ctx, tsv, input, txID).Execute()
+		require.NoError(t, err)
+		_, err = newTestQueryExecutor(ctx, tsv, input, txID).Execute()
+		require.EqualError(t, err, "transaction size limit exceeded: the transaction modified 6 rows of table test_table, over the limit of 5; it must be rolled back (errno 303) (sqlstate HY000)")
+		assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, convertErrorCode(err))
+		assert.EqualValues(t, 2, guard.exceeded.Counts()["test_table..Enforced"])
+
+		// The commit fails, and rolls the transaction back.
+		db.ResetQueryLog()
+		_, err = tsv.Commit(ctx, tsv.sm.Target(), txID)
+		require.ErrorContains(t, err, "transaction size limit exceeded")
+		assert.Contains(t, db.QueryLog(), "rollback")
+		assert.NotContains(t, db.QueryLog(), "commit")
+
+		// The DMLs which would be autocommitted run in a transaction of their
+		// own too, so that they are not committed when they exceed the limit.
+		tsv.SetPassthroughDMLs(true)
+		db.AddQuery("delete from test_table where addr = 3", &sqltypes.Result{RowsAffected: 10})
+		db.ResetQueryLog()
+		_, err = newTestQueryExecutor(ctx, tsv, "delete from test_table where addr = 3", 0).Execute()
+		require.ErrorContains(t, err, "the transaction modified 10 rows of table test_table, over the limit of 5")
+		assert.Equal(t, "begin;delete from test_table where addr = 3;rollback", db.QueryLog())
+
+		// The DMLs of the tables which are not limited are autocommitted.
+		guard.limit = tabletenv.TransactionSizeLimit{}
+		db.ResetQueryLog()
+		_, err = newTestQueryExecutor(ctx, tsv, "delete from test_table where addr = 3", 0).Execute()
+		require.NoError(t, err)
+		assert.NotContains(t, db.QueryLog(), "begin")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 10})
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+		guard := newGuard(true)
+		tsv.te.txPool.sizeGuard = guard
+
+		txID := newTransaction(tsv, nil)
+		_, err := newTestQueryExecutor(ctx, tsv, input, txID).Execute()
+		require.NoError(t, err)
+		_, err = newTestQueryExecutor(ctx, tsv, input, txID).Execute()
+		require.NoError(t, err)
+		_, err = tsv.Commit(ctx, tsv.sm.Target(), txID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, guard.exceeded.Counts()["test_table..DryRun"])
+	})
+}
+
+func TestQueryExecutorChunkedDML(t *testing.T) {
+	input := "delete /*vt+ DML_CHUNK_SIZE=2 */ from test_table where name = 1"
+	firstBounds := "select pk from test_table where `name` = 1 order by pk asc limit 2"
+	firstChunk := "delete /*vt+ DML_CHUNK_SIZE=2 */ from test_table where `name` = 1 and pk <= 2"
+	nextBounds := "select pk from test_table where `name` = 1 and pk > 2 order by pk asc limit 2"
+	nextChunk := "delete /*vt+ DML_CHUNK_SIZE=2 */ from test_table where `name` = 1 and pk > 2 and pk <= 5"
+	pks := func(values ...string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk", "int32"), values...)
+	}
+	ctx := context.Background()
+
+	t.Run("directive", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQuery(firstBounds, pks("1", "2"))
+		db.AddQuery(firstChunk, &sqltypes.Result{RowsAffected: 2})
+		db.AddQuery(nextBounds, pks("5"))
+		db.AddQuery(nextChunk, &sqltypes.Result{RowsAffected: 1})
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+
+		db.ResetQueryLog()
+		got, err := newTestQueryExecutor(ctx, tsv, input, 0).Execute()
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, got.RowsAffected)
+		// Each chunk is modified in a transaction of its own.
+		queryLog := db.QueryLog()
+		assert.Contains(t, queryLog, "begin;"+strings.ToLower(firstChunk)+";commit;"+nextBounds+";begin;"+strings.ToLower(nextChunk)+";commit")
+		assert.EqualValues(t, 2, tsv.dmlChunker.chunks.Counts()["test_table"])
+	})
+
+	t.Run("table", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQuery("select pk from test_table where `name` = 1 order by pk asc limit 2", pks())
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+		tsv.dmlChunker.tables = map[string]int64{"test_table": 2}
+
+		db.ResetQueryLog()
+		got, err := newTestQueryExecutor(ctx, tsv, "delete from test_table where name = 1", 0).Execute()
+		require.NoError(t, err)
+		assert.Zero(t, got.RowsAffected)
+		assert.Contains(t, db.QueryLog(), "select pk from test_table where `name` = 1 order by pk asc limit 2")
+		assert.NotContains(t, db.QueryLog(), "delete")
+
+		// The statements of a transaction are executed at once.
+		db.AddQuery("delete from test_table where `name` = 1 limit 10001", &sqltypes.Result{RowsAffected: 3})
+		txID := newTransaction(tsv, nil)
+		got, err = newTestQueryExecutor(ctx, tsv, "delete from test_table where name = 1", txID).Execute()
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, got.RowsAffected)
+		_, err = tsv.Rollback(ctx, tsv.sm.Target(), txID)
+		require.NoError(t, err)
+	})
+
+	t.Run("failed chunk", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQuery(firstBounds, pks("1", "2"))
+		db.AddQuery(firstChunk, &sqltypes.Result{RowsAffected: 2})
+		db.AddQuery(nextBounds, pks("5"))
+		db.AddRejectedQuery(nextChunk, errors.New("lock wait timeout"))
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+
+		_, err := newTestQueryExecutor(ctx, tsv, input, 0).Execute()
+		require.ErrorContains(t, err, "DeleteLimit executed in chunks failed after modifying 2 rows: unknown error: lock wait timeout")
+	})
+}
+
+func TestQueryExecutorIdempotencyToken(t *testing.T) {
+	input := "update test_table set name = 2 where pk = 1"
+	query := "update test_table set `name` = 2 where pk = 1 limit 10001"
+	insertToken := "insert into _vt\\.idempotency_tokens\\(token, time_created\\) values \\('tok', \\d+\\)"
+	updateToken := "update _vt.idempotency_tokens set rows_affected = 1, insert_id = 0 where token = 'tok'"
+	selectToken := "select rows_affected, insert_id from _vt.idempotency_tokens where token = 'tok'"
+	options := &querypb.ExecuteOptions{IdempotencyToken: "tok"}
+
+	t.Run("executed", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.AddQueryPattern(insertToken, &sqltypes.Result{RowsAffected: 1})
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 1})
+		db.AddQuery(updateToken, &sqltypes.Result{RowsAffected: 1})
+		ctx := context.Background()
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+
+		qre := newTestQueryExecutor(ctx, tsv, input, 0)
+		qre.options = options
+		got, err := qre.Execute()
+		require.NoError(t, err)
+		assert.Equal(t, &sqltypes.Result{RowsAffected: 1}, got)
+		assert.Equal(t, 1, db.GetQueryCalledNum(query))
+		assert.Equal(t, 1, db.GetQueryCalledNum(updateToken))
+		assert.Equal(t, int64(1), tsv.stats.IdempotentWrites.Counts()["Executed"])
+	})
+
+	t.Run("deduplicated", func(t *testing.T) {
+		db := setUpQueryExecutorTest(t)
+		defer db.Close()
+		db.RejectQueryPatternWithError(insertToken, sqlerror.NewSQLError(sqlerror.ERDupEntry, sqlerror.SSConstraintViolation, "Duplicate entry 'tok' for key 'idempotency_tokens.PRIMARY'"))
+		db.AddQuery(query, &sqltypes.Result{RowsAffected: 1})
+		db.AddQuery(selectToken, sqltypes.MakeTestResult(sqltypes.MakeTestFields("rows_affected|insert_id", "uint64|uint64"), "1|0"))
+		ctx := context.Background()
+		tsv := newTestTabletServer(ctx, noFlags, db)
+		defer tsv.StopService()
+
+		qre := newTestQueryExecutor(ctx, tsv, input, 0)
+		qre.options = options
+		got, err := qre.Execute()
+		require.NoError(t, err)
+		assert.Equal(t, &sqltypes.Result{RowsAffected: 1}, got)
+		assert.Equal(t, 0, db.GetQueryCalledNum(query))
+		assert.Equal(t, int64(1), tsv.stats.IdempotentWrites.Counts()["Deduplicated"])
+	})
+}
+
+func TestQueryExecutorSelectResultStats(t *testing.T) {
+	query := "select * from test_table"
+	for _, budget := range []int64{0, 1 << 20} {
+		t.Run(fmt.Sprintf("budget %d", budget), func(t *testing.T) {
+			db := setUpQueryExecutorTest(t)
+			defer db.Close()
+			db.AddQuery("select * from test_table limit 10001", sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk", "int64"), "1", "2"))
+			ctx := context.Background()
+			tsv := newTestTabletServer(ctx, noFlags, db)
+			defer tsv.StopService()
+			tsv.qe.memoryGovernor.budget.Store(budget)
+
+			qre := newTestQueryExecutor(ctx, tsv, query, 0)
+			_, err := qre.Execute()
+			require.NoError(t, err)
+			// The size of the results is only tracked by the memory governor.
+			rowSize, rowsPerResult := qre.plan.resultRowStats()
+			if budget == 0 {
+				assert.Zero(t, rowsPerResult)
+				return
+			}
+			assert.EqualValues(t, 2, rowsPerResult)
+			assert.Positive(t, rowSize)
+		})
+	}
+}
+
 func TestQueryExecutorPlanPassSelectWithLockOutsideATransaction(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -792,6 +1118,45 @@ func TestQueryExecutorPlanNextval(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorStreamSpool(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows:   [][]sqltypes.Value{{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)}},
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select @@session.net_write_timeout", sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@session.net_write_timeout", "int64"), "60"))
+	db.AddQuery("set @@session.net_write_timeout = 600", &sqltypes.Result{})
+	db.AddQuery("set @@session.net_write_timeout = 60", &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.streamSpoolSize = 1
+	tsv.qe.streamNetWriteTimeout = 10 * time.Minute
+
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	var rows [][]sqltypes.Value
+	err := qre.Stream(func(qr *sqltypes.Result) error {
+		rows = append(rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want.Rows, rows)
+	assert.Equal(t, 1, db.GetQueryCalledNum("set @@session.net_write_timeout = 600"))
+	// The pooled connection gets its net_write_timeout back.
+	assert.Equal(t, 1, db.GetQueryCalledNum("set @@session.net_write_timeout = 60"))
+
+	// The error of the client stops the stream.
+	qre = newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	err = qre.Stream(func(*sqltypes.Result) error {
+		return errors.New("client went away")
+	})
+	assert.ErrorContains(t, err, "client went away")
+	assert.Equal(t, 2, db.GetQueryCalledNum("set @@session.net_write_timeout = 60"))
+}
+
 func TestQueryExecutorMessageStreamACL(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -960,6 +1325,71 @@ func TestQueryExecutorTableAclNoPermission(t *testing.T) {
 	}
 }
 
+type fakeExternalAuthorizer struct {
+	requests []*authzpb.AuthorizeRequest
+	allowed  bool
+	closed   bool
+}
+
+func (fa *fakeExternalAuthorizer) Authorize(ctx context.Context, req *authzpb.AuthorizeRequest) (*authzpb.AuthorizeResponse, error) {
+	fa.requests = append(fa.requests, req)
+	return &authzpb.AuthorizeResponse{Allowed: fa.allowed, Reason: "denied by policy"}, nil
+}
+
+func (fa *fakeExternalAuthorizer) Close() error {
+	fa.closed = true
+	return nil
+}
+
+func TestQueryExecutorExternalAuthz(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	callerID := &querypb.VTGateCallerID{Username: "u1"}
+	ctx := callerid.NewContext(context.Background(), &vtrpcpb.CallerID{Principal: "app"}, callerID)
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	fa := &fakeExternalAuthorizer{allowed: true}
+	tsv.qe.externalAuthz = authz.NewChecker(tsv, fa)
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	require.Len(t, fa.requests, 1)
+	assert.Equal(t, "u1", fa.requests[0].ImmediateCallerId.Username)
+	assert.Equal(t, "app", fa.requests[0].EffectiveCallerId.Principal)
+	assert.Equal(t, "Select", fa.requests[0].StatementType)
+	assert.Equal(t, []*authzpb.TableAccess{{Name: "test_table", Role: "READER"}}, fa.requests[0].Tables)
+
+	fa.allowed = false
+	tsv.qe.externalAuthz = authz.NewChecker(tsv, fa)
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err = qre.Execute()
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	assert.ErrorContains(t, err, "Select command denied to user 'u1' by the external authorization service: denied by policy")
+
+	// Queries against dual are not checked.
+	fa.requests = nil
+	db.AddQuery("select 1 from dual limit 10001", &sqltypes.Result{})
+	qre = newTestQueryExecutor(ctx, tsv, "select 1 from dual", 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	assert.Empty(t, fa.requests)
+
+	// The client of the service is closed with the query engine.
+	tsv.StopService()
+	assert.True(t, fa.closed)
+}
+
 func TestQueryExecutorTableAclDualTableExempt(t *testing.T) {
 	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int64())
 	tableacl.Register(aclName, &simpleacl.Factory{})
@@ -1133,6 +1563,16 @@ func TestQueryExecutorTableAclDryRun(t *testing.T) {
 	if afterCount-beforeCount != 1 {
 		t.Fatalf("table acl pseudo denied count should increase by one. got: %d, want: %d", afterCount, beforeCount+1)
 	}
+
+	// The denial is in the dry run report.
+	denials := tsv.qe.aclDryRun.snapshot("", "")
+	require.Len(t, denials, 1)
+	assert.Equal(t, username, denials[0].Username)
+	assert.Equal(t, "test_table", denials[0].Table)
+	assert.Equal(t, "group02", denials[0].TableGroup)
+	assert.EqualValues(t, 1, denials[0].Count)
+	assert.Equal(t, map[string]int64{"Select": 1}, denials[0].Plans)
+	assert.Equal(t, []string{query}, denials[0].SampleQueries)
 }
 
 func TestQueryExecutorDenyListQRFail(t *testing.T) {
@@ -1189,6 +1629,304 @@ func TestQueryExecutorDenyListQRFail(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorResourceGroup(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	expected := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery("select /*+ RESOURCE_GROUP(adhoc) */ * from test_table limit 1000", expected)
+
+	rule := rules.NewQueryRule("ad-hoc queries", "adhoc", rules.QRContinue)
+	rule.SetUserCond("analyst")
+	require.NoError(t, rule.SetResourceGroup("adhoc"))
+	qrs := rules.New()
+	qrs.Add(rule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: "analyst"})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.RegisterSource("resourceGroupRules")
+	defer tsv.qe.queryRuleSources.UnRegisterSource("resourceGroupRules")
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules("resourceGroupRules", qrs))
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+	assert.EqualValues(t, 1, tsv.Stats().ResourceGroupQueryCount.Counts()["adhoc"])
+}
+
+func TestQueryExecutorHintRules(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	expected := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery("select /*+ NO_ICP(test_table) */ * from test_table use index (idx) limit 1000", expected)
+
+	optimizerRule := rules.NewQueryRule("no icp", "no_icp", rules.QRContinue)
+	optimizerRule.AddTableCond("test_table")
+	require.NoError(t, optimizerRule.SetOptimizerHints("NO_ICP(test_table)"))
+	indexRule := rules.NewQueryRule("use idx", "use_idx", rules.QRContinue)
+	require.NoError(t, indexRule.AddIndexHint(rules.IndexHint{Table: "test_table", Type: "use", Indexes: []string{"idx"}}))
+	dryRunRule := rules.NewQueryRule("force idx", "force_idx", rules.QRContinue)
+	require.NoError(t, dryRunRule.AddIndexHint(rules.IndexHint{Table: "test_table", Type: "force", Indexes: []string{"idx"}}))
+	dryRunRule.SetDryRun(true)
+	qrs := rules.New()
+	qrs.Add(optimizerRule)
+	qrs.Add(indexRule)
+	qrs.Add(dryRunRule)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.RegisterSource("hintRules")
+	defer tsv.qe.queryRuleSources.UnRegisterSource("hintRules")
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules("hintRules", qrs))
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	assert.Equal(t, []string{"no_icp", "use_idx"}, qre.plan.HintRules)
+	assert.Equal(t, []string{"force_idx"}, qre.plan.DryRunHintRules)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+	counts := tsv.Stats().HintRuleQueryCount.Counts()
+	assert.EqualValues(t, 1, counts["no_icp.applied"])
+	assert.EqualValues(t, 1, counts["use_idx.applied"])
+	assert.EqualValues(t, 1, counts["force_idx.dry_run"])
+
+	// The plans are rebuilt without the hints when the rules are removed.
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules("hintRules", rules.New()))
+	db.AddQuery(query, expected)
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	assert.Empty(t, qre.plan.HintRules)
+	got, err = qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestAddResourceGroupHint(t *testing.T) {
+	testcases := []struct {
+		query string
+		want  string
+		ok    bool
+	}{{
+		query: "select * from t",
+		want:  "select /*+ RESOURCE_GROUP(rg) */ * from t",
+		ok:    true,
+	}, {
+		query: "(SELECT a from t) union (select b from u)",
+		want:  "(SELECT /*+ RESOURCE_GROUP(rg) */ a from t) union (select b from u)",
+		ok:    true,
+	}, {
+		query: "select /*+ MAX_EXECUTION_TIME(1000) */ * from t",
+		want:  "select /*+ RESOURCE_GROUP(rg) MAX_EXECUTION_TIME(1000) */ * from t",
+		ok:    true,
+	}, {
+		query: "update t set a = 1",
+		want:  "update /*+ RESOURCE_GROUP(rg) */ t set a = 1",
+		ok:    true,
+	}, {
+		query: "set @a = 1",
+		want:  "set @a = 1",
+	}, {
+		query: "show tables",
+		want:  "show tables",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			got, ok := addResourceGroupHint(tc.query, "rg")
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestAddMaxExecutionTimeHint(t *testing.T) {
+	testcases := []struct {
+		query   string
+		timeout time.Duration
+		want    string
+		ok      bool
+	}{{
+		query:   "select * from t",
+		timeout: 1500 * time.Microsecond,
+		want:    "select /*+ MAX_EXECUTION_TIME(2) */ * from t",
+		ok:      true,
+	}, {
+		query:   "select /*+ RESOURCE_GROUP(rg) */ * from t",
+		timeout: time.Second,
+		want:    "select /*+ MAX_EXECUTION_TIME(1000) RESOURCE_GROUP(rg) */ * from t",
+		ok:      true,
+	}, {
+		query:   "select * from t",
+		timeout: -time.Second,
+		want:    "select /*+ MAX_EXECUTION_TIME(1) */ * from t",
+		ok:      true,
+	}, {
+		query:   "select /*+ max_execution_time(10) */ * from t",
+		timeout: time.Second,
+		want:    "select /*+ max_execution_time(10) */ * from t",
+	}, {
+		query:   "update t set a = 1",
+		timeout: time.Second,
+		want:    "update t set a = 1",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			got, ok := addMaxExecutionTimeHint(tc.query, tc.timeout)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestQueryExecutorPropagateDeadline(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery(query, want)
+	db.AddQueryPattern(`select /\*\+ MAX_EXECUTION_TIME\(\d+\) \*/ \* from test_table limit 1000`, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// The deadline is not propagated unless enabled.
+	db.ResetQueryLog()
+	_, err := newTestQueryExecutor(deadlineCtx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.NotContains(t, db.QueryLog(), "max_execution_time")
+
+	tsv.config.PropagateDeadline = true
+	db.ResetQueryLog()
+	got, err := newTestQueryExecutor(deadlineCtx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Regexp(t, `max_execution_time\((59\d{3}|60000)\)`, db.QueryLog())
+
+	// The queries without a deadline are sent as is.
+	db.ResetQueryLog()
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.NotContains(t, db.QueryLog(), "max_execution_time")
+}
+
+func TestQueryExecutorMaxStaleness(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	rt := &testReplTracker{lag: 5 * time.Second}
+	tsv.sm.rt = rt
+
+	testcases := []struct {
+		name           string
+		tabletType     topodatapb.TabletType
+		maxStalenessMs int64
+		lag            time.Duration
+		lagErr         error
+		wantErr        string
+	}{{
+		name:       "no requirement",
+		tabletType: topodatapb.TabletType_REPLICA,
+		lag:        5 * time.Second,
+	}, {
+		name:           "fresh enough",
+		tabletType:     topodatapb.TabletType_REPLICA,
+		maxStalenessMs: 10000,
+		lag:            5 * time.Second,
+	}, {
+		name:           "too stale",
+		tabletType:     topodatapb.TabletType_REPLICA,
+		maxStalenessMs: 1000,
+		lag:            5 * time.Second,
+		wantErr:        "VT14006: replication lag of 5s exceeds the maximum staleness of 1s of the query",
+	}, {
+		name:           "unknown lag",
+		tabletType:     topodatapb.TabletType_RDONLY,
+		maxStalenessMs: 1000,
+		lagErr:         errors.New("replication is not running"),
+		wantErr:        "VT14006: replication lag of unknown (replication is not running) exceeds the maximum staleness of 1s of the query",
+	}, {
+		name:           "primary is never stale",
+		tabletType:     topodatapb.TabletType_PRIMARY,
+		maxStalenessMs: 1000,
+		lag:            5 * time.Second,
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			rt.lag, rt.err = tcase.lag, tcase.lagErr
+			qre := newTestQueryExecutor(ctx, tsv, query, 0)
+			qre.targetTabletType = tcase.tabletType
+			qre.options = &querypb.ExecuteOptions{MaxStalenessMs: tcase.maxStalenessMs}
+
+			got, err := qre.Execute()
+			if tcase.wantErr != "" {
+				require.EqualError(t, err, tcase.wantErr)
+				assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestQueryExecutorPinnedTablet(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	ctx := callerid.NewContext(context.Background(), nil, callerid.NewImmediateCallerID("debugger"))
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.alias = &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{PinnedTabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}}
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.EqualValues(t, 1, tsv.Stats().UserPinnedQueryCount.Counts()["debugger"])
+
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{PinnedTabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 102}}
+	_, err = qre.Execute()
+	require.EqualError(t, err, "query pinned to tablet zone1-0000000102 received by tablet zone1-0000000101")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.Stats().UserPinnedQueryCount.Counts()["debugger"])
+}
+
 func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1598,7 +2336,7 @@ func initQueryExecutorTestDB(db *fakesqldb.DB) {
 	db.AddQueryPattern(baseShowTablesWithSizesPattern, &sqltypes.Result{
 		Fields: mysql.BaseShowTablesWithSizesFields,
 		Rows: [][]sqltypes.Value{
-			mysql.BaseShowTablesWithSizesRow("test_table", false, ""),
+			mysql.BaseShowTablesWithSizesRow("test_table", false, "vt_version_column=addr"),
 			mysql.BaseShowTablesWithSizesRow("seq", false, "vitess_sequence"),
 			mysql.BaseShowTablesWithSizesRow("msg", false, "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30"),
 		},
@@ -1607,7 +2345,7 @@ func initQueryExecutorTestDB(db *fakesqldb.DB) {
 		&sqltypes.Result{
 			Fields: mysql.BaseShowTablesFields,
 			Rows: [][]sqltypes.Value{
-				mysql.BaseShowTablesRow("test_table", false, ""),
+				mysql.BaseShowTablesRow("test_table", false, "vt_version_column=addr"),
 				mysql.BaseShowTablesRow("seq", false, "vitess_sequence"),
 				mysql.BaseShowTablesRow("msg", false, "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30"),
 			},
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
+)
+
+var indexHintTypes = map[string]sqlparser.IndexHintType{
+	"USE":    sqlparser.UseOp,
+	"FORCE":  sqlparser.ForceOp,
+	"IGNORE": sqlparser.IgnoreOp,
+}
+
+// applyHintRules injects the hints of the query rules of the plan into its
+// statement, and replaces the plan with the one build returns for the hinted
+// statement. The rules in dry run mode only log the query they would have
+// produced. Since the query rules are applied to the plans, the plans are
+// rebuilt with the new hints whenever the rules change.
+func (qe *QueryEngine) applyHintRules(plan *TabletPlan, statement sqlparser.Statement, build func(sqlparser.Statement) (*planbuilder.Plan, error)) {
+	hintRules := plan.Rules.HintRules()
+	if len(hintRules) == 0 {
+		return
+	}
+
+	parser := qe.env.Environment().Parser()
+	hinted := sqlparser.Clone(statement)
+	var applied []string
+	for _, qr := range hintRules {
+		if qr.DryRun() {
+			dryRun := sqlparser.Clone(statement)
+			if injectHints(dryRun, qr) {
+				log.Infof("Query rule %s would rewrite the query %s into %s", qr.Name, parser.TruncateForLog(plan.Original), parser.TruncateForLog(sqlparser.String(dryRun)))
+				plan.DryRunHintRules = append(plan.DryRunHintRules, qr.Name)
+			}
+			continue
+		}
+		if injectHints(hinted, qr) {
+			applied = append(applied, qr.Name)
+		}
+	}
+	if len(applied) == 0 {
+		return
+	}
+
+	splan, err := build(hinted)
+	if err != nil {
+		log.Warningf("Query rules %v: cannot plan the hinted query %s, running it without hints: %v", applied, parser.TruncateForLog(sqlparser.String(hinted)), err)
+		return
+	}
+	plan.Plan = splan
+	plan.HintRules = applied
+}
+
+// injectHints injects the optimizer and index hints of the rule into the
+// statement. It returns false if none of them applies to the statement, e.g.
+// if it does not reference the table of its index hints.
+func injectHints(statement sqlparser.Statement, qr *rules.Rule) bool {
+	injected := false
+	if hints := qr.OptimizerHints(); hints != "" {
+		if commented, ok := statement.(sqlparser.SupportOptimizerHint); ok {
+			if comments, err := commented.GetParsedComments().AddQueryHint(hints); err == nil {
+				commented.SetComments(comments)
+				injected = true
+			}
+		}
+	}
+	for _, hint := range qr.IndexHints() {
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			tableExpr, ok := node.(*sqlparser.AliasedTableExpr)
+			if !ok {
+				return true, nil
+			}
+			if tableName, ok := tableExpr.Expr.(sqlparser.TableName); ok && tableName.Name.String() == hint.Table {
+				indexHint := &sqlparser.IndexHint{Type: indexHintTypes[hint.Type]}
+				for _, index := range hint.Indexes {
+					indexHint.Indexes = append(indexHint.Indexes, sqlparser.NewIdentifierCI(index))
+				}
+				tableExpr.Hints = append(tableExpr.Hints, indexHint)
+				injected = true
+			}
+			return true, nil
+		}, statement)
+	}
+	return injected
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/syscallutil"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/logutil"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const (
+	threadsRunningQuery    = "show global status like 'Threads_running'"
+	historyListLengthQuery = "select count from information_schema.INNODB_METRICS where name = 'trx_rseg_history_len'"
+	datadirQuery           = "select @@global.datadir"
+)
+
+var resourceSamplerLogger = logutil.NewThrottledLogger("ResourceSampler", 5*time.Minute)
+
+// resourceStats are the host and MySQL resource metrics sampled for the
+// RealtimeStats of the health stream.
+type resourceStats struct {
+	cpuUsage                float64
+	loadAverage             float64
+	datadirFreeBytes        uint64
+	datadirUsedRatio        float64
+	mysqlThreadsRunning     int64
+	innodbHistoryListLength int64
+}
+
+// resourceSampler periodically samples the resource metrics of the tablet's
+// host and MySQL. The host metrics are read from /proc, and the metrics of
+// the data directory are only available when MySQL runs on the same host.
+// Metrics which cannot be sampled are reported as 0.
+type resourceSampler struct {
+	interval  time.Duration
+	connector dbconfigs.Connector
+
+	// The sources of the host metrics, which are overridden in tests.
+	readFile func(name string) ([]byte, error)
+	diskStat func(path string) (free, total uint64, err error)
+
+	mu     sync.Mutex
+	stats  resourceStats
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// The following fields are only accessed by the sampling goroutine.
+	conn         *mysql.Conn
+	datadir      string
+	lastCPUBusy  uint64
+	lastCPUTotal uint64
+}
+
+func newResourceSampler(interval time.Duration) *resourceSampler {
+	return &resourceSampler{
+		interval: interval,
+		readFile: os.ReadFile,
+		diskStat: syscallutil.DiskStat,
+	}
+}
+
+// Open starts sampling the metrics, if the sampler has an interval.
+func (rs *resourceSampler) Open(connector dbconfigs.Connector) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.interval <= 0 || rs.cancel != nil {
+		return
+	}
+	rs.connector = connector
+	var ctx context.Context
+	ctx, rs.cancel = context.WithCancel(context.Background())
+	rs.done = make(chan struct{})
+	go rs.run(ctx, rs.done)
+}
+
+// Close stops sampling the metrics.
+func (rs *resourceSampler) Close() {
+	rs.mu.Lock()
+	cancel, done := rs.cancel, rs.done
+	rs.cancel, rs.done = nil, nil
+	rs.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (rs *resourceSampler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	defer func() {
+		if rs.conn != nil {
+			rs.conn.Close()
+			rs.conn = nil
+		}
+	}()
+
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		rs.sample(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample samples all the metrics.
+func (rs *resourceSampler) sample(ctx context.Context) {
+	var stats resourceStats
+	stats.cpuUsage = rs.sampleCPUUsage()
+	stats.loadAverage = rs.sampleLoadAverage()
+	if err := rs.sampleMySQL(ctx, &stats); err != nil {
+		resourceSamplerLogger.Warningf("cannot sample the MySQL resource metrics: %v", err)
+		if rs.conn != nil {
+			rs.conn.Close()
+			rs.conn = nil
+		}
+	}
+	if rs.datadir != "" {
+		if free, total, err := rs.diskStat(rs.datadir); err == nil && total > 0 {
+			stats.datadirFreeBytes = free
+			stats.datadirUsedRatio = 1 - float64(free)/float64(total)
+		}
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.stats = stats
+}
+
+// sampleCPUUsage returns the fraction of the CPU time of the host spent
+// outside of idle since the previous sample.
+func (rs *resourceSampler) sampleCPUUsage() float64 {
+	data, err := rs.readFile("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0
+	}
+	var busy, total uint64
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0
+		}
+		total += v
+		// The fourth and fifth values are the idle and iowait times.
+		if i != 3 && i != 4 {
+			busy += v
+		}
+	}
+
+	var usage float64
+	if rs.lastCPUTotal != 0 && total > rs.lastCPUTotal {
+		usage = float64(busy-rs.lastCPUBusy) / float64(total-rs.lastCPUTotal)
+	}
+	rs.lastCPUBusy, rs.lastCPUTotal = busy, total
+	return usage
+}
+
+// sampleLoadAverage returns the one-minute load average of the host.
+func (rs *resourceSampler) sampleLoadAverage() float64 {
+	data, err := rs.readFile("/proc/loadavg")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return load
+}
+
+// sampleMySQL samples the metrics read from MySQL.
+func (rs *resourceSampler) sampleMySQL(ctx context.Context, stats *resourceStats) error {
+	if rs.conn == nil {
+		ctx, cancel := context.WithTimeout(ctx, rs.interval)
+		defer cancel()
+		conn, err := rs.connector.Connect(ctx)
+		if err != nil {
+			return err
+		}
+		rs.conn = conn
+	}
+	if rs.datadir == "" {
+		qr, err := rs.conn.ExecuteFetch(datadirQuery, 1, false)
+		if err != nil {
+			return err
+		}
+		if len(qr.Rows) == 1 {
+			rs.datadir = qr.Rows[0][0].ToString()
+		}
+	}
+
+	qr, err := rs.conn.ExecuteFetch(threadsRunningQuery, 1, false)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 2 {
+		return fmt.Errorf("unexpected result for %s: %v", threadsRunningQuery, qr.Rows)
+	}
+	if stats.mysqlThreadsRunning, err = qr.Rows[0][1].ToCastInt64(); err != nil {
+		return err
+	}
+
+	qr, err = rs.conn.ExecuteFetch(historyListLengthQuery, 1, false)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) == 1 {
+		if stats.innodbHistoryListLength, err = qr.Rows[0][0].ToCastInt64(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fill sets the last sampled metrics in the RealtimeStats.
+func (rs *resourceSampler) fill(stats *querypb.RealtimeStats) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	stats.CpuUsage = rs.stats.cpuUsage
+	stats.LoadAverage = rs.stats.loadAverage
+	stats.DatadirFreeBytes = rs.stats.datadirFreeBytes
+	stats.DatadirUsedRatio = rs.stats.datadirUsedRatio
+	stats.MysqlThreadsRunning = rs.stats.mysqlThreadsRunning
+	stats.InnodbHistoryListLength = rs.stats.innodbHistoryListLength
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestResourceSampler(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery(datadirQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.datadir", "varchar"), "/var/lib/mysql/"))
+	db.AddQuery(threadsRunningQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar"), "Threads_running|7"))
+	db.AddQuery(historyListLengthQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("count", "int64"), "1234"))
+
+	files := map[string]string{
+		"/proc/stat":    "cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 100 0 100 700 100 0 0 0 0 0\n",
+		"/proc/loadavg": "1.50 1.20 0.90 2/345 6789\n",
+	}
+	rs := newResourceSampler(time.Minute)
+	rs.connector = dbconfigs.New(db.ConnParams())
+	rs.readFile = func(name string) ([]byte, error) {
+		if data, ok := files[name]; ok {
+			return []byte(data), nil
+		}
+		return nil, errors.New("not found")
+	}
+	rs.diskStat = func(path string) (uint64, uint64, error) {
+		assert.Equal(t, "/var/lib/mysql/", path)
+		return 250, 1000, nil
+	}
+	defer func() {
+		if rs.conn != nil {
+			rs.conn.Close()
+		}
+	}()
+
+	ctx := context.Background()
+	rs.sample(ctx)
+	stats := &querypb.RealtimeStats{Qps: 10}
+	rs.fill(stats)
+	assert.Equal(t, &querypb.RealtimeStats{
+		Qps:                     10,
+		LoadAverage:             1.5,
+		DatadirFreeBytes:        250,
+		DatadirUsedRatio:        0.75,
+		MysqlThreadsRunning:     7,
+		InnodbHistoryListLength: 1234,
+	}, stats)
+
+	// The CPU usage is computed between two samples: 300 of the 400 elapsed
+	// ticks were spent outside of idle and iowait.
+	files["/proc/stat"] = "cpu  300 0 200 800 100 0 0 0 0 0\n"
+	rs.sample(ctx)
+	rs.fill(stats)
+	assert.Equal(t, 0.75, stats.CpuUsage)
+
+	// The metrics which cannot be sampled are reported as 0.
+	db.AddRejectedQuery(threadsRunningQuery, errors.New("connection lost"))
+	delete(files, "/proc/loadavg")
+	rs.sample(ctx)
+	rs.fill(stats)
+	assert.Zero(t, stats.LoadAverage)
+	assert.Zero(t, stats.MysqlThreadsRunning)
+	assert.Equal(t, uint64(250), stats.DatadirFreeBytes)
+	assert.Nil(t, rs.conn, "the connection is closed after an error")
+}
+
+func TestResourceSamplerOpenClose(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery(datadirQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.datadir", "varchar"), "/var/lib/mysql/"))
+	db.AddQuery(threadsRunningQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar"), "Threads_running|3"))
+	db.AddQuery(historyListLengthQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("count", "int64"), "10"))
+
+	// The sampler doesn't run without an interval.
+	rs := newResourceSampler(0)
+	rs.Open(dbconfigs.New(db.ConnParams()))
+	assert.Nil(t, rs.cancel)
+	rs.Close()
+
+	rs = newResourceSampler(10 * time.Millisecond)
+	rs.diskStat = func(path string) (uint64, uint64, error) {
+		return 0, 0, errors.New("not local")
+	}
+	rs.Open(dbconfigs.New(db.ConnParams()))
+	require.Eventually(t, func() bool {
+		stats := &querypb.RealtimeStats{}
+		rs.fill(stats)
+		return stats.MysqlThreadsRunning == 3 && stats.InnodbHistoryListLength == 10
+	}, 5*time.Second, 10*time.Millisecond)
+	rs.Close()
+	rs.Close()
+}
@@ -39,13 +39,34 @@ func (cached *BindVarCond) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *IndexHint) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(56)
+	}
+	// field Table string
+	size += hack.RuntimeAllocSize(int64(len(cached.Table)))
+	// field Type string
+	size += hack.RuntimeAllocSize(int64(len(cached.Type)))
+	// field Indexes []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Indexes)) * int64(16))
+		for _, elem := range cached.Indexes {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	return size
+}
 func (cached *Rule) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(256)
+		size += int64(344)
 	}
 	// field Description string
 	size += hack.RuntimeAllocSize(int64(len(cached.Description)))
@@ -55,6 +76,8 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 	size += cached.requestIP.CachedSize(false)
 	// field user vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
 	size += cached.user.CachedSize(false)
+	// field workloadName vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
+	size += cached.workloadName.CachedSize(false)
 	// field query vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
 	size += cached.query.CachedSize(false)
 	// field leadingComment vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
@@ -79,6 +102,17 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 			size += elem.CachedSize(false)
 		}
 	}
+	// field resourceGroup string
+	size += hack.RuntimeAllocSize(int64(len(cached.resourceGroup)))
+	// field optimizerHints string
+	size += hack.RuntimeAllocSize(int64(len(cached.optimizerHints)))
+	// field indexHints []vitess.io/vitess/go/vt/vttablet/tabletserver/rules.IndexHint
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.indexHints)) * int64(56))
+		for _, elem := range cached.indexHints {
+			size += elem.CachedSize(false)
+		}
+	}
 	return size
 }
 func (cached *Rules) CachedSize(alloc bool) int64 {
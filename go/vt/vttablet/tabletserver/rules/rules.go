@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -40,6 +41,10 @@ const (
 	bufferedTableRuleName = "buffered_table"
 )
 
+// resourceGroupNameRE matches the names of the MySQL resource groups which
+// can be used in optimizer hints without quoting.
+var resourceGroupNameRE = regexp.MustCompile(`^[A-Za-z0-9_$]{1,64}$`)
+
 // Rules is used to store and execute rules for the tabletserver.
 type Rules struct {
 	rules []*Rule
@@ -172,7 +177,8 @@ func (qrs *Rules) FilterByPlan(query string, planid planbuilder.PlanType, tableN
 // GetAction runs the input against the rules engine and returns the action to be performed.
 func (qrs *Rules) GetAction(
 	ip,
-	user string,
+	user,
+	workloadName string,
 	bindVars map[string]*querypb.BindVariable,
 	marginComments sqlparser.MarginComments,
 ) (
@@ -181,13 +187,44 @@ func (qrs *Rules) GetAction(
 	timeout time.Duration,
 	desc string) {
 	for _, qr := range qrs.rules {
-		if act := qr.GetAction(ip, user, bindVars, marginComments); act != QRContinue {
+		if act := qr.GetAction(ip, user, workloadName, bindVars, marginComments); act != QRContinue {
 			return act, qr.cancelCtx, qr.timeout, qr.Description
 		}
 	}
 	return QRContinue, nil, 0, ""
 }
 
+// HintRules returns the rules which have optimizer or index hints. They are
+// meant to be called on the rules filtered by FilterByPlan, whose hint rules
+// all match the plan since their conditions only depend on it.
+func (qrs *Rules) HintRules() []*Rule {
+	var hintRules []*Rule
+	for _, qr := range qrs.rules {
+		if qr.hasHints() {
+			hintRules = append(hintRules, qr)
+		}
+	}
+	return hintRules
+}
+
+// GetResourceGroup runs the input against the rules engine and returns the
+// MySQL resource group of the first matching rule which has one, or an empty
+// string if none matches.
+func (qrs *Rules) GetResourceGroup(
+	ip,
+	user,
+	workloadName string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) string {
+	for _, qr := range qrs.rules {
+		if qr.resourceGroup != "" && qr.match(ip, user, workloadName, bindVars, marginComments) {
+			return qr.resourceGroup
+		}
+	}
+	return ""
+}
+
 // -----------------------------------------------
 
 // Rule represents one rule (conditions-action).
@@ -205,7 +242,7 @@ type Rule struct {
 	// All defined conditions must match for the rule to fire (AND).
 
 	// Regexp conditions. nil conditions are ignored (TRUE).
-	requestIP, user, query, leadingComment, trailingComment namedRegexp
+	requestIP, user, workloadName, query, leadingComment, trailingComment namedRegexp
 
 	// Any matched plan will make this condition true (OR)
 	plans []planbuilder.PlanType
@@ -224,6 +261,27 @@ type Rule struct {
 
 	// a rule can timeout.
 	timeout time.Duration
+
+	// resourceGroup is the MySQL resource group assigned to the queries
+	// matching the rule. A rule with a resource group doesn't fail queries.
+	resourceGroup string
+
+	// optimizerHints and indexHints are injected into the plans of the
+	// queries matching the rule, unless it is in dryRun mode, in which case
+	// the hinted queries are only logged. A rule with hints doesn't fail
+	// queries.
+	optimizerHints string
+	indexHints     []IndexHint
+	dryRun         bool
+}
+
+// IndexHint is an index hint a rule injects into the queries it matches,
+// for each reference to Table.
+type IndexHint struct {
+	Table string
+	// Type is USE, FORCE or IGNORE.
+	Type    string
+	Indexes []string
 }
 
 type namedRegexp struct {
@@ -265,10 +323,15 @@ func (qr *Rule) Equal(other *Rule) bool {
 		qr.Name == other.Name &&
 		qr.requestIP.Equal(other.requestIP) &&
 		qr.user.Equal(other.user) &&
+		qr.workloadName.Equal(other.workloadName) &&
 		qr.query.Equal(other.query) &&
 		qr.leadingComment.Equal(other.leadingComment) &&
 		qr.trailingComment.Equal(other.trailingComment) &&
 		qr.timeout == other.timeout &&
+		qr.resourceGroup == other.resourceGroup &&
+		qr.optimizerHints == other.optimizerHints &&
+		reflect.DeepEqual(qr.indexHints, other.indexHints) &&
+		qr.dryRun == other.dryRun &&
 		reflect.DeepEqual(qr.plans, other.plans) &&
 		reflect.DeepEqual(qr.tableNames, other.tableNames) &&
 		reflect.DeepEqual(qr.bindVarConds, other.bindVarConds) &&
@@ -282,12 +345,16 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		Name:            qr.Name,
 		requestIP:       qr.requestIP,
 		user:            qr.user,
+		workloadName:    qr.workloadName,
 		query:           qr.query,
 		leadingComment:  qr.leadingComment,
 		trailingComment: qr.trailingComment,
 		act:             qr.act,
 		cancelCtx:       qr.cancelCtx,
 		timeout:         qr.timeout,
+		resourceGroup:   qr.resourceGroup,
+		optimizerHints:  qr.optimizerHints,
+		dryRun:          qr.dryRun,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]planbuilder.PlanType, len(qr.plans))
@@ -301,6 +368,10 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		newqr.bindVarConds = make([]BindVarCond, len(qr.bindVarConds))
 		copy(newqr.bindVarConds, qr.bindVarConds)
 	}
+	if qr.indexHints != nil {
+		newqr.indexHints = make([]IndexHint, len(qr.indexHints))
+		copy(newqr.indexHints, qr.indexHints)
+	}
 	return newqr
 }
 
@@ -315,6 +386,9 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.user.Regexp != nil {
 		safeEncode(b, `,"User":`, qr.user)
 	}
+	if qr.workloadName.Regexp != nil {
+		safeEncode(b, `,"WorkloadName":`, qr.workloadName)
+	}
 	if qr.query.Regexp != nil {
 		safeEncode(b, `,"Query":`, qr.query)
 	}
@@ -339,6 +413,18 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.timeout != 0 {
 		safeEncode(b, `,"Timeout":`, qr.timeout)
 	}
+	if qr.resourceGroup != "" {
+		safeEncode(b, `,"ResourceGroup":`, qr.resourceGroup)
+	}
+	if qr.optimizerHints != "" {
+		safeEncode(b, `,"OptimizerHints":`, qr.optimizerHints)
+	}
+	if qr.indexHints != nil {
+		safeEncode(b, `,"IndexHints":`, qr.indexHints)
+	}
+	if qr.dryRun {
+		safeEncode(b, `,"DryRun":`, qr.dryRun)
+	}
 	_, _ = b.WriteString("}")
 	return b.Bytes(), nil
 }
@@ -359,6 +445,91 @@ func (qr *Rule) SetUserCond(pattern string) (err error) {
 	return
 }
 
+// SetWorkloadNameCond adds a regular expression condition for the workload
+// name of the query, set by the WORKLOAD_NAME query directive.
+func (qr *Rule) SetWorkloadNameCond(pattern string) (err error) {
+	qr.workloadName.name = pattern
+	qr.workloadName.Regexp, err = regexp.Compile(makeExact(pattern))
+	return
+}
+
+// SetResourceGroup makes the rule assign the queries it matches to the
+// MySQL resource group, instead of performing an action.
+func (qr *Rule) SetResourceGroup(name string) error {
+	if !resourceGroupNameRE.MatchString(name) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid resource group name: %s", name)
+	}
+	qr.resourceGroup = name
+	qr.act = QRContinue
+	return nil
+}
+
+// ResourceGroup returns the MySQL resource group assigned by the rule.
+func (qr *Rule) ResourceGroup() string {
+	return qr.resourceGroup
+}
+
+// SetOptimizerHints makes the rule inject the optimizer hints, e.g.
+// "JOIN_ORDER(t1, t2) NO_ICP(t1)", into the plans of the queries it matches,
+// instead of performing an action. They are merged into the optimizer hint
+// comment of the queries which already have one.
+func (qr *Rule) SetOptimizerHints(hints string) error {
+	hints = strings.TrimSpace(hints)
+	if hints == "" || strings.Contains(hints, "/*") || strings.Contains(hints, "*/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid optimizer hints: %q", hints)
+	}
+	qr.optimizerHints = hints
+	qr.act = QRContinue
+	return nil
+}
+
+// OptimizerHints returns the optimizer hints injected by the rule.
+func (qr *Rule) OptimizerHints() string {
+	return qr.optimizerHints
+}
+
+// AddIndexHint makes the rule inject the index hint into the plans of the
+// queries it matches, for each reference to its table, instead of performing
+// an action.
+func (qr *Rule) AddIndexHint(hint IndexHint) error {
+	if hint.Table == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing Table in index hint")
+	}
+	hint.Type = strings.ToUpper(hint.Type)
+	switch hint.Type {
+	case "USE", "FORCE", "IGNORE":
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid index hint Type: %s", hint.Type)
+	}
+	if len(hint.Indexes) == 0 && hint.Type != "USE" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing Indexes in %s INDEX hint", hint.Type)
+	}
+	qr.indexHints = append(qr.indexHints, hint)
+	qr.act = QRContinue
+	return nil
+}
+
+// IndexHints returns the index hints injected by the rule.
+func (qr *Rule) IndexHints() []IndexHint {
+	return qr.indexHints
+}
+
+// SetDryRun makes the rule only log the queries it would inject its hints
+// into.
+func (qr *Rule) SetDryRun(dryRun bool) {
+	qr.dryRun = dryRun
+}
+
+// DryRun returns true if the rule only logs the queries it would inject its
+// hints into.
+func (qr *Rule) DryRun() bool {
+	return qr.dryRun
+}
+
+func (qr *Rule) hasHints() bool {
+	return qr.optimizerHints != "" || len(qr.indexHints) > 0
+}
+
 // AddPlanCond adds to the list of plans that can be matched for
 // the rule to fire.
 // This function acts as an OR: Any plan id match is considered a match.
@@ -483,38 +654,56 @@ func (qr *Rule) FilterByPlan(query string, planid planbuilder.PlanType, tableNam
 // GetAction returns the action for a single rule.
 func (qr *Rule) GetAction(
 	ip,
-	user string,
+	user,
+	workloadName string,
 	bindVars map[string]*querypb.BindVariable,
 	marginComments sqlparser.MarginComments,
 ) Action {
+	if !qr.match(ip, user, workloadName, bindVars, marginComments) {
+		return QRContinue
+	}
+	return qr.act
+}
+
+// match returns true if the execution time conditions of the rule match.
+func (qr *Rule) match(
+	ip,
+	user,
+	workloadName string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) bool {
 	if qr.cancelCtx != nil {
 		select {
 		case <-qr.cancelCtx.Done():
 			// rule was cancelled. Nothing else to check
-			return QRContinue
+			return false
 		default:
 			// rule will be cancelled in the future. Until then, it applies!
 			// proceed to evaluate rules
 		}
 	}
 	if !reMatch(qr.leadingComment.Regexp, marginComments.Leading) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.trailingComment.Regexp, marginComments.Trailing) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.requestIP.Regexp, ip) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.user.Regexp, user) {
-		return QRContinue
+		return false
+	}
+	if !reMatch(qr.workloadName.Regexp, workloadName) {
+		return false
 	}
 	for _, bvcond := range qr.bindVarConds {
 		if !bvMatch(bvcond, bindVars) {
-			return QRContinue
+			return false
 		}
 	}
-	return qr.act
+	return true
 }
 
 func reMatch(re *regexp.Regexp, val string) bool {
@@ -876,12 +1065,16 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 		var lv []any
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment":
+		case "Name", "Description", "RequestIP", "User", "WorkloadName", "Query", "Action", "LeadingComment", "TrailingComment", "ResourceGroup", "OptimizerHints":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for %s", k)
 			}
-		case "Plans", "BindVarConds", "TableNames":
+		case "DryRun":
+			if _, ok = v.(bool); !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want bool for %s", k)
+			}
+		case "Plans", "BindVarConds", "TableNames", "IndexHints":
 			lv, ok = v.([]any)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for %s", k)
@@ -904,6 +1097,11 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 			if err != nil {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set User condition: %v", sv)
 			}
+		case "WorkloadName":
+			err = qr.SetWorkloadNameCond(sv)
+			if err != nil {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set WorkloadName condition: %v", sv)
+			}
 		case "Query":
 			err = qr.SetQueryCond(sv)
 			if err != nil {
@@ -961,11 +1159,83 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 			default:
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid Action %s", sv)
 			}
+		case "ResourceGroup":
+			if _, ok := ruleInfo["Action"]; ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a rule cannot have both an Action and a ResourceGroup")
+			}
+			if err := qr.SetResourceGroup(sv); err != nil {
+				return nil, err
+			}
+		case "OptimizerHints":
+			if err := qr.SetOptimizerHints(sv); err != nil {
+				return nil, err
+			}
+		case "IndexHints":
+			for _, ih := range lv {
+				hint, err := buildIndexHint(ih)
+				if err != nil {
+					return nil, err
+				}
+				if err := qr.AddIndexHint(hint); err != nil {
+					return nil, err
+				}
+			}
+		case "DryRun":
+			qr.SetDryRun(v.(bool))
 		}
 	}
+	if qr.hasHints() {
+		// The hints are injected into the plans, so the rule can't depend on
+		// the execution of the queries.
+		if _, ok := ruleInfo["Action"]; ok || qr.resourceGroup != "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a rule with hints cannot have an Action or a ResourceGroup")
+		}
+		if qr.requestIP.Regexp != nil || qr.user.Regexp != nil || qr.workloadName.Regexp != nil ||
+			qr.leadingComment.Regexp != nil || qr.trailingComment.Regexp != nil || qr.bindVarConds != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a rule with hints can only have Query, Plans and TableNames conditions")
+		}
+	} else if _, ok := ruleInfo["DryRun"]; ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "DryRun is only supported by the rules with hints")
+	}
 	return qr, nil
 }
 
+func buildIndexHint(ih any) (hint IndexHint, err error) {
+	ihinfo, ok := ih.(map[string]any)
+	if !ok {
+		return hint, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want json object for index hints")
+	}
+	for k, v := range ihinfo {
+		switch k {
+		case "Table", "Type":
+			sv, ok := v.(string)
+			if !ok {
+				return hint, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for %s in IndexHints", k)
+			}
+			if k == "Table" {
+				hint.Table = sv
+			} else {
+				hint.Type = sv
+			}
+		case "Indexes":
+			lv, ok := v.([]any)
+			if !ok {
+				return hint, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for Indexes in IndexHints")
+			}
+			for _, index := range lv {
+				sv, ok := index.(string)
+				if !ok {
+					return hint, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for Indexes in IndexHints")
+				}
+				hint.Indexes = append(hint.Indexes, sv)
+			}
+		default:
+			return hint, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unrecognized tag %s in IndexHints", k)
+		}
+	}
+	return hint, nil
+}
+
 func buildBindVarCondition(bvc any) (name string, onAbsent, onMismatch bool, op Operator, value any, err error) {
 	bvcinfo, ok := bvc.(map[string]any)
 	if !ok {
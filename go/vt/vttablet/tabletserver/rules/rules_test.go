@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -543,23 +544,23 @@ func TestAction(t *testing.T) {
 		Trailing: "other trailing comments",
 	}
 
-	action, cancelCtx, timeout, desc := qrs.GetAction("123", "user1", bv, mc)
+	action, cancelCtx, timeout, desc := qrs.GetAction("123", "user1", "", bv, mc)
 	assert.Equalf(t, action, QRFail, "expected fail, got %v", action)
 	assert.Equalf(t, timeout, time.Duration(0), "expected zero timeout")
 	assert.Equalf(t, desc, "rule 1", "want rule 1, got %s", desc)
 	assert.Nil(t, cancelCtx)
 
-	action, cancelCtx, timeout, desc = qrs.GetAction("1234", "user", bv, mc)
+	action, cancelCtx, timeout, desc = qrs.GetAction("1234", "user", "", bv, mc)
 	assert.Equalf(t, action, QRFailRetry, "want fail_retry, got: %s", action)
 	assert.Equalf(t, timeout, time.Duration(0), "expected zero timeout")
 	assert.Equalf(t, desc, "rule 2", "want rule 2, got %s", desc)
 	assert.Nil(t, cancelCtx)
 
-	action, _, _, _ = qrs.GetAction("1234", "user1", bv, mc)
+	action, _, _, _ = qrs.GetAction("1234", "user1", "", bv, mc)
 	assert.Equalf(t, action, QRContinue, "want continue, got %s", action)
 
 	bv["a"] = sqltypes.Uint64BindVariable(1)
-	action, _, _, desc = qrs.GetAction("1234", "user1", bv, mc)
+	action, _, _, desc = qrs.GetAction("1234", "user1", "", bv, mc)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 3", "want rule 3, got %s", desc)
 
@@ -572,7 +573,7 @@ func TestAction(t *testing.T) {
 	newQrs := qrs.Copy()
 	newQrs.Add(qr4)
 
-	action, _, _, desc = newQrs.GetAction("1234", "user1", bv, mc)
+	action, _, _, desc = newQrs.GetAction("1234", "user1", "", bv, mc)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 4", "want rule 4, got %s", desc)
 
@@ -581,7 +582,7 @@ func TestAction(t *testing.T) {
 
 	newQrs = qrs.Copy()
 	newQrs.Add(qr5)
-	action, _, _, desc = newQrs.GetAction("1234", "user1", bv, mc)
+	action, _, _, desc = newQrs.GetAction("1234", "user1", "", bv, mc)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 5", "want rule 5, got %s", desc)
 }
@@ -771,6 +772,125 @@ func TestBuildQueryRuleActionFail(t *testing.T) {
 	}
 }
 
+func TestResourceGroup(t *testing.T) {
+	qrs := New()
+	err := qrs.UnmarshalJSON([]byte(`[{
+		"Name": "reports",
+		"WorkloadName": "olap",
+		"ResourceGroup": "batch"
+	},{
+		"Name": "adhoc",
+		"User": "analyst",
+		"ResourceGroup": "adhoc"
+	},{
+		"Name": "deny",
+		"User": "analyst",
+		"Action": "FAIL"
+	}]`))
+	assert.NoError(t, err)
+
+	mc := sqlparser.MarginComments{}
+	assert.Equal(t, "batch", qrs.GetResourceGroup("", "analyst", "olap", nil, mc))
+	assert.Equal(t, "adhoc", qrs.GetResourceGroup("", "analyst", "", nil, mc))
+	assert.Equal(t, "", qrs.GetResourceGroup("", "app", "oltp", nil, mc))
+
+	// The resource group rules don't change the action.
+	action, _, _, desc := qrs.GetAction("", "analyst", "olap", nil, mc)
+	assert.Equal(t, QRFail, action)
+	assert.Equal(t, "", desc)
+	action, _, _, _ = qrs.GetAction("", "app", "olap", nil, mc)
+	assert.Equal(t, QRContinue, action)
+
+	b, err := json.Marshal(qrs.Find("reports"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Description":"","Name":"reports","WorkloadName":"olap","ResourceGroup":"batch"}`, string(b))
+
+	var ruleInfo map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(`{"Action": "FAIL", "ResourceGroup": "batch"}`), &ruleInfo))
+	_, err = BuildQueryRule(ruleInfo)
+	assert.ErrorContains(t, err, "a rule cannot have both an Action and a ResourceGroup")
+
+	ruleInfo = nil
+	assert.NoError(t, json.Unmarshal([]byte(`{"ResourceGroup": "batch */ select"}`), &ruleInfo))
+	_, err = BuildQueryRule(ruleInfo)
+	assert.ErrorContains(t, err, "invalid resource group name")
+}
+
+func TestHintRules(t *testing.T) {
+	qrs := New()
+	err := qrs.UnmarshalJSON([]byte(`[{
+		"Name": "join order",
+		"Query": "select .* from orders join customers .*",
+		"OptimizerHints": "JOIN_ORDER(customers, orders)"
+	},{
+		"Name": "by date",
+		"TableNames": ["orders"],
+		"IndexHints": [{"Table": "orders", "Type": "force", "Indexes": ["by_date"]}],
+		"DryRun": true
+	},{
+		"Name": "deny",
+		"User": "analyst",
+		"Action": "FAIL"
+	}]`))
+	require.NoError(t, err)
+
+	hintRules := qrs.HintRules()
+	require.Len(t, hintRules, 2)
+	assert.Equal(t, "JOIN_ORDER(customers, orders)", hintRules[0].OptimizerHints())
+	assert.False(t, hintRules[0].DryRun())
+	assert.Equal(t, []IndexHint{{Table: "orders", Type: "FORCE", Indexes: []string{"by_date"}}}, hintRules[1].IndexHints())
+	assert.True(t, hintRules[1].DryRun())
+
+	filtered := qrs.FilterByPlan("select * from orders join customers on orders.cid = customers.id", planbuilder.PlanSelect, "orders", "customers")
+	assert.Len(t, filtered.HintRules(), 2)
+	filtered = qrs.FilterByPlan("select * from customers", planbuilder.PlanSelect, "customers")
+	assert.Empty(t, filtered.HintRules())
+
+	// The hint rules don't change the action.
+	mc := sqlparser.MarginComments{}
+	action, _, _, _ := qrs.GetAction("", "app", "", nil, mc)
+	assert.Equal(t, QRContinue, action)
+
+	b, err := json.Marshal(qrs.Find("by date"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Description":"","Name":"by date","TableNames":["orders"],"IndexHints":[{"Table":"orders","Type":"FORCE","Indexes":["by_date"]}],"DryRun":true}`, string(b))
+	assert.True(t, qrs.Find("by date").Equal(qrs.Find("by date").Copy()))
+
+	testcases := []struct {
+		rule string
+		err  string
+	}{{
+		rule: `{"OptimizerHints": "NO_ICP(t) */ select"}`,
+		err:  "invalid optimizer hints",
+	}, {
+		rule: `{"IndexHints": [{"Table": "t", "Type": "PREFER", "Indexes": ["a"]}]}`,
+		err:  "invalid index hint Type: PREFER",
+	}, {
+		rule: `{"IndexHints": [{"Type": "USE"}]}`,
+		err:  "missing Table in index hint",
+	}, {
+		rule: `{"IndexHints": [{"Table": "t", "Type": "FORCE"}]}`,
+		err:  "missing Indexes in FORCE INDEX hint",
+	}, {
+		rule: `{"OptimizerHints": "NO_ICP(t)", "Action": "FAIL"}`,
+		err:  "a rule with hints cannot have an Action or a ResourceGroup",
+	}, {
+		rule: `{"OptimizerHints": "NO_ICP(t)", "User": "analyst"}`,
+		err:  "a rule with hints can only have Query, Plans and TableNames conditions",
+	}, {
+		rule: `{"Action": "FAIL", "DryRun": true}`,
+		err:  "DryRun is only supported by the rules with hints",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.rule, func(t *testing.T) {
+			var ruleInfo map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.rule), &ruleInfo))
+			_, err := BuildQueryRule(ruleInfo)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
 func TestBadAddBindVarCond(t *testing.T) {
 	qr1 := NewQueryRule("rule 1", "r1", QRFail)
 	err := qr1.AddBindVarCond("a", true, false, QRMatch, uint64(1))
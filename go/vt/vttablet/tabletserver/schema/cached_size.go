@@ -42,7 +42,7 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(112)
+		size += int64(144)
 	}
 	// field Name vitess.io/vitess/go/vt/sqlparser.IdentifierCS
 	size += cached.Name.CachedSize(false)
@@ -63,5 +63,7 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	}
 	// field MessageInfo *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.MessageInfo
 	size += cached.MessageInfo.CachedSize(true)
+	// field VersionColumn vitess.io/vitess/go/vt/sqlparser.IdentifierCI
+	size += cached.VersionColumn.CachedSize(false)
 	return size
 }
@@ -669,6 +669,15 @@ func (se *Engine) RegisterVersionEvent() error {
 	return se.historian.RegisterVersionEvent()
 }
 
+// GetHistoricalTableForPos returns a table's schema at a specific GTID/position from
+// the schema history. Unlike GetTableForPos, it never falls back to the schema in the
+// cache or in the database, which may be the one of a later position. It returns nil
+// if the schema of the table at that position is unknown, which is always the case
+// when the schema history is not tracked.
+func (se *Engine) GetHistoricalTableForPos(tableName sqlparser.IdentifierCS, gtid string) (*binlogdatapb.MinimalTable, error) {
+	return se.historian.GetTableForPos(tableName, gtid)
+}
+
 // GetTableForPos makes a best-effort attempt to return a table's schema at a specific
 // GTID/position. If it cannot get the table schema for the given GTID/position then it
 // returns the latest table schema that is available in the database -- the table schema
@@ -1534,3 +1534,47 @@ func TestGetTableForPos(t *testing.T) {
 		})
 	}
 }
+
+func TestGetHistoricalTableForPos(t *testing.T) {
+	se := NewEngineForTests()
+	table := &Table{
+		Name:      sqlparser.NewIdentifierCS("t1"),
+		Fields:    []*querypb.Field{{Name: "id", Type: sqltypes.Int64}, {Name: "val", Type: sqltypes.VarChar}},
+		PKColumns: []int{0},
+	}
+	se.SetTableForTests(table)
+	gtid := "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10"
+
+	// Without the schema history, the schema is unknown even if the table is
+	// in the cache.
+	tbl, err := se.GetHistoricalTableForPos(sqlparser.NewIdentifierCS("t1"), gtid)
+	require.NoError(t, err)
+	require.Nil(t, tbl)
+
+	pos1, err := replication.DecodePosition("MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-5")
+	require.NoError(t, err)
+	pos2, err := replication.DecodePosition("MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-20")
+	require.NoError(t, err)
+	mt := newMinimalTable(table)
+	se.historian.isOpen = true
+	se.historian.schemas = []*trackedSchema{{
+		schema: map[string]*binlogdatapb.MinimalTable{"t1": mt},
+		pos:    pos1,
+	}, {
+		schema: map[string]*binlogdatapb.MinimalTable{},
+		pos:    pos2,
+	}}
+
+	tbl, err = se.GetHistoricalTableForPos(sqlparser.NewIdentifierCS("t1"), gtid)
+	require.NoError(t, err)
+	require.Equal(t, mt, tbl)
+
+	// The schema before the first one tracked is unknown.
+	tbl, err = se.GetHistoricalTableForPos(sqlparser.NewIdentifierCS("t1"), "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-2")
+	require.NoError(t, err)
+	require.Nil(t, tbl)
+
+	tbl, err = se.GetHistoricalTableForPos(sqlparser.NewIdentifierCS("t2"), gtid)
+	require.NoError(t, err)
+	require.Nil(t, tbl)
+}
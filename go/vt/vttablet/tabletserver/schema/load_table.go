@@ -52,6 +52,9 @@ func LoadTable(conn *connpool.PooledConn, databaseName, tableName, tableType str
 	case strings.Contains(tableType, tmutils.TableView):
 		ta.Type = View
 	}
+	if err := loadVersionColumn(ta, comment); err != nil {
+		return nil, err
+	}
 	return ta, nil
 }
 
@@ -160,6 +163,23 @@ func loadMessageInfo(ta *Table, comment string, collationEnv *collations.Environ
 	return nil
 }
 
+// loadVersionColumn loads the vt_version_column attribute of the table comment,
+// which is optional.
+func loadVersionColumn(ta *Table, comment string) error {
+	for _, input := range strings.Split(comment, ",") {
+		kv := strings.Split(input, "=")
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "vt_version_column" {
+			continue
+		}
+		col := sqlparser.NewIdentifierCI(strings.TrimSpace(kv[1]))
+		if ta.FindColumn(col) == -1 {
+			return fmt.Errorf("version column %s missing from table: %s", col.String(), ta.Name.String())
+		}
+		ta.VersionColumn = col
+	}
+	return nil
+}
+
 func getDuration(in map[string]string, key string) (time.Duration, error) {
 	sv := in[key]
 	if sv == "" {
@@ -84,6 +84,20 @@ func TestLoadView(t *testing.T) {
 	assert.Equal(t, want, table)
 }
 
+func TestLoadTableVersionColumn(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	mockLoadTableQueries(db)
+	table, err := newTestLoadTable("USER_TABLE", "vt_version_column=Name", db)
+	require.NoError(t, err)
+	assert.Equal(t, "Name", table.VersionColumn.String())
+	assert.True(t, table.VersionColumn.EqualString("name"))
+
+	mockLoadTableQueries(db)
+	_, err = newTestLoadTable("USER_TABLE", "vt_version_column=version", db)
+	require.EqualError(t, err, "version column version missing from table: test_table")
+}
+
 // TestLoadTableSequence tests that sequence tables are loaded correctly.
 // It also confirms that a reset of a sequence table works.
 func TestLoadTableSequence(t *testing.T) {
@@ -57,6 +57,11 @@ type Table struct {
 	// MessageInfo contains info for message tables.
 	MessageInfo *MessageInfo
 
+	// VersionColumn is the column used for the optimistic concurrency
+	// control of the table, set with the vt_version_column attribute of
+	// the table comment.
+	VersionColumn sqlparser.IdentifierCI
+
 	CreateTime    int64
 	FileSize      uint64
 	AllocatedSize uint64
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/syscallutil"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+const (
+	// A pool is reported as yellow when this fraction of its connections
+	// is in use, and as red when all of them are.
+	selfCheckPoolYellowRatio = 0.8
+
+	// The data directory is reported as yellow, respectively red, when this
+	// fraction of its file system is used.
+	selfCheckDiskYellowRatio = 0.85
+	selfCheckDiskRedRatio    = 0.95
+)
+
+// selfCheckDiskStat reads the disk space of the data directory, and is
+// overridden in tests.
+var selfCheckDiskStat = syscallutil.DiskStat
+
+// SelfCheck is part of the tabletserver.Controller interface. It checks the
+// connection pools of the tablet server, and the disk space of the data
+// directory of MySQL.
+func (tsv *TabletServer) SelfCheck(ctx context.Context) []*tabletmanagerdatapb.SelfCheckResult {
+	return []*tabletmanagerdatapb.SelfCheckResult{
+		tsv.checkPools(),
+		tsv.checkDiskSpace(ctx),
+	}
+}
+
+// checkPools reports the pools which have all, or most, of their connections
+// in use, so that new queries wait for a connection or will soon.
+func (tsv *TabletServer) checkPools() *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{
+		Name:   "pools",
+		Status: tabletmanagerdatapb.SelfCheckResult_GREEN,
+	}
+
+	pools := []struct {
+		name string
+		pool *connpool.Pool
+	}{
+		{"ConnPool", tsv.qe.conns},
+		{"StreamConnPool", tsv.qe.streamConns},
+		{"TransactionPool", tsv.te.txPool.scp.conns},
+	}
+	var usages []string
+	for _, p := range pools {
+		capacity, inUse := p.pool.Capacity(), p.pool.InUse()
+		if p.pool.MinCapacity() < p.pool.MaxCapacity() {
+			// an elastic pool is only exhausted once it cannot grow anymore
+			capacity = p.pool.MaxCapacity()
+		}
+		usages = append(usages, fmt.Sprintf("%s %d/%d", p.name, inUse, capacity))
+		switch {
+		case capacity <= 0:
+		case inUse >= capacity:
+			result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+		case float64(inUse) >= selfCheckPoolYellowRatio*float64(capacity):
+			result.Status = max(result.Status, tabletmanagerdatapb.SelfCheckResult_YELLOW)
+		}
+	}
+	result.Message = "connections in use: " + strings.Join(usages, ", ")
+	return result
+}
+
+// checkDiskSpace reports the file system of the data directory of MySQL
+// filling up. It can only be checked when MySQL runs on the same host.
+func (tsv *TabletServer) checkDiskSpace(ctx context.Context) *tabletmanagerdatapb.SelfCheckResult {
+	result := &tabletmanagerdatapb.SelfCheckResult{
+		Name:   "disk",
+		Status: tabletmanagerdatapb.SelfCheckResult_YELLOW,
+	}
+
+	conn, err := dbconnpool.NewDBConnection(ctx, tsv.config.DB.DbaWithDB())
+	if err != nil {
+		result.Message = fmt.Sprintf("cannot connect to MySQL to find its data directory: %v", err)
+		return result
+	}
+	defer conn.Close()
+	qr, err := conn.ExecuteFetch(datadirQuery, 1, false)
+	if err != nil || len(qr.Rows) != 1 {
+		result.Message = fmt.Sprintf("cannot find the data directory of MySQL: %v", err)
+		return result
+	}
+	datadir := qr.Rows[0][0].ToString()
+
+	free, total, err := selfCheckDiskStat(datadir)
+	if err != nil || total == 0 {
+		result.Message = fmt.Sprintf("cannot read the disk space of %s, MySQL may run on another host: %v", datadir, err)
+		return result
+	}
+	usedRatio := 1 - float64(free)/float64(total)
+	switch {
+	case usedRatio >= selfCheckDiskRedRatio:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_RED
+	case usedRatio >= selfCheckDiskYellowRatio:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_YELLOW
+	default:
+		result.Status = tabletmanagerdatapb.SelfCheckResult_GREEN
+	}
+	result.Message = fmt.Sprintf("%.1f%% of the file system of %s used, %d bytes free", 100*usedRatio, datadir, free)
+	return result
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestSelfCheckPools(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	result := tsv.checkPools()
+	assert.Equal(t, "pools", result.Name)
+	assert.Equal(t, tabletmanagerdatapb.SelfCheckResult_GREEN, result.Status, result.Message)
+	assert.Contains(t, result.Message, "ConnPool 0/100")
+
+	require.NoError(t, tsv.qe.conns.SetCapacity(ctx, 5))
+	var conns []*connpool.PooledConn
+	defer func() {
+		for _, conn := range conns {
+			conn.Recycle()
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		conn, err := tsv.qe.conns.Get(ctx, nil)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+	result = tsv.checkPools()
+	assert.Equal(t, tabletmanagerdatapb.SelfCheckResult_YELLOW, result.Status, result.Message)
+	assert.Contains(t, result.Message, "ConnPool 4/5")
+
+	conn, err := tsv.qe.conns.Get(ctx, nil)
+	require.NoError(t, err)
+	conns = append(conns, conn)
+	result = tsv.checkPools()
+	assert.Equal(t, tabletmanagerdatapb.SelfCheckResult_RED, result.Status, result.Message)
+	assert.Contains(t, result.Message, "ConnPool 5/5")
+}
+
+func TestSelfCheckDiskSpace(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery(datadirQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.datadir", "varchar"), "/var/lib/mysql/"))
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	defer func(saved func(string) (uint64, uint64, error)) {
+		selfCheckDiskStat = saved
+	}(selfCheckDiskStat)
+
+	testcases := []struct {
+		name        string
+		free, total uint64
+		err         error
+		wantStatus  tabletmanagerdatapb.SelfCheckResult_Status
+		wantMessage string
+	}{{
+		name:        "plenty of space",
+		free:        500,
+		total:       1000,
+		wantStatus:  tabletmanagerdatapb.SelfCheckResult_GREEN,
+		wantMessage: "50.0% of the file system of /var/lib/mysql/ used, 500 bytes free",
+	}, {
+		name:        "filling up",
+		free:        100,
+		total:       1000,
+		wantStatus:  tabletmanagerdatapb.SelfCheckResult_YELLOW,
+		wantMessage: "90.0% of the file system of /var/lib/mysql/ used, 100 bytes free",
+	}, {
+		name:        "almost full",
+		free:        10,
+		total:       1000,
+		wantStatus:  tabletmanagerdatapb.SelfCheckResult_RED,
+		wantMessage: "99.0% of the file system of /var/lib/mysql/ used, 10 bytes free",
+	}, {
+		name:        "remote MySQL",
+		err:         errors.New("no such file or directory"),
+		wantStatus:  tabletmanagerdatapb.SelfCheckResult_YELLOW,
+		wantMessage: "cannot read the disk space of /var/lib/mysql/, MySQL may run on another host: no such file or directory",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			selfCheckDiskStat = func(path string) (uint64, uint64, error) {
+				return tcase.free, tcase.total, tcase.err
+			}
+			result := tsv.checkDiskSpace(ctx)
+			assert.Equal(t, "disk", result.Name)
+			assert.Equal(t, tcase.wantStatus, result.Status)
+			assert.Equal(t, tcase.wantMessage, result.Message)
+		})
+	}
+}
@@ -157,6 +157,7 @@ type (
 	queryEngine interface {
 		Open() error
 		IsMySQLReachable() error
+		ConnectError() error
 		Close()
 	}
 
@@ -708,6 +709,11 @@ func (sm *stateManager) Broadcast() {
 	defer sm.mu.Unlock()
 
 	lag, err := sm.refreshReplHealthLocked()
+	if err == nil && sm.state == StateServing {
+		// report the connection pools backing off, so that vtgates route
+		// queries away from this tablet until it can connect to MySQL again
+		err = sm.qe.ConnectError()
+	}
 	sm.hs.ChangeState(sm.target.TabletType, sm.ptsTimestamp, lag, err, sm.isServingLocked())
 }
 
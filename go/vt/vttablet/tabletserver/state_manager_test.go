@@ -668,6 +668,29 @@ func TestStateManagerNotify(t *testing.T) {
 	sm.StopService()
 }
 
+func TestStateManagerBroadcastConnectError(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetServingType(topodatapb.TabletType_PRIMARY, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ch, _ := sm.hs.register()
+	defer sm.hs.unregister(ch)
+	<-ch
+
+	sm.qe.(*testQueryEngine).connectErr = errors.New("connection pool ConnPool is backing off")
+	sm.Broadcast()
+	shr := <-ch
+	assert.Equal(t, "connection pool ConnPool is backing off", shr.RealtimeStats.HealthError)
+	assert.True(t, shr.Serving)
+
+	sm.qe.(*testQueryEngine).connectErr = nil
+	sm.Broadcast()
+	shr = <-ch
+	assert.Empty(t, shr.RealtimeStats.HealthError)
+}
+
 func TestRefreshReplHealthLocked(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
@@ -864,7 +887,8 @@ func (te *testReplTracker) Status() (time.Duration, error) {
 type testQueryEngine struct {
 	testOrderState
 
-	failMySQL bool
+	failMySQL  bool
+	connectErr error
 }
 
 func (te *testQueryEngine) Open() error {
@@ -881,6 +905,10 @@ func (te *testQueryEngine) IsMySQLReachable() error {
 	return nil
 }
 
+func (te *testQueryEngine) ConnectError() error {
+	return te.connectErr
+}
+
 func (te *testQueryEngine) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
@@ -19,11 +19,14 @@ package tabletserver
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"time"
 
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -50,6 +53,9 @@ type StatefulConnection struct {
 	enforceTimeout bool
 	timeout        time.Duration
 	expiryTime     time.Time
+	// lastUsed is when the connection was last unlocked, from which on it
+	// is idle until it is locked again.
+	lastUsed time.Time
 }
 
 // Properties contains meta information about the connection
@@ -87,6 +93,17 @@ func (sc *StatefulConnection) ElapsedTimeout() bool {
 	return sc.expiryTime.Before(time.Now())
 }
 
+// IdleTransaction returns for how long the transaction of the connection has
+// not been used, and whether that is longer than threshold. The transactions
+// which are exempt from the timeouts are never idle.
+func (sc *StatefulConnection) IdleTransaction(threshold time.Duration) (time.Duration, bool) {
+	if !sc.enforceTimeout || threshold <= 0 || !sc.IsInTransaction() || sc.lastUsed.IsZero() {
+		return 0, false
+	}
+	idle := time.Since(sc.lastUsed)
+	return idle, idle > threshold
+}
+
 // Exec executes the statement in the dedicated connection
 func (sc *StatefulConnection) Exec(ctx context.Context, query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
 	if sc.IsClosed() {
@@ -193,6 +210,27 @@ func (sc *StatefulConnection) String(sanitize bool, parser *sqlparser.Parser) st
 	)
 }
 
+// Logf formats the transaction of the connection for the transaction log, as
+// per the streamlog.Formatter interface. With --querylog-format=json, the
+// record includes the timings of the statements. The queries are redacted
+// with --redact-debug-ui-queries or --sanitize_log_messages.
+func (sc *StatefulConnection) Logf(w io.Writer, params url.Values) error {
+	if sc.txProps == nil {
+		return nil
+	}
+	sanitize := streamlog.GetRedactDebugUIQueries() || sc.env.Config().SanitizeLogMessages
+	if streamlog.GetQueryLogFormat() == streamlog.QueryLogFormatJSON {
+		b, err := sc.txProps.JSON(sc.ConnID, sanitize, sc.env.Environment().Parser())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	}
+	_, err := io.WriteString(w, sc.String(sanitize, sc.env.Environment().Parser()))
+	return err
+}
+
 // Current returns the currently executing query
 func (sc *StatefulConnection) Current() string {
 	return sc.dbConn.Conn.Current()
@@ -269,15 +307,19 @@ func (sc *StatefulConnection) LogTransaction(reason tx.ReleaseReason) {
 	sc.txProps.Conclusion = reason.Name()
 	sc.txProps.EndTime = time.Now()
 
-	username := callerid.GetPrincipal(sc.txProps.EffectiveCaller)
-	if username == "" {
-		username = callerid.GetUsername(sc.txProps.ImmediateCaller)
-	}
+	username := sc.txUsername()
 	duration := sc.txProps.EndTime.Sub(sc.txProps.StartTime)
 	sc.Stats().UserTransactionCount.Add([]string{username, reason.Name()}, 1)
 	sc.Stats().UserTransactionTimesNs.Add([]string{username, reason.Name()}, int64(duration))
 	sc.txProps.Stats.Add(reason.Name(), duration)
-	tabletenv.TxLogger.Send(sc)
+	// The transaction state of the connection is cleared once it is logged,
+	// while the subscribers of the logger format it later: they get a
+	// snapshot of the connection with the transaction instead.
+	tabletenv.TxLogger.Send(&StatefulConnection{
+		ConnID:  sc.ConnID,
+		env:     sc.env,
+		txProps: sc.txProps,
+	})
 }
 
 func (sc *StatefulConnection) SetTimeout(timeout time.Duration) {
@@ -305,6 +347,15 @@ func (sc *StatefulConnection) getUsername() string {
 	return callerid.GetUsername(sc.reservedProps.ImmediateCaller)
 }
 
+// txUsername returns the user of the transaction of the connection.
+func (sc *StatefulConnection) txUsername() string {
+	username := callerid.GetPrincipal(sc.txProps.EffectiveCaller)
+	if username != "" {
+		return username
+	}
+	return callerid.GetUsername(sc.txProps.ImmediateCaller)
+}
+
 func (sc *StatefulConnection) ApplySetting(ctx context.Context, setting *smartconnpool.Setting) error {
 	if sc.dbConn.Conn.Setting() == setting {
 		return nil
@@ -145,6 +145,22 @@ func (sf *StatefulConnectionPool) GetElapsedTimeout(purpose string) []*StatefulC
 	}))
 }
 
+// GetIdleTransactions returns the connections whose transaction has not been
+// used for longer than idleTimeout. Does not return any connections that are
+// in use.
+func (sf *StatefulConnectionPool) GetIdleTransactions(idleTimeout time.Duration, purpose string) []*StatefulConnection {
+	return mapToTxConn(sf.active.GetByFilter(purpose, func(val any) bool {
+		_, idle := val.(*StatefulConnection).IdleTransaction(idleTimeout)
+		return idle
+	}))
+}
+
+// GetAll returns all the connections, including the ones in use. The
+// connections in use must not be used by the caller, except to kill them.
+func (sf *StatefulConnectionPool) GetAll() []*StatefulConnection {
+	return mapToTxConn(sf.active.GetAll())
+}
+
 func mapToTxConn(vals []any) []*StatefulConnection {
 	result := make([]*StatefulConnection, len(vals))
 	for i, el := range vals {
@@ -244,6 +260,7 @@ func (sf *StatefulConnectionPool) markAsNotInUse(sc *StatefulConnection, updateT
 	if updateTime {
 		sc.resetExpiryTime()
 	}
+	sc.lastUsed = time.Now()
 	sf.active.Put(sc.ConnID)
 }
 
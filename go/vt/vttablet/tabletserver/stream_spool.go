@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// streamSpool decouples reading the results of a streaming query from MySQL
+// from sending them to the client, so that a client briefly slower than MySQL
+// does not stall the query. The results read ahead are bounded by the size of
+// the spool: when it is full, reading from MySQL pauses until the client
+// catches up, and the gRPC flow control between vtgate and vttablet carries
+// the slowness of the client back to the tablet.
+//
+// The results waiting in the spool are reserved in the memory governor.
+type streamSpool struct {
+	results chan spooledResult
+	mg      *memoryGovernor
+	timings *servenv.TimingsWrapper
+
+	// done is closed when the sender stops, and err is the error of the
+	// client callback which stopped it, if any.
+	done chan struct{}
+	err  error
+}
+
+type spooledResult struct {
+	result      *sqltypes.Result
+	reservation *memoryReservation
+}
+
+// newStreamSpool starts sending the results added to a spool of size results
+// to callback.
+func newStreamSpool(size int, callback StreamCallback, mg *memoryGovernor, timings *servenv.TimingsWrapper) *streamSpool {
+	ss := &streamSpool{
+		results: make(chan spooledResult, size),
+		mg:      mg,
+		timings: timings,
+		done:    make(chan struct{}),
+	}
+	go ss.send(callback)
+	return ss
+}
+
+func (ss *streamSpool) send(callback StreamCallback) {
+	defer close(ss.done)
+	for spooled := range ss.results {
+		spooled.reservation.release()
+		if err := callback(spooled.result); err != nil {
+			ss.err = err
+			return
+		}
+	}
+}
+
+// add adds a result to the spool, waiting while the spool is full. It
+// returns the error of the client callback if the sender stopped.
+func (ss *streamSpool) add(result *sqltypes.Result) error {
+	select {
+	case <-ss.done:
+		return ss.err
+	default:
+	}
+
+	spooled := spooledResult{result: result}
+	if ss.mg.budget.Load() > 0 {
+		spooled.reservation = ss.mg.reserve(result.CachedSize(true))
+	}
+	select {
+	case ss.results <- spooled:
+		return nil
+	default:
+	}
+
+	// The client is slower than MySQL: pause reading until it catches up.
+	defer ss.timings.Record("StreamSpoolFull", time.Now())
+	select {
+	case <-ss.done:
+		spooled.reservation.release()
+		return ss.err
+	case ss.results <- spooled:
+		return nil
+	}
+}
+
+// close waits for the results of the spool to be sent, and returns the error
+// of the stream if any, or else of the client callback.
+func (ss *streamSpool) close(streamErr error) error {
+	close(ss.results)
+	<-ss.done
+	// Release the results which the sender did not send after an error.
+	for spooled := range ss.results {
+		spooled.reservation.release()
+	}
+	if streamErr != nil {
+		return streamErr
+	}
+	return ss.err
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestStreamSpoolEnv(budget int64) (*memoryGovernor, *tabletenv.Stats) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.MemoryBudget = budget
+	env := tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "StreamSpoolTest")
+	return newMemoryGovernor(env), env.Stats()
+}
+
+func TestStreamSpool(t *testing.T) {
+	mg, stats := newTestStreamSpoolEnv(1 << 20)
+
+	// The client blocks until it is unblocked, so that the spool fills up.
+	unblock := make(chan struct{})
+	var got []string
+	spool := newStreamSpool(2, func(qr *sqltypes.Result) error {
+		<-unblock
+		got = append(got, qr.Rows[0][0].ToString())
+		return nil
+	}, mg, stats.WaitTimings)
+
+	fields := sqltypes.MakeTestFields("a", "varchar")
+	var want []string
+	added := make(chan error)
+	go func() {
+		for i := 0; i < 5; i++ {
+			want = append(want, fmt.Sprint(i))
+			if err := spool.add(sqltypes.MakeTestResult(fields, fmt.Sprint(i))); err != nil {
+				added <- err
+				return
+			}
+		}
+		added <- nil
+	}()
+
+	// The first result is being sent and two are spooled: the fourth waits.
+	assert.Eventually(t, func() bool { return mg.inUse.Load() > 0 && len(spool.results) == 2 }, time.Second, time.Millisecond)
+	close(unblock)
+	require.NoError(t, <-added)
+	require.NoError(t, spool.close(nil))
+	assert.Equal(t, want, got)
+	assert.EqualValues(t, 0, mg.inUse.Load())
+	assert.NotZero(t, stats.WaitTimings.Counts()["All"])
+}
+
+func TestStreamSpoolErrors(t *testing.T) {
+	mg, stats := newTestStreamSpoolEnv(1 << 20)
+	fields := sqltypes.MakeTestFields("a", "varchar")
+
+	// The error of the client stops the stream.
+	clientErr := errors.New("client went away")
+	spool := newStreamSpool(10, func(*sqltypes.Result) error {
+		return clientErr
+	}, mg, stats.WaitTimings)
+	require.NoError(t, spool.add(sqltypes.MakeTestResult(fields, "0")))
+	assert.Eventually(t, func() bool {
+		return spool.add(sqltypes.MakeTestResult(fields, "1")) != nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, clientErr, spool.close(nil))
+	assert.EqualValues(t, 0, mg.inUse.Load())
+
+	// The error of the stream wins over the error of the client.
+	streamErr := errors.New("lost connection")
+	spool = newStreamSpool(10, func(*sqltypes.Result) error {
+		return clientErr
+	}, mg, stats.WaitTimings)
+	require.NoError(t, spool.add(sqltypes.MakeTestResult(fields, "0")))
+	assert.Equal(t, streamErr, spool.close(streamErr))
+}
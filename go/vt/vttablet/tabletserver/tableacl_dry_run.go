@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+)
+
+const (
+	// maxACLDryRunEntries bounds the number of callers and tables of the
+	// dry run report. The denials of the other callers and tables are only
+	// counted.
+	maxACLDryRunEntries = 10000
+
+	// maxACLDryRunSamples is the number of distinct sample queries kept for
+	// each caller and table.
+	maxACLDryRunSamples = 5
+)
+
+// aclDryRunReport aggregates the accesses which the table ACLs would deny
+// with --queryserver-config-enable-table-acl-dry-run, per caller and table,
+// so that the impact of --queryserver-config-strict-table-acl can be known
+// before enabling it.
+type aclDryRunReport struct {
+	mu      sync.Mutex
+	entries map[aclDryRunKey]*aclDryRunEntry
+	// dropped counts the denials which are not in the report because it is
+	// full.
+	dropped int64
+}
+
+type aclDryRunKey struct {
+	username string
+	table    string
+}
+
+// aclDryRunEntry reports the denials of a caller on a table. The Username
+// is empty for the queries without a caller ID, which are denied with
+// --queryserver-config-strict-table-acl.
+type aclDryRunEntry struct {
+	Username      string
+	Groups        []string `json:",omitempty"`
+	Table         string
+	TableGroup    string `json:",omitempty"`
+	Count         int64
+	Plans         map[string]int64
+	FirstSeen     time.Time
+	LastSeen      time.Time
+	SampleQueries []string
+}
+
+func newACLDryRunReport() *aclDryRunReport {
+	return &aclDryRunReport{
+		entries: make(map[aclDryRunKey]*aclDryRunEntry),
+	}
+}
+
+// record records a denial of a query on the table to the caller. The query
+// is only formatted while the entry needs more samples.
+func (r *aclDryRunReport) record(username string, groups []string, table, tableGroup, plan string, query func() string) {
+	now := time.Now()
+	key := aclDryRunKey{username: username, table: table}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[key]
+	if !ok {
+		if len(r.entries) >= maxACLDryRunEntries {
+			r.dropped++
+			return
+		}
+		entry = &aclDryRunEntry{
+			Username:   username,
+			Table:      table,
+			TableGroup: tableGroup,
+			Plans:      make(map[string]int64),
+			FirstSeen:  now,
+		}
+		r.entries[key] = entry
+	}
+	// The groups of a caller may change, the latest ones are reported.
+	entry.Groups = groups
+	entry.Count++
+	entry.Plans[plan]++
+	entry.LastSeen = now
+	if len(entry.SampleQueries) < maxACLDryRunSamples {
+		if sample := query(); !slices.Contains(entry.SampleQueries, sample) {
+			entry.SampleQueries = append(entry.SampleQueries, sample)
+		}
+	}
+}
+
+// snapshot returns a copy of the entries whose username and table contain the
+// filters, the most denied first.
+func (r *aclDryRunReport) snapshot(usernameFilter, tableFilter string) []*aclDryRunEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []*aclDryRunEntry
+	for _, entry := range r.entries {
+		if !strings.Contains(entry.Username, usernameFilter) || !strings.Contains(entry.Table, tableFilter) {
+			continue
+		}
+		e := *entry
+		e.Groups = slices.Clone(entry.Groups)
+		e.Plans = make(map[string]int64, len(entry.Plans))
+		for plan, count := range entry.Plans {
+			e.Plans[plan] = count
+		}
+		e.SampleQueries = slices.Clone(entry.SampleQueries)
+		entries = append(entries, &e)
+	}
+	slices.SortFunc(entries, func(a, b *aclDryRunEntry) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.Username, b.Username); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Table, b.Table)
+	})
+	return entries
+}
+
+func (r *aclDryRunReport) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[aclDryRunKey]*aclDryRunEntry)
+	r.dropped = 0
+}
+
+// counts returns the number of callers and tables in the report, and the
+// number of denials which are not in it.
+func (r *aclDryRunReport) counts() (entries int, dropped int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries), r.dropped
+}
+
+// handleHTTPACLDryRun serves the dry run report as JSON. The user and table
+// parameters filter the entries whose username, respectively table, contain
+// them. A POST request clears the report, e.g. after the ACLs are fixed.
+func (qe *QueryEngine) handleHTTPACLDryRun(response http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPost {
+		if err := acl.CheckAccessHTTP(request, acl.ADMIN); err != nil {
+			acl.SendError(response, err)
+			return
+		}
+		qe.aclDryRun.reset()
+		response.Write([]byte("ok\n"))
+		return
+	}
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+
+	entries, dropped := qe.aclDryRun.counts()
+	report := struct {
+		Enabled bool
+		Entries int
+		Dropped int64
+		Denials []*aclDryRunEntry
+	}{
+		Enabled: qe.enableTableACLDryRun,
+		Entries: entries,
+		Dropped: dropped,
+		Denials: qe.aclDryRun.snapshot(request.FormValue("user"), request.FormValue("table")),
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(report, "", " ")
+	if err != nil {
+		response.Write([]byte(err.Error()))
+		return
+	}
+	response.Write(b)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLDryRunReport(t *testing.T) {
+	report := newACLDryRunReport()
+	sample := func(query string) func() string {
+		return func() string { return query }
+	}
+	for i := 0; i < 10; i++ {
+		report.record("u1", []string{"g1"}, "t1", "group1", "Select", sample(fmt.Sprintf("select * from t1 where id = %d", i%7)))
+	}
+	report.record("u1", []string{"g1", "g2"}, "t1", "group1", "Insert", sample("insert into t1 values (1)"))
+	report.record("u2", nil, "t1", "group1", "Select", sample("select * from t1"))
+	report.record("u2", nil, "t2", "group2", "Select", sample("select * from t2"))
+	report.record("u2", nil, "t2", "group2", "Select", sample("select * from t2"))
+
+	denials := report.snapshot("", "")
+	require.Len(t, denials, 3)
+	// The most denied come first.
+	assert.Equal(t, "u1", denials[0].Username)
+	assert.EqualValues(t, 11, denials[0].Count)
+	assert.Equal(t, []string{"g1", "g2"}, denials[0].Groups)
+	assert.Equal(t, map[string]int64{"Select": 10, "Insert": 1}, denials[0].Plans)
+	assert.Len(t, denials[0].SampleQueries, maxACLDryRunSamples)
+	assert.Equal(t, "t2", denials[1].Table)
+	assert.Equal(t, []string{"select * from t2"}, denials[1].SampleQueries)
+	assert.Equal(t, "t1", denials[2].Table)
+	assert.False(t, denials[2].FirstSeen.After(denials[2].LastSeen))
+
+	denials = report.snapshot("u2", "t2")
+	require.Len(t, denials, 1)
+	assert.EqualValues(t, 2, denials[0].Count)
+
+	// The snapshots are copies.
+	denials[0].Plans["Select"] = 100
+	assert.EqualValues(t, 2, report.snapshot("u2", "t2")[0].Plans["Select"])
+
+	// Once the report is full, the denials of new callers and tables are only
+	// counted.
+	for i := 0; len(report.entries) < maxACLDryRunEntries; i++ {
+		report.record(fmt.Sprintf("user%d", i), nil, "t1", "group1", "Select", sample("select 1"))
+	}
+	report.record("u3", nil, "t1", "group1", "Select", sample("select 1"))
+	report.record("u1", nil, "t1", "group1", "Select", sample("select 1"))
+	entries, dropped := report.counts()
+	assert.Equal(t, maxACLDryRunEntries, entries)
+	assert.EqualValues(t, 1, dropped)
+	assert.EqualValues(t, 12, report.snapshot("u1", "t1")[0].Count)
+
+	report.reset()
+	entries, dropped = report.counts()
+	assert.Zero(t, entries)
+	assert.Zero(t, dropped)
+}
+
+func TestACLDryRunHandler(t *testing.T) {
+	qe := &QueryEngine{
+		enableTableACLDryRun: true,
+		aclDryRun:            newACLDryRunReport(),
+	}
+	qe.aclDryRun.record("u1", nil, "t1", "group1", "Select", func() string { return "select * from t1" })
+	qe.aclDryRun.record("u2", nil, "t2", "group2", "Select", func() string { return "select * from t2" })
+
+	resp := httptest.NewRecorder()
+	qe.handleHTTPACLDryRun(resp, httptest.NewRequest(http.MethodGet, "/debug/tableacl_dry_run?table=t2", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	var report struct {
+		Enabled bool
+		Entries int
+		Denials []*aclDryRunEntry
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &report))
+	assert.True(t, report.Enabled)
+	assert.Equal(t, 2, report.Entries)
+	require.Len(t, report.Denials, 1)
+	assert.Equal(t, "u2", report.Denials[0].Username)
+
+	resp = httptest.NewRecorder()
+	qe.handleHTTPACLDryRun(resp, httptest.NewRequest(http.MethodPost, "/debug/tableacl_dry_run", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	entries, _ := qe.aclDryRun.counts()
+	assert.Zero(t, entries)
+}
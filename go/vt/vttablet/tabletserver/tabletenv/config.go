@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -91,6 +93,7 @@ func init() {
 var (
 	queryLogHandler = "/debug/querylog"
 	txLogHandler    = "/debug/txlog"
+	txLogSinks      []string
 )
 
 type TxThrottlerConfigFlag struct {
@@ -120,6 +123,7 @@ func RegisterTabletEnvFlags(fs *pflag.FlagSet) {
 func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&queryLogHandler, "query-log-stream-handler", queryLogHandler, "URL handler for streaming queries log")
 	fs.StringVar(&txLogHandler, "transaction-log-stream-handler", txLogHandler, "URL handler for streaming transactions log")
+	fs.StringSliceVar(&txLogSinks, "txlog-sinks", txLogSinks, "URLs of the sinks to which the transaction logs are shipped, in the format of --querylog-sinks")
 
 	fs.IntVar(&currentConfig.OltpReadPool.Size, "queryserver-config-pool-size", defaultConfig.OltpReadPool.Size, "query server read pool size, connection pool is used by regular queries (non streaming, not in a transaction)")
 	fs.IntVar(&currentConfig.OlapReadPool.Size, "queryserver-config-stream-pool-size", defaultConfig.OlapReadPool.Size, "query server stream connection pool size, stream pool is used by stream queries: queries that return results to client in a streaming fashion")
@@ -132,8 +136,11 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.PassthroughDML, "queryserver-config-passthrough-dmls", defaultConfig.PassthroughDML, "query server pass through all dml statements without rewriting")
 
 	fs.IntVar(&currentConfig.StreamBufferSize, "queryserver-config-stream-buffer-size", defaultConfig.StreamBufferSize, "query server stream buffer size, the maximum number of bytes sent from vttablet for each stream call. It's recommended to keep this value in sync with vtgate's stream_buffer_size.")
+	fs.IntVar(&currentConfig.StreamSpoolSize, "queryserver-config-stream-spool-size", defaultConfig.StreamSpoolSize, "query server stream spool size, the maximum number of results of up to queryserver-config-stream-buffer-size bytes which a streaming query reads ahead from MySQL while the client receives the previous ones. When the spool is full, vttablet pauses reading from MySQL until the client catches up. 0 reads from MySQL only while the client is not receiving a result.")
+	fs.DurationVar(&currentConfig.StreamNetWriteTimeout, "queryserver-config-stream-net-write-timeout", defaultConfig.StreamNetWriteTimeout, "query server stream net write timeout, the session net_write_timeout set on the MySQL connections of streaming queries, so that MySQL does not abort the queries which vttablet pauses while their client is slow. 0 keeps the net_write_timeout of MySQL.")
 
 	fs.Int64Var(&currentConfig.QueryCacheMemory, "queryserver-config-query-cache-memory", defaultConfig.QueryCacheMemory, "query server query cache size in bytes, maximum amount of memory to be used for caching. vttablet analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
+	fs.DurationVar(&currentConfig.QueryCacheTTL, "queryserver-config-query-cache-ttl", defaultConfig.QueryCacheTTL, "query server query cache TTL, how long a query plan is cached before it is planned again, so that the memory of rarely used plans is released even when the cache is not full. 0 caches the plans until they are evicted.")
 
 	fs.DurationVar(&currentConfig.SchemaReloadInterval, "queryserver-config-schema-reload-time", defaultConfig.SchemaReloadInterval, "query server schema reload time, how often vttablet reloads schemas from underlying MySQL instance. vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.")
 	fs.DurationVar(&currentConfig.SchemaChangeReloadTimeout, "schema-change-reload-timeout", defaultConfig.SchemaChangeReloadTimeout, "query server schema change reload timeout, this is how long to wait for the signaled schema reload operation to complete before giving up")
@@ -144,15 +151,29 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&currentConfig.OlapReadPool.Timeout, "queryserver-config-stream-pool-timeout", defaultConfig.OlapReadPool.Timeout, "query server stream pool timeout, it is how long vttablet waits for a connection from the stream pool. If set to 0 (default) then there is no timeout.")
 	fs.DurationVar(&currentConfig.TxPool.Timeout, "queryserver-config-txpool-timeout", defaultConfig.TxPool.Timeout, "query server transaction pool timeout, it is how long vttablet waits if tx pool is full")
 	fs.DurationVar(&currentConfig.OltpReadPool.IdleTimeout, "queryserver-config-idle-timeout", defaultConfig.OltpReadPool.IdleTimeout, "query server idle timeout, vttablet manages various mysql connection pools. This config means if a connection has not been used in given idle timeout, this connection will be removed from pool. This effectively manages number of connection objects and optimize the pool performance.")
+	fs.DurationVar(&currentConfig.ConnectBackoff.Initial, "queryserver-config-pool-connect-backoff", defaultConfig.ConnectBackoff.Initial, "query server connection pools back off for this long after failing to connect to MySQL, doubling the delay on each consecutive failure. While backing off, a pool fails new connections immediately instead of connecting. Setting to 0 disables the backoff.")
+	fs.DurationVar(&currentConfig.ConnectBackoff.Max, "queryserver-config-pool-connect-max-backoff", defaultConfig.ConnectBackoff.Max, "maximum delay of the backoff of the query server connection pools after consecutive failures to connect to MySQL.")
 	fs.DurationVar(&currentConfig.OltpReadPool.MaxLifetime, "queryserver-config-pool-conn-max-lifetime", defaultConfig.OltpReadPool.MaxLifetime, "query server connection max lifetime, vttablet manages various mysql connection pools. This config means if a connection has lived at least this long, it connection will be removed from pool upon the next time it is returned to the pool.")
 
 	// tableacl related configurations.
 	fs.BoolVar(&currentConfig.StrictTableACL, "queryserver-config-strict-table-acl", defaultConfig.StrictTableACL, "only allow queries that pass table acl checks")
 	fs.BoolVar(&currentConfig.EnableTableACLDryRun, "queryserver-config-enable-table-acl-dry-run", defaultConfig.EnableTableACLDryRun, "If this flag is enabled, tabletserver will emit monitoring metrics and let the request pass regardless of table acl check results")
 	fs.StringVar(&currentConfig.TableACLExemptACL, "queryserver-config-acl-exempt-acl", defaultConfig.TableACLExemptACL, "an acl that exempt from table acl checking (this acl is free to access any vitess tables).")
+
+	// External authorization related configurations.
+	fs.StringVar(&currentConfig.ExternalAuthz.Address, "external-authz-address", defaultConfig.ExternalAuthz.Address, "Address of the external authorization service consulted, after the table ACL checks, with the caller IDs, statement type and tables of each query before executing it: host:port of a gRPC service implementing the authz.Authorizer service, or http(s) URL of an Open Policy Agent decision, e.g. http://localhost:8181/v1/data/vitess/allow. Disabled when empty.")
+	fs.DurationVar(&currentConfig.ExternalAuthz.Timeout, "external-authz-timeout", defaultConfig.ExternalAuthz.Timeout, "Timeout of the requests to the external authorization service.")
+	fs.DurationVar(&currentConfig.ExternalAuthz.CacheTTL, "external-authz-cache-ttl", defaultConfig.ExternalAuthz.CacheTTL, "How long the decisions of the external authorization service are cached, per caller, statement type and tables. Setting to 0 disables the cache.")
+	fs.IntVar(&currentConfig.ExternalAuthz.CacheSize, "external-authz-cache-size", defaultConfig.ExternalAuthz.CacheSize, "Maximum number of decisions of the external authorization service cached.")
+	fs.BoolVar(&currentConfig.ExternalAuthz.FailOpen, "external-authz-fail-open", defaultConfig.ExternalAuthz.FailOpen, "Allow the queries when the external authorization service fails or times out, instead of denying them.")
+	fs.StringVar(&currentConfig.ExternalAuthz.Cert, "external-authz-cert", defaultConfig.ExternalAuthz.Cert, "the cert to use to connect to the external authorization service")
+	fs.StringVar(&currentConfig.ExternalAuthz.Key, "external-authz-key", defaultConfig.ExternalAuthz.Key, "the key to use to connect to the external authorization service")
+	fs.StringVar(&currentConfig.ExternalAuthz.CA, "external-authz-ca", defaultConfig.ExternalAuthz.CA, "the server ca to use to validate the external authorization service when connecting")
+	fs.StringVar(&currentConfig.ExternalAuthz.ServerName, "external-authz-server-name", defaultConfig.ExternalAuthz.ServerName, "the server name to use to validate the certificate of the external authorization service")
 	fs.BoolVar(&currentConfig.TerseErrors, "queryserver-config-terse-errors", defaultConfig.TerseErrors, "prevent bind vars from escaping in client error messages")
 	fs.IntVar(&currentConfig.TruncateErrorLen, "queryserver-config-truncate-error-len", defaultConfig.TruncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
 	fs.BoolVar(&currentConfig.AnnotateQueries, "queryserver-config-annotate-queries", defaultConfig.AnnotateQueries, "prefix queries to MySQL backend with comment indicating vtgate principal (user) and target tablet type")
+	fs.BoolVar(&currentConfig.PropagateDeadline, "queryserver-config-propagate-deadline", defaultConfig.PropagateDeadline, "add a MAX_EXECUTION_TIME optimizer hint with the time left until the deadline of the query to the SELECTs sent to MySQL, so that MySQL stops executing them once nobody waits for their results")
 	fs.BoolVar(&currentConfig.WatchReplication, "watch_replication_stream", false, "When enabled, vttablet will stream the MySQL replication stream from the local server, and use it to update schema when it sees a DDL.")
 	fs.BoolVar(&currentConfig.TrackSchemaVersions, "track_schema_versions", false, "When enabled, vttablet will store versions of schemas at each position that a DDL is applied and allow retrieval of the schema corresponding to a position")
 	fs.Int64Var(&currentConfig.SchemaVersionMaxAgeSeconds, "schema-version-max-age-seconds", 0, "max age of schema version records to kept in memory by the vreplication historian")
@@ -181,6 +202,20 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.TransactionLimitByPrincipal, "transaction_limit_by_principal", defaultConfig.TransactionLimitByPrincipal, "Include CallerID.principal when considering who the user is for the purpose of transaction limit.")
 	fs.BoolVar(&currentConfig.TransactionLimitByComponent, "transaction_limit_by_component", defaultConfig.TransactionLimitByComponent, "Include CallerID.component when considering who the user is for the purpose of transaction limit.")
 	fs.BoolVar(&currentConfig.TransactionLimitBySubcomponent, "transaction_limit_by_subcomponent", defaultConfig.TransactionLimitBySubcomponent, "Include CallerID.subcomponent when considering who the user is for the purpose of transaction limit.")
+	fs.Float64Var(&currentConfig.TransactionLimitPerUserOLAP, "transaction-limit-per-user-olap", defaultConfig.TransactionLimitPerUserOLAP, "Maximum number of OLAP transactions a single user is allowed to use at any time, represented as fraction of -transaction_cap. The OLAP transactions are limited separately from the others. 0 uses --transaction_limit_per_user.")
+	fs.Float64Var(&currentConfig.TransactionLimitRatePerUser, "transaction-limit-rate-per-user", defaultConfig.TransactionLimitRatePerUser, "Maximum number of transactions per second a single user is allowed to begin, on top of --transaction-limit-burst-per-user transactions begun at once. 0 does not limit the rate.")
+	fs.Float64Var(&currentConfig.TransactionLimitRatePerUserOLAP, "transaction-limit-rate-per-user-olap", defaultConfig.TransactionLimitRatePerUserOLAP, "Maximum number of OLAP transactions per second a single user is allowed to begin. The OLAP transactions are limited separately from the others. 0 uses --transaction-limit-rate-per-user.")
+	fs.IntVar(&currentConfig.TransactionLimitBurstPerUser, "transaction-limit-burst-per-user", defaultConfig.TransactionLimitBurstPerUser, "Number of transactions a single user is allowed to begin at once when limited by --transaction-limit-rate-per-user, after beginning none for a while. 0 allows one second worth of the rate.")
+	fs.StringSliceVar(&currentConfig.TransactionLimitExemptUsers, "transaction-limit-exempt-users", defaultConfig.TransactionLimitExemptUsers, "Comma-separated list of the VTGateCallerID.username or CallerID.principal of the users the transaction limit does not apply to.")
+
+	fs.Int64Var(&currentConfig.TransactionSizeLimit.MaxRows, "transaction-max-rows-modified", defaultConfig.TransactionSizeLimit.MaxRows, "Maximum number of rows a transaction is allowed to modify in a table. The statement which exceeds it fails, and so does the commit of the transaction. 0 does not limit the rows.")
+	fs.Int64Var(&currentConfig.TransactionSizeLimit.MaxBytes, "transaction-max-bytes-modified", defaultConfig.TransactionSizeLimit.MaxBytes, "Maximum number of binlog bytes a transaction is allowed to generate for a table, estimated from the rows it modifies and the size of the columns of the table. The statement which exceeds it fails, and so does the commit of the transaction. 0 does not limit the bytes.")
+	fs.StringSliceVar(&currentConfig.TransactionSizeLimit.TableOverrides, "transaction-size-limit-table-overrides", defaultConfig.TransactionSizeLimit.TableOverrides, "Comma-separated list of table:rows:bytes overriding --transaction-max-rows-modified and --transaction-max-bytes-modified for the given tables, where 0 does not limit. Example: events:1000000:0")
+	fs.StringSliceVar(&currentConfig.TransactionSizeLimit.CallerOverrides, "transaction-size-limit-caller-overrides", defaultConfig.TransactionSizeLimit.CallerOverrides, "Comma-separated list of user:rows:bytes overriding the transaction size limits of the tables for the users with the given VTGateCallerID.username or CallerID.principal, where 0 does not limit. Example: etl:10000000:0")
+	fs.BoolVar(&currentConfig.TransactionSizeLimit.DryRun, "transaction-size-limit-dry-run", defaultConfig.TransactionSizeLimit.DryRun, "If true, the transactions which exceed the transaction size limits are counted and logged, but not failed.")
+	fs.DurationVar(&currentConfig.IdleTransaction.WarningThreshold, "transaction-idle-warning-threshold", defaultConfig.IdleTransaction.WarningThreshold, "Time a transaction may execute no statement before its next statement returns a warning to the application, which vtgate reports in SHOW WARNINGS. 0 disables the warning.")
+	fs.DurationVar(&currentConfig.IdleTransaction.Timeout, "transaction-idle-timeout", defaultConfig.IdleTransaction.Timeout, "Time a transaction may execute no statement before it is rolled back, which must be shorter than --queryserver-config-transaction-timeout. 0 only rolls back the transactions exceeding the transaction timeout.")
+	fs.StringSliceVar(&currentConfig.DMLChunkSizeTables, "dml-chunk-size-tables", defaultConfig.DMLChunkSizeTables, "Comma-separated list of table:rows of the tables whose UPDATE and DELETE statements without LIMIT, executed outside of a transaction, are executed in a series of transactions modifying at most the given number of rows each, with throttler checks between them. The DML_CHUNK_SIZE query directive does the same for a single statement. Example: events:1000")
 
 	fs.BoolVar(&enableHeartbeat, "heartbeat_enable", false, "If true, vttablet records (if master) or checks (if replica) the current time of a replication heartbeat in the sidecar database's heartbeat table. The result is used to inform the serving state of the vttablet via healthchecks.")
 	fs.DurationVar(&heartbeatInterval, "heartbeat_interval", 1*time.Second, "How frequently to read and write replication heartbeat.")
@@ -191,6 +226,8 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	flagutil.DualFormatBoolVar(fs, &enableConsolidatorReplicas, "enable_consolidator_replicas", false, "This option enables the query consolidator only on replicas.")
 	fs.Int64Var(&currentConfig.ConsolidatorStreamQuerySize, "consolidator-stream-query-size", defaultConfig.ConsolidatorStreamQuerySize, "Configure the stream consolidator query size in bytes. Setting to 0 disables the stream consolidator.")
 	fs.Int64Var(&currentConfig.ConsolidatorStreamTotalSize, "consolidator-stream-total-size", defaultConfig.ConsolidatorStreamTotalSize, "Configure the stream consolidator total size in bytes. Setting to 0 disables the stream consolidator.")
+	fs.Int64Var(&currentConfig.MemoryBudget, "queryserver-config-memory-budget", defaultConfig.MemoryBudget, "Budget in bytes for the result sets held by in-flight queries. Once exceeded, new SELECT queries are limited to the rows that fit in the remaining budget and new streaming queries are rejected, with RESOURCE_EXHAUSTED errors. Setting to 0 disables the memory governor.")
+	fs.IntVar(&currentConfig.QueryDigestsSize, "queryserver-config-query-digests-size", defaultConfig.QueryDigestsSize, "Number of queries, normalized and per caller, whose executions are aggregated for /debug/query_digests. When full, the query with the lowest total time is evicted. Setting to 0 disables the query digests.")
 
 	fs.DurationVar(&healthCheckInterval, "health_check_interval", defaultConfig.Healthcheck.Interval, "Interval between health checks")
 	fs.DurationVar(&degradedThreshold, "degraded_threshold", defaultConfig.Healthcheck.DegradedThreshold, "replication lag after which a replica is considered degraded")
@@ -200,6 +237,8 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&enableReplicationReporter, "enable_replication_reporter", false, "Use polling to track replication lag.")
 	fs.BoolVar(&currentConfig.EnableOnlineDDL, "queryserver_enable_online_ddl", true, "Enable online DDL.")
 	fs.BoolVar(&currentConfig.SanitizeLogMessages, "sanitize_log_messages", false, "Remove potentially sensitive information in tablet INFO, WARNING, and ERROR log messages such as query parameters.")
+	fs.DurationVar(&currentConfig.IdempotencyTokenRetention, "queryserver-config-idempotency-token-retention", defaultConfig.IdempotencyTokenRetention, "How long the idempotency tokens of the autocommit writes retried by vtgate after a failover are kept in the sidecar database. A write retried after its token was purged may be applied twice.")
+	fs.BoolVar(&currentConfig.TxLogLockWait, "txlog-lock-wait", false, "Read the lock wait time of the statements of each transaction from performance_schema at the end of the transaction, and include it in the transaction log. This costs a query per transaction, and requires the events_statements_history consumer.")
 	fs.BoolVar(&currentConfig.EnableSettingsPool, "queryserver-enable-settings-pool", true, "Enable pooling of connections with modified system settings")
 
 	fs.Int64Var(&currentConfig.RowStreamer.MaxInnoDBTrxHistLen, "vreplication_copy_phase_max_innodb_history_list_length", 1000000, "The maximum InnoDB transaction history that can exist on a vstreamer (source) before starting another round of copying rows. This helps to limit the impact on the source tablet.")
@@ -209,12 +248,15 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&currentConfig.EnablePerWorkloadTableMetrics, "enable-per-workload-table-metrics", defaultConfig.EnablePerWorkloadTableMetrics, "If true, query counts and query error metrics include a label that identifies the workload")
 
+	fs.BoolVar(&currentConfig.EnableFaultInjection, "enable-fault-injection", false, "Enable the injection of latency, connection errors, pool exhaustion and replication stalls into the queries of the tablet through the /debug/faults admin endpoint, for endtoend and chaos tests. Never enable it in production.")
+
 	fs.BoolVar(&currentConfig.Unmanaged, "unmanaged", false, "Indicates an unmanaged tablet, i.e. using an external mysql-compatible database")
 }
 
 var (
 	queryLogHandlerOnce sync.Once
 	txLogHandlerOnce    sync.Once
+	logSinksOnce        sync.Once
 )
 
 // Init must be called after flag.Parse, and before doing any other operations.
@@ -289,6 +331,23 @@ func Init() {
 			TxLogger.ServeLogs(txLogHandler, streamlog.GetFormatter(TxLogger))
 		})
 	}
+
+	logSinksOnce.Do(func() {
+		if sinks := streamlog.GetQueryLogSinks(); len(sinks) > 0 {
+			stop, err := StatsLogger.LogToSinks(sinks, streamlog.GetFormatter(StatsLogger))
+			if err != nil {
+				log.Exitf("Invalid querylog-sinks value %v: %v", sinks, err)
+			}
+			servenv.OnClose(stop)
+		}
+		if len(txLogSinks) > 0 {
+			stop, err := TxLogger.LogToSinks(txLogSinks, streamlog.GetFormatter(TxLogger))
+			if err != nil {
+				log.Exitf("Invalid txlog-sinks value %v: %v", txLogSinks, err)
+			}
+			servenv.OnClose(stop)
+		}
+	})
 }
 
 // TabletConfig contains all the configuration for query service
@@ -301,6 +360,8 @@ type TabletConfig struct {
 	OlapReadPool ConnPoolConfig `json:"olapReadPool,omitempty"`
 	TxPool       ConnPoolConfig `json:"txPool,omitempty"`
 
+	ConnectBackoff ConnectBackoffConfig `json:"connectBackoff,omitempty"`
+
 	Olap             OlapConfig             `json:"olap,omitempty"`
 	Oltp             OltpConfig             `json:"oltp,omitempty"`
 	HotRowProtection HotRowProtectionConfig `json:"hotRowProtection,omitempty"`
@@ -314,10 +375,15 @@ type TabletConfig struct {
 	Consolidator                     string        `json:"consolidator,omitempty"`
 	PassthroughDML                   bool          `json:"passthroughDML,omitempty"`
 	StreamBufferSize                 int           `json:"streamBufferSize,omitempty"`
+	StreamSpoolSize                  int           `json:"streamSpoolSize,omitempty"`
+	StreamNetWriteTimeout            time.Duration `json:"-"`
 	ConsolidatorStreamTotalSize      int64         `json:"consolidatorStreamTotalSize,omitempty"`
 	ConsolidatorStreamQuerySize      int64         `json:"consolidatorStreamQuerySize,omitempty"`
+	MemoryBudget                     int64         `json:"memoryBudget,omitempty"`
+	QueryDigestsSize                 int           `json:"queryDigestsSize,omitempty"`
 	QueryCacheMemory                 int64         `json:"queryCacheMemory,omitempty"`
 	QueryCacheDoorkeeper             bool          `json:"queryCacheDoorkeeper,omitempty"`
+	QueryCacheTTL                    time.Duration `json:"-"`
 	SchemaReloadInterval             time.Duration `json:"schemaReloadIntervalSeconds,omitempty"`
 	SignalSchemaChangeReloadInterval time.Duration `json:"signalSchemaChangeReloadIntervalSeconds,omitempty"`
 	SchemaChangeReloadTimeout        time.Duration `json:"schemaChangeReloadTimeout,omitempty"`
@@ -327,8 +393,10 @@ type TabletConfig struct {
 	TerseErrors                      bool          `json:"terseErrors,omitempty"`
 	TruncateErrorLen                 int           `json:"truncateErrorLen,omitempty"`
 	AnnotateQueries                  bool          `json:"annotateQueries,omitempty"`
+	PropagateDeadline                bool          `json:"propagateDeadline,omitempty"`
 	MessagePostponeParallelism       int           `json:"messagePostponeParallelism,omitempty"`
 	SignalWhenSchemaChange           bool          `json:"signalWhenSchemaChange,omitempty"`
+	TxLogLockWait                    bool          `json:"txLogLockWait,omitempty"`
 
 	ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
 
@@ -348,10 +416,22 @@ type TabletConfig struct {
 	TxThrottlerTopoRefreshInterval time.Duration                 `json:"-"`
 	TxThrottlerDryRun              bool                          `json:"-"`
 
+	IdempotencyTokenRetention time.Duration `json:"-"`
+
+	ExternalAuthz ExternalAuthzConfig `json:"-"`
+
 	EnableTableGC bool `json:"-"` // can be turned off programmatically by tests
 
 	TransactionLimitConfig `json:"-"`
 
+	TransactionSizeLimit TransactionSizeLimitConfig `json:"-"`
+
+	IdleTransaction IdleTransactionConfig `json:"-"`
+
+	// DMLChunkSizeTables are the table:rows chunk sizes of the tables whose
+	// UPDATE and DELETE statements are executed in chunks.
+	DMLChunkSizeTables []string `json:"-"`
+
 	EnforceStrictTransTables bool `json:"-"`
 	EnableOnlineDDL          bool `json:"-"`
 	EnableSettingsPool       bool `json:"-"`
@@ -361,6 +441,8 @@ type TabletConfig struct {
 	EnableViews bool `json:"-"`
 
 	EnablePerWorkloadTableMetrics bool `json:"-"`
+
+	EnableFaultInjection bool `json:"-"`
 }
 
 func (cfg *TabletConfig) MarshalJSON() ([]byte, error) {
@@ -445,6 +527,15 @@ type ConnPoolConfig struct {
 	IdleTimeout        time.Duration `json:"idleTimeoutSeconds,omitempty"`
 	MaxLifetime        time.Duration `json:"maxLifetimeSeconds,omitempty"`
 	PrefillParallelism int           `json:"prefillParallelism,omitempty"`
+	// MinSize makes the pool elastic between MinSize and Size connections: it
+	// grows by ResizeStep connections whenever queries have kept waiting for a
+	// connection for GrowInterval, and shrinks by ResizeStep connections whenever
+	// it has kept that many spare connections for ShrinkInterval. 0 keeps the
+	// pool at Size connections.
+	MinSize        int           `json:"minSize,omitempty"`
+	GrowInterval   time.Duration `json:"growIntervalSeconds,omitempty"`
+	ShrinkInterval time.Duration `json:"shrinkIntervalSeconds,omitempty"`
+	ResizeStep     int           `json:"resizeStep,omitempty"`
 }
 
 func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
@@ -452,9 +543,11 @@ func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
 
 	tmp := struct {
 		Proxy
-		Timeout     string `json:"timeoutSeconds,omitempty"`
-		IdleTimeout string `json:"idleTimeoutSeconds,omitempty"`
-		MaxLifetime string `json:"maxLifetimeSeconds,omitempty"`
+		Timeout        string `json:"timeoutSeconds,omitempty"`
+		IdleTimeout    string `json:"idleTimeoutSeconds,omitempty"`
+		MaxLifetime    string `json:"maxLifetimeSeconds,omitempty"`
+		GrowInterval   string `json:"growIntervalSeconds,omitempty"`
+		ShrinkInterval string `json:"shrinkIntervalSeconds,omitempty"`
 	}{
 		Proxy: Proxy(*cfg),
 	}
@@ -471,6 +564,14 @@ func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
 		tmp.MaxLifetime = d.String()
 	}
 
+	if d := cfg.GrowInterval; d != 0 {
+		tmp.GrowInterval = d.String()
+	}
+
+	if d := cfg.ShrinkInterval; d != 0 {
+		tmp.ShrinkInterval = d.String()
+	}
+
 	return json.Marshal(&tmp)
 }
 
@@ -481,6 +582,10 @@ func (cfg *ConnPoolConfig) UnmarshalJSON(data []byte) (err error) {
 		IdleTimeout        string `json:"idleTimeoutSeconds,omitempty"`
 		MaxLifetime        string `json:"maxLifetimeSeconds,omitempty"`
 		PrefillParallelism int    `json:"prefillParallelism,omitempty"`
+		MinSize            int    `json:"minSize,omitempty"`
+		GrowInterval       string `json:"growIntervalSeconds,omitempty"`
+		ShrinkInterval     string `json:"shrinkIntervalSeconds,omitempty"`
+		ResizeStep         int    `json:"resizeStep,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
@@ -508,8 +613,24 @@ func (cfg *ConnPoolConfig) UnmarshalJSON(data []byte) (err error) {
 		}
 	}
 
+	if tmp.GrowInterval != "" {
+		cfg.GrowInterval, err = time.ParseDuration(tmp.GrowInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tmp.ShrinkInterval != "" {
+		cfg.ShrinkInterval, err = time.ParseDuration(tmp.ShrinkInterval)
+		if err != nil {
+			return err
+		}
+	}
+
 	cfg.Size = tmp.Size
 	cfg.PrefillParallelism = tmp.PrefillParallelism
+	cfg.MinSize = tmp.MinSize
+	cfg.ResizeStep = tmp.ResizeStep
 
 	return nil
 }
@@ -613,6 +734,78 @@ func (cfg *OltpConfig) UnmarshalJSON(data []byte) (err error) {
 	return nil
 }
 
+// ConnectBackoffConfig contains the config for the backoff of the connection
+// pools after failing to connect to MySQL.
+type ConnectBackoffConfig struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+func (cfg *ConnectBackoffConfig) MarshalJSON() ([]byte, error) {
+	var tmp struct {
+		InitialSeconds string `json:"initialSeconds,omitempty"`
+		MaxSeconds     string `json:"maxSeconds,omitempty"`
+	}
+
+	if d := cfg.Initial; d != 0 {
+		tmp.InitialSeconds = d.String()
+	}
+
+	if d := cfg.Max; d != 0 {
+		tmp.MaxSeconds = d.String()
+	}
+
+	return json.Marshal(&tmp)
+}
+
+func (cfg *ConnectBackoffConfig) UnmarshalJSON(data []byte) (err error) {
+	var tmp struct {
+		Initial string `json:"initialSeconds,omitempty"`
+		Max     string `json:"maxSeconds,omitempty"`
+	}
+
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if tmp.Initial != "" {
+		cfg.Initial, err = time.ParseDuration(tmp.Initial)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tmp.Max != "" {
+		cfg.Max, err = time.ParseDuration(tmp.Max)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExternalAuthzConfig contains the config of the external authorization
+// service consulted before executing the queries.
+type ExternalAuthzConfig struct {
+	// Address is the host:port of a gRPC authz.Authorizer service, or the
+	// http(s) URL of an OPA decision. The service is not consulted when it
+	// is empty.
+	Address   string
+	Timeout   time.Duration
+	CacheTTL  time.Duration
+	CacheSize int
+	// FailOpen allows the queries when the service fails.
+	FailOpen bool
+
+	// Cert, Key, CA and ServerName configure the TLS of the connection to
+	// the service, for gRPC and https addresses.
+	Cert       string
+	Key        string
+	CA         string
+	ServerName string
+}
+
 // HotRowProtectionConfig contains the config for hot row protection.
 type HotRowProtectionConfig struct {
 	// Mode can be disable, dryRun or enable. Default is disable.
@@ -805,6 +998,99 @@ type TransactionLimitConfig struct {
 	TransactionLimitByPrincipal    bool
 	TransactionLimitByComponent    bool
 	TransactionLimitBySubcomponent bool
+	// TransactionLimitPerUserOLAP is the fraction of the pool the OLAP
+	// transactions of a user may use, or 0 to use TransactionLimitPerUser.
+	TransactionLimitPerUserOLAP float64
+	// TransactionLimitRatePerUser is the number of transactions per second a
+	// user may begin, or 0 for no limit.
+	TransactionLimitRatePerUser float64
+	// TransactionLimitRatePerUserOLAP is the number of OLAP transactions per
+	// second a user may begin, or 0 to use TransactionLimitRatePerUser.
+	TransactionLimitRatePerUserOLAP float64
+	// TransactionLimitBurstPerUser is the number of transactions a user
+	// limited by rate may begin at once, or 0 for one second of the rate.
+	TransactionLimitBurstPerUser int
+	// TransactionLimitExemptUsers are the usernames and principals of the
+	// users which are not limited.
+	TransactionLimitExemptUsers []string
+}
+
+// TransactionSizeLimitConfig contains the limits on the rows a transaction
+// modifies in each table, and on the binlog bytes it generates for them.
+type TransactionSizeLimitConfig struct {
+	// MaxRows is the number of rows a transaction may modify in a table, or 0
+	// for no limit.
+	MaxRows int64
+	// MaxBytes is the estimated number of binlog bytes a transaction may
+	// generate for a table, or 0 for no limit.
+	MaxBytes int64
+	// TableOverrides are the table:rows:bytes limits of the tables which do
+	// not use MaxRows and MaxBytes.
+	TableOverrides []string
+	// CallerOverrides are the user:rows:bytes limits of the users which
+	// use neither MaxRows and MaxBytes nor TableOverrides.
+	CallerOverrides []string
+	// DryRun only counts and logs the transactions over the limits.
+	DryRun bool
+}
+
+// IdleTransactionConfig contains the thresholds after which the transactions
+// which execute no statement are warned about and rolled back.
+type IdleTransactionConfig struct {
+	// WarningThreshold is the time after which the next statement of an idle
+	// transaction returns a warning, or 0 for no warning.
+	WarningThreshold time.Duration
+	// Timeout is the time after which an idle transaction is rolled back, or
+	// 0 to only roll back the transactions exceeding their TxTimeout.
+	Timeout time.Duration
+}
+
+// TransactionSizeLimit is the number of rows a transaction may modify in a
+// table, and the estimated number of binlog bytes it may generate for it,
+// where 0 does not limit.
+type TransactionSizeLimit struct {
+	Rows  int64
+	Bytes int64
+}
+
+// ParseTransactionSizeLimits parses the name:rows:bytes overrides of the
+// transaction size limits, keyed by name.
+func ParseTransactionSizeLimits(overrides []string) (map[string]TransactionSizeLimit, error) {
+	limits := make(map[string]TransactionSizeLimit, len(overrides))
+	for _, override := range overrides {
+		parts := strings.Split(override, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid transaction size limit override %q, expected name:rows:bytes", override)
+		}
+		rows, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || rows < 0 {
+			return nil, fmt.Errorf("invalid rows in transaction size limit override %q", override)
+		}
+		bytes, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || bytes < 0 {
+			return nil, fmt.Errorf("invalid bytes in transaction size limit override %q", override)
+		}
+		limits[parts[0]] = TransactionSizeLimit{Rows: rows, Bytes: bytes}
+	}
+	return limits, nil
+}
+
+// ParseDMLChunkSizes parses the table:rows chunk sizes of the tables whose
+// UPDATE and DELETE statements are executed in chunks, keyed by table.
+func ParseDMLChunkSizes(tables []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		name, rows, ok := strings.Cut(table, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid DML chunk size %q, expected table:rows", table)
+		}
+		size, err := strconv.ParseInt(rows, 10, 64)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid rows in DML chunk size %q", table)
+		}
+		sizes[name] = size
+	}
+	return sizes, nil
 }
 
 // RowStreamerConfig contains configuration parameters for a vstreamer (source) that is
@@ -869,6 +1155,18 @@ func (c *TabletConfig) Verify() error {
 	if err := c.verifyTxThrottlerConfig(); err != nil {
 		return err
 	}
+	if err := c.verifyTransactionSizeLimitConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyIdleTransactionConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyConnPoolConfig(); err != nil {
+		return err
+	}
+	if _, err := ParseDMLChunkSizes(c.DMLChunkSizeTables); err != nil {
+		return fmt.Errorf("--dml-chunk-size-tables: %w", err)
+	}
 	if v := c.HotRowProtection.MaxQueueSize; v <= 0 {
 		return fmt.Errorf("--hot_row_protection_max_queue_size must be > 0 (specified value: %v)", v)
 	}
@@ -955,6 +1253,63 @@ func (c *TabletConfig) verifyTransactionLimitConfig() error {
 	if limit := int(c.TransactionLimitPerUser * float64(c.TxPool.Size)); limit == 0 {
 		return fmt.Errorf("effective transaction limit per user is 0 due to rounding, increase --transaction_limit_per_user")
 	}
+	if v := c.TransactionLimitPerUserOLAP; v < 0 || v >= 1 {
+		return fmt.Errorf("--transaction-limit-per-user-olap should be 0 or a fraction within range (0, 1) (specified value: %v)", v)
+	} else if limit := int(v * float64(c.TxPool.Size)); v > 0 && limit == 0 {
+		return fmt.Errorf("effective OLAP transaction limit per user is 0 due to rounding, increase --transaction-limit-per-user-olap")
+	}
+	if c.TransactionLimitRatePerUser < 0 || c.TransactionLimitRatePerUserOLAP < 0 {
+		return fmt.Errorf("--transaction-limit-rate-per-user and --transaction-limit-rate-per-user-olap should not be negative")
+	}
+	if c.TransactionLimitBurstPerUser < 0 {
+		return fmt.Errorf("--transaction-limit-burst-per-user should not be negative (specified value: %v)", c.TransactionLimitBurstPerUser)
+	}
+	return nil
+}
+
+// verifyTransactionSizeLimitConfig checks TransactionSizeLimitConfig for sanity.
+func (c *TabletConfig) verifyTransactionSizeLimitConfig() error {
+	if c.TransactionSizeLimit.MaxRows < 0 || c.TransactionSizeLimit.MaxBytes < 0 {
+		return errors.New("--transaction-max-rows-modified and --transaction-max-bytes-modified should not be negative")
+	}
+	if _, err := ParseTransactionSizeLimits(c.TransactionSizeLimit.TableOverrides); err != nil {
+		return fmt.Errorf("--transaction-size-limit-table-overrides: %w", err)
+	}
+	if _, err := ParseTransactionSizeLimits(c.TransactionSizeLimit.CallerOverrides); err != nil {
+		return fmt.Errorf("--transaction-size-limit-caller-overrides: %w", err)
+	}
+	return nil
+}
+
+// verifyIdleTransactionConfig checks IdleTransactionConfig for sanity.
+func (c *TabletConfig) verifyIdleTransactionConfig() error {
+	warningThreshold, timeout := c.IdleTransaction.WarningThreshold, c.IdleTransaction.Timeout
+	if warningThreshold < 0 || timeout < 0 {
+		return errors.New("--transaction-idle-warning-threshold and --transaction-idle-timeout should not be negative")
+	}
+	if timeout > 0 && c.Oltp.TxTimeout > 0 && timeout >= c.Oltp.TxTimeout {
+		return fmt.Errorf("--transaction-idle-timeout must be shorter than --queryserver-config-transaction-timeout (%v >= %v)", timeout, c.Oltp.TxTimeout)
+	}
+	if warningThreshold > 0 && timeout > 0 && warningThreshold >= timeout {
+		return fmt.Errorf("--transaction-idle-warning-threshold must be shorter than --transaction-idle-timeout (%v >= %v)", warningThreshold, timeout)
+	}
+	return nil
+}
+
+// verifyConnPoolConfig checks the elastic sizes of the conn pools for sanity.
+func (c *TabletConfig) verifyConnPoolConfig() error {
+	for name, pool := range map[string]ConnPoolConfig{
+		"oltpReadPool": c.OltpReadPool,
+		"olapReadPool": c.OlapReadPool,
+		"txPool":       c.TxPool,
+	} {
+		if pool.MinSize < 0 || pool.ResizeStep < 0 || pool.GrowInterval < 0 || pool.ShrinkInterval < 0 {
+			return fmt.Errorf("%s: minSize, resizeStep, growIntervalSeconds and shrinkIntervalSeconds should not be negative", name)
+		}
+		if pool.MinSize > pool.Size {
+			return fmt.Errorf("%s: minSize must not be greater than size (%v > %v)", name, pool.MinSize, pool.Size)
+		}
+	}
 	return nil
 }
 
@@ -1004,6 +1359,17 @@ var defaultConfig = TabletConfig{
 		Timeout:     time.Second,
 		IdleTimeout: 30 * time.Minute,
 	},
+	// The backoff is disabled by default: while it is backing off, a serving
+	// tablet reports itself unhealthy, and a single failed connection would
+	// make its health flap.
+	ConnectBackoff: ConnectBackoffConfig{
+		Max: 10 * time.Second,
+	},
+	ExternalAuthz: ExternalAuthzConfig{
+		Timeout:   time.Second,
+		CacheTTL:  time.Minute,
+		CacheSize: 10000,
+	},
 	Olap: OlapConfig{
 		TxTimeout: 30 * time.Second,
 	},
@@ -1064,6 +1430,8 @@ var defaultConfig = TabletConfig{
 	TxThrottlerDryRun:              false,
 	TxThrottlerTopoRefreshInterval: time.Minute * 5,
 
+	IdempotencyTokenRetention: time.Hour,
+
 	TransactionLimitConfig: defaultTransactionLimitConfig(),
 
 	EnforceStrictTransTables: true,
@@ -49,10 +49,14 @@ func TestConfigParse(t *testing.T) {
 			},
 		},
 		OltpReadPool: ConnPoolConfig{
-			Size:        16,
-			Timeout:     10 * time.Second,
-			IdleTimeout: 20 * time.Second,
-			MaxLifetime: 50 * time.Second,
+			Size:           16,
+			Timeout:        10 * time.Second,
+			IdleTimeout:    20 * time.Second,
+			MaxLifetime:    50 * time.Second,
+			MinSize:        4,
+			GrowInterval:   2 * time.Second,
+			ShrinkInterval: 10 * time.Minute,
+			ResizeStep:     2,
 		},
 		RowStreamer: RowStreamerConfig{
 			MaxInnoDBTrxHistLen: 1000,
@@ -62,7 +66,8 @@ func TestConfigParse(t *testing.T) {
 
 	gotBytes, err := yaml2.Marshal(&cfg)
 	require.NoError(t, err)
-	wantBytes := `db:
+	wantBytes := `connectBackoff: {}
+db:
   allprivs:
     password: '****'
   app:
@@ -85,8 +90,12 @@ olap: {}
 olapReadPool: {}
 oltp: {}
 oltpReadPool:
+  growIntervalSeconds: 2s
   idleTimeoutSeconds: 20s
   maxLifetimeSeconds: 50s
+  minSize: 4
+  resizeStep: 2
+  shrinkIntervalSeconds: 10m0s
   size: 16
   timeoutSeconds: 10s
 replicationTracker: {}
@@ -110,6 +119,10 @@ oltpReadPool:
   size: 16
   idleTimeoutSeconds: 20s
   maxLifetimeSeconds: 50s
+  minSize: 4
+  growIntervalSeconds: 2s
+  shrinkIntervalSeconds: 10m
+  resizeStep: 2
 `)
 	gotCfg := cfg
 	gotCfg.DB = cfg.DB.Clone()
@@ -122,7 +135,9 @@ oltpReadPool:
 func TestDefaultConfig(t *testing.T) {
 	gotBytes, err := yaml2.Marshal(NewDefaultConfig())
 	require.NoError(t, err)
-	want := `consolidator: enable
+	want := `connectBackoff:
+  maxSeconds: 10s
+consolidator: enable
 consolidatorStreamQuerySize: 2097152
 consolidatorStreamTotalSize: 134217728
 gracePeriods:
@@ -481,3 +496,97 @@ func TestVerifyUnmanagedTabletConfig(t *testing.T) {
 	err = config.verifyUnmanagedTabletConfig()
 	assert.Nil(t, err)
 }
+
+func TestVerifyTransactionLimitConfig(t *testing.T) {
+	config := NewDefaultConfig()
+	config.TxPool.Size = 10
+	config.EnableTransactionLimit = true
+	assert.NoError(t, config.verifyTransactionLimitConfig())
+
+	config.TransactionLimitPerUserOLAP = 0.05
+	assert.EqualError(t, config.verifyTransactionLimitConfig(), "effective OLAP transaction limit per user is 0 due to rounding, increase --transaction-limit-per-user-olap")
+	config.TransactionLimitPerUserOLAP = 1
+	assert.ErrorContains(t, config.verifyTransactionLimitConfig(), "--transaction-limit-per-user-olap should be 0 or a fraction within range (0, 1)")
+	config.TransactionLimitPerUserOLAP = 0.2
+	assert.NoError(t, config.verifyTransactionLimitConfig())
+
+	config.TransactionLimitRatePerUser = -1
+	assert.ErrorContains(t, config.verifyTransactionLimitConfig(), "should not be negative")
+	config.TransactionLimitRatePerUser = 100
+	config.TransactionLimitBurstPerUser = -1
+	assert.ErrorContains(t, config.verifyTransactionLimitConfig(), "--transaction-limit-burst-per-user should not be negative")
+	config.TransactionLimitBurstPerUser = 10
+	assert.NoError(t, config.verifyTransactionLimitConfig())
+}
+
+func TestVerifyTransactionSizeLimitConfig(t *testing.T) {
+	config := defaultConfig.Clone()
+	assert.NoError(t, config.verifyTransactionSizeLimitConfig())
+
+	config.TransactionSizeLimit.MaxRows = -1
+	assert.ErrorContains(t, config.verifyTransactionSizeLimitConfig(), "should not be negative")
+	config.TransactionSizeLimit.MaxRows = 1000
+
+	config.TransactionSizeLimit.TableOverrides = []string{"events:1000000"}
+	assert.ErrorContains(t, config.verifyTransactionSizeLimitConfig(), `--transaction-size-limit-table-overrides: invalid transaction size limit override "events:1000000", expected name:rows:bytes`)
+	config.TransactionSizeLimit.TableOverrides = []string{"events:1000000:0"}
+	config.TransactionSizeLimit.CallerOverrides = []string{"etl:-1:0"}
+	assert.ErrorContains(t, config.verifyTransactionSizeLimitConfig(), `--transaction-size-limit-caller-overrides: invalid rows in transaction size limit override "etl:-1:0"`)
+	config.TransactionSizeLimit.CallerOverrides = []string{"etl:0:1073741824"}
+	assert.NoError(t, config.verifyTransactionSizeLimitConfig())
+
+	limits, err := ParseTransactionSizeLimits(config.TransactionSizeLimit.CallerOverrides)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]TransactionSizeLimit{"etl": {Rows: 0, Bytes: 1073741824}}, limits)
+}
+
+func TestVerifyIdleTransactionConfig(t *testing.T) {
+	config := NewDefaultConfig()
+	assert.NoError(t, config.verifyIdleTransactionConfig())
+
+	config.IdleTransaction.WarningThreshold = -time.Second
+	assert.ErrorContains(t, config.verifyIdleTransactionConfig(), "should not be negative")
+
+	config.IdleTransaction.WarningThreshold = 5 * time.Second
+	config.IdleTransaction.Timeout = config.Oltp.TxTimeout
+	assert.ErrorContains(t, config.verifyIdleTransactionConfig(), "--transaction-idle-timeout must be shorter than --queryserver-config-transaction-timeout (30s >= 30s)")
+
+	config.IdleTransaction.Timeout = 5 * time.Second
+	assert.ErrorContains(t, config.verifyIdleTransactionConfig(), "--transaction-idle-warning-threshold must be shorter than --transaction-idle-timeout (5s >= 5s)")
+
+	config.IdleTransaction.Timeout = 10 * time.Second
+	assert.NoError(t, config.verifyIdleTransactionConfig())
+}
+
+func TestVerifyConnPoolConfig(t *testing.T) {
+	config := NewDefaultConfig()
+	assert.NoError(t, config.verifyConnPoolConfig())
+
+	config.TxPool.MinSize = 5
+	config.TxPool.GrowInterval = time.Second
+	config.TxPool.ShrinkInterval = time.Minute
+	config.TxPool.ResizeStep = 2
+	assert.NoError(t, config.verifyConnPoolConfig())
+
+	config.TxPool.ResizeStep = -1
+	assert.ErrorContains(t, config.verifyConnPoolConfig(), "txPool: minSize, resizeStep, growIntervalSeconds and shrinkIntervalSeconds should not be negative")
+
+	config.TxPool.ResizeStep = 2
+	config.OltpReadPool.MinSize = 17
+	assert.ErrorContains(t, config.verifyConnPoolConfig(), "oltpReadPool: minSize must not be greater than size (17 > 16)")
+}
+
+func TestParseDMLChunkSizes(t *testing.T) {
+	sizes, err := ParseDMLChunkSizes([]string{"events:1000", "logs:50"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"events": 1000, "logs": 50}, sizes)
+
+	_, err = ParseDMLChunkSizes([]string{"events"})
+	assert.ErrorContains(t, err, `invalid DML chunk size "events", expected table:rows`)
+	_, err = ParseDMLChunkSizes([]string{"events:0"})
+	assert.ErrorContains(t, err, `invalid rows in DML chunk size "events:0"`)
+
+	config := NewDefaultConfig()
+	config.DMLChunkSizeTables = []string{":10"}
+	assert.ErrorContains(t, config.Verify(), "--dml-chunk-size-tables: invalid DML chunk size")
+}
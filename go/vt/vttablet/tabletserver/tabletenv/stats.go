@@ -39,6 +39,7 @@ type Stats struct {
 	UserTableQueryTimesNs  *stats.CountersWithMultiLabels // Per CallerID/table latencies
 	UserTransactionCount   *stats.CountersWithMultiLabels // Per CallerID transaction counts
 	UserTransactionTimesNs *stats.CountersWithMultiLabels // Per CallerID transaction latencies
+	UserIdleTransactions   *stats.CountersWithMultiLabels // Per CallerID counts of the idle transactions warned about and rolled back
 	ResultHistogram        *stats.Histogram               // Row count histograms
 	TableaclAllowed        *stats.CountersWithMultiLabels // Number of allows
 	TableaclDenied         *stats.CountersWithMultiLabels // Number of denials
@@ -49,6 +50,13 @@ type Stats struct {
 	UserReservedTimesNs     *stats.CountersWithSingleLabel // Per CallerID reserved connection duration
 
 	QueryTimingsByTabletType *servenv.TimingsWrapper // Query timings split by current tablet type
+
+	UserPinnedQueryCount *stats.CountersWithSingleLabel // Per CallerID counts of the queries pinned to this tablet
+
+	ResourceGroupQueryCount *stats.CountersWithSingleLabel // Per MySQL resource group counts of the queries assigned by the query rules
+	HintRuleQueryCount      *stats.CountersWithMultiLabels // Per query rule counts of the queries executed with its hints, or which would have been in dry run mode
+
+	IdempotentWrites *stats.CountersWithSingleLabel // Writes carrying an idempotency token, executed or deduplicated
 }
 
 // NewStats instantiates a new set of stats scoped by exporter.
@@ -81,13 +89,14 @@ func NewStats(exporter *servenv.Exporter) *Stats {
 			vtrpcpb.Code_DATA_LOSS.String(),
 			vtrpcpb.Code_CLUSTER_EVENT.String(),
 		),
-		InternalErrors:         exporter.NewCountersWithSingleLabel("InternalErrors", "Internal component errors", "type", "Task", "StrayTransactions", "Panic", "HungQuery", "Schema", "TwopcCommit", "TwopcResurrection", "WatchdogFail", "Messages"),
+		InternalErrors:         exporter.NewCountersWithSingleLabel("InternalErrors", "Internal component errors", "type", "Task", "StrayTransactions", "Panic", "HungQuery", "Schema", "TwopcCommit", "TwopcResurrection", "WatchdogFail", "Messages", "TxLogLockWait", "IdempotencyTokenPurge"),
 		Warnings:               exporter.NewCountersWithSingleLabel("Warnings", "Warnings", "type", "ResultsExceeded"),
 		Unresolved:             exporter.NewGaugesWithSingleLabel("Unresolved", "Unresolved items", "item_type", "Prepares"),
 		UserTableQueryCount:    exporter.NewCountersWithMultiLabels("UserTableQueryCount", "Queries received for each CallerID/table combination", []string{"TableName", "CallerID", "Type"}),
 		UserTableQueryTimesNs:  exporter.NewCountersWithMultiLabels("UserTableQueryTimesNs", "Total latency for each CallerID/table combination", []string{"TableName", "CallerID", "Type"}),
 		UserTransactionCount:   exporter.NewCountersWithMultiLabels("UserTransactionCount", "transactions received for each CallerID", []string{"CallerID", "Conclusion"}),
 		UserTransactionTimesNs: exporter.NewCountersWithMultiLabels("UserTransactionTimesNs", "Total transaction latency for each CallerID", []string{"CallerID", "Conclusion"}),
+		UserIdleTransactions:   exporter.NewCountersWithMultiLabels("UserIdleTransactions", "Idle transactions warned about or rolled back for each CallerID", []string{"CallerID", "Action"}),
 		ResultHistogram:        exporter.NewHistogram("Results", "Distribution of rows returned", []int64{0, 1, 5, 10, 50, 100, 500, 1000, 5000, 10000}),
 		TableaclAllowed:        exporter.NewCountersWithMultiLabels("TableACLAllowed", "ACL acceptances", []string{"TableName", "TableGroup", "PlanID", "Username"}),
 		TableaclDenied:         exporter.NewCountersWithMultiLabels("TableACLDenied", "ACL denials", []string{"TableName", "TableGroup", "PlanID", "Username"}),
@@ -98,6 +107,13 @@ func NewStats(exporter *servenv.Exporter) *Stats {
 		UserReservedTimesNs:     exporter.NewCountersWithSingleLabel("UserReservedTimesNs", "Total reserved connection latency for each CallerID", "CallerID"),
 
 		QueryTimingsByTabletType: exporter.NewTimings("QueryTimingsByTabletType", "Query timings broken down by active tablet type", "TabletType"),
+
+		UserPinnedQueryCount: exporter.NewCountersWithSingleLabel("UserPinnedQueryCount", "Queries pinned to this tablet by vtgate for each CallerID", "CallerID"),
+
+		ResourceGroupQueryCount: exporter.NewCountersWithSingleLabel("ResourceGroupQueryCount", "Queries assigned to each MySQL resource group by the query rules", "ResourceGroup"),
+		HintRuleQueryCount:      exporter.NewCountersWithMultiLabels("HintRuleQueryCount", "Queries executed with the hints of each query rule, or which would have been in dry run mode", []string{"Rule", "Mode"}),
+
+		IdempotentWrites: exporter.NewCountersWithSingleLabel("IdempotentWrites", "Writes carrying an idempotency token, by whether they were executed or deduplicated", "Result", "Executed", "Deduplicated"),
 	}
 	stats.QPSRates = exporter.NewRates("QPS", stats.QueryTimings, 15*60/5, 5*time.Second)
 	return stats
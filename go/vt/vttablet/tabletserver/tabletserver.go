@@ -116,7 +116,9 @@ type TabletServer struct {
 	messager     *messager.Engine
 	hs           *healthStreamer
 	lagThrottler *throttle.Throttler
+	dmlChunker   *dmlChunker
 	tableGC      *gc.TableGC
+	faults       *faultInjector
 
 	// sm manages state transitions.
 	sm                *stateManager
@@ -128,6 +130,10 @@ type TabletServer struct {
 	// This field is only stored for testing
 	checkMysqlGaugeFunc *stats.GaugeFunc
 
+	// lastIdempotencyTokenPurge is the time, in Unix nanoseconds, at which the
+	// expired idempotency tokens were last purged.
+	lastIdempotencyTokenPurge atomic.Int64
+
 	env *vtenv.Environment
 }
 
@@ -176,6 +182,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.hs = newHealthStreamer(tsv, alias, tsv.se)
 	tsv.rt = repltracker.NewReplTracker(tsv, alias)
 	tsv.lagThrottler = throttle.NewThrottler(tsv, srvTopoServer, topoServer, alias.Cell, tsv.rt.HeartbeatWriter(), tabletTypeFunc)
+	tsv.dmlChunker = newDMLChunker(tsv, tsv.lagThrottler)
 	tsv.vstreamer = vstreamer.NewEngine(tsv, srvTopoServer, tsv.se, tsv.lagThrottler, alias.Cell)
 	tsv.tracker = schema.NewTracker(tsv, tsv.vstreamer, tsv.se)
 	tsv.watcher = NewBinlogWatcher(tsv, tsv.vstreamer, tsv.config)
@@ -185,7 +192,9 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.messager = messager.NewEngine(tsv, tsv.se, tsv.vstreamer)
 
 	tsv.tableGC = gc.NewTableGC(tsv, topoServer, tsv.lagThrottler)
-	tsv.onlineDDLExecutor = onlineddl.NewExecutor(tsv, alias, topoServer, tsv.lagThrottler, tabletTypeFunc, tsv.onlineDDLExecutorToggleTableBuffer, tsv.tableGC.RequestChecks)
+	tsv.onlineDDLExecutor = onlineddl.NewExecutor(tsv, alias, topoServer, tsv.lagThrottler, tabletTypeFunc, tsv.onlineDDLExecutorToggleTableBuffer, tsv.tableGC.RequestChecks, tsv.qe.RecentQueries)
+
+	tsv.faults = newFaultInjector(exporter, config.EnableFaultInjection)
 
 	tsv.sm = &stateManager{
 		statelessql: tsv.statelessql,
@@ -206,6 +215,9 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 		tableGC:     tsv.tableGC,
 		rw:          newRequestsWaiter(),
 	}
+	if tsv.faults.enabled {
+		tsv.sm.rt = faultReplTracker{replTracker: tsv.rt, faults: tsv.faults}
+	}
 
 	tsv.exporter.NewGaugeFunc("TabletState", "Tablet server state", func() int64 { return int64(tsv.sm.State()) })
 	tsv.checkMysqlGaugeFunc = tsv.exporter.NewGaugeFunc("CheckMySQLRunning", "Check MySQL operation currently in progress", tsv.sm.isCheckMySQLRunning)
@@ -228,6 +240,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
 	tsv.registerDebugEnvHandler()
+	tsv.registerFaultsHandler()
 
 	return tsv
 }
@@ -779,6 +792,33 @@ func (tsv *TabletServer) ReadTransaction(ctx context.Context, target *querypb.Ta
 	return metadata, err
 }
 
+// TransactionLockWaits returns the lock waits between the transactions of the tablet.
+func (tsv *TabletServer) TransactionLockWaits(ctx context.Context, target *querypb.Target) (lockWaits []*querypb.TransactionLockWait, err error) {
+	err = tsv.execRequest(
+		ctx, tsv.loadQueryTimeout(),
+		"TransactionLockWaits", "transaction_lock_waits", nil,
+		target, nil, true, /* allowOnShutdown */
+		func(ctx context.Context, logStats *tabletenv.LogStats) error {
+			lockWaits, err = tsv.transactionLockWaits(ctx)
+			return err
+		},
+	)
+	return lockWaits, err
+}
+
+// AbortTransaction kills the connection of the specified transaction, even if
+// it is executing a query, which rolls the transaction back.
+func (tsv *TabletServer) AbortTransaction(ctx context.Context, target *querypb.Target, transactionID int64, reason string) (err error) {
+	return tsv.execRequest(
+		ctx, tsv.loadQueryTimeout(),
+		"AbortTransaction", "abort_transaction", nil,
+		target, nil, true, /* allowOnShutdown */
+		func(ctx context.Context, logStats *tabletenv.LogStats) error {
+			return tsv.te.txPool.Abort(transactionID, reason)
+		},
+	)
+}
+
 // Execute executes the query and returns the result as response.
 func (tsv *TabletServer) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (result *sqltypes.Result, err error) {
 	span, ctx := trace.NewSpan(ctx, "TabletServer.Execute")
@@ -1219,6 +1259,34 @@ func (tsv *TabletServer) VStreamResults(ctx context.Context, target *querypb.Tar
 	return tsv.vstreamer.StreamResults(ctx, query, send)
 }
 
+// SaveVStreamPosition persists the position acknowledged by a VStream
+// consumer group, from which its streams resume.
+func (tsv *TabletServer) SaveVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	return tsv.execRequest(
+		ctx, tsv.loadQueryTimeout(),
+		"SaveVStreamPosition", "save_vstream_position", nil,
+		target, nil, false, /* allowOnShutdown */
+		func(ctx context.Context, logStats *tabletenv.LogStats) error {
+			return tsv.saveVStreamPosition(ctx, consumerGroup, shardGtid)
+		},
+	)
+}
+
+// ReadVStreamPosition returns the position acknowledged by a VStream
+// consumer group, or nil if it has none.
+func (tsv *TabletServer) ReadVStreamPosition(ctx context.Context, target *querypb.Target, consumerGroup string) (shardGtid *binlogdatapb.ShardGtid, err error) {
+	err = tsv.execRequest(
+		ctx, tsv.loadQueryTimeout(),
+		"ReadVStreamPosition", "read_vstream_position", nil,
+		target, nil, false, /* allowOnShutdown */
+		func(ctx context.Context, logStats *tabletenv.LogStats) error {
+			shardGtid, err = tsv.readVStreamPosition(ctx, consumerGroup)
+			return err
+		},
+	)
+	return shardGtid, err
+}
+
 // ReserveBeginExecute implements the QueryService interface
 func (tsv *TabletServer) ReserveBeginExecute(ctx context.Context, target *querypb.Target, preQueries []string, postBeginQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, options *querypb.ExecuteOptions) (state queryservice.ReservedTransactionState, result *sqltypes.Result, err error) {
 	if tsv.config.EnableSettingsPool {
@@ -1702,6 +1770,11 @@ func convertErrorCode(err error) vtrpcpb.Code {
 	case sqlerror.ERGotSignal, sqlerror.ERForcingClose, sqlerror.ERAbortingConnection, sqlerror.ERLockDeadlock:
 		// For ERLockDeadlock, a deadlock rolls back the transaction.
 		errCode = vtrpcpb.Code_ABORTED
+	case sqlerror.ERVersionConflict:
+		// An optimistic concurrency conflict, the row was changed by another transaction.
+		errCode = vtrpcpb.Code_ABORTED
+	case sqlerror.ERTxSizeExceeded:
+		errCode = vtrpcpb.Code_RESOURCE_EXHAUSTED
 	case sqlerror.ERUnknownComError, sqlerror.ERBadNullError, sqlerror.ERBadDb, sqlerror.ERBadTable, sqlerror.ERNonUniq, sqlerror.ERWrongFieldWithGroup, sqlerror.ERWrongGroupField,
 		sqlerror.ERWrongSumSelect, sqlerror.ERWrongValueCount, sqlerror.ERTooLongIdent, sqlerror.ERDupFieldName, sqlerror.ERDupKeyName, sqlerror.ERWrongFieldSpec, sqlerror.ERParseError,
 		sqlerror.EREmptyQuery, sqlerror.ERNonUniqTable, sqlerror.ERInvalidDefault, sqlerror.ERMultiplePriKey, sqlerror.ERTooManyKeys, sqlerror.ERTooManyKeyParts, sqlerror.ERTooLongKey,
@@ -1744,6 +1817,18 @@ func (tsv *TabletServer) BroadcastHealth() {
 	tsv.sm.Broadcast()
 }
 
+// SetSemiSyncDivergence sets the divergence of the semi-sync settings
+// reported in the health stream.
+func (tsv *TabletServer) SetSemiSyncDivergence(divergence string) {
+	tsv.hs.SetSemiSyncDivergence(divergence)
+}
+
+// SetWritesFrozen sets whether the writes to the shard are frozen, which is
+// reported in the health stream.
+func (tsv *TabletServer) SetWritesFrozen(frozen bool) {
+	tsv.hs.SetWritesFrozen(frozen)
+}
+
 // EnterLameduck causes tabletserver to enter the lameduck state. This
 // state causes health checks to fail, but the behavior of tabletserver
 // otherwise remains the same. Any subsequent calls to SetServingType will
@@ -1881,23 +1966,29 @@ func (tsv *TabletServer) registerMigrationStatusHandler() {
 	})
 }
 
+// throttlerCheckRequest returns the app, remote address and flags of a throttler "check" request
+func throttlerCheckRequest(r *http.Request) (appName string, remoteAddr string, flags *throttle.CheckFlags) {
+	remoteAddr = r.Header.Get("X-Forwarded-For")
+	if remoteAddr == "" {
+		remoteAddr = r.RemoteAddr
+		remoteAddr = strings.Split(remoteAddr, ":")[0]
+	}
+	appName = r.URL.Query().Get("app")
+	if appName == "" {
+		appName = throttlerapp.DefaultName.String()
+	}
+	flags = &throttle.CheckFlags{
+		SkipRequestHeartbeats: (r.URL.Query().Get("s") == "true"),
+	}
+	return appName, remoteAddr, flags
+}
+
 // registerThrottlerCheckHandlers registers throttler "check" requests
 func (tsv *TabletServer) registerThrottlerCheckHandlers() {
 	handle := func(path string, checkType throttle.ThrottleCheckType) {
 		tsv.exporter.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
 			ctx := tabletenv.LocalContext()
-			remoteAddr := r.Header.Get("X-Forwarded-For")
-			if remoteAddr == "" {
-				remoteAddr = r.RemoteAddr
-				remoteAddr = strings.Split(remoteAddr, ":")[0]
-			}
-			appName := r.URL.Query().Get("app")
-			if appName == "" {
-				appName = throttlerapp.DefaultName.String()
-			}
-			flags := &throttle.CheckFlags{
-				SkipRequestHeartbeats: (r.URL.Query().Get("s") == "true"),
-			}
+			appName, remoteAddr, flags := throttlerCheckRequest(r)
 			checkResult := tsv.lagThrottler.CheckByType(ctx, appName, remoteAddr, flags, checkType)
 			if checkResult.StatusCode == http.StatusNotFound && flags.OKIfNotExists {
 				checkResult.StatusCode = http.StatusOK // 200
@@ -1914,6 +2005,28 @@ func (tsv *TabletServer) registerThrottlerCheckHandlers() {
 	}
 	handle("/throttler/check", throttle.ThrottleCheckPrimaryWrite)
 	handle("/throttler/check-self", throttle.ThrottleCheckSelf)
+
+	handleLease := func(path string, checkType throttle.ThrottleCheckType) {
+		tsv.exporter.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			ctx := tabletenv.LocalContext()
+			appName, remoteAddr, flags := throttlerCheckRequest(r)
+			var tokens int64
+			if tokensParam := r.URL.Query().Get("tokens"); tokensParam != "" {
+				var err error
+				if tokens, err = strconv.ParseInt(tokensParam, 10, 64); err != nil {
+					http.Error(w, fmt.Sprintf("not ok: %v", err), http.StatusBadRequest)
+					return
+				}
+			}
+			lease := tsv.lagThrottler.CheckLease(ctx, appName, remoteAddr, flags, checkType, tokens)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(lease.StatusCode)
+			json.NewEncoder(w).Encode(lease)
+		})
+	}
+	handleLease("/throttler/check-lease", throttle.ThrottleCheckPrimaryWrite)
+	handleLease("/throttler/check-self-lease", throttle.ThrottleCheckSelf)
 }
 
 // registerThrottlerStatusHandler registers a throttler "status" request
@@ -1976,6 +2089,12 @@ func (tsv *TabletServer) registerDebugEnvHandler() {
 	})
 }
 
+func (tsv *TabletServer) registerFaultsHandler() {
+	if tsv.faults.enabled {
+		tsv.exporter.HandleFunc("/debug/faults", tsv.faults.ServeHTTP)
+	}
+}
+
 // EnableHeartbeat forces heartbeat to be on or off.
 // Only to be used for testing.
 func (tsv *TabletServer) EnableHeartbeat(enabled bool) {
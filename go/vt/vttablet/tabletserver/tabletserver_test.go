@@ -430,6 +430,80 @@ func TestTabletServerReadTransaction(t *testing.T) {
 	utils.MustMatch(t, want, got, "ReadTransaction")
 }
 
+func TestTabletServerTransactionLockWaits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+
+	mysqlConnID := func(transactionID int64) int64 {
+		conn, err := tsv.te.txPool.GetAndLock(transactionID, "test")
+		require.NoError(t, err)
+		defer conn.Unlock()
+		return conn.ID()
+	}
+	state1, err := tsv.Begin(ctx, &target, &querypb.ExecuteOptions{SessionUuid: "session1"})
+	require.NoError(t, err)
+	state2, err := tsv.Begin(ctx, &target, &querypb.ExecuteOptions{SessionUuid: "session2"})
+	require.NoError(t, err)
+
+	db.AddQuery(sqlTransactionLockWaits, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("waiting_thread_id|blocking_thread_id|wait_seconds", "int64|int64|int64"),
+		fmt.Sprintf("%d|%d|3", mysqlConnID(state1.TransactionID), mysqlConnID(state2.TransactionID)),
+		// The lock waits of the connections which are not in a
+		// transaction of the pool are ignored.
+		fmt.Sprintf("%d|12345|1", mysqlConnID(state2.TransactionID)),
+	))
+	lockWaits, err := tsv.TransactionLockWaits(ctx, &target)
+	require.NoError(t, err)
+	require.Len(t, lockWaits, 1)
+	assert.Equal(t, state1.TransactionID, lockWaits[0].WaitingTransactionId)
+	assert.Equal(t, "session1", lockWaits[0].WaitingSessionUuid)
+	assert.Equal(t, state2.TransactionID, lockWaits[0].BlockingTransactionId)
+	assert.Equal(t, "session2", lockWaits[0].BlockingSessionUuid)
+	assert.LessOrEqual(t, lockWaits[0].WaitingStartTime, lockWaits[0].BlockingStartTime)
+	assert.EqualValues(t, 3, lockWaits[0].WaitSeconds)
+}
+
+func TestTabletServerAbortTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	startingKills := tsv.stats.KillCounters.Counts()["Transactions"]
+
+	// A transaction which is not executing a query is rolled back.
+	state, err := tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	err = tsv.AbortTransaction(ctx, &target, state.TransactionID, "test")
+	require.NoError(t, err)
+	_, err = tsv.Commit(ctx, &target, state.TransactionID)
+	require.ErrorContains(t, err, "aborted: test")
+
+	// The connection of a transaction executing a query is killed.
+	state, err = tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	conn, err := tsv.te.txPool.GetAndLock(state.TransactionID, "for query")
+	require.NoError(t, err)
+	db.AddQuery(fmt.Sprintf("kill %d", conn.ID()), &sqltypes.Result{})
+	err = tsv.AbortTransaction(ctx, &target, state.TransactionID, "test")
+	require.NoError(t, err)
+	assert.True(t, conn.IsClosed())
+	conn.Unlock()
+	_, err = tsv.Commit(ctx, &target, state.TransactionID)
+	require.ErrorContains(t, err, "unlocked closed connection")
+
+	assert.EqualValues(t, 2, tsv.stats.KillCounters.Counts()["Transactions"]-startingKills)
+	tsv.stats.KillCounters.ResetAll()
+
+	err = tsv.AbortTransaction(ctx, &target, 12345, "test")
+	require.ErrorContains(t, err, "not found")
+}
+
 func TestTabletServerConcludeTransaction(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -488,6 +562,35 @@ func TestTabletServerCommitTransaction(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTabletServerIdleTransactionWarning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.IdleTransaction.WarningThreshold = 10 * time.Millisecond
+	db, tsv := setupTabletServerTestCustom(t, ctx, cfg, "", vtenv.NewTestEnv())
+	defer tsv.StopService()
+	defer db.Close()
+
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{})
+
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	state, err := tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	qr, err := tsv.Execute(ctx, &target, executeSQL, nil, state.TransactionID, 0, nil)
+	require.NoError(t, err)
+	assert.Empty(t, qr.Warnings)
+
+	time.Sleep(20 * time.Millisecond)
+	qr, err = tsv.Execute(ctx, &target, executeSQL, nil, state.TransactionID, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Warnings, 1)
+	assert.EqualValues(t, sqlerror.ERIdleTransaction, qr.Warnings[0].Code)
+	assert.Contains(t, qr.Warnings[0].Message, "longer than 10ms")
+	_, err = tsv.Commit(ctx, &target, state.TransactionID)
+	require.NoError(t, err)
+}
+
 func TestTabletServerCommiRollbacktFail(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"time"
+)
+
+// CheckLease is the result of a throttler check, which an app may reuse until the lease expires: an OK lease
+// grants the app Tokens writes, a lease which is not OK tells the app to hold its writes until it expires.
+// It exports as JSON via the API.
+type CheckLease struct {
+	StatusCode  int     `json:"StatusCode"`
+	Value       float64 `json:"Value"`
+	Threshold   float64 `json:"Threshold"`
+	Message     string  `json:"Message"`
+	Tokens      int64   `json:"Tokens"`
+	LeaseMillis int64   `json:"LeaseMillis"`
+}
+
+// LeaseDuration returns how long the lease is valid since it was requested.
+func (lease *CheckLease) LeaseDuration() time.Duration {
+	return time.Duration(lease.LeaseMillis) * time.Millisecond
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+const (
+	// checkLeasesPruneSize is the number of leases above which the expired ones are pruned.
+	checkLeasesPruneSize = 100
+)
+
+var (
+	// flag vars
+	throttleCheckLeaseDuration  = time.Second
+	throttleCheckLeaseMaxTokens = int64(1000)
+)
+
+var (
+	statsThrottlerCheckLeases       = stats.NewCounter("ThrottlerCheckLeases", "number of check leases requested")
+	statsThrottlerCheckLeasesShared = stats.NewCounter("ThrottlerCheckLeasesShared", "number of check leases served from the result of an earlier check")
+)
+
+// checkLease is the result of a check, shared by the leases of an app until it expires.
+type checkLease struct {
+	// ready is closed once the check is done.
+	ready     chan struct{}
+	result    *CheckResult
+	expiresAt time.Time
+}
+
+// checkLeases runs the checks of the leases. The lease requests of an app and check type made while
+// a check is in progress, or while its result is valid, share that result instead of checking again.
+type checkLeases struct {
+	mu     sync.Mutex
+	leases map[string]*checkLease
+}
+
+func newCheckLeases() *checkLeases {
+	return &checkLeases{
+		leases: make(map[string]*checkLease),
+	}
+}
+
+// get returns the result of the check of the key, running check when there is no valid result to share,
+// and the time left until that result expires.
+func (l *checkLeases) get(ctx context.Context, key string, duration time.Duration, check func() *CheckResult) (*CheckResult, time.Duration) {
+	l.mu.Lock()
+	now := time.Now()
+	lease, ok := l.leases[key]
+	if ok && !isExpired(lease, now) {
+		l.mu.Unlock()
+		statsThrottlerCheckLeasesShared.Add(1)
+		select {
+		case <-lease.ready:
+		case <-ctx.Done():
+			return NewErrorCheckResult(http.StatusInternalServerError, ctx.Err()), 0
+		}
+		return lease.result, time.Until(lease.expiresAt)
+	}
+	if len(l.leases) >= checkLeasesPruneSize {
+		for k, lease := range l.leases {
+			if isExpired(lease, now) {
+				delete(l.leases, k)
+			}
+		}
+	}
+	lease = &checkLease{ready: make(chan struct{})}
+	l.leases[key] = lease
+	l.mu.Unlock()
+
+	lease.result = check()
+	// The lease starts once the check is done, so that the apps which waited for it get the full duration.
+	lease.expiresAt = time.Now().Add(duration)
+	close(lease.ready)
+	return lease.result, duration
+}
+
+// isExpired returns true when the check of the lease is done and its result expired. Must be called
+// with the mutex held.
+func isExpired(lease *checkLease, now time.Time) bool {
+	select {
+	case <-lease.ready:
+		return !now.Before(lease.expiresAt)
+	default:
+		return false
+	}
+}
+
+// CheckLease runs a check by requested check type, and leases its result to the app: the lease requests of the
+// app made while the lease is valid share that result. An OK lease grants the app the requested number of tokens,
+// up to --throttle-check-lease-max-tokens, or that maximum when no tokens are requested.
+func (throttler *Throttler) CheckLease(ctx context.Context, appName string, remoteAddr string, flags *CheckFlags, checkType ThrottleCheckType, tokens int64) *base.CheckLease {
+	statsThrottlerCheckLeases.Add(1)
+	key := fmt.Sprintf("%d:%s", checkType, appName)
+	checkResult, leaseDuration := throttler.checkLeases.get(ctx, key, throttleCheckLeaseDuration, func() *CheckResult {
+		return throttler.CheckByType(ctx, appName, remoteAddr, flags, checkType)
+	})
+	if leaseDuration < 0 {
+		leaseDuration = 0
+	}
+	lease := &base.CheckLease{
+		StatusCode:  checkResult.StatusCode,
+		Value:       checkResult.Value,
+		Threshold:   checkResult.Threshold,
+		Message:     checkResult.Message,
+		LeaseMillis: leaseDuration.Milliseconds(),
+	}
+	if lease.StatusCode == http.StatusNotFound && flags.OKIfNotExists {
+		lease.StatusCode = http.StatusOK
+	}
+	if lease.StatusCode == http.StatusOK {
+		lease.Tokens = throttleCheckLeaseMaxTokens
+		if tokens > 0 && tokens < lease.Tokens {
+			lease.Tokens = tokens
+		}
+	}
+	return lease
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLeasesGet(t *testing.T) {
+	ctx := context.Background()
+	leases := newCheckLeases()
+	var checks atomic.Int64
+	release := make(chan struct{})
+	check := func() *CheckResult {
+		checks.Add(1)
+		<-release
+		return NewCheckResult(http.StatusTooManyRequests, 7, 5, nil)
+	}
+
+	// The requests made while the check is in progress wait for it.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, leaseDuration := leases.get(ctx, "app", time.Hour, check)
+			assert.Equal(t, http.StatusTooManyRequests, result.StatusCode)
+			assert.Greater(t, leaseDuration, time.Minute)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.EqualValues(t, 1, checks.Load())
+
+	// Other keys are checked on their own, and expired results are checked again.
+	_, leaseDuration := leases.get(ctx, "other", 0, check)
+	require.Zero(t, leaseDuration)
+	require.EqualValues(t, 2, checks.Load())
+	leases.get(ctx, "other", 0, check)
+	require.EqualValues(t, 3, checks.Load())
+	leases.get(ctx, "app", time.Hour, check)
+	require.EqualValues(t, 3, checks.Load())
+}
+
+func TestCheckLease(t *testing.T) {
+	throttler := newTestThrottler()
+	oldMaxTokens := throttleCheckLeaseMaxTokens
+	defer func() { throttleCheckLeaseMaxTokens = oldMaxTokens }()
+	throttleCheckLeaseMaxTokens = 100
+
+	lease := throttler.CheckLease(context.Background(), "app", "", &CheckFlags{}, ThrottleCheckPrimaryWrite, 10)
+	assert.Equal(t, http.StatusOK, lease.StatusCode)
+	assert.EqualValues(t, 10, lease.Tokens)
+	assert.Positive(t, lease.LeaseMillis)
+
+	lease = throttler.CheckLease(context.Background(), "app", "", &CheckFlags{}, ThrottleCheckPrimaryWrite, 0)
+	assert.EqualValues(t, 100, lease.Tokens)
+	lease = throttler.CheckLease(context.Background(), "app", "", &CheckFlags{}, ThrottleCheckPrimaryWrite, 1000)
+	assert.EqualValues(t, 100, lease.Tokens)
+}
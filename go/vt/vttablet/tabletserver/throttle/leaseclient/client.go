@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package leaseclient is a client of the throttler check leases of the tablets, for the apps which make many small
+// writes. Instead of checking the throttler before each write, the client leases the result of a check from the
+// primary tablet of each shard, and spends the tokens of an OK lease on the writes until it expires.
+package leaseclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+// DefaultHTTPTimeout is the timeout of the lease requests of a client with no HTTP client of its own.
+const DefaultHTTPTimeout = time.Second
+
+// Config is the configuration of a Client.
+type Config struct {
+	// AppName is the name the app checks the throttler with.
+	AppName string
+	// Tokens is the number of writes the client asks each lease for. The tablet grants at most its
+	// --throttle-check-lease-max-tokens, which is also what it grants when Tokens is 0.
+	Tokens int64
+	// CheckSelf leases the check of the tablet itself rather than the one of its shard.
+	CheckSelf bool
+	// HTTPClient runs the lease requests. A client with a DefaultHTTPTimeout timeout is used when nil.
+	HTTPClient *http.Client
+}
+
+// Client leases throttler checks for an app, one lease per tablet. It is safe for concurrent use: the
+// goroutines which need a lease while it is renewed wait for that renewal instead of requesting their own.
+type Client struct {
+	config Config
+
+	mu     sync.Mutex
+	leases map[string]*lease
+}
+
+// lease is the lease of a tablet.
+type lease struct {
+	mu        sync.Mutex
+	ok        bool
+	tokens    int64
+	expiresAt time.Time
+	// renewing is closed once the renewal in progress is done, and is nil when the lease is not renewed.
+	renewing chan struct{}
+}
+
+// NewClient creates a Client.
+func NewClient(config Config) *Client {
+	if config.HTTPClient == nil {
+		config.HTTPClient = base.SetupHTTPClient(DefaultHTTPTimeout)
+	}
+	return &Client{
+		config: config,
+		leases: make(map[string]*lease),
+	}
+}
+
+// Acquire takes a token of the lease of the tablet, whose HTTP address is tabletAddr, e.g. "host:15100", renewing
+// the lease when it expired or ran out of tokens. It returns false, without waiting, when the throttler asks the app
+// to hold its writes.
+func (c *Client) Acquire(ctx context.Context, tabletAddr string) (bool, error) {
+	l := c.lease(tabletAddr)
+	for {
+		l.mu.Lock()
+		if renewing := l.renewing; renewing != nil {
+			l.mu.Unlock()
+			select {
+			case <-renewing:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+			continue
+		}
+		if time.Now().Before(l.expiresAt) && (!l.ok || l.tokens > 0) {
+			ok := l.take()
+			l.mu.Unlock()
+			return ok, nil
+		}
+		renewing := make(chan struct{})
+		l.renewing = renewing
+		l.mu.Unlock()
+
+		checkLease, err := c.requestLease(ctx, tabletAddr)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.renewing = nil
+		close(renewing)
+		if err != nil {
+			// The goroutines which waited for this renewal retry it.
+			return false, err
+		}
+		l.ok = checkLease.StatusCode == http.StatusOK
+		l.tokens = checkLease.Tokens
+		l.expiresAt = time.Now().Add(checkLease.LeaseDuration())
+		if l.ok && l.tokens == 0 {
+			// The lease has no token to spare, but this write is the one it was checked for.
+			return true, nil
+		}
+		return l.take(), nil
+	}
+}
+
+// take takes a token of a valid lease. Must be called with the mutex held.
+func (l *lease) take() bool {
+	if !l.ok {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until it takes a token of the lease of the tablet, or until ctx is done. While the throttler asks the
+// app to hold its writes, it sleeps until the lease expires before asking again.
+func (c *Client) Wait(ctx context.Context, tabletAddr string) error {
+	for {
+		ok, err := c.Acquire(ctx, tabletAddr)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		l := c.lease(tabletAddr)
+		l.mu.Lock()
+		wait := time.Until(l.expiresAt)
+		l.mu.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) lease(tabletAddr string) *lease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.leases[tabletAddr]
+	if !ok {
+		l = &lease{}
+		c.leases[tabletAddr] = l
+	}
+	return l
+}
+
+// requestLease requests a lease from the tablet.
+func (c *Client) requestLease(ctx context.Context, tabletAddr string) (*base.CheckLease, error) {
+	path := "throttler/check-lease"
+	if c.config.CheckSelf {
+		path = "throttler/check-self-lease"
+	}
+	query := url.Values{}
+	query.Set("app", c.config.AppName)
+	if c.config.Tokens > 0 {
+		query.Set("tokens", fmt.Sprintf("%d", c.config.Tokens))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/%s?%s", tabletAddr, path, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	checkLease := &base.CheckLease{}
+	if err := json.NewDecoder(resp.Body).Decode(checkLease); err != nil {
+		return nil, fmt.Errorf("invalid check lease from %s: %v", tabletAddr, err)
+	}
+	return checkLease, nil
+}
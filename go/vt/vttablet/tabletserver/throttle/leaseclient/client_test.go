@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaseclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+func newLeaseServer(t *testing.T, statusCode *atomic.Int64, leaseDuration time.Duration) (addr string, requests *atomic.Int64) {
+	requests = &atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/throttler/check-lease", r.URL.Path)
+		assert.Equal(t, "ingest", r.URL.Query().Get("app"))
+		assert.Equal(t, "10", r.URL.Query().Get("tokens"))
+		lease := &base.CheckLease{
+			StatusCode:  int(statusCode.Load()),
+			LeaseMillis: leaseDuration.Milliseconds(),
+		}
+		if lease.StatusCode == http.StatusOK {
+			lease.Tokens = 10
+		}
+		w.WriteHeader(lease.StatusCode)
+		json.NewEncoder(w).Encode(lease)
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://"), requests
+}
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	statusCode := &atomic.Int64{}
+	statusCode.Store(http.StatusOK)
+	addr, requests := newLeaseServer(t, statusCode, time.Hour)
+	client := NewClient(Config{AppName: "ingest", Tokens: 10})
+
+	// The writes spend the tokens of a lease, and renew it once it runs out of them.
+	for i := 0; i < 25; i++ {
+		ok, err := client.Acquire(ctx, addr)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.EqualValues(t, 3, requests.Load())
+
+	// Concurrent writes share the renewals.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := client.Acquire(ctx, addr)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 8, requests.Load())
+
+	// A lease which is not OK holds the writes until it expires.
+	statusCode.Store(http.StatusTooManyRequests)
+	for i := 0; i < 6; i++ {
+		_, err := client.Acquire(ctx, addr)
+		require.NoError(t, err)
+	}
+	ok, err := client.Acquire(ctx, addr)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.EqualValues(t, 9, requests.Load())
+}
+
+func TestWait(t *testing.T) {
+	statusCode := &atomic.Int64{}
+	statusCode.Store(http.StatusTooManyRequests)
+	addr, requests := newLeaseServer(t, statusCode, 50*time.Millisecond)
+	client := NewClient(Config{AppName: "ingest", Tokens: 10})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, client.Wait(ctx, addr), context.DeadlineExceeded)
+	// The client checks once per lease.
+	require.LessOrEqual(t, requests.Load(), int64(3))
+
+	statusCode.Store(http.StatusOK)
+	require.NoError(t, client.Wait(context.Background(), addr))
+}
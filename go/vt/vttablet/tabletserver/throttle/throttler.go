@@ -122,6 +122,8 @@ func init() {
 
 func registerThrottlerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&throttleTabletTypes, "throttle_tablet_types", throttleTabletTypes, "Comma separated VTTablet types to be considered by the throttler. default: 'replica'. example: 'replica,rdonly'. 'replica' always implicitly included")
+	fs.DurationVar(&throttleCheckLeaseDuration, "throttle-check-lease-duration", throttleCheckLeaseDuration, "How long the result of a throttler check lease is valid. The lease requests of an app made meanwhile share that result instead of checking the throttler again.")
+	fs.Int64Var(&throttleCheckLeaseMaxTokens, "throttle-check-lease-max-tokens", throttleCheckLeaseMaxTokens, "Maximum number of writes an OK throttler check lease grants to an app.")
 }
 
 var (
@@ -198,6 +200,8 @@ type Throttler struct {
 	recentApps             *cache.Cache
 	metricsHealth          *cache.Cache
 
+	checkLeases *checkLeases
+
 	initMutex           sync.Mutex
 	enableMutex         sync.Mutex
 	cancelOpenContext   context.CancelFunc
@@ -253,6 +257,7 @@ func NewThrottler(env tabletenv.Env, srvTopoServer srvtopo.Server, ts *topo.Serv
 	throttler.aggregatedMetrics = cache.New(aggregatedMetricsExpiration, 0)
 	throttler.recentApps = cache.New(recentAppsExpiration, 0)
 	throttler.metricsHealth = cache.New(cache.NoExpiration, 0)
+	throttler.checkLeases = newCheckLeases()
 
 	throttler.httpClient = base.SetupHTTPClient(2 * mysqlCollectInterval)
 	throttler.initThrottleTabletTypes()
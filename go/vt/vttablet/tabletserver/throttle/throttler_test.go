@@ -157,6 +157,7 @@ func newTestThrottler() *Throttler {
 	throttler.aggregatedMetrics = cache.New(10*aggregatedMetricsExpiration, 0)
 	throttler.recentApps = cache.New(recentAppsExpiration, 0)
 	throttler.metricsHealth = cache.New(cache.NoExpiration, 0)
+	throttler.checkLeases = newCheckLeases()
 	throttler.metricsQuery.Store(metricsQuery)
 	throttler.initThrottleTabletTypes()
 	throttler.check = NewThrottlerCheck(throttler)
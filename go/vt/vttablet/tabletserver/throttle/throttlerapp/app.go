@@ -51,6 +51,8 @@ const (
 	GhostName     Name = "gh-ost"
 	PTOSCName     Name = "pt-osc"
 
+	ChunkedDMLName Name = "chunked-dml"
+
 	VReplicationName      Name = "vreplication"
 	VStreamerName         Name = "vstreamer"
 	VPlayerName           Name = "vplayer"
@@ -17,10 +17,12 @@ limitations under the License.
 package tx
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"vitess.io/vitess/go/vt/callerid"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
@@ -54,12 +56,47 @@ type (
 		StartTime       time.Time
 		EndTime         time.Time
 		Queries         []string
+		Statements      []*Statement
 		Autocommit      bool
 		Conclusion      string
 		LogToFile       bool
+		// SessionUUID is the UUID of the vtgate session of the transaction,
+		// set when vtgate detects the cross-shard deadlocks.
+		SessionUUID string
+		// Workload is the workload of the session which began the
+		// transaction.
+		Workload querypb.ExecuteOptions_Workload
+		// Modified is what the transaction modified, by table, accounted
+		// for the transaction size limits.
+		Modified map[string]*Modification
+		// SizeLimitError is the error of the statement which exceeded the
+		// transaction size limits, which fails the commit.
+		SizeLimitError error
 
 		Stats *servenv.TimingsWrapper
 	}
+
+	// Modification is the number of rows a transaction modified in a table,
+	// and the estimated number of binlog bytes it generated for them.
+	Modification struct {
+		Rows  uint64
+		Bytes uint64
+		// LimitExceeded is set once the modification exceeded the
+		// transaction size limits of the table.
+		LimitExceeded bool
+	}
+
+	// Statement contains the timings of a statement of the transaction. The
+	// statements recorded without timings, e.g. the statements of a prepared
+	// transaction which is restored, only have their query set.
+	Statement struct {
+		Query        string
+		Duration     time.Duration
+		RowsAffected uint64
+		// LockWait is the time the statement waited for locks, as reported by
+		// performance_schema when --txlog-lock-wait is set.
+		LockWait time.Duration
+	}
 )
 
 const (
@@ -120,6 +157,21 @@ func (p *Properties) RecordQuery(query string) {
 		return
 	}
 	p.Queries = append(p.Queries, query)
+	p.Statements = append(p.Statements, &Statement{Query: query})
+}
+
+// RecordStatement records the query against this transaction, with the
+// time it took to execute and the number of rows it affected.
+func (p *Properties) RecordStatement(query string, duration time.Duration, rowsAffected uint64) {
+	if p == nil {
+		return
+	}
+	p.Queries = append(p.Queries, query)
+	p.Statements = append(p.Statements, &Statement{
+		Query:        query,
+		Duration:     duration,
+		RowsAffected: rowsAffected,
+	})
 }
 
 // InTransaction returns true as soon as this struct is not nil
@@ -154,3 +206,58 @@ func (p *Properties) String(sanitize bool, parser *sqlparser.Parser) string {
 		printQueries(),
 	)
 }
+
+// logTimeFormat is the format of the times of the JSON transaction log, which
+// is the one of the query log.
+const logTimeFormat = "2006-01-02 15:04:05.000000"
+
+type (
+	txLogRecord struct {
+		TransactionID   ConnID
+		EffectiveCaller string
+		ImmediateCaller string
+		Start           string
+		End             string
+		Duration        float64
+		Conclusion      string
+		Statements      []txLogStatement
+	}
+
+	txLogStatement struct {
+		Query        string
+		Duration     float64
+		RowsAffected uint64
+		LockWait     float64
+	}
+)
+
+// JSON returns a JSON version of the transaction for the transaction log,
+// which includes the timings of its statements. The durations are in seconds.
+func (p *Properties) JSON(connID ConnID, sanitize bool, parser *sqlparser.Parser) ([]byte, error) {
+	if p == nil {
+		return nil, nil
+	}
+	record := txLogRecord{
+		TransactionID:   connID,
+		EffectiveCaller: callerid.GetPrincipal(p.EffectiveCaller),
+		ImmediateCaller: callerid.GetUsername(p.ImmediateCaller),
+		Start:           p.StartTime.Format(logTimeFormat),
+		End:             p.EndTime.Format(logTimeFormat),
+		Duration:        p.EndTime.Sub(p.StartTime).Seconds(),
+		Conclusion:      p.Conclusion,
+		Statements:      make([]txLogStatement, 0, len(p.Statements)),
+	}
+	for _, stmt := range p.Statements {
+		query := stmt.Query
+		if sanitize {
+			query, _ = parser.RedactSQLQuery(query)
+		}
+		record.Statements = append(record.Statements, txLogStatement{
+			Query:        query,
+			Duration:     stmt.Duration.Seconds(),
+			RowsAffected: stmt.RowsAffected,
+			LockWait:     stmt.LockWait.Seconds(),
+		})
+	}
+	return json.Marshal(record)
+}
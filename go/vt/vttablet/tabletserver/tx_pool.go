@@ -18,11 +18,14 @@ package tabletserver
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
@@ -43,6 +46,13 @@ const (
 	txLogInterval  = 1 * time.Minute
 	beginWithCSRO  = "start transaction with consistent snapshot, read only"
 	trackGtidQuery = "set session session_track_gtids = START_GTID"
+
+	// lockWaitQuery reads the SQL text and the lock time of the latest
+	// statements executed on the connection.
+	lockWaitQuery = "select sql_text, lock_time from performance_schema.events_statements_history " +
+		"where thread_id = (select thread_id from performance_schema.threads where processlist_id = connection_id()) " +
+		"order by event_id desc limit 1024"
+	maxLockWaitStatements = 1024
 )
 
 var txIsolations = map[querypb.ExecuteOptions_TransactionIsolation]string{
@@ -69,6 +79,8 @@ type (
 		scp     *StatefulConnectionPool
 		ticks   *timer.Timer
 		limiter txlimiter.TxLimiter
+		// sizeGuard limits the size of the transactions.
+		sizeGuard *txSizeGuard
 
 		logMu   sync.Mutex
 		lastLog time.Time
@@ -80,11 +92,12 @@ type (
 func NewTxPool(env tabletenv.Env, limiter txlimiter.TxLimiter) *TxPool {
 	config := env.Config()
 	axp := &TxPool{
-		env:     env,
-		scp:     NewStatefulConnPool(env),
-		ticks:   timer.NewTimer(txKillerTimeoutInterval(config)),
-		limiter: limiter,
-		txStats: env.Exporter().NewTimings("Transactions", "Transaction stats", "operation"),
+		env:       env,
+		scp:       NewStatefulConnPool(env),
+		ticks:     timer.NewTimer(txKillerTimeoutInterval(config)),
+		limiter:   limiter,
+		sizeGuard: newTxSizeGuard(env),
+		txStats:   env.Exporter().NewTimings("Transactions", "Transaction stats", "operation"),
 	}
 	// Careful: conns also exports name+"xxx" vars,
 	// but we know it doesn't export Timeout.
@@ -131,26 +144,75 @@ func (tp *TxPool) transactionKiller() {
 	defer tp.env.LogError()
 	for _, conn := range tp.scp.GetElapsedTimeout(vterrors.TxKillerRollback) {
 		log.Warningf("killing transaction (exceeded timeout: %v): %s", conn.timeout, conn.String(tp.env.Config().SanitizeLogMessages, tp.env.Environment().Parser()))
-		switch {
-		case conn.IsTainted():
+		tp.kill(conn)
+		conn.Releasef("exceeded timeout: %v", conn.timeout)
+	}
+	idleTimeout := tp.env.Config().IdleTransaction.Timeout
+	if idleTimeout <= 0 {
+		return
+	}
+	for _, conn := range tp.scp.GetIdleTransactions(idleTimeout, vterrors.TxKillerRollback) {
+		log.Warningf("killing transaction (idle for more than %v): %s", idleTimeout, conn.String(tp.env.Config().SanitizeLogMessages, tp.env.Environment().Parser()))
+		tp.env.Stats().UserIdleTransactions.Add([]string{conn.txUsername(), "RolledBack"}, 1)
+		tp.kill(conn)
+		conn.Releasef("idle for more than %v", idleTimeout)
+	}
+}
+
+// kill rolls back the transaction of a connection found by the transaction
+// killer, or closes the connection if it is reserved. The caller releases it.
+func (tp *TxPool) kill(conn *StatefulConnection) {
+	switch {
+	case conn.IsTainted():
+		conn.Close()
+		tp.env.Stats().KillCounters.Add("ReservedConnection", 1)
+	case conn.IsInTransaction():
+		_, err := conn.Exec(context.Background(), "rollback", 1, false)
+		if err != nil {
 			conn.Close()
-			tp.env.Stats().KillCounters.Add("ReservedConnection", 1)
-		case conn.IsInTransaction():
-			_, err := conn.Exec(context.Background(), "rollback", 1, false)
-			if err != nil {
-				conn.Close()
-			}
-			tp.env.Stats().KillCounters.Add("Transactions", 1)
 		}
-		// For logging, as transaction is killed as the connection is closed.
-		if conn.IsTainted() && conn.IsInTransaction() {
-			tp.env.Stats().KillCounters.Add("Transactions", 1)
+		tp.env.Stats().KillCounters.Add("Transactions", 1)
+	}
+	// For logging, as transaction is killed as the connection is closed.
+	if conn.IsTainted() && conn.IsInTransaction() {
+		tp.env.Stats().KillCounters.Add("Transactions", 1)
+	}
+	if conn.IsInTransaction() {
+		tp.txComplete(conn, tx.TxKill)
+	}
+}
+
+// Abort kills the transaction of the specified connection. If the connection
+// is not in use, the transaction is rolled back and the connection released,
+// like the transaction killer does. Otherwise, the connection is killed, which
+// interrupts the query it is executing, and it is released when unlocked.
+func (tp *TxPool) Abort(connID tx.ConnID, reason string) error {
+	conn, err := tp.scp.GetAndLock(connID, reason)
+	if err == nil {
+		if !conn.IsInTransaction() {
+			conn.Unlock()
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "connection %d: not in a transaction", connID)
+		}
+		log.Warningf("aborting transaction (%s): %s", reason, conn.String(tp.env.Config().SanitizeLogMessages, tp.env.Environment().Parser()))
+		conn.Close()
+		tp.env.Stats().KillCounters.Add("Transactions", 1)
+		tp.txComplete(conn, tx.TxKill)
+		conn.Releasef("aborted: %s", reason)
+		return nil
+	}
+	for _, conn := range tp.scp.GetAll() {
+		if conn.ConnID != connID {
+			continue
 		}
-		if conn.IsInTransaction() {
-			tp.txComplete(conn, tx.TxKill)
+		dbConn, props := conn.UnderlyingDBConn(), conn.TxProperties()
+		if dbConn == nil || props == nil {
+			// The transaction completed in the meantime.
+			break
 		}
-		conn.Releasef("exceeded timeout: %v", conn.timeout)
+		tp.env.Stats().KillCounters.Add("Transactions", 1)
+		return dbConn.Conn.Kill(reason, time.Since(props.StartTime))
 	}
+	return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "transaction %d: not found", connID)
 }
 
 // WaitForEmpty waits until all active transactions are completed.
@@ -179,6 +241,26 @@ func (tp *TxPool) GetAndLock(connID tx.ConnID, reason string) (*StatefulConnecti
 	return conn, nil
 }
 
+// idleTransactionWarning returns the warning sent with the next statement of a
+// transaction which has been idle for longer than the warning threshold, so
+// that the application learns that it will be rolled back if it stays idle.
+func (tp *TxPool) idleTransactionWarning(conn *StatefulConnection) *querypb.QueryWarning {
+	config := tp.env.Config().IdleTransaction
+	idle, ok := conn.IdleTransaction(config.WarningThreshold)
+	if !ok {
+		return nil
+	}
+	tp.env.Stats().UserIdleTransactions.Add([]string{conn.txUsername(), "Warned"}, 1)
+	message := fmt.Sprintf("transaction %d was idle for %v, longer than %v", conn.ConnID, idle.Round(time.Millisecond), config.WarningThreshold)
+	if config.Timeout > 0 {
+		message += fmt.Sprintf(": it is rolled back when idle for more than %v", config.Timeout)
+	}
+	return &querypb.QueryWarning{
+		Code:    uint32(sqlerror.ERIdleTransaction),
+		Message: message,
+	}
+}
+
 // Commit commits the transaction on the connection.
 func (tp *TxPool) Commit(ctx context.Context, txConn *StatefulConnection) (string, error) {
 	if !txConn.IsInTransaction() {
@@ -186,8 +268,16 @@ func (tp *TxPool) Commit(ctx context.Context, txConn *StatefulConnection) (strin
 	}
 	span, ctx := trace.NewSpan(ctx, "TxPool.Commit")
 	defer span.Finish()
+	if err := txConn.TxProperties().SizeLimitError; err != nil {
+		// The transaction exceeded the size limits: it is rolled back instead.
+		if rollbackErr := tp.Rollback(ctx, txConn); rollbackErr != nil {
+			log.Errorf("tried to rollback the transaction over the size limits, but failed with: %v", rollbackErr)
+		}
+		return "", err
+	}
 	defer tp.txComplete(txConn, tx.TxCommit)
 	if txConn.TxProperties().Autocommit {
+		tp.recordLockWaits(ctx, txConn)
 		return "", nil
 	}
 
@@ -195,6 +285,7 @@ func (tp *TxPool) Commit(ctx context.Context, txConn *StatefulConnection) (strin
 		txConn.Close()
 		return "", err
 	}
+	tp.recordLockWaits(ctx, txConn)
 	return "commit", nil
 }
 
@@ -215,6 +306,7 @@ func (tp *TxPool) Rollback(ctx context.Context, txConn *StatefulConnection) erro
 		return nil
 	}
 	if txConn.TxProperties().Autocommit {
+		tp.recordLockWaits(ctx, txConn)
 		tp.txComplete(txConn, tx.TxCommit)
 		return nil
 	}
@@ -223,6 +315,7 @@ func (tp *TxPool) Rollback(ctx context.Context, txConn *StatefulConnection) erro
 		txConn.Close()
 		return err
 	}
+	tp.recordLockWaits(ctx, txConn)
 	return nil
 }
 
@@ -247,7 +340,7 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	} else {
 		immediateCaller := callerid.ImmediateCallerIDFromContext(ctx)
 		effectiveCaller := callerid.EffectiveCallerIDFromContext(ctx)
-		if !tp.limiter.Get(immediateCaller, effectiveCaller) {
+		if !tp.limiter.Get(immediateCaller, effectiveCaller, options.GetWorkload()) {
 			return nil, "", "", vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "per-user transaction pool connection limit exceeded")
 		}
 		conn, err = tp.createConn(ctx, options, setting)
@@ -255,7 +348,7 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 			if err != nil {
 				// The transaction limiter frees transactions on rollback or commit. If we fail to create the transaction,
 				// release immediately since there will be no rollback or commit.
-				tp.limiter.Release(immediateCaller, effectiveCaller)
+				tp.limiter.Release(immediateCaller, effectiveCaller, options.GetWorkload())
 			}
 		}()
 	}
@@ -280,6 +373,8 @@ func (tp *TxPool) begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	}
 
 	conn.txProps = tp.NewTxProps(immediateCaller, effectiveCaller, autocommit)
+	conn.txProps.SessionUUID = options.GetSessionUuid()
+	conn.txProps.Workload = options.GetWorkload()
 
 	return beginQueries, sessionStateChanges, nil
 }
@@ -454,15 +549,67 @@ func (tp *TxPool) LogActive() {
 	})
 }
 
+// recordLockWaits sets the time the statements of the transaction waited for
+// locks, as reported by performance_schema, with --txlog-lock-wait. It is
+// called once the transaction is concluded, so that it neither delays the
+// statements nor holds the locks of the transaction any longer.
+func (tp *TxPool) recordLockWaits(ctx context.Context, txConn *StatefulConnection) {
+	statements := txConn.TxProperties().Statements
+	if !tp.env.Config().TxLogLockWait || len(statements) == 0 {
+		return
+	}
+	qr, err := txConn.Exec(ctx, lockWaitQuery, maxLockWaitStatements, false)
+	if err != nil {
+		tp.env.Stats().InternalErrors.Add("TxLogLockWait", 1)
+		return
+	}
+	matchLockWaits(statements, qr.Rows)
+}
+
+// matchLockWaits sets the lock wait of the statements from the rows of
+// lockWaitQuery. The rows are the latest statements executed on the
+// connection, most recent first, which include other statements than the
+// ones of the transaction, e.g. the commit, while the earliest statements of
+// a long transaction may be missing.
+func matchLockWaits(statements []*tx.Statement, rows [][]sqltypes.Value) {
+	i := len(statements) - 1
+	for _, row := range rows {
+		if i < 0 {
+			return
+		}
+		if !sqlTextMatches(statements[i].Query, row[0].ToString()) {
+			continue
+		}
+		// The lock time is in picoseconds.
+		if lockTime, err := row[1].ToCastUint64(); err == nil {
+			statements[i].LockWait = time.Duration(lockTime / 1000)
+		}
+		i--
+	}
+}
+
+// sqlTextMatches returns whether the SQL text of a statement reported by
+// performance_schema, which truncates the long statements, is the query.
+func sqlTextMatches(query, sqlText string) bool {
+	if query == sqlText {
+		return true
+	}
+	prefix := strings.TrimSuffix(sqlText, "...")
+	return prefix != "" && len(prefix) < len(query) && strings.HasPrefix(query, prefix)
+}
+
 func (tp *TxPool) txComplete(conn *StatefulConnection, reason tx.ReleaseReason) {
 	conn.LogTransaction(reason)
-	tp.limiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller)
+	tp.limiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller, conn.TxProperties().Workload)
 	conn.CleanTxState()
 }
 
 func txKillerTimeoutInterval(config *tabletenv.TabletConfig) time.Duration {
 	return smallerTimeout(
-		config.TxTimeoutForWorkload(querypb.ExecuteOptions_OLAP),
-		config.TxTimeoutForWorkload(querypb.ExecuteOptions_OLTP),
+		smallerTimeout(
+			config.TxTimeoutForWorkload(querypb.ExecuteOptions_OLAP),
+			config.TxTimeoutForWorkload(querypb.ExecuteOptions_OLTP),
+		),
+		config.IdleTransaction.Timeout,
 	) / 10
 }
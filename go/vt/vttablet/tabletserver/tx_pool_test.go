@@ -18,7 +18,9 @@ package tabletserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -31,10 +33,13 @@ import (
 
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -502,6 +507,73 @@ func TestTxTimeoutDoesNotKillShortLivedTransactions(t *testing.T) {
 	require.Equal(t, int64(0), txPool.env.Stats().KillCounters.Counts()["Transactions"]-startingKills)
 }
 
+func TestTxIdleTimeoutKillsTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env := newEnv("TabletServerTest")
+	env.Config().IdleTransaction.Timeout = 100 * time.Millisecond
+	_, txPool, _, closer := setupWithEnv(t, env)
+	defer closer()
+	startingKills := txPool.env.Stats().KillCounters.Counts()["Transactions"]
+
+	ctxWithCallerID := callerid.NewContext(ctx, &vtrpcpb.CallerID{Principal: "app"}, &querypb.VTGateCallerID{Username: "user"})
+
+	// The transaction executing statements is not idle.
+	busy, _, _, err := txPool.Begin(ctxWithCallerID, &querypb.ExecuteOptions{}, false, 0, nil, nil)
+	require.NoError(t, err)
+	busy.Unlock()
+	idle, _, _, err := txPool.Begin(ctxWithCallerID, &querypb.ExecuteOptions{}, false, 0, nil, nil)
+	require.NoError(t, err)
+	idle.Unlock()
+	for range 5 {
+		time.Sleep(50 * time.Millisecond)
+		conn, err := txPool.GetAndLock(busy.ReservedID(), "for query")
+		require.NoError(t, err)
+		conn.Unlock()
+	}
+
+	require.Equal(t, int64(1), txPool.env.Stats().KillCounters.Counts()["Transactions"]-startingKills)
+	require.Equal(t, int64(1), txPool.env.Stats().UserIdleTransactions.Counts()["app.RolledBack"])
+	_, err = txPool.GetAndLock(idle.ReservedID(), "for query")
+	require.ErrorContains(t, err, "idle for more than 100ms")
+
+	conn, err := txPool.GetAndLock(busy.ReservedID(), "for query")
+	require.NoError(t, err)
+	txPool.RollbackAndRelease(ctx, conn)
+}
+
+func TestTxPoolIdleTransactionWarning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env := newEnv("TabletServerTest")
+	env.Config().IdleTransaction.WarningThreshold = 10 * time.Millisecond
+	env.Config().IdleTransaction.Timeout = 10 * time.Second
+	_, txPool, _, closer := setupWithEnv(t, env)
+	defer closer()
+
+	ctxWithCallerID := callerid.NewContext(ctx, &vtrpcpb.CallerID{Principal: "app"}, &querypb.VTGateCallerID{Username: "user"})
+	conn, _, _, err := txPool.Begin(ctxWithCallerID, &querypb.ExecuteOptions{}, false, 0, nil, nil)
+	require.NoError(t, err)
+	conn.Unlock()
+
+	conn, err = txPool.GetAndLock(conn.ReservedID(), "for query")
+	require.NoError(t, err)
+	assert.Nil(t, txPool.idleTransactionWarning(conn))
+	conn.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	conn, err = txPool.GetAndLock(conn.ReservedID(), "for query")
+	require.NoError(t, err)
+	warning := txPool.idleTransactionWarning(conn)
+	require.NotNil(t, warning)
+	assert.EqualValues(t, sqlerror.ERIdleTransaction, warning.Code)
+	assert.Contains(t, warning.Message, "longer than 10ms: it is rolled back when idle for more than 10s")
+	assert.Equal(t, int64(1), txPool.env.Stats().UserIdleTransactions.Counts()["app.Warned"])
+	txPool.RollbackAndRelease(ctx, conn)
+}
+
 func TestTxTimeoutKillsOlapTransactions(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -832,7 +904,7 @@ type fakeLimiter struct {
 	mu      sync.Mutex
 }
 
-func (fl *fakeLimiter) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) bool {
+func (fl *fakeLimiter) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) bool {
 	fl.mu.Lock()
 	defer fl.mu.Unlock()
 	fl.actions = append(fl.actions, fakeLimiterEntry{
@@ -843,7 +915,7 @@ func (fl *fakeLimiter) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb
 	return true
 }
 
-func (fl *fakeLimiter) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) {
+func (fl *fakeLimiter) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) {
 	fl.mu.Lock()
 	defer fl.mu.Unlock()
 	fl.actions = append(fl.actions, fakeLimiterEntry{
@@ -888,3 +960,78 @@ func setupWithEnv(t *testing.T, env tabletenv.Env) (*fakesqldb.DB, *TxPool, *fak
 		db.Close()
 	}
 }
+
+func TestTxPoolLockWaits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, txPool, _, closer := setup(t)
+	defer closer()
+	txPool.env.Config().TxLogLockWait = true
+
+	// The history has the latest statements first, and includes statements
+	// which are not recorded in the transaction, e.g. the commit.
+	db.AddQuery(lockWaitQuery, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("sql_text|lock_time", "varchar|uint64"),
+		"commit|0",
+		"update t set b = 2 where a = 1|1500000000",
+		"select b from t where a = 1 for update|2000000",
+		"begin|0",
+	))
+
+	ch := tabletenv.TxLogger.Subscribe("test")
+	defer tabletenv.TxLogger.Unsubscribe(ch)
+
+	conn, _, _, err := txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil, nil)
+	require.NoError(t, err)
+	defer conn.Release(tx.TxCommit)
+	conn.TxProperties().RecordStatement("select b from t where a = 1 for update", 3*time.Millisecond, 0)
+	conn.TxProperties().RecordStatement("update t set b = 2 where a = 1", 2*time.Second, 1)
+	_, err = txPool.Commit(ctx, conn)
+	require.NoError(t, err)
+
+	// The transaction log gets a snapshot of the connection with the
+	// transaction, which is formatted after the transaction is cleared.
+	logged := (<-ch).(*StatefulConnection)
+	require.Nil(t, conn.TxProperties())
+	statements := logged.TxProperties().Statements
+	require.Len(t, statements, 2)
+	require.Equal(t, 2*time.Microsecond, statements[0].LockWait)
+	require.Equal(t, 1500*time.Microsecond, statements[1].LockWait)
+
+	defer streamlog.SetQueryLogFormat(streamlog.GetQueryLogFormat())
+	streamlog.SetQueryLogFormat(streamlog.QueryLogFormatJSON)
+	var buf strings.Builder
+	require.NoError(t, logged.Logf(&buf, nil))
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &record))
+	require.Equal(t, "commit", record["Conclusion"])
+	require.Equal(t, []any{
+		map[string]any{"Query": "select b from t where a = 1 for update", "Duration": 0.003, "RowsAffected": float64(0), "LockWait": 0.000002},
+		map[string]any{"Query": "update t set b = 2 where a = 1", "Duration": float64(2), "RowsAffected": float64(1), "LockWait": 0.0015},
+	}, record["Statements"])
+}
+
+func TestMatchLockWaits(t *testing.T) {
+	longQuery := "select '" + strings.Repeat("a", 100) + "' from dual"
+	statements := []*tx.Statement{
+		{Query: "insert into t values (1)"},
+		{Query: "insert into t values (2)"},
+		{Query: longQuery},
+		{Query: "insert into t values (1)"},
+	}
+	// The first statement is missing from the history, and the long query is
+	// truncated.
+	matchLockWaits(statements, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("sql_text|lock_time", "varchar|uint64"),
+		"rollback|0",
+		"insert into t values (1)|4000",
+		longQuery[:50]+"...|3000",
+		"savepoint a|9000",
+		"insert into t values (2)|2000",
+	).Rows)
+	var lockWaits []time.Duration
+	for _, stmt := range statements {
+		lockWaits = append(lockWaits, stmt.LockWait)
+	}
+	require.Equal(t, []time.Duration{0, 2, 3, 4}, lockWaits)
+}
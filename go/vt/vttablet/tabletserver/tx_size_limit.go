@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// rowImageOverhead is the estimated number of bytes of a row event
+	// besides the values of the columns.
+	rowImageOverhead = 16
+	// maxColumnImageSize caps the estimated size of the values of the
+	// variable length columns, which are rarely as long as they may be.
+	maxColumnImageSize = 256
+	// unknownRowImageSize is the estimated size of the rows of the tables
+	// missing from the schema.
+	unknownRowImageSize = 256
+)
+
+var txSizeLimitLogger = logutil.NewThrottledLogger("TransactionSizeLimit", 1*time.Minute)
+
+// txSizeGuard limits the number of rows a transaction modifies in each table,
+// and the number of binlog bytes it generates for them, so that a runaway
+// batch does not stall the replication for hours. The statement which
+// exceeds a limit fails, and so does the commit of the transaction.
+//
+// The binlog bytes are estimated from the rows the statements affect and the
+// size of the columns of their table, counting the before and after images of
+// the updated rows.
+type txSizeGuard struct {
+	limit   tabletenv.TransactionSizeLimit
+	tables  map[string]tabletenv.TransactionSizeLimit
+	callers map[string]tabletenv.TransactionSizeLimit
+	dryRun  bool
+
+	exceeded *stats.CountersWithMultiLabels
+}
+
+func newTxSizeGuard(env tabletenv.Env) *txSizeGuard {
+	config := env.Config().TransactionSizeLimit
+	// The overrides are checked by the config verification.
+	tables, _ := tabletenv.ParseTransactionSizeLimits(config.TableOverrides)
+	callers, _ := tabletenv.ParseTransactionSizeLimits(config.CallerOverrides)
+	return &txSizeGuard{
+		limit:    tabletenv.TransactionSizeLimit{Rows: config.MaxRows, Bytes: config.MaxBytes},
+		tables:   tables,
+		callers:  callers,
+		dryRun:   config.DryRun,
+		exceeded: env.Exporter().NewCountersWithMultiLabels("TransactionSizeLimitExceeded", "Transactions which exceeded the transaction size limits of a table, by whether they were failed", []string{"TableName", "CallerID", "Mode"}),
+	}
+}
+
+func (g *txSizeGuard) enabled() bool {
+	return g != nil && (g.limit.Rows > 0 || g.limit.Bytes > 0 || len(g.tables) > 0 || len(g.callers) > 0)
+}
+
+// limitFor returns the limits of the transaction for the table: those of its
+// caller, else those of the table, else the default ones.
+func (g *txSizeGuard) limitFor(props *tx.Properties, table string) tabletenv.TransactionSizeLimit {
+	if props.ImmediateCaller != nil {
+		if limit, ok := g.callers[callerid.GetUsername(props.ImmediateCaller)]; ok {
+			return limit
+		}
+	}
+	if props.EffectiveCaller != nil {
+		if limit, ok := g.callers[callerid.GetPrincipal(props.EffectiveCaller)]; ok {
+			return limit
+		}
+	}
+	if limit, ok := g.tables[table]; ok {
+		return limit
+	}
+	return g.limit
+}
+
+// limits returns whether the transactions of the callers are limited in the
+// table.
+func (g *txSizeGuard) limits(immediateCaller *querypb.VTGateCallerID, effectiveCaller *vtrpcpb.CallerID, table string) bool {
+	if !g.enabled() {
+		return false
+	}
+	limit := g.limitFor(&tx.Properties{ImmediateCaller: immediateCaller, EffectiveCaller: effectiveCaller}, table)
+	return limit.Rows > 0 || limit.Bytes > 0
+}
+
+// record accounts for the rows a statement of the transaction modified in the
+// table, and returns an error if the transaction exceeded its limits. The
+// autocommit transactions are not limited, as their statements are committed
+// as they are executed: the DMLs of limited tables are executed in
+// transactions of their own instead.
+func (g *txSizeGuard) record(props *tx.Properties, table string, rows, bytes uint64) error {
+	if !g.enabled() || props == nil || props.Autocommit || rows == 0 {
+		return nil
+	}
+	if props.Modified == nil {
+		props.Modified = make(map[string]*tx.Modification)
+	}
+	modified := props.Modified[table]
+	if modified == nil {
+		modified = &tx.Modification{}
+		props.Modified[table] = modified
+	}
+	modified.Rows += rows
+	modified.Bytes += bytes
+
+	limit := g.limitFor(props, table)
+	var err error
+	switch {
+	case limit.Rows > 0 && modified.Rows > uint64(limit.Rows):
+		err = sqlerror.NewSQLError(sqlerror.ERTxSizeExceeded, sqlerror.SSUnknownSQLState,
+			"transaction size limit exceeded: the transaction modified %d rows of table %s, over the limit of %d; it must be rolled back", modified.Rows, table, limit.Rows)
+	case limit.Bytes > 0 && modified.Bytes > uint64(limit.Bytes):
+		err = sqlerror.NewSQLError(sqlerror.ERTxSizeExceeded, sqlerror.SSUnknownSQLState,
+			"transaction size limit exceeded: the transaction generated an estimated %d binlog bytes for table %s, over the limit of %d; it must be rolled back", modified.Bytes, table, limit.Bytes)
+	default:
+		return nil
+	}
+
+	if !modified.LimitExceeded {
+		modified.LimitExceeded = true
+		mode := "Enforced"
+		if g.dryRun {
+			mode = "DryRun"
+		}
+		caller := callerid.GetUsername(props.ImmediateCaller)
+		g.exceeded.Add([]string{table, caller, mode}, 1)
+		txSizeLimitLogger.Warningf("caller id: %s: %v (%s)", caller, err, mode)
+	}
+	if g.dryRun {
+		return nil
+	}
+	props.SizeLimitError = err
+	return err
+}
+
+// rowImageSize estimates the number of binlog bytes of a row of the table.
+func rowImageSize(table *schema.Table) uint64 {
+	if table == nil || len(table.Fields) == 0 {
+		return unknownRowImageSize
+	}
+	size := uint64(rowImageOverhead)
+	for _, field := range table.Fields {
+		switch {
+		case sqltypes.IsText(field.Type), sqltypes.IsBinary(field.Type):
+			size += min(uint64(field.ColumnLength), maxColumnImageSize)
+		default:
+			size += 8
+		}
+	}
+	return size
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestTxSizeGuard(t *testing.T) {
+	guard := &txSizeGuard{
+		limit:    tabletenv.TransactionSizeLimit{Rows: 10, Bytes: 1000},
+		tables:   map[string]tabletenv.TransactionSizeLimit{"events": {Rows: 100}},
+		callers:  map[string]tabletenv.TransactionSizeLimit{"etl": {}},
+		exceeded: stats.NewCountersWithMultiLabels("", "", []string{"TableName", "CallerID", "Mode"}),
+	}
+	props := func(username string) *tx.Properties {
+		return &tx.Properties{ImmediateCaller: &querypb.VTGateCallerID{Username: username}}
+	}
+
+	// The default limits.
+	p := props("app")
+	require.NoError(t, guard.record(p, "t1", 10, 100))
+	require.ErrorContains(t, guard.record(p, "t1", 1, 10), "modified 11 rows of table t1, over the limit of 10")
+	require.ErrorContains(t, p.SizeLimitError, "modified 11 rows of table t1")
+	require.ErrorContains(t, guard.record(props("app"), "t1", 1, 1001), "generated an estimated 1001 binlog bytes for table t1, over the limit of 1000")
+
+	// The rows are accounted by table.
+	p = props("app")
+	require.NoError(t, guard.record(p, "t1", 10, 0))
+	require.NoError(t, guard.record(p, "t2", 10, 0))
+
+	// The table overrides, where 0 does not limit.
+	require.NoError(t, guard.record(props("app"), "events", 100, 1000000))
+	require.Error(t, guard.record(props("app"), "events", 101, 0))
+
+	// The caller overrides, by username or principal.
+	require.NoError(t, guard.record(props("etl"), "t1", 1000, 1000000))
+	p = &tx.Properties{EffectiveCaller: &vtrpcpb.CallerID{Principal: "etl"}}
+	require.NoError(t, guard.record(p, "t1", 1000, 1000000))
+
+	// The autocommit transactions are not limited.
+	p = props("app")
+	p.Autocommit = true
+	require.NoError(t, guard.record(p, "t1", 1000, 0))
+
+	assert.EqualValues(t, 2, guard.exceeded.Counts()["t1.app.Enforced"])
+	assert.EqualValues(t, 1, guard.exceeded.Counts()["events.app.Enforced"])
+
+	// Without limits, nothing is accounted.
+	guard = &txSizeGuard{}
+	p = props("app")
+	require.NoError(t, guard.record(p, "t1", 1000, 0))
+	assert.Nil(t, p.Modified)
+}
+
+func TestTxSizeGuardLimits(t *testing.T) {
+	guard := &txSizeGuard{
+		tables:  map[string]tabletenv.TransactionSizeLimit{"events": {Rows: 100}},
+		callers: map[string]tabletenv.TransactionSizeLimit{"etl": {}},
+	}
+	assert.True(t, guard.limits(&querypb.VTGateCallerID{Username: "app"}, nil, "events"))
+	assert.False(t, guard.limits(&querypb.VTGateCallerID{Username: "app"}, nil, "users"))
+	// The callers without limits are not limited in any table.
+	assert.False(t, guard.limits(&querypb.VTGateCallerID{Username: "etl"}, nil, "events"))
+	assert.False(t, guard.limits(nil, &vtrpcpb.CallerID{Principal: "etl"}, "events"))
+
+	var disabled *txSizeGuard
+	assert.False(t, disabled.limits(nil, nil, "events"))
+}
+
+func TestRowImageSize(t *testing.T) {
+	assert.EqualValues(t, unknownRowImageSize, rowImageSize(nil))
+	table := &schema.Table{Fields: []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64, ColumnLength: 20},
+		{Name: "name", Type: sqltypes.VarChar, ColumnLength: 64},
+		{Name: "body", Type: sqltypes.Blob, ColumnLength: 65535},
+	}}
+	assert.EqualValues(t, rowImageOverhead+8+64+maxColumnImageSize, rowImageSize(table))
+}
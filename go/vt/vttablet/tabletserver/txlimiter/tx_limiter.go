@@ -17,8 +17,11 @@ limitations under the License.
 package txlimiter
 
 import (
+	"math"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/callerid"
@@ -33,13 +36,18 @@ const unknown string = "unknown"
 
 // TxLimiter is the transaction limiter interface.
 type TxLimiter interface {
-	Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) bool
-	Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID)
+	Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) bool
+	Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload)
 }
 
 // New creates a new TxLimiter.
 // slotCount: total slot count in transaction pool
-// maxPerUser: fraction of the pool that may be taken by single user
+// maxPerUser: fraction of the pool that may be taken by single user, with
+// a separate fraction for the OLAP transactions
+// ratePerUser: transactions per second a single user may begin, on top of
+// a burst of transactions begun at once, with a separate rate for the OLAP
+// transactions
+// exemptUsers: usernames and principals of the users which are not limited
 // enabled: should the feature be enabled. If false, will return
 // "allow-all" limiter
 // dryRun: if true, does no limiting, but records stats of the decisions made
@@ -52,15 +60,31 @@ func New(env tabletenv.Env) TxLimiter {
 		return &TxAllowAll{}
 	}
 
+	maxPerUser := int64(float64(config.TxPool.Size) * config.TransactionLimitPerUser)
+	maxPerUserOLAP := maxPerUser
+	if config.TransactionLimitPerUserOLAP > 0 {
+		maxPerUserOLAP = int64(float64(config.TxPool.Size) * config.TransactionLimitPerUserOLAP)
+	}
+	ratePerUserOLAP := config.TransactionLimitRatePerUser
+	if config.TransactionLimitRatePerUserOLAP > 0 {
+		ratePerUserOLAP = config.TransactionLimitRatePerUserOLAP
+	}
 	return &Impl{
-		maxPerUser:       int64(float64(config.TxPool.Size) * config.TransactionLimitPerUser),
+		maxPerUser:       maxPerUser,
+		maxPerUserOLAP:   maxPerUserOLAP,
+		ratePerUser:      config.TransactionLimitRatePerUser,
+		ratePerUserOLAP:  ratePerUserOLAP,
+		burstPerUser:     float64(config.TransactionLimitBurstPerUser),
+		exemptUsers:      slices.Clone(config.TransactionLimitExemptUsers),
+		now:              time.Now,
 		dryRun:           config.EnableTransactionLimitDryRun,
 		byUsername:       config.TransactionLimitByUsername,
 		byPrincipal:      config.TransactionLimitByPrincipal,
 		byComponent:      config.TransactionLimitByComponent,
 		bySubcomponent:   config.TransactionLimitBySubcomponent,
 		byEffectiveUser:  config.TransactionLimitByPrincipal || config.TransactionLimitByComponent || config.TransactionLimitBySubcomponent,
-		usageMap:         make(map[string]int64),
+		usageMap:         make(map[usageKey]int64),
+		buckets:          make(map[usageKey]*tokenBucket),
 		rejections:       env.Exporter().NewCountersWithSingleLabel("TxLimiterRejections", "rejections from TxLimiter", "user"),
 		rejectionsDryRun: env.Exporter().NewCountersWithSingleLabel("TxLimiterRejectionsDryRun", "rejections from TxLimiter in dry run", "user"),
 	}
@@ -72,23 +96,30 @@ type TxAllowAll struct{}
 
 // Get always returns true (allows all requests).
 // Implements TxLimiter.Get
-func (txa *TxAllowAll) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) bool {
+func (txa *TxAllowAll) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) bool {
 	return true
 }
 
 // Release is noop, because TxAllowAll does no tracking.
 // Implements TxLimiter.Release
-func (txa *TxAllowAll) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) {
+func (txa *TxAllowAll) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) {
 	// NOOP
 }
 
 // Impl limits the total number of transactions a single user may use
-// concurrently.
+// concurrently, and the rate at which they may begin them. The OLAP
+// transactions of a user are limited separately from the others.
 // Implements TxLimiter.
 type Impl struct {
-	maxPerUser int64
-	usageMap   map[string]int64
-	mu         sync.Mutex
+	maxPerUser, maxPerUserOLAP   int64
+	ratePerUser, ratePerUserOLAP float64
+	burstPerUser                 float64
+	exemptUsers                  []string
+	now                          func() time.Time
+
+	usageMap map[usageKey]int64
+	buckets  map[usageKey]*tokenBucket
+	mu       sync.Mutex
 
 	dryRun          bool
 	byUsername      bool
@@ -100,29 +131,82 @@ type Impl struct {
 	rejections, rejectionsDryRun *stats.CountersWithSingleLabel
 }
 
+// usageKey identifies the transactions of a user which are limited
+// together: the OLAP ones, or the others.
+type usageKey struct {
+	user string
+	olap bool
+}
+
+// tokenBucket limits the rate at which a user begins transactions: it holds
+// up to burst tokens, refilled at rate tokens per second, and beginning a
+// transaction takes one.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accumulated since the bucket was last refilled.
+func (b *tokenBucket) refill(now time.Time, rate, burst float64) {
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+}
+
 // Get tells whether given user (identified by context.Context) is allowed
 // to use another transaction slot. If this method returns true, it's
 // necessary to call Release once transaction is returned to the pool.
 // Implements TxLimiter.Get
-func (txl *Impl) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) bool {
+func (txl *Impl) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) bool {
+	if txl.isExempt(immediate, effective) {
+		return true
+	}
 	key := txl.extractKey(immediate, effective)
+	uk := usageKey{user: key, olap: workload == querypb.ExecuteOptions_OLAP}
+	maxPerUser, rate := txl.maxPerUser, txl.ratePerUser
+	if uk.olap {
+		maxPerUser, rate = txl.maxPerUserOLAP, txl.ratePerUserOLAP
+	}
 
 	txl.mu.Lock()
 	defer txl.mu.Unlock()
 
-	usage := txl.usageMap[key]
-	if usage < txl.maxPerUser {
-		txl.usageMap[key] = usage + 1
+	usage := txl.usageMap[uk]
+	overLimit := usage >= maxPerUser
+	var bucket *tokenBucket
+	overRate := false
+	if rate > 0 && !overLimit {
+		burst := txl.burstPerUser
+		if burst == 0 {
+			burst = math.Max(1, rate)
+		}
+		now := txl.now()
+		bucket = txl.buckets[uk]
+		if bucket == nil {
+			bucket = &tokenBucket{tokens: burst, last: now}
+			txl.buckets[uk] = bucket
+		}
+		bucket.refill(now, rate, burst)
+		overRate = bucket.tokens < 1
+	}
+	if !overLimit && !overRate {
+		txl.usageMap[uk] = usage + 1
+		if bucket != nil {
+			bucket.tokens--
+		}
 		return true
 	}
 
+	reason := "over limit"
+	if overRate {
+		reason = "over rate"
+	}
 	if txl.dryRun {
-		log.Infof("TxLimiter: DRY RUN: user over limit: %s", key)
+		log.Infof("TxLimiter: DRY RUN: user %s: %s", reason, key)
 		txl.rejectionsDryRun.Add(key, 1)
 		return true
 	}
 
-	log.Infof("TxLimiter: Over limit, rejecting transaction request for user: %s", key)
+	log.Infof("TxLimiter: %s, rejecting transaction request for user: %s", reason, key)
 	txl.rejections.Add(key, 1)
 	return false
 }
@@ -130,22 +214,38 @@ func (txl *Impl) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.Calle
 // Release marks that given user (identified by caller ID) is no longer using
 // a transaction slot.
 // Implements TxLimiter.Release
-func (txl *Impl) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) {
+func (txl *Impl) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID, workload querypb.ExecuteOptions_Workload) {
+	if txl.isExempt(immediate, effective) {
+		return
+	}
 	key := txl.extractKey(immediate, effective)
+	uk := usageKey{user: key, olap: workload == querypb.ExecuteOptions_OLAP}
 
 	txl.mu.Lock()
 	defer txl.mu.Unlock()
 
-	usage, ok := txl.usageMap[key]
+	usage, ok := txl.usageMap[uk]
 	if !ok {
 		return
 	}
 	if usage == 1 {
-		delete(txl.usageMap, key)
+		delete(txl.usageMap, uk)
 		return
 	}
 
-	txl.usageMap[key] = usage - 1
+	txl.usageMap[uk] = usage - 1
+}
+
+// isExempt returns true if the username of the immediate caller or the
+// principal of the effective caller is one of the exempt users.
+func (txl *Impl) isExempt(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) bool {
+	if len(txl.exemptUsers) == 0 {
+		return false
+	}
+	if immediate != nil && slices.Contains(txl.exemptUsers, callerid.GetUsername(immediate)) {
+		return true
+	}
+	return effective != nil && slices.Contains(txl.exemptUsers, callerid.GetPrincipal(effective))
 }
 
 // extractKey builds a string key used to differentiate users, based
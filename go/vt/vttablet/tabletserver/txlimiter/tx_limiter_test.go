@@ -18,6 +18,10 @@ package txlimiter
 
 import (
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/vtenv"
@@ -51,7 +55,7 @@ func TestTxLimiter_DisabledAllowsAll(t *testing.T) {
 	limiter := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"))
 	im, ef := createCallers("", "", "", "")
 	for i := 0; i < 5; i++ {
-		if got, want := limiter.Get(im, ef), true; got != want {
+		if got, want := limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 			t.Errorf("Transaction number %d, Get(): got %v, want %v", i, got, want)
 		}
 	}
@@ -81,13 +85,13 @@ func TestTxLimiter_LimitsOnlyOffendingUser(t *testing.T) {
 
 	// user1 uses 3 slots
 	for i := 0; i < 3; i++ {
-		if got, want := limiter.Get(im1, ef1), true; got != want {
+		if got, want := limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 			t.Errorf("Transaction number %d, Get(im1, ef1): got %v, want %v", i, got, want)
 		}
 	}
 
 	// user1 not allowed to use 4th slot, which increases counter
-	if got, want := limiter.Get(im1, ef1), false; got != want {
+	if got, want := limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED), false; got != want {
 		t.Errorf("Get(im1, ef1) after using up all allowed attempts: got %v, want %v", got, want)
 	}
 
@@ -98,13 +102,13 @@ func TestTxLimiter_LimitsOnlyOffendingUser(t *testing.T) {
 
 	// user2 uses 3 slots
 	for i := 0; i < 3; i++ {
-		if got, want := limiter.Get(im2, ef2), true; got != want {
+		if got, want := limiter.Get(im2, ef2, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 			t.Errorf("Transaction number %d, Get(im2, ef2): got %v, want %v", i, got, want)
 		}
 	}
 
 	// user2 not allowed to use 4th slot, which increases counter
-	if got, want := limiter.Get(im2, ef2), false; got != want {
+	if got, want := limiter.Get(im2, ef2, querypb.ExecuteOptions_UNSPECIFIED), false; got != want {
 		t.Errorf("Get(im2, ef2) after using up all allowed attempts: got %v, want %v", got, want)
 	}
 	key2 := limiter.extractKey(im2, ef2)
@@ -113,8 +117,8 @@ func TestTxLimiter_LimitsOnlyOffendingUser(t *testing.T) {
 	}
 
 	// user1 releases a slot, which allows to get another
-	limiter.Release(im1, ef1)
-	if got, want := limiter.Get(im1, ef1), true; got != want {
+	limiter.Release(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED)
+	if got, want := limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 		t.Errorf("Get(im1, ef1) after releasing: got %v, want %v", got, want)
 	}
 
@@ -147,13 +151,13 @@ func TestTxLimiterDryRun(t *testing.T) {
 
 	// uses 3 slots
 	for i := 0; i < 3; i++ {
-		if got, want := limiter.Get(im, ef), true; got != want {
+		if got, want := limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 			t.Errorf("Transaction number %d, Get(im, ef): got %v, want %v", i, got, want)
 		}
 	}
 
 	// allowed to use 4th slot, but dry run rejection counter increased
-	if got, want := limiter.Get(im, ef), true; got != want {
+	if got, want := limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED), true; got != want {
 		t.Errorf("Get(im, ef) after using up all allowed attempts: got %v, want %v", got, want)
 	}
 
@@ -164,3 +168,103 @@ func TestTxLimiterDryRun(t *testing.T) {
 		t.Errorf("RejectionsDryRun count for %s: got %d, want %d", key, got, want)
 	}
 }
+
+func TestTxLimiterRate(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.TxPool.Size = 10
+	cfg.TransactionLimitPerUser = 0.5
+	cfg.EnableTransactionLimit = true
+	cfg.TransactionLimitByUsername = true
+	cfg.TransactionLimitByPrincipal = false
+	cfg.TransactionLimitRatePerUser = 2
+	cfg.TransactionLimitBurstPerUser = 3
+
+	newlimiter := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"))
+	limiter, ok := newlimiter.(*Impl)
+	require.True(t, ok)
+	resetVariables(limiter)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	im1, ef1 := createCallers("user1", "", "", "")
+	im2, ef2 := createCallers("user2", "", "", "")
+	key1 := limiter.extractKey(im1, ef1)
+
+	// user1 begins a burst of 3 short transactions, then is over the rate,
+	// although it uses no slot.
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED), "transaction %d", i)
+		limiter.Release(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED)
+	}
+	assert.False(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+	assert.EqualValues(t, 1, limiter.rejections.Counts()[key1])
+	assert.True(t, limiter.Get(im2, ef2, querypb.ExecuteOptions_UNSPECIFIED))
+
+	// The rate refills the bucket of user1.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+	assert.False(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED), "transaction %d", i)
+	}
+
+	// The transactions rejected for using all their slots do not take tokens.
+	now = now.Add(time.Minute)
+	assert.True(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+	assert.False(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+	limiter.Release(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED)
+	assert.True(t, limiter.Get(im1, ef1, querypb.ExecuteOptions_UNSPECIFIED))
+}
+
+func TestTxLimiterOLAP(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.TxPool.Size = 10
+	cfg.TransactionLimitPerUser = 0.3
+	cfg.TransactionLimitPerUserOLAP = 0.1
+	cfg.EnableTransactionLimit = true
+	cfg.TransactionLimitByUsername = true
+	cfg.TransactionLimitByPrincipal = false
+
+	newlimiter := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"))
+	limiter, ok := newlimiter.(*Impl)
+	require.True(t, ok)
+	resetVariables(limiter)
+	im, ef := createCallers("user", "", "", "")
+
+	// The OLAP transactions have their own slots.
+	assert.True(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLAP))
+	assert.False(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLAP))
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLTP), "transaction %d", i)
+	}
+	assert.False(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLTP))
+
+	limiter.Release(im, ef, querypb.ExecuteOptions_OLAP)
+	assert.False(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLTP))
+	assert.True(t, limiter.Get(im, ef, querypb.ExecuteOptions_OLAP))
+}
+
+func TestTxLimiterExemptUsers(t *testing.T) {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.TxPool.Size = 10
+	cfg.TransactionLimitPerUser = 0.1
+	cfg.EnableTransactionLimit = true
+	cfg.TransactionLimitByUsername = true
+	cfg.TransactionLimitByPrincipal = true
+	cfg.TransactionLimitExemptUsers = []string{"admin", "migration"}
+
+	newlimiter := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"))
+	limiter, ok := newlimiter.(*Impl)
+	require.True(t, ok)
+	resetVariables(limiter)
+
+	for _, user := range [][2]string{{"admin", ""}, {"app", "migration"}} {
+		im, ef := createCallers(user[0], user[1], "", "")
+		for i := 0; i < 3; i++ {
+			assert.True(t, limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED), "user %v, transaction %d", user, i)
+		}
+	}
+	im, ef := createCallers("app", "", "", "")
+	assert.True(t, limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED))
+	assert.False(t, limiter.Get(im, ef, querypb.ExecuteOptions_UNSPECIFIED))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/constants/sidecar"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	sqlSaveVStreamPosition = `insert into %s.vstream_consumer_positions(consumer_group, pos, shard_gtid, time_updated) values (%a, %a, %a, %a)
+	on duplicate key update pos = values(pos), shard_gtid = values(shard_gtid), time_updated = values(time_updated)`
+	sqlReadVStreamPosition = "select shard_gtid from %s.vstream_consumer_positions where consumer_group = %a"
+)
+
+// saveVStreamPosition persists the position acknowledged by a VStream consumer
+// group in the sidecar database, replacing the previous one.
+func (tsv *TabletServer) saveVStreamPosition(ctx context.Context, consumerGroup string, shardGtid *binlogdatapb.ShardGtid) error {
+	if consumerGroup == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "consumer group must be specified")
+	}
+	if shardGtid.GetGtid() == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "position of consumer group %s must be specified", consumerGroup)
+	}
+	encoded, err := shardGtid.MarshalVT()
+	if err != nil {
+		return err
+	}
+	insert := sqlparser.BuildParsedQuery(sqlSaveVStreamPosition, sidecar.GetIdentifier(), ":consumer_group", ":pos", ":shard_gtid", ":time_updated")
+	query, err := insert.GenerateQuery(map[string]*querypb.BindVariable{
+		"consumer_group": sqltypes.StringBindVariable(consumerGroup),
+		"pos":            sqltypes.StringBindVariable(shardGtid.Gtid),
+		"shard_gtid":     sqltypes.BytesBindVariable(encoded),
+		"time_updated":   sqltypes.Int64BindVariable(time.Now().UnixNano()),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	conn, err := tsv.qe.conns.Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Recycle()
+	_, err = conn.Conn.Exec(ctx, query, 0, false)
+	return err
+}
+
+// readVStreamPosition returns the position acknowledged by a VStream consumer
+// group, or nil if it has none.
+func (tsv *TabletServer) readVStreamPosition(ctx context.Context, consumerGroup string) (*binlogdatapb.ShardGtid, error) {
+	if consumerGroup == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "consumer group must be specified")
+	}
+	sel := sqlparser.BuildParsedQuery(sqlReadVStreamPosition, sidecar.GetIdentifier(), ":consumer_group")
+	query, err := sel.GenerateQuery(map[string]*querypb.BindVariable{
+		"consumer_group": sqltypes.StringBindVariable(consumerGroup),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := tsv.qe.conns.Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+	qr, err := conn.Conn.Exec(ctx, query, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) == 0 {
+		return nil, nil
+	}
+	encoded, err := qr.Rows[0][0].ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	shardGtid := &binlogdatapb.ShardGtid{}
+	if err := shardGtid.UnmarshalVT(encoded); err != nil {
+		return nil, vterrors.Wrapf(err, "invalid position of consumer group %s", consumerGroup)
+	}
+	return shardGtid, nil
+}
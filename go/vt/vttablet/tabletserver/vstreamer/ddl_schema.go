@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"slices"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// ddlTableNames returns the names of the tables of the database which are
+// changed by a DDL and matched by the filter.
+func ddlTableNames(sql string, dbname string, filter *binlogdatapb.Filter, parser *sqlparser.Parser) []string {
+	ast, err := parser.Parse(sql)
+	if err != nil {
+		return nil
+	}
+	stmt, ok := ast.(sqlparser.DDLStatement)
+	if !ok {
+		return nil
+	}
+
+	tables := []sqlparser.TableName{stmt.GetTable()}
+	tables = append(tables, stmt.GetFromTables()...)
+	tables = append(tables, stmt.GetToTables()...)
+	var names []string
+	for _, table := range tables {
+		if table.IsEmpty() || !tableMatches(table, dbname, filter) {
+			continue
+		}
+		if name := table.Name.String(); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// tableSchemasBeforeDDL returns the schemas of the tables right before the DDL
+// of the current transaction.
+func (vs *vstreamer) tableSchemasBeforeDDL(tables []string) []*binlogdatapb.TableSchemaChange {
+	changes := make([]*binlogdatapb.TableSchemaChange, 0, len(tables))
+	for _, table := range tables {
+		changes = append(changes, &binlogdatapb.TableSchemaChange{
+			TableName: table,
+			Before:    vs.historicalTableForPos(table, vs.prevPos),
+		})
+	}
+	return changes
+}
+
+// addTableSchemasAfterDDL completes the changes with the schemas of the
+// tables after the DDL of the current transaction, and how they differ from
+// their schemas before it.
+func (vs *vstreamer) addTableSchemasAfterDDL(changes []*binlogdatapb.TableSchemaChange) {
+	for _, change := range changes {
+		change.After = vs.historicalTableForPos(change.TableName, vs.pos)
+		diffTableSchemas(change)
+	}
+}
+
+// historicalTableForPos returns the schema of a table at a position from the
+// schema history, or nil if it is unknown.
+func (vs *vstreamer) historicalTableForPos(table string, pos replication.Position) *binlogdatapb.MinimalTable {
+	mt, err := vs.se.GetHistoricalTableForPos(sqlparser.NewIdentifierCS(table), replication.EncodePosition(pos))
+	if err != nil {
+		log.Warningf("Failed to get the schema of table %s at %v for a DDL event: %v", table, pos, err)
+		return nil
+	}
+	return mt
+}
+
+// diffTableSchemas sets the columns added, dropped and modified by a change,
+// and whether it changed the primary key.
+func diffTableSchemas(change *binlogdatapb.TableSchemaChange) {
+	if change.Before == nil || change.After == nil {
+		return
+	}
+
+	before := make(map[string]*querypb.Field, len(change.Before.Fields))
+	for _, field := range change.Before.Fields {
+		before[field.Name] = field
+	}
+	after := make(map[string]bool, len(change.After.Fields))
+	for _, field := range change.After.Fields {
+		after[field.Name] = true
+		old, ok := before[field.Name]
+		switch {
+		case !ok:
+			change.AddedColumns = append(change.AddedColumns, field.Name)
+		case old.Type != field.Type || old.ColumnType != field.ColumnType:
+			change.ModifiedColumns = append(change.ModifiedColumns, field.Name)
+		}
+	}
+	for _, field := range change.Before.Fields {
+		if !after[field.Name] {
+			change.DroppedColumns = append(change.DroppedColumns, field.Name)
+		}
+	}
+
+	change.PrimaryKeyChanged = !slices.Equal(primaryKeyNames(change.Before), primaryKeyNames(change.After))
+}
+
+// primaryKeyNames returns the names of the primary key columns of a table.
+func primaryKeyNames(table *binlogdatapb.MinimalTable) []string {
+	names := make([]string, 0, len(table.PKColumns))
+	for _, col := range table.PKColumns {
+		if col >= 0 && int(col) < len(table.Fields) {
+			names = append(names, table.Fields[col].Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestDDLTableNames(t *testing.T) {
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/^t/",
+		}},
+	}
+	testcases := []struct {
+		sql  string
+		want []string
+	}{{
+		sql:  "alter table t1 add column c int",
+		want: []string{"t1"},
+	}, {
+		sql:  "create table t2 (id int primary key)",
+		want: []string{"t2"},
+	}, {
+		sql:  "drop table t1, other, t2",
+		want: []string{"t1", "t2"},
+	}, {
+		sql:  "rename table t1 to t1_old, t2 to t1",
+		want: []string{"t1", "t2", "t1_old"},
+	}, {
+		sql:  "alter table otherdb.t1 add column c int",
+		want: nil,
+	}, {
+		sql:  "alter table other add column c int",
+		want: nil,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			assert.Equal(t, tc.want, ddlTableNames(tc.sql, "db", filter, sqlparser.NewTestParser()))
+		})
+	}
+}
+
+func TestDiffTableSchemas(t *testing.T) {
+	field := func(name string, typ querypb.Type, columnType string) *querypb.Field {
+		return &querypb.Field{Name: name, Type: typ, ColumnType: columnType}
+	}
+	change := &binlogdatapb.TableSchemaChange{
+		TableName: "t1",
+		Before: &binlogdatapb.MinimalTable{
+			Name: "t1",
+			Fields: []*querypb.Field{
+				field("id", querypb.Type_INT32, "int"),
+				field("c1", querypb.Type_VARCHAR, "varchar(10)"),
+				field("c2", querypb.Type_INT32, "int"),
+			},
+			PKColumns: []int64{0},
+		},
+		After: &binlogdatapb.MinimalTable{
+			Name: "t1",
+			Fields: []*querypb.Field{
+				field("id", querypb.Type_INT32, "int"),
+				field("c1", querypb.Type_VARCHAR, "varchar(20)"),
+				field("c3", querypb.Type_INT64, "bigint"),
+			},
+			PKColumns: []int64{0, 2},
+		},
+	}
+	diffTableSchemas(change)
+	assert.Equal(t, []string{"c3"}, change.AddedColumns)
+	assert.Equal(t, []string{"c2"}, change.DroppedColumns)
+	assert.Equal(t, []string{"c1"}, change.ModifiedColumns)
+	assert.True(t, change.PrimaryKeyChanged)
+
+	// Nothing can be diffed when the table is created.
+	create := &binlogdatapb.TableSchemaChange{TableName: "t2", After: change.After}
+	diffTableSchemas(create)
+	assert.Empty(t, create.AddedColumns)
+	assert.False(t, create.PrimaryKeyChanged)
+}
+
+func TestDDLTableSchemas(t *testing.T) {
+	execStatement(t, "create table ddl_schema_test(id int, val1 varbinary(128), val2 int, primary key(id))")
+	defer execStatement(t, "drop table ddl_schema_test")
+	require.NoError(t, env.SchemaEngine.Reload(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/ddl_schema_test/",
+		}},
+		DdlTableSchemas: true,
+	}
+	wg, ch := startStream(ctx, t, filter, "", nil)
+	defer wg.Wait()
+
+	execStatement(t, "alter table ddl_schema_test add column val3 varbinary(128), drop column val2")
+
+	var ddl *binlogdatapb.VEvent
+	for ddl == nil {
+		evs, ok := <-ch
+		require.True(t, ok, "stream ended before the DDL event")
+		for _, ev := range evs {
+			if ev.Type == binlogdatapb.VEventType_DDL {
+				ddl = ev
+			}
+		}
+	}
+	cancel()
+
+	// The schema history is not tracked, so the schemas of the table are
+	// unknown rather than taken from the schema cache.
+	require.Len(t, ddl.TableSchemaChanges, 1)
+	change := ddl.TableSchemaChanges[0]
+	assert.Equal(t, "ddl_schema_test", change.TableName)
+	assert.Nil(t, change.Before)
+	assert.Nil(t, change.After)
+	assert.Empty(t, change.AddedColumns)
+	assert.Empty(t, change.DroppedColumns)
+}
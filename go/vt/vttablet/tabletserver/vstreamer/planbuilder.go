@@ -344,6 +344,47 @@ func ruleMatches(tableName string, filter *binlogdatapb.Filter) bool {
 	return false
 }
 
+// statementDirectives returns the directives of the /*vt+ */ comments of a
+// statement, wherever they are in the statement.
+func statementDirectives(parser *sqlparser.Parser, sql string) *sqlparser.CommentDirectives {
+	var comments sqlparser.Comments
+	tkn := parser.NewStringTokenizer(sql)
+	for {
+		typ, val := tkn.Scan()
+		if typ == 0 || typ == sqlparser.LEX_ERROR {
+			break
+		}
+		if typ == sqlparser.COMMENT && strings.HasPrefix(val, "/*vt+") {
+			comments = append(comments, val)
+		}
+	}
+	return comments.Parsed().Directives()
+}
+
+// commentTagsMatch returns true if the directives of a statement match one
+// of the tags: a tag "KEY" matches the KEY directive whatever its value, and
+// "KEY=VALUE" only matches it with that value.
+func commentTagsMatch(directives *sqlparser.CommentDirectives, tags []string) bool {
+	for _, tag := range tags {
+		key, value, hasValue := strings.Cut(tag, "=")
+		if val, ok := directives.GetString(key, ""); ok && (!hasValue || val == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentTagsSkip returns true if the comment tags of the filter filter out
+// the rows written by a statement with the given directives. The rows of
+// the statements whose binlog has no ROWS_QUERY_LOG_EVENT have no
+// directives.
+func commentTagsSkip(directives *sqlparser.CommentDirectives, filter *binlogdatapb.Filter) bool {
+	if len(filter.GetIncludeCommentTags()) > 0 && !commentTagsMatch(directives, filter.GetIncludeCommentTags()) {
+		return true
+	}
+	return commentTagsMatch(directives, filter.GetExcludeCommentTags())
+}
+
 // tableMatches is similar to buildPlan below and MatchTable in vreplication/table_plan_builder.go.
 func tableMatches(table sqlparser.TableName, dbname string, filter *binlogdatapb.Filter) bool {
 	if table.Qualifier.NotEmpty() && table.Qualifier.String() != dbname {
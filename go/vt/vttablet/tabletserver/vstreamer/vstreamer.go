@@ -83,6 +83,11 @@ type vstreamer struct {
 	format  mysql.BinlogFormat
 	pos     replication.Position
 	stopPos string
+	// prevPos is the position before the current transaction.
+	prevPos replication.Position
+	// skipStatementRows is set by parseEvent when the comment tags of the
+	// filter filter out the rows of the current statement.
+	skipStatementRows bool
 
 	phase string
 	vse   *Engine
@@ -452,7 +457,9 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 				Type: binlogdatapb.VEventType_BEGIN,
 			})
 		}
+		vs.prevPos = vs.pos
 		vs.pos = replication.AppendGTID(vs.pos, gtid)
+		vs.skipStatementRows = commentTagsSkip(nil, vs.filter)
 	case ev.IsXID():
 		vevents = append(vevents, &binlogdatapb.VEvent{
 			Type: binlogdatapb.VEventType_GTID,
@@ -509,13 +516,19 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 				Type: binlogdatapb.VEventType_COMMIT,
 			})
 		case sqlparser.StmtDDL:
+			var tableSchemaChanges []*binlogdatapb.TableSchemaChange
 			if mustSendDDL(q, vs.cp.DBName(), vs.filter, vs.vse.env.Environment().Parser()) {
+				if vs.filter.DdlTableSchemas {
+					tables := ddlTableNames(q.SQL, vs.cp.DBName(), vs.filter, vs.vse.env.Environment().Parser())
+					tableSchemaChanges = vs.tableSchemasBeforeDDL(tables)
+				}
 				vevents = append(vevents, &binlogdatapb.VEvent{
 					Type: binlogdatapb.VEventType_GTID,
 					Gtid: replication.EncodePosition(vs.pos),
 				}, &binlogdatapb.VEvent{
-					Type:      binlogdatapb.VEventType_DDL,
-					Statement: q.SQL,
+					Type:               binlogdatapb.VEventType_DDL,
+					Statement:          q.SQL,
+					TableSchemaChanges: tableSchemaChanges,
 				})
 			} else {
 				// If the DDL need not be sent, send a dummy OTHER event.
@@ -529,6 +542,9 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 			if schema.MustReloadSchemaOnDDL(q.SQL, vs.cp.DBName(), vs.vse.env.Environment().Parser()) {
 				vs.se.ReloadAt(context.Background(), vs.pos)
 			}
+			if len(tableSchemaChanges) > 0 {
+				vs.addTableSchemasAfterDDL(tableSchemaChanges)
+			}
 		case sqlparser.StmtSavepoint:
 			// We currently completely skip `SAVEPOINT ...` statements.
 			//
@@ -553,6 +569,18 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 		default:
 			return nil, fmt.Errorf("unexpected statement type %s in row-based replication: %q", cat, q.SQL)
 		}
+	case ev.IsRowsQuery():
+		// This precedes the row events of each statement when
+		// binlog_rows_query_log_events is ON.
+		if len(vs.filter.GetIncludeCommentTags()) == 0 && len(vs.filter.GetExcludeCommentTags()) == 0 {
+			return nil, nil
+		}
+		q, err := ev.RowsQuery(vs.format)
+		if err != nil {
+			return nil, fmt.Errorf("can't get rows query from binlog event: %v, event data: %#v", err, ev)
+		}
+		directives := statementDirectives(vs.vse.env.Environment().Parser(), q)
+		vs.skipStatementRows = commentTagsSkip(directives, vs.filter)
 	case ev.IsTableMap():
 		// This is very frequent. It precedes every row event.
 		// If it's the first time for a table, we generate a FIELD
@@ -617,6 +645,11 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 		if plan == nil {
 			return nil, nil
 		}
+		if vs.skipStatementRows && id != vs.journalTableID && id != vs.versionTableID {
+			// The comment tags of the statement which wrote the rows
+			// filter them out.
+			return nil, nil
+		}
 		rows, err := ev.Rows(vs.format, plan.TableMap)
 		if err != nil {
 			return nil, err
@@ -983,6 +1016,9 @@ func (vs *vstreamer) processRowEvent(vevents []*binlogdatapb.VEvent, plan *strea
 		rowChanges = append(rowChanges, rowChange)
 	}
 	if len(rowChanges) != 0 {
+		if workflow := vs.filter.GetWorkflowName(); workflow != "" {
+			vs.vse.vstreamerTableRowsStreamed.Add([]string{workflow, plan.Table.Name}, int64(len(rowChanges)))
+		}
 		vevents = append(vevents, &binlogdatapb.VEvent{
 			Type: binlogdatapb.VEventType_ROW,
 			RowEvent: &binlogdatapb.RowEvent{
@@ -689,6 +689,38 @@ func TestFilteredVarBinary(t *testing.T) {
 	ts.Run()
 }
 
+// TestCommentTags confirms that the rows written by the statements tagged
+// with an excluded comment tag are not streamed.
+func TestCommentTags(t *testing.T) {
+	ts := &TestSpec{
+		t: t,
+		ddls: []string{
+			"create table t1(id1 int, val varbinary(128), primary key(id1))",
+		},
+		options: &TestSpecOptions{
+			filter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{
+					Match: "t1",
+				}},
+				ExcludeCommentTags: []string{"JOB=backfill"},
+			},
+		},
+	}
+	defer ts.Close()
+	ts.Init()
+	ts.tests = [][]*TestQuery{{
+		{"set @@session.binlog_rows_query_log_events = ON", noEvents},
+		{"begin", nil},
+		{"insert into t1 values (1, 'kepler')", nil},
+		{"insert /*vt+ JOB=backfill */ into t1 values (2, 'newton')", noEvents},
+		{"insert /*vt+ JOB=resharding */ into t1 values (3, 'newton')", nil},
+		{"update /*vt+ JOB=backfill */ t1 set val = 'newton' where id1 = 1", noEvents},
+		{"commit", nil},
+		{"set @@session.binlog_rows_query_log_events = OFF", noEvents},
+	}}
+	ts.Run()
+}
+
 // TestFilteredInt confirms that adding a filter using an int column results in the correct set of events.
 func TestFilteredInt(t *testing.T) {
 	ts := &TestSpec{
@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -78,6 +79,12 @@ type Controller struct {
 	// TS is the return value for TopoServer.
 	TS *topo.Server
 
+	// CheckThrottlerResult is the return value for CheckThrottler.
+	CheckThrottlerResult *throttle.CheckResult
+
+	// SelfCheckResults is the return value for SelfCheck.
+	SelfCheckResults []*tabletmanagerdatapb.SelfCheckResult
+
 	// mu protects the next fields in this structure. They are
 	// accessed by both the methods in this interface, and the
 	// background health check.
@@ -91,6 +98,12 @@ type Controller struct {
 
 	// queryRulesMap has the latest query rules.
 	queryRulesMap map[string]*rules.Rules
+
+	// semiSyncDivergence is the last divergence set with SetSemiSyncDivergence.
+	semiSyncDivergence string
+
+	// writesFrozen is the last value set with SetWritesFrozen.
+	writesFrozen bool
 }
 
 // NewController returns a mock of tabletserver.Controller
@@ -211,6 +224,34 @@ func (tqsc *Controller) BroadcastHealth() {
 	}
 }
 
+// SetSemiSyncDivergence is part of the tabletserver.Controller interface
+func (tqsc *Controller) SetSemiSyncDivergence(divergence string) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	tqsc.semiSyncDivergence = divergence
+}
+
+// SemiSyncDivergence returns the last divergence set with SetSemiSyncDivergence.
+func (tqsc *Controller) SemiSyncDivergence() string {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	return tqsc.semiSyncDivergence
+}
+
+// SetWritesFrozen is part of the tabletserver.Controller interface
+func (tqsc *Controller) SetWritesFrozen(frozen bool) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	tqsc.writesFrozen = frozen
+}
+
+// WritesFrozen returns the last value set with SetWritesFrozen.
+func (tqsc *Controller) WritesFrozen() bool {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	return tqsc.writesFrozen
+}
+
 // TopoServer is part of the tabletserver.Controller interface.
 func (tqsc *Controller) TopoServer() *topo.Server {
 	return tqsc.TS
@@ -218,7 +259,12 @@ func (tqsc *Controller) TopoServer() *topo.Server {
 
 // CheckThrottler is part of the tabletserver.Controller interface
 func (tqsc *Controller) CheckThrottler(ctx context.Context, appName string, flags *throttle.CheckFlags) *throttle.CheckResult {
-	return nil
+	return tqsc.CheckThrottlerResult
+}
+
+// SelfCheck is part of the tabletserver.Controller interface
+func (tqsc *Controller) SelfCheck(ctx context.Context) []*tabletmanagerdatapb.SelfCheckResult {
+	return tqsc.SelfCheckResults
 }
 
 // EnterLameduck implements tabletserver.Controller.
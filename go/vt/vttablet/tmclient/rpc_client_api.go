@@ -78,6 +78,10 @@ type TabletManagerClient interface {
 	// An empty/nil variable name parameter slice means you want all of them.
 	GetGlobalStatusVars(ctx context.Context, tablet *topodatapb.Tablet, variables []string) (map[string]string, error)
 
+	// SelfCheck asks the remote tablet to run its self checks, and returns
+	// their results.
+	SelfCheck(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.SelfCheckResponse, error)
+
 	//
 	// Various read-write methods
 	//
@@ -259,6 +263,14 @@ type TabletManagerClient interface {
 	// RestoreFromBackup deletes local data and restores database from backup
 	RestoreFromBackup(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestoreFromBackupRequest) (logutil.EventStream, error)
 
+	//
+	// MySQL related methods
+	//
+
+	// RestartMySQL drains the tablet, restarts its MySQL and restores
+	// its replication and serving state, streaming the progress.
+	RestartMySQL(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestartMySQLRequest) (logutil.EventStream, error)
+
 	// Throttler
 	CheckThrottler(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.CheckThrottlerRequest) (*tabletmanagerdatapb.CheckThrottlerResponse, error)
 
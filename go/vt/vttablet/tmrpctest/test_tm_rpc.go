@@ -404,6 +404,36 @@ func tmRPCTestGetGlobalStatusVarsPanic(ctx context.Context, t *testing.T, client
 	expectHandleRPCPanic(t, "GetGlobalStatusVars", false /*verbose*/, err)
 }
 
+var testSelfCheckReply = &tabletmanagerdatapb.SelfCheckResponse{
+	Status: tabletmanagerdatapb.SelfCheckResult_YELLOW,
+	Checks: []*tabletmanagerdatapb.SelfCheckResult{{
+		Name:    "pools",
+		Status:  tabletmanagerdatapb.SelfCheckResult_GREEN,
+		Message: "connections in use: ConnPool 1/16",
+	}, {
+		Name:    "disk",
+		Status:  tabletmanagerdatapb.SelfCheckResult_YELLOW,
+		Message: "90.0% of the file system of /vt/data used",
+	}},
+}
+
+func (fra *fakeRPCTM) SelfCheck(ctx context.Context) *tabletmanagerdatapb.SelfCheckResponse {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	return testSelfCheckReply
+}
+
+func tmRPCTestSelfCheck(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.SelfCheck(ctx, tablet)
+	compareError(t, "SelfCheck", err, result, testSelfCheckReply)
+}
+
+func tmRPCTestSelfCheckPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.SelfCheck(ctx, tablet)
+	expectHandleRPCPanic(t, "SelfCheck", false /*verbose*/, err)
+}
+
 //
 // Various read-write methods
 //
@@ -1373,6 +1403,47 @@ func tmRPCTestRestoreFromBackupPanic(ctx context.Context, t *testing.T, client t
 	expectHandleRPCPanic(t, "RestoreFromBackup", true /*verbose*/, err)
 }
 
+//
+// MySQL related methods
+//
+
+var testRestartMySQLRequest = &tabletmanagerdatapb.RestartMySQLRequest{
+	AllowPrimary:    true,
+	ShutdownTimeout: protoutil.DurationToProto(time.Minute),
+}
+var testRestartMySQLCalled = false
+
+func (fra *fakeRPCTM) RestartMySQL(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestartMySQLRequest) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "RestartMySQL args", request, testRestartMySQLRequest)
+	logStuff(logger, 10)
+	testRestartMySQLCalled = true
+	return nil
+}
+
+func tmRPCTestRestartMySQL(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	stream, err := client.RestartMySQL(ctx, tablet, testRestartMySQLRequest)
+	if err != nil {
+		t.Fatalf("RestartMySQL failed: %v", err)
+	}
+	err = compareLoggedStuff(t, "RestartMySQL", stream, 10)
+	compareError(t, "RestartMySQL", err, true, testRestartMySQLCalled)
+}
+
+func tmRPCTestRestartMySQLPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	stream, err := client.RestartMySQL(ctx, tablet, testRestartMySQLRequest)
+	if err != nil {
+		t.Fatalf("RestartMySQL failed: %v", err)
+	}
+	e, err := stream.Recv()
+	if err == nil {
+		t.Fatalf("Unexpected RestartMySQL logs: %v", e)
+	}
+	expectHandleRPCPanic(t, "RestartMySQL", true /*verbose*/, err)
+}
+
 func tmRPCTestCheckThrottler(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.CheckThrottlerRequest) {
 	_, err := client.CheckThrottler(ctx, tablet, req)
 	expectHandleRPCPanic(t, "CheckThrottler", false /*verbose*/, err)
@@ -1415,6 +1486,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestGetSchema(ctx, t, client, tablet)
 	tmRPCTestGetPermissions(ctx, t, client, tablet)
 	tmRPCTestGetGlobalStatusVars(ctx, t, client, tablet)
+	tmRPCTestSelfCheck(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnly(ctx, t, client, tablet)
@@ -1463,6 +1535,9 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestBackup(ctx, t, client, tablet)
 	tmRPCTestRestoreFromBackup(ctx, t, client, tablet, restoreFromBackupRequest)
 
+	// MySQL related methods
+	tmRPCTestRestartMySQL(ctx, t, client, tablet)
+
 	// Throttler related methods
 	tmRPCTestCheckThrottler(ctx, t, client, tablet, checkThrottlerRequest)
 
@@ -1476,6 +1551,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestGetSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestGetPermissionsPanic(ctx, t, client, tablet)
 	tmRPCTestGetGlobalStatusVarsPanic(ctx, t, client, tablet)
+	tmRPCTestSelfCheckPanic(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnlyPanic(ctx, t, client, tablet)
@@ -1520,5 +1596,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestBackupPanic(ctx, t, client, tablet)
 	tmRPCTestRestoreFromBackupPanic(ctx, t, client, tablet, restoreFromBackupRequest)
 
+	// MySQL related methods
+	tmRPCTestRestartMySQLPanic(ctx, t, client, tablet)
+
 	client.Close()
 }
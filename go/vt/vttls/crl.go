@@ -21,6 +21,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"vitess.io/vitess/go/vt/log"
@@ -41,13 +42,26 @@ func certIsRevoked(cert *x509.Certificate, crl *x509.RevocationList) bool {
 	return false
 }
 
+var crlSets = sync.Map{}
+
+func loadCachedCRLSet(crl string) ([]*x509.RevocationList, error) {
+	return loadCached(&crlSets, []string{crl}, func() ([]*x509.RevocationList, error) {
+		return loadCRLSet(crl)
+	})
+}
+
 func verifyPeerCertificateAgainstCRL(crl string) (verifyPeerCertificateFunc, error) {
-	crlSet, err := loadCRLSet(crl)
-	if err != nil {
+	if _, err := loadCachedCRLSet(crl); err != nil {
 		return nil, err
 	}
 
 	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		// Load the CRL on each verification, so that updates to the file
+		// are picked up by long-lived configs.
+		crlSet, err := loadCachedCRLSet(crl)
+		if err != nil {
+			return err
+		}
 		for _, chain := range verifiedChains {
 			for i := 0; i < len(chain)-1; i++ {
 				cert := chain[i]
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	// reloadCheckInterval is how often the files backing the loaded
+	// certificates, CA pools and CRLs are checked for changes.
+	reloadCheckInterval = 10 * time.Second
+
+	reloads = stats.NewCountersWithSingleLabel("TLSFileReloads", "Number of reloads of changed TLS certificate, CA and CRL files, by result", "Result")
+)
+
+// cachedFiles holds the value loaded from a set of files, and reloads it when
+// the modification time of any of the files changes.
+type cachedFiles[T any] struct {
+	files []string
+	load  func() (T, error)
+
+	mu        sync.Mutex
+	loaded    bool
+	value     T
+	modTimes  []time.Time
+	lastCheck time.Time
+}
+
+// loadCached returns the value loaded from the given files, using the cache
+// entry stored in m for them. Files are checked for changes at most every
+// reloadCheckInterval. If reloading a changed file fails, for instance
+// because it is being rewritten, the previous value is kept.
+func loadCached[T any](m *sync.Map, files []string, load func() (T, error)) (T, error) {
+	entry, _ := m.LoadOrStore(tlsCertificatesIdentifier(files...), &cachedFiles[T]{files: files, load: load})
+	return entry.(*cachedFiles[T]).get()
+}
+
+func (cf *cachedFiles[T]) get() (T, error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	now := time.Now()
+	if cf.loaded && now.Sub(cf.lastCheck) < reloadCheckInterval {
+		return cf.value, nil
+	}
+	cf.lastCheck = now
+
+	modTimes := make([]time.Time, len(cf.files))
+	var statErr error
+	for i, file := range cf.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			statErr = err
+			break
+		}
+		modTimes[i] = info.ModTime()
+	}
+	if cf.loaded && (statErr != nil || equalTimes(modTimes, cf.modTimes)) {
+		return cf.value, nil
+	}
+
+	value, err := cf.load()
+	if err != nil {
+		if cf.loaded {
+			reloads.Add("error", 1)
+			log.Errorf("Failed to reload %v, using the previously loaded version: %v", cf.files, err)
+			return cf.value, nil
+		}
+		return value, err
+	}
+	if cf.loaded {
+		reloads.Add("success", 1)
+		log.Infof("Reloaded %v", cf.files)
+	}
+
+	cf.loaded = true
+	cf.value = value
+	cf.modTimes = modTimes
+	return value, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const spiffeScheme = "spiffe"
+
+// SPIFFEID returns the SPIFFE ID of an X.509 SVID, which is its only URI SAN.
+func SPIFFEID(cert *x509.Certificate) (string, error) {
+	var id string
+	for _, uri := range cert.URIs {
+		if uri.Scheme != spiffeScheme {
+			continue
+		}
+		if id != "" {
+			return "", vterrors.Errorf(vtrpc.Code_UNAUTHENTICATED, "certificate has more than one SPIFFE ID")
+		}
+		if uri.Host == "" {
+			return "", vterrors.Errorf(vtrpc.Code_UNAUTHENTICATED, "SPIFFE ID %s has no trust domain", uri)
+		}
+		id = uri.String()
+	}
+	if id == "" {
+		return "", vterrors.Errorf(vtrpc.Code_UNAUTHENTICATED, "certificate has no SPIFFE ID")
+	}
+	return id, nil
+}
+
+// ValidateSPIFFEIDs checks that the given allowed SPIFFE IDs are well formed.
+// An allowed ID is either a full SPIFFE ID, or a prefix ending in "/*", which
+// allows all the IDs under it, such as "spiffe://example.org/vitess/*".
+func ValidateSPIFFEIDs(allowedIDs []string) error {
+	for _, id := range allowedIDs {
+		if !strings.HasPrefix(id, spiffeScheme+"://") || len(id) == len(spiffeScheme+"://") {
+			return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid SPIFFE ID %q, must start with spiffe://<trust domain>", id)
+		}
+	}
+	return nil
+}
+
+// spiffeIDAllowed returns whether id matches one of the allowed IDs.
+func spiffeIDAllowed(id string, allowedIDs []string) bool {
+	for _, allowed := range allowedIDs {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok && strings.HasSuffix(prefix, "/") {
+			if strings.HasPrefix(id, prefix) {
+				return true
+			}
+			continue
+		}
+		if id == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireSPIFFEIDs updates the config so that the handshake fails unless the
+// peer presents a certificate with one of the allowed SPIFFE IDs. It is a
+// no-op if allowedIDs is empty. The check runs after the config's own
+// verification of the peer certificate chain.
+func RequireSPIFFEIDs(config *tls.Config, allowedIDs []string) error {
+	if len(allowedIDs) == 0 {
+		return nil
+	}
+	if err := ValidateSPIFFEIDs(allowedIDs); err != nil {
+		return err
+	}
+
+	verifyConnection := config.VerifyConnection
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		if verifyConnection != nil {
+			if err := verifyConnection(cs); err != nil {
+				return err
+			}
+		}
+		if len(cs.PeerCertificates) == 0 {
+			return vterrors.Errorf(vtrpc.Code_UNAUTHENTICATED, "peer did not present a certificate")
+		}
+		id, err := SPIFFEID(cs.PeerCertificates[0])
+		if err != nil {
+			return err
+		}
+		if !spiffeIDAllowed(id, allowedIDs) {
+			return vterrors.Errorf(vtrpc.Code_PERMISSION_DENIED, "SPIFFE ID %s is not allowed", id)
+		}
+		return nil
+	}
+	return nil
+}
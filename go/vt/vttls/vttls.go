@@ -99,8 +99,6 @@ func TLSVersionToNumber(tlsVersion string) (uint16, error) {
 	}
 }
 
-var onceByKeys = sync.Map{}
-
 // ClientConfig returns the TLS config to use for a client to
 // connect to a server with the provided parameters.
 func ClientConfig(mode SslMode, cert, key, ca, crl, name string, minTLSVersion uint16) (*tls.Config, error) {
@@ -158,8 +156,16 @@ func ClientConfig(mode SslMode, cert, key, ca, crl, name string, minTLSVersion u
 			for _, cert := range cs.PeerCertificates[1:] {
 				opts.Intermediates.AddCert(cert)
 			}
-			_, err := cs.PeerCertificates[0].Verify(opts)
-			return err
+			chains, err := cs.PeerCertificates[0].Verify(opts)
+			if err != nil {
+				return err
+			}
+			// The chains are not verified by the handshake itself, so the
+			// CRL must be checked against the ones verified here.
+			if config.VerifyPeerCertificate != nil {
+				return config.VerifyPeerCertificate(nil, chains)
+			}
+			return nil
 		}
 	case VerifyIdentity:
 		// Nothing to do here, default config is the strictest and correct.
@@ -226,39 +232,23 @@ func ServerConfig(cert, key, ca, crl, serverCA string, minTLSVersion uint16) (*t
 var certPools = sync.Map{}
 
 func loadx509CertPool(ca string) (*x509.CertPool, error) {
-	once, _ := onceByKeys.LoadOrStore(ca, &sync.Once{})
-
-	var err error
-	once.(*sync.Once).Do(func() {
-		err = doLoadx509CertPool(ca)
+	return loadCached(&certPools, []string{ca}, func() (*x509.CertPool, error) {
+		return doLoadx509CertPool(ca)
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	result, ok := certPools.Load(ca)
-
-	if !ok {
-		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "Cannot find loaded x509 cert pool for ca: %s", ca)
-	}
-
-	return result.(*x509.CertPool), nil
 }
 
-func doLoadx509CertPool(ca string) error {
+func doLoadx509CertPool(ca string) (*x509.CertPool, error) {
 	b, err := os.ReadFile(ca)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read ca file: %s", ca)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read ca file: %s", ca)
 	}
 
 	cp := x509.NewCertPool()
 	if !cp.AppendCertsFromPEM(b) {
-		return vterrors.Errorf(vtrpc.Code_UNKNOWN, "failed to append certificates")
+		return nil, vterrors.Errorf(vtrpc.Code_UNKNOWN, "failed to append certificates")
 	}
 
-	certPools.Store(ca, cp)
-
-	return nil
+	return cp, nil
 }
 
 var tlsCertificates = sync.Map{}
@@ -268,99 +258,55 @@ func tlsCertificatesIdentifier(tokens ...string) string {
 }
 
 func loadTLSCertificate(cert, key string) (*[]tls.Certificate, error) {
-	tlsIdentifier := tlsCertificatesIdentifier(cert, key)
-	once, _ := onceByKeys.LoadOrStore(tlsIdentifier, &sync.Once{})
-
-	var err error
-	once.(*sync.Once).Do(func() {
-		err = doLoadTLSCertificate(cert, key)
+	return loadCached(&tlsCertificates, []string{cert, key}, func() (*[]tls.Certificate, error) {
+		return doLoadTLSCertificate(cert, key)
 	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	result, ok := tlsCertificates.Load(tlsIdentifier)
-
-	if !ok {
-		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "Cannot find loaded tls certificate with cert: %s, key: %s", cert, key)
-	}
-
-	return result.(*[]tls.Certificate), nil
 }
 
-func doLoadTLSCertificate(cert, key string) error {
-	tlsIdentifier := tlsCertificatesIdentifier(cert, key)
-
-	var certificate []tls.Certificate
+func doLoadTLSCertificate(cert, key string) (*[]tls.Certificate, error) {
 	// Load the server cert and key.
 	crt, err := tls.LoadX509KeyPair(cert, key)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to load tls certificate, cert %s, key: %s", cert, key)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to load tls certificate, cert %s, key: %s", cert, key)
 	}
 
-	certificate = []tls.Certificate{crt}
-
-	tlsCertificates.Store(tlsIdentifier, &certificate)
-
-	return nil
+	certificate := []tls.Certificate{crt}
+	return &certificate, nil
 }
 
 var combinedTLSCertificates = sync.Map{}
 
 func combineAndLoadTLSCertificates(ca, cert, key string) (*[]tls.Certificate, error) {
-	combinedTLSIdentifier := tlsCertificatesIdentifier(ca, cert, key)
-	once, _ := onceByKeys.LoadOrStore(combinedTLSIdentifier, &sync.Once{})
-
-	var err error
-	once.(*sync.Once).Do(func() {
-		err = doLoadAndCombineTLSCertificates(ca, cert, key)
+	return loadCached(&combinedTLSCertificates, []string{ca, cert, key}, func() (*[]tls.Certificate, error) {
+		return doLoadAndCombineTLSCertificates(ca, cert, key)
 	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	result, ok := combinedTLSCertificates.Load(combinedTLSIdentifier)
-
-	if !ok {
-		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "Cannot find loaded tls certificate chain with ca: %s, cert: %s, key: %s", ca, cert, key)
-	}
-
-	return result.(*[]tls.Certificate), nil
 }
 
-func doLoadAndCombineTLSCertificates(ca, cert, key string) error {
-	combinedTLSIdentifier := tlsCertificatesIdentifier(ca, cert, key)
-
+func doLoadAndCombineTLSCertificates(ca, cert, key string) (*[]tls.Certificate, error) {
 	// Read CA certificates chain
 	caB, err := os.ReadFile(ca)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read ca file: %s", ca)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read ca file: %s", ca)
 	}
 
 	// Read server certificate
 	certB, err := os.ReadFile(cert)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read server cert file: %s", cert)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read server cert file: %s", cert)
 	}
 
 	// Read server key file
 	keyB, err := os.ReadFile(key)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read key file: %s", key)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read key file: %s", key)
 	}
 
 	// Load CA, server cert and key.
-	var certificate []tls.Certificate
 	crt, err := tls.X509KeyPair(append(certB, caB...), keyB)
 	if err != nil {
-		return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to load and merge tls certificate with CA, ca %s, cert %s, key: %s", ca, cert, key)
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to load and merge tls certificate with CA, ca %s, cert %s, key: %s", ca, cert, key)
 	}
 
-	certificate = []tls.Certificate{crt}
-
-	combinedTLSCertificates.Store(combinedTLSIdentifier, &certificate)
-
-	return nil
+	certificate := []tls.Certificate{crt}
+	return &certificate, nil
 }
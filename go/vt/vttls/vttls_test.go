@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/tlstest"
+)
+
+func TestCertificateReload(t *testing.T) {
+	oldInterval := reloadCheckInterval
+	reloadCheckInterval = 0
+	defer func() { reloadCheckInterval = oldInterval }()
+
+	root := t.TempDir()
+	tlstest.CreateCA(root)
+	tlstest.CreateSignedCert(root, tlstest.CA, "01", "server", "server.example.com")
+	cert := path.Join(root, "server-cert.pem")
+	key := path.Join(root, "server-key.pem")
+
+	// touch makes sure the modification time changes, whatever the
+	// resolution of the filesystem timestamps.
+	touch := func(offset time.Duration) {
+		mtime := time.Now().Add(offset)
+		require.NoError(t, os.Chtimes(cert, mtime, mtime))
+		require.NoError(t, os.Chtimes(key, mtime, mtime))
+	}
+
+	config, err := ServerConfig(cert, key, "", "", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	first := config.Certificates[0].Certificate[0]
+
+	// Unchanged files are not reloaded.
+	config, err = ServerConfig(cert, key, "", "", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	assert.Equal(t, first, config.Certificates[0].Certificate[0])
+
+	// A rotated certificate is picked up by new configs.
+	tlstest.CreateSignedCert(root, tlstest.CA, "02", "server", "server.example.com")
+	touch(time.Hour)
+	config, err = ServerConfig(cert, key, "", "", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	second := config.Certificates[0].Certificate[0]
+	assert.NotEqual(t, first, second)
+
+	// A broken file, for instance one being rewritten, keeps the previous
+	// certificate.
+	require.NoError(t, os.WriteFile(cert, []byte("garbage"), 0600))
+	touch(2 * time.Hour)
+	config, err = ServerConfig(cert, key, "", "", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	assert.Equal(t, second, config.Certificates[0].Certificate[0])
+}
+
+func spiffeCert(t *testing.T, ids ...string) *x509.Certificate {
+	cert := &x509.Certificate{}
+	for _, id := range ids {
+		uri, err := url.Parse(id)
+		require.NoError(t, err)
+		cert.URIs = append(cert.URIs, uri)
+	}
+	return cert
+}
+
+func TestRequireSPIFFEIDs(t *testing.T) {
+	allowed := []string{"spiffe://example.org/vtgate", "spiffe://example.org/vttablet/*"}
+
+	testcases := []struct {
+		name    string
+		peer    []*x509.Certificate
+		wantErr string
+	}{{
+		name: "exact match",
+		peer: []*x509.Certificate{spiffeCert(t, "spiffe://example.org/vtgate")},
+	}, {
+		name: "prefix match",
+		peer: []*x509.Certificate{spiffeCert(t, "https://example.org", "spiffe://example.org/vttablet/zone1")},
+	}, {
+		name:    "not allowed",
+		peer:    []*x509.Certificate{spiffeCert(t, "spiffe://example.org/vtctld")},
+		wantErr: "SPIFFE ID spiffe://example.org/vtctld is not allowed",
+	}, {
+		name:    "prefix without separator",
+		peer:    []*x509.Certificate{spiffeCert(t, "spiffe://example.org/vttablet-evil")},
+		wantErr: "is not allowed",
+	}, {
+		name:    "other trust domain",
+		peer:    []*x509.Certificate{spiffeCert(t, "spiffe://evil.org/vtgate")},
+		wantErr: "is not allowed",
+	}, {
+		name:    "no SPIFFE ID",
+		peer:    []*x509.Certificate{spiffeCert(t, "https://example.org/vtgate")},
+		wantErr: "certificate has no SPIFFE ID",
+	}, {
+		name:    "several SPIFFE IDs",
+		peer:    []*x509.Certificate{spiffeCert(t, "spiffe://example.org/vtgate", "spiffe://example.org/vtctld")},
+		wantErr: "more than one SPIFFE ID",
+	}, {
+		name:    "no certificate",
+		wantErr: "peer did not present a certificate",
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &tls.Config{}
+			require.NoError(t, RequireSPIFFEIDs(config, allowed))
+			err := config.VerifyConnection(tls.ConnectionState{PeerCertificates: tc.peer})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// The check runs after the existing verification.
+	config := &tls.Config{VerifyConnection: func(tls.ConnectionState) error { return assert.AnError }}
+	require.NoError(t, RequireSPIFFEIDs(config, allowed))
+	require.ErrorIs(t, config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{spiffeCert(t, "spiffe://example.org/vtgate")}}), assert.AnError)
+
+	require.ErrorContains(t, RequireSPIFFEIDs(&tls.Config{}, []string{"example.org/vtgate"}), "invalid SPIFFE ID")
+	require.ErrorContains(t, RequireSPIFFEIDs(&tls.Config{}, []string{"spiffe://"}), "invalid SPIFFE ID")
+}